@@ -0,0 +1,39 @@
+// Package nexbot provides the default assets embedded into bundle builds
+// (go build -tags bundle): the workspace bootstrap files from docs/workspace
+// and the skills from skills/. A bundled binary runs without prompt
+// directories; files in the workspace override the embedded ones.
+package nexbot
+
+import "io/fs"
+
+// bundled holds the embedded assets, nil in regular builds
+var bundled fs.FS
+
+// Bundled reports whether the binary was built with embedded assets.
+func Bundled() bool {
+	return bundled != nil
+}
+
+// Prompts returns the default bootstrap files (AGENTS.md, IDENTITY.md, ...),
+// or nil in regular builds.
+func Prompts() fs.FS {
+	return sub("docs/workspace")
+}
+
+// Skills returns the builtin skills (directories with SKILL.md), or nil in
+// regular builds.
+func Skills() fs.FS {
+	return sub("skills")
+}
+
+// sub returns a directory of the embedded assets.
+func sub(dir string) fs.FS {
+	if bundled == nil {
+		return nil
+	}
+	assets, err := fs.Sub(bundled, dir)
+	if err != nil {
+		return nil
+	}
+	return assets
+}
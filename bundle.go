@@ -0,0 +1,17 @@
+//go:build bundle
+
+package nexbot
+
+import (
+	"embed"
+
+	// Bundled binaries run in scratch containers without a zoneinfo database
+	_ "time/tzdata"
+)
+
+//go:embed docs/workspace/*.md skills
+var assets embed.FS
+
+func init() {
+	bundled = assets
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aatumaykin/nexbot/internal/analytics"
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/workspace"
+)
+
+var (
+	analyticsConfigPath string
+	analyticsDays       int
+	analyticsJSON       bool
+)
+
+var analyticsCmd = &cobra.Command{
+	Use:   "analytics",
+	Short: "Show daily conversation statistics",
+	Long: `Show the statistics collected by [analytics]: messages, distinct users,
+errors and answer latency percentiles per day, and tool usage of the period.
+The running bot saves the current day every minute.
+
+Example usage:
+  nexbot analytics
+  nexbot analytics --days 30
+  nexbot analytics --json`,
+	Args: cobra.NoArgs,
+	Run:  runAnalytics,
+}
+
+func runAnalytics(cmd *cobra.Command, args []string) {
+	configPath := analyticsConfigPath
+	if configPath == "" {
+		configPath = constants.DefaultConfigPath
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	if !cfg.Analytics.Enabled {
+		fmt.Fprintln(os.Stderr, "⚠️  Analytics is disabled ([analytics] enabled = false); showing stored days only")
+	}
+
+	store := analytics.NewStore(workspace.New(cfg.Workspace).Path(), cfg.Analytics.RetentionDays, nil)
+	days, err := store.Days(analyticsDays)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	if analyticsJSON {
+		summaries := make([]analytics.Summary, len(days))
+		for i, day := range days {
+			summaries[i] = day.Summarize()
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(map[string]any{"days": summaries, "total": analytics.Merge(days).Summarize()}); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Print(analytics.Format(days))
+}
+
+func init() {
+	rootCmd.AddCommand(analyticsCmd)
+
+	analyticsCmd.Flags().StringVarP(&analyticsConfigPath, "config", "c", "", "Path to configuration file (default: ~/.config/nexbot/config.toml)")
+	analyticsCmd.Flags().IntVar(&analyticsDays, "days", 7, "Number of days to show, including today")
+	analyticsCmd.Flags().BoolVar(&analyticsJSON, "json", false, "Print the statistics as JSON")
+}
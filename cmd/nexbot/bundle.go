@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aatumaykin/nexbot"
+)
+
+var bundleExportForce bool
+
+// bundleCmd represents the bundle command
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Show the assets embedded into the binary",
+	Long: `Bundle builds (make build-bundle, go build -tags bundle) embed the
+default workspace files (AGENTS.md, IDENTITY.md, ...) and builtin skills,
+so a single binary or a scratch container runs without mounting prompt
+directories. Files in the workspace override the embedded ones.
+
+Example usage:
+  nexbot bundle
+  nexbot bundle export ~/.nexbot`,
+	Run: runBundle,
+}
+
+// bundleExportCmd exports the embedded assets for customization
+var bundleExportCmd = &cobra.Command{
+	Use:   "export <dir>",
+	Short: "Write the embedded assets to a directory",
+	Long: `Write the embedded workspace files to <dir> and the builtin skills
+to <dir>/skills, to customize them. Existing files are kept unless --force
+is given.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runBundleExport,
+}
+
+func runBundle(cmd *cobra.Command, args []string) {
+	if !nexbot.Bundled() {
+		fmt.Println("This binary has no embedded assets (build with -tags bundle)")
+		return
+	}
+
+	for _, assets := range bundleAssets("") {
+		_ = fs.WalkDir(assets.fsys, ".", func(path string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				fmt.Println(filepath.Join(assets.dir, filepath.FromSlash(path)))
+			}
+			return err
+		})
+	}
+}
+
+func runBundleExport(cmd *cobra.Command, args []string) {
+	if !nexbot.Bundled() {
+		fmt.Fprintf(os.Stderr, "❌ This binary has no embedded assets (build with -tags bundle)\n")
+		os.Exit(1)
+	}
+
+	written, skipped := 0, 0
+	for _, assets := range bundleAssets(args[0]) {
+		w, s, err := exportAssets(assets.fsys, assets.dir, bundleExportForce)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to export assets: %v\n", err)
+			os.Exit(1)
+		}
+		written += w
+		skipped += s
+	}
+
+	fmt.Printf("✅ Exported %d files to %s", written, args[0])
+	if skipped > 0 {
+		fmt.Printf(" (%d existing files kept, use --force to overwrite)", skipped)
+	}
+	fmt.Println()
+}
+
+// bundledAssets is an embedded file system and its place in the workspace
+type bundledAssets struct {
+	fsys fs.FS
+	dir  string
+}
+
+// bundleAssets returns the embedded assets laid out under dir.
+func bundleAssets(dir string) []bundledAssets {
+	return []bundledAssets{
+		{nexbot.Prompts(), dir},
+		{nexbot.Skills(), filepath.Join(dir, "skills")},
+	}
+}
+
+// exportAssets writes all files of fsys to dir. Existing files are skipped
+// unless force is set. It returns the number of written and skipped files.
+func exportAssets(fsys fs.FS, dir string, force bool) (written, skipped int, err error) {
+	err = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		if _, err := os.Stat(target); err == nil && !force {
+			skipped++
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return err
+		}
+		written++
+		return nil
+	})
+	return written, skipped, err
+}
+
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+	bundleCmd.AddCommand(bundleExportCmd)
+	bundleExportCmd.Flags().BoolVarP(&bundleExportForce, "force", "f", false, "Overwrite existing files")
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestExportAssets(t *testing.T) {
+	assets := fstest.MapFS{
+		"AGENTS.md":                 {Data: []byte("default agents")},
+		"examples/example/SKILL.md": {Data: []byte("default skill")},
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "AGENTS.md"), []byte("custom agents"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	written, skipped, err := exportAssets(assets, dir, false)
+	if err != nil || written != 1 || skipped != 1 {
+		t.Fatalf("exportAssets() = %d, %d, %v, want 1 written and 1 skipped", written, skipped, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "AGENTS.md")); string(data) != "custom agents" {
+		t.Errorf("existing file was overwritten: %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "examples", "example", "SKILL.md")); string(data) != "default skill" {
+		t.Errorf("skill file = %q", data)
+	}
+
+	if written, _, err := exportAssets(assets, dir, true); err != nil || written != 2 {
+		t.Errorf("exportAssets(force) = %d, %v, want 2 written", written, err)
+	}
+}
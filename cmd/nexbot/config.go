@@ -42,6 +42,11 @@ var configValidateCmd = &cobra.Command{
 
 		log.Info("Validating configuration", logger.Field{Key: "path", Value: configPath})
 
+		// Load .env like serve does, so ${VAR} references resolve the same way
+		if err := config.LoadEnvOptional(constants.DefaultEnvPath); err != nil {
+			log.Warn("Failed to load .env file", logger.Field{Key: "error", Value: err.Error()})
+		}
+
 		// Load configuration
 		cfg, err := config.Load(configPath)
 		if err != nil {
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/experiment"
+	"github.com/aatumaykin/nexbot/internal/feedback"
+	"github.com/aatumaykin/nexbot/internal/workspace"
+)
+
+var (
+	feedbackConfigPath string
+	feedbackDays       int
+)
+
+var feedbackCmd = &cobra.Command{
+	Use:   "feedback",
+	Short: "Inspect collected user feedback",
+}
+
+var feedbackExperimentCmd = &cobra.Command{
+	Use:   "experiment",
+	Short: "Compare the variants of the prompt A/B test",
+	Long: `Compare the prompt variants of the experiment configured in [experiment]:
+sessions served by each variant, average messages per session and the
+feedback collected for the variant.
+
+Example usage:
+  nexbot feedback experiment
+  nexbot feedback experiment --days 14`,
+	Args: cobra.NoArgs,
+	Run:  runFeedbackExperiment,
+}
+
+var feedbackReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Show satisfaction by model, prompt profile and tool",
+	Long: `Aggregate feedback collected with the /feedback command and message
+reactions. Satisfaction is the share of positive ratings among rated answers;
+neutral feedback is counted but not rated.
+
+Example usage:
+  nexbot feedback report
+  nexbot feedback report --days 7`,
+	Args: cobra.NoArgs,
+	Run:  runFeedbackReport,
+}
+
+func runFeedbackReport(cmd *cobra.Command, args []string) {
+	configPath := feedbackConfigPath
+	if configPath == "" {
+		configPath = constants.DefaultConfigPath
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	entries, err := feedback.NewStore(cfg.Workspace.Path).Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	var since time.Time
+	if feedbackDays > 0 {
+		since = time.Now().AddDate(0, 0, -feedbackDays)
+	}
+
+	fmt.Print(feedback.BuildReport(entries, since).Format())
+}
+
+func runFeedbackExperiment(cmd *cobra.Command, args []string) {
+	configPath := feedbackConfigPath
+	if configPath == "" {
+		configPath = constants.DefaultConfigPath
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	if !cfg.Experiment.Enabled {
+		fmt.Fprintln(os.Stderr, "❌ No prompt experiment configured ([experiment] enabled = false)")
+		os.Exit(1)
+	}
+
+	variants := make([]experiment.Variant, len(cfg.Experiment.Variants))
+	for i, v := range cfg.Experiment.Variants {
+		variants[i] = experiment.Variant{Name: v.Name, Weight: v.Weight, Prompt: v.Prompt}
+	}
+	exp, err := experiment.New(cfg.Experiment.Name, variants)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	wsPath := workspace.New(cfg.Workspace).Path()
+	entries, err := feedback.NewStore(wsPath).Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	sessionMgr, err := session.NewManager(filepath.Join(wsPath, "sessions"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+	sessions, err := sessionMgr.List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	assignments := make([]experiment.Assignment, 0, len(sessions))
+	for _, s := range sessions {
+		if s.Variant != "" {
+			assignments = append(assignments, experiment.Assignment{SessionID: s.ID, Label: s.Variant, Messages: s.MessageCount})
+		}
+	}
+
+	var since time.Time
+	if feedbackDays > 0 {
+		since = time.Now().AddDate(0, 0, -feedbackDays)
+	}
+
+	fmt.Print(exp.BuildReport(assignments, entries, since).Format())
+}
+
+func init() {
+	rootCmd.AddCommand(feedbackCmd)
+	feedbackCmd.AddCommand(feedbackReportCmd)
+	feedbackCmd.AddCommand(feedbackExperimentCmd)
+
+	feedbackCmd.PersistentFlags().StringVarP(&feedbackConfigPath, "config", "c", "", "Path to configuration file (default: ~/.config/nexbot/config.toml)")
+	feedbackReportCmd.Flags().IntVar(&feedbackDays, "days", 0, "Only include feedback from the last N days (0 = all time)")
+	feedbackExperimentCmd.Flags().IntVar(&feedbackDays, "days", 0, "Only include feedback from the last N days (0 = all time)")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aatumaykin/nexbot/internal/cleanup"
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/constants"
+)
+
+var (
+	gcConfigPath string
+	gcDryRun     bool
+)
+
+// gcCmd represents the gc command
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Clean up old sessions and media",
+	Long: `Apply retention policies from the [cleanup] config section:
+sessions older than session_ttl_days, sessions beyond max_sessions,
+and the oldest media files when media_dirs exceed max_media_size_mb.
+
+Depending on cleanup.action, files are deleted or moved to <workspace>/archive.
+
+Example usage:
+  nexbot gc --dry-run
+  nexbot gc --config custom-config.toml`,
+	Run: runGC,
+}
+
+func runGC(cmd *cobra.Command, args []string) {
+	configPath := gcConfigPath
+	if configPath == "" {
+		configPath = constants.DefaultConfigPath
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	workspacePath := cfg.Workspace.Path
+	runner := cleanup.NewRunner(cleanup.ConfigFromSettings(cfg.Cleanup))
+
+	plan, err := runner.PlanRetention(workspacePath, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to plan cleanup: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(plan.Items) == 0 {
+		fmt.Println("✅ Nothing to clean up")
+		return
+	}
+
+	verb := string(plan.Action)
+	if gcDryRun {
+		verb = "would " + verb
+	}
+
+	for _, item := range plan.Items {
+		rel, err := filepath.Rel(workspacePath, item.Path)
+		if err != nil {
+			rel = item.Path
+		}
+		fmt.Printf("%-15s %-7s %10s  %s  (%s)\n", verb, item.Kind, formatSize(item.Size), rel, item.Reason)
+	}
+
+	if gcDryRun {
+		fmt.Printf("\nDry run: %d items, %s (no changes made)\n", len(plan.Items), formatSize(plan.TotalSize()))
+		return
+	}
+
+	stats, err := runner.ApplyRetention(workspacePath, plan, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Cleanup failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\n✅ Sessions deleted: %d, archived: %d, media removed: %d, freed: %dMB\n",
+		stats.SessionsDeleted, stats.SessionsArchived, stats.MediaRemoved, stats.MBytesFreed)
+}
+
+// formatSize formats a byte count into a human-readable string.
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+	gcCmd.Flags().StringVarP(&gcConfigPath, "config", "c", "", "Path to configuration file (default: ~/.config/nexbot/config.toml)")
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "Show what would be removed without changing anything")
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/constants"
+)
+
+var (
+	initConfigPath    string
+	initEnvPath       string
+	initProvider      string
+	initModel         string
+	initWorkspace     string
+	initAPIKey        string
+	initTelegramToken string
+	initAllowedUsers  []string
+	initShell         bool
+	initTimezone      string
+	initYes           bool
+	initForce         bool
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Generate a configuration file",
+	Long: `Generate a complete commented configuration file at the standard
+config path. Missing answers are asked interactively; with --yes the flags and
+defaults are used as is. The API key and the Telegram bot token are written to
+the .env file, the config references them as environment variables.
+
+Example usage:
+  nexbot init
+  nexbot init --yes --provider zai --api-key $ZAI_API_KEY --telegram-token 123:abc --allowed-users 123456789`,
+	Args: cobra.NoArgs,
+	Run:  runInit,
+}
+
+func runInit(cmd *cobra.Command, args []string) {
+	configPath := expandPath(initConfigPath)
+	if _, err := os.Stat(configPath); err == nil && !initForce {
+		fmt.Fprintf(os.Stderr, "❌ Config already exists: %s (use --force to overwrite)\n", configPath)
+		os.Exit(1)
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	ask := func(flag, question, value string) string {
+		if initYes || cmd.Flags().Changed(flag) {
+			return value
+		}
+		return prompt(in, question, value)
+	}
+
+	opts := config.InitOptions{
+		Provider: ask("provider", "LLM provider (zai, openai)", initProvider),
+	}
+	opts.Workspace = ask("workspace", "Workspace directory", initWorkspace)
+	opts.Model = ask("model", "Model (empty for the provider default)", initModel)
+	apiKey := ask("api-key", fmt.Sprintf("API key (stored in .env as %s)", config.APIKeyEnv(opts.Provider)), initAPIKey)
+	token := ask("telegram-token", "Telegram bot token from @BotFather (empty disables Telegram)", initTelegramToken)
+	opts.Telegram = token != ""
+	if opts.Telegram {
+		users := ask("allowed-users", "Allowed Telegram user IDs, comma separated (empty allows everyone)", strings.Join(initAllowedUsers, ","))
+		opts.AllowedUsers = splitList(users)
+	}
+	opts.Shell = ask("shell", "Enable shell commands (y/n)", yesNo(initShell)) == "y"
+	opts.Timezone = ask("timezone", "Timezone for reminders", initTimezone)
+
+	content, err := config.Generate(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to create config directory: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to write config: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Config written to %s\n", configPath)
+
+	secrets := make(map[string]string)
+	if apiKey != "" {
+		secrets[config.APIKeyEnv(opts.Provider)] = apiKey
+	}
+	if token != "" {
+		secrets[config.EnvTelegramBotToken] = token
+	}
+	if len(secrets) > 0 {
+		if err := config.SetEnv(initEnvPath, secrets); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Secrets written to %s\n", expandPath(initEnvPath))
+	}
+
+	if opts.Provider != "zai" {
+		fmt.Printf("⚠️  nexbot serve currently supports the zai provider only\n")
+	}
+	fmt.Println("Next steps: nexbot config validate, then nexbot serve")
+}
+
+// prompt asks a question on stdout and reads the answer from in.
+// An empty answer keeps the default.
+func prompt(in *bufio.Reader, question, def string) string {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", question, def)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+	answer, _ := in.ReadString('\n')
+	if answer = strings.TrimSpace(answer); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// splitList splits a comma separated list, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// yesNo formats a flag as an answer to a y/n question
+func yesNo(b bool) string {
+	if b {
+		return "y"
+	}
+	return "n"
+}
+
+// expandPath expands a leading ~ to the home directory
+func expandPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().StringVarP(&initConfigPath, "config", "c", constants.DefaultConfigPath, "Path to the configuration file to write")
+	initCmd.Flags().StringVar(&initEnvPath, "env", constants.DefaultEnvPath, "Path to the .env file for secrets")
+	initCmd.Flags().StringVar(&initProvider, "provider", "zai", "LLM provider: zai or openai")
+	initCmd.Flags().StringVar(&initModel, "model", "", "Model (default: provider default)")
+	initCmd.Flags().StringVar(&initWorkspace, "workspace", "~/.nexbot", "Workspace directory")
+	initCmd.Flags().StringVar(&initAPIKey, "api-key", "", "LLM provider API key (written to .env)")
+	initCmd.Flags().StringVar(&initTelegramToken, "telegram-token", "", "Telegram bot token (written to .env, empty disables Telegram)")
+	initCmd.Flags().StringSliceVar(&initAllowedUsers, "allowed-users", nil, "Allowed Telegram user IDs")
+	initCmd.Flags().BoolVar(&initShell, "shell", false, "Enable shell commands")
+	initCmd.Flags().StringVar(&initTimezone, "timezone", "UTC", "Timezone for cron and reminders")
+	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "Don't ask questions, use flags and defaults")
+	initCmd.Flags().BoolVarP(&initForce, "force", "f", false, "Overwrite an existing config")
+}
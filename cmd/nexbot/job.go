@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/jobs"
+	"github.com/aatumaykin/nexbot/internal/messages"
+)
+
+var (
+	jobConfigPath    string
+	jobSubmitPrompt  string
+	jobSubmitSession string
+	jobStatusWait    bool
+	jobStatusPoll    time.Duration
+)
+
+var jobCmd = &cobra.Command{
+	Use:   "job",
+	Short: "Queue non-interactive agent jobs",
+}
+
+var jobSubmitCmd = &cobra.Command{
+	Use:   "submit",
+	Short: "Queue a prompt to be processed when the bot is idle",
+	Long: `Queue a long-running task for the agent. The running bot processes queued
+jobs one at a time when no interactive messages are being handled ([jobs] section).
+When the job runs in a chat session, the result is also sent to that chat.
+
+Example usage:
+  nexbot job submit --prompt "Analyze last week's nginx logs and summarize errors"
+  nexbot job submit --prompt "Review open PRs" --session telegram:123456789`,
+	Args: cobra.NoArgs,
+	Run:  runJobSubmit,
+}
+
+var jobStatusCmd = &cobra.Command{
+	Use:   "status <job-id>",
+	Short: "Show job status and result",
+	Long: `Show the status of a job and, once finished, its result.
+
+Example usage:
+  nexbot job status job_1a2b3c4d
+  nexbot job status job_1a2b3c4d --wait`,
+	Args: cobra.ExactArgs(1),
+	Run:  runJobStatus,
+}
+
+var jobListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List queued, running and finished jobs",
+	Args:  cobra.NoArgs,
+	Run:   runJobList,
+}
+
+func runJobSubmit(cmd *cobra.Command, args []string) {
+	store, cfg, err := openJobStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	job, err := store.Submit(jobSubmitPrompt, jobSubmitSession)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to submit job: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Job %s queued (session %s)\n", job.ID, job.SessionID)
+	fmt.Printf("Check the result with: nexbot job status %s\n", job.ID)
+	if !cfg.Jobs.Enabled {
+		fmt.Println("⚠️  The job queue is disabled ([jobs] enabled = false); the job runs once it is enabled")
+	}
+}
+
+func runJobStatus(cmd *cobra.Command, args []string) {
+	store, _, err := openJobStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	job, err := store.Load(args[0])
+	for err == nil && jobStatusWait && !job.Status.Finished() {
+		time.Sleep(jobStatusPoll)
+		job, err = store.Load(args[0])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("ID:       %s\n", job.ID)
+	fmt.Printf("Status:   %s\n", job.Status)
+	fmt.Printf("Session:  %s\n", job.SessionID)
+	fmt.Printf("Created:  %s\n", job.CreatedAt.Format(time.RFC3339))
+	if job.StartedAt != nil {
+		fmt.Printf("Started:  %s\n", job.StartedAt.Format(time.RFC3339))
+	}
+	if job.FinishedAt != nil {
+		fmt.Printf("Finished: %s\n", job.FinishedAt.Format(time.RFC3339))
+	}
+	fmt.Printf("Prompt:   %s\n", job.Prompt)
+
+	switch job.Status {
+	case jobs.StatusDone:
+		fmt.Printf("\n%s\n", messages.CleanContent(job.Result))
+	case jobs.StatusFailed:
+		fmt.Printf("\nError: %s\n", job.Error)
+		os.Exit(1)
+	}
+}
+
+func runJobList(cmd *cobra.Command, args []string) {
+	store, _, err := openJobStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	list, err := store.List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(list) == 0 {
+		fmt.Println("No jobs found")
+		return
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "JOB\tSTATUS\tSESSION\tCREATED\tPROMPT")
+	for _, job := range list {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", job.ID, job.Status, job.SessionID,
+			messages.FormatLastActivity(job.CreatedAt, now), truncatePrompt(job.Prompt, 50))
+	}
+	_ = w.Flush()
+}
+
+// truncatePrompt shortens a prompt to one line of at most maxChars characters.
+func truncatePrompt(prompt string, maxChars int) string {
+	prompt = strings.Join(strings.Fields(prompt), " ")
+	if runes := []rune(prompt); len(runes) > maxChars {
+		return string(runes[:maxChars-1]) + "…"
+	}
+	return prompt
+}
+
+// openJobStore loads the configuration and opens the jobs directory.
+func openJobStore() (*jobs.Store, *config.Config, error) {
+	configPath := jobConfigPath
+	if configPath == "" {
+		configPath = constants.DefaultConfigPath
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return jobs.NewStore(filepath.Join(cfg.Workspace.Path, "jobs")), cfg, nil
+}
+
+func init() {
+	rootCmd.AddCommand(jobCmd)
+	jobCmd.AddCommand(jobSubmitCmd)
+	jobCmd.AddCommand(jobStatusCmd)
+	jobCmd.AddCommand(jobListCmd)
+
+	jobCmd.PersistentFlags().StringVarP(&jobConfigPath, "config", "c", "", "Path to configuration file (default: ~/.config/nexbot/config.toml)")
+	jobSubmitCmd.Flags().StringVarP(&jobSubmitPrompt, "prompt", "p", "", "Task for the agent")
+	jobSubmitCmd.Flags().StringVarP(&jobSubmitSession, "session", "s", "", "Session to run the job in and notify, e.g. telegram:123456789 (default: own session)")
+	_ = jobSubmitCmd.MarkFlagRequired("prompt")
+	jobStatusCmd.Flags().BoolVarP(&jobStatusWait, "wait", "w", false, "Wait until the job finishes")
+	jobStatusCmd.Flags().DurationVar(&jobStatusPoll, "interval", 5*time.Second, "Polling interval for --wait")
+}
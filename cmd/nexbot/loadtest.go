@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/loadtest"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+var (
+	loadtestConfigPath   string
+	loadtestRPS          int
+	loadtestDuration     time.Duration
+	loadtestUsers        int
+	loadtestWorkers      int
+	loadtestLLMDelay     time.Duration
+	loadtestDrainTimeout time.Duration
+	loadtestJSON         bool
+)
+
+var loadtestCmd = &cobra.Command{
+	Use:   "loadtest",
+	Short: "Drive the message bus with synthetic traffic",
+	Long: `Send synthetic inbound messages through a message bus and an agent loop
+answered by the mock LLM provider, and report throughput, saturation of the
+inbound queue and answer latency percentiles. The run uses a temporary
+workspace: no real channel, LLM provider or data of the bot is touched.
+
+Messages the processor can't take in time are dropped by the bus, exactly
+as in the running bot. With --config the bus uses the [message_bus] sizes
+of that configuration.
+
+Example usage:
+  nexbot loadtest
+  nexbot loadtest --rps 20 --duration 1m --users 50
+  nexbot loadtest --llm-delay 2s --workers 4 --json`,
+	Args: cobra.NoArgs,
+	Run:  runLoadtest,
+}
+
+func runLoadtest(cmd *cobra.Command, args []string) {
+	log, err := logger.New(logger.Config{
+		Level:  "error",
+		Format: "text",
+		Output: "stderr",
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg := loadtest.Config{
+		RPS:          loadtestRPS,
+		Duration:     loadtestDuration,
+		Users:        loadtestUsers,
+		Workers:      loadtestWorkers,
+		LLMDelay:     loadtestLLMDelay,
+		DrainTimeout: loadtestDrainTimeout,
+		Logger:       log,
+	}
+	if loadtestConfigPath != "" {
+		botCfg, err := config.Load(loadtestConfigPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.QueueCapacity = botCfg.MessageBus.Capacity
+		cfg.SubscriberSize = botCfg.MessageBus.SubscriberChannelSize
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if !loadtestJSON {
+		fmt.Fprintf(os.Stderr, "Running load test for %s (Ctrl+C to stop)...\n", loadtestDuration)
+	}
+	report, err := loadtest.Run(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	if loadtestJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Print(loadtest.Format(cfg, report))
+}
+
+func init() {
+	rootCmd.AddCommand(loadtestCmd)
+
+	loadtestCmd.Flags().StringVarP(&loadtestConfigPath, "config", "c", "", "Take the [message_bus] sizes from a configuration file")
+	loadtestCmd.Flags().IntVar(&loadtestRPS, "rps", loadtest.DefaultRPS, "Synthetic messages per second")
+	loadtestCmd.Flags().DurationVar(&loadtestDuration, "duration", loadtest.DefaultDuration, "How long messages are sent")
+	loadtestCmd.Flags().IntVar(&loadtestUsers, "users", loadtest.DefaultUsers, "Distinct users (sessions) the messages are spread over")
+	loadtestCmd.Flags().IntVar(&loadtestWorkers, "workers", loadtest.DefaultWorkers, "Messages processed concurrently (the bot processes one at a time)")
+	loadtestCmd.Flags().DurationVar(&loadtestLLMDelay, "llm-delay", loadtest.DefaultLLMDelay, "Simulated latency of every LLM call")
+	loadtestCmd.Flags().DurationVar(&loadtestDrainTimeout, "drain-timeout", loadtest.DefaultDrainTimeout, "How long to wait for answers after the last message")
+	loadtestCmd.Flags().BoolVar(&loadtestJSON, "json", false, "Print the report as JSON")
+}
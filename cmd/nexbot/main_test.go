@@ -12,7 +12,7 @@ func TestCommandStructure(t *testing.T) {
 
 	// Check that subcommands are added
 	subcommands := rootCmd.Commands()
-	expectedCommands := []string{"version", "config", "serve", "test"}
+	expectedCommands := []string{"version", "config", "serve", "test", "init", "bundle"}
 	foundCommands := make(map[string]bool)
 
 	for _, cmd := range subcommands {
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/egress"
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// modelsTimeout limits the model listing request of each provider
+const modelsTimeout = 30 * time.Second
+
+var (
+	modelsConfigPath string
+	modelsJSON       bool
+)
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "List the models of the configured LLM providers",
+	Long: `List the models available to each LLM provider with an API key in the
+configuration, with their capabilities: native tool calling, image input
+and context window. Capabilities come from the built-in model list; models
+missing from it show "?". When a provider cannot list its models, the
+built-in list is shown. The model in agent.model is marked with "*".
+
+Example usage:
+  nexbot models
+  nexbot models --json`,
+	Args: cobra.NoArgs,
+	Run:  runModels,
+}
+
+// providerModels are the models of one provider.
+type providerModels struct {
+	Provider string          `json:"provider"`
+	Source   string          `json:"source"` // "api" or "builtin"
+	Error    string          `json:"error,omitempty"`
+	Models   []llm.ModelInfo `json:"models"`
+}
+
+func runModels(cmd *cobra.Command, args []string) {
+	configPath := modelsConfigPath
+	if configPath == "" {
+		configPath = constants.DefaultConfigPath
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	network, err := egress.New(egress.Config{
+		Proxy:      cfg.Network.Proxy,
+		NoProxy:    cfg.Network.NoProxy,
+		CAFile:     cfg.Network.CAFile,
+		DNSServers: cfg.Network.DNSServers,
+		Hosts:      cfg.Network.Hosts,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to apply network settings: %v\n", err)
+		os.Exit(1)
+	}
+
+	var results []providerModels
+	if cfg.LLM.ZAI.APIKey != "" {
+		provider := llm.NewZAIProvider(llm.ZAIConfig{
+			APIKey:    cfg.LLM.ZAI.APIKey,
+			Transport: network.Transport(modelsTimeout),
+		}, nil)
+		results = append(results, listProviderModels(cmd.Context(), "zai", provider))
+	}
+	if cfg.LLM.OpenAI.APIKey != "" {
+		// There is no OpenAI provider client yet: only the built-in list is known
+		results = append(results, listProviderModels(cmd.Context(), "openai", nil))
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(os.Stderr, "❌ No LLM provider has an API key in the configuration")
+		os.Exit(1)
+	}
+
+	if modelsJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	for i, result := range results {
+		if i > 0 {
+			fmt.Println()
+		}
+		printProviderModels(result, cfg.Agent.Provider, cfg.Agent.Model)
+	}
+}
+
+// listProviderModels lists the models of a provider, falling back to the
+// built-in list when the provider cannot list them.
+func listProviderModels(ctx context.Context, name string, provider llm.Provider) providerModels {
+	result := providerModels{Provider: name, Source: "api"}
+	if provider != nil {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(ctx, modelsTimeout)
+		defer cancel()
+
+		models, err := llm.ListModels(ctx, provider)
+		if err == nil {
+			result.Models = models
+			return result
+		}
+		result.Error = err.Error()
+	} else {
+		result.Error = llm.ErrModelListingUnsupported.Error()
+	}
+	result.Source = "builtin"
+	result.Models = llm.KnownModels(name)
+	return result
+}
+
+// printProviderModels prints the models of a provider as a table.
+func printProviderModels(result providerModels, agentProvider, agentModel string) {
+	header := result.Provider
+	if result.Provider == agentProvider {
+		header += " (agent.provider)"
+	}
+	fmt.Println(header)
+	if result.Error != "" {
+		fmt.Printf("⚠️  Listing failed (%s); showing the built-in list\n", result.Error)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  MODEL\tTOOLS\tVISION\tCONTEXT")
+	for _, model := range result.Models {
+		mark := " "
+		if result.Provider == agentProvider && model.ID == agentModel {
+			mark = "*"
+		}
+		tools, vision, window := "?", "?", "?"
+		if model.Known {
+			tools, vision = capability(model.Capabilities.Tools), capability(model.Capabilities.Vision)
+			if model.Capabilities.MaxContext > 0 {
+				window = strconv.Itoa(model.Capabilities.MaxContext)
+			}
+		}
+		fmt.Fprintf(w, "%s %s\t%s\t%s\t%s\n", mark, model.ID, tools, vision, window)
+	}
+	w.Flush()
+}
+
+// capability formats a capability flag.
+func capability(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
+
+func init() {
+	rootCmd.AddCommand(modelsCmd)
+
+	modelsCmd.Flags().StringVarP(&modelsConfigPath, "config", "c", "", "Path to configuration file (default: ~/.config/nexbot/config.toml)")
+	modelsCmd.Flags().BoolVar(&modelsJSON, "json", false, "Print the models as JSON")
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aatumaykin/nexbot"
+	agentcontext "github.com/aatumaykin/nexbot/internal/agent/context"
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/workspace"
+)
+
+var (
+	promptConfigPath string
+	promptSessionID  string
+)
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Inspect the system prompt",
+}
+
+var promptRenderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Print the assembled system prompt",
+	Long: `Assemble the system prompt exactly as the agent does: bootstrap files
+with per-channel overrides (AGENTS.telegram.md), expanded includes
+({{include:prompts/style.md}}) and template variables (agent.prompt.variables,
+{{CHANNEL}}, {{CHAT_ID}}, ...), and print it to stdout.
+
+Example usage:
+  nexbot prompt render
+  nexbot prompt render --session telegram:123456789`,
+	Args: cobra.NoArgs,
+	Run:  runPromptRender,
+}
+
+func runPromptRender(cmd *cobra.Command, args []string) {
+	configPath := promptConfigPath
+	if configPath == "" {
+		configPath = constants.DefaultConfigPath
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	builder, err := agentcontext.NewBuilder(agentcontext.Config{
+		Workspace: workspace.New(cfg.Workspace).Path(),
+		Timezone:  cfg.Cron.Timezone,
+		Variables: cfg.Agent.Prompt.Variables,
+		Defaults:  nexbot.Prompts(),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	var prompt string
+	if promptSessionID != "" {
+		prompt, err = builder.BuildForSession(promptSessionID, nil)
+	} else {
+		prompt, err = builder.Build()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to build system prompt: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Print(prompt)
+}
+
+func init() {
+	rootCmd.AddCommand(promptCmd)
+	promptCmd.AddCommand(promptRenderCmd)
+
+	promptCmd.PersistentFlags().StringVarP(&promptConfigPath, "config", "c", "", "Path to configuration file (default: ~/.config/nexbot/config.toml)")
+	promptRenderCmd.Flags().StringVarP(&promptSessionID, "session", "s", "", "Session ID to render the prompt for (channel:chat_id), enables channel overrides and session variables")
+}
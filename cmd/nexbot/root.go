@@ -1,9 +1,16 @@
 package main
 
 import (
+	"os"
+
 	"github.com/spf13/cobra"
+
+	"github.com/aatumaykin/nexbot/internal/config"
 )
 
+// configProfile is the configuration profile selected with --profile
+var configProfile string
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "nexbot",
@@ -11,9 +18,17 @@ var rootCmd = &cobra.Command{
 	Long: `Nexbot is a self-hosted AI agent with message bus architecture,
 extensible channels and skills. It's designed to be simple and lightweight.`,
 	Version: Version,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// config.Load reads the profile from the environment
+		if configProfile != "" {
+			os.Setenv(config.ProfileEnv, configProfile)
+		}
+	},
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&configProfile, "profile", "", "Configuration profile to apply ([profiles.<name>], default: $NEXBOT_PROFILE)")
+
 	// Add subcommands
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(configCmd)
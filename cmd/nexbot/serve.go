@@ -82,6 +82,9 @@ func serveHandler(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 	logger.SetDefault(log)
+	if cfg.Profile() != "" {
+		log.Info("Configuration profile applied", logger.Field{Key: "profile", Value: cfg.Profile()})
+	}
 
 	// Create and run app
 	application := app.New(cfg, log)
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/agent/transcript"
+	"github.com/aatumaykin/nexbot/internal/capture"
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/messages"
+)
+
+var (
+	sessionConfigPath   string
+	sessionShowFormat   string
+	sessionShowSystem   bool
+	sessionCapturesJSON bool
+)
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Inspect conversation sessions",
+}
+
+var sessionListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List sessions with titles, last activity and message counts",
+	Long: `List conversation sessions, most recently active first.
+Titles are generated automatically after a few turns (agent.title_after_turns).
+
+Example usage:
+  nexbot session list`,
+	Args: cobra.NoArgs,
+	Run:  runSessionList,
+}
+
+var sessionShowCmd = &cobra.Command{
+	Use:   "show <session-id>",
+	Short: "Print a session transcript (markdown or html)",
+	Long: `Render the session history, including tool calls and tool results,
+as a readable transcript and print it to stdout.
+
+Example usage:
+  nexbot session show telegram:123456789
+  nexbot session show telegram:123456789 --format html > transcript.html`,
+	Args: cobra.ExactArgs(1),
+	Run:  runSessionShow,
+}
+
+var sessionCapturesCmd = &cobra.Command{
+	Use:   "captures <session-id>",
+	Short: "Print diagnostics of malformed LLM responses of a session",
+	Long: `Print the diagnostics captured for a session (agent.capture): finish
+reason, raw tool calls with the problems found in them and token log
+probabilities where the provider returns them.
+
+Example usage:
+  nexbot session captures telegram:123456789
+  nexbot session captures telegram:123456789 --json`,
+	Args: cobra.ExactArgs(1),
+	Run:  runSessionCaptures,
+}
+
+func runSessionCaptures(cmd *cobra.Command, args []string) {
+	cfg, err := loadSessionConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	entries, err := capture.NewStore(cfg.Workspace.Path, capture.Config{}).Entries(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	if sessionCapturesJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entries); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No captured responses")
+		return
+	}
+	for i, entry := range entries {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s  iteration %d  %s  finish=%s  tokens=%d/%d\n",
+			entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Iteration, entry.Model,
+			entry.FinishReason, entry.Usage.PromptTokens, entry.Usage.CompletionTokens)
+		for _, problem := range entry.Problems {
+			fmt.Printf("  ⚠️  %s\n", problem)
+		}
+		for _, call := range entry.ToolCalls {
+			mark := "✓"
+			if call.Problem != "" {
+				mark = "✗ " + call.Problem
+			}
+			fmt.Printf("  %s(%s) %s\n", call.Name, call.Arguments, mark)
+		}
+		if len(entry.Logprobs) > 0 {
+			fmt.Printf("  logprobs: %d tokens\n", len(entry.Logprobs))
+		}
+	}
+}
+
+func runSessionShow(cmd *cobra.Command, args []string) {
+	sessionID := args[0]
+
+	format, err := transcript.ParseFormat(sessionShowFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	sessionMgr, err := openSessionManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	sess, err := sessionMgr.Get(sessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Session not found: %s\n", sessionID)
+		os.Exit(1)
+	}
+
+	entries, err := sess.ReadEntries()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to read session: %v\n", err)
+		os.Exit(1)
+	}
+
+	content, err := transcript.Render(sessionID, entries, format, transcript.Options{
+		IncludeSystem: sessionShowSystem,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Print(content)
+}
+
+func runSessionList(cmd *cobra.Command, args []string) {
+	sessionMgr, err := openSessionManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	sessions, err := sessionMgr.List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(sessions) == 0 {
+		fmt.Println("No sessions found")
+		return
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SESSION\tTITLE\tMESSAGES\tLAST ACTIVITY")
+	for _, s := range sessions {
+		title := s.Title
+		if title == "" {
+			title = "-"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", s.ID, title, s.MessageCount, messages.FormatLastActivity(s.LastActivity, now))
+	}
+	_ = w.Flush()
+}
+
+// openSessionManager loads the configuration and opens the sessions directory.
+func openSessionManager() (*session.Manager, error) {
+	cfg, err := loadSessionConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return session.NewManager(filepath.Join(cfg.Workspace.Path, "sessions"))
+}
+
+// loadSessionConfig loads the configuration of the session commands.
+func loadSessionConfig() (*config.Config, error) {
+	configPath := sessionConfigPath
+	if configPath == "" {
+		configPath = constants.DefaultConfigPath
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
+}
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+	sessionCmd.AddCommand(sessionListCmd)
+	sessionCmd.AddCommand(sessionShowCmd)
+	sessionCmd.AddCommand(sessionCapturesCmd)
+
+	sessionCmd.PersistentFlags().StringVarP(&sessionConfigPath, "config", "c", "", "Path to configuration file (default: ~/.config/nexbot/config.toml)")
+	sessionShowCmd.Flags().StringVarP(&sessionShowFormat, "format", "f", "markdown", "Output format (markdown, html)")
+	sessionShowCmd.Flags().BoolVar(&sessionShowSystem, "system", false, "Include system messages")
+	sessionCapturesCmd.Flags().BoolVar(&sessionCapturesJSON, "json", false, "Print the diagnostics as JSON")
+}
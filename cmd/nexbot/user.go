@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/app"
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/privacy"
+	"github.com/aatumaykin/nexbot/internal/users"
+)
+
+var (
+	userConfigPath  string
+	userPurgeExport string
+	userPurgeYes    bool
+)
+
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Manage user data",
+}
+
+var userPurgeCmd = &cobra.Command{
+	Use:   "purge <user-id | channel:address>",
+	Short: "Delete all data stored about a user (optionally exporting it first)",
+	Long: `Delete the sessions, session metadata, artifacts, exported transcripts,
+feedback and registry entry of a user, and record a tombstone of the deletion
+in <workspace>/privacy/tombstones.jsonl.
+
+The user is a user ID from the registry or a "channel:address" identity.
+Lists the data and asks to type the user ID to confirm, unless --yes is set.
+Stop the bot first: a running bot keeps the user registry in memory.
+
+Example usage:
+  nexbot user purge alice
+  nexbot user purge telegram:123456789 --export alice-data.zip`,
+	Args: cobra.ExactArgs(1),
+	Run:  runUserPurge,
+}
+
+func runUserPurge(cmd *cobra.Command, args []string) {
+	ref := args[0]
+
+	service, err := openPrivacyService()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	subj, err := service.Resolve(ref)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+	inv, err := service.Inventory(subj)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+	if inv.Empty() {
+		fmt.Printf("✅ No data stored about %s\n", subj.UserID)
+		return
+	}
+
+	fmt.Printf("Data stored about %s:\n", subj.UserID)
+	if subj.User != nil {
+		fmt.Printf("  registry entry with %d identities\n", len(subj.User.Identities))
+	}
+	for _, path := range inv.Files {
+		fmt.Printf("  %s\n", path)
+	}
+	if inv.Feedback > 0 {
+		fmt.Printf("  %d feedback entries\n", inv.Feedback)
+	}
+
+	if !userPurgeYes {
+		in := bufio.NewReader(os.Stdin)
+		if prompt(in, fmt.Sprintf("\nType %q to delete this data", subj.UserID), "") != subj.UserID {
+			fmt.Println("Cancelled, nothing was deleted")
+			return
+		}
+	}
+
+	exported := false
+	if userPurgeExport != "" {
+		if err := exportUserData(service, subj, userPurgeExport); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Export failed, nothing was deleted: %v\n", err)
+			os.Exit(1)
+		}
+		exported = true
+		fmt.Printf("📦 Exported to %s\n", userPurgeExport)
+	}
+
+	ts, err := service.Erase(subj, privacy.RequestedByAdmin, exported)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Purge failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Deleted %d files, %d sessions and %d feedback entries (tombstone %s)\n",
+		ts.Files, ts.Sessions, ts.Feedback, ts.Subject[:12])
+	if subj.User != nil && subj.User.Static {
+		fmt.Printf("⚠️  %s is defined in [[users]] of the config; remove it there too\n", subj.UserID)
+	}
+}
+
+// exportUserData writes the export archive of a subject to path.
+func exportUserData(service *privacy.Service, subj privacy.Subject, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err := service.Export(subj, f); err != nil {
+		_ = f.Close()
+		_ = os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// openPrivacyService loads the configuration, the user registry and the
+// sessions directory.
+func openPrivacyService() (*privacy.Service, error) {
+	configPath := userConfigPath
+	if configPath == "" {
+		configPath = constants.DefaultConfigPath
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	staticUsers, err := app.StaticUsers(cfg.Users)
+	if err != nil {
+		return nil, err
+	}
+	registry := users.NewRegistry(cfg.Workspace.Path)
+	if err := registry.Load(staticUsers); err != nil {
+		return nil, err
+	}
+
+	sessions, err := session.NewManager(filepath.Join(cfg.Workspace.Path, "sessions"))
+	if err != nil {
+		return nil, err
+	}
+	return privacy.New(cfg.Workspace.Path, sessions, registry), nil
+}
+
+func init() {
+	rootCmd.AddCommand(userCmd)
+	userCmd.AddCommand(userPurgeCmd)
+
+	userCmd.PersistentFlags().StringVarP(&userConfigPath, "config", "c", "", "Path to configuration file (default: ~/.config/nexbot/config.toml)")
+	userPurgeCmd.Flags().StringVar(&userPurgeExport, "export", "", "Export the data to this zip file before deleting it")
+	userPurgeCmd.Flags().BoolVarP(&userPurgeYes, "yes", "y", false, "Delete without asking for confirmation")
+}
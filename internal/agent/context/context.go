@@ -2,12 +2,17 @@ package context
 
 import (
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/projects"
+	"github.com/aatumaykin/nexbot/internal/users"
 	"github.com/aatumaykin/nexbot/internal/workspace"
 )
 
@@ -20,12 +25,30 @@ type Context struct {
 type Builder struct {
 	workspace string
 	timezone  string
+	variables map[string]string
+	defaults  fs.FS
+	profiles  ProfileSource
+	projects  ProjectSource
+}
+
+// ProfileSource looks up the profile of the user of a session
+// (implemented by users.Registry).
+type ProfileSource interface {
+	FindBySession(sessionID string) (users.User, bool)
+}
+
+// ProjectSource looks up the project a session is attached to
+// (implemented by projects.Store).
+type ProjectSource interface {
+	ForSession(sessionID string) (projects.Project, bool)
 }
 
 // Config holds configuration for the context builder.
 type Config struct {
-	Workspace string // Workspace directory path
-	Timezone  string // User timezone (e.g., "Europe/Moscow")
+	Workspace string            // Workspace directory path
+	Timezone  string            // User timezone (e.g., "Europe/Moscow")
+	Variables map[string]string // Custom template variables (e.g., USER_NAME)
+	Defaults  fs.FS             // Default files used when missing in the workspace (bundle builds)
 }
 
 // NewBuilder creates a new context builder.
@@ -42,65 +65,60 @@ func NewBuilder(config Config) (*Builder, error) {
 	return &Builder{
 		workspace: config.Workspace,
 		timezone:  config.Timezone,
+		variables: config.Variables,
+		defaults:  config.Defaults,
 	}, nil
 }
 
+// SetProfiles enables user profiles: prompts built for a session get a
+// profile section and use the user's timezone. Must be called before the
+// builder is used.
+func (b *Builder) SetProfiles(profiles ProfileSource) {
+	b.profiles = profiles
+}
+
+// SetProjects enables projects: prompts built for a session attached to a
+// project get the project brief. Must be called before the builder is used.
+func (b *Builder) SetProjects(source ProjectSource) {
+	b.projects = source
+}
+
+// components are the bootstrap files of the system prompt in priority order
+var components = []string{
+	workspace.BootstrapAgents,   // Agent instructions and behavior
+	workspace.BootstrapIdentity, // Core identity and purpose
+	workspace.BootstrapUser,     // User profile and preferences
+	workspace.BootstrapTools,    // Available tools and operations
+}
+
 // Build creates a system prompt by combining context components in priority order:
 // AGENTS → IDENTITY → USER → TOOLS → HEARTBEAT → memory
 func (b *Builder) Build() (string, error) {
+	return b.build(promptSession{})
+}
+
+// build creates the system prompt for a session. Components are read with
+// the per-channel overrides of the session, includes are expanded and
+// template variables are replaced.
+func (b *Builder) build(s promptSession) (string, error) {
 	var builder strings.Builder
 
-	// 1. AGENTS - Agent instructions and behavior
-	agents, err := b.readFile(workspace.BootstrapAgents)
-	if err != nil && !os.IsNotExist(err) {
-		return "", fmt.Errorf("failed to read AGENTS.md: %w", err)
-	}
-	if agents != "" {
-		processed, err := b.processTemplates(agents)
-		if err != nil {
-			return "", fmt.Errorf("failed to process AGENTS.md templates: %w", err)
+	for _, name := range components {
+		content, err := b.readComponent(name, s.channel)
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read %s: %w", name, err)
 		}
-		builder.WriteString(processed)
-		builder.WriteString("\n\n---\n\n")
-	}
-
-	// 2. IDENTITY - Core identity and purpose
-	identity, err := b.readFile(workspace.BootstrapIdentity)
-	if err != nil && !os.IsNotExist(err) {
-		return "", fmt.Errorf("failed to read IDENTITY.md: %w", err)
-	}
-	if identity != "" {
-		processed, err := b.processTemplates(identity)
-		if err != nil {
-			return "", fmt.Errorf("failed to process IDENTITY.md templates: %w", err)
+		if content == "" {
+			continue
 		}
-		builder.WriteString(processed)
-		builder.WriteString("\n\n---\n\n")
-	}
 
-	// 3. USER - User profile and preferences
-	user, err := b.readFile(workspace.BootstrapUser)
-	if err != nil && !os.IsNotExist(err) {
-		return "", fmt.Errorf("failed to read USER.md: %w", err)
-	}
-	if user != "" {
-		processed, err := b.processTemplates(user)
+		processed, err := b.expandIncludes(content, nil)
 		if err != nil {
-			return "", fmt.Errorf("failed to process USER.md templates: %w", err)
+			return "", fmt.Errorf("failed to process %s includes: %w", name, err)
 		}
-		builder.WriteString(processed)
-		builder.WriteString("\n\n---\n\n")
-	}
-
-	// 4. TOOLS - Available tools and operations
-	tools, err := b.readFile(workspace.BootstrapTools)
-	if err != nil && !os.IsNotExist(err) {
-		return "", fmt.Errorf("failed to read TOOLS.md: %w", err)
-	}
-	if tools != "" {
-		processed, err := b.processTemplates(tools)
+		processed, err = b.processTemplates(processed, s)
 		if err != nil {
-			return "", fmt.Errorf("failed to process TOOLS.md templates: %w", err)
+			return "", fmt.Errorf("failed to process %s templates: %w", name, err)
 		}
 		builder.WriteString(processed)
 		builder.WriteString("\n\n---\n\n")
@@ -112,7 +130,13 @@ func (b *Builder) Build() (string, error) {
 // BuildWithMemory creates a system prompt with memory context appended.
 // This includes all components from Build() plus memory messages.
 func (b *Builder) BuildWithMemory(messages []llm.Message) (string, error) {
-	systemPrompt, err := b.Build()
+	return b.buildWithMemory(promptSession{}, messages)
+}
+
+// buildWithMemory creates the system prompt for a session with memory
+// context appended.
+func (b *Builder) buildWithMemory(s promptSession, messages []llm.Message) (string, error) {
+	systemPrompt, err := b.build(s)
 	if err != nil {
 		return "", err
 	}
@@ -144,33 +168,85 @@ func (b *Builder) BuildWithMemory(messages []llm.Message) (string, error) {
 }
 
 // BuildForSession creates a system prompt optimized for a specific session.
-// This can be customized per session based on session-specific context.
+// Components use the overrides of the session's channel and templates get
+// the session variables ({{CHANNEL}}, {{CHAT_ID}}, {{SESSION_ID}}).
+// If the session belongs to a known user, their profile is added; if it is
+// attached to a project, the project brief is added.
 func (b *Builder) BuildForSession(sessionID string, messages []llm.Message) (string, error) {
-	systemPrompt, err := b.BuildWithMemory(messages)
+	s := parseSession(sessionID)
+	if b.profiles != nil {
+		if u, ok := b.profiles.FindBySession(sessionID); ok {
+			s.user = &u
+		}
+	}
+	systemPrompt, err := b.buildWithMemory(s, messages)
 	if err != nil {
 		return "", err
 	}
 
-	// Parse sessionID to extract channel and chat_id
 	var sessionInfo string
-	if strings.Contains(sessionID, ":") {
-		parts := strings.SplitN(sessionID, ":", 2)
-		if len(parts) == 2 {
-			channel := parts[0]
-			chatID := parts[1]
-			sessionInfo = fmt.Sprintf("# Session Information\n\n- **Session ID:** %s\n- **Channel:** %s\n- **Chat ID:** %s\n\n", sessionID, channel, chatID)
-		} else {
-			sessionInfo = fmt.Sprintf("# Session: %s\n\n", sessionID)
-		}
+	if s.channel != "" {
+		sessionInfo = fmt.Sprintf("# Session Information\n\n- **Session ID:** %s\n- **Channel:** %s\n- **Chat ID:** %s\n\n", sessionID, s.channel, s.chatID)
 	} else {
 		sessionInfo = fmt.Sprintf("# Session: %s\n\n", sessionID)
 	}
+	if s.user != nil {
+		sessionInfo += userProfile(*s.user)
+	}
+	if b.projects != nil {
+		if p, ok := b.projects.ForSession(sessionID); ok {
+			sessionInfo += projectBrief(p)
+		}
+	}
 
-	// DEBUG: Log what's in the system prompt
-	// We'll split it into parts for readability
-	systemPromptWithSession := sessionInfo + systemPrompt
+	return sessionInfo + systemPrompt, nil
+}
+
+// userProfile formats the profile section of a user.
+func userProfile(u users.User) string {
+	var sb strings.Builder
+	sb.WriteString("# User Profile\n\n")
+	sb.WriteString(fmt.Sprintf("- **User ID:** %s\n", u.ID))
+	if u.Name != "" {
+		sb.WriteString(fmt.Sprintf("- **Name:** %s\n", u.Name))
+	}
+	if u.Language != "" {
+		sb.WriteString(fmt.Sprintf("- **Language:** %s (reply in this language)\n", u.Language))
+	}
+	if u.Timezone != "" {
+		sb.WriteString(fmt.Sprintf("- **Timezone:** %s\n", u.Timezone))
+	}
+	if u.Role != "" {
+		sb.WriteString(fmt.Sprintf("- **Role:** %s\n", u.Role))
+	}
+	if len(u.Identities) > 0 {
+		ids := make([]string, len(u.Identities))
+		for i, id := range u.Identities {
+			ids[i] = id.String()
+		}
+		sb.WriteString(fmt.Sprintf("- **Channels:** %s\n", strings.Join(ids, ", ")))
+	}
+	if len(u.Preferences) > 0 {
+		sb.WriteString("- **Preferences:**\n")
+		for _, key := range slices.Sorted(maps.Keys(u.Preferences)) {
+			sb.WriteString(fmt.Sprintf("  - %s: %s\n", key, u.Preferences[key]))
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
 
-	return systemPromptWithSession, nil
+// projectBrief formats the project section of a session.
+func projectBrief(p projects.Project) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Project: %s\n\n", p.Name))
+	sb.WriteString("This conversation is part of the project. The brief below summarizes earlier conversations on it; use it as context and don't ask again for what it already says.\n\n")
+	if brief := strings.TrimSpace(p.Brief); brief != "" {
+		sb.WriteString(brief + "\n\n")
+	} else {
+		sb.WriteString("(No brief yet: this is the first conversation on the project.)\n\n")
+	}
+	return sb.String()
 }
 
 // ReadMemory reads memory files from the workspace memory directory.
@@ -217,20 +293,33 @@ func (b *Builder) ReadMemory() ([]llm.Message, error) {
 }
 
 // processTemplates replaces template variables with actual values.
-func (b *Builder) processTemplates(content string) (string, error) {
+// Custom variables can't override the built-in ones.
+func (b *Builder) processTemplates(content string, s promptSession) (string, error) {
 	now := time.Now()
 
 	timezone := b.timezone
+	if s.user != nil {
+		if loc := s.user.Location(); loc != nil {
+			timezone = s.user.Timezone
+			now = now.In(loc)
+		}
+	}
 	if timezone == "" {
 		timezone = "UTC"
 	}
 
-	data := map[string]string{
-		"CURRENT_TIME":   now.Format("15:04:05"),
-		"CURRENT_DATE":   now.Format("2006-01-02"),
-		"WORKSPACE_PATH": b.workspace,
-		"TIMEZONE":       timezone,
+	data := make(map[string]string, len(b.variables)+7)
+	for key, value := range b.variables {
+		data[key] = value
 	}
+	data["CURRENT_TIME"] = now.Format("15:04:05")
+	data["CURRENT_DATE"] = now.Format("2006-01-02")
+	data["CURRENT_WEEKDAY"] = now.Weekday().String()
+	data["WORKSPACE_PATH"] = b.workspace
+	data["TIMEZONE"] = timezone
+	data["CHANNEL"] = s.channel
+	data["CHAT_ID"] = s.chatID
+	data["SESSION_ID"] = s.id
 
 	result := content
 	for key, value := range data {
@@ -246,6 +335,12 @@ func (b *Builder) readFile(filename string) (string, error) {
 	filePath := filepath.Join(b.workspace, filename)
 
 	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) && b.defaults != nil {
+		// Workspace files override the defaults
+		if defaultData, defaultErr := fs.ReadFile(b.defaults, filepath.ToSlash(filename)); defaultErr == nil {
+			return string(defaultData), nil
+		}
+	}
 	if err != nil {
 		return "", err
 	}
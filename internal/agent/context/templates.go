@@ -0,0 +1,100 @@
+package context
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/users"
+)
+
+// maxIncludeDepth limits nested includes
+const maxIncludeDepth = 5
+
+// includePattern matches file includes: {{include:prompts/style.md}}
+var includePattern = regexp.MustCompile(`\{\{include:\s*([^}]+?)\s*\}\}`)
+
+// promptSession describes the session a system prompt is built for.
+type promptSession struct {
+	id      string
+	channel string
+	chatID  string
+	user    *users.User // Profile of the session's user, if known
+}
+
+// parseSession splits a session ID of the form channel:chat_id.
+func parseSession(sessionID string) promptSession {
+	s := promptSession{id: sessionID}
+	if channel, chatID, ok := strings.Cut(sessionID, ":"); ok {
+		s.channel, s.chatID = channel, chatID
+	}
+	return s
+}
+
+// readComponent reads a bootstrap file, preferring the override of the
+// channel (AGENTS.telegram.md for AGENTS.md) when it exists.
+func (b *Builder) readComponent(name, channel string) (string, error) {
+	if channel != "" {
+		override := strings.TrimSuffix(name, ".md") + "." + channel + ".md"
+		content, err := b.readFile(override)
+		if err == nil || !os.IsNotExist(err) {
+			return content, err
+		}
+	}
+	return b.readFile(name)
+}
+
+// expandIncludes replaces {{include:path}} with the content of the file at
+// path, relative to the workspace. Included files may include other files;
+// stack holds the files being expanded to detect cycles.
+func (b *Builder) expandIncludes(content string, stack []string) (string, error) {
+	var expandErr error
+	result := includePattern.ReplaceAllStringFunc(content, func(match string) string {
+		if expandErr != nil {
+			return match
+		}
+		path := includePattern.FindStringSubmatch(match)[1]
+
+		included, err := b.readInclude(path, stack)
+		if err != nil {
+			expandErr = err
+			return match
+		}
+		return strings.TrimRight(included, "\n")
+	})
+	return result, expandErr
+}
+
+// RenderFile renders a workspace file for a session like a bootstrap file:
+// includes are expanded and template variables replaced.
+func (b *Builder) RenderFile(path, sessionID string) (string, error) {
+	content, err := b.readInclude(path, nil)
+	if err != nil {
+		return "", err
+	}
+	return b.processTemplates(content, parseSession(sessionID))
+}
+
+// readInclude reads an included file and expands its own includes.
+func (b *Builder) readInclude(path string, stack []string) (string, error) {
+	rel := filepath.Clean(path)
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("include %q is outside the workspace", path)
+	}
+	for _, parent := range stack {
+		if parent == rel {
+			return "", fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), rel)
+		}
+	}
+	if len(stack) >= maxIncludeDepth {
+		return "", fmt.Errorf("include %q is nested deeper than %d levels", path, maxIncludeDepth)
+	}
+
+	content, err := b.readFile(rel)
+	if err != nil {
+		return "", fmt.Errorf("failed to read include %q: %w", path, err)
+	}
+	return b.expandIncludes(content, append(stack, rel))
+}
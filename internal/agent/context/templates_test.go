@@ -0,0 +1,244 @@
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/aatumaykin/nexbot/internal/projects"
+	"github.com/aatumaykin/nexbot/internal/users"
+	"github.com/aatumaykin/nexbot/internal/workspace"
+)
+
+// writeWorkspaceFile creates a file in the workspace, with parent directories
+func writeWorkspaceFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory for %s: %v", name, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create %s: %v", name, err)
+	}
+}
+
+// TestBuildForSession_VariablesAndIncludes tests session variables, custom
+// variables and nested includes
+func TestBuildForSession_VariablesAndIncludes(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeWorkspaceFile(t, tmpDir, workspace.BootstrapUser, "# User\nName: {{USER_NAME}}\n{{include:prompts/style.md}}\n")
+	writeWorkspaceFile(t, tmpDir, "prompts/style.md", "Channel: {{CHANNEL}}, chat {{CHAT_ID}}\n{{include: prompts/signature.md }}\n")
+	writeWorkspaceFile(t, tmpDir, "prompts/signature.md", "Sign as {{USER_NAME}}'s assistant\n")
+
+	builder, err := NewBuilder(Config{
+		Workspace: tmpDir,
+		Variables: map[string]string{"USER_NAME": "Alex", "CHANNEL": "overridden"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create builder: %v", err)
+	}
+
+	result, err := builder.BuildForSession("telegram:42", nil)
+	if err != nil {
+		t.Fatalf("BuildForSession() error: %v", err)
+	}
+
+	for _, want := range []string{"Name: Alex", "Channel: telegram, chat 42", "Sign as Alex's assistant"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("BuildForSession() should contain %q, got:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "{{") {
+		t.Errorf("BuildForSession() left unexpanded templates:\n%s", result)
+	}
+}
+
+// TestBuildForSession_ChannelOverride tests per-channel component overrides
+func TestBuildForSession_ChannelOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeWorkspaceFile(t, tmpDir, workspace.BootstrapAgents, "Default agent instructions\n")
+	writeWorkspaceFile(t, tmpDir, "AGENTS.telegram.md", "Telegram agent instructions\n")
+
+	builder, err := NewBuilder(Config{Workspace: tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create builder: %v", err)
+	}
+
+	telegram, err := builder.BuildForSession("telegram:42", nil)
+	if err != nil {
+		t.Fatalf("BuildForSession() error: %v", err)
+	}
+	if !strings.Contains(telegram, "Telegram agent instructions") || strings.Contains(telegram, "Default agent instructions") {
+		t.Errorf("telegram session should use AGENTS.telegram.md, got:\n%s", telegram)
+	}
+
+	cli, err := builder.BuildForSession("cli:local", nil)
+	if err != nil {
+		t.Fatalf("BuildForSession() error: %v", err)
+	}
+	if !strings.Contains(cli, "Default agent instructions") {
+		t.Errorf("cli session should use AGENTS.md, got:\n%s", cli)
+	}
+}
+
+// staticProfiles maps session IDs to user profiles
+type staticProfiles map[string]users.User
+
+func (p staticProfiles) FindBySession(sessionID string) (users.User, bool) {
+	u, ok := p[sessionID]
+	return u, ok
+}
+
+// TestBuildForSession_UserProfile tests the profile section and the user's timezone
+func TestBuildForSession_UserProfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeWorkspaceFile(t, tmpDir, workspace.BootstrapUser, "Timezone: {{TIMEZONE}}\n")
+
+	builder, err := NewBuilder(Config{Workspace: tmpDir, Timezone: "UTC"})
+	if err != nil {
+		t.Fatalf("Failed to create builder: %v", err)
+	}
+	builder.SetProfiles(staticProfiles{"telegram:42": {
+		ID:          "alice",
+		Name:        "Alice",
+		Language:    "de",
+		Timezone:    "Europe/Berlin",
+		Identities:  []users.Identity{{Channel: "telegram", Address: "42"}},
+		Preferences: map[string]string{"units": "metric"},
+	}})
+
+	result, err := builder.BuildForSession("telegram:42", nil)
+	if err != nil {
+		t.Fatalf("BuildForSession() error: %v", err)
+	}
+	for _, want := range []string{"# User Profile", "**Name:** Alice", "**Language:** de", "units: metric", "Timezone: Europe/Berlin"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("BuildForSession() should contain %q, got:\n%s", want, result)
+		}
+	}
+
+	other, err := builder.BuildForSession("telegram:7", nil)
+	if err != nil {
+		t.Fatalf("BuildForSession() error: %v", err)
+	}
+	if strings.Contains(other, "# User Profile") || !strings.Contains(other, "Timezone: UTC") {
+		t.Errorf("unknown user should get no profile, got:\n%s", other)
+	}
+}
+
+// TestBuildForSession_ProjectBrief tests the project section of attached sessions
+func TestBuildForSession_ProjectBrief(t *testing.T) {
+	builder, err := NewBuilder(Config{Workspace: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create builder: %v", err)
+	}
+	store := projects.NewStore(t.TempDir())
+	if _, _, err := store.Attach("telegram:42", "homelab"); err != nil {
+		t.Fatalf("Attach() error: %v", err)
+	}
+	builder.SetProjects(store)
+
+	result, err := builder.BuildForSession("telegram:42", nil)
+	if err != nil {
+		t.Fatalf("BuildForSession() error: %v", err)
+	}
+	if !strings.Contains(result, "# Project: homelab") || !strings.Contains(result, "No brief yet") {
+		t.Errorf("BuildForSession() should contain the project section, got:\n%s", result)
+	}
+
+	if err := store.SetBrief("homelab", "- nginx runs on 10.0.0.2"); err != nil {
+		t.Fatalf("SetBrief() error: %v", err)
+	}
+	result, _ = builder.BuildForSession("telegram:42", nil)
+	if !strings.Contains(result, "- nginx runs on 10.0.0.2") {
+		t.Errorf("BuildForSession() should contain the brief, got:\n%s", result)
+	}
+
+	other, _ := builder.BuildForSession("telegram:7", nil)
+	if strings.Contains(other, "# Project") {
+		t.Errorf("session without a project should get no project section, got:\n%s", other)
+	}
+}
+
+// TestBuild_InvalidIncludes tests includes outside the workspace, missing
+// files and cycles
+func TestBuild_InvalidIncludes(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "outside workspace",
+			files:   map[string]string{workspace.BootstrapUser: "{{include:../secret.md}}"},
+			wantErr: "outside the workspace",
+		},
+		{
+			name:    "missing file",
+			files:   map[string]string{workspace.BootstrapUser: "{{include:prompts/missing.md}}"},
+			wantErr: "failed to read include",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				workspace.BootstrapUser: "{{include:a.md}}",
+				"a.md":                  "{{include:b.md}}",
+				"b.md":                  "{{include:a.md}}",
+			},
+			wantErr: "include cycle: a.md -> b.md -> a.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			for name, content := range tt.files {
+				writeWorkspaceFile(t, tmpDir, name, content)
+			}
+
+			builder, err := NewBuilder(Config{Workspace: tmpDir})
+			if err != nil {
+				t.Fatalf("Failed to create builder: %v", err)
+			}
+
+			_, err = builder.Build()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Build() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestBuild_DefaultFiles tests embedded default files and their workspace
+// overrides
+func TestBuild_DefaultFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeWorkspaceFile(t, tmpDir, workspace.BootstrapUser, "Workspace user profile\n")
+
+	builder, err := NewBuilder(Config{
+		Workspace: tmpDir,
+		Defaults: fstest.MapFS{
+			workspace.BootstrapAgents: {Data: []byte("Default agent instructions\n{{include:prompts/style.md}}\n")},
+			workspace.BootstrapUser:   {Data: []byte("Default user profile\n")},
+			"prompts/style.md":        {Data: []byte("Default style\n")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create builder: %v", err)
+	}
+
+	result, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	for _, want := range []string{"Default agent instructions", "Default style", "Workspace user profile"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Build() should contain %q, got:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "Default user profile") {
+		t.Errorf("workspace files should override the defaults, got:\n%s", result)
+	}
+}
@@ -0,0 +1,328 @@
+// Package debate refines answers by letting agent personas converse: a
+// proposer drafts the answer, critics review it and the proposer revises the
+// draft until every critic approves or the turn or token budget runs out.
+// Debates are started by the /debate command or, when enabled, for messages
+// that look like they need careful reasoning (comparisons, trade-offs, long
+// questions).
+package debate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// Persona roles.
+const (
+	RoleProposer = "proposer"
+	RoleCritic   = "critic"
+)
+
+// Trigger reasons.
+const (
+	ReasonCommand = "command"
+	ReasonKeyword = "keyword"
+	ReasonLength  = "length"
+)
+
+// Stop reasons.
+const (
+	StopAgreed      = "agreed"
+	StopMaxTurns    = "max_turns"
+	StopTokenBudget = "token_budget"
+)
+
+const (
+	// DefaultMaxTurns is the number of persona replies in a debate
+	DefaultMaxTurns = 4
+
+	// DefaultMaxTokens is the token budget of a debate
+	DefaultMaxTokens = 16000
+
+	// DefaultAutoMinChars is the shortest question debated automatically
+	DefaultAutoMinChars = 800
+
+	// ApprovalMarker starts the reply of a critic that approves the draft
+	ApprovalMarker = "APPROVED"
+)
+
+// DefaultKeywords are phrases that start a debate automatically.
+var DefaultKeywords = []string{
+	"pros and cons",
+	"trade-off",
+	"tradeoff",
+	"which is better",
+	"should i",
+	"devil's advocate",
+	"плюсы и минусы",
+	"что лучше",
+	"стоит ли",
+	"взвесь",
+}
+
+// DefaultPersonas are used when no personas are configured.
+var DefaultPersonas = []Persona{
+	{
+		Name:   "Proposer",
+		Role:   RoleProposer,
+		Prompt: "You give well-reasoned, practical answers and state your assumptions.",
+	},
+	{
+		Name:   "Critic",
+		Role:   RoleCritic,
+		Prompt: "You are a skeptical reviewer looking for mistakes, missing alternatives and hidden risks.",
+	},
+}
+
+// ErrNoProposer is returned for personas that do not start with a proposer.
+var ErrNoProposer = errors.New("the first debate persona must be a proposer")
+
+// ErrNoCritic is returned for personas without a critic.
+var ErrNoCritic = errors.New("a debate needs at least one critic")
+
+// Persona is a participant of a debate.
+type Persona struct {
+	Name   string // Shown in the transcript and logs
+	Role   string // RoleProposer or RoleCritic
+	Prompt string // Character of the persona, prepended to the role instructions
+	Model  string // Model of the persona (Config.Model if empty)
+}
+
+// Config configures a Debater.
+type Config struct {
+	Personas     []Persona // Participants in speaking order, starting with a proposer (DefaultPersonas if nil)
+	Model        string    // Model for personas without their own model
+	MaxTurns     int       // Persona replies per debate (DefaultMaxTurns if 0)
+	MaxTokens    int       // Token budget per debate (DefaultMaxTokens if 0)
+	ReplyTokens  int       // Maximum tokens of one reply
+	Temperature  float64
+	Auto         bool     // Debate messages matching the heuristics without /debate
+	AutoMinChars int      // Longer questions are debated automatically (DefaultAutoMinChars if 0, negative disables)
+	Keywords     []string // Phrases that start a debate automatically (DefaultKeywords if nil)
+}
+
+// Debater runs debates between personas.
+type Debater struct {
+	cfg      Config
+	provider llm.Provider
+}
+
+// New creates a Debater.
+func New(provider llm.Provider, cfg Config) (*Debater, error) {
+	if cfg.Personas == nil {
+		cfg.Personas = DefaultPersonas
+	}
+	if len(cfg.Personas) == 0 || cfg.Personas[0].Role != RoleProposer {
+		return nil, ErrNoProposer
+	}
+	if !slices.ContainsFunc(cfg.Personas, func(p Persona) bool { return p.Role == RoleCritic }) {
+		return nil, ErrNoCritic
+	}
+	for _, p := range cfg.Personas {
+		if p.Role != RoleProposer && p.Role != RoleCritic {
+			return nil, fmt.Errorf("invalid role of debate persona %s: %s", p.Name, p.Role)
+		}
+	}
+	if cfg.MaxTurns == 0 {
+		cfg.MaxTurns = DefaultMaxTurns
+	}
+	if cfg.MaxTokens == 0 {
+		cfg.MaxTokens = DefaultMaxTokens
+	}
+	if cfg.AutoMinChars == 0 {
+		cfg.AutoMinChars = DefaultAutoMinChars
+	}
+	if cfg.Keywords == nil {
+		cfg.Keywords = DefaultKeywords
+	}
+	keywords := make([]string, 0, len(cfg.Keywords))
+	for _, keyword := range cfg.Keywords {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	cfg.Keywords = keywords
+	return &Debater{cfg: cfg, provider: provider}, nil
+}
+
+// Trigger returns why a message should be debated automatically, or "" if it
+// should be handled normally. Always "" unless Config.Auto is set.
+func (d *Debater) Trigger(message string) string {
+	if !d.cfg.Auto {
+		return ""
+	}
+	lower := strings.ToLower(message)
+	switch {
+	case slices.ContainsFunc(d.cfg.Keywords, func(keyword string) bool { return strings.Contains(lower, keyword) }):
+		return ReasonKeyword
+	case d.cfg.AutoMinChars > 0 && utf8.RuneCountInString(message) > d.cfg.AutoMinChars && strings.Contains(message, "?"):
+		return ReasonLength
+	}
+	return ""
+}
+
+// Request is a question to debate.
+type Request struct {
+	Question string
+	Context  []llm.Message // Earlier conversation (user and assistant messages)
+	OnTurn   func(turn Turn, number, total int)
+}
+
+// Turn is one reply of a persona.
+type Turn struct {
+	Persona  string
+	Role     string
+	Content  string
+	Tokens   int
+	Approved bool // A critic approved the current draft
+}
+
+// Result is the outcome of a debate.
+type Result struct {
+	Answer string // The last draft of the proposer
+	Turns  []Turn
+	Tokens int
+	Stop   string // StopAgreed, StopMaxTurns or StopTokenBudget
+}
+
+// Run debates the question. The debate ends when every critic approves the
+// latest draft, after MaxTurns replies or when the token budget is spent.
+func (d *Debater) Run(ctx context.Context, req Request) (*Result, error) {
+	result := &Result{Stop: StopMaxTurns}
+	approved := make(map[string]bool)
+
+	for number := 1; number <= d.cfg.MaxTurns; number++ {
+		if d.cfg.MaxTokens > 0 && result.Tokens >= d.cfg.MaxTokens {
+			result.Stop = StopTokenBudget
+			break
+		}
+
+		persona := d.cfg.Personas[(number-1)%len(d.cfg.Personas)]
+		model := persona.Model
+		if model == "" {
+			model = d.cfg.Model
+		}
+		resp, err := d.provider.Chat(ctx, llm.ChatRequest{
+			Messages:    d.messages(persona, req, result),
+			Model:       model,
+			Temperature: d.cfg.Temperature,
+			MaxTokens:   d.cfg.ReplyTokens,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("debate turn of %s failed: %w", persona.Name, err)
+		}
+
+		turn := Turn{
+			Persona: persona.Name,
+			Role:    persona.Role,
+			Content: strings.TrimSpace(resp.Content),
+			Tokens:  resp.Usage.TotalTokens,
+		}
+		if persona.Role == RoleProposer {
+			if turn.Content != "" {
+				result.Answer = turn.Content
+			}
+			clear(approved)
+		} else {
+			turn.Approved = isApproval(turn.Content)
+			approved[persona.Name] = turn.Approved
+		}
+		result.Turns = append(result.Turns, turn)
+		result.Tokens += turn.Tokens
+		if req.OnTurn != nil {
+			req.OnTurn(turn, number, d.cfg.MaxTurns)
+		}
+
+		if d.allApproved(approved) {
+			result.Stop = StopAgreed
+			break
+		}
+	}
+
+	if result.Answer == "" {
+		return nil, errors.New("debate produced no answer")
+	}
+	return result, nil
+}
+
+// allApproved reports whether every critic approved the current draft.
+func (d *Debater) allApproved(approved map[string]bool) bool {
+	for _, p := range d.cfg.Personas {
+		if p.Role == RoleCritic && !approved[p.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+// messages builds the request of a persona's turn.
+func (d *Debater) messages(persona Persona, req Request, result *Result) []llm.Message {
+	messages := []llm.Message{{Role: llm.RoleSystem, Content: systemPrompt(persona)}}
+	messages = append(messages, req.Context...)
+
+	if persona.Role == RoleCritic {
+		return append(messages, llm.Message{
+			Role:    llm.RoleUser,
+			Content: fmt.Sprintf("Question:\n%s\n\nDraft answer:\n%s", req.Question, result.Answer),
+		})
+	}
+
+	messages = append(messages, llm.Message{Role: llm.RoleUser, Content: req.Question})
+	if result.Answer == "" {
+		return messages
+	}
+
+	// Revise the draft with the critiques given since it was written
+	var feedback strings.Builder
+	for _, turn := range result.Turns[lastDraft(result.Turns)+1:] {
+		if turn.Role == RoleCritic && !turn.Approved {
+			fmt.Fprintf(&feedback, "%s:\n%s\n\n", turn.Persona, turn.Content)
+		}
+	}
+	if feedback.Len() == 0 {
+		feedback.WriteString("No objections were raised.\n\n")
+	}
+	return append(messages,
+		llm.Message{Role: llm.RoleAssistant, Content: result.Answer},
+		llm.Message{Role: llm.RoleUser, Content: "Reviewers' feedback on your answer:\n\n" + feedback.String() +
+			"Write the improved answer to the original question. Reply with the answer only."},
+	)
+}
+
+// lastDraft returns the index of the last proposer turn, or -1.
+func lastDraft(turns []Turn) int {
+	for i := len(turns) - 1; i >= 0; i-- {
+		if turns[i].Role == RoleProposer {
+			return i
+		}
+	}
+	return -1
+}
+
+// systemPrompt combines the persona's character with its role instructions.
+func systemPrompt(p Persona) string {
+	var instructions string
+	if p.Role == RoleCritic {
+		instructions = fmt.Sprintf("You are %s, reviewing a draft answer to the user's question. "+
+			"Point out factual errors, gaps, risks and unclear parts, concisely and specifically. "+
+			"If the draft needs no changes, reply with %s only.", p.Name, ApprovalMarker)
+	} else {
+		instructions = fmt.Sprintf("You are %s, writing the answer to the user's question. "+
+			"Reviewers will critique your draft; address their points when revising it. "+
+			"Reply with the answer only, addressed to the user.", p.Name)
+	}
+	if p.Prompt == "" {
+		return instructions
+	}
+	return p.Prompt + "\n\n" + instructions
+}
+
+// isApproval reports whether a critic's reply approves the draft.
+func isApproval(content string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(content)), ApprovalMarker)
+}
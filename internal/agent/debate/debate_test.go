@@ -0,0 +1,194 @@
+package debate
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// scriptedProvider replies with the scripted answers in order and records the requests.
+type scriptedProvider struct {
+	replies  []string
+	tokens   int
+	requests []llm.ChatRequest
+}
+
+func (p *scriptedProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.requests = append(p.requests, req)
+	if len(p.requests) > len(p.replies) {
+		return nil, errors.New("unexpected request")
+	}
+	return &llm.ChatResponse{
+		Content:      p.replies[len(p.requests)-1],
+		FinishReason: llm.FinishReasonStop,
+		Usage:        llm.Usage{TotalTokens: p.tokens},
+	}, nil
+}
+
+func (p *scriptedProvider) SupportsToolCalling() bool { return false }
+
+func TestDebater_Run(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		replies []string
+		tokens  int
+		answer  string
+		turns   int
+		stop    string
+	}{
+		{
+			name:    "critic approves revision",
+			replies: []string{"Use SQLite.", "It does not scale to many writers.", "Use PostgreSQL.", "APPROVED"},
+			answer:  "Use PostgreSQL.",
+			turns:   4,
+			stop:    StopAgreed,
+		},
+		{
+			name:    "turn budget",
+			cfg:     Config{MaxTurns: 3},
+			replies: []string{"Use SQLite.", "Too simple.", "Use PostgreSQL."},
+			answer:  "Use PostgreSQL.",
+			turns:   3,
+			stop:    StopMaxTurns,
+		},
+		{
+			name:    "token budget",
+			cfg:     Config{MaxTokens: 150},
+			replies: []string{"Use SQLite.", "Too simple."},
+			tokens:  100,
+			answer:  "Use SQLite.",
+			turns:   2,
+			stop:    StopTokenBudget,
+		},
+		{
+			name: "all critics must approve",
+			cfg: Config{Personas: []Persona{
+				{Name: "Architect", Role: RoleProposer},
+				{Name: "Security", Role: RoleCritic},
+				{Name: "Ops", Role: RoleCritic},
+			}},
+			replies: []string{"Use PostgreSQL.", "APPROVED", "Add backups.", "Use PostgreSQL with backups."},
+			answer:  "Use PostgreSQL with backups.",
+			turns:   4,
+			stop:    StopMaxTurns,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &scriptedProvider{replies: tt.replies, tokens: tt.tokens}
+			d, err := New(provider, tt.cfg)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			var reported int
+			result, err := d.Run(context.Background(), Request{
+				Question: "Which database should I use?",
+				OnTurn:   func(turn Turn, number, total int) { reported = number },
+			})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if result.Answer != tt.answer || len(result.Turns) != tt.turns || result.Stop != tt.stop {
+				t.Errorf("Run() = %q after %d turns (%s), want %q after %d turns (%s)",
+					result.Answer, len(result.Turns), result.Stop, tt.answer, tt.turns, tt.stop)
+			}
+			if reported != tt.turns {
+				t.Errorf("OnTurn reported %d turns, want %d", reported, tt.turns)
+			}
+			if result.Tokens != tt.tokens*tt.turns {
+				t.Errorf("Tokens = %d, want %d", result.Tokens, tt.tokens*tt.turns)
+			}
+		})
+	}
+}
+
+func TestDebater_RunMessages(t *testing.T) {
+	provider := &scriptedProvider{replies: []string{"Use SQLite.", "It does not scale.", "Use PostgreSQL."}}
+	d, err := New(provider, Config{
+		Model:    "glm-4.7",
+		MaxTurns: 3,
+		Personas: []Persona{
+			{Name: "Proposer", Role: RoleProposer},
+			{Name: "Critic", Role: RoleCritic, Model: "glm-4.7-flash"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	history := []llm.Message{{Role: llm.RoleUser, Content: "I build a blog"}, {Role: llm.RoleAssistant, Content: "Nice"}}
+	if _, err := d.Run(context.Background(), Request{Question: "Which database?", Context: history}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	critic := provider.requests[1]
+	if critic.Model != "glm-4.7-flash" || provider.requests[0].Model != "glm-4.7" {
+		t.Errorf("models = %s/%s, want glm-4.7/glm-4.7-flash", provider.requests[0].Model, critic.Model)
+	}
+	if last := critic.Messages[len(critic.Messages)-1].Content; !strings.Contains(last, "Use SQLite.") {
+		t.Errorf("critic did not get the draft: %q", last)
+	}
+	if critic.Messages[1].Content != "I build a blog" {
+		t.Errorf("conversation context missing: %+v", critic.Messages)
+	}
+
+	revision := provider.requests[2].Messages
+	if got := revision[len(revision)-2]; got.Role != llm.RoleAssistant || got.Content != "Use SQLite." {
+		t.Errorf("proposer did not get its draft: %+v", got)
+	}
+	if got := revision[len(revision)-1].Content; !strings.Contains(got, "Critic:\nIt does not scale.") {
+		t.Errorf("proposer did not get the critique: %q", got)
+	}
+}
+
+func TestNew_InvalidPersonas(t *testing.T) {
+	tests := []struct {
+		name     string
+		personas []Persona
+		wantErr  error
+	}{
+		{"critic first", []Persona{{Name: "C", Role: RoleCritic}, {Name: "P", Role: RoleProposer}}, ErrNoProposer},
+		{"no critic", []Persona{{Name: "P", Role: RoleProposer}}, ErrNoCritic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(&scriptedProvider{}, Config{Personas: tt.personas}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("New() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDebater_Trigger(t *testing.T) {
+	d, err := New(&scriptedProvider{}, Config{Auto: true, AutoMinChars: 50})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		message string
+		reason  string
+	}{
+		{"What time is it?", ""},
+		{"What are the pros and cons of Go?", ReasonKeyword},
+		{"Стоит ли переезжать в облако", ReasonKeyword},
+		{strings.Repeat("word ", 20) + "?", ReasonLength},
+		{strings.Repeat("word ", 20), ""},
+	}
+	for _, tt := range tests {
+		if got := d.Trigger(tt.message); got != tt.reason {
+			t.Errorf("Trigger(%q) = %q, want %q", tt.message, got, tt.reason)
+		}
+	}
+
+	manual, _ := New(&scriptedProvider{}, Config{})
+	if got := manual.Trigger("pros and cons"); got != "" {
+		t.Errorf("Trigger() without Auto = %q, want empty", got)
+	}
+}
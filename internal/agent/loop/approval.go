@@ -0,0 +1,106 @@
+package loop
+
+import (
+	stdcontext "context"
+	"errors"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/approval"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+const (
+	approvalRequestedResult = "The user is being asked to approve this action (it deletes or overwrites data) with Approve/Reject buttons. " +
+		"It will run automatically once approved and you will get the results. " +
+		"Do not call the tool again and do not try to do it another way; briefly tell the user what needs their approval."
+
+	approvalActiveResult = "Another approval request is waiting for the user's answer, so this action was not run. " +
+		"Ask the user to answer it first and try again later."
+)
+
+// SetApprovals sets the approval manager and the tool classes (tools.ClassFile,
+// tools.ClassShell, ...) whose destructive calls need the user's approval.
+func (te *ToolExecutor) SetApprovals(manager *approval.Manager, classes []string) {
+	te.approvals = manager
+	te.approvalClasses = make(map[string]bool, len(classes))
+	for _, class := range classes {
+		te.approvalClasses[class] = true
+	}
+}
+
+// needsApproval reports whether a call destroys data with a tool whose class needs approval.
+func (te *ToolExecutor) needsApproval(toolCall tools.ToolCall) bool {
+	if te.approvals == nil || !te.approvalClasses[tools.ToolClass(toolCall.Name)] {
+		return false
+	}
+	tool, ok := te.tools.Get(toolCall.Name)
+	if !ok {
+		return false
+	}
+	destructive, ok := tool.(tools.DestructiveTool)
+	return ok && destructive.Destructive(toolCall.Arguments)
+}
+
+// holdForApproval asks the user to approve the destructive calls of a round
+// with one request and returns the results of the held calls. Other calls
+// run as usual. Channels without approval buttons deny destructive calls.
+// Dry runs destroy nothing and need no approval.
+func (te *ToolExecutor) holdForApproval(ctx stdcontext.Context, toolCalls []tools.ToolCall, sessionID string) map[string]tools.ToolResult {
+	if tools.DryRun(ctx) {
+		return nil
+	}
+	var held []approval.Call
+	for _, toolCall := range toolCalls {
+		if te.needsApproval(toolCall) {
+			held = append(held, approval.Call{ID: toolCall.ID, Tool: toolCall.Name, Arguments: toolCall.Arguments})
+		}
+	}
+	if len(held) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(held))
+	for i, call := range held {
+		names[i] = call.Tool
+	}
+
+	content := approvalRequestedResult
+	var toolErr *tools.ToolError
+	_, err := te.approvals.Request(sessionID, planSummary(ctx), held)
+	switch {
+	case errors.Is(err, approval.ErrActive):
+		content = approvalActiveResult
+	case err != nil:
+		te.logger.WarnCtx(ctx, "Destructive tool calls denied without approval",
+			logger.Field{Key: "tools", Value: names},
+			logger.Field{Key: "error", Value: err.Error()})
+		toolErr = &tools.ToolError{
+			Type:       tools.ErrorTypePermission,
+			Code:       tools.ErrCodePermissionDenied,
+			Message:    "this action deletes or overwrites data and needs the user's approval, which is not available here: " + err.Error(),
+			Suggestion: "Tell the user what you wanted to do so they can do it or ask for it in a chat",
+		}
+		content = ""
+	default:
+		te.logger.InfoCtx(ctx, "Destructive tool calls held for approval",
+			logger.Field{Key: "tools", Value: names})
+	}
+
+	results := make(map[string]tools.ToolResult, len(held))
+	for _, call := range held {
+		results[call.ID] = tools.ToolResult{ToolCallID: call.ID, Content: content, Error: toolErr}
+	}
+	return results
+}
+
+// planSummary returns the plan of the request running with ctx, if any.
+func planSummary(ctx stdcontext.Context) string {
+	state, ok := ctx.Value(planKey{}).(*planState)
+	if !ok || state == nil {
+		return ""
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return strings.TrimSpace(state.plan.Render())
+}
@@ -0,0 +1,36 @@
+package loop
+
+import (
+	stdcontext "context"
+
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// ArtifactFunc receives the files produced by the tools of a request.
+type ArtifactFunc func(tool string, a tools.Artifact)
+
+// artifactKey is the context key of the artifact function of a request
+type artifactKey struct{}
+
+// WithArtifacts returns a context that delivers the artifacts of the
+// successful tool calls of a request to fn.
+func WithArtifacts(ctx stdcontext.Context, fn ArtifactFunc) stdcontext.Context {
+	return stdcontext.WithValue(ctx, artifactKey{}, fn)
+}
+
+// forwardArtifacts passes the artifacts of successful tool results to the
+// artifact function of the request, if any. Results are in the order of calls.
+func forwardArtifacts(ctx stdcontext.Context, calls []tools.ToolCall, results []tools.ToolResult) {
+	fn, ok := ctx.Value(artifactKey{}).(ArtifactFunc)
+	if !ok || fn == nil {
+		return
+	}
+	for i, result := range results {
+		if result.Error != nil || i >= len(calls) {
+			continue
+		}
+		for _, artifact := range result.Artifacts {
+			fn(calls[i].Name, artifact)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package loop
+
+import (
+	stdcontext "context"
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+const (
+	// defaultBudgetWarning is the number of remaining iterations at which the LLM is asked to wrap up
+	defaultBudgetWarning = 2
+
+	budgetWarningPrompt = "[Budget] %d tool iteration(s) left for this request. " +
+		"Wrap up: avoid starting new work, finish with the information you have."
+
+	budgetSummaryPrompt = "[Budget] The tool iteration limit for this request has been reached and tools are no longer available. " +
+		"Reply to the user with a summary: what was done, the results so far, and what remains to be done."
+
+	budgetFallbackSummary = "I reached the tool iteration limit (%d) before finishing the task. " +
+		"Ask me to continue and I will pick up where I left off."
+)
+
+// requestBudget tracks tool usage within one user request.
+type requestBudget struct {
+	maxIterations int
+	warnAt        int // Remaining iterations at which the wrap-up warning is injected (0 disables)
+	calls         *tools.CallBudget
+	plan          *planState // Plan of the request with per-step tool budgets (nil if not planned)
+}
+
+// newRequestBudget creates a budget for a request from the loop configuration.
+func (l *Loop) newRequestBudget() *requestBudget {
+	return &requestBudget{
+		maxIterations: l.config.MaxToolIterations,
+		warnAt:        max(l.config.BudgetWarning, 0),
+		calls:         tools.NewCallBudget(l.config.ToolBudgets),
+	}
+}
+
+// exhausted reports whether no tool iterations are left.
+func (b *requestBudget) exhausted(iteration int) bool {
+	return iteration >= b.maxIterations
+}
+
+// warning returns the wrap-up note for the iteration, or "" if it is not due yet.
+func (b *requestBudget) warning(iteration int) string {
+	remaining := b.maxIterations - iteration
+	if b.warnAt == 0 || iteration == 0 || remaining > b.warnAt {
+		return ""
+	}
+	return fmt.Sprintf(budgetWarningPrompt, remaining)
+}
+
+// takeCalls splits tool calls into the calls allowed by the step budget of
+// the plan and the per-class budgets, and denied results for the rest.
+func (b *requestBudget) takeCalls(calls []tools.ToolCall) ([]tools.ToolCall, map[string]tools.ToolResult) {
+	denied := make(map[string]tools.ToolResult)
+	if b.plan != nil {
+		calls, denied = b.plan.takeCalls(calls)
+	}
+	allowed := make([]tools.ToolCall, 0, len(calls))
+	for _, call := range calls {
+		if b.calls.Take(call.Name) {
+			allowed = append(allowed, call)
+			continue
+		}
+		denied[call.ID] = tools.ToolResult{
+			ToolCallID: call.ID,
+			Error:      b.calls.ExhaustedError(call.Name),
+		}
+	}
+	return allowed, denied
+}
+
+// annotate appends a note to successful results of tools whose budget is running out.
+func (b *requestBudget) annotate(calls []tools.ToolCall, results []tools.ToolResult) {
+	for i, call := range calls {
+		if i >= len(results) || results[i].Error != nil {
+			continue
+		}
+		if remaining := b.calls.Remaining(call.Name); remaining >= 0 && remaining <= 1 {
+			results[i].Content += fmt.Sprintf("\n\n[Budget] %d call(s) left for %s tools in this request.",
+				remaining, tools.ToolClass(call.Name))
+		}
+	}
+}
+
+// executeWithBudget runs the allowed tool calls, truncates their output and
+// applies guardrails to it, and merges in denied results, keeping the order
+// of the original calls.
+func (l *Loop) executeWithBudget(ctx stdcontext.Context, budget *requestBudget, calls []tools.ToolCall) ([]tools.ToolResult, error) {
+	allowed, denied := budget.takeCalls(calls)
+	if len(denied) > 0 {
+		l.logger.WarnCtx(ctx, "Tool calls denied by budget",
+			logger.Field{Key: "denied_count", Value: len(denied)})
+	}
+
+	executed, err := l.toolExecutor.ProcessToolCalls(ctx, allowed)
+	if err != nil {
+		return nil, err
+	}
+	l.applyTruncation(ctx, allowed, executed)
+	l.applyGuard(ctx, allowed, executed)
+	budget.annotate(allowed, executed)
+
+	results := make([]tools.ToolResult, 0, len(calls))
+	next := 0
+	for _, call := range calls {
+		if result, ok := denied[call.ID]; ok {
+			results = append(results, result)
+			continue
+		}
+		results = append(results, executed[next])
+		next++
+	}
+	return results, nil
+}
+
+// finalSummary asks the LLM for a summary without tools once the iteration
+// budget is exhausted, instead of failing the request.
+func (l *Loop) finalSummary(ctx stdcontext.Context, sessionID string, iteration int) (string, error) {
+	l.logger.WarnCtx(ctx, "Tool iteration budget exhausted, requesting final summary",
+		logger.Field{Key: "iterations", Value: iteration})
+
+	req, err := l.prepareLLMRequest(ctx, sessionID, iteration)
+	if err != nil {
+		return "", err
+	}
+	req.Tools = nil
+	req.Messages = append(req.Messages, llm.Message{Role: llm.RoleSystem, Content: budgetSummaryPrompt})
+
+	resp, err := l.chat(ctx, req)
+	content := ""
+	if err != nil {
+		l.logger.WarnCtx(ctx, "Final summary request failed",
+			logger.Field{Key: "error", Value: err.Error()})
+	} else {
+		content = strings.TrimSpace(resp.Content)
+	}
+	if content == "" {
+		content = fmt.Sprintf(budgetFallbackSummary, l.config.MaxToolIterations)
+	}
+
+	return l.handleNormalResponse(ctx, sessionID, llm.ChatResponse{Content: content})
+}
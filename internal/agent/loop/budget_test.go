@@ -0,0 +1,136 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// budgetProvider calls the read tool on every iteration and answers the
+// final summary request with "Summary".
+func budgetProvider() *mockToolCallProvider {
+	return &mockToolCallProvider{responses: []llm.ChatResponse{
+		toolCallResponse("call_1", "read", `{"path":"a.txt"}`),
+		toolCallResponse("call_2", "read", `{"path":"b.txt"}`),
+		toolCallResponse("call_3", "read", `{"path":"c.txt"}`),
+		textResponse("Summary"),
+	}}
+}
+
+func TestLoop_BudgetWarningAndFinalSummary(t *testing.T) {
+	provider := budgetProvider()
+	looper := newTestLoop(t, Config{LLMProvider: provider, MaxToolIterations: 3, BudgetWarning: 1})
+	tool := &recordingTool{name: "read", result: "content"}
+	if err := looper.RegisterTool(tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	answer, err := looper.Process(context.Background(), "budget", "Read all files")
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if answer != "Summary" {
+		t.Errorf("Expected the final summary, got %q", answer)
+	}
+	if len(provider.requests) != 4 || len(tool.calls) != 3 {
+		t.Fatalf("Expected 4 LLM requests and 3 tool runs, got %d and %d", len(provider.requests), len(tool.calls))
+	}
+
+	// The wrap-up warning is injected once one iteration is left
+	for i, req := range provider.requests[:3] {
+		last := req.Messages[len(req.Messages)-1]
+		warned := last.Role == llm.RoleSystem && strings.HasPrefix(last.Content, "[Budget] 1 tool iteration(s) left")
+		if warned != (i == 2) {
+			t.Errorf("Request %d: warning = %v, last message %+v", i, warned, last)
+		}
+		if len(req.Tools) == 0 {
+			t.Errorf("Request %d: expected tools", i)
+		}
+	}
+
+	// The summary is requested without tools
+	summary := provider.requests[3]
+	if summary.Tools != nil {
+		t.Errorf("Expected no tools in the summary request, got %d", len(summary.Tools))
+	}
+	if last := summary.Messages[len(summary.Messages)-1]; last.Role != llm.RoleSystem || last.Content != budgetSummaryPrompt {
+		t.Errorf("Expected the summary prompt, got %+v", last)
+	}
+
+	// Only the answer is saved, not the prompts
+	history, _ := looper.GetSessionHistory(context.Background(), "budget")
+	if last := history[len(history)-1]; last.Role != llm.RoleAssistant || last.Content != "Summary" {
+		t.Errorf("Expected the summary as the last message, got %+v", last)
+	}
+	for _, msg := range history {
+		if strings.HasPrefix(msg.Content, "[Budget]") {
+			t.Errorf("Budget prompt saved in history: %+v", msg)
+		}
+	}
+}
+
+func TestLoop_FinalSummaryFallback(t *testing.T) {
+	provider := budgetProvider()
+	provider.errs = map[int]error{3: errors.New("provider unavailable")}
+	looper := newTestLoop(t, Config{LLMProvider: provider, MaxToolIterations: 3})
+	if err := looper.RegisterTool(&recordingTool{name: "read", result: "content"}); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	answer, err := looper.Process(context.Background(), "fallback", "Read all files")
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if want := fmt.Sprintf(budgetFallbackSummary, 3); answer != want {
+		t.Errorf("Expected the fallback summary, got %q", answer)
+	}
+}
+
+func TestLoop_DeniedCallsKeepOrder(t *testing.T) {
+	provider := &mockToolCallProvider{responses: []llm.ChatResponse{
+		{
+			FinishReason: llm.FinishReasonToolCalls,
+			ToolCalls: []llm.ToolCall{
+				{ID: "call_1", Name: "read", Arguments: `{"path":"a.txt"}`},
+				{ID: "call_2", Name: "read", Arguments: `{"path":"b.txt"}`},
+				{ID: "call_3", Name: "list", Arguments: `{"path":"."}`},
+			},
+		},
+		textResponse("done"),
+	}}
+	looper := newTestLoop(t, Config{LLMProvider: provider, ToolBudgets: map[string]int{"read": 1}})
+	read := &recordingTool{name: "read", result: "file content"}
+	list := &recordingTool{name: "list", result: "a.txt b.txt"}
+	for _, tool := range []*recordingTool{read, list} {
+		if err := looper.RegisterTool(tool); err != nil {
+			t.Fatalf("Failed to register tool: %v", err)
+		}
+	}
+
+	if _, err := looper.Process(context.Background(), "denied", "Read the files"); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if len(read.calls) != 1 || len(list.calls) != 1 {
+		t.Errorf("Expected one read and one list run, got %v and %v", read.calls, list.calls)
+	}
+
+	// The denied call gets a placeholder result in its place
+	var results []llm.Message
+	for _, msg := range provider.requests[1].Messages {
+		if msg.Role == llm.RoleTool {
+			results = append(results, msg)
+		}
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 tool results, got %+v", results)
+	}
+	for i, want := range []string{"file content", "tool budget exhausted", "a.txt b.txt"} {
+		if results[i].ToolCallID != fmt.Sprintf("call_%d", i+1) || !strings.Contains(results[i].Content, want) {
+			t.Errorf("Result %d = %+v, want call_%d with %q", i, results[i], i+1, want)
+		}
+	}
+}
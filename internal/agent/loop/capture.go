@@ -0,0 +1,36 @@
+package loop
+
+import (
+	stdcontext "context"
+
+	"github.com/aatumaykin/nexbot/internal/capture"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// captureResponse stores the diagnostics of an LLM response when capturing is
+// enabled. Tool calls are checked against the tools offered in req.
+func (l *Loop) captureResponse(ctx stdcontext.Context, sessionID string, iteration int, req llm.ChatRequest, resp llm.ChatResponse) {
+	if l.config.Capture == nil {
+		return
+	}
+
+	offered := make(map[string]bool, len(req.Tools))
+	for _, tool := range req.Tools {
+		offered[tool.Name] = true
+	}
+	entry := capture.Diagnose(resp, func(name string) bool { return offered[name] })
+	entry.Iteration = iteration
+
+	stored, err := l.config.Capture.Record(sessionID, entry)
+	if err != nil {
+		l.logger.WarnCtx(ctx, "Failed to capture LLM response diagnostics",
+			logger.Field{Key: "error", Value: err.Error()})
+		return
+	}
+	if stored && entry.Malformed() {
+		l.logger.WarnCtx(ctx, "Malformed LLM response captured",
+			logger.Field{Key: "finish_reason", Value: entry.FinishReason},
+			logger.Field{Key: "iteration", Value: iteration})
+	}
+}
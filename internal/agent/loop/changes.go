@@ -0,0 +1,36 @@
+package loop
+
+import (
+	stdcontext "context"
+
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// ChangeFunc receives the file changes made by the tools of a request.
+type ChangeFunc func(tool string, c tools.FileChange)
+
+// changeKey is the context key of the change function of a request
+type changeKey struct{}
+
+// WithChanges returns a context that delivers the file changes of the
+// successful tool calls of a request to fn.
+func WithChanges(ctx stdcontext.Context, fn ChangeFunc) stdcontext.Context {
+	return stdcontext.WithValue(ctx, changeKey{}, fn)
+}
+
+// forwardChanges passes the file changes of successful tool results to the
+// change function of the request, if any. Results are in the order of calls.
+func forwardChanges(ctx stdcontext.Context, calls []tools.ToolCall, results []tools.ToolResult) {
+	fn, ok := ctx.Value(changeKey{}).(ChangeFunc)
+	if !ok || fn == nil {
+		return
+	}
+	for i, result := range results {
+		if result.Error != nil || i >= len(calls) {
+			continue
+		}
+		for _, change := range result.Changes {
+			fn(calls[i].Name, change)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package loop
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/agent/debate"
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+const (
+	// contextMessages limits the earlier conversation given to requests without tools
+	contextMessages = 20
+
+	// debateProgressName labels debate turns in progress reports
+	debateProgressName = "debate"
+)
+
+// Debate answers a question through a debate of the configured personas
+// (the /debate command). The question and the final answer are stored in the
+// session like a regular exchange; the intermediate turns are only logged.
+func (l *Loop) Debate(ctx stdcontext.Context, sessionID, question string) (string, error) {
+	ctx = sessionLogContext(ctx, sessionID)
+	if l.config.Debate == nil {
+		return "Debate mode is disabled. Enable [agent.debate] in the configuration.", nil
+	}
+	if strings.TrimSpace(question) == "" {
+		return "Usage: /debate <question>", nil
+	}
+
+	ctx, err := l.sessionOps.StartTurn(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+	if err := l.sessionOps.AddMessageToSession(ctx, sessionID, llm.Message{
+		Role:    llm.RoleUser,
+		Content: question,
+	}); err != nil {
+		return "", fmt.Errorf("failed to add user message: %w", err)
+	}
+
+	response, err := l.runDebate(ctx, sessionID, question, debate.ReasonCommand)
+	if errors.Is(err, session.ErrConflict) {
+		return "", err
+	}
+	if err != nil {
+		l.logger.ErrorCtx(ctx, "Debate failed", err)
+		return fmt.Sprintf("I encountered an error processing your message: %v", err), nil
+	}
+
+	l.maybeGenerateTitle(sessionID)
+	l.maybeUpdateBrief(sessionID)
+	return response, nil
+}
+
+// runDebate debates a question already added to the session and stores the answer.
+func (l *Loop) runDebate(ctx stdcontext.Context, sessionID, question, reason string) (string, error) {
+	history, err := l.sessionOps.GetSessionHistory(ctx, sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to read session: %w", err)
+	}
+
+	l.logger.InfoCtx(ctx, "Debate started",
+		logger.Field{Key: "reason", Value: reason})
+
+	progress, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	result, err := l.config.Debate.Run(ctx, debate.Request{
+		Question: question,
+		Context:  conversationContext(history),
+		OnTurn: func(turn debate.Turn, number, total int) {
+			l.logger.DebugCtx(ctx, "Debate turn",
+				logger.Field{Key: "persona", Value: turn.Persona},
+				logger.Field{Key: "role", Value: turn.Role},
+				logger.Field{Key: "approved", Value: turn.Approved},
+				logger.Field{Key: "tokens", Value: turn.Tokens})
+			if progress != nil {
+				progress(debateProgressName, tools.Progress{Percent: number * 100 / total, Status: turn.Persona})
+			}
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	l.logger.InfoCtx(ctx, "Debate finished",
+		logger.Field{Key: "turns", Value: len(result.Turns)},
+		logger.Field{Key: "tokens", Value: result.Tokens},
+		logger.Field{Key: "stop", Value: result.Stop})
+
+	if _, err := l.handleNormalResponse(ctx, sessionID, llm.ChatResponse{Content: result.Answer}); err != nil {
+		return "", err
+	}
+	return result.Answer + debateFooter(result), nil
+}
+
+// conversationContext returns the conversation before the current message:
+// user and assistant messages without tool calls, for LLM requests made
+// without tools (debate personas, planning).
+func conversationContext(history []llm.Message) []llm.Message {
+	if len(history) > 0 {
+		history = history[:len(history)-1] // The current message itself
+	}
+	var messages []llm.Message
+	for _, msg := range history {
+		if (msg.Role == llm.RoleUser || msg.Role == llm.RoleAssistant) && len(msg.ToolCalls) == 0 && msg.Content != "" {
+			messages = append(messages, llm.Message{Role: msg.Role, Content: msg.Content})
+		}
+	}
+	if len(messages) > contextMessages {
+		messages = messages[len(messages)-contextMessages:]
+	}
+	return messages
+}
+
+// debateFooter summarizes how the answer was reached.
+func debateFooter(result *debate.Result) string {
+	var outcome string
+	switch result.Stop {
+	case debate.StopAgreed:
+		outcome = "reviewers agreed"
+	case debate.StopTokenBudget:
+		outcome = "token budget reached"
+	default:
+		outcome = "turn limit reached"
+	}
+	return fmt.Sprintf("\n\n🗣 Refined in a debate of %d turns: %s.", len(result.Turns), outcome)
+}
@@ -0,0 +1,68 @@
+package loop
+
+import (
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/experiment"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// sessionVariant returns the prompt variant serving a session. A session keeps
+// the variant recorded in its metadata; new sessions, and sessions of a
+// variant that was removed, are assigned one and it is recorded.
+func (l *Loop) sessionVariant(sessionID string) (experiment.Variant, bool) {
+	exp := l.config.Experiment
+	if exp == nil {
+		return experiment.Variant{}, false
+	}
+
+	sess, err := l.sessionMgr.Get(sessionID)
+	if err != nil {
+		return exp.Assign(sessionID), true
+	}
+	meta, err := sess.ReadMeta()
+	if err == nil {
+		if variant, ok := exp.Lookup(meta.Variant); ok {
+			return variant, true
+		}
+	}
+
+	variant := exp.Assign(sessionID)
+	if err == nil {
+		meta.Variant = exp.Label(variant)
+		err = sess.WriteMeta(meta)
+	}
+	if err != nil {
+		l.logger.Warn("Failed to record prompt variant",
+			logger.Field{Key: "session_id", Value: sessionID},
+			logger.Field{Key: "error", Value: err.Error()})
+	}
+	return variant, true
+}
+
+// variantPrompt returns the prompt of the variant serving a session, to be
+// appended to the system prompt. Empty without an experiment or when the
+// variant keeps the base prompt.
+func (l *Loop) variantPrompt(sessionID string) (string, error) {
+	variant, ok := l.sessionVariant(sessionID)
+	if !ok || variant.Prompt == "" {
+		return "", nil
+	}
+	return l.contextBldr.RenderFile(variant.Prompt, sessionID)
+}
+
+// SessionVariant returns the label of the prompt variant recorded for a
+// session, or an empty string if no variant served it.
+func (l *Loop) SessionVariant(sessionID string) string {
+	if l.config.Experiment == nil {
+		return ""
+	}
+	sess, err := l.sessionMgr.Get(sessionID)
+	if err != nil {
+		return ""
+	}
+	var meta session.Meta
+	if meta, err = sess.ReadMeta(); err != nil {
+		return ""
+	}
+	return meta.Variant
+}
@@ -0,0 +1,100 @@
+package loop
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/forms"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/secrets"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+const (
+	formStartedResult = "The user is being asked for the missing arguments (%s) with a form. " +
+		"The tool will run automatically once the form is completed. " +
+		"Do not ask for these values yourself and do not call the tool again; briefly tell the user to answer the questions."
+
+	formActiveResult = "The user is already filling in another form. " +
+		"Ask them to finish or cancel it (" + forms.CancelCommand + ") and try again later."
+)
+
+// SetForms sets the form manager used to ask the user for missing tool arguments.
+func (te *ToolExecutor) SetForms(manager *forms.Manager) {
+	te.forms = manager
+}
+
+// startForm starts a form if the tool declares required fields that are
+// missing from the call. Returns false if the call should run as usual.
+func (te *ToolExecutor) startForm(ctx stdcontext.Context, toolCall tools.ToolCall, sessionID string) (tools.ToolResult, bool) {
+	if te.forms == nil || !te.forms.Supports(sessionID) {
+		return tools.ToolResult{}, false
+	}
+	tool, ok := te.tools.Get(toolCall.Name)
+	if !ok {
+		return tools.ToolResult{}, false
+	}
+	formTool, ok := tool.(tools.FormTool)
+	if !ok {
+		return tools.ToolResult{}, false
+	}
+
+	args := make(map[string]any)
+	if toolCall.Arguments != "" {
+		if err := json.Unmarshal([]byte(toolCall.Arguments), &args); err != nil {
+			return tools.ToolResult{}, false
+		}
+	}
+
+	missing := missingFields(formTool.FormFields(args), args)
+	if len(missing) == 0 {
+		return tools.ToolResult{}, false
+	}
+
+	names := make([]string, len(missing))
+	for i, field := range missing {
+		names[i] = field.Name
+	}
+
+	if _, err := te.forms.Start(sessionID, toolCall.Name, args, missing); err != nil {
+		if errors.Is(err, forms.ErrActive) {
+			return tools.ToolResult{ToolCallID: toolCall.ID, Content: formActiveResult}, true
+		}
+		te.logger.WarnCtx(ctx, "Failed to start form, executing tool as is",
+			logger.Field{Key: "tool_name", Value: toolCall.Name},
+			logger.Field{Key: "error", Value: err.Error()})
+		return tools.ToolResult{}, false
+	}
+
+	te.logger.InfoCtx(ctx, "Form started for missing tool arguments",
+		logger.Field{Key: "tool_name", Value: toolCall.Name},
+		logger.Field{Key: "fields", Value: names})
+	return tools.ToolResult{ToolCallID: toolCall.ID, Content: fmt.Sprintf(formStartedResult, names)}, true
+}
+
+// missingFields returns the fields without a non-empty value in args.
+func missingFields(fields []forms.Field, args map[string]any) []forms.Field {
+	var missing []forms.Field
+	for _, field := range fields {
+		if value, ok := args[field.Name]; !ok || value == nil || value == "" {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// ExecuteTool runs a tool call outside of an LLM request, e.g. after a form
+// has collected its arguments. Forms are not started for the call.
+func (l *Loop) ExecuteTool(ctx stdcontext.Context, sessionID string, toolCall tools.ToolCall) tools.ToolResult {
+	cfg := &tools.ExecutionConfig{
+		DefaultTimeout: 30 * time.Second,
+		SessionID:      sessionID,
+	}
+	if l.secrets != nil && sessionID != "" {
+		cfg.SecretResolver = secrets.NewResolver(l.secrets).Resolve
+	}
+	return l.toolExecutor.ExecuteToolCall(ctx, toolCall, cfg)
+}
@@ -0,0 +1,38 @@
+package loop
+
+import (
+	stdcontext "context"
+
+	"github.com/aatumaykin/nexbot/internal/guardrail"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// applyGuard scans and wraps successful results of untrusted tools before
+// they are added to the session. Results are in the order of calls.
+func (l *Loop) applyGuard(ctx stdcontext.Context, calls []tools.ToolCall, results []tools.ToolResult) {
+	if l.config.Guard == nil {
+		return
+	}
+	for i, call := range calls {
+		if i >= len(results) || results[i].Error != nil {
+			continue
+		}
+		content, result := l.config.Guard.Process(ctx, call.Name, results[i].Content)
+		results[i].Content = content
+
+		if len(result.Findings) > 0 {
+			fields := []logger.Field{
+				{Key: "tool_name", Value: call.Name},
+				{Key: "tool_call_id", Value: call.ID},
+				{Key: "patterns", Value: guardrail.PatternNames(result.Findings)},
+				{Key: "flagged", Value: result.Flagged},
+				{Key: "blocked", Value: result.Blocked},
+			}
+			if result.Verdict != nil {
+				fields = append(fields, logger.Field{Key: "classifier_reason", Value: result.Verdict.Reason})
+			}
+			l.logger.WarnCtx(ctx, "Possible prompt injection in tool output", fields...)
+		}
+	}
+}
@@ -2,12 +2,31 @@ package loop
 
 import (
 	stdcontext "context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	agentcontext "github.com/aatumaykin/nexbot/internal/agent/context"
+	"github.com/aatumaykin/nexbot/internal/agent/debate"
+	"github.com/aatumaykin/nexbot/internal/agent/planner"
+	"github.com/aatumaykin/nexbot/internal/agent/routing"
 	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/agent/toolselect"
+	"github.com/aatumaykin/nexbot/internal/agent/transcript"
+	"github.com/aatumaykin/nexbot/internal/agent/truncate"
+	"github.com/aatumaykin/nexbot/internal/agent/verify"
+	"github.com/aatumaykin/nexbot/internal/approval"
+	"github.com/aatumaykin/nexbot/internal/capture"
+	"github.com/aatumaykin/nexbot/internal/experiment"
+	"github.com/aatumaykin/nexbot/internal/forms"
+	"github.com/aatumaykin/nexbot/internal/guardrail"
 	"github.com/aatumaykin/nexbot/internal/llm"
 	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/projects"
 	"github.com/aatumaykin/nexbot/internal/secrets"
 	"github.com/aatumaykin/nexbot/internal/tools"
 )
@@ -19,6 +38,9 @@ var (
 	sessionIDKey contextKey = struct{}{}
 )
 
+// exportsSubdir is the workspace subdirectory for exported transcripts
+const exportsSubdir = "exports"
+
 // Loop manages the agent's execution loop, coordinating between
 // LLM provider, session management, and tools.
 type Loop struct {
@@ -33,20 +55,51 @@ type Loop struct {
 	toolExecutor *ToolExecutor
 	secrets      *secrets.Store
 	config       Config
+	titling      sync.Map // Sessions with title generation in progress
+	briefing     sync.Map // Projects with a brief update in progress
 }
 
 // Config holds configuration for the loop.
 type Config struct {
-	Workspace         string
-	SessionDir        string
-	Timezone          string
-	LLMProvider       llm.Provider
-	Logger            *logger.Logger
-	Model             string
-	MaxTokens         int
-	Temperature       float64
-	MaxToolIterations int
-	SecretsDir        string
+	Workspace              string
+	SessionDir             string
+	Timezone               string
+	PromptVariables        map[string]string // Custom system prompt template variables (e.g., USER_NAME)
+	PromptDefaults         fs.FS             // Default bootstrap files missing in the workspace (bundle builds)
+	LLMProvider            llm.Provider
+	Logger                 *logger.Logger
+	Model                  string
+	MaxTokens              int
+	Temperature            float64
+	Sampling               llm.Sampling // Sampling parameters besides Temperature (zero keeps provider defaults)
+	Deterministic          bool         // Greedy decoding with Sampling.Seed; session and variant sampling is ignored
+	MaxToolIterations      int
+	BudgetWarning          int                    // Ask the LLM to wrap up when N tool iterations are left (negative disables)
+	ToolRepairAttempts     int                    // Ask the LLM to fix invalid tool arguments N times before failing (negative disables)
+	ToolBudgets            map[string]int         // Tool calls per request, by tool class or tool name
+	TitleAfterTurns        int                    // Generate a session title after N user messages (0 disables)
+	Projects               *projects.Store        // Projects of sessions; attached sessions get the project brief (nil disables)
+	ProjectBriefTurns      int                    // Update the project brief after N user messages (0 disables)
+	ProjectBriefChars      int                    // Maximum length of a project brief
+	Guard                  *guardrail.Guard       // Guardrails on untrusted tool outputs (nil disables)
+	Truncator              *truncate.Truncator    // Shortens tool outputs to their token budgets (nil disables)
+	Router                 *routing.Router        // Routes requests between a cheap and a strong model (nil uses Model)
+	Debate                 *debate.Debater        // Answers questions through persona debates (nil disables)
+	Planner                *planner.Planner       // Plans multi-step requests before executing them (nil disables)
+	Verifier               *verify.Verifier       // Checks final answers against tool evidence before sending (nil disables)
+	Experiment             *experiment.Experiment // Splits sessions between prompt variants (nil disables)
+	ToolSelector           *toolselect.Selector   // Sends only the tools relevant to a request (nil sends all tools)
+	DisabledToolNamespaces []string               // Tool namespaces whose tools are not registered (e.g., "sys")
+	PromptCache            bool                   // Resend a per-request system prompt on every iteration as a cacheable prefix
+	Forms                  *forms.Manager         // Asks the user for missing arguments of form tools (nil disables)
+	Approvals              *approval.Manager      // Asks the user to approve destructive tool calls (nil disables)
+	ApprovalClasses        []string               // Tool classes whose destructive calls need approval
+	ToolProtocol           string                 // How tools are offered: auto, native or text (empty means auto)
+	Scrubber               session.Scrubber       // Masks personal data in stored session history (nil disables)
+	ToolRecorder           *tools.ToolRecorder    // Records tool results or replays recorded ones (nil disables)
+	Capture                *capture.Store         // Stores diagnostics of malformed LLM responses (nil disables)
+	CaptureLogprobs        bool                   // Request token log probabilities for the captured diagnostics
+	SecretsDir             string
 }
 
 // NewLoop creates a new execution loop.
@@ -74,12 +127,21 @@ func NewLoop(cfg Config) (*Loop, error) {
 	if cfg.MaxToolIterations == 0 {
 		cfg.MaxToolIterations = 10
 	}
+	if cfg.BudgetWarning == 0 {
+		cfg.BudgetWarning = defaultBudgetWarning
+	}
+	if cfg.ToolRepairAttempts == 0 {
+		cfg.ToolRepairAttempts = defaultToolRepairAttempts
+	}
 
 	// Create session manager
 	sessionMgr, err := session.NewManager(cfg.SessionDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session manager: %w", err)
 	}
+	if cfg.Scrubber != nil {
+		sessionMgr.SetScrubber(cfg.Scrubber)
+	}
 
 	// Create secrets store
 	secretsStore := secrets.NewStore(cfg.SecretsDir)
@@ -88,16 +150,36 @@ func NewLoop(cfg Config) (*Loop, error) {
 	contextBldr, err := agentcontext.NewBuilder(agentcontext.Config{
 		Workspace: cfg.Workspace,
 		Timezone:  cfg.Timezone,
+		Variables: cfg.PromptVariables,
+		Defaults:  cfg.PromptDefaults,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create context builder: %w", err)
 	}
+	if cfg.Projects != nil {
+		contextBldr.SetProjects(cfg.Projects)
+	}
 
 	// Create tool registry
 	toolRegistry := tools.NewRegistry()
+	for _, namespace := range cfg.DisabledToolNamespaces {
+		toolRegistry.DisableNamespace(namespace)
+	}
+
+	// The plan tool reports progress on the plan of a request
+	if cfg.Planner != nil {
+		if err := toolRegistry.Register(planTool{}); err != nil {
+			return nil, fmt.Errorf("failed to register plan tool: %w", err)
+		}
+	}
 
 	// Create tool executor with secrets support
 	toolExecutor := NewToolExecutor(cfg.Logger, toolRegistry, secretsStore)
+	toolExecutor.SetForms(cfg.Forms)
+	if cfg.Approvals != nil {
+		toolExecutor.SetApprovals(cfg.Approvals, cfg.ApprovalClasses)
+	}
+	toolExecutor.SetRecorder(cfg.ToolRecorder)
 
 	// Create session operations
 	sessionOps := NewSessionOperations(sessionMgr)
@@ -120,10 +202,16 @@ func NewLoop(cfg Config) (*Loop, error) {
 // Process handles a user message and returns the assistant's response.
 // This is the main entry point for the agent loop.
 func (l *Loop) Process(ctx stdcontext.Context, sessionID, userMessage string) (string, error) {
+	ctx = sessionLogContext(ctx, sessionID)
 	l.logger.DebugCtx(ctx, "Processing user message",
-		logger.Field{Key: "session_id", Value: sessionID},
 		logger.Field{Key: "message_length", Value: len(userMessage)})
 
+	// Writes of the turn detect changes of the history made meanwhile
+	ctx, err := l.sessionOps.StartTurn(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+
 	// Add user message to session
 	if err := l.sessionOps.AddMessageToSession(ctx, sessionID, llm.Message{
 		Role:    llm.RoleUser,
@@ -132,25 +220,58 @@ func (l *Loop) Process(ctx stdcontext.Context, sessionID, userMessage string) (s
 		return "", fmt.Errorf("failed to add user message: %w", err)
 	}
 
+	// Debate questions that need careful reasoning instead of answering directly
+	if l.config.Debate != nil {
+		if reason := l.config.Debate.Trigger(userMessage); reason != "" {
+			response, err := l.runDebate(ctx, sessionID, userMessage, reason)
+			if err == nil {
+				l.maybeGenerateTitle(sessionID)
+				l.maybeUpdateBrief(sessionID)
+				return response, nil
+			}
+			if errors.Is(err, session.ErrConflict) {
+				return "", err
+			}
+			l.logger.WarnCtx(ctx, "Debate failed, answering directly",
+				logger.Field{Key: "error", Value: err.Error()})
+		}
+	}
+
+	// Pick the model for this request
+	ctx = l.startRoute(ctx, sessionID, userMessage)
+	ctx = l.startPromptCache(ctx)
+	ctx = l.startToolSelection(ctx, sessionID, userMessage)
+
+	// Plan multi-step requests before executing them
+	budget := l.newRequestBudget()
+	if plan := l.startPlan(ctx, sessionID, userMessage); plan != nil {
+		budget.plan = plan
+		ctx = stdcontext.WithValue(ctx, planKey{}, plan)
+	}
+
 	// Process message with tool calling support
-	response, err := l.processWithToolCalling(ctx, sessionID, 0)
+	response, err := l.processWithToolCalling(ctx, sessionID, 0, budget)
+	if errors.Is(err, session.ErrConflict) {
+		// The history changed under the turn (e.g. /new): the caller drops the answer
+		return "", err
+	}
 	if err != nil {
-		l.logger.ErrorCtx(ctx, "Failed to process message", err,
-			logger.Field{Key: "session_id", Value: sessionID})
+		l.logger.ErrorCtx(ctx, "Failed to process message", err)
 		// Return a graceful error message instead of failing
 		return fmt.Sprintf("I encountered an error processing your message: %v", err), nil
 	}
 
+	l.maybeGenerateTitle(sessionID)
+	l.maybeUpdateBrief(sessionID)
+
 	return response, nil
 }
 
 // processWithToolCalling processes a message, handling tool calls recursively.
-func (l *Loop) processWithToolCalling(ctx stdcontext.Context, sessionID string, iteration int) (string, error) {
-	// Prevent infinite loops
-	if iteration >= l.config.MaxToolIterations {
-		l.logger.ErrorCtx(ctx, "Maximum tool call iterations reached", nil,
-			logger.Field{Key: "iterations", Value: iteration})
-		return "", fmt.Errorf("reached maximum tool call iterations (%d)", l.config.MaxToolIterations)
+func (l *Loop) processWithToolCalling(ctx stdcontext.Context, sessionID string, iteration int, budget *requestBudget) (string, error) {
+	// Prevent infinite loops: summarize instead of failing
+	if budget.exhausted(iteration) {
+		return l.finalSummary(ctx, sessionID, iteration)
 	}
 
 	// Prepare LLM request
@@ -159,8 +280,18 @@ func (l *Loop) processWithToolCalling(ctx stdcontext.Context, sessionID string,
 		return "", err
 	}
 
+	// Ask the LLM to wrap up when the budget is nearly exhausted (not persisted)
+	if note := budget.warning(iteration); note != "" {
+		req.Messages = append(req.Messages, llm.Message{Role: llm.RoleSystem, Content: note})
+	}
+
+	// Remind the LLM of the plan and the current step (not persisted)
+	if budget.plan != nil {
+		req.Messages = append(req.Messages, llm.Message{Role: llm.RoleSystem, Content: budget.plan.note()})
+	}
+
 	// Call LLM
-	resp, err := l.provider.Chat(ctx, req)
+	resp, err := l.chat(ctx, req)
 	if err != nil {
 		return "", fmt.Errorf("LLM call failed: %w", err)
 	}
@@ -169,13 +300,24 @@ func (l *Loop) processWithToolCalling(ctx stdcontext.Context, sessionID string,
 		logger.Field{Key: "finish_reason", Value: resp.FinishReason},
 		logger.Field{Key: "content_length", Value: len(resp.Content)},
 		logger.Field{Key: "tool_calls_count", Value: len(resp.ToolCalls)},
+		logger.Field{Key: "prompt_tokens", Value: resp.Usage.PromptTokens},
+		logger.Field{Key: "cached_tokens", Value: resp.Usage.CachedTokens},
 		logger.Field{Key: "iteration", Value: iteration})
+	l.captureResponse(ctx, sessionID, iteration, req, *resp)
+
+	// Ask the LLM to fix tool calls with invalid arguments before running them
+	if resp, err = l.repairToolCalls(ctx, sessionID, iteration, req, resp); err != nil {
+		return "", err
+	}
 
 	// Handle tool calls or normal response
 	if resp.FinishReason == llm.FinishReasonToolCalls && len(resp.ToolCalls) > 0 {
-		return l.handleToolCalls(ctx, sessionID, iteration, *resp)
+		return l.handleToolCalls(ctx, sessionID, iteration, *resp, budget)
 	}
 
+	// Check the final answer against the tool evidence before sending it
+	resp.Content = l.verifyAnswer(ctx, sessionID, resp.Content)
+
 	return l.handleNormalResponse(ctx, sessionID, *resp)
 }
 
@@ -186,30 +328,24 @@ func (l *Loop) prepareLLMRequest(ctx stdcontext.Context, sessionID string, itera
 		return llm.ChatRequest{}, fmt.Errorf("failed to get session history: %w", err)
 	}
 
-	// Build system prompt (only on first iteration)
+	// Build system prompt (only on first iteration unless prompt caching is enabled)
 	messages := sessionHistory
-	if iteration == 0 {
-		systemPrompt, err := l.buildSystemPrompt(sessionID)
-		if err != nil {
-			l.logger.WarnCtx(ctx, "Failed to build system prompt",
-				logger.Field{Key: "error", Value: err.Error()})
-		} else if systemPrompt != "" {
-			messages = append([]llm.Message{{
-				Role:    llm.RoleSystem,
-				Content: systemPrompt,
-			}}, sessionHistory...)
-		}
+	if systemMsg, ok := l.systemMessage(ctx, sessionID, iteration); ok {
+		messages = append([]llm.Message{systemMsg}, sessionHistory...)
 	}
 
 	req := llm.ChatRequest{
-		Messages:    messages,
-		Model:       l.config.Model,
-		Temperature: l.config.Temperature,
-		MaxTokens:   l.config.MaxTokens,
+		Messages:   messages,
+		Model:      l.requestModel(ctx),
+		MaxTokens:  l.config.MaxTokens,
+		CacheTools: l.config.PromptCache,
 	}
+	l.sessionSampling(ctx, sessionID).Apply(&req)
+	req.Deterministic = l.config.Deterministic
+	req.Logprobs = l.config.Capture != nil && l.config.CaptureLogprobs
 
-	// Add tool definitions if provider supports them
-	if l.provider.SupportsToolCalling() {
+	// Add tool definitions if provider supports them or the text protocol is used
+	if l.provider.SupportsToolCalling() || l.textTools() {
 		toolSchemas := l.tools.ToSchema()
 		if len(toolSchemas) > 0 {
 			llmTools := make([]llm.ToolDefinition, len(toolSchemas))
@@ -220,7 +356,7 @@ func (l *Loop) prepareLLMRequest(ctx stdcontext.Context, sessionID string, itera
 					Parameters:  schema.Parameters,
 				}
 			}
-			req.Tools = llmTools
+			req.Tools = l.selectTools(ctx, llmTools)
 			l.logger.DebugCtx(ctx, "Added tool definitions to request",
 				logger.Field{Key: "tool_count", Value: len(llmTools)},
 				logger.Field{Key: "tools", Value: fmt.Sprintf("%+v", llmTools)})
@@ -231,15 +367,16 @@ func (l *Loop) prepareLLMRequest(ctx stdcontext.Context, sessionID string, itera
 }
 
 // handleToolCalls processes tool calls from LLM response.
-func (l *Loop) handleToolCalls(ctx stdcontext.Context, sessionID string, iteration int, resp llm.ChatResponse) (string, error) {
+func (l *Loop) handleToolCalls(ctx stdcontext.Context, sessionID string, iteration int, resp llm.ChatResponse, budget *requestBudget) (string, error) {
 	l.logger.DebugCtx(ctx, "LLM requested tool calls",
 		logger.Field{Key: "tool_call_count", Value: len(resp.ToolCalls)},
 		logger.Field{Key: "iteration", Value: iteration})
 
 	// Add assistant message with tool calls to session
 	if err := l.sessionOps.AddMessageToSession(ctx, sessionID, llm.Message{
-		Role:    llm.RoleAssistant,
-		Content: resp.Content,
+		Role:      llm.RoleAssistant,
+		Content:   resp.Content,
+		ToolCalls: resp.ToolCalls,
 	}); err != nil {
 		return "", fmt.Errorf("failed to add assistant message: %w", err)
 	}
@@ -249,10 +386,13 @@ func (l *Loop) handleToolCalls(ctx stdcontext.Context, sessionID string, iterati
 
 	// Prepare and execute tool calls
 	toolCalls := l.toolExecutor.PrepareToolCalls(resp.ToolCalls)
-	results, err := l.toolExecutor.ProcessToolCalls(ctxWithSession, toolCalls)
+	results, err := l.executeWithBudget(ctxWithSession, budget, toolCalls)
 	if err != nil {
 		return "", fmt.Errorf("failed to execute tools: %w", err)
 	}
+	l.observeTools(ctx, sessionID, toolCalls)
+	forwardArtifacts(ctx, toolCalls, results)
+	forwardChanges(ctx, toolCalls, results)
 
 	// Add tool results to session
 	if err := l.addToolResultsToSession(ctx, sessionID, results); err != nil {
@@ -262,7 +402,7 @@ func (l *Loop) handleToolCalls(ctx stdcontext.Context, sessionID string, iterati
 	// Recursively process again with tool results
 	l.logger.DebugCtx(ctx, "Recursively processing with tool results",
 		logger.Field{Key: "next_iteration", Value: iteration + 1})
-	return l.processWithToolCalling(ctx, sessionID, iteration+1)
+	return l.processWithToolCalling(ctx, sessionID, iteration+1, budget)
 }
 
 // handleNormalResponse processes a normal LLM response without tool calls.
@@ -312,6 +452,15 @@ func (l *Loop) buildSystemPrompt(sessionID string) (string, error) {
 		return "", err
 	}
 
+	// Append the prompt of the A/B test variant serving the session
+	variant, err := l.variantPrompt(sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to render prompt variant: %w", err)
+	}
+	if variant != "" {
+		systemPrompt += variant + "\n\n---\n\n"
+	}
+
 	// Log system prompt for debugging
 	var preview string
 	if len(systemPrompt) > 500 {
@@ -343,6 +492,16 @@ func (l *Loop) ClearSession(ctx stdcontext.Context, sessionID string) error {
 	return l.sessionOps.ClearSession(ctx, sessionID)
 }
 
+// SaveSessionAs saves the session history under a name; the chat continues in the named session.
+func (l *Loop) SaveSessionAs(ctx stdcontext.Context, sessionID, name string) (string, error) {
+	return l.sessionOps.SaveSessionAs(ctx, sessionID, name)
+}
+
+// ResumeSession continues a named session in the given chat.
+func (l *Loop) ResumeSession(ctx stdcontext.Context, sessionID, name string) (string, error) {
+	return l.sessionOps.ResumeSession(ctx, sessionID, name)
+}
+
 // DeleteSession deletes a session entirely.
 func (l *Loop) DeleteSession(ctx stdcontext.Context, sessionID string) error {
 	return l.sessionOps.DeleteSession(ctx, sessionID)
@@ -381,6 +540,12 @@ func (l *Loop) GetSessionMaxTokens(sessionID string) int {
 // RegisterTool registers a tool with the loop's tool registry.
 func (l *Loop) RegisterTool(tool tools.Tool) error {
 	if err := l.tools.Register(tool); err != nil {
+		if errors.Is(err, tools.ErrNamespaceDisabled) {
+			l.logger.DebugCtx(stdcontext.Background(), "Tool skipped: namespace disabled",
+				logger.Field{Key: "tool_name", Value: tool.Name()},
+				logger.Field{Key: "qualified_name", Value: tools.QualifiedName(tool)})
+			return nil
+		}
 		return fmt.Errorf("failed to register tool %s: %w", tool.Name(), err)
 	}
 	l.logger.DebugCtx(stdcontext.Background(), "Tool registered",
@@ -400,8 +565,8 @@ func (l *Loop) GetSecretsStore() *secrets.Store {
 
 // AddErrorToSession adds an error message to the session history.
 func (l *Loop) AddErrorToSession(ctx stdcontext.Context, sessionID string, err error) error {
-	l.logger.ErrorCtx(ctx, "Adding error to session", err,
-		logger.Field{Key: "session_id", Value: sessionID})
+	ctx = sessionLogContext(ctx, sessionID)
+	l.logger.ErrorCtx(ctx, "Adding error to session", err)
 	errorMsg := fmt.Sprintf("**Error from previous attempt:**\n%s\n\nPlease analyze this error and suggest a solution.", err.Error())
 	return l.sessionOps.AddMessageToSession(ctx, sessionID, llm.Message{
 		Role:    llm.RoleUser,
@@ -411,8 +576,8 @@ func (l *Loop) AddErrorToSession(ctx stdcontext.Context, sessionID string, err e
 
 // ProcessRecovery processes a recovery request after an error.
 func (l *Loop) ProcessRecovery(ctx stdcontext.Context, sessionID string, originalErr error) (string, error) {
-	l.logger.ErrorCtx(ctx, "Starting recovery processing", originalErr,
-		logger.Field{Key: "session_id", Value: sessionID})
+	ctx = sessionLogContext(ctx, sessionID)
+	l.logger.ErrorCtx(ctx, "Starting recovery processing", originalErr)
 
 	// Build recovery prompt with length limit (500 chars)
 	basePrompt := "The previous attempt failed. Please analyze this error and suggest a solution:"
@@ -429,3 +594,43 @@ func (l *Loop) ProcessRecovery(ctx stdcontext.Context, sessionID string, origina
 	// Process with normal timeout (not reduced)
 	return l.Process(ctx, sessionID, recoveryPrompt)
 }
+
+// ExportSession renders the session transcript and saves it to the workspace
+// exports directory. Returns the path to the exported file.
+func (l *Loop) ExportSession(ctx stdcontext.Context, sessionID string, format transcript.Format) (string, error) {
+	ctx = sessionLogContext(ctx, sessionID)
+	entries, err := l.sessionOps.GetSessionEntries(ctx, sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to read session: %w", err)
+	}
+
+	content, err := transcript.Render(sessionID, entries, format, transcript.Options{})
+	if err != nil {
+		return "", err
+	}
+
+	exportDir := filepath.Join(l.workspace, exportsSubdir)
+	if err := os.MkdirAll(exportDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create exports directory: %w", err)
+	}
+
+	fileName := fmt.Sprintf("%s-%s.%s",
+		transcript.SafeFileName(sessionID), time.Now().Format("20060102-150405"), format.Extension())
+	path := filepath.Join(exportDir, fileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write transcript: %w", err)
+	}
+
+	l.logger.InfoCtx(ctx, "Session exported",
+		logger.Field{Key: "path", Value: path})
+
+	return path, nil
+}
+
+// sessionLogContext adds the session ID to the log fields of ctx, so the
+// *Ctx log calls of the request don't repeat it, and to the LLM requests,
+// so a provider queue serves the sessions in turn.
+func sessionLogContext(ctx stdcontext.Context, sessionID string) stdcontext.Context {
+	ctx = llm.WithSession(ctx, sessionID)
+	return logger.WithContext(ctx, logger.Field{Key: "session_id", Value: sessionID})
+}
@@ -11,6 +11,10 @@ import (
 	"github.com/google/uuid"
 )
 
+// sendAttempts is how many times a message waiting for its result is
+// published; repeats carry the same idempotency key, so they never double-post
+const sendAttempts = 2
+
 // AgentMessageSender implements agent.MessageSender through the message bus.
 // This bridges the Agent Layer's MessageSender interface with the Bus Layer.
 type AgentMessageSender struct {
@@ -35,21 +39,35 @@ func (a *AgentMessageSender) SendMessage(userID, channelType, sessionID, message
 // SendMessageWithKeyboard sends a message with inline keyboard through the message bus and waits for result.
 // Implements agent.MessageSender interface.
 func (a *AgentMessageSender) SendMessageWithKeyboard(userID, channelType, sessionID, message string, keyboard *bus.InlineKeyboard, format bus.FormatType, timeout time.Duration) (*agent.MessageResult, error) {
-	// Use default timeout of 5 seconds if not provided
-	if timeout == 0 {
-		timeout = 5 * time.Second
-	}
+	return a.sendTextMessage(userID, channelType, sessionID, message, nil, keyboard, format, timeout)
+}
+
+// SendTableMessage sends a text message with a table through the message bus and waits for result.
+// Implements agent.TableSender interface.
+func (a *AgentMessageSender) SendTableMessage(userID, channelType, sessionID, message string, table *bus.Table, keyboard *bus.InlineKeyboard, format bus.FormatType, timeout time.Duration) (*agent.MessageResult, error) {
+	return a.sendTextMessage(userID, channelType, sessionID, message, table, keyboard, format, timeout)
+}
 
+// sendTextMessage publishes a text message, optionally with a table and an inline keyboard, and waits for result.
+func (a *AgentMessageSender) sendTextMessage(userID, channelType, sessionID, message string, table *bus.Table, keyboard *bus.InlineKeyboard, format bus.FormatType, timeout time.Duration) (*agent.MessageResult, error) {
 	// Генерируем correlation ID
 	correlationID := uuid.New().String()
 
-	// Регистрируем ожидание результата
-	tracker := a.messageBus.GetResultTracker()
-	resultCh := tracker.Register(correlationID)
-
-	// Публикуем сообщение в bus
 	var event *bus.OutboundMessage
-	if keyboard != nil {
+	switch {
+	case table != nil:
+		event = bus.NewTableMessage(
+			bus.ChannelType(channelType),
+			userID,
+			sessionID,
+			message,
+			table,
+			correlationID,
+			keyboard,
+			format,
+			nil, // metadata
+		)
+	case keyboard != nil:
 		event = bus.NewOutboundMessageWithKeyboard(
 			bus.ChannelType(channelType),
 			userID,
@@ -60,7 +78,7 @@ func (a *AgentMessageSender) SendMessageWithKeyboard(userID, channelType, sessio
 			format,
 			nil, // metadata
 		)
-	} else {
+	default:
 		event = bus.NewOutboundMessage(
 			bus.ChannelType(channelType),
 			userID,
@@ -71,56 +89,15 @@ func (a *AgentMessageSender) SendMessageWithKeyboard(userID, channelType, sessio
 			nil, // metadata
 		)
 	}
-
-	if err := a.messageBus.PublishOutbound(*event); err != nil {
-		// Удаляем регистрацию при ошибке публикации
-		a.logger.ErrorCtx(context.Background(), "failed to publish outbound message", err,
-			logger.Field{Key: "user_id", Value: userID},
-			logger.Field{Key: "channel_type", Value: channelType})
-		return nil, fmt.Errorf("failed to publish message: %w", err)
-	}
-
-	// Ждем результат отправки с указанным timeout
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	// Ждем результат напрямую через канал (более эффективно, чем через Wait)
-	select {
-	case result := <-resultCh:
-		a.logger.DebugCtx(context.Background(), "message send result received",
-			logger.Field{Key: "correlation_id", Value: correlationID},
-			logger.Field{Key: "success", Value: result.Success})
-
-		// Возвращаем результат
-		return &agent.MessageResult{
-			Success:      result.Success,
-			Error:        result.Error,
-			ResponseText: "",
-		}, nil
-	case <-ctx.Done():
-		a.logger.ErrorCtx(context.Background(), "timeout waiting for send result", ctx.Err(),
-			logger.Field{Key: "correlation_id", Value: correlationID},
-			logger.Field{Key: "timeout", Value: timeout})
-		return nil, fmt.Errorf("timeout waiting for send result: %w", ctx.Err())
-	}
+	return a.publishAndWait(event, "outbound message", timeout)
 }
 
 // SendEditMessage edits an existing message.
 // Implements agent.MessageSender interface.
 func (a *AgentMessageSender) SendEditMessage(userID, channelType, sessionID, messageID, content string, keyboard *bus.InlineKeyboard, format bus.FormatType, timeout time.Duration) (*agent.MessageResult, error) {
-	// Use default timeout of 5 seconds if not provided
-	if timeout == 0 {
-		timeout = 5 * time.Second
-	}
-
 	// Генерируем correlation ID
 	correlationID := uuid.New().String()
 
-	// Регистрируем ожидание результата
-	tracker := a.messageBus.GetResultTracker()
-	resultCh := tracker.Register(correlationID)
-
-	// Публикуем сообщение в bus
 	var event *bus.OutboundMessage
 	if keyboard != nil {
 		event = bus.NewEditMessageWithKeyboard(
@@ -146,102 +123,29 @@ func (a *AgentMessageSender) SendEditMessage(userID, channelType, sessionID, mes
 			nil, // metadata
 		)
 	}
-
-	if err := a.messageBus.PublishOutbound(*event); err != nil {
-		a.logger.ErrorCtx(context.Background(), "failed to publish edit message", err,
-			logger.Field{Key: "user_id", Value: userID},
-			logger.Field{Key: "channel_type", Value: channelType},
-			logger.Field{Key: "message_id", Value: messageID})
-		return nil, fmt.Errorf("failed to publish edit message: %w", err)
-	}
-
-	// Ждем результат с указанным timeout
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	select {
-	case result := <-resultCh:
-		return &agent.MessageResult{
-			Success:      result.Success,
-			Error:        result.Error,
-			ResponseText: "",
-		}, nil
-	case <-ctx.Done():
-		a.logger.ErrorCtx(context.Background(), "timeout waiting for edit message result", ctx.Err(),
-			logger.Field{Key: "correlation_id", Value: correlationID},
-			logger.Field{Key: "timeout", Value: timeout})
-		return nil, fmt.Errorf("timeout waiting for edit message result: %w", ctx.Err())
-	}
+	return a.publishAndWait(event, "edit message", timeout)
 }
 
 // SendDeleteMessage deletes an existing message.
 // Implements agent.MessageSender interface.
 func (a *AgentMessageSender) SendDeleteMessage(userID, channelType, sessionID, messageID string, timeout time.Duration) (*agent.MessageResult, error) {
-	// Use default timeout of 5 seconds if not provided
-	if timeout == 0 {
-		timeout = 5 * time.Second
-	}
-
-	// Генерируем correlation ID
-	correlationID := uuid.New().String()
-
-	// Регистрируем ожидание результата
-	tracker := a.messageBus.GetResultTracker()
-	resultCh := tracker.Register(correlationID)
-
-	// Публикуем сообщение в bus
 	event := bus.NewDeleteMessage(
 		bus.ChannelType(channelType),
 		userID,
 		sessionID,
 		messageID,
-		correlationID,
+		uuid.New().String(),
 		nil, // metadata
 	)
-
-	if err := a.messageBus.PublishOutbound(*event); err != nil {
-		a.logger.ErrorCtx(context.Background(), "failed to publish delete message", err,
-			logger.Field{Key: "user_id", Value: userID},
-			logger.Field{Key: "channel_type", Value: channelType},
-			logger.Field{Key: "message_id", Value: messageID})
-		return nil, fmt.Errorf("failed to publish delete message: %w", err)
-	}
-
-	// Ждем результат с указанным timeout
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	select {
-	case result := <-resultCh:
-		return &agent.MessageResult{
-			Success:      result.Success,
-			Error:        result.Error,
-			ResponseText: "",
-		}, nil
-	case <-ctx.Done():
-		a.logger.ErrorCtx(context.Background(), "timeout waiting for delete message result", ctx.Err(),
-			logger.Field{Key: "correlation_id", Value: correlationID},
-			logger.Field{Key: "timeout", Value: timeout})
-		return nil, fmt.Errorf("timeout waiting for delete message result: %w", ctx.Err())
-	}
+	return a.publishAndWait(event, "delete message", timeout)
 }
 
 // SendPhotoMessage sends a photo message.
 // Implements agent.MessageSender interface.
 func (a *AgentMessageSender) SendPhotoMessage(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard, format bus.FormatType, timeout time.Duration) (*agent.MessageResult, error) {
-	// Use default timeout of 5 seconds if not provided
-	if timeout == 0 {
-		timeout = 5 * time.Second
-	}
-
 	// Генерируем correlation ID
 	correlationID := uuid.New().String()
 
-	// Регистрируем ожидание результата
-	tracker := a.messageBus.GetResultTracker()
-	resultCh := tracker.Register(correlationID)
-
-	// Публикуем сообщение в bus
 	var event *bus.OutboundMessage
 	if keyboard != nil {
 		event = bus.NewPhotoMessageWithKeyboard(
@@ -265,49 +169,15 @@ func (a *AgentMessageSender) SendPhotoMessage(userID, channelType, sessionID str
 			nil, // metadata
 		)
 	}
-
-	if err := a.messageBus.PublishOutbound(*event); err != nil {
-		a.logger.ErrorCtx(context.Background(), "failed to publish photo message", err,
-			logger.Field{Key: "user_id", Value: userID},
-			logger.Field{Key: "channel_type", Value: channelType})
-		return nil, fmt.Errorf("failed to publish photo message: %w", err)
-	}
-
-	// Ждем результат с указанным timeout
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	select {
-	case result := <-resultCh:
-		return &agent.MessageResult{
-			Success:      result.Success,
-			Error:        result.Error,
-			ResponseText: "",
-		}, nil
-	case <-ctx.Done():
-		a.logger.ErrorCtx(context.Background(), "timeout waiting for photo message result", ctx.Err(),
-			logger.Field{Key: "correlation_id", Value: correlationID},
-			logger.Field{Key: "timeout", Value: timeout})
-		return nil, fmt.Errorf("timeout waiting for photo message result: %w", ctx.Err())
-	}
+	return a.publishAndWait(event, "photo message", timeout)
 }
 
 // SendDocumentMessage sends a document message.
 // Implements agent.MessageSender interface.
 func (a *AgentMessageSender) SendDocumentMessage(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard, format bus.FormatType, timeout time.Duration) (*agent.MessageResult, error) {
-	// Use default timeout of 5 seconds if not provided
-	if timeout == 0 {
-		timeout = 5 * time.Second
-	}
-
 	// Генерируем correlation ID
 	correlationID := uuid.New().String()
 
-	// Регистрируем ожидание результата
-	tracker := a.messageBus.GetResultTracker()
-	resultCh := tracker.Register(correlationID)
-
-	// Публикуем сообщение в bus
 	var event *bus.OutboundMessage
 	if keyboard != nil {
 		event = bus.NewDocumentMessageWithKeyboard(
@@ -331,31 +201,7 @@ func (a *AgentMessageSender) SendDocumentMessage(userID, channelType, sessionID
 			nil, // metadata
 		)
 	}
-
-	if err := a.messageBus.PublishOutbound(*event); err != nil {
-		a.logger.ErrorCtx(context.Background(), "failed to publish document message", err,
-			logger.Field{Key: "user_id", Value: userID},
-			logger.Field{Key: "channel_type", Value: channelType})
-		return nil, fmt.Errorf("failed to publish document message: %w", err)
-	}
-
-	// Ждем результат с указанным timeout
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	select {
-	case result := <-resultCh:
-		return &agent.MessageResult{
-			Success:      result.Success,
-			Error:        result.Error,
-			ResponseText: "",
-		}, nil
-	case <-ctx.Done():
-		a.logger.ErrorCtx(context.Background(), "timeout waiting for document message result", ctx.Err(),
-			logger.Field{Key: "correlation_id", Value: correlationID},
-			logger.Field{Key: "timeout", Value: timeout})
-		return nil, fmt.Errorf("timeout waiting for document message result: %w", ctx.Err())
-	}
+	return a.publishAndWait(event, "document message", timeout)
 }
 
 // SendMessageAsync sends a message asynchronously (fire-and-forget) without waiting for result.
@@ -548,4 +394,116 @@ func (a *AgentMessageSender) SendDocumentMessageAsync(userID, channelType, sessi
 	return nil
 }
 
+// SendStickerMessage sends a sticker by file ID or URL.
+// Implements agent.MessageSender interface.
+func (a *AgentMessageSender) SendStickerMessage(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard, timeout time.Duration) (*agent.MessageResult, error) {
+	correlationID := uuid.New().String()
+	event := bus.NewStickerMessage(bus.ChannelType(channelType), userID, sessionID, media, keyboard, correlationID, nil)
+	return a.publishAndWait(event, "sticker message", timeout)
+}
+
+// SendAnimationMessage sends a GIF or MPEG-4 animation.
+// Implements agent.MessageSender interface.
+func (a *AgentMessageSender) SendAnimationMessage(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard, format bus.FormatType, timeout time.Duration) (*agent.MessageResult, error) {
+	correlationID := uuid.New().String()
+	event := bus.NewAnimationMessage(bus.ChannelType(channelType), userID, sessionID, media, keyboard, correlationID, format, nil)
+	return a.publishAndWait(event, "animation message", timeout)
+}
+
+// SendStickerMessageAsync sends a sticker asynchronously.
+// Implements agent.MessageSender interface.
+func (a *AgentMessageSender) SendStickerMessageAsync(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard) error {
+	event := bus.NewStickerMessage(bus.ChannelType(channelType), userID, sessionID, media, keyboard, uuid.New().String(), nil)
+	return a.publishAsync(event, "sticker message")
+}
+
+// SendAnimationMessageAsync sends an animation asynchronously.
+// Implements agent.MessageSender interface.
+func (a *AgentMessageSender) SendAnimationMessageAsync(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard, format bus.FormatType) error {
+	event := bus.NewAnimationMessage(bus.ChannelType(channelType), userID, sessionID, media, keyboard, uuid.New().String(), format, nil)
+	return a.publishAsync(event, "animation message")
+}
+
+// PinMessage pins a message in the chat of the session; an empty messageID
+// pins the last message sent by the bot.
+func (a *AgentMessageSender) PinMessage(userID, channelType, sessionID, messageID string, timeout time.Duration) (*agent.MessageResult, error) {
+	event := bus.NewPinMessage(bus.ChannelType(channelType), userID, sessionID, messageID, uuid.New().String(), nil)
+	return a.publishAndWait(event, "pin message", timeout)
+}
+
+// UnpinMessage unpins a message in the chat of the session; an empty
+// messageID unpins the most recently pinned message.
+func (a *AgentMessageSender) UnpinMessage(userID, channelType, sessionID, messageID string, timeout time.Duration) (*agent.MessageResult, error) {
+	event := bus.NewUnpinMessage(bus.ChannelType(channelType), userID, sessionID, messageID, uuid.New().String(), nil)
+	return a.publishAndWait(event, "unpin message", timeout)
+}
+
+// SetChatTitle renames the chat of the session.
+func (a *AgentMessageSender) SetChatTitle(userID, channelType, sessionID, title string, timeout time.Duration) (*agent.MessageResult, error) {
+	event := bus.NewSetChatTitleMessage(bus.ChannelType(channelType), userID, sessionID, title, uuid.New().String(), nil)
+	return a.publishAndWait(event, "set chat title", timeout)
+}
+
+// publishAndWait publishes an outbound message and waits for its result;
+// kind names the message in logs and errors.
+func (a *AgentMessageSender) publishAndWait(event *bus.OutboundMessage, kind string, timeout time.Duration) (*agent.MessageResult, error) {
+	// Use default timeout of 5 seconds if not provided
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	// The message is published again with the same key if its result doesn't
+	// arrive in time; the connector skips it if the first one was sent
+	if event.IdempotencyKey == "" {
+		event.IdempotencyKey = event.CorrelationID
+	}
+
+	tracker := a.messageBus.GetResultTracker()
+	for attempt := 1; ; attempt++ {
+		resultCh := tracker.Register(event.CorrelationID)
+		if err := a.publishAsync(event, kind); err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		select {
+		case result := <-resultCh:
+			cancel()
+			a.logger.DebugCtx(context.Background(), kind+" result received",
+				logger.Field{Key: "correlation_id", Value: event.CorrelationID},
+				logger.Field{Key: "success", Value: result.Success},
+				logger.Field{Key: "duplicate", Value: result.Duplicate})
+			return &agent.MessageResult{
+				Success:       result.Success,
+				Error:         result.Error,
+				CorrelationID: event.CorrelationID,
+				MessageID:     result.MessageID,
+			}, nil
+		case <-ctx.Done():
+			cancel()
+			if attempt < sendAttempts {
+				a.logger.WarnCtx(context.Background(), "timeout waiting for "+kind+" result, publishing again",
+					logger.Field{Key: "correlation_id", Value: event.CorrelationID},
+					logger.Field{Key: "timeout", Value: timeout})
+				continue
+			}
+			a.logger.ErrorCtx(context.Background(), "timeout waiting for "+kind+" result", ctx.Err(),
+				logger.Field{Key: "correlation_id", Value: event.CorrelationID},
+				logger.Field{Key: "timeout", Value: timeout})
+			return nil, fmt.Errorf("timeout waiting for %s result: %w", kind, ctx.Err())
+		}
+	}
+}
+
+// publishAsync publishes an outbound message without waiting for its result.
+func (a *AgentMessageSender) publishAsync(event *bus.OutboundMessage, kind string) error {
+	if err := a.messageBus.PublishOutbound(*event); err != nil {
+		a.logger.ErrorCtx(context.Background(), "failed to publish "+kind, err,
+			logger.Field{Key: "user_id", Value: event.UserID},
+			logger.Field{Key: "channel_type", Value: event.ChannelType})
+		return fmt.Errorf("failed to publish %s: %w", kind, err)
+	}
+	return nil
+}
+
 var _ agent.MessageSender = (*AgentMessageSender)(nil) // Compile-time interface check
@@ -0,0 +1,259 @@
+package loop
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aatumaykin/nexbot/internal/agent/planner"
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+const (
+	// planToolName is the built-in tool the LLM uses to report plan progress
+	planToolName = "update_plan"
+
+	planNotePrompt = "[Plan] You are working through this plan:\n%s\n" +
+		"Work on the current step [>] only (%d tool round(s) left for it). " +
+		"Call update_plan with action \"complete\" when it is done, or \"revise\" if the remaining steps need to change."
+
+	planStepExhaustedPrompt = "[Plan] The current step has no tool rounds left. " +
+		"Call update_plan to complete it with what you have, or revise the plan."
+
+	planDonePrompt = "[Plan] All steps are done:\n%s\nReply to the user with the result."
+)
+
+// planKey is the context key of the plan state of a request
+type planKey struct{}
+
+// planState tracks the execution of a plan within one request.
+type planState struct {
+	mu        sync.Mutex
+	loop      *Loop
+	sessionID string
+	plan      *planner.Plan
+	spent     int // Tool rounds spent on the current step
+}
+
+// startPlan asks the planner for a plan of a multi-step request and stores
+// it in the session. Returns nil when planning is disabled, the request is
+// too short or the planner decided no plan is needed.
+func (l *Loop) startPlan(ctx stdcontext.Context, sessionID, message string) *planState {
+	if l.config.Planner == nil || !l.config.Planner.ShouldPlan(message) {
+		return nil
+	}
+
+	history, err := l.sessionOps.GetSessionHistory(ctx, sessionID)
+	if err != nil {
+		l.logger.WarnCtx(ctx, "Failed to read session for planning",
+			logger.Field{Key: "error", Value: err.Error()})
+		return nil
+	}
+
+	var toolNames []string
+	for _, schema := range l.tools.ToSchema() {
+		if schema.Name != planToolName {
+			toolNames = append(toolNames, schema.Name)
+		}
+	}
+
+	plan, err := l.config.Planner.Plan(ctx, l.requestModel(ctx), message, conversationContext(history), toolNames)
+	if err != nil {
+		l.logger.WarnCtx(ctx, "Planning failed, executing without a plan",
+			logger.Field{Key: "error", Value: err.Error()})
+		return nil
+	}
+	if plan == nil {
+		return nil
+	}
+
+	state := &planState{loop: l, sessionID: sessionID, plan: plan}
+	state.save(ctx)
+	l.logger.InfoCtx(ctx, "Request planned",
+		logger.Field{Key: "steps", Value: len(plan.Steps)},
+		logger.Field{Key: "goal", Value: plan.Goal})
+	return state
+}
+
+// save stores the plan in the session metadata.
+func (s *planState) save(ctx stdcontext.Context) {
+	sess, err := s.loop.sessionMgr.Get(s.sessionID)
+	if err == nil {
+		var meta session.Meta
+		if meta, err = sess.ReadMeta(); err == nil {
+			meta.Plan = s.plan
+			err = sess.WriteMeta(meta)
+		}
+	}
+	if err != nil {
+		s.loop.logger.WarnCtx(ctx, "Failed to save plan",
+			logger.Field{Key: "error", Value: err.Error()})
+	}
+}
+
+// stepBudget returns the tool rounds left for the current step.
+// Caller must hold s.mu.
+func (s *planState) stepBudget() int {
+	current := s.plan.Current()
+	if current < 0 {
+		return 0
+	}
+	return s.plan.Steps[current].MaxIterations - s.spent
+}
+
+// note returns the plan reminder added to every LLM request.
+func (s *planState) note() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.plan.Current() < 0 {
+		return fmt.Sprintf(planDonePrompt, s.plan.Render())
+	}
+	left := s.stepBudget()
+	if left <= 0 {
+		return planStepExhaustedPrompt
+	}
+	return fmt.Sprintf(planNotePrompt, s.plan.Render(), left)
+}
+
+// takeCalls denies tool calls other than update_plan once the current step
+// has used up its tool rounds, and counts the round otherwise.
+func (s *planState) takeCalls(calls []tools.ToolCall) ([]tools.ToolCall, map[string]tools.ToolResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exhausted := s.plan.Current() >= 0 && s.stepBudget() <= 0
+	allowed := make([]tools.ToolCall, 0, len(calls))
+	denied := make(map[string]tools.ToolResult)
+	working := false
+	for _, call := range calls {
+		if call.Name == planToolName {
+			allowed = append(allowed, call)
+			continue
+		}
+		if exhausted {
+			denied[call.ID] = tools.ToolResult{
+				ToolCallID: call.ID,
+				Error: &tools.ToolError{
+					Type:       tools.ErrorTypeRateLimit,
+					Code:       tools.ErrCodeBudgetExhausted,
+					Message:    "step budget exhausted: the current plan step has no tool rounds left",
+					Suggestion: "Call update_plan to complete the step or revise the plan",
+				},
+			}
+			continue
+		}
+		allowed = append(allowed, call)
+		working = true
+	}
+	if working {
+		s.spent++
+	}
+	return allowed, denied
+}
+
+// update applies an update_plan call.
+func (s *planState) update(ctx stdcontext.Context, args planToolArgs) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch args.Action {
+	case "complete":
+		if err := s.plan.Complete(args.Result); err != nil {
+			return "", err
+		}
+	case "revise":
+		if err := s.plan.Revise(args.Steps, s.loop.config.Planner.StepIterations()); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unknown action: %s (expected: complete, revise)", args.Action)
+	}
+	s.spent = 0
+	s.save(ctx)
+
+	s.loop.logger.InfoCtx(ctx, "Plan updated",
+		logger.Field{Key: "action", Value: args.Action},
+		logger.Field{Key: "current_step", Value: s.plan.Current() + 1})
+
+	if s.plan.Current() < 0 {
+		return "All steps are done. Reply to the user with the result.\n\n" + s.plan.Render(), nil
+	}
+	return "Plan updated. Continue with the current step [>].\n\n" + s.plan.Render(), nil
+}
+
+// planToolArgs are the arguments of update_plan.
+type planToolArgs struct {
+	Action string         `json:"action"`
+	Result string         `json:"result,omitempty"`
+	Steps  []planner.Step `json:"steps,omitempty"`
+}
+
+// planTool lets the LLM complete plan steps and revise the plan.
+type planTool struct{}
+
+// Name returns the tool name.
+func (planTool) Name() string {
+	return planToolName
+}
+
+// ReadOnly reports that the tool only changes the plan, so it runs in dry-run mode.
+func (planTool) ReadOnly() bool {
+	return true
+}
+
+// Description returns a description of what the tool does.
+func (planTool) Description() string {
+	return "Report progress on the plan of the current request: complete the current step or revise the remaining steps. " +
+		"Only available while a plan is shown."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (planTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"complete", "revise"},
+				"description": "complete: the current step is done; revise: replace the steps that are not done yet",
+			},
+			"result": map[string]any{
+				"type":        "string",
+				"description": "Short outcome of the completed step (for complete)",
+			},
+			"steps": map[string]any{
+				"type":        "array",
+				"description": "New remaining steps (for revise)",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"title":          map[string]any{"type": "string"},
+						"tools":          map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+						"max_iterations": map[string]any{"type": "integer"},
+					},
+					"required": []string{"title"},
+				},
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+// Execute applies the update to the plan of the request running with ctx.
+func (planTool) Execute(ctx stdcontext.Context, args string) (string, error) {
+	state, ok := ctx.Value(planKey{}).(*planState)
+	if !ok || state == nil {
+		return "", fmt.Errorf("this request has no plan")
+	}
+	var params planToolArgs
+	if err := json.Unmarshal([]byte(args), &params); err != nil {
+		return "", fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	params.Action = strings.ToLower(strings.TrimSpace(params.Action))
+	return state.update(ctx, params)
+}
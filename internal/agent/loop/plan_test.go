@@ -0,0 +1,180 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/agent/planner"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// twoStepPlan is a planner reply with two steps of one tool round each.
+const twoStepPlan = `{"goal": "Report on a.txt", "steps": [` +
+	`{"title": "Read a.txt", "tools": ["read"], "max_iterations": 1}, ` +
+	`{"title": "Summarize", "max_iterations": 1}]}`
+
+// failingTool is a test tool that always fails.
+type failingTool struct {
+	recordingTool
+}
+
+func (f *failingTool) Execute(ctx context.Context, args string) (string, error) {
+	f.calls = append(f.calls, args)
+	return "", errors.New("file is locked")
+}
+
+// newPlanLoop returns a loop planning every request with the responses of
+// provider, the first of which is the plan.
+func newPlanLoop(t *testing.T, provider *mockToolCallProvider, tool tools.Tool) *Loop {
+	t.Helper()
+	looper := newTestLoop(t, Config{
+		LLMProvider: provider,
+		Planner:     planner.New(provider, planner.Config{MinChars: -1}),
+	})
+	if err := looper.RegisterTool(tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+	return looper
+}
+
+// savedPlan returns the plan stored in the session metadata.
+func savedPlan(t *testing.T, looper *Loop, sessionID string) *planner.Plan {
+	t.Helper()
+	sess, err := looper.sessionMgr.Get(sessionID)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	meta, err := sess.ReadMeta()
+	if err != nil {
+		t.Fatalf("Failed to read session metadata: %v", err)
+	}
+	if meta.Plan == nil {
+		t.Fatal("Expected the plan to be saved in the session metadata")
+	}
+	return meta.Plan
+}
+
+// planNote returns the plan reminder of a request.
+func planNote(req llm.ChatRequest) string {
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if msg := req.Messages[i]; msg.Role == llm.RoleSystem && strings.HasPrefix(msg.Content, "[Plan]") {
+			return msg.Content
+		}
+	}
+	return ""
+}
+
+func TestLoop_Plan_Persisted(t *testing.T) {
+	provider := &mockToolCallProvider{responses: []llm.ChatResponse{
+		textResponse(twoStepPlan),
+		toolCallResponse("call_1", "read", `{"path":"a.txt"}`),
+		toolCallResponse("call_2", planToolName, `{"action":"complete","result":"read it"}`),
+		toolCallResponse("call_3", planToolName, `{"action":"complete","result":"summarized"}`),
+		textResponse("done"),
+	}}
+	tool := &recordingTool{name: "read", result: "content"}
+	looper := newPlanLoop(t, provider, tool)
+
+	answer, err := looper.Process(context.Background(), "plan", "Read a.txt and summarize it")
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if answer != "done" {
+		t.Errorf("Expected the final answer, got %q", answer)
+	}
+	if len(tool.calls) != 1 {
+		t.Errorf("Expected 1 read call, got %d", len(tool.calls))
+	}
+
+	// Every loop request shows the plan with its current step
+	if note := planNote(provider.requests[1]); !strings.Contains(note, "[>] 1. Read a.txt") {
+		t.Errorf("Expected the first step to be current, got note %q", note)
+	}
+	if note := planNote(provider.requests[3]); !strings.Contains(note, "[x] 1. Read a.txt (tools: read) — read it") || !strings.Contains(note, "[>] 2. Summarize") {
+		t.Errorf("Expected the second step to be current, got note %q", note)
+	}
+	if note := planNote(provider.requests[4]); !strings.Contains(note, "All steps are done") {
+		t.Errorf("Expected the done note, got %q", note)
+	}
+
+	plan := savedPlan(t, looper, "plan")
+	if plan.Request != "Read a.txt and summarize it" || plan.Current() != -1 {
+		t.Errorf("Expected the saved plan to be done, got current step %d for %q", plan.Current(), plan.Request)
+	}
+	if plan.Steps[0].Result != "read it" || plan.Steps[1].Result != "summarized" {
+		t.Errorf("Expected the step results to be saved, got %+v", plan.Steps)
+	}
+}
+
+func TestLoop_Plan_StepBudget(t *testing.T) {
+	provider := &mockToolCallProvider{responses: []llm.ChatResponse{
+		textResponse(twoStepPlan),
+		toolCallResponse("call_1", "read", `{"path":"a.txt"}`),
+		toolCallResponse("call_2", "read", `{"path":"b.txt"}`),
+		toolCallResponse("call_3", planToolName, `{"action":"complete","result":"read a.txt only"}`),
+		toolCallResponse("call_4", "read", `{"path":"c.txt"}`),
+		textResponse("done"),
+	}}
+	tool := &recordingTool{name: "read", result: "content"}
+	looper := newPlanLoop(t, provider, tool)
+
+	if _, err := looper.Process(context.Background(), "plan", "Read a.txt and summarize it"); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	// The second read exceeds the single round of the first step
+	if len(tool.calls) != 2 || tool.calls[0] != `{"path":"a.txt"}` || tool.calls[1] != `{"path":"c.txt"}` {
+		t.Errorf("Expected reads of a.txt and c.txt, got %v", tool.calls)
+	}
+	if note := planNote(provider.requests[2]); note != planStepExhaustedPrompt {
+		t.Errorf("Expected the step exhausted note, got %q", note)
+	}
+	if got := lastToolMessage(t, provider.requests[3]); !strings.Contains(got, "step budget exhausted") {
+		t.Errorf("Expected the read to be denied, got %q", got)
+	}
+
+	// Completing the step gives the next step its own round
+	if note := planNote(provider.requests[4]); !strings.Contains(note, "[>] 2. Summarize") || !strings.Contains(note, "1 tool round(s) left") {
+		t.Errorf("Expected a fresh budget for the second step, got note %q", note)
+	}
+}
+
+func TestLoop_Plan_ReviseAfterFailedStep(t *testing.T) {
+	provider := &mockToolCallProvider{responses: []llm.ChatResponse{
+		textResponse(twoStepPlan),
+		toolCallResponse("call_1", "read", `{"path":"a.txt"}`),
+		toolCallResponse("call_2", planToolName,
+			`{"action":"revise","steps":[{"title":"Read the backup of a.txt","tools":["read"],"max_iterations":1},{"title":"Summarize"}]}`),
+		toolCallResponse("call_3", "read", `{"path":"a.txt.bak"}`),
+		textResponse("done"),
+	}}
+	tool := &failingTool{recordingTool{name: "read"}}
+	looper := newPlanLoop(t, provider, tool)
+
+	if _, err := looper.Process(context.Background(), "plan", "Read a.txt and summarize it"); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if got := lastToolMessage(t, provider.requests[2]); !strings.Contains(got, "file is locked") {
+		t.Errorf("Expected the failed read as the tool result, got %q", got)
+	}
+
+	// The revised step is current with a fresh budget: its read is allowed
+	if note := planNote(provider.requests[3]); !strings.Contains(note, "[>] 1. Read the backup of a.txt") || !strings.Contains(note, "1 tool round(s) left") {
+		t.Errorf("Expected the revised step to be current, got note %q", note)
+	}
+	if len(tool.calls) != 2 {
+		t.Errorf("Expected the read of the revised step to run, got %d calls", len(tool.calls))
+	}
+
+	plan := savedPlan(t, looper, "plan")
+	if plan.Revisions != 1 || len(plan.Steps) != 2 || plan.Steps[0].Title != "Read the backup of a.txt" {
+		t.Errorf("Expected the revised plan to be saved, got %d revisions, steps %+v", plan.Revisions, plan.Steps)
+	}
+	if plan.Steps[1].MaxIterations != planner.DefaultStepIterations {
+		t.Errorf("Expected revised steps without a budget to get the default, got %d", plan.Steps[1].MaxIterations)
+	}
+}
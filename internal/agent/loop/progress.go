@@ -0,0 +1,31 @@
+package loop
+
+import (
+	stdcontext "context"
+
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// ProgressFunc receives progress reports of the tools running for a request.
+type ProgressFunc func(tool string, p tools.Progress)
+
+// progressKey is the context key of the progress function of a request
+type progressKey struct{}
+
+// WithProgress returns a context that delivers progress reports of the tools
+// of a request to fn.
+func WithProgress(ctx stdcontext.Context, fn ProgressFunc) stdcontext.Context {
+	return stdcontext.WithValue(ctx, progressKey{}, fn)
+}
+
+// toolProgress returns the context for running a tool call, which forwards
+// progress reports of the tool to the progress function of the request.
+func toolProgress(ctx stdcontext.Context, toolName string) stdcontext.Context {
+	fn, ok := ctx.Value(progressKey{}).(ProgressFunc)
+	if !ok || fn == nil {
+		return ctx
+	}
+	return tools.WithProgress(ctx, func(p tools.Progress) {
+		fn(toolName, p)
+	})
+}
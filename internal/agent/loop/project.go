@@ -0,0 +1,100 @@
+package loop
+
+import (
+	stdcontext "context"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/projects"
+)
+
+const (
+	// briefTimeout limits the LLM call that updates a project brief
+	briefTimeout = 60 * time.Second
+
+	// briefContextMessages is the number of latest messages sent for a brief update
+	briefContextMessages = 30
+)
+
+// maybeUpdateBrief updates the brief of the session's project in the
+// background once ProjectBriefTurns user messages were sent since its last update.
+func (l *Loop) maybeUpdateBrief(sessionID string) {
+	if l.config.Projects == nil || l.config.ProjectBriefTurns <= 0 {
+		return
+	}
+	project, ok := l.config.Projects.ForSession(sessionID)
+	if !ok {
+		return
+	}
+	if _, running := l.briefing.LoadOrStore(project.Name, struct{}{}); running {
+		return
+	}
+
+	go func() {
+		defer l.briefing.Delete(project.Name)
+
+		if err := l.updateBrief(sessionID, project); err != nil {
+			l.logger.Warn("Failed to update project brief",
+				logger.Field{Key: "session_id", Value: sessionID},
+				logger.Field{Key: "project", Value: project.Name},
+				logger.Field{Key: "error", Value: err.Error()})
+		}
+	}()
+}
+
+// updateBrief asks the LLM to merge the messages sent since the last update
+// into the project brief.
+func (l *Loop) updateBrief(sessionID string, project projects.Project) error {
+	sess, err := l.sessionMgr.Get(sessionID)
+	if err != nil {
+		return err
+	}
+	entries, err := sess.ReadEntries()
+	if err != nil {
+		return err
+	}
+
+	var messages []llm.Message
+	turns := 0
+	for _, entry := range entries {
+		if !project.BriefUpdatedAt.IsZero() {
+			ts, err := time.Parse(time.RFC3339, entry.Timestamp)
+			if err != nil || !ts.After(project.BriefUpdatedAt) {
+				continue
+			}
+		}
+		if entry.Message.Role == llm.RoleUser {
+			turns++
+		}
+		messages = append(messages, entry.Message)
+	}
+	if turns < l.config.ProjectBriefTurns {
+		return nil
+	}
+	if len(messages) > briefContextMessages {
+		messages = messages[len(messages)-briefContextMessages:]
+	}
+
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), briefTimeout)
+	defer cancel()
+
+	resp, err := l.provider.Chat(ctx, projects.BriefRequest(project, messages, l.config.Model, l.config.ProjectBriefChars))
+	if err != nil {
+		return err
+	}
+
+	brief := projects.CleanBrief(resp.Content, l.config.ProjectBriefChars)
+	if brief == "" {
+		return nil
+	}
+	if err := l.config.Projects.SetBrief(project.Name, brief); err != nil {
+		return err
+	}
+
+	l.logger.Info("Project brief updated",
+		logger.Field{Key: "session_id", Value: sessionID},
+		logger.Field{Key: "project", Value: project.Name},
+		logger.Field{Key: "brief_length", Value: len(brief)})
+	return nil
+}
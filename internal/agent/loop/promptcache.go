@@ -0,0 +1,53 @@
+package loop
+
+import (
+	stdcontext "context"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// promptKey is the context key of the system prompt cached for a request
+type promptKey struct{}
+
+// requestPrompt is the system prompt built once per request
+type requestPrompt struct {
+	content string
+	built   bool
+}
+
+// startPromptCache prepares a request for provider-side prompt caching. The
+// context is returned unchanged when prompt caching is disabled.
+func (l *Loop) startPromptCache(ctx stdcontext.Context) stdcontext.Context {
+	if !l.config.PromptCache {
+		return ctx
+	}
+	return stdcontext.WithValue(ctx, promptKey{}, &requestPrompt{})
+}
+
+// systemMessage returns the system prompt for an LLM call, or false if none
+// is sent. Without prompt caching the system prompt is sent on the first
+// iteration only. With caching it is built once per request (templates like
+// {{CURRENT_TIME}} would otherwise change the prefix) and resent on every
+// iteration, marked as a cacheable prefix.
+func (l *Loop) systemMessage(ctx stdcontext.Context, sessionID string, iteration int) (llm.Message, bool) {
+	cached, ok := ctx.Value(promptKey{}).(*requestPrompt)
+	if !ok && iteration > 0 {
+		return llm.Message{}, false
+	}
+
+	if !ok || !cached.built {
+		systemPrompt, err := l.buildSystemPrompt(sessionID)
+		if err != nil {
+			l.logger.WarnCtx(ctx, "Failed to build system prompt",
+				logger.Field{Key: "error", Value: err.Error()})
+			return llm.Message{}, false
+		}
+		if !ok {
+			return llm.Message{Role: llm.RoleSystem, Content: systemPrompt}, systemPrompt != ""
+		}
+		cached.content, cached.built = systemPrompt, true
+	}
+
+	return llm.Message{Role: llm.RoleSystem, Content: cached.content, Cache: true}, cached.content != ""
+}
@@ -0,0 +1,89 @@
+package loop
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/textutil"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// defaultToolRepairAttempts is the number of times the LLM is asked to fix
+// malformed tool calls before the request fails
+const defaultToolRepairAttempts = 2
+
+// maxRepairArgumentChars limits the malformed arguments quoted back to the LLM
+const maxRepairArgumentChars = 500
+
+// ErrMalformedToolCalls is returned when the LLM keeps sending tool calls
+// whose arguments don't match the tool schemas.
+var ErrMalformedToolCalls = errors.New("LLM returned malformed tool calls")
+
+// toolCallProblems validates the arguments of tool calls against the tool
+// schemas with the same check the registry runs before dispatch, so that
+// no call of a response runs while another one needs a repair. Returns a
+// description of each invalid call; calls of unknown tools are left to the
+// tool executor.
+func (l *Loop) toolCallProblems(calls []llm.ToolCall) []string {
+	var problems []string
+	for _, call := range calls {
+		tool, ok := l.tools.Get(call.Name)
+		if !ok {
+			continue
+		}
+		if found := tools.ValidateArguments(tool, call.Arguments); len(found) > 0 {
+			problems = append(problems, fmt.Sprintf("%s(%s): %s",
+				call.Name, textutil.Truncate(call.Arguments, maxRepairArgumentChars), strings.Join(found, "; ")))
+		}
+	}
+	return problems
+}
+
+// repairToolCalls asks the LLM to fix tool calls with invalid arguments:
+// the request is repeated with the validation errors appended (not
+// persisted) until the calls are valid or the repair attempts run out.
+func (l *Loop) repairToolCalls(ctx stdcontext.Context, sessionID string, iteration int, req llm.ChatRequest, resp *llm.ChatResponse) (*llm.ChatResponse, error) {
+	if l.config.ToolRepairAttempts < 0 {
+		return resp, nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		if resp.FinishReason != llm.FinishReasonToolCalls || len(resp.ToolCalls) == 0 {
+			return resp, nil
+		}
+		problems := l.toolCallProblems(resp.ToolCalls)
+		if len(problems) == 0 {
+			return resp, nil
+		}
+		if attempt > l.config.ToolRepairAttempts {
+			return nil, fmt.Errorf("%w after %d repair attempts: %s",
+				ErrMalformedToolCalls, l.config.ToolRepairAttempts, strings.Join(problems, "; "))
+		}
+
+		l.logger.WarnCtx(ctx, "Asking the LLM to repair malformed tool calls",
+			logger.Field{Key: "attempt", Value: attempt},
+			logger.Field{Key: "problems", Value: problems})
+
+		retry := req
+		retry.Messages = append(append([]llm.Message(nil), req.Messages...), llm.Message{
+			Role:    llm.RoleSystem,
+			Content: repairNote(problems),
+		})
+		var err error
+		if resp, err = l.chat(ctx, retry); err != nil {
+			return nil, fmt.Errorf("LLM call failed: %w", err)
+		}
+		l.captureResponse(ctx, sessionID, iteration, retry, *resp)
+	}
+}
+
+// repairNote asks the LLM to repeat its tool calls with valid arguments.
+func repairNote(problems []string) string {
+	return "Your previous tool calls were not executed because their arguments are invalid:\n- " +
+		strings.Join(problems, "\n- ") +
+		"\nCall the tools again with arguments that are a valid JSON object matching each tool's parameters schema."
+}
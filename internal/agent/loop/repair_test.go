@@ -0,0 +1,125 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+func TestLoop_RepairToolCalls(t *testing.T) {
+	provider := &mockToolCallProvider{responses: []llm.ChatResponse{
+		toolCallResponse("call_1", "read", `{}`),
+		toolCallResponse("call_2", "read", `{"path":"a.txt"}`),
+		textResponse("done"),
+	}}
+	looper := newTestLoop(t, Config{LLMProvider: provider})
+	tool := &recordingTool{name: "read", result: "content"}
+	if err := looper.RegisterTool(tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	answer, err := looper.Process(context.Background(), "repair", "Read a.txt")
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if answer != "done" {
+		t.Errorf("Expected the final answer, got %q", answer)
+	}
+	if len(tool.calls) != 1 || tool.calls[0] != `{"path":"a.txt"}` {
+		t.Errorf("Expected only the repaired call to run, got %v", tool.calls)
+	}
+
+	// The retry repeats the request with the problems appended
+	if len(provider.requests) != 3 {
+		t.Fatalf("Expected 3 LLM requests, got %d", len(provider.requests))
+	}
+	retry := provider.requests[1].Messages
+	note := retry[len(retry)-1]
+	if note.Role != llm.RoleSystem || !strings.Contains(note.Content, "not executed") || !strings.Contains(note.Content, `"path"`) {
+		t.Errorf("Expected a repair note, got %+v", note)
+	}
+
+	// Neither the malformed call nor the note is saved
+	history, _ := looper.GetSessionHistory(context.Background(), "repair")
+	for _, msg := range history {
+		if strings.Contains(msg.Content, "not executed") {
+			t.Errorf("Repair note saved in history: %+v", msg)
+		}
+		for _, call := range msg.ToolCalls {
+			if call.ID == "call_1" {
+				t.Errorf("Malformed call saved in history: %+v", msg)
+			}
+		}
+	}
+}
+
+func TestLoop_RepairToolCalls_AttemptsExhausted(t *testing.T) {
+	provider := &mockToolCallProvider{responses: []llm.ChatResponse{
+		toolCallResponse("call_1", "read", `{}`),
+		toolCallResponse("call_2", "read", `{"path":`),
+		textResponse("unreachable"),
+	}}
+	looper := newTestLoop(t, Config{LLMProvider: provider, ToolRepairAttempts: 1})
+	tool := &recordingTool{name: "read"}
+	if err := looper.RegisterTool(tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := looper.sessionOps.AddMessageToSession(ctx, "exhausted", llm.Message{Role: llm.RoleUser, Content: "Read a.txt"}); err != nil {
+		t.Fatalf("Failed to add message: %v", err)
+	}
+	_, err := looper.processWithToolCalling(ctx, "exhausted", 0, looper.newRequestBudget())
+	if !errors.Is(err, ErrMalformedToolCalls) {
+		t.Fatalf("Expected ErrMalformedToolCalls, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `read({"path":)`) {
+		t.Errorf("Expected the last problems in the error, got %v", err)
+	}
+	if len(provider.requests) != 2 || len(tool.calls) != 0 {
+		t.Errorf("Expected 2 requests and no tool runs, got %d requests and %d runs", len(provider.requests), len(tool.calls))
+	}
+}
+
+func TestLoop_RepairToolCalls_Disabled(t *testing.T) {
+	provider := &mockToolCallProvider{responses: []llm.ChatResponse{
+		toolCallResponse("call_1", "read", `{}`),
+		textResponse("done"),
+	}}
+	looper := newTestLoop(t, Config{LLMProvider: provider, ToolRepairAttempts: -1})
+	tool := &recordingTool{name: "read"}
+	if err := looper.RegisterTool(tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	answer, err := looper.Process(context.Background(), "disabled", "Read a.txt")
+	if err != nil || answer != "done" {
+		t.Fatalf("Process() = %q, %v", answer, err)
+	}
+
+	// No repair request: the registry rejects the call and the LLM gets the
+	// problems as the tool result
+	if len(provider.requests) != 2 {
+		t.Fatalf("Expected 2 LLM requests, got %d", len(provider.requests))
+	}
+	for _, msg := range provider.requests[1].Messages {
+		if msg.Role == llm.RoleSystem && strings.Contains(msg.Content, "not executed") {
+			t.Errorf("Expected no repair note, got %q", msg.Content)
+		}
+	}
+	var result *llm.Message
+	for i, msg := range provider.requests[1].Messages {
+		if msg.Role == llm.RoleTool && msg.ToolCallID == "call_1" {
+			result = &provider.requests[1].Messages[i]
+		}
+	}
+	if result == nil || !strings.Contains(result.Content, `"path"`) {
+		t.Errorf("Expected the validation problems as the tool result, got %+v", result)
+	}
+	if len(tool.calls) != 0 {
+		t.Errorf("Expected the invalid call not to run, got %v", tool.calls)
+	}
+}
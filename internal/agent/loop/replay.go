@@ -0,0 +1,40 @@
+package loop
+
+import (
+	"context"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// SetRecorder sets the recorder of tool results for reproducible runs. In
+// replay mode tools are not run: the recorded results are returned.
+func (te *ToolExecutor) SetRecorder(recorder *tools.ToolRecorder) {
+	te.recorder = recorder
+}
+
+// runToolCall runs a tool call, or returns its recorded result when replaying.
+func (te *ToolExecutor) runToolCall(ctx context.Context, toolCall tools.ToolCall, cfg *tools.ExecutionConfig) tools.ToolResult {
+	if te.recorder != nil && te.recorder.Replaying() {
+		result, err := te.recorder.Load(toolCall)
+		if err != nil {
+			te.logger.WarnCtx(ctx, "No recorded tool result to replay",
+				logger.Field{Key: "error", Value: err.Error()})
+			return tools.ToolResult{
+				ToolCallID: toolCall.ID,
+				Error: tools.NewNotFoundError("recording_not_found", err.Error(),
+					"The run diverged from the recording; record it again"),
+			}
+		}
+		return result
+	}
+
+	result, _ := tools.ExecuteToolCallWithContext(te.tools, toolCall, toolProgress(ctx, toolCall.Name), cfg)
+	if te.recorder != nil {
+		if err := te.recorder.Save(toolCall, result); err != nil {
+			te.logger.WarnCtx(ctx, "Failed to record tool result",
+				logger.Field{Key: "error", Value: err.Error()})
+		}
+	}
+	return result
+}
@@ -0,0 +1,80 @@
+package loop
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// runRecorded processes a message calling the read tool with the given
+// recorder and returns the tool and the requests sent to the provider.
+func runRecorded(t *testing.T, recorder *tools.ToolRecorder, result string) (*recordingTool, []llm.ChatRequest) {
+	t.Helper()
+	provider := &mockToolCallProvider{responses: []llm.ChatResponse{
+		toolCallResponse("call_1", "read", `{"path":"a.txt"}`),
+		textResponse("done"),
+	}}
+	looper := newTestLoop(t, Config{LLMProvider: provider, ToolRecorder: recorder})
+	tool := &recordingTool{name: "read", result: result}
+	if err := looper.RegisterTool(tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+	if _, err := looper.Process(context.Background(), "replay", "Read a.txt"); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	return tool, provider.requests
+}
+
+// lastToolMessage returns the content of the last tool message of a request.
+func lastToolMessage(t *testing.T, req llm.ChatRequest) string {
+	t.Helper()
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == llm.RoleTool {
+			return req.Messages[i].Content
+		}
+	}
+	t.Fatal("Expected a tool message in the request")
+	return ""
+}
+
+func TestLoop_ReplayToolResults(t *testing.T) {
+	dir := t.TempDir()
+
+	tool, requests := runRecorded(t, tools.NewToolRecorder(dir, false), "recorded content")
+	if len(tool.calls) != 1 {
+		t.Fatalf("Expected the tool to run while recording, got %d calls", len(tool.calls))
+	}
+	if len(requests) != 2 || !strings.Contains(lastToolMessage(t, requests[1]), "recorded content") {
+		t.Fatalf("Expected the tool result in the second request, got %d requests", len(requests))
+	}
+
+	// Replaying returns the recorded result without running the tool
+	tool, requests = runRecorded(t, tools.NewToolRecorder(dir, true), "live content")
+	if len(tool.calls) != 0 {
+		t.Errorf("Expected the tool not to run while replaying, got %d calls", len(tool.calls))
+	}
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(requests))
+	}
+	if got := lastToolMessage(t, requests[1]); !strings.Contains(got, "recorded content") {
+		t.Errorf("Expected the recorded tool result, got %q", got)
+	}
+}
+
+func TestLoop_ReplayToolResults_NotRecorded(t *testing.T) {
+	tool, requests := runRecorded(t, tools.NewToolRecorder(filepath.Join(t.TempDir(), "empty"), true), "live content")
+	if len(tool.calls) != 0 {
+		t.Errorf("Expected the tool not to run while replaying, got %d calls", len(tool.calls))
+	}
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(requests))
+	}
+	got := lastToolMessage(t, requests[1])
+	if strings.Contains(got, "live content") || !strings.Contains(got, "record it again") {
+		t.Errorf("Expected a recording-not-found error as the tool result, got %q", got)
+	}
+}
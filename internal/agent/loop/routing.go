@@ -0,0 +1,50 @@
+package loop
+
+import (
+	stdcontext "context"
+
+	"github.com/aatumaykin/nexbot/internal/agent/routing"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// routeKey is the context key of the model route of a request
+type routeKey struct{}
+
+// startRoute picks the model for a new request. The context is returned
+// unchanged when model routing is disabled.
+func (l *Loop) startRoute(ctx stdcontext.Context, sessionID, message string) stdcontext.Context {
+	if l.config.Router == nil {
+		return ctx
+	}
+	route := l.config.Router.Start(message)
+	l.logger.InfoCtx(ctx, "Model routed",
+		logger.Field{Key: "model", Value: route.Model()},
+		logger.Field{Key: "reason", Value: route.Reason()})
+	return stdcontext.WithValue(ctx, routeKey{}, route)
+}
+
+// requestModel returns the model for the next LLM call of the request.
+func (l *Loop) requestModel(ctx stdcontext.Context) string {
+	if route, ok := ctx.Value(routeKey{}).(*routing.Route); ok {
+		return route.Model()
+	}
+	return l.config.Model
+}
+
+// observeTools lets the route escalate the request after a tool iteration.
+func (l *Loop) observeTools(ctx stdcontext.Context, sessionID string, calls []tools.ToolCall) {
+	route, ok := ctx.Value(routeKey{}).(*routing.Route)
+	if !ok {
+		return
+	}
+	names := make([]string, len(calls))
+	for i, call := range calls {
+		names[i] = call.Name
+	}
+	if route.ObserveTools(names) {
+		l.logger.InfoCtx(ctx, "Request escalated to strong model",
+			logger.Field{Key: "model", Value: route.Model()},
+			logger.Field{Key: "reason", Value: route.Reason()})
+	}
+}
@@ -0,0 +1,34 @@
+package loop
+
+import (
+	stdcontext "context"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// sessionSampling returns the sampling parameters of a session: the agent
+// settings, overridden by the prompt variant serving the session and then by
+// the overrides stored with the session. Deterministic runs always use
+// temperature 0 and the configured seed.
+func (l *Loop) sessionSampling(ctx stdcontext.Context, sessionID string) llm.Sampling {
+	sampling := l.config.Sampling
+	if l.config.Deterministic {
+		sampling.Temperature = 0
+		return sampling
+	}
+	sampling.Temperature = l.config.Temperature
+
+	if variant, ok := l.sessionVariant(sessionID); ok {
+		sampling = sampling.Merge(variant.Sampling)
+	}
+
+	override, err := l.sessionMgr.Sampling(sessionID)
+	if err != nil {
+		l.logger.WarnCtx(ctx, "Failed to read session sampling overrides",
+			logger.Field{Key: "session_id", Value: sessionID},
+			logger.Field{Key: "error", Value: err.Error()})
+		return sampling
+	}
+	return sampling.Merge(override)
+}
@@ -21,26 +21,98 @@ func NewSessionOperations(sessionMgr *session.Manager) *SessionOperations {
 	}
 }
 
-// AddMessageToSession adds a message to the session history.
+// turnVersionKey is the context key of the session version of a turn
+type turnVersionKey struct{}
+
+// turnVersion is the version of the session history after the last write of
+// a turn.
+type turnVersion struct {
+	sessionID string
+	version   int64
+	messages  []llm.Message // Messages written by the turn, before scrubbing
+}
+
+// StartTurn returns a context whose writes to the session history fail with
+// session.ErrConflict if someone else changed the history during the turn.
+func (so *SessionOperations) StartTurn(ctx stdcontext.Context, sessionID string) (stdcontext.Context, error) {
+	sess, _, err := so.sessionMgr.GetOrCreate(sessionID)
+	if err != nil {
+		return ctx, fmt.Errorf("failed to get or create session: %w", err)
+	}
+	version, err := sess.Version()
+	if err != nil {
+		return ctx, err
+	}
+	return stdcontext.WithValue(ctx, turnVersionKey{}, &turnVersion{sessionID: sessionID, version: version}), nil
+}
+
+// AddMessageToSession adds a message to the session history. Within a turn
+// (see StartTurn) the write is checked against the turn's version.
 func (so *SessionOperations) AddMessageToSession(ctx stdcontext.Context, sessionID string, message llm.Message) error {
 	sess, _, err := so.sessionMgr.GetOrCreate(sessionID)
 	if err != nil {
 		return fmt.Errorf("failed to get or create session: %w", err)
 	}
-	return sess.Append(message)
+	turn, ok := ctx.Value(turnVersionKey{}).(*turnVersion)
+	if !ok || turn.sessionID != sessionID {
+		return sess.Append(message)
+	}
+	version, err := sess.AppendAt(turn.version, message)
+	if err != nil {
+		return err
+	}
+	turn.version = version
+	turn.messages = append(turn.messages, message)
+	return nil
 }
 
-// GetSessionHistory returns the message history for a session.
+// GetSessionHistory returns the message history for a session. Within a turn
+// the messages written by the turn are returned as written: the stored copy
+// is scrubbed (see session.Scrubber), but the turn still needs the original
+// text to do what the user asked.
 func (so *SessionOperations) GetSessionHistory(ctx stdcontext.Context, sessionID string) ([]llm.Message, error) {
 	sess, _, err := so.sessionMgr.GetOrCreate(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get or create session: %w", err)
 	}
-	return sess.Read()
+	history, err := sess.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	turn, ok := ctx.Value(turnVersionKey{}).(*turnVersion)
+	if !ok || turn.sessionID != sessionID || len(turn.messages) > len(history) {
+		return history, nil
+	}
+	// The turn's messages are the last ones only while nobody else wrote
+	if version, err := sess.Version(); err != nil || version != turn.version {
+		return history, nil
+	}
+	copy(history[len(history)-len(turn.messages):], turn.messages)
+	return history, nil
+}
+
+// GetSessionEntries returns the session entries (messages with timestamps).
+func (so *SessionOperations) GetSessionEntries(ctx stdcontext.Context, sessionID string) ([]session.Entry, error) {
+	sess, err := so.sessionMgr.Get(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return sess.ReadEntries()
 }
 
 // ClearSession clears all messages from a session.
+// A chat bound to a named session is detached instead, so the named session
+// is kept and the chat starts over in its own history.
 func (so *SessionOperations) ClearSession(ctx stdcontext.Context, sessionID string) error {
+	// A context of the chat is cleared in place; a named session is left
+	// and stays saved
+	if _, ok := so.sessionMgr.CurrentContext(sessionID); !ok {
+		if _, err := so.sessionMgr.Detach(sessionID); err != nil {
+			return fmt.Errorf("failed to detach named session: %w", err)
+		}
+	}
+
 	sess, _, err := so.sessionMgr.GetOrCreate(sessionID)
 	if err != nil {
 		return fmt.Errorf("failed to get or create session: %w", err)
@@ -48,6 +120,16 @@ func (so *SessionOperations) ClearSession(ctx stdcontext.Context, sessionID stri
 	return sess.Clear()
 }
 
+// SaveSessionAs moves the session history under a name and binds the chat to it.
+func (so *SessionOperations) SaveSessionAs(ctx stdcontext.Context, sessionID, name string) (string, error) {
+	return so.sessionMgr.SaveAs(sessionID, name)
+}
+
+// ResumeSession binds the chat to an existing named session.
+func (so *SessionOperations) ResumeSession(ctx stdcontext.Context, sessionID, name string) (string, error) {
+	return so.sessionMgr.Resume(sessionID, name)
+}
+
 // DeleteSession deletes a session entirely.
 func (so *SessionOperations) DeleteSession(ctx stdcontext.Context, sessionID string) error {
 	sess, _, err := so.sessionMgr.GetOrCreate(sessionID)
@@ -76,17 +158,50 @@ func (so *SessionOperations) GetSessionStatus(ctx stdcontext.Context, sessionID
 		fileSize = fileInfo.Size()
 	}
 
+	// Tools used while answering the latest user message
+	var lastTools []string
+	if history, err := sess.Read(); err == nil {
+		lastTools = lastTurnTools(history)
+	}
+
+	var title string
+	if meta, err := sess.ReadMeta(); err == nil {
+		title = meta.Title
+	}
+
 	return map[string]any{
 		"session_id":      sessionID,
+		"history_id":      sess.ID,
+		"title":           title,
 		"message_count":   msgCount,
 		"file_size":       fileSize,
 		"file_size_human": formatBytes(fileSize),
 		"model":           loop.config.Model,
-		"temperature":     loop.config.Temperature,
+		"temperature":     loop.sessionSampling(ctx, sessionID).Temperature,
 		"max_tokens":      loop.config.MaxTokens,
+		"last_tools":      lastTools,
 	}, nil
 }
 
+// lastTurnTools returns the names of tools called after the last user message.
+func lastTurnTools(history []llm.Message) []string {
+	start := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == llm.RoleUser {
+			start = i + 1
+			break
+		}
+	}
+
+	var names []string
+	for _, msg := range history[start:] {
+		for _, call := range msg.ToolCalls {
+			names = append(names, call.Name)
+		}
+	}
+	return names
+}
+
 // getFileInfo returns file information for a given path.
 func getFileInfo(path string) (os.FileInfo, error) {
 	return os.Stat(path)
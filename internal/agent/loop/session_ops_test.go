@@ -0,0 +1,137 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/pii"
+)
+
+// newSessionProvider starts a new session (as /new does) and writes its
+// first message while the LLM call of a turn is in flight.
+type newSessionProvider struct {
+	mockToolCallProvider
+	looper    *Loop
+	sessionID string
+}
+
+func (p *newSessionProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	if err := p.looper.ClearSession(context.Background(), p.sessionID); err != nil {
+		return nil, err
+	}
+	if err := p.looper.AddMessageToSession(context.Background(), p.sessionID, llm.Message{
+		Role:    llm.RoleUser,
+		Content: "new topic",
+	}); err != nil {
+		return nil, err
+	}
+	return p.mockToolCallProvider.Chat(ctx, req)
+}
+
+func TestLoop_Process_ConflictAfterNewSession(t *testing.T) {
+	tests := []struct {
+		name     string
+		response llm.ChatResponse
+	}{
+		{name: "final answer", response: textResponse("stale answer")},
+		{name: "tool call", response: toolCallResponse("call_1", "read", `{"path":"a.txt"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &newSessionProvider{sessionID: "conflict"}
+			provider.responses = []llm.ChatResponse{tt.response}
+			looper := newTestLoop(t, Config{LLMProvider: provider})
+			provider.looper = looper
+			tool := &recordingTool{name: "read", result: "content"}
+			if err := looper.RegisterTool(tool); err != nil {
+				t.Fatalf("Failed to register tool: %v", err)
+			}
+
+			answer, err := looper.Process(context.Background(), "conflict", "old topic")
+			if !errors.Is(err, session.ErrConflict) {
+				t.Fatalf("Expected session.ErrConflict, got answer %q, error %v", answer, err)
+			}
+			if len(tool.calls) != 0 {
+				t.Errorf("Expected no tool calls after the conflict, got %d", len(tool.calls))
+			}
+
+			history, err := looper.GetSessionHistory(context.Background(), "conflict")
+			if err != nil {
+				t.Fatalf("GetSessionHistory failed: %v", err)
+			}
+			if len(history) != 1 || history[0].Content != "new topic" {
+				t.Errorf("Expected the new session to keep only its own message, got %+v", history)
+			}
+		})
+	}
+}
+
+func TestLoop_Process_ScrubsStoredCopyOnly(t *testing.T) {
+	scrubber, err := pii.New([]string{pii.CategoryEmail})
+	if err != nil {
+		t.Fatalf("pii.New failed: %v", err)
+	}
+	provider := &mockToolCallProvider{responses: []llm.ChatResponse{
+		toolCallResponse("call_1", "read", `{"path":"bob@example.com.txt"}`),
+		textResponse("sent to bob@example.com"),
+	}}
+	looper := newTestLoop(t, Config{LLMProvider: provider, Scrubber: scrubber})
+	tool := &recordingTool{name: "read", result: "report for bob@example.com"}
+	if err := looper.RegisterTool(tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	if _, err := looper.Process(context.Background(), "pii", "email the report to bob@example.com"); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if len(provider.requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(provider.requests))
+	}
+
+	// The turn sends its own messages to the LLM as written
+	want := []llm.Message{
+		{Role: llm.RoleUser, Content: "email the report to bob@example.com"},
+		{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{{ID: "call_1", Name: "read", Arguments: `{"path":"bob@example.com.txt"}`}}},
+		{Role: llm.RoleTool, Content: "report for bob@example.com", ToolCallID: "call_1"},
+	}
+	var got []llm.Message
+	for _, msg := range provider.requests[1].Messages {
+		if msg.Role != llm.RoleSystem {
+			got = append(got, msg)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d messages in the second request, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i].Role != want[i].Role || got[i].Content != want[i].Content || got[i].ToolCallID != want[i].ToolCallID {
+			t.Errorf("Message %d = %+v, want %+v", i, got[i], want[i])
+		}
+		if len(want[i].ToolCalls) > 0 && (len(got[i].ToolCalls) != 1 || got[i].ToolCalls[0].Arguments != want[i].ToolCalls[0].Arguments) {
+			t.Errorf("Message %d tool calls = %+v, want %+v", i, got[i].ToolCalls, want[i].ToolCalls)
+		}
+	}
+	if first := provider.requests[0].Messages[len(provider.requests[0].Messages)-1]; first.Content != "email the report to bob@example.com" {
+		t.Errorf("Expected the user message as written in the first request, got %q", first.Content)
+	}
+
+	// The stored copy is scrubbed, and later turns see it scrubbed
+	history, err := looper.GetSessionHistory(context.Background(), "pii")
+	if err != nil {
+		t.Fatalf("GetSessionHistory failed: %v", err)
+	}
+	for _, msg := range history {
+		stored := msg.Content
+		for _, call := range msg.ToolCalls {
+			stored += call.Arguments
+		}
+		if strings.Contains(stored, "bob@example.com") {
+			t.Errorf("Expected the stored message to be scrubbed, got %+v", msg)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package loop
+
+import (
+	stdcontext "context"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// StreamFunc receives the answer text generated so far by the current LLM call.
+// Each LLM call of a request starts over with its own text.
+type StreamFunc func(text string)
+
+// streamKey is the context key of the stream function of a request
+type streamKey struct{}
+
+// WithStream returns a context that streams the answer of a request to fn,
+// if the LLM provider supports streaming.
+func WithStream(ctx stdcontext.Context, fn StreamFunc) stdcontext.Context {
+	return stdcontext.WithValue(ctx, streamKey{}, fn)
+}
+
+// chat sends a request to the LLM provider, streaming the response when the
+// request has a stream function and the provider supports streaming. Tools
+// go through the text protocol when the model has no native tool calling.
+func (l *Loop) chat(ctx stdcontext.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	if l.textTools() {
+		return l.chatText(ctx, req)
+	}
+
+	fn, ok := ctx.Value(streamKey{}).(StreamFunc)
+	streamer, streaming := l.provider.(llm.StreamingProvider)
+	if !ok || fn == nil || !streaming {
+		return l.provider.Chat(ctx, req)
+	}
+
+	var text strings.Builder
+	return streamer.ChatStream(ctx, req, func(delta string) {
+		text.WriteString(delta)
+		fn(text.String())
+	})
+}
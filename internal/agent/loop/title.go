@@ -0,0 +1,88 @@
+package loop
+
+import (
+	stdcontext "context"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// titleTimeout limits the LLM call that generates a session title
+const titleTimeout = 30 * time.Second
+
+// maybeGenerateTitle generates a session title in the background once the
+// session has TitleAfterTurns user messages and no title yet.
+func (l *Loop) maybeGenerateTitle(sessionID string) {
+	if l.config.TitleAfterTurns <= 0 {
+		return
+	}
+	if _, running := l.titling.LoadOrStore(sessionID, struct{}{}); running {
+		return
+	}
+
+	go func() {
+		defer l.titling.Delete(sessionID)
+
+		if err := l.generateTitle(sessionID); err != nil {
+			l.logger.Warn("Failed to generate session title",
+				logger.Field{Key: "session_id", Value: sessionID},
+				logger.Field{Key: "error", Value: err.Error()})
+		}
+	}()
+}
+
+// generateTitle asks the LLM for a short title and stores it in session metadata.
+func (l *Loop) generateTitle(sessionID string) error {
+	sess, err := l.sessionMgr.Get(sessionID)
+	if err != nil {
+		return err
+	}
+
+	meta, err := sess.ReadMeta()
+	if err != nil || meta.Title != "" {
+		return err
+	}
+
+	history, err := sess.Read()
+	if err != nil {
+		return err
+	}
+	if session.UserTurns(history) < l.config.TitleAfterTurns {
+		return nil
+	}
+
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), titleTimeout)
+	defer cancel()
+
+	resp, err := l.provider.Chat(ctx, session.TitleRequest(history, l.config.Model))
+	if err != nil {
+		return err
+	}
+
+	title := session.CleanTitle(resp.Content)
+	if title == "" {
+		return nil
+	}
+
+	meta.Title = title
+	meta.TitledAt = time.Now()
+	if err := sess.WriteMeta(meta); err != nil {
+		return err
+	}
+
+	l.logger.Info("Session title generated",
+		logger.Field{Key: "session_id", Value: sessionID},
+		logger.Field{Key: "title", Value: title})
+	return nil
+}
+
+// ListSessions returns all sessions with titles, most recently active first.
+func (l *Loop) ListSessions(ctx stdcontext.Context) ([]session.Info, error) {
+	return l.sessionMgr.List()
+}
+
+// GetSessionEntries returns the history entries of a session.
+func (l *Loop) GetSessionEntries(ctx stdcontext.Context, sessionID string) ([]session.Entry, error) {
+	return l.sessionOps.GetSessionEntries(ctx, sessionID)
+}
@@ -147,9 +147,16 @@ func TestLoop_ToolCalling(t *testing.T) {
 type mockToolCallProvider struct {
 	responses []llm.ChatResponse
 	callIndex int
+	requests  []llm.ChatRequest // Requests received, in order
+	errs      map[int]error     // Errors returned instead of a response, by call index
 }
 
 func (m *mockToolCallProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	m.requests = append(m.requests, req)
+	if err := m.errs[m.callIndex]; err != nil {
+		m.callIndex++
+		return nil, err
+	}
 	if m.callIndex >= len(m.responses) {
 		return &llm.ChatResponse{
 			Content:      "Default response",
@@ -170,6 +177,63 @@ func (m *mockToolCallProvider) GetCallCount() int {
 	return m.callIndex
 }
 
+// newTestLoop creates a loop with cfg in a temporary workspace.
+func newTestLoop(t *testing.T, cfg Config) *Loop {
+	t.Helper()
+	tmpDir := t.TempDir()
+	cfg.Workspace = filepath.Join(tmpDir, "workspace")
+	cfg.SessionDir = filepath.Join(tmpDir, "sessions")
+	for _, dir := range []string{cfg.Workspace, cfg.SessionDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger, _ = logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	}
+
+	looper, err := NewLoop(cfg)
+	if err != nil {
+		t.Fatalf("NewLoop failed: %v", err)
+	}
+	return looper
+}
+
+// recordingTool is a test tool with a parameters schema that records the
+// arguments it is called with.
+type recordingTool struct {
+	name   string
+	result string
+	calls  []string
+}
+
+func (r *recordingTool) Name() string        { return r.name }
+func (r *recordingTool) Description() string { return "Test tool " + r.name }
+func (r *recordingTool) Parameters() map[string]any {
+	return map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"path": map[string]any{"type": "string"}},
+		"required":   []any{"path"},
+	}
+}
+func (r *recordingTool) Execute(ctx context.Context, args string) (string, error) {
+	r.calls = append(r.calls, args)
+	return r.result, nil
+}
+
+// toolCallResponse returns a response calling name with args.
+func toolCallResponse(id, name, args string) llm.ChatResponse {
+	return llm.ChatResponse{
+		FinishReason: llm.FinishReasonToolCalls,
+		ToolCalls:    []llm.ToolCall{{ID: id, Name: name, Arguments: args}},
+	}
+}
+
+// textResponse returns a final answer.
+func textResponse(content string) llm.ChatResponse {
+	return llm.ChatResponse{Content: content, FinishReason: llm.FinishReasonStop}
+}
+
 // jsonMapToString converts a map to JSON string.
 func jsonMapToString(m map[string]interface{}) string {
 	data, _ := json.Marshal(m)
@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/aatumaykin/nexbot/internal/approval"
+	"github.com/aatumaykin/nexbot/internal/forms"
 	"github.com/aatumaykin/nexbot/internal/llm"
 	"github.com/aatumaykin/nexbot/internal/logger"
 	"github.com/aatumaykin/nexbot/internal/secrets"
@@ -15,6 +17,14 @@ type ToolExecutor struct {
 	logger  *logger.Logger
 	tools   *tools.Registry
 	secrets *secrets.Store
+	forms   *forms.Manager // Asks the user for missing arguments of form tools (nil disables)
+
+	// Asks the user to approve destructive calls of these tool classes (nil disables)
+	approvals       *approval.Manager
+	approvalClasses map[string]bool
+
+	// Records tool results or serves recorded ones for reproducible runs (nil disables)
+	recorder *tools.ToolRecorder
 }
 
 // NewToolExecutor creates a new ToolExecutor.
@@ -68,7 +78,18 @@ func (te *ToolExecutor) ProcessToolCalls(ctx context.Context, toolCalls []tools.
 		secretResolver = resolver.Resolve
 	}
 
+	held := te.holdForApproval(ctx, toolCalls, sessionID)
+
 	for i, toolCall := range toolCalls {
+		if result, ok := held[toolCall.ID]; ok {
+			results[i] = result
+			continue
+		}
+		if result, ok := te.startForm(ctx, toolCall, sessionID); ok {
+			results[i] = result
+			continue
+		}
+
 		// Create execution config with secrets support
 		cfg := &tools.ExecutionConfig{
 			DefaultTimeout: 30 * time.Second,
@@ -85,28 +106,34 @@ func (te *ToolExecutor) ProcessToolCalls(ctx context.Context, toolCalls []tools.
 
 // ExecuteToolCall executes a single tool call with context and logging.
 func (te *ToolExecutor) ExecuteToolCall(ctx context.Context, toolCall tools.ToolCall, cfg *tools.ExecutionConfig) tools.ToolResult {
-	te.logger.DebugCtx(ctx, "executing tool",
+	// Log lines of the tool itself carry the call too
+	ctx = logger.WithContext(ctx,
+		logger.Field{Key: "session_id", Value: cfg.SessionID},
 		logger.Field{Key: "tool_name", Value: toolCall.Name},
-		logger.Field{Key: "tool_call_id", Value: toolCall.ID},
-		logger.Field{Key: "session_id", Value: cfg.SessionID})
+		logger.Field{Key: "tool_call_id", Value: toolCall.ID})
+	te.logger.DebugCtx(ctx, "executing tool")
 
 	start := time.Now()
-	result, _ := tools.ExecuteToolCallWithContext(te.tools, toolCall, ctx, cfg)
+	result := te.runToolCall(ctx, toolCall, cfg)
 
 	duration := time.Since(start)
+	// A nil *ToolError must not become a non-nil error
+	var callErr error
+	if result.Error != nil {
+		callErr = result.Error
+	}
+	reportToolCall(ctx, toolCall.Name, duration, callErr)
 
 	// Логируем результат
 	if result.Error != nil {
 		te.logger.ErrorCtx(ctx, "tool execution failed", result.Error,
-			logger.Field{Key: "tool_name", Value: toolCall.Name},
-			logger.Field{Key: "tool_call_id", Value: toolCall.ID},
 			logger.Field{Key: "duration_ms", Value: duration.Milliseconds()},
+			logger.Field{Key: "error_class", Value: string(result.ErrorClass())},
 			logger.Field{Key: "timed_out", Value: result.TimedOut})
 	} else {
 		te.logger.DebugCtx(ctx, "tool execution completed",
-			logger.Field{Key: "tool_name", Value: toolCall.Name},
-			logger.Field{Key: "tool_call_id", Value: toolCall.ID},
-			logger.Field{Key: "duration_ms", Value: duration.Milliseconds()})
+			logger.Field{Key: "duration_ms", Value: duration.Milliseconds()},
+			logger.Field{Key: "artifact_count", Value: len(result.Artifacts)})
 	}
 
 	return result
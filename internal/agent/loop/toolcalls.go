@@ -0,0 +1,27 @@
+package loop
+
+import (
+	stdcontext "context"
+	"time"
+)
+
+// ToolCallFunc receives the name, duration and error of every tool call of
+// a request.
+type ToolCallFunc func(tool string, duration time.Duration, err error)
+
+// toolCallKey is the context key of the tool call function of a request
+type toolCallKey struct{}
+
+// WithToolCalls returns a context that reports the tool calls of a request
+// to fn.
+func WithToolCalls(ctx stdcontext.Context, fn ToolCallFunc) stdcontext.Context {
+	return stdcontext.WithValue(ctx, toolCallKey{}, fn)
+}
+
+// reportToolCall passes a finished tool call to the tool call function of
+// the request, if any.
+func reportToolCall(ctx stdcontext.Context, tool string, duration time.Duration, err error) {
+	if fn, ok := ctx.Value(toolCallKey{}).(ToolCallFunc); ok && fn != nil {
+		fn(tool, duration, err)
+	}
+}
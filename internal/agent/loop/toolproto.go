@@ -0,0 +1,46 @@
+package loop
+
+import (
+	stdcontext "context"
+
+	"github.com/aatumaykin/nexbot/internal/agent/toolproto"
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// Tool calling protocols
+const (
+	ToolProtocolAuto   = "auto"   // Native tool calling when the provider supports it, text protocol otherwise
+	ToolProtocolNative = "native" // Native tool calling only; providers without it get no tools
+	ToolProtocolText   = "text"   // Text protocol even when native tool calling is supported
+)
+
+// textTools reports whether tools are offered through the text protocol.
+func (l *Loop) textTools() bool {
+	switch l.config.ToolProtocol {
+	case ToolProtocolText:
+		return true
+	case ToolProtocolNative:
+		return false
+	default:
+		return !l.provider.SupportsToolCalling()
+	}
+}
+
+// chatText sends a request through the text tool protocol: tool definitions
+// and tool history are encoded as text and tool calls in the reply are parsed
+// back, so the loop handles them like native tool calls. The reply is not
+// streamed, since the tool call markup must not reach the user.
+func (l *Loop) chatText(ctx stdcontext.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	offered := len(req.Tools) > 0
+	resp, err := l.provider.Chat(ctx, toolproto.Encode(req))
+	if err != nil || !offered {
+		return resp, err
+	}
+
+	if content, calls := toolproto.Parse(resp.Content); len(calls) > 0 {
+		resp.Content = content
+		resp.ToolCalls = calls
+		resp.FinishReason = llm.FinishReasonToolCalls
+	}
+	return resp, nil
+}
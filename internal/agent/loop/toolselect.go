@@ -0,0 +1,99 @@
+package loop
+
+import (
+	stdcontext "context"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// selectionKey is the context key of the tool selection of a request
+type selectionKey struct{}
+
+// selectionTurns is how many user turns, including the current one, describe
+// a request for tool selection: follow-ups like "yes, do it" rely on the
+// previous turn
+const selectionTurns = 2
+
+// toolSelection holds the tools picked for a request. The tools are picked on
+// the first LLM call and kept for the whole request, so the model can call
+// the same tools on every iteration.
+type toolSelection struct {
+	sessionID string
+	query     string
+	extra     []string        // Tools kept regardless of the query
+	names     map[string]bool // Picked tools, nil until the first LLM call
+}
+
+// startToolSelection prepares the tool selection of a new request. The
+// context is returned unchanged when tool selection is disabled.
+func (l *Loop) startToolSelection(ctx stdcontext.Context, sessionID, message string) stdcontext.Context {
+	if l.config.ToolSelector == nil {
+		return ctx
+	}
+
+	selection := &toolSelection{sessionID: sessionID, query: message}
+	if history, err := l.sessionOps.GetSessionHistory(ctx, sessionID); err == nil {
+		selection.query, selection.extra = selectionContext(history, selectionTurns)
+	}
+	if l.config.Planner != nil {
+		selection.extra = append(selection.extra, planToolName)
+	}
+	return stdcontext.WithValue(ctx, selectionKey{}, selection)
+}
+
+// selectTools returns the tool definitions to send with an LLM call of the
+// request: all tools without tool selection, the picked tools otherwise.
+func (l *Loop) selectTools(ctx stdcontext.Context, tools []llm.ToolDefinition) []llm.ToolDefinition {
+	selection, ok := ctx.Value(selectionKey{}).(*toolSelection)
+	if !ok {
+		return tools
+	}
+
+	if selection.names == nil {
+		picked := l.config.ToolSelector.Select(selection.query, tools, selection.extra...)
+		selection.names = make(map[string]bool, len(picked))
+		names := make([]string, len(picked))
+		for i, tool := range picked {
+			selection.names[tool.Name] = true
+			names[i] = tool.Name
+		}
+		l.logger.DebugCtx(ctx, "Tools selected for request",
+			logger.Field{Key: "selected", Value: strings.Join(names, ",")},
+			logger.Field{Key: "dropped", Value: len(tools) - len(picked)})
+	}
+
+	selected := make([]llm.ToolDefinition, 0, len(selection.names))
+	for _, tool := range tools {
+		if selection.names[tool.Name] {
+			selected = append(selected, tool)
+		}
+	}
+	return selected
+}
+
+// selectionContext returns the text of the last user turns of a history and
+// the tools called since the first of them.
+func selectionContext(history []llm.Message, turns int) (string, []string) {
+	var (
+		texts []string
+		used  []string
+	)
+	seen := make(map[string]bool)
+	for i := len(history) - 1; i >= 0 && len(texts) < turns; i-- {
+		msg := history[i]
+		switch msg.Role {
+		case llm.RoleUser:
+			texts = append(texts, msg.Content)
+		case llm.RoleAssistant:
+			for _, call := range msg.ToolCalls {
+				if !seen[call.Name] {
+					seen[call.Name] = true
+					used = append(used, call.Name)
+				}
+			}
+		}
+	}
+	return strings.Join(texts, "\n"), used
+}
@@ -0,0 +1,32 @@
+package loop
+
+import (
+	stdcontext "context"
+
+	"github.com/aatumaykin/nexbot/internal/agent/truncate"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// applyTruncation shortens successful results to the token budgets of their
+// tools before they are added to the session. Results are in the order of calls.
+func (l *Loop) applyTruncation(ctx stdcontext.Context, calls []tools.ToolCall, results []tools.ToolResult) {
+	if l.config.Truncator == nil {
+		return
+	}
+	for i, call := range calls {
+		if i >= len(results) || results[i].Error != nil {
+			continue
+		}
+		content, truncated := l.config.Truncator.Truncate(call.Name, results[i].Content)
+		if !truncated {
+			continue
+		}
+		l.logger.DebugCtx(ctx, "Tool output truncated",
+			logger.Field{Key: "tool_name", Value: call.Name},
+			logger.Field{Key: "tool_call_id", Value: call.ID},
+			logger.Field{Key: "tokens_before", Value: truncate.EstimateTokens(results[i].Content)},
+			logger.Field{Key: "tokens_after", Value: truncate.EstimateTokens(content)})
+		results[i].Content = content
+	}
+}
@@ -0,0 +1,78 @@
+package loop
+
+import (
+	stdcontext "context"
+
+	"github.com/aatumaykin/nexbot/internal/agent/verify"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// verifyAnswer checks the final draft of a request against the tool outputs
+// of the request and returns the answer to send. The draft is returned
+// unchanged when verification is disabled, not needed or fails.
+func (l *Loop) verifyAnswer(ctx stdcontext.Context, sessionID, draft string) string {
+	if l.config.Verifier == nil {
+		return draft
+	}
+
+	history, err := l.sessionOps.GetSessionHistory(ctx, sessionID)
+	if err != nil {
+		l.logger.WarnCtx(ctx, "Failed to read session for verification",
+			logger.Field{Key: "error", Value: err.Error()})
+		return draft
+	}
+
+	question, evidence, earlier := requestEvidence(history)
+	if !l.config.Verifier.ShouldVerify(draft, evidence) {
+		return draft
+	}
+
+	result, err := l.config.Verifier.Verify(ctx, verify.Request{
+		Question: question,
+		Draft:    draft,
+		Evidence: evidence,
+		Context:  earlier,
+		Model:    l.requestModel(ctx),
+	})
+	if err != nil {
+		l.logger.WarnCtx(ctx, "Verification failed, sending the draft",
+			logger.Field{Key: "error", Value: err.Error()})
+		return draft
+	}
+
+	l.logger.InfoCtx(ctx, "Answer verified",
+		logger.Field{Key: "verdict", Value: result.Verdict},
+		logger.Field{Key: "confidence", Value: result.Confidence},
+		logger.Field{Key: "issues", Value: len(result.Issues)},
+		logger.Field{Key: "corrected", Value: result.Corrected},
+		logger.Field{Key: "tokens", Value: result.Tokens})
+	return result.Answer
+}
+
+// requestEvidence splits the history at the last user message: the question,
+// the tool outputs that followed it and the conversation before it.
+func requestEvidence(history []llm.Message) (string, []verify.Evidence, []llm.Message) {
+	last := -1
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == llm.RoleUser {
+			last = i
+			break
+		}
+	}
+	if last < 0 {
+		return "", nil, nil
+	}
+
+	names := make(map[string]string)
+	var evidence []verify.Evidence
+	for _, msg := range history[last+1:] {
+		for _, call := range msg.ToolCalls {
+			names[call.ID] = call.Name
+		}
+		if msg.Role == llm.RoleTool && names[msg.ToolCallID] != planToolName {
+			evidence = append(evidence, verify.Evidence{Tool: names[msg.ToolCallID], Output: msg.Content})
+		}
+	}
+	return history[last].Content, evidence, conversationContext(history[:last+1])
+}
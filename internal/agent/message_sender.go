@@ -9,9 +9,11 @@ import (
 
 // MessageResult - результат отправки сообщения
 type MessageResult struct {
-	Success      bool                  // Успешная отправка
-	Error        channels.ErrorDetails // Детали ошибки (если есть)
-	ResponseText string                // Текст ответа от канала (если есть)
+	Success       bool                  // Успешная отправка
+	Error         channels.ErrorDetails // Детали ошибки (если есть)
+	ResponseText  string                // Текст ответа от канала (если есть)
+	CorrelationID string                // ID запроса на отправку
+	MessageID     string                // ID сообщения в канале (если известен)
 }
 
 // MessageSender interface for sending messages from tools.
@@ -24,10 +26,20 @@ type MessageSender interface {
 	SendDeleteMessage(userID, channelType, sessionID, messageID string, timeout time.Duration) (*MessageResult, error)
 	SendPhotoMessage(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard, format bus.FormatType, timeout time.Duration) (*MessageResult, error)
 	SendDocumentMessage(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard, format bus.FormatType, timeout time.Duration) (*MessageResult, error)
+	SendStickerMessage(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard, timeout time.Duration) (*MessageResult, error)
+	SendAnimationMessage(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard, format bus.FormatType, timeout time.Duration) (*MessageResult, error)
 	SendMessageAsync(userID, channelType, sessionID, message string) error
 	SendMessageAsyncWithKeyboard(userID, channelType, sessionID, message string, keyboard *bus.InlineKeyboard, format bus.FormatType) error
 	SendEditMessageAsync(userID, channelType, sessionID, messageID, content string, keyboard *bus.InlineKeyboard, format bus.FormatType) error
 	SendDeleteMessageAsync(userID, channelType, sessionID, messageID string) error
 	SendPhotoMessageAsync(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard, format bus.FormatType) error
 	SendDocumentMessageAsync(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard, format bus.FormatType) error
+	SendStickerMessageAsync(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard) error
+	SendAnimationMessageAsync(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard, format bus.FormatType) error
+}
+
+// TableSender is an optional interface of message senders that can attach a
+// table to a text message; connectors render the table for their channel.
+type TableSender interface {
+	SendTableMessage(userID, channelType, sessionID, message string, table *bus.Table, keyboard *bus.InlineKeyboard, format bus.FormatType, timeout time.Duration) (*MessageResult, error)
 }
@@ -0,0 +1,261 @@
+// Package planner splits multi-step requests into a plan before they are
+// executed: the LLM is first asked for a list of steps (structured JSON), the
+// agent loop then works through the steps one by one with a budget of tool
+// rounds per step and may revise the remaining steps on the way.
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// Step statuses.
+const (
+	StatusPending = "pending"
+	StatusCurrent = "current"
+	StatusDone    = "done"
+)
+
+const (
+	// DefaultMinChars is the shortest request that is planned
+	DefaultMinChars = 200
+
+	// DefaultMaxSteps is the maximum number of steps of a plan
+	DefaultMaxSteps = 8
+
+	// DefaultStepIterations is the maximum number of tool rounds of a step
+	DefaultStepIterations = 3
+
+	planPrompt = "You plan tasks for an assistant that works with tools. " +
+		"Break the user's request into a short sequence of concrete steps (at most %d), each achieving one intermediate result. " +
+		"Reply with JSON only, no prose: " +
+		`{"goal": "...", "steps": [{"title": "...", "tools": ["tool_name"], "max_iterations": 2}]}` +
+		". max_iterations is the number of tool-calling rounds the step needs (1-%d). " +
+		`If the request can be answered directly or in a single step, reply {"steps": []}.` +
+		"\n\nAvailable tools: %s"
+)
+
+// ErrInvalidPlan is returned for planner replies that are not a valid plan.
+var ErrInvalidPlan = errors.New("invalid plan")
+
+// Step is one step of a plan.
+type Step struct {
+	Title         string   `json:"title"`
+	Tools         []string `json:"tools,omitempty"`          // Tools the step is expected to use
+	MaxIterations int      `json:"max_iterations,omitempty"` // Tool rounds available to the step
+	Status        string   `json:"status"`
+	Result        string   `json:"result,omitempty"` // Outcome noted when the step was completed
+}
+
+// Plan is the step plan of a request.
+type Plan struct {
+	Goal      string    `json:"goal,omitempty"`
+	Request   string    `json:"request"`
+	Steps     []Step    `json:"steps"`
+	Revisions int       `json:"revisions,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Current returns the index of the step being worked on, or -1 once all
+// steps are done.
+func (p *Plan) Current() int {
+	for i, step := range p.Steps {
+		if step.Status != StatusDone {
+			return i
+		}
+	}
+	return -1
+}
+
+// Complete marks the current step as done and starts the next one.
+func (p *Plan) Complete(result string) error {
+	current := p.Current()
+	if current < 0 {
+		return errors.New("all steps are already done")
+	}
+	p.Steps[current].Status = StatusDone
+	p.Steps[current].Result = strings.TrimSpace(result)
+	p.start()
+	return nil
+}
+
+// Revise replaces the steps that are not done yet.
+func (p *Plan) Revise(steps []Step, stepIterations int) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("%w: a revision needs at least one step", ErrInvalidPlan)
+	}
+	done := p.Steps[:0:0]
+	for _, step := range p.Steps {
+		if step.Status == StatusDone {
+			done = append(done, step)
+		}
+	}
+	p.Steps = append(done, normalizeSteps(steps, stepIterations)...)
+	p.Revisions++
+	p.start()
+	return nil
+}
+
+// start marks the first pending step as current.
+func (p *Plan) start() {
+	p.UpdatedAt = time.Now()
+	if current := p.Current(); current >= 0 {
+		p.Steps[current].Status = StatusCurrent
+	}
+}
+
+// Render formats the plan as a checklist.
+func (p *Plan) Render() string {
+	var b strings.Builder
+	if p.Goal != "" {
+		fmt.Fprintf(&b, "Goal: %s\n", p.Goal)
+	}
+	for i, step := range p.Steps {
+		mark := "[ ]"
+		switch step.Status {
+		case StatusDone:
+			mark = "[x]"
+		case StatusCurrent:
+			mark = "[>]"
+		}
+		fmt.Fprintf(&b, "%s %d. %s", mark, i+1, step.Title)
+		if len(step.Tools) > 0 {
+			fmt.Fprintf(&b, " (tools: %s)", strings.Join(step.Tools, ", "))
+		}
+		if step.Result != "" {
+			fmt.Fprintf(&b, " — %s", step.Result)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Config configures a Planner.
+type Config struct {
+	MinChars       int // Shorter requests are not planned (DefaultMinChars if 0, negative plans every request)
+	MaxSteps       int // Maximum steps of a plan (DefaultMaxSteps if 0)
+	StepIterations int // Maximum tool rounds of a step (DefaultStepIterations if 0)
+}
+
+// Planner asks the LLM for step plans.
+type Planner struct {
+	cfg      Config
+	provider llm.Provider
+}
+
+// New creates a Planner.
+func New(provider llm.Provider, cfg Config) *Planner {
+	if cfg.MinChars == 0 {
+		cfg.MinChars = DefaultMinChars
+	}
+	if cfg.MaxSteps <= 0 {
+		cfg.MaxSteps = DefaultMaxSteps
+	}
+	if cfg.StepIterations <= 0 {
+		cfg.StepIterations = DefaultStepIterations
+	}
+	return &Planner{cfg: cfg, provider: provider}
+}
+
+// StepIterations returns the maximum number of tool rounds of a step.
+func (p *Planner) StepIterations() int {
+	return p.cfg.StepIterations
+}
+
+// ShouldPlan reports whether a request is long enough to be planned.
+func (p *Planner) ShouldPlan(message string) bool {
+	return p.cfg.MinChars < 0 || utf8.RuneCountInString(message) >= p.cfg.MinChars
+}
+
+// Plan asks the LLM for a plan of the request. history is the conversation
+// before the request. Returns nil if the request does not need a plan (the
+// LLM returned less than two steps).
+func (p *Planner) Plan(ctx context.Context, model, request string, history []llm.Message, toolNames []string) (*Plan, error) {
+	tools := "none"
+	if len(toolNames) > 0 {
+		tools = strings.Join(toolNames, ", ")
+	}
+
+	messages := []llm.Message{{
+		Role:    llm.RoleSystem,
+		Content: fmt.Sprintf(planPrompt, p.cfg.MaxSteps, p.cfg.StepIterations, tools),
+	}}
+	messages = append(messages, history...)
+	messages = append(messages, llm.Message{Role: llm.RoleUser, Content: request})
+
+	resp, err := p.provider.Chat(ctx, llm.ChatRequest{
+		Messages:    messages,
+		Model:       model,
+		Temperature: 0.2,
+		MaxTokens:   1024,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("planning request failed: %w", err)
+	}
+
+	plan, err := Parse(resp.Content, p.cfg.MaxSteps, p.cfg.StepIterations)
+	if err != nil || len(plan.Steps) < 2 {
+		return nil, err
+	}
+	plan.Request = request
+	return plan, nil
+}
+
+// Parse reads a plan from an LLM reply, tolerating code fences and text
+// around the JSON object. Steps beyond maxSteps are dropped and step
+// iterations are limited to 1..stepIterations.
+func Parse(content string, maxSteps, stepIterations int) (*Plan, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("%w: no JSON object in reply", ErrInvalidPlan)
+	}
+
+	var raw struct {
+		Goal  string `json:"goal"`
+		Steps []Step `json:"steps"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPlan, err)
+	}
+
+	steps := normalizeSteps(raw.Steps, stepIterations)
+	if maxSteps > 0 && len(steps) > maxSteps {
+		steps = steps[:maxSteps]
+	}
+
+	now := time.Now()
+	plan := &Plan{
+		Goal:      strings.TrimSpace(raw.Goal),
+		Steps:     steps,
+		CreatedAt: now,
+	}
+	plan.start()
+	return plan, nil
+}
+
+// normalizeSteps drops untitled steps, resets their status and limits their iterations.
+func normalizeSteps(steps []Step, stepIterations int) []Step {
+	normalized := make([]Step, 0, len(steps))
+	for _, step := range steps {
+		step.Title = strings.TrimSpace(step.Title)
+		if step.Title == "" {
+			continue
+		}
+		if step.MaxIterations <= 0 || (stepIterations > 0 && step.MaxIterations > stepIterations) {
+			step.MaxIterations = stepIterations
+		}
+		step.Status = StatusPending
+		step.Result = ""
+		normalized = append(normalized, step)
+	}
+	return normalized
+}
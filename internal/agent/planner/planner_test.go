@@ -0,0 +1,131 @@
+package planner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+func TestParse(t *testing.T) {
+	content := "Here is the plan:\n```json\n" + `{"goal": "Weekly report", "steps": [
+		{"title": "Read the logs", "tools": ["read_file"], "max_iterations": 2},
+		{"title": "  "},
+		{"title": "Summarize errors", "max_iterations": 10},
+		{"title": "Send the report", "tools": ["send_message"]}
+	]}` + "\n```"
+
+	plan, err := Parse(content, 8, 3)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if plan.Goal != "Weekly report" || len(plan.Steps) != 3 {
+		t.Fatalf("Parse() = %+v, want 3 steps of the weekly report", plan)
+	}
+
+	wantIterations := []int{2, 3, 3}
+	for i, step := range plan.Steps {
+		if step.MaxIterations != wantIterations[i] {
+			t.Errorf("step %d max_iterations = %d, want %d", i, step.MaxIterations, wantIterations[i])
+		}
+	}
+	if plan.Steps[0].Status != StatusCurrent || plan.Steps[1].Status != StatusPending {
+		t.Errorf("statuses = %s/%s, want current/pending", plan.Steps[0].Status, plan.Steps[1].Status)
+	}
+
+	limited, err := Parse(content, 2, 3)
+	if err != nil || len(limited.Steps) != 2 {
+		t.Errorf("Parse() with max 2 steps = %+v, %v", limited, err)
+	}
+
+	for _, invalid := range []string{"no plan", `{"steps": "read"}`} {
+		if _, err := Parse(invalid, 8, 3); !errors.Is(err, ErrInvalidPlan) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidPlan", invalid, err)
+		}
+	}
+}
+
+func TestPlan_CompleteAndRevise(t *testing.T) {
+	plan, err := Parse(`{"steps": [{"title": "a"}, {"title": "b"}, {"title": "c"}]}`, 8, 3)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if err := plan.Complete("found 3 files"); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if plan.Current() != 1 || plan.Steps[0].Result != "found 3 files" || plan.Steps[1].Status != StatusCurrent {
+		t.Errorf("after Complete() plan = %+v", plan.Steps)
+	}
+
+	if err := plan.Revise([]Step{{Title: "d", MaxIterations: 1}}, 3); err != nil {
+		t.Fatalf("Revise() error = %v", err)
+	}
+	if len(plan.Steps) != 2 || plan.Steps[1].Title != "d" || plan.Steps[1].Status != StatusCurrent || plan.Revisions != 1 {
+		t.Errorf("after Revise() plan = %+v", plan.Steps)
+	}
+	if err := plan.Revise(nil, 3); !errors.Is(err, ErrInvalidPlan) {
+		t.Errorf("Revise(nil) error = %v, want ErrInvalidPlan", err)
+	}
+
+	if err := plan.Complete(""); err != nil || plan.Current() != -1 {
+		t.Errorf("Complete() of last step: current = %d, err = %v", plan.Current(), err)
+	}
+	if err := plan.Complete(""); err == nil {
+		t.Error("Complete() of a finished plan should fail")
+	}
+
+	rendered := plan.Render()
+	if !strings.Contains(rendered, "[x] 1. a — found 3 files") || !strings.Contains(rendered, "[x] 2. d") {
+		t.Errorf("Render() = %q", rendered)
+	}
+}
+
+// planProvider returns a fixed reply and records the request.
+type planProvider struct {
+	reply   string
+	request llm.ChatRequest
+}
+
+func (p *planProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.request = req
+	return &llm.ChatResponse{Content: p.reply, FinishReason: llm.FinishReasonStop}, nil
+}
+
+func (p *planProvider) SupportsToolCalling() bool { return false }
+
+func TestPlanner_Plan(t *testing.T) {
+	provider := &planProvider{reply: `{"goal": "g", "steps": [{"title": "a"}, {"title": "b"}]}`}
+	p := New(provider, Config{})
+
+	plan, err := p.Plan(context.Background(), "glm-4.7", "do a then b", nil, []string{"read_file", "shell_exec"})
+	if err != nil || plan == nil {
+		t.Fatalf("Plan() = %v, %v", plan, err)
+	}
+	if plan.Request != "do a then b" || provider.request.Model != "glm-4.7" {
+		t.Errorf("Plan() request = %q, model = %q", plan.Request, provider.request.Model)
+	}
+	if system := provider.request.Messages[0].Content; !strings.Contains(system, "read_file, shell_exec") {
+		t.Errorf("planning prompt does not list tools: %q", system)
+	}
+
+	provider.reply = `{"steps": []}`
+	if plan, err := p.Plan(context.Background(), "glm-4.7", "hi", nil, nil); plan != nil || err != nil {
+		t.Errorf("Plan() of a simple request = %v, %v, want nil", plan, err)
+	}
+}
+
+func TestPlanner_ShouldPlan(t *testing.T) {
+	p := New(&planProvider{}, Config{MinChars: 20})
+	if p.ShouldPlan("short") {
+		t.Error("ShouldPlan() of a short request = true")
+	}
+	if !p.ShouldPlan(strings.Repeat("long ", 5)) {
+		t.Error("ShouldPlan() of a long request = false")
+	}
+	if !New(&planProvider{}, Config{MinChars: -1}).ShouldPlan("hi") {
+		t.Error("ShouldPlan() with negative MinChars = false")
+	}
+}
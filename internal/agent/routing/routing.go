@@ -0,0 +1,155 @@
+// Package routing picks the model for each request: simple requests go to a
+// cheap, fast model and escalate to a stronger model only when needed — long
+// messages, code, complex tool usage or an explicit request from the user.
+package routing
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Escalation reasons.
+const (
+	ReasonSimple         = "simple"
+	ReasonUserRequest    = "user_request"
+	ReasonLength         = "length"
+	ReasonCode           = "code"
+	ReasonComplexTool    = "complex_tool"
+	ReasonToolIterations = "tool_iterations"
+)
+
+const (
+	// DefaultMaxCheapChars is the longest message handled by the cheap model
+	DefaultMaxCheapChars = 600
+
+	// DefaultMaxCheapToolIterations is the number of tool iterations after which requests escalate
+	DefaultMaxCheapToolIterations = 2
+)
+
+// DefaultComplexTools escalate a request as soon as the model calls them.
+var DefaultComplexTools = []string{"spawn", "shell_exec", "process", "write_file"}
+
+// DefaultKeywords are phrases by which the user explicitly asks for a stronger model.
+var DefaultKeywords = []string{
+	"think harder",
+	"think carefully",
+	"step by step",
+	"in depth",
+	"detailed analysis",
+	"use the best model",
+	"use the strong model",
+	"подумай хорошо",
+	"подумай внимательно",
+	"тщательно",
+	"подробный анализ",
+	"по шагам",
+}
+
+// Config configures a Router.
+type Config struct {
+	CheapModel             string   // Model for simple requests
+	StrongModel            string   // Model for escalated requests
+	MaxCheapChars          int      // Longer messages escalate (DefaultMaxCheapChars if 0, negative disables)
+	MaxCheapToolIterations int      // Escalate after N tool iterations (DefaultMaxCheapToolIterations if 0, negative disables)
+	ComplexTools           []string // Tools that escalate a request (DefaultComplexTools if nil)
+	Keywords               []string // Phrases that escalate a request (DefaultKeywords if nil)
+}
+
+// Router routes requests between the cheap and the strong model.
+type Router struct {
+	cfg Config
+}
+
+// New creates a Router.
+func New(cfg Config) *Router {
+	if cfg.MaxCheapChars == 0 {
+		cfg.MaxCheapChars = DefaultMaxCheapChars
+	}
+	if cfg.MaxCheapToolIterations == 0 {
+		cfg.MaxCheapToolIterations = DefaultMaxCheapToolIterations
+	}
+	if cfg.ComplexTools == nil {
+		cfg.ComplexTools = DefaultComplexTools
+	}
+	if cfg.Keywords == nil {
+		cfg.Keywords = DefaultKeywords
+	}
+	keywords := make([]string, 0, len(cfg.Keywords))
+	for _, keyword := range cfg.Keywords {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	cfg.Keywords = keywords
+	return &Router{cfg: cfg}
+}
+
+// Start routes a new request from the user message.
+func (r *Router) Start(message string) *Route {
+	route := &Route{router: r, reason: ReasonSimple}
+
+	lower := strings.ToLower(message)
+	switch {
+	case slices.ContainsFunc(r.cfg.Keywords, func(keyword string) bool { return strings.Contains(lower, keyword) }):
+		route.escalate(ReasonUserRequest)
+	case r.cfg.MaxCheapChars > 0 && utf8.RuneCountInString(message) > r.cfg.MaxCheapChars:
+		route.escalate(ReasonLength)
+	case strings.Contains(message, "```"):
+		route.escalate(ReasonCode)
+	}
+	return route
+}
+
+// Route is the routing state of one request. Once escalated, a request
+// stays on the strong model. Not safe for concurrent use.
+type Route struct {
+	router     *Router
+	strong     bool
+	reason     string
+	iterations int
+}
+
+// escalate switches the request to the strong model.
+func (rt *Route) escalate(reason string) {
+	rt.strong = true
+	rt.reason = reason
+}
+
+// Model returns the model for the next LLM call.
+func (rt *Route) Model() string {
+	if rt.strong {
+		return rt.router.cfg.StrongModel
+	}
+	return rt.router.cfg.CheapModel
+}
+
+// Strong reports whether the request is on the strong model.
+func (rt *Route) Strong() bool {
+	return rt.strong
+}
+
+// Reason returns why the current model was chosen.
+func (rt *Route) Reason() string {
+	return rt.reason
+}
+
+// ObserveTools records the tools called in one iteration and escalates the
+// request on complex tools or too many iterations. Returns true if the
+// request has just been escalated.
+func (rt *Route) ObserveTools(names []string) bool {
+	if rt.strong {
+		return false
+	}
+	rt.iterations++
+
+	if slices.ContainsFunc(names, func(name string) bool { return slices.Contains(rt.router.cfg.ComplexTools, name) }) {
+		rt.escalate(ReasonComplexTool)
+		return true
+	}
+	if limit := rt.router.cfg.MaxCheapToolIterations; limit > 0 && rt.iterations >= limit {
+		rt.escalate(ReasonToolIterations)
+		return true
+	}
+	return false
+}
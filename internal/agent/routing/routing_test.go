@@ -0,0 +1,67 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestRouter() *Router {
+	return New(Config{CheapModel: "glm-4.7-flash", StrongModel: "glm-4.7", MaxCheapChars: 50})
+}
+
+func TestRouter_Start(t *testing.T) {
+	r := newTestRouter()
+
+	tests := []struct {
+		name    string
+		message string
+		model   string
+		reason  string
+	}{
+		{"simple", "What time is it?", "glm-4.7-flash", ReasonSimple},
+		{"explicit ask", "Think harder about this", "glm-4.7", ReasonUserRequest},
+		{"explicit ask in russian", "Подумай хорошо, что выбрать", "glm-4.7", ReasonUserRequest},
+		{"long message", strings.Repeat("word ", 20), "glm-4.7", ReasonLength},
+		{"code", "fix ```x := 1```", "glm-4.7", ReasonCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := r.Start(tt.message)
+			if route.Model() != tt.model || route.Reason() != tt.reason {
+				t.Errorf("Start() = %s (%s), want %s (%s)", route.Model(), route.Reason(), tt.model, tt.reason)
+			}
+		})
+	}
+}
+
+func TestRoute_ObserveTools(t *testing.T) {
+	r := newTestRouter()
+
+	route := r.Start("list my files")
+	if route.ObserveTools([]string{"list_dir"}) || route.Strong() {
+		t.Fatal("Expected a simple tool not to escalate on the first iteration")
+	}
+	if !route.ObserveTools([]string{"read_file"}) || route.Reason() != ReasonToolIterations {
+		t.Errorf("Expected escalation after %d iterations, got %s", DefaultMaxCheapToolIterations, route.Reason())
+	}
+	if route.ObserveTools([]string{"read_file"}) {
+		t.Error("Expected an escalated request not to escalate again")
+	}
+
+	route = r.Start("run the tests")
+	if !route.ObserveTools([]string{"system_time", "shell_exec"}) || route.Model() != "glm-4.7" || route.Reason() != ReasonComplexTool {
+		t.Errorf("Expected complex tool to escalate, got %s (%s)", route.Model(), route.Reason())
+	}
+}
+
+func TestNew_DisabledLimits(t *testing.T) {
+	r := New(Config{CheapModel: "cheap", StrongModel: "strong", MaxCheapChars: -1, MaxCheapToolIterations: -1, ComplexTools: []string{}})
+
+	route := r.Start(strings.Repeat("long ", 1000))
+	for i := 0; i < 10; i++ {
+		route.ObserveTools([]string{"shell_exec"})
+	}
+	if route.Strong() {
+		t.Errorf("Expected disabled limits not to escalate, got %s", route.Reason())
+	}
+}
@@ -0,0 +1,200 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// NamedSessionPrefix is the session ID prefix of named sessions
+	NamedSessionPrefix = "named:"
+
+	// bindingsFilename stores chat-to-named-session bindings in the metadata directory
+	bindingsFilename = "bindings.json"
+)
+
+var (
+	// ErrInvalidName is returned for session names that are not allowed
+	ErrInvalidName = errors.New("session name must be 1-64 characters: lowercase letters, digits, '-' or '_'")
+
+	// ErrNameTaken is returned when saving under a name used by another session
+	ErrNameTaken = errors.New("session name is already taken")
+
+	// ErrNamedSessionNotFound is returned when resuming an unknown named session
+	ErrNamedSessionNotFound = errors.New("named session not found")
+
+	sessionNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+)
+
+// NamedSessionID returns the session ID of a named session.
+func NamedSessionID(name string) string {
+	return NamedSessionPrefix + name
+}
+
+// SessionName returns the name of a named session ID.
+func SessionName(sessionID string) (string, bool) {
+	return strings.CutPrefix(sessionID, NamedSessionPrefix)
+}
+
+// NormalizeName lowercases and validates a session name.
+func NormalizeName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !sessionNamePattern.MatchString(name) {
+		return "", ErrInvalidName
+	}
+	return name, nil
+}
+
+// bindingsPath returns the path of the bindings file.
+func (m *Manager) bindingsPath() string {
+	return filepath.Join(m.baseDir, metaSubdir, bindingsFilename)
+}
+
+// loadBindings reads bindings from disk. Called before the manager is shared.
+func (m *Manager) loadBindings() error {
+	m.bindings = map[string]string{}
+	data, err := os.ReadFile(m.bindingsPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read session bindings: %w", err)
+	}
+	if err := json.Unmarshal(data, &m.bindings); err != nil {
+		return fmt.Errorf("failed to parse session bindings: %w", err)
+	}
+	return nil
+}
+
+// saveBindings writes bindings to disk. Caller must hold m.mu.
+func (m *Manager) saveBindings() error {
+	path := m.bindingsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create session metadata directory: %w", err)
+	}
+	data, err := json.MarshalIndent(m.bindings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session bindings: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session bindings: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to save session bindings: %w", err)
+	}
+	return nil
+}
+
+// resolve returns the session ID a chat session is bound to. Caller must hold m.mu.
+func (m *Manager) resolve(sessionID string) string {
+	if target, ok := m.bindings[sessionID]; ok {
+		return target
+	}
+	return sessionID
+}
+
+// Resolve returns the session ID that stores the history of sessionID:
+// the bound named session, or sessionID itself if it is not bound.
+func (m *Manager) Resolve(sessionID string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.resolve(sessionID)
+}
+
+// SaveAs stores the current history of sessionID under a name and binds
+// sessionID to it, so the conversation continues in the named session.
+// If sessionID is already bound to a named session, that session is renamed.
+// Returns the named session ID.
+func (m *Manager) SaveAs(sessionID, name string) (string, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current := m.resolve(sessionID)
+	target := NamedSessionID(name)
+	if current == target {
+		return target, nil
+	}
+	if _, err := os.Stat(m.sessionFile(target)); err == nil {
+		return "", fmt.Errorf("%w: %s", ErrNameTaken, name)
+	}
+
+	// Move history and metadata to the named session
+	if err := os.Rename(m.sessionFile(current), m.sessionFile(target)); err != nil {
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to save session as %s: %w", name, err)
+		}
+		if err := os.WriteFile(m.sessionFile(target), []byte{}, 0644); err != nil {
+			return "", fmt.Errorf("failed to create session file: %w", err)
+		}
+	}
+	if err := os.Rename(metaPath(m.sessionFile(current)), metaPath(m.sessionFile(target))); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to move session metadata: %w", err)
+	}
+
+	// Chats bound to the renamed session follow it
+	for address, bound := range m.bindings {
+		if bound == current {
+			m.bindings[address] = target
+		}
+	}
+	m.bindings[sessionID] = target
+
+	if err := m.saveBindings(); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// Resume binds sessionID to an existing named session. A named session is
+// bound to one chat at a time: other chats bound to it return to their own history.
+// Returns the named session ID.
+func (m *Manager) Resume(sessionID, name string) (string, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	target := NamedSessionID(name)
+	if _, err := os.Stat(m.sessionFile(target)); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrNamedSessionNotFound, name)
+	}
+
+	for address, bound := range m.bindings {
+		if bound == target {
+			delete(m.bindings, address)
+		}
+	}
+	m.bindings[sessionID] = target
+
+	if err := m.saveBindings(); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// Detach removes the binding of sessionID, returning it to its own history.
+// Reports whether the session was bound.
+func (m *Manager) Detach(sessionID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.bindings[sessionID]; !ok {
+		return false, nil
+	}
+	delete(m.bindings, sessionID)
+	return true, m.saveBindings()
+}
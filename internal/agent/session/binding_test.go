@@ -0,0 +1,142 @@
+package session
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+func TestManager_SaveAsAndResume(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	sess, _, _ := mgr.GetOrCreate("telegram:1")
+	_ = sess.Append(llm.Message{Role: llm.RoleUser, Content: "plan the migration"})
+	_ = sess.WriteMeta(Meta{Title: "Migration"})
+
+	named, err := mgr.SaveAs("telegram:1", "Project-X")
+	if err != nil {
+		t.Fatalf("SaveAs() error = %v", err)
+	}
+	if named != "named:project-x" || mgr.Resolve("telegram:1") != named {
+		t.Fatalf("Expected chat to be bound to %s, got %s", named, mgr.Resolve("telegram:1"))
+	}
+
+	// History and metadata moved to the named session
+	sess, _, _ = mgr.GetOrCreate("telegram:1")
+	if sess.ID != named {
+		t.Errorf("GetOrCreate() should open the bound session, got %s", sess.ID)
+	}
+	if count, _ := sess.MessageCount(); count != 1 {
+		t.Errorf("Expected 1 message in named session, got %d", count)
+	}
+	if meta, _ := sess.ReadMeta(); meta.Title != "Migration" {
+		t.Errorf("Expected metadata to move with the session, got %+v", meta)
+	}
+
+	// Resume in another chat moves the binding
+	if _, err := mgr.Resume("telegram:2", "project-x"); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if mgr.Resolve("telegram:2") != named || mgr.Resolve("telegram:1") != "telegram:1" {
+		t.Error("Expected binding to move to the resuming chat")
+	}
+
+	// Bindings survive restarts
+	reloaded, err := NewManager(dir)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if reloaded.Resolve("telegram:2") != named {
+		t.Error("Expected binding to be persisted")
+	}
+
+	infos, _ := reloaded.List()
+	for _, info := range infos {
+		if info.ID == named && (info.Name != "project-x" || len(info.BoundTo) != 1) {
+			t.Errorf("Unexpected named session info: %+v", info)
+		}
+	}
+
+	detached, err := reloaded.Detach("telegram:2")
+	if err != nil || !detached || reloaded.Resolve("telegram:2") != "telegram:2" {
+		t.Errorf("Detach() = %v, %v", detached, err)
+	}
+}
+
+func TestManager_SaveAsErrors(t *testing.T) {
+	mgr, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	if _, err := mgr.SaveAs("telegram:1", "bad name!"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("Expected ErrInvalidName, got %v", err)
+	}
+
+	// Saving a chat without history creates an empty named session
+	if _, err := mgr.SaveAs("telegram:1", "alpha"); err != nil {
+		t.Fatalf("SaveAs() error = %v", err)
+	}
+	if _, err := mgr.SaveAs("telegram:2", "alpha"); !errors.Is(err, ErrNameTaken) {
+		t.Errorf("Expected ErrNameTaken, got %v", err)
+	}
+	if _, err := mgr.Resume("telegram:2", "beta"); !errors.Is(err, ErrNamedSessionNotFound) {
+		t.Errorf("Expected ErrNamedSessionNotFound, got %v", err)
+	}
+
+	// Saving a bound chat under a new name renames the named session
+	renamed, err := mgr.SaveAs("telegram:1", "gamma")
+	if err != nil {
+		t.Fatalf("SaveAs() rename error = %v", err)
+	}
+	if exists, _ := mgr.Exists("telegram:1"); !exists || mgr.Resolve("telegram:1") != renamed {
+		t.Error("Expected chat to follow the renamed session")
+	}
+	if _, err := mgr.Resume("telegram:3", "alpha"); !errors.Is(err, ErrNamedSessionNotFound) {
+		t.Error("Expected old name to be gone after rename")
+	}
+}
+
+func TestManager_Erase(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManager(dir)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	sess, _, _ := mgr.GetOrCreate("telegram:1")
+	_ = sess.Append(llm.Message{Role: llm.RoleUser, Content: "secret"})
+	_ = sess.WriteMeta(Meta{Title: "Secret"})
+	named, err := mgr.SaveAs("telegram:1", "private")
+	if err != nil {
+		t.Fatalf("SaveAs() error = %v", err)
+	}
+	other, _, _ := mgr.GetOrCreate("telegram:2")
+	_ = other.Append(llm.Message{Role: llm.RoleUser, Content: "keep"})
+
+	if files := mgr.Files("telegram:1"); len(files) != 2 {
+		t.Errorf("Files() = %v, want history and metadata of the named session", files)
+	}
+
+	erased, err := mgr.Erase("telegram:1")
+	if err != nil {
+		t.Fatalf("Erase() error = %v", err)
+	}
+	if len(erased) != 1 || erased[0] != named {
+		t.Errorf("Erase() = %v, want [%s]", erased, named)
+	}
+	if mgr.Resolve("telegram:1") != "telegram:1" {
+		t.Error("binding must be removed")
+	}
+	if len(mgr.Files("telegram:1")) != 0 {
+		t.Error("files left after Erase()")
+	}
+	if exists, _ := mgr.Exists("telegram:2"); !exists {
+		t.Error("other sessions must be kept")
+	}
+}
@@ -0,0 +1,172 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ContextSeparator separates the chat session ID and the name of a context
+	// in the session ID of the context ("telegram:123@work")
+	ContextSeparator = "@"
+
+	// DefaultContext is the name of the chat's own history
+	DefaultContext = "default"
+)
+
+var (
+	// ErrContextNotFound is returned when deleting an unknown context
+	ErrContextNotFound = errors.New("context not found")
+
+	// ErrContextInUse is returned when deleting the current or the default context
+	ErrContextInUse = errors.New("context is in use")
+)
+
+// ContextInfo summarizes a context of a chat.
+type ContextInfo struct {
+	Name         string
+	Current      bool
+	Title        string
+	MessageCount int
+	LastActivity time.Time
+}
+
+// ContextSessionID returns the session ID storing a context of a chat. The
+// default context is the chat session itself.
+func ContextSessionID(sessionID, name string) string {
+	if name == DefaultContext {
+		return sessionID
+	}
+	return sessionID + ContextSeparator + name
+}
+
+// SwitchContext continues a chat in one of its named contexts, creating the
+// context if needed. Each context has a history of its own; the chat stays
+// bound to the context until it switches again. Switching to DefaultContext
+// returns the chat to its own history. Returns the normalized name.
+func (m *Manager) SwitchContext(sessionID, name string) (string, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if name == DefaultContext {
+		if _, ok := m.bindings[sessionID]; ok {
+			delete(m.bindings, sessionID)
+			return name, m.saveBindings()
+		}
+		return name, nil
+	}
+
+	target := ContextSessionID(sessionID, name)
+	file := m.sessionFile(target)
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		if err := os.WriteFile(file, []byte{}, 0644); err != nil {
+			return "", fmt.Errorf("failed to create context %s: %w", name, err)
+		}
+	}
+	m.bindings[sessionID] = target
+	return name, m.saveBindings()
+}
+
+// CurrentContext returns the context a chat continues in. ok is false if
+// the chat is bound to a named session instead.
+func (m *Manager) CurrentContext(sessionID string) (name string, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	current := m.resolve(sessionID)
+	if current == sessionID {
+		return DefaultContext, true
+	}
+	return strings.CutPrefix(current, sessionID+ContextSeparator)
+}
+
+// Contexts returns the contexts of a chat: the default context first, the
+// others by name.
+func (m *Manager) Contexts(sessionID string) ([]ContextInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries, err := os.ReadDir(m.baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contexts: %w", err)
+	}
+
+	current := m.resolve(sessionID)
+	contexts := []ContextInfo{m.contextInfo(sessionID, DefaultContext, current)}
+	prefix := sessionID + ContextSeparator
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".jsonl")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		if name, ok := strings.CutPrefix(id, prefix); ok && name != "" {
+			contexts = append(contexts, m.contextInfo(sessionID, name, current))
+		}
+	}
+
+	named := contexts[1:]
+	sort.Slice(named, func(i, j int) bool {
+		return named[i].Name < named[j].Name
+	})
+	return contexts, nil
+}
+
+// contextInfo summarizes a context of a chat. Caller must hold m.mu.
+func (m *Manager) contextInfo(sessionID, name, current string) ContextInfo {
+	id := ContextSessionID(sessionID, name)
+	info := ContextInfo{Name: name, Current: id == current}
+
+	sess := &Session{ID: id, File: m.sessionFile(id), mu: m.fileLock(id), loaded: true}
+	if stat, err := os.Stat(sess.File); err == nil {
+		info.LastActivity = stat.ModTime()
+		info.MessageCount, _ = sess.MessageCount()
+	}
+	if meta, err := readMeta(metaPath(sess.File)); err == nil {
+		info.Title = meta.Title
+	}
+	return info
+}
+
+// DeleteContext deletes a context of a chat with its history. The default
+// context and the context the chat continues in can't be deleted.
+func (m *Manager) DeleteContext(sessionID, name string) error {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := ContextSessionID(sessionID, name)
+	if name == DefaultContext || m.resolve(sessionID) == id {
+		return fmt.Errorf("%w: %s", ErrContextInUse, name)
+	}
+
+	file := m.sessionFile(id)
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", ErrContextNotFound, name)
+	}
+
+	mu := m.fileLock(id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	mu.drop(file)
+	if err := os.Remove(file); err != nil {
+		return fmt.Errorf("failed to delete context %s: %w", name, err)
+	}
+	if err := os.Remove(metaPath(file)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete context metadata: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package session
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+func TestManager_Contexts(t *testing.T) {
+	mgr, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	chat, _, _ := mgr.GetOrCreate("telegram:1")
+	_ = chat.Append(llm.Message{Role: llm.RoleUser, Content: "chat history"})
+
+	if _, err := mgr.SwitchContext("telegram:1", "work"); err != nil {
+		t.Fatalf("SwitchContext() error = %v", err)
+	}
+	work, _, _ := mgr.GetOrCreate("telegram:1")
+	if work.ID != "telegram:1@work" {
+		t.Fatalf("Expected the work context, got %s", work.ID)
+	}
+	_ = work.Append(llm.Message{Role: llm.RoleUser, Content: "work 1"})
+	_ = work.Append(llm.Message{Role: llm.RoleAssistant, Content: "work 2"})
+
+	if name, ok := mgr.CurrentContext("telegram:1"); !ok || name != "work" {
+		t.Errorf("CurrentContext() = %s, %v", name, ok)
+	}
+
+	// Contexts of other chats are not listed
+	_, _ = mgr.SwitchContext("telegram:2", "personal")
+
+	contexts, err := mgr.Contexts("telegram:1")
+	if err != nil {
+		t.Fatalf("Contexts() error = %v", err)
+	}
+	if len(contexts) != 2 || contexts[0].Name != DefaultContext || contexts[0].MessageCount != 1 ||
+		contexts[1].Name != "work" || !contexts[1].Current || contexts[1].MessageCount != 2 {
+		t.Errorf("Unexpected contexts: %+v", contexts)
+	}
+
+	if err := mgr.DeleteContext("telegram:1", "work"); !errors.Is(err, ErrContextInUse) {
+		t.Errorf("DeleteContext(current) error = %v", err)
+	}
+
+	// Switching back keeps the context
+	if _, err := mgr.SwitchContext("telegram:1", DefaultContext); err != nil {
+		t.Fatalf("SwitchContext(default) error = %v", err)
+	}
+	history, _, _ := mgr.GetOrCreate("telegram:1")
+	if messages, _ := history.Read(); len(messages) != 1 || messages[0].Content != "chat history" {
+		t.Errorf("Expected the chat history back, got %+v", messages)
+	}
+
+	if err := mgr.DeleteContext("telegram:1", "work"); err != nil {
+		t.Fatalf("DeleteContext() error = %v", err)
+	}
+	if err := mgr.DeleteContext("telegram:1", "work"); !errors.Is(err, ErrContextNotFound) {
+		t.Errorf("DeleteContext(deleted) error = %v", err)
+	}
+	if _, err := mgr.SwitchContext("telegram:1", "Bad Name"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("SwitchContext(invalid) error = %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+var (
+	// ErrBusy is returned by TryLock when another turn of the session is in flight.
+	ErrBusy = errors.New("session is busy with another turn")
+
+	// ErrConflict is returned by AppendAt when the session was changed after
+	// the expected version, e.g. cleared while a turn was in flight.
+	ErrConflict = errors.New("session was changed by another turn")
+)
+
+// fileLock is the lock of the history file of a session ID.
+type fileLock struct {
+	sync.Mutex
+
+	// dropped counts the bytes of history cleared or deleted, so that the
+	// version of the history keeps growing when it starts over
+	dropped int64
+}
+
+// fileLock returns the lock of the history file of a session ID, shared by
+// all Session values of the ID.
+func (m *Manager) fileLock(sessionID string) *fileLock {
+	m.locksMu.Lock()
+	defer m.locksMu.Unlock()
+
+	if m.fileLocks == nil {
+		m.fileLocks = make(map[string]*fileLock)
+	}
+	mu, ok := m.fileLocks[sessionID]
+	if !ok {
+		mu = &fileLock{}
+		m.fileLocks[sessionID] = mu
+	}
+	return mu
+}
+
+// drop accounts for the history file being cleared or deleted. Caller must
+// hold the lock.
+func (l *fileLock) drop(file string) {
+	size := int64(0)
+	if info, err := os.Stat(file); err == nil {
+		size = info.Size()
+	}
+	// One more byte so that an empty history changes version too
+	l.dropped += size + 1
+}
+
+// turnLock returns the turn lock of a session, resolving bindings.
+func (m *Manager) turnLock(sessionID string) chan struct{} {
+	m.mu.RLock()
+	sessionID = m.resolve(sessionID)
+	m.mu.RUnlock()
+
+	m.locksMu.Lock()
+	defer m.locksMu.Unlock()
+
+	if m.turnLocks == nil {
+		m.turnLocks = make(map[string]chan struct{})
+	}
+	lock, ok := m.turnLocks[sessionID]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		m.turnLocks[sessionID] = lock
+	}
+	return lock
+}
+
+// TryLock takes the turn lock of a session, so that turns of one session
+// never interleave their history writes. Returns ErrBusy if another turn
+// holds it. A chat bound to a named session shares the lock of the named
+// session.
+func (m *Manager) TryLock(sessionID string) (unlock func(), err error) {
+	lock := m.turnLock(sessionID)
+	select {
+	case lock <- struct{}{}:
+		return releaser(lock), nil
+	default:
+		return nil, ErrBusy
+	}
+}
+
+// Lock takes the turn lock of a session like TryLock, waiting for the turn
+// in flight. Waiting turns take the lock in the order they asked for it.
+func (m *Manager) Lock(ctx context.Context, sessionID string) (unlock func(), err error) {
+	lock := m.turnLock(sessionID)
+	select {
+	case lock <- struct{}{}:
+		return releaser(lock), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// releaser returns a function releasing a turn lock once.
+func releaser(lock chan struct{}) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-lock })
+	}
+}
+
+// Version returns the version of the session history: the bytes ever written
+// to its file, which only grow, even when the history is cleared. A turn
+// therefore can't mistake a new history of the same size for its own.
+func (s *Session) Version() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.version()
+}
+
+// version returns the bytes ever written to the history file. Caller must
+// hold s.mu.
+func (s *Session) version() (int64, error) {
+	info, err := os.Stat(s.File)
+	if os.IsNotExist(err) {
+		return s.mu.dropped, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat session file: %w", err)
+	}
+	return s.mu.dropped + info.Size(), nil
+}
+
+// AppendAt appends a message like Append if the session is still at version,
+// and returns the new version. Returns ErrConflict if the session was changed
+// in between.
+func (s *Session) AppendAt(version int64, msg llm.Message) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.version()
+	if err != nil {
+		return 0, err
+	}
+	if current != version {
+		return current, ErrConflict
+	}
+
+	data, err := json.Marshal(Entry{
+		Message:   s.scrub(msg),
+		Timestamp: time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return current, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	data = append(data, '\n')
+
+	file, err := os.OpenFile(s.File, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return current, fmt.Errorf("failed to open session file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return current, fmt.Errorf("failed to write message: %w", err)
+	}
+	return current + int64(len(data)), nil
+}
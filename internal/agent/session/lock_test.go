@@ -0,0 +1,151 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+func TestManager_TryLock(t *testing.T) {
+	mgr, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	unlock, err := mgr.TryLock("telegram:1")
+	if err != nil {
+		t.Fatalf("TryLock() error = %v", err)
+	}
+	if _, err := mgr.TryLock("telegram:1"); !errors.Is(err, ErrBusy) {
+		t.Errorf("second TryLock() error = %v, want ErrBusy", err)
+	}
+	// Other sessions are not affected
+	unlockOther, err := mgr.TryLock("telegram:2")
+	if err != nil {
+		t.Fatalf("TryLock() of another session error = %v", err)
+	}
+	unlockOther()
+
+	// A waiting turn runs once the lock is released
+	acquired := make(chan func())
+	go func() {
+		unlock, err := mgr.Lock(context.Background(), "telegram:1")
+		if err != nil {
+			t.Errorf("Lock() error = %v", err)
+		}
+		acquired <- unlock
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("Lock() returned while the session was locked")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlock()
+	unlock() // Releasing twice is harmless
+	(<-acquired)()
+
+	// Waiting ends with the context
+	unlock, _ = mgr.TryLock("telegram:1")
+	defer unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := mgr.Lock(ctx, "telegram:1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Lock() with canceled context error = %v", err)
+	}
+}
+
+func TestManager_TryLock_NamedSession(t *testing.T) {
+	mgr, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if _, err := mgr.SaveAs("telegram:1", "project"); err != nil {
+		t.Fatalf("SaveAs() error = %v", err)
+	}
+
+	unlock, err := mgr.TryLock(NamedSessionID("project"))
+	if err != nil {
+		t.Fatalf("TryLock() error = %v", err)
+	}
+	defer unlock()
+	if _, err := mgr.TryLock("telegram:1"); !errors.Is(err, ErrBusy) {
+		t.Errorf("TryLock() of the bound chat error = %v, want ErrBusy", err)
+	}
+}
+
+func TestSession_AppendAt(t *testing.T) {
+	mgr, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	sess, _, err := mgr.GetOrCreate("telegram:1")
+	if err != nil {
+		t.Fatalf("GetOrCreate() error = %v", err)
+	}
+
+	version, err := sess.Version()
+	if err != nil || version != 0 {
+		t.Fatalf("Version() = %d, %v, want 0", version, err)
+	}
+	version, err = sess.AppendAt(version, llm.Message{Role: llm.RoleUser, Content: "hello"})
+	if err != nil {
+		t.Fatalf("AppendAt() error = %v", err)
+	}
+	if current, _ := sess.Version(); current != version {
+		t.Errorf("Version() = %d, want %d", current, version)
+	}
+
+	// Another writer changes the session: the turn's next write conflicts
+	other, _, _ := mgr.GetOrCreate("telegram:1")
+	if err := other.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if _, err := sess.AppendAt(version, llm.Message{Role: llm.RoleAssistant, Content: "hi"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("AppendAt() after Clear() error = %v, want ErrConflict", err)
+	}
+	if count, _ := sess.MessageCount(); count != 0 {
+		t.Errorf("MessageCount() = %d, want the conflicting message not written", count)
+	}
+}
+
+func TestSession_AppendAt_ClearedToSameSize(t *testing.T) {
+	mgr, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	sess, _, err := mgr.GetOrCreate("telegram:1")
+	if err != nil {
+		t.Fatalf("GetOrCreate() error = %v", err)
+	}
+	version, err := sess.AppendAt(0, llm.Message{Role: llm.RoleUser, Content: "old topic"})
+	if err != nil {
+		t.Fatalf("AppendAt() error = %v", err)
+	}
+
+	// A new history of the same size is still a change of the session
+	other, _, _ := mgr.GetOrCreate("telegram:1")
+	if err := other.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if err := other.Append(llm.Message{Role: llm.RoleUser, Content: "new topic"}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if current, _ := sess.Version(); current <= version {
+		t.Errorf("Version() = %d after Clear(), want more than %d", current, version)
+	}
+	if _, err := sess.AppendAt(version, llm.Message{Role: llm.RoleAssistant, Content: "hi"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("AppendAt() after Clear() error = %v, want ErrConflict", err)
+	}
+
+	// Deleting the history changes the version as well
+	version, _ = sess.Version()
+	if err := other.Delete(); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := sess.AppendAt(version, llm.Message{Role: llm.RoleAssistant, Content: "hi"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("AppendAt() after Delete() error = %v, want ErrConflict", err)
+	}
+}
@@ -0,0 +1,179 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/planner"
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// metaSubdir is the hidden subdirectory of the sessions directory holding
+// per-session metadata. It is a directory so that session scanners that only
+// look at files (e.g. cleanup) are not affected.
+const metaSubdir = ".meta"
+
+// Meta holds session metadata stored next to the session history.
+type Meta struct {
+	Title     string    `json:"title,omitempty"`
+	TitledAt  time.Time `json:"titled_at,omitzero"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+
+	// Plan is the step plan of the latest planned request
+	Plan *planner.Plan `json:"plan,omitempty"`
+
+	// Variant is the label of the prompt experiment variant serving the
+	// session ("experiment/variant")
+	Variant string `json:"variant,omitempty"`
+
+	// Sampling overrides the sampling parameters of the agent and the
+	// prompt variant for the session
+	Sampling *llm.Sampling `json:"sampling,omitempty"`
+
+	// Receipts are the delivery results of the latest messages sent to the
+	// chat of the session, oldest first
+	Receipts []Receipt `json:"receipts,omitempty"`
+}
+
+// Info summarizes a session for listings.
+type Info struct {
+	ID           string
+	Name         string   // Name of a named session
+	BoundTo      []string // Chat sessions currently bound to a named session
+	Title        string
+	Variant      string // Label of the prompt experiment variant serving the session
+	MessageCount int
+	Size         int64
+	LastActivity time.Time
+}
+
+// metaPath returns the metadata file path of a session file.
+func metaPath(sessionFile string) string {
+	name := strings.TrimSuffix(filepath.Base(sessionFile), ".jsonl")
+	return filepath.Join(filepath.Dir(sessionFile), metaSubdir, name+".json")
+}
+
+// ReadMeta reads session metadata. Returns empty metadata if none was stored.
+func (s *Session) ReadMeta() (Meta, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return readMeta(metaPath(s.File))
+}
+
+// WriteMeta stores session metadata.
+func (s *Session) WriteMeta(meta Meta) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writeMeta(meta)
+}
+
+// writeMeta stores session metadata. Caller must hold s.mu.
+func (s *Session) writeMeta(meta Meta) error {
+	path := metaPath(s.File)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create session metadata directory: %w", err)
+	}
+
+	if s.scrubber != nil {
+		meta.Title = s.scrubber.Scrub(meta.Title)
+	}
+	meta.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session metadata: %w", err)
+	}
+
+	// Write to temp file first, then rename for atomicity
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session metadata: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to save session metadata: %w", err)
+	}
+	return nil
+}
+
+// removeMeta deletes session metadata. Caller must hold s.mu.
+func (s *Session) removeMeta() error {
+	if err := os.Remove(metaPath(s.File)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete session metadata: %w", err)
+	}
+	return nil
+}
+
+func readMeta(path string) (Meta, error) {
+	var meta Meta
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return meta, nil
+	}
+	if err != nil {
+		return meta, fmt.Errorf("failed to read session metadata: %w", err)
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return meta, fmt.Errorf("failed to parse session metadata: %w", err)
+	}
+	return meta, nil
+}
+
+// List returns all top-level sessions, most recently active first.
+// Subagent session directories are not included.
+func (m *Manager) List() ([]Info, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries, err := os.ReadDir(m.baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	bound := map[string][]string{}
+	for address, target := range m.bindings {
+		bound[target] = append(bound[target], address)
+	}
+
+	var infos []Info
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".jsonl" {
+			continue
+		}
+		fileInfo, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		id := strings.TrimSuffix(entry.Name(), ".jsonl")
+		sess := &Session{
+			ID:     id,
+			File:   filepath.Join(m.baseDir, entry.Name()),
+			mu:     m.fileLock(id),
+			loaded: true,
+		}
+		count, _ := sess.MessageCount()
+		meta, _ := readMeta(metaPath(sess.File))
+
+		name, _ := SessionName(sess.ID)
+
+		infos = append(infos, Info{
+			ID:           sess.ID,
+			Name:         name,
+			BoundTo:      bound[sess.ID],
+			Title:        meta.Title,
+			Variant:      meta.Variant,
+			MessageCount: count,
+			Size:         fileInfo.Size(),
+			LastActivity: fileInfo.ModTime(),
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].LastActivity.After(infos[j].LastActivity)
+	})
+	return infos, nil
+}
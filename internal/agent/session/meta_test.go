@@ -0,0 +1,115 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+func TestSessionMeta_ReadWriteClear(t *testing.T) {
+	mgr, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	sess, _, err := mgr.GetOrCreate("telegram:1")
+	if err != nil {
+		t.Fatalf("GetOrCreate() error = %v", err)
+	}
+
+	meta, err := sess.ReadMeta()
+	if err != nil || meta.Title != "" {
+		t.Fatalf("Expected empty metadata, got %+v (err: %v)", meta, err)
+	}
+
+	if err := sess.WriteMeta(Meta{Title: "Deploy pipeline"}); err != nil {
+		t.Fatalf("WriteMeta() error = %v", err)
+	}
+	meta, _ = sess.ReadMeta()
+	if meta.Title != "Deploy pipeline" || meta.UpdatedAt.IsZero() {
+		t.Errorf("Unexpected metadata: %+v", meta)
+	}
+
+	if err := sess.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	meta, _ = sess.ReadMeta()
+	if meta.Title != "" {
+		t.Error("Expected title to be removed when session is cleared")
+	}
+}
+
+func TestManager_List(t *testing.T) {
+	mgr, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	first, _, _ := mgr.GetOrCreate("telegram:1")
+	_ = first.Append(llm.Message{Role: llm.RoleUser, Content: "hi"})
+	_ = first.WriteMeta(Meta{Title: "Greeting"})
+
+	second, _, _ := mgr.GetOrCreate("telegram:2")
+	_ = second.Append(llm.Message{Role: llm.RoleUser, Content: "a"})
+	_ = second.Append(llm.Message{Role: llm.RoleAssistant, Content: "b"})
+
+	infos, err := mgr.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("Expected 2 sessions (metadata directory excluded), got %d", len(infos))
+	}
+
+	byID := map[string]Info{}
+	for _, info := range infos {
+		byID[info.ID] = info
+	}
+	if byID["telegram:1"].Title != "Greeting" || byID["telegram:1"].MessageCount != 1 {
+		t.Errorf("Unexpected info: %+v", byID["telegram:1"])
+	}
+	if byID["telegram:2"].MessageCount != 2 || byID["telegram:2"].Title != "" {
+		t.Errorf("Unexpected info: %+v", byID["telegram:2"])
+	}
+}
+
+func TestTitleRequest(t *testing.T) {
+	history := []llm.Message{
+		{Role: llm.RoleUser, Content: "How do I rotate nginx logs?"},
+		{Role: llm.RoleAssistant, Content: "", ToolCalls: []llm.ToolCall{{Name: "shell"}}},
+		{Role: llm.RoleTool, Content: "logrotate output"},
+		{Role: llm.RoleAssistant, Content: "Use logrotate."},
+	}
+
+	req := TitleRequest(history, "glm-4")
+	if len(req.Messages) != 2 || req.Model != "glm-4" {
+		t.Fatalf("Unexpected request: %+v", req)
+	}
+	content := req.Messages[1].Content
+	if !strings.Contains(content, "rotate nginx logs") || strings.Contains(content, "logrotate output") {
+		t.Errorf("Unexpected conversation excerpt: %q", content)
+	}
+
+	if UserTurns(history) != 1 {
+		t.Errorf("UserTurns() = %d, want 1", UserTurns(history))
+	}
+}
+
+func TestCleanTitle(t *testing.T) {
+	tests := map[string]string{
+		`"Nginx log rotation"`:               "Nginx log rotation",
+		"Title: Nginx log rotation.":         "Nginx log rotation",
+		"\n## **Deploy  setup**\nextra line": "Deploy setup",
+		"«Ротация логов»":                    "Ротация логов",
+	}
+	for input, want := range tests {
+		if got := CleanTitle(input); got != want {
+			t.Errorf("CleanTitle(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	long := CleanTitle(strings.Repeat("word ", 30))
+	if len([]rune(long)) > maxTitleRunes || !strings.HasSuffix(long, "…") {
+		t.Errorf("Expected long title to be truncated, got %q", long)
+	}
+}
@@ -0,0 +1,56 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// MaxReceipts is the number of delivery receipts kept per session.
+const MaxReceipts = 20
+
+// Receipt is the delivery result of a message sent to the chat of a session.
+// Channels report whether a message was sent, not whether it was read.
+type Receipt struct {
+	CorrelationID string    `json:"correlation_id"`
+	MessageID     string    `json:"message_id,omitempty"` // ID of the message in the channel
+	Type          string    `json:"type,omitempty"`
+	Success       bool      `json:"success"`
+	Duplicate     bool      `json:"duplicate,omitempty"` // A repeated send that was skipped
+	Error         string    `json:"error,omitempty"`
+	Time          time.Time `json:"time"`
+}
+
+// AddReceipt records a delivery receipt in the session metadata, keeping the
+// latest MaxReceipts.
+func (s *Session) AddReceipt(r Receipt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	meta, err := readMeta(metaPath(s.File))
+	if err != nil {
+		return err
+	}
+	meta.Receipts = append(meta.Receipts, r)
+	if n := len(meta.Receipts) - MaxReceipts; n > 0 {
+		meta.Receipts = meta.Receipts[n:]
+	}
+	return s.writeMeta(meta)
+}
+
+// Receipts returns the delivery receipts of a session, oldest first. A session
+// that doesn't exist has none.
+func (m *Manager) Receipts(sessionID string) ([]Receipt, error) {
+	sess, err := m.Get(sessionID)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	meta, err := sess.ReadMeta()
+	if err != nil {
+		return nil, err
+	}
+	return meta.Receipts, nil
+}
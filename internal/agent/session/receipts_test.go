@@ -0,0 +1,46 @@
+package session
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+func TestSession_AddReceipt(t *testing.T) {
+	mgr, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if receipts, err := mgr.Receipts("telegram:1"); err != nil || receipts != nil {
+		t.Fatalf("Receipts(missing session) = %v, %v", receipts, err)
+	}
+
+	sess, _, _ := mgr.GetOrCreate("telegram:1")
+	_ = sess.Append(llm.Message{Role: llm.RoleUser, Content: "hi"})
+	if err := sess.WriteMeta(Meta{Title: "Greeting"}); err != nil {
+		t.Fatalf("WriteMeta() error = %v", err)
+	}
+
+	for i := range MaxReceipts + 2 {
+		if err := sess.AddReceipt(Receipt{CorrelationID: fmt.Sprintf("c%d", i), Success: true}); err != nil {
+			t.Fatalf("AddReceipt() error = %v", err)
+		}
+	}
+
+	receipts, err := mgr.Receipts("telegram:1")
+	if err != nil {
+		t.Fatalf("Receipts() error = %v", err)
+	}
+	if len(receipts) != MaxReceipts {
+		t.Fatalf("Expected %d receipts, got %d", MaxReceipts, len(receipts))
+	}
+	if receipts[0].CorrelationID != "c2" || receipts[MaxReceipts-1].CorrelationID != fmt.Sprintf("c%d", MaxReceipts+1) {
+		t.Errorf("Expected the latest receipts, got %s..%s", receipts[0].CorrelationID, receipts[MaxReceipts-1].CorrelationID)
+	}
+
+	meta, _ := sess.ReadMeta()
+	if meta.Title != "Greeting" {
+		t.Errorf("Expected the title to be kept, got %q", meta.Title)
+	}
+}
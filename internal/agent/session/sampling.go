@@ -0,0 +1,47 @@
+package session
+
+import (
+	"errors"
+	"os"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// Sampling returns the sampling overrides of a session. A session that
+// doesn't exist has none.
+func (m *Manager) Sampling(sessionID string) (llm.Sampling, error) {
+	sess, err := m.Get(sessionID)
+	if errors.Is(err, os.ErrNotExist) {
+		return llm.Sampling{}, nil
+	}
+	if err != nil {
+		return llm.Sampling{}, err
+	}
+	meta, err := sess.ReadMeta()
+	if err != nil || meta.Sampling == nil {
+		return llm.Sampling{}, err
+	}
+	return *meta.Sampling, nil
+}
+
+// SetSampling stores the sampling overrides of a session, creating the
+// session if needed. Zero overrides clear them.
+func (m *Manager) SetSampling(sessionID string, sampling llm.Sampling) error {
+	sess, _, err := m.GetOrCreate(sessionID)
+	if err != nil {
+		return err
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	meta, err := readMeta(metaPath(sess.File))
+	if err != nil {
+		return err
+	}
+	meta.Sampling = nil
+	if !sampling.IsZero() {
+		meta.Sampling = &sampling
+	}
+	return sess.writeMeta(meta)
+}
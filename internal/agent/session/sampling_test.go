@@ -0,0 +1,36 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+func TestManager_SetSampling(t *testing.T) {
+	mgr, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if s, err := mgr.Sampling("telegram:1"); err != nil || !s.IsZero() {
+		t.Fatalf("Sampling(missing session) = %+v, %v", s, err)
+	}
+
+	if err := mgr.SetSampling("telegram:1", llm.Sampling{TopP: 0.9, Stop: []string{"END"}}); err != nil {
+		t.Fatalf("SetSampling() error = %v", err)
+	}
+	s, err := mgr.Sampling("telegram:1")
+	if err != nil {
+		t.Fatalf("Sampling() error = %v", err)
+	}
+	if s.TopP != 0.9 || len(s.Stop) != 1 || s.Stop[0] != "END" {
+		t.Errorf("Unexpected overrides: %+v", s)
+	}
+
+	if err := mgr.SetSampling("telegram:1", llm.Sampling{}); err != nil {
+		t.Fatalf("SetSampling(reset) error = %v", err)
+	}
+	sess, _ := mgr.Get("telegram:1")
+	if meta, _ := sess.ReadMeta(); meta.Sampling != nil {
+		t.Errorf("Expected the overrides to be cleared, got %+v", meta.Sampling)
+	}
+}
@@ -0,0 +1,233 @@
+package session
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/textutil"
+)
+
+// DefaultSearchLimit is the number of matches returned when SearchOptions.Limit is 0
+const DefaultSearchLimit = 5
+
+const (
+	// minSearchTokenLen is the shortest query word used for matching
+	minSearchTokenLen = 2
+
+	// minSearchStemLen is the shortest common prefix matching two word forms
+	// ("nginx" and "nginx's", "сервер" and "сервера")
+	minSearchStemLen = 4
+
+	// questionWeight boosts matches in the user message over the reply
+	questionWeight = 2
+
+	// maxQuestionRunes and maxAnswerRunes truncate the texts of a match
+	maxQuestionRunes = 300
+	maxAnswerRunes   = 600
+)
+
+// searchStopWords are frequent words that carry no meaning for a search
+var searchStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "this": true, "that": true, "from": true,
+	"what": true, "about": true, "did": true, "you": true, "me": true, "my": true, "we": true,
+	"что": true, "как": true, "это": true, "для": true, "мне": true, "про": true, "тебя": true,
+	"ты": true, "мы": true, "об": true,
+}
+
+// SearchOptions configures a history search.
+type SearchOptions struct {
+	Query       string
+	AllSessions bool      // Search the named sessions too, not only the sessions of the chat
+	Since       time.Time // Skip exchanges before this time (zero = no limit)
+	Limit       int       // Maximum matches (DefaultSearchLimit if 0)
+}
+
+// Match is an earlier exchange matching a search: a user message and the reply to it.
+type Match struct {
+	SessionID string
+	Title     string // Title of the session, if generated
+	Time      time.Time
+	Question  string
+	Answer    string
+	Score     int
+}
+
+// exchange is a user message and the first text reply to it.
+type exchange struct {
+	time     time.Time
+	question string
+	answer   string
+}
+
+// Search finds earlier exchanges of sessionID matching the query, best first
+// and newer first among equals. The chat's own history, its named contexts
+// and the named session it is bound to are searched; with AllSessions also
+// all named sessions.
+// Histories of other chats are never searched.
+func (m *Manager) Search(sessionID string, opts SearchOptions) ([]Match, error) {
+	query := searchTokens(opts.Query)
+	if len(query) == 0 {
+		return nil, fmt.Errorf("search query has no words to match")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultSearchLimit
+	}
+
+	m.mu.RLock()
+	ids := m.ownedSessions(sessionID)
+	m.mu.RUnlock()
+
+	if opts.AllSessions {
+		infos, err := m.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range infos {
+			if _, named := SessionName(info.ID); named && !slices.Contains(ids, info.ID) {
+				ids = append(ids, info.ID)
+			}
+		}
+	}
+
+	var matches []Match
+	for _, id := range ids {
+		sess := m.open(id)
+		entries, err := sess.ReadEntries()
+		if err != nil {
+			// A chat without history of its own yet
+			continue
+		}
+		meta, _ := sess.ReadMeta()
+
+		for _, ex := range exchanges(entries) {
+			if !opts.Since.IsZero() && ex.time.Before(opts.Since) {
+				continue
+			}
+			score := scoreExchange(query, ex)
+			if score == 0 {
+				continue
+			}
+			matches = append(matches, Match{
+				SessionID: id,
+				Title:     meta.Title,
+				Time:      ex.time,
+				Question:  textutil.Truncate(strings.TrimSpace(ex.question), maxQuestionRunes),
+				Answer:    textutil.Truncate(strings.TrimSpace(ex.answer), maxAnswerRunes),
+				Score:     score,
+			})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Time.After(matches[j].Time)
+	})
+	if len(matches) > opts.Limit {
+		matches = matches[:opts.Limit]
+	}
+	return matches, nil
+}
+
+// open returns the session of an ID without resolving bindings, so the own
+// history of a bound chat can be read.
+func (m *Manager) open(sessionID string) *Session {
+	return &Session{
+		ID:       sessionID,
+		File:     m.sessionFile(sessionID),
+		mu:       m.fileLock(sessionID),
+		loaded:   true,
+		scrubber: m.scrubber,
+	}
+}
+
+// exchanges groups history entries into answered user messages. Tool calls
+// and results are skipped; the exchange in progress (not answered yet) is
+// left out, so a search doesn't find the request that triggered it.
+func exchanges(entries []Entry) []exchange {
+	var result []exchange
+	var current *exchange
+	for _, entry := range entries {
+		switch entry.Message.Role {
+		case llm.RoleUser:
+			if current != nil && current.answer != "" {
+				result = append(result, *current)
+			}
+			ts, _ := time.Parse(time.RFC3339, entry.Timestamp)
+			current = &exchange{time: ts, question: entry.Message.Content}
+		case llm.RoleAssistant:
+			if current != nil && current.answer == "" && strings.TrimSpace(entry.Message.Content) != "" {
+				current.answer = entry.Message.Content
+			}
+		}
+	}
+	if current != nil && current.answer != "" {
+		result = append(result, *current)
+	}
+	return result
+}
+
+// scoreExchange returns how well an exchange matches the query words: each
+// word found in the user message counts questionWeight, in the reply 1.
+func scoreExchange(query []string, ex exchange) int {
+	question := searchTokens(ex.question)
+	answer := searchTokens(ex.answer)
+
+	score := 0
+	for _, word := range query {
+		match := func(t string) bool { return similarWords(t, word) }
+		switch {
+		case slices.ContainsFunc(question, match):
+			score += questionWeight
+		case slices.ContainsFunc(answer, match):
+			score++
+		}
+	}
+	return score
+}
+
+// similarWords reports whether two words are forms of the same word: they
+// are equal or share a common prefix of at least minSearchStemLen runes that
+// covers the shorter word except for an ending of up to two runes.
+func similarWords(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) > len(rb) {
+		ra, rb = rb, ra
+	}
+	if len(ra) < minSearchStemLen {
+		return false
+	}
+
+	common := 0
+	for common < len(ra) && ra[common] == rb[common] {
+		common++
+	}
+	return common >= minSearchStemLen && common >= len(ra)-2
+}
+
+// searchTokens splits text into unique lower case words without stop words.
+func searchTokens(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var tokens []string
+	seen := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if len([]rune(f)) < minSearchTokenLen || searchStopWords[f] || seen[f] {
+			continue
+		}
+		seen[f] = true
+		tokens = append(tokens, f)
+	}
+	return tokens
+}
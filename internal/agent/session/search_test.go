@@ -0,0 +1,95 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// writeHistory writes messages to a session file, each a day after the previous one.
+func writeHistory(t *testing.T, mgr *Manager, sessionID string, start time.Time, msgs ...llm.Message) {
+	t.Helper()
+	var data []byte
+	for i, msg := range msgs {
+		line, err := json.Marshal(Entry{Message: msg, Timestamp: start.Add(time.Duration(i/2) * 24 * time.Hour).Format(time.RFC3339)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+	if err := os.WriteFile(mgr.sessionFile(sessionID), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManager_Search(t *testing.T) {
+	mgr, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	start := time.Now().Add(-10 * 24 * time.Hour)
+
+	writeHistory(t, mgr, "telegram:1", start,
+		llm.Message{Role: llm.RoleUser, Content: "How do I configure nginx as a reverse proxy?"},
+		llm.Message{Role: llm.RoleAssistant, Content: "Use proxy_pass in a location block."},
+		llm.Message{Role: llm.RoleUser, Content: "What's the weather?"},
+		llm.Message{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{{ID: "1", Name: "web_fetch"}}},
+		llm.Message{Role: llm.RoleTool, Content: "nginx weather service", ToolCallID: "1"},
+		llm.Message{Role: llm.RoleAssistant, Content: "Sunny."},
+		llm.Message{Role: llm.RoleUser, Content: "Reload the nginx config"},
+		llm.Message{Role: llm.RoleAssistant, Content: "Done: nginx -s reload."},
+		llm.Message{Role: llm.RoleUser, Content: "What did I ask about nginx?"},
+	)
+	writeHistory(t, mgr, "telegram:2", start,
+		llm.Message{Role: llm.RoleUser, Content: "nginx logs of another chat"},
+		llm.Message{Role: llm.RoleAssistant, Content: "Private."},
+	)
+	writeHistory(t, mgr, NamedSessionID("server"), start,
+		llm.Message{Role: llm.RoleUser, Content: "Install nginx on the server"},
+		llm.Message{Role: llm.RoleAssistant, Content: "Installed."},
+	)
+
+	matches, err := mgr.Search("telegram:1", SearchOptions{Query: "nginx"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	// Tool results and the unanswered request are not searched; newer first
+	if len(matches) != 2 {
+		t.Fatalf("Search() = %+v, want 2 matches", matches)
+	}
+	if matches[0].Question != "Reload the nginx config" || matches[0].Answer != "Done: nginx -s reload." {
+		t.Errorf("first match = %+v", matches[0])
+	}
+	if matches[1].SessionID != "telegram:1" || matches[1].Time.IsZero() {
+		t.Errorf("second match = %+v", matches[1])
+	}
+
+	// Word forms match; a match in the question outranks one in the reply
+	matches, _ = mgr.Search("telegram:1", SearchOptions{Query: "proxies reload"})
+	if len(matches) != 2 || matches[0].Question != "Reload the nginx config" {
+		t.Errorf("Search(proxies reload) = %+v", matches)
+	}
+
+	// Named sessions are searched on request, other chats never
+	matches, _ = mgr.Search("telegram:1", SearchOptions{Query: "nginx", AllSessions: true})
+	if len(matches) != 3 {
+		t.Fatalf("Search(all) = %+v, want 3 matches", matches)
+	}
+	for _, m := range matches {
+		if m.SessionID == "telegram:2" {
+			t.Errorf("history of another chat found: %+v", m)
+		}
+	}
+
+	matches, _ = mgr.Search("telegram:1", SearchOptions{Query: "nginx", Since: start.Add(36 * time.Hour), Limit: 5})
+	if len(matches) != 1 || matches[0].Question != "Reload the nginx config" {
+		t.Errorf("Search(since) = %+v", matches)
+	}
+
+	if _, err := mgr.Search("telegram:1", SearchOptions{Query: "what about"}); err == nil {
+		t.Error("Search() with only stop words should fail")
+	}
+}
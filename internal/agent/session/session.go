@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,10 +15,18 @@ import (
 
 // Session represents a chat session with messages stored in JSONL format.
 type Session struct {
-	ID     string     // Unique session identifier
-	File   string     // Path to JSONL file
-	mu     sync.Mutex // Protects file operations
-	loaded bool       // Track if session was just created
+	ID     string    // Unique session identifier
+	File   string    // Path to JSONL file
+	mu     *fileLock // Protects file operations, shared by the Session values of one ID
+	loaded bool      // Track if session was just created
+
+	scrubber Scrubber // Masks personal data before it is stored (nil — off)
+}
+
+// Scrubber masks personal data in text before it is written to disk
+// (implemented by pii.Scrubber).
+type Scrubber interface {
+	Scrub(text string) string
 }
 
 // Entry represents a single entry in the JSONL session file.
@@ -27,9 +37,17 @@ type Entry struct {
 }
 
 // Manager manages sessions stored as JSONL files.
+// Chat sessions can be bound to named sessions (see SaveAs and Resume);
+// sessions are always opened by the resolved ID.
 type Manager struct {
-	baseDir string // Base directory for session files
-	mu      sync.RWMutex
+	baseDir  string // Base directory for session files
+	mu       sync.RWMutex
+	bindings map[string]string // Chat session ID -> named session ID
+	scrubber Scrubber          // Applied to messages and titles of opened sessions
+
+	locksMu   sync.Mutex
+	fileLocks map[string]*fileLock     // Session ID -> lock of its history file
+	turnLocks map[string]chan struct{} // Session ID -> lock of its agent turn (see Lock)
 }
 
 // NewManager creates a new session manager with the specified base directory.
@@ -43,9 +61,26 @@ func NewManager(baseDir string) (*Manager, error) {
 		return nil, fmt.Errorf("failed to create base directory: %w", err)
 	}
 
-	return &Manager{
+	m := &Manager{
 		baseDir: baseDir,
-	}, nil
+	}
+	if err := m.loadBindings(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SetScrubber enables masking of personal data in stored messages and
+// titles of sessions opened afterwards.
+func (m *Manager) SetScrubber(scrubber Scrubber) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scrubber = scrubber
+}
+
+// sessionFile returns the history file path of a session ID.
+func (m *Manager) sessionFile(sessionID string) string {
+	return filepath.Join(m.baseDir, sessionID+".jsonl")
 }
 
 // Exists проверяет существует ли сессия
@@ -54,7 +89,7 @@ func (m *Manager) Exists(sessionID string) (bool, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	sessionFile := filepath.Join(m.baseDir, sessionID+".jsonl")
+	sessionFile := m.sessionFile(m.resolve(sessionID))
 	_, err := os.Stat(sessionFile)
 	if os.IsNotExist(err) {
 		return false, nil
@@ -71,16 +106,19 @@ func (m *Manager) GetOrCreate(sessionID string) (*Session, bool, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	sessionFile := filepath.Join(m.baseDir, sessionID+".jsonl")
+	sessionID = m.resolve(sessionID)
+	sessionFile := m.sessionFile(sessionID)
 
 	// Check if session file exists
 	_, err := os.Stat(sessionFile)
 	if os.IsNotExist(err) {
 		// Create new session
 		session := &Session{
-			ID:     sessionID,
-			File:   sessionFile,
-			loaded: false,
+			ID:       sessionID,
+			File:     sessionFile,
+			mu:       m.fileLock(sessionID),
+			loaded:   false,
+			scrubber: m.scrubber,
 		}
 
 		// Create empty file
@@ -97,18 +135,42 @@ func (m *Manager) GetOrCreate(sessionID string) (*Session, bool, error) {
 
 	// Return existing session
 	return &Session{
-		ID:     sessionID,
-		File:   sessionFile,
-		loaded: true,
+		ID:       sessionID,
+		File:     sessionFile,
+		mu:       m.fileLock(sessionID),
+		loaded:   true,
+		scrubber: m.scrubber,
 	}, false, nil
 }
 
+// Get retrieves an existing session without creating it.
+// Returns an error wrapping os.ErrNotExist if the session does not exist.
+func (m *Manager) Get(sessionID string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sessionID = m.resolve(sessionID)
+	sessionFile := m.sessionFile(sessionID)
+	if _, err := os.Stat(sessionFile); err != nil {
+		return nil, fmt.Errorf("failed to open session %s: %w", sessionID, err)
+	}
+
+	return &Session{
+		ID:       sessionID,
+		File:     sessionFile,
+		mu:       m.fileLock(sessionID),
+		loaded:   true,
+		scrubber: m.scrubber,
+	}, nil
+}
+
 // Append adds a message to the session.
 // The message is appended as a JSON line to the session file.
 func (s *Session) Append(msg llm.Message) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	msg = s.scrub(msg)
 	entry := Entry{
 		Message:   msg,
 		Timestamp: time.Now().Format(time.RFC3339),
@@ -136,6 +198,23 @@ func (s *Session) Append(msg llm.Message) error {
 	return nil
 }
 
+// scrub masks personal data in the content and tool call arguments of msg.
+func (s *Session) scrub(msg llm.Message) llm.Message {
+	if s.scrubber == nil {
+		return msg
+	}
+	msg.Content = s.scrubber.Scrub(msg.Content)
+	if len(msg.ToolCalls) > 0 {
+		calls := make([]llm.ToolCall, len(msg.ToolCalls))
+		for i, call := range msg.ToolCalls {
+			call.Arguments = s.scrubber.Scrub(call.Arguments)
+			calls[i] = call
+		}
+		msg.ToolCalls = calls
+	}
+	return msg
+}
+
 // Read reads all messages from the session.
 // Returns messages in chronological order (as they were appended).
 func (s *Session) Read() ([]llm.Message, error) {
@@ -169,6 +248,35 @@ func (s *Session) Read() ([]llm.Message, error) {
 	return messages, nil
 }
 
+// ReadEntries reads all entries (messages with timestamps and metadata) from the session.
+// Returns entries in chronological order (as they were appended).
+func (s *Session) ReadEntries() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	var entries []Entry
+	for _, line := range splitLines(data) {
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			// Skip malformed lines
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
 // splitLines splits byte data into lines, handling both \n and \r\n.
 func splitLines(data []byte) [][]byte {
 	var lines [][]byte
@@ -197,11 +305,12 @@ func (s *Session) Delete() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.mu.drop(s.File)
 	if err := os.Remove(s.File); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to delete session file: %w", err)
 	}
 
-	return nil
+	return s.removeMeta()
 }
 
 // Exists checks if the session file exists.
@@ -241,11 +350,100 @@ func (s *Session) Clear() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.mu.drop(s.File)
 	if err := os.WriteFile(s.File, []byte{}, 0644); err != nil {
 		return fmt.Errorf("failed to clear session file: %w", err)
 	}
 
-	return nil
+	// A cleared session starts a new conversation, so its title is outdated
+	return s.removeMeta()
+}
+
+// Files returns the existing history and metadata files of sessionID, of its
+// named contexts and of the named session it is bound to.
+func (m *Manager) Files(sessionID string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var files []string
+	for _, id := range m.ownedSessions(sessionID) {
+		file := m.sessionFile(id)
+		for _, path := range []string{file, metaPath(file)} {
+			if _, err := os.Stat(path); err == nil {
+				files = append(files, path)
+			}
+		}
+	}
+	return files
+}
+
+// Erase deletes the history and metadata of sessionID, of its named contexts
+// and of the named session it is bound to, and removes the binding. Returns
+// the IDs of the deleted sessions.
+func (m *Manager) Erase(sessionID string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var erased []string
+	for _, id := range m.ownedSessions(sessionID) {
+		found, err := m.erase(id)
+		if found {
+			erased = append(erased, id)
+		}
+		if err != nil {
+			return erased, fmt.Errorf("failed to erase session %s: %w", id, err)
+		}
+	}
+
+	if _, ok := m.bindings[sessionID]; ok {
+		delete(m.bindings, sessionID)
+		if err := m.saveBindings(); err != nil {
+			return erased, err
+		}
+	}
+	return erased, nil
+}
+
+// erase deletes the history and metadata files of a session ID. Reports
+// whether any file existed. Caller must hold m.mu.
+func (m *Manager) erase(id string) (bool, error) {
+	mu := m.fileLock(id)
+	mu.Lock()
+	defer mu.Unlock()
+
+	file := m.sessionFile(id)
+	mu.drop(file)
+	found := false
+	for _, path := range []string{file, metaPath(file)} {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return found, err
+		}
+		found = found || err == nil
+	}
+	return found, nil
+}
+
+// ownedSessions returns sessionID, its named contexts and the named session
+// it is bound to. Caller must hold m.mu.
+func (m *Manager) ownedSessions(sessionID string) []string {
+	ids := []string{sessionID}
+	if entries, err := os.ReadDir(m.baseDir); err == nil {
+		prefix := sessionID + ContextSeparator
+		for _, entry := range entries {
+			id, ok := strings.CutSuffix(entry.Name(), ".jsonl")
+			if entry.IsDir() || !ok {
+				continue
+			}
+			if name, ok := strings.CutPrefix(id, prefix); ok && name != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if target := m.resolve(sessionID); !slices.Contains(ids, target) {
+		ids = append(ids, target)
+	}
+	return ids
 }
 
 // DeleteSession removes a session directory by sessionID.
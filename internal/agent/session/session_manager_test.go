@@ -197,6 +197,47 @@ func TestAppend(t *testing.T) {
 	})
 }
 
+// replaceScrubber masks a fixed string.
+type replaceScrubber struct{ old, mark string }
+
+func (r replaceScrubber) Scrub(text string) string {
+	return strings.ReplaceAll(text, r.old, r.mark)
+}
+
+func TestAppend_Scrubber(t *testing.T) {
+	mgr, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	mgr.SetScrubber(replaceScrubber{old: "bob@example.com", mark: "[email]"})
+
+	session, _, err := mgr.GetOrCreate("telegram:1")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	calls := []llm.ToolCall{{ID: "1", Name: "send", Arguments: `{"to":"bob@example.com"}`}}
+	if err := session.Append(llm.Message{Role: llm.RoleAssistant, Content: "Mail bob@example.com", ToolCalls: calls}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := session.WriteMeta(Meta{Title: "Mail to bob@example.com"}); err != nil {
+		t.Fatalf("WriteMeta() error = %v", err)
+	}
+
+	data, err := os.ReadFile(session.File)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(data), "bob@example.com") {
+		t.Errorf("stored history contains personal data: %s", data)
+	}
+	if calls[0].Arguments != `{"to":"bob@example.com"}` {
+		t.Error("Append() must not modify the caller's tool calls")
+	}
+	if meta, _ := session.ReadMeta(); meta.Title != "Mail to [email]" {
+		t.Errorf("stored title = %q, want masked", meta.Title)
+	}
+}
+
 func TestRead(t *testing.T) {
 	tmpDir := t.TempDir()
 	mgr, err := NewManager(tmpDir)
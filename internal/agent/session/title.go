@@ -0,0 +1,81 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/textutil"
+)
+
+const (
+	// maxTitleRunes limits the length of a generated title
+	maxTitleRunes = 60
+
+	// titleContextMessages is the number of conversation messages sent for title generation
+	titleContextMessages = 8
+
+	// titleMessageRunes truncates each message sent for title generation
+	titleMessageRunes = 500
+)
+
+const titlePrompt = "Generate a short title (at most 6 words) for the conversation below. " +
+	"Use the language of the conversation. Reply with the title only, without quotes or punctuation at the end."
+
+// UserTurns returns the number of user messages in the history.
+func UserTurns(history []llm.Message) int {
+	n := 0
+	for _, msg := range history {
+		if msg.Role == llm.RoleUser {
+			n++
+		}
+	}
+	return n
+}
+
+// TitleRequest builds an LLM request that asks for a session title.
+// Only the beginning of the conversation is included; tool messages are skipped.
+func TitleRequest(history []llm.Message, model string) llm.ChatRequest {
+	var b strings.Builder
+	count := 0
+	for _, msg := range history {
+		if count >= titleContextMessages {
+			break
+		}
+		if (msg.Role != llm.RoleUser && msg.Role != llm.RoleAssistant) || strings.TrimSpace(msg.Content) == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n\n", msg.Role, textutil.Truncate(strings.TrimSpace(msg.Content), titleMessageRunes))
+		count++
+	}
+
+	return llm.ChatRequest{
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: titlePrompt},
+			{Role: llm.RoleUser, Content: b.String()},
+		},
+		Model:       model,
+		Temperature: 0.3,
+		MaxTokens:   32,
+	}
+}
+
+// CleanTitle normalizes a generated title: takes the first non-empty line,
+// strips quotes, markdown and a "Title:" prefix, and limits the length.
+func CleanTitle(s string) string {
+	var line string
+	for l := range strings.SplitSeq(s, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			line = l
+			break
+		}
+	}
+
+	line = strings.TrimLeft(line, "#*_ ")
+	if before, after, found := strings.Cut(line, ":"); found && strings.EqualFold(strings.TrimSpace(before), "title") {
+		line = after
+	}
+	line = strings.Trim(line, " \t\"'`«»*_.")
+	line = strings.Join(strings.Fields(line), " ")
+	return textutil.Truncate(line, maxTitleRunes)
+}
@@ -0,0 +1,50 @@
+package session
+
+import (
+	"os"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+func TestSession_ReadsHistoryWithoutToolCalls(t *testing.T) {
+	mgr, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	sess, _, err := mgr.GetOrCreate("old-session")
+	if err != nil {
+		t.Fatalf("GetOrCreate() error = %v", err)
+	}
+
+	// Sessions written before assistant tool calls were stored
+	old := `{"message":{"role":"user","content":"Read a.txt"},"timestamp":"2026-01-01T10:00:00Z"}
+{"message":{"role":"assistant","content":""},"timestamp":"2026-01-01T10:00:01Z"}
+{"message":{"role":"tool","content":"hello","tool_call_id":"call_1"},"timestamp":"2026-01-01T10:00:02Z"}
+{"message":{"role":"assistant","content":"It says hello"},"timestamp":"2026-01-01T10:00:03Z"}
+`
+	if err := os.WriteFile(sess.File, []byte(old), 0644); err != nil {
+		t.Fatalf("Failed to write session: %v", err)
+	}
+
+	messages, err := sess.Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(messages) != 4 || messages[1].ToolCalls != nil || messages[2].ToolCallID != "call_1" {
+		t.Fatalf("Read() = %+v", messages)
+	}
+
+	// New messages store their tool calls next to the old ones
+	call := llm.ToolCall{ID: "call_2", Name: "read_file", Arguments: `{"path":"b.txt"}`}
+	if err := sess.Append(llm.Message{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{call}}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	entries, err := sess.ReadEntries()
+	if err != nil {
+		t.Fatalf("ReadEntries() error = %v", err)
+	}
+	if len(entries) != 5 || len(entries[4].Message.ToolCalls) != 1 || entries[4].Message.ToolCalls[0] != call {
+		t.Errorf("ReadEntries() = %+v", entries)
+	}
+}
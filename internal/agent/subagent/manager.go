@@ -270,6 +270,9 @@ func (s *Subagent) Process(ctx context.Context, task string) (string, error) {
 		defer cancel()
 	}
 
+	// Relay partial answers to the parent while the task runs
+	ctx = relayPartial(ctx)
+
 	// Process task through subagent's loop
 	response, err := s.Loop.Process(ctx, s.Session, task)
 	if err != nil {
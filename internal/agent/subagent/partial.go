@@ -0,0 +1,39 @@
+package subagent
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/agent/loop"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// partialStatusChars is how much of a partial answer is relayed as progress
+const partialStatusChars = 200
+
+// relayPartial returns the context for processing a task: partial answers of
+// the subagent are reported as progress of the calling tool (spawn), so the
+// parent can relay them to the user while the task runs. Without it partial
+// answers would go to the stream of the parent request, which only shows the
+// parent's own answer.
+func relayPartial(ctx context.Context) context.Context {
+	return loop.WithStream(ctx, func(text string) {
+		if status := partialStatus(text); status != "" {
+			tools.ReportProgress(ctx, -1, status)
+		}
+	})
+}
+
+// partialStatus returns the last line of a partial answer, shortened from the
+// start to fit into a progress status.
+func partialStatus(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.LastIndex(text, "\n"); i >= 0 {
+		text = strings.TrimSpace(text[i+1:])
+	}
+	runes := []rune(text)
+	if len(runes) <= partialStatusChars {
+		return text
+	}
+	return "…" + string(runes[len(runes)-partialStatusChars+1:])
+}
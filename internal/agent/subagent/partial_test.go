@@ -0,0 +1,78 @@
+package subagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/agent/loop"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamingMockProvider streams its response in the given chunks
+type streamingMockProvider struct {
+	chunks []string
+}
+
+func (m *streamingMockProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	return &llm.ChatResponse{Content: strings.Join(m.chunks, ""), FinishReason: llm.FinishReasonStop}, nil
+}
+
+func (m *streamingMockProvider) ChatStream(ctx context.Context, req llm.ChatRequest, onDelta llm.StreamFunc) (*llm.ChatResponse, error) {
+	for _, chunk := range m.chunks {
+		onDelta(chunk)
+	}
+	return m.Chat(ctx, req)
+}
+
+func (m *streamingMockProvider) SupportsToolCalling() bool {
+	return true
+}
+
+func TestSubagentProcess_RelaysPartialAnswers(t *testing.T) {
+	tempDir := t.TempDir()
+	log := testLogger()
+
+	manager, err := NewManager(Config{
+		SessionDir: tempDir,
+		Logger:     log,
+		LoopConfig: loop.Config{
+			Workspace:   tempDir,
+			SessionDir:  tempDir,
+			LLMProvider: &streamingMockProvider{chunks: []string{"Checking logs", "...\nFound 3 errors"}},
+			Logger:      log,
+		},
+	})
+	require.NoError(t, err)
+
+	var statuses []string
+	ctx := tools.WithProgress(context.Background(), func(p tools.Progress) {
+		statuses = append(statuses, p.Status)
+	})
+
+	// The parent stream must not receive the subagent's answer
+	ctx = loop.WithStream(ctx, func(text string) {
+		t.Errorf("parent stream received %q", text)
+	})
+
+	subagent, err := manager.Spawn(ctx, "parent-123", "Check logs")
+	require.NoError(t, err)
+
+	response, err := subagent.Process(ctx, "Check logs")
+	require.NoError(t, err)
+	assert.Equal(t, "Checking logs...\nFound 3 errors", response)
+	assert.Equal(t, []string{"Checking logs", "Found 3 errors"}, statuses)
+}
+
+func TestPartialStatus(t *testing.T) {
+	assert.Equal(t, "", partialStatus("  \n"))
+	assert.Equal(t, "second line", partialStatus("first line\nsecond line\n"))
+
+	long := partialStatus(strings.Repeat("a", 300) + "end")
+	assert.Len(t, []rune(long), partialStatusChars)
+	assert.True(t, strings.HasPrefix(long, "…"))
+	assert.True(t, strings.HasSuffix(long, "end"))
+}
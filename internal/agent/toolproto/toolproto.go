@@ -0,0 +1,173 @@
+// Package toolproto lets models without native tool calling use tools through
+// a text protocol: the tool definitions are described in a system message,
+// the model requests calls with <tool_call> blocks and tool results are
+// returned in <tool_result> blocks. Requests are encoded for the text
+// protocol and replies are parsed back into native tool calls, so the agent
+// loop and the session history work the same for both kinds of models.
+package toolproto
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+const (
+	callOpen    = "<tool_call>"
+	callClose   = "</tool_call>"
+	resultClose = "</tool_result>"
+
+	protocolPrompt = "You can use tools. To call a tool, reply with one or more blocks in exactly this format and write nothing after them:\n" +
+		callOpen + "\n" + `{"name": "tool_name", "arguments": {"param": "value"}}` + "\n" + callClose + "\n" +
+		"The results are returned in <tool_result> blocks; never write them yourself. " +
+		"When you have everything you need, reply to the user without tool_call blocks.\n\n" +
+		"Available tools:\n"
+)
+
+var (
+	// callPattern matches tool call blocks; an unclosed block runs to the end
+	callPattern = regexp.MustCompile(`(?s)<tool_call>\s*(.*?)\s*(?:</tool_call>|$)`)
+
+	// resultPattern matches tool result blocks written by the model itself
+	resultPattern = regexp.MustCompile(`(?s)<tool_result\b.*?(?:</tool_result>|$)`)
+)
+
+// Prompt describes the protocol and the tools for the system message.
+func Prompt(tools []llm.ToolDefinition) string {
+	var b strings.Builder
+	b.WriteString(protocolPrompt)
+	for _, tool := range tools {
+		fmt.Fprintf(&b, "\n- %s: %s\n", tool.Name, tool.Description)
+		if params, err := json.Marshal(tool.Parameters); err == nil && tool.Parameters != nil {
+			fmt.Fprintf(&b, "  Parameters (JSON Schema): %s\n", params)
+		}
+	}
+	return b.String()
+}
+
+// Encode converts a request for a model without native tool calling: the
+// tool definitions become a system message after the leading system
+// messages, assistant tool calls become <tool_call> blocks and tool results
+// become user messages with <tool_result> blocks.
+func Encode(req llm.ChatRequest) llm.ChatRequest {
+	names := make(map[string]string)
+	messages := make([]llm.Message, 0, len(req.Messages)+1)
+	for _, msg := range req.Messages {
+		switch {
+		case msg.Role == llm.RoleAssistant && len(msg.ToolCalls) > 0:
+			for _, call := range msg.ToolCalls {
+				names[call.ID] = call.Name
+			}
+			messages = append(messages, llm.Message{Role: llm.RoleAssistant, Content: FormatCalls(msg.Content, msg.ToolCalls)})
+		case msg.Role == llm.RoleTool:
+			result := FormatResult(names[msg.ToolCallID], msg.ToolCallID, msg.Content)
+			// Results of one round go into a single user message
+			if last := len(messages) - 1; last >= 0 && messages[last].Role == llm.RoleUser && strings.HasSuffix(messages[last].Content, resultClose) {
+				messages[last].Content += "\n\n" + result
+				continue
+			}
+			messages = append(messages, llm.Message{Role: llm.RoleUser, Content: result})
+		default:
+			messages = append(messages, msg)
+		}
+	}
+
+	if len(req.Tools) > 0 {
+		at := 0
+		for at < len(messages) && messages[at].Role == llm.RoleSystem {
+			at++
+		}
+		messages = append(messages[:at], append([]llm.Message{{Role: llm.RoleSystem, Content: Prompt(req.Tools)}}, messages[at:]...)...)
+	}
+
+	req.Messages = messages
+	req.Tools = nil
+	req.CacheTools = false
+	return req
+}
+
+// FormatCalls renders an assistant message with tool calls as text.
+func FormatCalls(content string, calls []llm.ToolCall) string {
+	var b strings.Builder
+	if content = strings.TrimSpace(content); content != "" {
+		b.WriteString(content)
+		b.WriteString("\n")
+	}
+	for _, call := range calls {
+		arguments := json.RawMessage(call.Arguments)
+		if !json.Valid(arguments) {
+			arguments = json.RawMessage("{}")
+		}
+		data, _ := json.Marshal(struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}{call.Name, arguments})
+		fmt.Fprintf(&b, "%s\n%s\n%s\n", callOpen, data, callClose)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// FormatResult renders a tool result as text.
+func FormatResult(name, id, content string) string {
+	return fmt.Sprintf("<tool_result name=%q id=%q>\n%s\n%s", name, id, content, resultClose)
+}
+
+// Parse extracts tool calls from a reply. Returns the text before the first
+// call and the calls; the reply is returned unchanged when it has no valid
+// calls. Blocks that are not valid JSON are skipped.
+func Parse(content string) (string, []llm.ToolCall) {
+	matches := callPattern.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return content, nil
+	}
+
+	var calls []llm.ToolCall
+	for _, m := range matches {
+		if call, ok := parseCall(content[m[2]:m[3]]); ok {
+			calls = append(calls, call)
+		}
+	}
+	if len(calls) == 0 {
+		return content, nil
+	}
+
+	text := resultPattern.ReplaceAllString(content[:matches[0][0]], "")
+	return strings.TrimSpace(text), calls
+}
+
+// parseCall decodes the JSON of one tool call block.
+func parseCall(block string) (llm.ToolCall, bool) {
+	block = strings.TrimSpace(block)
+	block = strings.TrimPrefix(block, "```json")
+	block = strings.TrimPrefix(block, "```")
+	block = strings.TrimSpace(strings.TrimSuffix(block, "```"))
+
+	var raw struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal([]byte(block), &raw); err != nil || strings.TrimSpace(raw.Name) == "" {
+		return llm.ToolCall{}, false
+	}
+
+	arguments := "{}"
+	if len(raw.Arguments) > 0 && string(raw.Arguments) != "null" {
+		arguments = string(raw.Arguments)
+		// Some models send the arguments as a JSON string
+		var encoded string
+		if json.Unmarshal(raw.Arguments, &encoded) == nil {
+			arguments = encoded
+		}
+	}
+
+	return llm.ToolCall{
+		ID:        "call_" + strings.ReplaceAll(uuid.NewString(), "-", "")[:12],
+		Name:      strings.TrimSpace(raw.Name),
+		Arguments: arguments,
+	}, true
+}
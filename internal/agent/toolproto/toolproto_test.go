@@ -0,0 +1,110 @@
+package toolproto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+func TestParse(t *testing.T) {
+	content := "I'll check the file first.\n" +
+		"<tool_call>\n{\"name\": \"read_file\", \"arguments\": {\"path\": \"notes.md\"}}\n</tool_call>\n" +
+		"<tool_call>```json\n{\"name\": \"list_dir\", \"arguments\": \"{\\\"path\\\": \\\".\\\"}\"}\n```</tool_call>\n" +
+		"<tool_result>made up</tool_result>"
+
+	text, calls := Parse(content)
+	if text != "I'll check the file first." {
+		t.Errorf("Parse() text = %q", text)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("Parse() calls = %+v, want 2", calls)
+	}
+	if calls[0].Name != "read_file" || calls[0].Arguments != `{"path": "notes.md"}` {
+		t.Errorf("first call = %+v", calls[0])
+	}
+	if calls[1].Name != "list_dir" || calls[1].Arguments != `{"path": "."}` {
+		t.Errorf("second call = %+v", calls[1])
+	}
+	if calls[0].ID == "" || calls[0].ID == calls[1].ID {
+		t.Errorf("call IDs = %q, %q, want unique", calls[0].ID, calls[1].ID)
+	}
+}
+
+func TestParse_Unclosed(t *testing.T) {
+	_, calls := Parse(`<tool_call>{"name": "system_time"}`)
+	if len(calls) != 1 || calls[0].Name != "system_time" || calls[0].Arguments != "{}" {
+		t.Errorf("Parse() of an unclosed block = %+v", calls)
+	}
+}
+
+func TestParse_NoCalls(t *testing.T) {
+	for _, content := range []string{
+		"The answer is 42.",
+		"<tool_call>not json</tool_call>",
+		`<tool_call>{"arguments": {}}</tool_call>`,
+	} {
+		text, calls := Parse(content)
+		if text != content || calls != nil {
+			t.Errorf("Parse(%q) = %q, %+v, want the content unchanged", content, text, calls)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	req := Encode(llm.ChatRequest{
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: "You are Nexbot."},
+			{Role: llm.RoleUser, Content: "What's in notes.md and todo.md?"},
+			{Role: llm.RoleAssistant, Content: "Reading both.", ToolCalls: []llm.ToolCall{
+				{ID: "call_1", Name: "read_file", Arguments: `{"path":"notes.md"}`},
+				{ID: "call_2", Name: "read_file", Arguments: `{"path":"todo.md"}`},
+			}},
+			{Role: llm.RoleTool, ToolCallID: "call_1", Content: "notes"},
+			{Role: llm.RoleTool, ToolCallID: "call_2", Content: "todo"},
+		},
+		Tools: []llm.ToolDefinition{{
+			Name:        "read_file",
+			Description: "Reads a file",
+			Parameters:  map[string]any{"type": "object"},
+		}},
+		CacheTools: true,
+	})
+
+	if req.Tools != nil || req.CacheTools {
+		t.Errorf("Encode() kept native tools: %+v", req.Tools)
+	}
+	if len(req.Messages) != 5 {
+		t.Fatalf("Encode() messages = %+v, want 5", req.Messages)
+	}
+
+	if req.Messages[0].Content != "You are Nexbot." || req.Messages[1].Role != llm.RoleSystem ||
+		!strings.Contains(req.Messages[1].Content, `- read_file: Reads a file`) {
+		t.Errorf("tool prompt should follow the system prompt: %+v", req.Messages[:2])
+	}
+
+	assistant := req.Messages[3]
+	if assistant.ToolCalls != nil || !strings.HasPrefix(assistant.Content, "Reading both.\n<tool_call>\n") ||
+		strings.Count(assistant.Content, callOpen) != 2 {
+		t.Errorf("assistant message = %+v", assistant)
+	}
+
+	results := req.Messages[4]
+	if results.Role != llm.RoleUser || strings.Count(results.Content, resultClose) != 2 ||
+		!strings.Contains(results.Content, `<tool_result name="read_file" id="call_2">`+"\ntodo\n") {
+		t.Errorf("tool results = %+v", results)
+	}
+
+	// The encoded calls parse back to the same tools and arguments
+	_, calls := Parse(assistant.Content)
+	if len(calls) != 2 || calls[1].Arguments != `{"path":"todo.md"}` {
+		t.Errorf("round trip calls = %+v", calls)
+	}
+}
+
+func TestEncode_WithoutTools(t *testing.T) {
+	req := Encode(llm.ChatRequest{Messages: []llm.Message{{Role: llm.RoleUser, Content: "hi"}}})
+	if len(req.Messages) != 1 || req.Messages[0].Content != "hi" {
+		t.Errorf("Encode() without tools = %+v", req.Messages)
+	}
+}
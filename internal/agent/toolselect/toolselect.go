@@ -0,0 +1,238 @@
+// Package toolselect trims the tool definitions sent with each LLM request:
+// tools are ranked by how well their name, description and parameters match
+// the user request, and only the top-K relevant tools plus always-on core
+// tools are sent. This saves tokens when many tools are registered.
+package toolselect
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// DefaultTopK is the number of relevant tools sent besides the core tools
+const DefaultTopK = 8
+
+// DefaultCore are tools sent with every request.
+var DefaultCore = []string{"read_file", "write_file", "list_dir", "shell_exec"}
+
+const (
+	// minTokenLen is the shortest token used for matching
+	minTokenLen = 3
+
+	// minStemLen is the shortest common prefix matching two word forms
+	// ("files" and "file", "файлы" and "файл")
+	minStemLen = 4
+
+	// nameWeight boosts matches in the tool name over its description
+	nameWeight = 3
+)
+
+// stopWords are frequent words that carry no meaning for tool selection
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "this": true, "that": true, "from": true,
+	"what": true, "how": true, "can": true, "you": true, "please": true, "are": true, "was": true,
+	"что": true, "как": true, "это": true, "для": true, "мне": true, "пожалуйста": true, "его": true,
+}
+
+// Config configures a Selector.
+type Config struct {
+	TopK int      // Relevant tools sent besides the core tools (DefaultTopK if 0)
+	Core []string // Tools sent with every request (DefaultCore if nil)
+}
+
+// Selector picks the tools relevant to a request.
+type Selector struct {
+	topK int
+	core []string
+}
+
+// New creates a tool selector.
+func New(cfg Config) *Selector {
+	if cfg.TopK <= 0 {
+		cfg.TopK = DefaultTopK
+	}
+	if cfg.Core == nil {
+		cfg.Core = DefaultCore
+	}
+	return &Selector{topK: cfg.TopK, core: cfg.Core}
+}
+
+// Select returns the tools to send for a request: the core tools, the extra
+// tools (e.g. tools already used in the conversation) and the top-K tools
+// matching the query. Tools keep their order, so the tool list is a stable
+// prefix for prompt caching. All tools are returned when trimming would not
+// drop any.
+func (s *Selector) Select(query string, tools []llm.ToolDefinition, extra ...string) []llm.ToolDefinition {
+	if len(tools) <= s.topK {
+		return tools
+	}
+
+	keep := make(map[string]bool, s.topK+len(s.core)+len(extra))
+	for _, name := range s.core {
+		keep[name] = true
+	}
+	for _, name := range extra {
+		keep[name] = true
+	}
+
+	added := 0
+	for _, name := range s.Rank(query, tools) {
+		if added == s.topK {
+			break
+		}
+		if !keep[name] {
+			keep[name] = true
+			added++
+		}
+	}
+
+	selected := make([]llm.ToolDefinition, 0, len(keep))
+	for _, tool := range tools {
+		if keep[tool.Name] {
+			selected = append(selected, tool)
+		}
+	}
+	return selected
+}
+
+// Rank returns the names of the tools matching the query, best match first.
+// Tools that don't match at all are not returned.
+func (s *Selector) Rank(query string, tools []llm.ToolDefinition) []string {
+	queryTokens := uniqueTokens(query)
+	if len(queryTokens) == 0 {
+		return nil
+	}
+
+	docs := make([]document, len(tools))
+	for i, tool := range tools {
+		docs[i] = newDocument(tool)
+	}
+
+	type ranked struct {
+		name  string
+		score float64
+	}
+	var results []ranked
+	for _, doc := range docs {
+		score := 0.0
+		for _, token := range queryTokens {
+			weight := doc.match(token)
+			if weight == 0 {
+				continue
+			}
+			score += float64(weight) * idf(token, docs)
+		}
+		if score > 0 {
+			results = append(results, ranked{doc.name, score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.name
+	}
+	return names
+}
+
+// document is the searchable text of a tool.
+type document struct {
+	name   string
+	title  []string // Tokens of the tool name
+	tokens []string // Tokens of the description and parameters
+}
+
+// newDocument tokenizes a tool definition.
+func newDocument(tool llm.ToolDefinition) document {
+	var text strings.Builder
+	text.WriteString(tool.Description)
+	if props, ok := tool.Parameters["properties"].(map[string]any); ok {
+		for name, prop := range props {
+			text.WriteString(" " + name)
+			if p, ok := prop.(map[string]any); ok {
+				if desc, ok := p["description"].(string); ok {
+					text.WriteString(" " + desc)
+				}
+			}
+		}
+	}
+	return document{
+		name:   tool.Name,
+		title:  uniqueTokens(tool.Name),
+		tokens: uniqueTokens(text.String()),
+	}
+}
+
+// match returns the weight of a query token in the document: nameWeight for
+// a match in the tool name, 1 for the description, 0 if it doesn't match.
+func (d document) match(token string) int {
+	if slices.ContainsFunc(d.title, func(t string) bool { return similar(t, token) }) {
+		return nameWeight
+	}
+	if slices.ContainsFunc(d.tokens, func(t string) bool { return similar(t, token) }) {
+		return 1
+	}
+	return 0
+}
+
+// idf returns the inverse document frequency of a token: tokens that match
+// fewer tools rank them higher.
+func idf(token string, docs []document) float64 {
+	n := 0
+	for _, doc := range docs {
+		if doc.match(token) > 0 {
+			n++
+		}
+	}
+	return math.Log(1 + float64(len(docs))/float64(1+n))
+}
+
+// similar reports whether two tokens are forms of the same word: they are
+// equal or share a common prefix of at least minStemLen runes that covers
+// the shorter token, allowing for inflected endings.
+func similar(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) > len(rb) {
+		ra, rb = rb, ra
+	}
+	if len(ra) < minStemLen {
+		return false
+	}
+
+	common := 0
+	for common < len(ra) && ra[common] == rb[common] {
+		common++
+	}
+	// The shorter word may differ in its last two runes (its ending)
+	return common >= minStemLen && common >= len(ra)-2
+}
+
+// uniqueTokens splits text into lower case word tokens, without short
+// tokens, stop words and duplicates. Identifiers like web_fetch are split
+// into words.
+func uniqueTokens(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var tokens []string
+	seen := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if len([]rune(f)) < minTokenLen || stopWords[f] || seen[f] {
+			continue
+		}
+		seen[f] = true
+		tokens = append(tokens, f)
+	}
+	return tokens
+}
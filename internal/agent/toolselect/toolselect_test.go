@@ -0,0 +1,91 @@
+package toolselect
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// testTools are tool definitions like the ones registered by the agent
+var testTools = []llm.ToolDefinition{
+	{Name: "read_file", Description: "Read the contents of a file in the workspace"},
+	{Name: "write_file", Description: "Write content to a file in the workspace"},
+	{Name: "list_dir", Description: "List files in a directory"},
+	{Name: "shell_exec", Description: "Execute a shell command"},
+	{Name: "web_fetch", Description: "Fetch a web page by URL and return its text"},
+	{Name: "cron", Description: "Schedule reminders and recurring tasks. Напоминания по расписанию", Parameters: map[string]any{
+		"properties": map[string]any{"schedule": map[string]any{"description": "Cron expression or delay"}},
+	}},
+	{Name: "plot", Description: "Draw a chart from data series"},
+	{Name: "transcribe_audio", Description: "Transcribe an audio file or voice message to text"},
+	{Name: "system_time", Description: "Get the current date and time"},
+	{Name: "send_message", Description: "Send a message to a chat"},
+}
+
+func names(tools []llm.ToolDefinition) []string {
+	result := make([]string, len(tools))
+	for i, tool := range tools {
+		result[i] = tool.Name
+	}
+	return result
+}
+
+func TestSelect(t *testing.T) {
+	selector := New(Config{TopK: 2, Core: []string{"read_file"}})
+
+	got := names(selector.Select("Draw a chart of my expenses", testTools))
+	if !slices.Equal(got, []string{"read_file", "plot"}) {
+		t.Errorf("Select() = %v, want core tool and plot", got)
+	}
+
+	// Extra tools are kept; results keep the registry order
+	got = names(selector.Select("Напомни мне завтра, поставь напоминание", testTools, "web_fetch"))
+	if !slices.Equal(got, []string{"read_file", "web_fetch", "cron"}) {
+		t.Errorf("Select() = %v, want core, extra and cron", got)
+	}
+}
+
+func TestSelect_FewTools(t *testing.T) {
+	selector := New(Config{TopK: 20})
+	if got := selector.Select("anything", testTools); len(got) != len(testTools) {
+		t.Errorf("Select() should send all tools when they fit into top-K, got %v", names(got))
+	}
+}
+
+func TestRank(t *testing.T) {
+	selector := New(Config{})
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"what time is it now?", "system_time"},
+		{"fetch https://example.com and summarize the page", "web_fetch"},
+		{"transcribe this voice message", "transcribe_audio"},
+		{"schedule a daily report", "cron"},
+	}
+	for _, tt := range tests {
+		ranked := selector.Rank(tt.query, testTools)
+		if len(ranked) == 0 || ranked[0] != tt.want {
+			t.Errorf("Rank(%q) = %v, want %s first", tt.query, ranked, tt.want)
+		}
+	}
+
+	if ranked := selector.Rank("ok", testTools); ranked != nil {
+		t.Errorf("Rank() without meaningful words = %v, want nil", ranked)
+	}
+}
+
+func TestSimilar(t *testing.T) {
+	for _, pair := range [][2]string{{"files", "file"}, {"напомни", "напоминания"}, {"charts", "chart"}} {
+		if !similar(pair[0], pair[1]) {
+			t.Errorf("similar(%q, %q) = false", pair[0], pair[1])
+		}
+	}
+	for _, pair := range [][2]string{{"file", "filter"}, {"web", "webs"}, {"date", "data"}} {
+		if similar(pair[0], pair[1]) {
+			t.Errorf("similar(%q, %q) = true", pair[0], pair[1])
+		}
+	}
+}
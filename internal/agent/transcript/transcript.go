@@ -0,0 +1,224 @@
+// Package transcript renders session history into human-readable transcripts.
+// It supports Markdown and HTML output and includes tool calls and tool results,
+// so the transcript reflects everything the agent did during the conversation.
+package transcript
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/textutil"
+)
+
+// Format represents the transcript output format.
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown" // Markdown transcript (default)
+	FormatHTML     Format = "html"     // Standalone HTML document
+)
+
+// ParseFormat parses a format name. Empty string defaults to Markdown.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "md", "markdown":
+		return FormatMarkdown, nil
+	case "html", "htm":
+		return FormatHTML, nil
+	default:
+		return "", fmt.Errorf("unsupported transcript format: %s (expected: markdown, html)", s)
+	}
+}
+
+// Extension returns the file extension for the format (without dot).
+func (f Format) Extension() string {
+	if f == FormatHTML {
+		return "html"
+	}
+	return "md"
+}
+
+// Options controls what is included in the transcript.
+type Options struct {
+	Title         string // Transcript title (defaults to session ID)
+	IncludeSystem bool   // Include system messages
+	MaxToolOutput int    // Truncate tool results longer than this (0 = no limit)
+}
+
+// Render renders session entries into a transcript in the requested format.
+func Render(sessionID string, entries []session.Entry, format Format, opts Options) (string, error) {
+	if opts.Title == "" {
+		opts.Title = fmt.Sprintf("Session %s", sessionID)
+	}
+
+	switch format {
+	case FormatMarkdown, "":
+		return renderMarkdown(entries, opts), nil
+	case FormatHTML:
+		return renderHTML(entries, opts), nil
+	default:
+		return "", fmt.Errorf("unsupported transcript format: %s", format)
+	}
+}
+
+// roleLabel returns a display label for a message role.
+func roleLabel(role llm.Role) string {
+	switch role {
+	case llm.RoleUser:
+		return "👤 User"
+	case llm.RoleAssistant:
+		return "🤖 Assistant"
+	case llm.RoleTool:
+		return "🔧 Tool result"
+	case llm.RoleSystem:
+		return "⚙️ System"
+	default:
+		return string(role)
+	}
+}
+
+// formatTimestamp converts an RFC3339 timestamp to a short display form.
+func formatTimestamp(ts string) string {
+	if ts == "" {
+		return ""
+	}
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return ts
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// truncate shortens s to max bytes, keeping valid UTF-8.
+func truncate(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	return textutil.TruncateBytes(s, max) + fmt.Sprintf("\n… (truncated, %d bytes total)", len(s))
+}
+
+// fence returns a code fence that does not collide with backticks in content.
+func fence(content string) string {
+	f := "```"
+	for strings.Contains(content, f) {
+		f += "`"
+	}
+	return f
+}
+
+func renderMarkdown(entries []session.Entry, opts Options) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", opts.Title)
+
+	for _, entry := range entries {
+		msg := entry.Message
+		if msg.Role == llm.RoleSystem && !opts.IncludeSystem {
+			continue
+		}
+
+		fmt.Fprintf(&b, "### %s", roleLabel(msg.Role))
+		if ts := formatTimestamp(entry.Timestamp); ts != "" {
+			fmt.Fprintf(&b, " · %s", ts)
+		}
+		b.WriteString("\n\n")
+
+		switch msg.Role {
+		case llm.RoleTool:
+			content := truncate(msg.Content, opts.MaxToolOutput)
+			if msg.ToolCallID != "" {
+				fmt.Fprintf(&b, "Call `%s`:\n\n", msg.ToolCallID)
+			}
+			f := fence(content)
+			fmt.Fprintf(&b, "%s\n%s\n%s\n\n", f, content, f)
+		default:
+			if msg.Content != "" {
+				b.WriteString(msg.Content)
+				b.WriteString("\n\n")
+			}
+		}
+
+		for _, tc := range msg.ToolCalls {
+			fmt.Fprintf(&b, "**Tool call** `%s` (`%s`):\n\n", tc.Name, tc.ID)
+			f := fence(tc.Arguments)
+			fmt.Fprintf(&b, "%sjson\n%s\n%s\n\n", f, tc.Arguments, f)
+		}
+	}
+
+	return b.String()
+}
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: -apple-system, sans-serif; max-width: 860px; margin: 2em auto; padding: 0 1em; }
+.msg { border-left: 4px solid #ccc; margin: 1em 0; padding: 0.5em 1em; }
+.user { border-color: #2b7de9; }
+.assistant { border-color: #2ea44f; }
+.tool { border-color: #d29922; background: #fafafa; }
+.system { border-color: #888; color: #555; }
+.meta { color: #888; font-size: 0.85em; }
+pre { white-space: pre-wrap; word-wrap: break-word; background: #f4f4f4; padding: 0.5em; }
+</style>
+</head>
+<body>
+<h1>%s</h1>
+`
+
+func renderHTML(entries []session.Entry, opts Options) string {
+	var b strings.Builder
+	title := html.EscapeString(opts.Title)
+	fmt.Fprintf(&b, htmlHeader, title, title)
+
+	for _, entry := range entries {
+		msg := entry.Message
+		if msg.Role == llm.RoleSystem && !opts.IncludeSystem {
+			continue
+		}
+
+		fmt.Fprintf(&b, "<div class=\"msg %s\">\n", html.EscapeString(string(msg.Role)))
+		fmt.Fprintf(&b, "<div class=\"meta\">%s", html.EscapeString(roleLabel(msg.Role)))
+		if ts := formatTimestamp(entry.Timestamp); ts != "" {
+			fmt.Fprintf(&b, " · %s", html.EscapeString(ts))
+		}
+		if msg.ToolCallID != "" {
+			fmt.Fprintf(&b, " · <code>%s</code>", html.EscapeString(msg.ToolCallID))
+		}
+		b.WriteString("</div>\n")
+
+		if msg.Role == llm.RoleTool {
+			fmt.Fprintf(&b, "<pre>%s</pre>\n", html.EscapeString(truncate(msg.Content, opts.MaxToolOutput)))
+		} else if msg.Content != "" {
+			fmt.Fprintf(&b, "<p>%s</p>\n", strings.ReplaceAll(html.EscapeString(msg.Content), "\n", "<br>\n"))
+		}
+
+		for _, tc := range msg.ToolCalls {
+			fmt.Fprintf(&b, "<div class=\"meta\">Tool call <code>%s</code> (<code>%s</code>)</div>\n",
+				html.EscapeString(tc.Name), html.EscapeString(tc.ID))
+			fmt.Fprintf(&b, "<pre>%s</pre>\n", html.EscapeString(tc.Arguments))
+		}
+
+		b.WriteString("</div>\n")
+	}
+
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// SafeFileName converts a session ID (e.g. "telegram:123") into a string
+// safe to use as a file name.
+func SafeFileName(sessionID string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '/', '\\', ' ', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, sessionID)
+}
@@ -0,0 +1,94 @@
+package transcript
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEntries() []session.Entry {
+	return []session.Entry{
+		{Message: llm.Message{Role: llm.RoleSystem, Content: "system prompt"}},
+		{Message: llm.Message{Role: llm.RoleUser, Content: "list files <now>"}, Timestamp: "2026-02-07T10:30:00Z"},
+		{Message: llm.Message{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{
+			{ID: "call_1", Name: "list_dir", Arguments: `{"path":"."}`},
+		}}},
+		{Message: llm.Message{Role: llm.RoleTool, Content: "a.txt\nb.txt", ToolCallID: "call_1"}},
+		{Message: llm.Message{Role: llm.RoleAssistant, Content: "Two files."}},
+	}
+}
+
+func TestRender_Markdown(t *testing.T) {
+	out, err := Render("telegram:1", testEntries(), FormatMarkdown, Options{})
+	require.NoError(t, err)
+
+	assert.Contains(t, out, "# Session telegram:1")
+	assert.Contains(t, out, "2026-02-07 10:30:00")
+	assert.Contains(t, out, "**Tool call** `list_dir` (`call_1`)")
+	assert.Contains(t, out, `{"path":"."}`)
+	assert.Contains(t, out, "```\na.txt\nb.txt\n```")
+	assert.Contains(t, out, "Two files.")
+	assert.NotContains(t, out, "system prompt")
+}
+
+func TestRender_MarkdownIncludeSystem(t *testing.T) {
+	out, err := Render("s", testEntries(), FormatMarkdown, Options{IncludeSystem: true, Title: "Custom"})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(out, "# Custom\n"))
+	assert.Contains(t, out, "system prompt")
+}
+
+func TestRender_HTMLEscapes(t *testing.T) {
+	out, err := Render("s", testEntries(), FormatHTML, Options{})
+	require.NoError(t, err)
+
+	assert.Contains(t, out, "<!DOCTYPE html>")
+	assert.Contains(t, out, "list files &lt;now&gt;")
+	assert.NotContains(t, out, "<now>")
+	assert.Contains(t, out, "<code>list_dir</code>")
+}
+
+func TestRender_TruncatesToolOutput(t *testing.T) {
+	entries := []session.Entry{
+		{Message: llm.Message{Role: llm.RoleTool, Content: strings.Repeat("x", 100)}},
+	}
+
+	out, err := Render("s", entries, FormatMarkdown, Options{MaxToolOutput: 10})
+	require.NoError(t, err)
+
+	assert.Contains(t, out, "truncated, 100 bytes total")
+	assert.NotContains(t, out, strings.Repeat("x", 11))
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{"", FormatMarkdown, false},
+		{"md", FormatMarkdown, false},
+		{"HTML", FormatHTML, false},
+		{"pdf", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.input)
+		if tt.wantErr {
+			assert.Error(t, err, tt.input)
+			continue
+		}
+		require.NoError(t, err, tt.input)
+		assert.Equal(t, tt.want, got, tt.input)
+	}
+}
+
+func TestSafeFileName(t *testing.T) {
+	assert.Equal(t, "telegram_123", SafeFileName("telegram:123"))
+	assert.Equal(t, "a_b_c", SafeFileName("a/b\\c"))
+}
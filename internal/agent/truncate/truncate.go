@@ -0,0 +1,187 @@
+// Package truncate shortens tool results before they are fed back to the
+// LLM, so one large output (a long log, a big file, a verbose command) doesn't
+// eat the context window. Runs of repeated lines are collapsed first; if the
+// result is still over the token budget of the tool, its head and tail are
+// kept and the middle is replaced with a note of how much was omitted.
+package truncate
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxTokens is the budget of tools without their own when Config.MaxTokens is 0
+const DefaultMaxTokens = 4000
+
+const (
+	// minRepeats is the shortest run of identical lines that is collapsed
+	minRepeats = 3
+
+	// headShare is the part of the budget spent on the head of the output;
+	// the rest goes to the tail, where errors and summaries usually are
+	headShare = 0.6
+
+	// minBudget keeps a tiny budget from cutting the output to nothing
+	minBudget = 50
+)
+
+// Config configures a Truncator.
+type Config struct {
+	MaxTokens  int            // Budget of tools without their own (DefaultMaxTokens if 0)
+	ToolTokens map[string]int // Budgets by tool name; 0 or less disables truncation of the tool
+}
+
+// Truncator shortens tool outputs to their token budgets.
+type Truncator struct {
+	maxTokens  int
+	toolTokens map[string]int
+}
+
+// New creates a truncator.
+func New(cfg Config) *Truncator {
+	if cfg.MaxTokens == 0 {
+		cfg.MaxTokens = DefaultMaxTokens
+	}
+	return &Truncator{maxTokens: cfg.MaxTokens, toolTokens: cfg.ToolTokens}
+}
+
+// Budget returns the token budget of a tool; 0 or less means no limit.
+func (t *Truncator) Budget(tool string) int {
+	if budget, ok := t.toolTokens[tool]; ok {
+		return budget
+	}
+	return t.maxTokens
+}
+
+// Truncate shortens the output of a tool to its budget. It reports whether
+// the output was changed.
+func (t *Truncator) Truncate(tool, output string) (string, bool) {
+	budget := t.Budget(tool)
+	if budget <= 0 || EstimateTokens(output) <= budget {
+		return output, false
+	}
+	budget = max(budget, minBudget)
+
+	lines := strings.Split(output, "\n")
+	lines = collapseRepeats(lines)
+	if collapsed := strings.Join(lines, "\n"); EstimateTokens(collapsed) <= budget {
+		return collapsed, true
+	}
+	return keepHeadTail(lines, budget), true
+}
+
+// EstimateTokens approximates the number of tokens of s: about four ASCII
+// characters per token, and two characters per token for other scripts
+// (Cyrillic, CJK), which tokenizers split into smaller pieces.
+func EstimateTokens(s string) int {
+	ascii, other := 0, 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + (other+1)/2
+}
+
+// collapseRepeats replaces runs of identical lines with the line and a note
+// of how many times it repeats.
+func collapseRepeats(lines []string) []string {
+	result := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); {
+		j := i + 1
+		for j < len(lines) && lines[j] == lines[i] {
+			j++
+		}
+		if run := j - i; run >= minRepeats && strings.TrimSpace(lines[i]) != "" {
+			result = append(result, lines[i], fmt.Sprintf("… (previous line repeated %d more times)", run-1))
+		} else {
+			result = append(result, lines[i:j]...)
+		}
+		i = j
+	}
+	return result
+}
+
+// keepHeadTail keeps the lines of the head and the tail that fit into the
+// budget and replaces the rest with a note. A single line longer than its
+// share is cut by characters.
+func keepHeadTail(lines []string, budget int) string {
+	headBudget := int(float64(budget) * headShare)
+	tailBudget := budget - headBudget
+
+	var head []string
+	used := 0
+	for _, line := range lines {
+		cost := EstimateTokens(line) + 1
+		if used+cost > headBudget {
+			break
+		}
+		head = append(head, line)
+		used += cost
+	}
+
+	var tail []string
+	used = 0
+	for i := len(lines) - 1; i >= len(head); i-- {
+		cost := EstimateTokens(lines[i]) + 1
+		if used+cost > tailBudget {
+			break
+		}
+		tail = append(tail, lines[i])
+		used += cost
+	}
+	for i, j := 0, len(tail)-1; i < j; i, j = i+1, j-1 {
+		tail[i], tail[j] = tail[j], tail[i]
+	}
+
+	omitted := lines[len(head) : len(lines)-len(tail)]
+	if len(head) == 0 && len(tail) == 0 {
+		// One huge line (minified JSON, base64): keep its start and end
+		return cutLine(strings.Join(lines, "\n"), headBudget, tailBudget)
+	}
+
+	var b strings.Builder
+	for _, line := range head {
+		b.WriteString(line + "\n")
+	}
+	fmt.Fprintf(&b, "… [%d lines omitted] …", len(omitted))
+	for _, line := range tail {
+		b.WriteString("\n" + line)
+	}
+	return b.String()
+}
+
+// cutLine keeps about headTokens tokens of the start of s and tailTokens of
+// its end.
+func cutLine(s string, headTokens, tailTokens int) string {
+	runes := []rune(s)
+	headRunes := fitRunes(runes, headTokens, false)
+	tailRunes := min(fitRunes(runes, tailTokens, true), len(runes)-headRunes)
+	omitted := len(runes) - headRunes - tailRunes
+	return fmt.Sprintf("%s… [%d characters omitted] …%s",
+		string(runes[:headRunes]), omitted, string(runes[len(runes)-tailRunes:]))
+}
+
+// fitRunes returns how many runes from the start (or the end) of runes fit
+// into the token budget.
+func fitRunes(runes []rune, tokens int, fromEnd bool) int {
+	ascii, other := 0, 0
+	for i := range runes {
+		r := runes[i]
+		if fromEnd {
+			r = runes[len(runes)-1-i]
+		}
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+		if (ascii+3)/4+(other+1)/2 > tokens {
+			return i
+		}
+	}
+	return len(runes)
+}
@@ -0,0 +1,128 @@
+package truncate
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func numberedLines(n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %03d of the command output", i+1)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"привет", 3},
+		{"ok да", 2},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.in); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate_WithinBudget(t *testing.T) {
+	tr := New(Config{MaxTokens: 100})
+	out, truncated := tr.Truncate("shell_exec", "short output")
+	if truncated || out != "short output" {
+		t.Errorf("Truncate() = %q, %v; want unchanged", out, truncated)
+	}
+}
+
+func TestTruncate_HeadAndTail(t *testing.T) {
+	tr := New(Config{MaxTokens: 200})
+	output := numberedLines(200)
+
+	out, truncated := tr.Truncate("shell_exec", output)
+	if !truncated {
+		t.Fatal("Truncate() didn't truncate an output over the budget")
+	}
+	if got := EstimateTokens(out); got > 200+20 {
+		t.Errorf("truncated output has %d tokens, want about 200", got)
+	}
+	if !strings.HasPrefix(out, "line 001 ") {
+		t.Errorf("head not kept: %q", out[:40])
+	}
+	if !strings.HasSuffix(out, "line 200 of the command output") {
+		t.Errorf("tail not kept: %q", out[len(out)-40:])
+	}
+	if !strings.Contains(out, "lines omitted] …") {
+		t.Errorf("omission note missing: %q", out)
+	}
+
+	// Lines kept plus lines omitted add up to the whole output
+	kept := 0
+	omitted := 0
+	for line := range strings.SplitSeq(out, "\n") {
+		if strings.HasPrefix(line, "line ") {
+			kept++
+		}
+		_, _ = fmt.Sscanf(line, "… [%d lines omitted] …", &omitted)
+	}
+	if kept+omitted != 200 {
+		t.Errorf("kept %d + omitted %d lines, want 200", kept, omitted)
+	}
+}
+
+func TestTruncate_CollapsesRepeats(t *testing.T) {
+	tr := New(Config{MaxTokens: 60})
+	output := "start\n" + strings.Repeat("warning: deprecated call\n", 50) + "done"
+
+	out, truncated := tr.Truncate("shell_exec", output)
+	if !truncated {
+		t.Fatal("Truncate() didn't shorten repeated lines")
+	}
+	want := "start\nwarning: deprecated call\n… (previous line repeated 49 more times)\ndone"
+	if out != want {
+		t.Errorf("Truncate() = %q, want %q", out, want)
+	}
+}
+
+func TestTruncate_SingleLongLine(t *testing.T) {
+	tr := New(Config{MaxTokens: 100})
+	output := "{" + strings.Repeat(`"key":"value",`, 500) + `"last":true}`
+
+	out, truncated := tr.Truncate("web_fetch", output)
+	if !truncated {
+		t.Fatal("Truncate() didn't cut a long line")
+	}
+	if !strings.HasPrefix(out, `{"key":"value"`) || !strings.HasSuffix(out, `"last":true}`) {
+		t.Errorf("start or end of the line not kept: %q", out)
+	}
+	if !strings.Contains(out, "characters omitted") {
+		t.Errorf("omission note missing: %q", out)
+	}
+	if got := EstimateTokens(out); got > 120 {
+		t.Errorf("truncated line has %d tokens, want about 100", got)
+	}
+}
+
+func TestTruncate_PerToolBudgets(t *testing.T) {
+	tr := New(Config{MaxTokens: 100, ToolTokens: map[string]int{"read_file": 0, "shell_exec": 400}})
+	output := numberedLines(100)
+
+	if _, truncated := tr.Truncate("read_file", output); truncated {
+		t.Error("tool with budget 0 was truncated")
+	}
+	out, _ := tr.Truncate("shell_exec", output)
+	long := EstimateTokens(out)
+	out, _ = tr.Truncate("web_fetch", output)
+	short := EstimateTokens(out)
+	if long <= short {
+		t.Errorf("tool budget not applied: shell_exec %d tokens, default %d", long, short)
+	}
+	if got := New(Config{}).Budget("any"); got != DefaultMaxTokens {
+		t.Errorf("Budget() = %d, want DefaultMaxTokens", got)
+	}
+}
@@ -0,0 +1,230 @@
+// Package verify checks draft answers before they are sent: the draft, the
+// user's question and the tool outputs the answer is based on are given to a
+// (possibly cheaper) model that looks for claims the evidence does not
+// support. Unsupported answers are either corrected automatically or sent
+// with a confidence note.
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// Modes of handling unsupported answers.
+const (
+	ModeCorrect = "correct" // Replace the draft with the corrected answer
+	ModeNote    = "note"    // Send the draft with a confidence note
+)
+
+// Verdicts.
+const (
+	VerdictSupported   = "supported"
+	VerdictUnsupported = "unsupported"
+)
+
+const (
+	// DefaultMinChars is the shortest answer that is verified
+	DefaultMinChars = 200
+
+	// DefaultMaxEvidenceChars limits the tool output given to the verifier
+	DefaultMaxEvidenceChars = 12000
+
+	verifyPrompt = "You verify answers of an assistant that works with tools. " +
+		"Check every factual claim of the draft answer against the tool outputs (the evidence) and the conversation. " +
+		"A claim is unsupported if the evidence contradicts it or does not contain it and it is not common knowledge. " +
+		"Reply with JSON only, no prose: " +
+		`{"verdict": "supported" or "unsupported", "confidence": 0.0-1.0, "issues": ["unsupported claim", ...], "corrected_answer": "..."}` +
+		". corrected_answer is the draft with unsupported claims fixed or removed, in the language and style of the draft; " +
+		"leave it empty when the verdict is supported."
+)
+
+// ErrInvalidVerdict is returned for verifier replies that are not a valid verdict.
+var ErrInvalidVerdict = errors.New("invalid verdict")
+
+// Evidence is the output of a tool call the answer may rely on.
+type Evidence struct {
+	Tool   string
+	Output string
+}
+
+// Config configures a Verifier.
+type Config struct {
+	Model            string // Model of the verifier (the request model if empty)
+	Mode             string // ModeCorrect or ModeNote (ModeCorrect if empty)
+	MinChars         int    // Shorter answers are not verified (DefaultMinChars if 0, negative verifies every answer)
+	MaxEvidenceChars int    // Tool output given to the verifier (DefaultMaxEvidenceChars if 0)
+	Always           bool   // Verify answers given without tool calls too
+	MaxTokens        int    // Maximum tokens of the verifier reply
+}
+
+// Verifier checks draft answers against tool evidence.
+type Verifier struct {
+	cfg      Config
+	provider llm.Provider
+}
+
+// New creates a Verifier.
+func New(provider llm.Provider, cfg Config) (*Verifier, error) {
+	if cfg.Mode == "" {
+		cfg.Mode = ModeCorrect
+	}
+	if cfg.Mode != ModeCorrect && cfg.Mode != ModeNote {
+		return nil, fmt.Errorf("invalid verification mode: %s (expected: %s, %s)", cfg.Mode, ModeCorrect, ModeNote)
+	}
+	if cfg.MinChars == 0 {
+		cfg.MinChars = DefaultMinChars
+	}
+	if cfg.MaxEvidenceChars <= 0 {
+		cfg.MaxEvidenceChars = DefaultMaxEvidenceChars
+	}
+	return &Verifier{cfg: cfg, provider: provider}, nil
+}
+
+// ShouldVerify reports whether a draft needs verification: it is long enough
+// and is based on tool evidence (or Config.Always is set).
+func (v *Verifier) ShouldVerify(draft string, evidence []Evidence) bool {
+	if strings.TrimSpace(draft) == "" {
+		return false
+	}
+	if v.cfg.MinChars > 0 && utf8.RuneCountInString(draft) < v.cfg.MinChars {
+		return false
+	}
+	return v.cfg.Always || len(evidence) > 0
+}
+
+// Request is a draft answer to verify.
+type Request struct {
+	Question string
+	Draft    string
+	Evidence []Evidence
+	Context  []llm.Message // Earlier conversation (user and assistant messages)
+	Model    string        // Used when Config.Model is empty
+}
+
+// Result is the outcome of a verification.
+type Result struct {
+	Verdict    string   // VerdictSupported or VerdictUnsupported
+	Confidence float64  // Confidence of the verifier that the draft is correct
+	Issues     []string // Unsupported claims
+	Correction string   // Draft with unsupported claims fixed, proposed by the verifier
+	Answer     string   // The answer to send: the draft, the corrected draft or the draft with a note
+	Corrected  bool     // Answer is the corrected draft
+	Tokens     int
+}
+
+// Verify checks the draft and returns the answer to send.
+func (v *Verifier) Verify(ctx context.Context, req Request) (*Result, error) {
+	model := v.cfg.Model
+	if model == "" {
+		model = req.Model
+	}
+
+	messages := []llm.Message{{Role: llm.RoleSystem, Content: verifyPrompt}}
+	messages = append(messages, req.Context...)
+	messages = append(messages, llm.Message{Role: llm.RoleUser, Content: v.prompt(req)})
+
+	resp, err := v.provider.Chat(ctx, llm.ChatRequest{
+		Messages:    messages,
+		Model:       model,
+		Temperature: 0,
+		MaxTokens:   v.cfg.MaxTokens,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("verification request failed: %w", err)
+	}
+
+	result, err := Parse(resp.Content)
+	if err != nil {
+		return nil, err
+	}
+	result.Tokens = resp.Usage.TotalTokens
+	result.Answer = req.Draft
+
+	if result.Verdict == VerdictSupported {
+		return result, nil
+	}
+	if v.cfg.Mode == ModeCorrect && result.Correction != "" {
+		result.Answer = result.Correction
+		result.Corrected = true
+		return result, nil
+	}
+	result.Answer = req.Draft + Note(result)
+	return result, nil
+}
+
+// prompt formats the question, the evidence and the draft for the verifier.
+func (v *Verifier) prompt(req Request) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Question:\n%s\n\n", req.Question)
+
+	b.WriteString("Evidence:\n")
+	if len(req.Evidence) == 0 {
+		b.WriteString("(no tools were called)\n")
+	}
+	left := v.cfg.MaxEvidenceChars
+	for _, e := range req.Evidence {
+		if left <= 0 {
+			b.WriteString("[further tool outputs omitted]\n")
+			break
+		}
+		output := e.Output
+		if utf8.RuneCountInString(output) > left {
+			output = string([]rune(output)[:left]) + " [truncated]"
+		}
+		left -= utf8.RuneCountInString(output)
+		fmt.Fprintf(&b, "--- %s ---\n%s\n", e.Tool, output)
+	}
+
+	fmt.Fprintf(&b, "\nDraft answer:\n%s", req.Draft)
+	return b.String()
+}
+
+// Note returns the confidence note appended to unsupported answers.
+func Note(result *Result) string {
+	note := fmt.Sprintf("\n\n⚠️ Verification: confidence %d%%", int(result.Confidence*100+0.5))
+	if len(result.Issues) > 0 {
+		note += ". Not supported by the sources: " + strings.Join(result.Issues, "; ")
+	}
+	return note
+}
+
+// Parse reads a verdict from a verifier reply, tolerating code fences and
+// text around the JSON object.
+func Parse(content string) (*Result, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("%w: no JSON object in reply", ErrInvalidVerdict)
+	}
+
+	var raw struct {
+		Verdict         string   `json:"verdict"`
+		Confidence      float64  `json:"confidence"`
+		Issues          []string `json:"issues"`
+		CorrectedAnswer string   `json:"corrected_answer"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidVerdict, err)
+	}
+
+	result := &Result{
+		Verdict:    strings.ToLower(strings.TrimSpace(raw.Verdict)),
+		Confidence: min(max(raw.Confidence, 0), 1),
+		Correction: strings.TrimSpace(raw.CorrectedAnswer),
+	}
+	if result.Verdict != VerdictSupported && result.Verdict != VerdictUnsupported {
+		return nil, fmt.Errorf("%w: unknown verdict %q", ErrInvalidVerdict, raw.Verdict)
+	}
+	for _, issue := range raw.Issues {
+		if issue = strings.TrimSpace(issue); issue != "" {
+			result.Issues = append(result.Issues, issue)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,152 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// verifyProvider returns a fixed reply and records the request.
+type verifyProvider struct {
+	reply   string
+	request llm.ChatRequest
+}
+
+func (p *verifyProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.request = req
+	return &llm.ChatResponse{Content: p.reply, FinishReason: llm.FinishReasonStop}, nil
+}
+
+func (p *verifyProvider) SupportsToolCalling() bool { return false }
+
+func TestParse(t *testing.T) {
+	result, err := Parse("```json\n" + `{"verdict": "Unsupported", "confidence": 1.4, "issues": ["the port is 8080", " "], "corrected_answer": " fixed "}` + "\n```")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if result.Verdict != VerdictUnsupported || result.Confidence != 1 || len(result.Issues) != 1 || result.Correction != "fixed" {
+		t.Errorf("Parse() = %+v", result)
+	}
+
+	for _, invalid := range []string{"looks fine", `{"verdict": "maybe"}`, `{"verdict": 1}`} {
+		if _, err := Parse(invalid); !errors.Is(err, ErrInvalidVerdict) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidVerdict", invalid, err)
+		}
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	evidence := []Evidence{{Tool: "read_file", Output: "port = 9090"}}
+	unsupported := `{"verdict": "unsupported", "confidence": 0.3, "issues": ["the port is 8080"], "corrected_answer": "The server listens on port 9090."}`
+
+	tests := []struct {
+		name          string
+		mode          string
+		reply         string
+		wantAnswer    string
+		wantCorrected bool
+	}{
+		{
+			name:       "supported draft is kept",
+			reply:      `{"verdict": "supported", "confidence": 0.9}`,
+			wantAnswer: "The server listens on port 8080.",
+		},
+		{
+			name:          "correct mode replaces the draft",
+			reply:         unsupported,
+			wantAnswer:    "The server listens on port 9090.",
+			wantCorrected: true,
+		},
+		{
+			name:       "note mode appends a confidence note",
+			mode:       ModeNote,
+			reply:      unsupported,
+			wantAnswer: "The server listens on port 8080.\n\n⚠️ Verification: confidence 30%. Not supported by the sources: the port is 8080",
+		},
+		{
+			name:       "correct mode without correction falls back to a note",
+			reply:      `{"verdict": "unsupported", "confidence": 0.5}`,
+			wantAnswer: "The server listens on port 8080.\n\n⚠️ Verification: confidence 50%",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &verifyProvider{reply: tt.reply}
+			v, err := New(provider, Config{Mode: tt.mode, Model: "glm-4.7-flash"})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			result, err := v.Verify(context.Background(), Request{
+				Question: "Which port does the server use?",
+				Draft:    "The server listens on port 8080.",
+				Evidence: evidence,
+				Model:    "glm-4.7",
+			})
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if result.Answer != tt.wantAnswer || result.Corrected != tt.wantCorrected {
+				t.Errorf("Verify() answer = %q (corrected %v), want %q (corrected %v)",
+					result.Answer, result.Corrected, tt.wantAnswer, tt.wantCorrected)
+			}
+			if provider.request.Model != "glm-4.7-flash" {
+				t.Errorf("verifier model = %q, want glm-4.7-flash", provider.request.Model)
+			}
+			if prompt := provider.request.Messages[len(provider.request.Messages)-1].Content; !strings.Contains(prompt, "port = 9090") {
+				t.Errorf("verification prompt does not include the evidence: %q", prompt)
+			}
+		})
+	}
+}
+
+func TestVerifier_EvidenceLimit(t *testing.T) {
+	provider := &verifyProvider{reply: `{"verdict": "supported", "confidence": 1}`}
+	v, err := New(provider, Config{MaxEvidenceChars: 10})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = v.Verify(context.Background(), Request{
+		Draft:    "draft",
+		Evidence: []Evidence{{Tool: "a", Output: strings.Repeat("x", 20)}, {Tool: "b", Output: "y"}},
+	})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	prompt := provider.request.Messages[len(provider.request.Messages)-1].Content
+	if !strings.Contains(prompt, strings.Repeat("x", 10)+" [truncated]") || !strings.Contains(prompt, "[further tool outputs omitted]") {
+		t.Errorf("evidence was not limited: %q", prompt)
+	}
+}
+
+func TestVerifier_ShouldVerify(t *testing.T) {
+	v, _ := New(&verifyProvider{}, Config{MinChars: 10})
+	long := strings.Repeat("answer ", 3)
+	evidence := []Evidence{{Tool: "read_file", Output: "x"}}
+
+	if v.ShouldVerify("short", evidence) {
+		t.Error("ShouldVerify() of a short answer = true")
+	}
+	if v.ShouldVerify(long, nil) {
+		t.Error("ShouldVerify() without evidence = true")
+	}
+	if !v.ShouldVerify(long, evidence) {
+		t.Error("ShouldVerify() of a long answer with evidence = false")
+	}
+
+	always, _ := New(&verifyProvider{}, Config{MinChars: 10, Always: true})
+	if !always.ShouldVerify(long, nil) {
+		t.Error("ShouldVerify() with Always = false")
+	}
+}
+
+func TestNew_InvalidMode(t *testing.T) {
+	if _, err := New(&verifyProvider{}, Config{Mode: "rewrite"}); err == nil {
+		t.Error("New() with an invalid mode should fail")
+	}
+}
@@ -0,0 +1,228 @@
+// Package alerts sends notices about operational errors (LLM provider
+// outages, tool crashes, growing bus queues) to an admin chat, separately
+// from the error replies users get. A notice repeating within the cooldown
+// is folded into the next one, and the number of notices per hour is
+// capped, so an outage does not flood the chat.
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+const (
+	// DefaultCooldown is used when Config.Cooldown is not set
+	DefaultCooldown = 10 * time.Minute
+
+	// DefaultMaxPerHour is used when Config.MaxPerHour is not set
+	DefaultMaxPerHour = 20
+
+	// DefaultQueueThreshold is the queue fill percentage used when
+	// WatchQueues gets no threshold
+	DefaultQueueThreshold = 80
+
+	// queueCheckInterval is how often WatchQueues checks the queues
+	queueCheckInterval = 30 * time.Second
+
+	// maxTracked limits the remembered notice keys; expired keys are
+	// forgotten when the limit is reached
+	maxTracked = 1000
+
+	// maxDetailRunes limits the error text quoted in a notice
+	maxDetailRunes = 500
+)
+
+// Kind is the source of an operational error.
+type Kind string
+
+const (
+	KindProvider Kind = "provider" // The agent failed a turn after retries
+	KindTool     Kind = "tool"     // A tool panicked
+	KindQueue    Kind = "queue"    // A bus queue is filling up
+)
+
+// Publisher publishes outbound messages (implemented by bus.MessageBus).
+type Publisher interface {
+	PublishOutbound(msg bus.OutboundMessage) error
+}
+
+// QueueSource reports the depth of the bus queues (implemented by bus.MessageBus).
+type QueueSource interface {
+	QueueDepths() []bus.QueueDepth
+}
+
+// Config configures a Notifier.
+type Config struct {
+	SessionID  string        // Admin session receiving the notices ("channel:chat_id")
+	Cooldown   time.Duration // Repeats of a notice within this time are folded
+	MaxPerHour int           // Maximum notices sent per hour
+	Logger     *logger.Logger
+}
+
+// Notifier sends deduplicated and rate-limited notices to the admin session.
+// It is safe for concurrent use.
+type Notifier struct {
+	sessionID  string
+	channel    string
+	chatID     string
+	cooldown   time.Duration
+	maxPerHour int
+	publisher  Publisher
+	logger     *logger.Logger
+
+	mu      sync.Mutex
+	notices map[string]*notice // Last notice of every kind and key
+	sent    []time.Time        // Send times within the last hour
+	dropped int                // Notices dropped by the rate limit since the last sent one
+	now     func() time.Time
+}
+
+// notice is the state of the notices of one kind and key.
+type notice struct {
+	sent       time.Time // When the last notice was sent
+	suppressed int       // Repeats folded since then
+}
+
+// New creates a Notifier publishing notices with publisher.
+func New(cfg Config, publisher Publisher) *Notifier {
+	n := &Notifier{
+		sessionID:  cfg.SessionID,
+		cooldown:   cfg.Cooldown,
+		maxPerHour: cfg.MaxPerHour,
+		publisher:  publisher,
+		logger:     cfg.Logger,
+		notices:    make(map[string]*notice),
+		now:        time.Now,
+	}
+	n.channel, n.chatID, _ = strings.Cut(cfg.SessionID, ":")
+	if n.cooldown <= 0 {
+		n.cooldown = DefaultCooldown
+	}
+	if n.maxPerHour <= 0 {
+		n.maxPerHour = DefaultMaxPerHour
+	}
+	return n
+}
+
+// Notify sends a notice about an error of kind. Notices with the same kind
+// and key within the cooldown are counted instead of sent; the count is
+// reported with the next notice. Returns whether the notice was sent.
+func (n *Notifier) Notify(ctx context.Context, kind Kind, key, text string) bool {
+	repeated, dropped, ok := n.admit(kind, key)
+	if !ok {
+		return false
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "⚠️ %s: %s", kind, excerpt(text, maxDetailRunes))
+	if repeated > 0 {
+		fmt.Fprintf(&sb, "\n(repeated %d more times since the last notice)", repeated)
+	}
+	if dropped > 0 {
+		fmt.Fprintf(&sb, "\n(%d other notices dropped by the rate limit)", dropped)
+	}
+
+	msg := bus.NewOutboundMessage(bus.ChannelType(n.channel), n.chatID, n.sessionID, sb.String(), "",
+		bus.FormatTypePlain, nil)
+	if err := n.publisher.PublishOutbound(*msg); err != nil {
+		n.logger.WarnCtx(ctx, "failed to send alert",
+			logger.Field{Key: "kind", Value: string(kind)},
+			logger.Field{Key: "error", Value: err.Error()})
+		return false
+	}
+	return true
+}
+
+// admit decides whether a notice is sent now. Returns the repeats folded
+// since the last notice of the key and the notices dropped by the rate limit.
+func (n *Notifier) admit(kind Kind, key string) (repeated, dropped int, ok bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := n.now()
+	id := string(kind) + "\x00" + key
+	last, seen := n.notices[id]
+	if seen && now.Sub(last.sent) < n.cooldown {
+		last.suppressed++
+		return 0, 0, false
+	}
+
+	cutoff := now.Add(-time.Hour)
+	i := 0
+	for i < len(n.sent) && !n.sent[i].After(cutoff) {
+		i++
+	}
+	n.sent = n.sent[i:]
+	if len(n.sent) >= n.maxPerHour {
+		n.dropped++
+		return 0, 0, false
+	}
+
+	if seen {
+		repeated = last.suppressed
+	}
+	dropped = n.dropped
+	n.dropped = 0
+	n.sent = append(n.sent, now)
+	if !seen && len(n.notices) >= maxTracked {
+		n.forget(now)
+	}
+	n.notices[id] = &notice{sent: now}
+	return repeated, dropped, true
+}
+
+// forget removes the notice keys whose cooldown has passed. Caller must hold n.mu.
+func (n *Notifier) forget(now time.Time) {
+	for id, last := range n.notices {
+		if now.Sub(last.sent) >= n.cooldown {
+			delete(n.notices, id)
+		}
+	}
+}
+
+// WatchQueues checks the bus queues until ctx is done and notifies when a
+// queue is at least threshold percent full, i.e. messages pile up faster
+// than they are delivered.
+func (n *Notifier) WatchQueues(ctx context.Context, source QueueSource, threshold int) {
+	if threshold <= 0 {
+		threshold = DefaultQueueThreshold
+	}
+	go func() {
+		ticker := time.NewTicker(queueCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n.checkQueues(ctx, source, threshold)
+			}
+		}
+	}()
+}
+
+// checkQueues notifies about every queue at least threshold percent full.
+func (n *Notifier) checkQueues(ctx context.Context, source QueueSource, threshold int) {
+	for _, q := range source.QueueDepths() {
+		if q.Capacity <= 0 || q.Length*100 < q.Capacity*threshold {
+			continue
+		}
+		n.Notify(ctx, KindQueue, q.Name, fmt.Sprintf("%s queue is %d%% full (%d of %d messages)",
+			q.Name, q.Length*100/q.Capacity, q.Length, q.Capacity))
+	}
+}
+
+// excerpt shortens text to n runes.
+func excerpt(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,118 @@
+package alerts
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+type recordingPublisher struct {
+	messages []bus.OutboundMessage
+}
+
+func (p *recordingPublisher) PublishOutbound(msg bus.OutboundMessage) error {
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+type fixedQueues []bus.QueueDepth
+
+func (q fixedQueues) QueueDepths() []bus.QueueDepth { return q }
+
+func newTestNotifier(t *testing.T, maxPerHour int) (*Notifier, *recordingPublisher, *time.Time) {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	publisher := &recordingPublisher{}
+	n := New(Config{
+		SessionID:  "telegram:42",
+		Cooldown:   time.Minute,
+		MaxPerHour: maxPerHour,
+		Logger:     log,
+	}, publisher)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	n.now = func() time.Time { return now }
+	return n, publisher, &now
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	n, publisher, _ := newTestNotifier(t, 10)
+
+	if !n.Notify(context.Background(), KindProvider, "timeout", "LLM request timed out") {
+		t.Fatal("Expected the first notice to be sent")
+	}
+	msg := publisher.messages[0]
+	if msg.ChannelType != bus.ChannelTypeTelegram || msg.UserID != "42" || msg.SessionID != "telegram:42" {
+		t.Errorf("Notice sent to %s/%s (%s), expected telegram/42", msg.ChannelType, msg.UserID, msg.SessionID)
+	}
+	if !strings.Contains(msg.Content, "provider: LLM request timed out") {
+		t.Errorf("Unexpected notice: %q", msg.Content)
+	}
+}
+
+func TestNotifier_Dedup(t *testing.T) {
+	n, publisher, now := newTestNotifier(t, 10)
+	ctx := context.Background()
+
+	n.Notify(ctx, KindTool, "shell", "shell panicked")
+	if n.Notify(ctx, KindTool, "shell", "shell panicked") {
+		t.Error("Expected a repeat within the cooldown to be folded")
+	}
+	n.Notify(ctx, KindTool, "shell", "shell panicked")
+	if !n.Notify(ctx, KindTool, "web_fetch", "web_fetch panicked") {
+		t.Error("Expected a notice with another key to be sent")
+	}
+
+	*now = now.Add(2 * time.Minute)
+	if !n.Notify(ctx, KindTool, "shell", "shell panicked") {
+		t.Fatal("Expected a notice after the cooldown to be sent")
+	}
+	if len(publisher.messages) != 3 {
+		t.Fatalf("Expected 3 notices, got %d", len(publisher.messages))
+	}
+	if last := publisher.messages[2].Content; !strings.Contains(last, "repeated 2 more times") {
+		t.Errorf("Expected the folded repeats in the notice, got %q", last)
+	}
+}
+
+func TestNotifier_RateLimit(t *testing.T) {
+	n, publisher, now := newTestNotifier(t, 2)
+	ctx := context.Background()
+
+	n.Notify(ctx, KindProvider, "a", "a")
+	n.Notify(ctx, KindProvider, "b", "b")
+	if n.Notify(ctx, KindProvider, "c", "c") {
+		t.Error("Expected a notice over the hourly limit to be dropped")
+	}
+
+	*now = now.Add(time.Hour + time.Second)
+	if !n.Notify(ctx, KindProvider, "d", "d") {
+		t.Fatal("Expected a notice to be sent after an hour")
+	}
+	if last := publisher.messages[len(publisher.messages)-1].Content; !strings.Contains(last, "1 other notices dropped") {
+		t.Errorf("Expected the dropped notices in the notice, got %q", last)
+	}
+}
+
+func TestNotifier_CheckQueues(t *testing.T) {
+	n, publisher, _ := newTestNotifier(t, 10)
+
+	n.checkQueues(context.Background(), fixedQueues{
+		{Name: "inbound", Length: 10, Capacity: 100},
+		{Name: "outbound", Length: 90, Capacity: 100},
+		{Name: "event", Length: 0, Capacity: 0},
+	}, 80)
+
+	if len(publisher.messages) != 1 {
+		t.Fatalf("Expected 1 notice, got %d", len(publisher.messages))
+	}
+	if content := publisher.messages[0].Content; !strings.Contains(content, "outbound queue is 90% full") {
+		t.Errorf("Unexpected notice: %q", content)
+	}
+}
@@ -0,0 +1,231 @@
+// Package analytics aggregates per-day conversation statistics (messages,
+// distinct users, tool usage, answer latency and errors) into JSON files in
+// the workspace. It needs no external metrics system; the dashboard and the
+// "nexbot analytics" command render the stored days.
+package analytics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+const (
+	// Subdirectory is the workspace subdirectory holding the daily files
+	Subdirectory = "analytics"
+
+	// DateLayout is the layout of day dates and file names
+	DateLayout = "2006-01-02"
+
+	// maxLatencySamples caps the latency sample kept per day
+	maxLatencySamples = 2000
+
+	// maxToolDurations caps the call durations kept per tool and day
+	maxToolDurations = 500
+)
+
+// ToolStats counts the calls of one tool.
+type ToolStats struct {
+	Calls     int     `json:"calls"`
+	Errors    int     `json:"errors"`
+	TotalMs   int64   `json:"total_ms"`               // Sum of call durations
+	Durations []int64 `json:"durations_ms,omitempty"` // Durations of the latest calls
+}
+
+// Day holds the statistics of one day.
+type Day struct {
+	Date      string                `json:"date"`
+	Messages  int                   `json:"messages"` // Messages processed by the agent
+	Errors    int                   `json:"errors"`   // Messages the agent failed to answer
+	Users     []string              `json:"users,omitempty"`
+	Tools     map[string]*ToolStats `json:"tools,omitempty"`
+	Latencies []int64               `json:"latencies_ms,omitempty"` // Uniform sample of answer latencies
+}
+
+// Summary is a rendered view of one or more days.
+type Summary struct {
+	Date     string        `json:"date"` // Date or "from..to" range
+	Messages int           `json:"messages"`
+	Users    int           `json:"users"`
+	Errors   int           `json:"errors"`
+	P50Ms    int64         `json:"p50_ms"`
+	P90Ms    int64         `json:"p90_ms"`
+	P99Ms    int64         `json:"p99_ms"`
+	Tools    []ToolSummary `json:"tools,omitempty"` // Most used first
+}
+
+// ToolSummary is the usage of one tool in a Summary.
+type ToolSummary struct {
+	Name      string  `json:"name"`
+	Calls     int     `json:"calls"`
+	Errors    int     `json:"errors"`
+	ErrorRate float64 `json:"error_rate"` // Errors per call, 0..1
+	AvgMs     int64   `json:"avg_ms"`
+	P50Ms     int64   `json:"p50_ms"`
+	P95Ms     int64   `json:"p95_ms"`
+}
+
+// HashUser returns the identifier stored for a user: a truncated SHA-256,
+// so that the daily files hold no user IDs.
+func HashUser(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Merge combines days into one; the date is the range of the days.
+// Latency samples are concatenated, so percentiles of the result weight
+// each day by its sample size.
+func Merge(days []Day) Day {
+	var merged Day
+	users := make(map[string]bool)
+	for _, d := range days {
+		merged.Messages += d.Messages
+		merged.Errors += d.Errors
+		for _, u := range d.Users {
+			users[u] = true
+		}
+		for name, stats := range d.Tools {
+			if merged.Tools == nil {
+				merged.Tools = make(map[string]*ToolStats)
+			}
+			t, ok := merged.Tools[name]
+			if !ok {
+				t = &ToolStats{}
+				merged.Tools[name] = t
+			}
+			t.Calls += stats.Calls
+			t.Errors += stats.Errors
+			t.TotalMs += stats.TotalMs
+			t.Durations = append(t.Durations, stats.Durations...)
+		}
+		merged.Latencies = append(merged.Latencies, d.Latencies...)
+	}
+	merged.Users = slices.Sorted(maps.Keys(users))
+	if len(days) > 0 {
+		merged.Date = days[0].Date
+		if last := days[len(days)-1].Date; last != merged.Date {
+			merged.Date += ".." + last
+		}
+	}
+	return merged
+}
+
+// Summarize computes the summary of a day.
+func (d Day) Summarize() Summary {
+	s := Summary{
+		Date:     d.Date,
+		Messages: d.Messages,
+		Users:    len(d.Users),
+		Errors:   d.Errors,
+	}
+
+	if len(d.Latencies) > 0 {
+		sorted := slices.Clone(d.Latencies)
+		slices.Sort(sorted)
+		s.P50Ms = percentile(sorted, 50)
+		s.P90Ms = percentile(sorted, 90)
+		s.P99Ms = percentile(sorted, 99)
+	}
+
+	for name, stats := range d.Tools {
+		t := ToolSummary{Name: name, Calls: stats.Calls, Errors: stats.Errors}
+		if stats.Calls > 0 {
+			t.ErrorRate = float64(stats.Errors) / float64(stats.Calls)
+			t.AvgMs = stats.TotalMs / int64(stats.Calls)
+		}
+		if len(stats.Durations) > 0 {
+			sorted := slices.Clone(stats.Durations)
+			slices.Sort(sorted)
+			t.P50Ms = percentile(sorted, 50)
+			t.P95Ms = percentile(sorted, 95)
+		}
+		s.Tools = append(s.Tools, t)
+	}
+	sort.Slice(s.Tools, func(i, j int) bool {
+		if s.Tools[i].Calls != s.Tools[j].Calls {
+			return s.Tools[i].Calls > s.Tools[j].Calls
+		}
+		return s.Tools[i].Name < s.Tools[j].Name
+	})
+	return s
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []int64, p int) int64 {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// record adds a processed message to the day.
+func (d *Day) record(user string, latency time.Duration, failed bool, sample func(n int) int) {
+	d.Messages++
+	if failed {
+		d.Errors++
+	}
+	if user != "" && !slices.Contains(d.Users, user) {
+		d.Users = append(d.Users, user)
+	}
+
+	// Reservoir sampling keeps a uniform sample of the day's latencies
+	ms := latency.Milliseconds()
+	if len(d.Latencies) < maxLatencySamples {
+		d.Latencies = append(d.Latencies, ms)
+	} else if i := sample(d.Messages); i < maxLatencySamples {
+		d.Latencies[i] = ms
+	}
+}
+
+// recordTool adds a tool call to the day.
+func (d *Day) recordTool(name string, duration time.Duration, failed bool) {
+	if d.Tools == nil {
+		d.Tools = make(map[string]*ToolStats)
+	}
+	t, ok := d.Tools[name]
+	if !ok {
+		t = &ToolStats{}
+		d.Tools[name] = t
+	}
+	t.Calls++
+	if failed {
+		t.Errors++
+	}
+	t.TotalMs += duration.Milliseconds()
+
+	// The durations of the latest calls form a ring, so the percentiles
+	// follow the current behaviour of the tool
+	if len(t.Durations) < maxToolDurations {
+		t.Durations = append(t.Durations, duration.Milliseconds())
+	} else {
+		t.Durations[(t.Calls-1)%maxToolDurations] = duration.Milliseconds()
+	}
+}
+
+// P95 returns the 95th percentile of the recorded call durations, 0 when
+// there are none.
+func (t *ToolStats) P95() time.Duration {
+	if len(t.Durations) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(t.Durations)
+	slices.Sort(sorted)
+	return time.Duration(percentile(sorted, 95)) * time.Millisecond
+}
+
+// clone returns a deep copy of the day.
+func (d *Day) clone() Day {
+	c := *d
+	c.Users = slices.Clone(d.Users)
+	c.Latencies = slices.Clone(d.Latencies)
+	if d.Tools != nil {
+		c.Tools = make(map[string]*ToolStats, len(d.Tools))
+		for name, stats := range d.Tools {
+			s := *stats
+			s.Durations = slices.Clone(stats.Durations)
+			c.Tools[name] = &s
+		}
+	}
+	return c
+}
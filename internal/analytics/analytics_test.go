@@ -0,0 +1,169 @@
+package analytics
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDay_Summarize(t *testing.T) {
+	d := Day{Date: "2026-05-04"}
+	first := func(int) int { return 0 }
+	for i := 1; i <= 100; i++ {
+		d.record(HashUser("alice"), time.Duration(i)*time.Millisecond, i == 100, first)
+	}
+	d.record(HashUser("bob"), 5*time.Millisecond, false, first)
+	d.recordTool("read_file", 10*time.Millisecond, false)
+	d.recordTool("read_file", 30*time.Millisecond, true)
+	d.recordTool("web_fetch", 100*time.Millisecond, false)
+
+	s := d.Summarize()
+	if s.Messages != 101 || s.Users != 2 || s.Errors != 1 {
+		t.Errorf("Summarize() = %+v, want 101 messages from 2 users with 1 error", s)
+	}
+	if s.P50Ms != 50 || s.P90Ms != 90 || s.P99Ms != 99 {
+		t.Errorf("percentiles = %d/%d/%d, want 50/90/99", s.P50Ms, s.P90Ms, s.P99Ms)
+	}
+	if len(s.Tools) != 2 || s.Tools[0].Name != "read_file" || s.Tools[0].Errors != 1 || s.Tools[0].AvgMs != 20 {
+		t.Errorf("tools = %+v, want read_file first with 1 error and 20 ms average", s.Tools)
+	}
+}
+
+func TestDay_Summarize_ToolPercentiles(t *testing.T) {
+	d := Day{Date: "2026-05-04"}
+	for i := 1; i <= 100; i++ {
+		d.recordTool("web_fetch", time.Duration(i)*time.Millisecond, i%4 == 0)
+	}
+
+	s := d.Summarize()
+	if tool := s.Tools[0]; tool.P50Ms != 50 || tool.P95Ms != 95 || tool.ErrorRate != 0.25 {
+		t.Errorf("tool = %+v, want 50/95 ms percentiles and a 0.25 error rate", tool)
+	}
+
+	// Only the latest calls are kept
+	for i := 0; i < maxToolDurations; i++ {
+		d.recordTool("web_fetch", time.Second, false)
+	}
+	if p95 := d.Tools["web_fetch"].P95(); p95 != time.Second || len(d.Tools["web_fetch"].Durations) != maxToolDurations {
+		t.Errorf("P95() = %v over %d durations, want 1s over %d", p95, len(d.Tools["web_fetch"].Durations), maxToolDurations)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := Day{Date: "2026-05-03", Messages: 2, Users: []string{"x"}, Tools: map[string]*ToolStats{"shell": {Calls: 1}}, Latencies: []int64{10, 20}}
+	b := Day{Date: "2026-05-04", Messages: 1, Errors: 1, Users: []string{"x", "y"}, Tools: map[string]*ToolStats{"shell": {Calls: 2, Errors: 1}}, Latencies: []int64{30}}
+
+	s := Merge([]Day{a, b}).Summarize()
+	if s.Date != "2026-05-03..2026-05-04" || s.Messages != 3 || s.Users != 2 || s.Errors != 1 || s.P99Ms != 30 {
+		t.Errorf("Merge() = %+v", s)
+	}
+	if len(s.Tools) != 1 || s.Tools[0].Calls != 3 || s.Tools[0].Errors != 1 {
+		t.Errorf("merged tools = %+v", s.Tools)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	days := []Day{
+		{Date: "2026-05-03"},
+		{Date: "2026-05-04", Messages: 2, Users: []string{"x"}, Errors: 1, Latencies: []int64{800, 12000},
+			Tools: map[string]*ToolStats{"web_fetch": {Calls: 2, TotalMs: 3000}}},
+	}
+	out := Format(days)
+	for _, want := range []string{"2026-05-03..2026-05-04", "800ms", "12s", "web_fetch", "1.5s", "total"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Format() missing %q:\n%s", want, out)
+		}
+	}
+
+	if out := Format([]Day{{Date: "2026-05-04"}}); !strings.Contains(out, "No messages recorded") {
+		t.Errorf("Format() of an empty day = %s", out)
+	}
+}
+
+func TestStore_PersistsDays(t *testing.T) {
+	ws := t.TempDir()
+	now := time.Date(2026, 5, 4, 23, 59, 0, 0, time.UTC)
+
+	store := NewStore(ws, 0, nil)
+	store.now = func() time.Time { return now }
+	store.RecordMessage("telegram:1", 2*time.Second, false)
+	store.RecordTool("read_file", time.Second, errors.New("boom"))
+
+	// Writing the next day saves the previous one
+	now = now.Add(2 * time.Minute)
+	store.RecordMessage("telegram:2", time.Second, true)
+	if err := store.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	reopened := NewStore(ws, 0, nil)
+	reopened.now = func() time.Time { return now }
+	days, err := reopened.Days(3)
+	if err != nil {
+		t.Fatalf("Days() error = %v", err)
+	}
+	if len(days) != 3 || days[0].Messages != 0 || days[1].Date != "2026-05-04" || days[2].Date != "2026-05-05" {
+		t.Fatalf("Days() = %+v", days)
+	}
+	if s := days[1].Summarize(); s.Messages != 1 || s.P50Ms != 2000 || s.Tools[0].Errors != 1 {
+		t.Errorf("first day = %+v", s)
+	}
+	if s := days[2].Summarize(); s.Messages != 1 || s.Errors != 1 {
+		t.Errorf("second day = %+v", s)
+	}
+
+	// Continues the stored day after a restart
+	reopened.RecordMessage("telegram:2", time.Second, false)
+	if days, _ := reopened.Days(1); days[0].Messages != 2 || len(days[0].Users) != 1 {
+		t.Errorf("today after restart = %+v", days[0])
+	}
+}
+
+func TestStore_SlowTool(t *testing.T) {
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	store := NewStore(t.TempDir(), 0, nil)
+	store.now = func() time.Time { return now }
+	store.SetSlowToolThreshold(time.Second)
+
+	for range slowToolMinCalls {
+		store.RecordTool("read_file", 10*time.Millisecond, nil)
+		store.RecordTool("web_fetch", 3*time.Second, nil)
+	}
+	if !store.slow["web_fetch"] || store.slow["read_file"] {
+		t.Errorf("slow tools = %v, want only web_fetch", store.slow)
+	}
+
+	// Tools are reported again on the next day
+	now = now.AddDate(0, 0, 1)
+	store.RecordTool("web_fetch", 3*time.Second, nil)
+	if store.slow["web_fetch"] {
+		t.Error("Expected the slow tools to reset on the next day")
+	}
+}
+
+func TestStore_PrunesExpiredDays(t *testing.T) {
+	ws := t.TempDir()
+	dir := filepath.Join(ws, Subdirectory)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"2026-01-01.json", "2026-05-01.json", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store := NewStore(ws, 30, nil)
+	store.now = func() time.Time { return time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC) }
+	store.Start(t.Context(), time.Hour)
+	store.Stop()
+
+	for name, kept := range map[string]bool{"2026-01-01.json": false, "2026-05-01.json": true, "notes.txt": true} {
+		if _, err := os.Stat(filepath.Join(dir, name)); (err == nil) != kept {
+			t.Errorf("%s kept = %v, want %v", name, err == nil, kept)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package analytics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format renders days as a text report: one line per day, the total of the
+// period and the tool usage of the period.
+func Format(days []Day) string {
+	var b strings.Builder
+	total := Merge(days).Summarize()
+	fmt.Fprintf(&b, "Conversation analytics %s\n", total.Date)
+
+	if total.Messages == 0 && len(total.Tools) == 0 {
+		b.WriteString("\nNo messages recorded.\n")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "\n  %-22s %8s %6s %6s %8s %8s %8s\n", "DATE", "MESSAGES", "USERS", "ERRORS", "P50", "P90", "P99")
+	for _, day := range days {
+		writeRow(&b, day.Summarize())
+	}
+	writeRow(&b, Summary{
+		Date: "total", Messages: total.Messages, Users: total.Users, Errors: total.Errors,
+		P50Ms: total.P50Ms, P90Ms: total.P90Ms, P99Ms: total.P99Ms,
+	})
+
+	if len(total.Tools) > 0 {
+		b.WriteString("\nTools:\n")
+		b.WriteString(formatTools(total.Tools))
+	}
+	return b.String()
+}
+
+// formatTools renders the usage of tools as a table: calls, errors with
+// the error rate and the average, p50 and p95 call durations.
+func formatTools(tools []ToolSummary) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %-30s %6s %6s %6s %8s %8s %8s\n", "NAME", "CALLS", "ERRORS", "ERR%", "AVG", "P50", "P95")
+	for _, t := range tools {
+		fmt.Fprintf(&b, "  %-30s %6d %6d %5.1f%% %8s %8s %8s\n", t.Name, t.Calls, t.Errors, t.ErrorRate*100,
+			formatMs(t.AvgMs, t.Calls), formatMs(t.P50Ms, t.Calls), formatMs(t.P95Ms, t.Calls))
+	}
+	return b.String()
+}
+
+func writeRow(b *strings.Builder, s Summary) {
+	fmt.Fprintf(b, "  %-22s %8d %6d %6d %8s %8s %8s\n", s.Date, s.Messages, s.Users, s.Errors,
+		formatMs(s.P50Ms, s.Messages), formatMs(s.P90Ms, s.Messages), formatMs(s.P99Ms, s.Messages))
+}
+
+// formatMs formats a duration in milliseconds; "-" when there is no data.
+func formatMs(ms int64, count int) string {
+	switch {
+	case count == 0:
+		return "-"
+	case ms >= 10000:
+		return fmt.Sprintf("%.0fs", float64(ms)/1000)
+	case ms >= 1000:
+		return fmt.Sprintf("%.1fs", float64(ms)/1000)
+	default:
+		return fmt.Sprintf("%dms", ms)
+	}
+}
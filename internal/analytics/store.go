@@ -0,0 +1,303 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+const (
+	// DefaultRetentionDays is how long daily files are kept by default
+	DefaultRetentionDays = 90
+
+	// DefaultFlushInterval is how often the current day is written to disk
+	DefaultFlushInterval = time.Minute
+
+	// slowToolMinCalls is the number of calls of a tool in a day before its
+	// p95 is compared with the slow tool threshold
+	slowToolMinCalls = 10
+)
+
+// Store records statistics of the current day in memory and keeps one JSON
+// file per day in <workspace>/analytics. It is safe for concurrent use.
+type Store struct {
+	dir           string
+	retentionDays int
+	logger        *logger.Logger
+	now           func() time.Time
+	sample        func(n int) int
+	slowTool      time.Duration // p95 above which a tool is reported as slow, 0 disables
+
+	mu     sync.Mutex
+	today  *Day // Loaded lazily; nil until the first record
+	dirty  bool
+	slow   map[string]bool // Tools reported as slow today
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewStore creates a store in the workspace. retentionDays is
+// DefaultRetentionDays if not positive. Call Start to flush periodically.
+func NewStore(workspace string, retentionDays int, log *logger.Logger) *Store {
+	if retentionDays <= 0 {
+		retentionDays = DefaultRetentionDays
+	}
+	return &Store{
+		dir:           filepath.Join(workspace, Subdirectory),
+		retentionDays: retentionDays,
+		logger:        log,
+		now:           time.Now,
+		sample:        rand.IntN,
+	}
+}
+
+// RecordMessage records a message processed by the agent: the user who sent
+// it, the time to the answer and whether processing failed.
+func (s *Store) RecordMessage(userID string, latency time.Duration, failed bool) {
+	user := ""
+	if userID != "" {
+		user = HashUser(userID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current().record(user, latency, failed, s.sample)
+	s.dirty = true
+}
+
+// SetSlowToolThreshold enables slow tool detection: a warning is logged
+// once a day for each tool whose p95 call duration exceeds threshold.
+// Zero disables it.
+func (s *Store) SetSlowToolThreshold(threshold time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.slowTool = threshold
+}
+
+// RecordTool records a tool call. It matches loop.ToolCallFunc.
+func (s *Store) RecordTool(name string, duration time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	day := s.current()
+	day.recordTool(name, duration, err != nil)
+	s.dirty = true
+	s.checkSlowTool(name, day.Tools[name])
+}
+
+// checkSlowTool logs a warning the first time in a day the p95 of a tool
+// exceeds the slow tool threshold. Caller must hold s.mu.
+func (s *Store) checkSlowTool(name string, stats *ToolStats) {
+	if s.slowTool <= 0 || s.slow[name] || stats.Calls < slowToolMinCalls {
+		return
+	}
+	p95 := stats.P95()
+	if p95 <= s.slowTool {
+		return
+	}
+	if s.slow == nil {
+		s.slow = make(map[string]bool)
+	}
+	s.slow[name] = true
+	if s.logger != nil {
+		s.logger.Warn("slow tool detected",
+			logger.Field{Key: "tool", Value: name},
+			logger.Field{Key: "p95_ms", Value: p95.Milliseconds()},
+			logger.Field{Key: "threshold_ms", Value: s.slowTool.Milliseconds()},
+			logger.Field{Key: "calls", Value: stats.Calls})
+	}
+}
+
+// current returns the current day, writing the previous day when the date
+// changes. Caller must hold s.mu.
+func (s *Store) current() *Day {
+	date := s.now().Format(DateLayout)
+	if s.today != nil && s.today.Date == date {
+		return s.today
+	}
+
+	if s.today != nil && s.dirty {
+		if err := s.write(s.today); err != nil {
+			s.logError("failed to save analytics", err)
+		}
+	}
+	day, err := s.read(date)
+	if err != nil {
+		s.logError("failed to load analytics", err)
+		day = Day{Date: date}
+	}
+	s.today = &day
+	s.dirty = false
+	s.slow = nil
+	return s.today
+}
+
+// Days returns the statistics of the last n days including today, oldest
+// first. Days without data are included with zero values.
+func (s *Store) Days(n int) ([]Day, error) {
+	if n <= 0 {
+		n = 1
+	}
+	now := s.now()
+	today := now.Format(DateLayout)
+
+	days := make([]Day, 0, n)
+	for i := n - 1; i >= 0; i-- {
+		date := now.AddDate(0, 0, -i).Format(DateLayout)
+
+		if date == today {
+			s.mu.Lock()
+			if s.today != nil && s.today.Date == today {
+				days = append(days, s.today.clone())
+				s.mu.Unlock()
+				continue
+			}
+			s.mu.Unlock()
+		}
+
+		day, err := s.read(date)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// Start begins writing the current day every interval
+// (DefaultFlushInterval if not positive) and removes expired days.
+func (s *Store) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	if err := s.prune(); err != nil {
+		s.logError("failed to remove expired analytics", err)
+	}
+
+	go func() {
+		defer close(s.done)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Flush(); err != nil {
+					s.logError("failed to save analytics", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops periodic writing and writes the current day.
+func (s *Store) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+	if err := s.Flush(); err != nil {
+		s.logError("failed to save analytics", err)
+	}
+}
+
+// Flush writes the current day if it changed since the last write.
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.today == nil || !s.dirty {
+		return nil
+	}
+	if err := s.write(s.today); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// path returns the file of a date.
+func (s *Store) path(date string) string {
+	return filepath.Join(s.dir, date+".json")
+}
+
+// read loads the day of a date; a missing file is an empty day.
+func (s *Store) read(date string) (Day, error) {
+	data, err := os.ReadFile(s.path(date))
+	if errors.Is(err, os.ErrNotExist) {
+		return Day{Date: date}, nil
+	}
+	if err != nil {
+		return Day{}, fmt.Errorf("failed to read analytics of %s: %w", date, err)
+	}
+	var day Day
+	if err := json.Unmarshal(data, &day); err != nil {
+		return Day{}, fmt.Errorf("failed to parse analytics of %s: %w", date, err)
+	}
+	day.Date = date
+	return day, nil
+}
+
+// write stores a day atomically.
+func (s *Store) write(day *Day) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create analytics directory: %w", err)
+	}
+	data, err := json.Marshal(day)
+	if err != nil {
+		return fmt.Errorf("failed to marshal analytics: %w", err)
+	}
+
+	path := s.path(day.Date)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write analytics: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to save analytics: %w", err)
+	}
+	return nil
+}
+
+// prune removes daily files older than the retention period.
+func (s *Store) prune() error {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	cutoff := s.now().AddDate(0, 0, -s.retentionDays).Format(DateLayout)
+	for _, entry := range entries {
+		date, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(DateLayout, date); err != nil || date >= cutoff {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) logError(msg string, err error) {
+	if s.logger != nil {
+		s.logger.Error(msg, err)
+	}
+}
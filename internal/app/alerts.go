@@ -0,0 +1,37 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/alerts"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// alertAgentError notifies the admins about a turn the agent failed after
+// retries, usually because the LLM provider is unavailable. Identical errors
+// are deduplicated. Failures caused by shutdown are not reported.
+func (a *App) alertAgentError(ctx context.Context, msg bus.InboundMessage, err error) {
+	if a.alerts == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	key, _, _ := strings.Cut(err.Error(), "\n")
+	a.alerts.Notify(ctx, alerts.KindProvider, key,
+		fmt.Sprintf("agent failed after retries (provider %s, session %s): %v", a.config.Agent.Provider, msg.SessionID, err))
+}
+
+// recordToolCall counts a tool call in the daily statistics and notifies
+// the admins if the tool panicked.
+func (a *App) recordToolCall(tool string, duration time.Duration, err error) {
+	if a.analytics != nil {
+		a.analytics.RecordTool(tool, duration, err)
+	}
+	var toolErr *tools.ToolError
+	if a.alerts != nil && errors.As(err, &toolErr) && toolErr.Code == tools.ErrCodeToolPanic {
+		a.alerts.Notify(a.ctx, alerts.KindTool, tool, fmt.Sprintf("%s: %s", tool, toolErr.Message))
+	}
+}
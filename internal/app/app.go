@@ -8,17 +8,35 @@ import (
 
 	"github.com/aatumaykin/nexbot/internal/agent/loop"
 	"github.com/aatumaykin/nexbot/internal/agent/subagent"
+	"github.com/aatumaykin/nexbot/internal/alerts"
+	"github.com/aatumaykin/nexbot/internal/analytics"
+	"github.com/aatumaykin/nexbot/internal/approval"
+	"github.com/aatumaykin/nexbot/internal/artifacts"
 	"github.com/aatumaykin/nexbot/internal/bus"
 	"github.com/aatumaykin/nexbot/internal/channels/telegram"
 	"github.com/aatumaykin/nexbot/internal/cleanup"
+	"github.com/aatumaykin/nexbot/internal/codeimage"
 	"github.com/aatumaykin/nexbot/internal/commands"
 	"github.com/aatumaykin/nexbot/internal/config"
 	"github.com/aatumaykin/nexbot/internal/cron"
+	"github.com/aatumaykin/nexbot/internal/dashboard"
+	"github.com/aatumaykin/nexbot/internal/digest"
+	"github.com/aatumaykin/nexbot/internal/export"
 
+	"github.com/aatumaykin/nexbot/internal/forms"
+	"github.com/aatumaykin/nexbot/internal/gitws"
 	"github.com/aatumaykin/nexbot/internal/ipc"
+	"github.com/aatumaykin/nexbot/internal/jobs"
 	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/monitor"
+	"github.com/aatumaykin/nexbot/internal/process"
+	"github.com/aatumaykin/nexbot/internal/snapshot"
+	"github.com/aatumaykin/nexbot/internal/throttle"
+	"github.com/aatumaykin/nexbot/internal/update"
+	"github.com/aatumaykin/nexbot/internal/watcher"
 	"github.com/aatumaykin/nexbot/internal/workers"
 	"sync"
+	"sync/atomic"
 )
 
 // App represents the main application structure.
@@ -38,6 +56,13 @@ type App struct {
 	// Channels
 	telegram *telegram.Connector
 
+	// Leader election: Telegram is polled only while leading
+	leaderDone chan struct{}
+	leading    atomic.Bool
+
+	// Per-user inbound message limits
+	limiter *throttle.Limiter
+
 	// Scheduled tasks
 	cronScheduler *cron.Scheduler
 
@@ -50,9 +75,58 @@ type App struct {
 	// Cleanup scheduler
 	cleanupScheduler *cleanup.Scheduler
 
+	// Background job queue
+	jobQueue *jobs.Queue
+
+	// Guided forms for missing tool arguments
+	formManager *forms.Manager
+
+	// Approval of destructive tool calls
+	approvalManager *approval.Manager
+
+	// Files produced by tools, kept per session
+	artifactStore *artifacts.Store
+
+	// Workspace files saved before each turn changes them, for /rollback
+	snapshots *snapshot.Store
+
+	// Git repository of the workspace, committed after each turn
+	workspaceRepo *gitws.Repo
+
+	// Long code blocks of answers sent as images
+	codeImages    *codeimage.Renderer
+	codeImagesDir string
+
+	// Digest of automated notifications
+	digest *digest.Aggregator
+
+	// Daily conversation statistics
+	analytics *analytics.Store
+
+	// Notices about operational errors to the admin chat
+	alerts *alerts.Notifier
+
+	// Check for new releases
+	updateChecker *update.Checker
+
+	// Periodic export of sessions to Obsidian/Notion
+	exportScheduler *export.Scheduler
+
+	// File watcher
+	watcher *watcher.Watcher
+
+	// Web page monitor
+	monitor *monitor.Monitor
+
+	// Background process manager
+	processManager *process.Manager
+
 	// IPC handler
 	ipcHandler *ipc.Handler
 
+	// Web dashboard
+	dashboard *dashboard.Server
+
 	// Context management
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -77,7 +151,7 @@ func New(cfg *config.Config, log *logger.Logger) *App {
 // It performs the following steps:
 //  1. Initializes all components via Initialize()
 //  2. Starts message processing via StartMessageProcessing()
-//  3. Logs that the application is running
+//  3. Logs that the application is running and notifies systemd
 //  4. Waits for the context to be cancelled
 //  5. Performs graceful shutdown via Shutdown()
 func (a *App) Run(ctx context.Context) error {
@@ -93,6 +167,7 @@ func (a *App) Run(ctx context.Context) error {
 
 	// Log that application is running
 	a.logger.Info("Application is running")
+	a.notifyReady(a.ctx)
 
 	// Wait for context cancellation
 	<-ctx.Done()
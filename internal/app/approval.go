@@ -0,0 +1,84 @@
+// Package app provides approval of destructive actions for Nexbot.
+// This file runs the destructive tool calls the user approved.
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/approval"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// newApprovalManager creates the approval manager. Only channels whose
+// connector routes button presses to it can approve; destructive calls in
+// other channels are denied.
+func (a *App) newApprovalManager() *approval.Manager {
+	var channels []string
+	if a.config.Channels.Telegram.Enabled {
+		channels = append(channels, string(bus.ChannelTypeTelegram))
+	}
+	return approval.NewManager(approval.Config{
+		Channels: channels,
+		TTL:      time.Duration(a.config.Agent.Approval.TTLMinutes) * time.Minute,
+	}, a.messageBus, a.completeApproval)
+}
+
+// completeApproval runs the approved calls and hands the results to the
+// agent, which continues the task in the request's session. Rejected calls
+// are reported to the agent without running them.
+func (a *App) completeApproval(ctx context.Context, req *approval.Request, approved bool) {
+	// Approved calls are a turn of their own for /rollback
+	if a.snapshots != nil {
+		ctx = tools.WithSnapshotter(ctx, a.snapshots.Begin(req.SessionID))
+	}
+
+	var b strings.Builder
+	for i, call := range req.Calls {
+		fmt.Fprintf(&b, "%d. %s %s\n", i+1, call.Tool, call.Arguments)
+		if !approved {
+			continue
+		}
+		result := a.agentLoop.ExecuteTool(tools.WithApproved(ctx), req.SessionID, tools.ToolCall{
+			ID:        call.ID,
+			Name:      call.Tool,
+			Arguments: call.Arguments,
+		})
+		if result.Error != nil {
+			fmt.Fprintf(&b, "Failed: %s\n", result.Error.ToLLMContext())
+		} else {
+			fmt.Fprintf(&b, "Result: %s\n", result.Content)
+		}
+	}
+
+	content := fmt.Sprintf(constants.MsgApprovalRejected, b.String())
+	if approved {
+		content = fmt.Sprintf(constants.MsgApprovalCompleted, b.String())
+	}
+	a.logger.InfoCtx(ctx, "Destructive actions answered",
+		logger.Field{Key: "session_id", Value: req.SessionID},
+		logger.Field{Key: "approved", Value: approved},
+		logger.Field{Key: "calls", Value: len(req.Calls)})
+
+	channel, _, _ := strings.Cut(req.SessionID, ":")
+	msg := bus.NewInboundMessage(
+		bus.ChannelType(channel),
+		"", // Empty user_id for system notifications
+		req.SessionID,
+		content,
+		map[string]any{
+			"source":         "approval",
+			"approval_token": req.Token,
+			"approved":       approved,
+		},
+	)
+	if err := a.messageBus.PublishInbound(*msg); err != nil {
+		a.logger.ErrorCtx(ctx, "Failed to publish approval result", err,
+			logger.Field{Key: "session_id", Value: req.SessionID})
+	}
+}
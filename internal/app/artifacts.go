@@ -0,0 +1,92 @@
+// Package app provides tool artifact delivery for Nexbot.
+// This file keeps files produced by tools and sends them to the user after the answer.
+package app
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aatumaykin/nexbot/internal/agent/loop"
+	"github.com/aatumaykin/nexbot/internal/artifacts"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// producedArtifact is an artifact together with the tool that produced it.
+type producedArtifact struct {
+	tool     string
+	artifact tools.Artifact
+}
+
+// collectArtifacts collects the artifacts produced by the tools of a message.
+// The returned function returns the collected artifacts, each file once.
+func collectArtifacts(ctx context.Context) (context.Context, func() []producedArtifact) {
+	var (
+		mu       sync.Mutex
+		seen     = make(map[string]bool)
+		produced []producedArtifact
+	)
+
+	ctx = loop.WithArtifacts(ctx, func(tool string, a tools.Artifact) {
+		mu.Lock()
+		defer mu.Unlock()
+		if seen[a.Path] {
+			return
+		}
+		seen[a.Path] = true
+		produced = append(produced, producedArtifact{tool: tool, artifact: a})
+	})
+
+	return ctx, func() []producedArtifact {
+		mu.Lock()
+		defer mu.Unlock()
+		return produced
+	}
+}
+
+// sendArtifacts stores the artifacts of a message in its session and sends
+// them to the user, images as photos and other files as documents. The
+// stored copy is sent, so later changes to the original file don't matter.
+func (a *App) sendArtifacts(ctx context.Context, msg bus.InboundMessage, produced []producedArtifact) {
+	for _, p := range produced {
+		artifact := p.artifact
+		if a.artifactStore != nil {
+			entry, err := a.artifactStore.Register(msg.SessionID, artifact.Path, artifacts.Entry{
+				Name:     artifact.Name,
+				MimeType: artifact.MimeType,
+				Tool:     p.tool,
+				Caption:  artifact.Caption,
+			})
+			if err != nil {
+				a.logger.WarnCtx(ctx, "Failed to store tool artifact",
+					logger.Field{Key: "session_id", Value: msg.SessionID},
+					logger.Field{Key: "path", Value: artifact.Path},
+					logger.Field{Key: "error", Value: err.Error()})
+			} else {
+				artifact.Path = entry.Path
+			}
+		}
+
+		media := &bus.MediaData{
+			LocalPath: artifact.Path,
+			FileName:  artifact.Name,
+			Caption:   artifact.Caption,
+		}
+
+		var outboundMsg *bus.OutboundMessage
+		if artifact.IsImage() {
+			media.Type = string(bus.MessageTypePhoto)
+			outboundMsg = bus.NewPhotoMessage(msg.ChannelType, msg.UserID, msg.SessionID, media, msg.SessionID, bus.FormatTypePlain, nil)
+		} else {
+			media.Type = string(bus.MessageTypeDocument)
+			outboundMsg = bus.NewDocumentMessage(msg.ChannelType, msg.UserID, msg.SessionID, media, msg.SessionID, bus.FormatTypePlain, nil)
+		}
+
+		if err := a.messageBus.PublishOutbound(*outboundMsg); err != nil {
+			a.logger.ErrorCtx(ctx, "Failed to publish tool artifact", err,
+				logger.Field{Key: "session_id", Value: msg.SessionID},
+				logger.Field{Key: "path", Value: artifact.Path})
+		}
+	}
+}
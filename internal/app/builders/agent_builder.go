@@ -6,8 +6,11 @@ import (
 	"time"
 
 	"github.com/aatumaykin/nexbot/internal/agent/loop"
+	"github.com/aatumaykin/nexbot/internal/agent/routing"
 	"github.com/aatumaykin/nexbot/internal/agent/subagent"
+	"github.com/aatumaykin/nexbot/internal/agent/truncate"
 	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/guardrail"
 	"github.com/aatumaykin/nexbot/internal/llm"
 	"github.com/aatumaykin/nexbot/internal/logger"
 	"github.com/aatumaykin/nexbot/internal/tools"
@@ -30,6 +33,47 @@ func NewAgentBuilder(cfg *config.Config, log *logger.Logger, provider llm.Provid
 	}
 }
 
+// BuildGuard returns guardrails for untrusted tool outputs, or nil if they are disabled.
+func (b *AgentBuilder) BuildGuard() *guardrail.Guard {
+	if !b.config.Guardrails.Enabled {
+		return nil
+	}
+	cfg := guardrail.Config{
+		Tools:  b.config.Guardrails.Tools,
+		Action: b.config.Guardrails.Action,
+	}
+	if b.config.Guardrails.Classify {
+		cfg.Classifier = guardrail.NewLLMClassifier(b.provider, b.config.Guardrails.ClassifierModel, b.config.Guardrails.ClassifyMaxChars)
+	}
+	return guardrail.New(cfg)
+}
+
+// BuildTruncator returns the tool output truncator, or nil if truncation is disabled.
+func (b *AgentBuilder) BuildTruncator() *truncate.Truncator {
+	if !b.config.Agent.ToolOutput.Enabled {
+		return nil
+	}
+	return truncate.New(truncate.Config{
+		MaxTokens:  b.config.Agent.ToolOutput.MaxTokens,
+		ToolTokens: b.config.Agent.ToolOutput.Tools,
+	})
+}
+
+// BuildRouter returns the model router, or nil if routing is disabled.
+func (b *AgentBuilder) BuildRouter() *routing.Router {
+	if !b.config.Agent.Routing.Enabled {
+		return nil
+	}
+	return routing.New(routing.Config{
+		CheapModel:             b.config.Agent.Routing.CheapModel,
+		StrongModel:            b.config.Agent.Model,
+		MaxCheapChars:          b.config.Agent.Routing.MaxCheapChars,
+		MaxCheapToolIterations: b.config.Agent.Routing.MaxCheapToolIterations,
+		ComplexTools:           b.config.Agent.Routing.ComplexTools,
+		Keywords:               b.config.Agent.Routing.Keywords,
+	})
+}
+
 func (b *AgentBuilder) BuildLoop() (*loop.Loop, error) {
 	agentLoop, err := loop.NewLoop(loop.Config{
 		Workspace:         b.workspace.Path(),
@@ -41,6 +85,13 @@ func (b *AgentBuilder) BuildLoop() (*loop.Loop, error) {
 		MaxTokens:         b.config.Agent.MaxTokens,
 		Temperature:       b.config.Agent.Temperature,
 		MaxToolIterations: b.config.Agent.MaxIterations,
+		BudgetWarning:     b.config.Agent.BudgetWarning,
+		ToolBudgets:       b.config.Agent.ToolBudgets,
+		TitleAfterTurns:   b.config.Agent.TitleAfterTurns,
+		Guard:             b.BuildGuard(),
+		Truncator:         b.BuildTruncator(),
+		Router:            b.BuildRouter(),
+		PromptCache:       b.config.Agent.PromptCache,
 		SecretsDir:        b.config.SecretsDir(),
 	})
 	if err != nil {
@@ -68,6 +119,10 @@ func (b *AgentBuilder) BuildSubagentManager(agentLoop *loop.Loop) (*subagent.Man
 			MaxTokens:         b.config.Agent.MaxTokens,
 			Temperature:       b.config.Agent.Temperature,
 			MaxToolIterations: b.config.Agent.MaxIterations,
+			BudgetWarning:     b.config.Agent.BudgetWarning,
+			ToolBudgets:       b.config.Agent.ToolBudgets,
+			Guard:             b.BuildGuard(),
+			Truncator:         b.BuildTruncator(),
 		},
 	})
 	if err != nil {
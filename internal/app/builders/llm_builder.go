@@ -27,7 +27,13 @@ func (b *LLMBuilder) Build() (llm.Provider, error) {
 			APIKey:         b.config.LLM.ZAI.APIKey,
 			TimeoutSeconds: b.config.LLM.ZAI.TimeoutSeconds,
 		}
-		provider := llm.NewZAIProvider(zaiConfig, b.logger)
+		var provider llm.Provider = llm.NewZAIProvider(zaiConfig, b.logger)
+		if b.config.LLM.ZAI.MaxConcurrent > 0 || b.config.LLM.ZAI.RequestsPerMinute > 0 {
+			provider = llm.NewScheduledProvider(provider, llm.SchedulerForKey(b.config.LLM.ZAI.APIKey, llm.SchedulerConfig{
+				MaxConcurrent:     b.config.LLM.ZAI.MaxConcurrent,
+				RequestsPerMinute: b.config.LLM.ZAI.RequestsPerMinute,
+			}))
+		}
 		b.logger.Info("LLM provider initialized", logger.Field{Key: "provider", Value: "zai"})
 		return provider, nil
 	default:
@@ -2,6 +2,7 @@ package builders
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/aatumaykin/nexbot/internal/agent/loop"
 	"github.com/aatumaykin/nexbot/internal/bus"
@@ -103,6 +104,9 @@ func (b *ToolsBuilder) RegisterFileTools(agentLoop *loop.Loop) error {
 
 func (b *ToolsBuilder) RegisterFetchTool(agentLoop *loop.Loop) error {
 	fetchTool := fetch.NewFetchTool(b.config, b.logger)
+	if b.config.Tools.Fetch.CacheDir != "" {
+		fetchTool.SetCacheDir(filepath.Join(b.workspace.Path(), b.config.Tools.Fetch.CacheDir))
+	}
 	if err := agentLoop.RegisterTool(fetchTool); err != nil {
 		return fmt.Errorf("failed to register fetch tool: %w", err)
 	}
@@ -0,0 +1,53 @@
+// Package app provides file change summaries for Nexbot.
+// This file collects the diffs of files changed by tools and appends them to the answer.
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aatumaykin/nexbot/internal/agent/loop"
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// collectChanges collects the file changes made by the tools of a message.
+// The returned function returns the collected changes in the order they were made.
+func collectChanges(ctx context.Context) (context.Context, func() []tools.FileChange) {
+	var (
+		mu      sync.Mutex
+		changes []tools.FileChange
+	)
+
+	ctx = loop.WithChanges(ctx, func(_ string, c tools.FileChange) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, c)
+	})
+
+	return ctx, func() []tools.FileChange {
+		mu.Lock()
+		defer mu.Unlock()
+		return changes
+	}
+}
+
+// appendChanges appends the diffs of file changes to an answer as one diff
+// block, cut to maxLines lines.
+func appendChanges(response string, changes []tools.FileChange, maxLines int) string {
+	if len(changes) == 0 {
+		return response
+	}
+
+	var lines []string
+	for _, change := range changes {
+		lines = append(lines, strings.Split(change.Diff, "\n")...)
+	}
+	if maxLines > 0 && len(lines) > maxLines {
+		hidden := len(lines) - maxLines
+		lines = append(lines[:maxLines], fmt.Sprintf(constants.MsgChangesTruncated, hidden))
+	}
+	return strings.TrimRight(response, "\n") + "\n\n" + constants.MsgChanges + "\n```diff\n" + strings.Join(lines, "\n") + "\n```"
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+func TestAppendChanges(t *testing.T) {
+	changes := []tools.FileChange{
+		{Path: "/ws/a.txt", Diff: "--- a/ws/a.txt\n+++ b/ws/a.txt\n@@ -1 +1 @@\n-old\n+new"},
+		{Path: "/ws/b.txt", Diff: "--- /dev/null\n+++ b/ws/b.txt\n@@ -0,0 +1 @@\n+hello"},
+	}
+
+	got := appendChanges("Done.\n", changes, 0)
+	want := "Done.\n\n" + constants.MsgChanges + "\n```diff\n" + changes[0].Diff + "\n" + changes[1].Diff + "\n```"
+	if got != want {
+		t.Errorf("appendChanges() = %q, want %q", got, want)
+	}
+
+	// Long diffs are cut
+	got = appendChanges("Done.", changes, 5)
+	if !strings.Contains(got, "-old\n+new\n… 4 more lines\n```") || strings.Contains(got, "b.txt") {
+		t.Errorf("appendChanges() with limit = %q", got)
+	}
+
+	// No changes: the answer is unchanged
+	if got := appendChanges("Done.", nil, 5); got != "Done." {
+		t.Errorf("appendChanges() without changes = %q", got)
+	}
+}
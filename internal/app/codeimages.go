@@ -0,0 +1,79 @@
+// Package app provides code image rendering for Nexbot.
+// This file sends long code blocks of answers as highlighted images with the raw code as a file.
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/codeimage"
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// codeImageTool is the producer of code image artifacts in the artifact store.
+const codeImageTool = "code_image"
+
+// renderCodeImages replaces the long code blocks of an answer with a
+// reference and returns an image and a raw code file for each of them,
+// delivered as artifacts. Blocks that fail to render stay in the answer.
+func (a *App) renderCodeImages(ctx context.Context, response string) (string, []producedArtifact) {
+	if a.codeImages == nil {
+		return response, nil
+	}
+
+	var produced []producedArtifact
+	prefix := fmt.Sprintf("code-%d", time.Now().UnixNano())
+	response, _ = a.codeImages.Extract(response, func(_ int, block codeimage.Block) string {
+		n := len(produced)/2 + 1
+		files, err := a.writeCodeImage(prefix, n, block)
+		if err != nil {
+			a.logger.WarnCtx(ctx, "Failed to render code image",
+				logger.Field{Key: "language", Value: block.Language},
+				logger.Field{Key: "error", Value: err.Error()})
+			return "```" + block.Language + "\n" + block.Code + "\n```"
+		}
+		produced = append(produced, files...)
+		return fmt.Sprintf(constants.MsgCodeImage, n, strings.Count(block.Code, "\n")+1)
+	})
+	return response, produced
+}
+
+// writeCodeImage saves the image and the raw code of the n-th block in the
+// code images directory.
+func (a *App) writeCodeImage(prefix string, n int, block codeimage.Block) ([]producedArtifact, error) {
+	image, err := a.codeImages.Render(block)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(a.codeImagesDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create code images directory: %w", err)
+	}
+
+	ext := a.codeImages.Extension(block)
+	name := fmt.Sprintf("%s-%d", prefix, n)
+	imagePath := filepath.Join(a.codeImagesDir, name+".png")
+	if err := os.WriteFile(imagePath, image, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write code image: %w", err)
+	}
+	codePath := filepath.Join(a.codeImagesDir, name+ext)
+	if err := os.WriteFile(codePath, []byte(block.Code+"\n"), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write code file: %w", err)
+	}
+
+	// Files are shown to the user without the unique prefix
+	imageArtifact := tools.NewArtifact(imagePath, fmt.Sprintf(constants.MsgCodeImageCaption, n))
+	imageArtifact.Name = fmt.Sprintf("code-%d.png", n)
+	codeArtifact := tools.NewArtifact(codePath, "")
+	codeArtifact.Name = fmt.Sprintf("code-%d%s", n, ext)
+
+	return []producedArtifact{
+		{tool: codeImageTool, artifact: imageArtifact},
+		{tool: codeImageTool, artifact: codeArtifact},
+	}, nil
+}
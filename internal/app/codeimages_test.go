@@ -0,0 +1,45 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/codeimage"
+	"github.com/aatumaykin/nexbot/internal/constants"
+)
+
+func TestApp_renderCodeImages(t *testing.T) {
+	renderer, err := codeimage.New(codeimage.Config{MinLines: 3})
+	if err != nil {
+		t.Fatalf("codeimage.New() error = %v", err)
+	}
+	app := &App{logger: createTestLogger(t), codeImages: renderer, codeImagesDir: t.TempDir()}
+
+	code := "package main\n\nfunc main() {\n}"
+	response, produced := app.renderCodeImages(t.Context(), "Done:\n```go\n"+code+"\n```\nShort: ```x```")
+
+	if want := "Done:\n" + fmt.Sprintf(constants.MsgCodeImage, 1, 4) + "\nShort: ```x```"; response != want {
+		t.Errorf("response = %q, want %q", response, want)
+	}
+	if len(produced) != 2 {
+		t.Fatalf("produced = %+v, want image and code", produced)
+	}
+	if !produced[0].artifact.IsImage() || produced[0].artifact.Name != "code-1.png" {
+		t.Errorf("image artifact = %+v", produced[0].artifact)
+	}
+	if produced[1].artifact.Name != "code-1.go" {
+		t.Errorf("code artifact = %+v", produced[1].artifact)
+	}
+	data, err := os.ReadFile(produced[1].artifact.Path)
+	if err != nil || strings.TrimSpace(string(data)) != code {
+		t.Errorf("code file = %q, %v", data, err)
+	}
+
+	// Disabled: the answer is unchanged
+	app.codeImages = nil
+	if response, produced := app.renderCodeImages(t.Context(), "```go\n"+code+"\n```"); len(produced) != 0 || !strings.Contains(response, code) {
+		t.Errorf("disabled: response = %q, produced = %+v", response, produced)
+	}
+}
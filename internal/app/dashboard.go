@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/dashboard"
+	"github.com/aatumaykin/nexbot/internal/httpauth"
+	"github.com/aatumaykin/nexbot/internal/jobs"
+)
+
+// startDashboard starts the web dashboard over the session history, the
+// queue depths, the daily statistics and a configuration summary without
+// secrets.
+func (a *App) startDashboard(ctx context.Context, jobStore *jobs.Store) error {
+	cfg := a.config.Dashboard
+	logs := a.logger.Stream()
+	if logs != nil {
+		logs.Enable(cfg.LogBacklog)
+	}
+	server, err := dashboard.New(dashboard.Config{
+		Listen:         cfg.Listen,
+		Tokens:         cfg.Tokens,
+		AllowedIPs:     cfg.AllowedIPs,
+		Routes:         dashboardRoutes(cfg.Routes),
+		Sessions:       a.agentLoop.GetSessionManager(),
+		Queues:         func() []dashboard.Queue { return a.queueDepths(jobStore) },
+		Settings:       configSummary(a.config),
+		Logs:           logs,
+		Analytics:      a.analytics,
+		ActiveWindow:   time.Duration(cfg.ActiveMinutes) * time.Minute,
+		RecentMessages: cfg.RecentMessages,
+	}, a.logger)
+	if err != nil {
+		return err
+	}
+	if err := server.Start(ctx); err != nil {
+		return err
+	}
+	a.dashboard = server
+	return nil
+}
+
+// dashboardRoutes converts the configured access rules of dashboard paths.
+func dashboardRoutes(routes []config.DashboardRouteConfig) []httpauth.Route {
+	converted := make([]httpauth.Route, 0, len(routes))
+	for _, route := range routes {
+		converted = append(converted, httpauth.Route{
+			Path:       route.Path,
+			Secret:     route.Secret,
+			Tokens:     route.Tokens,
+			AllowedIPs: route.AllowedIPs,
+		})
+	}
+	return converted
+}
+
+// queueDepths reports the message bus queues, the worker pool queue and
+// the number of queued background jobs.
+func (a *App) queueDepths(jobStore *jobs.Store) []dashboard.Queue {
+	var queues []dashboard.Queue
+	for _, q := range a.messageBus.QueueDepths() {
+		queues = append(queues, dashboard.Queue{Name: "bus." + q.Name, Length: q.Length, Capacity: q.Capacity})
+	}
+	if a.workerPool != nil {
+		queues = append(queues, dashboard.Queue{
+			Name:     "workers",
+			Length:   a.workerPool.QueueSize(),
+			Capacity: a.workerPool.QueueCapacity(),
+		})
+	}
+	if jobStore != nil {
+		if list, err := jobStore.List(); err == nil {
+			queued := 0
+			for _, job := range list {
+				if job.Status == jobs.StatusQueued {
+					queued++
+				}
+			}
+			queues = append(queues, dashboard.Queue{Name: "jobs", Length: queued})
+		}
+	}
+	return queues
+}
+
+// configSummary lists the main configuration values. Keys, tokens and URLs
+// that may carry credentials are left out.
+func configSummary(cfg *config.Config) []dashboard.Setting {
+	enabled := func(on bool) string { return strconv.FormatBool(on) }
+	settings := []dashboard.Setting{
+		{Key: "profile", Value: cfg.Profile()},
+		{Key: "workspace.path", Value: cfg.Workspace.Path},
+		{Key: "agent.provider", Value: cfg.Agent.Provider},
+		{Key: "agent.model", Value: cfg.Agent.Model},
+		{Key: "agent.max_tokens", Value: strconv.Itoa(cfg.Agent.MaxTokens)},
+		{Key: "agent.max_iterations", Value: strconv.Itoa(cfg.Agent.MaxIterations)},
+		{Key: "message_bus.capacity", Value: strconv.Itoa(cfg.MessageBus.Capacity)},
+		{Key: "channels.telegram.enabled", Value: enabled(cfg.Channels.Telegram.Enabled)},
+		{Key: "tools.file.enabled", Value: enabled(cfg.Tools.File.Enabled)},
+		{Key: "tools.shell.enabled", Value: enabled(cfg.Tools.Shell.Enabled)},
+		{Key: "tools.fetch.enabled", Value: enabled(cfg.Tools.Fetch.Enabled)},
+		{Key: "tools.process.enabled", Value: enabled(cfg.Tools.Process.Enabled)},
+		{Key: "tools.namespaces.disabled", Value: strings.Join(cfg.Tools.DisabledNamespaces(), ", ")},
+		{Key: "cron.enabled", Value: enabled(cfg.Cron.Enabled)},
+		{Key: "jobs.enabled", Value: enabled(cfg.Jobs.Enabled)},
+		{Key: "watcher.enabled", Value: enabled(cfg.Watcher.Enabled)},
+		{Key: "monitor.enabled", Value: enabled(cfg.Monitor.Enabled)},
+		{Key: "throttle.enabled", Value: enabled(cfg.Throttle.Enabled)},
+		{Key: "moderation.enabled", Value: enabled(cfg.Moderation.Enabled)},
+		{Key: "stt.enabled", Value: enabled(cfg.STT.Enabled)},
+		{Key: "leader.enabled", Value: enabled(cfg.Leader.Enabled)},
+		{Key: "analytics.enabled", Value: enabled(cfg.Analytics.Enabled)},
+	}
+	if cfg.Leader.Enabled {
+		settings = append(settings, dashboard.Setting{Key: "leader.backend", Value: cfg.Leader.Backend})
+	}
+	return settings
+}
@@ -0,0 +1,34 @@
+// Package app provides the digest of automated notifications for Nexbot.
+// This file routes answers to cron jobs, watcher notifications and job results into the digest.
+package app
+
+import (
+	"context"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/digest"
+)
+
+// digestSource returns the digest source of an automated inbound message,
+// or "" for messages of users.
+func digestSource(msg bus.InboundMessage) string {
+	if _, ok := msg.Metadata["cron_job_id"]; ok {
+		return digest.SourceCron
+	}
+	if source, _ := msg.Metadata["source"].(string); source == "watcher" {
+		return digest.SourceWatcher
+	}
+	return ""
+}
+
+// digested reports whether answers to the message are batched into the digest.
+func (a *App) digested(msg bus.InboundMessage) bool {
+	return a.digest != nil && a.digest.Accepts(digestSource(msg))
+}
+
+// queueDigest adds a notification to the digest of the session. Returns
+// false if the digest is disabled or doesn't batch the source; the caller
+// then sends the notification right away.
+func (a *App) queueDigest(ctx context.Context, sessionID, source, content string) bool {
+	return a.digest != nil && a.digest.Add(ctx, sessionID, source, content)
+}
@@ -0,0 +1,36 @@
+// Package app provides session export for Nexbot.
+// This file creates the exporter pushing transcripts to Obsidian and Notion.
+package app
+
+import (
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/export"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// notionTimeout limits a single request to the Notion API
+const notionTimeout = 30 * time.Second
+
+// newExporter creates the session exporter for the configured targets.
+// Returns nil when no export target is enabled.
+func (a *App) newExporter(workspacePath string) *export.Exporter {
+	cfg := a.config.Export
+
+	var targets []export.Target
+	if cfg.Obsidian.Enabled {
+		targets = append(targets, export.NewObsidianTarget(cfg.Obsidian.VaultPath, cfg.Obsidian.Folder))
+	}
+	if cfg.Notion.Enabled {
+		targets = append(targets, export.NewNotionTarget(cfg.Notion.Token, cfg.Notion.ParentPageID, cfg.Notion.APIURL, notionTimeout))
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	exporter := export.NewExporter(a.agentLoop, workspacePath, targets...)
+	a.logger.Info("Session export enabled",
+		logger.Field{Key: "targets", Value: exporter.Targets()},
+		logger.Field{Key: "interval_minutes", Value: cfg.IntervalMinutes})
+	return exporter
+}
@@ -0,0 +1,65 @@
+// Package app provides guided form handling for Nexbot.
+// This file runs tools whose missing arguments were collected with a form.
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/forms"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// newFormManager creates the form manager for channels that route answers to it.
+func (a *App) newFormManager() *forms.Manager {
+	return forms.NewManager(forms.Config{
+		Channels: []string{string(bus.ChannelTypeTelegram)},
+		TTL:      time.Duration(a.config.Forms.TTLMinutes) * time.Minute,
+	}, a.messageBus, a.completeForm)
+}
+
+// completeForm runs the tool with the collected arguments and hands the result
+// to the agent, which reports it to the user in the form's session.
+func (a *App) completeForm(ctx context.Context, form *forms.Form) {
+	args, err := json.Marshal(form.Args)
+	if err != nil {
+		a.logger.ErrorCtx(ctx, "Failed to encode form arguments", err,
+			logger.Field{Key: "tool_name", Value: form.Tool})
+		return
+	}
+
+	result := a.agentLoop.ExecuteTool(ctx, form.SessionID, tools.ToolCall{
+		ID:        "form_" + form.Token,
+		Name:      form.Tool,
+		Arguments: string(args),
+	})
+
+	content := fmt.Sprintf(constants.MsgFormCompleted, form.Tool, result.Content)
+	if result.Error != nil {
+		content = fmt.Sprintf(constants.MsgFormToolFailed, form.Tool, result.Error.ToLLMContext())
+	}
+
+	channel, _, _ := strings.Cut(form.SessionID, ":")
+	msg := bus.NewInboundMessage(
+		bus.ChannelType(channel),
+		"", // Empty user_id for system notifications
+		form.SessionID,
+		content,
+		map[string]any{
+			"source":     "form",
+			"form_token": form.Token,
+			"tool_name":  form.Tool,
+		},
+	)
+	if err := a.messageBus.PublishInbound(*msg); err != nil {
+		a.logger.ErrorCtx(ctx, "Failed to publish form result", err,
+			logger.Field{Key: "tool_name", Value: form.Tool},
+			logger.Field{Key: "session_id", Value: form.SessionID})
+	}
+}
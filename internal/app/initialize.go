@@ -2,23 +2,69 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/aatumaykin/nexbot"
+	"github.com/aatumaykin/nexbot/internal/agent/debate"
 	"github.com/aatumaykin/nexbot/internal/agent/loop"
+	"github.com/aatumaykin/nexbot/internal/agent/planner"
+	"github.com/aatumaykin/nexbot/internal/agent/routing"
+	"github.com/aatumaykin/nexbot/internal/agent/session"
 	"github.com/aatumaykin/nexbot/internal/agent/subagent"
+	"github.com/aatumaykin/nexbot/internal/agent/toolselect"
+	"github.com/aatumaykin/nexbot/internal/agent/truncate"
+	"github.com/aatumaykin/nexbot/internal/agent/verify"
+	"github.com/aatumaykin/nexbot/internal/alerts"
+	"github.com/aatumaykin/nexbot/internal/analytics"
+	"github.com/aatumaykin/nexbot/internal/artifacts"
 	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/capture"
 	"github.com/aatumaykin/nexbot/internal/channels/telegram"
+	"github.com/aatumaykin/nexbot/internal/cleanup"
+	"github.com/aatumaykin/nexbot/internal/codeimage"
 	"github.com/aatumaykin/nexbot/internal/commands"
+	"github.com/aatumaykin/nexbot/internal/config"
 	"github.com/aatumaykin/nexbot/internal/cron"
+	"github.com/aatumaykin/nexbot/internal/digest"
+	"github.com/aatumaykin/nexbot/internal/egress"
+	"github.com/aatumaykin/nexbot/internal/experiment"
+	"github.com/aatumaykin/nexbot/internal/export"
+	"github.com/aatumaykin/nexbot/internal/feedback"
+	"github.com/aatumaykin/nexbot/internal/followup"
 
+	"github.com/aatumaykin/nexbot/internal/gitws"
+	"github.com/aatumaykin/nexbot/internal/guardrail"
+	"github.com/aatumaykin/nexbot/internal/invites"
 	"github.com/aatumaykin/nexbot/internal/ipc"
+	"github.com/aatumaykin/nexbot/internal/jobs"
 	"github.com/aatumaykin/nexbot/internal/llm"
 	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/media"
+	"github.com/aatumaykin/nexbot/internal/moderation"
+	"github.com/aatumaykin/nexbot/internal/monitor"
+	"github.com/aatumaykin/nexbot/internal/netguard"
+	"github.com/aatumaykin/nexbot/internal/onboarding"
+	"github.com/aatumaykin/nexbot/internal/pii"
+	"github.com/aatumaykin/nexbot/internal/privacy"
+	"github.com/aatumaykin/nexbot/internal/process"
+	"github.com/aatumaykin/nexbot/internal/projects"
+	"github.com/aatumaykin/nexbot/internal/snapshot"
+	"github.com/aatumaykin/nexbot/internal/structured"
+	"github.com/aatumaykin/nexbot/internal/stt"
+	"github.com/aatumaykin/nexbot/internal/throttle"
 	"github.com/aatumaykin/nexbot/internal/tools"
 	"github.com/aatumaykin/nexbot/internal/tools/fetch"
 	"github.com/aatumaykin/nexbot/internal/tools/file"
+	"github.com/aatumaykin/nexbot/internal/tools/plot"
+	"github.com/aatumaykin/nexbot/internal/update"
+	"github.com/aatumaykin/nexbot/internal/upload"
+	"github.com/aatumaykin/nexbot/internal/users"
+	"github.com/aatumaykin/nexbot/internal/version"
+	"github.com/aatumaykin/nexbot/internal/watcher"
 	"github.com/aatumaykin/nexbot/internal/workers"
 	"github.com/aatumaykin/nexbot/internal/workspace"
 )
@@ -56,12 +102,42 @@ func (a *App) Initialize(ctx context.Context) error {
 	// 1. Create application context
 	a.ctx, a.cancel = context.WithCancel(ctx)
 
+	// 1.1. Initialize outbound network settings (proxy, CA bundle, DNS)
+	network, err := egress.New(egress.Config{
+		Proxy:      a.config.Network.Proxy,
+		NoProxy:    a.config.Network.NoProxy,
+		CAFile:     a.config.Network.CAFile,
+		DNSServers: a.config.Network.DNSServers,
+		Hosts:      a.config.Network.Hosts,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize network settings: %w", err)
+	}
+	if network.Configured() {
+		a.logger.Info("Outbound network settings applied",
+			logger.Field{Key: "proxy", Value: network.ProxyAddr()},
+			logger.Field{Key: "ca_file", Value: a.config.Network.CAFile},
+			logger.Field{Key: "dns_servers", Value: a.config.Network.DNSServers})
+	}
+
 	// 2. Initialize message bus
 	a.messageBus = bus.New(a.config.MessageBus.Capacity, a.config.MessageBus.SubscriberChannelSize, a.logger)
 	if err := a.messageBus.Start(a.ctx); err != nil {
 		return fmt.Errorf("failed to start message bus: %w", err)
 	}
 
+	// 2.1. Initialize outbound moderation
+	if a.config.Moderation.Enabled {
+		moderator, err := newModerator(a.config.Moderation, a.messageBus, a.logger)
+		if err != nil {
+			return fmt.Errorf("failed to initialize moderation: %w", err)
+		}
+		a.messageBus.SetOutboundFilter(moderator)
+		a.logger.Info("Outbound moderation enabled",
+			logger.Field{Key: "backends", Value: a.config.Moderation.Backends},
+			logger.Field{Key: "action", Value: a.config.Moderation.Action})
+	}
+
 	// 3. Initialize LLM provider
 	var provider llm.Provider
 	switch a.config.Agent.Provider {
@@ -70,7 +146,19 @@ func (a *App) Initialize(ctx context.Context) error {
 			APIKey:         a.config.LLM.ZAI.APIKey,
 			TimeoutSeconds: a.config.LLM.ZAI.TimeoutSeconds,
 		}
+		if network.Configured() {
+			zaiConfig.Transport = network.Transport(30 * time.Second)
+		}
 		provider = llm.NewZAIProvider(zaiConfig, a.logger)
+		if a.config.LLM.ZAI.MaxConcurrent > 0 || a.config.LLM.ZAI.RequestsPerMinute > 0 {
+			provider = llm.NewScheduledProvider(provider, llm.SchedulerForKey(a.config.LLM.ZAI.APIKey, llm.SchedulerConfig{
+				MaxConcurrent:     a.config.LLM.ZAI.MaxConcurrent,
+				RequestsPerMinute: a.config.LLM.ZAI.RequestsPerMinute,
+			}))
+			a.logger.Info("LLM request queue enabled",
+				logger.Field{Key: "max_concurrent", Value: a.config.LLM.ZAI.MaxConcurrent},
+				logger.Field{Key: "requests_per_minute", Value: a.config.LLM.ZAI.RequestsPerMinute})
+		}
 	default:
 		return fmt.Errorf("unsupported LLM provider: %s", a.config.Agent.Provider)
 	}
@@ -84,6 +172,37 @@ func (a *App) Initialize(ctx context.Context) error {
 		return fmt.Errorf("failed to create sessions subdirectory: %w", err)
 	}
 
+	// 4.0.1. Capture diagnostics of malformed LLM responses
+	var captureStore *capture.Store
+	captureLogprobs := a.config.Agent.Capture.Logprobs
+	if a.config.Agent.Capture.Enabled {
+		if captureLogprobs && !llm.SupportsLogprobs(provider) {
+			// Requesting them anyway would only store empty logprobs
+			a.logger.Warn("LLM provider does not return logprobs, agent.capture.logprobs is ignored",
+				logger.Field{Key: "provider", Value: a.config.Agent.Provider})
+			captureLogprobs = false
+		}
+		captureStore = capture.NewStore(ws.Path(), capture.Config{
+			MaxEntries: a.config.Agent.Capture.MaxEntries,
+			All:        a.config.Agent.Capture.All,
+		})
+		a.logger.Info("LLM response capture enabled",
+			logger.Field{Key: "all", Value: a.config.Agent.Capture.All},
+			logger.Field{Key: "logprobs", Value: captureLogprobs})
+	}
+
+	// 4.0.2. Record or replay LLM responses and tool results for reproducible runs
+	var toolRecorder *tools.ToolRecorder
+	if det := a.config.Agent.Deterministic; det.Enabled && det.Mode != "" {
+		dir := ws.Subpath(det.Dir)
+		provider = newRecordingProvider(det.Mode, provider, filepath.Join(dir, "llm"))
+		toolRecorder = tools.NewToolRecorder(filepath.Join(dir, "tools"), det.Mode == "replay")
+		a.logger.Info("Deterministic run recording enabled",
+			logger.Field{Key: "mode", Value: det.Mode},
+			logger.Field{Key: "dir", Value: dir},
+			logger.Field{Key: "seed", Value: det.Seed})
+	}
+
 	// 4.1. Initialize secrets directory
 	secretsDir := a.config.SecretsDir()
 	if err := os.MkdirAll(secretsDir, 0700); err != nil {
@@ -92,8 +211,67 @@ func (a *App) Initialize(ctx context.Context) error {
 	a.logger.Info("Secrets directory initialized",
 		logger.Field{Key: "path", Value: secretsDir})
 
+	// 4.1. Initialize digest of automated notifications
+	if a.config.Digest.Enabled {
+		a.digest = digest.New(digest.Config{
+			Interval:   time.Duration(a.config.Digest.IntervalMinutes) * time.Minute,
+			MaxItems:   a.config.Digest.MaxItems,
+			Sources:    a.config.Digest.Sources,
+			Summarizer: digest.NewLLMSummarizer(provider, a.config.Digest.Model),
+			Publisher:  a.messageBus,
+			Logger:     a.logger,
+		})
+		a.digest.Start(a.ctx)
+	}
+
+	// 4.1. Initialize conversation analytics
+	if a.config.Analytics.Enabled {
+		a.analytics = analytics.NewStore(ws.Path(), a.config.Analytics.RetentionDays, a.logger)
+		a.analytics.SetSlowToolThreshold(time.Duration(a.config.Analytics.SlowToolP95Ms) * time.Millisecond)
+		a.analytics.Start(a.ctx, analytics.DefaultFlushInterval)
+	}
+
+	// 4.1. Initialize admin alerts
+	if a.config.Alerts.Enabled {
+		a.alerts = alerts.New(alerts.Config{
+			SessionID:  a.config.Alerts.SessionID,
+			Cooldown:   time.Duration(a.config.Alerts.CooldownSeconds) * time.Second,
+			MaxPerHour: a.config.Alerts.MaxPerHour,
+			Logger:     a.logger,
+		}, a.messageBus)
+		a.alerts.WatchQueues(a.ctx, a.messageBus, a.config.Alerts.QueueThresholdPercent)
+		a.logger.Info("Admin alerts enabled",
+			logger.Field{Key: "session_id", Value: a.config.Alerts.SessionID})
+	}
+
+	// 4.1. Initialize release update checker
+	if a.config.Update.Enabled {
+		a.updateChecker = update.New(update.Config{
+			Repo:           a.config.Update.Repo,
+			CurrentVersion: version.Version,
+			Interval:       time.Duration(a.config.Update.IntervalHours) * time.Hour,
+			SessionID:      a.config.Update.SessionID,
+			SelfUpdate:     a.config.Update.SelfUpdate,
+			Client:         network.Client(time.Minute),
+			Logger:         a.logger,
+		}, a.messageBus)
+		a.updateChecker.Start(a.ctx)
+	}
+
+	// 4.1. Initialize message templates
+	messageTemplates, err := MessageTemplates(a.config.Templates)
+	if err != nil {
+		return err
+	}
+
 	// 4.1. Initialize worker pool
 	workerPool := workers.NewPool(a.config.Workers.PoolSize, a.config.Workers.QueueSize, a.logger, a.messageBus)
+	if a.digest != nil {
+		workerPool.SetDigest(a.digest)
+	}
+	if messageTemplates != nil {
+		workerPool.SetTemplates(messageTemplates)
+	}
 	workerPool.Start()
 	a.workerPool = workerPool
 
@@ -106,18 +284,179 @@ func (a *App) Initialize(ctx context.Context) error {
 	a.logger.Info("loaded cron jobs from storage",
 		logger.Field{Key: "count", Value: len(cronJobs)})
 
+	// 4.3. Initialize guardrails on untrusted tool outputs
+	var guard *guardrail.Guard
+	if a.config.Guardrails.Enabled {
+		guardCfg := guardrail.Config{
+			Tools:  a.config.Guardrails.Tools,
+			Action: a.config.Guardrails.Action,
+		}
+		if a.config.Guardrails.Classify {
+			guardCfg.Classifier = guardrail.NewLLMClassifier(provider, a.config.Guardrails.ClassifierModel, a.config.Guardrails.ClassifyMaxChars)
+		}
+		guard = guardrail.New(guardCfg)
+		a.logger.Info("Guardrails enabled",
+			logger.Field{Key: "tools", Value: a.config.Guardrails.Tools},
+			logger.Field{Key: "action", Value: a.config.Guardrails.Action},
+			logger.Field{Key: "classify", Value: a.config.Guardrails.Classify})
+	}
+
+	// 4.4. Initialize model routing (cheap model for simple requests)
+	var router *routing.Router
+	if a.config.Agent.Routing.Enabled {
+		router = newRouter(a.config.Agent)
+		a.logger.Info("Model routing enabled",
+			logger.Field{Key: "cheap_model", Value: a.config.Agent.Routing.CheapModel},
+			logger.Field{Key: "strong_model", Value: a.config.Agent.Model})
+	}
+
+	// 4.4.1. Initialize debate mode (personas refine answers to hard questions)
+	var debater *debate.Debater
+	if a.config.Agent.Debate.Enabled {
+		debater, err = newDebater(a.config.Agent, provider)
+		if err != nil {
+			return fmt.Errorf("failed to create debate mode: %w", err)
+		}
+		a.logger.Info("Debate mode enabled",
+			logger.Field{Key: "auto", Value: a.config.Agent.Debate.Auto},
+			logger.Field{Key: "max_turns", Value: a.config.Agent.Debate.MaxTurns},
+			logger.Field{Key: "max_tokens", Value: a.config.Agent.Debate.MaxTokens})
+	}
+
+	// 4.4.2. Initialize planning phase (multi-step requests are planned before execution)
+	var plan *planner.Planner
+	if a.config.Agent.Planning.Enabled {
+		plan = newPlanner(a.config.Agent.Planning, provider)
+		a.logger.Info("Planning enabled",
+			logger.Field{Key: "min_chars", Value: a.config.Agent.Planning.MinChars},
+			logger.Field{Key: "max_steps", Value: a.config.Agent.Planning.MaxSteps},
+			logger.Field{Key: "step_iterations", Value: a.config.Agent.Planning.StepIterations})
+	}
+
+	// 4.4.3. Initialize answer verification (final answers are checked against tool outputs)
+	var verifier *verify.Verifier
+	if a.config.Agent.Verify.Enabled {
+		verifier, err = newVerifier(a.config.Agent.Verify, provider)
+		if err != nil {
+			return fmt.Errorf("failed to create answer verification: %w", err)
+		}
+		a.logger.Info("Answer verification enabled",
+			logger.Field{Key: "model", Value: a.config.Agent.Verify.Model},
+			logger.Field{Key: "mode", Value: a.config.Agent.Verify.Mode})
+	}
+
+	// 4.4.4. Initialize the prompt A/B test (sessions are split between prompt variants)
+	var exp *experiment.Experiment
+	if a.config.Experiment.Enabled {
+		exp, err = newExperiment(a.config.Experiment)
+		if err != nil {
+			return fmt.Errorf("failed to create prompt experiment: %w", err)
+		}
+		a.logger.Info("Prompt experiment enabled",
+			logger.Field{Key: "name", Value: a.config.Experiment.Name},
+			logger.Field{Key: "variants", Value: len(a.config.Experiment.Variants)})
+	}
+
+	// 4.4.5. Initialize tool selection (only the tools relevant to a request are sent)
+	var selector *toolselect.Selector
+	if a.config.Agent.ToolSelection.Enabled {
+		selector = toolselect.New(toolselect.Config{
+			TopK: a.config.Agent.ToolSelection.TopK,
+			Core: a.config.Agent.ToolSelection.Core,
+		})
+		a.logger.Info("Tool selection enabled",
+			logger.Field{Key: "top_k", Value: a.config.Agent.ToolSelection.TopK})
+	}
+
+	// 4.4.6. Initialize tool output truncation (long results are cut to token budgets)
+	var truncator *truncate.Truncator
+	if a.config.Agent.ToolOutput.Enabled {
+		truncator = truncate.New(truncate.Config{
+			MaxTokens:  a.config.Agent.ToolOutput.MaxTokens,
+			ToolTokens: a.config.Agent.ToolOutput.Tools,
+		})
+		a.logger.Info("Tool output truncation enabled",
+			logger.Field{Key: "max_tokens", Value: a.config.Agent.ToolOutput.MaxTokens})
+	}
+
+	// 4.5. Initialize guided forms for missing tool arguments (answers are routed by telegram)
+	if a.config.Forms.Enabled && a.config.Channels.Telegram.Enabled {
+		a.formManager = a.newFormManager()
+		a.logger.Info("Guided forms enabled",
+			logger.Field{Key: "ttl_minutes", Value: a.config.Forms.TTLMinutes})
+	}
+
+	// 4.5.1. Initialize approval of destructive tool calls (buttons are routed by telegram)
+	if a.config.Agent.Approval.Enabled {
+		a.approvalManager = a.newApprovalManager()
+		a.logger.Info("Approval of destructive actions enabled",
+			logger.Field{Key: "classes", Value: a.config.Agent.Approval.Classes})
+	}
+
+	// 4.6. Initialize masking of personal data in stored history and memory
+	var scrubber session.Scrubber
+	if a.config.PII.Enabled {
+		piiScrubber, err := pii.New(a.config.PII.Categories)
+		if err != nil {
+			return fmt.Errorf("failed to initialize PII scrubber: %w", err)
+		}
+		scrubber = piiScrubber
+		a.logger.Info("PII masking enabled",
+			logger.Field{Key: "categories", Value: a.config.PII.Categories})
+	}
+
+	// 4.7. Initialize projects: attached sessions share an auto-maintained brief
+	var projectStore *projects.Store
+	if a.config.Agent.Projects.Enabled {
+		projectStore = projects.NewStore(ws.Path())
+		if err := projectStore.Load(); err != nil {
+			return fmt.Errorf("failed to load projects: %w", err)
+		}
+		a.logger.Info("Projects enabled",
+			logger.Field{Key: "brief_after_turns", Value: a.config.Agent.Projects.BriefAfterTurns})
+	}
+
 	// 5. Initialize agent loop
 	agentLoop, err := loop.NewLoop(loop.Config{
-		Workspace:         ws.Path(),
-		SessionDir:        ws.Subpath("sessions"),
-		Timezone:          a.config.Cron.Timezone,
-		LLMProvider:       provider,
-		Logger:            a.logger,
-		Model:             a.config.Agent.Model,
-		MaxTokens:         a.config.Agent.MaxTokens,
-		Temperature:       a.config.Agent.Temperature,
-		MaxToolIterations: a.config.Agent.MaxIterations,
-		SecretsDir:        a.config.SecretsDir(),
+		Workspace:              ws.Path(),
+		SessionDir:             ws.Subpath("sessions"),
+		Timezone:               a.config.Cron.Timezone,
+		PromptVariables:        a.config.Agent.Prompt.Variables,
+		PromptDefaults:         nexbot.Prompts(),
+		LLMProvider:            provider,
+		Logger:                 a.logger,
+		Model:                  a.config.Agent.Model,
+		MaxTokens:              a.config.Agent.MaxTokens,
+		Temperature:            a.config.Agent.Temperature,
+		Sampling:               agentSampling(a.config.Agent),
+		Deterministic:          a.config.Agent.Deterministic.Enabled,
+		ToolRecorder:           toolRecorder,
+		Capture:                captureStore,
+		CaptureLogprobs:        captureLogprobs,
+		MaxToolIterations:      a.config.Agent.MaxIterations,
+		BudgetWarning:          a.config.Agent.BudgetWarning,
+		ToolRepairAttempts:     a.config.Agent.ToolRepairAttempts,
+		ToolBudgets:            a.config.Agent.ToolBudgets,
+		TitleAfterTurns:        a.config.Agent.TitleAfterTurns,
+		Projects:               projectStore,
+		ProjectBriefTurns:      a.config.Agent.Projects.BriefAfterTurns,
+		ProjectBriefChars:      a.config.Agent.Projects.MaxBriefChars,
+		Guard:                  guard,
+		Router:                 router,
+		Debate:                 debater,
+		Planner:                plan,
+		Verifier:               verifier,
+		Experiment:             exp,
+		ToolSelector:           selector,
+		Truncator:              truncator,
+		DisabledToolNamespaces: a.config.Tools.DisabledNamespaces(),
+		PromptCache:            a.config.Agent.PromptCache,
+		ToolProtocol:           a.config.Agent.ToolProtocol,
+		Forms:                  a.formManager,
+		Approvals:              a.approvalManager,
+		ApprovalClasses:        a.config.Agent.Approval.Classes,
+		Scrubber:               scrubber,
+		SecretsDir:             a.config.SecretsDir(),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create agent loop: %w", err)
@@ -132,14 +471,29 @@ func (a *App) Initialize(ctx context.Context) error {
 			SessionDir: ws.Subpath("sessions"),
 			Logger:     a.logger,
 			LoopConfig: loop.Config{
-				Workspace:         ws.Path(),
-				SessionDir:        ws.Subpath("sessions"),
-				LLMProvider:       provider,
-				Logger:            a.logger,
-				Model:             a.config.Agent.Model,
-				MaxTokens:         a.config.Agent.MaxTokens,
-				Temperature:       a.config.Agent.Temperature,
-				MaxToolIterations: a.config.Agent.MaxIterations,
+				Workspace:              ws.Path(),
+				SessionDir:             ws.Subpath("sessions"),
+				LLMProvider:            provider,
+				Logger:                 a.logger,
+				Model:                  a.config.Agent.Model,
+				MaxTokens:              a.config.Agent.MaxTokens,
+				Temperature:            a.config.Agent.Temperature,
+				Sampling:               agentSampling(a.config.Agent),
+				Deterministic:          a.config.Agent.Deterministic.Enabled,
+				ToolRecorder:           toolRecorder,
+				Capture:                captureStore,
+				CaptureLogprobs:        captureLogprobs,
+				MaxToolIterations:      a.config.Agent.MaxIterations,
+				BudgetWarning:          a.config.Agent.BudgetWarning,
+				ToolRepairAttempts:     a.config.Agent.ToolRepairAttempts,
+				ToolBudgets:            a.config.Agent.ToolBudgets,
+				Guard:                  guard,
+				Truncator:              truncator,
+				ToolProtocol:           a.config.Agent.ToolProtocol,
+				Scrubber:               scrubber,
+				PromptVariables:        a.config.Agent.Prompt.Variables,
+				PromptDefaults:         nexbot.Prompts(),
+				DisabledToolNamespaces: a.config.Tools.DisabledNamespaces(),
 			},
 		})
 		if err != nil {
@@ -174,6 +528,71 @@ func (a *App) Initialize(ctx context.Context) error {
 		a.logger,
 		a.Restart,
 	)
+	if a.config.Feedback.Enabled {
+		a.commandHandler.SetFeedbackStore(feedback.NewStore(ws.Path()), a.config.Feedback.Profile)
+		if exp != nil {
+			a.commandHandler.SetVariantSource(a.agentLoop)
+		}
+		a.logger.Info("Feedback collection enabled",
+			logger.Field{Key: "profile", Value: a.config.Feedback.Profile})
+	}
+
+	if projectStore != nil {
+		a.commandHandler.SetProjectStore(projectStore)
+	}
+	a.commandHandler.SetContextStore(a.agentLoop.GetSessionManager())
+	a.commandHandler.SetSamplingStore(a.agentLoop.GetSessionManager())
+
+	// Files produced by tools live as long as their session
+	a.artifactStore = artifacts.NewStore(ws.Path())
+	a.commandHandler.SetArtifactStore(a.artifactStore)
+
+	// Workspace changes of each turn are committed to git
+	if cfg := a.config.Workspace.Git; cfg.Enabled {
+		repo, err := gitws.Open(ctx, ws.Path(), gitws.Config{
+			AuthorName:  cfg.AuthorName,
+			AuthorEmail: cfg.AuthorEmail,
+			Exclude:     cfg.Exclude,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to initialize workspace git repository: %w", err)
+		}
+		a.workspaceRepo = repo
+	}
+
+	// Files changed by the agent can be restored with /rollback
+	if cfg := a.config.Agent.Rollback; cfg.Enabled {
+		a.snapshots = snapshot.NewStore(ws.Path(), snapshot.Config{
+			MaxTurns:    cfg.MaxTurns,
+			MaxFileSize: int64(cfg.MaxFileSizeMB) << 20,
+		})
+		a.commandHandler.SetRollbackStore(a.snapshots)
+	}
+
+	// Long code blocks of answers are sent as highlighted images
+	if cfg := a.config.Media.CodeImages; cfg.Enabled {
+		renderer, err := codeimage.New(codeimage.Config{
+			MinLines: cfg.MinLines,
+			MaxLines: cfg.MaxLines,
+			Style:    cfg.Style,
+			FontSize: cfg.FontSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to initialize code images: %w", err)
+		}
+		a.codeImages = renderer
+		a.codeImagesDir = filepath.Join(ws.Path(), cfg.Dir)
+	}
+
+	// Session export to external notes (/export notion, /export obsidian)
+	if exporter := a.newExporter(ws.Path()); exporter != nil {
+		a.commandHandler.SetExporter(exporter)
+		if a.config.Export.IntervalMinutes > 0 {
+			a.exportScheduler = export.NewScheduler(exporter,
+				time.Duration(a.config.Export.IntervalMinutes)*time.Minute, a.logger)
+			a.exportScheduler.Start(a.ctx)
+		}
+	}
 
 	// 7. Register tools
 	// Create message sender interface implementation
@@ -181,11 +600,41 @@ func (a *App) Initialize(ctx context.Context) error {
 
 	// Register SendMessageTool
 	sendMessageTool := tools.NewSendMessageTool(messageSender, a.logger)
+	sendMessageTool.SetPageStore(a.messageBus.GetPageStore())
+	if messageTemplates != nil {
+		sendMessageTool.SetTemplates(messageTemplates)
+	}
 	if err := a.agentLoop.RegisterTool(sendMessageTool); err != nil {
 		return fmt.Errorf("failed to register send message tool: %w", err)
 	}
 	a.logger.Info("Send message tool registered")
 
+	// Register notify tool backed by the user registry
+	userRegistry := users.NewRegistry(ws.Path())
+	staticUsers, err := StaticUsers(a.config.Users)
+	if err != nil {
+		return err
+	}
+	if err := userRegistry.Load(staticUsers); err != nil {
+		return fmt.Errorf("failed to load user registry: %w", err)
+	}
+	var connectedChannels []string
+	if a.config.Channels.Telegram.Enabled {
+		connectedChannels = append(connectedChannels, string(bus.ChannelTypeTelegram))
+	}
+	notifyTool := tools.NewNotifyTool(userRegistry, messageSender, connectedChannels, a.logger)
+	if messageTemplates != nil {
+		notifyTool.SetTemplates(messageTemplates)
+	}
+	if err := a.agentLoop.RegisterTool(notifyTool); err != nil {
+		return fmt.Errorf("failed to register notify tool: %w", err)
+	}
+	if err := a.agentLoop.RegisterTool(tools.NewProfileTool(userRegistry, a.logger)); err != nil {
+		return fmt.Errorf("failed to register profile tool: %w", err)
+	}
+	// System prompts include the profile of the session's user
+	a.agentLoop.GetContextBuilder().SetProfiles(userRegistry)
+
 	// Register shell tool if enabled
 	if a.config.Tools.Shell.Enabled {
 		shellTool := tools.NewShellExecTool(a.config, a.logger)
@@ -202,6 +651,9 @@ func (a *App) Initialize(ctx context.Context) error {
 		}
 
 		writeFileTool := file.NewWriteFileTool(ws, a.config)
+		if scrubber != nil {
+			writeFileTool.SetScrubber(scrubber)
+		}
 		if err := a.agentLoop.RegisterTool(writeFileTool); err != nil {
 			return fmt.Errorf("failed to register write file tool: %w", err)
 		}
@@ -217,15 +669,84 @@ func (a *App) Initialize(ctx context.Context) error {
 		}
 	}
 
+	// Register process tool if enabled
+	if a.config.Tools.Process.Enabled {
+		a.processManager = process.NewManager(process.Config{
+			Workspace:   ws.Path(),
+			MaxJobs:     a.config.Tools.Process.MaxJobs,
+			OutputLines: a.config.Tools.Process.OutputLines,
+			StopTimeout: time.Duration(a.config.Tools.Process.StopTimeoutSeconds) * time.Second,
+		}, a.logger)
+		processTool := tools.NewProcessTool(a.processManager, a.config, a.logger)
+		if err := a.agentLoop.RegisterTool(processTool); err != nil {
+			return fmt.Errorf("failed to register process tool: %w", err)
+		}
+		a.logger.Info("Process tool registered")
+	}
+
 	// Register fetch tool if enabled
 	if a.config.Tools.Fetch.Enabled {
 		fetchTool := fetch.NewFetchTool(a.config, a.logger)
+		fetchTool.SetNetwork(network)
+		if a.config.Tools.Fetch.CacheDir != "" {
+			fetchTool.SetCacheDir(filepath.Join(ws.Path(), a.config.Tools.Fetch.CacheDir))
+		}
 		if err := a.agentLoop.RegisterTool(fetchTool); err != nil {
 			return fmt.Errorf("failed to register fetch tool: %w", err)
 		}
 		a.logger.Info("Fetch tool registered")
 	}
 
+	// Register plot tool if enabled
+	if a.config.Tools.Plot.Enabled {
+		plotTool := plot.NewPlotTool(filepath.Join(ws.Path(), a.config.Tools.Plot.Dir), a.logger)
+		if err := a.agentLoop.RegisterTool(plotTool); err != nil {
+			return fmt.Errorf("failed to register plot tool: %w", err)
+		}
+		a.logger.Info("Plot tool registered")
+	}
+
+	// Register chat administration tools if enabled
+	if a.config.Tools.ChatAdmin.Enabled {
+		for _, tool := range []tools.Tool{
+			tools.NewPinMessageTool(messageSender, a.logger),
+			tools.NewUnpinMessageTool(messageSender, a.logger),
+			tools.NewSetChatTitleTool(messageSender, a.logger),
+		} {
+			if err := a.agentLoop.RegisterTool(tool); err != nil {
+				return fmt.Errorf("failed to register %s tool: %w", tool.Name(), err)
+			}
+		}
+		a.logger.Info("Chat administration tools registered")
+	}
+
+	// Register transcribe_audio tool if speech-to-text is enabled
+	var sttProvider stt.Provider
+	if a.config.STT.Enabled {
+		provider, err := newSTTProvider(a.config.STT)
+		if err != nil {
+			return fmt.Errorf("failed to create speech-to-text provider: %w", err)
+		}
+		sttProvider = provider
+		transcribeTool := file.NewTranscribeAudioTool(ws, a.config, sttProvider, a.config.STT.Language)
+		if err := a.agentLoop.RegisterTool(transcribeTool); err != nil {
+			return fmt.Errorf("failed to register transcribe_audio tool: %w", err)
+		}
+		a.logger.Info("Transcribe audio tool registered",
+			logger.Field{Key: "provider", Value: sttProvider.Name()})
+	}
+
+	// Register structured_output tool if structured output is enabled
+	var generator *structured.Generator
+	if a.config.Structured.Enabled {
+		generator = newStructuredGenerator(a.config, provider)
+		if err := a.agentLoop.RegisterTool(tools.NewStructuredOutputTool(generator)); err != nil {
+			return fmt.Errorf("failed to register structured_output tool: %w", err)
+		}
+		a.logger.Info("Structured output tool registered",
+			logger.Field{Key: "max_retries", Value: a.config.Structured.MaxRetries})
+	}
+
 	// Register SystemTimeTool
 	systemTimeTool := tools.NewSystemTimeTool(a.logger)
 	if err := a.agentLoop.RegisterTool(systemTimeTool); err != nil {
@@ -233,6 +754,39 @@ func (a *App) Initialize(ctx context.Context) error {
 	}
 	a.logger.Info("System time tool registered")
 
+	// Register artifacts tool
+	artifactsTool := tools.NewArtifactsTool(a.artifactStore)
+	if err := a.agentLoop.RegisterTool(artifactsTool); err != nil {
+		return fmt.Errorf("failed to register artifacts tool: %w", err)
+	}
+
+	// Register conversation history search tool
+	searchHistoryTool := tools.NewSearchHistoryTool(a.agentLoop.GetSessionManager())
+	if err := a.agentLoop.RegisterTool(searchHistoryTool); err != nil {
+		return fmt.Errorf("failed to register search_history tool: %w", err)
+	}
+
+	// Register delivery status tool
+	sendStatusTool := tools.NewSendStatusTool(a.agentLoop.GetSessionManager())
+	if err := a.agentLoop.RegisterTool(sendStatusTool); err != nil {
+		return fmt.Errorf("failed to register get_send_status tool: %w", err)
+	}
+
+	// Register tools correcting the agent's own earlier messages
+	for _, tool := range []tools.Tool{
+		tools.NewEditMessageTool(messageSender, a.agentLoop.GetSessionManager(), a.logger),
+		tools.NewDeleteMessageTool(messageSender, a.agentLoop.GetSessionManager(), a.logger),
+	} {
+		if err := a.agentLoop.RegisterTool(tool); err != nil {
+			return fmt.Errorf("failed to register %s tool: %w", tool.Name(), err)
+		}
+	}
+
+	// 7.1. Check configuration tables of the registered tools against their schemas
+	if err := validateToolConfigs(a.config, a.agentLoop.GetTools()); err != nil {
+		return fmt.Errorf("invalid tool configuration: %w", err)
+	}
+
 	// 8. Initialize telegram connector if enabled
 	if a.config.Channels.Telegram.Enabled {
 		a.telegram = telegram.New(
@@ -240,8 +794,79 @@ func (a *App) Initialize(ctx context.Context) error {
 			a.logger,
 			a.messageBus,
 		)
-		if err := a.telegram.Start(a.ctx); err != nil {
-			return fmt.Errorf("failed to start telegram connector: %w", err)
+		if a.config.Throttle.Enabled {
+			a.limiter = newLimiter(a.config.Throttle)
+			a.telegram.SetLimiter(a.limiter)
+			a.logger.Info("Inbound throttling enabled",
+				logger.Field{Key: "messages_per_minute", Value: a.config.Throttle.MessagesPerMinute},
+				logger.Field{Key: "max_concurrent", Value: a.config.Throttle.MaxConcurrent})
+		}
+		if a.formManager != nil {
+			a.telegram.SetForms(a.formManager)
+		}
+		if a.approvalManager != nil {
+			a.telegram.SetApprovals(a.approvalManager)
+		}
+		if a.analytics != nil {
+			a.telegram.SetAnalytics(a.analytics)
+		}
+		if a.config.Invites.Enabled {
+			inviteStore := invites.NewStore(ws.Path(), time.Duration(a.config.Invites.TTLHours)*time.Hour)
+			if err := inviteStore.Load(); err != nil {
+				return fmt.Errorf("failed to load invites: %w", err)
+			}
+			a.telegram.SetInvites(inviteStore)
+			a.logger.Info("Invite codes enabled",
+				logger.Field{Key: "ttl_hours", Value: a.config.Invites.TTLHours},
+				logger.Field{Key: "admins", Value: len(a.config.Channels.Telegram.AdminUsers)})
+		}
+		if a.config.Onboarding.Enabled {
+			a.telegram.SetOnboarding(newOnboarding(a.config.Onboarding, userRegistry, a.messageBus, a.logger))
+			a.logger.Info("Onboarding enabled",
+				logger.Field{Key: "invite_codes", Value: len(a.config.Onboarding.InviteCodes)},
+				logger.Field{Key: "consents", Value: len(a.config.Onboarding.Consents)})
+		}
+		// Users export and delete their data with /forget_me
+		a.telegram.SetPrivacy(privacy.New(ws.Path(), a.agentLoop.GetSessionManager(), userRegistry))
+		mediaPolicy, err := a.newMediaPolicy(ws.Path())
+		if err != nil {
+			return fmt.Errorf("failed to create media policy: %w", err)
+		}
+		a.telegram.SetMedia(mediaPolicy)
+		a.telegram.SetImageOptions(media.ImageOptions{
+			MaxWidth:  a.config.Media.Images.MaxWidth,
+			MaxHeight: a.config.Media.Images.MaxHeight,
+			MaxSize:   int64(a.config.Media.Images.MaxSizeMB) << 20,
+			Quality:   a.config.Media.Images.JPEGQuality,
+		})
+		if a.config.Upload.Enabled {
+			a.telegram.SetUploads(upload.NewStore(upload.Config{
+				Workspace:    ws.Path(),
+				AllowedDirs:  a.config.Tools.File.WhitelistDirs,
+				ReadOnlyDirs: a.config.Tools.File.ReadOnlyDirs,
+				Dir:          a.config.Upload.Dir,
+				MaxSize:      int64(a.config.Upload.MaxSizeMB) << 20,
+			}))
+			a.logger.Info("Uploads enabled",
+				logger.Field{Key: "dir", Value: a.config.Upload.Dir},
+				logger.Field{Key: "max_size_mb", Value: a.config.Upload.MaxSizeMB})
+		}
+		if sttProvider != nil {
+			a.telegram.SetSpeechToText(sttProvider, a.config.STT.Language,
+				time.Duration(a.config.STT.MaxVoiceSeconds)*time.Second)
+			a.logger.Info("Voice messages enabled",
+				logger.Field{Key: "provider", Value: sttProvider.Name()},
+				logger.Field{Key: "max_voice_seconds", Value: a.config.STT.MaxVoiceSeconds})
+		}
+		if network.Configured() {
+			// No overall timeout: long polling requests are bounded by their context
+			a.telegram.SetHTTPClient(network.Client(0))
+		}
+		// With leader election the connector is started by the elected leader
+		if !a.config.Leader.Enabled {
+			if err := a.telegram.Start(a.ctx); err != nil {
+				return fmt.Errorf("failed to start telegram connector: %w", err)
+			}
 		}
 
 		// 8.1. Set secrets store on telegram command handler
@@ -299,9 +924,22 @@ func (a *App) Initialize(ctx context.Context) error {
 		// Register CronTool
 		cronAdapter := cron.NewCronSchedulerAdapter(a.cronScheduler, cronStorage)
 		cronTool := tools.NewCronTool(cronAdapter, a.logger)
+		cronTool.SetProfiles(userRegistry)
 		if err := a.agentLoop.RegisterTool(cronTool); err != nil {
 			return fmt.Errorf("failed to register cron tool: %w", err)
 		}
+
+		// Register schedule_followup tool (proactive check-ins the user opts into)
+		if a.config.Cron.Followups.Enabled {
+			followups := followup.NewManager(cronAdapter, userRegistry, followup.Config{
+				MaxPending: a.config.Cron.Followups.MaxPending,
+				MaxDelay:   time.Duration(a.config.Cron.Followups.MaxDelayHours) * time.Hour,
+			})
+			if err := a.agentLoop.RegisterTool(tools.NewScheduleFollowupTool(followups, a.logger)); err != nil {
+				return fmt.Errorf("failed to register schedule_followup tool: %w", err)
+			}
+			a.commandHandler.SetFollowups(followups)
+		}
 	}
 
 	// 10. Initialize IPC handler
@@ -309,6 +947,9 @@ func (a *App) Initialize(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to create IPC handler: %w", err)
 	}
+	if generator != nil {
+		a.ipcHandler.SetStructured(generator)
+	}
 
 	// Write PID file
 	if err := ipc.WritePID(ws.Path(), os.Getpid()); err != nil {
@@ -321,10 +962,390 @@ func (a *App) Initialize(ctx context.Context) error {
 		return fmt.Errorf("failed to start IPC server: %w", err)
 	}
 
-	// 12. Mark as started
+	// 11. Initialize cleanup scheduler (session retention janitor) if enabled
+	if a.config.Cleanup.Enabled {
+		runner := cleanup.NewRunner(cleanup.ConfigFromSettings(a.config.Cleanup))
+		a.cleanupScheduler = cleanup.NewScheduler(runner, cleanup.SchedulerConfig{
+			Enabled:         true,
+			IntervalMinutes: a.config.Cleanup.IntervalMinutes,
+		}, ws.Path(), a.logger)
+		if err := a.cleanupScheduler.Start(a.ctx); err != nil {
+			return fmt.Errorf("failed to start cleanup scheduler: %w", err)
+		}
+	}
+
+	// 12. Initialize file watcher if enabled
+	if a.config.Watcher.Enabled {
+		rules := make([]watcher.Rule, 0, len(a.config.Watcher.Watches))
+		for _, wc := range a.config.Watcher.Watches {
+			rules = append(rules, watcher.Rule{
+				ID:        wc.ID,
+				Path:      wc.Path,
+				Patterns:  wc.Patterns,
+				Match:     wc.Match,
+				SessionID: wc.SessionID,
+			})
+		}
+
+		a.watcher = watcher.New(watcher.Config{
+			Workspace:   ws.Path(),
+			Debounce:    time.Duration(a.config.Watcher.DebounceMs) * time.Millisecond,
+			MaxLines:    a.config.Watcher.MaxLines,
+			AllowedDirs: a.config.Watcher.AllowedDirs,
+			Rules:       rules,
+			Storage:     watcher.NewStorage(ws.Path()),
+		}, a.messageBus, a.logger)
+		if err := a.watcher.Start(a.ctx); err != nil {
+			return fmt.Errorf("failed to start file watcher: %w", err)
+		}
+
+		watchTool := tools.NewWatchTool(a.watcher, a.logger)
+		if err := a.agentLoop.RegisterTool(watchTool); err != nil {
+			return fmt.Errorf("failed to register watch tool: %w", err)
+		}
+		a.logger.Info("Watch tool registered")
+	}
+
+	// 12.1. Initialize web page monitor if enabled
+	if a.config.Monitor.Enabled {
+		rules := make([]monitor.Rule, 0, len(a.config.Monitor.Monitors))
+		for _, mc := range a.config.Monitor.Monitors {
+			rules = append(rules, monitor.Rule{
+				ID:              mc.ID,
+				URL:             mc.URL,
+				Selector:        mc.Selector,
+				IntervalMinutes: mc.IntervalMinutes,
+				SessionID:       mc.SessionID,
+			})
+		}
+
+		// Pages are downloaded under the same SSRF policy and limits as web_fetch
+		fetchCfg := a.config.Tools.Fetch
+		policy := &netguard.Policy{
+			AllowPrivate:   fetchCfg.AllowPrivateNetworks,
+			AllowedDomains: fetchCfg.AllowedDomains,
+			DeniedDomains:  fetchCfg.DeniedDomains,
+			Network:        network,
+		}
+		a.monitor = monitor.New(monitor.Config{
+			DefaultInterval: time.Duration(a.config.Monitor.DefaultIntervalMinutes) * time.Minute,
+			MinInterval:     time.Duration(a.config.Monitor.MinIntervalMinutes) * time.Minute,
+			MaxDiffLines:    a.config.Monitor.MaxDiffLines,
+			MaxMonitors:     a.config.Monitor.MaxMonitors,
+			Rules:           rules,
+			Storage:         monitor.NewStorage(ws.Path()),
+			Fetcher: monitor.NewHTTPFetcher(policy, time.Duration(fetchCfg.TimeoutSeconds)*time.Second,
+				fetchCfg.UserAgent, fetchCfg.MaxResponseSize),
+		}, a.messageBus, a.logger)
+		if err := a.monitor.Start(a.ctx); err != nil {
+			return fmt.Errorf("failed to start web page monitor: %w", err)
+		}
+
+		monitorTool := tools.NewMonitorTool(a.monitor, a.logger)
+		if err := a.agentLoop.RegisterTool(monitorTool); err != nil {
+			return fmt.Errorf("failed to register monitor tool: %w", err)
+		}
+		a.logger.Info("Monitor tool registered")
+	}
+
+	// 13. Initialize background job queue if enabled
+	if a.config.Jobs.Enabled {
+		a.jobQueue = jobs.NewQueue(jobs.NewStore(ws.Subpath("jobs")), jobs.Config{
+			IdleAfter:    time.Duration(a.config.Jobs.IdleSeconds) * time.Second,
+			PollInterval: time.Duration(a.config.Jobs.PollSeconds) * time.Second,
+		}, a.runJob, a.notifyJob, a.logger)
+		if err := a.jobQueue.Start(a.ctx); err != nil {
+			return fmt.Errorf("failed to start job queue: %w", err)
+		}
+	}
+
+	// 14. Start web dashboard if enabled
+	if a.config.Dashboard.Enabled {
+		var jobStore *jobs.Store
+		if a.config.Jobs.Enabled {
+			jobStore = jobs.NewStore(ws.Subpath("jobs"))
+		}
+		if err := a.startDashboard(a.ctx, jobStore); err != nil {
+			return fmt.Errorf("failed to start dashboard: %w", err)
+		}
+	}
+
+	// 15. Start leader election if enabled
+	if a.config.Leader.Enabled {
+		if err := a.startLeaderElection(a.ctx); err != nil {
+			return fmt.Errorf("failed to start leader election: %w", err)
+		}
+	}
+
+	// 16. Mark as started
 	a.mu.Lock()
 	a.started = true
 	a.mu.Unlock()
 
 	return nil
 }
+
+// newModerator creates the outbound message moderator from configuration.
+func newModerator(cfg config.ModerationConfig, publisher moderation.Publisher, log *logger.Logger) (*moderation.Moderator, error) {
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+
+	var backends []moderation.Backend
+	for _, name := range cfg.Backends {
+		switch name {
+		case "keywords":
+			rules := make([]moderation.KeywordRule, len(cfg.Keywords))
+			for i, rule := range cfg.Keywords {
+				rules[i] = moderation.KeywordRule{Category: rule.Category, Pattern: rule.Pattern}
+			}
+			backend, err := moderation.NewKeywordBackend(rules)
+			if err != nil {
+				return nil, err
+			}
+			backends = append(backends, backend)
+		case "openai":
+			backends = append(backends, moderation.NewOpenAIBackend(cfg.OpenAI.APIKey, cfg.OpenAI.BaseURL, cfg.OpenAI.Model, timeout))
+		case "classifier":
+			backends = append(backends, moderation.NewClassifierBackend(cfg.Classifier.URL, timeout))
+		default:
+			return nil, fmt.Errorf("unknown moderation backend: %s", name)
+		}
+	}
+
+	return moderation.New(moderation.Config{
+		Backends:       backends,
+		Action:         cfg.Action,
+		AdminSessionID: cfg.AdminSessionID,
+		BlockMessage:   cfg.BlockMessage,
+		Logger:         log,
+	}, publisher), nil
+}
+
+// newDebater creates the debate mode; personas without a model use the agent model.
+func newDebater(cfg config.AgentConfig, provider llm.Provider) (*debate.Debater, error) {
+	var personas []debate.Persona
+	for _, p := range cfg.Debate.Personas {
+		personas = append(personas, debate.Persona{Name: p.Name, Role: p.Role, Prompt: p.Prompt, Model: p.Model})
+	}
+	return debate.New(provider, debate.Config{
+		Personas:     personas,
+		Model:        cfg.Model,
+		MaxTurns:     cfg.Debate.MaxTurns,
+		MaxTokens:    cfg.Debate.MaxTokens,
+		ReplyTokens:  cfg.MaxTokens,
+		Temperature:  cfg.Temperature,
+		Auto:         cfg.Debate.Auto,
+		AutoMinChars: cfg.Debate.AutoMinChars,
+		Keywords:     cfg.Debate.Keywords,
+	})
+}
+
+// newOnboarding creates the onboarding flow of new users from configuration.
+func newOnboarding(cfg config.OnboardingConfig, registry *users.Registry, publisher onboarding.Publisher, log *logger.Logger) *onboarding.Flow {
+	consents := make([]onboarding.Consent, 0, len(cfg.Consents))
+	for _, c := range cfg.Consents {
+		consents = append(consents, onboarding.Consent{Name: c.Name, Question: c.Question, Required: c.Required})
+	}
+	return onboarding.New(onboarding.Config{
+		Messages:    cfg.Messages,
+		Consents:    consents,
+		InviteCodes: cfg.InviteCodes,
+	}, registry, publisher, log)
+}
+
+// newPlanner creates the planning phase from configuration.
+func newPlanner(cfg config.PlanningConfig, provider llm.Provider) *planner.Planner {
+	return planner.New(provider, planner.Config{
+		MinChars:       cfg.MinChars,
+		MaxSteps:       cfg.MaxSteps,
+		StepIterations: cfg.StepIterations,
+	})
+}
+
+// newVerifier creates the answer verification from configuration.
+func newVerifier(cfg config.VerifyConfig, provider llm.Provider) (*verify.Verifier, error) {
+	return verify.New(provider, verify.Config{
+		Model:            cfg.Model,
+		Mode:             cfg.Mode,
+		MinChars:         cfg.MinChars,
+		MaxEvidenceChars: cfg.MaxEvidenceChars,
+		Always:           cfg.Always,
+	})
+}
+
+// newExperiment creates the prompt A/B test from configuration.
+func newExperiment(cfg config.ExperimentConfig) (*experiment.Experiment, error) {
+	variants := make([]experiment.Variant, len(cfg.Variants))
+	for i, v := range cfg.Variants {
+		variants[i] = experiment.Variant{
+			Name:     v.Name,
+			Weight:   v.Weight,
+			Prompt:   v.Prompt,
+			Sampling: v.Sampling.Sampling(v.Temperature),
+		}
+	}
+	return experiment.New(cfg.Name, variants)
+}
+
+// agentSampling returns the sampling parameters of the agent besides the
+// temperature; deterministic runs use the configured seed.
+func agentSampling(cfg config.AgentConfig) llm.Sampling {
+	sampling := cfg.Sampling.Sampling(0)
+	if cfg.Deterministic.Enabled {
+		sampling.Seed = cfg.Deterministic.Seed
+	}
+	return sampling
+}
+
+// newRecordingProvider wraps provider to record its responses to dir, or
+// replaces it with the responses recorded there. System prompts are left out
+// of request keys since they contain the current time.
+func newRecordingProvider(mode string, provider llm.Provider, dir string) llm.Provider {
+	if mode == "replay" {
+		return llm.NewCassetteProvider(llm.MockModeReplay, provider, dir, true)
+	}
+	return llm.NewCassetteProvider(llm.MockModeRecord, provider, dir, true)
+}
+
+// newStructuredGenerator creates the structured output generator; the model
+// and token limit default to the agent settings.
+func newStructuredGenerator(cfg *config.Config, provider llm.Provider) *structured.Generator {
+	model := cfg.Structured.Model
+	if model == "" {
+		model = cfg.Agent.Model
+	}
+	maxTokens := cfg.Structured.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = cfg.Agent.MaxTokens
+	}
+	return structured.New(provider, structured.Config{
+		Model:      model,
+		MaxRetries: cfg.Structured.MaxRetries,
+		MaxTokens:  maxTokens,
+	})
+}
+
+// newSTTProvider creates the speech-to-text provider from configuration.
+func newSTTProvider(cfg config.STTConfig) (stt.Provider, error) {
+	switch cfg.Provider {
+	case "whisper_api":
+		return stt.NewWhisperAPIProvider(cfg.WhisperAPI.APIKey, cfg.WhisperAPI.BaseURL, cfg.WhisperAPI.Model,
+			time.Duration(cfg.TimeoutSeconds)*time.Second), nil
+	case "whisper_cpp":
+		return stt.NewWhisperCppProvider(cfg.WhisperCpp.Binary, cfg.WhisperCpp.Model, cfg.WhisperCpp.FFmpeg, cfg.WhisperCpp.Threads), nil
+	default:
+		return nil, fmt.Errorf("unknown speech-to-text provider: %s", cfg.Provider)
+	}
+}
+
+// newLimiter creates the inbound message limiter from configuration.
+// Channel rules inherit unset (zero) values from the [throttle] section.
+func newLimiter(cfg config.ThrottleConfig) *throttle.Limiter {
+	base := config.ThrottleRuleConfig{
+		MessagesPerMinute: cfg.MessagesPerMinute,
+		MaxConcurrent:     cfg.MaxConcurrent,
+		MuteThreshold:     cfg.MuteThreshold,
+		MuteMinutes:       cfg.MuteMinutes,
+	}
+	toRule := func(r config.ThrottleRuleConfig) throttle.Rule {
+		inherit := func(value, fallback int) int {
+			if value == 0 {
+				return fallback
+			}
+			return value
+		}
+		return throttle.Rule{
+			MessagesPerMinute: inherit(r.MessagesPerMinute, base.MessagesPerMinute),
+			MaxConcurrent:     inherit(r.MaxConcurrent, base.MaxConcurrent),
+			MuteThreshold:     inherit(r.MuteThreshold, base.MuteThreshold),
+			MuteDuration:      time.Duration(inherit(r.MuteMinutes, base.MuteMinutes)) * time.Minute,
+		}
+	}
+
+	channels := make(map[string]throttle.Rule, len(cfg.Channels))
+	for channel, rule := range cfg.Channels {
+		channels[channel] = toRule(rule)
+	}
+	return throttle.New(throttle.Config{
+		Default:         toRule(base),
+		Channels:        channels,
+		SlowDownMessage: cfg.SlowDownMessage,
+		BusyMessage:     cfg.BusyMessage,
+		MuteMessage:     cfg.MuteMessage,
+	})
+}
+
+// newMediaPolicy creates the policy for files downloaded from users; the
+// quarantine directory is relative to the workspace. With ClamAV enabled an
+// unreachable clamd is only logged: files are then handled by fail_open.
+func (a *App) newMediaPolicy(workspace string) (*media.Policy, error) {
+	cfg := a.config.Media
+	quarantine := ""
+	if !cfg.DiscardExecutables {
+		quarantine = filepath.Join(workspace, cfg.QuarantineDir)
+	}
+
+	var scanner media.Scanner
+	if cfg.ClamAV.Enabled {
+		clamav, err := media.NewClamAV(cfg.ClamAV.Address, time.Duration(cfg.ClamAV.TimeoutSeconds)*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		if err := clamav.Ping(a.ctx); err != nil {
+			a.logger.Warn("clamd is not reachable",
+				logger.Field{Key: "address", Value: cfg.ClamAV.Address},
+				logger.Field{Key: "fail_open", Value: cfg.ClamAV.FailOpen},
+				logger.Field{Key: "error", Value: err.Error()})
+		}
+		scanner = clamav
+		a.logger.Info("Virus scanning enabled",
+			logger.Field{Key: "address", Value: cfg.ClamAV.Address},
+			logger.Field{Key: "action", Value: cfg.ClamAV.Action})
+	}
+
+	return media.New(media.Config{
+		MaxSizes: map[media.Kind]int64{
+			media.KindPhoto:    int64(cfg.PhotoMaxMB) << 20,
+			media.KindDocument: int64(cfg.DocumentMaxMB) << 20,
+			media.KindVoice:    int64(cfg.VoiceMaxMB) << 20,
+			media.KindAudio:    int64(cfg.AudioMaxMB) << 20,
+			media.KindVideo:    int64(cfg.VideoMaxMB) << 20,
+		},
+		AllowedTypes:  cfg.AllowedTypes,
+		QuarantineDir: quarantine,
+		Scanner:       scanner,
+		ScanAction:    cfg.ClamAV.Action,
+		ScanFailOpen:  cfg.ClamAV.FailOpen,
+		Logger:        a.logger,
+	}), nil
+}
+
+// newRouter creates the model router; escalated requests use the agent model.
+func newRouter(cfg config.AgentConfig) *routing.Router {
+	return routing.New(routing.Config{
+		CheapModel:             cfg.Routing.CheapModel,
+		StrongModel:            cfg.Model,
+		MaxCheapChars:          cfg.Routing.MaxCheapChars,
+		MaxCheapToolIterations: cfg.Routing.MaxCheapToolIterations,
+		ComplexTools:           cfg.Routing.ComplexTools,
+		Keywords:               cfg.Routing.Keywords,
+	})
+}
+
+// validateToolConfigs checks the [tools.*] tables of the registered tools
+// that declare a configuration schema. Tools sharing a table are checked once.
+func validateToolConfigs(cfg *config.Config, registry *tools.Registry) error {
+	var errs []error
+	checked := make(map[string]bool)
+	for _, tool := range registry.List() {
+		configurable, ok := tool.(tools.ToolConfig)
+		if !ok || checked[configurable.ConfigSection()] {
+			continue
+		}
+		section := configurable.ConfigSection()
+		checked[section] = true
+
+		table, _ := cfg.ToolTable(section)
+		errs = append(errs, tools.ValidateConfig(section, configurable.ConfigSchema(), table)...)
+	}
+	return errors.Join(errs...)
+}
@@ -4,8 +4,13 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/tools"
+	"github.com/aatumaykin/nexbot/internal/tools/plot"
 )
 
 func TestApp_Initialize_Success(t *testing.T) {
@@ -328,3 +333,38 @@ func TestApp_Initialize_StartMessageProcessing(t *testing.T) {
 	// Cleanup
 	_ = app.Shutdown()
 }
+
+func TestValidateToolConfigs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := `
+[tools.shell]
+enabled = true
+timeout_secs = 30
+
+[tools.plot]
+enabled = true
+dir = "charts"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("config.Load() error: %v", err)
+	}
+
+	registry := tools.NewRegistry()
+	for _, tool := range []tools.Tool{tools.NewShellExecTool(cfg, nil), plot.NewPlotTool("charts", nil)} {
+		if err := registry.Register(tool); err != nil {
+			t.Fatalf("Register() error: %v", err)
+		}
+	}
+
+	err = validateToolConfigs(cfg, registry)
+	if err == nil || !strings.Contains(err.Error(), `tools.shell: unknown key "timeout_secs"`) {
+		t.Errorf("validateToolConfigs() error = %v, want unknown key timeout_secs", err)
+	}
+	if err != nil && strings.Contains(err.Error(), "tools.plot") {
+		t.Errorf("validateToolConfigs() reported a valid section: %v", err)
+	}
+}
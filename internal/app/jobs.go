@@ -0,0 +1,62 @@
+// Package app provides background job processing for Nexbot.
+// This file implements the job runner and completion notifications.
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/digest"
+	"github.com/aatumaykin/nexbot/internal/jobs"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/messages"
+)
+
+// jobNotificationMaxChars limits the result in a job notification; the full
+// result is available via `nexbot job status`
+const jobNotificationMaxChars = 3500
+
+// runJob processes a background job through the agent loop.
+func (a *App) runJob(ctx context.Context, job *jobs.Job) (string, error) {
+	jobCtx, cancel := context.WithTimeout(ctx, time.Duration(a.config.Jobs.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	// A job in a chat session waits for the chat's turn in flight
+	unlock, err := a.agentLoop.GetSessionManager().Lock(jobCtx, job.SessionID)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	return a.agentLoop.Process(jobCtx, job.SessionID, job.Prompt)
+}
+
+// notifyJob sends the result of a finished job to the chat of its session.
+// Jobs running in their own session are only available via the CLI.
+func (a *App) notifyJob(ctx context.Context, job *jobs.Job) {
+	channel, chatID, ok := job.Chat()
+	if !ok {
+		return
+	}
+
+	content := messages.FormatJobNotification(job, jobNotificationMaxChars)
+	if a.queueDigest(ctx, job.SessionID, digest.SourceJobs, content) {
+		return
+	}
+
+	outboundMsg := bus.NewOutboundMessage(
+		bus.ChannelType(channel),
+		chatID,
+		job.SessionID,
+		content,
+		job.ID,
+		bus.FormatTypePlain,
+		nil,
+	)
+	if err := a.messageBus.PublishOutbound(*outboundMsg); err != nil {
+		a.logger.ErrorCtx(ctx, "Failed to publish job notification", err,
+			logger.Field{Key: "job_id", Value: job.ID},
+			logger.Field{Key: "session_id", Value: job.SessionID})
+	}
+}
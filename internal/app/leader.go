@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/leader"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// startLeaderElection campaigns for leadership in the background. The
+// Telegram connector polls only while this instance is the leader, so two
+// instances can run as an active/passive pair.
+func (a *App) startLeaderElection(ctx context.Context) error {
+	cfg := a.config.Leader
+	identity := cfg.Identity
+	if identity == "" {
+		identity = leader.DefaultIdentity()
+	}
+
+	lock, err := newLeaderLock(cfg, identity)
+	if err != nil {
+		return err
+	}
+	elector := leader.New(lock, leader.Config{
+		Identity:      identity,
+		RenewDeadline: time.Duration(cfg.RenewDeadlineSeconds) * time.Second,
+		RetryPeriod:   time.Duration(cfg.RetryPeriodSeconds) * time.Second,
+	}, a.logger)
+
+	done := make(chan struct{})
+	a.leaderDone = done
+	go func() {
+		defer close(done)
+		elector.Run(ctx, a.lead, a.stepDown)
+	}()
+
+	a.logger.Info("Leader election started",
+		logger.Field{Key: "backend", Value: cfg.Backend},
+		logger.Field{Key: "identity", Value: identity})
+	return nil
+}
+
+// newLeaderLock creates the lock of the configured backend.
+func newLeaderLock(cfg config.LeaderConfig, identity string) (leader.Lock, error) {
+	switch cfg.Backend {
+	case leader.BackendFile:
+		return leader.NewFileLock(cfg.File.Path, identity), nil
+	case leader.BackendKubernetes:
+		lock, err := leader.NewKubernetesLock(leader.KubernetesConfig{
+			Namespace:     cfg.Kubernetes.Namespace,
+			Name:          cfg.Kubernetes.LeaseName,
+			Identity:      identity,
+			LeaseDuration: time.Duration(cfg.LeaseDurationSeconds) * time.Second,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kubernetes lease lock: %w", err)
+		}
+		return lock, nil
+	default:
+		return nil, fmt.Errorf("unknown leader backend: %s", cfg.Backend)
+	}
+}
+
+// lead starts polling Telegram when this instance becomes the leader.
+func (a *App) lead(ctx context.Context) error {
+	a.leading.Store(true)
+	if a.telegram == nil {
+		return nil
+	}
+	if err := a.telegram.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start telegram connector: %w", err)
+	}
+	return nil
+}
+
+// stepDown stops polling Telegram when leadership is lost.
+func (a *App) stepDown() {
+	a.leading.Store(false)
+	if a.telegram == nil {
+		return
+	}
+	if err := a.telegram.Stop(); err != nil {
+		a.logger.Error("Failed to stop telegram connector", err)
+	}
+}
+
+// waitLeaderElection waits for the election started by startLeaderElection
+// to step down and release the lock after the application context is
+// cancelled, so the standby takes over without waiting for lease expiry.
+func (a *App) waitLeaderElection() {
+	if a.leaderDone == nil {
+		return
+	}
+	<-a.leaderDone
+	a.leaderDone = nil
+}
@@ -6,10 +6,13 @@ import (
 	"context"
 	"time"
 
+	"github.com/aatumaykin/nexbot/internal/agent/loop"
 	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/constants"
 	"github.com/aatumaykin/nexbot/internal/logger"
 	"github.com/aatumaykin/nexbot/internal/messages"
 	"github.com/aatumaykin/nexbot/internal/retry"
+	"github.com/aatumaykin/nexbot/internal/tools"
 )
 
 // StartMessageProcessing starts the message processing loop.
@@ -22,6 +25,8 @@ func (a *App) StartMessageProcessing(ctx context.Context) error {
 		return nil
 	}
 
+	a.startReceiptRecording(ctx)
+
 	// Start goroutine to process messages
 	go func() {
 		a.logger.Info("Message processing started")
@@ -47,10 +52,27 @@ func (a *App) StartMessageProcessing(ctx context.Context) error {
 // processMessage processes a single inbound message.
 // It handles commands, publishes events, and processes through the agent loop.
 func (a *App) processMessage(ctx context.Context, msg bus.InboundMessage) {
-	// Log message processing start
-	a.logger.InfoCtx(ctx, "Processing message",
-		logger.Field{Key: "user_id", Value: msg.UserID},
-		logger.Field{Key: "session_id", Value: msg.SessionID})
+	// Turns of one session run one at a time; a busy session queues the message
+	unlock, queued := a.lockSession(ctx, msg)
+	if queued {
+		return
+	}
+	defer unlock()
+
+	// Release the in-flight slot taken when the message was admitted
+	if a.limiter != nil {
+		defer a.limiter.Done(msg)
+	}
+
+	// Keep background jobs waiting while interactive messages are processed
+	if a.jobQueue != nil {
+		a.jobQueue.Begin()
+		defer a.jobQueue.End()
+	}
+
+	// Every log line of the request carries its correlation and session IDs
+	ctx = msg.LogContext(ctx)
+	a.logger.InfoCtx(ctx, "Processing message")
 
 	// Check if message contains a command in metadata
 	var cmd string
@@ -63,14 +85,12 @@ func (a *App) processMessage(ctx context.Context, msg bus.InboundMessage) {
 	// Handle command if present
 	if cmd != "" {
 		a.logger.InfoCtx(ctx, "Command received",
-			logger.Field{Key: "command", Value: cmd},
-			logger.Field{Key: "session_id", Value: msg.SessionID})
+			logger.Field{Key: "command", Value: cmd})
 
 		err := a.commandHandler.HandleCommand(ctx, cmd, msg)
 		if err != nil {
 			a.logger.ErrorCtx(ctx, "Failed to handle command", err,
-				logger.Field{Key: "command", Value: cmd},
-				logger.Field{Key: "session_id", Value: msg.SessionID})
+				logger.Field{Key: "command", Value: cmd})
 		}
 
 		// Return early for commands (don't process further)
@@ -78,10 +98,10 @@ func (a *App) processMessage(ctx context.Context, msg bus.InboundMessage) {
 	}
 
 	// Publish processing start event
+	started := time.Now()
 	startEvent := bus.NewProcessingStartEvent(msg.ChannelType, msg.UserID, msg.SessionID, nil)
 	if err := a.messageBus.PublishEvent(*startEvent); err != nil {
-		a.logger.ErrorCtx(ctx, "Failed to publish processing start event", err,
-			logger.Field{Key: "session_id", Value: msg.SessionID})
+		a.logger.ErrorCtx(ctx, "Failed to publish processing start event", err)
 	}
 
 	// Create context with timeout for agent processing
@@ -89,20 +109,56 @@ func (a *App) processMessage(ctx context.Context, msg bus.InboundMessage) {
 	agentCtx, cancel := context.WithTimeout(ctx,
 		time.Duration(cfg.Agent.TimeoutSeconds)*time.Second)
 
+	// Stream the answer into an in-place edited message, if enabled
+	agentCtx, answerMetadata := a.startStream(agentCtx, msg)
+
+	// Report progress of long tool executions
+	agentCtx, stopProgress := a.startProgress(agentCtx, msg)
+
+	// Collect files produced by tools, delivered after the answer
+	agentCtx, produced := collectArtifacts(agentCtx)
+
+	// Collect the diffs of changed files, appended to the answer
+	agentCtx, changes := collectChanges(agentCtx)
+
+	// Files changed in this turn are saved first, so /rollback can undo them
+	if a.snapshots != nil {
+		agentCtx = tools.WithSnapshotter(agentCtx, a.snapshots.Begin(msg.SessionID))
+	}
+
+	// Mutating tools report instead of executing in dry-run mode
+	if dryRun, _ := msg.Metadata["dry_run"].(bool); dryRun || cfg.Agent.DryRun {
+		agentCtx = tools.WithDryRun(agentCtx)
+	}
+
+	// Count tool calls in the daily statistics and report tool crashes
+	if a.analytics != nil || a.alerts != nil {
+		agentCtx = loop.WithToolCalls(agentCtx, a.recordToolCall)
+	}
+
 	// Retry logic for LLM calls
 	response, err := retry.DoWithRetry(agentCtx, func() (string, error) {
+		if debate, _ := msg.Metadata["debate"].(bool); debate {
+			return a.agentLoop.Debate(agentCtx, msg.SessionID, msg.Content)
+		}
 		return a.agentLoop.Process(agentCtx, msg.SessionID, msg.Content)
 	}, retry.Config{
 		MaxAttempts:    3,
 		InitialBackoff: 1 * time.Second,
 		MaxBackoff:     10 * time.Second,
 	})
+	stopProgress()
 	cancel()
 
 	// Handle error
-	if err != nil {
-		a.logger.ErrorCtx(ctx, "Failed to process message through agent (after retries)", err,
-			logger.Field{Key: "session_id", Value: msg.SessionID})
+	if err != nil && isSessionConflict(err) {
+		// The history changed under the turn: the answer is dropped, not recovered
+		a.logger.WarnCtx(ctx, "Session changed during the turn, answer dropped",
+			logger.Field{Key: "error", Value: err.Error()})
+		response = constants.MsgSessionConflict
+	} else if err != nil {
+		a.logger.ErrorCtx(ctx, "Failed to process message through agent (after retries)", err)
+		a.alertAgentError(ctx, msg, err)
 
 		// Add error to session so LLM can see it and try to find solution
 		if sessionErr := a.agentLoop.AddErrorToSession(ctx, msg.SessionID, err); sessionErr != nil {
@@ -119,17 +175,34 @@ func (a *App) processMessage(ctx context.Context, msg bus.InboundMessage) {
 		}
 	}
 
+	if a.analytics != nil {
+		a.analytics.RecordMessage(msg.UserID, time.Since(started), err != nil)
+	}
+
 	// Publish processing end event
 	endEvent := bus.NewProcessingEndEvent(msg.ChannelType, msg.UserID, msg.SessionID, nil)
 	if err := a.messageBus.PublishEvent(*endEvent); err != nil {
-		a.logger.ErrorCtx(ctx, "Failed to publish processing end event", err,
-			logger.Field{Key: "session_id", Value: msg.SessionID})
+		a.logger.ErrorCtx(ctx, "Failed to publish processing end event", err)
+	}
+
+	// Answers to cron jobs and watcher notifications wait for the digest, if enabled
+	if response != "" && a.queueDigest(ctx, msg.SessionID, digestSource(msg), messages.CleanContent(response)) {
+		response = ""
 	}
 
 	// Send response if non-empty
+	var codeArtifacts []producedArtifact
 	if response != "" {
-		correlationID := msg.SessionID // Use session ID as correlation ID
+		correlationID := msg.CorrelationID
+		if correlationID == "" {
+			correlationID = msg.SessionID
+		}
 		cleanedResponse := messages.CleanContent(response)
+		if cfg.Tools.File.DiffSummary {
+			cleanedResponse = appendChanges(cleanedResponse, changes(), cfg.Tools.File.DiffSummaryMaxLines)
+		}
+		// Long code blocks are sent as images with the answer's files
+		cleanedResponse, codeArtifacts = a.renderCodeImages(ctx, cleanedResponse)
 		outboundMsg := bus.NewOutboundMessage(
 			msg.ChannelType,
 			msg.UserID,
@@ -137,11 +210,16 @@ func (a *App) processMessage(ctx context.Context, msg bus.InboundMessage) {
 			cleanedResponse,
 			correlationID,
 			bus.FormatTypePlain,
-			nil,
+			answerMetadata,
 		)
 		if err := a.messageBus.PublishOutbound(*outboundMsg); err != nil {
-			a.logger.ErrorCtx(ctx, "Failed to publish outbound message", err,
-				logger.Field{Key: "session_id", Value: msg.SessionID})
+			a.logger.ErrorCtx(ctx, "Failed to publish outbound message", err)
 		}
 	}
+
+	// Send files produced by tools
+	a.sendArtifacts(ctx, msg, append(codeArtifacts, produced()...))
+
+	// Record the turn's workspace changes in git
+	a.commitWorkspace(ctx, msg)
 }
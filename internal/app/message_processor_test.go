@@ -462,3 +462,24 @@ func TestApp_processMessage_PublishesOutbound(t *testing.T) {
 	// Cleanup
 	_ = app.Shutdown()
 }
+
+func TestDigestSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]any
+		want     string
+	}{
+		{"user message", nil, ""},
+		{"cron agent task", map[string]any{"cron_job_id": "job-1", "tool": "agent"}, "cron"},
+		{"watcher notification", map[string]any{"source": "watcher", "watch_id": "w1"}, "watcher"},
+		{"other source", map[string]any{"source": "webhook"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := bus.InboundMessage{Metadata: tt.metadata}
+			if got := digestSource(msg); got != tt.want {
+				t.Errorf("digestSource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
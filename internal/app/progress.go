@@ -0,0 +1,50 @@
+// Package app provides tool progress reporting for Nexbot.
+// This file publishes progress of long tool executions as bus events.
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/loop"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/tools"
+)
+
+// progressInterval is the minimum interval between progress events of a request
+const progressInterval = time.Second
+
+// startProgress publishes progress reports of the tools running for a message
+// as tool progress events. The returned function stops publishing: tools that
+// outlive their timeout must not report progress after processing has ended.
+func (a *App) startProgress(ctx context.Context, msg bus.InboundMessage) (context.Context, func()) {
+	var (
+		mu      sync.Mutex
+		last    time.Time
+		stopped bool
+	)
+
+	ctx = loop.WithProgress(ctx, func(tool string, p tools.Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		if stopped || time.Since(last) < progressInterval {
+			return
+		}
+		last = time.Now()
+
+		event := bus.NewToolProgressEvent(msg.ChannelType, msg.UserID, msg.SessionID, tool, p.Percent, p.Status)
+		if err := a.messageBus.PublishEvent(*event); err != nil {
+			a.logger.DebugCtx(ctx, "Failed to publish tool progress",
+				logger.Field{Key: "session_id", Value: msg.SessionID},
+				logger.Field{Key: "error", Value: err.Error()})
+		}
+	})
+
+	return ctx, func() {
+		mu.Lock()
+		stopped = true
+		mu.Unlock()
+	}
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// startReceiptRecording records the results of sent messages in the metadata
+// of their sessions, so the agent can check them with get_send_status.
+func (a *App) startReceiptRecording(ctx context.Context) {
+	if a.agentLoop == nil {
+		return
+	}
+	resultCh := a.messageBus.SubscribeSendResults(ctx)
+	if resultCh == nil {
+		return
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case result, ok := <-resultCh:
+				if !ok {
+					return
+				}
+				a.recordReceipt(ctx, result)
+			}
+		}
+	}()
+}
+
+// recordReceipt stores a send result in the metadata of its session. Results
+// of sessions that don't exist (yet) are dropped.
+func (a *App) recordReceipt(ctx context.Context, result bus.MessageSendResult) {
+	if result.SessionID == "" {
+		return
+	}
+	sess, err := a.agentLoop.GetSessionManager().Get(result.SessionID)
+	if err != nil {
+		return
+	}
+	if err := sess.AddReceipt(receiptFromResult(result)); err != nil {
+		a.logger.WarnCtx(ctx, "failed to record delivery receipt",
+			logger.Field{Key: "session_id", Value: result.SessionID},
+			logger.Field{Key: "correlation_id", Value: result.CorrelationID},
+			logger.Field{Key: "error", Value: err.Error()})
+	}
+}
+
+// receiptFromResult converts a send result to a session receipt.
+func receiptFromResult(result bus.MessageSendResult) session.Receipt {
+	r := session.Receipt{
+		CorrelationID: result.CorrelationID,
+		MessageID:     result.MessageID,
+		Type:          string(result.Type),
+		Success:       result.Success,
+		Duplicate:     result.Duplicate,
+		Time:          result.Timestamp,
+	}
+	if result.Error != nil {
+		r.Error = result.Error.Error()
+	}
+	return r
+}
@@ -0,0 +1,73 @@
+// Package app provides per-session turn locking for Nexbot.
+// This file keeps turns of one session from interleaving their history writes.
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/constants"
+)
+
+// sessionLockedKey marks the context of a message that already holds the
+// turn lock of its session.
+type sessionLockedKey struct{}
+
+// lockSession takes the turn lock of the session of a message. If another
+// turn of the session (e.g. a background job) is in flight, the user is told
+// the message is queued, the message is processed again once the turn ends
+// and queued is true. Commands don't take the lock.
+func (a *App) lockSession(ctx context.Context, msg bus.InboundMessage) (unlock func(), queued bool) {
+	if _, isCommand := msg.Metadata["command"].(string); isCommand || a.agentLoop == nil || ctx.Value(sessionLockedKey{}) != nil {
+		return func() {}, false
+	}
+
+	sessions := a.agentLoop.GetSessionManager()
+	unlock, err := sessions.TryLock(msg.SessionID)
+	if err == nil {
+		return unlock, false
+	}
+
+	a.logger.InfoCtx(msg.LogContext(ctx), "Session is busy, message queued")
+	if msg.UserID != "" {
+		a.replySession(ctx, msg, constants.MsgSessionBusy)
+	}
+
+	go func() {
+		unlock, err := sessions.Lock(ctx, msg.SessionID)
+		if err != nil {
+			// Shutting down: the message is dropped with its in-flight slot
+			if a.limiter != nil {
+				a.limiter.Done(msg)
+			}
+			return
+		}
+		defer unlock()
+		a.processMessage(context.WithValue(ctx, sessionLockedKey{}, true), msg)
+	}()
+	return nil, true
+}
+
+// isSessionConflict reports whether a turn failed because its session
+// history was changed meanwhile.
+func isSessionConflict(err error) bool {
+	return errors.Is(err, session.ErrConflict)
+}
+
+// replySession sends a plain notice to the chat of a message.
+func (a *App) replySession(ctx context.Context, msg bus.InboundMessage, content string) {
+	outboundMsg := bus.NewOutboundMessage(
+		msg.ChannelType,
+		msg.UserID,
+		msg.SessionID,
+		content,
+		msg.CorrelationID,
+		bus.FormatTypePlain,
+		nil,
+	)
+	if err := a.messageBus.PublishOutbound(*outboundMsg); err != nil {
+		a.logger.ErrorCtx(ctx, "Failed to publish session notice", err)
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/constants"
+)
+
+func TestApp_processMessage_BusySession(t *testing.T) {
+	app := New(createTestConfig(t), createTestLogger(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := app.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	defer func() { _ = app.Shutdown() }()
+
+	outboundCh := app.messageBus.SubscribeOutbound(ctx)
+	if outboundCh == nil {
+		t.Fatal("SubscribeOutbound() returned nil")
+	}
+
+	// A background job holds the session
+	sessions := app.agentLoop.GetSessionManager()
+	unlock, err := sessions.TryLock("telegram:42")
+	if err != nil {
+		t.Fatalf("TryLock() error = %v", err)
+	}
+
+	msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "42", "telegram:42", "hello", nil)
+	app.processMessage(ctx, *msg)
+
+	select {
+	case out := <-outboundCh:
+		if out.Content != constants.MsgSessionBusy || out.UserID != "42" {
+			t.Errorf("outbound = %q to %q, want the busy notice", out.Content, out.UserID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a busy notice")
+	}
+
+	// The queued message takes the session once the job ends
+	unlock()
+	deadline := time.After(10 * time.Second)
+	for {
+		select {
+		case <-outboundCh:
+			return // Answered
+		case <-deadline:
+			t.Fatal("Expected the queued message to be processed")
+		case <-time.After(time.Millisecond):
+		}
+		unlock, err := sessions.TryLock("telegram:42")
+		if err != nil {
+			return // Being processed
+		}
+		unlock()
+	}
+}
@@ -13,9 +13,10 @@ import (
 // Shutdown performs graceful shutdown of all components.
 // It stops the application in the following order:
 //  1. Cancels the application context
-//  2. Stops the Telegram connector (if running)
-//  3. Stops the cron scheduler (if running)
-//  4. Stops the message bus
+//  2. Releases leadership (if leader election is enabled)
+//  3. Stops the Telegram connector (if running)
+//  4. Stops the cron scheduler (if running)
+//  5. Stops the message bus
 //
 // The method is thread-safe and can be called from multiple goroutines.
 func (a *App) Shutdown() error {
@@ -23,6 +24,9 @@ func (a *App) Shutdown() error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	if a.started {
+		a.notifyStopping()
+	}
 	return a.shutdownInternal()
 }
 
@@ -33,6 +37,7 @@ func (a *App) Shutdown() error {
 //  3. Creates a new context
 //  4. Reinitializes all components via Initialize()
 //  5. Restarts message processing via StartMessageProcessing()
+//  6. Notifies systemd and restarts the watchdog
 //
 // This method is thread-safe and can be called from any goroutine.
 // Only one restart can be in progress at a time.
@@ -61,6 +66,7 @@ func (a *App) Restart() error {
 		return fmt.Errorf("failed to restart message processing: %w", err)
 	}
 
+	a.notifyReady(a.ctx)
 	a.logger.Info("Application restarted successfully")
 	return nil
 }
@@ -76,6 +82,9 @@ func (a *App) shutdownInternal() error {
 	// Cancel context to stop all background operations
 	a.cancel()
 
+	// Step down and release the leader lock before stopping the components
+	a.waitLeaderElection()
+
 	// Cleanup IPC
 	if a.ipcHandler != nil {
 		if err := a.ipcHandler.Stop(); err != nil {
@@ -83,11 +92,29 @@ func (a *App) shutdownInternal() error {
 		}
 	}
 
+	// Stop web dashboard if not nil
+	if a.dashboard != nil {
+		if err := a.dashboard.Stop(); err != nil {
+			a.logger.Error("Failed to stop dashboard", err)
+		}
+		a.dashboard = nil
+	}
+
 	// Remove PID file and socket
 	if err := ipc.Cleanup(a.config.Workspace.Path); err != nil {
 		a.logger.Error("failed to cleanup IPC files", err)
 	}
 
+	// Deliver pending digests while the connectors still run
+	if a.digest != nil {
+		a.digest.Stop()
+	}
+
+	// Save the statistics of the current day
+	if a.analytics != nil {
+		a.analytics.Stop()
+	}
+
 	// Stop telegram connector if not nil
 	if a.telegram != nil {
 		if err := a.telegram.Stop(); err != nil {
@@ -102,6 +129,41 @@ func (a *App) shutdownInternal() error {
 		}
 	}
 
+	// Stop background processes if not nil
+	if a.processManager != nil {
+		a.processManager.StopAll()
+	}
+
+	// Stop file watcher if not nil
+	if a.watcher != nil {
+		_ = a.watcher.Stop()
+	}
+
+	// Stop web page monitor if not nil
+	if a.monitor != nil {
+		_ = a.monitor.Stop()
+	}
+
+	// Stop job queue if not nil (an interrupted job is queued again)
+	if a.jobQueue != nil {
+		a.jobQueue.Stop()
+	}
+
+	// Stop cleanup scheduler if not nil
+	if a.cleanupScheduler != nil {
+		a.cleanupScheduler.Stop()
+	}
+
+	// Stop export scheduler if not nil
+	if a.exportScheduler != nil {
+		a.exportScheduler.Stop()
+	}
+
+	// Stop update checker if not nil
+	if a.updateChecker != nil {
+		a.updateChecker.Stop()
+	}
+
 	// Stop worker pool if not nil
 	if a.workerPool != nil {
 		a.workerPool.Stop()
@@ -0,0 +1,42 @@
+// Package app provides answer streaming for Nexbot.
+// This file forwards partial answers of the agent to the channel while they are generated.
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/loop"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/google/uuid"
+)
+
+// startStream enables answer streaming for a message, if the channel supports it.
+// Returns the context for the agent and the metadata of the final answer, which
+// links it to the streamed draft. Streaming is disabled with outbound moderation
+// and answer verification, since partial answers can't be checked.
+func (a *App) startStream(ctx context.Context, msg bus.InboundMessage) (context.Context, map[string]any) {
+	cfg := a.config.Channels.Telegram
+	if !cfg.StreamAnswers || msg.ChannelType != bus.ChannelTypeTelegram || a.config.Moderation.Enabled || a.config.Agent.Verify.Enabled || a.digested(msg) {
+		return ctx, nil
+	}
+
+	streamID := uuid.NewString()
+	interval := time.Duration(cfg.StreamIntervalMS) * time.Millisecond
+	var last time.Time
+	ctx = loop.WithStream(ctx, func(text string) {
+		// Keep edits within Telegram rate limits
+		if time.Since(last) < interval {
+			return
+		}
+		last = time.Now()
+		streamMsg := bus.NewStreamMessage(msg.ChannelType, msg.UserID, msg.SessionID, streamID, text)
+		if err := a.messageBus.PublishOutbound(*streamMsg); err != nil {
+			a.logger.DebugCtx(ctx, "Failed to publish partial answer",
+				logger.Field{Key: "session_id", Value: msg.SessionID},
+				logger.Field{Key: "error", Value: err.Error()})
+		}
+	})
+	return ctx, map[string]any{bus.MetadataStreamID: streamID}
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"context"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/systemd"
+)
+
+// notifyReady tells systemd that the application is running and, when the
+// unit sets WatchdogSec, starts the watchdog. Keep-alive pings are sent only
+// while the message bus and the Telegram connector are alive, so systemd
+// restarts a hung service. Without systemd this is a no-op.
+func (a *App) notifyReady(ctx context.Context) {
+	if _, err := systemd.Notify(systemd.StateReady); err != nil {
+		a.logger.Warn("Failed to notify systemd", logger.Field{Key: "error", Value: err.Error()})
+	}
+
+	timeout, err := systemd.WatchdogInterval()
+	if err != nil {
+		a.logger.Warn("Invalid systemd watchdog settings", logger.Field{Key: "error", Value: err.Error()})
+		return
+	}
+	if timeout == 0 {
+		return
+	}
+
+	watchdog := systemd.NewWatchdog(timeout, func(name string, err error) {
+		a.logger.Error("Watchdog check failed, keep-alive skipped", err, logger.Field{Key: "component", Value: name})
+	})
+	watchdog.AddCheck("bus", a.messageBus.CheckHealth)
+	if tg := a.telegram; tg != nil {
+		watchdog.AddCheck("telegram", func() error {
+			// A standby instance doesn't poll Telegram
+			if a.config.Leader.Enabled && !a.leading.Load() {
+				return nil
+			}
+			return tg.CheckHealth(timeout)
+		})
+	}
+	go watchdog.Run(ctx)
+
+	a.logger.Info("systemd watchdog enabled", logger.Field{Key: "timeout", Value: timeout.String()})
+}
+
+// notifyStopping tells systemd that the application is shutting down.
+func (a *App) notifyStopping() {
+	if _, err := systemd.Notify(systemd.StateStopping); err != nil {
+		a.logger.Warn("Failed to notify systemd", logger.Field{Key: "error", Value: err.Error()})
+	}
+}
@@ -0,0 +1,32 @@
+// Package app provides the message template setup for Nexbot.
+// This file converts the [templates] config section into a template registry.
+package app
+
+import (
+	"fmt"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/msgtemplate"
+)
+
+// MessageTemplates parses the message templates from config.
+// Returns nil when no templates are configured.
+func MessageTemplates(cfgTemplates map[string]config.MessageTemplateConfig) (*msgtemplate.Registry, error) {
+	if len(cfgTemplates) == 0 {
+		return nil, nil
+	}
+	defs := make(map[string]msgtemplate.Definition, len(cfgTemplates))
+	for name, tc := range cfgTemplates {
+		defs[name] = msgtemplate.Definition{
+			Text:        tc.Text,
+			Format:      tc.Format,
+			Description: tc.Description,
+			Optional:    tc.Optional,
+		}
+	}
+	registry, err := msgtemplate.NewRegistry(defs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message templates: %w", err)
+	}
+	return registry, nil
+}
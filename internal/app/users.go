@@ -0,0 +1,35 @@
+// Package app provides the user registry setup for Nexbot.
+// This file converts the [[users]] config section into registry users.
+package app
+
+import (
+	"fmt"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/users"
+)
+
+// StaticUsers converts the users from config into registry users.
+func StaticUsers(cfgUsers []config.UserConfig) ([]users.User, error) {
+	staticUsers := make([]users.User, 0, len(cfgUsers))
+	for _, uc := range cfgUsers {
+		u := users.User{
+			ID:              uc.ID,
+			Name:            uc.Name,
+			DefaultChannels: uc.DefaultChannels,
+			Language:        uc.Language,
+			Timezone:        uc.Timezone,
+			Role:            uc.Role,
+			Preferences:     uc.Preferences,
+		}
+		for _, s := range uc.Identities {
+			identity, err := users.ParseIdentity(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid identity for user %s: %w", uc.ID, err)
+			}
+			u.Identities = append(u.Identities, identity)
+		}
+		staticUsers = append(staticUsers, u)
+	}
+	return staticUsers, nil
+}
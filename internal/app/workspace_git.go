@@ -0,0 +1,40 @@
+// Package app provides workspace auto-commits for Nexbot.
+// This file commits the workspace changes of each agent turn to its git repository.
+package app
+
+import (
+	"context"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/gitws"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// commitWorkspace commits the changes the turn of msg made to the workspace.
+// Failures are logged: the answer is delivered anyway.
+func (a *App) commitWorkspace(ctx context.Context, msg bus.InboundMessage) {
+	if a.workspaceRepo == nil {
+		return
+	}
+
+	correlationID := msg.CorrelationID
+	if correlationID == "" {
+		correlationID = msg.SessionID
+	}
+	hash, committed, err := a.workspaceRepo.Commit(context.WithoutCancel(ctx), gitws.Turn{
+		CorrelationID: correlationID,
+		SessionID:     msg.SessionID,
+		Request:       msg.Content,
+	})
+	if err != nil {
+		a.logger.WarnCtx(ctx, "Failed to commit workspace changes",
+			logger.Field{Key: "error", Value: err},
+			logger.Field{Key: "session_id", Value: msg.SessionID})
+		return
+	}
+	if committed {
+		a.logger.InfoCtx(ctx, "Workspace changes committed",
+			logger.Field{Key: "commit", Value: hash},
+			logger.Field{Key: "session_id", Value: msg.SessionID})
+	}
+}
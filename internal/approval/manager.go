@@ -0,0 +1,249 @@
+// Package approval asks the user to approve destructive tool calls (deleting
+// or overwriting files, rm in the shell) before they run. The agent loop
+// holds such calls, the user gets a summary of the plan with Approve and
+// Reject buttons, and the calls run only once they are approved.
+package approval
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+)
+
+const (
+	// CallbackPrefix marks callback data of approval buttons.
+	// Callback data format: "approval:<token>:approve" or "approval:<token>:reject".
+	CallbackPrefix = "approval:"
+
+	// DefaultTTL is how long an unanswered request stays active
+	DefaultTTL = 30 * time.Minute
+
+	// maxArgsChars limits the arguments shown for a call
+	maxArgsChars = 300
+
+	approveValue = "approve"
+	rejectValue  = "reject"
+)
+
+var (
+	// ErrUnsupported is returned for sessions of channels that cannot route answers to the manager.
+	ErrUnsupported = errors.New("approvals are not supported in this channel")
+
+	// ErrActive is returned when the session already has a request waiting for an answer.
+	ErrActive = errors.New("another approval request is waiting for an answer")
+)
+
+// Publisher sends requests to the user.
+type Publisher interface {
+	PublishOutbound(msg bus.OutboundMessage) error
+}
+
+// CompleteFunc receives an answered request. It runs in its own goroutine.
+type CompleteFunc func(ctx context.Context, req *Request, approved bool)
+
+// Config configures a Manager.
+type Config struct {
+	Channels []string      // Channels whose connector routes button presses to the manager
+	TTL      time.Duration // How long an unanswered request stays active (DefaultTTL if 0)
+}
+
+// Call is a held tool call.
+type Call struct {
+	ID        string
+	Tool      string
+	Arguments string
+}
+
+// Request is a set of tool calls waiting for the user's approval.
+type Request struct {
+	Token     string
+	SessionID string
+	Plan      string // Summary of the plan the calls belong to, may be empty
+	Calls     []Call
+
+	expiresAt time.Time
+}
+
+// Manager keeps the pending request of each session. It is safe for concurrent use.
+type Manager struct {
+	mu        sync.Mutex
+	cfg       Config
+	requests  map[string]*Request // By session ID
+	publisher Publisher
+	complete  CompleteFunc
+	now       func() time.Time
+}
+
+// NewManager creates a Manager.
+func NewManager(cfg Config, publisher Publisher, complete CompleteFunc) *Manager {
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultTTL
+	}
+	return &Manager{
+		cfg:       cfg,
+		requests:  make(map[string]*Request),
+		publisher: publisher,
+		complete:  complete,
+		now:       time.Now,
+	}
+}
+
+// Supports reports whether button presses from the session's channel reach the manager.
+func (m *Manager) Supports(sessionID string) bool {
+	channel, _, ok := strings.Cut(sessionID, ":")
+	return ok && slices.Contains(m.cfg.Channels, channel)
+}
+
+// Request sends the user a summary of the plan and the calls with Approve
+// and Reject buttons.
+func (m *Manager) Request(sessionID, plan string, calls []Call) (*Request, error) {
+	if !m.Supports(sessionID) {
+		return nil, ErrUnsupported
+	}
+	if len(calls) == 0 {
+		return nil, fmt.Errorf("approval request has no calls")
+	}
+
+	m.mu.Lock()
+	if m.activeLocked(sessionID) != nil {
+		m.mu.Unlock()
+		return nil, ErrActive
+	}
+	req := &Request{
+		Token:     newToken(),
+		SessionID: sessionID,
+		Plan:      strings.TrimSpace(plan),
+		Calls:     calls,
+		expiresAt: m.now().Add(m.cfg.TTL),
+	}
+	m.requests[sessionID] = req
+	m.mu.Unlock()
+
+	keyboard := &bus.InlineKeyboard{Rows: [][]bus.InlineButton{{
+		{Text: "✅ Approve", Data: CallbackData(req.Token, approveValue)},
+		{Text: "✖ Reject", Data: CallbackData(req.Token, rejectValue)},
+	}}}
+	if err := m.send(req, Summary(req), keyboard); err != nil {
+		m.mu.Lock()
+		delete(m.requests, sessionID)
+		m.mu.Unlock()
+		return nil, err
+	}
+	return req, nil
+}
+
+// Pending reports whether the session has an unanswered, unexpired request.
+func (m *Manager) Pending(sessionID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.activeLocked(sessionID) != nil
+}
+
+// AnswerCallback handles an Approve or Reject button. Returns false if data
+// is not approval callback data; buttons of answered or expired requests
+// are ignored.
+func (m *Manager) AnswerCallback(ctx context.Context, sessionID, data string) bool {
+	token, value, ok := ParseCallback(data)
+	if !ok {
+		return false
+	}
+
+	m.mu.Lock()
+	req := m.activeLocked(sessionID)
+	if req == nil || req.Token != token || (value != approveValue && value != rejectValue) {
+		m.mu.Unlock()
+		return true
+	}
+	delete(m.requests, sessionID)
+	m.mu.Unlock()
+
+	approved := value == approveValue
+	if approved {
+		_ = m.send(req, "✅ Approved. Running the actions…", nil)
+	} else {
+		_ = m.send(req, "❌ Rejected. The actions will not run.", nil)
+	}
+	if m.complete != nil {
+		go m.complete(context.WithoutCancel(ctx), req, approved)
+	}
+	return true
+}
+
+// activeLocked returns the unexpired request of a session. Must be called with mu held.
+func (m *Manager) activeLocked(sessionID string) *Request {
+	req, ok := m.requests[sessionID]
+	if !ok {
+		return nil
+	}
+	if !m.now().Before(req.expiresAt) {
+		delete(m.requests, sessionID)
+		return nil
+	}
+	return req
+}
+
+// send publishes a message to the chat of the request.
+func (m *Manager) send(req *Request, content string, keyboard *bus.InlineKeyboard) error {
+	channel, chatID, _ := strings.Cut(req.SessionID, ":")
+	msg := bus.NewOutboundMessageWithKeyboard(bus.ChannelType(channel), chatID, req.SessionID, content,
+		req.Token, keyboard, bus.FormatTypePlain, map[string]any{"approval_token": req.Token})
+	if err := m.publisher.PublishOutbound(*msg); err != nil {
+		return fmt.Errorf("failed to send approval request: %w", err)
+	}
+	return nil
+}
+
+// Summary formats a request for the user: the plan, then each call.
+func Summary(req *Request) string {
+	var b strings.Builder
+	b.WriteString("⚠️ The agent wants to run actions that delete or overwrite data.\n\n")
+	if req.Plan != "" {
+		b.WriteString("Plan:\n" + req.Plan + "\n\n")
+	}
+	b.WriteString("Actions:\n")
+	for i, call := range req.Calls {
+		fmt.Fprintf(&b, "%d. %s %s\n", i+1, call.Tool, shorten(call.Arguments))
+	}
+	b.WriteString("\nRun them?")
+	return b.String()
+}
+
+// shorten limits call arguments to maxArgsChars runes on one line.
+func shorten(args string) string {
+	args = strings.Join(strings.Fields(args), " ")
+	if utf8.RuneCountInString(args) <= maxArgsChars {
+		return args
+	}
+	return string([]rune(args)[:maxArgsChars-1]) + "…"
+}
+
+// CallbackData builds callback data for an approval button.
+func CallbackData(token, value string) string {
+	return CallbackPrefix + token + ":" + value
+}
+
+// ParseCallback extracts the request token and button value from callback data.
+func ParseCallback(data string) (token, value string, ok bool) {
+	rest, found := strings.CutPrefix(data, CallbackPrefix)
+	if !found {
+		return "", "", false
+	}
+	token, value, ok = strings.Cut(rest, ":")
+	return token, value, ok && token != "" && value != ""
+}
+
+// newToken returns a random request token.
+func newToken() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
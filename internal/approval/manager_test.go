@@ -0,0 +1,134 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+)
+
+// testPublisher records published messages.
+type testPublisher struct {
+	mu       sync.Mutex
+	messages []bus.OutboundMessage
+}
+
+func (p *testPublisher) PublishOutbound(msg bus.OutboundMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *testPublisher) last(t *testing.T) bus.OutboundMessage {
+	t.Helper()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.messages) == 0 {
+		t.Fatal("Expected a published message")
+	}
+	return p.messages[len(p.messages)-1]
+}
+
+// answer is a completed request
+type answer struct {
+	req      *Request
+	approved bool
+}
+
+func newTestManager() (*Manager, *testPublisher, chan answer) {
+	publisher := &testPublisher{}
+	answers := make(chan answer, 1)
+	m := NewManager(Config{Channels: []string{"telegram"}}, publisher, func(ctx context.Context, req *Request, approved bool) {
+		answers <- answer{req: req, approved: approved}
+	})
+	return m, publisher, answers
+}
+
+var testCalls = []Call{
+	{ID: "call-1", Tool: "delete_file", Arguments: `{"path": "old.log"}`},
+	{ID: "call-2", Tool: "shell_exec", Arguments: `{"command": "rm -r build"}`},
+}
+
+func TestManager_Approve(t *testing.T) {
+	m, publisher, answers := newTestManager()
+	ctx := context.Background()
+
+	req, err := m.Request("telegram:1", "[>] 1. Clean up the build", testCalls)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	msg := publisher.last(t)
+	if msg.UserID != "1" || msg.InlineKeyboard == nil || len(msg.InlineKeyboard.Rows[0]) != 2 {
+		t.Fatalf("Unexpected request message: %+v", msg)
+	}
+	for _, want := range []string{"Clean up the build", `1. delete_file {"path": "old.log"}`, "2. shell_exec"} {
+		if !strings.Contains(msg.Content, want) {
+			t.Errorf("Request message %q does not contain %q", msg.Content, want)
+		}
+	}
+
+	// A second request waits for the first one
+	if _, err := m.Request("telegram:1", "", testCalls); !errors.Is(err, ErrActive) {
+		t.Errorf("Request() while pending error = %v, want ErrActive", err)
+	}
+
+	// Buttons of other requests are ignored
+	if !m.AnswerCallback(ctx, "telegram:1", CallbackData("other", approveValue)) || !m.Pending("telegram:1") {
+		t.Error("A button of another request should be consumed and ignored")
+	}
+
+	if !m.AnswerCallback(ctx, "telegram:1", msg.InlineKeyboard.Rows[0][0].Data) {
+		t.Fatal("AnswerCallback() = false for the Approve button")
+	}
+	select {
+	case got := <-answers:
+		if !got.approved || got.req.Token != req.Token || len(got.req.Calls) != 2 {
+			t.Errorf("Unexpected answer: %+v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Request was not completed")
+	}
+	if m.Pending("telegram:1") {
+		t.Error("Answered request should not be pending")
+	}
+}
+
+func TestManager_RejectAndExpire(t *testing.T) {
+	m, publisher, answers := newTestManager()
+	ctx := context.Background()
+
+	if _, err := m.Request("telegram:1", "", testCalls[:1]); err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	m.AnswerCallback(ctx, "telegram:1", publisher.last(t).InlineKeyboard.Rows[0][1].Data)
+	if got := <-answers; got.approved {
+		t.Error("Reject button approved the request")
+	}
+
+	// Expired requests are not answered
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	if _, err := m.Request("telegram:1", "", testCalls[:1]); err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	data := publisher.last(t).InlineKeyboard.Rows[0][0].Data
+	m.now = func() time.Time { return now.Add(DefaultTTL) }
+	m.AnswerCallback(ctx, "telegram:1", data)
+	select {
+	case got := <-answers:
+		t.Errorf("Expired request was answered: %+v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if m.AnswerCallback(ctx, "telegram:1", "form:abc:0") {
+		t.Error("AnswerCallback() should not consume other callback data")
+	}
+	if _, err := m.Request("cron:1", "", testCalls); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Request() for cron error = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,238 @@
+// Package artifacts keeps the files produced by tools for the session that
+// produced them, so the agent can refer to them later ("send me that CSV").
+// Files are copied to <workspace>/artifacts/<session>/ and live as long as
+// the session: they are removed when the session is cleared or cleaned up.
+package artifacts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Subdirectory is the workspace subdirectory holding artifacts
+	Subdirectory = "artifacts"
+
+	// indexFilename is the per-session artifact index
+	indexFilename = "index.json"
+)
+
+var (
+	// ErrNotFound is returned when no artifact matches a reference.
+	ErrNotFound = errors.New("artifact not found")
+
+	// ErrInvalidSession is returned for session IDs that are not usable as a directory name.
+	ErrInvalidSession = errors.New("invalid session ID")
+)
+
+// Entry describes a stored artifact.
+type Entry struct {
+	ID        string    `json:"id"`                // Sequential ID within the session
+	Name      string    `json:"name"`              // File name shown to the user
+	Path      string    `json:"path"`              // Absolute path of the stored copy
+	MimeType  string    `json:"mime_type"`         // Content type of the file
+	Size      int64     `json:"size"`              // Size in bytes
+	Tool      string    `json:"tool,omitempty"`    // Tool that produced the file
+	Caption   string    `json:"caption,omitempty"` // Optional description
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Store keeps artifacts per session in <workspace>/artifacts/<session>/.
+type Store struct {
+	mu   sync.Mutex
+	root string
+}
+
+// NewStore creates an artifact store in the workspace.
+func NewStore(workspacePath string) *Store {
+	return &Store{root: filepath.Join(workspacePath, Subdirectory)}
+}
+
+// SessionDir returns the artifact directory of a session.
+func (s *Store) SessionDir(sessionID string) (string, error) {
+	if sessionID == "" || sessionID == "." || sessionID == ".." || strings.ContainsAny(sessionID, `/\`) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidSession, sessionID)
+	}
+	return filepath.Join(s.root, sessionID), nil
+}
+
+// Register copies the file at src into the session and records it. Name,
+// MimeType, Tool and Caption are taken from meta; Name defaults to the base
+// name of src. A file that is already stored in the session is not copied
+// again and its existing entry is returned.
+func (s *Store) Register(sessionID, src string, meta Entry) (Entry, error) {
+	dir, err := s.SessionDir(sessionID)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := readIndex(dir)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	src = filepath.Clean(src)
+	for _, entry := range entries {
+		if entry.Path == src {
+			return entry, nil
+		}
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to access artifact: %w", err)
+	}
+	if info.IsDir() {
+		return Entry{}, fmt.Errorf("artifact is a directory: %s", src)
+	}
+
+	entry := meta
+	entry.ID = nextID(entries)
+	if entry.Name == "" {
+		entry.Name = filepath.Base(src)
+	}
+	entry.Name = filepath.Base(filepath.Clean("/" + entry.Name))
+	entry.Path = filepath.Join(dir, entry.ID, entry.Name)
+	entry.Size = info.Size()
+	entry.CreatedAt = time.Now()
+
+	if err := copyFile(src, entry.Path); err != nil {
+		return Entry{}, err
+	}
+
+	if err := writeIndex(dir, append(entries, entry)); err != nil {
+		_ = os.RemoveAll(filepath.Dir(entry.Path))
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// List returns the artifacts of a session, oldest first.
+func (s *Store) List(sessionID string) ([]Entry, error) {
+	dir, err := s.SessionDir(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return readIndex(dir)
+}
+
+// Find returns the artifact of a session with the given ID or, failing
+// that, the most recent one with the given file name (case-insensitive).
+func (s *Store) Find(sessionID, ref string) (Entry, error) {
+	entries, err := s.List(sessionID)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	ref = strings.TrimSpace(ref)
+	for _, entry := range entries {
+		if entry.ID == ref {
+			return entry, nil
+		}
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if strings.EqualFold(entries[i].Name, ref) {
+			return entries[i], nil
+		}
+	}
+	return Entry{}, fmt.Errorf("%w: %s", ErrNotFound, ref)
+}
+
+// DeleteSession removes all artifacts of a session.
+func (s *Store) DeleteSession(sessionID string) error {
+	dir, err := s.SessionDir(sessionID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to delete artifacts: %w", err)
+	}
+	return nil
+}
+
+// nextID returns the ID following the highest ID of entries.
+func nextID(entries []Entry) string {
+	highest := 0
+	for _, entry := range entries {
+		if id, err := strconv.Atoi(entry.ID); err == nil && id > highest {
+			highest = id
+		}
+	}
+	return strconv.Itoa(highest + 1)
+}
+
+func readIndex(dir string) ([]Entry, error) {
+	entries := []Entry{}
+	data, err := os.ReadFile(filepath.Join(dir, indexFilename))
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact index: %w", err)
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse artifact index: %w", err)
+	}
+	return entries, nil
+}
+
+func writeIndex(dir string, entries []Entry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal artifact index: %w", err)
+	}
+
+	// Write to temp file first, then rename for atomicity
+	path := filepath.Join(dir, indexFilename)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write artifact index: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to save artifact index: %w", err)
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open artifact: %w", err)
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create artifact copy: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to copy artifact: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to copy artifact: %w", err)
+	}
+	return nil
+}
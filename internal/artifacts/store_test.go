@@ -0,0 +1,112 @@
+package artifacts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSource creates a source file outside the store.
+func writeSource(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write source file: %v", err)
+	}
+	return path
+}
+
+func TestStore_Register(t *testing.T) {
+	store := NewStore(t.TempDir())
+	src := writeSource(t, "report.csv", "a,b\n1,2\n")
+
+	entry, err := store.Register("telegram:1", src, Entry{MimeType: "text/csv", Tool: "write_file"})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if entry.ID != "1" || entry.Name != "report.csv" || entry.Tool != "write_file" || entry.Size != 8 {
+		t.Errorf("Register() = %+v", entry)
+	}
+	data, err := os.ReadFile(entry.Path)
+	if err != nil || string(data) != "a,b\n1,2\n" {
+		t.Errorf("stored copy = %q, %v", data, err)
+	}
+
+	// The stored copy survives changes of the source
+	if err := os.WriteFile(src, []byte("changed"), 0644); err != nil {
+		t.Fatalf("Failed to change source: %v", err)
+	}
+	data, _ = os.ReadFile(entry.Path)
+	if string(data) != "a,b\n1,2\n" {
+		t.Errorf("stored copy changed with the source: %q", data)
+	}
+
+	// Same name again gets a new ID and its own copy
+	second, err := store.Register("telegram:1", src, Entry{})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if second.ID != "2" || second.Path == entry.Path {
+		t.Errorf("second Register() = %+v", second)
+	}
+
+	// Registering a stored copy returns its entry
+	again, err := store.Register("telegram:1", entry.Path, Entry{})
+	if err != nil || again.ID != entry.ID {
+		t.Errorf("Register(stored copy) = %+v, %v", again, err)
+	}
+}
+
+func TestStore_Find(t *testing.T) {
+	store := NewStore(t.TempDir())
+	first, _ := store.Register("s", writeSource(t, "data.csv", "1"), Entry{})
+	second, _ := store.Register("s", writeSource(t, "data.csv", "2"), Entry{})
+
+	tests := []struct {
+		ref    string
+		wantID string
+	}{
+		{first.ID, first.ID},
+		{"DATA.csv", second.ID},
+	}
+	for _, tt := range tests {
+		entry, err := store.Find("s", tt.ref)
+		if err != nil || entry.ID != tt.wantID {
+			t.Errorf("Find(%q) = %+v, %v; want ID %s", tt.ref, entry, err, tt.wantID)
+		}
+	}
+
+	if _, err := store.Find("s", "missing.csv"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Find(missing) error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Find("other", first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Find(other session) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStore_DeleteSession(t *testing.T) {
+	store := NewStore(t.TempDir())
+	entry, _ := store.Register("s", writeSource(t, "a.txt", "a"), Entry{})
+
+	if err := store.DeleteSession("s"); err != nil {
+		t.Fatalf("DeleteSession() error = %v", err)
+	}
+	if _, err := os.Stat(entry.Path); !os.IsNotExist(err) {
+		t.Errorf("artifact still exists: %v", err)
+	}
+	entries, err := store.List("s")
+	if err != nil || len(entries) != 0 {
+		t.Errorf("List() after delete = %v, %v", entries, err)
+	}
+}
+
+func TestStore_InvalidSession(t *testing.T) {
+	store := NewStore(t.TempDir())
+	for _, id := range []string{"", "..", "a/b"} {
+		if _, err := store.List(id); !errors.Is(err, ErrInvalidSession) {
+			t.Errorf("List(%q) error = %v, want ErrInvalidSession", id, err)
+		}
+	}
+}
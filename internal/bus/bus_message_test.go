@@ -107,6 +107,22 @@ func TestMessageBus_SubscribeInbound(t *testing.T) {
 		if receivedMsg.Content != msg.Content {
 			t.Errorf("Expected Content %s, got %s", msg.Content, receivedMsg.Content)
 		}
+		if receivedMsg.CorrelationID == "" {
+			t.Error("Expected PublishInbound to assign a correlation ID")
+		}
+	case <-time.After(1 * time.Second):
+		t.Error("Timeout waiting for message")
+	}
+
+	msg.CorrelationID = "req-1"
+	if err := bus.PublishInbound(*msg); err != nil {
+		t.Fatalf("PublishInbound() failed: %v", err)
+	}
+	select {
+	case receivedMsg := <-ch:
+		if receivedMsg.CorrelationID != "req-1" {
+			t.Errorf("Expected CorrelationID req-1, got %s", receivedMsg.CorrelationID)
+		}
 	case <-time.After(1 * time.Second):
 		t.Error("Timeout waiting for message")
 	}
@@ -153,3 +169,89 @@ func TestMessageBus_SubscribeOutbound(t *testing.T) {
 		t.Error("Timeout waiting for message")
 	}
 }
+
+func TestMessageBus_PublishDoesNotAllocate(t *testing.T) {
+	bus := New(10, 10, createTestLogger(t))
+	if err := bus.Start(t.Context()); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer func() { _ = bus.Stop() }()
+	inboundCh := bus.SubscribeInbound(t.Context())
+	outboundCh := bus.SubscribeOutbound(t.Context())
+
+	inbound := NewInboundMessage(ChannelTypeTelegram, "user123", "telegram:123", "Hello", nil)
+	inbound.CorrelationID = "corr123"
+	outbound := NewOutboundMessage(ChannelTypeTelegram, "user123", "telegram:123", "Hi", "corr123", FormatTypePlain, nil)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := bus.PublishInbound(*inbound); err != nil {
+			t.Fatalf("PublishInbound() failed: %v", err)
+		}
+		<-inboundCh
+		if err := bus.PublishOutbound(*outbound); err != nil {
+			t.Fatalf("PublishOutbound() failed: %v", err)
+		}
+		<-outboundCh
+	})
+	if allocs != 0 {
+		t.Errorf("publishing allocates %.0f times per message pair, want 0", allocs)
+	}
+}
+
+func BenchmarkMessageBus_InboundRoundTrip(b *testing.B) {
+	bus := New(100, 100, createTestLogger(b))
+	if err := bus.Start(b.Context()); err != nil {
+		b.Fatalf("Start() failed: %v", err)
+	}
+	defer func() { _ = bus.Stop() }()
+	inboundCh := bus.SubscribeInbound(b.Context())
+
+	msg := NewInboundMessage(ChannelTypeTelegram, "user123", "telegram:123", "Hello", nil)
+	msg.CorrelationID = "corr123"
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := bus.PublishInbound(*msg); err != nil {
+			b.Fatalf("PublishInbound() failed: %v", err)
+		}
+		<-inboundCh
+	}
+}
+
+func BenchmarkMessageBus_OutboundRoundTrip(b *testing.B) {
+	bus := New(100, 100, createTestLogger(b))
+	if err := bus.Start(b.Context()); err != nil {
+		b.Fatalf("Start() failed: %v", err)
+	}
+	defer func() { _ = bus.Stop() }()
+	outboundCh := bus.SubscribeOutbound(b.Context())
+
+	msg := NewOutboundMessage(ChannelTypeTelegram, "user123", "telegram:123", "Hello", "corr123", FormatTypeMarkdown, nil)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := bus.PublishOutbound(*msg); err != nil {
+			b.Fatalf("PublishOutbound() failed: %v", err)
+		}
+		<-outboundCh
+	}
+}
+
+func BenchmarkMessageBus_PublishSendResult(b *testing.B) {
+	bus := New(100, 100, createTestLogger(b))
+	if err := bus.Start(b.Context()); err != nil {
+		b.Fatalf("Start() failed: %v", err)
+	}
+	defer func() { _ = bus.Stop() }()
+	resultCh := bus.SubscribeSendResults(b.Context())
+
+	result := MessageSendResult{CorrelationID: "corr123", ChannelType: ChannelTypeTelegram, Success: true}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := bus.PublishSendResult(result); err != nil {
+			b.Fatalf("PublishSendResult() failed: %v", err)
+		}
+		<-resultCh
+	}
+}
@@ -14,10 +14,12 @@
 package bus
 
 import (
+	"context"
 	"encoding/json"
 	"time"
 
 	"github.com/aatumaykin/nexbot/internal/channels"
+	"github.com/aatumaykin/nexbot/internal/logger"
 )
 
 // EventType represents the type of lifecycle event
@@ -26,19 +28,46 @@ type EventType string
 const (
 	EventTypeProcessingStart EventType = "processing_start" // Event when LLM processing starts
 	EventTypeProcessingEnd   EventType = "processing_end"   // Event when LLM processing ends
+	EventTypeToolProgress    EventType = "tool_progress"    // Progress report of a running tool
+
+	EventTypeConnectionLost     EventType = "connection_lost"     // Channel lost its connection to the platform
+	EventTypeConnectionRestored EventType = "connection_restored" // Channel reconnected after a loss
+)
+
+// Metadata keys of tool progress events
+const (
+	MetadataProgressTool    = "tool"    // Name of the tool
+	MetadataProgressPercent = "percent" // Completion percentage (int), -1 if unknown
+	MetadataProgressStatus  = "status"  // Status text, optional
+)
+
+// Metadata keys of connection events
+const (
+	MetadataConnectionError    = "error"            // Error that broke the connection (connection_lost)
+	MetadataConnectionDowntime = "downtime_seconds" // Length of the outage (connection_restored), float64
 )
 
 // MessageType represents the type of outbound message
 type MessageType string
 
 const (
-	MessageTypeText     MessageType = "text"     // Plain text message
-	MessageTypeEdit     MessageType = "edit"     // Edit existing message
-	MessageTypeDelete   MessageType = "delete"   // Delete existing message
-	MessageTypePhoto    MessageType = "photo"    // Photo message
-	MessageTypeDocument MessageType = "document" // Document message
+	MessageTypeText         MessageType = "text"           // Plain text message
+	MessageTypeEdit         MessageType = "edit"           // Edit existing message
+	MessageTypeDelete       MessageType = "delete"         // Delete existing message
+	MessageTypePhoto        MessageType = "photo"          // Photo message
+	MessageTypeDocument     MessageType = "document"       // Document message
+	MessageTypeSticker      MessageType = "sticker"        // Sticker message (file ID or URL of a .webp/.tgs/.webm sticker)
+	MessageTypeAnimation    MessageType = "animation"      // GIF or silent MPEG-4 animation
+	MessageTypeStream       MessageType = "stream"         // Partial answer, rendered by editing a draft message
+	MessageTypePin          MessageType = "pin"            // Pin a message (MessageID, empty for the last message sent by the bot)
+	MessageTypeUnpin        MessageType = "unpin"          // Unpin a message (MessageID, empty for the most recently pinned)
+	MessageTypeSetChatTitle MessageType = "set_chat_title" // Rename the chat to Content
 )
 
+// MetadataStreamID is the metadata key of the stream an answer belongs to.
+// Stream messages and the final text message of the answer share the same ID.
+const MetadataStreamID = "stream_id"
+
 // FormatType represents the format type for message content
 type FormatType string
 
@@ -72,12 +101,13 @@ const (
 
 // InboundMessage represents a message received from an external channel
 type InboundMessage struct {
-	ChannelType ChannelType    `json:"channel_type"`
-	UserID      string         `json:"user_id"`
-	SessionID   string         `json:"session_id"`
-	Content     string         `json:"content"`
-	Timestamp   time.Time      `json:"timestamp"`
-	Metadata    map[string]any `json:"metadata,omitempty"`
+	ChannelType   ChannelType    `json:"channel_type"`
+	UserID        string         `json:"user_id"`
+	SessionID     string         `json:"session_id"`
+	Content       string         `json:"content"`
+	CorrelationID string         `json:"correlation_id,omitempty"` // Request ID in logs; assigned by PublishInbound
+	Timestamp     time.Time      `json:"timestamp"`
+	Metadata      map[string]any `json:"metadata,omitempty"`
 }
 
 // MediaData represents media attachments in outbound messages
@@ -107,24 +137,31 @@ type OutboundMessage struct {
 	ChannelType    ChannelType     `json:"channel_type"`
 	UserID         string          `json:"user_id"`
 	SessionID      string          `json:"session_id"`
-	Type           MessageType     `json:"type"`                      // Message type (text, edit, delete, photo, document)
+	Type           MessageType     `json:"type"`                      // Message type (text, edit, delete, photo, document, stream)
 	Content        string          `json:"content"`                   // Text content (for text/edit messages)
 	Format         FormatType      `json:"format,omitempty"`          // Format type (plain, markdown, html, markdownv2)
 	CorrelationID  string          `json:"correlation_id,omitempty"`  // для отслеживания результата отправки
+	IdempotencyKey string          `json:"idempotency_key,omitempty"` // Повторная отправка с тем же ключом не дублирует сообщение в канале
 	MessageID      string          `json:"message_id,omitempty"`      // ID of message to edit/delete
 	Media          *MediaData      `json:"media,omitempty"`           // Media data (for photo/document messages)
 	InlineKeyboard *InlineKeyboard `json:"inline_keyboard,omitempty"` // Inline keyboard for interactive buttons
+	Table          *Table          `json:"table,omitempty"`           // Table rendered after the content (for text messages)
 	Timestamp      time.Time       `json:"timestamp"`
 	Metadata       map[string]any  `json:"metadata,omitempty"`
 }
 
 // MessageSendResult - результат отправки сообщения в канал
 type MessageSendResult struct {
-	CorrelationID string                // ID для сопоставления с запросом
-	ChannelType   ChannelType           // Канал отправки (telegram и т.д.)
-	Success       bool                  // Успешная отправка
-	Error         channels.ErrorDetails // Детали ошибки (если есть)
-	Timestamp     time.Time             // Время получения результата
+	CorrelationID  string                // ID для сопоставления с запросом
+	IdempotencyKey string                // Ключ идемпотентности сообщения (если есть)
+	ChannelType    ChannelType           // Канал отправки (telegram и т.д.)
+	SessionID      string                // Сессия, в чат которой отправлено сообщение
+	Type           MessageType           // Тип отправленного сообщения
+	MessageID      string                // ID сообщения в канале (отправленного или изменённого)
+	Success        bool                  // Успешная отправка
+	Duplicate      bool                  // Сообщение с этим ключом уже было отправлено, повтор пропущен
+	Error          channels.ErrorDetails // Детали ошибки (если есть)
+	Timestamp      time.Time             // Время получения результата
 }
 
 // ToJSON serializes the InboundMessage to JSON bytes
@@ -147,6 +184,24 @@ func (m *OutboundMessage) FromJSON(data []byte) error {
 	return json.Unmarshal(data, m)
 }
 
+// LogContext returns ctx carrying the message's correlation, session and
+// user IDs, which logger *Ctx methods add to every log line.
+func (m *InboundMessage) LogContext(ctx context.Context) context.Context {
+	return logger.WithContext(ctx,
+		logger.Field{Key: "correlation_id", Value: m.CorrelationID},
+		logger.Field{Key: "session_id", Value: m.SessionID},
+		logger.Field{Key: "user_id", Value: m.UserID},
+		logger.Field{Key: "channel", Value: string(m.ChannelType)})
+}
+
+// LogContext returns ctx carrying the message's correlation and session IDs.
+func (m *OutboundMessage) LogContext(ctx context.Context) context.Context {
+	return logger.WithContext(ctx,
+		logger.Field{Key: "correlation_id", Value: m.CorrelationID},
+		logger.Field{Key: "session_id", Value: m.SessionID},
+		logger.Field{Key: "channel", Value: string(m.ChannelType)})
+}
+
 // NewInboundMessage creates a new InboundMessage with the current timestamp
 func NewInboundMessage(channelType ChannelType, userID, sessionID, content string, metadata map[string]any) *InboundMessage {
 	return &InboundMessage{
@@ -190,6 +245,13 @@ func NewOutboundMessageWithKeyboard(channelType ChannelType, userID, sessionID,
 	}
 }
 
+// NewTableMessage creates a new text message with a table and the current timestamp
+func NewTableMessage(channelType ChannelType, userID, sessionID, content string, table *Table, correlationID string, keyboard *InlineKeyboard, format FormatType, metadata map[string]any) *OutboundMessage {
+	msg := NewOutboundMessageWithKeyboard(channelType, userID, sessionID, content, correlationID, keyboard, format, metadata)
+	msg.Table = table
+	return msg
+}
+
 // NewEditMessage creates a new edit message with the current timestamp
 func NewEditMessage(channelType ChannelType, userID, sessionID, messageID, content string, correlationID string, format FormatType, metadata map[string]any) *OutboundMessage {
 	return &OutboundMessage{
@@ -237,6 +299,63 @@ func NewDeleteMessage(channelType ChannelType, userID, sessionID, messageID stri
 	}
 }
 
+// NewPinMessage creates a message pinning messageID in the chat of the session.
+// An empty messageID pins the last message sent by the bot.
+func NewPinMessage(channelType ChannelType, userID, sessionID, messageID, correlationID string, metadata map[string]any) *OutboundMessage {
+	return &OutboundMessage{
+		ChannelType:   channelType,
+		UserID:        userID,
+		SessionID:     sessionID,
+		Type:          MessageTypePin,
+		CorrelationID: correlationID,
+		MessageID:     messageID,
+		Timestamp:     time.Now(),
+		Metadata:      metadata,
+	}
+}
+
+// NewUnpinMessage creates a message unpinning messageID in the chat of the session.
+// An empty messageID unpins the most recently pinned message.
+func NewUnpinMessage(channelType ChannelType, userID, sessionID, messageID, correlationID string, metadata map[string]any) *OutboundMessage {
+	return &OutboundMessage{
+		ChannelType:   channelType,
+		UserID:        userID,
+		SessionID:     sessionID,
+		Type:          MessageTypeUnpin,
+		CorrelationID: correlationID,
+		MessageID:     messageID,
+		Timestamp:     time.Now(),
+		Metadata:      metadata,
+	}
+}
+
+// NewSetChatTitleMessage creates a message renaming the chat of the session
+func NewSetChatTitleMessage(channelType ChannelType, userID, sessionID, title, correlationID string, metadata map[string]any) *OutboundMessage {
+	return &OutboundMessage{
+		ChannelType:   channelType,
+		UserID:        userID,
+		SessionID:     sessionID,
+		Type:          MessageTypeSetChatTitle,
+		Content:       title,
+		CorrelationID: correlationID,
+		Timestamp:     time.Now(),
+		Metadata:      metadata,
+	}
+}
+
+// NewStreamMessage creates a partial answer message of the given stream
+func NewStreamMessage(channelType ChannelType, userID, sessionID, streamID, content string) *OutboundMessage {
+	return &OutboundMessage{
+		ChannelType: channelType,
+		UserID:      userID,
+		SessionID:   sessionID,
+		Type:        MessageTypeStream,
+		Content:     content,
+		Timestamp:   time.Now(),
+		Metadata:    map[string]any{MetadataStreamID: streamID},
+	}
+}
+
 // NewPhotoMessage creates a new photo message with the current timestamp
 func NewPhotoMessage(channelType ChannelType, userID, sessionID string, media *MediaData, correlationID string, format FormatType, metadata map[string]any) *OutboundMessage {
 	return &OutboundMessage{
@@ -299,6 +418,37 @@ func NewDocumentMessageWithKeyboard(channelType ChannelType, userID, sessionID s
 	}
 }
 
+// NewStickerMessage creates a new sticker message; keyboard may be nil
+func NewStickerMessage(channelType ChannelType, userID, sessionID string, media *MediaData, keyboard *InlineKeyboard, correlationID string, metadata map[string]any) *OutboundMessage {
+	return &OutboundMessage{
+		ChannelType:    channelType,
+		UserID:         userID,
+		SessionID:      sessionID,
+		Type:           MessageTypeSticker,
+		CorrelationID:  correlationID,
+		Media:          media,
+		InlineKeyboard: keyboard,
+		Timestamp:      time.Now(),
+		Metadata:       metadata,
+	}
+}
+
+// NewAnimationMessage creates a new animation message; keyboard may be nil
+func NewAnimationMessage(channelType ChannelType, userID, sessionID string, media *MediaData, keyboard *InlineKeyboard, correlationID string, format FormatType, metadata map[string]any) *OutboundMessage {
+	return &OutboundMessage{
+		ChannelType:    channelType,
+		UserID:         userID,
+		SessionID:      sessionID,
+		Type:           MessageTypeAnimation,
+		CorrelationID:  correlationID,
+		Media:          media,
+		Format:         format,
+		InlineKeyboard: keyboard,
+		Timestamp:      time.Now(),
+		Metadata:       metadata,
+	}
+}
+
 // ToJSON serializes the Event to JSON bytes
 func (e *Event) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
@@ -333,6 +483,51 @@ func NewProcessingEndEvent(channelType ChannelType, userID, sessionID string, me
 	}
 }
 
+// NewToolProgressEvent creates a progress event of a running tool.
+// Percent is -1 if the progress is unknown.
+func NewToolProgressEvent(channelType ChannelType, userID, sessionID, tool string, percent int, status string) *Event {
+	return &Event{
+		Type:        EventTypeToolProgress,
+		ChannelType: channelType,
+		UserID:      userID,
+		SessionID:   sessionID,
+		Timestamp:   time.Now(),
+		Metadata: map[string]any{
+			MetadataProgressTool:    tool,
+			MetadataProgressPercent: percent,
+			MetadataProgressStatus:  status,
+		},
+	}
+}
+
+// NewConnectionLostEvent creates an event reporting that a channel lost its
+// connection to the platform.
+func NewConnectionLostEvent(channelType ChannelType, err error) *Event {
+	metadata := map[string]any{}
+	if err != nil {
+		metadata[MetadataConnectionError] = err.Error()
+	}
+	return &Event{
+		Type:        EventTypeConnectionLost,
+		ChannelType: channelType,
+		Timestamp:   time.Now(),
+		Metadata:    metadata,
+	}
+}
+
+// NewConnectionRestoredEvent creates an event reporting that a channel
+// reconnected after an outage of the given length.
+func NewConnectionRestoredEvent(channelType ChannelType, downtime time.Duration) *Event {
+	return &Event{
+		Type:        EventTypeConnectionRestored,
+		ChannelType: channelType,
+		Timestamp:   time.Now(),
+		Metadata: map[string]any{
+			MetadataConnectionDowntime: downtime.Seconds(),
+		},
+	}
+}
+
 // Metrics holds message bus metrics
 type Metrics struct {
 	InboundMessagesDropped   int64
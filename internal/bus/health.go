@@ -0,0 +1,66 @@
+package bus
+
+import (
+	"fmt"
+	"time"
+)
+
+// healthLockTimeout is how long CheckHealth waits for the bus lock
+const healthLockTimeout = 5 * time.Second
+
+// CheckHealth reports whether the bus is alive: it is started, its lock is
+// not held indefinitely and the distributors drain the queues. A queue that
+// stays full means its distributor is stuck.
+func (mb *MessageBus) CheckHealth() error {
+	deadline := time.Now().Add(healthLockTimeout)
+	for !mb.mu.TryRLock() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("message bus lock held for more than %s", healthLockTimeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer mb.mu.RUnlock()
+
+	if !mb.started {
+		return ErrNotStarted
+	}
+
+	queues := []struct {
+		name      string
+		len, size int
+	}{
+		{"inbound", len(mb.inboundCh), cap(mb.inboundCh)},
+		{"outbound", len(mb.outboundCh), cap(mb.outboundCh)},
+		{"event", len(mb.eventCh), cap(mb.eventCh)},
+	}
+	for _, q := range queues {
+		if q.size > 0 && q.len >= q.size {
+			return fmt.Errorf("%s queue is full (%d messages)", q.name, q.len)
+		}
+	}
+	return nil
+}
+
+// QueueDepth is the number of messages waiting in a bus queue.
+type QueueDepth struct {
+	Name     string
+	Length   int
+	Capacity int
+}
+
+// QueueDepths returns the number of waiting messages in every bus queue.
+// A stopped bus reports no queues.
+func (mb *MessageBus) QueueDepths() []QueueDepth {
+	mb.mu.RLock()
+	defer mb.mu.RUnlock()
+
+	if !mb.started {
+		return nil
+	}
+	return []QueueDepth{
+		{"inbound", len(mb.inboundCh), cap(mb.inboundCh)},
+		{"outbound", len(mb.outboundCh), cap(mb.outboundCh)},
+		{"event", len(mb.eventCh), cap(mb.eventCh)},
+		{"result", len(mb.resultCh), cap(mb.resultCh)},
+	}
+}
@@ -0,0 +1,183 @@
+package bus
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// PageCallbackPrefix marks callback data of pagination buttons.
+	// Callback data format: "page:<token>:<page>" (fits the 64-byte Telegram limit).
+	PageCallbackPrefix = "page:"
+
+	// DefaultPageSize is used when a page size is not specified
+	DefaultPageSize = 10
+
+	// DefaultPageTTL is how long a paged list can be browsed after it was sent
+	DefaultPageTTL = 24 * time.Hour
+
+	// defaultMaxPagedLists limits the number of paged lists kept in memory
+	defaultMaxPagedLists = 500
+)
+
+// PagedList is a long list split into pages that is browsed with Prev/Next buttons.
+type PagedList struct {
+	Token     string
+	Title     string
+	Items     []string
+	PageSize  int
+	Format    FormatType
+	CreatedAt time.Time
+}
+
+// PageCount returns the number of pages (at least 1).
+func (l *PagedList) PageCount() int {
+	if len(l.Items) == 0 {
+		return 1
+	}
+	return (len(l.Items) + l.PageSize - 1) / l.PageSize
+}
+
+// Render returns the content and navigation keyboard of a page (0-based).
+// The page number is clamped to the valid range. The keyboard is nil when
+// the list fits on a single page.
+func (l *PagedList) Render(page int) (string, *InlineKeyboard) {
+	pages := l.PageCount()
+	page = max(0, min(page, pages-1))
+
+	var b strings.Builder
+	if l.Title != "" {
+		b.WriteString(l.Title)
+		b.WriteString("\n\n")
+	}
+	start := page * l.PageSize
+	end := min(start+l.PageSize, len(l.Items))
+	b.WriteString(strings.Join(l.Items[start:end], "\n"))
+
+	if pages == 1 {
+		return b.String(), nil
+	}
+
+	var row []InlineButton
+	if page > 0 {
+		row = append(row, InlineButton{Text: "◀ Prev", Data: PageCallbackData(l.Token, page-1)})
+	}
+	// Counter button re-renders the current page
+	row = append(row, InlineButton{Text: fmt.Sprintf("%d/%d", page+1, pages), Data: PageCallbackData(l.Token, page)})
+	if page < pages-1 {
+		row = append(row, InlineButton{Text: "Next ▶", Data: PageCallbackData(l.Token, page+1)})
+	}
+
+	return b.String(), &InlineKeyboard{Rows: [][]InlineButton{row}}
+}
+
+// PageCallbackData builds callback data for a page button.
+func PageCallbackData(token string, page int) string {
+	return fmt.Sprintf("%s%s:%d", PageCallbackPrefix, token, page)
+}
+
+// ParsePageCallback parses pagination callback data.
+func ParsePageCallback(data string) (token string, page int, ok bool) {
+	rest, found := strings.CutPrefix(data, PageCallbackPrefix)
+	if !found {
+		return "", 0, false
+	}
+	token, pageStr, found := strings.Cut(rest, ":")
+	if !found || token == "" {
+		return "", 0, false
+	}
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 0 {
+		return "", 0, false
+	}
+	return token, page, true
+}
+
+// PageStore keeps paged lists in memory so channels can render other pages
+// when a navigation button is pressed. Old lists expire after the TTL and the
+// oldest lists are evicted when the store is full.
+type PageStore struct {
+	mu       sync.Mutex
+	lists    map[string]*PagedList
+	order    []string
+	ttl      time.Duration
+	maxLists int
+}
+
+// NewPageStore creates a page store. Zero values use the defaults.
+func NewPageStore(ttl time.Duration, maxLists int) *PageStore {
+	if ttl <= 0 {
+		ttl = DefaultPageTTL
+	}
+	if maxLists <= 0 {
+		maxLists = defaultMaxPagedLists
+	}
+	return &PageStore{
+		lists:    make(map[string]*PagedList),
+		ttl:      ttl,
+		maxLists: maxLists,
+	}
+}
+
+// Put stores a list and returns it with an assigned token.
+func (s *PageStore) Put(title string, items []string, pageSize int, format FormatType) *PagedList {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	list := &PagedList{
+		Token:     newPageToken(),
+		Title:     title,
+		Items:     items,
+		PageSize:  pageSize,
+		Format:    format,
+		CreatedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictLocked()
+	for len(s.order) >= s.maxLists {
+		delete(s.lists, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.lists[list.Token] = list
+	s.order = append(s.order, list.Token)
+	return list
+}
+
+// Get returns a stored list by token.
+func (s *PageStore) Get(token string) (*PagedList, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictLocked()
+	list, ok := s.lists[token]
+	return list, ok
+}
+
+// evictLocked removes expired lists. Caller must hold s.mu.
+func (s *PageStore) evictLocked() {
+	cutoff := time.Now().Add(-s.ttl)
+	n := 0
+	for _, token := range s.order {
+		if s.lists[token].CreatedAt.Before(cutoff) {
+			delete(s.lists, token)
+			continue
+		}
+		s.order[n] = token
+		n++
+	}
+	s.order = s.order[:n]
+}
+
+func newPageToken() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
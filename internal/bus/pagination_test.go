@@ -0,0 +1,103 @@
+package bus
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func makeItems(n int) []string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = fmt.Sprintf("item %d", i+1)
+	}
+	return items
+}
+
+// TestPagedList_Render tests page content and navigation buttons
+func TestPagedList_Render(t *testing.T) {
+	store := NewPageStore(0, 0)
+	list := store.Put("Results", makeItems(25), 10, FormatTypePlain)
+
+	if list.PageCount() != 3 {
+		t.Fatalf("Expected 3 pages, got %d", list.PageCount())
+	}
+
+	content, keyboard := list.Render(0)
+	if !strings.HasPrefix(content, "Results\n\nitem 1\n") || strings.Contains(content, "item 11") {
+		t.Errorf("Unexpected first page content: %q", content)
+	}
+	if keyboard == nil || len(keyboard.Rows) != 1 {
+		t.Fatal("Expected a single navigation row")
+	}
+	row := keyboard.Rows[0]
+	if len(row) != 2 || row[0].Text != "1/3" || row[1].Data != PageCallbackData(list.Token, 1) {
+		t.Errorf("Unexpected first page buttons: %+v", row)
+	}
+
+	content, keyboard = list.Render(2)
+	if !strings.Contains(content, "item 25") || strings.Contains(content, "item 20") {
+		t.Errorf("Unexpected last page content: %q", content)
+	}
+	row = keyboard.Rows[0]
+	if len(row) != 2 || row[0].Data != PageCallbackData(list.Token, 1) || row[1].Text != "3/3" {
+		t.Errorf("Unexpected last page buttons: %+v", row)
+	}
+
+	// Out of range pages are clamped
+	clamped, _ := list.Render(10)
+	if clamped != content {
+		t.Error("Expected out of range page to render the last page")
+	}
+}
+
+// TestPagedList_SinglePage tests that short lists have no keyboard
+func TestPagedList_SinglePage(t *testing.T) {
+	list := NewPageStore(0, 0).Put("", makeItems(3), 0, FormatTypePlain)
+
+	content, keyboard := list.Render(0)
+	if keyboard != nil {
+		t.Error("Expected no keyboard for a single page")
+	}
+	if content != "item 1\nitem 2\nitem 3" {
+		t.Errorf("Unexpected content: %q", content)
+	}
+}
+
+// TestParsePageCallback tests callback data parsing
+func TestParsePageCallback(t *testing.T) {
+	token, page, ok := ParsePageCallback(PageCallbackData("abc123", 4))
+	if !ok || token != "abc123" || page != 4 {
+		t.Errorf("ParsePageCallback() = %q, %d, %v", token, page, ok)
+	}
+
+	for _, data := range []string{"", "page:", "page:abc", "page::1", "page:abc:x", "page:abc:-1", "other:abc:1"} {
+		if _, _, ok := ParsePageCallback(data); ok {
+			t.Errorf("ParsePageCallback(%q) should fail", data)
+		}
+	}
+
+	if len(PageCallbackData(newPageToken(), 9999)) > 64 {
+		t.Error("Callback data exceeds Telegram limit of 64 bytes")
+	}
+}
+
+// TestPageStore_Eviction tests TTL expiry and capacity limits
+func TestPageStore_Eviction(t *testing.T) {
+	store := NewPageStore(time.Hour, 2)
+	first := store.Put("", makeItems(1), 0, FormatTypePlain)
+	store.Put("", makeItems(1), 0, FormatTypePlain)
+	store.Put("", makeItems(1), 0, FormatTypePlain)
+
+	if _, ok := store.Get(first.Token); ok {
+		t.Error("Expected oldest list to be evicted when store is full")
+	}
+
+	expiring := NewPageStore(time.Millisecond, 0)
+	list := expiring.Put("", makeItems(1), 0, FormatTypePlain)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := expiring.Get(list.Token); ok {
+		t.Error("Expected list to expire after TTL")
+	}
+}
@@ -3,12 +3,19 @@ package bus
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/google/uuid"
 )
 
+// resultPublishTimeout is how long PublishSendResult waits for space in a
+// full result channel before forcing the publish
+const resultPublishTimeout = 100 * time.Millisecond
+
 var (
 	// ErrQueueClosed is returned when attempting to publish to a closed queue.
 	ErrQueueClosed = errors.New("queue is closed")
@@ -23,6 +30,12 @@ var (
 	ErrNotStarted = errors.New("message bus is not started")
 )
 
+// OutboundFilter inspects outbound messages before they reach connectors
+// and returns the message to deliver (e.g. moderation).
+type OutboundFilter interface {
+	FilterOutbound(ctx context.Context, msg OutboundMessage) OutboundMessage
+}
+
 // MessageBus represents an asynchronous message queue for inbound and outbound messages.
 // It implements the publish-subscribe pattern, allowing multiple subscribers to receive
 // copies of all published messages.
@@ -65,7 +78,9 @@ type MessageBus struct {
 	eventCh    chan Event
 	resultCh   chan MessageSendResult // для result tracking
 	tracker    *ResultTracker
+	pages      *PageStore
 	metrics    Metrics
+	filter     OutboundFilter
 
 	inboundSubscribers    map[int64]chan InboundMessage
 	outboundSubscribers   map[int64]chan OutboundMessage
@@ -84,6 +99,7 @@ func New(capacity, subscriberChannelSize int, logger *logger.Logger) *MessageBus
 		eventCh:               make(chan Event, capacity),
 		resultCh:              make(chan MessageSendResult, 500),
 		tracker:               NewResultTracker(logger),
+		pages:                 NewPageStore(0, 0),
 		inboundSubscribers:    make(map[int64]chan InboundMessage),
 		outboundSubscribers:   make(map[int64]chan OutboundMessage),
 		eventSubscribers:      make(map[int64]chan Event),
@@ -164,73 +180,81 @@ func (mb *MessageBus) Stop() error {
 	return nil
 }
 
-// publishMessage publishes a message of any type to a channel.
-// This is a generic function to eliminate code duplication between
-// PublishInbound, PublishOutbound, and PublishEvent.
-func publishMessage[T any](
-	ctx context.Context,
-	mu *sync.RWMutex,
-	started bool,
-	ch chan<- T,
-	msg T,
-	logDebug func(),
-	logWarn func(),
-) error {
+// publishMessage publishes a message of any type to a channel without
+// blocking. This is a generic function to eliminate code duplication between
+// PublishInbound, PublishOutbound, and PublishEvent; callers log the result
+// themselves, so the message doesn't escape to the heap.
+func publishMessage[T any](mu *sync.RWMutex, started *bool, ch chan<- T, msg T) error {
 	mu.RLock()
 	defer mu.RUnlock()
 
-	if !started {
+	if !*started {
 		return ErrNotStarted
 	}
 
 	select {
 	case ch <- msg:
-		logDebug()
 		return nil
 	default:
-		logWarn()
 		return ErrQueueFull
 	}
 }
 
-// PublishInbound publishes an inbound message to the queue
+// debugEnabled reports whether debug messages are logged. Publish paths
+// check it before building log fields, which allocate.
+func (mb *MessageBus) debugEnabled() bool {
+	return mb.logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+// PublishInbound publishes an inbound message to the queue. A message
+// without a correlation ID gets a new one, so every log line of the request
+// can be found by it.
 func (mb *MessageBus) PublishInbound(msg InboundMessage) error {
-	return publishMessage(
-		mb.ctx,
-		&mb.mu,
-		mb.started,
-		mb.inboundCh,
-		msg,
-		func() {
-			mb.logger.DebugCtx(mb.ctx, "inbound message published",
-				logger.Field{Key: "session_id", Value: msg.SessionID},
-				logger.Field{Key: "user_id", Value: msg.UserID})
-		},
-		func() {
-			mb.logger.WarnCtx(mb.ctx, "inbound queue full",
-				logger.Field{Key: "capacity", Value: cap(mb.inboundCh)})
-		},
-	)
+	if msg.CorrelationID == "" {
+		msg.CorrelationID = uuid.NewString()
+	}
+
+	err := publishMessage(&mb.mu, &mb.started, mb.inboundCh, msg)
+	switch {
+	case err == nil && mb.debugEnabled():
+		mb.logger.DebugCtx(msg.LogContext(mb.ctx), "inbound message published")
+	case errors.Is(err, ErrQueueFull):
+		mb.logger.WarnCtx(msg.LogContext(mb.ctx), "inbound queue full",
+			logger.Field{Key: "capacity", Value: cap(mb.inboundCh)})
+	}
+	return err
+}
+
+// SetOutboundFilter sets the filter applied to every outbound message before it is queued.
+func (mb *MessageBus) SetOutboundFilter(filter OutboundFilter) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	mb.filter = filter
 }
 
 // PublishOutbound publishes an outbound message to the queue
 func (mb *MessageBus) PublishOutbound(msg OutboundMessage) error {
-	return publishMessage(
-		mb.ctx,
-		&mb.mu,
-		mb.started,
-		mb.outboundCh,
-		msg,
-		func() {
-			mb.logger.DebugCtx(mb.ctx, "outbound message published",
-				logger.Field{Key: "session_id", Value: msg.SessionID},
-				logger.Field{Key: "user_id", Value: msg.UserID})
-		},
-		func() {
-			mb.logger.WarnCtx(mb.ctx, "outbound queue full",
-				logger.Field{Key: "capacity", Value: cap(mb.outboundCh)})
-		},
-	)
+	// Apply the filter without holding the lock: it may publish messages itself
+	mb.mu.RLock()
+	filter, ctx := mb.filter, mb.ctx
+	mb.mu.RUnlock()
+	if filter != nil {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		msg = filter.FilterOutbound(ctx, msg)
+	}
+
+	err := publishMessage(&mb.mu, &mb.started, mb.outboundCh, msg)
+	switch {
+	case err == nil && mb.debugEnabled():
+		mb.logger.DebugCtx(msg.LogContext(mb.ctx), "outbound message published",
+			logger.Field{Key: "user_id", Value: msg.UserID})
+	case errors.Is(err, ErrQueueFull):
+		mb.logger.WarnCtx(msg.LogContext(mb.ctx), "outbound queue full",
+			logger.Field{Key: "capacity", Value: cap(mb.outboundCh)})
+	}
+	return err
 }
 
 // MessageInfo provides details about a message for logging
@@ -279,7 +303,7 @@ func (mb *MessageBus) SubscribeInbound(ctx context.Context) <-chan InboundMessag
 	return ch
 }
 
-// SubscribeOutbound subscribes to outbound messages
+// SubscribeOutbound subscribes to outbound messages until ctx is done
 func (mb *MessageBus) SubscribeOutbound(ctx context.Context) <-chan OutboundMessage {
 	mb.mu.Lock()
 	defer mb.mu.Unlock()
@@ -293,6 +317,14 @@ func (mb *MessageBus) SubscribeOutbound(ctx context.Context) <-chan OutboundMess
 	id := mb.subscriberID
 	mb.outboundSubscribers[id] = ch
 	mb.metrics.OutboundSubscribersCount++
+	context.AfterFunc(ctx, func() {
+		mb.unsubscribe(func() {
+			if _, ok := mb.outboundSubscribers[id]; ok {
+				delete(mb.outboundSubscribers, id)
+				mb.metrics.OutboundSubscribersCount--
+			}
+		})
+	})
 
 	mb.logger.DebugCtx(ctx, "outbound subscriber added",
 		logger.Field{Key: "subscriber_id", Value: id},
@@ -324,12 +356,12 @@ func distributeMessages[T any, M MessageInfo](
 				return
 			}
 			mu.RLock()
-			msgInfo := getMessageInfo(msg)
 			for subID, subCh := range getSubscribers() {
 				select {
 				case subCh <- msg:
 				default:
 					// Subscriber channel is full, log with details and increment metrics
+					msgInfo := getMessageInfo(msg)
 					log.WarnCtx(ctx, logMsg,
 						logger.Field{Key: "subscriber_id", Value: subID},
 						logger.Field{Key: "message_type", Value: msgInfo.GetType()},
@@ -347,12 +379,22 @@ func distributeMessages[T any, M MessageInfo](
 	}
 }
 
+// unsubscribe removes a subscriber whose context is done, so a channel
+// that can be started again (e.g. after a leader failover) doesn't leave
+// stale subscribers behind. The channel itself is not closed: its reader
+// has already stopped on the same context.
+func (mb *MessageBus) unsubscribe(remove func()) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	remove()
+}
+
 // distributeInbound distributes inbound messages to all subscribers
 func (mb *MessageBus) distributeInbound() {
 	distributeMessages(mb.ctx, mb.logger, &mb.mu, &mb.metrics, mb.inboundCh, func() map[int64]chan InboundMessage {
 		return mb.inboundSubscribers
 	}, func(m InboundMessage) InboundMessage { return m }, "inbound subscriber channel full, skipping message", func() {
-		mb.metrics.InboundMessagesDropped++
+		atomic.AddInt64(&mb.metrics.InboundMessagesDropped, 1)
 	})
 }
 
@@ -361,7 +403,7 @@ func (mb *MessageBus) distributeOutbound() {
 	distributeMessages(mb.ctx, mb.logger, &mb.mu, &mb.metrics, mb.outboundCh, func() map[int64]chan OutboundMessage {
 		return mb.outboundSubscribers
 	}, func(m OutboundMessage) OutboundMessage { return m }, "outbound subscriber channel full, skipping message", func() {
-		mb.metrics.OutboundMessagesDropped++
+		atomic.AddInt64(&mb.metrics.OutboundMessagesDropped, 1)
 	})
 }
 
@@ -374,78 +416,57 @@ func (mb *MessageBus) IsStarted() bool {
 
 // PublishEvent publishes a lifecycle event to the queue
 func (mb *MessageBus) PublishEvent(event Event) error {
-	return publishMessage(
-		mb.ctx,
-		&mb.mu,
-		mb.started,
-		mb.eventCh,
-		event,
-		func() {
-			mb.logger.DebugCtx(mb.ctx, "event published",
-				logger.Field{Key: "event_type", Value: event.Type},
-				logger.Field{Key: "session_id", Value: event.SessionID},
-				logger.Field{Key: "user_id", Value: event.UserID})
-		},
-		func() {
-			mb.logger.WarnCtx(mb.ctx, "event queue full",
-				logger.Field{Key: "capacity", Value: cap(mb.eventCh)})
-		},
-	)
+	err := publishMessage(&mb.mu, &mb.started, mb.eventCh, event)
+	switch {
+	case err == nil && mb.debugEnabled():
+		mb.logger.DebugCtx(mb.ctx, "event published",
+			logger.Field{Key: "event_type", Value: event.Type},
+			logger.Field{Key: "session_id", Value: event.SessionID},
+			logger.Field{Key: "user_id", Value: event.UserID})
+	case errors.Is(err, ErrQueueFull):
+		mb.logger.WarnCtx(mb.ctx, "event queue full",
+			logger.Field{Key: "capacity", Value: cap(mb.eventCh)})
+	}
+	return err
 }
 
-// publishMessageWithTimeout publishes a message with custom timeout handling.
-// This is used for PublishSendResult which has special force-publish logic.
-func publishMessageWithTimeout[T any](
-	ctx context.Context,
-	mu *sync.RWMutex,
-	started bool,
-	ch chan<- T,
-	msg T,
-	onSuccess func(),
-	onTimeout func(),
-) error {
-	mu.RLock()
-	defer mu.RUnlock()
+// PublishSendResult публикует результат отправки сообщения. Если канал
+// результатов заполнен, ждёт до resultPublishTimeout, затем публикует
+// принудительно: результат нельзя потерять, его ждёт ResultTracker
+func (mb *MessageBus) PublishSendResult(result MessageSendResult) error {
+	mb.mu.RLock()
+	defer mb.mu.RUnlock()
 
-	if !started {
+	if !mb.started {
 		return ErrNotStarted
 	}
 
 	select {
-	case ch <- msg:
-		onSuccess()
-		return nil
-	case <-time.After(100 * time.Millisecond):
-		onTimeout()
-		return nil
-	}
-}
-
-// PublishSendResult публикует результат отправки сообщения
-func (mb *MessageBus) PublishSendResult(result MessageSendResult) error {
-	return publishMessageWithTimeout(
-		mb.ctx,
-		&mb.mu,
-		mb.started,
-		mb.resultCh,
-		result,
-		func() {
-			mb.tracker.Complete(result.CorrelationID, result)
-			mb.logger.DebugCtx(mb.ctx, "send result published",
-				logger.Field{Key: "correlation_id", Value: result.CorrelationID},
-				logger.Field{Key: "success", Value: result.Success})
-		},
-		func() {
+	case mb.resultCh <- result:
+	default:
+		// The timer is only needed when the channel is full
+		timer := time.NewTimer(resultPublishTimeout)
+		select {
+		case mb.resultCh <- result:
+		case <-timer.C:
 			mb.logger.WarnCtx(mb.ctx, "result channel full, forcing publish",
 				logger.Field{Key: "correlation_id", Value: result.CorrelationID},
 				logger.Field{Key: "queue_size", Value: len(mb.resultCh)})
 			mb.resultCh <- result
-			mb.tracker.Complete(result.CorrelationID, result)
-		},
-	)
+		}
+		timer.Stop()
+	}
+
+	mb.tracker.Complete(result.CorrelationID, result)
+	if mb.debugEnabled() {
+		mb.logger.DebugCtx(mb.ctx, "send result published",
+			logger.Field{Key: "correlation_id", Value: result.CorrelationID},
+			logger.Field{Key: "success", Value: result.Success})
+	}
+	return nil
 }
 
-// SubscribeEvent subscribes to lifecycle events
+// SubscribeEvent subscribes to lifecycle events until ctx is done
 func (mb *MessageBus) SubscribeEvent(ctx context.Context) <-chan Event {
 	mb.mu.Lock()
 	defer mb.mu.Unlock()
@@ -459,6 +480,14 @@ func (mb *MessageBus) SubscribeEvent(ctx context.Context) <-chan Event {
 	id := mb.subscriberID
 	mb.eventSubscribers[id] = ch
 	mb.metrics.EventSubscribersCount++
+	context.AfterFunc(ctx, func() {
+		mb.unsubscribe(func() {
+			if _, ok := mb.eventSubscribers[id]; ok {
+				delete(mb.eventSubscribers, id)
+				mb.metrics.EventSubscribersCount--
+			}
+		})
+	})
 
 	mb.logger.DebugCtx(ctx, "event subscriber added",
 		logger.Field{Key: "subscriber_id", Value: id},
@@ -472,7 +501,7 @@ func (mb *MessageBus) distributeEvents() {
 	distributeMessages(mb.ctx, mb.logger, &mb.mu, &mb.metrics, mb.eventCh, func() map[int64]chan Event {
 		return mb.eventSubscribers
 	}, func(e Event) Event { return e }, "event subscriber channel full, skipping event", func() {
-		mb.metrics.EventsDropped++
+		atomic.AddInt64(&mb.metrics.EventsDropped, 1)
 	})
 }
 
@@ -503,11 +532,27 @@ func (mb *MessageBus) GetResultTracker() *ResultTracker {
 	return mb.tracker
 }
 
+// GetPageStore возвращает хранилище постраничных списков (общее для tools и каналов)
+func (mb *MessageBus) GetPageStore() *PageStore {
+	return mb.pages
+}
+
 // GetMetrics возвращает метрики message bus
 func (mb *MessageBus) GetMetrics() Metrics {
 	mb.mu.RLock()
 	defer mb.mu.RUnlock()
-	return mb.metrics
+
+	// Dropped counters are incremented under the read lock by the distributors
+	return Metrics{
+		InboundMessagesDropped:   atomic.LoadInt64(&mb.metrics.InboundMessagesDropped),
+		OutboundMessagesDropped:  atomic.LoadInt64(&mb.metrics.OutboundMessagesDropped),
+		EventsDropped:            atomic.LoadInt64(&mb.metrics.EventsDropped),
+		ResultsDropped:           atomic.LoadInt64(&mb.metrics.ResultsDropped),
+		InboundSubscribersCount:  mb.metrics.InboundSubscribersCount,
+		OutboundSubscribersCount: mb.metrics.OutboundSubscribersCount,
+		EventSubscribersCount:    mb.metrics.EventSubscribersCount,
+		ResultSubscribersCount:   mb.metrics.ResultSubscribersCount,
+	}
 }
 
 // distributeResults distributes send results to all subscribers
@@ -515,6 +560,6 @@ func (mb *MessageBus) distributeResults() {
 	distributeMessages(mb.ctx, mb.logger, &mb.mu, &mb.metrics, mb.resultCh, func() map[int64]chan MessageSendResult {
 		return mb.resultSubscribers
 	}, func(r MessageSendResult) MessageSendResult { return r }, "result subscriber channel full, skipping result", func() {
-		mb.metrics.ResultsDropped++
+		atomic.AddInt64(&mb.metrics.ResultsDropped, 1)
 	})
 }
@@ -3,6 +3,7 @@ package bus
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"sync"
 	"time"
 
@@ -88,13 +89,16 @@ func (rt *ResultTracker) Complete(correlationID string, result MessageSendResult
 	regTime, timeOk := rt.pendingTimes[correlationID]
 	rt.mu.Unlock()
 
+	debug := rt.logger.Enabled(context.Background(), slog.LevelDebug)
 	if !ok {
-		rt.logger.DebugCtx(context.Background(), "no pending request for result",
-			logger.Field{Key: "correlation_id", Value: correlationID})
+		if debug {
+			rt.logger.DebugCtx(context.Background(), "no pending request for result",
+				logger.Field{Key: "correlation_id", Value: correlationID})
+		}
 		return
 	}
 
-	if timeOk {
+	if timeOk && debug {
 		duration := time.Since(regTime)
 		rt.logger.DebugCtx(context.Background(), "completing send result",
 			logger.Field{Key: "correlation_id", Value: correlationID},
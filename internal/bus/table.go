@@ -0,0 +1,178 @@
+package bus
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aatumaykin/nexbot/internal/textutil"
+)
+
+// MaxTableCellWidth truncates cells of text tables so rows fit narrow screens
+const MaxTableCellWidth = 32
+
+// Table is tabular data attached to an outbound message. Connectors render it
+// in the best way their channel supports (a monospace block in Telegram, a
+// real table in HTML or Markdown channels) instead of the markdown fallback
+// mangling pipe tables.
+type Table struct {
+	Title   string     `json:"title,omitempty"`
+	Headers []string   `json:"headers,omitempty"`
+	Rows    [][]string `json:"rows"`
+}
+
+// Columns returns the number of columns: the widest of the header and rows.
+func (t *Table) Columns() int {
+	columns := len(t.Headers)
+	for _, row := range t.Rows {
+		columns = max(columns, len(row))
+	}
+	return columns
+}
+
+// Text renders the table as aligned plain text for monospace blocks.
+// Numeric columns are right-aligned, long cells are truncated.
+func (t *Table) Text() string {
+	columns := t.Columns()
+	headers := padRow(t.Headers, columns)
+	rows := make([][]string, len(t.Rows))
+	for i, row := range t.Rows {
+		rows[i] = padRow(row, columns)
+	}
+
+	widths := make([]int, columns)
+	numeric := make([]bool, columns)
+	for c := range columns {
+		widths[c] = utf8.RuneCountInString(truncateCell(headers[c]))
+		numeric[c] = len(rows) > 0
+		for _, row := range rows {
+			widths[c] = max(widths[c], utf8.RuneCountInString(truncateCell(row[c])))
+			if row[c] != "" && !isNumeric(row[c]) {
+				numeric[c] = false
+			}
+		}
+	}
+
+	var b strings.Builder
+	if t.Title != "" {
+		b.WriteString(t.Title + "\n")
+	}
+	writeRow := func(row []string) {
+		cells := make([]string, columns)
+		for c, cell := range row {
+			cells[c] = alignCell(truncateCell(cell), widths[c], numeric[c])
+		}
+		b.WriteString(strings.TrimRight(strings.Join(cells, "  "), " ") + "\n")
+	}
+	if len(t.Headers) > 0 {
+		writeRow(headers)
+		separators := make([]string, columns)
+		for c := range columns {
+			separators[c] = strings.Repeat("-", widths[c])
+		}
+		b.WriteString(strings.Join(separators, "  ") + "\n")
+	}
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Markdown renders the table as a GitHub-flavored Markdown table.
+func (t *Table) Markdown() string {
+	columns := t.Columns()
+	if columns == 0 {
+		return t.Title
+	}
+
+	var b strings.Builder
+	if t.Title != "" {
+		b.WriteString("**" + t.Title + "**\n\n")
+	}
+	writeRow := func(row []string) {
+		cells := padRow(row, columns)
+		for c, cell := range cells {
+			cells[c] = strings.ReplaceAll(strings.ReplaceAll(cell, "|", `\|`), "\n", " ")
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	// Markdown tables need a header row
+	writeRow(t.Headers)
+	b.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+	for _, row := range t.Rows {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// HTML renders the table as an HTML table.
+func (t *Table) HTML() string {
+	columns := t.Columns()
+
+	var b strings.Builder
+	b.WriteString("<table>")
+	if t.Title != "" {
+		b.WriteString("<caption>" + html.EscapeString(t.Title) + "</caption>")
+	}
+	if len(t.Headers) > 0 {
+		b.WriteString("<thead><tr>")
+		for _, cell := range padRow(t.Headers, columns) {
+			b.WriteString("<th>" + html.EscapeString(cell) + "</th>")
+		}
+		b.WriteString("</tr></thead>")
+	}
+	b.WriteString("<tbody>")
+	for _, row := range t.Rows {
+		b.WriteString("<tr>")
+		for _, cell := range padRow(row, columns) {
+			b.WriteString("<td>" + html.EscapeString(cell) + "</td>")
+		}
+		b.WriteString("</tr>")
+	}
+	b.WriteString("</tbody></table>")
+	return b.String()
+}
+
+// padRow returns a copy of row with exactly columns cells.
+func padRow(row []string, columns int) []string {
+	padded := make([]string, columns)
+	copy(padded, row)
+	return padded
+}
+
+// truncateCell shortens a cell to MaxTableCellWidth runes on one line.
+func truncateCell(cell string) string {
+	cell = strings.ReplaceAll(cell, "\n", " ")
+	return textutil.Truncate(cell, MaxTableCellWidth)
+}
+
+// alignCell pads a cell to width, on the left for numbers.
+func alignCell(cell string, width int, right bool) string {
+	padding := strings.Repeat(" ", width-utf8.RuneCountInString(cell))
+	if right {
+		return padding + cell
+	}
+	return cell + padding
+}
+
+// isNumeric reports whether a cell is a number, optionally with a sign,
+// thousands separators, a decimal part or a percent/currency suffix.
+func isNumeric(cell string) bool {
+	cell = strings.TrimSpace(cell)
+	cell = strings.TrimLeft(cell, "+-$€₽")
+	cell = strings.TrimRight(cell, "%$€₽ ")
+	if cell == "" {
+		return false
+	}
+	digits := 0
+	for _, r := range cell {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '.' || r == ',' || r == ' ' || r == '_':
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
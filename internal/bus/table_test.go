@@ -0,0 +1,45 @@
+package bus
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestTable_Text tests the aligned monospace rendering
+func TestTable_Text(t *testing.T) {
+	table := &Table{
+		Title:   "Disks",
+		Headers: []string{"Host", "Used", "Note"},
+		Rows: [][]string{
+			{"nas", "93%", "almost full"},
+			{"web-01", "7%"},
+			{"db", "1,200", strings.Repeat("x", MaxTableCellWidth+5)},
+		},
+	}
+
+	want := "Disks\n" +
+		"Host     Used  Note\n" +
+		"------  -----  --------------------------------\n" +
+		"nas       93%  almost full\n" +
+		"web-01     7%\n" +
+		"db      1,200  " + strings.Repeat("x", MaxTableCellWidth-1) + "…"
+	if got := table.Text(); got != want {
+		t.Errorf("Text() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestTable_MarkdownHTML tests Markdown and HTML rendering with escaping
+func TestTable_MarkdownHTML(t *testing.T) {
+	table := &Table{Headers: []string{"Cmd", "Result"}, Rows: [][]string{{"a|b", "<ok>"}, {"c"}}}
+
+	wantMarkdown := "| Cmd | Result |\n| --- | --- |\n| a\\|b | <ok> |\n| c |  |"
+	if got := table.Markdown(); got != wantMarkdown {
+		t.Errorf("Markdown() = %q, want %q", got, wantMarkdown)
+	}
+
+	wantHTML := "<table><thead><tr><th>Cmd</th><th>Result</th></tr></thead><tbody>" +
+		"<tr><td>a|b</td><td>&lt;ok&gt;</td></tr><tr><td>c</td><td></td></tr></tbody></table>"
+	if got := table.HTML(); got != wantHTML {
+		t.Errorf("HTML() = %q, want %q", got, wantHTML)
+	}
+}
@@ -0,0 +1,213 @@
+// Package capture stores diagnostics of LLM responses per session: finish
+// reason, raw tool calls with the problems found in them and, where the
+// provider supports it, token log probabilities. They show why the model
+// produced malformed tool calls in a specific session.
+package capture
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/transcript"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/textutil"
+)
+
+// Subdirectory is the workspace subdirectory holding the captures
+const Subdirectory = "captures"
+
+// DefaultMaxEntries is the number of responses kept per session
+const DefaultMaxEntries = 50
+
+// maxContentChars limits the stored response text
+const maxContentChars = 4000
+
+// toolCallMarker starts a tool call of the text tool protocol
+const toolCallMarker = "<tool_call"
+
+// ToolCall is a tool call requested by the model.
+type ToolCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`         // Raw arguments as sent by the model
+	Problem   string `json:"problem,omitempty"` // Why the call is malformed
+}
+
+// Entry holds the diagnostics of one LLM response.
+type Entry struct {
+	Time         time.Time          `json:"time"`
+	Iteration    int                `json:"iteration"`
+	Model        string             `json:"model"`
+	FinishReason string             `json:"finish_reason"`
+	Content      string             `json:"content,omitempty"`
+	ToolCalls    []ToolCall         `json:"tool_calls,omitempty"`
+	Problems     []string           `json:"problems,omitempty"` // Problems of the response as a whole
+	Usage        llm.Usage          `json:"usage"`
+	Logprobs     []llm.TokenLogprob `json:"logprobs,omitempty"`
+}
+
+// Malformed reports whether a problem was found in the response.
+func (e Entry) Malformed() bool {
+	if len(e.Problems) > 0 {
+		return true
+	}
+	for _, call := range e.ToolCalls {
+		if call.Problem != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Diagnose returns the diagnostics of a response. known reports whether a
+// tool name was offered to the model (nil skips the check).
+func Diagnose(resp llm.ChatResponse, known func(name string) bool) Entry {
+	entry := Entry{
+		Time:         time.Now(),
+		Model:        resp.Model,
+		FinishReason: string(resp.FinishReason),
+		Content:      textutil.Truncate(resp.Content, maxContentChars),
+		Usage:        resp.Usage,
+		Logprobs:     resp.Logprobs,
+	}
+
+	for _, tc := range resp.ToolCalls {
+		call := ToolCall{Name: tc.Name, Arguments: tc.Arguments}
+		switch {
+		case tc.Name == "":
+			call.Problem = "missing tool name"
+		case known != nil && !known(tc.Name):
+			call.Problem = "unknown tool"
+		case strings.TrimSpace(tc.Arguments) != "" && !json.Valid([]byte(tc.Arguments)):
+			call.Problem = "arguments are not valid JSON"
+		}
+		entry.ToolCalls = append(entry.ToolCalls, call)
+	}
+
+	switch {
+	case resp.FinishReason == llm.FinishReasonLength && len(resp.ToolCalls) > 0:
+		entry.Problems = append(entry.Problems, "tool calls cut off by the token limit")
+	case resp.FinishReason == llm.FinishReasonToolCalls && len(resp.ToolCalls) == 0:
+		entry.Problems = append(entry.Problems, "finish reason is tool_calls without tool calls")
+	case resp.FinishReason == llm.FinishReasonError:
+		entry.Problems = append(entry.Problems, "provider returned no choices")
+	}
+	if strings.Contains(resp.Content, toolCallMarker) {
+		entry.Problems = append(entry.Problems, "tool call markup in the answer was not parsed")
+	}
+	return entry
+}
+
+// Config configures the capture store.
+type Config struct {
+	MaxEntries int  // Responses kept per session (DefaultMaxEntries if 0)
+	All        bool // Keep every response, not only malformed ones
+}
+
+// Store keeps the latest diagnostics of each session in
+// <workspace>/captures/<session>.jsonl.
+type Store struct {
+	dir string
+	cfg Config
+	mu  sync.Mutex
+}
+
+// NewStore creates a capture store in the workspace.
+func NewStore(workspacePath string, cfg Config) *Store {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = DefaultMaxEntries
+	}
+	return &Store{dir: filepath.Join(workspacePath, Subdirectory), cfg: cfg}
+}
+
+// Record stores the diagnostics of a response, keeping the latest
+// MaxEntries of the session. Responses without problems are skipped unless
+// all responses are kept. Returns whether the entry was stored.
+func (s *Store) Record(sessionID string, entry Entry) (bool, error) {
+	if !s.cfg.All && !entry.Malformed() {
+		return false, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.read(sessionID)
+	if err != nil {
+		return false, err
+	}
+	entries = append(entries, entry)
+	if n := len(entries) - s.cfg.MaxEntries; n > 0 {
+		entries = entries[n:]
+	}
+	return true, s.write(sessionID, entries)
+}
+
+// Entries returns the diagnostics stored for a session, oldest first.
+func (s *Store) Entries(sessionID string) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read(sessionID)
+}
+
+// path returns the capture file of a session.
+func (s *Store) path(sessionID string) string {
+	return filepath.Join(s.dir, transcript.SafeFileName(sessionID)+".jsonl")
+}
+
+func (s *Store) read(sessionID string) ([]Entry, error) {
+	f, err := os.Open(s.path(sessionID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open captures: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue // Skip malformed lines
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read captures: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *Store) write(sessionID string, entries []Entry) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create captures directory: %w", err)
+	}
+
+	var b strings.Builder
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal capture: %w", err)
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+
+	path := s.path(sessionID)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write captures: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to save captures: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package capture
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+func TestDiagnose(t *testing.T) {
+	known := func(name string) bool { return name == "read_file" }
+
+	entry := Diagnose(llm.ChatResponse{
+		FinishReason: llm.FinishReasonToolCalls,
+		ToolCalls: []llm.ToolCall{
+			{Name: "read_file", Arguments: `{"path":"a.md"}`},
+			{Name: "read_file", Arguments: `{"path":"a.md"`},
+			{Name: "reed_file", Arguments: `{}`},
+		},
+	}, known)
+	if !entry.Malformed() || len(entry.ToolCalls) != 3 {
+		t.Fatalf("Expected a malformed response with 3 calls, got %+v", entry)
+	}
+	if entry.ToolCalls[0].Problem != "" || entry.ToolCalls[1].Problem != "arguments are not valid JSON" || entry.ToolCalls[2].Problem != "unknown tool" {
+		t.Errorf("Unexpected problems: %+v", entry.ToolCalls)
+	}
+
+	entry = Diagnose(llm.ChatResponse{
+		FinishReason: llm.FinishReasonStop,
+		Content:      `Sure <tool_call>{"name": "read_file"`,
+	}, known)
+	if len(entry.Problems) != 1 || !strings.Contains(entry.Problems[0], "markup") {
+		t.Errorf("Expected unparsed tool call markup, got %+v", entry.Problems)
+	}
+
+	if Diagnose(llm.ChatResponse{FinishReason: llm.FinishReasonStop, Content: "Hello"}, known).Malformed() {
+		t.Error("Expected a plain answer to be well-formed")
+	}
+}
+
+func TestStore_Record(t *testing.T) {
+	store := NewStore(t.TempDir(), Config{MaxEntries: 2})
+	good := Entry{FinishReason: "stop"}
+	bad := Entry{FinishReason: "length", Problems: []string{"tool calls cut off by the token limit"}}
+
+	if stored, err := store.Record("telegram:1", good); err != nil || stored {
+		t.Errorf("Record(well-formed) = %v, %v; want skipped", stored, err)
+	}
+	for i := range 3 {
+		bad.Iteration = i
+		if stored, err := store.Record("telegram:1", bad); err != nil || !stored {
+			t.Fatalf("Record(malformed) = %v, %v", stored, err)
+		}
+	}
+
+	entries, err := store.Entries("telegram:1")
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Iteration != 1 || entries[1].Iteration != 2 {
+		t.Errorf("Expected the latest 2 entries, got %+v", entries)
+	}
+
+	// All responses are kept on request
+	all := NewStore(t.TempDir(), Config{All: true})
+	if stored, _ := all.Record("telegram:1", good); !stored {
+		t.Error("Expected well-formed responses to be kept")
+	}
+	if entries, _ := all.Entries("telegram:2"); entries != nil {
+		t.Errorf("Expected no entries for another session, got %+v", entries)
+	}
+}
@@ -0,0 +1,103 @@
+package channels
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSentKeysTTL is how long a sent idempotency key is remembered
+	DefaultSentKeysTTL = 10 * time.Minute
+
+	// DefaultSentKeysMax limits the number of remembered keys
+	DefaultSentKeysMax = 10000
+)
+
+// SentKeys remembers the idempotency keys of recently sent messages with the
+// IDs of the sent messages, so a connector can skip a message published
+// again after a timeout and still report its ID. Keys are forgotten after
+// the TTL; when the limit is reached the oldest key goes first. It is safe
+// for concurrent use.
+type SentKeys struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	max   int
+	sent  map[string]sentKey
+	order []string // Keys in the order they were added
+	now   func() time.Time
+}
+
+// sentKey is a remembered send.
+type sentKey struct {
+	at        time.Time
+	messageID string
+}
+
+// NewSentKeys creates a SentKeys. Zero values use the defaults.
+func NewSentKeys(ttl time.Duration, max int) *SentKeys {
+	if ttl <= 0 {
+		ttl = DefaultSentKeysTTL
+	}
+	if max <= 0 {
+		max = DefaultSentKeysMax
+	}
+	return &SentKeys{
+		ttl:  ttl,
+		max:  max,
+		sent: make(map[string]sentKey),
+		now:  time.Now,
+	}
+}
+
+// Seen reports whether a message with key was sent within the TTL. An empty
+// key is never seen.
+func (s *SentKeys) Seen(key string) bool {
+	_, ok := s.Lookup(key)
+	return ok
+}
+
+// Lookup returns the ID of the message sent with key within the TTL. The ID
+// is empty if the channel reported none.
+func (s *SentKeys) Lookup(key string) (messageID string, ok bool) {
+	if key == "" {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+	sent, ok := s.sent[key]
+	return sent.messageID, ok
+}
+
+// Add records that a message with key was sent as messageID. Empty keys are
+// ignored.
+func (s *SentKeys) Add(key, messageID string) {
+	if key == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire()
+	if _, ok := s.sent[key]; ok {
+		return
+	}
+	if len(s.order) >= s.max {
+		delete(s.sent, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.sent[key] = sentKey{at: s.now(), messageID: messageID}
+	s.order = append(s.order, key)
+}
+
+// expire forgets the keys older than the TTL. Caller must hold s.mu.
+func (s *SentKeys) expire() {
+	cutoff := s.now().Add(-s.ttl)
+	n := 0
+	for n < len(s.order) && !s.sent[s.order[n]].at.After(cutoff) {
+		delete(s.sent, s.order[n])
+		n++
+	}
+	s.order = s.order[n:]
+}
@@ -0,0 +1,50 @@
+package channels
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSentKeys(t *testing.T) {
+	keys := NewSentKeys(time.Minute, 2)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	keys.now = func() time.Time { return now }
+
+	if keys.Seen("a") {
+		t.Error("Seen() of an unsent key = true")
+	}
+	keys.Add("a", "101")
+	keys.Add("", "102")
+	if !keys.Seen("a") || keys.Seen("") {
+		t.Errorf("Seen() after Add() = %v, empty key %v", keys.Seen("a"), keys.Seen(""))
+	}
+	if id, ok := keys.Lookup("a"); !ok || id != "101" {
+		t.Errorf("Lookup() = %q, %v, want the ID of the sent message", id, ok)
+	}
+
+	// A key sent again keeps the ID of the first send
+	keys.Add("a", "103")
+	if id, _ := keys.Lookup("a"); id != "101" {
+		t.Errorf("Lookup() after a second Add() = %q, want %q", id, "101")
+	}
+
+	// The oldest key goes first when the limit is reached
+	keys.Add("b", "")
+	keys.Add("c", "")
+	for key, want := range map[string]bool{"a": false, "b": true, "c": true} {
+		if got := keys.Seen(key); got != want {
+			t.Errorf("Seen(%q) = %v, want %v", key, got, want)
+		}
+	}
+
+	// Keys are forgotten after the TTL
+	now = now.Add(time.Minute)
+	for _, key := range []string{"b", "c"} {
+		if keys.Seen(key) {
+			t.Errorf("Seen(%q) after the TTL = true", key)
+		}
+	}
+	if len(keys.order) != 0 || len(keys.sent) != 0 {
+		t.Errorf("expired keys kept: %v", keys.order)
+	}
+}
@@ -0,0 +1,123 @@
+package telegram
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/analytics"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/mymmrac/telego"
+)
+
+// adminUsage lists the /admin commands
+const adminUsage = "Usage:\n/admin invite — create a single-use invite code\n/admin invites — list unused invite codes\n/admin tools — tool usage of the last 7 days"
+
+// adminToolDays is the number of days of tool usage shown by /admin tools
+const adminToolDays = 7
+
+// handleAdmin handles /admin commands of the users in admin_users.
+func (uh *UpdateHandler) handleAdmin(msg *telego.Message, userID string) error {
+	if !slices.Contains(uh.connector.cfg.AdminUsers, userID) {
+		uh.logger.WarnCtx(uh.connector.ctx, "admin command blocked - user is not an admin",
+			logger.Field{Key: "user_id", Value: userID})
+		uh.notify(msg.Chat.ID, "Sorry, this command is only available to bot admins.")
+		return nil
+	}
+
+	args := strings.Fields(strings.TrimPrefix(msg.Text, "/admin"))
+	if len(args) == 0 {
+		uh.notify(msg.Chat.ID, adminUsage)
+		return nil
+	}
+
+	switch args[0] {
+	case "invite":
+		uh.createInvite(msg, userID)
+	case "invites":
+		uh.listInvites(msg)
+	case "tools":
+		uh.showToolStats(msg)
+	default:
+		uh.notify(msg.Chat.ID, "Unknown admin command: "+args[0]+"\n\n"+adminUsage)
+	}
+	return nil
+}
+
+// createInvite creates a single-use invite code and sends it with its link.
+func (uh *UpdateHandler) createInvite(msg *telego.Message, userID string) {
+	if uh.connector.invites == nil {
+		uh.notify(msg.Chat.ID, "Invite codes are disabled. Enable [invites] in the configuration.")
+		return
+	}
+
+	inv, err := uh.connector.invites.Create(userID)
+	if err != nil {
+		uh.logger.ErrorCtx(uh.connector.ctx, "failed to create invite code", err,
+			logger.Field{Key: "user_id", Value: userID})
+		uh.notify(msg.Chat.ID, "❌ Failed to create an invite code.")
+		return
+	}
+	uh.logger.InfoCtx(uh.connector.ctx, "invite code created",
+		logger.Field{Key: "user_id", Value: userID},
+		logger.Field{Key: "expires_at", Value: inv.ExpiresAt})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🎟 Invite code: %s\nSingle use, valid until %s\n\n", inv.Code, inv.ExpiresAt.Format("2006-01-02 15:04"))
+	if uh.connector.botUsername != "" {
+		fmt.Fprintf(&b, "Share the link: https://t.me/%s?start=inv_%s\nor ask the user to send: /start %s", uh.connector.botUsername, inv.Code, inv.Code)
+	} else {
+		fmt.Fprintf(&b, "Ask the user to send: /start %s", inv.Code)
+	}
+	uh.notify(msg.Chat.ID, b.String())
+}
+
+// listInvites sends the unused invite codes.
+func (uh *UpdateHandler) listInvites(msg *telego.Message) {
+	if uh.connector.invites == nil {
+		uh.notify(msg.Chat.ID, "Invite codes are disabled. Enable [invites] in the configuration.")
+		return
+	}
+
+	pending := uh.connector.invites.Pending()
+	if len(pending) == 0 {
+		uh.notify(msg.Chat.ID, "No unused invite codes.")
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "🎟 Unused invite codes (%d):\n", len(pending))
+	for _, inv := range pending {
+		fmt.Fprintf(&b, "\n%s — valid until %s", inv.Code, inv.ExpiresAt.Format("2006-01-02 15:04"))
+	}
+	uh.notify(msg.Chat.ID, b.String())
+}
+
+// showToolStats sends the tool usage of the last days: calls, error rate
+// and call duration percentiles.
+func (uh *UpdateHandler) showToolStats(msg *telego.Message) {
+	if uh.connector.analytics == nil {
+		uh.notify(msg.Chat.ID, "Tool statistics are disabled. Enable [analytics] in the configuration.")
+		return
+	}
+
+	days, err := uh.connector.analytics.Days(adminToolDays)
+	if err != nil {
+		uh.logger.ErrorCtx(uh.connector.ctx, "failed to read analytics", err)
+		uh.notify(msg.Chat.ID, "❌ Failed to read the tool statistics.")
+		return
+	}
+	summary := analytics.Merge(days).Summarize()
+	if len(summary.Tools) == 0 {
+		uh.notify(msg.Chat.ID, "No tool calls recorded in the last "+strconv.Itoa(adminToolDays)+" days.")
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "🛠 Tool usage %s:\n", summary.Date)
+	for _, t := range summary.Tools {
+		fmt.Fprintf(&b, "\n%s — %d calls, %.1f%% errors, p50 %s, p95 %s", t.Name, t.Calls, t.ErrorRate*100,
+			time.Duration(t.P50Ms)*time.Millisecond, time.Duration(t.P95Ms)*time.Millisecond)
+	}
+	uh.notify(msg.Chat.ID, b.String())
+}
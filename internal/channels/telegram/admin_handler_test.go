@@ -0,0 +1,100 @@
+package telegram
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/analytics"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/invites"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateHandler_AdminInviteRedeem(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	msgBus := bus.New(10, 10, log)
+	require.NoError(t, msgBus.Start(ctx))
+	t.Cleanup(func() { _ = msgBus.Stop() })
+	inboundCh := msgBus.SubscribeInbound(ctx)
+
+	// Record the texts sent directly through the Bot API
+	var sent []string
+	mockBot := &MockBot{}
+	mockBot.On("SendMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(1).(*telego.SendMessageParams).Text)
+	}).Return(&telego.Message{MessageID: 1}, nil)
+
+	store := invites.NewStore(t.TempDir(), time.Hour)
+	conn := New(config.TelegramConfig{AllowedUsers: []string{"1"}, AdminUsers: []string{"1"}}, log, msgBus)
+	conn.ctx = ctx
+	conn.bot = mockBot
+	conn.botUsername = "nexbot"
+	conn.SetInvites(store)
+
+	// Only admins create codes
+	require.NoError(t, conn.handleUpdate(textUpdate(777, "/admin invite")))
+	assert.Contains(t, sent[len(sent)-1], "only available to bot admins")
+	assert.Empty(t, store.Pending())
+
+	require.NoError(t, conn.handleUpdate(textUpdate(1, "/admin invite")))
+	pending := store.Pending()
+	require.Len(t, pending, 1)
+	code := pending[0].Code
+	assert.Contains(t, sent[len(sent)-1], "https://t.me/nexbot?start=inv_"+code)
+
+	// A wrong code is rejected; the code from the link is redeemed once
+	require.NoError(t, conn.handleUpdate(textUpdate(777, "/start WRONG")))
+	assert.Contains(t, sent[len(sent)-1], "invalid, expired or has already been used")
+	assert.False(t, conn.isAllowedUser("777"))
+
+	require.NoError(t, conn.handleUpdate(textUpdate(777, "/start inv_"+code)))
+	assert.True(t, strings.HasPrefix(sent[len(sent)-1], "✅ Invite accepted"))
+	assert.True(t, conn.isAllowedUser("777"))
+
+	require.NoError(t, conn.handleUpdate(textUpdate(888, "/start "+code)))
+	assert.False(t, conn.isAllowedUser("888"), "invite codes are single-use")
+
+	require.NoError(t, conn.handleUpdate(textUpdate(777, "hello")))
+	select {
+	case msg := <-inboundCh:
+		assert.Equal(t, "hello", msg.Content)
+	case <-time.After(time.Second):
+		t.Fatal("message of the invited user was not published")
+	}
+}
+
+func TestUpdateHandler_AdminTools(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	require.NoError(t, err)
+
+	var sent []string
+	mockBot := &MockBot{}
+	mockBot.On("SendMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(1).(*telego.SendMessageParams).Text)
+	}).Return(&telego.Message{MessageID: 1}, nil)
+
+	conn := New(config.TelegramConfig{AllowedUsers: []string{"1"}, AdminUsers: []string{"1"}}, log, bus.New(10, 10, log))
+	conn.ctx = t.Context()
+	conn.bot = mockBot
+
+	require.NoError(t, conn.handleUpdate(textUpdate(1, "/admin tools")))
+	assert.Contains(t, sent[len(sent)-1], "Enable [analytics]")
+
+	store := analytics.NewStore(t.TempDir(), 0, nil)
+	store.RecordTool("web_fetch", 200*time.Millisecond, nil)
+	store.RecordTool("web_fetch", 2*time.Second, errors.New("timeout"))
+	conn.SetAnalytics(store)
+
+	require.NoError(t, conn.handleUpdate(textUpdate(1, "/admin tools")))
+	assert.Contains(t, sent[len(sent)-1], "web_fetch — 2 calls, 50.0% errors, p50 200ms, p95 2s")
+}
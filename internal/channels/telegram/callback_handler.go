@@ -3,6 +3,7 @@ package telegram
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/aatumaykin/nexbot/internal/bus"
@@ -37,6 +38,11 @@ func (ch *CallbackHandler) Handle(callbackQuery *telego.CallbackQuery) error {
 	// Extract user information
 	userID := fmt.Sprintf("%d", callbackQuery.From.ID)
 
+	// Confirmation of /forget_me, which is available outside the whitelist too
+	if action, requested, ok := parseForgetCallback(callbackQuery.Data); ok && ch.connector.privacy != nil {
+		return ch.handleForgetCallback(callbackQuery, userID, action, requested)
+	}
+
 	// Check whitelist - block unauthorized users
 	if !ch.connector.isAllowedUser(userID) {
 		ch.logger.WarnCtx(ch.connector.ctx, "callback query blocked - user not in whitelist",
@@ -64,12 +70,17 @@ func (ch *CallbackHandler) Handle(callbackQuery *telego.CallbackQuery) error {
 		return nil
 	}
 
+	// Pagination buttons are handled in the channel layer and never reach the agent
+	if token, page, ok := bus.ParsePageCallback(callbackQuery.Data); ok {
+		return ch.handlePageCallback(callbackQuery, userID, token, page)
+	}
+
 	// Use chat ID or message chat ID as session ID with channel prefix
 	var sessionID string
 	if callbackQuery.Message != nil {
 		chat := callbackQuery.Message.GetChat()
 		if chat.ID != 0 {
-			sessionID = fmt.Sprintf("telegram:%d", chat.ID)
+			sessionID = ch.connector.messageSessionID(chat.ID, callbackQuery.Message.GetMessageID())
 		}
 	}
 	if sessionID == "" {
@@ -77,6 +88,25 @@ func (ch *CallbackHandler) Handle(callbackQuery *telego.CallbackQuery) error {
 		sessionID = fmt.Sprintf("telegram:%s", userID)
 	}
 
+	// Consent buttons are answers to onboarding questions and never reach the agent
+	if ch.connector.onboarding != nil &&
+		ch.connector.onboarding.AnswerCallback(ch.connector.ctx, sessionID, telegramIdentity(userID), callbackQuery.Data) {
+		ch.answerCallback(callbackQuery.ID, "")
+		return nil
+	}
+
+	// Form buttons are answers to form questions and never reach the agent
+	if ch.connector.forms != nil && ch.connector.forms.AnswerCallback(ch.connector.ctx, sessionID, callbackQuery.Data) {
+		ch.answerCallback(callbackQuery.ID, "")
+		return nil
+	}
+
+	// Approval buttons answer approval requests and never reach the agent
+	if ch.connector.approvals != nil && ch.connector.approvals.AnswerCallback(ch.connector.ctx, sessionID, callbackQuery.Data) {
+		ch.answerCallback(callbackQuery.ID, "")
+		return nil
+	}
+
 	// Extract metadata from callback query
 	metadata := map[string]any{
 		"callback_query_id": callbackQuery.ID,
@@ -152,3 +182,63 @@ func (ch *CallbackHandler) Handle(callbackQuery *telego.CallbackQuery) error {
 
 	return nil
 }
+
+// handlePageCallback renders the requested page of a paged list by editing
+// the message that holds the navigation keyboard.
+func (ch *CallbackHandler) handlePageCallback(callbackQuery *telego.CallbackQuery, userID, token string, page int) error {
+	list, ok := ch.bus.GetPageStore().Get(token)
+	if !ok || callbackQuery.Message == nil {
+		ch.answerCallback(callbackQuery.ID, "This list has expired.")
+		return nil
+	}
+
+	chat := callbackQuery.Message.GetChat()
+	sessionID := ch.connector.messageSessionID(chat.ID, callbackQuery.Message.GetMessageID())
+	messageID := strconv.Itoa(callbackQuery.Message.GetMessageID())
+
+	content, keyboard := list.Render(page)
+	editMsg := bus.NewEditMessageWithKeyboard(
+		bus.ChannelTypeTelegram,
+		userID,
+		sessionID,
+		messageID,
+		content,
+		keyboard,
+		"",
+		list.Format,
+		map[string]any{"page_token": token, "page": page},
+	)
+
+	if err := ch.bus.PublishOutbound(*editMsg); err != nil {
+		return fmt.Errorf("failed to publish page edit: %w", err)
+	}
+
+	ch.answerCallback(callbackQuery.ID, "")
+
+	ch.logger.DebugCtx(ch.connector.ctx, "paged list navigated",
+		logger.Field{Key: "session_id", Value: sessionID},
+		logger.Field{Key: "page_token", Value: token},
+		logger.Field{Key: "page", Value: page})
+
+	return nil
+}
+
+// answerCallback answers a callback query with an optional notification text.
+func (ch *CallbackHandler) answerCallback(callbackQueryID, text string) {
+	if ch.connector.bot == nil {
+		return
+	}
+
+	timeout := time.Duration(ch.connector.cfg.AnswerCallbackTimeout) * time.Second
+	ctx, cancel := context.WithTimeout(ch.connector.ctx, timeout)
+	defer cancel()
+
+	params := &telego.AnswerCallbackQueryParams{
+		CallbackQueryID: callbackQueryID,
+		Text:            text,
+	}
+	if err := ch.connector.bot.AnswerCallbackQuery(ctx, params); err != nil {
+		ch.logger.ErrorCtx(ch.connector.ctx, "failed to answer callback query", err,
+			logger.Field{Key: "callback_query_id", Value: callbackQueryID})
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/aatumaykin/nexbot/internal/bus"
 	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/forms"
 	"github.com/aatumaykin/nexbot/internal/logger"
 	"github.com/mymmrac/telego"
 	"github.com/stretchr/testify/assert"
@@ -239,3 +240,72 @@ func TestCallbackHandler_Handle_InlineMessage(t *testing.T) {
 
 	_ = mockBus.Stop()
 }
+
+// formPublisher discards form questions.
+type formPublisher struct{}
+
+func (formPublisher) PublishOutbound(bus.OutboundMessage) error { return nil }
+
+func TestCallbackHandler_Handle_FormCallback(t *testing.T) {
+	ctx := context.Background()
+	log, err := logger.New(logger.Config{
+		Level:  "error",
+		Format: "text",
+		Output: "stdout",
+	})
+	require.NoError(t, err)
+	mockBus := bus.New(10, 10, log)
+	require.NoError(t, mockBus.Start(ctx))
+	defer func() { _ = mockBus.Stop() }()
+
+	mockBot := NewMockBotSuccess()
+	mockBot.On("AnswerCallbackQuery", mock.Anything, mock.Anything).Return(nil)
+
+	completed := make(chan *forms.Form, 1)
+	manager := forms.NewManager(forms.Config{Channels: []string{"telegram"}}, formPublisher{},
+		func(ctx context.Context, form *forms.Form) { completed <- form })
+	form, err := manager.Start("telegram:123456789", "cron", nil, []forms.Field{
+		{Name: "tool", Question: "What to do?", Type: forms.FieldChoice, Options: []string{"send_message", "agent"}},
+	})
+	require.NoError(t, err)
+
+	connector := &Connector{
+		cfg: config.TelegramConfig{
+			AllowedUsers:          []string{"123456"},
+			AnswerCallbackTimeout: 5,
+		},
+		ctx:    ctx,
+		logger: log,
+		bus:    mockBus,
+		bot:    mockBot,
+		forms:  manager,
+	}
+	handler := NewCallbackHandler(connector, log, mockBus)
+	inboundCh := mockBus.SubscribeInbound(ctx)
+
+	err = handler.Handle(&telego.CallbackQuery{
+		ID:   "callback_123",
+		From: telego.User{ID: 123456},
+		Data: forms.CallbackData(form.Token, "1"),
+		Message: &telego.Message{
+			MessageID: 123,
+			Chat:      telego.Chat{ID: 123456789, Type: "private"},
+		},
+	})
+	assert.NoError(t, err)
+	mockBot.AssertCalled(t, "AnswerCallbackQuery", mock.Anything, mock.Anything)
+
+	select {
+	case done := <-completed:
+		assert.Equal(t, "agent", done.Args["tool"])
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for form completion")
+	}
+
+	// Form answers never reach the agent
+	select {
+	case msg := <-inboundCh:
+		t.Fatalf("Unexpected inbound message: %+v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,223 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/mymmrac/telego"
+	telegoapi "github.com/mymmrac/telego/telegoapi"
+)
+
+// Administrator rights checked before changing a chat
+const (
+	rightPinMessages = "can_pin_messages"
+	rightChangeInfo  = "can_change_info"
+)
+
+// rememberSent records the last message the bot sent to a chat, so
+// pin_message can pin it without knowing its ID.
+func (c *Connector) rememberSent(chatID int64, sent *telego.Message) {
+	if sent == nil || sent.MessageID == 0 {
+		return
+	}
+	if c.lastSent == nil {
+		c.lastSent = make(map[int64]int)
+	}
+	c.lastSent[chatID] = sent.MessageID
+}
+
+// pinMessage pins a message; without a message ID the last message sent
+// by the bot to the chat is pinned.
+func (c *Connector) pinMessage(msg bus.OutboundMessage, chatID int64) {
+	ctx := msg.LogContext(c.ctx)
+	messageID, err := c.adminMessageID(msg, chatID)
+	if err != nil {
+		c.logger.ErrorCtx(ctx, "failed to pin message", err,
+			logger.Field{Key: "chat_id", Value: chatID})
+		c.publishResult(msg, chatID, false, err)
+		return
+	}
+
+	sendCtx, cancel := c.getSendTimeout()
+	defer cancel()
+
+	// Pinning in private chats needs no rights
+	if !isPrivateChat(chatID) {
+		if err := c.requireAdminRight(sendCtx, chatID, rightPinMessages); err != nil {
+			c.logger.WarnCtx(ctx, "pin message rejected",
+				logger.Field{Key: "chat_id", Value: chatID},
+				logger.Field{Key: "error", Value: err.Error()})
+			c.publishResult(msg, chatID, false, err)
+			return
+		}
+	}
+
+	params := telego.PinChatMessageParams{
+		ChatID:              telego.ChatID{ID: chatID},
+		MessageID:           messageID,
+		DisableNotification: c.cfg.QuietMode,
+	}
+	if err := c.bot.PinChatMessage(sendCtx, &params); err != nil {
+		c.logger.ErrorCtx(ctx, "failed to pin message", err,
+			logger.Field{Key: "chat_id", Value: chatID},
+			logger.Field{Key: "message_id", Value: messageID})
+		c.publishResult(msg, chatID, false, err)
+		return
+	}
+
+	c.logger.InfoCtx(ctx, "message pinned",
+		logger.Field{Key: "chat_id", Value: chatID},
+		logger.Field{Key: "message_id", Value: messageID})
+	c.publishResult(msg, chatID, true, nil)
+}
+
+// unpinMessage unpins a message; without a message ID the most recently
+// pinned message is unpinned.
+func (c *Connector) unpinMessage(msg bus.OutboundMessage, chatID int64) {
+	ctx := msg.LogContext(c.ctx)
+	var messageID int
+	if msg.MessageID != "" {
+		id, err := strconv.Atoi(msg.MessageID)
+		if err != nil {
+			c.logger.ErrorCtx(ctx, "invalid message ID format", err,
+				logger.Field{Key: "message_id", Value: msg.MessageID})
+			c.publishResult(msg, chatID, false, fmt.Errorf("invalid message ID format: %w", err))
+			return
+		}
+		messageID = id
+	}
+
+	sendCtx, cancel := c.getSendTimeout()
+	defer cancel()
+
+	if !isPrivateChat(chatID) {
+		if err := c.requireAdminRight(sendCtx, chatID, rightPinMessages); err != nil {
+			c.logger.WarnCtx(ctx, "unpin message rejected",
+				logger.Field{Key: "chat_id", Value: chatID},
+				logger.Field{Key: "error", Value: err.Error()})
+			c.publishResult(msg, chatID, false, err)
+			return
+		}
+	}
+
+	params := telego.UnpinChatMessageParams{
+		ChatID:    telego.ChatID{ID: chatID},
+		MessageID: messageID,
+	}
+	if err := c.bot.UnpinChatMessage(sendCtx, &params); err != nil {
+		c.logger.ErrorCtx(ctx, "failed to unpin message", err,
+			logger.Field{Key: "chat_id", Value: chatID},
+			logger.Field{Key: "message_id", Value: messageID})
+		c.publishResult(msg, chatID, false, err)
+		return
+	}
+
+	c.publishResult(msg, chatID, true, nil)
+}
+
+// setChatTitle renames a group or channel to the message content.
+func (c *Connector) setChatTitle(msg bus.OutboundMessage, chatID int64) {
+	ctx := msg.LogContext(c.ctx)
+	if msg.Content == "" {
+		c.logger.ErrorCtx(ctx, "title is required for set_chat_title", nil)
+		c.publishResult(msg, chatID, false, fmt.Errorf("title is required for set_chat_title"))
+		return
+	}
+
+	sendCtx, cancel := c.getSendTimeout()
+	defer cancel()
+
+	if err := c.requireAdminRight(sendCtx, chatID, rightChangeInfo); err != nil {
+		c.logger.WarnCtx(ctx, "set chat title rejected",
+			logger.Field{Key: "chat_id", Value: chatID},
+			logger.Field{Key: "error", Value: err.Error()})
+		c.publishResult(msg, chatID, false, err)
+		return
+	}
+
+	params := telego.SetChatTitleParams{
+		ChatID: telego.ChatID{ID: chatID},
+		Title:  msg.Content,
+	}
+	if err := c.bot.SetChatTitle(sendCtx, &params); err != nil {
+		c.logger.ErrorCtx(ctx, "failed to set chat title", err,
+			logger.Field{Key: "chat_id", Value: chatID})
+		c.publishResult(msg, chatID, false, err)
+		return
+	}
+
+	c.logger.InfoCtx(ctx, "chat title changed",
+		logger.Field{Key: "chat_id", Value: chatID},
+		logger.Field{Key: "title", Value: msg.Content})
+	c.publishResult(msg, chatID, true, nil)
+}
+
+// adminMessageID returns the message a pin applies to: the requested one
+// or the last message sent by the bot to the chat.
+func (c *Connector) adminMessageID(msg bus.OutboundMessage, chatID int64) (int, error) {
+	if msg.MessageID == "" {
+		id, ok := c.lastSent[chatID]
+		if !ok {
+			return 0, fmt.Errorf("no message sent to this chat yet: message ID is required")
+		}
+		return id, nil
+	}
+	id, err := strconv.Atoi(msg.MessageID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid message ID format: %w", err)
+	}
+	return id, nil
+}
+
+// requireAdminRight checks that the bot is the owner of the chat or an
+// administrator with the right. A missing right is reported as a Bot API
+// error, so the agent gets it with the other error details.
+func (c *Connector) requireAdminRight(ctx context.Context, chatID int64, right string) error {
+	if c.botID == 0 {
+		me, err := c.bot.GetMe(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get bot info: %w", err)
+		}
+		c.botID = me.ID
+	}
+
+	member, err := c.bot.GetChatMember(ctx, &telego.GetChatMemberParams{
+		ChatID: telego.ChatID{ID: chatID},
+		UserID: c.botID,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch m := member.(type) {
+	case *telego.ChatMemberOwner:
+		return nil
+	case *telego.ChatMemberAdministrator:
+		allowed := m.CanChangeInfo
+		if right == rightPinMessages {
+			// Channel administrators pin with the right to edit messages
+			allowed = m.CanPinMessages || m.CanEditMessages
+		}
+		if allowed {
+			return nil
+		}
+		return &telegoapi.Error{
+			ErrorCode:   403,
+			Description: fmt.Sprintf("Forbidden: the bot is an administrator without the %s right", right),
+		}
+	default:
+		return &telegoapi.Error{
+			ErrorCode:   403,
+			Description: fmt.Sprintf("Forbidden: the bot is not an administrator of this chat (needs %s)", right),
+		}
+	}
+}
+
+// isPrivateChat reports whether a chat ID belongs to a private chat;
+// groups and channels have negative IDs.
+func isPrivateChat(chatID int64) bool {
+	return chatID > 0
+}
@@ -0,0 +1,101 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/mymmrac/telego"
+	telegoapi "github.com/mymmrac/telego/telegoapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testGroupID = -100123
+
+func TestConnector_PinMessage_LastSent(t *testing.T) {
+	mockBot := &MockBot{}
+	mockBot.On("SendMessage", mock.Anything, mock.Anything).Return(&telego.Message{MessageID: 77}, nil)
+	mockBot.On("GetMe", mock.Anything).Return(&telego.User{ID: 1}, nil)
+	mockBot.On("GetChatMember", mock.Anything, mock.Anything).Return(&telego.ChatMemberAdministrator{
+		Status:         telego.MemberStatusAdministrator,
+		CanPinMessages: true,
+	}, nil)
+	var pinned int
+	mockBot.On("PinChatMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		pinned = args.Get(1).(*telego.PinChatMessageParams).MessageID
+	}).Return(nil)
+	c := newStreamTestConnector(t, mockBot)
+
+	summary := bus.NewOutboundMessage(bus.ChannelTypeTelegram, "1", "telegram:-100123", "Daily summary", "corr", bus.FormatTypePlain, nil)
+	c.sendTextMessage(*summary, testGroupID)
+	c.pinMessage(*bus.NewPinMessage(bus.ChannelTypeTelegram, "1", "telegram:-100123", "", "corr2", nil), testGroupID)
+
+	assert.Equal(t, 77, pinned, "the summary sent last is pinned")
+}
+
+func TestConnector_ChatAdmin_RequiresRights(t *testing.T) {
+	tests := []struct {
+		name   string
+		member telego.ChatMember
+		send   func(c *Connector)
+		method string
+	}{
+		{
+			name:   "pin as a regular member",
+			member: &telego.ChatMemberMember{Status: telego.MemberStatusMember},
+			send: func(c *Connector) {
+				c.pinMessage(*bus.NewPinMessage(bus.ChannelTypeTelegram, "1", "telegram:-100123", "5", "corr", nil), testGroupID)
+			},
+			method: "PinChatMessage",
+		},
+		{
+			name:   "set title without can_change_info",
+			member: &telego.ChatMemberAdministrator{Status: telego.MemberStatusAdministrator, CanPinMessages: true},
+			send: func(c *Connector) {
+				c.setChatTitle(*bus.NewSetChatTitleMessage(bus.ChannelTypeTelegram, "1", "telegram:-100123", "Weekly", "corr", nil), testGroupID)
+			},
+			method: "SetChatTitle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBot := &MockBot{}
+			mockBot.On("GetMe", mock.Anything).Return(&telego.User{ID: 1}, nil)
+			mockBot.On("GetChatMember", mock.Anything, mock.Anything).Return(tt.member, nil)
+			c := newStreamTestConnector(t, mockBot)
+
+			tt.send(c)
+			mockBot.AssertNotCalled(t, tt.method, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestConnector_RequireAdminRight(t *testing.T) {
+	mockBot := &MockBot{}
+	mockBot.On("GetChatMember", mock.Anything, mock.Anything).Return(&telego.ChatMemberMember{Status: telego.MemberStatusMember}, nil).Once()
+	mockBot.On("GetChatMember", mock.Anything, mock.Anything).Return(&telego.ChatMemberOwner{Status: telego.MemberStatusCreator}, nil).Once()
+	c := newStreamTestConnector(t, mockBot)
+	c.botID = 1
+
+	err := c.requireAdminRight(t.Context(), testGroupID, rightChangeInfo)
+	apiErr, ok := err.(*telegoapi.Error)
+	require.True(t, ok, "error = %v, want a Bot API error", err)
+	assert.Equal(t, 403, apiErr.ErrorCode)
+	assert.Contains(t, apiErr.Description, rightChangeInfo)
+
+	assert.NoError(t, c.requireAdminRight(t.Context(), testGroupID, rightChangeInfo), "the owner has all rights")
+}
+
+func TestConnector_PinMessage_PrivateChat(t *testing.T) {
+	mockBot := &MockBot{}
+	mockBot.On("PinChatMessage", mock.Anything, mock.Anything).Return(nil)
+	c := newStreamTestConnector(t, mockBot)
+
+	c.pinMessage(*bus.NewPinMessage(bus.ChannelTypeTelegram, "42", "telegram:42", "9", "corr", nil), 42)
+
+	// No rights are checked in private chats
+	mockBot.AssertNotCalled(t, "GetChatMember", mock.Anything, mock.Anything)
+	mockBot.AssertNumberOfCalls(t, "PinChatMessage", 1)
+}
@@ -3,7 +3,7 @@
 // supporting basic chat functionality without tools.
 //
 // Features:
-//   - Long polling for receiving updates
+//   - Long polling for receiving updates, reconnecting after network failures
 //   - Whitelist-based user authorization
 //   - Graceful shutdown handling
 //   - Integration with internal message bus
@@ -12,12 +12,25 @@ package telegram
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"slices"
-	"strings"
+	"time"
 
+	"github.com/aatumaykin/nexbot/internal/analytics"
+	"github.com/aatumaykin/nexbot/internal/approval"
 	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/channels"
 	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/forms"
+	"github.com/aatumaykin/nexbot/internal/invites"
 	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/media"
+	"github.com/aatumaykin/nexbot/internal/messages"
+	"github.com/aatumaykin/nexbot/internal/onboarding"
+	"github.com/aatumaykin/nexbot/internal/privacy"
+	"github.com/aatumaykin/nexbot/internal/stt"
+	"github.com/aatumaykin/nexbot/internal/throttle"
+	"github.com/aatumaykin/nexbot/internal/upload"
 	"github.com/aatumaykin/nexbot/internal/version"
 	"github.com/mymmrac/telego"
 )
@@ -36,6 +49,25 @@ type Connector struct {
 	typingManager   *TypingManager
 	longPollManager *LongPollManager
 	updateHandler   *UpdateHandler
+	httpClient      *http.Client
+	limiter         *throttle.Limiter
+	forms           *forms.Manager
+	approvals       *approval.Manager
+	onboarding      *onboarding.Flow
+	invites         *invites.Store
+	analytics       *analytics.Store
+	privacy         *privacy.Service
+	uploads         *upload.Store
+	media           *media.Policy
+	images          media.ImageOptions
+	voice           *voiceConfig
+	streams         map[string]*streamDraft    // Drafts of streamed answers by stream ID
+	progress        map[string]*progressStatus // Tool progress messages by session ID
+	lastSent        map[int64]int              // Last message sent by the bot by chat ID (pin_message default)
+	sentKeys        *channels.SentKeys         // Idempotency keys of recently sent messages
+	threads         *threadTracker             // Reply chains of group chats (group_threads)
+	botID           int64
+	botUsername     string
 }
 
 // GetCommandHandler returns the command handler instance.
@@ -53,12 +85,98 @@ func New(cfg config.TelegramConfig, log *logger.Logger, msgBus *bus.MessageBus)
 		typingManager:   NewTypingManager(nil, log),
 		longPollManager: NewLongPollManager(nil, nil, log),
 		updateHandler:   NewUpdateHandler(nil, log, msgBus),
+		sentKeys:        channels.NewSentKeys(0, 0),
+		threads:         newThreadTracker(0),
 	}
 	conn.longPollManager.connector = conn
 	conn.updateHandler.connector = conn
+	conn.updateHandler.callbackHandler.connector = conn
 	return conn
 }
 
+// SetHTTPClient sets the HTTP client used for Bot API requests (proxy, CA bundle, DNS).
+// Must be called before Start; by default telego's fasthttp client is used.
+func (c *Connector) SetHTTPClient(client *http.Client) {
+	c.httpClient = client
+}
+
+// SetLimiter sets the per-user limiter applied to inbound messages (commands are not limited).
+func (c *Connector) SetLimiter(limiter *throttle.Limiter) {
+	c.limiter = limiter
+}
+
+// SetApprovals sets the approval manager that receives Approve/Reject buttons.
+func (c *Connector) SetApprovals(manager *approval.Manager) {
+	c.approvals = manager
+}
+
+// SetForms sets the form manager that receives answers to form questions.
+func (c *Connector) SetForms(manager *forms.Manager) {
+	c.forms = manager
+}
+
+// SetOnboarding enables /start deep links, onboarding and consent questions
+// in private chats. Users who joined with a valid invite code are allowed
+// outside the whitelist.
+func (c *Connector) SetOnboarding(flow *onboarding.Flow) {
+	c.onboarding = flow
+}
+
+// SetInvites enables single-use invite codes: admins create them with
+// "/admin invite", new users redeem them with /start and stay allowed
+// outside the whitelist.
+func (c *Connector) SetInvites(store *invites.Store) {
+	c.invites = store
+}
+
+// SetAnalytics enables "/admin tools": admins see the tool usage
+// statistics of the last days.
+func (c *Connector) SetAnalytics(store *analytics.Store) {
+	c.analytics = store
+}
+
+// SetPrivacy enables /forget_me: users export and delete all data the bot
+// stores about them. Must be called before Start, so the command is
+// registered in the bot menu.
+func (c *Connector) SetPrivacy(service *privacy.Service) {
+	c.privacy = service
+}
+
+// SetUploads enables the /upload flow that stores documents from users in the workspace.
+// Must be called before Start, so the command is registered in the bot menu.
+func (c *Connector) SetUploads(store *upload.Store) {
+	c.uploads = store
+}
+
+// SetMedia sets the policy that checks documents and voice messages
+// downloaded from users; without it the default size limits apply and
+// executables are rejected without quarantine.
+func (c *Connector) SetMedia(policy *media.Policy) {
+	c.media = policy
+}
+
+// SetImageOptions sets the limits of local images sent as photos: larger
+// images are scaled down, converted to JPEG or PNG and compressed before
+// sending. Zero options use the Telegram limits.
+func (c *Connector) SetImageOptions(opts media.ImageOptions) {
+	c.images = opts
+}
+
+// mediaPolicy returns the policy for downloads from users.
+func (c *Connector) mediaPolicy() *media.Policy {
+	if c.media == nil {
+		return media.New(media.Config{})
+	}
+	return c.media
+}
+
+// SetSpeechToText enables voice messages: they are transcribed with the
+// provider and handled like text messages. language is the expected
+// language (empty detects it); longer messages than maxDuration are rejected.
+func (c *Connector) SetSpeechToText(provider stt.Provider, language string, maxDuration time.Duration) {
+	c.voice = &voiceConfig{provider: provider, language: language, maxDuration: maxDuration}
+}
+
 // Start initializes the Telegram bot and starts listening for updates
 func (c *Connector) Start(ctx context.Context) error {
 	c.logger.Info("starting telegram connector",
@@ -75,7 +193,11 @@ func (c *Connector) Start(ctx context.Context) error {
 	}
 
 	// Initialize Telegram bot
-	bot, err := telego.NewBot(c.cfg.Token)
+	var opts []telego.BotOption
+	if c.httpClient != nil {
+		opts = append(opts, telego.WithHTTPClient(c.httpClient))
+	}
+	bot, err := telego.NewBot(c.cfg.Token, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to initialize telegram bot: %w", err)
 	}
@@ -93,6 +215,11 @@ func (c *Connector) Start(ctx context.Context) error {
 	// Update long poll manager with bot and context
 	c.longPollManager.SetContext(c.ctx)
 	c.longPollManager.bot = c.bot
+	maxBackoff := time.Duration(c.cfg.ReconnectMaxBackoffSeconds) * time.Second
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultReconnectMaxBackoff
+	}
+	c.longPollManager.SetReconnect(maxBackoff, time.Duration(c.cfg.NotifyReconnectAfterSeconds)*time.Second)
 
 	// Get bot info
 	botUser, err := c.bot.GetMe(c.ctx)
@@ -100,6 +227,9 @@ func (c *Connector) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to get bot info: %w", err)
 	}
 
+	c.botID = botUser.ID
+	c.botUsername = botUser.Username
+
 	c.logger.Info("telegram bot initialized",
 		logger.Field{Key: "bot_id", Value: botUser.ID},
 		logger.Field{Key: "username", Value: botUser.Username})
@@ -149,6 +279,16 @@ func (c *Connector) Stop() error {
 	return nil
 }
 
+// CheckHealth reports whether the connector is alive: long polling runs and
+// update handling hasn't stalled for longer than maxStall. A disabled
+// connector is always healthy.
+func (c *Connector) CheckHealth(maxStall time.Duration) error {
+	if !c.cfg.Enabled {
+		return nil
+	}
+	return c.longPollManager.CheckHealth(maxStall)
+}
+
 // validateConfig validates the Telegram configuration
 func (c *Connector) validateConfig() error {
 	if c.cfg.Token == "" {
@@ -168,10 +308,30 @@ func (c *Connector) registerCommands() error {
 		Commands: []telego.BotCommand{
 			{Command: "new", Description: "Start a new session (clear history)"},
 			{Command: "status", Description: "Show session and bot status"},
+			{Command: "sessions", Description: "List sessions with titles and last activity"},
+			{Command: "save_as", Description: "Save the current session under a name"},
+			{Command: "resume", Description: "Continue a named session in this chat"},
+			{Command: "context", Description: "Switch between named contexts of this chat"},
+			{Command: "sampling", Description: "Override sampling parameters (temperature, top_p...) of this chat"},
+			{Command: "project", Description: "Attach this chat to a project with a shared brief"},
+			{Command: "followups", Description: "Allow or forbid proactive follow-ups"},
+			{Command: "rollback", Description: "Undo the file changes of the agent's last turn"},
 			{Command: "restart", Description: "Restart bot"},
 			{Command: "secret", Description: "Manage secrets (passwords, tokens)"},
+			{Command: "export", Description: "Export session transcript (markdown or html)"},
+			{Command: "feedback", Description: "Rate the last answer (+, - or 1-5) with an optional comment"},
+			{Command: "debate", Description: "Answer a question through a debate of agent personas"},
+			{Command: "dryrun", Description: "Run a request with changes reported instead of made"},
 		},
 	}
+	if c.privacy != nil {
+		commands.Commands = append(commands.Commands,
+			telego.BotCommand{Command: "forget_me", Description: "Export and delete all data stored about you"})
+	}
+	if c.uploads != nil {
+		commands.Commands = append(commands.Commands,
+			telego.BotCommand{Command: "upload", Description: "Save a document to the workspace (send it with /upload <path>)"})
+	}
 
 	err := c.bot.SetMyCommands(c.ctx, commands)
 	if err != nil {
@@ -190,19 +350,40 @@ func (c *Connector) isAllowedUser(userID string) bool {
 		return true
 	}
 
-	// Check if user ID is in the whitelist
-	return slices.Contains(c.cfg.AllowedUsers, userID)
+	// Check if user ID is in the whitelist or joined with an invite code
+	return slices.Contains(c.cfg.AllowedUsers, userID) ||
+		c.invites != nil && c.invites.Allowed(userID) ||
+		c.onboarding != nil && c.onboarding.Invited(telegramIdentity(userID))
 }
 
 // sendStartupMessage sends a startup message to all allowed users
 func (c *Connector) sendStartupMessage() error {
+	return c.sendToAllowedUsers(version.FormatStartupMessage(), "startup message")
+}
+
+// notifyReconnected tells the allowed users that the bot is reachable again
+// after an outage.
+func (c *Connector) notifyReconnected(downtime time.Duration) {
+	_ = c.sendToAllowedUsers(messages.FormatReconnectMessage(downtime), "reconnect notice")
+}
+
+// publishEvent publishes a connector event on the message bus.
+func (c *Connector) publishEvent(event *bus.Event) {
+	if err := c.bus.PublishEvent(*event); err != nil {
+		c.logger.WarnCtx(c.ctx, "failed to publish event",
+			logger.Field{Key: "event_type", Value: event.Type},
+			logger.Field{Key: "error", Value: err.Error()})
+	}
+}
+
+// sendToAllowedUsers sends a service message to the private chat of every
+// allowed user; kind names the message in logs.
+func (c *Connector) sendToAllowedUsers(message, kind string) error {
 	if len(c.cfg.AllowedUsers) == 0 {
-		c.logger.Info("no allowed users configured, skipping startup message")
+		c.logger.Info("no allowed users configured, skipping " + kind)
 		return nil
 	}
 
-	message := version.FormatStartupMessage()
-
 	for _, userID := range c.cfg.AllowedUsers {
 		var chatID int64
 		_, err := fmt.Sscanf(userID, "%d", &chatID)
@@ -219,12 +400,12 @@ func (c *Connector) sendStartupMessage() error {
 
 		_, err = c.bot.SendMessage(c.ctx, &params)
 		if err != nil {
-			c.logger.ErrorCtx(c.ctx, "failed to send startup message", err,
+			c.logger.ErrorCtx(c.ctx, "failed to send "+kind, err,
 				logger.Field{Key: "user_id", Value: userID})
 			continue
 		}
 
-		c.logger.InfoCtx(c.ctx, "startup message sent",
+		c.logger.InfoCtx(c.ctx, kind+" sent",
 			logger.Field{Key: "user_id", Value: userID})
 	}
 
@@ -251,39 +432,48 @@ func (c *Connector) handleOutbound() {
 				continue
 			}
 
+			// Log lines of the message carry its correlation and session IDs
+			ctx := msg.LogContext(c.ctx)
+
 			// Extract chat ID from session ID
 			chatID, err := c.extractChatID(msg.SessionID)
 			if err != nil {
-				c.logger.ErrorCtx(c.ctx, "failed to extract chat ID", err,
-					logger.Field{Key: "session_id", Value: msg.SessionID},
-					logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+				c.logger.ErrorCtx(ctx, "failed to extract chat ID", err)
 				continue
 			}
 
 			// Send message to Telegram
 			if c.bot == nil {
-				c.logger.WarnCtx(c.ctx, "bot is nil, skipping message send")
+				c.logger.WarnCtx(ctx, "bot is nil, skipping message send")
+				continue
+			}
+
+			// A message published again after a timeout is not sent twice
+			if c.skipDuplicate(msg, chatID) {
 				continue
 			}
 
 			// Route message based on type
 			switch msg.Type {
 			case bus.MessageTypeText:
+				if c.finishStream(msg, chatID) {
+					continue
+				}
 				c.sendTextMessage(msg, chatID)
+			case bus.MessageTypeStream:
+				c.sendStreamMessage(msg, chatID)
 			case bus.MessageTypeEdit:
 				if !c.cfg.EnableInlineUpdates {
-					c.logger.WarnCtx(c.ctx, "inline updates disabled in config",
-						logger.Field{Key: "message_type", Value: msg.Type},
-						logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+					c.logger.WarnCtx(ctx, "inline updates disabled in config",
+						logger.Field{Key: "message_type", Value: msg.Type})
 					c.publishResult(msg, chatID, false, fmt.Errorf("inline updates disabled"))
 					continue
 				}
 				c.editMessage(msg, chatID)
 			case bus.MessageTypeDelete:
 				if !c.cfg.EnableInlineUpdates {
-					c.logger.WarnCtx(c.ctx, "inline updates disabled in config",
-						logger.Field{Key: "message_type", Value: msg.Type},
-						logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+					c.logger.WarnCtx(ctx, "inline updates disabled in config",
+						logger.Field{Key: "message_type", Value: msg.Type})
 					c.publishResult(msg, chatID, false, fmt.Errorf("inline updates disabled"))
 					continue
 				}
@@ -292,10 +482,19 @@ func (c *Connector) handleOutbound() {
 				c.sendPhoto(msg, chatID)
 			case bus.MessageTypeDocument:
 				c.sendDocument(msg, chatID)
+			case bus.MessageTypeSticker:
+				c.sendSticker(msg, chatID)
+			case bus.MessageTypeAnimation:
+				c.sendAnimation(msg, chatID)
+			case bus.MessageTypePin:
+				c.pinMessage(msg, chatID)
+			case bus.MessageTypeUnpin:
+				c.unpinMessage(msg, chatID)
+			case bus.MessageTypeSetChatTitle:
+				c.setChatTitle(msg, chatID)
 			default:
-				c.logger.WarnCtx(c.ctx, "unknown message type",
-					logger.Field{Key: "message_type", Value: msg.Type},
-					logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+				c.logger.WarnCtx(ctx, "unknown message type",
+					logger.Field{Key: "message_type", Value: msg.Type})
 				c.publishResult(msg, chatID, false, fmt.Errorf("unknown message type: %s", msg.Type))
 			}
 		}
@@ -303,29 +502,10 @@ func (c *Connector) handleOutbound() {
 }
 
 // extractChatID extracts chat ID from session ID
-// Format: "telegram:chat_id"
+// Format: "telegram:chat_id" or "telegram:chat_id:root_message_id" (group thread)
 func (c *Connector) extractChatID(sessionID string) (int64, error) {
-	parts := strings.Split(sessionID, ":")
-	if len(parts) != 2 {
-		return 0, fmt.Errorf("invalid session ID format: expected 'channel:chat_id', got: %s", sessionID)
-	}
-
-	channel := parts[0]
-	chatIDStr := parts[1]
-
-	// Verify channel matches telegram
-	if channel != string(bus.ChannelTypeTelegram) {
-		return 0, fmt.Errorf("session ID channel mismatch: expected %s, got %s",
-			bus.ChannelTypeTelegram, channel)
-	}
-
-	var chatID int64
-	_, err := fmt.Sscanf(chatIDStr, "%d", &chatID)
-	if err != nil {
-		return 0, fmt.Errorf("invalid chat ID in session ID: %w", err)
-	}
-
-	return chatID, nil
+	chatID, _, err := parseSessionID(sessionID)
+	return chatID, err
 }
 
 // handleEvents processes lifecycle events from the message bus
@@ -355,6 +535,9 @@ func (c *Connector) handleEvents() {
 			case bus.EventTypeProcessingEnd:
 				// Stop typing indicator
 				c.typingManager.Stop(event)
+				c.clearProgress(event)
+			case bus.EventTypeToolProgress:
+				c.showProgress(event)
 			}
 		}
 	}
@@ -13,6 +13,7 @@ import (
 	"github.com/aatumaykin/nexbot/internal/bus"
 	"github.com/aatumaykin/nexbot/internal/config"
 	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/throttle"
 )
 
 // TestConnector_RegisterCommands_WithMock tests registering bot commands with a mock bot.
@@ -359,3 +360,54 @@ func TestConnector_Start_WithMockGetMeError(t *testing.T) {
 	assert.Contains(t, err.Error(), "API error")
 	mockBot.AssertExpectations(t)
 }
+
+// TestConnector_handleUpdate_Throttled tests that throttled messages get a reply and are not published.
+func TestConnector_handleUpdate_Throttled(t *testing.T) {
+	log, _ := logger.New(logger.Config{
+		Level:  "error",
+		Format: "text",
+		Output: "stdout",
+	})
+
+	ctx := t.Context()
+	msgBus := bus.New(100, 10, log)
+	if err := msgBus.Start(ctx); err != nil {
+		t.Fatalf("Failed to start message bus: %v", err)
+	}
+	defer func() { _ = msgBus.Stop() }()
+	inboundCh := msgBus.SubscribeInbound(ctx)
+
+	conn := New(config.TelegramConfig{}, log, msgBus)
+	conn.ctx = ctx
+	mockBot := new(MockBot)
+	mockBot.On("SendMessage", mock.Anything, mock.MatchedBy(func(params *telego.SendMessageParams) bool {
+		return params.ChatID.ID == 987654321 && params.Text == throttle.DefaultBusyMessage
+	})).Return(&telego.Message{MessageID: 2}, nil).Once()
+	conn.bot = mockBot
+	conn.SetLimiter(throttle.New(throttle.Config{Default: throttle.Rule{MaxConcurrent: 1}}))
+
+	update := telego.Update{
+		Message: &telego.Message{
+			MessageID: 1,
+			From:      &telego.User{ID: 123456789},
+			Chat:      telego.Chat{ID: 987654321, Type: "private"},
+			Text:      "Hello",
+		},
+	}
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, conn.handleUpdate(update))
+	}
+
+	select {
+	case msg := <-inboundCh:
+		assert.Equal(t, true, msg.Metadata[throttle.MetadataKey])
+	case <-time.After(time.Second):
+		t.Fatal("Expected the first message to be published")
+	}
+	select {
+	case msg := <-inboundCh:
+		t.Fatalf("Expected the second message to be throttled, got %q", msg.Content)
+	case <-time.After(100 * time.Millisecond):
+	}
+	mockBot.AssertExpectations(t)
+}
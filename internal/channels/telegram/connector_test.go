@@ -318,6 +318,85 @@ func TestConnector_handleUpdate_Success(t *testing.T) {
 	})
 }
 
+// TestConnector_handleUpdate_Debate tests that /debate is published as a debate question
+func TestConnector_handleUpdate_Debate(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+
+	msgBus := bus.New(100, 10, log)
+	ctx := t.Context()
+	require.NoError(t, msgBus.Start(ctx))
+	t.Cleanup(func() {
+		require.NoError(t, msgBus.Stop())
+	})
+
+	conn := New(config.TelegramConfig{AllowedUsers: []string{"123456789"}}, log, msgBus)
+	conn.ctx = ctx
+	inboundCh := msgBus.SubscribeInbound(ctx)
+
+	update := telego.Update{
+		Message: &telego.Message{
+			MessageID: 1,
+			From:      &telego.User{ID: 123456789},
+			Chat:      telego.Chat{ID: 987654321, Type: "private"},
+			Text:      "/debate Postgres or SQLite for a blog?",
+		},
+	}
+	require.NoError(t, conn.handleUpdate(update))
+
+	select {
+	case msg := <-inboundCh:
+		if msg.Content != "Postgres or SQLite for a blog?" {
+			t.Errorf("Expected the question as content, got '%s'", msg.Content)
+		}
+		if msg.Metadata["debate"] != true {
+			t.Errorf("Expected debate metadata, got %v", msg.Metadata["debate"])
+		}
+		if _, ok := msg.Metadata["command"]; ok {
+			t.Errorf("Expected no command metadata, got %v", msg.Metadata["command"])
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("Timeout waiting for inbound message")
+	}
+}
+
+// TestConnector_handleUpdate_DryRun tests that /dryrun is published as a dry-run request
+func TestConnector_handleUpdate_DryRun(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+
+	msgBus := bus.New(100, 10, log)
+	ctx := t.Context()
+	require.NoError(t, msgBus.Start(ctx))
+	t.Cleanup(func() {
+		require.NoError(t, msgBus.Stop())
+	})
+
+	conn := New(config.TelegramConfig{AllowedUsers: []string{"123456789"}}, log, msgBus)
+	conn.ctx = ctx
+	inboundCh := msgBus.SubscribeInbound(ctx)
+
+	update := telego.Update{
+		Message: &telego.Message{
+			MessageID: 1,
+			From:      &telego.User{ID: 123456789},
+			Chat:      telego.Chat{ID: 987654321, Type: "private"},
+			Text:      "/dryrun clean up the logs directory",
+		},
+	}
+	require.NoError(t, conn.handleUpdate(update))
+
+	select {
+	case msg := <-inboundCh:
+		if msg.Content != "clean up the logs directory" {
+			t.Errorf("Expected the request as content, got '%s'", msg.Content)
+		}
+		if msg.Metadata["dry_run"] != true {
+			t.Errorf("Expected dry_run metadata, got %v", msg.Metadata["dry_run"])
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("Timeout waiting for inbound message")
+	}
+}
+
 // TestConnector_handleUpdate_WhitelistBlocked tests update blocking by whitelist
 func TestConnector_handleUpdate_WhitelistBlocked(t *testing.T) {
 	log, _ := logger.New(logger.Config{
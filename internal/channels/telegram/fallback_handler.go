@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 
 // handleSendError обрабатывает ошибки отправки с smart fallback для markdown
 func (c *Connector) handleSendError(err error, msg bus.OutboundMessage, chatID int64, params telego.SendMessageParams) {
+	ctx := msg.LogContext(c.ctx)
 	if telErr, ok := errors.AsType[*telegoapi.Error](err); ok {
 		details := &channels.TelegramErrorDetails{
 			ErrorCode:       telErr.ErrorCode,
@@ -40,37 +42,35 @@ func (c *Connector) handleSendError(err error, msg bus.OutboundMessage, chatID i
 
 		// Smart fallback: try different parsing modes based on content type
 		if isMarkdownError {
-			c.logger.WarnCtx(c.ctx, "markdown parse error, trying fallback strategies",
+			c.logger.WarnCtx(ctx, "markdown parse error, trying fallback strategies",
 				logger.Field{Key: "chat_id", Value: chatID},
-				logger.Field{Key: "correlation_id", Value: msg.CorrelationID},
 				logger.Field{Key: "error", Value: telErr.Description})
 
 			// Fallback 1: Try HTML
-			c.logger.InfoCtx(c.ctx, "trying HTML fallback")
+			c.logger.InfoCtx(ctx, "trying HTML fallback")
 			htmlContent := MarkdownToHTML(msg.Content)
 			params.ParseMode = telego.ModeHTML
 			params.Text = htmlContent
-			_, htmlErr := c.bot.SendMessage(c.ctx, &params)
+			sent, htmlErr := c.bot.SendMessage(c.ctx, &params)
 			if htmlErr == nil {
-				c.logger.InfoCtx(c.ctx, "message sent with HTML fallback")
-				c.publishResult(msg, chatID, true, nil)
+				c.logger.InfoCtx(ctx, "message sent with HTML fallback")
+				c.publishSent(msg, chatID, sent)
 				return
 			}
 
-			c.logger.WarnCtx(c.ctx, "HTML fallback failed, trying plain text")
+			c.logger.WarnCtx(ctx, "HTML fallback failed, trying plain text")
 			plainContent := StripFormatting(msg.Content)
 			params.ParseMode = ""
 			params.Text = plainContent
-			_, plainErr := c.bot.SendMessage(c.ctx, &params)
+			sent, plainErr := c.bot.SendMessage(c.ctx, &params)
 			if plainErr == nil {
-				c.logger.InfoCtx(c.ctx, "message sent with plain text fallback")
-				c.publishResult(msg, chatID, true, nil)
+				c.logger.InfoCtx(ctx, "message sent with plain text fallback")
+				c.publishSent(msg, chatID, sent)
 				return
 			}
 
-			c.logger.ErrorCtx(c.ctx, "all markdown fallbacks failed", plainErr,
-				logger.Field{Key: "chat_id", Value: chatID},
-				logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+			c.logger.ErrorCtx(ctx, "all markdown fallbacks failed", plainErr,
+				logger.Field{Key: "chat_id", Value: chatID})
 			c.publishResult(msg, chatID, false, plainErr)
 			return
 		}
@@ -84,13 +84,68 @@ func (c *Connector) handleSendError(err error, msg bus.OutboundMessage, chatID i
 	c.publishResult(msg, chatID, false, err)
 }
 
+// skipDuplicate skips a message whose idempotency key was sent recently and
+// reports the earlier send, with the ID of the sent message, as its result.
+func (c *Connector) skipDuplicate(msg bus.OutboundMessage, chatID int64) bool {
+	if c.sentKeys == nil {
+		return false
+	}
+	messageID, ok := c.sentKeys.Lookup(msg.IdempotencyKey)
+	if !ok {
+		return false
+	}
+	c.logger.InfoCtx(msg.LogContext(c.ctx), "duplicate message skipped",
+		logger.Field{Key: "idempotency_key", Value: msg.IdempotencyKey},
+		logger.Field{Key: "chat_id", Value: chatID})
+
+	result := bus.MessageSendResult{
+		CorrelationID:  msg.CorrelationID,
+		IdempotencyKey: msg.IdempotencyKey,
+		ChannelType:    bus.ChannelTypeTelegram,
+		SessionID:      msg.SessionID,
+		Type:           msg.Type,
+		MessageID:      messageID,
+		Success:        true,
+		Duplicate:      true,
+		Timestamp:      time.Now(),
+	}
+	if err := c.bus.PublishSendResult(result); err != nil {
+		c.logger.ErrorCtx(msg.LogContext(c.ctx), "failed to publish send result", err)
+	}
+	return true
+}
+
+// publishSent publishes the result of a message sent to a chat, with the ID
+// of the new message.
+func (c *Connector) publishSent(msg bus.OutboundMessage, chatID int64, sent *telego.Message) {
+	c.rememberSent(chatID, sent)
+	if sent != nil && sent.MessageID != 0 {
+		msg.MessageID = strconv.Itoa(sent.MessageID)
+		// Replies to the message continue its thread
+		if _, root, err := parseSessionID(msg.SessionID); err == nil && root != 0 && c.threads != nil {
+			c.threads.sent(chatID, sent.MessageID, root)
+		}
+	}
+	c.publishResult(msg, chatID, true, nil)
+}
+
 // publishResult публикует результат отправки сообщения
 func (c *Connector) publishResult(msg bus.OutboundMessage, chatID int64, success bool, err error) {
+	ctx := msg.LogContext(c.ctx)
 	result := bus.MessageSendResult{
-		CorrelationID: msg.CorrelationID,
-		ChannelType:   bus.ChannelTypeTelegram,
-		Success:       success,
-		Timestamp:     time.Now(),
+		CorrelationID:  msg.CorrelationID,
+		IdempotencyKey: msg.IdempotencyKey,
+		ChannelType:    bus.ChannelTypeTelegram,
+		SessionID:      msg.SessionID,
+		Type:           msg.Type,
+		Success:        success,
+		Timestamp:      time.Now(),
+	}
+	if success {
+		result.MessageID = msg.MessageID
+		if c.sentKeys != nil {
+			c.sentKeys.Add(msg.IdempotencyKey, msg.MessageID)
+		}
 	}
 
 	if !success && err != nil {
@@ -113,7 +168,6 @@ func (c *Connector) publishResult(msg bus.OutboundMessage, chatID int64, success
 	}
 
 	if pubErr := c.bus.PublishSendResult(result); pubErr != nil {
-		c.logger.ErrorCtx(c.ctx, "failed to publish send result", pubErr,
-			logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+		c.logger.ErrorCtx(ctx, "failed to publish send result", pubErr)
 	}
 }
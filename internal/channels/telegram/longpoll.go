@@ -2,17 +2,45 @@ package telegram
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
 
+	"github.com/aatumaykin/nexbot/internal/bus"
 	"github.com/aatumaykin/nexbot/internal/logger"
 	"github.com/mymmrac/telego"
 )
 
+const (
+	// reconnectInitialBackoff is the first pause before probing the Bot API
+	// after getUpdates failed
+	reconnectInitialBackoff = time.Second
+
+	// DefaultReconnectMaxBackoff caps the pause between reconnect attempts
+	DefaultReconnectMaxBackoff = time.Minute
+)
+
+// errPollingStopped reports that getUpdates failed and telego stopped
+// polling; telego logs the error itself.
+var errPollingStopped = errors.New("getUpdates failed")
+
 // LongPollManager handles long polling for Telegram updates.
 type LongPollManager struct {
 	connector *Connector
 	bot       BotInterface
 	logger    *logger.Logger
 	ctx       context.Context
+
+	// Reconnecting after failures, disabled while maxBackoff is 0
+	maxBackoff  time.Duration
+	notifyAfter time.Duration                                   // Notify allowed users after a longer outage; 0 disables
+	offset      int                                             // Next update ID, kept across reconnects
+	sleep       func(ctx context.Context, d time.Duration) bool // Waits d; false when ctx is done first
+
+	// Liveness for the systemd watchdog
+	running       atomic.Bool
+	handlingSince atomic.Int64 // Unix nanoseconds when the current update started, 0 when idle
 }
 
 // NewLongPollManager creates a new long poll manager.
@@ -21,6 +49,7 @@ func NewLongPollManager(connector *Connector, bot BotInterface, logger *logger.L
 		connector: connector,
 		bot:       bot,
 		logger:    logger,
+		sleep:     sleepContext,
 	}
 }
 
@@ -34,32 +63,153 @@ func (lpm *LongPollManager) SetBot(bot BotInterface) {
 	lpm.bot = bot
 }
 
-// Start starts long polling for Telegram updates.
+// SetReconnect makes Start survive network partitions: when getUpdates
+// fails, the Bot API is probed with exponential backoff capped at
+// maxBackoff and polling resumes from the last handled update. After an
+// outage of at least notifyAfter (0 disables) the allowed users are told
+// that the bot is reachable again.
+func (lpm *LongPollManager) SetReconnect(maxBackoff, notifyAfter time.Duration) {
+	lpm.maxBackoff = maxBackoff
+	lpm.notifyAfter = notifyAfter
+}
+
+// Start starts long polling for Telegram updates. Without SetReconnect it
+// returns when polling stops; otherwise only when the context is done.
 func (lpm *LongPollManager) Start() {
 	lpm.logger.Info("starting long polling for telegram updates")
 
+	lpm.running.Store(true)
+	defer lpm.running.Store(false)
+
+	backoff := reconnectInitialBackoff
+	for {
+		started := time.Now()
+		err := lpm.poll()
+		if lpm.ctx.Err() != nil || lpm.maxBackoff <= 0 {
+			return
+		}
+
+		// A connection that held for a while starts over with a short pause;
+		// one failing at once (e.g. a conflicting poller) keeps backing off
+		if time.Since(started) > lpm.maxBackoff {
+			backoff = reconnectInitialBackoff
+		}
+
+		lostAt := time.Now()
+		lpm.connectionLost(err)
+		if !lpm.reconnect(&backoff) {
+			return
+		}
+		lpm.connectionRestored(time.Since(lostAt))
+	}
+}
+
+// poll receives and handles updates until polling stops and returns the
+// error that stopped it.
+func (lpm *LongPollManager) poll() error {
+	var opts []telego.LongPollingOption
+	if lpm.maxBackoff > 0 {
+		// Stop on the first failure instead of telego retrying blindly
+		opts = append(opts, telego.WithLongPollingRetryTimeout(0))
+	}
+
 	updates, err := lpm.bot.UpdatesViaLongPolling(lpm.ctx, &telego.GetUpdatesParams{
+		Offset:  lpm.offset,
 		Timeout: 30,
-	})
+		// message_reaction is not delivered by default and is needed for feedback
+		AllowedUpdates: []string{"message", "callback_query", "message_reaction"},
+	}, opts...)
 	if err != nil {
 		lpm.logger.ErrorCtx(lpm.ctx, "failed to start long polling", err)
-		return
+		return err
 	}
 
 	for {
 		select {
 		case <-lpm.ctx.Done():
 			lpm.logger.Info("long polling stopped")
-			return
+			return nil
 		case update, ok := <-updates:
 			if !ok {
 				lpm.logger.Info("updates channel closed")
-				return
+				return errPollingStopped
 			}
 
+			lpm.offset = update.UpdateID + 1
+			lpm.handlingSince.Store(time.Now().UnixNano())
 			if err := lpm.connector.updateHandler.Handle(update); err != nil {
 				lpm.logger.ErrorCtx(lpm.ctx, "failed to handle update", err)
 			}
+			lpm.handlingSince.Store(0)
+		}
+	}
+}
+
+// reconnect probes the Bot API with growing pauses until it answers. It
+// returns false when the context is done first.
+func (lpm *LongPollManager) reconnect(backoff *time.Duration) bool {
+	for attempt := 1; ; attempt++ {
+		if !lpm.sleep(lpm.ctx, *backoff) {
+			return false
+		}
+		*backoff = min(*backoff*2, lpm.maxBackoff)
+
+		if _, err := lpm.bot.GetMe(lpm.ctx); err != nil {
+			if lpm.ctx.Err() != nil {
+				return false
+			}
+			lpm.logger.WarnCtx(lpm.ctx, "telegram API still unreachable",
+				logger.Field{Key: "attempt", Value: attempt},
+				logger.Field{Key: "next_attempt_in", Value: backoff.String()},
+				logger.Field{Key: "error", Value: err.Error()})
+			continue
+		}
+		return true
+	}
+}
+
+func (lpm *LongPollManager) connectionLost(err error) {
+	lpm.logger.WarnCtx(lpm.ctx, "telegram connection lost, reconnecting",
+		logger.Field{Key: "error", Value: err.Error()})
+	if lpm.connector != nil {
+		lpm.connector.publishEvent(bus.NewConnectionLostEvent(bus.ChannelTypeTelegram, err))
+	}
+}
+
+func (lpm *LongPollManager) connectionRestored(downtime time.Duration) {
+	lpm.logger.InfoCtx(lpm.ctx, "telegram connection restored",
+		logger.Field{Key: "downtime", Value: downtime.Round(time.Second).String()})
+	if lpm.connector == nil {
+		return
+	}
+	lpm.connector.publishEvent(bus.NewConnectionRestoredEvent(bus.ChannelTypeTelegram, downtime))
+	if lpm.notifyAfter > 0 && downtime >= lpm.notifyAfter {
+		lpm.connector.notifyReconnected(downtime)
+	}
+}
+
+// sleepContext waits for d and reports whether ctx is still active.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// CheckHealth reports whether long polling is alive: the polling loop is
+// running and no update has been handled for longer than maxStall.
+func (lpm *LongPollManager) CheckHealth(maxStall time.Duration) error {
+	if !lpm.running.Load() {
+		return fmt.Errorf("long polling is not running")
+	}
+	if since := lpm.handlingSince.Load(); since != 0 {
+		if stalled := time.Since(time.Unix(0, since)); stalled > maxStall {
+			return fmt.Errorf("update handling stalled for %s", stalled.Round(time.Second))
 		}
 	}
+	return nil
 }
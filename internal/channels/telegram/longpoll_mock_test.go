@@ -399,3 +399,98 @@ func TestLongPollManager_Start_WithNilMessage(t *testing.T) {
 	// Verify
 	mockBot.AssertExpectations(t)
 }
+
+// TestLongPollManager_CheckHealth tests the liveness check used by the
+// systemd watchdog.
+func TestLongPollManager_CheckHealth(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	lp := NewLongPollManager(nil, nil, log)
+
+	assert.Error(t, lp.CheckHealth(time.Minute), "stopped long polling is unhealthy")
+
+	lp.running.Store(true)
+	assert.NoError(t, lp.CheckHealth(time.Minute))
+
+	lp.handlingSince.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	assert.ErrorContains(t, lp.CheckHealth(time.Minute), "stalled")
+
+	conn := New(config.TelegramConfig{Enabled: false}, log, nil)
+	assert.NoError(t, conn.CheckHealth(time.Minute), "disabled connector is healthy")
+}
+
+// TestLongPollManager_Start_Reconnects tests that polling resumes from the
+// last handled update after getUpdates fails and the API becomes reachable.
+func TestLongPollManager_Start_Reconnects(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "debug", Format: "text", Output: "stdout"})
+
+	msgBus := bus.New(100, 10, log)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := msgBus.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = msgBus.Stop() }()
+	events := msgBus.SubscribeEvent(ctx)
+
+	conn := New(config.TelegramConfig{AllowedUsers: []string{"42"}}, log, msgBus)
+	conn.ctx = ctx
+
+	// First poll delivers update 7 and fails; the second one stays open
+	failed := make(chan telego.Update, 1)
+	failed <- telego.Update{UpdateID: 7}
+	close(failed)
+	resumed := make(chan telego.Update)
+	polled := make(chan struct{})
+
+	mockBot := new(MockBot)
+	mockBot.On("UpdatesViaLongPolling", mock.Anything, mock.MatchedBy(func(p *telego.GetUpdatesParams) bool {
+		return p.Offset == 0
+	}), mock.Anything).Return(failed, nil).Once()
+	mockBot.On("UpdatesViaLongPolling", mock.Anything, mock.MatchedBy(func(p *telego.GetUpdatesParams) bool {
+		return p.Offset == 8
+	}), mock.Anything).Run(func(mock.Arguments) { close(polled) }).Return(resumed, nil).Once()
+	mockBot.On("GetMe", mock.Anything).Return(nil, assert.AnError).Once()
+	mockBot.On("GetMe", mock.Anything).Return(&telego.User{ID: 1}, nil).Once()
+	mockBot.On("SendMessage", mock.Anything, mock.MatchedBy(func(p *telego.SendMessageParams) bool {
+		return p.ChatID.ID == 42
+	})).Return(&telego.Message{}, nil).Once()
+	conn.bot = mockBot
+
+	lp := NewLongPollManager(conn, mockBot, log)
+	lp.SetContext(ctx)
+	lp.SetReconnect(4*time.Second, time.Nanosecond)
+	var pauses []time.Duration
+	lp.sleep = func(ctx context.Context, d time.Duration) bool {
+		pauses = append(pauses, d)
+		return ctx.Err() == nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		lp.Start()
+		close(done)
+	}()
+
+	var types []bus.EventType
+	for len(types) < 2 {
+		select {
+		case event := <-events:
+			types = append(types, event.Type)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for connection events, got %v", types)
+		}
+	}
+	assert.Equal(t, []bus.EventType{bus.EventTypeConnectionLost, bus.EventTypeConnectionRestored}, types)
+
+	select {
+	case <-polled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("polling was not resumed")
+	}
+	assert.NoError(t, lp.CheckHealth(time.Minute))
+
+	cancel()
+	<-done
+	mockBot.AssertExpectations(t)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, pauses)
+}
@@ -1,54 +1,75 @@
 package telegram
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/media"
 	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
 )
 
-// prepareMediaParams is a generic function that prepares parameters for sending media (photo/document)
+// prepareMediaParams is a generic function that prepares parameters for sending media (photo, document, sticker, animation).
+// The returned function releases a local file and must be called once the media is sent.
 func prepareMediaParams[T any](
 	conn *Connector,
 	msg bus.OutboundMessage,
 	chatID int64,
 	setMediaField func(*T, telego.InputFile),
-) (*T, error) {
+) (*T, func(), error) {
+	release := func() {}
+
 	// Initialize params with ChatID
 	var params T
-	chatIDField, ok := any(&params).(interface{ SetChatID(telego.ChatID) })
+	chatIDField, ok := any(&params).(interface{ WithChatID(telego.ChatID) *T })
 	if ok {
-		chatIDField.SetChatID(telego.ChatID{ID: chatID})
-	}
-
-	// Set caption if provided
-	captionField, ok := any(&params).(interface{ SetCaption(string) })
-	if ok && msg.Content != "" {
-		captionField.SetCaption(msg.Content)
+		chatIDField.WithChatID(telego.ChatID{ID: chatID})
 	}
 
 	if msg.Media == nil {
-		return &params, fmt.Errorf("media data is required")
+		return &params, release, fmt.Errorf("media data is required")
 	}
 
 	media := msg.Media
 
+	// Set caption if provided, falling back to the media caption
+	caption := msg.Content
+	if caption == "" {
+		caption = media.Caption
+	}
+	captionField, ok := any(&params).(interface{ WithCaption(string) *T })
+	if ok && caption != "" {
+		captionField.WithCaption(caption)
+	}
+
 	// Priority order: LocalPath > FileID > URL
 	if media.LocalPath != "" {
 		if !conn.isValidFilePath(media.LocalPath) {
-			return &params, fmt.Errorf("invalid file path: %s", media.LocalPath)
+			return &params, release, fmt.Errorf("invalid file path: %s", media.LocalPath)
 		}
 
-		// Open file for reading
+		// Open file for reading; it is read while the media is sent
 		file, err := os.Open(media.LocalPath)
 		if err != nil {
-			return &params, fmt.Errorf("failed to open file: %w", err)
+			return &params, release, fmt.Errorf("failed to open file: %w", err)
 		}
-		defer file.Close()
+		release = func() { _ = file.Close() }
 
-		inputFile := telego.InputFile{File: file}
+		name := media.FileName
+		if name == "" {
+			name = filepath.Base(media.LocalPath)
+		}
+		var content io.Reader = file
+		if msg.Type == bus.MessageTypePhoto {
+			content, name = conn.preparePhoto(msg, file, name)
+		}
+		inputFile := telego.InputFile{File: tu.NameReader(content, name)}
 		setMediaField(&params, inputFile)
 	} else if media.FileID != "" {
 		inputFile := telego.InputFile{FileID: media.FileID}
@@ -57,10 +78,36 @@ func prepareMediaParams[T any](
 		inputFile := telego.InputFile{URL: media.URL}
 		setMediaField(&params, inputFile)
 	} else {
-		return &params, fmt.Errorf("no valid media source provided (local_path, file_id, or url)")
+		return &params, release, fmt.Errorf("no valid media source provided (local_path, file_id, or url)")
+	}
+
+	return &params, release, nil
+}
+
+// preparePhoto scales down, converts or compresses a local image that does
+// not fit the photo limits. On failure the original file is sent, and
+// Telegram decides whether to accept it.
+func (c *Connector) preparePhoto(msg bus.OutboundMessage, file *os.File, name string) (io.Reader, string) {
+	ctx := msg.LogContext(c.ctx)
+	prepared, err := media.PrepareImage(file, name, c.images)
+	if err != nil {
+		c.logger.WarnCtx(ctx, "failed to prepare photo, sending the original file",
+			logger.Field{Key: "path", Value: file.Name()},
+			logger.Field{Key: "error", Value: err.Error()})
+	}
+	if err != nil || prepared == nil {
+		// PrepareImage has read the file
+		_, _ = file.Seek(0, io.SeekStart)
+		return file, name
 	}
 
-	return &params, nil
+	c.logger.DebugCtx(ctx, "photo prepared for sending",
+		logger.Field{Key: "path", Value: file.Name()},
+		logger.Field{Key: "name", Value: prepared.Name},
+		logger.Field{Key: "width", Value: prepared.Width},
+		logger.Field{Key: "height", Value: prepared.Height},
+		logger.Field{Key: "size", Value: len(prepared.Data)})
+	return bytes.NewReader(prepared.Data), prepared.Name
 }
 
 // isValidFilePath validates a file path
@@ -0,0 +1,105 @@
+package telegram
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/media"
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnector_SendDocument_LocalFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "1")
+	require.NoError(t, os.WriteFile(path, []byte("a,b"), 0644))
+
+	var name, content, caption string
+	var chatID int64
+	mockBot := &MockBot{}
+	mockBot.On("SendDocument", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		params := args.Get(1).(*telego.SendDocumentParams)
+		name = params.Document.File.Name()
+		data, err := io.ReadAll(params.Document.File)
+		require.NoError(t, err)
+		content = string(data)
+		caption = params.Caption
+		chatID = params.ChatID.ID
+	}).Return(&telego.Message{MessageID: 1}, nil)
+	c := newStreamTestConnector(t, mockBot)
+
+	media := &bus.MediaData{Type: "document", LocalPath: path, FileName: "report.csv", Caption: "Отчёт"}
+	c.sendDocument(*bus.NewDocumentMessage(bus.ChannelTypeTelegram, "1", "telegram:42", media, "corr", bus.FormatTypePlain, nil), 42)
+
+	// The file is still open while it is sent, under the artifact name
+	assert.Equal(t, "report.csv", name)
+	assert.Equal(t, "a,b", content)
+	assert.Equal(t, "Отчёт", caption)
+	assert.Equal(t, int64(42), chatID)
+}
+
+func TestConnector_SendPhoto_ScalesLargeImage(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 400, 100))))
+	path := filepath.Join(t.TempDir(), "chart.png")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+
+	var name string
+	var sent image.Config
+	mockBot := &MockBot{}
+	mockBot.On("SendPhoto", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		params := args.Get(1).(*telego.SendPhotoParams)
+		name = params.Photo.File.Name()
+		var err error
+		sent, _, err = image.DecodeConfig(params.Photo.File)
+		require.NoError(t, err)
+	}).Return(&telego.Message{MessageID: 1}, nil)
+	c := newStreamTestConnector(t, mockBot)
+	c.SetImageOptions(media.ImageOptions{MaxWidth: 200, MaxHeight: 200})
+
+	photo := &bus.MediaData{Type: "photo", LocalPath: path}
+	c.sendPhoto(*bus.NewPhotoMessage(bus.ChannelTypeTelegram, "1", "telegram:42", photo, "corr", bus.FormatTypePlain, nil), 42)
+
+	assert.Equal(t, "chart.png", name)
+	assert.Equal(t, 200, sent.Width)
+	assert.Equal(t, 50, sent.Height)
+}
+
+func TestConnector_SendSticker_ByFileID(t *testing.T) {
+	var sticker telego.InputFile
+	mockBot := &MockBot{}
+	mockBot.On("SendSticker", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sticker = args.Get(1).(*telego.SendStickerParams).Sticker
+	}).Return(&telego.Message{MessageID: 4}, nil)
+	c := newStreamTestConnector(t, mockBot)
+
+	media := &bus.MediaData{Type: "sticker", FileID: "CAACAgIAAxkBAAE"}
+	c.sendSticker(*bus.NewStickerMessage(bus.ChannelTypeTelegram, "1", "telegram:42", media, nil, "corr", nil), 42)
+
+	assert.Equal(t, "CAACAgIAAxkBAAE", sticker.FileID)
+	mockBot.AssertNumberOfCalls(t, "SendSticker", 1)
+}
+
+func TestConnector_SendAnimation_ByURL(t *testing.T) {
+	var params *telego.SendAnimationParams
+	mockBot := &MockBot{}
+	mockBot.On("SendAnimation", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		params = args.Get(1).(*telego.SendAnimationParams)
+	}).Return(&telego.Message{MessageID: 5}, nil)
+	c := newStreamTestConnector(t, mockBot)
+
+	media := &bus.MediaData{Type: "animation", URL: "https://example.com/cat.gif", Caption: "Кот"}
+	c.sendAnimation(*bus.NewAnimationMessage(bus.ChannelTypeTelegram, "1", "telegram:42", media, nil, "corr", bus.FormatTypePlain, nil), 42)
+
+	require.NotNil(t, params)
+	assert.Equal(t, "https://example.com/cat.gif", params.Animation.URL)
+	assert.Equal(t, "Кот", params.Caption)
+	assert.Equal(t, int64(42), params.ChatID.ID)
+}
@@ -13,12 +13,15 @@ import (
 
 // sendTextMessage sends a text message to Telegram
 func (c *Connector) sendTextMessage(msg bus.OutboundMessage, chatID int64) {
+	ctx := msg.LogContext(c.ctx)
+	if msg.Table != nil {
+		msg.Content, msg.Format = renderTable(msg.Content, msg.Format, msg.Table)
+	}
 	// Prepare message with format
 	params, err := c.prepareMessage(msg.Content, chatID, msg.Format)
 	if err != nil {
-		c.logger.ErrorCtx(c.ctx, "failed to prepare text message", err,
-			logger.Field{Key: "chat_id", Value: chatID},
-			logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+		c.logger.ErrorCtx(ctx, "failed to prepare text message", err,
+			logger.Field{Key: "chat_id", Value: chatID})
 		c.publishResult(msg, chatID, false, err)
 		return
 	}
@@ -27,26 +30,26 @@ func (c *Connector) sendTextMessage(msg bus.OutboundMessage, chatID int64) {
 	if msg.InlineKeyboard != nil && c.cfg.EnableInlineKeyboard {
 		params.ReplyMarkup = c.buildInlineKeyboard(msg.InlineKeyboard)
 	}
+	params.ReplyParameters = c.threadReply(msg.SessionID)
 
 	// Try to send with format and timeout
 	sendCtx, cancel := c.getSendTimeout()
 	defer cancel()
-	_, err = c.bot.SendMessage(sendCtx, &params)
+	sent, err := c.bot.SendMessage(sendCtx, &params)
 	if err != nil {
 		// Smart fallback for markdown errors
 		c.handleSendError(err, msg, chatID, params)
 		return
 	}
-
 	// Successful send - publish result immediately
-	c.publishResult(msg, chatID, true, nil)
+	c.publishSent(msg, chatID, sent)
 }
 
 // editMessage edits an existing message in Telegram
 func (c *Connector) editMessage(msg bus.OutboundMessage, chatID int64) {
+	ctx := msg.LogContext(c.ctx)
 	if msg.MessageID == "" {
-		c.logger.ErrorCtx(c.ctx, "message ID is required for edit", nil,
-			logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+		c.logger.ErrorCtx(ctx, "message ID is required for edit", nil)
 		c.publishResult(msg, chatID, false, fmt.Errorf("message ID is required for edit"))
 		return
 	}
@@ -74,18 +77,17 @@ func (c *Connector) editMessage(msg bus.OutboundMessage, chatID int64) {
 
 // deleteMessage deletes an existing message from Telegram
 func (c *Connector) deleteMessage(msg bus.OutboundMessage, chatID int64) {
+	ctx := msg.LogContext(c.ctx)
 	if msg.MessageID == "" {
-		c.logger.ErrorCtx(c.ctx, "message ID is required for delete", nil,
-			logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+		c.logger.ErrorCtx(ctx, "message ID is required for delete", nil)
 		c.publishResult(msg, chatID, false, fmt.Errorf("message ID is required for delete"))
 		return
 	}
 
 	messageID, err := strconv.Atoi(msg.MessageID)
 	if err != nil {
-		c.logger.ErrorCtx(c.ctx, "invalid message ID format", err,
-			logger.Field{Key: "message_id", Value: msg.MessageID},
-			logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+		c.logger.ErrorCtx(ctx, "invalid message ID format", err,
+			logger.Field{Key: "message_id", Value: msg.MessageID})
 		c.publishResult(msg, chatID, false, fmt.Errorf("invalid message ID format: %w", err))
 		return
 	}
@@ -97,10 +99,9 @@ func (c *Connector) deleteMessage(msg bus.OutboundMessage, chatID int64) {
 
 	err = c.bot.DeleteMessage(c.ctx, &params)
 	if err != nil {
-		c.logger.ErrorCtx(c.ctx, "failed to delete message", err,
+		c.logger.ErrorCtx(ctx, "failed to delete message", err,
 			logger.Field{Key: "chat_id", Value: chatID},
-			logger.Field{Key: "message_id", Value: msg.MessageID},
-			logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+			logger.Field{Key: "message_id", Value: msg.MessageID})
 		c.publishResult(msg, chatID, false, err)
 		return
 	}
@@ -111,20 +112,20 @@ func (c *Connector) deleteMessage(msg bus.OutboundMessage, chatID int64) {
 
 // sendPhoto sends a photo message to Telegram
 func (c *Connector) sendPhoto(msg bus.OutboundMessage, chatID int64) {
+	ctx := msg.LogContext(c.ctx)
 	if msg.Media == nil {
-		c.logger.ErrorCtx(c.ctx, "media data is required for photo message", nil,
-			logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+		c.logger.ErrorCtx(ctx, "media data is required for photo message", nil)
 		c.publishResult(msg, chatID, false, fmt.Errorf("media data is required for photo message"))
 		return
 	}
 
-	params, err := prepareMediaParams[telego.SendPhotoParams](c, msg, chatID, func(p *telego.SendPhotoParams, f telego.InputFile) {
+	params, release, err := prepareMediaParams[telego.SendPhotoParams](c, msg, chatID, func(p *telego.SendPhotoParams, f telego.InputFile) {
 		p.Photo = f
 	})
+	defer release()
 	if err != nil {
-		c.logger.ErrorCtx(c.ctx, "failed to prepare photo message", err,
-			logger.Field{Key: "chat_id", Value: chatID},
-			logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+		c.logger.ErrorCtx(ctx, "failed to prepare photo message", err,
+			logger.Field{Key: "chat_id", Value: chatID})
 		c.publishResult(msg, chatID, false, err)
 		return
 	}
@@ -133,39 +134,38 @@ func (c *Connector) sendPhoto(msg bus.OutboundMessage, chatID int64) {
 	if msg.InlineKeyboard != nil && c.cfg.EnableInlineKeyboard {
 		params.ReplyMarkup = c.buildInlineKeyboard(msg.InlineKeyboard)
 	}
+	params.ReplyParameters = c.threadReply(msg.SessionID)
 
 	// Send with timeout
 	sendCtx, cancel := c.getSendTimeout()
 	defer cancel()
-	_, err = c.bot.SendPhoto(sendCtx, params)
+	sent, err := c.bot.SendPhoto(sendCtx, params)
 	if err != nil {
-		c.logger.ErrorCtx(c.ctx, "failed to send photo", err,
-			logger.Field{Key: "chat_id", Value: chatID},
-			logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+		c.logger.ErrorCtx(ctx, "failed to send photo", err,
+			logger.Field{Key: "chat_id", Value: chatID})
 		c.publishResult(msg, chatID, false, err)
 		return
 	}
-
 	// Successful send - publish result immediately
-	c.publishResult(msg, chatID, true, nil)
+	c.publishSent(msg, chatID, sent)
 }
 
 // sendDocument sends a document message to Telegram
 func (c *Connector) sendDocument(msg bus.OutboundMessage, chatID int64) {
+	ctx := msg.LogContext(c.ctx)
 	if msg.Media == nil {
-		c.logger.ErrorCtx(c.ctx, "media data is required for document message", nil,
-			logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+		c.logger.ErrorCtx(ctx, "media data is required for document message", nil)
 		c.publishResult(msg, chatID, false, fmt.Errorf("media data is required for document message"))
 		return
 	}
 
-	params, err := prepareMediaParams[telego.SendDocumentParams](c, msg, chatID, func(p *telego.SendDocumentParams, f telego.InputFile) {
+	params, release, err := prepareMediaParams[telego.SendDocumentParams](c, msg, chatID, func(p *telego.SendDocumentParams, f telego.InputFile) {
 		p.Document = f
 	})
+	defer release()
 	if err != nil {
-		c.logger.ErrorCtx(c.ctx, "failed to prepare document message", err,
-			logger.Field{Key: "chat_id", Value: chatID},
-			logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+		c.logger.ErrorCtx(ctx, "failed to prepare document message", err,
+			logger.Field{Key: "chat_id", Value: chatID})
 		c.publishResult(msg, chatID, false, err)
 		return
 	}
@@ -174,21 +174,100 @@ func (c *Connector) sendDocument(msg bus.OutboundMessage, chatID int64) {
 	if msg.InlineKeyboard != nil && c.cfg.EnableInlineKeyboard {
 		params.ReplyMarkup = c.buildInlineKeyboard(msg.InlineKeyboard)
 	}
+	params.ReplyParameters = c.threadReply(msg.SessionID)
 
 	// Send with timeout
 	sendCtx, cancel := c.getSendTimeout()
 	defer cancel()
-	_, err = c.bot.SendDocument(sendCtx, params)
+	sent, err := c.bot.SendDocument(sendCtx, params)
 	if err != nil {
-		c.logger.ErrorCtx(c.ctx, "failed to send document", err,
-			logger.Field{Key: "chat_id", Value: chatID},
-			logger.Field{Key: "correlation_id", Value: msg.CorrelationID})
+		c.logger.ErrorCtx(ctx, "failed to send document", err,
+			logger.Field{Key: "chat_id", Value: chatID})
+		c.publishResult(msg, chatID, false, err)
+		return
+	}
+	// Successful send - publish result immediately
+	c.publishSent(msg, chatID, sent)
+}
+
+// sendSticker sends a sticker message to Telegram
+func (c *Connector) sendSticker(msg bus.OutboundMessage, chatID int64) {
+	ctx := msg.LogContext(c.ctx)
+	if msg.Media == nil {
+		c.logger.ErrorCtx(ctx, "media data is required for sticker message", nil)
+		c.publishResult(msg, chatID, false, fmt.Errorf("media data is required for sticker message"))
+		return
+	}
+
+	params, release, err := prepareMediaParams[telego.SendStickerParams](c, msg, chatID, func(p *telego.SendStickerParams, f telego.InputFile) {
+		p.Sticker = f
+	})
+	defer release()
+	if err != nil {
+		c.logger.ErrorCtx(ctx, "failed to prepare sticker message", err,
+			logger.Field{Key: "chat_id", Value: chatID})
+		c.publishResult(msg, chatID, false, err)
+		return
+	}
+
+	// Attach inline keyboard if enabled and present
+	if msg.InlineKeyboard != nil && c.cfg.EnableInlineKeyboard {
+		params.ReplyMarkup = c.buildInlineKeyboard(msg.InlineKeyboard)
+	}
+	params.ReplyParameters = c.threadReply(msg.SessionID)
+
+	// Send with timeout
+	sendCtx, cancel := c.getSendTimeout()
+	defer cancel()
+	sent, err := c.bot.SendSticker(sendCtx, params)
+	if err != nil {
+		c.logger.ErrorCtx(ctx, "failed to send sticker", err,
+			logger.Field{Key: "chat_id", Value: chatID})
+		c.publishResult(msg, chatID, false, err)
+		return
+	}
+	// Successful send - publish result immediately
+	c.publishSent(msg, chatID, sent)
+}
+
+// sendAnimation sends a GIF or MPEG-4 animation to Telegram
+func (c *Connector) sendAnimation(msg bus.OutboundMessage, chatID int64) {
+	ctx := msg.LogContext(c.ctx)
+	if msg.Media == nil {
+		c.logger.ErrorCtx(ctx, "media data is required for animation message", nil)
+		c.publishResult(msg, chatID, false, fmt.Errorf("media data is required for animation message"))
+		return
+	}
+
+	params, release, err := prepareMediaParams[telego.SendAnimationParams](c, msg, chatID, func(p *telego.SendAnimationParams, f telego.InputFile) {
+		p.Animation = f
+	})
+	defer release()
+	if err != nil {
+		c.logger.ErrorCtx(ctx, "failed to prepare animation message", err,
+			logger.Field{Key: "chat_id", Value: chatID})
 		c.publishResult(msg, chatID, false, err)
 		return
 	}
 
+	// Attach inline keyboard if enabled and present
+	if msg.InlineKeyboard != nil && c.cfg.EnableInlineKeyboard {
+		params.ReplyMarkup = c.buildInlineKeyboard(msg.InlineKeyboard)
+	}
+	params.ReplyParameters = c.threadReply(msg.SessionID)
+
+	// Send with timeout
+	sendCtx, cancel := c.getSendTimeout()
+	defer cancel()
+	sent, err := c.bot.SendAnimation(sendCtx, params)
+	if err != nil {
+		c.logger.ErrorCtx(ctx, "failed to send animation", err,
+			logger.Field{Key: "chat_id", Value: chatID})
+		c.publishResult(msg, chatID, false, err)
+		return
+	}
 	// Successful send - publish result immediately
-	c.publishResult(msg, chatID, true, nil)
+	c.publishSent(msg, chatID, sent)
 }
 
 // prepareEditMessageParams prepares parameters for editing a message
@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/onboarding"
+	"github.com/aatumaykin/nexbot/internal/users"
+	"github.com/mymmrac/telego"
+)
+
+// handleStart runs the onboarding for /start with an optional deep-link
+// payload. Users outside the whitelist need a valid invite code: a
+// single-use code from "/admin invite" or one of onboarding.invite_codes.
+func (uh *UpdateHandler) handleStart(msg *telego.Message, userID string) error {
+	payload := strings.TrimSpace(strings.TrimPrefix(msg.Text, onboarding.StartCommand))
+	p := onboarding.ParsePayload(payload)
+
+	allowed := uh.connector.isAllowedUser(userID)
+	if !allowed && uh.connector.invites != nil {
+		// Codes are passed as "inv_<code>" in links or as is ("/start <code>")
+		code := p.InviteCode
+		if code == "" {
+			code = payload
+		}
+		if code != "" {
+			if _, err := uh.connector.invites.Redeem(code, userID); err == nil {
+				allowed = true
+				uh.logger.InfoCtx(uh.connector.ctx, "invite code redeemed",
+					logger.Field{Key: "user_id", Value: userID},
+					logger.Field{Key: "username", Value: msg.From.Username})
+				// The single-use code is not an onboarding invite code
+				if p.InviteCode == "" {
+					p = onboarding.Payload{}
+				}
+				p.InviteCode = ""
+			} else if uh.connector.onboarding == nil || !uh.connector.onboarding.ValidInvite(p.InviteCode) {
+				uh.logger.WarnCtx(uh.connector.ctx, "invite code rejected",
+					logger.Field{Key: "user_id", Value: userID},
+					logger.Field{Key: "error", Value: err.Error()})
+				uh.notify(msg.Chat.ID, "⚠️ This invite code is invalid, expired or has already been used.")
+				return nil
+			}
+		}
+	}
+
+	if !allowed && (uh.connector.onboarding == nil || !uh.connector.onboarding.ValidInvite(p.InviteCode)) {
+		uh.logger.WarnCtx(uh.connector.ctx, "start blocked - user not in whitelist",
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "username", Value: msg.From.Username},
+			logger.Field{Key: "invite_code", Value: p.InviteCode})
+		uh.notify(msg.Chat.ID, "Sorry, you are not authorized to use this bot.")
+		return nil
+	}
+
+	if uh.connector.onboarding == nil {
+		uh.notify(msg.Chat.ID, "✅ Invite accepted. Send me a message to get started.")
+		return nil
+	}
+
+	sessionID := fmt.Sprintf("telegram:%d", msg.Chat.ID)
+	if err := uh.connector.onboarding.Start(uh.connector.ctx, sessionID, telegramIdentity(userID), msg.From.FirstName, p); err != nil {
+		return fmt.Errorf("failed to start onboarding: %w", err)
+	}
+	return nil
+}
+
+// telegramIdentity returns the user registry identity of a Telegram user.
+func telegramIdentity(userID string) users.Identity {
+	return users.Identity{Channel: string(bus.ChannelTypeTelegram), Address: userID}
+}
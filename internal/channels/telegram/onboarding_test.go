@@ -0,0 +1,94 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/onboarding"
+	"github.com/aatumaykin/nexbot/internal/users"
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func textUpdate(userID int64, text string) telego.Update {
+	return telego.Update{Message: &telego.Message{
+		MessageID: 1,
+		From:      &telego.User{ID: userID, FirstName: "Alice"},
+		Chat:      telego.Chat{ID: userID, Type: "private"},
+		Text:      text,
+	}}
+}
+
+// receiveOutbound returns the next outbound message or fails the test.
+func receiveOutbound(t *testing.T, ch <-chan bus.OutboundMessage) bus.OutboundMessage {
+	t.Helper()
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no outbound message")
+		return bus.OutboundMessage{}
+	}
+}
+
+func TestUpdateHandler_StartWithInvite(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	msgBus := bus.New(10, 10, log)
+	require.NoError(t, msgBus.Start(ctx))
+	t.Cleanup(func() { _ = msgBus.Stop() })
+	inboundCh := msgBus.SubscribeInbound(ctx)
+	outboundCh := msgBus.SubscribeOutbound(ctx)
+
+	registry := users.NewRegistry(t.TempDir())
+	require.NoError(t, registry.Load(nil))
+
+	conn := New(config.TelegramConfig{AllowedUsers: []string{"123456"}, AnswerCallbackTimeout: 5}, log, msgBus)
+	conn.ctx = ctx
+	conn.bot = NewMockBotSuccess()
+	conn.SetOnboarding(onboarding.New(onboarding.Config{
+		Messages:    []string{"Hi {name}!"},
+		Consents:    []onboarding.Consent{{Name: "history", Question: "Keep the history?", Required: true}},
+		InviteCodes: []string{"team2026"},
+	}, registry, msgBus, log))
+
+	// Users outside the whitelist need a valid invite
+	require.NoError(t, conn.handleUpdate(textUpdate(777, "/start ref_github")))
+	assert.False(t, conn.isAllowedUser("777"))
+
+	require.NoError(t, conn.handleUpdate(textUpdate(777, "/start inv_team2026__ref_github")))
+	assert.Equal(t, "Hi Alice!", receiveOutbound(t, outboundCh).Content)
+	question := receiveOutbound(t, outboundCh)
+	require.NotNil(t, question.InlineKeyboard)
+	assert.True(t, conn.isAllowedUser("777"))
+
+	u, ok := registry.FindByIdentity(users.Identity{Channel: "telegram", Address: "777"})
+	require.True(t, ok)
+	assert.Equal(t, "github", u.Source)
+
+	// Messages are held until the required consent is granted
+	require.NoError(t, conn.handleUpdate(textUpdate(777, "hello")))
+	assert.Equal(t, question.Content, receiveOutbound(t, outboundCh).Content)
+
+	require.NoError(t, conn.handleUpdate(telego.Update{CallbackQuery: &telego.CallbackQuery{
+		ID:      "cb1",
+		From:    telego.User{ID: 777},
+		Message: &telego.Message{MessageID: 2, Chat: telego.Chat{ID: 777, Type: "private"}},
+		Data:    question.InlineKeyboard.Rows[0][0].Data,
+	}}))
+	assert.Contains(t, receiveOutbound(t, outboundCh).Content, "all set")
+
+	require.NoError(t, conn.handleUpdate(textUpdate(777, "hello")))
+	select {
+	case msg := <-inboundCh:
+		assert.Equal(t, "hello", msg.Content)
+	case <-time.After(time.Second):
+		t.Fatal("message was not published after consent")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/aatumaykin/nexbot/internal/bus"
 	"github.com/aatumaykin/nexbot/internal/config"
 	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/mymmrac/telego"
 	"github.com/stretchr/testify/require"
 )
 
@@ -384,3 +385,101 @@ type testError string
 func (e testError) Error() string {
 	return string(e)
 }
+
+func Test_skipDuplicate(t *testing.T) {
+	log, _ := logger.New(logger.Config{
+		Level:  "debug",
+		Format: "text",
+		Output: "stdout",
+	})
+
+	msgBus := bus.New(100, 10, log)
+	err := msgBus.Start(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		err := msgBus.Stop()
+		require.NoError(t, err)
+	}()
+
+	ctx := context.Background()
+	conn := New(config.TelegramConfig{}, log, msgBus)
+	conn.ctx = ctx
+	resultCh := msgBus.SubscribeSendResults(ctx)
+
+	chatID := int64(987654321)
+	msg := bus.OutboundMessage{
+		CorrelationID:  "first",
+		IdempotencyKey: "key-1",
+		ChannelType:    bus.ChannelTypeTelegram,
+		Content:        "test message",
+	}
+
+	// Not sent yet: the message goes out
+	require.False(t, conn.skipDuplicate(msg, chatID))
+	conn.publishSent(msg, chatID, &telego.Message{MessageID: 42})
+	select {
+	case result := <-resultCh:
+		require.Equal(t, "key-1", result.IdempotencyKey)
+		require.Equal(t, "42", result.MessageID)
+		require.False(t, result.Duplicate)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for result")
+	}
+
+	// Published again after a timeout: skipped and reported as sent
+	msg.CorrelationID = "retry"
+	require.True(t, conn.skipDuplicate(msg, chatID))
+	select {
+	case result := <-resultCh:
+		require.Equal(t, "retry", result.CorrelationID)
+		require.Equal(t, "42", result.MessageID)
+		require.True(t, result.Success)
+		require.True(t, result.Duplicate)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for result")
+	}
+
+	// Messages without a key are never skipped
+	msg.IdempotencyKey = ""
+	require.False(t, conn.skipDuplicate(msg, chatID))
+}
+
+func Test_publishSent(t *testing.T) {
+	log, _ := logger.New(logger.Config{
+		Level:  "debug",
+		Format: "text",
+		Output: "stdout",
+	})
+
+	msgBus := bus.New(100, 10, log)
+	err := msgBus.Start(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		err := msgBus.Stop()
+		require.NoError(t, err)
+	}()
+
+	ctx := context.Background()
+	conn := New(config.TelegramConfig{}, log, msgBus)
+	conn.ctx = ctx
+	resultCh := msgBus.SubscribeSendResults(ctx)
+
+	msg := bus.OutboundMessage{
+		CorrelationID: "sent",
+		SessionID:     "telegram:987654321",
+		ChannelType:   bus.ChannelTypeTelegram,
+		Type:          bus.MessageTypeText,
+		Content:       "test message",
+	}
+	conn.publishSent(msg, 987654321, &telego.Message{MessageID: 42})
+
+	select {
+	case result := <-resultCh:
+		require.True(t, result.Success)
+		require.Equal(t, "42", result.MessageID)
+		require.Equal(t, "telegram:987654321", result.SessionID)
+		require.Equal(t, bus.MessageTypeText, result.Type)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for result")
+	}
+}
@@ -0,0 +1,168 @@
+package telegram
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/privacy"
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+const (
+	// forgetCallbackPrefix marks callback data of the /forget_me confirmation.
+	// Callback data format: "forget:<action>:<unix time of the request>".
+	forgetCallbackPrefix = "forget:"
+
+	// forgetConfirmTTL is how long the confirmation buttons stay valid
+	forgetConfirmTTL = 10 * time.Minute
+)
+
+// Actions of the /forget_me confirmation buttons
+const (
+	forgetActionDelete = "delete"
+	forgetActionExport = "export"
+	forgetActionCancel = "cancel"
+)
+
+// handleForgetMe asks the user to confirm the deletion of all their data.
+// It works in private chats only, and for users outside the whitelist too.
+func (uh *UpdateHandler) handleForgetMe(msg *telego.Message, userID string) error {
+	if msg.Chat.Type != telego.ChatTypePrivate {
+		uh.notify(msg.Chat.ID, "Send /forget_me in a private chat with the bot.")
+		return nil
+	}
+
+	subj, err := uh.connector.privacy.Resolve(telegramIdentity(userID).String())
+	if err != nil {
+		return fmt.Errorf("failed to resolve user data: %w", err)
+	}
+	inv, err := uh.connector.privacy.Inventory(subj)
+	if err != nil {
+		uh.logger.ErrorCtx(uh.connector.ctx, "failed to list user data", err,
+			logger.Field{Key: "user_id", Value: userID})
+		uh.notify(msg.Chat.ID, "❌ Failed to look up your data.")
+		return nil
+	}
+	if inv.Empty() {
+		uh.notify(msg.Chat.ID, "ℹ️ I don't store any data about you.")
+		return nil
+	}
+
+	text := fmt.Sprintf("⚠️ This deletes everything I store about you: %d files "+
+		"(conversations, files I created for you, exported transcripts), %d feedback entries "+
+		"and your profile. This can't be undone.\n\nExport your data first?", len(inv.Files), inv.Feedback)
+	requested := strconv.FormatInt(time.Now().Unix(), 10)
+	keyboard := &bus.InlineKeyboard{Rows: [][]bus.InlineButton{
+		{{Text: "📦 Export and delete", Data: forgetCallbackPrefix + forgetActionExport + ":" + requested}},
+		{{Text: "🗑 Delete", Data: forgetCallbackPrefix + forgetActionDelete + ":" + requested}},
+		{{Text: "Cancel", Data: forgetCallbackPrefix + forgetActionCancel + ":" + requested}},
+	}}
+
+	if uh.connector.bot == nil {
+		return nil
+	}
+	params := telego.SendMessageParams{
+		ChatID:      telego.ChatID{ID: msg.Chat.ID},
+		Text:        text,
+		ReplyMarkup: uh.connector.buildInlineKeyboard(keyboard),
+	}
+	if _, err := uh.connector.bot.SendMessage(uh.connector.ctx, &params); err != nil {
+		return fmt.Errorf("failed to send forget_me confirmation: %w", err)
+	}
+	return nil
+}
+
+// parseForgetCallback extracts the action and request time from callback data.
+func parseForgetCallback(data string) (action string, requested time.Time, ok bool) {
+	rest, found := strings.CutPrefix(data, forgetCallbackPrefix)
+	if !found {
+		return "", time.Time{}, false
+	}
+	action, unix, found := strings.Cut(rest, ":")
+	sec, err := strconv.ParseInt(unix, 10, 64)
+	if !found || err != nil {
+		return "", time.Time{}, false
+	}
+	return action, time.Unix(sec, 0), true
+}
+
+// handleForgetCallback runs the confirmed /forget_me request: the data is
+// optionally sent to the user as a zip archive, then erased.
+func (ch *CallbackHandler) handleForgetCallback(callbackQuery *telego.CallbackQuery, userID, action string, requested time.Time) error {
+	notify := ch.connector.updateHandler.notify
+	chatID := callbackQuery.From.ID
+
+	if time.Since(requested) > forgetConfirmTTL {
+		ch.answerCallback(callbackQuery.ID, "This request has expired. Send /forget_me again.")
+		return nil
+	}
+	if action == forgetActionCancel {
+		ch.answerCallback(callbackQuery.ID, "Cancelled")
+		notify(chatID, "Nothing was deleted.")
+		return nil
+	}
+	if action != forgetActionDelete && action != forgetActionExport {
+		ch.answerCallback(callbackQuery.ID, "")
+		return nil
+	}
+	ch.answerCallback(callbackQuery.ID, "")
+
+	subj, err := ch.connector.privacy.Resolve(telegramIdentity(userID).String())
+	if err != nil {
+		return fmt.Errorf("failed to resolve user data: %w", err)
+	}
+
+	exported := action == forgetActionExport
+	if exported {
+		if err := ch.sendExport(chatID, subj); err != nil {
+			ch.logger.ErrorCtx(ch.connector.ctx, "failed to export user data", err,
+				logger.Field{Key: "user_id", Value: userID})
+			notify(chatID, "❌ Failed to export your data. Nothing was deleted.")
+			return nil
+		}
+	}
+
+	ts, err := ch.connector.privacy.Erase(subj, privacy.RequestedByUser, exported)
+	if err != nil {
+		ch.logger.ErrorCtx(ch.connector.ctx, "failed to erase user data", err,
+			logger.Field{Key: "user_id", Value: userID})
+		notify(chatID, "❌ Failed to delete your data. Please contact the bot admin.")
+		return nil
+	}
+	ch.logger.InfoCtx(ch.connector.ctx, "user data erased",
+		logger.Field{Key: "subject", Value: ts.Subject},
+		logger.Field{Key: "files", Value: ts.Files},
+		logger.Field{Key: "exported", Value: exported})
+	notify(chatID, "✅ All data I stored about you has been deleted.")
+	return nil
+}
+
+// sendExport sends the data of the subject as a zip archive.
+func (ch *CallbackHandler) sendExport(chatID int64, subj privacy.Subject) error {
+	var buf bytes.Buffer
+	if err := ch.connector.privacy.Export(subj, &buf); err != nil {
+		return err
+	}
+	if ch.connector.bot == nil {
+		return nil
+	}
+
+	name := fmt.Sprintf("nexbot-data-%s.zip", time.Now().Format("20060102"))
+	params := &telego.SendDocumentParams{
+		ChatID:   telego.ChatID{ID: chatID},
+		Document: tu.FileFromBytes(buf.Bytes(), name),
+		Caption:  "📦 Your data",
+	}
+	sendCtx, cancel := ch.connector.getSendTimeout()
+	defer cancel()
+	if _, err := ch.connector.bot.SendDocument(sendCtx, params); err != nil {
+		return fmt.Errorf("failed to send export archive: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package telegram
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/privacy"
+	"github.com/aatumaykin/nexbot/internal/users"
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateHandler_ForgetMe(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	msgBus := bus.New(10, 10, log)
+	require.NoError(t, msgBus.Start(ctx))
+	t.Cleanup(func() { _ = msgBus.Stop() })
+
+	ws := t.TempDir()
+	sessions, err := session.NewManager(filepath.Join(ws, "sessions"))
+	require.NoError(t, err)
+	sess, _, err := sessions.GetOrCreate("telegram:777")
+	require.NoError(t, err)
+	require.NoError(t, sess.Append(llm.Message{Role: llm.RoleUser, Content: "hello"}))
+	registry := users.NewRegistry(ws)
+	require.NoError(t, registry.Load(nil))
+
+	// Record the messages and documents sent directly through the Bot API
+	var sent []*telego.SendMessageParams
+	var documents int
+	mockBot := &MockBot{}
+	mockBot.On("SendMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(1).(*telego.SendMessageParams))
+	}).Return(&telego.Message{MessageID: 1}, nil)
+	mockBot.On("SendDocument", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		documents++
+	}).Return(&telego.Message{MessageID: 2}, nil)
+	mockBot.On("AnswerCallbackQuery", mock.Anything, mock.Anything).Return(nil)
+
+	// The user is not in the whitelist: /forget_me works anyway
+	conn := New(config.TelegramConfig{AllowedUsers: []string{"1"}, AnswerCallbackTimeout: 5}, log, msgBus)
+	conn.ctx = ctx
+	conn.bot = mockBot
+	conn.SetPrivacy(privacy.New(ws, sessions, registry))
+
+	require.NoError(t, conn.handleUpdate(textUpdate(777, "/forget_me")))
+	require.NotEmpty(t, sent)
+	confirm := sent[len(sent)-1]
+	assert.Contains(t, confirm.Text, "1 files")
+	keyboard, ok := confirm.ReplyMarkup.(*telego.InlineKeyboardMarkup)
+	require.True(t, ok)
+	require.Len(t, keyboard.InlineKeyboard, 3)
+
+	press := func(data string) {
+		require.NoError(t, conn.handleUpdate(telego.Update{CallbackQuery: &telego.CallbackQuery{
+			ID:      "cb",
+			From:    telego.User{ID: 777},
+			Message: &telego.Message{MessageID: 2, Chat: telego.Chat{ID: 777, Type: "private"}},
+			Data:    data,
+		}}))
+	}
+
+	// Cancel keeps the data
+	press(keyboard.InlineKeyboard[2][0].CallbackData)
+	assert.FileExists(t, filepath.Join(ws, "sessions", "telegram:777.jsonl"))
+
+	// Export and delete sends the archive, then erases the history
+	press(keyboard.InlineKeyboard[0][0].CallbackData)
+	assert.Equal(t, 1, documents)
+	assert.Contains(t, sent[len(sent)-1].Text, "has been deleted")
+	_, err = os.Stat(filepath.Join(ws, "sessions", "telegram:777.jsonl"))
+	assert.True(t, os.IsNotExist(err))
+	assert.FileExists(t, filepath.Join(ws, privacy.Subdirectory, privacy.TombstonesFilename))
+
+	// Nothing is left to delete
+	require.NoError(t, conn.handleUpdate(textUpdate(777, "/forget_me")))
+	assert.Contains(t, sent[len(sent)-1].Text, "don't store any data")
+}
+
+func TestParseForgetCallback(t *testing.T) {
+	action, requested, ok := parseForgetCallback("forget:export:1700000000")
+	assert.True(t, ok)
+	assert.Equal(t, forgetActionExport, action)
+	assert.Equal(t, int64(1700000000), requested.Unix())
+
+	for _, data := range []string{"forget:delete", "forget:delete:x", "confirm:delete:1"} {
+		_, _, ok := parseForgetCallback(data)
+		assert.False(t, ok, data)
+	}
+}
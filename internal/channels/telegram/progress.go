@@ -0,0 +1,114 @@
+package telegram
+
+import (
+	"fmt"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/mymmrac/telego"
+)
+
+// progressStatus is the status message showing tool progress in a chat
+type progressStatus struct {
+	messageID int
+	text      string
+}
+
+// formatProgress renders a tool progress event as status message text.
+func formatProgress(event bus.Event) string {
+	tool, _ := event.Metadata[bus.MetadataProgressTool].(string)
+	status, _ := event.Metadata[bus.MetadataProgressStatus].(string)
+	percent, ok := event.Metadata[bus.MetadataProgressPercent].(int)
+	if !ok {
+		percent = -1
+	}
+
+	text := "⏳ " + tool
+	if percent >= 0 {
+		text += fmt.Sprintf(" — %d%%", percent)
+	}
+	if status != "" {
+		text += "\n" + status
+	}
+	return text
+}
+
+// showProgress sends a status message with the progress of a running tool,
+// or edits the status message already shown in the chat.
+func (c *Connector) showProgress(event bus.Event) {
+	if !c.cfg.EnableInlineUpdates || c.bot == nil {
+		return
+	}
+	chatID, err := c.extractChatID(event.SessionID)
+	if err != nil {
+		return
+	}
+
+	text := formatProgress(event)
+	sendCtx, cancel := c.getSendTimeout()
+	defer cancel()
+
+	status, ok := c.progress[event.SessionID]
+	if !ok {
+		params := telego.SendMessageParams{
+			ChatID:              telego.ChatID{ID: chatID},
+			Text:                text,
+			DisableNotification: true,
+			ReplyParameters:     c.threadReply(event.SessionID),
+		}
+		sent, err := c.bot.SendMessage(sendCtx, &params)
+		if err != nil || sent == nil {
+			c.logger.WarnCtx(c.ctx, "failed to send progress message",
+				logger.Field{Key: "chat_id", Value: chatID},
+				logger.Field{Key: "error", Value: err})
+			return
+		}
+		if c.progress == nil {
+			c.progress = make(map[string]*progressStatus)
+		}
+		c.progress[event.SessionID] = &progressStatus{messageID: sent.MessageID, text: text}
+		return
+	}
+
+	if status.text == text {
+		return
+	}
+	params := telego.EditMessageTextParams{
+		ChatID:    telego.ChatID{ID: chatID},
+		MessageID: status.messageID,
+		Text:      text,
+	}
+	if _, err := c.bot.EditMessageText(sendCtx, &params); err != nil {
+		c.logger.DebugCtx(c.ctx, "failed to update progress message",
+			logger.Field{Key: "chat_id", Value: chatID},
+			logger.Field{Key: "error", Value: err.Error()})
+		return
+	}
+	status.text = text
+}
+
+// clearProgress deletes the status message of a chat once processing ends.
+func (c *Connector) clearProgress(event bus.Event) {
+	status, ok := c.progress[event.SessionID]
+	if !ok {
+		return
+	}
+	delete(c.progress, event.SessionID)
+
+	chatID, err := c.extractChatID(event.SessionID)
+	if err != nil {
+		return
+	}
+	sendCtx, cancel := c.getSendTimeout()
+	defer cancel()
+
+	params := telego.DeleteMessageParams{
+		ChatID:    telego.ChatID{ID: chatID},
+		MessageID: status.messageID,
+	}
+	if err := c.bot.DeleteMessage(sendCtx, &params); err != nil {
+		c.logger.WarnCtx(c.ctx, "failed to delete progress message",
+			logger.Field{Key: "chat_id", Value: chatID},
+			logger.Field{Key: "error", Value: err.Error()})
+	}
+}
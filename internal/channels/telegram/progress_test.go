@@ -0,0 +1,45 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatProgress(t *testing.T) {
+	event := bus.NewToolProgressEvent(bus.ChannelTypeTelegram, "1", "telegram:42", "web_fetch", 45, "1.2 MB of 2.6 MB")
+	assert.Equal(t, "⏳ web_fetch — 45%\n1.2 MB of 2.6 MB", formatProgress(*event))
+
+	event = bus.NewToolProgressEvent(bus.ChannelTypeTelegram, "1", "telegram:42", "shell_exec", -1, "")
+	assert.Equal(t, "⏳ shell_exec", formatProgress(*event))
+}
+
+func TestConnector_Progress_EditsAndClearsStatus(t *testing.T) {
+	mockBot := NewMockBotSuccess()
+	c := newStreamTestConnector(t, mockBot)
+	c.cfg.EnableInlineUpdates = true
+
+	progress := func(percent int) bus.Event {
+		return *bus.NewToolProgressEvent(bus.ChannelTypeTelegram, "1", "telegram:42", "web_fetch", percent, "")
+	}
+	c.showProgress(progress(10))
+	c.showProgress(progress(10))
+	c.showProgress(progress(60))
+	c.clearProgress(*bus.NewProcessingEndEvent(bus.ChannelTypeTelegram, "1", "telegram:42", nil))
+	// Nothing to clear for a second request without progress
+	c.clearProgress(*bus.NewProcessingEndEvent(bus.ChannelTypeTelegram, "1", "telegram:42", nil))
+
+	assert.Equal(t, []string{"SendMessage", "EditMessageText", "DeleteMessage"}, botCalls(mockBot))
+	edit := mockBot.Calls[1].Arguments.Get(1).(*telego.EditMessageTextParams)
+	assert.Equal(t, "⏳ web_fetch — 60%", edit.Text)
+}
+
+func TestConnector_Progress_InlineUpdatesDisabled(t *testing.T) {
+	mockBot := NewMockBotSuccess()
+	c := newStreamTestConnector(t, mockBot)
+
+	c.showProgress(*bus.NewToolProgressEvent(bus.ChannelTypeTelegram, "1", "telegram:42", "web_fetch", 10, ""))
+	assert.Empty(t, mockBot.Calls)
+}
@@ -0,0 +1,136 @@
+package telegram
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/mymmrac/telego"
+)
+
+// maxStreamLength is the maximum length of a draft answer in runes
+// (Telegram limits messages to 4096 characters).
+const maxStreamLength = 4000
+
+// streamDraft is the message showing a streamed answer while it is generated
+type streamDraft struct {
+	messageID int
+	text      string
+}
+
+// streamID returns the stream an outbound message belongs to, if any.
+func streamID(msg bus.OutboundMessage) string {
+	id, _ := msg.Metadata[bus.MetadataStreamID].(string)
+	return id
+}
+
+// draftText truncates a partial answer to fit into a single message.
+func draftText(content string) string {
+	runes := []rune(strings.TrimSpace(content))
+	if len(runes) <= maxStreamLength {
+		return string(runes)
+	}
+	return string(runes[:maxStreamLength-1]) + "…"
+}
+
+// sendStreamMessage shows a partial answer: the first part is sent as a new
+// message, later parts edit it in place. Drafts are sent as plain text,
+// since unfinished markdown can't be rendered.
+func (c *Connector) sendStreamMessage(msg bus.OutboundMessage, chatID int64) {
+	ctx := msg.LogContext(c.ctx)
+	id := streamID(msg)
+	text := draftText(msg.Content)
+	if id == "" || text == "" {
+		return
+	}
+
+	sendCtx, cancel := c.getSendTimeout()
+	defer cancel()
+
+	draft, ok := c.streams[id]
+	if !ok {
+		params := telego.SendMessageParams{
+			ChatID:              telego.ChatID{ID: chatID},
+			Text:                text,
+			DisableNotification: c.cfg.QuietMode,
+			ReplyParameters:     c.threadReply(msg.SessionID),
+		}
+		sent, err := c.bot.SendMessage(sendCtx, &params)
+		if err != nil || sent == nil {
+			c.logger.WarnCtx(ctx, "failed to send draft answer",
+				logger.Field{Key: "chat_id", Value: chatID},
+				logger.Field{Key: "stream_id", Value: id},
+				logger.Field{Key: "error", Value: err})
+			return
+		}
+		if c.streams == nil {
+			c.streams = make(map[string]*streamDraft)
+		}
+		c.streams[id] = &streamDraft{messageID: sent.MessageID, text: text}
+		return
+	}
+
+	if draft.text == text {
+		return
+	}
+	params := telego.EditMessageTextParams{
+		ChatID:    telego.ChatID{ID: chatID},
+		MessageID: draft.messageID,
+		Text:      text,
+	}
+	if _, err := c.bot.EditMessageText(sendCtx, &params); err != nil {
+		// Rate limited or not modified: the next part or the final answer catches up
+		c.logger.DebugCtx(ctx, "failed to update draft answer",
+			logger.Field{Key: "chat_id", Value: chatID},
+			logger.Field{Key: "stream_id", Value: id},
+			logger.Field{Key: "error", Value: err.Error()})
+		return
+	}
+	draft.text = text
+}
+
+// finishStream replaces the draft of a streamed answer with the final,
+// formatted answer. Returns false if the answer has no draft or the draft
+// could not be edited; the draft is then removed and the answer must be
+// sent as a new message.
+func (c *Connector) finishStream(msg bus.OutboundMessage, chatID int64) bool {
+	ctx := msg.LogContext(c.ctx)
+	id := streamID(msg)
+	draft, ok := c.streams[id]
+	if !ok {
+		return false
+	}
+	delete(c.streams, id)
+
+	sendCtx, cancel := c.getSendTimeout()
+	defer cancel()
+
+	if len([]rune(msg.Content)) <= maxStreamLength {
+		params := c.prepareEditMessageParams(msg.Content, chatID, strconv.Itoa(draft.messageID), msg.Format)
+		if msg.InlineKeyboard != nil && c.cfg.EnableInlineKeyboard {
+			params.ReplyMarkup = c.buildInlineKeyboard(msg.InlineKeyboard)
+		}
+		_, err := c.bot.EditMessageText(sendCtx, &params)
+		if err == nil || strings.Contains(err.Error(), "message is not modified") {
+			c.publishSent(msg, chatID, &telego.Message{MessageID: draft.messageID})
+			return true
+		}
+		c.logger.WarnCtx(ctx, "failed to finish draft answer, sending it as a new message",
+			logger.Field{Key: "chat_id", Value: chatID},
+			logger.Field{Key: "stream_id", Value: id},
+			logger.Field{Key: "error", Value: err.Error()})
+	}
+
+	params := telego.DeleteMessageParams{
+		ChatID:    telego.ChatID{ID: chatID},
+		MessageID: draft.messageID,
+	}
+	if err := c.bot.DeleteMessage(sendCtx, &params); err != nil {
+		c.logger.WarnCtx(ctx, "failed to delete draft answer",
+			logger.Field{Key: "chat_id", Value: chatID},
+			logger.Field{Key: "stream_id", Value: id},
+			logger.Field{Key: "error", Value: err.Error()})
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamTestConnector(t *testing.T, mockBot *MockBot) *Connector {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	require.NoError(t, err)
+	return &Connector{
+		cfg:    config.TelegramConfig{SendTimeoutSeconds: 5},
+		ctx:    context.Background(),
+		logger: log,
+		bus:    bus.New(10, 10, log),
+		bot:    mockBot,
+	}
+}
+
+func finalAnswer(content, streamID string) bus.OutboundMessage {
+	return *bus.NewOutboundMessage(bus.ChannelTypeTelegram, "1", "telegram:42", content, "corr",
+		bus.FormatTypePlain, map[string]any{bus.MetadataStreamID: streamID})
+}
+
+// botCalls returns the names of the bot methods called, in order.
+func botCalls(mockBot *MockBot) []string {
+	var names []string
+	for _, call := range mockBot.Calls {
+		names = append(names, call.Method)
+	}
+	return names
+}
+
+func TestConnector_Stream_EditsDraftInPlace(t *testing.T) {
+	mockBot := NewMockBotSuccess()
+	c := newStreamTestConnector(t, mockBot)
+
+	c.sendStreamMessage(*bus.NewStreamMessage(bus.ChannelTypeTelegram, "1", "telegram:42", "s1", "Hel"), 42)
+	c.sendStreamMessage(*bus.NewStreamMessage(bus.ChannelTypeTelegram, "1", "telegram:42", "s1", "Hello"), 42)
+	// Unchanged text is not edited again
+	c.sendStreamMessage(*bus.NewStreamMessage(bus.ChannelTypeTelegram, "1", "telegram:42", "s1", "Hello"), 42)
+
+	assert.True(t, c.finishStream(finalAnswer("Hello!", "s1"), 42))
+	assert.Equal(t, []string{"SendMessage", "EditMessageText", "EditMessageText"}, botCalls(mockBot))
+
+	final := mockBot.Calls[2].Arguments.Get(1).(*telego.EditMessageTextParams)
+	assert.Equal(t, 1, final.MessageID)
+	assert.Equal(t, "Hello!", final.Text)
+	assert.Empty(t, c.streams)
+}
+
+func TestConnector_Stream_FinishWithoutDraft(t *testing.T) {
+	mockBot := NewMockBotSuccess()
+	c := newStreamTestConnector(t, mockBot)
+
+	assert.False(t, c.finishStream(finalAnswer("Hello", "s1"), 42))
+	assert.False(t, c.finishStream(finalAnswer("Hello", ""), 42))
+	assert.Empty(t, mockBot.Calls)
+}
+
+func TestConnector_Stream_LongAnswerReplacesDraft(t *testing.T) {
+	mockBot := NewMockBotSuccess()
+	c := newStreamTestConnector(t, mockBot)
+
+	long := strings.Repeat("a", maxStreamLength+10)
+	c.sendStreamMessage(*bus.NewStreamMessage(bus.ChannelTypeTelegram, "1", "telegram:42", "s1", long), 42)
+	draft := mockBot.Calls[0].Arguments.Get(1).(*telego.SendMessageParams)
+	assert.Len(t, []rune(draft.Text), maxStreamLength)
+
+	assert.False(t, c.finishStream(finalAnswer(long, "s1"), 42))
+	assert.Equal(t, []string{"SendMessage", "DeleteMessage"}, botCalls(mockBot))
+}
+
+func TestConnector_Stream_FailedEditReplacesDraft(t *testing.T) {
+	mockBot := new(MockBot)
+	mockBot.On("SendMessage", mock.Anything, mock.Anything).Return(&telego.Message{MessageID: 7}, nil)
+	mockBot.On("EditMessageText", mock.Anything, mock.Anything).Return(nil, errors.New("can't parse entities"))
+	mockBot.On("DeleteMessage", mock.Anything, mock.Anything).Return(nil)
+	c := newStreamTestConnector(t, mockBot)
+
+	c.sendStreamMessage(*bus.NewStreamMessage(bus.ChannelTypeTelegram, "1", "telegram:42", "s1", "Hello"), 42)
+	assert.False(t, c.finishStream(finalAnswer("**Hello**", "s1"), 42))
+
+	deleted := mockBot.Calls[2].Arguments.Get(1).(*telego.DeleteMessageParams)
+	assert.Equal(t, 7, deleted.MessageID)
+}
@@ -0,0 +1,39 @@
+package telegram
+
+import (
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+)
+
+// renderTable appends a table to message content as a monospace block.
+// Telegram has no tables, and pipe tables in Markdown are mangled by the
+// markdown fallbacks, so the table is aligned as plain text inside a
+// preformatted block. Plain content is converted to HTML to carry the block.
+func renderTable(content string, format bus.FormatType, table *bus.Table) (string, bus.FormatType) {
+	text := table.Text()
+	join := func(block string) string {
+		if strings.TrimSpace(content) == "" {
+			return block
+		}
+		return content + "\n\n" + block
+	}
+
+	switch format {
+	case bus.FormatTypeHTML:
+		return join("<pre>" + htmlEscape(text) + "</pre>"), format
+	case bus.FormatTypeMarkdown:
+		return join("```\n" + text + "\n```"), format
+	case bus.FormatTypeMarkdownV2:
+		// Only ` and \ must be escaped inside pre blocks
+		escaped := strings.NewReplacer(`\`, `\\`, "`", "\\`").Replace(text)
+		return join("```\n" + escaped + "\n```"), format
+	default:
+		if DetectContentType(content) == ContentTypePlain {
+			content = htmlEscape(content)
+		} else {
+			content = MarkdownToHTML(content)
+		}
+		return join("<pre>" + htmlEscape(text) + "</pre>"), bus.FormatTypeHTML
+	}
+}
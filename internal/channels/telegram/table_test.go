@@ -0,0 +1,36 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+)
+
+// TestRenderTable tests tables rendered as monospace blocks for each format
+func TestRenderTable(t *testing.T) {
+	table := &bus.Table{Headers: []string{"Host", "Used"}, Rows: [][]string{{"<nas>", "93%"}}}
+	const text = "Host   Used\n-----  ----\n<nas>   93%"
+
+	tests := []struct {
+		name       string
+		content    string
+		format     bus.FormatType
+		want       string
+		wantFormat bus.FormatType
+	}{
+		{"plain", "Disks & usage:", bus.FormatTypePlain,
+			"Disks &amp; usage:\n\n<pre>Host   Used\n-----  ----\n&lt;nas&gt;   93%</pre>", bus.FormatTypeHTML},
+		{"html", "<b>Disks</b>", bus.FormatTypeHTML,
+			"<b>Disks</b>\n\n<pre>Host   Used\n-----  ----\n&lt;nas&gt;   93%</pre>", bus.FormatTypeHTML},
+		{"markdown", "*Disks*", bus.FormatTypeMarkdown, "*Disks*\n\n```\n" + text + "\n```", bus.FormatTypeMarkdown},
+		{"table only", "", bus.FormatTypeMarkdownV2, "```\n" + text + "\n```", bus.FormatTypeMarkdownV2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, format := renderTable(tt.content, tt.format, table)
+			if got != tt.want || format != tt.wantFormat {
+				t.Errorf("renderTable() = %q, %q; want %q, %q", got, format, tt.want, tt.wantFormat)
+			}
+		})
+	}
+}
@@ -38,8 +38,32 @@ type BotInterface interface {
 	// SendDocument sends a document to a chat.
 	SendDocument(ctx context.Context, params *telego.SendDocumentParams) (*telego.Message, error)
 
+	// SendSticker sends a sticker to a chat.
+	SendSticker(ctx context.Context, params *telego.SendStickerParams) (*telego.Message, error)
+
+	// SendAnimation sends an animation (GIF or silent MPEG-4 video) to a chat.
+	SendAnimation(ctx context.Context, params *telego.SendAnimationParams) (*telego.Message, error)
+
+	// PinChatMessage pins a message in a chat.
+	PinChatMessage(ctx context.Context, params *telego.PinChatMessageParams) error
+
+	// UnpinChatMessage unpins a message in a chat.
+	UnpinChatMessage(ctx context.Context, params *telego.UnpinChatMessageParams) error
+
+	// SetChatTitle changes the title of a chat.
+	SetChatTitle(ctx context.Context, params *telego.SetChatTitleParams) error
+
+	// GetChatMember returns information about a member of a chat.
+	GetChatMember(ctx context.Context, params *telego.GetChatMemberParams) (telego.ChatMember, error)
+
 	// AnswerCallbackQuery answers a callback query sent from inline keyboards.
 	AnswerCallbackQuery(ctx context.Context, params *telego.AnswerCallbackQueryParams) error
+
+	// GetFile returns information about a file for downloading.
+	GetFile(ctx context.Context, params *telego.GetFileParams) (*telego.File, error)
+
+	// FileDownloadURL returns the download URL of a file path returned by GetFile.
+	FileDownloadURL(filepath string) string
 }
 
 // telegoAdapter wraps telego.Bot to implement BotInterface.
@@ -101,7 +125,47 @@ func (a *telegoAdapter) SendDocument(ctx context.Context, params *telego.SendDoc
 	return a.bot.SendDocument(ctx, params)
 }
 
+// SendSticker sends a sticker to a chat.
+func (a *telegoAdapter) SendSticker(ctx context.Context, params *telego.SendStickerParams) (*telego.Message, error) {
+	return a.bot.SendSticker(ctx, params)
+}
+
+// SendAnimation sends an animation (GIF or silent MPEG-4 video) to a chat.
+func (a *telegoAdapter) SendAnimation(ctx context.Context, params *telego.SendAnimationParams) (*telego.Message, error) {
+	return a.bot.SendAnimation(ctx, params)
+}
+
+// PinChatMessage pins a message in a chat.
+func (a *telegoAdapter) PinChatMessage(ctx context.Context, params *telego.PinChatMessageParams) error {
+	return a.bot.PinChatMessage(ctx, params)
+}
+
+// UnpinChatMessage unpins a message in a chat.
+func (a *telegoAdapter) UnpinChatMessage(ctx context.Context, params *telego.UnpinChatMessageParams) error {
+	return a.bot.UnpinChatMessage(ctx, params)
+}
+
+// SetChatTitle changes the title of a chat.
+func (a *telegoAdapter) SetChatTitle(ctx context.Context, params *telego.SetChatTitleParams) error {
+	return a.bot.SetChatTitle(ctx, params)
+}
+
+// GetChatMember returns information about a member of a chat.
+func (a *telegoAdapter) GetChatMember(ctx context.Context, params *telego.GetChatMemberParams) (telego.ChatMember, error) {
+	return a.bot.GetChatMember(ctx, params)
+}
+
 // AnswerCallbackQuery answers a callback query sent from inline keyboards.
 func (a *telegoAdapter) AnswerCallbackQuery(ctx context.Context, params *telego.AnswerCallbackQueryParams) error {
 	return a.bot.AnswerCallbackQuery(ctx, params)
 }
+
+// GetFile returns information about a file for downloading.
+func (a *telegoAdapter) GetFile(ctx context.Context, params *telego.GetFileParams) (*telego.File, error) {
+	return a.bot.GetFile(ctx, params)
+}
+
+// FileDownloadURL returns the download URL of a file path returned by GetFile.
+func (a *telegoAdapter) FileDownloadURL(filepath string) string {
+	return a.bot.FileDownloadURL(filepath)
+}
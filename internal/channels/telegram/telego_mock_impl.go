@@ -87,12 +87,72 @@ func (m *MockBot) SendDocument(ctx context.Context, params *telego.SendDocumentP
 	return args.Get(0).(*telego.Message), args.Error(1)
 }
 
+// SendSticker sends a sticker to a chat.
+func (m *MockBot) SendSticker(ctx context.Context, params *telego.SendStickerParams) (*telego.Message, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*telego.Message), args.Error(1)
+}
+
+// SendAnimation sends an animation (GIF or silent MPEG-4 video) to a chat.
+func (m *MockBot) SendAnimation(ctx context.Context, params *telego.SendAnimationParams) (*telego.Message, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*telego.Message), args.Error(1)
+}
+
+// PinChatMessage pins a message in a chat.
+func (m *MockBot) PinChatMessage(ctx context.Context, params *telego.PinChatMessageParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
+// UnpinChatMessage unpins a message in a chat.
+func (m *MockBot) UnpinChatMessage(ctx context.Context, params *telego.UnpinChatMessageParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
+// SetChatTitle changes the title of a chat.
+func (m *MockBot) SetChatTitle(ctx context.Context, params *telego.SetChatTitleParams) error {
+	args := m.Called(ctx, params)
+	return args.Error(0)
+}
+
+// GetChatMember returns information about a member of a chat.
+func (m *MockBot) GetChatMember(ctx context.Context, params *telego.GetChatMemberParams) (telego.ChatMember, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(telego.ChatMember), args.Error(1)
+}
+
 // AnswerCallbackQuery answers a callback query sent from inline keyboards.
 func (m *MockBot) AnswerCallbackQuery(ctx context.Context, params *telego.AnswerCallbackQueryParams) error {
 	args := m.Called(ctx, params)
 	return args.Error(0)
 }
 
+// GetFile returns information about a file for downloading.
+func (m *MockBot) GetFile(ctx context.Context, params *telego.GetFileParams) (*telego.File, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*telego.File), args.Error(1)
+}
+
+// FileDownloadURL returns the download URL of a file path returned by GetFile.
+func (m *MockBot) FileDownloadURL(filepath string) string {
+	args := m.Called(filepath)
+	return args.String(0)
+}
+
 // NewMockBotSuccess creates a MockBot that returns success for all operations.
 // This is a helper function for tests that don't need to verify specific behavior.
 // All expectations are optional (.Maybe()), so only called methods are checked.
@@ -131,6 +191,25 @@ func NewMockBotSuccess() *MockBot {
 		Document:  &telego.Document{FileID: "test"},
 	}, nil).Maybe()
 
+	mockBot.On("SendSticker", mock.Anything, mock.Anything).Return(&telego.Message{
+		MessageID: 4,
+		Sticker:   &telego.Sticker{FileID: "test"},
+	}, nil).Maybe()
+
+	mockBot.On("SendAnimation", mock.Anything, mock.Anything).Return(&telego.Message{
+		MessageID: 5,
+		Animation: &telego.Animation{FileID: "test"},
+	}, nil).Maybe()
+
+	mockBot.On("PinChatMessage", mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockBot.On("UnpinChatMessage", mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockBot.On("SetChatTitle", mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockBot.On("GetChatMember", mock.Anything, mock.Anything).Return(&telego.ChatMemberAdministrator{
+		Status:         telego.MemberStatusAdministrator,
+		CanPinMessages: true,
+		CanChangeInfo:  true,
+	}, nil).Maybe()
+
 	mockBot.On("AnswerCallbackQuery", mock.Anything, mock.Anything).Return(nil).Maybe()
 
 	return mockBot
@@ -149,7 +228,13 @@ func NewMockBotError(err error) *MockBot {
 	mockBot.On("DeleteMessage", mock.Anything, mock.Anything).Return(err).Maybe()
 	mockBot.On("SendPhoto", mock.Anything, mock.Anything).Return((*telego.Message)(nil), err).Maybe()
 	mockBot.On("SendDocument", mock.Anything, mock.Anything).Return((*telego.Message)(nil), err).Maybe()
+	mockBot.On("SendSticker", mock.Anything, mock.Anything).Return((*telego.Message)(nil), err).Maybe()
+	mockBot.On("SendAnimation", mock.Anything, mock.Anything).Return((*telego.Message)(nil), err).Maybe()
 	mockBot.On("AnswerCallbackQuery", mock.Anything, mock.Anything).Return(err).Maybe()
+	mockBot.On("PinChatMessage", mock.Anything, mock.Anything).Return(err).Maybe()
+	mockBot.On("UnpinChatMessage", mock.Anything, mock.Anything).Return(err).Maybe()
+	mockBot.On("SetChatTitle", mock.Anything, mock.Anything).Return(err).Maybe()
+	mockBot.On("GetChatMember", mock.Anything, mock.Anything).Return(nil, err).Maybe()
 
 	return mockBot
 }
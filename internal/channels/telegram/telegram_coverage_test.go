@@ -1683,3 +1683,59 @@ func TestConnector_handleEvents_ClosedChannel(t *testing.T) {
 	cancel()
 	time.Sleep(100 * time.Millisecond)
 }
+
+// TestUpdateHandler_MessageReaction tests that new reactions are published as feedback
+func TestUpdateHandler_MessageReaction(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	log, _ := logger.New(logger.Config{
+		Level:  "debug",
+		Format: "text",
+		Output: "stdout",
+	})
+	msgBus := bus.New(10, 10, log)
+	require.NoError(t, msgBus.Start(ctx))
+	inboundCh := msgBus.SubscribeInbound(ctx)
+
+	conn := New(config.TelegramConfig{AllowedUsers: []string{"42"}}, log, msgBus)
+	conn.ctx = ctx
+	handler := NewUpdateHandler(conn, log, msgBus)
+
+	// Reaction from a user outside the whitelist is ignored
+	require.NoError(t, handler.Handle(telego.Update{MessageReaction: &telego.MessageReactionUpdated{
+		Chat:        telego.Chat{ID: 100},
+		MessageID:   7,
+		User:        &telego.User{ID: 99},
+		NewReaction: []telego.ReactionType{&telego.ReactionTypeEmoji{Type: "emoji", Emoji: "👍"}},
+	}}))
+
+	// Only the newly added reaction is published
+	require.NoError(t, handler.Handle(telego.Update{MessageReaction: &telego.MessageReactionUpdated{
+		Chat:        telego.Chat{ID: 100},
+		MessageID:   7,
+		User:        &telego.User{ID: 42},
+		OldReaction: []telego.ReactionType{&telego.ReactionTypeEmoji{Type: "emoji", Emoji: "🔥"}},
+		NewReaction: []telego.ReactionType{
+			&telego.ReactionTypeEmoji{Type: "emoji", Emoji: "🔥"},
+			&telego.ReactionTypeEmoji{Type: "emoji", Emoji: "👎"},
+		},
+	}}))
+
+	select {
+	case msg := <-inboundCh:
+		require.Equal(t, "42", msg.UserID)
+		require.Equal(t, "telegram:100", msg.SessionID)
+		require.Equal(t, "feedback", msg.Metadata["command"])
+		require.Equal(t, "👎", msg.Metadata["reaction"])
+		require.Equal(t, 7, msg.Metadata["message_id"])
+	case <-time.After(time.Second):
+		t.Fatal("Expected reaction to be published")
+	}
+
+	select {
+	case msg := <-inboundCh:
+		t.Fatalf("Unexpected extra message: %+v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
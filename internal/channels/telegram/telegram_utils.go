@@ -2,9 +2,9 @@
 package telegram
 
 import (
-	"slices"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // ContentType represents the type of text content.
@@ -51,26 +51,25 @@ func DetectContentType(text string) ContentType {
 	}
 
 	// Check for single char markdown (italic, link, etc.)
-	singleCharPatterns := []rune{'*', '_', '[', '~'}
-	runes := []rune(text)
-	for i := 0; i < len(runes); i++ {
-		r := runes[i]
+	// The patterns are ASCII, so the text is scanned by bytes
+	for i := 0; i < len(text); i++ {
+		c := text[i]
 		// Skip escaped characters
-		if r == '\\' && i+1 < len(runes) {
-			i++
+		if c == '\\' && i+1 < len(text) {
+			_, size := utf8.DecodeRuneInString(text[i+1:])
+			i += size
 			continue
 		}
 
-		for _, pattern := range singleCharPatterns {
-			if r == pattern {
-				// For * and _, check if it's part of a pair (bold/underline)
-				if r == '*' || r == '_' {
-					if i+1 < len(runes) && runes[i+1] == r {
-						continue // Skip as it's handled by multi-char patterns
-					}
-				}
-				return ContentTypeMarkdown
+		switch c {
+		case '*', '_':
+			// Skip pairs (bold/underline), handled by multi-char patterns
+			if i+1 < len(text) && text[i+1] == c {
+				continue
 			}
+			return ContentTypeMarkdown
+		case '[', '~':
+			return ContentTypeMarkdown
 		}
 	}
 
@@ -79,8 +78,14 @@ func DetectContentType(text string) ContentType {
 
 // containsNonEscaped checks if pattern exists in text without being escaped.
 func containsNonEscaped(text, pattern string) bool {
-	idx := strings.Index(text, pattern)
-	for idx != -1 {
+	offset := 0
+	for {
+		idx := strings.Index(text[offset:], pattern)
+		if idx == -1 {
+			return false
+		}
+		idx += offset
+
 		// Check if this pattern is escaped
 		backslashes := 0
 		for i := idx - 1; i >= 0 && text[i] == '\\'; i-- {
@@ -90,19 +95,16 @@ func containsNonEscaped(text, pattern string) bool {
 		if backslashes%2 == 0 {
 			return true // Found non-escaped pattern
 		}
-		// Look for next occurrence
-		idx = strings.Index(text[idx+len(pattern):], pattern)
-		if idx != -1 {
-			idx += len(pattern)
-		}
+		// Look for next occurrence after the escaped one
+		offset = idx + len(pattern)
 	}
-	return false
 }
 
 // PreprocessMarkdownV2 preprocesses text for Telegram MarkdownV2 format.
 // It escapes special characters while preserving code blocks.
 func PreprocessMarkdownV2(text string) string {
 	var result strings.Builder
+	result.Grow(len(text) + len(text)/4)
 	lines := strings.Split(text, "\n")
 	inCodeBlock := false
 
@@ -146,6 +148,7 @@ func PreprocessMarkdownV2(text string) string {
 // processInlineCodeAndEscape processes inline code blocks and escapes special characters.
 func processInlineCodeAndEscape(line string) string {
 	var result strings.Builder
+	result.Grow(len(line) + len(line)/4)
 	runes := []rune(line)
 	i := 0
 
@@ -212,9 +215,17 @@ func processInlineCodeAndEscape(line string) string {
 	return result.String()
 }
 
+// markdownV2Special is a lookup table of MarkdownV2SpecialChars, all ASCII.
+var markdownV2Special = func() (table [utf8.RuneSelf]bool) {
+	for _, r := range MarkdownV2SpecialChars {
+		table[r] = true
+	}
+	return table
+}()
+
 // isMarkdownV2SpecialChar checks if a rune needs escaping in MarkdownV2.
 func isMarkdownV2SpecialChar(r rune) bool {
-	return slices.Contains(MarkdownV2SpecialChars, r)
+	return r < utf8.RuneSelf && markdownV2Special[r]
 }
 
 // MarkdownToHTML converts markdown text to HTML format for Telegram.
@@ -224,7 +235,7 @@ func MarkdownToHTML(markdown string) string {
 		return ""
 	}
 
-	html := markdown
+	html := validUTF8(markdown)
 
 	// Process code blocks first (```)
 	html = processCodeBlocks(html, true)
@@ -257,7 +268,7 @@ func StripFormatting(text string) string {
 		return ""
 	}
 
-	plain := text
+	plain := validUTF8(text)
 
 	// Process code blocks first (```)
 	plain = processCodeBlocks(plain, false)
@@ -283,379 +294,283 @@ func StripFormatting(text string) string {
 	return plain
 }
 
+// validUTF8 replaces every invalid byte with U+FFFD. The conversion passes
+// work on bytes: markdown delimiters are ASCII and never occur inside a
+// multi-byte sequence of valid UTF-8.
+func validUTF8(text string) string {
+	if utf8.ValidString(text) {
+		return text
+	}
+	return string([]rune(text))
+}
+
+// delimiterFinder finds the next occurrence of a delimiter. Passes scan the
+// text forward, so the result of the last search is reused while it is still
+// ahead: text with many unmatched openers (snake_case identifiers in code,
+// stray brackets) is searched once instead of once per opener.
+type delimiterFinder struct {
+	text  string
+	delim string
+	from  int // Start of the last search, -1 before the first one
+	found int // Result of the last search
+}
+
+func newDelimiterFinder(text, delim string) *delimiterFinder {
+	return &delimiterFinder{text: text, delim: delim, from: -1}
+}
+
+// next returns the index of the first occurrence at or after start, or -1.
+func (f *delimiterFinder) next(start int) int {
+	if f.from >= 0 && start >= f.from && (f.found == -1 || start <= f.found) {
+		return f.found
+	}
+
+	f.from, f.found = start, -1
+	if start <= len(f.text) {
+		if idx := strings.Index(f.text[start:], f.delim); idx != -1 {
+			f.found = start + idx
+		}
+	}
+	return f.found
+}
+
 // processCodeBlocks processes code blocks (```).
 // If htmlMode is true, converts to <pre><code>; otherwise removes formatting.
 func processCodeBlocks(text string, htmlMode bool) string {
+	fence := newDelimiterFinder(text, "```")
+	if fence.next(0) == -1 {
+		return text
+	}
+
 	var result strings.Builder
-	runes := []rune(text)
+	result.Grow(len(text) + len(text)/8)
 	i := 0
-
-	for i < len(runes) {
-		// Look for code block start
-		if i+2 < len(runes) && runes[i] == '`' && runes[i+1] == '`' && runes[i+2] == '`' {
-			start := i + 3
-
-			// Skip language identifier and whitespace
-			for start < len(runes) && !unicode.IsSpace(runes[start]) {
-				start++
-			}
-			for start < len(runes) && unicode.IsSpace(runes[start]) {
-				start++
+	for i < len(text) {
+		// Copy the text up to the code block start
+		open := fence.next(i)
+		if open == -1 {
+			result.WriteString(text[i:])
+			break
+		}
+		result.WriteString(text[i:open])
+		start := open + 3
+
+		// Skip language identifier and whitespace
+		for start < len(text) {
+			r, size := utf8.DecodeRuneInString(text[start:])
+			if unicode.IsSpace(r) {
+				break
 			}
-
-			// Find code block end
-			end := start
-			for end < len(runes)-2 {
-				if runes[end] == '`' && runes[end+1] == '`' && runes[end+2] == '`' {
-					break
-				}
-				end++
+			start += size
+		}
+		for start < len(text) {
+			r, size := utf8.DecodeRuneInString(text[start:])
+			if !unicode.IsSpace(r) {
+				break
 			}
+			start += size
+		}
 
-			// Extract code content (skip trailing newline)
-			codeContent := string(runes[start:end])
-			if len(codeContent) > 0 && codeContent[len(codeContent)-1] == '\n' {
-				codeContent = codeContent[:len(codeContent)-1]
-			}
+		// Find code block end; an unclosed block runs to the end of the text
+		end := fence.next(start)
+		next := end + 3
+		if end == -1 {
+			end = len(text)
+			next = len(text)
+		}
 
-			if htmlMode {
-				result.WriteString("<pre><code>")
-				result.WriteString(htmlEscape(codeContent))
-				result.WriteString("</code></pre>")
-			} else {
-				result.WriteString(codeContent)
-			}
+		// Extract code content (skip trailing newline)
+		codeContent := strings.TrimSuffix(text[start:end], "\n")
 
-			i = end + 3
-			continue
+		if htmlMode {
+			result.WriteString("<pre><code>")
+			htmlReplacer.WriteString(&result, codeContent)
+			result.WriteString("</code></pre>")
+		} else {
+			result.WriteString(codeContent)
 		}
-
-		result.WriteRune(runes[i])
-		i++
+		i = next
 	}
-
 	return result.String()
 }
 
 // processInlineCode processes inline code (`).
 // If htmlMode is true, converts to <code>; otherwise removes formatting.
 func processInlineCode(text string, htmlMode bool) string {
+	if !strings.Contains(text, "`") {
+		return text
+	}
+
 	var result strings.Builder
+	result.Grow(len(text) + len(text)/8)
 	inCode := false
-	runes := []rune(text)
-
-	for i := 0; i < len(runes); i++ {
-		if runes[i] == '`' {
-			inCode = !inCode
-			if htmlMode {
-				if inCode {
-					// Check if this is an empty inline code (next char is also backtick)
-					if i+1 < len(runes) && runes[i+1] == '`' {
-						result.WriteString("``")
-						i++
-						continue
-					}
-					result.WriteString("<code>")
-				} else {
-					result.WriteString("</code>")
-				}
-			}
-			continue
+	i := 0
+	for i < len(text) {
+		// Copy the text up to the next backtick
+		idx := strings.IndexByte(text[i:], '`')
+		if idx == -1 {
+			result.WriteString(text[i:])
+			break
 		}
+		result.WriteString(text[i : i+idx])
+		i += idx + 1
 
-		// Always write the character, only add HTML tags if htmlMode is true
-		result.WriteRune(runes[i])
+		inCode = !inCode
+		if !htmlMode {
+			continue
+		}
+		if inCode {
+			// Check if this is an empty inline code (next char is also backtick)
+			if i < len(text) && text[i] == '`' {
+				result.WriteString("``")
+				i++
+				continue
+			}
+			result.WriteString("<code>")
+		} else {
+			result.WriteString("</code>")
+		}
 	}
-
 	return result.String()
 }
 
 // processBold processes bold text (**text** or __text__).
 // If htmlMode is true, converts to <b>; otherwise removes formatting.
 func processBold(text string, htmlMode bool) string {
-	// Process **text** first
-	var result1 strings.Builder
-	runes := []rune(text)
-	i := 0
-
-	for i < len(runes) {
-		// Look for ** pattern
-		if i+1 < len(runes) && runes[i] == '*' && runes[i+1] == '*' {
-			start := i + 2
-
-			// Find closing **
-			end := start
-			for end < len(runes)-1 {
-				if runes[end] == '*' && runes[end+1] == '*' {
-					break
-				}
-				end++
-			}
-
-			if end < len(runes)-1 {
-				content := string(runes[start:end])
-				if htmlMode {
-					result1.WriteString("<b>")
-					result1.WriteString(content)
-					result1.WriteString("</b>")
-				} else {
-					result1.WriteString(content)
-				}
-				i = end + 2
-				continue
-			}
-		}
-
-		result1.WriteRune(runes[i])
-		i++
-	}
-
-	// Process __text__ (alternative bold)
-	var result2 strings.Builder
-	runes = []rune(result1.String())
-	i = 0
-
-	for i < len(runes) {
-		// Look for __ pattern
-		if i+1 < len(runes) && runes[i] == '_' && runes[i+1] == '_' {
-			start := i + 2
-
-			// Find closing __
-			end := start
-			for end < len(runes)-1 {
-				if runes[end] == '_' && runes[end+1] == '_' {
-					break
-				}
-				end++
-			}
-
-			if end < len(runes)-1 {
-				content := string(runes[start:end])
-				if htmlMode {
-					result2.WriteString("<b>")
-					result2.WriteString(content)
-					result2.WriteString("</b>")
-				} else {
-					result2.WriteString(content)
-				}
-				i = end + 2
-				continue
-			}
-		}
-
-		result2.WriteRune(runes[i])
-		i++
-	}
-
-	return result2.String()
+	text = processPairs(text, "b", false, htmlMode, "**")
+	return processPairs(text, "b", false, htmlMode, "__")
 }
 
 // processItalic processes italic text (*text* or _text_).
 // If htmlMode is true, converts to <i>; otherwise removes formatting.
 func processItalic(text string, htmlMode bool) string {
-	var result strings.Builder
-	runes := []rune(text)
-	i := 0
-
-	for i < len(runes) {
-		// Look for * or _ pattern (single character)
-		if runes[i] == '*' || runes[i] == '_' {
-			char := runes[i]
-			start := i + 1
-
-			// Find closing * or _
-			end := start
-			for end < len(runes) {
-				if runes[end] == char {
-					break
-				}
-				end++
-			}
-
-			if end < len(runes) && start < end {
-				content := string(runes[start:end])
-				if htmlMode {
-					result.WriteString("<i>")
-					result.WriteString(content)
-					result.WriteString("</i>")
-				} else {
-					result.WriteString(content)
-				}
-				i = end + 1
-				continue
-			}
-		}
-
-		result.WriteRune(runes[i])
-		i++
-	}
-
-	return result.String()
+	return processPairs(text, "i", true, htmlMode, "*", "_")
 }
 
 // processStrikethrough processes strikethrough text (~~text~~).
 // If htmlMode is true, converts to <s>; otherwise removes formatting.
 func processStrikethrough(text string, htmlMode bool) string {
-	var result strings.Builder
-	runes := []rune(text)
-	i := 0
+	return processPairs(text, "s", false, htmlMode, "~~")
+}
 
-	for i < len(runes) {
-		// Look for ~~ pattern
-		if i+1 < len(runes) && runes[i] == '~' && runes[i+1] == '~' {
-			start := i + 2
-
-			// Find closing ~~
-			end := start
-			for end < len(runes)-1 {
-				if runes[end] == '~' && runes[end+1] == '~' {
-					break
-				}
-				end++
-			}
+// processUnderline processes underline text (__text__).
+// If htmlMode is true, converts to <u>; otherwise removes formatting.
+func processUnderline(text string, htmlMode bool) string {
+	return processPairs(text, "u", false, htmlMode, "__")
+}
 
-			if end < len(runes)-1 {
-				content := string(runes[start:end])
-				if htmlMode {
-					result.WriteString("<s>")
-					result.WriteString(content)
-					result.WriteString("</s>")
-				} else {
-					result.WriteString(content)
-				}
-				i = end + 2
-				continue
-			}
+// processPairs processes text between pairs of delimiters in one pass, the
+// nearest delimiter winning. If htmlMode is true, wraps the text in the tag;
+// otherwise removes formatting. With nonEmpty, adjacent delimiters are left
+// as is.
+func processPairs(text, tag string, nonEmpty, htmlMode bool, delims ...string) string {
+	finders := make([]*delimiterFinder, 0, len(delims))
+	for _, delim := range delims {
+		if f := newDelimiterFinder(text, delim); f.next(0) != -1 {
+			finders = append(finders, f)
 		}
-
-		result.WriteRune(runes[i])
-		i++
+	}
+	if len(finders) == 0 {
+		return text
 	}
 
-	return result.String()
-}
-
-// processLinks processes links [text](url).
-// If htmlMode is true, converts to <a href="url">text</a>; otherwise returns text only.
-func processLinks(text string, htmlMode bool) string {
 	var result strings.Builder
-	runes := []rune(text)
+	result.Grow(len(text) + len(text)/8)
 	i := 0
-
-	for i < len(runes) {
-		// Look for [ pattern
-		if runes[i] == '[' {
-			start := i + 1
-
-			// Find closing ]
-			end := start
-			for end < len(runes) {
-				if runes[end] == ']' {
-					break
-				}
-				end++
-			}
-
-			if end < len(runes) && end+1 < len(runes) && runes[end+1] == '(' {
-				// Extract link text
-				linkText := string(runes[start:end])
-
-				// Extract URL
-				urlStart := end + 2
-				urlEnd := urlStart
-				for urlEnd < len(runes) {
-					if runes[urlEnd] == ')' {
-						break
-					}
-					urlEnd++
-				}
-
-				if urlEnd < len(runes) {
-					url := string(runes[urlStart:urlEnd])
-					if htmlMode {
-						result.WriteString(`<a href="`)
-						result.WriteString(htmlEscape(url))
-						result.WriteString(`">`)
-						result.WriteString(linkText)
-						result.WriteString(`</a>`)
-					} else {
-						result.WriteString(linkText)
-					}
-					i = urlEnd + 1
-					continue
-				}
+	for i < len(text) {
+		// Copy the text up to the nearest opening delimiter
+		open, f := -1, finders[0]
+		for _, candidate := range finders {
+			if next := candidate.next(i); next != -1 && (open == -1 || next < open) {
+				open, f = next, candidate
 			}
 		}
+		if open == -1 {
+			result.WriteString(text[i:])
+			break
+		}
+		result.WriteString(text[i:open])
+
+		// Find the closing delimiter
+		start := open + len(f.delim)
+		end := f.next(start)
+		if end == -1 || (nonEmpty && start == end) {
+			result.WriteByte(text[open])
+			i = open + 1
+			continue
+		}
 
-		result.WriteRune(runes[i])
-		i++
+		if htmlMode {
+			result.WriteString("<" + tag + ">")
+			result.WriteString(text[start:end])
+			result.WriteString("</" + tag + ">")
+		} else {
+			result.WriteString(text[start:end])
+		}
+		i = end + len(f.delim)
 	}
-
 	return result.String()
 }
 
-// processUnderline processes underline text (__text__).
-// If htmlMode is true, converts to <u>; otherwise removes formatting.
-func processUnderline(text string, htmlMode bool) string {
+// processLinks processes links [text](url).
+// If htmlMode is true, converts to <a href="url">text</a>; otherwise returns text only.
+func processLinks(text string, htmlMode bool) string {
+	closeText := newDelimiterFinder(text, "]")
+	closeURL := newDelimiterFinder(text, ")")
+	if !strings.Contains(text, "[") || closeText.next(0) == -1 {
+		return text
+	}
+
 	var result strings.Builder
-	runes := []rune(text)
+	result.Grow(len(text) + len(text)/8)
 	i := 0
-
-	for i < len(runes) {
-		// Look for __ pattern
-		if i+1 < len(runes) && runes[i] == '_' && runes[i+1] == '_' {
-			start := i + 2
-
-			// Find closing __
-			end := start
-			for end < len(runes)-1 {
-				if runes[end] == '_' && runes[end+1] == '_' {
-					break
-				}
-				end++
-			}
-
-			if end < len(runes)-1 {
-				content := string(runes[start:end])
+	for i < len(text) {
+		// Copy the text up to the next [
+		idx := strings.IndexByte(text[i:], '[')
+		if idx == -1 {
+			result.WriteString(text[i:])
+			break
+		}
+		open := i + idx
+		result.WriteString(text[i:open])
+		start := open + 1
+
+		// Find closing ] followed by (, then the closing )
+		end := closeText.next(start)
+		if end != -1 && end+1 < len(text) && text[end+1] == '(' {
+			urlStart := end + 2
+			if urlEnd := closeURL.next(urlStart); urlEnd != -1 {
+				linkText := text[start:end]
 				if htmlMode {
-					result.WriteString("<u>")
-					result.WriteString(content)
-					result.WriteString("</u>")
+					result.WriteString(`<a href="`)
+					htmlReplacer.WriteString(&result, text[urlStart:urlEnd])
+					result.WriteString(`">`)
+					result.WriteString(linkText)
+					result.WriteString(`</a>`)
 				} else {
-					result.WriteString(content)
+					result.WriteString(linkText)
 				}
-				i = end + 2
+				i = urlEnd + 1
 				continue
 			}
 		}
 
-		result.WriteRune(runes[i])
-		i++
+		result.WriteByte('[')
+		i = start
 	}
-
 	return result.String()
 }
 
+// htmlReplacer escapes HTML special characters.
+var htmlReplacer = strings.NewReplacer(
+	"<", "&lt;",
+	">", "&gt;",
+	"&", "&amp;",
+	`"`, "&quot;",
+	"'", "&#39;",
+)
+
 // htmlEscape escapes HTML special characters.
 func htmlEscape(text string) string {
-	result := strings.Builder{}
-
-	for _, r := range text {
-		switch r {
-		case '<':
-			result.WriteString("&lt;")
-		case '>':
-			result.WriteString("&gt;")
-		case '&':
-			result.WriteString("&amp;")
-		case '"':
-			result.WriteString("&quot;")
-		case '\'':
-			result.WriteString("&#39;")
-		default:
-			result.WriteRune(r)
-		}
-	}
-
-	return result.String()
+	return htmlReplacer.Replace(validUTF8(text))
 }
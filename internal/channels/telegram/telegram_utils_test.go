@@ -2,6 +2,8 @@
 package telegram
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -428,6 +430,16 @@ func TestContentTypeEdgeCases(t *testing.T) {
 			text:     "\n\n",
 			expected: ContentTypePlain,
 		},
+		{
+			name:     "escaped backticks only",
+			text:     "\\`a\\`",
+			expected: ContentTypePlain,
+		},
+		{
+			name:     "backtick after escaped backtick",
+			text:     "\\``",
+			expected: ContentTypeCode,
+		},
 	}
 
 	for _, tt := range tests {
@@ -519,6 +531,16 @@ func TestMarkdownToHTMLEdgeCases(t *testing.T) {
 			input:    "**bold with *italic***",
 			expected: "<b>bold with <i>italic</b></i>",
 		},
+		{
+			name:     "unclosed code block with multi-byte text",
+			input:    "```\nкод",
+			expected: "<pre><code>код</code></pre>",
+		},
+		{
+			name:     "invalid UTF-8",
+			input:    "**a\xff**",
+			expected: "<b>a\uFFFD</b>",
+		},
 	}
 
 	for _, tt := range tests {
@@ -530,3 +552,55 @@ func TestMarkdownToHTMLEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+// benchmarkAnswer builds an answer like the ones the agent sends: prose with
+// formatting around large code blocks full of snake_case identifiers.
+func benchmarkAnswer(blocks int) string {
+	var b strings.Builder
+	b.WriteString("Here is the **updated** handler with _error handling_ and a [reference](https://example.com/docs?a=1&b=2):\n\n")
+	for i := range blocks {
+		fmt.Fprintf(&b, "Step %d: call `process_message` after ~~validation~~ parsing.\n\n```go\n", i+1)
+		for j := range 40 {
+			fmt.Fprintf(&b, "\tresult_%d := process_item(ctx, items[%d], &opts) // <check> a && b\n", j, j)
+		}
+		b.WriteString("```\n\n")
+	}
+	b.WriteString("Let me know if __anything__ else is needed!")
+	return b.String()
+}
+
+func BenchmarkMarkdownToHTML(b *testing.B) {
+	for _, blocks := range []int{1, 10} {
+		text := benchmarkAnswer(blocks)
+		b.Run(fmt.Sprintf("%dKB", len(text)/1024), func(b *testing.B) {
+			b.SetBytes(int64(len(text)))
+			for b.Loop() {
+				MarkdownToHTML(text)
+			}
+		})
+	}
+}
+
+func BenchmarkStripFormatting(b *testing.B) {
+	text := benchmarkAnswer(10)
+	b.SetBytes(int64(len(text)))
+	for b.Loop() {
+		StripFormatting(text)
+	}
+}
+
+func BenchmarkPreprocessMarkdownV2(b *testing.B) {
+	text := benchmarkAnswer(10)
+	b.SetBytes(int64(len(text)))
+	for b.Loop() {
+		PreprocessMarkdownV2(text)
+	}
+}
+
+func BenchmarkDetectContentType(b *testing.B) {
+	text := strings.Repeat("plain text without any formatting at all. ", 100)
+	b.SetBytes(int64(len(text)))
+	for b.Loop() {
+		DetectContentType(text)
+	}
+}
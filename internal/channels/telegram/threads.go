@@ -0,0 +1,180 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/mymmrac/telego"
+)
+
+// maxThreadMessages limits the number of messages remembered by threadTracker
+const maxThreadMessages = 10000
+
+// threadKey identifies a message of a chat.
+type threadKey struct {
+	chatID    int64
+	messageID int
+}
+
+// threadTracker maps the messages of group chats to the reply chains
+// (threads) they belong to, so that parallel requests of different members
+// get separate sessions. A thread is identified by its root message. Threads
+// are kept in memory: after a restart a reply to an earlier message starts a
+// new thread rooted at that message.
+type threadTracker struct {
+	mu     sync.Mutex
+	max    int
+	roots  map[threadKey]int // Message → root message of its thread
+	latest map[threadKey]int // Root message → latest user message of the thread
+	order  []threadKey       // Messages in the order they were added
+}
+
+// newThreadTracker creates a threadTracker remembering up to max messages.
+func newThreadTracker(max int) *threadTracker {
+	if max <= 0 {
+		max = maxThreadMessages
+	}
+	return &threadTracker{
+		max:    max,
+		roots:  make(map[threadKey]int),
+		latest: make(map[threadKey]int),
+	}
+}
+
+// inbound records a user message and returns the root of its thread. A reply
+// joins the thread of the message it replies to; a reply to an unknown
+// message starts a thread rooted at that message, any other message starts
+// a thread of its own.
+func (t *threadTracker) inbound(chatID int64, msg *telego.Message) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	root := msg.MessageID
+	if reply := msg.ReplyToMessage; reply != nil {
+		var ok bool
+		if root, ok = t.roots[threadKey{chatID, reply.MessageID}]; !ok {
+			root = reply.MessageID
+			t.add(threadKey{chatID, root}, root)
+		}
+	}
+	t.add(threadKey{chatID, msg.MessageID}, root)
+	t.latest[threadKey{chatID, root}] = msg.MessageID
+	return root
+}
+
+// sent records a message the bot sent to a thread.
+func (t *threadTracker) sent(chatID int64, messageID, root int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.add(threadKey{chatID, messageID}, root)
+}
+
+// thread returns the root of the thread a message belongs to.
+func (t *threadTracker) thread(chatID int64, messageID int) (int, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	root, ok := t.roots[threadKey{chatID, messageID}]
+	return root, ok
+}
+
+// replyTarget returns the message an answer in a thread replies to: the
+// latest user message of the thread, or its root.
+func (t *threadTracker) replyTarget(chatID int64, root int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if latest, ok := t.latest[threadKey{chatID, root}]; ok {
+		return latest
+	}
+	return root
+}
+
+// add maps a message to a thread root, forgetting the oldest message when
+// the limit is reached. Caller must hold t.mu.
+func (t *threadTracker) add(key threadKey, root int) {
+	if _, ok := t.roots[key]; ok {
+		return
+	}
+	if len(t.order) >= t.max {
+		oldest := t.order[0]
+		t.order = t.order[1:]
+		if t.roots[oldest] == oldest.messageID {
+			delete(t.latest, oldest)
+		}
+		delete(t.roots, oldest)
+	}
+	t.roots[key] = root
+	t.order = append(t.order, key)
+}
+
+// isGroupChat reports whether a chat is a group or a supergroup.
+func isGroupChat(chat telego.Chat) bool {
+	return chat.Type == telego.ChatTypeGroup || chat.Type == telego.ChatTypeSupergroup
+}
+
+// chatSessionID returns the session ID of a chat.
+func chatSessionID(chatID int64) string {
+	return fmt.Sprintf("telegram:%d", chatID)
+}
+
+// threadSessionID returns the session ID of a thread of a group chat.
+func threadSessionID(chatID int64, root int) string {
+	return fmt.Sprintf("telegram:%d:%d", chatID, root)
+}
+
+// parseSessionID parses a session ID in "telegram:chat_id" or
+// "telegram:chat_id:root_message_id" (thread) format. root is 0 for a chat.
+func parseSessionID(sessionID string) (chatID int64, root int, err error) {
+	parts := strings.Split(sessionID, ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return 0, 0, fmt.Errorf("invalid session ID format: expected 'channel:chat_id', got: %s", sessionID)
+	}
+
+	// Verify channel matches telegram
+	if parts[0] != string(bus.ChannelTypeTelegram) {
+		return 0, 0, fmt.Errorf("session ID channel mismatch: expected %s, got %s",
+			bus.ChannelTypeTelegram, parts[0])
+	}
+
+	chatID, err = strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid chat ID in session ID: %w", err)
+	}
+	if len(parts) == 3 {
+		if root, err = strconv.Atoi(parts[2]); err != nil || root <= 0 {
+			return 0, 0, fmt.Errorf("invalid thread in session ID: %s", sessionID)
+		}
+	}
+	return chatID, root, nil
+}
+
+// messageSessionID returns the session of a message in a chat: the session
+// of its thread when group threads are enabled and the message belongs to
+// one, otherwise the session of the chat.
+func (c *Connector) messageSessionID(chatID int64, messageID int) string {
+	if c.cfg.GroupThreads && c.threads != nil {
+		if root, ok := c.threads.thread(chatID, messageID); ok {
+			return threadSessionID(chatID, root)
+		}
+	}
+	return chatSessionID(chatID)
+}
+
+// threadReply returns the reply parameters of a message sent to a thread
+// session, so that the answer continues the reply chain. Returns nil for
+// chat sessions.
+func (c *Connector) threadReply(sessionID string) *telego.ReplyParameters {
+	chatID, root, err := parseSessionID(sessionID)
+	if err != nil || root == 0 || c.threads == nil {
+		return nil
+	}
+	return &telego.ReplyParameters{
+		MessageID:                c.threads.replyTarget(chatID, root),
+		AllowSendingWithoutReply: true,
+	}
+}
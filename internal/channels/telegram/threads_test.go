@@ -0,0 +1,83 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThreadTracker(t *testing.T) {
+	const chatID = int64(-100123)
+	threads := newThreadTracker(0)
+
+	// Two members ask in parallel: two threads
+	alice := threads.inbound(chatID, &telego.Message{MessageID: 10})
+	bob := threads.inbound(chatID, &telego.Message{MessageID: 11})
+	assert.Equal(t, 10, alice)
+	assert.Equal(t, 11, bob)
+
+	// The answer to Alice joins her thread, her reply to it continues the thread
+	threads.sent(chatID, 12, alice)
+	root := threads.inbound(chatID, &telego.Message{MessageID: 13, ReplyToMessage: &telego.Message{MessageID: 12}})
+	assert.Equal(t, alice, root)
+	assert.Equal(t, 13, threads.replyTarget(chatID, alice))
+	assert.Equal(t, 11, threads.replyTarget(chatID, bob))
+
+	// A reply to an unknown message starts a thread rooted at that message
+	root = threads.inbound(chatID, &telego.Message{MessageID: 20, ReplyToMessage: &telego.Message{MessageID: 5}})
+	assert.Equal(t, 5, root)
+
+	// Threads of other chats are separate
+	_, ok := threads.thread(chatID+1, 12)
+	assert.False(t, ok)
+}
+
+func TestThreadTracker_Limit(t *testing.T) {
+	threads := newThreadTracker(2)
+	threads.inbound(1, &telego.Message{MessageID: 1})
+	threads.sent(1, 2, 1)
+	threads.inbound(1, &telego.Message{MessageID: 3})
+
+	_, ok := threads.thread(1, 1)
+	assert.False(t, ok, "the oldest message should be forgotten")
+	root, ok := threads.thread(1, 2)
+	assert.True(t, ok)
+	assert.Equal(t, 1, root)
+	assert.Equal(t, 1, threads.replyTarget(1, 1), "a forgotten thread is answered at its root")
+}
+
+func TestParseSessionID(t *testing.T) {
+	chatID, root, err := parseSessionID("telegram:-100123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(-100123), chatID)
+	assert.Zero(t, root)
+
+	chatID, root, err = parseSessionID(threadSessionID(-100123, 42))
+	require.NoError(t, err)
+	assert.Equal(t, int64(-100123), chatID)
+	assert.Equal(t, 42, root)
+
+	for _, id := range []string{"telegram", "discord:1", "telegram:abc", "telegram:1:x", "telegram:1:0", "telegram:1:2:3"} {
+		_, _, err := parseSessionID(id)
+		assert.Error(t, err, id)
+	}
+}
+
+func TestConnector_threadSessions(t *testing.T) {
+	c := &Connector{cfg: config.TelegramConfig{GroupThreads: true}, threads: newThreadTracker(0)}
+	root := c.threads.inbound(-100, &telego.Message{MessageID: 7})
+
+	assert.Equal(t, "telegram:-100:7", c.messageSessionID(-100, 7))
+	assert.Equal(t, "telegram:-100", c.messageSessionID(-100, 8))
+
+	reply := c.threadReply(threadSessionID(-100, root))
+	require.NotNil(t, reply)
+	assert.Equal(t, 7, reply.MessageID)
+	assert.Nil(t, c.threadReply("telegram:-100"))
+
+	c.cfg.GroupThreads = false
+	assert.Equal(t, "telegram:-100", c.messageSessionID(-100, 7))
+}
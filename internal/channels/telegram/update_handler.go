@@ -2,9 +2,11 @@ package telegram
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/aatumaykin/nexbot/internal/bus"
 	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/onboarding"
 	"github.com/mymmrac/telego"
 )
 
@@ -34,16 +36,17 @@ func (uh *UpdateHandler) Handle(update telego.Update) error {
 		return uh.callbackHandler.Handle(update.CallbackQuery)
 	}
 
+	// Handle reactions to messages as feedback
+	if update.MessageReaction != nil {
+		return uh.handleReaction(update.MessageReaction)
+	}
+
 	// Only process message updates
 	if update.Message == nil {
 		return nil
 	}
 
 	msg := update.Message
-	if msg.Text == "" {
-		// Skip non-text messages (photos, stickers, etc.) for now
-		return nil
-	}
 
 	// Extract user information
 	var userID string
@@ -51,6 +54,51 @@ func (uh *UpdateHandler) Handle(update telego.Update) error {
 		userID = fmt.Sprintf("%d", msg.From.ID)
 	}
 
+	// Handle /upload (a document with the command in the caption)
+	if uh.connector.uploads != nil && isUpload(msg) {
+		return uh.handleUpload(msg, userID)
+	}
+
+	// Voice messages are transcribed and handled like text
+	voice := false
+	if msg.Voice != nil && msg.Text == "" && uh.connector.voice != nil {
+		text, ok := uh.transcribeVoice(msg, userID)
+		if !ok {
+			return nil
+		}
+		msg.Text = text
+		voice = true
+	}
+
+	// Stickers reach the agent as their emoji, with the sticker in the metadata
+	var sticker map[string]any
+	if msg.Sticker != nil && msg.Text == "" {
+		msg.Text, sticker = stickerMessage(msg.Sticker)
+	}
+
+	if msg.Text == "" {
+		// Skip non-text messages (photos, etc.) for now
+		return nil
+	}
+
+	// /start with a deep-link payload runs the onboarding and redeems invite
+	// codes (handles invites before the whitelist check)
+	if msg.Chat.Type == telego.ChatTypePrivate && commandWithArgs(msg.Text, onboarding.StartCommand) &&
+		(uh.connector.onboarding != nil || uh.connector.invites != nil && !uh.connector.isAllowedUser(userID)) {
+		return uh.handleStart(msg, userID)
+	}
+
+	// /forget_me deletes the user's data (/forget-me is accepted as an alias);
+	// users outside the whitelist may have data too
+	if uh.connector.privacy != nil && commandWithArgs(msg.Text, "/forget_me", "/forget-me") {
+		return uh.handleForgetMe(msg, userID)
+	}
+
+	// /admin commands are available to the users in admin_users only
+	if commandWithArgs(msg.Text, "/admin") {
+		return uh.handleAdmin(msg, userID)
+	}
+
 	// Check for built-in commands (handle before whitelist check)
 	if msg.Text == "/new" {
 		return uh.connector.commandHandler.HandleCommand(uh.connector.ctx, uh.connector.isAllowedUser, msg, "new_session", userID)
@@ -60,10 +108,53 @@ func (uh *UpdateHandler) Handle(update telego.Update) error {
 		return uh.connector.commandHandler.HandleCommand(uh.connector.ctx, uh.connector.isAllowedUser, msg, "status", userID)
 	}
 
+	if msg.Text == "/sessions" {
+		return uh.connector.commandHandler.HandleCommand(uh.connector.ctx, uh.connector.isAllowedUser, msg, "sessions", userID)
+	}
+
+	// Handle named session commands (/save-as is accepted as an alias of /save_as)
+	if commandWithArgs(msg.Text, "/save_as", "/save-as") {
+		return uh.connector.commandHandler.HandleCommand(uh.connector.ctx, uh.connector.isAllowedUser, msg, "save_as", userID)
+	}
+
+	if commandWithArgs(msg.Text, "/resume") {
+		return uh.connector.commandHandler.HandleCommand(uh.connector.ctx, uh.connector.isAllowedUser, msg, "resume", userID)
+	}
+
+	if commandWithArgs(msg.Text, "/context") {
+		return uh.connector.commandHandler.HandleCommand(uh.connector.ctx, uh.connector.isAllowedUser, msg, "context", userID)
+	}
+
+	if commandWithArgs(msg.Text, "/sampling") {
+		return uh.connector.commandHandler.HandleCommand(uh.connector.ctx, uh.connector.isAllowedUser, msg, "sampling", userID)
+	}
+
+	if commandWithArgs(msg.Text, "/project") {
+		return uh.connector.commandHandler.HandleCommand(uh.connector.ctx, uh.connector.isAllowedUser, msg, "project", userID)
+	}
+
+	if commandWithArgs(msg.Text, "/followups") {
+		return uh.connector.commandHandler.HandleCommand(uh.connector.ctx, uh.connector.isAllowedUser, msg, "followups", userID)
+	}
+
+	if msg.Text == "/rollback" {
+		return uh.connector.commandHandler.HandleCommand(uh.connector.ctx, uh.connector.isAllowedUser, msg, "rollback", userID)
+	}
+
 	if msg.Text == "/restart" {
 		return uh.connector.commandHandler.HandleCommand(uh.connector.ctx, uh.connector.isAllowedUser, msg, "restart", userID)
 	}
 
+	// Handle /export command (with optional format argument)
+	if msg.Text == "/export" || strings.HasPrefix(msg.Text, "/export ") {
+		return uh.connector.commandHandler.HandleCommand(uh.connector.ctx, uh.connector.isAllowedUser, msg, "export", userID)
+	}
+
+	// Handle /feedback command (with rating and comment)
+	if msg.Text == "/feedback" || strings.HasPrefix(msg.Text, "/feedback ") {
+		return uh.connector.commandHandler.HandleCommand(uh.connector.ctx, uh.connector.isAllowedUser, msg, "feedback", userID)
+	}
+
 	// Handle /secret commands (with or without arguments)
 	if len(msg.Text) >= 7 && msg.Text[:7] == "/secret" {
 		return uh.connector.commandHandler.HandleCommand(uh.connector.ctx, uh.connector.isAllowedUser, msg, "secret", userID)
@@ -76,29 +167,50 @@ func (uh *UpdateHandler) Handle(update telego.Update) error {
 			logger.Field{Key: "username", Value: msg.From.Username})
 
 		// Optionally send a message back informing the user
-		if msg.Chat.ID != 0 && uh.connector.bot != nil {
-			notifyParams := telego.SendMessageParams{
-				ChatID: telego.ChatID{ID: msg.Chat.ID},
-				Text:   "Sorry, you are not authorized to use this bot.",
-			}
-			_, err := uh.connector.bot.SendMessage(uh.connector.ctx, &notifyParams)
-			if err != nil {
-				uh.logger.ErrorCtx(uh.connector.ctx, "failed to send notification", err)
-			}
-		}
+		uh.notify(msg.Chat.ID, "Sorry, you are not authorized to use this bot.")
+
+		return nil
+	}
 
+	// Use chat ID as session ID with channel prefix; in groups with threads
+	// every reply chain has a session of its own
+	sessionID := chatSessionID(msg.Chat.ID)
+	if uh.connector.cfg.GroupThreads && isGroupChat(msg.Chat) {
+		sessionID = threadSessionID(msg.Chat.ID, uh.connector.threads.inbound(msg.Chat.ID, msg))
+	}
+
+	// Messages wait until the user agrees to the required consent questions
+	if uh.connector.onboarding != nil && msg.Chat.Type == telego.ChatTypePrivate &&
+		uh.connector.onboarding.Hold(uh.connector.ctx, sessionID, telegramIdentity(userID), msg.From.FirstName) {
+		return nil
+	}
+
+	// Answers to an active form are consumed by the form and never reach the agent
+	if uh.connector.forms != nil && uh.connector.forms.Answer(uh.connector.ctx, sessionID, msg.Text) {
+		uh.logger.DebugCtx(uh.connector.ctx, "form answer received",
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "session_id", Value: sessionID})
 		return nil
 	}
 
-	// Use chat ID as session ID with channel prefix
-	sessionID := fmt.Sprintf("telegram:%d", msg.Chat.ID)
+	// /debate <question> is answered by the agent in debate mode,
+	// /dryrun <request> with mutating tools reporting instead of executing
+	content := msg.Text
+	debate := commandWithArgs(msg.Text, "/debate")
+	if debate {
+		content = strings.TrimSpace(strings.TrimPrefix(msg.Text, "/debate"))
+	}
+	dryRun := commandWithArgs(msg.Text, "/dryrun")
+	if dryRun {
+		content = strings.TrimSpace(strings.TrimPrefix(msg.Text, "/dryrun"))
+	}
 
 	// Create inbound message
 	inboundMsg := bus.NewInboundMessage(
 		bus.ChannelTypeTelegram,
 		userID,
 		sessionID,
-		msg.Text,
+		content,
 		map[string]any{
 			"message_id":    msg.MessageID,
 			"chat_id":       msg.Chat.ID,
@@ -109,9 +221,38 @@ func (uh *UpdateHandler) Handle(update telego.Update) error {
 			"language_code": msg.From.LanguageCode,
 		},
 	)
+	if voice {
+		inboundMsg.Metadata["voice"] = true
+	}
+	if debate {
+		inboundMsg.Metadata["debate"] = true
+	}
+	if dryRun {
+		inboundMsg.Metadata["dry_run"] = true
+	}
+	if sticker != nil {
+		inboundMsg.Metadata["sticker"] = sticker
+	}
+
+	// Apply per-user limits
+	if uh.connector.limiter != nil {
+		if decision := uh.connector.limiter.Admit(inboundMsg); !decision.Allowed {
+			uh.logger.WarnCtx(uh.connector.ctx, "message throttled",
+				logger.Field{Key: "user_id", Value: userID},
+				logger.Field{Key: "session_id", Value: sessionID},
+				logger.Field{Key: "muted", Value: decision.Muted})
+			if decision.Reply != "" {
+				uh.notify(msg.Chat.ID, decision.Reply)
+			}
+			return nil
+		}
+	}
 
 	// Publish to message bus
 	if err := uh.bus.PublishInbound(*inboundMsg); err != nil {
+		if uh.connector.limiter != nil {
+			uh.connector.limiter.Done(*inboundMsg)
+		}
 		return fmt.Errorf("failed to publish inbound message: %w", err)
 	}
 
@@ -122,3 +263,93 @@ func (uh *UpdateHandler) Handle(update telego.Update) error {
 
 	return nil
 }
+
+// notify sends a plain text message directly to a chat, bypassing the message bus.
+func (uh *UpdateHandler) notify(chatID int64, text string) {
+	if chatID == 0 || uh.connector.bot == nil {
+		return
+	}
+	params := telego.SendMessageParams{
+		ChatID: telego.ChatID{ID: chatID},
+		Text:   text,
+	}
+	if _, err := uh.connector.bot.SendMessage(uh.connector.ctx, &params); err != nil {
+		uh.logger.ErrorCtx(uh.connector.ctx, "failed to send notification", err)
+	}
+}
+
+// handleReaction publishes newly added emoji reactions as feedback commands.
+// Anonymous reactions and reactions from users outside the whitelist are ignored.
+func (uh *UpdateHandler) handleReaction(reaction *telego.MessageReactionUpdated) error {
+	if reaction.User == nil {
+		return nil
+	}
+	userID := fmt.Sprintf("%d", reaction.User.ID)
+	if !uh.connector.isAllowedUser(userID) {
+		return nil
+	}
+
+	old := make(map[string]bool, len(reaction.OldReaction))
+	for _, r := range reaction.OldReaction {
+		if emoji, ok := r.(*telego.ReactionTypeEmoji); ok {
+			old[emoji.Emoji] = true
+		}
+	}
+
+	sessionID := fmt.Sprintf("telegram:%d", reaction.Chat.ID)
+	for _, r := range reaction.NewReaction {
+		emoji, ok := r.(*telego.ReactionTypeEmoji)
+		if !ok || old[emoji.Emoji] {
+			continue
+		}
+
+		inboundMsg := bus.NewInboundMessage(
+			bus.ChannelTypeTelegram,
+			userID,
+			sessionID,
+			emoji.Emoji,
+			map[string]any{
+				"command":    "feedback",
+				"reaction":   emoji.Emoji,
+				"message_id": reaction.MessageID,
+				"chat_id":    reaction.Chat.ID,
+			},
+		)
+		if err := uh.bus.PublishInbound(*inboundMsg); err != nil {
+			return fmt.Errorf("failed to publish reaction: %w", err)
+		}
+
+		uh.logger.DebugCtx(uh.connector.ctx, "reaction published",
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "session_id", Value: sessionID},
+			logger.Field{Key: "reaction", Value: emoji.Emoji})
+	}
+	return nil
+}
+
+// stickerMessage returns the text an incoming sticker is handled as and its
+// metadata. The file ID lets the agent send the same sticker back.
+func stickerMessage(sticker *telego.Sticker) (string, map[string]any) {
+	text := "[Sticker]"
+	if sticker.Emoji != "" {
+		text = fmt.Sprintf("[Sticker %s]", sticker.Emoji)
+	}
+	return text, map[string]any{
+		"file_id":     sticker.FileID,
+		"emoji":       sticker.Emoji,
+		"set_name":    sticker.SetName,
+		"type":        sticker.Type,
+		"is_animated": sticker.IsAnimated,
+		"is_video":    sticker.IsVideo,
+	}
+}
+
+// commandWithArgs reports whether text is one of the commands, with or without arguments.
+func commandWithArgs(text string, commands ...string) bool {
+	for _, cmd := range commands {
+		if text == cmd || strings.HasPrefix(text, cmd+" ") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/media"
+	"github.com/aatumaykin/nexbot/internal/upload"
+	"github.com/mymmrac/telego"
+)
+
+const (
+	// uploadCommand stores a document sent with it in the caption
+	uploadCommand = "/upload"
+
+	// uploadTimeout bounds downloading a document from Telegram
+	uploadTimeout = 2 * time.Minute
+
+	uploadUsageMessage = "📎 Send a document with the caption /upload <path>, e.g. /upload data/report.csv. " +
+		"Without a path the file is stored in the uploads directory."
+)
+
+// isUpload reports whether the message is an /upload command (in the text or a document caption).
+func isUpload(msg *telego.Message) bool {
+	return commandWithArgs(msg.Text, uploadCommand) || commandWithArgs(msg.Caption, uploadCommand)
+}
+
+// handleUpload stores a document from the user in the workspace and confirms
+// it with the size and hash. The document never reaches the agent.
+func (uh *UpdateHandler) handleUpload(msg *telego.Message, userID string) error {
+	if !uh.connector.isAllowedUser(userID) {
+		uh.logger.WarnCtx(uh.connector.ctx, "upload blocked - user not in whitelist",
+			logger.Field{Key: "user_id", Value: userID})
+		uh.notify(msg.Chat.ID, "Sorry, you are not authorized to use this bot.")
+		return nil
+	}
+
+	doc := msg.Document
+	if doc == nil {
+		uh.notify(msg.Chat.ID, uploadUsageMessage)
+		return nil
+	}
+
+	uploads := uh.connector.uploads
+	maxSize := min(uploads.MaxSize(), uh.connector.mediaPolicy().MaxSize(media.KindDocument))
+	if doc.FileSize > maxSize {
+		uh.notify(msg.Chat.ID, fmt.Sprintf("❌ Upload failed: the file is larger than %s.", upload.FormatSize(maxSize)))
+		return nil
+	}
+
+	path := strings.TrimSpace(strings.TrimPrefix(msg.Caption, uploadCommand))
+	target, err := uploads.Resolve(path, doc.FileName)
+	if err != nil {
+		uh.notify(msg.Chat.ID, fmt.Sprintf("❌ Upload failed: %v", err))
+		return nil
+	}
+
+	ctx := logger.WithContext(uh.connector.ctx, logger.Field{Key: "user_id", Value: userID})
+	result, err := uh.downloadDocument(ctx, doc.FileID, doc.FileName, target)
+	if err != nil {
+		uh.logger.ErrorCtx(uh.connector.ctx, "upload failed", err,
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "path", Value: target})
+		reason := "could not download the file"
+		if errors.Is(err, upload.ErrExists) || errors.Is(err, upload.ErrTooLarge) {
+			reason = err.Error()
+		} else if rejection, ok := mediaRejection(err); ok {
+			reason = rejection
+		}
+		uh.notify(msg.Chat.ID, fmt.Sprintf("❌ Upload failed: %s.", reason))
+		return nil
+	}
+
+	uh.logger.InfoCtx(uh.connector.ctx, "file uploaded",
+		logger.Field{Key: "user_id", Value: userID},
+		logger.Field{Key: "path", Value: result.Path},
+		logger.Field{Key: "size", Value: result.Size},
+		logger.Field{Key: "sha256", Value: result.SHA256})
+
+	uh.notify(msg.Chat.ID, fmt.Sprintf("✅ Saved %s\nSize: %s (%d bytes)\nSHA-256: %s",
+		result.Name, upload.FormatSize(result.Size), result.Size, result.SHA256))
+	return nil
+}
+
+// downloadDocument downloads a file from Telegram into the target path.
+// Executables are moved to quarantine instead.
+func (uh *UpdateHandler) downloadDocument(ctx context.Context, fileID, name, target string) (*upload.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, uploadTimeout)
+	defer cancel()
+
+	content, err := uh.openMedia(ctx, media.KindDocument, fileID, name)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	return uh.connector.uploads.Save(target, content)
+}
+
+// openMedia starts downloading a file from Telegram and checks it with the
+// media policy: reading fails once the size limit of the kind is exceeded,
+// executables are quarantined and rejected like files of types that are not
+// allowed, and with a virus scanner the file is scanned before it is
+// returned. The caller closes the content.
+func (uh *UpdateHandler) openMedia(ctx context.Context, kind media.Kind, fileID, name string) (io.ReadCloser, error) {
+	body, err := uh.openFile(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := uh.connector.mediaPolicy()
+	content, info, err := policy.Open(kind, name, body)
+	if errors.Is(err, media.ErrExecutable) {
+		path, qerr := policy.Quarantine(name, content)
+		if qerr != nil {
+			uh.logger.ErrorCtx(ctx, "failed to quarantine executable file", qerr,
+				logger.Field{Key: "name", Value: name})
+		}
+		uh.logger.WarnCtx(ctx, "executable file rejected",
+			logger.Field{Key: "kind", Value: string(kind)},
+			logger.Field{Key: "name", Value: name},
+			logger.Field{Key: "mime", Value: info.MIME},
+			logger.Field{Key: "quarantine", Value: path})
+	}
+	if err != nil {
+		_ = body.Close()
+		return nil, err
+	}
+
+	scanned, err := policy.Scan(ctx, kind, name, content)
+	if err != nil {
+		_ = body.Close()
+		return nil, err
+	}
+	return &mediaContent{ReadCloser: scanned, body: body}, nil
+}
+
+// mediaContent closes the download together with the checked content.
+type mediaContent struct {
+	io.ReadCloser
+	body io.Closer
+}
+
+func (c *mediaContent) Close() error {
+	err := c.ReadCloser.Close()
+	if bodyErr := c.body.Close(); err == nil {
+		err = bodyErr
+	}
+	return err
+}
+
+// mediaRejection describes a file rejected by the media policy for the user.
+func mediaRejection(err error) (string, bool) {
+	// Reading errors are wrapped, so the sentinel text is used
+	for _, target := range []error{media.ErrTooLarge, media.ErrExecutable, media.ErrInfected, media.ErrScanFailed} {
+		if errors.Is(err, target) {
+			return target.Error(), true
+		}
+	}
+	if errors.Is(err, media.ErrTypeNotAllowed) {
+		return err.Error(), true
+	}
+	return "", false
+}
+
+// openFile starts downloading a file from Telegram. The caller closes the body.
+func (uh *UpdateHandler) openFile(ctx context.Context, fileID string) (io.ReadCloser, error) {
+	file, err := uh.connector.bot.GetFile(ctx, &telego.GetFileParams{FileID: fileID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uh.connector.bot.FileDownloadURL(file.FilePath), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+	client := uh.connector.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
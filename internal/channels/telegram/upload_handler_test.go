@@ -0,0 +1,161 @@
+package telegram
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/media"
+	"github.com/aatumaykin/nexbot/internal/upload"
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newUploadTestHandler(t *testing.T, content string) (*UpdateHandler, *MockBot, string) {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	mockBot := NewMockBotSuccess()
+	mockBot.On("GetFile", mock.Anything, mock.Anything).Return(&telego.File{FilePath: "documents/file_1.csv"}, nil).Maybe()
+	mockBot.On("FileDownloadURL", "documents/file_1.csv").Return(server.URL).Maybe()
+
+	ws := t.TempDir()
+	msgBus := bus.New(10, 10, log)
+	connector := &Connector{
+		cfg:     config.TelegramConfig{AllowedUsers: []string{"123456"}},
+		ctx:     context.Background(),
+		logger:  log,
+		bus:     msgBus,
+		bot:     mockBot,
+		uploads: upload.NewStore(upload.Config{Workspace: ws}),
+	}
+	handler := NewUpdateHandler(connector, log, msgBus)
+	return handler, mockBot, ws
+}
+
+func uploadUpdate(userID int64, caption string, doc *telego.Document) telego.Update {
+	return telego.Update{Message: &telego.Message{
+		MessageID: 1,
+		From:      &telego.User{ID: userID},
+		Chat:      telego.Chat{ID: 42, Type: "private"},
+		Caption:   caption,
+		Document:  doc,
+	}}
+}
+
+// sentText returns the text of the last message sent by the bot.
+func sentText(t *testing.T, mockBot *MockBot) string {
+	t.Helper()
+	var text string
+	for _, call := range mockBot.Calls {
+		if call.Method == "SendMessage" {
+			text = call.Arguments.Get(1).(*telego.SendMessageParams).Text
+		}
+	}
+	return text
+}
+
+func TestUpdateHandler_Upload(t *testing.T) {
+	handler, mockBot, ws := newUploadTestHandler(t, "a,b\n1,2\n")
+
+	err := handler.Handle(uploadUpdate(123456, "/upload data/", &telego.Document{FileID: "f1", FileName: "report.csv", FileSize: 8}))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(ws, "data", "report.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "a,b\n1,2\n", string(data))
+
+	reply := sentText(t, mockBot)
+	assert.Contains(t, reply, "✅ Saved data/report.csv")
+	assert.Contains(t, reply, "8 bytes")
+	assert.Contains(t, reply, "SHA-256: ")
+
+	// Existing files are not overwritten
+	err = handler.Handle(uploadUpdate(123456, "/upload data/report.csv", &telego.Document{FileID: "f1", FileName: "report.csv"}))
+	require.NoError(t, err)
+	assert.Contains(t, sentText(t, mockBot), upload.ErrExists.Error())
+}
+
+func TestUpdateHandler_UploadRejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		update telego.Update
+		reply  string
+	}{
+		{"unauthorized user", uploadUpdate(999, "/upload", &telego.Document{FileID: "f1", FileName: "a.txt"}), "not authorized"},
+		{"without document", telego.Update{Message: &telego.Message{
+			From: &telego.User{ID: 123456}, Chat: telego.Chat{ID: 42}, Text: "/upload data.csv",
+		}}, "Send a document"},
+		{"path outside workspace", uploadUpdate(123456, "/upload ../escape.txt", &telego.Document{FileID: "f1", FileName: "a.txt"}), "Upload failed"},
+		{"too large", uploadUpdate(123456, "/upload", &telego.Document{FileID: "f1", FileName: "a.txt", FileSize: upload.DefaultMaxSize + 1}), "larger than"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockBot, ws := newUploadTestHandler(t, "data")
+			require.NoError(t, handler.Handle(tt.update))
+
+			assert.True(t, strings.Contains(sentText(t, mockBot), tt.reply), "reply %q should contain %q", sentText(t, mockBot), tt.reply)
+			mockBot.AssertNotCalled(t, "GetFile", mock.Anything, mock.Anything)
+			_, err := os.Stat(filepath.Join(ws, "uploads"))
+			assert.True(t, os.IsNotExist(err), "No file should be stored")
+		})
+	}
+}
+
+func TestUpdateHandler_UploadExecutable(t *testing.T) {
+	handler, mockBot, ws := newUploadTestHandler(t, "\x7fELF\x02\x01\x01 payload")
+	quarantine := filepath.Join(ws, "quarantine")
+	handler.connector.SetMedia(media.New(media.Config{QuarantineDir: quarantine}))
+
+	err := handler.Handle(uploadUpdate(123456, "/upload", &telego.Document{FileID: "f1", FileName: "photo.png", FileSize: 20}))
+	require.NoError(t, err)
+
+	assert.Contains(t, sentText(t, mockBot), media.ErrExecutable.Error())
+	_, err = os.Stat(filepath.Join(ws, "uploads", "photo.png"))
+	assert.True(t, os.IsNotExist(err), "Executables must not be stored in the workspace")
+
+	entries, err := os.ReadDir(quarantine)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	info, err := entries[0].Info()
+	require.NoError(t, err)
+	assert.Zero(t, info.Mode().Perm()&0111, "Quarantined files must not be executable")
+}
+
+// infectedScanner reports every file as infected.
+type infectedScanner struct{}
+
+func (infectedScanner) Name() string { return "test" }
+
+func (infectedScanner) Scan(context.Context, io.Reader) (media.Verdict, error) {
+	return media.Verdict{Infected: true, Signature: "Eicar-Signature"}, nil
+}
+
+func TestUpdateHandler_UploadInfected(t *testing.T) {
+	handler, mockBot, ws := newUploadTestHandler(t, "a,b\n1,2\n")
+	handler.connector.SetMedia(media.New(media.Config{Scanner: infectedScanner{}, Logger: handler.logger}))
+
+	err := handler.Handle(uploadUpdate(123456, "/upload", &telego.Document{FileID: "f1", FileName: "report.csv", FileSize: 8}))
+	require.NoError(t, err)
+
+	assert.Contains(t, sentText(t, mockBot), media.ErrInfected.Error())
+	_, err = os.Stat(filepath.Join(ws, "uploads"))
+	assert.True(t, os.IsNotExist(err), "Infected files must not be written to the workspace")
+}
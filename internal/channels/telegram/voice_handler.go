@@ -0,0 +1,106 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/media"
+	"github.com/aatumaykin/nexbot/internal/stt"
+	"github.com/aatumaykin/nexbot/internal/upload"
+	"github.com/mymmrac/telego"
+)
+
+// voiceTimeout bounds downloading and transcribing a voice message
+const voiceTimeout = 5 * time.Minute
+
+// voiceConfig holds the speech-to-text settings for voice messages.
+type voiceConfig struct {
+	provider    stt.Provider
+	language    string
+	maxDuration time.Duration
+}
+
+// transcribeVoice converts a voice message to text. It reports false when the
+// message must not be processed further; the user has then been notified.
+func (uh *UpdateHandler) transcribeVoice(msg *telego.Message, userID string) (string, bool) {
+	if !uh.connector.isAllowedUser(userID) {
+		uh.logger.WarnCtx(uh.connector.ctx, "voice message blocked - user not in whitelist",
+			logger.Field{Key: "user_id", Value: userID})
+		uh.notify(msg.Chat.ID, "Sorry, you are not authorized to use this bot.")
+		return "", false
+	}
+
+	voice := uh.connector.voice
+	duration := time.Duration(msg.Voice.Duration) * time.Second
+	if voice.maxDuration > 0 && duration > voice.maxDuration {
+		uh.notify(msg.Chat.ID, fmt.Sprintf("🎤 The voice message is too long (max %s).", voice.maxDuration))
+		return "", false
+	}
+
+	maxSize := uh.connector.mediaPolicy().MaxSize(media.KindVoice)
+	if msg.Voice.FileSize > maxSize {
+		uh.notify(msg.Chat.ID, fmt.Sprintf("🎤 The voice message is too large (max %s).", upload.FormatSize(maxSize)))
+		return "", false
+	}
+
+	ctx := logger.WithContext(uh.connector.ctx, logger.Field{Key: "user_id", Value: userID})
+	ctx, cancel := context.WithTimeout(ctx, voiceTimeout)
+	defer cancel()
+
+	start := time.Now()
+	text, err := uh.transcribeFile(ctx, msg.Voice.FileID)
+	if errors.Is(err, stt.ErrNoSpeech) {
+		uh.notify(msg.Chat.ID, "🎤 No speech recognized in the voice message.")
+		return "", false
+	}
+	if reason, ok := mediaRejection(err); ok {
+		uh.notify(msg.Chat.ID, fmt.Sprintf("❌ The voice message was rejected: %s.", reason))
+		return "", false
+	}
+	if err != nil {
+		uh.logger.ErrorCtx(uh.connector.ctx, "voice transcription failed", err,
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "provider", Value: voice.provider.Name()})
+		uh.notify(msg.Chat.ID, "❌ Could not transcribe the voice message. Please try again or send text.")
+		return "", false
+	}
+
+	uh.logger.InfoCtx(uh.connector.ctx, "voice message transcribed",
+		logger.Field{Key: "user_id", Value: userID},
+		logger.Field{Key: "provider", Value: voice.provider.Name()},
+		logger.Field{Key: "duration_seconds", Value: msg.Voice.Duration},
+		logger.Field{Key: "elapsed_ms", Value: time.Since(start).Milliseconds()})
+	return text, true
+}
+
+// transcribeFile downloads a Telegram voice file to a temporary file and transcribes it.
+func (uh *UpdateHandler) transcribeFile(ctx context.Context, fileID string) (string, error) {
+	body, err := uh.openMedia(ctx, media.KindVoice, fileID, "voice.ogg")
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	// Telegram voice messages are OGG/Opus
+	tmp, err := os.CreateTemp("", "nexbot-voice-*.ogg")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		_ = tmp.Close()
+		return "", fmt.Errorf("failed to download file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to save voice message: %w", err)
+	}
+
+	voice := uh.connector.voice
+	return voice.provider.Transcribe(ctx, tmp.Name(), stt.Options{Language: voice.language})
+}
@@ -0,0 +1,140 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/stt"
+	"github.com/mymmrac/telego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSTT is an stt.Provider returning a fixed transcript.
+type fakeSTT struct {
+	text  string
+	err   error
+	audio string // Content of the transcribed file
+}
+
+func (f *fakeSTT) Name() string { return "fake" }
+
+func (f *fakeSTT) Transcribe(ctx context.Context, path string, opts stt.Options) (string, error) {
+	data, _ := os.ReadFile(path)
+	f.audio = string(data)
+	return f.text, f.err
+}
+
+func newVoiceTestConnector(t *testing.T, provider stt.Provider) (*Connector, *MockBot, <-chan bus.InboundMessage) {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ogg-data"))
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := t.Context()
+	msgBus := bus.New(10, 10, log)
+	require.NoError(t, msgBus.Start(ctx))
+	t.Cleanup(func() { _ = msgBus.Stop() })
+	inboundCh := msgBus.SubscribeInbound(ctx)
+
+	mockBot := NewMockBotSuccess()
+	mockBot.On("GetFile", mock.Anything, mock.Anything).Return(&telego.File{FilePath: "voice/file_1.oga"}, nil).Maybe()
+	mockBot.On("FileDownloadURL", "voice/file_1.oga").Return(server.URL).Maybe()
+
+	conn := New(config.TelegramConfig{AllowedUsers: []string{"123456"}}, log, msgBus)
+	conn.ctx = ctx
+	conn.bot = mockBot
+	conn.SetSpeechToText(provider, "en", time.Minute)
+	return conn, mockBot, inboundCh
+}
+
+func voiceUpdate(userID int64, duration int) telego.Update {
+	return telego.Update{Message: &telego.Message{
+		MessageID: 1,
+		From:      &telego.User{ID: userID},
+		Chat:      telego.Chat{ID: 42, Type: "private"},
+		Voice:     &telego.Voice{FileID: "v1", Duration: duration},
+	}}
+}
+
+func TestUpdateHandler_Voice(t *testing.T) {
+	provider := &fakeSTT{text: "Remind me to buy milk"}
+	conn, _, inboundCh := newVoiceTestConnector(t, provider)
+
+	require.NoError(t, conn.handleUpdate(voiceUpdate(123456, 5)))
+	assert.Equal(t, "ogg-data", provider.audio)
+
+	select {
+	case msg := <-inboundCh:
+		assert.Equal(t, "Remind me to buy milk", msg.Content)
+		assert.Equal(t, "telegram:42", msg.SessionID)
+		assert.Equal(t, true, msg.Metadata["voice"])
+	case <-time.After(time.Second):
+		t.Fatal("transcribed voice message was not published")
+	}
+}
+
+func TestUpdateHandler_VoiceRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider *fakeSTT
+		userID   int64
+		duration int
+		wantText string
+	}{
+		{"too long", &fakeSTT{text: "x"}, 123456, 120, "too long"},
+		{"no speech", &fakeSTT{err: stt.ErrNoSpeech}, 123456, 5, "No speech recognized"},
+		{"provider failure", &fakeSTT{err: assert.AnError}, 123456, 5, "Could not transcribe"},
+		{"not allowed", &fakeSTT{text: "x"}, 999, 5, "not authorized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mockBot, inboundCh := newVoiceTestConnector(t, tt.provider)
+
+			require.NoError(t, conn.handleUpdate(voiceUpdate(tt.userID, tt.duration)))
+			assert.Contains(t, sentText(t, mockBot), tt.wantText)
+
+			select {
+			case msg := <-inboundCh:
+				t.Fatalf("unexpected inbound message: %+v", msg)
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestUpdateHandler_Sticker(t *testing.T) {
+	conn, _, inboundCh := newVoiceTestConnector(t, &fakeSTT{})
+
+	require.NoError(t, conn.handleUpdate(telego.Update{Message: &telego.Message{
+		MessageID: 1,
+		From:      &telego.User{ID: 123456},
+		Chat:      telego.Chat{ID: 42, Type: "private"},
+		Sticker:   &telego.Sticker{FileID: "CAACAgIAAxkBAAE", Emoji: "😀", SetName: "Animals", Type: "regular"},
+	}}))
+
+	select {
+	case msg := <-inboundCh:
+		assert.Equal(t, "[Sticker 😀]", msg.Content)
+		sticker, ok := msg.Metadata["sticker"].(map[string]any)
+		require.True(t, ok, "sticker metadata is missing")
+		assert.Equal(t, "CAACAgIAAxkBAAE", sticker["file_id"])
+		assert.Equal(t, "😀", sticker["emoji"])
+		assert.Equal(t, "Animals", sticker["set_name"])
+	case <-time.After(time.Second):
+		t.Fatal("sticker message was not published")
+	}
+}
@@ -78,6 +78,11 @@ func (r *Runner) Run(workspacePath string, activeSessions map[string]bool, log *
 			stats.SessionsDeleted++
 			stats.MBytesFreed += sizeBefore
 
+			if err := removeArtifacts(workspacePath, session.ID, ActionDelete); err != nil && log != nil {
+				log.Error("failed to delete session artifacts", err,
+					logger.Field{Key: "session_id", Value: session.ID})
+			}
+
 			if log != nil {
 				log.Debug("deleted session",
 					logger.Field{Key: "session_id", Value: session.ID},
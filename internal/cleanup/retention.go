@@ -0,0 +1,278 @@
+package cleanup
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// ArchiveSubdirectory is the workspace subdirectory for archived sessions and media.
+const ArchiveSubdirectory = "archive"
+
+// ItemKind identifies what a retention item refers to.
+type ItemKind string
+
+const (
+	ItemSession ItemKind = "session" // Session JSONL file
+	ItemMedia   ItemKind = "media"   // File in a media directory
+)
+
+// PlanItem is a single file selected by retention policies.
+type PlanItem struct {
+	Kind    ItemKind  // Session or media file
+	Path    string    // Absolute path to the file
+	Size    int64     // File size in bytes
+	ModTime time.Time // Last modification time
+	Reason  string    // Human-readable reason (e.g. "older than 30 days")
+}
+
+// Plan is the result of evaluating retention policies against a workspace.
+// It lists what would be removed without changing anything on disk.
+type Plan struct {
+	Action Action     // Delete or archive
+	Items  []PlanItem // Items selected for removal
+}
+
+// TotalSize returns the total size in bytes of all planned items.
+func (p *Plan) TotalSize() int64 {
+	var total int64
+	for _, item := range p.Items {
+		total += item.Size
+	}
+	return total
+}
+
+// PlanRetention evaluates retention policies (max age, max sessions, max media size)
+// and returns the items that should be removed. Active sessions are never selected.
+func (r *Runner) PlanRetention(workspacePath string, activeSessions map[string]bool) (*Plan, error) {
+	plan := &Plan{Action: r.action()}
+
+	sessionItems, err := r.planSessions(workspacePath, activeSessions)
+	if err != nil {
+		return nil, err
+	}
+	plan.Items = append(plan.Items, sessionItems...)
+
+	mediaItems, err := r.planMedia(workspacePath)
+	if err != nil {
+		return nil, err
+	}
+	plan.Items = append(plan.Items, mediaItems...)
+
+	return plan, nil
+}
+
+// action returns the configured action, defaulting to delete.
+func (r *Runner) action() Action {
+	if r.config.Action == ActionArchive {
+		return ActionArchive
+	}
+	return ActionDelete
+}
+
+// planSessions selects sessions older than SessionTTLDays and sessions
+// beyond the MaxSessions most recently modified ones.
+func (r *Runner) planSessions(workspacePath string, activeSessions map[string]bool) ([]PlanItem, error) {
+	sessionDir := r.GetSessionDir(workspacePath)
+	sessions, err := r.ListSessions(sessionDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	// Newest first so the MaxSessions limit keeps recent sessions
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].ModTime.After(sessions[j].ModTime)
+	})
+
+	// Active sessions are always kept and count towards the MaxSessions limit
+	kept := 0
+	for _, session := range sessions {
+		if activeSessions[session.ID] {
+			kept++
+		}
+	}
+
+	var items []PlanItem
+	now := time.Now()
+	for _, session := range sessions {
+		if activeSessions[session.ID] {
+			continue
+		}
+
+		reason := ""
+		if r.config.SessionTTLDays > 0 && now.Sub(session.ModTime) > time.Duration(r.config.SessionTTLDays)*24*time.Hour {
+			reason = fmt.Sprintf("older than %d days", r.config.SessionTTLDays)
+		} else if r.config.MaxSessions > 0 && kept >= r.config.MaxSessions {
+			reason = fmt.Sprintf("exceeds max_sessions (%d)", r.config.MaxSessions)
+		}
+
+		if reason == "" {
+			kept++
+			continue
+		}
+
+		items = append(items, PlanItem{
+			Kind:    ItemSession,
+			Path:    session.Path,
+			Size:    session.Size,
+			ModTime: session.ModTime,
+			Reason:  reason,
+		})
+	}
+
+	return items, nil
+}
+
+// planMedia selects the oldest media files until the total media size
+// fits within MaxMediaSizeMB.
+func (r *Runner) planMedia(workspacePath string) ([]PlanItem, error) {
+	if r.config.MaxMediaSizeMB <= 0 {
+		return nil, nil
+	}
+
+	var files []PlanItem
+	var total int64
+	for _, dir := range r.config.MediaDirs {
+		root := filepath.Join(workspacePath, dir)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			files = append(files, PlanItem{
+				Kind:    ItemMedia,
+				Path:    path,
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+			})
+			total += info.Size()
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan media directory %s: %w", dir, err)
+		}
+	}
+
+	limit := r.config.MaxMediaSizeMB * 1024 * 1024
+	if total <= limit {
+		return nil, nil
+	}
+
+	// Oldest first
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime.Before(files[j].ModTime)
+	})
+
+	var items []PlanItem
+	for _, file := range files {
+		if total <= limit {
+			break
+		}
+		file.Reason = fmt.Sprintf("media exceeds max_media_size_mb (%d)", r.config.MaxMediaSizeMB)
+		items = append(items, file)
+		total -= file.Size
+	}
+
+	return items, nil
+}
+
+// ApplyRetention executes a retention plan, deleting or archiving each item.
+// Failures on individual items are logged and skipped.
+func (r *Runner) ApplyRetention(workspacePath string, plan *Plan, log *logger.Logger) (Stats, error) {
+	startTime := time.Now()
+	stats := Stats{}
+	var freed int64
+
+	for _, item := range plan.Items {
+		var err error
+		if plan.Action == ActionArchive {
+			err = archiveFile(workspacePath, item)
+		} else {
+			err = os.Remove(item.Path)
+			if os.IsNotExist(err) {
+				err = nil
+			}
+		}
+
+		if err != nil {
+			if log != nil {
+				log.Error("failed to apply retention", err,
+					logger.Field{Key: "path", Value: item.Path},
+					logger.Field{Key: "action", Value: string(plan.Action)})
+			}
+			continue
+		}
+
+		if item.Kind == ItemSession {
+			sessionID := strings.TrimSuffix(filepath.Base(item.Path), filepath.Ext(item.Path))
+			if err := removeArtifacts(workspacePath, sessionID, plan.Action); err != nil && log != nil {
+				log.Error("failed to apply retention to session artifacts", err,
+					logger.Field{Key: "path", Value: item.Path},
+					logger.Field{Key: "action", Value: string(plan.Action)})
+			}
+		}
+
+		switch {
+		case item.Kind == ItemMedia:
+			stats.MediaRemoved++
+		case plan.Action == ActionArchive:
+			stats.SessionsArchived++
+		default:
+			stats.SessionsDeleted++
+		}
+		if plan.Action == ActionDelete {
+			freed += item.Size
+		}
+
+		if log != nil {
+			log.Debug("retention applied",
+				logger.Field{Key: "path", Value: item.Path},
+				logger.Field{Key: "action", Value: string(plan.Action)},
+				logger.Field{Key: "reason", Value: item.Reason})
+		}
+	}
+
+	stats.MBytesFreed = (freed + 1024*1024 - 1) / (1024 * 1024)
+	stats.Duration = time.Since(startTime)
+	return stats, nil
+}
+
+// archiveFile moves a file into <workspace>/archive/<kind>/, preserving its
+// path relative to the workspace for media files.
+func archiveFile(workspacePath string, item PlanItem) error {
+	rel := filepath.Base(item.Path)
+	if item.Kind == ItemMedia {
+		if r, err := filepath.Rel(workspacePath, item.Path); err == nil {
+			rel = r
+		}
+	}
+
+	dest := filepath.Join(workspacePath, ArchiveSubdirectory, string(item.Kind), rel)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	if err := os.Rename(item.Path, dest); err != nil {
+		return fmt.Errorf("failed to archive file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package cleanup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeFileWithAge creates a file with the given size and modification time offset.
+func writeFileWithAge(t *testing.T, path string, size int, age time.Duration) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	modTime := time.Now().Add(-age)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("failed to set file time: %v", err)
+	}
+}
+
+func TestPlanRetention_MaxAgeAndMaxSessions(t *testing.T) {
+	workspace := t.TempDir()
+	sessionDir := filepath.Join(workspace, "sessions")
+
+	writeFileWithAge(t, filepath.Join(sessionDir, "old.jsonl"), 10, 40*24*time.Hour)
+	writeFileWithAge(t, filepath.Join(sessionDir, "a.jsonl"), 10, 3*time.Hour)
+	writeFileWithAge(t, filepath.Join(sessionDir, "b.jsonl"), 10, 2*time.Hour)
+	writeFileWithAge(t, filepath.Join(sessionDir, "c.jsonl"), 10, 1*time.Hour)
+	writeFileWithAge(t, filepath.Join(sessionDir, "active.jsonl"), 10, 5*time.Hour)
+
+	runner := NewRunner(Config{SessionTTLDays: 30, MaxSessions: 3})
+	plan, err := runner.PlanRetention(workspace, map[string]bool{"active": true})
+	if err != nil {
+		t.Fatalf("PlanRetention() error = %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, item := range plan.Items {
+		got[filepath.Base(item.Path)] = true
+	}
+
+	// active + c + b are kept (3), a exceeds max_sessions, old exceeds TTL
+	if len(plan.Items) != 2 || !got["old.jsonl"] || !got["a.jsonl"] {
+		t.Errorf("unexpected plan items: %v", got)
+	}
+	if plan.Action != ActionDelete {
+		t.Errorf("expected default action delete, got %s", plan.Action)
+	}
+}
+
+func TestPlanRetention_MediaSize(t *testing.T) {
+	workspace := t.TempDir()
+	mediaDir := filepath.Join(workspace, "media")
+	mb := 1024 * 1024
+
+	writeFileWithAge(t, filepath.Join(mediaDir, "oldest.bin"), mb, 3*time.Hour)
+	writeFileWithAge(t, filepath.Join(mediaDir, "sub", "older.bin"), mb, 2*time.Hour)
+	writeFileWithAge(t, filepath.Join(mediaDir, "newest.bin"), mb, 1*time.Hour)
+
+	runner := NewRunner(Config{MaxMediaSizeMB: 2, MediaDirs: []string{"media", "missing"}})
+	plan, err := runner.PlanRetention(workspace, nil)
+	if err != nil {
+		t.Fatalf("PlanRetention() error = %v", err)
+	}
+
+	if len(plan.Items) != 1 || filepath.Base(plan.Items[0].Path) != "oldest.bin" {
+		t.Fatalf("expected only oldest.bin to be planned, got %+v", plan.Items)
+	}
+	if plan.TotalSize() != int64(mb) {
+		t.Errorf("TotalSize() = %d, want %d", plan.TotalSize(), mb)
+	}
+}
+
+func TestApplyRetention_Archive(t *testing.T) {
+	workspace := t.TempDir()
+	sessionPath := filepath.Join(workspace, "sessions", "old.jsonl")
+	mediaPath := filepath.Join(workspace, "media", "photo.jpg")
+	artifactPath := filepath.Join(workspace, "artifacts", "old", "1", "report.csv")
+	writeFileWithAge(t, sessionPath, 10, 40*24*time.Hour)
+	writeFileWithAge(t, mediaPath, 10, time.Hour)
+	writeFileWithAge(t, artifactPath, 10, time.Hour)
+
+	runner := NewRunner(Config{Action: ActionArchive})
+	plan := &Plan{Action: ActionArchive, Items: []PlanItem{
+		{Kind: ItemSession, Path: sessionPath},
+		{Kind: ItemMedia, Path: mediaPath},
+	}}
+
+	stats, err := runner.ApplyRetention(workspace, plan, nil)
+	if err != nil {
+		t.Fatalf("ApplyRetention() error = %v", err)
+	}
+	if stats.SessionsArchived != 1 || stats.MediaRemoved != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	if _, err := os.Stat(sessionPath); !os.IsNotExist(err) {
+		t.Error("session file should be moved")
+	}
+	if _, err := os.Stat(filepath.Join(workspace, ArchiveSubdirectory, "session", "old.jsonl")); err != nil {
+		t.Errorf("archived session not found: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, ArchiveSubdirectory, "media", "media", "photo.jpg")); err != nil {
+		t.Errorf("archived media not found: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, ArchiveSubdirectory, "artifacts", "old", "1", "report.csv")); err != nil {
+		t.Errorf("archived session artifacts not found: %v", err)
+	}
+}
+
+func TestApplyRetention_Delete(t *testing.T) {
+	workspace := t.TempDir()
+	sessionPath := filepath.Join(workspace, "sessions", "old.jsonl")
+	artifactPath := filepath.Join(workspace, "artifacts", "old", "1", "report.csv")
+	writeFileWithAge(t, sessionPath, 10, time.Hour)
+	writeFileWithAge(t, artifactPath, 10, time.Hour)
+
+	runner := NewRunner(Config{})
+	plan := &Plan{Action: ActionDelete, Items: []PlanItem{{Kind: ItemSession, Path: sessionPath, Size: 10}}}
+
+	stats, err := runner.ApplyRetention(workspace, plan, nil)
+	if err != nil {
+		t.Fatalf("ApplyRetention() error = %v", err)
+	}
+	if stats.SessionsDeleted != 1 {
+		t.Errorf("SessionsDeleted = %d, want 1", stats.SessionsDeleted)
+	}
+	if _, err := os.Stat(sessionPath); !os.IsNotExist(err) {
+		t.Error("session file should be deleted")
+	}
+	if _, err := os.Stat(artifactPath); !os.IsNotExist(err) {
+		t.Error("session artifacts should be deleted")
+	}
+}
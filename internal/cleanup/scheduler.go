@@ -93,6 +93,21 @@ func (s *Scheduler) runCleanup(ctx context.Context) {
 	// Get active sessions (empty for now - this would be integrated with agent loop)
 	activeSessions := make(map[string]bool)
 
+	// Apply retention policies first so aged sessions are archived (if configured)
+	// instead of being deleted by the TTL cleanup below
+	plan, err := s.runner.PlanRetention(s.workspace, activeSessions)
+	if err != nil {
+		s.logger.Error("retention planning failed", err)
+	} else if len(plan.Items) > 0 {
+		retentionStats, _ := s.runner.ApplyRetention(s.workspace, plan, s.logger)
+		s.logger.Info("retention applied",
+			logger.Field{Key: "action", Value: string(plan.Action)},
+			logger.Field{Key: "sessions_deleted", Value: retentionStats.SessionsDeleted},
+			logger.Field{Key: "sessions_archived", Value: retentionStats.SessionsArchived},
+			logger.Field{Key: "media_removed", Value: retentionStats.MediaRemoved},
+			logger.Field{Key: "mb_freed", Value: retentionStats.MBytesFreed})
+	}
+
 	stats, err := s.runner.Run(s.workspace, activeSessions, s.logger)
 	if err != nil {
 		s.logger.Error("cleanup failed", err)
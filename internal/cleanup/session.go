@@ -1,9 +1,12 @@
 package cleanup
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/aatumaykin/nexbot/internal/artifacts"
 )
 
 // SessionInfo holds information about a session file.
@@ -126,3 +129,32 @@ func (r *Runner) DeleteSession(sessionPath string) error {
 	}
 	return nil
 }
+
+// removeArtifacts deletes or archives the artifacts of a removed session,
+// since files produced by tools live as long as their session.
+func removeArtifacts(workspacePath, sessionID string, action Action) error {
+	store := artifacts.NewStore(workspacePath)
+	dir, err := store.SessionDir(sessionID)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+
+	if action != ActionArchive {
+		return store.DeleteSession(sessionID)
+	}
+
+	dest := filepath.Join(workspacePath, ArchiveSubdirectory, artifacts.Subdirectory, sessionID)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	if err := os.RemoveAll(dest); err != nil {
+		return fmt.Errorf("failed to replace archived artifacts: %w", err)
+	}
+	if err := os.Rename(dir, dest); err != nil {
+		return fmt.Errorf("failed to archive artifacts: %w", err)
+	}
+	return nil
+}
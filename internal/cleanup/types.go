@@ -1,6 +1,10 @@
 package cleanup
 
-import "time"
+import (
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+)
 
 // Stats holds statistics about cleanup operations.
 type Stats struct {
@@ -9,6 +13,9 @@ type Stats struct {
 	MessagesExpired int           // Number of messages expired (by TTL)
 	MBytesFreed     int64         // Megabytes freed (rounded)
 	Duration        time.Duration // Time taken for cleanup
+
+	SessionsArchived int // Number of sessions moved to archive
+	MediaRemoved     int // Number of media files deleted or archived
 }
 
 // Config holds configuration for cleanup operations.
@@ -17,8 +24,22 @@ type Config struct {
 	SessionTTLDays   int64 // TTL for sessions in days (0 = no TTL)
 	MaxSessionSizeMB int64 // Maximum session size in MB (0 = no limit)
 	KeepActiveDays   int64 // Keep sessions active for N days after last modification
+
+	// Retention policies
+	MaxSessions    int      // Maximum number of sessions to keep (0 = no limit)
+	MaxMediaSizeMB int64    // Maximum total size of media directories in MB (0 = no limit)
+	MediaDirs      []string // Media directories relative to workspace
+	Action         Action   // What to do with expired items (delete or archive)
 }
 
+// Action defines what happens to items selected by retention policies.
+type Action string
+
+const (
+	ActionDelete  Action = "delete"  // Remove files permanently
+	ActionArchive Action = "archive" // Move files to the workspace archive directory
+)
+
 // Runner manages periodic cleanup operations.
 type Runner struct {
 	config  Config
@@ -33,3 +54,17 @@ func NewRunner(config Config) *Runner {
 		config: config,
 	}
 }
+
+// ConfigFromSettings converts the [cleanup] configuration section into runner Config.
+func ConfigFromSettings(cfg config.CleanupConfig) Config {
+	return Config{
+		MessageTTLDays:   int64(cfg.MessageTTLDays),
+		SessionTTLDays:   int64(cfg.SessionTTLDays),
+		MaxSessionSizeMB: cfg.MaxSessionSizeMB,
+		KeepActiveDays:   int64(cfg.KeepActiveDays),
+		MaxSessions:      cfg.MaxSessions,
+		MaxMediaSizeMB:   cfg.MaxMediaSizeMB,
+		MediaDirs:        cfg.MediaDirs,
+		Action:           Action(cfg.Action),
+	}
+}
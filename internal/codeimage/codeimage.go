@@ -0,0 +1,310 @@
+// Package codeimage renders long code blocks of answers as syntax-highlighted
+// PNG images. Long code blocks are unreadable in Telegram, so the answer
+// gets an image of the code to read and the raw code as a file to copy.
+package codeimage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// DefaultMinLines is the number of lines from which a code block is rendered
+	DefaultMinLines = 40
+
+	// DefaultMaxLines is the number of lines shown in an image; the raw code
+	// file always has all of them. Telegram rejects photos that are more
+	// than 20 times taller than wide and scales down large ones.
+	DefaultMaxLines = 60
+
+	// DefaultStyle is the highlighting style
+	DefaultStyle = "monokai"
+
+	// DefaultFontSize is the font size in points
+	DefaultFontSize = 14
+
+	// maxColumns truncates long lines, so images stay readable when scaled down
+	maxColumns = 120
+
+	// tabWidth is the number of spaces a tab is shown as
+	tabWidth = 4
+
+	// padding around the code in pixels
+	padding = 24
+)
+
+// ErrUnknownStyle is returned for styles chroma doesn't know.
+var ErrUnknownStyle = errors.New("unknown highlighting style")
+
+// Config configures the renderer. Zero values use the defaults.
+type Config struct {
+	MinLines int     // Code blocks with fewer lines stay in the text
+	MaxLines int     // Lines shown in an image
+	Style    string  // Chroma style name, e.g. "monokai" or "github"
+	FontSize float64 // Font size in points
+}
+
+// Block is a fenced code block of a text.
+type Block struct {
+	Language string // Language of the fence ("go" for ```go), may be empty
+	Code     string
+}
+
+// Renderer renders code blocks as images. It is safe for concurrent use.
+type Renderer struct {
+	minLines int
+	maxLines int
+	style    *chroma.Style
+
+	mu   sync.Mutex // The font face caches glyphs and is not safe for concurrent use
+	face font.Face
+}
+
+// New creates a renderer.
+func New(cfg Config) (*Renderer, error) {
+	if cfg.MinLines <= 0 {
+		cfg.MinLines = DefaultMinLines
+	}
+	if cfg.MaxLines <= 0 {
+		cfg.MaxLines = DefaultMaxLines
+	}
+	if cfg.Style == "" {
+		cfg.Style = DefaultStyle
+	}
+	if cfg.FontSize <= 0 {
+		cfg.FontSize = DefaultFontSize
+	}
+
+	style, ok := styles.Registry[cfg.Style]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownStyle, cfg.Style)
+	}
+
+	ttf, err := opentype.Parse(gomono.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+	face, err := opentype.NewFace(ttf, &opentype.FaceOptions{
+		Size:    cfg.FontSize,
+		DPI:     144,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create font face: %w", err)
+	}
+
+	return &Renderer{
+		minLines: cfg.MinLines,
+		maxLines: cfg.MaxLines,
+		style:    style,
+		face:     face,
+	}, nil
+}
+
+// Extract replaces the code blocks of text that have at least MinLines
+// lines with placeholder(i, block) and returns them. Shorter blocks and
+// unterminated fences stay in the text.
+func (r *Renderer) Extract(text string, placeholder func(i int, block Block) string) (string, []Block) {
+	lines := strings.Split(text, "\n")
+
+	var (
+		out    []string
+		blocks []Block
+	)
+	for i := 0; i < len(lines); i++ {
+		fence, language, ok := openingFence(lines[i])
+		if !ok {
+			out = append(out, lines[i])
+			continue
+		}
+		end := -1
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == fence {
+				end = j
+				break
+			}
+		}
+		if end < 0 || end-i-1 < r.minLines {
+			if end < 0 {
+				end = len(lines) - 1
+			}
+			out = append(out, lines[i:end+1]...)
+			i = end
+			continue
+		}
+		block := Block{Language: language, Code: strings.Join(lines[i+1:end], "\n")}
+		out = append(out, placeholder(len(blocks), block))
+		blocks = append(blocks, block)
+		i = end
+	}
+	return strings.Join(out, "\n"), blocks
+}
+
+// openingFence returns the fence and language of a line opening a code block.
+func openingFence(line string) (fence, language string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "```") {
+		return "", "", false
+	}
+	fence = trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, "`"))]
+	language = strings.TrimSpace(trimmed[len(fence):])
+	if strings.Contains(language, "`") {
+		// Inline code such as ```x```
+		return "", "", false
+	}
+	if fields := strings.Fields(language); len(fields) > 0 {
+		language = fields[0]
+	}
+	return fence, language, true
+}
+
+// Render renders a code block as a syntax-highlighted PNG with line
+// numbers. Lines after MaxLines are replaced by a "more lines" note.
+func (r *Renderer) Render(block Block) ([]byte, error) {
+	lexer := r.lexer(block)
+	code := strings.ReplaceAll(block.Code, "\t", strings.Repeat(" ", tabWidth))
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to highlight code: %w", err)
+	}
+	lines := chroma.SplitTokensIntoLines(iterator.Tokens())
+
+	hidden := 0
+	if len(lines) > r.maxLines {
+		hidden = len(lines) - r.maxLines
+		lines = lines[:r.maxLines]
+	}
+
+	gutter := len(fmt.Sprint(len(lines))) + 2
+	columns := 0
+	for _, line := range lines {
+		columns = max(columns, min(lineWidth(line), maxColumns))
+	}
+	columns = max(columns, 40) // Room for the "more lines" note
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	metrics := r.face.Metrics()
+	lineHeight := (metrics.Height * 6 / 5).Ceil()
+	advance, _ := r.face.GlyphAdvance('0')
+	rows := len(lines)
+	if hidden > 0 {
+		rows++
+	}
+	width := padding*2 + (advance * fixed.Int26_6(gutter+columns)).Ceil()
+	height := padding*2 + lineHeight*rows
+
+	background := r.colour(r.style.Get(chroma.Background).Background, color.RGBA{R: 0x27, G: 0x28, B: 0x22, A: 0xff})
+	foreground := r.colour(r.style.Get(chroma.Text).Colour, color.RGBA{R: 0xf8, G: 0xf8, B: 0xf2, A: 0xff})
+	muted := r.colour(r.style.Get(chroma.Comment).Colour, foreground)
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	drawer := &font.Drawer{Dst: img, Face: r.face}
+
+	for i, line := range lines {
+		y := fixed.I(padding + lineHeight*i + metrics.Ascent.Ceil())
+		drawer.Dot = fixed.Point26_6{X: fixed.I(padding), Y: y}
+		drawer.Src = image.NewUniform(muted)
+		drawer.DrawString(fmt.Sprintf("%*d  ", gutter-2, i+1))
+
+		// Lines longer than maxColumns end with "…" in the last column
+		limit := maxColumns
+		if lineWidth(line) > maxColumns {
+			limit--
+		}
+		column := 0
+		for _, token := range line {
+			value := strings.TrimRight(token.Value, "\n")
+			drawer.Src = image.NewUniform(r.colour(r.style.Get(token.Type).Colour, foreground))
+			for _, ch := range value {
+				if column == limit {
+					break
+				}
+				drawer.DrawString(string(ch))
+				column++
+			}
+		}
+		if limit < maxColumns {
+			drawer.Src = image.NewUniform(muted)
+			drawer.DrawString("…")
+		}
+	}
+	if hidden > 0 {
+		drawer.Dot = fixed.Point26_6{X: fixed.I(padding), Y: fixed.I(padding + lineHeight*len(lines) + metrics.Ascent.Ceil())}
+		drawer.Src = image.NewUniform(muted)
+		drawer.DrawString(fmt.Sprintf("… %d more lines in the attached file", hidden))
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Extension returns the file extension of a code block's language
+// (".go" for go), ".txt" if it is unknown.
+func (r *Renderer) Extension(block Block) string {
+	lexer := lexers.Get(block.Language)
+	if block.Language == "" || lexer == nil {
+		return ".txt"
+	}
+	for _, pattern := range lexer.Config().Filenames {
+		if ext := filepath.Ext(pattern); strings.HasPrefix(pattern, "*.") && !strings.ContainsAny(ext, "*[?") {
+			return ext
+		}
+	}
+	return ".txt"
+}
+
+// lexer returns the lexer of a code block's language, guessing it from the
+// code if the fence has none.
+func (r *Renderer) lexer(block Block) chroma.Lexer {
+	var lexer chroma.Lexer
+	if block.Language != "" {
+		lexer = lexers.Get(block.Language)
+	}
+	if lexer == nil {
+		lexer = lexers.Analyse(block.Code)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	return lexer
+}
+
+// lineWidth returns the number of characters of a highlighted line.
+func lineWidth(line []chroma.Token) int {
+	width := 0
+	for _, token := range line {
+		width += utf8.RuneCountInString(strings.TrimRight(token.Value, "\n"))
+	}
+	return width
+}
+
+// colour converts a chroma colour, returning fallback if it is unset.
+func (r *Renderer) colour(c chroma.Colour, fallback color.Color) color.Color {
+	if !c.IsSet() {
+		return fallback
+	}
+	return color.RGBA{R: c.Red(), G: c.Green(), B: c.Blue(), A: 0xff}
+}
@@ -0,0 +1,85 @@
+package codeimage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// numberedLines returns n lines of Go code
+func numberedLines(n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("\tfmt.Println(%d) // line %d", i, i)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestRenderer_Extract(t *testing.T) {
+	r, err := New(Config{MinLines: 3})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	long := numberedLines(3)
+	text := "Here it is:\n```go\n" + long + "\n```\nShort one:\n```\nx := 1\n```\nUnterminated:\n```sh\necho"
+	got, blocks := r.Extract(text, func(i int, block Block) string {
+		return fmt.Sprintf("[code %d]", i+1)
+	})
+
+	want := "Here it is:\n[code 1]\nShort one:\n```\nx := 1\n```\nUnterminated:\n```sh\necho"
+	if got != want {
+		t.Errorf("Extract() text = %q, want %q", got, want)
+	}
+	if len(blocks) != 1 || blocks[0].Language != "go" || blocks[0].Code != long {
+		t.Errorf("Extract() blocks = %+v", blocks)
+	}
+}
+
+func TestRenderer_Render(t *testing.T) {
+	r, err := New(Config{MaxLines: 10})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	data, err := r.Render(Block{Language: "go", Code: numberedLines(25)})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Render() is not a PNG: %v", err)
+	}
+	// 10 lines and the "more lines" note
+	short, _ := r.Render(Block{Language: "go", Code: numberedLines(5)})
+	shortImg, _ := png.Decode(bytes.NewReader(short))
+	if img.Bounds().Dy() <= shortImg.Bounds().Dy() || img.Bounds().Dy() > shortImg.Bounds().Dy()*3 {
+		t.Errorf("Render() height = %d, 5 lines = %d", img.Bounds().Dy(), shortImg.Bounds().Dy())
+	}
+
+	// Code without a language is rendered too
+	if _, err := r.Render(Block{Code: "SELECT 1;"}); err != nil {
+		t.Errorf("Render() without language error = %v", err)
+	}
+}
+
+func TestRenderer_Extension(t *testing.T) {
+	r, err := New(Config{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for language, want := range map[string]string{"go": ".go", "python": ".py", "": ".txt", "no-such-language": ".txt"} {
+		if got := r.Extension(Block{Language: language}); got != want {
+			t.Errorf("Extension(%q) = %q, want %q", language, got, want)
+		}
+	}
+}
+
+func TestNew_UnknownStyle(t *testing.T) {
+	if _, err := New(Config{Style: "no-such-style"}); !errors.Is(err, ErrUnknownStyle) {
+		t.Errorf("New() error = %v, want ErrUnknownStyle", err)
+	}
+}
@@ -3,31 +3,125 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/aatumaykin/nexbot/internal/agent"
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/agent/transcript"
 	"github.com/aatumaykin/nexbot/internal/bus"
 	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/feedback"
+	"github.com/aatumaykin/nexbot/internal/llm"
 	"github.com/aatumaykin/nexbot/internal/logger"
 	"github.com/aatumaykin/nexbot/internal/messages"
+	"github.com/aatumaykin/nexbot/internal/projects"
+	"github.com/aatumaykin/nexbot/internal/snapshot"
 )
 
 // AgentLoopInterface defines the interface for agent loop operations needed by Handler
 type AgentLoopInterface interface {
 	ClearSession(ctx context.Context, sessionID string) error
 	GetSessionStatus(ctx context.Context, sessionID string) (map[string]any, error)
+	ExportSession(ctx context.Context, sessionID string, format transcript.Format) (string, error)
+	ListSessions(ctx context.Context) ([]session.Info, error)
+	SaveSessionAs(ctx context.Context, sessionID, name string) (string, error)
+	ResumeSession(ctx context.Context, sessionID, name string) (string, error)
 }
 
+// maxListedSessions limits the number of sessions shown by the sessions command
+const maxListedSessions = 15
+
+// projectLeaveArg is the argument of the project command that detaches the chat
+const projectLeaveArg = "leave"
+
 // MessageBusInterface defines the interface for message bus operations needed by Handler
 type MessageBusInterface interface {
 	PublishOutbound(msg bus.OutboundMessage) error
 }
 
+// FeedbackStore defines the interface for persisting feedback (implemented by feedback.Store)
+type FeedbackStore interface {
+	Append(entry feedback.Entry) error
+}
+
+// VariantSource defines the interface for looking up the prompt variant that
+// served a session (implemented by loop.Loop)
+type VariantSource interface {
+	SessionVariant(sessionID string) string
+}
+
+// ArtifactStore defines the interface for removing the files produced by
+// tools in a session (implemented by artifacts.Store)
+type ArtifactStore interface {
+	DeleteSession(sessionID string) error
+}
+
+// Exporter defines the interface for exporting sessions to external notes
+// (implemented by export.Exporter)
+type Exporter interface {
+	HasTarget(name string) bool
+	Export(ctx context.Context, sessionID, target string) (string, error)
+}
+
+// ProjectStore defines the interface for attaching chats to projects
+// (implemented by projects.Store)
+type ProjectStore interface {
+	Attach(sessionID, name string) (projects.Project, bool, error)
+	Detach(sessionID string) (string, error)
+	ForSession(sessionID string) (projects.Project, bool)
+	List() []projects.Project
+}
+
+// ContextStore defines the interface for named contexts of a chat
+// (implemented by session.Manager)
+type ContextStore interface {
+	SwitchContext(sessionID, name string) (string, error)
+	CurrentContext(sessionID string) (string, bool)
+	Contexts(sessionID string) ([]session.ContextInfo, error)
+	DeleteContext(sessionID, name string) error
+}
+
+// SamplingStore defines the interface for the sampling overrides of a chat
+// (implemented by session.Manager)
+type SamplingStore interface {
+	Sampling(sessionID string) (llm.Sampling, error)
+	SetSampling(sessionID string, sampling llm.Sampling) error
+}
+
+// FollowupSettings defines the interface for the user's opt-in to proactive
+// follow-ups (implemented by followup.Manager)
+type FollowupSettings interface {
+	OptedIn(sessionID string) bool
+	SetOptIn(sessionID string, allowed bool) (int, error)
+	Pending(sessionID string) []agent.Job
+}
+
+// RollbackStore defines the interface for undoing the file changes of the
+// agent's turns (implemented by snapshot.Store)
+type RollbackStore interface {
+	Rollback(sessionID string) (snapshot.Result, error)
+}
+
 // Handler handles Telegram commands for the agent.
 type Handler struct {
-	agentLoop  AgentLoopInterface
-	messageBus MessageBusInterface
-	logger     *logger.Logger
-	onRestart  func() error
+	agentLoop       AgentLoopInterface
+	messageBus      MessageBusInterface
+	logger          *logger.Logger
+	onRestart       func() error
+	feedback        FeedbackStore
+	feedbackProfile string
+	variants        VariantSource
+	artifacts       ArtifactStore
+	exporter        Exporter
+	projects        ProjectStore
+	followups       FollowupSettings
+	rollback        RollbackStore
+	contexts        ContextStore
+	sampling        SamplingStore
 }
 
 // NewHandler creates a new command handler.
@@ -45,6 +139,60 @@ func NewHandler(
 	}
 }
 
+// SetFeedbackStore enables feedback collection. profile labels the active
+// prompt profile so reports can compare prompt variants.
+func (h *Handler) SetFeedbackStore(store FeedbackStore, profile string) {
+	h.feedback = store
+	h.feedbackProfile = profile
+}
+
+// SetVariantSource labels feedback with the prompt experiment variant that
+// served the session instead of the static profile.
+func (h *Handler) SetVariantSource(source VariantSource) {
+	h.variants = source
+}
+
+// SetArtifactStore makes the new session command remove the files produced
+// by tools in the cleared session.
+func (h *Handler) SetArtifactStore(store ArtifactStore) {
+	h.artifacts = store
+}
+
+// SetExporter enables exporting sessions to external notes with
+// "/export <target>" (e.g. "/export notion").
+func (h *Handler) SetExporter(exporter Exporter) {
+	h.exporter = exporter
+}
+
+// SetProjectStore enables attaching chats to projects with "/project <name>".
+func (h *Handler) SetProjectStore(store ProjectStore) {
+	h.projects = store
+}
+
+// SetFollowups enables "/followups on|off" to allow or forbid proactive
+// follow-ups of the agent.
+func (h *Handler) SetFollowups(settings FollowupSettings) {
+	h.followups = settings
+}
+
+// SetRollbackStore enables "/rollback" to undo the file changes of the
+// agent's last turn.
+func (h *Handler) SetRollbackStore(store RollbackStore) {
+	h.rollback = store
+}
+
+// SetContextStore enables the context command switching between named
+// contexts of a chat.
+func (h *Handler) SetContextStore(store ContextStore) {
+	h.contexts = store
+}
+
+// SetSamplingStore enables the sampling command overriding the sampling
+// parameters of a chat.
+func (h *Handler) SetSamplingStore(store SamplingStore) {
+	h.sampling = store
+}
+
 // HandleCommand processes a command based on its type.
 func (h *Handler) HandleCommand(ctx context.Context, cmd string, msg bus.InboundMessage) error {
 	switch cmd {
@@ -54,6 +202,26 @@ func (h *Handler) HandleCommand(ctx context.Context, cmd string, msg bus.Inbound
 		return h.handleStatus(ctx, msg)
 	case constants.CommandRestart:
 		return h.handleRestart(ctx, msg)
+	case constants.CommandExport:
+		return h.handleExport(ctx, msg)
+	case constants.CommandFeedback:
+		return h.handleFeedback(ctx, msg)
+	case constants.CommandSessions:
+		return h.handleSessions(ctx, msg)
+	case constants.CommandSaveAs:
+		return h.handleSaveAs(ctx, msg)
+	case constants.CommandResume:
+		return h.handleResume(ctx, msg)
+	case constants.CommandContext:
+		return h.handleContext(ctx, msg)
+	case constants.CommandSampling:
+		return h.handleSampling(ctx, msg)
+	case constants.CommandProject:
+		return h.handleProject(ctx, msg)
+	case constants.CommandFollowups:
+		return h.handleFollowups(ctx, msg)
+	case constants.CommandRollback:
+		return h.handleRollback(ctx, msg)
 	default:
 		h.logger.WarnCtx(ctx, "Unknown command",
 			logger.Field{Key: "command", Value: cmd},
@@ -73,6 +241,15 @@ func (h *Handler) handleNewSession(ctx context.Context, msg bus.InboundMessage)
 		return fmt.Errorf("failed to clear session: %w", err)
 	}
 
+	// Files produced by tools live as long as the session
+	if h.artifacts != nil {
+		if err := h.artifacts.DeleteSession(msg.SessionID); err != nil {
+			h.logger.WarnCtx(ctx, "Failed to delete session artifacts",
+				logger.Field{Key: "session_id", Value: msg.SessionID},
+				logger.Field{Key: "error", Value: err.Error()})
+		}
+	}
+
 	// Send confirmation message
 	confirmationMsg := bus.NewOutboundMessage(
 		msg.ChannelType,
@@ -188,3 +365,517 @@ func (h *Handler) handleRestart(ctx context.Context, msg bus.InboundMessage) err
 
 	return nil
 }
+
+// handleExport exports the session transcript and sends it as a document.
+// The format can be passed as a command argument: "/export html". A configured
+// export target as the argument pushes the transcript there: "/export notion".
+func (h *Handler) handleExport(ctx context.Context, msg bus.InboundMessage) error {
+	h.logger.InfoCtx(ctx, "Exporting session",
+		logger.Field{Key: "session_id", Value: msg.SessionID})
+
+	var formatArg string
+	if fields := strings.Fields(msg.Content); len(fields) > 1 {
+		formatArg = fields[1]
+	}
+
+	if target := strings.ToLower(formatArg); h.exporter != nil && h.exporter.HasTarget(target) {
+		location, err := h.exporter.Export(ctx, msg.SessionID, target)
+		if err != nil {
+			return h.publishExportError(ctx, msg, err)
+		}
+		return h.publishText(ctx, msg, fmt.Sprintf(constants.MsgExportedTo, target, location))
+	}
+
+	format, err := transcript.ParseFormat(formatArg)
+	if err != nil {
+		return h.publishExportError(ctx, msg, err)
+	}
+
+	path, err := h.agentLoop.ExportSession(ctx, msg.SessionID, format)
+	if err != nil {
+		return h.publishExportError(ctx, msg, err)
+	}
+
+	documentMsg := bus.NewDocumentMessage(
+		msg.ChannelType,
+		msg.UserID,
+		msg.SessionID,
+		&bus.MediaData{
+			Type:      "document",
+			LocalPath: path,
+			FileName:  filepath.Base(path),
+			Caption:   constants.MsgExportCaption,
+		},
+		"", // correlationID (not used for commands)
+		bus.FormatTypePlain,
+		nil, // metadata
+	)
+
+	if err := h.messageBus.PublishOutbound(*documentMsg); err != nil {
+		h.logger.ErrorCtx(ctx, "Failed to publish export document", err,
+			logger.Field{Key: "session_id", Value: msg.SessionID})
+		return fmt.Errorf("failed to publish export document: %w", err)
+	}
+
+	return nil
+}
+
+// publishExportError notifies the user that export failed.
+func (h *Handler) publishExportError(ctx context.Context, msg bus.InboundMessage, err error) error {
+	h.logger.ErrorCtx(ctx, "Failed to export session", err,
+		logger.Field{Key: "session_id", Value: msg.SessionID})
+
+	errorMsg := bus.NewOutboundMessage(
+		msg.ChannelType,
+		msg.UserID,
+		msg.SessionID,
+		constants.MsgExportError,
+		"", // correlationID (not used for commands)
+		bus.FormatTypePlain,
+		nil, // metadata
+	)
+
+	if pubErr := h.messageBus.PublishOutbound(*errorMsg); pubErr != nil {
+		return fmt.Errorf("failed to export session and failed to publish error message: %w (publish error: %v)", err, pubErr)
+	}
+	return fmt.Errorf("failed to export session: %w", err)
+}
+
+// handleSessions lists sessions with titles, last activity and message counts.
+func (h *Handler) handleSessions(ctx context.Context, msg bus.InboundMessage) error {
+	sessions, err := h.agentLoop.ListSessions(ctx)
+	if err != nil {
+		h.logger.ErrorCtx(ctx, "Failed to list sessions", err,
+			logger.Field{Key: "session_id", Value: msg.SessionID})
+		if pubErr := h.publishText(ctx, msg, constants.MsgSessionsError); pubErr != nil {
+			return fmt.Errorf("failed to list sessions and failed to publish error message: %w (publish error: %v)", err, pubErr)
+		}
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return h.publishText(ctx, msg, messages.FormatSessionList(sessions, msg.SessionID, maxListedSessions))
+}
+
+// handleSaveAs saves the current session under a name: "/save_as project-x".
+func (h *Handler) handleSaveAs(ctx context.Context, msg bus.InboundMessage) error {
+	name, ok := commandArg(msg.Content)
+	if !ok {
+		return h.publishText(ctx, msg, constants.MsgSaveAsUsage)
+	}
+
+	named, err := h.agentLoop.SaveSessionAs(ctx, msg.SessionID, name)
+	if err != nil {
+		return h.publishSessionNameError(ctx, msg, name, err)
+	}
+
+	h.logger.InfoCtx(ctx, "Session saved under name",
+		logger.Field{Key: "session_id", Value: msg.SessionID},
+		logger.Field{Key: "named_session", Value: named})
+
+	name, _ = session.SessionName(named)
+	return h.publishText(ctx, msg, fmt.Sprintf(constants.MsgSessionSaved, name, name))
+}
+
+// handleResume continues a named session in the current chat: "/resume project-x".
+func (h *Handler) handleResume(ctx context.Context, msg bus.InboundMessage) error {
+	name, ok := commandArg(msg.Content)
+	if !ok {
+		return h.publishText(ctx, msg, constants.MsgResumeUsage)
+	}
+
+	named, err := h.agentLoop.ResumeSession(ctx, msg.SessionID, name)
+	if err != nil {
+		return h.publishSessionNameError(ctx, msg, name, err)
+	}
+
+	h.logger.InfoCtx(ctx, "Named session resumed",
+		logger.Field{Key: "session_id", Value: msg.SessionID},
+		logger.Field{Key: "named_session", Value: named})
+
+	name, _ = session.SessionName(named)
+	return h.publishText(ctx, msg, fmt.Sprintf(constants.MsgSessionResumed, name))
+}
+
+// publishSessionNameError explains why saving or resuming a named session failed.
+func (h *Handler) publishSessionNameError(ctx context.Context, msg bus.InboundMessage, name string, err error) error {
+	var text string
+	switch {
+	case errors.Is(err, session.ErrInvalidName):
+		text = session.ErrInvalidName.Error()
+	case errors.Is(err, session.ErrNameTaken):
+		text = fmt.Sprintf(constants.MsgSessionNameTaken, name, name)
+	case errors.Is(err, session.ErrNamedSessionNotFound):
+		text = fmt.Sprintf(constants.MsgNamedSessionNotFound, name)
+	default:
+		h.logger.ErrorCtx(ctx, "Failed to update named session", err,
+			logger.Field{Key: "session_id", Value: msg.SessionID})
+		text = constants.MsgSessionNameError
+	}
+
+	if pubErr := h.publishText(ctx, msg, text); pubErr != nil {
+		return fmt.Errorf("failed to update named session and failed to publish error message: %w (publish error: %v)", err, pubErr)
+	}
+	return fmt.Errorf("failed to update named session: %w", err)
+}
+
+// handleContext lists, switches or deletes the named contexts of the chat:
+// "/context", "/context work", "/context delete work".
+func (h *Handler) handleContext(ctx context.Context, msg bus.InboundMessage) error {
+	if h.contexts == nil {
+		return h.publishText(ctx, msg, constants.MsgContextUsage)
+	}
+
+	fields := strings.Fields(msg.Content)
+	switch {
+	case len(fields) < 2:
+		contexts, err := h.contexts.Contexts(msg.SessionID)
+		if err != nil {
+			return h.publishContextError(ctx, msg, "", err)
+		}
+		_, inContext := h.contexts.CurrentContext(msg.SessionID)
+		return h.publishText(ctx, msg, messages.FormatContexts(contexts, !inContext))
+
+	case fields[1] == "delete":
+		if len(fields) < 3 {
+			return h.publishText(ctx, msg, constants.MsgContextUsage)
+		}
+		if err := h.contexts.DeleteContext(msg.SessionID, fields[2]); err != nil {
+			return h.publishContextError(ctx, msg, fields[2], err)
+		}
+		h.logger.InfoCtx(ctx, "Context deleted",
+			logger.Field{Key: "session_id", Value: msg.SessionID},
+			logger.Field{Key: "context", Value: fields[2]})
+		return h.publishText(ctx, msg, fmt.Sprintf(constants.MsgContextDeleted, fields[2]))
+	}
+
+	name, err := h.contexts.SwitchContext(msg.SessionID, fields[1])
+	if err != nil {
+		return h.publishContextError(ctx, msg, fields[1], err)
+	}
+	h.logger.InfoCtx(ctx, "Context switched",
+		logger.Field{Key: "session_id", Value: msg.SessionID},
+		logger.Field{Key: "context", Value: name})
+
+	count := 0
+	if contexts, err := h.contexts.Contexts(msg.SessionID); err == nil {
+		for _, c := range contexts {
+			if c.Name == name {
+				count = c.MessageCount
+			}
+		}
+	}
+	return h.publishText(ctx, msg, fmt.Sprintf(constants.MsgContextSwitched, name, count))
+}
+
+// publishContextError explains why a context command failed.
+func (h *Handler) publishContextError(ctx context.Context, msg bus.InboundMessage, name string, err error) error {
+	var text string
+	switch {
+	case errors.Is(err, session.ErrInvalidName):
+		text = session.ErrInvalidName.Error()
+	case errors.Is(err, session.ErrContextNotFound):
+		text = fmt.Sprintf(constants.MsgContextNotFound, name)
+	case errors.Is(err, session.ErrContextInUse):
+		text = fmt.Sprintf(constants.MsgContextInUse, name)
+	default:
+		h.logger.ErrorCtx(ctx, "Failed to update context", err,
+			logger.Field{Key: "session_id", Value: msg.SessionID})
+		text = constants.MsgContextError
+	}
+
+	if pubErr := h.publishText(ctx, msg, text); pubErr != nil {
+		return fmt.Errorf("failed to update context and failed to publish error message: %w (publish error: %v)", err, pubErr)
+	}
+	return fmt.Errorf("failed to update context: %w", err)
+}
+
+// handleSampling shows, sets or clears the sampling overrides of the chat:
+// "/sampling", "/sampling temperature=0.2 top_p=0.9", "/sampling reset".
+// New parameters are merged into the current overrides.
+func (h *Handler) handleSampling(ctx context.Context, msg bus.InboundMessage) error {
+	if h.sampling == nil {
+		return h.publishText(ctx, msg, constants.MsgSamplingUsage)
+	}
+
+	current, err := h.sampling.Sampling(msg.SessionID)
+	if err != nil {
+		return h.publishSamplingError(ctx, msg, err)
+	}
+
+	arg, ok := commandArg(msg.Content)
+	switch {
+	case !ok:
+		text := constants.MsgSamplingNone
+		if !current.IsZero() {
+			text = fmt.Sprintf(constants.MsgSamplingCurrent, current)
+		}
+		return h.publishText(ctx, msg, text+constants.MsgSamplingUsage)
+
+	case arg == "reset":
+		if err := h.sampling.SetSampling(msg.SessionID, llm.Sampling{}); err != nil {
+			return h.publishSamplingError(ctx, msg, err)
+		}
+		h.logger.InfoCtx(ctx, "Sampling overrides cleared",
+			logger.Field{Key: "session_id", Value: msg.SessionID})
+		return h.publishText(ctx, msg, constants.MsgSamplingReset)
+	}
+
+	_, args, _ := strings.Cut(strings.TrimSpace(msg.Content), " ")
+	override, err := llm.ParseSampling(args)
+	if err != nil {
+		return h.publishText(ctx, msg, fmt.Sprintf(constants.MsgSamplingInvalid, err))
+	}
+	sampling := current.Merge(override)
+	if err := h.sampling.SetSampling(msg.SessionID, sampling); err != nil {
+		return h.publishSamplingError(ctx, msg, err)
+	}
+	h.logger.InfoCtx(ctx, "Sampling overrides set",
+		logger.Field{Key: "session_id", Value: msg.SessionID},
+		logger.Field{Key: "sampling", Value: sampling.String()})
+	return h.publishText(ctx, msg, fmt.Sprintf(constants.MsgSamplingSet, sampling))
+}
+
+// publishSamplingError reports a failure to read or store the sampling overrides.
+func (h *Handler) publishSamplingError(ctx context.Context, msg bus.InboundMessage, err error) error {
+	h.logger.ErrorCtx(ctx, "Failed to update sampling overrides", err,
+		logger.Field{Key: "session_id", Value: msg.SessionID})
+	if pubErr := h.publishText(ctx, msg, constants.MsgSamplingError); pubErr != nil {
+		return fmt.Errorf("failed to update sampling overrides and failed to publish error message: %w (publish error: %v)", err, pubErr)
+	}
+	return fmt.Errorf("failed to update sampling overrides: %w", err)
+}
+
+// handleProject shows, attaches or detaches the project of the chat:
+// "/project", "/project homelab", "/project leave".
+func (h *Handler) handleProject(ctx context.Context, msg bus.InboundMessage) error {
+	if h.projects == nil {
+		return h.publishText(ctx, msg, constants.MsgProjectsDisabled)
+	}
+
+	name, ok := commandArg(msg.Content)
+	if !ok {
+		var current *projects.Project
+		if p, attached := h.projects.ForSession(msg.SessionID); attached {
+			current = &p
+		}
+		return h.publishText(ctx, msg, messages.FormatProject(current, h.projects.List()))
+	}
+
+	if name == projectLeaveArg {
+		left, err := h.projects.Detach(msg.SessionID)
+		if err != nil {
+			return h.publishProjectError(ctx, msg, err)
+		}
+		if left == "" {
+			return h.publishText(ctx, msg, constants.MsgProjectNone)
+		}
+		h.logger.InfoCtx(ctx, "Chat detached from project",
+			logger.Field{Key: "session_id", Value: msg.SessionID},
+			logger.Field{Key: "project", Value: left})
+		return h.publishText(ctx, msg, fmt.Sprintf(constants.MsgProjectLeft, left))
+	}
+
+	p, created, err := h.projects.Attach(msg.SessionID, name)
+	if errors.Is(err, projects.ErrInvalidName) {
+		return h.publishText(ctx, msg, projects.ErrInvalidName.Error())
+	}
+	if err != nil {
+		return h.publishProjectError(ctx, msg, err)
+	}
+
+	h.logger.InfoCtx(ctx, "Chat attached to project",
+		logger.Field{Key: "session_id", Value: msg.SessionID},
+		logger.Field{Key: "project", Value: p.Name},
+		logger.Field{Key: "created", Value: created})
+
+	if created {
+		return h.publishText(ctx, msg, fmt.Sprintf(constants.MsgProjectCreated, p.Name))
+	}
+	return h.publishText(ctx, msg, fmt.Sprintf(constants.MsgProjectAttached, p.Name))
+}
+
+// publishProjectError reports a failed project update.
+func (h *Handler) publishProjectError(ctx context.Context, msg bus.InboundMessage, err error) error {
+	h.logger.ErrorCtx(ctx, "Failed to update project", err,
+		logger.Field{Key: "session_id", Value: msg.SessionID})
+	if pubErr := h.publishText(ctx, msg, constants.MsgProjectError); pubErr != nil {
+		return fmt.Errorf("failed to update project and failed to publish error message: %w (publish error: %v)", err, pubErr)
+	}
+	return fmt.Errorf("failed to update project: %w", err)
+}
+
+// handleFollowups shows or changes whether the agent may follow up on its
+// own: /followups, /followups on, /followups off.
+func (h *Handler) handleFollowups(ctx context.Context, msg bus.InboundMessage) error {
+	if h.followups == nil {
+		return h.publishText(ctx, msg, constants.MsgFollowupsDisabled)
+	}
+
+	arg, _ := commandArg(msg.Content)
+	switch strings.ToLower(arg) {
+	case "on", "off":
+	default:
+		state := "off"
+		if h.followups.OptedIn(msg.SessionID) {
+			state = "on"
+		}
+		return h.publishText(ctx, msg, fmt.Sprintf(constants.MsgFollowupsStatus, state, len(h.followups.Pending(msg.SessionID))))
+	}
+
+	allowed := strings.ToLower(arg) == "on"
+	cancelled, err := h.followups.SetOptIn(msg.SessionID, allowed)
+	if err != nil {
+		h.logger.ErrorCtx(ctx, "Failed to update follow-up settings", err,
+			logger.Field{Key: "session_id", Value: msg.SessionID})
+		if pubErr := h.publishText(ctx, msg, constants.MsgFollowupsError); pubErr != nil {
+			return fmt.Errorf("failed to update follow-up settings and failed to publish error message: %w (publish error: %v)", err, pubErr)
+		}
+		return fmt.Errorf("failed to update follow-up settings: %w", err)
+	}
+
+	h.logger.InfoCtx(ctx, "Follow-up settings changed",
+		logger.Field{Key: "session_id", Value: msg.SessionID},
+		logger.Field{Key: "allowed", Value: allowed},
+		logger.Field{Key: "cancelled", Value: cancelled})
+
+	if allowed {
+		return h.publishText(ctx, msg, constants.MsgFollowupsOn)
+	}
+	return h.publishText(ctx, msg, fmt.Sprintf(constants.MsgFollowupsOff, cancelled))
+}
+
+// handleRollback undoes the file changes of the agent's last turn that
+// changed files; repeated /rollback goes further back.
+func (h *Handler) handleRollback(ctx context.Context, msg bus.InboundMessage) error {
+	if h.rollback == nil {
+		return h.publishText(ctx, msg, constants.MsgRollbackDisabled)
+	}
+
+	result, err := h.rollback.Rollback(msg.SessionID)
+	if errors.Is(err, snapshot.ErrNothingToRollback) {
+		return h.publishText(ctx, msg, constants.MsgRollbackNothing)
+	}
+	if err != nil {
+		h.logger.ErrorCtx(ctx, "Failed to roll back changes", err,
+			logger.Field{Key: "session_id", Value: msg.SessionID})
+		if pubErr := h.publishText(ctx, msg, constants.MsgRollbackError); pubErr != nil {
+			return fmt.Errorf("failed to roll back changes and failed to publish error message: %w (publish error: %v)", err, pubErr)
+		}
+		return fmt.Errorf("failed to roll back changes: %w", err)
+	}
+
+	h.logger.InfoCtx(ctx, "Changes rolled back",
+		logger.Field{Key: "session_id", Value: msg.SessionID},
+		logger.Field{Key: "restored", Value: result.Restored},
+		logger.Field{Key: "removed", Value: result.Removed},
+		logger.Field{Key: "skipped", Value: len(result.Skipped)})
+
+	text := fmt.Sprintf(constants.MsgRollbackDone, result.Time.Format("2006-01-02 15:04"),
+		result.Restored, result.Removed, result.Remaining)
+	if len(result.Skipped) > 0 {
+		text += fmt.Sprintf(constants.MsgRollbackSkipped, strings.Join(result.Skipped, ", "))
+	}
+	return h.publishText(ctx, msg, text)
+}
+
+// commandArg returns the first argument of a command message ("/resume project-x" -> "project-x").
+func commandArg(content string) (string, bool) {
+	fields := strings.Fields(content)
+	if len(fields) < 2 {
+		return "", false
+	}
+	return fields[1], true
+}
+
+// handleFeedback records feedback from the /feedback command or a message reaction.
+// Reactions are recorded silently; the command is answered with a confirmation.
+func (h *Handler) handleFeedback(ctx context.Context, msg bus.InboundMessage) error {
+	entry := feedback.Entry{
+		Timestamp: time.Now(),
+		SessionID: msg.SessionID,
+		UserID:    msg.UserID,
+		Channel:   string(msg.ChannelType),
+		Source:    feedback.SourceCommand,
+		Profile:   h.feedbackProfile,
+	}
+	if h.variants != nil {
+		if variant := h.variants.SessionVariant(msg.SessionID); variant != "" {
+			entry.Profile = variant
+		}
+	}
+	if entry.Profile == "" {
+		entry.Profile = feedback.DefaultProfile
+	}
+	if messageID, ok := msg.Metadata["message_id"].(int); ok {
+		entry.MessageID = messageID
+	}
+
+	if reaction, ok := msg.Metadata["reaction"].(string); ok {
+		score, known := feedback.ReactionScore(reaction)
+		if !known || h.feedback == nil {
+			return nil
+		}
+		entry.Source = feedback.SourceReaction
+		entry.Reaction = reaction
+		entry.Score = score
+	} else {
+		if h.feedback == nil {
+			return h.publishText(ctx, msg, constants.MsgFeedbackDisabled)
+		}
+		score, rating, comment, err := feedback.ParseCommand(msg.Content)
+		if err != nil {
+			return h.publishText(ctx, msg, constants.MsgFeedbackUsage)
+		}
+		entry.Score = score
+		entry.Rating = rating
+		entry.Comment = comment
+	}
+
+	// Attach the model and tools of the latest answer for aggregation
+	if status, err := h.agentLoop.GetSessionStatus(ctx, msg.SessionID); err == nil {
+		entry.Model, _ = status["model"].(string)
+		entry.Tools, _ = status["last_tools"].([]string)
+	}
+
+	if err := h.feedback.Append(entry); err != nil {
+		h.logger.ErrorCtx(ctx, "Failed to save feedback", err,
+			logger.Field{Key: "session_id", Value: msg.SessionID})
+		if entry.Source == feedback.SourceReaction {
+			return fmt.Errorf("failed to save feedback: %w", err)
+		}
+		if pubErr := h.publishText(ctx, msg, constants.MsgFeedbackError); pubErr != nil {
+			return fmt.Errorf("failed to save feedback and failed to publish error message: %w (publish error: %v)", err, pubErr)
+		}
+		return fmt.Errorf("failed to save feedback: %w", err)
+	}
+
+	h.logger.InfoCtx(ctx, "Feedback recorded",
+		logger.Field{Key: "session_id", Value: msg.SessionID},
+		logger.Field{Key: "source", Value: entry.Source},
+		logger.Field{Key: "score", Value: entry.Score})
+
+	if entry.Source == feedback.SourceReaction {
+		return nil
+	}
+	return h.publishText(ctx, msg, constants.MsgFeedbackThanks)
+}
+
+// publishText sends a plain text reply to the command sender.
+func (h *Handler) publishText(ctx context.Context, msg bus.InboundMessage, text string) error {
+	outboundMsg := bus.NewOutboundMessage(
+		msg.ChannelType,
+		msg.UserID,
+		msg.SessionID,
+		text,
+		"", // correlationID (not used for commands)
+		bus.FormatTypePlain,
+		nil, // metadata
+	)
+
+	if err := h.messageBus.PublishOutbound(*outboundMsg); err != nil {
+		h.logger.ErrorCtx(ctx, "Failed to publish command reply", err,
+			logger.Field{Key: "session_id", Value: msg.SessionID})
+		return fmt.Errorf("failed to publish command reply: %w", err)
+	}
+	return nil
+}
@@ -3,8 +3,11 @@ package commands
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/aatumaykin/nexbot/internal/artifacts"
 	"github.com/aatumaykin/nexbot/internal/bus"
 	"github.com/aatumaykin/nexbot/internal/constants"
 )
@@ -161,3 +164,30 @@ func TestHandleCommand(t *testing.T) {
 		})
 	}
 }
+
+// TestHandleNewSession_DeletesArtifacts tests that clearing a session removes its artifacts
+func TestHandleNewSession_DeletesArtifacts(t *testing.T) {
+	workspace := t.TempDir()
+	store := artifacts.NewStore(workspace)
+
+	src := filepath.Join(t.TempDir(), "report.csv")
+	if err := os.WriteFile(src, []byte("a,b"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	entry, err := store.Register("telegram:1", src, artifacts.Entry{})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	handler := NewHandler(&MockAgentLoop{}, &MockMessageBus{}, createTestLogger(t), nil)
+	handler.SetArtifactStore(store)
+
+	msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", "/new", nil)
+	if err := handler.HandleCommand(context.Background(), constants.CommandNewSession, *msg); err != nil {
+		t.Fatalf("HandleCommand() error = %v", err)
+	}
+
+	if _, err := os.Stat(entry.Path); !os.IsNotExist(err) {
+		t.Errorf("Expected artifact to be deleted, stat error: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/constants"
+)
+
+// TestHandleContext tests switching, listing and deleting contexts of a chat
+func TestHandleContext(t *testing.T) {
+	mgr, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	messageBus := &MockMessageBus{}
+	handler := NewHandler(&MockAgentLoop{}, messageBus, createTestLogger(t), nil)
+	handler.SetContextStore(mgr)
+	ctx := context.Background()
+
+	run := func(content string) (string, error) {
+		msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", content, nil)
+		err := handler.HandleCommand(ctx, constants.CommandContext, *msg)
+		outbound := messageBus.GetOutboundMessages()
+		return outbound[len(outbound)-1].Content, err
+	}
+
+	reply, err := run("/context Work")
+	if err != nil || reply != fmt.Sprintf(constants.MsgContextSwitched, "work", 0) {
+		t.Fatalf("switch reply = %q, %v", reply, err)
+	}
+	if got := mgr.Resolve("telegram:1"); got != "telegram:1@work" {
+		t.Errorf("Expected the chat to continue in its work context, got %s", got)
+	}
+
+	reply, _ = run("/context")
+	if !strings.Contains(reply, "• default") || !strings.Contains(reply, "▶ work") {
+		t.Errorf("Unexpected context list: %q", reply)
+	}
+
+	if reply, err = run("/context delete work"); err == nil || reply != fmt.Sprintf(constants.MsgContextInUse, "work") {
+		t.Errorf("delete current reply = %q, %v", reply, err)
+	}
+
+	if _, err = run("/context default"); err != nil {
+		t.Fatalf("switch to default error = %v", err)
+	}
+	if got := mgr.Resolve("telegram:1"); got != "telegram:1" {
+		t.Errorf("Expected the chat to return to its own history, got %s", got)
+	}
+
+	if reply, err = run("/context delete work"); err != nil || reply != fmt.Sprintf(constants.MsgContextDeleted, "work") {
+		t.Errorf("delete reply = %q, %v", reply, err)
+	}
+	if reply, err = run("/context delete work"); err == nil || reply != fmt.Sprintf(constants.MsgContextNotFound, "work") {
+		t.Errorf("delete unknown reply = %q, %v", reply, err)
+	}
+	if reply, _ = run("/context delete"); reply != constants.MsgContextUsage {
+		t.Errorf("delete without name reply = %q", reply)
+	}
+}
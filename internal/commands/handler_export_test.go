@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/agent/transcript"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/constants"
+)
+
+// TestHandleExport tests the handleExport function
+func TestHandleExport(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		exportPath string
+		exportErr  error
+		wantErr    bool
+		wantFormat transcript.Format
+		wantType   bus.MessageType
+	}{
+		{
+			name:       "default markdown export",
+			content:    "/export",
+			exportPath: "/tmp/exports/telegram_1.md",
+			wantFormat: transcript.FormatMarkdown,
+			wantType:   bus.MessageTypeDocument,
+		},
+		{
+			name:       "html export",
+			content:    "/export html",
+			exportPath: "/tmp/exports/telegram_1.html",
+			wantFormat: transcript.FormatHTML,
+			wantType:   bus.MessageTypeDocument,
+		},
+		{
+			name:     "invalid format",
+			content:  "/export pdf",
+			wantErr:  true,
+			wantType: bus.MessageTypeText,
+		},
+		{
+			name:       "export failure",
+			content:    "/export",
+			exportErr:  errors.New("session not found"),
+			wantErr:    true,
+			wantFormat: transcript.FormatMarkdown,
+			wantType:   bus.MessageTypeText,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agentLoop := &MockAgentLoop{}
+			messageBus := &MockMessageBus{}
+			agentLoop.SetExportResult(tt.exportPath, tt.exportErr)
+
+			handler := NewHandler(agentLoop, messageBus, createTestLogger(t), nil)
+			msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", tt.content, nil)
+
+			err := handler.HandleCommand(context.Background(), constants.CommandExport, *msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HandleCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if agentLoop.GetExportFormat() != tt.wantFormat {
+				t.Errorf("ExportSession() format = %q, want %q", agentLoop.GetExportFormat(), tt.wantFormat)
+			}
+
+			messages := messageBus.GetOutboundMessages()
+			if len(messages) != 1 {
+				t.Fatalf("Expected 1 outbound message, got %d", len(messages))
+			}
+			if messages[0].Type != tt.wantType {
+				t.Errorf("Outbound message type = %q, want %q", messages[0].Type, tt.wantType)
+			}
+			if tt.wantType == bus.MessageTypeDocument && messages[0].Media.LocalPath != tt.exportPath {
+				t.Errorf("Document path = %q, want %q", messages[0].Media.LocalPath, tt.exportPath)
+			}
+		})
+	}
+}
+
+// mockExporter is a mock implementation of Exporter for testing
+type mockExporter struct {
+	location  string
+	err       error
+	sessionID string
+	target    string
+}
+
+func (m *mockExporter) HasTarget(name string) bool {
+	return name == "notion"
+}
+
+func (m *mockExporter) Export(ctx context.Context, sessionID, target string) (string, error) {
+	m.sessionID = sessionID
+	m.target = target
+	return m.location, m.err
+}
+
+// TestHandleExport_Target tests exporting to a configured notes target
+func TestHandleExport_Target(t *testing.T) {
+	agentLoop := &MockAgentLoop{}
+	messageBus := &MockMessageBus{}
+	exporter := &mockExporter{location: "https://notion.so/page-1"}
+
+	handler := NewHandler(agentLoop, messageBus, createTestLogger(t), nil)
+	handler.SetExporter(exporter)
+	msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", "/export Notion", nil)
+
+	if err := handler.HandleCommand(context.Background(), constants.CommandExport, *msg); err != nil {
+		t.Fatalf("HandleCommand() error = %v", err)
+	}
+	if exporter.sessionID != "telegram:1" || exporter.target != "notion" {
+		t.Errorf("Export() called with %q, %q", exporter.sessionID, exporter.target)
+	}
+	if agentLoop.GetExportFormat() != "" {
+		t.Errorf("ExportSession() called for a notes target")
+	}
+
+	messages := messageBus.GetOutboundMessages()
+	if len(messages) != 1 || messages[0].Content != "✅ Session exported to notion: https://notion.so/page-1" {
+		t.Fatalf("Outbound messages = %+v", messages)
+	}
+
+	// Unconfigured targets are still treated as formats
+	if err := handler.HandleCommand(context.Background(), constants.CommandExport,
+		*bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", "/export obsidian", nil)); err == nil {
+		t.Error("HandleCommand(unconfigured target) error = nil")
+	}
+
+	exporter.err = errors.New("unauthorized")
+	if err := handler.HandleCommand(context.Background(), constants.CommandExport, *msg); err == nil {
+		t.Error("HandleCommand(export failure) error = nil")
+	}
+	if last := messageBus.GetOutboundMessages(); last[len(last)-1].Content != constants.MsgExportError {
+		t.Errorf("last message = %q, want export error", last[len(last)-1].Content)
+	}
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/feedback"
+)
+
+// TestHandleFeedback_Command tests recording feedback from the /feedback command
+func TestHandleFeedback_Command(t *testing.T) {
+	agentLoop := &MockAgentLoop{}
+	agentLoop.SetSessionStatus(map[string]any{
+		"model":      "glm-4.7",
+		"last_tools": []string{"shell"},
+	}, nil)
+	messageBus := &MockMessageBus{}
+	store := feedback.NewStore(t.TempDir())
+
+	handler := NewHandler(agentLoop, messageBus, createTestLogger(t), nil)
+	handler.SetFeedbackStore(store, "concise")
+
+	msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", "/feedback - too verbose", nil)
+	if err := handler.HandleCommand(context.Background(), constants.CommandFeedback, *msg); err != nil {
+		t.Fatalf("HandleCommand() error = %v", err)
+	}
+
+	entries, err := store.Load()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected 1 feedback entry, got %d (err: %v)", len(entries), err)
+	}
+	entry := entries[0]
+	if entry.Score != -1 || entry.Comment != "too verbose" || entry.Source != feedback.SourceCommand {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+	if entry.Model != "glm-4.7" || entry.Profile != "concise" || len(entry.Tools) != 1 {
+		t.Errorf("Expected model, profile and tools to be recorded: %+v", entry)
+	}
+
+	outbound := messageBus.GetOutboundMessages()
+	if len(outbound) != 1 || outbound[0].Content != constants.MsgFeedbackThanks {
+		t.Errorf("Expected thanks message, got %+v", outbound)
+	}
+}
+
+// TestHandleFeedback_Reaction tests silent recording of reactions
+func TestHandleFeedback_Reaction(t *testing.T) {
+	messageBus := &MockMessageBus{}
+	store := feedback.NewStore(t.TempDir())
+
+	handler := NewHandler(&MockAgentLoop{}, messageBus, createTestLogger(t), nil)
+	handler.SetFeedbackStore(store, "")
+
+	for _, reaction := range []string{"👍", "🤔"} {
+		msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", reaction,
+			map[string]any{"reaction": reaction, "message_id": 42})
+		if err := handler.HandleCommand(context.Background(), constants.CommandFeedback, *msg); err != nil {
+			t.Fatalf("HandleCommand() error = %v", err)
+		}
+	}
+
+	entries, _ := store.Load()
+	if len(entries) != 1 {
+		t.Fatalf("Expected only the known reaction to be recorded, got %d", len(entries))
+	}
+	if entries[0].Score != 1 || entries[0].MessageID != 42 || entries[0].Profile != feedback.DefaultProfile {
+		t.Errorf("Unexpected entry: %+v", entries[0])
+	}
+	if messageBus.WasPublishCalled() {
+		t.Error("Reactions should not be answered")
+	}
+}
+
+// staticVariants serves every session with the same prompt variant
+type staticVariants string
+
+func (v staticVariants) SessionVariant(sessionID string) string {
+	return string(v)
+}
+
+// TestHandleFeedback_Variant tests labeling feedback with the experiment variant
+func TestHandleFeedback_Variant(t *testing.T) {
+	store := feedback.NewStore(t.TempDir())
+
+	handler := NewHandler(&MockAgentLoop{}, &MockMessageBus{}, createTestLogger(t), nil)
+	handler.SetFeedbackStore(store, "concise")
+	handler.SetVariantSource(staticVariants("tone/friendly"))
+
+	msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", "/feedback +", nil)
+	if err := handler.HandleCommand(context.Background(), constants.CommandFeedback, *msg); err != nil {
+		t.Fatalf("HandleCommand() error = %v", err)
+	}
+
+	entries, _ := store.Load()
+	if len(entries) != 1 || entries[0].Profile != "tone/friendly" {
+		t.Errorf("Expected the variant as profile, got %+v", entries)
+	}
+}
+
+// TestHandleFeedback_UsageAndDisabled tests replies for invalid input and disabled collection
+func TestHandleFeedback_UsageAndDisabled(t *testing.T) {
+	messageBus := &MockMessageBus{}
+	handler := NewHandler(&MockAgentLoop{}, messageBus, createTestLogger(t), nil)
+
+	msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", "/feedback +", nil)
+	_ = handler.HandleCommand(context.Background(), constants.CommandFeedback, *msg)
+
+	handler.SetFeedbackStore(feedback.NewStore(t.TempDir()), "")
+	msg = bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", "/feedback", nil)
+	_ = handler.HandleCommand(context.Background(), constants.CommandFeedback, *msg)
+
+	outbound := messageBus.GetOutboundMessages()
+	if len(outbound) != 2 {
+		t.Fatalf("Expected 2 replies, got %d", len(outbound))
+	}
+	if outbound[0].Content != constants.MsgFeedbackDisabled || outbound[1].Content != constants.MsgFeedbackUsage {
+		t.Errorf("Unexpected replies: %q, %q", outbound[0].Content, outbound[1].Content)
+	}
+}
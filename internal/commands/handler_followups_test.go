@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/constants"
+)
+
+// mockFollowups keeps the opt-in of chats in memory
+type mockFollowups struct {
+	optedIn map[string]bool
+	pending []agent.Job
+}
+
+func (m *mockFollowups) OptedIn(sessionID string) bool {
+	return m.optedIn[sessionID]
+}
+
+func (m *mockFollowups) SetOptIn(sessionID string, allowed bool) (int, error) {
+	m.optedIn[sessionID] = allowed
+	if allowed {
+		return 0, nil
+	}
+	cancelled := len(m.pending)
+	m.pending = nil
+	return cancelled, nil
+}
+
+func (m *mockFollowups) Pending(string) []agent.Job {
+	return m.pending
+}
+
+// TestHandleFollowups tests allowing, showing and forbidding follow-ups
+func TestHandleFollowups(t *testing.T) {
+	messageBus := &MockMessageBus{}
+	handler := NewHandler(&MockAgentLoop{}, messageBus, createTestLogger(t), nil)
+	settings := &mockFollowups{optedIn: make(map[string]bool)}
+	handler.SetFollowups(settings)
+	ctx := context.Background()
+
+	send := func(content string) string {
+		t.Helper()
+		msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", content, nil)
+		if err := handler.HandleCommand(ctx, constants.CommandFollowups, *msg); err != nil {
+			t.Fatalf("HandleCommand(%q) error = %v", content, err)
+		}
+		outbound := messageBus.GetOutboundMessages()
+		return outbound[len(outbound)-1].Content
+	}
+
+	if got := send("/followups"); got != fmt.Sprintf(constants.MsgFollowupsStatus, "off", 0) {
+		t.Errorf("status reply = %q", got)
+	}
+	if got := send("/followups ON"); got != constants.MsgFollowupsOn || !settings.optedIn["telegram:1"] {
+		t.Errorf("on reply = %q", got)
+	}
+
+	at := time.Now().Add(time.Hour)
+	settings.pending = []agent.Job{{ID: "job-1", ExecuteAt: &at}}
+	if got := send("/followups"); got != fmt.Sprintf(constants.MsgFollowupsStatus, "on", 1) {
+		t.Errorf("status reply = %q", got)
+	}
+	if got := send("/followups off"); got != fmt.Sprintf(constants.MsgFollowupsOff, 1) || settings.optedIn["telegram:1"] {
+		t.Errorf("off reply = %q", got)
+	}
+}
+
+// TestHandleFollowups_Disabled tests the reply when follow-ups are disabled
+func TestHandleFollowups_Disabled(t *testing.T) {
+	messageBus := &MockMessageBus{}
+	handler := NewHandler(&MockAgentLoop{}, messageBus, createTestLogger(t), nil)
+
+	msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", "/followups on", nil)
+	if err := handler.HandleCommand(context.Background(), constants.CommandFollowups, *msg); err != nil {
+		t.Fatalf("HandleCommand() error = %v", err)
+	}
+	outbound := messageBus.GetOutboundMessages()
+	if len(outbound) != 1 || outbound[0].Content != constants.MsgFollowupsDisabled {
+		t.Errorf("outbound = %+v", outbound)
+	}
+}
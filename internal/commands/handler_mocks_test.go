@@ -5,6 +5,8 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/agent/transcript"
 	"github.com/aatumaykin/nexbot/internal/bus"
 	"github.com/aatumaykin/nexbot/internal/logger"
 )
@@ -20,6 +22,16 @@ type MockAgentLoop struct {
 	clearSessionID     string
 	getStatusCalled    bool
 	getStatusSessionID string
+
+	exportPath   string
+	exportErr    error
+	exportFormat transcript.Format
+
+	sessions    []session.Info
+	sessionsErr error
+
+	namedSessions map[string]bool // Existing named sessions
+	bindings      map[string]string
 }
 
 func (m *MockAgentLoop) ClearSession(ctx context.Context, sessionID string) error {
@@ -38,6 +50,82 @@ func (m *MockAgentLoop) GetSessionStatus(ctx context.Context, sessionID string)
 	return m.getSessionStatus, m.getStatusErr
 }
 
+func (m *MockAgentLoop) ExportSession(ctx context.Context, sessionID string, format transcript.Format) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exportFormat = format
+	return m.exportPath, m.exportErr
+}
+
+func (m *MockAgentLoop) ListSessions(ctx context.Context) ([]session.Info, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sessions, m.sessionsErr
+}
+
+func (m *MockAgentLoop) SaveSessionAs(ctx context.Context, sessionID, name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name, err := session.NormalizeName(name)
+	if err != nil {
+		return "", err
+	}
+	if m.namedSessions[name] && m.bindings[sessionID] != name {
+		return "", session.ErrNameTaken
+	}
+	m.setNamedLocked(sessionID, name)
+	return session.NamedSessionID(name), nil
+}
+
+func (m *MockAgentLoop) ResumeSession(ctx context.Context, sessionID, name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.namedSessions[name] {
+		return "", session.ErrNamedSessionNotFound
+	}
+	m.setNamedLocked(sessionID, name)
+	return session.NamedSessionID(name), nil
+}
+
+func (m *MockAgentLoop) setNamedLocked(sessionID, name string) {
+	if m.namedSessions == nil {
+		m.namedSessions = map[string]bool{}
+		m.bindings = map[string]string{}
+	}
+	m.namedSessions[name] = true
+	m.bindings[sessionID] = name
+}
+
+// GetBinding returns the named session a chat is bound to
+func (m *MockAgentLoop) GetBinding(sessionID string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.bindings[sessionID]
+}
+
+// SetSessions sets the sessions and error to return from ListSessions
+func (m *MockAgentLoop) SetSessions(sessions []session.Info, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions = sessions
+	m.sessionsErr = err
+}
+
+// SetExportResult sets the path and error to return from ExportSession
+func (m *MockAgentLoop) SetExportResult(path string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exportPath = path
+	m.exportErr = err
+}
+
+// GetExportFormat returns the format passed to ExportSession
+func (m *MockAgentLoop) GetExportFormat() transcript.Format {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.exportFormat
+}
+
 // Reset resets the mock state
 func (m *MockAgentLoop) Reset() {
 	m.mu.Lock()
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/constants"
+)
+
+// TestHandleSaveAsAndResume tests naming a session and resuming it from another chat
+func TestHandleSaveAsAndResume(t *testing.T) {
+	agentLoop := &MockAgentLoop{}
+	messageBus := &MockMessageBus{}
+	handler := NewHandler(agentLoop, messageBus, createTestLogger(t), nil)
+	ctx := context.Background()
+
+	saveMsg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", "/save_as project-x", nil)
+	if err := handler.HandleCommand(ctx, constants.CommandSaveAs, *saveMsg); err != nil {
+		t.Fatalf("save_as error = %v", err)
+	}
+
+	resumeMsg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:2", "/resume project-x", nil)
+	if err := handler.HandleCommand(ctx, constants.CommandResume, *resumeMsg); err != nil {
+		t.Fatalf("resume error = %v", err)
+	}
+	if agentLoop.GetBinding("telegram:2") != "project-x" {
+		t.Error("Expected second chat to be bound to project-x")
+	}
+
+	outbound := messageBus.GetOutboundMessages()
+	if len(outbound) != 2 {
+		t.Fatalf("Expected 2 replies, got %d", len(outbound))
+	}
+	if outbound[0].Content != fmt.Sprintf(constants.MsgSessionSaved, "project-x", "project-x") {
+		t.Errorf("Unexpected save reply: %q", outbound[0].Content)
+	}
+	if outbound[1].Content != fmt.Sprintf(constants.MsgSessionResumed, "project-x") || outbound[1].SessionID != "telegram:2" {
+		t.Errorf("Unexpected resume reply: %+v", outbound[1])
+	}
+}
+
+// TestHandleSaveAsAndResume_Errors tests usage and error replies
+func TestHandleSaveAsAndResume_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{"save without name", constants.CommandSaveAs, "/save_as", constants.MsgSaveAsUsage, false},
+		{"resume without name", constants.CommandResume, "/resume", constants.MsgResumeUsage, false},
+		{"resume unknown", constants.CommandResume, "/resume nope", fmt.Sprintf(constants.MsgNamedSessionNotFound, "nope"), true},
+		{"invalid name", constants.CommandSaveAs, "/save_as Bad!Name", "session name must be 1-64 characters: lowercase letters, digits, '-' or '_'", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messageBus := &MockMessageBus{}
+			handler := NewHandler(&MockAgentLoop{}, messageBus, createTestLogger(t), nil)
+			msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", tt.content, nil)
+
+			err := handler.HandleCommand(context.Background(), tt.command, *msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HandleCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			outbound := messageBus.GetOutboundMessages()
+			if len(outbound) != 1 || outbound[0].Content != tt.want {
+				t.Errorf("Expected reply %q, got %+v", tt.want, outbound)
+			}
+		})
+	}
+}
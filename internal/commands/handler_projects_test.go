@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/projects"
+)
+
+// TestHandleProject tests attaching a chat to a project, showing and leaving it
+func TestHandleProject(t *testing.T) {
+	messageBus := &MockMessageBus{}
+	handler := NewHandler(&MockAgentLoop{}, messageBus, createTestLogger(t), nil)
+	store := projects.NewStore(t.TempDir())
+	handler.SetProjectStore(store)
+	ctx := context.Background()
+
+	send := func(sessionID, content string) string {
+		t.Helper()
+		msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", sessionID, content, nil)
+		if err := handler.HandleCommand(ctx, constants.CommandProject, *msg); err != nil {
+			t.Fatalf("HandleCommand(%q) error = %v", content, err)
+		}
+		outbound := messageBus.GetOutboundMessages()
+		return outbound[len(outbound)-1].Content
+	}
+
+	if got := send("telegram:1", "/project homelab"); got != fmt.Sprintf(constants.MsgProjectCreated, "homelab") {
+		t.Errorf("create reply = %q", got)
+	}
+	if got := send("telegram:2", "/project homelab"); got != fmt.Sprintf(constants.MsgProjectAttached, "homelab") {
+		t.Errorf("attach reply = %q", got)
+	}
+	if p, ok := store.ForSession("telegram:2"); !ok || p.Name != "homelab" {
+		t.Errorf("ForSession() = %+v, %v", p, ok)
+	}
+
+	_ = store.SetBrief("homelab", "- nginx on 10.0.0.2")
+	if got := send("telegram:1", "/project"); !strings.Contains(got, "Project homelab · 2 chats") || !strings.Contains(got, "- nginx on 10.0.0.2") {
+		t.Errorf("show reply = %q", got)
+	}
+
+	if got := send("telegram:1", "/project leave"); got != fmt.Sprintf(constants.MsgProjectLeft, "homelab") {
+		t.Errorf("leave reply = %q", got)
+	}
+	if got := send("telegram:1", "/project"); !strings.HasPrefix(got, constants.MsgProjectNone) || !strings.Contains(got, "Other projects: homelab") {
+		t.Errorf("show without project reply = %q", got)
+	}
+	if got := send("telegram:1", "/project Bad!Name"); got != projects.ErrInvalidName.Error() {
+		t.Errorf("invalid name reply = %q", got)
+	}
+}
+
+// TestHandleProject_Disabled tests the reply when projects are disabled
+func TestHandleProject_Disabled(t *testing.T) {
+	messageBus := &MockMessageBus{}
+	handler := NewHandler(&MockAgentLoop{}, messageBus, createTestLogger(t), nil)
+	msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", "/project homelab", nil)
+
+	if err := handler.HandleCommand(context.Background(), constants.CommandProject, *msg); err != nil {
+		t.Fatalf("HandleCommand() error = %v", err)
+	}
+	outbound := messageBus.GetOutboundMessages()
+	if len(outbound) != 1 || outbound[0].Content != constants.MsgProjectsDisabled {
+		t.Errorf("Expected disabled reply, got %+v", outbound)
+	}
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/snapshot"
+)
+
+// mockRollback returns prepared results of rollbacks
+type mockRollback struct {
+	results []snapshot.Result
+}
+
+func (m *mockRollback) Rollback(string) (snapshot.Result, error) {
+	if len(m.results) == 0 {
+		return snapshot.Result{}, snapshot.ErrNothingToRollback
+	}
+	result := m.results[0]
+	m.results = m.results[1:]
+	return result, nil
+}
+
+// TestHandleRollback tests rolling back turns until nothing is left
+func TestHandleRollback(t *testing.T) {
+	messageBus := &MockMessageBus{}
+	handler := NewHandler(&MockAgentLoop{}, messageBus, createTestLogger(t), nil)
+	at := time.Date(2026, 10, 18, 12, 30, 0, 0, time.UTC)
+	handler.SetRollbackStore(&mockRollback{results: []snapshot.Result{
+		{Time: at, Restored: 2, Removed: 1, Skipped: []string{"/ws/big.bin"}},
+	}})
+	ctx := context.Background()
+
+	send := func() string {
+		t.Helper()
+		msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", "/rollback", nil)
+		if err := handler.HandleCommand(ctx, constants.CommandRollback, *msg); err != nil {
+			t.Fatalf("HandleCommand() error = %v", err)
+		}
+		outbound := messageBus.GetOutboundMessages()
+		return outbound[len(outbound)-1].Content
+	}
+
+	want := fmt.Sprintf(constants.MsgRollbackDone, "2026-10-18 12:30", 2, 1, 0) +
+		fmt.Sprintf(constants.MsgRollbackSkipped, "/ws/big.bin")
+	if got := send(); got != want {
+		t.Errorf("rollback reply = %q, want %q", got, want)
+	}
+	if got := send(); got != constants.MsgRollbackNothing {
+		t.Errorf("second rollback reply = %q", got)
+	}
+}
+
+// TestHandleRollback_Disabled tests the reply when rollback is disabled
+func TestHandleRollback_Disabled(t *testing.T) {
+	messageBus := &MockMessageBus{}
+	handler := NewHandler(&MockAgentLoop{}, messageBus, createTestLogger(t), nil)
+
+	msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", "/rollback", nil)
+	if err := handler.HandleCommand(context.Background(), constants.CommandRollback, *msg); err != nil {
+		t.Fatalf("HandleCommand() error = %v", err)
+	}
+	outbound := messageBus.GetOutboundMessages()
+	if len(outbound) != 1 || outbound[0].Content != constants.MsgRollbackDisabled {
+		t.Errorf("outbound = %+v", outbound)
+	}
+}
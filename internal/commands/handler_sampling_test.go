@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/constants"
+)
+
+// TestHandleSampling tests setting, showing and clearing sampling overrides of a chat
+func TestHandleSampling(t *testing.T) {
+	mgr, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	messageBus := &MockMessageBus{}
+	handler := NewHandler(&MockAgentLoop{}, messageBus, createTestLogger(t), nil)
+	handler.SetSamplingStore(mgr)
+	ctx := context.Background()
+
+	run := func(content string) (string, error) {
+		msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", content, nil)
+		err := handler.HandleCommand(ctx, constants.CommandSampling, *msg)
+		outbound := messageBus.GetOutboundMessages()
+		return outbound[len(outbound)-1].Content, err
+	}
+
+	reply, _ := run("/sampling")
+	if !strings.HasPrefix(reply, constants.MsgSamplingNone) {
+		t.Errorf("Unexpected reply without overrides: %q", reply)
+	}
+
+	if reply, err = run("/sampling temperature=0.2"); err != nil || reply != fmt.Sprintf(constants.MsgSamplingSet, "temperature=0.2") {
+		t.Fatalf("set reply = %q, %v", reply, err)
+	}
+	// New parameters are merged into the current overrides
+	if reply, err = run("/sampling top_p=0.9"); err != nil || reply != fmt.Sprintf(constants.MsgSamplingSet, "temperature=0.2 top_p=0.9") {
+		t.Fatalf("merge reply = %q, %v", reply, err)
+	}
+	if s, _ := mgr.Sampling("telegram:1"); s.Temperature != 0.2 || s.TopP != 0.9 {
+		t.Errorf("Expected the overrides to be stored, got %+v", s)
+	}
+
+	if reply, _ = run("/sampling top_p=2"); !strings.HasPrefix(reply, "❌ Invalid sampling parameters") {
+		t.Errorf("invalid reply = %q", reply)
+	}
+
+	if reply, err = run("/sampling reset"); err != nil || reply != constants.MsgSamplingReset {
+		t.Errorf("reset reply = %q, %v", reply, err)
+	}
+	if s, _ := mgr.Sampling("telegram:1"); !s.IsZero() {
+		t.Errorf("Expected the overrides to be cleared, got %+v", s)
+	}
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/constants"
+)
+
+// TestHandleSessions tests the handleSessions function
+func TestHandleSessions(t *testing.T) {
+	agentLoop := &MockAgentLoop{}
+	agentLoop.SetSessions([]session.Info{
+		{ID: "telegram:1", Title: "Deploy pipeline", MessageCount: 8, LastActivity: time.Now()},
+	}, nil)
+	messageBus := &MockMessageBus{}
+
+	handler := NewHandler(agentLoop, messageBus, createTestLogger(t), nil)
+	msg := bus.NewInboundMessage(bus.ChannelTypeTelegram, "user-1", "telegram:1", "/sessions", nil)
+
+	if err := handler.HandleCommand(context.Background(), constants.CommandSessions, *msg); err != nil {
+		t.Fatalf("HandleCommand() error = %v", err)
+	}
+
+	outbound := messageBus.GetOutboundMessages()
+	if len(outbound) != 1 || !strings.Contains(outbound[0].Content, "▶ Deploy pipeline") {
+		t.Errorf("Expected session list with current session marked, got %+v", outbound)
+	}
+
+	agentLoop.SetSessions(nil, errors.New("disk error"))
+	if err := handler.HandleCommand(context.Background(), constants.CommandSessions, *msg); err == nil {
+		t.Error("Expected error when sessions cannot be listed")
+	}
+	outbound = messageBus.GetOutboundMessages()
+	if outbound[len(outbound)-1].Content != constants.MsgSessionsError {
+		t.Errorf("Expected error message, got %q", outbound[len(outbound)-1].Content)
+	}
+}
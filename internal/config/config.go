@@ -1,11 +1,20 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"github.com/BurntSushi/toml"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/andybalholm/cascadia"
+	"net"
+	"net/netip"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
+	"time"
 )
 
 const (
@@ -13,12 +22,29 @@ const (
 	DefaultLLMAPITimeoutSeconds = 30
 )
 
-// Load загружает конфигурацию из TOML файла
+// Load загружает конфигурацию из TOML файла с подключёнными файлами и
+// профилем из переменной окружения NEXBOT_PROFILE
 func Load(path string) (*Config, error) {
+	return LoadProfile(path, os.Getenv(ProfileEnv))
+}
+
+// LoadProfile загружает конфигурацию из TOML файла с подключёнными файлами
+// (include) и накладывает профиль [profiles.<profile>]. Порядок приоритета:
+// подключённые файлы, затем сам файл, затем профиль. Пустой profile не
+// накладывает профиль.
+func LoadProfile(path, profile string) (*Config, error) {
 	path = expandHome(path)
-	data, err := os.ReadFile(path)
+	tree, err := readTree(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyProfile(tree, profile); err != nil {
+		return nil, err
+	}
+
+	data, err := encodeTree(tree)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, err
 	}
 
 	var cfg Config
@@ -26,6 +52,10 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	// Таблицы инструментов сохраняются как есть для проверки по схемам инструментов
+	cfg.tools, _ = tree["tools"].(map[string]any)
+	cfg.profile = profile
+
 	applyDefaults(&cfg)
 
 	if err := expandEnvVars(&cfg); err != nil {
@@ -68,6 +98,21 @@ func (c *Config) Validate() []error {
 		}
 	}
 
+	// Проверка очереди запросов к LLM
+	if c.LLM.ZAI.MaxConcurrent < 0 {
+		errors = append(errors, fmt.Errorf("llm.zai.max_concurrent must be positive (got: %d)", c.LLM.ZAI.MaxConcurrent))
+	}
+	if c.LLM.ZAI.RequestsPerMinute < 0 {
+		errors = append(errors, fmt.Errorf("llm.zai.requests_per_minute must be positive (got: %d)", c.LLM.ZAI.RequestsPerMinute))
+	}
+
+	// Проверка бюджетов инструментов
+	for key, limit := range c.Agent.ToolBudgets {
+		if limit <= 0 {
+			errors = append(errors, fmt.Errorf("agent.tool_budgets.%s must be positive (got: %d)", key, limit))
+		}
+	}
+
 	// Проверка Telegram канала
 	if c.Channels.Telegram.Enabled {
 		if c.Channels.Telegram.Token == "" {
@@ -93,6 +138,19 @@ func (c *Config) Validate() []error {
 		if c.Channels.Telegram.AnswerCallbackTimeout < 0 {
 			errors = append(errors, fmt.Errorf("channels.telegram.answer_callback_timeout must be positive (got: %d)", c.Channels.Telegram.AnswerCallbackTimeout))
 		}
+
+		// Проверка stream_interval_ms
+		if c.Channels.Telegram.StreamIntervalMS < 0 {
+			errors = append(errors, fmt.Errorf("channels.telegram.stream_interval_ms must be positive (got: %d)", c.Channels.Telegram.StreamIntervalMS))
+		}
+
+		// Проверка параметров переподключения
+		if c.Channels.Telegram.ReconnectMaxBackoffSeconds < 0 {
+			errors = append(errors, fmt.Errorf("channels.telegram.reconnect_max_backoff_seconds must be positive (got: %d)", c.Channels.Telegram.ReconnectMaxBackoffSeconds))
+		}
+		if c.Channels.Telegram.NotifyReconnectAfterSeconds < 0 {
+			errors = append(errors, fmt.Errorf("channels.telegram.notify_reconnect_after_seconds must be positive (got: %d)", c.Channels.Telegram.NotifyReconnectAfterSeconds))
+		}
 	}
 
 	// Проверка logging config
@@ -136,10 +194,48 @@ func (c *Config) Validate() []error {
 				errors = append(errors, fmt.Errorf("tools.shell.ask_commands contains empty command"))
 			}
 		}
+		switch c.Tools.Shell.Shell {
+		case "", "direct", "cmd", "powershell", "pwsh":
+		default:
+			errors = append(errors, fmt.Errorf("invalid tools.shell.shell: %s (expected: direct, cmd, powershell, pwsh)", c.Tools.Shell.Shell))
+		}
 		// Если все три списка пустые — это допустимо (все команды разрешены)
 		// Если хотя бы один список не пустой — это допустимо (разрешено смешанное управление)
 	}
 
+	// Проверка file tool
+	if c.Tools.File.DiffSummaryMaxLines < 0 {
+		errors = append(errors, fmt.Errorf("tools.file.diff_summary_max_lines must be positive (got: %d)", c.Tools.File.DiffSummaryMaxLines))
+	}
+
+	// Проверка process tool
+	if c.Tools.Process.MaxJobs < 0 {
+		errors = append(errors, fmt.Errorf("tools.process.max_jobs must be positive (got: %d)", c.Tools.Process.MaxJobs))
+	}
+	if c.Tools.Process.OutputLines < 0 {
+		errors = append(errors, fmt.Errorf("tools.process.output_lines must be positive (got: %d)", c.Tools.Process.OutputLines))
+	}
+
+	// Проверка fetch tool
+	if c.Tools.Fetch.MinIntervalMs < 0 {
+		errors = append(errors, fmt.Errorf("tools.fetch.min_interval_ms must be positive (got: %d)", c.Tools.Fetch.MinIntervalMs))
+	}
+	if c.Tools.Fetch.CacheDir != "" && (filepath.IsAbs(c.Tools.Fetch.CacheDir) || strings.HasPrefix(filepath.Clean(c.Tools.Fetch.CacheDir), "..")) {
+		errors = append(errors, fmt.Errorf("tools.fetch.cache_dir must be relative to the workspace (got: %s)", c.Tools.Fetch.CacheDir))
+	}
+
+	// Проверка plot tool
+	if c.Tools.Plot.Enabled && (filepath.IsAbs(c.Tools.Plot.Dir) || strings.HasPrefix(filepath.Clean(c.Tools.Plot.Dir), "..")) {
+		errors = append(errors, fmt.Errorf("tools.plot.dir must be relative to the workspace (got: %s)", c.Tools.Plot.Dir))
+	}
+
+	// Проверка пространств имён инструментов
+	for namespace := range c.Tools.Namespaces {
+		if !toolNamespacePattern.MatchString(namespace) {
+			errors = append(errors, fmt.Errorf("tools.namespaces: invalid namespace %q (expected: lower case letters, digits and underscores)", namespace))
+		}
+	}
+
 	// Проверка workers configuration
 	if c.Workers.PoolSize < 0 {
 		errors = append(errors, fmt.Errorf("workers.pool_size must be positive (got: %d)", c.Workers.PoolSize))
@@ -156,6 +252,366 @@ func (c *Config) Validate() []error {
 		errors = append(errors, fmt.Errorf("subagent.timeout_seconds must be at least 1 when enabled (got: %d)", c.Subagent.TimeoutSeconds))
 	}
 
+	// Проверка cleanup configuration
+	if c.Cleanup.Action != "" && c.Cleanup.Action != "delete" && c.Cleanup.Action != "archive" {
+		errors = append(errors, fmt.Errorf("invalid cleanup.action: %s (expected: delete, archive)", c.Cleanup.Action))
+	}
+	if c.Cleanup.MaxSessions < 0 {
+		errors = append(errors, fmt.Errorf("cleanup.max_sessions must be positive (got: %d)", c.Cleanup.MaxSessions))
+	}
+	if c.Cleanup.MaxMediaSizeMB < 0 {
+		errors = append(errors, fmt.Errorf("cleanup.max_media_size_mb must be positive (got: %d)", c.Cleanup.MaxMediaSizeMB))
+	}
+	for _, dir := range c.Cleanup.MediaDirs {
+		if err := validatePath(dir, "cleanup.media_dirs"); err != nil {
+			errors = append(errors, err)
+		}
+	}
+
+	// Проверка watcher configuration
+	if c.Watcher.DebounceMs < 0 {
+		errors = append(errors, fmt.Errorf("watcher.debounce_ms must be positive (got: %d)", c.Watcher.DebounceMs))
+	}
+	if c.Watcher.MaxLines < 0 {
+		errors = append(errors, fmt.Errorf("watcher.max_lines must be positive (got: %d)", c.Watcher.MaxLines))
+	}
+	for i, w := range c.Watcher.Watches {
+		if w.Path == "" {
+			errors = append(errors, fmt.Errorf("watcher.watches[%d].path is required", i))
+		}
+		if !strings.Contains(w.SessionID, ":") {
+			errors = append(errors, fmt.Errorf("watcher.watches[%d].session_id must have format 'channel:chat_id' (got: %q)", i, w.SessionID))
+		}
+		if w.Match != "" {
+			if _, err := regexp.Compile(w.Match); err != nil {
+				errors = append(errors, fmt.Errorf("watcher.watches[%d].match is not a valid regex: %w", i, err))
+			}
+		}
+	}
+
+	// Проверка monitor configuration
+	if c.Monitor.DefaultIntervalMinutes < 0 {
+		errors = append(errors, fmt.Errorf("monitor.default_interval_minutes must be positive (got: %d)", c.Monitor.DefaultIntervalMinutes))
+	}
+	if c.Monitor.MinIntervalMinutes < 0 {
+		errors = append(errors, fmt.Errorf("monitor.min_interval_minutes must be positive (got: %d)", c.Monitor.MinIntervalMinutes))
+	}
+	if c.Monitor.MaxDiffLines < 0 {
+		errors = append(errors, fmt.Errorf("monitor.max_diff_lines must be positive (got: %d)", c.Monitor.MaxDiffLines))
+	}
+	if c.Monitor.MaxMonitors < 0 {
+		errors = append(errors, fmt.Errorf("monitor.max_monitors must be positive (got: %d)", c.Monitor.MaxMonitors))
+	}
+	for i, m := range c.Monitor.Monitors {
+		if u, err := url.Parse(m.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errors = append(errors, fmt.Errorf("monitor.monitors[%d].url must be an http:// or https:// URL (got: %q)", i, m.URL))
+		}
+		if m.Selector != "" {
+			if _, err := cascadia.Compile(m.Selector); err != nil {
+				errors = append(errors, fmt.Errorf("monitor.monitors[%d].selector is not a valid CSS selector: %w", i, err))
+			}
+		}
+		if m.IntervalMinutes < 0 {
+			errors = append(errors, fmt.Errorf("monitor.monitors[%d].interval_minutes must be positive (got: %d)", i, m.IntervalMinutes))
+		}
+		if !strings.Contains(m.SessionID, ":") {
+			errors = append(errors, fmt.Errorf("monitor.monitors[%d].session_id must have format 'channel:chat_id' (got: %q)", i, m.SessionID))
+		}
+	}
+
+	// Проверка tool_protocol
+	switch c.Agent.ToolProtocol {
+	case "", "auto", "native", "text":
+	default:
+		errors = append(errors, fmt.Errorf("invalid agent.tool_protocol: %s (expected: auto, native, text)", c.Agent.ToolProtocol))
+	}
+
+	// Проверка возможностей известных моделей
+	errors = append(errors, c.validateModels()...)
+	if err := validateSampling("agent", c.Agent.Temperature, c.Agent.Sampling); err != nil {
+		errors = append(errors, err)
+	}
+
+	// Проверка переменных промпта
+	errors = append(errors, c.validatePrompt()...)
+
+	// Проверка routing
+	if c.Agent.Routing.Enabled && c.Agent.Routing.CheapModel == "" {
+		errors = append(errors, fmt.Errorf("agent.routing.cheap_model is required when routing is enabled"))
+	}
+
+	// Проверка debate
+	if c.Agent.Debate.Enabled {
+		errors = append(errors, c.validateDebate()...)
+	}
+
+	// Проверка planning
+	if c.Agent.Planning.Enabled {
+		errors = append(errors, c.validatePlanning()...)
+	}
+
+	// Проверка verify
+	if c.Agent.Verify.Enabled {
+		errors = append(errors, c.validateVerify()...)
+	}
+
+	// Проверка tool_selection
+	if c.Agent.ToolSelection.Enabled && c.Agent.ToolSelection.TopK < 0 {
+		errors = append(errors, fmt.Errorf("agent.tool_selection.top_k must be positive (got: %d)", c.Agent.ToolSelection.TopK))
+	}
+
+	// Проверка tool_output
+	if c.Agent.ToolOutput.MaxTokens < 0 {
+		errors = append(errors, fmt.Errorf("agent.tool_output.max_tokens must be positive (got: %d)", c.Agent.ToolOutput.MaxTokens))
+	}
+	for tool, budget := range c.Agent.ToolOutput.Tools {
+		if budget < 0 {
+			errors = append(errors, fmt.Errorf("agent.tool_output.tools.%s must be positive (got: %d)", tool, budget))
+		}
+	}
+
+	// Проверка projects
+	if c.Agent.Projects.MaxBriefChars < 0 {
+		errors = append(errors, fmt.Errorf("agent.projects.max_brief_chars must be positive (got: %d)", c.Agent.Projects.MaxBriefChars))
+	}
+
+	// Проверка approval
+	if c.Agent.Approval.Enabled {
+		if c.Agent.Approval.TTLMinutes < 0 {
+			errors = append(errors, fmt.Errorf("agent.approval.ttl_minutes must be positive (got: %d)", c.Agent.Approval.TTLMinutes))
+		}
+		validClasses := map[string]bool{"file": true, "shell": true, "web": true, "messaging": true, "scheduling": true, "agent": true}
+		for _, class := range c.Agent.Approval.Classes {
+			if !validClasses[class] {
+				errors = append(errors, fmt.Errorf("invalid agent.approval.classes value: %s (expected: file, shell, web, messaging, scheduling, agent)", class))
+			}
+		}
+	}
+
+	// Проверка rollback
+	if c.Agent.Rollback.MaxTurns < 0 {
+		errors = append(errors, fmt.Errorf("agent.rollback.max_turns must be positive (got: %d)", c.Agent.Rollback.MaxTurns))
+	}
+	if c.Agent.Rollback.MaxFileSizeMB < 0 {
+		errors = append(errors, fmt.Errorf("agent.rollback.max_file_size_mb must be positive (got: %d)", c.Agent.Rollback.MaxFileSizeMB))
+	}
+
+	// Проверка детерминированного режима
+	if c.Agent.Deterministic.Enabled {
+		switch c.Agent.Deterministic.Mode {
+		case "", "record", "replay":
+		default:
+			errors = append(errors, fmt.Errorf("invalid agent.deterministic.mode: %s (expected: record, replay or empty)", c.Agent.Deterministic.Mode))
+		}
+		if c.Agent.Deterministic.Seed < 0 {
+			errors = append(errors, fmt.Errorf("agent.deterministic.seed must be non-negative (got: %d)", c.Agent.Deterministic.Seed))
+		}
+		if err := validatePath(c.Agent.Deterministic.Dir, "agent.deterministic.dir"); err != nil {
+			errors = append(errors, err)
+		}
+	}
+
+	// Проверка capture
+	if c.Agent.Capture.MaxEntries < 0 {
+		errors = append(errors, fmt.Errorf("agent.capture.max_entries must be positive (got: %d)", c.Agent.Capture.MaxEntries))
+	}
+
+	// Проверка followups
+	if c.Cron.Followups.MaxPending < 0 {
+		errors = append(errors, fmt.Errorf("cron.followups.max_pending must be positive (got: %d)", c.Cron.Followups.MaxPending))
+	}
+	if c.Cron.Followups.MaxDelayHours < 0 {
+		errors = append(errors, fmt.Errorf("cron.followups.max_delay_hours must be positive (got: %d)", c.Cron.Followups.MaxDelayHours))
+	}
+
+	// Проверка guardrails
+	if c.Guardrails.Action != "" && c.Guardrails.Action != "warn" && c.Guardrails.Action != "block" {
+		errors = append(errors, fmt.Errorf("invalid guardrails.action: %s (expected: warn, block)", c.Guardrails.Action))
+	}
+	if c.Guardrails.ClassifyMaxChars < 0 {
+		errors = append(errors, fmt.Errorf("guardrails.classify_max_chars must be positive (got: %d)", c.Guardrails.ClassifyMaxChars))
+	}
+
+	// Проверка moderation
+	if c.Moderation.Enabled {
+		errors = append(errors, c.validateModeration()...)
+	}
+
+	// Проверка pii
+	validPIICategories := map[string]bool{"email": true, "phone": true, "credit_card": true}
+	for _, category := range c.PII.Categories {
+		if !validPIICategories[category] {
+			errors = append(errors, fmt.Errorf("invalid pii category: %s (expected: email, phone, credit_card)", category))
+		}
+	}
+
+	// Проверка stt
+	if c.STT.Enabled {
+		errors = append(errors, c.validateSTT()...)
+	}
+
+	// Проверка experiment
+	if c.Experiment.Enabled {
+		errors = append(errors, c.validateExperiment()...)
+	}
+
+	// Проверка structured
+	if c.Structured.Enabled && c.Structured.MaxTokens < 0 {
+		errors = append(errors, fmt.Errorf("structured.max_tokens must be positive (got: %d)", c.Structured.MaxTokens))
+	}
+
+	// Проверка throttle
+	if c.Throttle.Enabled && c.Throttle.MuteThreshold > 0 && c.Throttle.MessagesPerMinute > 0 &&
+		c.Throttle.MuteThreshold <= c.Throttle.MessagesPerMinute {
+		errors = append(errors, fmt.Errorf("throttle.mute_threshold must be greater than throttle.messages_per_minute (got: %d <= %d)",
+			c.Throttle.MuteThreshold, c.Throttle.MessagesPerMinute))
+	}
+
+	// Проверка network
+	errors = append(errors, c.validateNetwork()...)
+
+	// Проверка leader
+	if c.Leader.Enabled {
+		errors = append(errors, c.validateLeader()...)
+	}
+
+	// Проверка dashboard
+	if c.Dashboard.Enabled {
+		errors = append(errors, c.validateDashboard()...)
+	}
+
+	// Проверка templates
+	for name, tmpl := range c.Templates {
+		if strings.TrimSpace(tmpl.Text) == "" {
+			errors = append(errors, fmt.Errorf("templates.%s.text is required", name))
+		}
+		switch tmpl.Format {
+		case "", "markdown", "html", "markdownv2":
+		default:
+			errors = append(errors, fmt.Errorf("invalid templates.%s.format: %s (expected: markdown, html, markdownv2)", name, tmpl.Format))
+		}
+	}
+
+	// Проверка analytics
+	if c.Analytics.RetentionDays < 0 {
+		errors = append(errors, fmt.Errorf("analytics.retention_days must be positive (got: %d)", c.Analytics.RetentionDays))
+	}
+	if c.Analytics.SlowToolP95Ms < 0 {
+		errors = append(errors, fmt.Errorf("analytics.slow_tool_p95_ms cannot be negative (got: %d)", c.Analytics.SlowToolP95Ms))
+	}
+
+	// Проверка alerts
+	if c.Alerts.Enabled {
+		errors = append(errors, c.validateAlerts()...)
+	}
+
+	// Проверка update
+	if c.Update.Enabled {
+		if !strings.Contains(c.Update.Repo, "/") {
+			errors = append(errors, fmt.Errorf("update.repo must have format 'owner/name' (got: %q)", c.Update.Repo))
+		}
+		if c.Update.IntervalHours < 0 {
+			errors = append(errors, fmt.Errorf("update.interval_hours must be positive (got: %d)", c.Update.IntervalHours))
+		}
+		if c.Update.SessionID != "" && !strings.Contains(c.Update.SessionID, ":") {
+			errors = append(errors, fmt.Errorf("update.session_id must have format 'channel:chat_id' (got: %q)", c.Update.SessionID))
+		}
+	}
+
+	// Проверка jobs
+	if c.Jobs.Enabled {
+		if c.Jobs.PollSeconds < 0 {
+			errors = append(errors, fmt.Errorf("jobs.poll_seconds must be positive (got: %d)", c.Jobs.PollSeconds))
+		}
+		if c.Jobs.TimeoutSeconds < 0 {
+			errors = append(errors, fmt.Errorf("jobs.timeout_seconds must be positive (got: %d)", c.Jobs.TimeoutSeconds))
+		}
+	}
+
+	// Проверка forms
+	if c.Forms.Enabled && c.Forms.TTLMinutes < 0 {
+		errors = append(errors, fmt.Errorf("forms.ttl_minutes must be positive (got: %d)", c.Forms.TTLMinutes))
+	}
+
+	// Проверка onboarding
+	errors = append(errors, c.validateOnboarding()...)
+
+	// Проверка invites
+	if c.Invites.Enabled {
+		if c.Invites.TTLHours < 1 {
+			errors = append(errors, fmt.Errorf("invites.ttl_hours must be at least 1 (got: %d)", c.Invites.TTLHours))
+		}
+		if len(c.Channels.Telegram.AdminUsers) == 0 {
+			errors = append(errors, fmt.Errorf("invites require channels.telegram.admin_users to create invite codes"))
+		}
+	}
+
+	// Проверка upload
+	if c.Upload.Enabled {
+		if c.Upload.MaxSizeMB < 0 {
+			errors = append(errors, fmt.Errorf("upload.max_size_mb must be positive (got: %d)", c.Upload.MaxSizeMB))
+		}
+		if filepath.IsAbs(c.Upload.Dir) || strings.HasPrefix(filepath.Clean(c.Upload.Dir), "..") {
+			errors = append(errors, fmt.Errorf("upload.dir must be relative to the workspace (got: %s)", c.Upload.Dir))
+		}
+	}
+
+	// Проверка media
+	errors = append(errors, c.validateMedia()...)
+
+	// Проверка digest
+	errors = append(errors, c.validateDigest()...)
+
+	// Проверка export
+	if c.Export.IntervalMinutes < 0 {
+		errors = append(errors, fmt.Errorf("export.interval_minutes must not be negative (got: %d)", c.Export.IntervalMinutes))
+	}
+	if c.Export.IntervalMinutes > 0 && !c.Export.Obsidian.Enabled && !c.Export.Notion.Enabled {
+		errors = append(errors, fmt.Errorf("export.interval_minutes requires export.obsidian or export.notion to be enabled"))
+	}
+	if c.Export.Obsidian.Enabled {
+		if err := validatePath(c.Export.Obsidian.VaultPath, "export.obsidian.vault_path"); err != nil {
+			errors = append(errors, err)
+		}
+		if filepath.IsAbs(c.Export.Obsidian.Folder) || strings.HasPrefix(filepath.Clean(c.Export.Obsidian.Folder), "..") {
+			errors = append(errors, fmt.Errorf("export.obsidian.folder must be relative to the vault (got: %s)", c.Export.Obsidian.Folder))
+		}
+	}
+	if c.Export.Notion.Enabled {
+		if c.Export.Notion.Token == "" {
+			errors = append(errors, fmt.Errorf("export.notion.token is required when Notion export is enabled"))
+		}
+		if c.Export.Notion.ParentPageID == "" {
+			errors = append(errors, fmt.Errorf("export.notion.parent_page_id is required when Notion export is enabled"))
+		}
+	}
+
+	// Проверка users
+	userIDs := make(map[string]bool)
+	validUserRoles := map[string]bool{"admin": true, "user": true}
+	for i, u := range c.Users {
+		if u.ID == "" {
+			errors = append(errors, fmt.Errorf("users[%d].id is required", i))
+		} else if userIDs[u.ID] {
+			errors = append(errors, fmt.Errorf("users[%d].id is duplicated: %s", i, u.ID))
+		}
+		userIDs[u.ID] = true
+		for _, identity := range u.Identities {
+			channel, address, ok := strings.Cut(identity, ":")
+			if !ok || channel == "" || address == "" {
+				errors = append(errors, fmt.Errorf("users[%d].identities contains invalid identity %q (expected: channel:address)", i, identity))
+			}
+		}
+		if u.Timezone != "" {
+			if _, err := time.LoadLocation(u.Timezone); err != nil {
+				errors = append(errors, fmt.Errorf("users[%d].timezone is invalid: %s", i, u.Timezone))
+			}
+		}
+		if u.Role != "" && !validUserRoles[u.Role] {
+			errors = append(errors, fmt.Errorf("users[%d].role must be one of: admin, user (got: %s)", i, u.Role))
+		}
+	}
+
 	return errors
 }
 
@@ -227,6 +683,21 @@ func applyDefaults(c *Config) {
 	if c.Workspace.BootstrapMaxChars == 0 {
 		c.Workspace.BootstrapMaxChars = 20000
 	}
+	if c.Workspace.Git.AuthorName == "" {
+		c.Workspace.Git.AuthorName = "nexbot"
+	}
+	if c.Workspace.Git.AuthorEmail == "" {
+		c.Workspace.Git.AuthorEmail = "nexbot@localhost"
+	}
+	if c.Workspace.Git.Exclude == nil {
+		// Служебные данные бота: сессии, секреты, очереди, снимки и т.д.
+		c.Workspace.Git.Exclude = []string{
+			"/sessions/", "/secrets/", "/snapshots/", "/artifacts/", "/archive/",
+			"/jobs/", "/cron/", "/analytics/", "/feedback/", "/invites/", "/privacy/",
+			"/processes/", "/users/", "/monitor/", "/watcher/", "/exports/",
+			"/quarantine/", "/code_images/", "/leader.lock", "*.tmp",
+		}
+	}
 
 	if c.Agent.Provider == "" {
 		c.Agent.Provider = "zai"
@@ -240,12 +711,93 @@ func applyDefaults(c *Config) {
 	if c.Agent.MaxIterations == 0 {
 		c.Agent.MaxIterations = 20
 	}
+	// Отрицательное значение отключает предупреждение о бюджете
+	if c.Agent.BudgetWarning == 0 {
+		c.Agent.BudgetWarning = 2
+	}
+	if c.Agent.ToolRepairAttempts == 0 {
+		c.Agent.ToolRepairAttempts = 2
+	}
+	if c.Agent.ToolProtocol == "" {
+		c.Agent.ToolProtocol = "auto"
+	}
+	if c.Agent.Routing.MaxCheapChars == 0 {
+		c.Agent.Routing.MaxCheapChars = 600
+	}
+	if c.Agent.Routing.MaxCheapToolIterations == 0 {
+		c.Agent.Routing.MaxCheapToolIterations = 2
+	}
+	if c.Agent.Debate.MaxTurns == 0 {
+		c.Agent.Debate.MaxTurns = 4
+	}
+	if c.Agent.Debate.MaxTokens == 0 {
+		c.Agent.Debate.MaxTokens = 16000
+	}
+	if c.Agent.Debate.AutoMinChars == 0 {
+		c.Agent.Debate.AutoMinChars = 800
+	}
+	if c.Agent.Planning.MinChars == 0 {
+		c.Agent.Planning.MinChars = 200
+	}
+	if c.Agent.Planning.MaxSteps == 0 {
+		c.Agent.Planning.MaxSteps = 8
+	}
+	if c.Agent.Planning.StepIterations == 0 {
+		c.Agent.Planning.StepIterations = 3
+	}
+	if c.Agent.Verify.Mode == "" {
+		c.Agent.Verify.Mode = "correct"
+	}
+	if c.Agent.Verify.MinChars == 0 {
+		c.Agent.Verify.MinChars = 200
+	}
+	if c.Agent.Verify.MaxEvidenceChars == 0 {
+		c.Agent.Verify.MaxEvidenceChars = 12000
+	}
+	if c.Agent.ToolSelection.TopK == 0 {
+		c.Agent.ToolSelection.TopK = 8
+	}
+	if c.Agent.ToolOutput.MaxTokens == 0 {
+		c.Agent.ToolOutput.MaxTokens = 4000
+	}
+	// Отрицательное значение отключает обновление сводок проектов
+	if c.Agent.Projects.BriefAfterTurns == 0 {
+		c.Agent.Projects.BriefAfterTurns = 4
+	}
+	if c.Agent.Projects.MaxBriefChars == 0 {
+		c.Agent.Projects.MaxBriefChars = 2000
+	}
+	if c.Agent.Approval.Classes == nil {
+		c.Agent.Approval.Classes = []string{"file", "shell"}
+	}
+	if c.Agent.Approval.TTLMinutes == 0 {
+		c.Agent.Approval.TTLMinutes = 30
+	}
+	if c.Agent.Rollback.MaxTurns == 0 {
+		c.Agent.Rollback.MaxTurns = 5
+	}
+	if c.Agent.Rollback.MaxFileSizeMB == 0 {
+		c.Agent.Rollback.MaxFileSizeMB = 10
+	}
 	if c.Agent.Temperature == 0 {
 		c.Agent.Temperature = 0.7
 	}
+	if c.Agent.Deterministic.Seed == 0 {
+		c.Agent.Deterministic.Seed = 42
+	}
+	if c.Agent.Deterministic.Dir == "" {
+		c.Agent.Deterministic.Dir = "replay"
+	}
+	if c.Agent.Capture.MaxEntries == 0 {
+		c.Agent.Capture.MaxEntries = 50
+	}
 	if c.Agent.TimeoutSeconds == 0 {
 		c.Agent.TimeoutSeconds = DefaultAgentTimeoutSeconds
 	}
+	// Отрицательное значение отключает генерацию заголовков
+	if c.Agent.TitleAfterTurns == 0 {
+		c.Agent.TitleAfterTurns = 3
+	}
 
 	if c.LLM.ZAI.BaseURL == "" {
 		c.LLM.ZAI.BaseURL = "https://api.z.ai/api/coding/paas/v4"
@@ -291,6 +843,12 @@ func applyDefaults(c *Config) {
 	if c.Cron.Timezone == "" {
 		c.Cron.Timezone = "UTC"
 	}
+	if c.Cron.Followups.MaxPending == 0 {
+		c.Cron.Followups.MaxPending = 5
+	}
+	if c.Cron.Followups.MaxDelayHours == 0 {
+		c.Cron.Followups.MaxDelayHours = 168
+	}
 
 	// Workers defaults
 	if c.Workers.PoolSize == 0 {
@@ -311,84 +869,452 @@ func applyDefaults(c *Config) {
 		c.Subagent.SessionPrefix = "subagent-"
 	}
 
-	// Telegram defaults
-	if c.Channels.Telegram.SendTimeoutSeconds == 0 {
-		c.Channels.Telegram.SendTimeoutSeconds = 5
+	// File tool defaults
+	if c.Tools.File.DiffSummaryMaxLines == 0 {
+		c.Tools.File.DiffSummaryMaxLines = 40
 	}
-	if !c.Channels.Telegram.EnableInlineUpdates {
-		c.Channels.Telegram.EnableInlineUpdates = true
+
+	// Process tool defaults
+	if c.Tools.Process.MaxJobs == 0 {
+		c.Tools.Process.MaxJobs = 5
 	}
-	if c.Channels.Telegram.DefaultParseMode == "" {
-		c.Channels.Telegram.DefaultParseMode = "markdown"
+	if c.Tools.Process.OutputLines == 0 {
+		c.Tools.Process.OutputLines = 200
 	}
-	if !c.Channels.Telegram.EnableInlineKeyboard {
-		c.Channels.Telegram.EnableInlineKeyboard = true
+	if c.Tools.Process.StopTimeoutSeconds == 0 {
+		c.Tools.Process.StopTimeoutSeconds = 5
 	}
-	if !c.Channels.Telegram.QuietMode {
-		c.Channels.Telegram.QuietMode = false
+
+	// Plot tool defaults
+	if c.Tools.Plot.Dir == "" {
+		c.Tools.Plot.Dir = "charts"
 	}
-	if c.Channels.Telegram.AnswerCallbackTimeout == 0 {
-		c.Channels.Telegram.AnswerCallbackTimeout = 5
+
+	// Cleanup defaults
+	if c.Cleanup.IntervalMinutes == 0 {
+		c.Cleanup.IntervalMinutes = 60
+	}
+	if c.Cleanup.Action == "" {
+		c.Cleanup.Action = "delete"
+	}
+	if c.Cleanup.MediaDirs == nil {
+		c.Cleanup.MediaDirs = []string{"media", "exports"}
 	}
-}
 
-// expandEnvVars расширяет переменные окружения в конфигурации
-func expandEnvVars(c *Config) error {
-	// ZAI API Key
-	if strings.HasPrefix(c.LLM.ZAI.APIKey, "${") {
-		c.LLM.ZAI.APIKey = expandEnv(c.LLM.ZAI.APIKey)
+	// Feedback defaults
+	if c.Feedback.Profile == "" {
+		c.Feedback.Profile = "default"
 	}
 
-	// Telegram Token
-	if strings.HasPrefix(c.Channels.Telegram.Token, "${") {
-		c.Channels.Telegram.Token = expandEnv(c.Channels.Telegram.Token)
+	// Guardrails defaults
+	if c.Guardrails.Tools == nil {
+		c.Guardrails.Tools = []string{"web_fetch", "read_file", "search"}
+	}
+	if c.Guardrails.Action == "" {
+		c.Guardrails.Action = "warn"
+	}
+	if c.Guardrails.ClassifierModel == "" {
+		c.Guardrails.ClassifierModel = c.Agent.Model
+	}
+	if c.Guardrails.ClassifyMaxChars == 0 {
+		c.Guardrails.ClassifyMaxChars = 4000
 	}
 
-	// Workspace path
-	if strings.HasPrefix(c.Workspace.Path, "${") {
-		c.Workspace.Path = expandEnv(c.Workspace.Path)
+	// Digest defaults
+	if c.Digest.IntervalMinutes == 0 {
+		c.Digest.IntervalMinutes = 60
+	}
+	if c.Digest.MaxItems == 0 {
+		c.Digest.MaxItems = 50
+	}
+	if c.Digest.Model == "" {
+		c.Digest.Model = c.Agent.Model
 	}
-	c.Workspace.Path = expandHome(c.Workspace.Path)
 
-	// File tool directories
-	for i, dir := range c.Tools.File.WhitelistDirs {
-		c.Tools.File.WhitelistDirs[i] = expandHome(dir)
+	// Moderation defaults
+	if c.Moderation.Backends == nil {
+		c.Moderation.Backends = []string{"keywords"}
 	}
-	for i, dir := range c.Tools.File.ReadOnlyDirs {
-		c.Tools.File.ReadOnlyDirs[i] = expandHome(dir)
+	if c.Moderation.Action == "" {
+		c.Moderation.Action = "block"
+	}
+	if c.Moderation.TimeoutSeconds == 0 {
+		c.Moderation.TimeoutSeconds = 5
+	}
+	if c.Moderation.OpenAI.APIKey == "" {
+		c.Moderation.OpenAI.APIKey = c.LLM.OpenAI.APIKey
 	}
 
-	return nil
-}
+	// PII defaults
+	if c.PII.Categories == nil {
+		c.PII.Categories = []string{"email", "phone", "credit_card"}
+	}
 
-// expandEnv расширяет переменную окружения формата ${VAR:default}
-func expandEnv(s string) string {
-	if !strings.HasPrefix(s, "${") {
-		return s
+	// STT defaults
+	if c.STT.Provider == "" {
+		c.STT.Provider = "whisper_api"
+	}
+	if c.STT.TimeoutSeconds == 0 {
+		c.STT.TimeoutSeconds = 120
+	}
+	if c.STT.MaxVoiceSeconds == 0 {
+		c.STT.MaxVoiceSeconds = 300
+	}
+	if c.STT.WhisperAPI.APIKey == "" {
+		c.STT.WhisperAPI.APIKey = c.LLM.OpenAI.APIKey
 	}
 
-	end := strings.Index(s, "}")
-	if end == -1 {
-		return s
+	// Structured output defaults
+	if c.Structured.MaxRetries == 0 {
+		c.Structured.MaxRetries = 2
 	}
 
-	content := s[2:end]
-	if parts := strings.SplitN(content, ":", 2); len(parts) == 2 {
-		key := parts[0]
-		defaultVal := parts[1]
-		if val := os.Getenv(key); val != "" {
-			return val
-		}
-		return defaultVal
+	// Watcher defaults
+	if c.Watcher.DebounceMs == 0 {
+		c.Watcher.DebounceMs = 500
+	}
+	if c.Watcher.MaxLines == 0 {
+		c.Watcher.MaxLines = 20
 	}
 
-	// Без значения по умолчанию
-	return os.Getenv(s[2:end])
-}
+	// Monitor defaults
+	if c.Monitor.DefaultIntervalMinutes == 0 {
+		c.Monitor.DefaultIntervalMinutes = 60
+	}
+	if c.Monitor.MinIntervalMinutes == 0 {
+		c.Monitor.MinIntervalMinutes = 5
+	}
+	if c.Monitor.MaxDiffLines == 0 {
+		c.Monitor.MaxDiffLines = 20
+	}
+	if c.Monitor.MaxMonitors == 0 {
+		c.Monitor.MaxMonitors = 50
+	}
 
-// expandHome расширяет ~ в пути
-func expandHome(path string) string {
-	if strings.HasPrefix(path, "~/") {
+	// Throttle defaults
+	if c.Throttle.MessagesPerMinute == 0 {
+		c.Throttle.MessagesPerMinute = 20
+	}
+	if c.Throttle.MaxConcurrent == 0 {
+		c.Throttle.MaxConcurrent = 3
+	}
+	if c.Throttle.MuteThreshold == 0 {
+		c.Throttle.MuteThreshold = 40
+	}
+	if c.Throttle.MuteMinutes == 0 {
+		c.Throttle.MuteMinutes = 10
+	}
+
+	// Jobs defaults
+	if c.Jobs.IdleSeconds == 0 {
+		c.Jobs.IdleSeconds = 120
+	}
+	if c.Jobs.PollSeconds == 0 {
+		c.Jobs.PollSeconds = 10
+	}
+	if c.Jobs.TimeoutSeconds == 0 {
+		c.Jobs.TimeoutSeconds = 1800
+	}
+
+	// Forms defaults
+	if c.Forms.TTLMinutes == 0 {
+		c.Forms.TTLMinutes = 30
+	}
+	if c.Invites.TTLHours == 0 {
+		c.Invites.TTLHours = 72
+	}
+
+	// Upload defaults
+	if c.Upload.Dir == "" {
+		c.Upload.Dir = "uploads"
+	}
+	if c.Upload.MaxSizeMB == 0 {
+		c.Upload.MaxSizeMB = 20
+	}
+
+	// Media defaults
+	if c.Media.PhotoMaxMB == 0 {
+		c.Media.PhotoMaxMB = 10
+	}
+	if c.Media.DocumentMaxMB == 0 {
+		c.Media.DocumentMaxMB = 20
+	}
+	if c.Media.VoiceMaxMB == 0 {
+		c.Media.VoiceMaxMB = 20
+	}
+	if c.Media.AudioMaxMB == 0 {
+		c.Media.AudioMaxMB = 20
+	}
+	if c.Media.VideoMaxMB == 0 {
+		c.Media.VideoMaxMB = 20
+	}
+	if c.Media.QuarantineDir == "" {
+		c.Media.QuarantineDir = "quarantine"
+	}
+	if c.Media.ClamAV.Address == "" {
+		c.Media.ClamAV.Address = "unix:///var/run/clamav/clamd.ctl"
+	}
+	if c.Media.ClamAV.TimeoutSeconds == 0 {
+		c.Media.ClamAV.TimeoutSeconds = 30
+	}
+	if c.Media.ClamAV.Action == "" {
+		c.Media.ClamAV.Action = "reject"
+	}
+	if c.Media.Images.MaxWidth == 0 {
+		c.Media.Images.MaxWidth = 2560
+	}
+	if c.Media.Images.MaxHeight == 0 {
+		c.Media.Images.MaxHeight = 2560
+	}
+	if c.Media.Images.MaxSizeMB == 0 {
+		c.Media.Images.MaxSizeMB = 10
+	}
+	if c.Media.Images.JPEGQuality == 0 {
+		c.Media.Images.JPEGQuality = 85
+	}
+	if c.Media.CodeImages.MinLines == 0 {
+		c.Media.CodeImages.MinLines = 40
+	}
+	if c.Media.CodeImages.MaxLines == 0 {
+		c.Media.CodeImages.MaxLines = 60
+	}
+	if c.Media.CodeImages.Style == "" {
+		c.Media.CodeImages.Style = "monokai"
+	}
+	if c.Media.CodeImages.FontSize == 0 {
+		c.Media.CodeImages.FontSize = 14
+	}
+	if c.Media.CodeImages.Dir == "" {
+		c.Media.CodeImages.Dir = "code_images"
+	}
+
+	// Export defaults
+	if c.Export.Obsidian.Folder == "" {
+		c.Export.Obsidian.Folder = "Nexbot"
+	}
+	if c.Export.Notion.APIURL == "" {
+		c.Export.Notion.APIURL = "https://api.notion.com"
+	}
+
+	// Leader defaults
+	if c.Leader.Backend == "" {
+		c.Leader.Backend = "file"
+	}
+	if c.Leader.LeaseDurationSeconds == 0 {
+		c.Leader.LeaseDurationSeconds = 15
+	}
+	if c.Leader.RenewDeadlineSeconds == 0 {
+		c.Leader.RenewDeadlineSeconds = 10
+	}
+	if c.Leader.RetryPeriodSeconds == 0 {
+		c.Leader.RetryPeriodSeconds = 2
+	}
+	if c.Leader.File.Path == "" {
+		c.Leader.File.Path = filepath.Join(c.Workspace.Path, "leader.lock")
+	}
+	if c.Leader.Kubernetes.LeaseName == "" {
+		c.Leader.Kubernetes.LeaseName = "nexbot"
+	}
+
+	// Dashboard defaults
+	if c.Dashboard.Listen == "" {
+		c.Dashboard.Listen = "127.0.0.1:8090"
+	}
+	if c.Dashboard.ActiveMinutes == 0 {
+		c.Dashboard.ActiveMinutes = 60
+	}
+	if c.Dashboard.RecentMessages == 0 {
+		c.Dashboard.RecentMessages = 50
+	}
+	if c.Dashboard.LogBacklog == 0 {
+		c.Dashboard.LogBacklog = 1000
+	}
+
+	// Analytics defaults
+	if c.Analytics.RetentionDays == 0 {
+		c.Analytics.RetentionDays = 90
+	}
+
+	// Alerts defaults
+	if c.Alerts.CooldownSeconds == 0 {
+		c.Alerts.CooldownSeconds = 600
+	}
+	if c.Alerts.MaxPerHour == 0 {
+		c.Alerts.MaxPerHour = 20
+	}
+	if c.Alerts.QueueThresholdPercent == 0 {
+		c.Alerts.QueueThresholdPercent = 80
+	}
+
+	// Update defaults
+	if c.Update.Repo == "" {
+		c.Update.Repo = "aatumaykin/nexbot"
+	}
+	if c.Update.IntervalHours == 0 {
+		c.Update.IntervalHours = 24
+	}
+
+	// Telegram defaults
+	if c.Channels.Telegram.SendTimeoutSeconds == 0 {
+		c.Channels.Telegram.SendTimeoutSeconds = 5
+	}
+	if !c.Channels.Telegram.EnableInlineUpdates {
+		c.Channels.Telegram.EnableInlineUpdates = true
+	}
+	if c.Channels.Telegram.DefaultParseMode == "" {
+		c.Channels.Telegram.DefaultParseMode = "markdown"
+	}
+	if !c.Channels.Telegram.EnableInlineKeyboard {
+		c.Channels.Telegram.EnableInlineKeyboard = true
+	}
+	if !c.Channels.Telegram.QuietMode {
+		c.Channels.Telegram.QuietMode = false
+	}
+	if c.Channels.Telegram.AnswerCallbackTimeout == 0 {
+		c.Channels.Telegram.AnswerCallbackTimeout = 5
+	}
+	if c.Channels.Telegram.StreamIntervalMS == 0 {
+		c.Channels.Telegram.StreamIntervalMS = 1000
+	}
+	if c.Channels.Telegram.ReconnectMaxBackoffSeconds == 0 {
+		c.Channels.Telegram.ReconnectMaxBackoffSeconds = 60
+	}
+}
+
+// expandEnvVars расширяет переменные окружения во всех строковых значениях
+// конфигурации (${VAR}, ${VAR:default}, env:VAR) и ~ в путях
+func expandEnvVars(c *Config) error {
+	var errs []error
+	interpolateValue(reflect.ValueOf(c).Elem(), "", &errs)
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	c.STT.WhisperCpp.Binary = expandHome(c.STT.WhisperCpp.Binary)
+	c.STT.WhisperCpp.Model = expandHome(c.STT.WhisperCpp.Model)
+	c.Network.CAFile = expandHome(c.Network.CAFile)
+	c.Export.Obsidian.VaultPath = expandHome(c.Export.Obsidian.VaultPath)
+	c.Leader.File.Path = expandHome(c.Leader.File.Path)
+
+	// Workspace path
+	c.Workspace.Path = expandHome(c.Workspace.Path)
+
+	// File tool directories
+	for i, dir := range c.Tools.File.WhitelistDirs {
+		c.Tools.File.WhitelistDirs[i] = expandHome(dir)
+	}
+	for i, dir := range c.Tools.File.ReadOnlyDirs {
+		c.Tools.File.ReadOnlyDirs[i] = expandHome(dir)
+	}
+
+	return nil
+}
+
+// interpolateValue раскрывает переменные окружения в строках значения:
+// полях структур, элементах списков и значениях map. path — ключ значения
+// в TOML для сообщений об ошибках.
+func interpolateValue(v reflect.Value, path string, errs *[]error) {
+	switch v.Kind() {
+	case reflect.String:
+		s, err := interpolate(v.String())
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %w", path, err))
+			return
+		}
+		v.SetString(s)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			key, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+			if key == "" {
+				key = field.Name
+			}
+			if path != "" {
+				key = path + "." + key
+			}
+			interpolateValue(v.Field(i), key, errs)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			interpolateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Map:
+		// Значения map не адресуемы: раскрываются в копии и записываются обратно
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			interpolateValue(elem, fmt.Sprintf("%s.%v", path, iter.Key()), errs)
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			interpolateValue(v.Elem(), path, errs)
+		}
+	}
+}
+
+// envRefPattern — значение вида env:VAR, целиком взятое из переменной окружения
+var envRefPattern = regexp.MustCompile(`^env:([A-Za-z_][A-Za-z0-9_]*)$`)
+
+// interpolate раскрывает переменные окружения в строке. Значение env:VAR
+// заменяется значением VAR, которая должна быть задана. ${VAR} и
+// ${VAR:default} раскрываются в любом месте строки; $${ оставляет ${ как есть.
+func interpolate(s string) (string, error) {
+	if m := envRefPattern.FindStringSubmatch(s); m != nil {
+		value, ok := os.LookupEnv(m[1])
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", m[1])
+		}
+		return value, nil
+	}
+	return expandEnv(s), nil
+}
+
+// expandEnv расширяет переменные окружения формата ${VAR} и ${VAR:default}
+// в строке. Незаданная переменная без значения по умолчанию даёт пустую строку.
+func expandEnv(s string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start == -1 {
+			break
+		}
+		// $${ — экранированная последовательность
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1] + "${")
+			s = s[start+2:]
+			continue
+		}
+		end := strings.Index(s[start:], "}")
+		if end == -1 {
+			break
+		}
+		b.WriteString(s[:start])
+
+		key, defaultVal, hasDefault := strings.Cut(s[start+2:start+end], ":")
+		if val := os.Getenv(key); val != "" || !hasDefault {
+			b.WriteString(val)
+		} else {
+			b.WriteString(defaultVal)
+		}
+		s = s[start+end+1:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// expandHome расширяет ~ в пути
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
 		home, err := os.UserHomeDir()
 		if err != nil {
 			return path
@@ -397,3 +1323,533 @@ func expandHome(path string) string {
 	}
 	return path
 }
+
+// validateSampling проверяет диапазоны параметров сэмплинга
+func validateSampling(key string, temperature float64, s SamplingConfig) error {
+	if err := s.Sampling(temperature).Validate(); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+// Sampling возвращает параметры сэмплинга с заданной температурой
+func (s SamplingConfig) Sampling(temperature float64) llm.Sampling {
+	return llm.Sampling{
+		Temperature:      temperature,
+		TopP:             s.TopP,
+		FrequencyPenalty: s.FrequencyPenalty,
+		PresencePenalty:  s.PresencePenalty,
+		Stop:             s.Stop,
+		Seed:             s.Seed,
+	}
+}
+
+// validateModels проверяет, что известные модели провайдера поддерживают
+// настроенные возможности. Модели, которых нет во встроенном списке
+// (llm.KnownModels), не проверяются.
+func (c *Config) validateModels() []error {
+	var errors []error
+	models := []struct{ key, model string }{{"agent.model", c.Agent.Model}}
+	if c.Agent.Routing.Enabled && c.Agent.Routing.CheapModel != "" {
+		models = append(models, struct{ key, model string }{"agent.routing.cheap_model", c.Agent.Routing.CheapModel})
+	}
+
+	for _, m := range models {
+		caps, ok := llm.LookupModel(c.Agent.Provider, m.model)
+		if !ok {
+			continue
+		}
+		if c.Agent.ToolProtocol == "native" && !caps.Tools {
+			errors = append(errors, fmt.Errorf("%s %s does not support native tool calling (agent.tool_protocol = native)", m.key, m.model))
+		}
+		if caps.MaxContext > 0 && c.Agent.MaxTokens >= caps.MaxContext {
+			errors = append(errors, fmt.Errorf("agent.max_tokens (%d) must be less than the context window of %s %s (%d tokens)",
+				c.Agent.MaxTokens, m.key, m.model, caps.MaxContext))
+		}
+	}
+	return errors
+}
+
+// validateAlerts проверяет конфигурацию уведомлений администраторов
+func (c *Config) validateAlerts() []error {
+	var errors []error
+	a := c.Alerts
+
+	if a.SessionID == "" {
+		errors = append(errors, fmt.Errorf("alerts.session_id is required when alerts are enabled"))
+	} else if !strings.Contains(a.SessionID, ":") {
+		errors = append(errors, fmt.Errorf("alerts.session_id must have format 'channel:chat_id' (got: %q)", a.SessionID))
+	}
+	if a.CooldownSeconds < 0 {
+		errors = append(errors, fmt.Errorf("alerts.cooldown_seconds must be positive (got: %d)", a.CooldownSeconds))
+	}
+	if a.MaxPerHour < 0 {
+		errors = append(errors, fmt.Errorf("alerts.max_per_hour must be positive (got: %d)", a.MaxPerHour))
+	}
+	if a.QueueThresholdPercent < 0 || a.QueueThresholdPercent > 100 {
+		errors = append(errors, fmt.Errorf("alerts.queue_threshold_percent must be between 1 and 100 (got: %d)", a.QueueThresholdPercent))
+	}
+	return errors
+}
+
+// validateModeration проверяет конфигурацию модерации
+func (c *Config) validateModeration() []error {
+	var errors []error
+	m := c.Moderation
+
+	switch m.Action {
+	case "block", "redact", "flag":
+	default:
+		errors = append(errors, fmt.Errorf("invalid moderation.action: %s (expected: block, redact, flag)", m.Action))
+	}
+	if m.AdminSessionID != "" && !strings.Contains(m.AdminSessionID, ":") {
+		errors = append(errors, fmt.Errorf("moderation.admin_session_id must have format 'channel:chat_id' (got: %q)", m.AdminSessionID))
+	}
+	if m.TimeoutSeconds < 0 {
+		errors = append(errors, fmt.Errorf("moderation.timeout_seconds must be positive (got: %d)", m.TimeoutSeconds))
+	}
+	if len(m.Backends) == 0 {
+		errors = append(errors, fmt.Errorf("moderation.backends must not be empty"))
+	}
+
+	for _, backend := range m.Backends {
+		switch backend {
+		case "keywords":
+			if len(m.Keywords) == 0 {
+				errors = append(errors, fmt.Errorf("moderation.keywords is required when the keywords backend is enabled"))
+			}
+		case "openai":
+			if m.OpenAI.APIKey == "" {
+				errors = append(errors, fmt.Errorf("moderation.openai.api_key is required when the openai backend is enabled"))
+			}
+		case "classifier":
+			if m.Classifier.URL == "" {
+				errors = append(errors, fmt.Errorf("moderation.classifier.url is required when the classifier backend is enabled"))
+			}
+		default:
+			errors = append(errors, fmt.Errorf("invalid moderation backend: %s (expected: keywords, openai, classifier)", backend))
+		}
+	}
+
+	for i, rule := range m.Keywords {
+		if rule.Pattern == "" {
+			errors = append(errors, fmt.Errorf("moderation.keywords[%d].pattern is required", i))
+		} else if _, err := regexp.Compile(rule.Pattern); err != nil {
+			errors = append(errors, fmt.Errorf("moderation.keywords[%d].pattern is not a valid regex: %w", i, err))
+		}
+	}
+
+	return errors
+}
+
+// validateDebate проверяет конфигурацию режима дебатов
+func (c *Config) validateDebate() []error {
+	var errors []error
+	d := c.Agent.Debate
+
+	if d.MaxTurns < 0 {
+		errors = append(errors, fmt.Errorf("agent.debate.max_turns must be positive (got: %d)", d.MaxTurns))
+	}
+	if d.MaxTokens < 0 {
+		errors = append(errors, fmt.Errorf("agent.debate.max_tokens must be positive (got: %d)", d.MaxTokens))
+	}
+
+	if len(d.Personas) == 0 {
+		return errors
+	}
+	if d.Personas[0].Role != "proposer" {
+		errors = append(errors, fmt.Errorf("agent.debate.personas[0] must be a proposer"))
+	}
+	hasCritic := false
+	for i, p := range d.Personas {
+		if p.Name == "" {
+			errors = append(errors, fmt.Errorf("agent.debate.personas[%d].name is required", i))
+		}
+		switch p.Role {
+		case "proposer":
+		case "critic":
+			hasCritic = true
+		default:
+			errors = append(errors, fmt.Errorf("invalid agent.debate.personas[%d].role: %s (expected: proposer, critic)", i, p.Role))
+		}
+	}
+	if !hasCritic {
+		errors = append(errors, fmt.Errorf("agent.debate.personas must include a critic"))
+	}
+
+	return errors
+}
+
+// validatePlanning проверяет конфигурацию фазы планирования
+func (c *Config) validatePlanning() []error {
+	var errors []error
+	p := c.Agent.Planning
+
+	if p.MaxSteps < 0 {
+		errors = append(errors, fmt.Errorf("agent.planning.max_steps must be positive (got: %d)", p.MaxSteps))
+	}
+	if p.StepIterations < 0 {
+		errors = append(errors, fmt.Errorf("agent.planning.step_iterations must be positive (got: %d)", p.StepIterations))
+	}
+	return errors
+}
+
+// validateVerify проверяет конфигурацию проверки ответов
+func (c *Config) validateVerify() []error {
+	var errors []error
+	v := c.Agent.Verify
+
+	if v.Mode != "correct" && v.Mode != "note" {
+		errors = append(errors, fmt.Errorf("invalid agent.verify.mode: %s (expected: correct, note)", v.Mode))
+	}
+	if v.MaxEvidenceChars < 0 {
+		errors = append(errors, fmt.Errorf("agent.verify.max_evidence_chars must be positive (got: %d)", v.MaxEvidenceChars))
+	}
+	return errors
+}
+
+// toolNamespacePattern — имя пространства имён инструментов
+var toolNamespacePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// promptVariablePattern — имя переменной шаблона промпта
+var promptVariablePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// validatePrompt проверяет имена переменных шаблонов промпта
+func (c *Config) validatePrompt() []error {
+	var errors []error
+	for name := range c.Agent.Prompt.Variables {
+		if !promptVariablePattern.MatchString(name) {
+			errors = append(errors, fmt.Errorf("agent.prompt.variables: invalid name %q (expected: upper case letters, digits and underscores)", name))
+		}
+	}
+	return errors
+}
+
+// validateExperiment проверяет конфигурацию A/B теста промптов
+func (c *Config) validateExperiment() []error {
+	var errors []error
+	e := c.Experiment
+
+	if e.Name == "" || strings.Contains(e.Name, "/") {
+		errors = append(errors, fmt.Errorf("experiment.name is required and can't contain '/' (got: %q)", e.Name))
+	}
+	if len(e.Variants) < 2 {
+		errors = append(errors, fmt.Errorf("experiment.variants must have at least 2 variants (got: %d)", len(e.Variants)))
+	}
+
+	seen := make(map[string]bool, len(e.Variants))
+	for i, v := range e.Variants {
+		if v.Name == "" || strings.Contains(v.Name, "/") {
+			errors = append(errors, fmt.Errorf("experiment.variants[%d].name is required and can't contain '/' (got: %q)", i, v.Name))
+		} else if seen[v.Name] {
+			errors = append(errors, fmt.Errorf("experiment.variants[%d].name is duplicated: %s", i, v.Name))
+		}
+		seen[v.Name] = true
+
+		if v.Weight < 0 {
+			errors = append(errors, fmt.Errorf("experiment.variants[%d].weight must be positive (got: %d)", i, v.Weight))
+		}
+		if v.Prompt != "" {
+			if err := validatePath(v.Prompt, fmt.Sprintf("experiment.variants[%d].prompt", i)); err != nil {
+				errors = append(errors, err)
+			}
+		}
+		if err := validateSampling(fmt.Sprintf("experiment.variants[%d]", i), v.Temperature, v.Sampling); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	return errors
+}
+
+// validateSTT проверяет конфигурацию распознавания речи
+func (c *Config) validateSTT() []error {
+	var errors []error
+	s := c.STT
+
+	switch s.Provider {
+	case "whisper_api":
+		if s.WhisperAPI.APIKey == "" && s.WhisperAPI.BaseURL == "" {
+			errors = append(errors, fmt.Errorf("stt.whisper_api.api_key is required when stt.whisper_api.base_url is not set"))
+		}
+	case "whisper_cpp":
+		if s.WhisperCpp.Model == "" {
+			errors = append(errors, fmt.Errorf("stt.whisper_cpp.model is required when the whisper_cpp provider is used"))
+		}
+		if s.WhisperCpp.Threads < 0 {
+			errors = append(errors, fmt.Errorf("stt.whisper_cpp.threads must be positive (got: %d)", s.WhisperCpp.Threads))
+		}
+	default:
+		errors = append(errors, fmt.Errorf("invalid stt.provider: %s (expected: whisper_api, whisper_cpp)", s.Provider))
+	}
+	if s.TimeoutSeconds < 0 {
+		errors = append(errors, fmt.Errorf("stt.timeout_seconds must be positive (got: %d)", s.TimeoutSeconds))
+	}
+	if s.MaxVoiceSeconds < 0 {
+		errors = append(errors, fmt.Errorf("stt.max_voice_seconds must be positive (got: %d)", s.MaxVoiceSeconds))
+	}
+
+	return errors
+}
+
+// validateLeader проверяет настройки выбора ведущего экземпляра
+func (c *Config) validateLeader() []error {
+	var errors []error
+	l := c.Leader
+
+	switch l.Backend {
+	case "file":
+		if l.File.Path == "" {
+			errors = append(errors, fmt.Errorf("leader.file.path is required when the file backend is used"))
+		}
+	case "kubernetes":
+		if l.Kubernetes.LeaseName == "" {
+			errors = append(errors, fmt.Errorf("leader.kubernetes.lease_name is required when the kubernetes backend is used"))
+		}
+	default:
+		errors = append(errors, fmt.Errorf("invalid leader.backend: %s (expected: file, kubernetes)", l.Backend))
+	}
+
+	if l.RetryPeriodSeconds <= 0 {
+		errors = append(errors, fmt.Errorf("leader.retry_period_seconds must be positive (got: %d)", l.RetryPeriodSeconds))
+	}
+	if l.RenewDeadlineSeconds <= l.RetryPeriodSeconds {
+		errors = append(errors, fmt.Errorf("leader.renew_deadline_seconds must be greater than leader.retry_period_seconds (got: %d <= %d)",
+			l.RenewDeadlineSeconds, l.RetryPeriodSeconds))
+	}
+	if l.LeaseDurationSeconds <= l.RenewDeadlineSeconds {
+		errors = append(errors, fmt.Errorf("leader.lease_duration_seconds must be greater than leader.renew_deadline_seconds (got: %d <= %d)",
+			l.LeaseDurationSeconds, l.RenewDeadlineSeconds))
+	}
+
+	return errors
+}
+
+// validateDashboard проверяет настройки веб-панели
+func (c *Config) validateDashboard() []error {
+	var errors []error
+	d := c.Dashboard
+
+	if _, _, err := net.SplitHostPort(d.Listen); err != nil {
+		errors = append(errors, fmt.Errorf("invalid dashboard.listen: %s (expected: host:port)", d.Listen))
+	}
+
+	if !hasToken(d.Tokens) {
+		errors = append(errors, fmt.Errorf("dashboard.tokens must contain at least one token when the dashboard is enabled"))
+	}
+
+	errors = append(errors, validateAllowedIPs("dashboard.allowed_ips", d.AllowedIPs)...)
+
+	paths := make(map[string]bool)
+	for i, route := range d.Routes {
+		field := fmt.Sprintf("dashboard.routes[%d]", i)
+		if !strings.HasPrefix(route.Path, "/") {
+			errors = append(errors, fmt.Errorf("%s.path must start with / (got: %q)", field, route.Path))
+		} else if paths[route.Path] {
+			errors = append(errors, fmt.Errorf("%s.path is duplicated: %s", field, route.Path))
+		}
+		paths[route.Path] = true
+
+		// The API exposes conversation history and is never left open
+		api := route.Path == "/api" || strings.HasPrefix(route.Path, "/api/")
+		if api && strings.TrimSpace(route.Secret) == "" && !hasToken(route.Tokens) {
+			errors = append(errors, fmt.Errorf("%s must set tokens or secret for the API path %s", field, route.Path))
+		}
+		errors = append(errors, validateAllowedIPs(field+".allowed_ips", route.AllowedIPs)...)
+	}
+
+	if d.ActiveMinutes < 0 {
+		errors = append(errors, fmt.Errorf("dashboard.active_minutes must be positive (got: %d)", d.ActiveMinutes))
+	}
+	if d.RecentMessages < 0 {
+		errors = append(errors, fmt.Errorf("dashboard.recent_messages must be positive (got: %d)", d.RecentMessages))
+	}
+	if d.LogBacklog < 0 {
+		errors = append(errors, fmt.Errorf("dashboard.log_backlog must be positive (got: %d)", d.LogBacklog))
+	}
+
+	return errors
+}
+
+// hasToken сообщает, есть ли среди токенов непустой
+func hasToken(tokens []string) bool {
+	for _, token := range tokens {
+		if strings.TrimSpace(token) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAllowedIPs проверяет список IP адресов и CIDR
+func validateAllowedIPs(field string, entries []string) []error {
+	var errors []error
+	for i, entry := range entries {
+		if _, err := netip.ParsePrefix(entry); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(entry); err != nil {
+			errors = append(errors, fmt.Errorf("invalid %s[%d]: %s (expected: IP address or CIDR)", field, i, entry))
+		}
+	}
+	return errors
+}
+
+// validateDigest проверяет режим дайджеста уведомлений
+func (c *Config) validateDigest() []error {
+	if !c.Digest.Enabled {
+		return nil
+	}
+	var errors []error
+	if c.Digest.IntervalMinutes < 1 {
+		errors = append(errors, fmt.Errorf("digest.interval_minutes must be at least 1 (got: %d)", c.Digest.IntervalMinutes))
+	}
+	if c.Digest.MaxItems < 1 {
+		errors = append(errors, fmt.Errorf("digest.max_items must be at least 1 (got: %d)", c.Digest.MaxItems))
+	}
+	validSources := map[string]bool{"cron": true, "watcher": true, "jobs": true}
+	for _, source := range c.Digest.Sources {
+		if !validSources[source] {
+			errors = append(errors, fmt.Errorf("invalid digest source: %s (must be one of: cron, watcher, jobs)", source))
+		}
+	}
+	return errors
+}
+
+// consentNamePattern — ключ согласия (входит в callback data кнопок)
+var consentNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// inviteCodePattern — код приглашения (входит в payload deep link, не длиннее 64 символов)
+var inviteCodePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,60}$`)
+
+// validateOnboarding проверяет коды приглашений и вопросы о согласии
+func (c *Config) validateOnboarding() []error {
+	if !c.Onboarding.Enabled {
+		return nil
+	}
+	var errors []error
+	for _, code := range c.Onboarding.InviteCodes {
+		if !inviteCodePattern.MatchString(code) || strings.Contains(code, "__") {
+			errors = append(errors, fmt.Errorf("invalid onboarding invite code %q (use up to 60 letters, digits, '-' and single '_')", code))
+		}
+	}
+	names := make(map[string]bool)
+	for i, consent := range c.Onboarding.Consents {
+		if !consentNamePattern.MatchString(consent.Name) {
+			errors = append(errors, fmt.Errorf("onboarding.consents[%d].name %q must match %s", i, consent.Name, consentNamePattern))
+		} else if names[consent.Name] {
+			errors = append(errors, fmt.Errorf("onboarding.consents[%d].name is duplicated: %s", i, consent.Name))
+		}
+		names[consent.Name] = true
+		if strings.TrimSpace(consent.Question) == "" {
+			errors = append(errors, fmt.Errorf("onboarding.consents[%d].question is required", i))
+		}
+	}
+	return errors
+}
+
+// validateMedia проверяет лимиты и типы скачиваемых файлов
+func (c *Config) validateMedia() []error {
+	var errors []error
+	m := c.Media
+
+	for _, limit := range []struct {
+		key  string
+		size int
+	}{
+		{"photo_max_mb", m.PhotoMaxMB},
+		{"document_max_mb", m.DocumentMaxMB},
+		{"voice_max_mb", m.VoiceMaxMB},
+		{"audio_max_mb", m.AudioMaxMB},
+		{"video_max_mb", m.VideoMaxMB},
+		{"images.max_width", m.Images.MaxWidth},
+		{"images.max_height", m.Images.MaxHeight},
+		{"images.max_size_mb", m.Images.MaxSizeMB},
+		{"code_images.min_lines", m.CodeImages.MinLines},
+		{"code_images.max_lines", m.CodeImages.MaxLines},
+	} {
+		if limit.size < 0 {
+			errors = append(errors, fmt.Errorf("media.%s must be positive (got: %d)", limit.key, limit.size))
+		}
+	}
+
+	if m.Images.JPEGQuality < 0 || m.Images.JPEGQuality > 100 {
+		errors = append(errors, fmt.Errorf("media.images.jpeg_quality must be between 1 and 100 (got: %d)", m.Images.JPEGQuality))
+	}
+
+	if m.CodeImages.FontSize < 0 {
+		errors = append(errors, fmt.Errorf("media.code_images.font_size must be positive (got: %g)", m.CodeImages.FontSize))
+	}
+	if m.CodeImages.Enabled && (filepath.IsAbs(m.CodeImages.Dir) || strings.HasPrefix(filepath.Clean(m.CodeImages.Dir), "..")) {
+		errors = append(errors, fmt.Errorf("media.code_images.dir must be relative to the workspace (got: %s)", m.CodeImages.Dir))
+	}
+
+	for i, pattern := range m.AllowedTypes {
+		group, sub, ok := strings.Cut(pattern, "/")
+		if !ok || group == "" || group == "*" || sub == "" || strings.ContainsAny(pattern, " ;") {
+			errors = append(errors, fmt.Errorf("invalid media.allowed_types[%d]: %s (expected: type/subtype or type/*)", i, pattern))
+		}
+	}
+
+	if filepath.IsAbs(m.QuarantineDir) || strings.HasPrefix(filepath.Clean(m.QuarantineDir), "..") {
+		errors = append(errors, fmt.Errorf("media.quarantine_dir must be relative to the workspace (got: %s)", m.QuarantineDir))
+	}
+
+	if m.ClamAV.Enabled {
+		address, valid := strings.CutPrefix(m.ClamAV.Address, "unix://")
+		if !valid {
+			address, valid = strings.CutPrefix(m.ClamAV.Address, "tcp://")
+			if valid {
+				_, _, err := net.SplitHostPort(address)
+				valid = err == nil
+			}
+		}
+		if !valid || address == "" {
+			errors = append(errors, fmt.Errorf("invalid media.clamav.address: %s (expected: unix:///path or tcp://host:port)", m.ClamAV.Address))
+		}
+		if m.ClamAV.TimeoutSeconds < 0 {
+			errors = append(errors, fmt.Errorf("media.clamav.timeout_seconds must be positive (got: %d)", m.ClamAV.TimeoutSeconds))
+		}
+		switch m.ClamAV.Action {
+		case "reject", "quarantine", "warn":
+		default:
+			errors = append(errors, fmt.Errorf("invalid media.clamav.action: %s (expected: reject, quarantine, warn)", m.ClamAV.Action))
+		}
+	}
+
+	return errors
+}
+
+// validateNetwork проверяет настройки исходящих соединений
+func (c *Config) validateNetwork() []error {
+	var errors []error
+	n := c.Network
+
+	if n.Proxy != "" {
+		proxy, err := url.Parse(n.Proxy)
+		switch {
+		case err != nil:
+			errors = append(errors, fmt.Errorf("invalid network.proxy: %s", maskSecret(n.Proxy)))
+		case proxy.Scheme != "http" && proxy.Scheme != "https" && proxy.Scheme != "socks5" && proxy.Scheme != "socks5h":
+			errors = append(errors, fmt.Errorf("invalid network.proxy scheme: %s (expected: http, https, socks5, socks5h)", proxy.Scheme))
+		case proxy.Host == "":
+			errors = append(errors, fmt.Errorf("network.proxy must include a host (got: %s)", maskSecret(n.Proxy)))
+		}
+	}
+
+	for i, server := range n.DNSServers {
+		if _, err := netip.ParseAddr(server); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddrPort(server); err != nil {
+			errors = append(errors, fmt.Errorf("invalid network.dns_servers[%d]: %s (expected: IP address with optional port)", i, server))
+		}
+	}
+
+	for host, ip := range n.Hosts {
+		if _, err := netip.ParseAddr(ip); err != nil {
+			errors = append(errors, fmt.Errorf("invalid network.hosts address for %s: %s (expected: IP address)", host, ip))
+		}
+	}
+
+	return errors
+}
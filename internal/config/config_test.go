@@ -20,6 +20,7 @@ func TestConfigDefaults(t *testing.T) {
 		{"workspace path", "workspace.path", "~/.nexbot", cfg.Workspace.Path},
 		{"agent provider", "agent.provider", "zai", cfg.Agent.Provider},
 		{"agent model", "agent.model", "glm-4.7-flash", cfg.Agent.Model},
+		{"agent tool protocol", "agent.tool_protocol", "auto", cfg.Agent.ToolProtocol},
 		{"logging level", "logging.level", "info", cfg.Logging.Level},
 		{"logging format", "logging.format", "json", cfg.Logging.Format},
 		{"logging output", "logging.output", "stdout", cfg.Logging.Output},
@@ -35,12 +36,81 @@ func TestConfigDefaults(t *testing.T) {
 	}
 
 	// Check numeric defaults
+	if cfg.Agent.BudgetWarning != 2 {
+		t.Errorf("Expected agent.budget_warning = 2, got %d", cfg.Agent.BudgetWarning)
+	}
 	if cfg.Channels.Telegram.AnswerCallbackTimeout != 5 {
 		t.Errorf("Expected channels.telegram.answer_callback_timeout = 5, got %d", cfg.Channels.Telegram.AnswerCallbackTimeout)
 	}
 	if cfg.Channels.Telegram.SendTimeoutSeconds != 5 {
 		t.Errorf("Expected channels.telegram.send_timeout_seconds = 5, got %d", cfg.Channels.Telegram.SendTimeoutSeconds)
 	}
+	if cfg.Channels.Telegram.StreamAnswers || cfg.Channels.Telegram.StreamIntervalMS != 1000 {
+		t.Errorf("Expected telegram answer streaming disabled with 1000ms interval, got %v/%d",
+			cfg.Channels.Telegram.StreamAnswers, cfg.Channels.Telegram.StreamIntervalMS)
+	}
+	if cfg.Channels.Telegram.ReconnectMaxBackoffSeconds != 60 || cfg.Channels.Telegram.NotifyReconnectAfterSeconds != 0 {
+		t.Errorf("Expected telegram reconnect backoff 60s without notification, got %d/%d",
+			cfg.Channels.Telegram.ReconnectMaxBackoffSeconds, cfg.Channels.Telegram.NotifyReconnectAfterSeconds)
+	}
+	if cfg.Throttle.MessagesPerMinute != 20 || cfg.Throttle.MuteThreshold != 40 {
+		t.Errorf("Expected throttle limits 20/40, got %d/%d", cfg.Throttle.MessagesPerMinute, cfg.Throttle.MuteThreshold)
+	}
+	if cfg.Jobs.IdleSeconds != 120 || cfg.Jobs.TimeoutSeconds != 1800 {
+		t.Errorf("Expected jobs idle/timeout 120/1800, got %d/%d", cfg.Jobs.IdleSeconds, cfg.Jobs.TimeoutSeconds)
+	}
+	if cfg.Forms.TTLMinutes != 30 {
+		t.Errorf("Expected forms.ttl_minutes = 30, got %d", cfg.Forms.TTLMinutes)
+	}
+	if cfg.Invites.TTLHours != 72 {
+		t.Errorf("Expected invites.ttl_hours = 72, got %d", cfg.Invites.TTLHours)
+	}
+	if cfg.Upload.Dir != "uploads" || cfg.Upload.MaxSizeMB != 20 {
+		t.Errorf("Expected upload dir/max size uploads/20, got %s/%d", cfg.Upload.Dir, cfg.Upload.MaxSizeMB)
+	}
+	if cfg.Media.PhotoMaxMB != 10 || cfg.Media.DocumentMaxMB != 20 || cfg.Media.QuarantineDir != "quarantine" {
+		t.Errorf("Expected media photo/document max size and quarantine dir 10/20/quarantine, got %d/%d/%s",
+			cfg.Media.PhotoMaxMB, cfg.Media.DocumentMaxMB, cfg.Media.QuarantineDir)
+	}
+	if cfg.Media.ClamAV.Address != "unix:///var/run/clamav/clamd.ctl" || cfg.Media.ClamAV.Action != "reject" {
+		t.Errorf("Expected media.clamav address/action unix:///var/run/clamav/clamd.ctl/reject, got %s/%s",
+			cfg.Media.ClamAV.Address, cfg.Media.ClamAV.Action)
+	}
+	if cfg.Media.Images.MaxWidth != 2560 || cfg.Media.Images.MaxSizeMB != 10 || cfg.Media.Images.JPEGQuality != 85 {
+		t.Errorf("Expected media.images max width/size/quality 2560/10/85, got %d/%d/%d",
+			cfg.Media.Images.MaxWidth, cfg.Media.Images.MaxSizeMB, cfg.Media.Images.JPEGQuality)
+	}
+	if cfg.Digest.IntervalMinutes != 60 || cfg.Digest.MaxItems != 50 || cfg.Digest.Model != cfg.Agent.Model {
+		t.Errorf("Expected digest interval/max items/model 60/50/%s, got %d/%d/%s",
+			cfg.Agent.Model, cfg.Digest.IntervalMinutes, cfg.Digest.MaxItems, cfg.Digest.Model)
+	}
+	if cfg.Tools.Plot.Dir != "charts" {
+		t.Errorf("Expected tools.plot.dir = charts, got %s", cfg.Tools.Plot.Dir)
+	}
+	if cfg.Agent.Debate.MaxTurns != 4 || cfg.Agent.Debate.MaxTokens != 16000 || cfg.Agent.Debate.AutoMinChars != 800 {
+		t.Errorf("Expected debate max turns/tokens/auto min chars 4/16000/800, got %d/%d/%d",
+			cfg.Agent.Debate.MaxTurns, cfg.Agent.Debate.MaxTokens, cfg.Agent.Debate.AutoMinChars)
+	}
+	if cfg.Agent.Planning.MinChars != 200 || cfg.Agent.Planning.MaxSteps != 8 || cfg.Agent.Planning.StepIterations != 3 {
+		t.Errorf("Expected planning min chars/max steps/step iterations 200/8/3, got %d/%d/%d",
+			cfg.Agent.Planning.MinChars, cfg.Agent.Planning.MaxSteps, cfg.Agent.Planning.StepIterations)
+	}
+	if cfg.Agent.Verify.Mode != "correct" || cfg.Agent.Verify.MinChars != 200 || cfg.Agent.Verify.MaxEvidenceChars != 12000 {
+		t.Errorf("Expected verify mode/min chars/max evidence chars correct/200/12000, got %s/%d/%d",
+			cfg.Agent.Verify.Mode, cfg.Agent.Verify.MinChars, cfg.Agent.Verify.MaxEvidenceChars)
+	}
+	if cfg.Agent.ToolSelection.TopK != 8 {
+		t.Errorf("Expected agent.tool_selection.top_k = 8, got %d", cfg.Agent.ToolSelection.TopK)
+	}
+	if cfg.STT.Provider != "whisper_api" || cfg.STT.TimeoutSeconds != 120 || cfg.STT.MaxVoiceSeconds != 300 {
+		t.Errorf("Expected stt provider/timeout/max voice whisper_api/120/300, got %s/%d/%d", cfg.STT.Provider, cfg.STT.TimeoutSeconds, cfg.STT.MaxVoiceSeconds)
+	}
+	if cfg.Structured.MaxRetries != 2 {
+		t.Errorf("Expected structured.max_retries = 2, got %d", cfg.Structured.MaxRetries)
+	}
+	if cfg.Export.Obsidian.Folder != "Nexbot" || cfg.Export.Notion.APIURL != "https://api.notion.com" {
+		t.Errorf("Expected export folder/api url Nexbot/https://api.notion.com, got %s/%s", cfg.Export.Obsidian.Folder, cfg.Export.Notion.APIURL)
+	}
 
 	// Check boolean defaults
 	if cfg.Channels.Telegram.EnableInlineUpdates != true {
@@ -61,12 +131,1035 @@ func TestConfigValidation(t *testing.T) {
 		wantErr bool
 	}{
 		{
-			name: "valid config with minimal fields",
+			name: "valid config with minimal fields",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+					Model:    "glm-4.7-flash",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown digest source",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Digest:    DigestConfig{Enabled: true, IntervalMinutes: 60, MaxItems: 50, Sources: []string{"cron", "webhook"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "onboarding with invalid consent and invite code",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Onboarding: OnboardingConfig{
+					Enabled:     true,
+					InviteCodes: []string{"team__2026"},
+					Consents:    []ConsentConfig{{Name: "Keep History", Question: "Keep the history?"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invites without admin users",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Invites:   InvitesConfig{Enabled: true, TTLHours: 72},
+			},
+			wantErr: true,
+		},
+		{
+			name: "user with invalid timezone and role",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Users:     []UserConfig{{ID: "alice", Timezone: "Mars/Olympus", Role: "owner"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "pii with unknown category",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				PII:       PIIConfig{Enabled: true, Categories: []string{"email", "passport"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "analytics with negative retention",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Analytics: AnalyticsConfig{Enabled: true, RetentionDays: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dashboard with negative log backlog",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Dashboard: DashboardConfig{Enabled: true, Listen: "127.0.0.1:8090", Tokens: []string{"secret"}, LogBacklog: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dashboard without tokens",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Dashboard: DashboardConfig{Enabled: true, Listen: "127.0.0.1:8090", Tokens: []string{" "}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dashboard with invalid allowed IP",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Dashboard: DashboardConfig{
+					Enabled: true, Listen: "127.0.0.1:8090",
+					Tokens: []string{"secret"}, AllowedIPs: []string{"10.0.0.0/8", "office"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dashboard route without leading slash",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Dashboard: DashboardConfig{
+					Enabled: true, Listen: "127.0.0.1:8090", Tokens: []string{"secret"},
+					Routes: []DashboardRouteConfig{{Path: "api/logs", Tokens: []string{"logs"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dashboard API route without tokens or secret",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Dashboard: DashboardConfig{
+					Enabled: true, Listen: "127.0.0.1:8090", Tokens: []string{"secret"},
+					Routes: []DashboardRouteConfig{{Path: "/api/logs", AllowedIPs: []string{"127.0.0.1"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dashboard route with invalid allowed IP",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Dashboard: DashboardConfig{
+					Enabled: true, Listen: "127.0.0.1:8090", Tokens: []string{"secret"},
+					Routes: []DashboardRouteConfig{{Path: "/api/logs", Secret: "hook", AllowedIPs: []string{"office"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dashboard with duplicated route",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Dashboard: DashboardConfig{
+					Enabled: true, Listen: "127.0.0.1:8090", Tokens: []string{"secret"},
+					Routes: []DashboardRouteConfig{
+						{Path: "/api/logs", Tokens: []string{"logs"}},
+						{Path: "/api/logs", Tokens: []string{"other"}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dashboard with routes",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Dashboard: DashboardConfig{
+					Enabled: true, Listen: "127.0.0.1:8090", Tokens: []string{"secret"},
+					Routes: []DashboardRouteConfig{
+						{Path: "/api/logs", Tokens: []string{"logs"}, AllowedIPs: []string{"10.0.0.0/8"}},
+						{Path: "/", AllowedIPs: []string{"127.0.0.1"}},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unsupported leader backend",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Leader: LeaderConfig{
+					Enabled: true, Backend: "postgres",
+					LeaseDurationSeconds: 15, RenewDeadlineSeconds: 10, RetryPeriodSeconds: 2,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "leader renew deadline not shorter than lease",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Leader: LeaderConfig{
+					Enabled: true, Backend: "file", File: LeaderFileConfig{Path: "/tmp/leader.lock"},
+					LeaseDurationSeconds: 10, RenewDeadlineSeconds: 10, RetryPeriodSeconds: 2,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid shell interpreter",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Tools: ToolsConfig{
+					Shell: ShellToolConfig{Enabled: true, Shell: "bash"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "non-positive tool budget",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider:    "zai",
+					ToolBudgets: map[string]int{"shell": 5, "web": 0},
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid guardrails action",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Guardrails: GuardrailsConfig{Enabled: true, Action: "drop"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "moderation backend without settings",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Moderation: ModerationConfig{
+					Enabled:  true,
+					Backends: []string{"keywords", "classifier"},
+					Action:   "block",
+					Keywords: []ModerationKeywordConfig{{Pattern: "secret"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "alerts without admin session",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Alerts: AlertsConfig{Enabled: true, MaxPerHour: 20},
+			},
+			wantErr: true,
+		},
+		{
+			name: "update repo without owner",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Update: UpdateConfig{Enabled: true, Repo: "nexbot"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "max tokens over the model context window",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider:  "zai",
+					Model:     "glm-4.5v",
+					MaxTokens: 100000,
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "sampling parameter out of range",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+					Model:    "glm-4.7",
+					Sampling: SamplingConfig{TopP: 1.5},
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid deterministic mode",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider:      "zai",
+					Model:         "glm-4.7",
+					Deterministic: DeterministicConfig{Enabled: true, Mode: "rerun", Dir: "replay"},
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative slow tool threshold",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+					Model:    "glm-4.7",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Analytics: AnalyticsConfig{SlowToolP95Ms: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid network settings",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Network: NetworkConfig{
+					Proxy:      "ftp://proxy.corp:21",
+					DNSServers: []string{"dns.corp"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "throttle mute threshold below rate limit",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Throttle: ThrottleConfig{Enabled: true, MessagesPerMinute: 20, MuteThreshold: 10},
+			},
+			wantErr: true,
+		},
+		{
+			name: "routing without cheap model",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+					Routing:  RoutingConfig{Enabled: true},
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative job timeout",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Jobs: JobsConfig{Enabled: true, TimeoutSeconds: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative form ttl",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Forms: FormsConfig{Enabled: true, TTLMinutes: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid jpeg quality",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Media: MediaConfig{Images: ImagesConfig{JPEGQuality: 101}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid clamav action",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Media: MediaConfig{ClamAV: ClamAVConfig{Enabled: true, Address: "tcp://127.0.0.1:3310", Action: "delete"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid media allowed type",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Media: MediaConfig{AllowedTypes: []string{"text/csv", "pdf"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "upload dir outside workspace",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Upload: UploadConfig{Enabled: true, Dir: "../uploads"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "plot dir outside workspace",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Tools: ToolsConfig{Plot: PlotToolConfig{Enabled: true, Dir: "/tmp/charts"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "fetch cache dir outside workspace",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Tools: ToolsConfig{Fetch: FetchToolConfig{CacheDir: "../cache"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative fetch min interval",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Tools: ToolsConfig{Fetch: FetchToolConfig{MinIntervalMs: -1}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "monitor with invalid selector",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Monitor: MonitorConfig{Monitors: []PageMonitorConfig{
+					{URL: "https://example.com/", Selector: "div[", SessionID: "telegram:1"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid tool namespace",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Tools: ToolsConfig{Namespaces: map[string]bool{"fs.read": false}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "notion export without token",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Export: ExportConfig{Notion: NotionExportConfig{Enabled: true, ParentPageID: "page"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "scheduled export without targets",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Export: ExportConfig{IntervalMinutes: 60},
+			},
+			wantErr: true,
+		},
+		{
+			name: "experiment with a single variant",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Experiment: ExperimentConfig{
+					Enabled:  true,
+					Name:     "tone",
+					Variants: []ExperimentVariant{{Name: "control"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "experiment with duplicate variants",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Experiment: ExperimentConfig{
+					Enabled:  true,
+					Name:     "tone",
+					Variants: []ExperimentVariant{{Name: "control"}, {Name: "control", Prompt: "prompts/concise.md"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid prompt variable name",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+					Prompt:   PromptConfig{Variables: map[string]string{"user name": "Alex"}},
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid tool protocol",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider:     "zai",
+					ToolProtocol: "xml",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "structured with negative max tokens",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Structured: StructuredConfig{Enabled: true, MaxTokens: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid verify",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+					Verify:   VerifyConfig{Enabled: true, Mode: "note", Model: "glm-4.7-flash"},
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid verify mode",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+					Verify:   VerifyConfig{Enabled: true, Mode: "rewrite"},
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid tool selection top_k",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider:      "zai",
+					ToolSelection: ToolSelectionConfig{Enabled: true, TopK: -1},
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid planning",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+					Planning: PlanningConfig{Enabled: true, MaxSteps: 6, StepIterations: 2},
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "planning with negative step iterations",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+					Planning: PlanningConfig{Enabled: true, StepIterations: -1},
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid debate with default personas",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+					Debate:   DebateConfig{Enabled: true, MaxTurns: 4},
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid debate personas",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+					Debate:   DebateConfig{Enabled: true, Personas: []DebatePersonaConfig{{Name: "Architect", Role: "proposer"}, {Name: "Security", Role: "critic"}}},
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "debate personas starting with critic",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+					Debate:   DebateConfig{Enabled: true, Personas: []DebatePersonaConfig{{Name: "Security", Role: "critic"}, {Name: "Architect", Role: "proposer"}}},
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "debate personas without critic",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+					Debate:   DebateConfig{Enabled: true, Personas: []DebatePersonaConfig{{Name: "Architect", Role: "proposer"}}},
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid debate persona role",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+					Debate:   DebateConfig{Enabled: true, Personas: []DebatePersonaConfig{{Name: "Architect", Role: "proposer"}, {Name: "Judge", Role: "judge"}}},
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid stt whisper_api",
 			cfg: &Config{
 				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
 				Agent: AgentConfig{
 					Provider: "zai",
-					Model:    "glm-4.7-flash",
 				},
 				LLM: LLMConfig{
 					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
@@ -76,9 +1169,67 @@ func TestConfigValidation(t *testing.T) {
 					Format: "json",
 					Output: "stdout",
 				},
+				STT: STTConfig{Enabled: true, Provider: "whisper_api", WhisperAPI: WhisperAPISTTConfig{APIKey: "sk-test"}},
 			},
 			wantErr: false,
 		},
+		{
+			name: "stt whisper_api without api key",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				STT: STTConfig{Enabled: true, Provider: "whisper_api"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "stt whisper_cpp without model",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				STT: STTConfig{Enabled: true, Provider: "whisper_cpp"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid stt provider",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				STT: STTConfig{Enabled: true, Provider: "vosk"},
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing workspace path",
 			cfg: &Config{
@@ -332,6 +1483,23 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid telegram reconnect notification delay (negative)",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent:     AgentConfig{Provider: "zai"},
+				LLM:       LLMConfig{ZAI: ZAIConfig{APIKey: "zai-test-key-valid"}},
+				Logging:   LoggingConfig{Level: "info", Format: "json", Output: "stdout"},
+				Channels: ChannelsConfig{
+					Telegram: TelegramConfig{
+						Token:                       "123456789:ABCDEF",
+						Enabled:                     true,
+						NotifyReconnectAfterSeconds: -1,
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid telegram answer callback timeout (negative)",
 			cfg: &Config{
@@ -382,6 +1550,32 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid telegram stream interval (negative)",
+			cfg: &Config{
+				Workspace: WorkspaceConfig{Path: "~/.nexbot"},
+				Agent: AgentConfig{
+					Provider: "zai",
+				},
+				LLM: LLMConfig{
+					ZAI: ZAIConfig{APIKey: "zai-test-key-valid"},
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+					Output: "stdout",
+				},
+				Channels: ChannelsConfig{
+					Telegram: TelegramConfig{
+						Token:            "123456789:ABCDEF",
+						Enabled:          true,
+						StreamAnswers:    true,
+						StreamIntervalMS: -1,
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "valid telegram config with new fields",
 			cfg: &Config{
@@ -467,6 +1661,78 @@ func TestExpandEnv(t *testing.T) {
 	}
 }
 
+func TestInterpolate(t *testing.T) {
+	t.Setenv("NEXBOT_TEST_TOKEN", "secret")
+	t.Setenv("NEXBOT_TEST_HOST", "proxy.local")
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "env prefix", input: "env:NEXBOT_TEST_TOKEN", want: "secret"},
+		{name: "env prefix unset", input: "env:NEXBOT_TEST_MISSING", wantErr: true},
+		{name: "env prefix in text is literal", input: "env: production", want: "env: production"},
+		{name: "embedded variables", input: "http://${NEXBOT_TEST_HOST}:${NEXBOT_TEST_PORT:3128}/", want: "http://proxy.local:3128/"},
+		{name: "escaped", input: "echo $${HOME}", want: "echo ${HOME}"},
+		{name: "unclosed", input: "${NEXBOT_TEST_HOST", want: "${NEXBOT_TEST_HOST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interpolate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("interpolate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("interpolate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLoad_Interpolation tests that any string value, in nested tables,
+// lists and maps, is interpolated
+func TestLoad_Interpolation(t *testing.T) {
+	t.Setenv("NEXBOT_TEST_MODEL", "glm-4.7")
+	t.Setenv("NEXBOT_TEST_USER", "123456")
+	t.Setenv("NEXBOT_TEST_NAME", "Alex")
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := `[agent]
+model = "env:NEXBOT_TEST_MODEL"
+
+[agent.prompt.variables]
+USER_NAME = "${NEXBOT_TEST_NAME}"
+
+[channels.telegram]
+allowed_users = ["${NEXBOT_TEST_USER}", "42"]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Agent.Model != "glm-4.7" || cfg.Agent.Prompt.Variables["USER_NAME"] != "Alex" ||
+		cfg.Channels.Telegram.AllowedUsers[0] != "123456" {
+		t.Errorf("Load() model/variable/user = %q/%q/%v", cfg.Agent.Model,
+			cfg.Agent.Prompt.Variables["USER_NAME"], cfg.Channels.Telegram.AllowedUsers)
+	}
+
+	// An unset env: reference fails loading with the key of the value
+	if err := os.WriteFile(path, []byte("[llm.zai]\napi_key = \"env:NEXBOT_TEST_MISSING_KEY\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	_, err = Load(path)
+	if err == nil || !strings.Contains(err.Error(), "llm.zai.api_key: environment variable NEXBOT_TEST_MISSING_KEY is not set") {
+		t.Errorf("Load() error = %v, want missing variable with key", err)
+	}
+}
+
 func TestWorkspacePathExpansion(t *testing.T) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -0,0 +1,308 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Модели по умолчанию для провайдеров в сгенерированной конфигурации
+var defaultModels = map[string]string{
+	"zai":    "glm-4.7-flash",
+	"openai": "gpt-4o-mini",
+}
+
+// Переменные окружения с секретами в сгенерированной конфигурации
+const (
+	EnvZAIAPIKey        = "ZAI_API_KEY"
+	EnvOpenAIAPIKey     = "OPENAI_API_KEY"
+	EnvTelegramBotToken = "TELEGRAM_BOT_TOKEN"
+)
+
+// InitOptions представляет ответы мастера nexbot init
+type InitOptions struct {
+	Provider     string   // zai или openai
+	Model        string   // Пусто — модель провайдера по умолчанию
+	Workspace    string   // Путь к workspace (по умолчанию ~/.nexbot)
+	Telegram     bool     // Включить канал Telegram
+	AllowedUsers []string // Telegram user ID, которым разрешён доступ
+	Shell        bool     // Включить shell_exec
+	Timezone     string   // Часовой пояс cron (по умолчанию UTC)
+}
+
+// Generate возвращает полный файл конфигурации с комментариями. Секреты не
+// попадают в файл: API ключ и токен Telegram читаются из переменных окружения
+// (см. APIKeyEnv, EnvTelegramBotToken). Вывод детерминирован: одинаковые
+// опции дают одинаковый файл.
+func Generate(opts InitOptions) (string, error) {
+	if _, ok := defaultModels[opts.Provider]; !ok {
+		return "", fmt.Errorf("invalid provider: %s (expected: zai, openai)", opts.Provider)
+	}
+	if opts.Model == "" {
+		opts.Model = defaultModels[opts.Provider]
+	}
+	if opts.Workspace == "" {
+		opts.Workspace = "~/.nexbot"
+	}
+	if opts.Timezone == "" {
+		opts.Timezone = "UTC"
+	}
+
+	var buf bytes.Buffer
+	if err := configTemplate.Execute(&buf, struct {
+		InitOptions
+		APIKeyEnv string
+		TokenEnv  string
+	}{opts, APIKeyEnv(opts.Provider), EnvTelegramBotToken}); err != nil {
+		return "", fmt.Errorf("failed to render config: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// APIKeyEnv возвращает переменную окружения с API ключом провайдера
+func APIKeyEnv(provider string) string {
+	if provider == "openai" {
+		return EnvOpenAIAPIKey
+	}
+	return EnvZAIAPIKey
+}
+
+// SetEnv записывает переменные в .env файл: существующие строки KEY=...
+// заменяются, новые переменные добавляются в конец. Файл создаётся с правами
+// 0600, так как содержит секреты.
+func SetEnv(path string, vars map[string]string) error {
+	path = expandHome(path)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	}
+	written := make(map[string]bool, len(vars))
+	for i, line := range lines {
+		key, _, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if value, set := vars[key]; ok && set {
+			lines[i] = key + "=" + value
+			written[key] = true
+		}
+	}
+	for _, key := range sortedKeys(vars) {
+		if !written[key] {
+			lines = append(lines, key+"="+vars[key])
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create env directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write env file: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(path, 0600)
+}
+
+// sortedKeys возвращает ключи map в алфавитном порядке
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// tomlString кавычит строку как базовую строку TOML
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// tomlList форматирует список строк TOML
+func tomlList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = tomlString(item)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+var configTemplate = template.Must(template.New("config").Funcs(template.FuncMap{
+	"str":  tomlString,
+	"list": tomlList,
+	"env":  func(name string) string { return tomlString("${" + name + ":}") },
+}).Parse(`# =============================================================================
+# Nexbot Configuration
+# =============================================================================
+# Создано командой nexbot init. Секреты хранятся в .env
+# (~/.config/nexbot/.env), а не в этом файле.
+#
+# Подробная документация: docs/CONFIGURATION.md
+# Проверка: nexbot config validate
+# =============================================================================
+
+# -----------------------------------------------------------------------------
+# Workspace Settings
+# -----------------------------------------------------------------------------
+[workspace]
+# Путь к директории workspace (bootstrap файлы, сессии, логи)
+path = {{str .Workspace}}
+
+# Максимальное количество символов для чтения из bootstrap файлов
+bootstrap_max_chars = 20000
+
+# -----------------------------------------------------------------------------
+# Agent Settings
+# -----------------------------------------------------------------------------
+[agent]
+# LLM провайдер: "zai" или "openai"
+provider = {{str .Provider}}
+
+# Модель по умолчанию для запросов к LLM
+model = {{str .Model}}
+
+# Максимум токенов в ответе LLM
+max_tokens = 8192
+
+# Максимум итераций tool calling на запрос
+max_iterations = 20
+
+# Temperature для сэмплинга LLM (0.0 - 2.0)
+temperature = 0.7
+
+# Таймаут обработки запроса агента (включая tool calls)
+timeout_seconds = 60
+
+# -----------------------------------------------------------------------------
+# LLM Provider Settings
+# -----------------------------------------------------------------------------
+{{- if eq .Provider "zai"}}
+[llm.zai]
+# API ключ Z.ai из переменной окружения (https://z.ai)
+api_key = {{env .APIKeyEnv}}
+
+# Base URL Z.ai API
+base_url = "https://api.z.ai/api/coding/paas/v4"
+
+# Таймаут HTTP запросов к Z.ai API
+timeout_seconds = 60
+{{- else}}
+[llm.openai]
+# API ключ OpenAI из переменной окружения
+api_key = {{env .APIKeyEnv}}
+
+# Base URL OpenAI API
+base_url = "https://api.openai.com/v1"
+{{- end}}
+
+# -----------------------------------------------------------------------------
+# Telegram Channel Settings
+# -----------------------------------------------------------------------------
+[channels.telegram]
+# Включить канал Telegram
+enabled = {{.Telegram}}
+
+# Токен Telegram бота (получите у @BotFather) из переменной окружения
+token = {{env .TokenEnv}}
+
+# Разрешённые Telegram user ID (пусто = разрешить всем)
+# Узнайте свой ID через @userinfobot
+allowed_users = {{list .AllowedUsers}}
+
+# Разрешённые Telegram chat ID (пусто = разрешить всем)
+allowed_chats = []
+
+# Parse mode по умолчанию: "markdown", "html", "none"
+default_parse_mode = "markdown"
+
+# -----------------------------------------------------------------------------
+# Tools Settings
+# -----------------------------------------------------------------------------
+[tools.file]
+# Включить операции с файлами (read_file, write_file, list_dir, delete_file)
+enabled = true
+
+# Директории вне workspace, где разрешены операции с файлами
+whitelist_dirs = []
+
+# Директории только для чтения
+read_only_dirs = ["/etc", "/usr", "/bin"]
+
+[tools.shell]
+# Включить выполнение shell команд
+enabled = {{.Shell}}
+
+# Разрешённые команды (пусто = все, кроме запрещённых)
+allowed_commands = ["ls", "cat", "grep", "find", "pwd", "echo", "date", "git"]
+
+# Запрещённые команды (заблокированы независимо от allowed)
+deny_commands = ["rm", "rmdir", "dd", "mkfs", "fdisk", "shutdown", "reboot"]
+
+# Команды, требующие подтверждения пользователя ("*" — любые подкоманды)
+ask_commands = ["git *"]
+
+# Таймаут выполнения shell команды (в секундах)
+timeout_seconds = 30
+
+[tools.fetch]
+# Включить загрузку контента по URL (запросы в частные сети блокируются)
+enabled = true
+
+# Таймаут HTTP запроса (в секундах)
+timeout_seconds = 30
+
+[tools.process]
+# Фоновые процессы (команды проверяются по спискам [tools.shell])
+enabled = false
+
+[tools.plot]
+# Графики в PNG, отправляются фото
+enabled = false
+
+# -----------------------------------------------------------------------------
+# Cron Scheduler Settings
+# -----------------------------------------------------------------------------
+[cron]
+# Включить cron планировщик (напоминания и регулярные задачи)
+enabled = true
+
+# Часовой пояс для планирования задач
+timezone = {{str .Timezone}}
+
+# -----------------------------------------------------------------------------
+# Logging Settings
+# -----------------------------------------------------------------------------
+[logging]
+# Уровень логирования: "debug", "info", "warn", "error"
+level = "info"
+
+# Формат логов: "json" или "text"
+format = "text"
+
+# Вывод логов: "stdout", "stderr", или путь к файлу
+output = "stdout"
+
+# Остальные секции (subagent, cleanup, guardrails, moderation, stt, ...)
+# описаны в docs/CONFIGURATION.md и config.example.toml.
+`))
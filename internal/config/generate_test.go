@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGenerate tests that generated configs load and pass validation
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name string
+		opts InitOptions
+		want []string
+	}{
+		{
+			name: "zai with telegram",
+			opts: InitOptions{Provider: "zai", Telegram: true, AllowedUsers: []string{"123456789"}, Shell: true, Timezone: "Europe/Moscow"},
+			want: []string{`model = "glm-4.7-flash"`, "[llm.zai]", `api_key = "${ZAI_API_KEY:}"`, `allowed_users = ["123456789"]`, `timezone = "Europe/Moscow"`},
+		},
+		{
+			name: "openai without telegram",
+			opts: InitOptions{Provider: "openai", Model: "gpt-4.1", Workspace: `C:\nexbot "bot"`},
+			want: []string{`model = "gpt-4.1"`, "[llm.openai]", `path = "C:\\nexbot \"bot\""`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := Generate(tt.opts)
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(content, want) {
+					t.Errorf("Generate() should contain %q", want)
+				}
+			}
+
+			again, _ := Generate(tt.opts)
+			if again != content {
+				t.Error("Generate() should be deterministic")
+			}
+
+			t.Setenv(EnvZAIAPIKey, "zai-test-key-valid")
+			t.Setenv(EnvOpenAIAPIKey, "sk-test-key-valid")
+			t.Setenv(EnvTelegramBotToken, "123456789:ABCdefGHIjklMNOpqrsTUVwxyz")
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() error: %v", err)
+			}
+			if cfg.Channels.Telegram.Enabled != tt.opts.Telegram || cfg.Tools.Shell.Enabled != tt.opts.Shell {
+				t.Errorf("Load() telegram/shell = %v/%v, want %v/%v",
+					cfg.Channels.Telegram.Enabled, cfg.Tools.Shell.Enabled, tt.opts.Telegram, tt.opts.Shell)
+			}
+			if errs := cfg.Validate(); len(errs) > 0 {
+				t.Errorf("Validate() errors: %v", errs)
+			}
+		})
+	}
+
+	if _, err := Generate(InitOptions{Provider: "anthropic"}); err == nil {
+		t.Error("Generate() should reject an unknown provider")
+	}
+}
+
+func TestSetEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nexbot", ".env")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("# secrets\nZAI_API_KEY=old\nOTHER=keep\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := SetEnv(path, map[string]string{EnvZAIAPIKey: "new", EnvTelegramBotToken: "1:token"})
+	if err != nil {
+		t.Fatalf("SetEnv() error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# secrets\nZAI_API_KEY=new\nOTHER=keep\nTELEGRAM_BOT_TOKEN=1:token\n"
+	if string(data) != want {
+		t.Errorf("SetEnv() wrote %q, want %q", data, want)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("env file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ProfileEnv — переменная окружения с именем профиля конфигурации
+// (флаг --profile задаёт её для команд nexbot)
+const ProfileEnv = "NEXBOT_PROFILE"
+
+// maxIncludeDepth ограничивает вложенность подключаемых файлов
+const maxIncludeDepth = 5
+
+// Ключи верхнего уровня, которые управляют сборкой конфигурации и не
+// попадают в Config
+const (
+	includeKey  = "include"
+	profilesKey = "profiles"
+)
+
+// readTree читает файл конфигурации вместе с подключёнными файлами
+// (include = ["tools.toml"]) и возвращает объединённую таблицу. Подключённые
+// файлы объединяются по порядку, значения самого файла переопределяют их.
+// Пути подключений — относительно файла, который их подключает; stack
+// содержит открытые файлы для обнаружения циклов.
+func readTree(path string, stack []string) (map[string]any, error) {
+	for _, parent := range stack {
+		if parent == path {
+			return nil, fmt.Errorf("config include cycle: %s", path)
+		}
+	}
+	if len(stack) > maxIncludeDepth {
+		return nil, fmt.Errorf("config include %s is nested deeper than %d levels", path, maxIncludeDepth)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if len(stack) > 0 {
+			return nil, fmt.Errorf("failed to read included config file %s: %w", path, err)
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var table map[string]any
+	if err := toml.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	includes, err := includePaths(table[includeKey], filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	delete(table, includeKey)
+
+	tree := make(map[string]any)
+	for _, include := range includes {
+		included, err := readTree(include, append(stack, path))
+		if err != nil {
+			return nil, err
+		}
+		mergeTables(tree, included)
+	}
+	mergeTables(tree, table)
+	return tree, nil
+}
+
+// includePaths возвращает пути подключаемых файлов из значения include
+func includePaths(value any, dir string) ([]string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("include must be a list of file paths")
+	}
+
+	paths := make([]string, len(list))
+	for i, item := range list {
+		path, ok := item.(string)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("include must be a list of file paths")
+		}
+		path = expandHome(path)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		paths[i] = path
+	}
+	return paths, nil
+}
+
+// applyProfile накладывает таблицу [profiles.<name>] на конфигурацию и
+// удаляет описания профилей. Пустое имя только удаляет профили.
+func applyProfile(tree map[string]any, name string) error {
+	profiles, _ := tree[profilesKey].(map[string]any)
+	delete(tree, profilesKey)
+	if name == "" {
+		return nil
+	}
+
+	profile, ok := profiles[name].(map[string]any)
+	if !ok {
+		available := make([]string, 0, len(profiles))
+		for profileName := range profiles {
+			available = append(available, profileName)
+		}
+		sort.Strings(available)
+		return fmt.Errorf("config profile %q not found (available: %v)", name, available)
+	}
+	mergeTables(tree, profile)
+	return nil
+}
+
+// mergeTables переносит значения src в dst: вложенные таблицы объединяются по
+// ключам, остальные значения (в том числе списки и [[массивы таблиц]])
+// заменяются целиком.
+func mergeTables(dst, src map[string]any) {
+	for key, value := range src {
+		srcTable, srcIsTable := value.(map[string]any)
+		dstTable, dstIsTable := dst[key].(map[string]any)
+		if srcIsTable && dstIsTable {
+			mergeTables(dstTable, srcTable)
+			continue
+		}
+		if srcIsTable {
+			// Копия, чтобы профиль не менял исходную таблицу
+			copied := make(map[string]any, len(srcTable))
+			mergeTables(copied, srcTable)
+			value = copied
+		}
+		dst[key] = value
+	}
+}
+
+// encodeTree кодирует объединённую таблицу обратно в TOML для разбора в Config
+func encodeTree(tree map[string]any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(tree); err != nil {
+		return nil, fmt.Errorf("failed to merge config files: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfigFiles writes config files into a temporary directory and returns it
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoad_IncludesAndProfiles(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"config.toml": `include = ["conf.d/tools.toml", "conf.d/channels.toml"]
+
+[agent]
+provider = "zai"
+model = "glm-4.7-flash"
+
+[tools.shell]
+timeout_seconds = 60
+
+[profiles.dev.logging]
+level = "debug"
+
+[profiles.dev.channels.telegram]
+enabled = false
+`,
+		"conf.d/tools.toml": `include = ["shell.toml"]
+
+[tools.shell]
+enabled = true
+timeout_seconds = 10
+
+[tools.fetch]
+enabled = true
+`,
+		"conf.d/shell.toml": `[tools.shell]
+allowed_commands = ["ls", "git"]
+`,
+		"conf.d/channels.toml": `[channels.telegram]
+enabled = true
+allowed_users = ["1", "2"]
+
+[profiles.prod.channels.telegram]
+allowed_users = ["1"]
+`,
+	})
+	path := filepath.Join(dir, "config.toml")
+
+	cfg, err := LoadProfile(path, "")
+	if err != nil {
+		t.Fatalf("LoadProfile() error: %v", err)
+	}
+	// The including file overrides included values, tables merge by key
+	if !cfg.Tools.Shell.Enabled || cfg.Tools.Shell.TimeoutSeconds != 60 || len(cfg.Tools.Shell.AllowedCommands) != 2 || !cfg.Tools.Fetch.Enabled {
+		t.Errorf("merged tools = %+v / fetch %v", cfg.Tools.Shell, cfg.Tools.Fetch.Enabled)
+	}
+	if !cfg.Channels.Telegram.Enabled || cfg.Logging.Level != "info" || cfg.Profile() != "" {
+		t.Errorf("without profile: telegram %v, level %s, profile %q", cfg.Channels.Telegram.Enabled, cfg.Logging.Level, cfg.Profile())
+	}
+	if table, ok := cfg.ToolTable("shell"); !ok || table["enabled"] != true {
+		t.Errorf("ToolTable(shell) = %v, want the merged table", table)
+	}
+
+	dev, err := LoadProfile(path, "dev")
+	if err != nil {
+		t.Fatalf("LoadProfile(dev) error: %v", err)
+	}
+	if dev.Channels.Telegram.Enabled || dev.Logging.Level != "debug" || len(dev.Channels.Telegram.AllowedUsers) != 2 || dev.Profile() != "dev" {
+		t.Errorf("dev profile: telegram %v, level %s, users %v", dev.Channels.Telegram.Enabled, dev.Logging.Level, dev.Channels.Telegram.AllowedUsers)
+	}
+
+	// Profiles can be defined in included files; lists are replaced
+	t.Setenv(ProfileEnv, "prod")
+	prod, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() with %s error: %v", ProfileEnv, err)
+	}
+	if len(prod.Channels.Telegram.AllowedUsers) != 1 || !prod.Channels.Telegram.Enabled {
+		t.Errorf("prod profile users = %v", prod.Channels.Telegram.AllowedUsers)
+	}
+
+	if _, err := LoadProfile(path, "staging"); err == nil || !strings.Contains(err.Error(), `profile "staging" not found (available: [dev prod])`) {
+		t.Errorf("LoadProfile(staging) error = %v", err)
+	}
+}
+
+func TestLoad_InvalidIncludes(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "missing file",
+			files:   map[string]string{"config.toml": `include = ["missing.toml"]`},
+			wantErr: "failed to read included config file",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"config.toml": `include = ["a.toml"]`,
+				"a.toml":      `include = ["config.toml"]`,
+			},
+			wantErr: "config include cycle",
+		},
+		{
+			name:    "not a list",
+			files:   map[string]string{"config.toml": `include = "tools.toml"`},
+			wantErr: "include must be a list of file paths",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigFiles(t, tt.files)
+			_, err := LoadProfile(filepath.Join(dir, "config.toml"), "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadProfile() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
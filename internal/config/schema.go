@@ -11,13 +11,35 @@
 //   - [tools]: Tool configurations (file, shell)
 //   - [cron]: Cron job configuration
 //   - [message_bus]: Message bus capacity settings
+//   - [watcher]: File watches that notify sessions about changes
+//   - [monitor]: Web pages checked for changes that notify sessions
+//   - [feedback]: Feedback collection (/feedback command and reactions)
+//   - [experiment]: Prompt A/B tests compared by feedback
+//   - [guardrails]: Prompt injection guardrails on tool outputs
+//   - [moderation]: Moderation of outbound messages
+//   - [pii]: Masking of personal data in stored history and memory
+//   - [network]: Outbound proxy, CA bundle and DNS overrides
+//   - [throttle]: Per-user limits on inbound messages
+//   - [jobs]: Queue of non-interactive agent jobs
+//   - [forms]: Guided forms for missing tool arguments
+//   - [upload]: Storing user documents in the workspace (/upload)
+//   - [export]: Exporting session transcripts to Obsidian and Notion
+//   - [stt]: Speech-to-text for voice messages and audio files
+//   - [leader]: Leader election for active/passive instance pairs
+//   - [dashboard]: Built-in read-only web dashboard
+//   - [analytics]: Daily conversation statistics
+//   - [[users]]: User registry linking identities across channels
+//   - [templates]: Outbound message templates for notifications
 //
 // Environment variables:
 // Environment variables can be referenced using ${VAR} or ${VAR:default} syntax.
 // For example: api_key = "${ZAI_API_KEY:default_key}"
 package config
 
-import "path/filepath"
+import (
+	"path/filepath"
+	"sort"
+)
 
 // Config represents the main application configuration.
 type Config struct {
@@ -33,22 +55,216 @@ type Config struct {
 	Subagent   SubagentConfig   `toml:"subagent"`
 	MessageBus MessageBusConfig `toml:"message_bus"`
 	Cleanup    CleanupConfig    `toml:"cleanup"`
+	Watcher    WatcherConfig    `toml:"watcher"`
+	Monitor    MonitorConfig    `toml:"monitor"`
+	Feedback   FeedbackConfig   `toml:"feedback"`
+	Experiment ExperimentConfig `toml:"experiment"`
+	Guardrails GuardrailsConfig `toml:"guardrails"`
+	Moderation ModerationConfig `toml:"moderation"`
+	PII        PIIConfig        `toml:"pii"`
+	Network    NetworkConfig    `toml:"network"`
+	Throttle   ThrottleConfig   `toml:"throttle"`
+	Jobs       JobsConfig       `toml:"jobs"`
+	Digest     DigestConfig     `toml:"digest"`
+	Forms      FormsConfig      `toml:"forms"`
+	Onboarding OnboardingConfig `toml:"onboarding"`
+	Invites    InvitesConfig    `toml:"invites"`
+	Upload     UploadConfig     `toml:"upload"`
+	Media      MediaConfig      `toml:"media"`
+	Export     ExportConfig     `toml:"export"`
+	STT        STTConfig        `toml:"stt"`
+	Structured StructuredConfig `toml:"structured"`
+	Leader     LeaderConfig     `toml:"leader"`
+	Dashboard  DashboardConfig  `toml:"dashboard"`
+	Analytics  AnalyticsConfig  `toml:"analytics"`
+	Alerts     AlertsConfig     `toml:"alerts"`
+	Update     UpdateConfig     `toml:"update"`
+	Users      []UserConfig     `toml:"users"`
+
+	Templates map[string]MessageTemplateConfig `toml:"templates"`
+
+	// tools — таблицы [tools.*] в том виде, в котором они записаны в файле,
+	// для проверки по схемам инструментов
+	tools map[string]any
+
+	// profile — профиль, с которым загружена конфигурация
+	profile string
 }
 
 // WorkspaceConfig представляет конфигурацию workspace
 type WorkspaceConfig struct {
-	Path              string `toml:"path"`
-	BootstrapMaxChars int    `toml:"bootstrap_max_chars"`
+	Path              string             `toml:"path"`
+	BootstrapMaxChars int                `toml:"bootstrap_max_chars"`
+	Git               WorkspaceGitConfig `toml:"git"`
+}
+
+// WorkspaceGitConfig представляет git-репозиторий workspace: каждый ход
+// агента, изменивший файлы, завершается коммитом с correlation ID запроса
+type WorkspaceGitConfig struct {
+	Enabled     bool     `toml:"enabled"`
+	AuthorName  string   `toml:"author_name"`
+	AuthorEmail string   `toml:"author_email"`
+	Exclude     []string `toml:"exclude"` // Шаблоны .gitignore для путей, которые не коммитятся
 }
 
 // AgentConfig представляет конфигурацию agent
 type AgentConfig struct {
-	Provider       string  `toml:"provider"`
-	Model          string  `toml:"model"`
-	MaxTokens      int     `toml:"max_tokens"`
-	MaxIterations  int     `toml:"max_iterations"`
-	Temperature    float64 `toml:"temperature"`
-	TimeoutSeconds int     `toml:"timeout_seconds"`
+	Provider           string              `toml:"provider"`
+	Model              string              `toml:"model"`
+	MaxTokens          int                 `toml:"max_tokens"`
+	MaxIterations      int                 `toml:"max_iterations"`
+	BudgetWarning      int                 `toml:"budget_warning"`
+	ToolRepairAttempts int                 `toml:"tool_repair_attempts"` // Попыток исправить некорректные аргументы инструментов (-1 отключает)
+	ToolBudgets        map[string]int      `toml:"tool_budgets"`
+	Temperature        float64             `toml:"temperature"`
+	Sampling           SamplingConfig      `toml:"sampling"`
+	TimeoutSeconds     int                 `toml:"timeout_seconds"`
+	TitleAfterTurns    int                 `toml:"title_after_turns"`
+	PromptCache        bool                `toml:"prompt_cache"`
+	ToolProtocol       string              `toml:"tool_protocol"` // auto, native или text
+	DryRun             bool                `toml:"dry_run"`       // Изменяющие инструменты только сообщают, что бы они сделали
+	Routing            RoutingConfig       `toml:"routing"`
+	Debate             DebateConfig        `toml:"debate"`
+	Planning           PlanningConfig      `toml:"planning"`
+	Verify             VerifyConfig        `toml:"verify"`
+	Prompt             PromptConfig        `toml:"prompt"`
+	ToolSelection      ToolSelectionConfig `toml:"tool_selection"`
+	ToolOutput         ToolOutputConfig    `toml:"tool_output"`
+	Projects           ProjectsConfig      `toml:"projects"`
+	Approval           ApprovalConfig      `toml:"approval"`
+	Rollback           RollbackConfig      `toml:"rollback"`
+	Deterministic      DeterministicConfig `toml:"deterministic"`
+	Capture            CaptureConfig       `toml:"capture"`
+}
+
+// SamplingConfig представляет дополнительные параметры сэмплинга LLM.
+// Нулевые значения не передаются провайдеру
+type SamplingConfig struct {
+	TopP             float64  `toml:"top_p"`
+	FrequencyPenalty float64  `toml:"frequency_penalty"`
+	PresencePenalty  float64  `toml:"presence_penalty"`
+	Stop             []string `toml:"stop"`
+	Seed             int      `toml:"seed"`
+}
+
+// RoutingConfig представляет маршрутизацию запросов между дешёвой и сильной
+// (agent.model) моделью
+type RoutingConfig struct {
+	Enabled                bool     `toml:"enabled"`
+	CheapModel             string   `toml:"cheap_model"`
+	MaxCheapChars          int      `toml:"max_cheap_chars"`
+	MaxCheapToolIterations int      `toml:"max_cheap_tool_iterations"`
+	ComplexTools           []string `toml:"complex_tools"`
+	Keywords               []string `toml:"keywords"`
+}
+
+// DebateConfig представляет режим дебатов: персоны агента (proposer и critic)
+// обсуждают ответ, пока критики не согласятся или не кончится бюджет
+type DebateConfig struct {
+	Enabled      bool                  `toml:"enabled"`
+	Auto         bool                  `toml:"auto"`           // Запускать дебаты по эвристикам сложности без /debate
+	MaxTurns     int                   `toml:"max_turns"`      // Ответов персон за одни дебаты
+	MaxTokens    int                   `toml:"max_tokens"`     // Бюджет токенов на одни дебаты
+	AutoMinChars int                   `toml:"auto_min_chars"` // Вопросы длиннее обсуждаются автоматически
+	Keywords     []string              `toml:"keywords"`       // Фразы, запускающие дебаты автоматически
+	Personas     []DebatePersonaConfig `toml:"personas"`
+}
+
+// DebatePersonaConfig представляет участника дебатов
+type DebatePersonaConfig struct {
+	Name   string `toml:"name"`
+	Role   string `toml:"role"`   // proposer или critic
+	Prompt string `toml:"prompt"` // Характер персоны
+	Model  string `toml:"model"`  // Модель персоны (по умолчанию agent.model)
+}
+
+// PlanningConfig представляет фазу планирования: агент сначала составляет план
+// шагов, затем выполняет их с бюджетом инструментов на каждый шаг
+type PlanningConfig struct {
+	Enabled        bool `toml:"enabled"`
+	MinChars       int  `toml:"min_chars"`       // Запросы короче не планируются
+	MaxSteps       int  `toml:"max_steps"`       // Максимум шагов в плане
+	StepIterations int  `toml:"step_iterations"` // Максимум раундов инструментов на шаг
+}
+
+// VerifyConfig представляет проверку ответов: перед отправкой ответ и вывод
+// инструментов проверяются моделью на неподтверждённые утверждения
+type VerifyConfig struct {
+	Enabled          bool   `toml:"enabled"`
+	Model            string `toml:"model"`              // Модель проверяющего (по умолчанию модель запроса)
+	Mode             string `toml:"mode"`               // correct или note
+	MinChars         int    `toml:"min_chars"`          // Ответы короче не проверяются
+	MaxEvidenceChars int    `toml:"max_evidence_chars"` // Символов вывода инструментов для проверяющего
+	Always           bool   `toml:"always"`             // Проверять ответы без вызовов инструментов
+}
+
+// ToolSelectionConfig представляет отбор инструментов: с запросом к LLM
+// отправляются только схемы инструментов, подходящих к запросу
+type ToolSelectionConfig struct {
+	Enabled bool     `toml:"enabled"`
+	TopK    int      `toml:"top_k"` // Число подходящих инструментов помимо основных
+	Core    []string `toml:"core"`  // Инструменты, отправляемые всегда
+}
+
+// ToolOutputConfig представляет сокращение вывода инструментов перед отправкой
+// в LLM: повторяющиеся строки схлопываются, от длинного вывода остаются
+// начало и конец
+type ToolOutputConfig struct {
+	Enabled   bool           `toml:"enabled"`
+	MaxTokens int            `toml:"max_tokens"` // Бюджет токенов на результат инструмента
+	Tools     map[string]int `toml:"tools"`      // Бюджеты отдельных инструментов (0 — без сокращения)
+}
+
+// ProjectsConfig представляет проекты: чаты привязываются к проекту командой
+// /project, его сводка (цели, решения, файлы) ведётся автоматически и
+// добавляется в системный промпт новых сессий
+type ProjectsConfig struct {
+	Enabled         bool `toml:"enabled"`
+	BriefAfterTurns int  `toml:"brief_after_turns"` // Обновлять сводку после N сообщений пользователя (отрицательное — не обновлять)
+	MaxBriefChars   int  `toml:"max_brief_chars"`   // Максимальная длина сводки
+}
+
+// ApprovalConfig представляет подтверждение разрушительных действий: вызовы,
+// которые удаляют или перезаписывают данные, выполняются только после того,
+// как пользователь одобрит план кнопкой
+type ApprovalConfig struct {
+	Enabled    bool     `toml:"enabled"`
+	Classes    []string `toml:"classes"`     // Классы инструментов, требующие подтверждения (file, shell)
+	TTLMinutes int      `toml:"ttl_minutes"` // Время жизни неотвеченного запроса
+}
+
+// RollbackConfig представляет откат изменений файлов: перед тем как файловые
+// инструменты изменят файлы, их состояние сохраняется, и команда /rollback
+// отменяет всё, что агент изменил за ход
+type RollbackConfig struct {
+	Enabled       bool `toml:"enabled"`
+	MaxTurns      int  `toml:"max_turns"`        // Сколько последних ходов можно откатить
+	MaxFileSizeMB int  `toml:"max_file_size_mb"` // Файлы больше не сохраняются и не восстанавливаются
+}
+
+// DeterministicConfig представляет режим воспроизводимых запусков: жадное
+// декодирование с фиксированным seed и запись или воспроизведение ответов LLM
+// и результатов инструментов
+type DeterministicConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Seed    int    `toml:"seed"`
+	Mode    string `toml:"mode"` // record, replay или пусто (только seed и temperature 0)
+	Dir     string `toml:"dir"`  // Каталог записей относительно workspace
+}
+
+// CaptureConfig представляет сохранение диагностики ответов LLM (причина
+// завершения, исходные tool calls, logprobs) для разбора некорректных вызовов
+// инструментов
+type CaptureConfig struct {
+	Enabled    bool `toml:"enabled"`
+	All        bool `toml:"all"`         // Сохранять все ответы, а не только некорректные
+	Logprobs   bool `toml:"logprobs"`    // Запрашивать logprobs токенов (если провайдер поддерживает)
+	MaxEntries int  `toml:"max_entries"` // Ответов на сессию
+}
+
+// PromptConfig представляет сборку системного промпта из bootstrap файлов
+type PromptConfig struct {
+	Variables map[string]string `toml:"variables"` // Пользовательские переменные шаблонов, например USER_NAME
 }
 
 // LLMConfig представляет конфигурацию LLM провайдера
@@ -65,6 +281,10 @@ type ZAIConfig struct {
 	APIKey         string `toml:"api_key"`
 	BaseURL        string `toml:"base_url"`
 	TimeoutSeconds int    `toml:"timeout_seconds"`
+
+	// Ограничения запросов с этим API ключом; лишние запросы ждут в очереди (0 — без ограничения)
+	MaxConcurrent     int `toml:"max_concurrent"`
+	RequestsPerMinute int `toml:"requests_per_minute"`
 }
 
 // LoggingConfig представляет конфигурацию логирования
@@ -90,6 +310,7 @@ type TelegramConfig struct {
 	Enabled               bool     `toml:"enabled"`
 	Token                 string   `toml:"token"`
 	AllowedUsers          []string `toml:"allowed_users"`
+	AdminUsers            []string `toml:"admin_users"` // Telegram user ID администраторов бота (команды /admin)
 	AllowedChats          []string `toml:"allowed_chats"`
 	SendTimeoutSeconds    int      `toml:"send_timeout_seconds"`
 	EnableInlineUpdates   bool     `toml:"enable_inline_updates"`
@@ -97,13 +318,44 @@ type TelegramConfig struct {
 	EnableInlineKeyboard  bool     `toml:"enable_inline_keyboard"`
 	QuietMode             bool     `toml:"quiet_mode"`
 	AnswerCallbackTimeout int      `toml:"answer_callback_timeout"`
+	StreamAnswers         bool     `toml:"stream_answers"`
+	StreamIntervalMS      int      `toml:"stream_interval_ms"`
+
+	// GroupThreads отвечает в группах цепочкой ответов на исходное сообщение
+	// и ведёт отдельную сессию для каждой цепочки
+	GroupThreads bool `toml:"group_threads"`
+
+	// Переподключение при потере связи с Telegram
+	ReconnectMaxBackoffSeconds  int `toml:"reconnect_max_backoff_seconds"`  // Максимальная пауза между попытками
+	NotifyReconnectAfterSeconds int `toml:"notify_reconnect_after_seconds"` // Уведомить allowed_users о восстановлении после такого простоя (0 — не уведомлять)
 }
 
 // ToolsConfig представляет конфигурацию tools
 type ToolsConfig struct {
-	File  FileToolConfig  `toml:"file"`
-	Shell ShellToolConfig `toml:"shell"`
-	Fetch FetchToolConfig `toml:"fetch"`
+	File    FileToolConfig    `toml:"file"`
+	Shell   ShellToolConfig   `toml:"shell"`
+	Fetch   FetchToolConfig   `toml:"fetch"`
+	Process ProcessToolConfig `toml:"process"`
+	Plot    PlotToolConfig    `toml:"plot"`
+
+	// ChatAdmin включает инструменты закрепления сообщений и смены названия чата
+	ChatAdmin ChatAdminToolConfig `toml:"chat_admin"`
+
+	// Namespaces включает и отключает группы инструментов по пространству
+	// имён (fs, net, sys, msg, agent); не указанные пространства включены
+	Namespaces map[string]bool `toml:"namespaces"`
+}
+
+// DisabledNamespaces возвращает отключённые пространства имён инструментов
+func (c *ToolsConfig) DisabledNamespaces() []string {
+	var disabled []string
+	for namespace, enabled := range c.Namespaces {
+		if !enabled {
+			disabled = append(disabled, namespace)
+		}
+	}
+	sort.Strings(disabled)
+	return disabled
 }
 
 // FileToolConfig представляет конфигурацию file tool
@@ -112,6 +364,10 @@ type FileToolConfig struct {
 	WhitelistDirs        []string `toml:"whitelist_dirs"`
 	ReadOnlyDirs         []string `toml:"read_only_dirs"`
 	ValidateSkillContent bool     `toml:"validate_skill_content"`
+	// DiffSummary добавляет к ответу diff изменений, сделанных write_file
+	DiffSummary bool `toml:"diff_summary"`
+	// DiffSummaryMaxLines — максимум строк diff в ответе (по умолчанию 40)
+	DiffSummaryMaxLines int `toml:"diff_summary_max_lines"`
 }
 
 // ShellToolConfig представляет конфигурацию shell tool
@@ -121,14 +377,51 @@ type ShellToolConfig struct {
 	DenyCommands    []string `toml:"deny_commands"`
 	AskCommands     []string `toml:"ask_commands"`
 	TimeoutSeconds  int      `toml:"timeout_seconds"`
+	// Shell — интерпретатор команд: direct (без shell), cmd, powershell, pwsh.
+	// Пусто — cmd на Windows, direct на остальных системах
+	Shell string `toml:"shell"`
 }
 
 // FetchToolConfig представляет конфигурацию fetch tool
 type FetchToolConfig struct {
-	Enabled         bool   `toml:"enabled"`
-	TimeoutSeconds  int    `toml:"timeout_seconds"`
-	MaxResponseSize int64  `toml:"max_response_size"`
-	UserAgent       string `toml:"user_agent"`
+	Enabled              bool     `toml:"enabled"`
+	TimeoutSeconds       int      `toml:"timeout_seconds"`
+	MaxResponseSize      int64    `toml:"max_response_size"`
+	UserAgent            string   `toml:"user_agent"`
+	AllowPrivateNetworks bool     `toml:"allow_private_networks"`
+	AllowedDomains       []string `toml:"allowed_domains"`
+	DeniedDomains        []string `toml:"denied_domains"`
+
+	// RespectRobots запрещает загрузку страниц, закрытых в robots.txt сайта
+	RespectRobots bool `toml:"respect_robots"`
+	// MinIntervalMs — минимальный интервал между запросами к одному домену
+	// (0 — без ограничения); Crawl-delay из robots.txt увеличивает его
+	MinIntervalMs int `toml:"min_interval_ms"`
+	// CacheDir — директория кэша ответов (относительно workspace); пусто —
+	// кэш отключён. Закэшированные ответы перепроверяются по ETag и Last-Modified
+	CacheDir string `toml:"cache_dir"`
+}
+
+// ProcessToolConfig представляет конфигурацию process tool (фоновые процессы).
+// Команды проверяются по спискам allowed/deny/ask из [tools.shell].
+type ProcessToolConfig struct {
+	Enabled            bool `toml:"enabled"`
+	MaxJobs            int  `toml:"max_jobs"`
+	OutputLines        int  `toml:"output_lines"`
+	StopTimeoutSeconds int  `toml:"stop_timeout_seconds"`
+}
+
+// PlotToolConfig представляет конфигурацию plot tool (графики в PNG)
+type PlotToolConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Dir     string `toml:"dir"` // Директория для графиков (относительно workspace)
+}
+
+// ChatAdminToolConfig представляет конфигурацию инструментов администрирования
+// чатов (pin_message, unpin_message, set_chat_title); бот должен быть
+// администратором чата с нужными правами
+type ChatAdminToolConfig struct {
+	Enabled bool `toml:"enabled"`
 }
 
 const (
@@ -138,8 +431,19 @@ const (
 
 // CronConfig представляет конфигурацию cron (v0.2)
 type CronConfig struct {
-	Enabled  bool   `toml:"enabled"`
-	Timezone string `toml:"timezone"`
+	Enabled   bool            `toml:"enabled"`
+	Timezone  string          `toml:"timezone"`
+	Followups FollowupsConfig `toml:"followups"`
+}
+
+// FollowupsConfig представляет проактивные напоминания агента: инструмент
+// schedule_followup планирует разовую задачу cron, по которой агент проверяет
+// незавершённое дело и сам пишет пользователю. Нужно согласие пользователя
+// (/followups on)
+type FollowupsConfig struct {
+	Enabled       bool `toml:"enabled"`
+	MaxPending    int  `toml:"max_pending"`     // Максимум ожидающих напоминаний на чат
+	MaxDelayHours int  `toml:"max_delay_hours"` // Насколько далеко вперёд можно запланировать напоминание
 }
 
 // JobsDir возвращает путь к директории для хранения cron jobs
@@ -177,9 +481,433 @@ type CleanupConfig struct {
 	SessionTTLDays   int   `toml:"session_ttl_days"`
 	MaxSessionSizeMB int64 `toml:"max_session_size_mb"`
 	KeepActiveDays   int   `toml:"keep_active_days"`
+
+	// Retention policies
+	MaxSessions    int      `toml:"max_sessions"`
+	MaxMediaSizeMB int64    `toml:"max_media_size_mb"`
+	MediaDirs      []string `toml:"media_dirs"`
+	Action         string   `toml:"action"`
+}
+
+// WatcherConfig представляет конфигурацию наблюдения за файлами workspace
+type WatcherConfig struct {
+	Enabled     bool          `toml:"enabled"`
+	DebounceMs  int           `toml:"debounce_ms"`
+	MaxLines    int           `toml:"max_lines"`
+	AllowedDirs []string      `toml:"allowed_dirs"`
+	Watches     []WatchConfig `toml:"watches"`
+}
+
+// WatchConfig представляет одно правило наблюдения
+type WatchConfig struct {
+	ID        string   `toml:"id"`
+	Path      string   `toml:"path"`
+	Patterns  []string `toml:"patterns"`
+	Match     string   `toml:"match"`
+	SessionID string   `toml:"session_id"`
+}
+
+// MonitorConfig представляет конфигурацию отслеживания изменений веб-страниц
+type MonitorConfig struct {
+	Enabled                bool                `toml:"enabled"`
+	DefaultIntervalMinutes int                 `toml:"default_interval_minutes"`
+	MinIntervalMinutes     int                 `toml:"min_interval_minutes"` // Минимальный интервал для мониторов, добавленных агентом
+	MaxDiffLines           int                 `toml:"max_diff_lines"`
+	MaxMonitors            int                 `toml:"max_monitors"` // Максимум мониторов, добавленных агентом
+	Monitors               []PageMonitorConfig `toml:"monitors"`
+}
+
+// PageMonitorConfig представляет одну отслеживаемую страницу
+type PageMonitorConfig struct {
+	ID              string `toml:"id"`
+	URL             string `toml:"url"`
+	Selector        string `toml:"selector"` // CSS-селектор отслеживаемой части страницы
+	IntervalMinutes int    `toml:"interval_minutes"`
+	SessionID       string `toml:"session_id"`
+}
+
+// FeedbackConfig представляет конфигурацию сбора обратной связи
+type FeedbackConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Profile string `toml:"profile"`
+}
+
+// ExperimentConfig представляет A/B тест вариантов промпта: сессии
+// распределяются между вариантами по весам, результаты сравниваются по
+// обратной связи
+type ExperimentConfig struct {
+	Enabled  bool                `toml:"enabled"`
+	Name     string              `toml:"name"`
+	Variants []ExperimentVariant `toml:"variants"`
+}
+
+// ExperimentVariant представляет вариант промпта эксперимента
+type ExperimentVariant struct {
+	Name   string `toml:"name"`
+	Weight int    `toml:"weight"` // Доля сессий относительно других вариантов (по умолчанию 1)
+	Prompt string `toml:"prompt"` // Файл workspace, добавляемый к системному промпту
+
+	// Параметры сэмплинга варианта поверх agent.temperature и agent.sampling
+	Temperature float64        `toml:"temperature"`
+	Sampling    SamplingConfig `toml:"sampling"`
+}
+
+// GuardrailsConfig представляет конфигурацию защиты от prompt injection в выводе инструментов
+type GuardrailsConfig struct {
+	Enabled          bool     `toml:"enabled"`
+	Tools            []string `toml:"tools"`
+	Action           string   `toml:"action"`
+	Classify         bool     `toml:"classify"`
+	ClassifierModel  string   `toml:"classifier_model"`
+	ClassifyMaxChars int      `toml:"classify_max_chars"`
+}
+
+// PIIConfig представляет маскирование персональных данных перед сохранением
+// истории сессий и файлов памяти
+type PIIConfig struct {
+	Enabled    bool     `toml:"enabled"`
+	Categories []string `toml:"categories"` // email, phone, credit_card
+}
+
+// ModerationConfig представляет конфигурацию модерации исходящих сообщений
+type ModerationConfig struct {
+	Enabled        bool                       `toml:"enabled"`
+	Backends       []string                   `toml:"backends"`
+	Action         string                     `toml:"action"`
+	AdminSessionID string                     `toml:"admin_session_id"`
+	BlockMessage   string                     `toml:"block_message"`
+	TimeoutSeconds int                        `toml:"timeout_seconds"`
+	Keywords       []ModerationKeywordConfig  `toml:"keywords"`
+	OpenAI         ModerationOpenAIConfig     `toml:"openai"`
+	Classifier     ModerationClassifierConfig `toml:"classifier"`
+}
+
+// ModerationKeywordConfig представляет правило модерации по ключевым словам
+type ModerationKeywordConfig struct {
+	Category string `toml:"category"`
+	Pattern  string `toml:"pattern"`
+}
+
+// ModerationOpenAIConfig представляет конфигурацию OpenAI moderation API
+type ModerationOpenAIConfig struct {
+	APIKey  string `toml:"api_key"`
+	BaseURL string `toml:"base_url"`
+	Model   string `toml:"model"`
+}
+
+// ModerationClassifierConfig представляет конфигурацию локального классификатора
+type ModerationClassifierConfig struct {
+	URL string `toml:"url"`
+}
+
+// NetworkConfig представляет настройки исходящих соединений (прокси, CA, DNS)
+// для fetch tool, LLM провайдеров и Telegram
+type NetworkConfig struct {
+	Proxy      string            `toml:"proxy"`       // http://, https://, socks5:// или socks5h:// URL
+	NoProxy    []string          `toml:"no_proxy"`    // Хосты и CIDR без прокси
+	CAFile     string            `toml:"ca_file"`     // PEM бандл в дополнение к системным корневым сертификатам
+	DNSServers []string          `toml:"dns_servers"` // DNS серверы вместо системного резолвера
+	Hosts      map[string]string `toml:"hosts"`       // Статические адреса хостов
+}
+
+// ThrottleConfig представляет ограничения входящих сообщений от пользователей
+type ThrottleConfig struct {
+	Enabled           bool                          `toml:"enabled"`
+	MessagesPerMinute int                           `toml:"messages_per_minute"`
+	MaxConcurrent     int                           `toml:"max_concurrent"`
+	MuteThreshold     int                           `toml:"mute_threshold"`
+	MuteMinutes       int                           `toml:"mute_minutes"`
+	SlowDownMessage   string                        `toml:"slow_down_message"`
+	BusyMessage       string                        `toml:"busy_message"`
+	MuteMessage       string                        `toml:"mute_message"`
+	Channels          map[string]ThrottleRuleConfig `toml:"channels"`
+}
+
+// ThrottleRuleConfig представляет ограничения для отдельного канала.
+// 0 — значение из [throttle], отрицательное значение отключает ограничение.
+type ThrottleRuleConfig struct {
+	MessagesPerMinute int `toml:"messages_per_minute"`
+	MaxConcurrent     int `toml:"max_concurrent"`
+	MuteThreshold     int `toml:"mute_threshold"`
+	MuteMinutes       int `toml:"mute_minutes"`
+}
+
+// JobsConfig представляет очередь неинтерактивных задач агента
+// (nexbot job submit), выполняемых в простое
+type JobsConfig struct {
+	Enabled        bool `toml:"enabled"`
+	IdleSeconds    int  `toml:"idle_seconds"`    // Пауза после интерактивных сообщений перед запуском задачи
+	PollSeconds    int  `toml:"poll_seconds"`    // Интервал проверки очереди
+	TimeoutSeconds int  `toml:"timeout_seconds"` // Таймаут выполнения одной задачи
+}
+
+// DigestConfig представляет режим дайджеста: автоматические уведомления
+// (cron, watcher, фоновые задачи) собираются по сессиям и раз в интервал
+// отправляются одним сообщением со сводкой от LLM
+type DigestConfig struct {
+	Enabled         bool     `toml:"enabled"`
+	IntervalMinutes int      `toml:"interval_minutes"` // Интервал отправки дайджеста
+	MaxItems        int      `toml:"max_items"`        // Отправить дайджест раньше, если накопилось столько уведомлений
+	Sources         []string `toml:"sources"`          // Источники: cron, watcher, jobs (пусто — все)
+	Model           string   `toml:"model"`            // Модель для сводки (по умолчанию agent.model)
+}
+
+// FormsConfig представляет пошаговый опрос пользователя о недостающих
+// аргументах инструментов
+type FormsConfig struct {
+	Enabled    bool `toml:"enabled"`
+	TTLMinutes int  `toml:"ttl_minutes"` // Время жизни неотвеченной формы
+}
+
+// OnboardingConfig представляет знакомство с новыми пользователями Telegram:
+// /start с deep link (код приглашения, источник), регистрация в реестре
+// пользователей, приветственные сообщения и вопросы о согласии
+type OnboardingConfig struct {
+	Enabled     bool            `toml:"enabled"`
+	Messages    []string        `toml:"messages"`     // Сообщения новому пользователю по порядку ({name} — имя)
+	InviteCodes []string        `toml:"invite_codes"` // Коды приглашений: пользователи с кодом допускаются вне allowed_users
+	Consents    []ConsentConfig `toml:"consents"`     // Вопросы о согласии, задаются по порядку
+}
+
+// InvitesConfig представляет одноразовые коды приглашений: администратор
+// создаёт код командой /admin invite, новый пользователь активирует его через
+// /start и добавляется в белый список
+type InvitesConfig struct {
+	Enabled  bool `toml:"enabled"`
+	TTLHours int  `toml:"ttl_hours"` // Срок действия неиспользованного кода
+}
+
+// ConsentConfig представляет вопрос о согласии; ответ сохраняется в реестре пользователей
+type ConsentConfig struct {
+	Name     string `toml:"name"`     // Ключ ответа в реестре пользователей
+	Question string `toml:"question"` // Текст вопроса
+	Required bool   `toml:"required"` // Не передавать сообщения агенту без согласия
+}
+
+// UploadConfig представляет сохранение документов пользователя в workspace
+// командой /upload
+type UploadConfig struct {
+	Enabled   bool   `toml:"enabled"`
+	Dir       string `toml:"dir"`         // Директория для файлов без пути (относительно workspace)
+	MaxSizeMB int    `toml:"max_size_mb"` // Максимальный размер файла
+}
+
+// MediaConfig представляет проверку файлов, скачиваемых у пользователя
+// (документы, голосовые сообщения, фото): лимиты размера по типу,
+// определение MIME-типа по содержимому и карантин исполняемых файлов
+type MediaConfig struct {
+	PhotoMaxMB         int              `toml:"photo_max_mb"`        // Максимальный размер фото
+	DocumentMaxMB      int              `toml:"document_max_mb"`     // Максимальный размер документа
+	VoiceMaxMB         int              `toml:"voice_max_mb"`        // Максимальный размер голосового сообщения
+	AudioMaxMB         int              `toml:"audio_max_mb"`        // Максимальный размер аудио
+	VideoMaxMB         int              `toml:"video_max_mb"`        // Максимальный размер видео
+	AllowedTypes       []string         `toml:"allowed_types"`       // Разрешённые MIME-типы документов ("image/*" — группа); пусто — все
+	QuarantineDir      string           `toml:"quarantine_dir"`      // Директория карантина (относительно workspace)
+	DiscardExecutables bool             `toml:"discard_executables"` // Отклонять исполняемые файлы без сохранения в карантин
+	ClamAV             ClamAVConfig     `toml:"clamav"`
+	Images             ImagesConfig     `toml:"images"`
+	CodeImages         CodeImagesConfig `toml:"code_images"`
+}
+
+// ImagesConfig представляет обработку изображений перед отправкой фото:
+// уменьшение, сжатие и преобразование формата
+type ImagesConfig struct {
+	MaxWidth    int `toml:"max_width"`    // Максимальная ширина (пиксели)
+	MaxHeight   int `toml:"max_height"`   // Максимальная высота (пиксели)
+	MaxSizeMB   int `toml:"max_size_mb"`  // Максимальный размер файла
+	JPEGQuality int `toml:"jpeg_quality"` // Качество JPEG при пересжатии (1-100)
+}
+
+// CodeImagesConfig представляет отправку длинных блоков кода в ответах
+// картинкой с подсветкой синтаксиса и исходным кодом файлом
+type CodeImagesConfig struct {
+	Enabled  bool    `toml:"enabled"`
+	MinLines int     `toml:"min_lines"` // Блоки кода с таким числом строк и больше отправляются картинкой
+	MaxLines int     `toml:"max_lines"` // Строк на картинке; полный код — в файле
+	Style    string  `toml:"style"`     // Стиль подсветки chroma (monokai, github, dracula и т.п.)
+	FontSize float64 `toml:"font_size"` // Размер шрифта (пункты)
+	Dir      string  `toml:"dir"`       // Директория для картинок и файлов кода (относительно workspace)
+}
+
+// ClamAVConfig представляет проверку файлов пользователя антивирусом ClamAV
+// (демон clamd) до записи в workspace
+type ClamAVConfig struct {
+	Enabled        bool   `toml:"enabled"`
+	Address        string `toml:"address"`         // Сокет clamd: unix:///path или tcp://host:port
+	TimeoutSeconds int    `toml:"timeout_seconds"` // Таймаут проверки одного файла
+	Action         string `toml:"action"`          // Действие при обнаружении: reject, quarantine, warn
+	FailOpen       bool   `toml:"fail_open"`       // Принимать файлы, если clamd недоступен
+}
+
+// ExportConfig представляет экспорт транскриптов сессий во внешние заметки
+type ExportConfig struct {
+	IntervalMinutes int                  `toml:"interval_minutes"` // Периодический экспорт изменённых сессий (0 — только командой /export)
+	Obsidian        ObsidianExportConfig `toml:"obsidian"`
+	Notion          NotionExportConfig   `toml:"notion"`
+}
+
+// ObsidianExportConfig представляет экспорт в папку хранилища Obsidian
+type ObsidianExportConfig struct {
+	Enabled   bool   `toml:"enabled"`
+	VaultPath string `toml:"vault_path"` // Путь к хранилищу Obsidian
+	Folder    string `toml:"folder"`     // Папка внутри хранилища
+}
+
+// NotionExportConfig представляет экспорт страницами Notion через API
+type NotionExportConfig struct {
+	Enabled      bool   `toml:"enabled"`
+	Token        string `toml:"token"`          // Токен интеграции Notion
+	ParentPageID string `toml:"parent_page_id"` // Страница, в которую добавляются транскрипты
+	APIURL       string `toml:"api_url"`        // Адрес API Notion
+}
+
+// LeaderConfig представляет выбор ведущего экземпляра для пары
+// active/passive: Telegram опрашивает только ведущий
+type LeaderConfig struct {
+	Enabled              bool                   `toml:"enabled"`
+	Backend              string                 `toml:"backend"`                // file или kubernetes
+	Identity             string                 `toml:"identity"`               // Имя экземпляра (по умолчанию hostname-pid)
+	LeaseDurationSeconds int                    `toml:"lease_duration_seconds"` // Срок аренды без продления, после которого её забирает резервный экземпляр
+	RenewDeadlineSeconds int                    `toml:"renew_deadline_seconds"` // Ведущий слагает полномочия, если не продлил аренду за это время
+	RetryPeriodSeconds   int                    `toml:"retry_period_seconds"`   // Интервал попыток захвата и продления
+	File                 LeaderFileConfig       `toml:"file"`
+	Kubernetes           LeaderKubernetesConfig `toml:"kubernetes"`
+}
+
+// LeaderFileConfig представляет блокировку файла на общем диске
+type LeaderFileConfig struct {
+	Path string `toml:"path"` // Файл блокировки
+}
+
+// LeaderKubernetesConfig представляет аренду Lease в Kubernetes
+type LeaderKubernetesConfig struct {
+	Namespace string `toml:"namespace"`  // Namespace объекта Lease (по умолчанию namespace пода)
+	LeaseName string `toml:"lease_name"` // Имя объекта Lease
+}
+
+// DashboardConfig представляет встроенную веб-панель состояния бота
+type DashboardConfig struct {
+	Enabled        bool     `toml:"enabled"`
+	Listen         string   `toml:"listen"`          // Адрес HTTP сервера
+	Tokens         []string `toml:"tokens"`          // Bearer токены доступа к API
+	AllowedIPs     []string `toml:"allowed_ips"`     // IP адреса и CIDR клиентов (пусто — все)
+	ActiveMinutes  int      `toml:"active_minutes"`  // Сессии с активностью за это время считаются активными
+	RecentMessages int      `toml:"recent_messages"` // Сообщений сессии и вызовов инструментов на странице
+	LogBacklog     int      `toml:"log_backlog"`     // Последних записей лога, хранимых для /api/logs
+
+	// Правила доступа к отдельным путям; правило пути "/" или "/api" заменяет
+	// правило по умолчанию (tokens и allowed_ips выше)
+	Routes []DashboardRouteConfig `toml:"routes"`
+}
+
+// DashboardRouteConfig представляет правило доступа к путям веб-панели:
+// запрос должен пройти все заданные проверки
+type DashboardRouteConfig struct {
+	Path       string   `toml:"path"`        // Префикс пути ("/api/logs")
+	Secret     string   `toml:"secret"`      // Секрет подписи HMAC-SHA256 в заголовке X-Hub-Signature-256
+	Tokens     []string `toml:"tokens"`      // Bearer токены доступа
+	AllowedIPs []string `toml:"allowed_ips"` // IP адреса и CIDR клиентов (пусто — все)
+}
+
+// AnalyticsConfig представляет ежедневную статистику разговоров
+// (сообщения, пользователи, инструменты, задержки, ошибки)
+type AnalyticsConfig struct {
+	Enabled       bool `toml:"enabled"`
+	RetentionDays int  `toml:"retention_days"`   // Сколько дней хранить статистику
+	SlowToolP95Ms int  `toml:"slow_tool_p95_ms"` // Порог p95 длительности вызовов инструмента для предупреждения, 0 — отключено
+}
+
+// AlertsConfig представляет уведомления администраторов об эксплуатационных
+// ошибках: сбоях LLM провайдера, падениях инструментов и переполнении очередей
+type AlertsConfig struct {
+	Enabled               bool   `toml:"enabled"`
+	SessionID             string `toml:"session_id"`              // Чат администраторов ("channel:chat_id")
+	CooldownSeconds       int    `toml:"cooldown_seconds"`        // Повторы того же уведомления за это время не отправляются
+	MaxPerHour            int    `toml:"max_per_hour"`            // Максимум уведомлений в час
+	QueueThresholdPercent int    `toml:"queue_threshold_percent"` // Заполненность очереди шины, при которой отправляется уведомление
+}
+
+// UpdateConfig представляет проверку новых версий бота по релизам GitHub
+type UpdateConfig struct {
+	Enabled       bool   `toml:"enabled"`
+	Repo          string `toml:"repo"`           // Репозиторий GitHub ("owner/name")
+	IntervalHours int    `toml:"interval_hours"` // Интервал проверки
+	SessionID     string `toml:"session_id"`     // Чат администраторов для уведомлений ("channel:chat_id")
+	SelfUpdate    bool   `toml:"self_update"`    // Устанавливать новую версию поверх запущенного бинарника
+}
+
+// MessageTemplateConfig представляет шаблон исходящего сообщения: инструменты
+// send_message и notify и задачи cron отправляют уведомления по имени шаблона
+// и данным вместо свободного текста
+type MessageTemplateConfig struct {
+	Text        string   `toml:"text"`        // Шаблон Go text/template, например "Сборка {{.build}} упала"
+	Format      string   `toml:"format"`      // Формат: "" (обычный текст), "markdown", "html", "markdownv2"
+	Description string   `toml:"description"` // Когда использовать шаблон (видит LLM)
+	Optional    []string `toml:"optional"`    // Поля, которые можно не передавать
+}
+
+// UserConfig представляет пользователя с идентичностями в разных каналах
+type UserConfig struct {
+	ID              string   `toml:"id"`
+	Name            string   `toml:"name"`
+	Identities      []string `toml:"identities"`       // "channel:address", например "telegram:123"
+	DefaultChannels []string `toml:"default_channels"` // Каналы для уведомлений по умолчанию
+
+	// Профиль пользователя (пустые значения не перезаписывают сохранённые)
+	Language    string            `toml:"language"`    // Язык ответов, например "ru"
+	Timezone    string            `toml:"timezone"`    // Часовой пояс IANA, например "Europe/Moscow"
+	Role        string            `toml:"role"`        // "admin" или "user"
+	Preferences map[string]string `toml:"preferences"` // Произвольные предпочтения
+}
+
+// Profile возвращает профиль, с которым загружена конфигурация ("" — без профиля)
+func (c *Config) Profile() string {
+	return c.profile
+}
+
+// ToolTable возвращает таблицу [tools.<section>] из файла конфигурации.
+// Возвращает false, если таблицы нет или конфигурация создана не через Load.
+func (c *Config) ToolTable(section string) (map[string]any, bool) {
+	table, ok := c.tools[section].(map[string]any)
+	return table, ok
 }
 
 // SecretsDir возвращает путь к директории для хранения секретов
 func (c *Config) SecretsDir() string {
 	return filepath.Join(c.Workspace.Path, "secrets")
 }
+
+// STTConfig представляет распознавание речи для голосовых сообщений и аудиофайлов
+type STTConfig struct {
+	Enabled         bool                `toml:"enabled"`
+	Provider        string              `toml:"provider"`          // whisper_api или whisper_cpp
+	Language        string              `toml:"language"`          // Код языка (пусто — автоопределение)
+	TimeoutSeconds  int                 `toml:"timeout_seconds"`   // Таймаут запроса к whisper_api
+	MaxVoiceSeconds int                 `toml:"max_voice_seconds"` // Максимальная длительность голосового сообщения
+	WhisperAPI      WhisperAPISTTConfig `toml:"whisper_api"`
+	WhisperCpp      WhisperCppSTTConfig `toml:"whisper_cpp"`
+}
+
+// StructuredConfig представляет структурированные ответы LLM (JSON по схеме)
+// для инструмента structured_output и IPC запросов structured
+type StructuredConfig struct {
+	Enabled    bool   `toml:"enabled"`
+	Model      string `toml:"model"`       // Модель (по умолчанию agent.model)
+	MaxRetries int    `toml:"max_retries"` // Повторов после невалидного ответа (отрицательное значение отключает)
+	MaxTokens  int    `toml:"max_tokens"`  // Максимум токенов ответа (по умолчанию agent.max_tokens)
+}
+
+// WhisperAPISTTConfig представляет OpenAI-совместимый API транскрипции
+type WhisperAPISTTConfig struct {
+	APIKey  string `toml:"api_key"`
+	BaseURL string `toml:"base_url"`
+	Model   string `toml:"model"`
+}
+
+// WhisperCppSTTConfig представляет локальный whisper.cpp
+type WhisperCppSTTConfig struct {
+	Binary  string `toml:"binary"`  // Путь к whisper-cli
+	Model   string `toml:"model"`   // Путь к ggml модели
+	FFmpeg  string `toml:"ffmpeg"`  // Путь к ffmpeg для конвертации в wav
+	Threads int    `toml:"threads"` // Число потоков (0 — по умолчанию whisper.cpp)
+}
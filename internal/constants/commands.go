@@ -8,3 +8,33 @@ const CommandStatus = "status"
 
 // CommandRestart is the command to restart the current agent session.
 const CommandRestart = "restart"
+
+// CommandExport is the command to export the current session transcript.
+const CommandExport = "export"
+
+// CommandFeedback is the command to rate the latest answer or leave a comment.
+const CommandFeedback = "feedback"
+
+// CommandSessions is the command to list conversation sessions.
+const CommandSessions = "sessions"
+
+// CommandSaveAs is the command to save the current session under a name.
+const CommandSaveAs = "save_as"
+
+// CommandResume is the command to continue a named session in the current chat.
+const CommandResume = "resume"
+
+// CommandContext is the command to switch between named contexts of the current chat.
+const CommandContext = "context"
+
+// CommandSampling is the command to override the sampling parameters of the current chat.
+const CommandSampling = "sampling"
+
+// CommandProject is the command to attach the current chat to a project.
+const CommandProject = "project"
+
+// CommandFollowups is the command to allow or forbid proactive follow-ups of the agent.
+const CommandFollowups = "followups"
+
+// CommandRollback is the command to undo the file changes of the agent's last turn.
+const CommandRollback = "rollback"
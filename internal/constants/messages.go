@@ -13,6 +13,171 @@ const (
 	// MsgRestarting is the notification message when a restart command is received.
 	MsgRestarting = "🔄 Restarting..."
 
+	// MsgExportError is the error message when a session transcript cannot be exported.
+	MsgExportError = "❌ Failed to export session. Please try again later."
+
+	// MsgExportCaption is the caption for the exported transcript document.
+	MsgExportCaption = "📄 Session transcript"
+
+	// MsgCodeImage replaces a long code block of an answer sent as an image and a file.
+	MsgCodeImage = "📎 Code %d (%d lines) is attached as an image and a file"
+
+	// MsgChanges is the heading of the file changes appended to an answer.
+	MsgChanges = "📝 Changes:"
+
+	// MsgChangesTruncated ends a diff summary cut to the configured number of lines.
+	MsgChangesTruncated = "… %d more lines"
+
+	// MsgCodeImageCaption is the caption of a code block image.
+	MsgCodeImageCaption = "Code %d"
+
+	// MsgExportedTo is the confirmation message after a session is exported to a notes target.
+	MsgExportedTo = "✅ Session exported to %s: %s"
+
+	// MsgFeedbackThanks is the confirmation message after feedback is recorded.
+	MsgFeedbackThanks = "🙏 Thanks for the feedback!"
+
+	// MsgFeedbackUsage is the help message for the /feedback command.
+	MsgFeedbackUsage = "Usage: /feedback <+|-|1-5> [comment]\nExamples: /feedback + great answer, /feedback 2 too verbose"
+
+	// MsgFeedbackDisabled is the message when feedback collection is disabled.
+	MsgFeedbackDisabled = "Feedback collection is disabled."
+
+	// MsgFeedbackError is the error message when feedback cannot be saved.
+	MsgFeedbackError = "❌ Failed to save feedback. Please try again later."
+
+	// MsgSessionsError is the error message when sessions cannot be listed.
+	MsgSessionsError = "❌ Failed to list sessions. Please try again later."
+
+	// MsgSessionsEmpty is the message when there are no sessions.
+	MsgSessionsEmpty = "📭 No sessions yet."
+
+	// MsgSessionsHeader is the header for the session list.
+	MsgSessionsHeader = "🗂 Sessions (%d)\n"
+
+	// MsgSessionsMore is the note about sessions not shown in the list.
+	MsgSessionsMore = "\n…and %d more. Use `nexbot session list` for the full list."
+
+	// MsgSessionSaved is the confirmation message after a session is saved under a name.
+	MsgSessionSaved = "💾 Session saved as %s. Continue it from any chat with /resume %s"
+
+	// MsgSessionResumed is the confirmation message after a named session is resumed.
+	MsgSessionResumed = "▶️ Resumed session %s. Use /new to leave it (it stays saved)."
+
+	// MsgSaveAsUsage is the help message for the save-as command.
+	MsgSaveAsUsage = "Usage: /save_as <name>\nNames may contain lowercase letters, digits, '-' and '_'."
+
+	// MsgResumeUsage is the help message for the resume command.
+	MsgResumeUsage = "Usage: /resume <name>\nUse /sessions to see saved sessions."
+
+	// MsgSessionNameTaken is the error message when a session name is already used.
+	MsgSessionNameTaken = "❌ Session name %s is already taken. Use /resume %s to continue it."
+
+	// MsgNamedSessionNotFound is the error message when a named session does not exist.
+	MsgNamedSessionNotFound = "❌ Session %s not found. Use /sessions to see saved sessions."
+
+	// MsgSessionNameError is the error message when a named session operation fails.
+	MsgSessionNameError = "❌ Failed to update session. Please try again later."
+
+	// MsgContextUsage is the help message for the context command.
+	MsgContextUsage = "Usage: /context <name> to switch to a context (created if needed), /context default to return to the chat history, /context delete <name> to delete one.\nNames may contain lowercase letters, digits, '-' and '_'."
+
+	// MsgContextsHeader is the header for the list of contexts of a chat.
+	MsgContextsHeader = "🗂 Contexts of this chat\n"
+
+	// MsgContextNamedSession is the note when the chat continues a named session instead of a context.
+	MsgContextNamedSession = "\nThis chat currently continues a named session (/resume); switching to a context leaves it saved."
+
+	// MsgContextSwitched is the confirmation after the chat switches to a context.
+	MsgContextSwitched = "🗂 Switched to context %s (%d messages). Use /context to see all contexts."
+
+	// MsgContextDeleted is the confirmation after a context is deleted.
+	MsgContextDeleted = "🗑 Context %s deleted."
+
+	// MsgContextNotFound is the error message when deleting an unknown context.
+	MsgContextNotFound = "❌ Context %s not found. Use /context to see the contexts of this chat."
+
+	// MsgContextInUse is the error message when deleting the current or the default context.
+	MsgContextInUse = "❌ Context %s is in use and can't be deleted. Switch to another context first."
+
+	// MsgContextError is the error message when a context operation fails.
+	MsgContextError = "❌ Failed to update the context. Please try again later."
+
+	// MsgSamplingUsage is the help message for the sampling command.
+	MsgSamplingUsage = "Usage: /sampling temperature=0.2 top_p=0.9 to override sampling parameters of this chat, /sampling reset to use the defaults.\nParameters: temperature, top_p, frequency_penalty, presence_penalty, stop (separated by |), seed."
+
+	// MsgSamplingCurrent is the message listing the sampling overrides of a chat.
+	MsgSamplingCurrent = "🎛 Sampling overrides of this chat: %s\n"
+
+	// MsgSamplingNone is the message when a chat has no sampling overrides.
+	MsgSamplingNone = "🎛 This chat uses the default sampling parameters.\n"
+
+	// MsgSamplingSet is the confirmation after the sampling overrides of a chat change.
+	MsgSamplingSet = "🎛 Sampling overrides of this chat: %s"
+
+	// MsgSamplingReset is the confirmation after the sampling overrides of a chat are cleared.
+	MsgSamplingReset = "🎛 Sampling overrides cleared; this chat uses the default parameters."
+
+	// MsgSamplingInvalid is the error message for invalid sampling parameters.
+	MsgSamplingInvalid = "❌ Invalid sampling parameters: %v"
+
+	// MsgSamplingError is the error message when the sampling overrides can't be read or stored.
+	MsgSamplingError = "❌ Failed to update the sampling parameters. Please try again later."
+
+	// MsgProjectsDisabled is the message when projects are disabled.
+	MsgProjectsDisabled = "Projects are disabled."
+
+	// MsgProjectAttached is the confirmation message after a chat is attached to an existing project.
+	MsgProjectAttached = "📁 This chat is now part of project %s. New sessions here start with its brief."
+
+	// MsgProjectCreated is the confirmation message after a chat is attached to a new project.
+	MsgProjectCreated = "📁 Created project %s. Its brief builds up as you talk; new sessions here start with it."
+
+	// MsgProjectLeft is the confirmation message after a chat is detached from a project.
+	MsgProjectLeft = "📁 This chat left project %s (the project and its brief are kept)."
+
+	// MsgProjectNone is the message when the chat isn't attached to a project.
+	MsgProjectNone = "This chat isn't part of a project.\nUsage: /project <name> to attach it, /project leave to detach it."
+
+	// MsgProjectError is the error message when a project operation fails.
+	MsgProjectError = "❌ Failed to update project. Please try again later."
+
+	// MsgFollowupsDisabled is the message when follow-ups are disabled.
+	MsgFollowupsDisabled = "Follow-ups are disabled."
+
+	// MsgFollowupsOn is the confirmation message after the user allows follow-ups.
+	MsgFollowupsOn = "🔔 Follow-ups are on: I may check back on my own about unfinished tasks, e.g. when a build you asked me to watch finishes. /followups off to stop."
+
+	// MsgFollowupsOff is the confirmation message after the user forbids follow-ups.
+	MsgFollowupsOff = "🔕 Follow-ups are off. Pending follow-ups cancelled: %d."
+
+	// MsgFollowupsStatus shows whether follow-ups are allowed and how many are pending.
+	MsgFollowupsStatus = "Follow-ups are %s (pending: %d).\nUsage: /followups on or /followups off."
+
+	// MsgFollowupsError is the error message when follow-up settings can't be changed.
+	MsgFollowupsError = "❌ Failed to update follow-up settings. Please try again later."
+
+	// MsgRollbackDisabled is the message when rollback is disabled.
+	MsgRollbackDisabled = "Rollback is disabled."
+
+	// MsgRollbackNothing is the message when there are no changes to roll back.
+	MsgRollbackNothing = "Nothing to roll back: the agent hasn't changed any files recently."
+
+	// MsgRollbackDone is the confirmation after the changes of a turn are rolled back.
+	MsgRollbackDone = "↩️ Rolled back the changes of %s: %d restored, %d removed. Turns that can still be rolled back: %d."
+
+	// MsgRollbackSkipped lists the files that were too large to restore.
+	MsgRollbackSkipped = "\n⚠️ Not restored (too large to keep): %s"
+
+	// MsgRollbackError is the error message when a rollback fails.
+	MsgRollbackError = "❌ Failed to roll back the changes. Please try again later."
+
+	// MsgSessionBusy tells the user a message waits for the turn in flight in the chat.
+	MsgSessionBusy = "⏳ Still working on the previous request in this chat. Your message is queued and will be answered next."
+
+	// MsgSessionConflict is the answer when the chat history changed during the turn (e.g. /new).
+	MsgSessionConflict = "⚠️ The chat history changed while I was answering (e.g. it was cleared), so the answer was dropped. Please send your message again."
+
 	// MsgErrorFormat is the prefix for formatting error messages.
 	MsgErrorFormat = "Error: %v"
 )
@@ -122,6 +287,33 @@ const (
 	MsgJobsNotFound = "No scheduled tasks found."
 )
 
+// Background job messages
+const (
+	// MsgBackgroundJobDone is the notification when a background job completes.
+	MsgBackgroundJobDone = "✅ Job %s finished\n\n%s"
+
+	// MsgBackgroundJobFailed is the notification when a background job fails.
+	MsgBackgroundJobFailed = "❌ Job %s failed: %s"
+
+	// MsgBackgroundJobTruncated is the note about a result cut in the notification.
+	MsgBackgroundJobTruncated = "\n\n…truncated. Full result: nexbot job status %s"
+)
+
+// Form messages
+const (
+	// MsgFormCompleted is the agent message with the result of a tool run after a completed form.
+	MsgFormCompleted = "[Form] The user completed the form and the %s tool was executed. Report the result to the user.\n\n%s"
+
+	// MsgFormToolFailed is the agent message when a tool fails after a completed form.
+	MsgFormToolFailed = "[Form] The user completed the form but the %s tool failed. Explain the error to the user.\n\n%s"
+
+	// MsgApprovalCompleted is the agent message with the results of approved destructive actions.
+	MsgApprovalCompleted = "[Approval] The user approved the actions and they were executed. Continue the task and report the result to the user.\n\n%s"
+
+	// MsgApprovalRejected is the agent message when the user rejects destructive actions.
+	MsgApprovalRejected = "[Approval] The user rejected these actions, they were not executed. Do not run them; ask the user how to proceed if needed.\n\n%s"
+)
+
 // Telegram messages
 const (
 	// TelegramMsgAuthError is the error message for Telegram authentication failure.
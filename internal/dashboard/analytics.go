@@ -0,0 +1,53 @@
+package dashboard
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/aatumaykin/nexbot/internal/analytics"
+)
+
+const (
+	// DefaultAnalyticsDays is the number of days shown by /api/analytics
+	DefaultAnalyticsDays = 7
+
+	// maxAnalyticsDays caps the days query parameter
+	maxAnalyticsDays = 366
+)
+
+// analyticsReport is the response of /api/analytics.
+type analyticsReport struct {
+	Days  []analytics.Summary `json:"days"`  // Oldest first
+	Total analytics.Summary   `json:"total"` // All days together
+}
+
+// handleAnalytics returns the daily statistics of the last days (query
+// parameter days, default DefaultAnalyticsDays) and their total.
+func (s *Server) handleAnalytics(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.Analytics == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("analytics is not enabled"))
+		return
+	}
+
+	n := DefaultAnalyticsDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid days: %s", value))
+			return
+		}
+		n = min(days, maxAnalyticsDays)
+	}
+
+	days, err := s.cfg.Analytics.Days(n)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	report := analyticsReport{Total: analytics.Merge(days).Summarize()}
+	for _, day := range days {
+		report.Days = append(report.Days, day.Summarize())
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,41 @@
+package dashboard
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/analytics"
+)
+
+func TestServer_Analytics(t *testing.T) {
+	s := newTestServer(t)
+	if code := get(t, s, "/api/analytics", "secret", nil); code != http.StatusNotFound {
+		t.Errorf("disabled analytics: status %d, want 404", code)
+	}
+
+	store := analytics.NewStore(t.TempDir(), 0, nil)
+	store.RecordMessage("telegram:42", 1500*time.Millisecond, false)
+	store.RecordMessage("telegram:42", 500*time.Millisecond, true)
+	store.RecordTool("system_time", 10*time.Millisecond, nil)
+	s.cfg.Analytics = store
+
+	var report analyticsReport
+	if code := get(t, s, "/api/analytics?days=3", "secret", &report); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if len(report.Days) != 3 {
+		t.Fatalf("days = %d, want 3", len(report.Days))
+	}
+	today := report.Days[2]
+	if today.Messages != 2 || today.Users != 1 || today.Errors != 1 || today.P90Ms != 1500 {
+		t.Errorf("today = %+v", today)
+	}
+	if report.Total.Messages != 2 || len(report.Total.Tools) != 1 || report.Total.Tools[0].Name != "system_time" {
+		t.Errorf("total = %+v", report.Total)
+	}
+
+	if code := get(t, s, "/api/analytics?days=0", "secret", nil); code != http.StatusBadRequest {
+		t.Errorf("invalid days: status %d, want 400", code)
+	}
+}
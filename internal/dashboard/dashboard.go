@@ -0,0 +1,286 @@
+// Package dashboard serves a read-only web UI of the running bot: active
+// sessions, their recent messages, a timeline of tool calls, queue depths,
+// a configuration summary, live logs and daily statistics. Static assets
+// are embedded in the binary and the JSON API is protected by bearer tokens
+// and an optional IP allowlist.
+package dashboard
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/analytics"
+	"github.com/aatumaykin/nexbot/internal/httpauth"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+const (
+	// DefaultActiveWindow is how recently a session must have been active to be listed
+	DefaultActiveWindow = time.Hour
+
+	// DefaultRecentMessages is the number of messages shown per session
+	DefaultRecentMessages = 50
+
+	// maxLimit caps the limit query parameter
+	maxLimit = 500
+
+	// shutdownTimeout bounds waiting for open requests on Stop
+	shutdownTimeout = 5 * time.Second
+)
+
+//go:embed static
+var static embed.FS
+
+// Queue is the depth of a queue shown on the dashboard.
+type Queue struct {
+	Name     string `json:"name"`
+	Length   int    `json:"length"`
+	Capacity int    `json:"capacity,omitempty"`
+}
+
+// Setting is a configuration value shown on the dashboard. Callers must not
+// pass secrets.
+type Setting struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Config configures the dashboard server.
+type Config struct {
+	Listen         string           // Listen address, e.g. "127.0.0.1:8090"
+	Tokens         []string         // Bearer tokens accepted by the API
+	AllowedIPs     []string         // Allowed client IPs and CIDRs (all when empty)
+	Routes         []httpauth.Route // Access rules of paths; a rule for "/" or "/api" replaces the default one
+	Sessions       *session.Manager // Source of sessions, messages and tool calls
+	Queues         func() []Queue   // Current queue depths
+	Settings       []Setting        // Configuration summary
+	Logs           *logger.Stream   // Log entries for /api/logs (disabled when nil)
+	Analytics      *analytics.Store // Daily statistics for /api/analytics (disabled when nil)
+	ActiveWindow   time.Duration    // DefaultActiveWindow if 0
+	RecentMessages int              // DefaultRecentMessages if 0
+}
+
+// Server is the dashboard HTTP server.
+type Server struct {
+	cfg     Config
+	handler http.Handler
+	logger  *logger.Logger
+	now     func() time.Time
+
+	server   *http.Server
+	listener net.Listener
+	cancel   context.CancelFunc // Ends open log streams on Stop
+}
+
+// New creates a dashboard server. At least one token is required: the
+// dashboard exposes conversation history.
+func New(cfg Config, log *logger.Logger) (*Server, error) {
+	if len(cfg.Tokens) == 0 {
+		return nil, fmt.Errorf("dashboard requires at least one access token")
+	}
+	if cfg.ActiveWindow <= 0 {
+		cfg.ActiveWindow = DefaultActiveWindow
+	}
+	if cfg.RecentMessages <= 0 {
+		cfg.RecentMessages = DefaultRecentMessages
+	}
+
+	guard, err := httpauth.New(accessRoutes(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("invalid dashboard access rules: %w", err)
+	}
+
+	assets, err := fs.Sub(static, "static")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{cfg: cfg, logger: log, now: time.Now}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/overview", s.handleOverview)
+	mux.HandleFunc("GET /api/sessions/{id}/messages", s.handleMessages)
+	mux.HandleFunc("GET /api/tools", s.handleTools)
+	mux.HandleFunc("GET /api/logs", s.handleLogs)
+	mux.HandleFunc("GET /api/analytics", s.handleAnalytics)
+	mux.Handle("GET /", http.FileServerFS(assets))
+	s.handler = securityHeaders(guard.Middleware(mux))
+	return s, nil
+}
+
+// accessRoutes returns the default access rules, the static assets open and
+// the API behind tokens, replaced or extended by the configured routes.
+func accessRoutes(cfg Config) []httpauth.Route {
+	routes := []httpauth.Route{
+		{Path: "/", AllowedIPs: cfg.AllowedIPs},
+		{Path: "/api", Tokens: cfg.Tokens, AllowedIPs: cfg.AllowedIPs},
+	}
+	for _, route := range cfg.Routes {
+		replaced := false
+		for i := range routes {
+			if routes[i].Path == route.Path {
+				routes[i] = route
+				replaced = true
+			}
+		}
+		if !replaced {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// Handler returns the HTTP handler of the dashboard.
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// Start listens on the configured address and serves in the background.
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.cfg.Listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.cfg.Listen, err)
+	}
+	s.listener = listener
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.server = &http.Server{
+		Handler:           s.handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Dashboard server failed", err)
+		}
+	}()
+
+	s.logger.Info("Dashboard started", logger.Field{Key: "address", Value: listener.Addr().String()})
+	return nil
+}
+
+// Addr returns the address the server listens on, or "" before Start.
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Stop shuts the server down, waiting briefly for open requests.
+func (s *Server) Stop() error {
+	if s.server == nil {
+		return nil
+	}
+	s.cancel()
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := s.server.Shutdown(ctx)
+	s.server = nil
+	return err
+}
+
+// overview is the response of GET /api/overview.
+type overview struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	Sessions    []sessionInfo `json:"sessions"`
+	Queues      []Queue       `json:"queues"`
+	Settings    []Setting     `json:"settings"`
+}
+
+func (s *Server) handleOverview(w http.ResponseWriter, r *http.Request) {
+	sessions, err := s.activeSessions()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	var queues []Queue
+	if s.cfg.Queues != nil {
+		queues = s.cfg.Queues()
+	}
+	writeJSON(w, http.StatusOK, overview{
+		GeneratedAt: s.now(),
+		Sessions:    sessions,
+		Queues:      queues,
+		Settings:    s.cfg.Settings,
+	})
+}
+
+func (s *Server) handleMessages(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseLimit(r, s.cfg.RecentMessages)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	messages, err := s.recentMessages(r.PathValue("id"), limit)
+	if errors.Is(err, errUnknownSession) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, messages)
+}
+
+func (s *Server) handleTools(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseLimit(r, s.cfg.RecentMessages)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	calls, err := s.toolTimeline(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, calls)
+}
+
+// parseLimit reads the limit query parameter.
+func parseLimit(r *http.Request, def int) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return def, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("invalid limit: %s", value)
+	}
+	return min(limit, maxLimit), nil
+}
+
+// securityHeaders keeps the dashboard out of frames and limits scripts to
+// the embedded assets.
+func securityHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("Referrer-Policy", "no-referrer")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,203 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/httpauth"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sess, _, err := sessions.GetOrCreate("telegram:42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []llm.Message{
+		{Role: llm.RoleUser, Content: "what time is it?"},
+		{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{{ID: "call_1", Name: "system_time", Arguments: `{}`}}},
+		{Role: llm.RoleTool, ToolCallID: "call_1", Content: "12:00"},
+		{Role: llm.RoleAssistant, Content: "It's noon."},
+	} {
+		if err := sess.Append(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := New(Config{
+		Tokens:   []string{"secret"},
+		Sessions: sessions,
+		Queues:   func() []Queue { return []Queue{{Name: "inbound", Length: 1, Capacity: 100}} },
+		Settings: []Setting{{Key: "agent.model", Value: "glm-4.7"}},
+	}, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func get(t *testing.T, s *Server, path, token string, out any) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	if out != nil && rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestNew_RequiresToken(t *testing.T) {
+	if _, err := New(Config{}, nil); err == nil {
+		t.Error("expected error without tokens")
+	}
+}
+
+func TestServer_APIRequiresToken(t *testing.T) {
+	s := newTestServer(t)
+	if code := get(t, s, "/api/overview", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("without token: status %d, want 401", code)
+	}
+	if code := get(t, s, "/api/overview", "wrong", nil); code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status %d, want 401", code)
+	}
+
+	// Static assets hold no data and load without a token
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Nexbot dashboard") {
+		t.Errorf("index: status %d", rec.Code)
+	}
+	if rec.Header().Get("Content-Security-Policy") == "" {
+		t.Error("Content-Security-Policy header not set")
+	}
+}
+
+func TestServer_Routes(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(Config{
+		Tokens:   []string{"secret"},
+		Sessions: sessions,
+		Queues:   func() []Queue { return nil },
+		Routes: []httpauth.Route{
+			{Path: "/api/logs", Tokens: []string{"logs"}},
+			{Path: "/api/analytics", Tokens: []string{"secret"}, AllowedIPs: []string{"10.0.0.0/8"}},
+			{Path: "/", AllowedIPs: []string{"10.0.0.0/8"}},
+		},
+	}, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The logs route accepts its own token only
+	if code := get(t, s, "/api/logs?follow=false", "secret", nil); code != http.StatusUnauthorized {
+		t.Errorf("logs with the API token: status %d, want 401", code)
+	}
+	if code := get(t, s, "/api/logs?follow=false", "logs", nil); code == http.StatusUnauthorized || code == http.StatusForbidden {
+		t.Errorf("logs with the logs token: status %d", code)
+	}
+
+	// Other API paths keep the default rule
+	if code := get(t, s, "/api/overview", "logs", nil); code != http.StatusUnauthorized {
+		t.Errorf("overview with the logs token: status %d, want 401", code)
+	}
+	if code := get(t, s, "/api/overview", "secret", nil); code != http.StatusOK {
+		t.Errorf("overview with the API token: status %d, want 200", code)
+	}
+
+	// The analytics route and the replaced "/" route check the client address
+	// (httptest requests come from 192.0.2.1)
+	if code := get(t, s, "/api/analytics", "secret", nil); code != http.StatusForbidden {
+		t.Errorf("analytics from outside the allowlist: status %d, want 403", code)
+	}
+	if code := get(t, s, "/", "", nil); code != http.StatusForbidden {
+		t.Errorf("index from outside the allowlist: status %d, want 403", code)
+	}
+}
+
+func TestServer_Overview(t *testing.T) {
+	s := newTestServer(t)
+	var got overview
+	if code := get(t, s, "/api/overview", "secret", &got); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if len(got.Sessions) != 1 || got.Sessions[0].ID != "telegram:42" || got.Sessions[0].MessageCount != 4 {
+		t.Errorf("sessions = %+v", got.Sessions)
+	}
+	if len(got.Queues) != 1 || got.Queues[0].Length != 1 {
+		t.Errorf("queues = %+v", got.Queues)
+	}
+	if len(got.Settings) != 1 || got.Settings[0].Value != "glm-4.7" {
+		t.Errorf("settings = %+v", got.Settings)
+	}
+
+	// Sessions idle for longer than the active window are not listed
+	s.now = func() time.Time { return time.Now().Add(2 * DefaultActiveWindow) }
+	if get(t, s, "/api/overview", "secret", &got); len(got.Sessions) != 0 {
+		t.Errorf("idle sessions listed: %+v", got.Sessions)
+	}
+}
+
+func TestServer_Messages(t *testing.T) {
+	s := newTestServer(t)
+	var got []message
+	path := "/api/sessions/" + url.PathEscape("telegram:42") + "/messages?limit=2"
+	if code := get(t, s, path, "secret", &got); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if len(got) != 2 || got[0].Role != llm.RoleTool || got[1].Content != "It's noon." {
+		t.Errorf("messages = %+v", got)
+	}
+
+	if code := get(t, s, "/api/sessions/..%2Fsecrets/messages", "secret", nil); code != http.StatusNotFound {
+		t.Errorf("unknown session: status %d, want 404", code)
+	}
+	if code := get(t, s, "/api/tools?limit=x", "secret", nil); code != http.StatusBadRequest {
+		t.Errorf("invalid limit: status %d, want 400", code)
+	}
+}
+
+func TestServer_ToolTimeline(t *testing.T) {
+	s := newTestServer(t)
+	var got []toolCall
+	if code := get(t, s, "/api/tools", "secret", &got); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if len(got) != 1 {
+		t.Fatalf("tool calls = %+v", got)
+	}
+	call := got[0]
+	if call.Name != "system_time" || call.SessionID != "telegram:42" || call.Result != "12:00" || call.FinishedAt == "" {
+		t.Errorf("tool call = %+v", call)
+	}
+}
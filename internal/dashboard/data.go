@@ -0,0 +1,206 @@
+package dashboard
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/textutil"
+)
+
+const (
+	// maxContentRunes truncates message contents and tool results
+	maxContentRunes = 2000
+
+	// maxTimelineSessions limits the sessions scanned for the tool timeline
+	maxTimelineSessions = 20
+)
+
+// errUnknownSession is returned for sessions that are not listed.
+var errUnknownSession = errors.New("unknown session")
+
+// sessionInfo is a session in the overview.
+type sessionInfo struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name,omitempty"`
+	Title        string    `json:"title,omitempty"`
+	MessageCount int       `json:"message_count"`
+	LastActivity time.Time `json:"last_activity"`
+}
+
+// message is a session message.
+type message struct {
+	Role       llm.Role   `json:"role"`
+	Content    string     `json:"content,omitempty"`
+	Timestamp  string     `json:"timestamp,omitempty"`
+	ToolCalls  []toolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+// toolCall is a tool call requested by the model and its result.
+type toolCall struct {
+	SessionID  string `json:"session_id,omitempty"`
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name"`
+	Arguments  string `json:"arguments,omitempty"`
+	Result     string `json:"result,omitempty"`
+	StartedAt  string `json:"started_at,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+}
+
+// activeSessions lists sessions active within the active window, most
+// recent first.
+func (s *Server) activeSessions() ([]sessionInfo, error) {
+	if s.cfg.Sessions == nil {
+		return []sessionInfo{}, nil
+	}
+	infos, err := s.cfg.Sessions.List()
+	if err != nil {
+		return nil, err
+	}
+
+	since := s.now().Add(-s.cfg.ActiveWindow)
+	sessions := []sessionInfo{}
+	for _, info := range infos {
+		if info.LastActivity.Before(since) {
+			break
+		}
+		sessions = append(sessions, sessionInfo{
+			ID:           info.ID,
+			Name:         info.Name,
+			Title:        info.Title,
+			MessageCount: info.MessageCount,
+			LastActivity: info.LastActivity,
+		})
+	}
+	return sessions, nil
+}
+
+// recentMessages returns the last limit messages of a listed session. Only
+// listed sessions are opened, so the ID never escapes the sessions directory.
+func (s *Server) recentMessages(sessionID string, limit int) ([]message, error) {
+	entries, err := s.readListedSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	messages := make([]message, 0, len(entries))
+	for _, entry := range entries {
+		msg := message{
+			Role:       entry.Message.Role,
+			Content:    textutil.Truncate(entry.Message.Content, maxContentRunes),
+			Timestamp:  entry.Timestamp,
+			ToolCallID: entry.Message.ToolCallID,
+		}
+		for _, call := range entry.Message.ToolCalls {
+			msg.ToolCalls = append(msg.ToolCalls, toolCall{ID: call.ID, Name: call.Name, Arguments: textutil.Truncate(call.Arguments, maxContentRunes)})
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// readListedSession reads the entries of a session returned by List.
+func (s *Server) readListedSession(sessionID string) ([]session.Entry, error) {
+	if s.cfg.Sessions == nil {
+		return nil, errUnknownSession
+	}
+	infos, err := s.cfg.Sessions.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		if info.ID != sessionID {
+			continue
+		}
+		sess, err := s.cfg.Sessions.Get(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		return sess.ReadEntries()
+	}
+	return nil, fmt.Errorf("%w: %s", errUnknownSession, sessionID)
+}
+
+// toolTimeline returns the latest tool calls of the active sessions, most
+// recent first. A call is matched with its result by the tool call ID.
+func (s *Server) toolTimeline(limit int) ([]toolCall, error) {
+	sessions, err := s.activeSessions()
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) > maxTimelineSessions {
+		sessions = sessions[:maxTimelineSessions]
+	}
+
+	calls := []toolCall{}
+	for _, info := range sessions {
+		entries, err := s.readListedSession(info.ID)
+		if err != nil {
+			continue
+		}
+		calls = append(calls, sessionToolCalls(info.ID, entries)...)
+	}
+
+	sort.SliceStable(calls, func(i, j int) bool {
+		return calls[i].StartedAt > calls[j].StartedAt
+	})
+	if len(calls) > limit {
+		calls = calls[:limit]
+	}
+	return calls, nil
+}
+
+// sessionToolCalls extracts the tool calls of a session with their results.
+func sessionToolCalls(sessionID string, entries []session.Entry) []toolCall {
+	var calls []toolCall
+	pending := map[string]int{} // Tool call ID -> index in calls
+
+	for _, entry := range entries {
+		msg := entry.Message
+		switch msg.Role {
+		case llm.RoleAssistant:
+			for _, call := range msg.ToolCalls {
+				pending[call.ID] = len(calls)
+				calls = append(calls, toolCall{
+					SessionID: sessionID,
+					ID:        call.ID,
+					Name:      call.Name,
+					Arguments: textutil.Truncate(call.Arguments, maxContentRunes),
+					StartedAt: entry.Timestamp,
+				})
+			}
+		case llm.RoleTool:
+			i, ok := pending[msg.ToolCallID]
+			if !ok {
+				continue
+			}
+			delete(pending, msg.ToolCallID)
+			calls[i].Result = textutil.Truncate(msg.Content, maxContentRunes)
+			calls[i].FinishedAt = entry.Timestamp
+			calls[i].DurationMS = durationMS(calls[i].StartedAt, entry.Timestamp)
+		}
+	}
+	return calls
+}
+
+// durationMS returns the time between two RFC 3339 timestamps; 0 when
+// either is missing.
+func durationMS(start, end string) int64 {
+	from, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		return 0
+	}
+	to, err := time.Parse(time.RFC3339, end)
+	if err != nil || to.Before(from) {
+		return 0
+	}
+	return to.Sub(from).Milliseconds()
+}
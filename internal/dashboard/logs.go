@@ -0,0 +1,91 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// DefaultLogBacklog is the number of recent log entries sent before new ones
+const DefaultLogBacklog = 100
+
+// handleLogs streams log entries as newline-delimited JSON: first recent
+// entries from the backlog, then new entries until the client disconnects.
+// Query parameters: level (minimum level, default info), component
+// (comma-separated packages, e.g. "telegram,bus"), backlog (number of recent
+// entries) and follow=false to return the backlog only.
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.Logs == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("log streaming is not enabled"))
+		return
+	}
+
+	query := r.URL.Query()
+	filter := logger.Filter{Level: slog.LevelInfo}
+	if value := query.Get("level"); value != "" {
+		level, ok := logger.ParseLevel(value)
+		if !ok {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid level: %s (expected: debug, info, warn, error)", value))
+			return
+		}
+		filter.Level = level
+	}
+	for _, c := range strings.Split(query.Get("component"), ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			filter.Components = append(filter.Components, c)
+		}
+	}
+	backlog := DefaultLogBacklog
+	if value := query.Get("backlog"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid backlog: %s", value))
+			return
+		}
+		backlog = n
+	}
+	follow := query.Get("follow") != "false"
+
+	sub := s.cfg.Logs.Subscribe(r.Context(), filter, backlog)
+	pending := len(sub.C)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Send the headers at once: a follower may wait long for the first entry
+	flusher := http.NewResponseController(w)
+	_ = flusher.Flush()
+
+	encoder := json.NewEncoder(w)
+	var reported int64
+	for sent := 0; follow || sent < pending; sent++ {
+		entry, ok := <-sub.C
+		if !ok {
+			return
+		}
+		if err := encoder.Encode(entry); err != nil {
+			return
+		}
+		if dropped := sub.Dropped(); dropped > reported {
+			_ = encoder.Encode(logger.Entry{
+				Time:      s.now(),
+				Level:     slog.LevelWarn.String(),
+				Component: "dashboard",
+				Message:   "log entries dropped: the client is reading too slowly",
+				Attrs:     map[string]any{"count": dropped - reported},
+			})
+			reported = dropped
+		}
+		if len(sub.C) == 0 {
+			_ = flusher.Flush()
+		}
+	}
+	_ = flusher.Flush()
+}
@@ -0,0 +1,89 @@
+package dashboard
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+func newLogsServer(t *testing.T) (*Server, *logger.Logger) {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "info", Format: "json", Output: "stdout"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Stream().Enable(10)
+	s, err := New(Config{Tokens: []string{"secret"}, Logs: log.Stream()}, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, log
+}
+
+func TestServer_LogsBacklog(t *testing.T) {
+	s, log := newLogsServer(t)
+	log.Info("first")
+	log.Warn("second")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/logs?follow=false&level=warn", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"msg":"second"`) {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+
+	if code := get(t, s, "/api/logs?level=trace", "secret", nil); code != http.StatusBadRequest {
+		t.Errorf("invalid level: status %d, want 400", code)
+	}
+}
+
+func TestServer_LogsFollow(t *testing.T) {
+	s, log := newLogsServer(t)
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/logs?backlog=0&component=dashboard", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// The subscription exists once the headers are sent
+	log.Info("live entry", logger.Field{Key: "attempt", Value: 1})
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry logger.Entry
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Message != "live entry" || entry.Component != "dashboard" || entry.Attrs["attempt"] != float64(1) {
+		t.Errorf("entry = %+v", entry)
+	}
+}
+
+func TestServer_LogsDisabled(t *testing.T) {
+	s := newTestServer(t)
+	if code := get(t, s, "/api/logs", "secret", nil); code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", code)
+	}
+}
@@ -0,0 +1,188 @@
+"use strict";
+
+// Token is kept in the browser and sent as a bearer token with every API request
+const tokenKey = "nexbot-dashboard-token";
+const refreshMs = 5000;
+
+let selectedSession = "";
+let timer = 0;
+
+async function api(path) {
+  const response = await fetch(path, {
+    headers: { Authorization: "Bearer " + localStorage.getItem(tokenKey) },
+    cache: "no-store",
+  });
+  if (response.status === 401 || response.status === 403) {
+    throw new Error("unauthorized");
+  }
+  if (!response.ok) {
+    throw new Error("request failed: " + response.status);
+  }
+  return response.json();
+}
+
+function cell(row, text, className) {
+  const td = row.insertCell();
+  td.textContent = text ?? "";
+  if (className) {
+    td.className = className;
+  }
+  return td;
+}
+
+function fill(table, items, render) {
+  const body = document.querySelector(table + " tbody");
+  body.replaceChildren();
+  for (const item of items ?? []) {
+    render(body.insertRow(), item);
+  }
+}
+
+function time(value) {
+  return value ? new Date(value).toLocaleString() : "";
+}
+
+function renderOverview(data) {
+  document.getElementById("updated").textContent = "Updated " + time(data.generated_at);
+
+  fill("#queues", data.queues, (row, q) => {
+    cell(row, q.name);
+    cell(row, q.length, q.capacity && q.length >= q.capacity ? "full" : "");
+    cell(row, q.capacity || "");
+  });
+
+  fill("#sessions", data.sessions, (row, s) => {
+    cell(row, s.name || s.id);
+    cell(row, s.title);
+    cell(row, s.message_count);
+    cell(row, time(s.last_activity));
+    row.classList.toggle("selected", s.id === selectedSession);
+    row.addEventListener("click", () => selectSession(s.id));
+  });
+
+  fill("#settings", data.settings, (row, s) => {
+    cell(row, s.key);
+    cell(row, s.value, "code");
+  });
+}
+
+function renderMessages(messages) {
+  const list = document.getElementById("messages");
+  list.replaceChildren();
+  for (const m of messages) {
+    const item = document.createElement("li");
+    if (m.role === "tool") {
+      item.className = "tool";
+    }
+    const role = document.createElement("span");
+    role.className = "role";
+    role.textContent = m.role;
+    const when = document.createElement("span");
+    when.className = "time";
+    when.textContent = time(m.timestamp);
+    const content = document.createElement("div");
+    content.className = "content";
+    const calls = (m.tool_calls ?? []).map((c) => "→ " + c.name + " " + (c.arguments || ""));
+    content.textContent = [m.content, ...calls].filter(Boolean).join("\n");
+    item.append(role, when, content);
+    list.append(item);
+  }
+}
+
+function renderTools(calls) {
+  fill("#tools", calls, (row, c) => {
+    cell(row, time(c.started_at));
+    cell(row, c.session_id);
+    cell(row, c.name);
+    cell(row, c.finished_at ? c.duration_ms + " ms" : "running");
+    cell(row, c.arguments, "code");
+    cell(row, c.result, "code");
+  });
+}
+
+function renderAnalytics(report) {
+  const section = document.getElementById("analytics-section");
+  section.hidden = !report;
+  if (!report) {
+    return;
+  }
+  const days = [...report.days].reverse();
+  fill("#analytics", [...days, report.total], (row, d) => {
+    cell(row, d === report.total ? "Total" : d.date);
+    cell(row, d.messages);
+    cell(row, d.users);
+    cell(row, d.errors, d.errors ? "error" : "");
+    cell(row, d.messages ? d.p50_ms + " ms" : "");
+    cell(row, d.messages ? d.p90_ms + " ms" : "");
+    cell(row, d.messages ? d.p99_ms + " ms" : "");
+    cell(row, (d.tools ?? []).slice(0, 3).map((t) => t.name + " × " + t.calls).join(", "));
+  });
+}
+
+async function selectSession(id) {
+  selectedSession = id;
+  await refresh();
+}
+
+async function refresh() {
+  try {
+    renderOverview(await api("/api/overview"));
+    renderTools(await api("/api/tools"));
+    // Analytics is optional: 404 when disabled
+    renderAnalytics(await api("/api/analytics").catch((err) => {
+      if (err.message === "unauthorized") {
+        throw err;
+      }
+      return null;
+    }));
+
+    const section = document.getElementById("messages-section");
+    section.hidden = !selectedSession;
+    if (selectedSession) {
+      document.getElementById("messages-session").textContent = "— " + selectedSession;
+      renderMessages(await api("/api/sessions/" + encodeURIComponent(selectedSession) + "/messages"));
+    }
+    showDashboard();
+  } catch (err) {
+    if (err.message === "unauthorized") {
+      showLogin(localStorage.getItem(tokenKey) ? "Invalid token" : "");
+      return;
+    }
+    document.getElementById("updated").textContent = "Update failed: " + err.message;
+  }
+}
+
+function showDashboard() {
+  document.getElementById("login").hidden = true;
+  document.getElementById("dashboard").hidden = false;
+  document.getElementById("logout").hidden = false;
+  if (!timer) {
+    timer = setInterval(refresh, refreshMs);
+  }
+}
+
+function showLogin(error) {
+  clearInterval(timer);
+  timer = 0;
+  document.getElementById("dashboard").hidden = true;
+  document.getElementById("logout").hidden = true;
+  document.getElementById("login").hidden = false;
+  document.getElementById("login-error").textContent = error;
+}
+
+document.getElementById("login").addEventListener("submit", (event) => {
+  event.preventDefault();
+  localStorage.setItem(tokenKey, document.getElementById("token").value);
+  refresh();
+});
+
+document.getElementById("logout").addEventListener("click", () => {
+  localStorage.removeItem(tokenKey);
+  showLogin("");
+});
+
+if (localStorage.getItem(tokenKey)) {
+  refresh();
+} else {
+  showLogin("");
+}
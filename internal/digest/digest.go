@@ -0,0 +1,272 @@
+// Package digest batches automated notifications (cron jobs, file watcher,
+// background jobs) per session and delivers them as one summarized message
+// per interval instead of one message each.
+package digest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// Sources of automated notifications
+const (
+	SourceCron    = "cron"    // Messages of cron jobs and answers to them
+	SourceWatcher = "watcher" // Answers to file watcher notifications
+	SourceJobs    = "jobs"    // Results of background jobs
+)
+
+// Sources lists the known notification sources.
+var Sources = []string{SourceCron, SourceWatcher, SourceJobs}
+
+const (
+	// DefaultInterval is the delivery interval when none is set
+	DefaultInterval = time.Hour
+
+	// DefaultMaxItems is the batch size that is delivered before the interval ends
+	DefaultMaxItems = 50
+
+	// MetadataDigest marks delivered digests; the value is the number of items
+	MetadataDigest = "digest"
+)
+
+// Item is a notification waiting for delivery.
+type Item struct {
+	Source  string
+	Content string
+	Time    time.Time
+}
+
+// Summarizer turns a batch of notifications into one message.
+type Summarizer interface {
+	Summarize(ctx context.Context, items []Item) (string, error)
+}
+
+// Publisher publishes delivered digests (implemented by bus.MessageBus).
+type Publisher interface {
+	PublishOutbound(msg bus.OutboundMessage) error
+}
+
+// Config configures an Aggregator.
+type Config struct {
+	Interval   time.Duration // Delivery interval (DefaultInterval if 0)
+	MaxItems   int           // Deliver a batch early once it has that many items (DefaultMaxItems if 0)
+	Sources    []string      // Sources to batch; empty batches all sources
+	Summarizer Summarizer    // Summarizes batches; without it notifications are listed
+	Publisher  Publisher
+	Logger     *logger.Logger
+}
+
+// Aggregator collects notifications per session and delivers them periodically.
+type Aggregator struct {
+	cfg     Config
+	now     func() time.Time
+	mu      sync.Mutex
+	pending map[string]*batch // Batches by session ID
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// batch holds the notifications of one session.
+type batch struct {
+	items []Item
+}
+
+// New creates an Aggregator. Call Start to begin periodic delivery.
+func New(cfg Config) *Aggregator {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.MaxItems <= 0 {
+		cfg.MaxItems = DefaultMaxItems
+	}
+	return &Aggregator{
+		cfg:     cfg,
+		now:     time.Now,
+		pending: make(map[string]*batch),
+	}
+}
+
+// Accepts reports whether notifications of the source are batched.
+func (a *Aggregator) Accepts(source string) bool {
+	return source != "" && (len(a.cfg.Sources) == 0 || slices.Contains(a.cfg.Sources, source))
+}
+
+// Add queues a notification for the session ("channel:chat_id"). It returns
+// false if the source is not batched; the caller then sends it directly.
+func (a *Aggregator) Add(ctx context.Context, sessionID, source, content string) bool {
+	if !a.Accepts(source) || strings.TrimSpace(content) == "" {
+		return false
+	}
+	if _, _, ok := strings.Cut(sessionID, ":"); !ok {
+		return false
+	}
+
+	a.mu.Lock()
+	b, ok := a.pending[sessionID]
+	if !ok {
+		b = &batch{}
+		a.pending[sessionID] = b
+	}
+	b.items = append(b.items, Item{Source: source, Content: content, Time: a.now()})
+	full := len(b.items) >= a.cfg.MaxItems
+	if full {
+		delete(a.pending, sessionID)
+	}
+	a.mu.Unlock()
+
+	a.cfg.Logger.DebugCtx(ctx, "notification queued for digest",
+		logger.Field{Key: "session_id", Value: sessionID},
+		logger.Field{Key: "source", Value: source})
+
+	if full {
+		a.deliver(ctx, sessionID, b.items, true)
+	}
+	return true
+}
+
+// Pending returns the number of queued notifications of the session.
+func (a *Aggregator) Pending(sessionID string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if b, ok := a.pending[sessionID]; ok {
+		return len(b.items)
+	}
+	return 0
+}
+
+// Start begins delivering the batches every interval.
+func (a *Aggregator) Start(ctx context.Context) {
+	ctx, a.cancel = context.WithCancel(ctx)
+	a.done = make(chan struct{})
+	ticker := time.NewTicker(a.cfg.Interval)
+
+	a.cfg.Logger.Info("digest started",
+		logger.Field{Key: "interval_minutes", Value: int(a.cfg.Interval.Minutes())},
+		logger.Field{Key: "sources", Value: a.cfg.Sources})
+
+	go func() {
+		defer close(a.done)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				a.Flush(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops periodic delivery and delivers the queued notifications as a
+// list, without summarizing them.
+func (a *Aggregator) Stop() {
+	if a.cancel != nil {
+		a.cancel()
+		<-a.done
+	}
+	for sessionID, items := range a.take() {
+		a.deliver(context.Background(), sessionID, items, false)
+	}
+}
+
+// Flush delivers all queued notifications now.
+func (a *Aggregator) Flush(ctx context.Context) {
+	batches := a.take()
+	sessions := make([]string, 0, len(batches))
+	for sessionID := range batches {
+		sessions = append(sessions, sessionID)
+	}
+	sort.Strings(sessions)
+
+	for _, sessionID := range sessions {
+		if ctx.Err() != nil {
+			// Stopping: the rest is delivered by Stop
+			a.requeue(sessionID, batches[sessionID])
+			continue
+		}
+		a.deliver(ctx, sessionID, batches[sessionID], true)
+	}
+}
+
+// take removes and returns all queued batches.
+func (a *Aggregator) take() map[string][]Item {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	batches := make(map[string][]Item, len(a.pending))
+	for sessionID, b := range a.pending {
+		batches[sessionID] = b.items
+	}
+	a.pending = make(map[string]*batch)
+	return batches
+}
+
+// requeue puts notifications back in front of the session queue.
+func (a *Aggregator) requeue(sessionID string, items []Item) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	b, ok := a.pending[sessionID]
+	if !ok {
+		b = &batch{}
+		a.pending[sessionID] = b
+	}
+	b.items = append(items, b.items...)
+}
+
+// deliver publishes the digest of a batch. A single notification is
+// delivered unchanged; a failed summary falls back to a list.
+func (a *Aggregator) deliver(ctx context.Context, sessionID string, items []Item, summarize bool) {
+	if len(items) == 0 {
+		return
+	}
+	channel, chatID, _ := strings.Cut(sessionID, ":")
+
+	content := items[0].Content
+	if len(items) > 1 {
+		content = ""
+		if summarize && a.cfg.Summarizer != nil {
+			summary, err := a.cfg.Summarizer.Summarize(ctx, items)
+			if err != nil {
+				a.cfg.Logger.WarnCtx(ctx, "failed to summarize digest, sending the list",
+					logger.Field{Key: "session_id", Value: sessionID},
+					logger.Field{Key: "items", Value: len(items)},
+					logger.Field{Key: "error", Value: err.Error()})
+			} else if summary = strings.TrimSpace(summary); summary != "" {
+				content = fmt.Sprintf("📬 Digest: %d notifications\n\n%s", len(items), summary)
+			}
+		}
+		if content == "" {
+			content = Format(items)
+		}
+	}
+
+	msg := bus.NewOutboundMessage(bus.ChannelType(channel), chatID, sessionID, content, "",
+		bus.FormatTypePlain, map[string]any{MetadataDigest: len(items)})
+	if err := a.cfg.Publisher.PublishOutbound(*msg); err != nil {
+		a.cfg.Logger.ErrorCtx(ctx, "failed to publish digest", err,
+			logger.Field{Key: "session_id", Value: sessionID},
+			logger.Field{Key: "items", Value: len(items)})
+		return
+	}
+	a.cfg.Logger.InfoCtx(ctx, "digest delivered",
+		logger.Field{Key: "session_id", Value: sessionID},
+		logger.Field{Key: "items", Value: len(items)})
+}
+
+// Format lists notifications with their time and source.
+func Format(items []Item) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📬 Digest: %d notifications\n", len(items))
+	for _, item := range items {
+		fmt.Fprintf(&b, "\n[%s, %s]\n%s\n", item.Time.Format("15:04"), item.Source, strings.TrimSpace(item.Content))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
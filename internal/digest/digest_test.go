@@ -0,0 +1,178 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// recordingPublisher collects published messages.
+type recordingPublisher struct {
+	mu   sync.Mutex
+	msgs []bus.OutboundMessage
+}
+
+func (p *recordingPublisher) PublishOutbound(msg bus.OutboundMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.msgs = append(p.msgs, msg)
+	return nil
+}
+
+// fakeSummarizer returns a fixed summary or error.
+type fakeSummarizer struct {
+	summary string
+	err     error
+	items   int
+}
+
+func (s *fakeSummarizer) Summarize(_ context.Context, items []Item) (string, error) {
+	s.items = len(items)
+	return s.summary, s.err
+}
+
+func newTestAggregator(t *testing.T, cfg Config) (*Aggregator, *recordingPublisher) {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("logger.New() error = %v", err)
+	}
+	pub := &recordingPublisher{}
+	cfg.Publisher = pub
+	cfg.Logger = log
+	a := New(cfg)
+	a.now = func() time.Time { return time.Date(2026, 5, 4, 9, 30, 0, 0, time.UTC) }
+	return a, pub
+}
+
+func TestAggregator_FlushSummarizesPerSession(t *testing.T) {
+	summarizer := &fakeSummarizer{summary: "- backup done\n- disk at 91%"}
+	a, pub := newTestAggregator(t, Config{Summarizer: summarizer})
+	ctx := context.Background()
+
+	a.Add(ctx, "telegram:1", SourceCron, "Backup finished")
+	a.Add(ctx, "telegram:1", SourceWatcher, "Disk usage 91%")
+	a.Add(ctx, "telegram:2", SourceJobs, "Report ready")
+	if len(pub.msgs) != 0 {
+		t.Fatalf("published %d messages before the interval ended", len(pub.msgs))
+	}
+
+	a.Flush(ctx)
+
+	if len(pub.msgs) != 2 {
+		t.Fatalf("published %d messages, want one per session", len(pub.msgs))
+	}
+	first := pub.msgs[0]
+	if first.SessionID != "telegram:1" || first.UserID != "1" || first.ChannelType != bus.ChannelTypeTelegram {
+		t.Errorf("digest addressed to %s/%s", first.SessionID, first.UserID)
+	}
+	if !strings.Contains(first.Content, "2 notifications") || !strings.Contains(first.Content, "disk at 91%") {
+		t.Errorf("digest = %q", first.Content)
+	}
+	if first.Metadata[MetadataDigest] != 2 {
+		t.Errorf("metadata = %v", first.Metadata)
+	}
+	// A single notification is delivered unchanged
+	if pub.msgs[1].Content != "Report ready" {
+		t.Errorf("single notification = %q", pub.msgs[1].Content)
+	}
+	if a.Pending("telegram:1") != 0 {
+		t.Error("batch was not cleared after delivery")
+	}
+}
+
+func TestAggregator_SummaryFailureFallsBackToList(t *testing.T) {
+	a, pub := newTestAggregator(t, Config{Summarizer: &fakeSummarizer{err: errors.New("llm down")}})
+	ctx := context.Background()
+
+	a.Add(ctx, "telegram:1", SourceCron, "Backup finished")
+	a.Add(ctx, "telegram:1", SourceCron, "Certificates renewed")
+	a.Flush(ctx)
+
+	want := "📬 Digest: 2 notifications\n\n[09:30, cron]\nBackup finished\n\n[09:30, cron]\nCertificates renewed"
+	if len(pub.msgs) != 1 || pub.msgs[0].Content != want {
+		t.Errorf("digest = %+v, want the list %q", pub.msgs, want)
+	}
+}
+
+func TestAggregator_Add(t *testing.T) {
+	summarizer := &fakeSummarizer{summary: "summary"}
+	a, pub := newTestAggregator(t, Config{Sources: []string{SourceCron}, MaxItems: 3, Summarizer: summarizer})
+	ctx := context.Background()
+
+	if a.Add(ctx, "telegram:1", SourceWatcher, "not batched") {
+		t.Error("Add() accepted a source that is not configured")
+	}
+	if a.Add(ctx, "no-channel", SourceCron, "text") {
+		t.Error("Add() accepted an invalid session ID")
+	}
+
+	// A full batch is delivered before the interval ends
+	for range 3 {
+		if !a.Add(ctx, "telegram:1", SourceCron, "tick") {
+			t.Fatal("Add() rejected a configured source")
+		}
+	}
+	if len(pub.msgs) != 1 || summarizer.items != 3 {
+		t.Errorf("published %d messages with %d items, want the full batch", len(pub.msgs), summarizer.items)
+	}
+}
+
+func TestAggregator_StopDeliversPending(t *testing.T) {
+	summarizer := &fakeSummarizer{summary: "summary"}
+	a, pub := newTestAggregator(t, Config{Interval: time.Hour, Summarizer: summarizer})
+	a.Start(context.Background())
+
+	a.Add(context.Background(), "telegram:1", SourceCron, "one")
+	a.Add(context.Background(), "telegram:1", SourceCron, "two")
+	a.Stop()
+
+	if len(pub.msgs) != 1 || !strings.Contains(pub.msgs[0].Content, "one") {
+		t.Errorf("messages = %+v, want the pending list", pub.msgs)
+	}
+	if summarizer.items != 0 {
+		t.Error("Stop() must not wait for a summary")
+	}
+}
+
+// summaryProvider returns a fixed reply and records the request.
+type summaryProvider struct {
+	req llm.ChatRequest
+}
+
+func (p *summaryProvider) Chat(_ context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.req = req
+	return &llm.ChatResponse{Content: "- all good"}, nil
+}
+
+func (p *summaryProvider) SupportsToolCalling() bool { return false }
+
+func TestLLMSummarizer(t *testing.T) {
+	provider := &summaryProvider{}
+	s := NewLLMSummarizer(provider, "cheap-model")
+
+	summary, err := s.Summarize(context.Background(), []Item{
+		{Source: SourceCron, Content: "Backup finished", Time: time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)},
+		{Source: SourceJobs, Content: strings.Repeat("x", maxItemChars+10), Time: time.Date(2026, 5, 4, 9, 5, 0, 0, time.UTC)},
+	})
+	if err != nil || summary != "- all good" {
+		t.Fatalf("Summarize() = %q, %v", summary, err)
+	}
+	if provider.req.Model != "cheap-model" {
+		t.Errorf("model = %q", provider.req.Model)
+	}
+	prompt := provider.req.Messages[1].Content
+	if !strings.Contains(prompt, "1. [2026-05-04 09:00, cron]\nBackup finished") {
+		t.Errorf("prompt = %q", prompt)
+	}
+	if strings.Contains(prompt, strings.Repeat("x", maxItemChars+1)) {
+		t.Error("long notifications must be truncated")
+	}
+}
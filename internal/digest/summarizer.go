@@ -0,0 +1,54 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+const summarizerPrompt = "You write digests of automated notifications (scheduled tasks, file watchers, " +
+	"background jobs) for the user of a chat assistant. Summarize the notifications below in one short message: " +
+	"group related items, keep numbers, errors, names and links that matter, drop duplicates and routine noise. " +
+	"Use plain text with short bullet points and write in the language of the notifications."
+
+// maxItemChars limits a single notification in the summarizer prompt
+const maxItemChars = 2000
+
+// LLMSummarizer summarizes batches with an LLM.
+type LLMSummarizer struct {
+	provider llm.Provider
+	model    string
+}
+
+// NewLLMSummarizer creates a summarizer using the given provider and model.
+func NewLLMSummarizer(provider llm.Provider, model string) *LLMSummarizer {
+	return &LLMSummarizer{provider: provider, model: model}
+}
+
+// Summarize asks the model for a digest of the notifications.
+func (s *LLMSummarizer) Summarize(ctx context.Context, items []Item) (string, error) {
+	var b strings.Builder
+	for i, item := range items {
+		content := strings.TrimSpace(item.Content)
+		if runes := []rune(content); len(runes) > maxItemChars {
+			content = string(runes[:maxItemChars]) + "…"
+		}
+		fmt.Fprintf(&b, "%d. [%s, %s]\n%s\n\n", i+1, item.Time.Format("2006-01-02 15:04"), item.Source, content)
+	}
+
+	resp, err := s.provider.Chat(ctx, llm.ChatRequest{
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: summarizerPrompt},
+			{Role: llm.RoleUser, Content: b.String()},
+		},
+		Model:       s.model,
+		Temperature: 0.3,
+		MaxTokens:   1024,
+	})
+	if err != nil {
+		return "", fmt.Errorf("digest summary request failed: %w", err)
+	}
+	return resp.Content, nil
+}
@@ -0,0 +1,241 @@
+// Package egress configures how Nexbot reaches external services: an HTTP or
+// SOCKS proxy, an additional CA bundle and DNS overrides. It is shared by the
+// fetch tool, LLM providers and the Telegram client, which is required in
+// networks where direct egress is blocked.
+package egress
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config describes outbound network settings.
+type Config struct {
+	Proxy      string            // Proxy URL: http://, https://, socks5:// or socks5h://
+	NoProxy    []string          // Hosts reached directly (domains match subdomains, CIDRs match IPs)
+	CAFile     string            // PEM bundle trusted in addition to the system roots
+	DNSServers []string          // DNS servers ("host" or "host:port") used instead of the system resolver
+	Hosts      map[string]string // Static host name to IP address overrides
+}
+
+// Network applies Config to HTTP clients and connections.
+type Network struct {
+	proxy    *url.URL
+	noProxy  []string
+	noCIDRs  []netip.Prefix
+	tls      *tls.Config
+	resolver *Resolver
+}
+
+// New validates cfg and creates a Network.
+func New(cfg Config) (*Network, error) {
+	n := &Network{}
+
+	if cfg.Proxy != "" {
+		proxy, err := ParseProxy(cfg.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		n.proxy = proxy
+	}
+
+	for _, entry := range cfg.NoProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			n.noCIDRs = append(n.noCIDRs, prefix)
+			continue
+		}
+		n.noProxy = append(n.noProxy, strings.TrimSuffix(strings.TrimPrefix(entry, "*."), "."))
+	}
+
+	if cfg.CAFile != "" {
+		tlsConfig, err := loadCA(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		n.tls = tlsConfig
+	}
+
+	resolver, err := NewResolver(cfg.DNSServers, cfg.Hosts)
+	if err != nil {
+		return nil, err
+	}
+	n.resolver = resolver
+
+	return n, nil
+}
+
+// ParseProxy parses and validates a proxy URL.
+func ParseProxy(raw string) (*url.URL, error) {
+	proxy, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch proxy.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: unsupported scheme %q (expected: http, https, socks5, socks5h)", raw, proxy.Scheme)
+	}
+	if proxy.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", raw)
+	}
+	return proxy, nil
+}
+
+// loadCA builds a TLS config trusting the system roots and the PEM bundle at path.
+func loadCA(path string) (*tls.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA file %s", path)
+	}
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
+}
+
+// Configured reports whether any outbound setting differs from the defaults.
+func (n *Network) Configured() bool {
+	return n.proxy != nil || n.tls != nil || n.resolver.Custom()
+}
+
+// Proxy returns the configured proxy for req, or nil when no proxy is
+// configured or the request host is listed in NoProxy.
+func (n *Network) Proxy(req *http.Request) (*url.URL, error) {
+	if n.proxy == nil || n.bypassProxy(req.URL.Hostname()) {
+		return nil, nil
+	}
+	return n.proxy, nil
+}
+
+// ProxyAddr returns the "host:port" address of the configured proxy, or "".
+func (n *Network) ProxyAddr() string {
+	if n.proxy == nil {
+		return ""
+	}
+	port := n.proxy.Port()
+	if port == "" {
+		switch n.proxy.Scheme {
+		case "https":
+			port = "443"
+		case "socks5", "socks5h":
+			port = "1080"
+		default:
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(n.proxy.Hostname(), port)
+}
+
+// bypassProxy reports whether host is listed in NoProxy.
+func (n *Network) bypassProxy(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if addr, err := netip.ParseAddr(host); err == nil {
+		for _, prefix := range n.noCIDRs {
+			if prefix.Contains(addr.Unmap()) {
+				return true
+			}
+		}
+	}
+	for _, domain := range n.noProxy {
+		if domain == "*" || host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// TLSConfig returns a copy of the TLS config with the custom CA bundle,
+// or nil when no CA file is configured.
+func (n *Network) TLSConfig() *tls.Config {
+	if n.tls == nil {
+		return nil
+	}
+	return n.tls.Clone()
+}
+
+// Resolver returns the resolver applying the DNS overrides.
+func (n *Network) Resolver() *Resolver {
+	return n.resolver
+}
+
+// DialContext returns a dial function that resolves host names with Resolver.
+func (n *Network) DialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if !n.resolver.Custom() {
+			return dialer.DialContext(ctx, network, address)
+		}
+
+		addrs, err := n.resolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("no addresses found for %s", host)
+		}
+
+		var lastErr error
+		for _, addr := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		return nil, lastErr
+	}
+}
+
+// Transport returns an HTTP transport with the proxy, CA bundle and DNS
+// overrides applied. Without a configured proxy, the environment proxy
+// settings (HTTP_PROXY, HTTPS_PROXY, NO_PROXY) are used, as in net/http.
+func (n *Network) Transport(dialTimeout time.Duration) *http.Transport {
+	dialer := &net.Dialer{Timeout: dialTimeout, KeepAlive: 30 * time.Second}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           n.DialContext(dialer),
+		TLSClientConfig:       n.TLSConfig(),
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if n.proxy != nil {
+		transport.Proxy = n.Proxy
+	}
+	return transport
+}
+
+// Client returns an HTTP client using Transport. A zero timeout means no
+// overall request timeout (for long polling); connections still time out after 30s.
+func (n *Network) Client(timeout time.Duration) *http.Client {
+	dialTimeout := timeout
+	if dialTimeout == 0 {
+		dialTimeout = 30 * time.Second
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: n.Transport(dialTimeout),
+	}
+}
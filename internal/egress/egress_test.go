@@ -0,0 +1,123 @@
+package egress
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"unsupported proxy scheme", Config{Proxy: "ftp://proxy:21"}, "unsupported scheme"},
+		{"proxy without host", Config{Proxy: "http://"}, "missing host"},
+		{"invalid DNS server", Config{DNSServers: []string{"dns.example"}}, "invalid DNS server"},
+		{"invalid host override", Config{Hosts: map[string]string{"api.example": "nope"}}, "invalid address"},
+		{"missing CA file", Config{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, "failed to read CA file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("New() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	n, err := New(Config{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if n.Configured() {
+		t.Error("Expected empty config not to be configured")
+	}
+}
+
+func TestNetwork_Proxy(t *testing.T) {
+	n, err := New(Config{
+		Proxy:   "socks5://proxy.corp:1080",
+		NoProxy: []string{"*.internal.corp", "10.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if n.ProxyAddr() != "proxy.corp:1080" {
+		t.Errorf("ProxyAddr() = %q", n.ProxyAddr())
+	}
+
+	tests := []struct {
+		url     string
+		proxied bool
+	}{
+		{"https://api.example.com/", true},
+		{"https://git.internal.corp/", false},
+		{"http://internal.corp/", false},
+		{"http://10.1.2.3/", false},
+		{"http://192.168.1.1/", true},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+		proxy, _ := n.Proxy(req)
+		if (proxy != nil) != tt.proxied {
+			t.Errorf("Proxy(%s) = %v, want proxied=%v", tt.url, proxy, tt.proxied)
+		}
+	}
+}
+
+func TestResolver_Hosts(t *testing.T) {
+	r, err := NewResolver(nil, map[string]string{"API.Example.": "10.0.0.7"})
+	if err != nil {
+		t.Fatalf("NewResolver() error = %v", err)
+	}
+	addrs, err := r.LookupNetIP(context.Background(), "ip", "api.example")
+	if err != nil || len(addrs) != 1 || addrs[0] != netip.MustParseAddr("10.0.0.7") {
+		t.Errorf("LookupNetIP() = %v, %v", addrs, err)
+	}
+}
+
+func TestNetwork_Client_HostsAndCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, pemData, 0600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	// The test certificate is issued for example.com; route it to the test server
+	n, err := New(Config{
+		CAFile: caFile,
+		Hosts:  map[string]string{"example.com": "127.0.0.1"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if !n.Configured() {
+		t.Error("Expected network to be configured")
+	}
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	resp, err := n.Client(time.Second).Get("https://example.com:" + port + "/")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "ok" {
+		t.Errorf("Body = %q, want ok", body)
+	}
+}
@@ -0,0 +1,100 @@
+package egress
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+)
+
+// Resolver resolves host names using static overrides and, optionally,
+// dedicated DNS servers instead of the system resolver.
+type Resolver struct {
+	hosts    map[string]netip.Addr
+	servers  []string
+	resolver *net.Resolver
+	next     atomic.Uint32
+}
+
+// NewResolver creates a resolver. Servers are IP addresses with an optional
+// port (53 by default); hosts maps host names to IP addresses.
+func NewResolver(servers []string, hosts map[string]string) (*Resolver, error) {
+	r := &Resolver{
+		hosts:    make(map[string]netip.Addr, len(hosts)),
+		resolver: net.DefaultResolver,
+	}
+
+	for host, ip := range hosts {
+		addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+		if err != nil {
+			return nil, fmt.Errorf("invalid address for host %s: %s (expected: IP address)", host, ip)
+		}
+		r.hosts[normalizeHost(host)] = addr
+	}
+
+	for _, server := range servers {
+		address, err := serverAddress(server)
+		if err != nil {
+			return nil, err
+		}
+		r.servers = append(r.servers, address)
+	}
+	if len(r.servers) > 0 {
+		r.resolver = &net.Resolver{PreferGo: true, Dial: r.dialServer}
+	}
+
+	return r, nil
+}
+
+// serverAddress normalizes a DNS server to "ip:port".
+func serverAddress(server string) (string, error) {
+	server = strings.TrimSpace(server)
+	if addr, err := netip.ParseAddr(server); err == nil {
+		return net.JoinHostPort(addr.String(), "53"), nil
+	}
+	addrPort, err := netip.ParseAddrPort(server)
+	if err != nil {
+		return "", fmt.Errorf("invalid DNS server: %s (expected: IP address with optional port)", server)
+	}
+	return addrPort.String(), nil
+}
+
+// dialServer connects to the configured DNS servers in turn, ignoring the
+// server chosen by the system configuration.
+func (r *Resolver) dialServer(ctx context.Context, network, _ string) (net.Conn, error) {
+	var dialer net.Dialer
+	start := int(r.next.Add(1))
+	var lastErr error
+	for i := range r.servers {
+		conn, err := dialer.DialContext(ctx, network, r.servers[(start+i)%len(r.servers)])
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// Custom reports whether the resolver differs from the system resolver.
+func (r *Resolver) Custom() bool {
+	return len(r.hosts) > 0 || len(r.servers) > 0
+}
+
+// LookupNetIP resolves host to IP addresses. IP literals are returned as is,
+// static overrides take precedence over DNS.
+func (r *Resolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr}, nil
+	}
+	if addr, ok := r.hosts[normalizeHost(host)]; ok {
+		return []netip.Addr{addr}, nil
+	}
+	return r.resolver.LookupNetIP(ctx, network, host)
+}
+
+// normalizeHost lowercases host and strips the trailing dot.
+func normalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+}
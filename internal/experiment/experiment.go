@@ -0,0 +1,111 @@
+// Package experiment runs prompt A/B tests: sessions are split between prompt
+// variants by weight, the variant that served a session is recorded with it
+// and reports compare the feedback collected for each variant.
+package experiment
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// labelSeparator separates the experiment and variant names in a label
+const labelSeparator = "/"
+
+// Variant is a prompt variant of an experiment.
+type Variant struct {
+	Name   string
+	Weight int    // Share of sessions relative to the other variants (0 means 1)
+	Prompt string // Workspace file appended to the system prompt (empty keeps the base prompt)
+
+	// Sampling overrides the agent sampling parameters for the variant
+	Sampling llm.Sampling
+}
+
+// Experiment splits sessions between prompt variants.
+type Experiment struct {
+	name     string
+	variants []Variant
+	total    int
+}
+
+// New creates an experiment. Variant names must be unique; weights can't be
+// negative.
+func New(name string, variants []Variant) (*Experiment, error) {
+	if name == "" || strings.Contains(name, labelSeparator) {
+		return nil, fmt.Errorf("invalid experiment name %q", name)
+	}
+	if len(variants) < 2 {
+		return nil, fmt.Errorf("experiment %s needs at least 2 variants (got: %d)", name, len(variants))
+	}
+
+	e := &Experiment{name: name, variants: make([]Variant, 0, len(variants))}
+	seen := make(map[string]bool, len(variants))
+	for _, v := range variants {
+		if v.Name == "" || strings.Contains(v.Name, labelSeparator) {
+			return nil, fmt.Errorf("invalid variant name %q", v.Name)
+		}
+		if seen[v.Name] {
+			return nil, fmt.Errorf("duplicate variant %s", v.Name)
+		}
+		seen[v.Name] = true
+
+		if v.Weight < 0 {
+			return nil, fmt.Errorf("variant %s: weight must be positive (got: %d)", v.Name, v.Weight)
+		}
+		if v.Weight == 0 {
+			v.Weight = 1
+		}
+		e.total += v.Weight
+		e.variants = append(e.variants, v)
+	}
+	return e, nil
+}
+
+// Name returns the experiment name.
+func (e *Experiment) Name() string {
+	return e.name
+}
+
+// Variants returns the variants in configuration order.
+func (e *Experiment) Variants() []Variant {
+	return e.variants
+}
+
+// Assign picks the variant of a session. The choice is deterministic, so a
+// session keeps its variant as long as the variants don't change.
+func (e *Experiment) Assign(sessionID string) Variant {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(e.name + labelSeparator + sessionID))
+	point := int(h.Sum32() % uint32(e.total))
+
+	for _, v := range e.variants {
+		if point < v.Weight {
+			return v
+		}
+		point -= v.Weight
+	}
+	return e.variants[len(e.variants)-1]
+}
+
+// Label returns the label recorded for sessions and feedback served by a
+// variant: "experiment/variant".
+func (e *Experiment) Label(v Variant) string {
+	return e.name + labelSeparator + v.Name
+}
+
+// Lookup returns the variant of a label recorded by this experiment.
+func (e *Experiment) Lookup(label string) (Variant, bool) {
+	name, ok := strings.CutPrefix(label, e.name+labelSeparator)
+	if !ok {
+		return Variant{}, false
+	}
+	for _, v := range e.variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Variant{}, false
+}
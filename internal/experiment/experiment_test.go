@@ -0,0 +1,113 @@
+package experiment
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/feedback"
+)
+
+func TestNew_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		exp      string
+		variants []Variant
+	}{
+		{"empty name", "", []Variant{{Name: "a"}, {Name: "b"}}},
+		{"single variant", "tone", []Variant{{Name: "a"}}},
+		{"duplicate variant", "tone", []Variant{{Name: "a"}, {Name: "a"}}},
+		{"separator in variant", "tone", []Variant{{Name: "a/b"}, {Name: "c"}}},
+		{"negative weight", "tone", []Variant{{Name: "a", Weight: -1}, {Name: "b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.exp, tt.variants); err == nil {
+				t.Errorf("New() should fail")
+			}
+		})
+	}
+}
+
+func TestAssign(t *testing.T) {
+	exp, err := New("tone", []Variant{{Name: "control", Weight: 3}, {Name: "concise"}})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	counts := map[string]int{}
+	for i := range 4000 {
+		sessionID := fmt.Sprintf("telegram:%d", i)
+		v := exp.Assign(sessionID)
+		if again := exp.Assign(sessionID); again.Name != v.Name {
+			t.Fatalf("Assign(%s) is not stable: %s, then %s", sessionID, v.Name, again.Name)
+		}
+		counts[v.Name]++
+	}
+
+	// 3:1 split within a few percent
+	if share := float64(counts["control"]) / 4000; share < 0.70 || share > 0.80 {
+		t.Errorf("control share = %.2f, want about 0.75 (counts: %v)", share, counts)
+	}
+}
+
+func TestLabelLookup(t *testing.T) {
+	exp, err := New("tone", []Variant{{Name: "control"}, {Name: "concise", Prompt: "prompts/concise.md"}})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	label := exp.Label(exp.Variants()[1])
+	if label != "tone/concise" {
+		t.Errorf("Label() = %q", label)
+	}
+	if v, ok := exp.Lookup(label); !ok || v.Prompt != "prompts/concise.md" {
+		t.Errorf("Lookup(%q) = %+v, %v", label, v, ok)
+	}
+	for _, other := range []string{"length/concise", "tone/removed", "default"} {
+		if _, ok := exp.Lookup(other); ok {
+			t.Errorf("Lookup(%q) should fail", other)
+		}
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	exp, err := New("tone", []Variant{{Name: "control"}, {Name: "concise"}})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	now := time.Now()
+	assignments := []Assignment{
+		{SessionID: "telegram:1", Label: "tone/control", Messages: 10},
+		{SessionID: "telegram:2", Label: "tone/control", Messages: 6},
+		{SessionID: "telegram:3", Label: "tone/concise", Messages: 4},
+		{SessionID: "telegram:4", Label: "length/short", Messages: 2},
+	}
+	entries := []feedback.Entry{
+		{Timestamp: now, Profile: "tone/control", Score: 1},
+		{Timestamp: now, Profile: "tone/control", Score: -1},
+		{Timestamp: now, Profile: "tone/concise", Score: 1},
+		{Timestamp: now.AddDate(0, 0, -30), Profile: "tone/concise", Score: -1},
+		{Timestamp: now, Profile: "default", Score: -1},
+	}
+
+	report := exp.BuildReport(assignments, entries, now.AddDate(0, 0, -7))
+	if len(report.Outcomes) != 2 {
+		t.Fatalf("Outcomes = %+v, want 2", report.Outcomes)
+	}
+
+	control, concise := report.Outcomes[0], report.Outcomes[1]
+	if control.Sessions != 2 || control.MessagesPerSession() != 8 || control.Feedback.Satisfaction() != 0.5 {
+		t.Errorf("control outcome = %+v", control)
+	}
+	if concise.Sessions != 1 || concise.Feedback.Count != 1 || concise.Feedback.Satisfaction() != 1 {
+		t.Errorf("concise outcome = %+v", concise)
+	}
+
+	text := report.Format()
+	if !strings.Contains(text, "Experiment tone") || !strings.Contains(text, "100%") {
+		t.Errorf("Format() = %s", text)
+	}
+}
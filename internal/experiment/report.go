@@ -0,0 +1,93 @@
+package experiment
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/feedback"
+)
+
+// Assignment is a session served by a variant.
+type Assignment struct {
+	SessionID string
+	Label     string // Variant label recorded for the session
+	Messages  int    // Messages in the session
+}
+
+// Outcome compares the sessions and feedback of one variant.
+type Outcome struct {
+	Variant  string
+	Sessions int
+	Messages int
+	Feedback feedback.Bucket
+}
+
+// MessagesPerSession returns the average session length, a proxy of how
+// much back-and-forth a variant needs.
+func (o Outcome) MessagesPerSession() float64 {
+	if o.Sessions == 0 {
+		return 0
+	}
+	return float64(o.Messages) / float64(o.Sessions)
+}
+
+// Report compares the variants of an experiment.
+type Report struct {
+	Experiment string
+	Since      time.Time
+	Outcomes   []Outcome // In variant order
+}
+
+// BuildReport aggregates the sessions and the feedback recorded at or after
+// since (zero means all) for each variant. Sessions and feedback of other
+// experiments are ignored.
+func (e *Experiment) BuildReport(assignments []Assignment, entries []feedback.Entry, since time.Time) Report {
+	report := Report{Experiment: e.name, Since: since}
+	index := make(map[string]int, len(e.variants))
+	for i, v := range e.variants {
+		report.Outcomes = append(report.Outcomes, Outcome{Variant: v.Name, Feedback: feedback.Bucket{Name: v.Name}})
+		index[e.Label(v)] = i
+	}
+
+	for _, a := range assignments {
+		if i, ok := index[a.Label]; ok {
+			report.Outcomes[i].Sessions++
+			report.Outcomes[i].Messages += a.Messages
+		}
+	}
+
+	var rated []feedback.Entry
+	for _, entry := range entries {
+		if _, ok := index[entry.Profile]; ok {
+			rated = append(rated, entry)
+		}
+	}
+	for _, bucket := range feedback.BuildReport(rated, since).ByProfile {
+		i := index[bucket.Name]
+		bucket.Name = report.Outcomes[i].Variant
+		report.Outcomes[i].Feedback = bucket
+	}
+	return report
+}
+
+// Format renders the report as a plain text table.
+func (r Report) Format() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Experiment %s", r.Experiment)
+	if !r.Since.IsZero() {
+		fmt.Fprintf(&b, " (feedback since %s)", r.Since.Format("2006-01-02"))
+	}
+	b.WriteString("\n\n")
+
+	fmt.Fprintf(&b, "  %-20s %8s %9s %8s %6s %6s %8s\n", "VARIANT", "SESSIONS", "MSG/SESS", "FEEDBACK", "👍", "👎", "SATISF.")
+	for _, o := range r.Outcomes {
+		satisfaction := "n/a"
+		if s := o.Feedback.Satisfaction(); s >= 0 {
+			satisfaction = fmt.Sprintf("%.0f%%", s*100)
+		}
+		fmt.Fprintf(&b, "  %-20s %8d %9.1f %8d %6d %6d %8s\n",
+			o.Variant, o.Sessions, o.MessagesPerSession(), o.Feedback.Count, o.Feedback.Positive, o.Feedback.Negative, satisfaction)
+	}
+	return b.String()
+}
@@ -0,0 +1,268 @@
+// Package export pushes session transcripts to external note-taking tools:
+// Markdown notes in an Obsidian vault folder or pages in Notion. Exports run
+// on demand (/export notion) or periodically for sessions changed since their
+// last export. Each target keeps one note per session: a repeated export
+// replaces the note instead of adding another one.
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/agent/transcript"
+)
+
+const (
+	// StateSubdirectory is the workspace subdirectory holding export state
+	StateSubdirectory = "export"
+
+	// StateFilename is the file recording what has been exported where
+	StateFilename = "state.json"
+)
+
+var (
+	// ErrUnknownTarget is returned for targets that are not configured.
+	ErrUnknownTarget = errors.New("unknown export target")
+
+	// ErrEmptySession is returned for sessions without messages.
+	ErrEmptySession = errors.New("session is empty")
+)
+
+// Document is a rendered session transcript.
+type Document struct {
+	SessionID string
+	Title     string
+	Markdown  string
+	UpdatedAt time.Time
+}
+
+// Record describes the note a session was last exported to.
+type Record struct {
+	Ref        string    `json:"ref"`      // Target-specific note reference (file path, page ID)
+	Location   string    `json:"location"` // Where the user finds the note (path, URL)
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// Target is a destination for transcripts.
+type Target interface {
+	// Name returns the target name used in commands ("obsidian", "notion").
+	Name() string
+
+	// Export writes the document. previous is the record of the last export
+	// of the same session (nil if none) so the target can replace that note.
+	Export(ctx context.Context, doc Document, previous *Record) (Record, error)
+}
+
+// SessionSource provides sessions to export (implemented by loop.Loop).
+type SessionSource interface {
+	ListSessions(ctx context.Context) ([]session.Info, error)
+	GetSessionEntries(ctx context.Context, sessionID string) ([]session.Entry, error)
+}
+
+// Exporter exports sessions to the configured targets and remembers the
+// exported notes in <workspace>/export/state.json.
+type Exporter struct {
+	mu        sync.Mutex
+	source    SessionSource
+	targets   []Target
+	statePath string
+}
+
+// NewExporter creates an exporter for the given targets.
+func NewExporter(source SessionSource, workspacePath string, targets ...Target) *Exporter {
+	return &Exporter{
+		source:    source,
+		targets:   targets,
+		statePath: filepath.Join(workspacePath, StateSubdirectory, StateFilename),
+	}
+}
+
+// Targets returns the names of the configured targets.
+func (e *Exporter) Targets() []string {
+	names := make([]string, 0, len(e.targets))
+	for _, t := range e.targets {
+		names = append(names, t.Name())
+	}
+	return names
+}
+
+// HasTarget reports whether a target with the given name is configured.
+func (e *Exporter) HasTarget(name string) bool {
+	return e.target(name) != nil
+}
+
+func (e *Exporter) target(name string) Target {
+	for _, t := range e.targets {
+		if t.Name() == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// Export exports a session to the named target and returns the location of
+// the note.
+func (e *Exporter) Export(ctx context.Context, sessionID, target string) (string, error) {
+	t := e.target(target)
+	if t == nil {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTarget, target)
+	}
+
+	sessions, err := e.source.ListSessions(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list sessions: %w", err)
+	}
+	info := session.Info{ID: sessionID}
+	for _, s := range sessions {
+		if s.ID == sessionID || slices.Contains(s.BoundTo, sessionID) {
+			info = s
+			break
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	record, err := e.exportSession(ctx, t, info)
+	if err != nil {
+		return "", err
+	}
+	return record.Location, nil
+}
+
+// ExportChanged exports every session changed since its last export to all
+// targets. Returns the number of exported notes; failures of individual
+// sessions are joined into the returned error.
+func (e *Exporter) ExportChanged(ctx context.Context) (int, error) {
+	sessions, err := e.source.ListSessions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	state, err := e.readState()
+	if err != nil {
+		return 0, err
+	}
+
+	exported := 0
+	var errs []error
+	for _, t := range e.targets {
+		for _, info := range sessions {
+			if ctx.Err() != nil {
+				return exported, ctx.Err()
+			}
+			if info.MessageCount == 0 {
+				continue
+			}
+			if record, ok := state[t.Name()][info.ID]; ok && !info.LastActivity.After(record.ExportedAt) {
+				continue
+			}
+			if _, err := e.exportSession(ctx, t, info); err != nil {
+				errs = append(errs, fmt.Errorf("%s to %s: %w", info.ID, t.Name(), err))
+				continue
+			}
+			exported++
+		}
+	}
+	return exported, errors.Join(errs...)
+}
+
+// exportSession renders a session, exports it and records the result.
+// Callers must hold e.mu.
+func (e *Exporter) exportSession(ctx context.Context, t Target, info session.Info) (Record, error) {
+	entries, err := e.source.GetSessionEntries(ctx, info.ID)
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to read session: %w", err)
+	}
+	if len(entries) == 0 {
+		return Record{}, fmt.Errorf("%w: %s", ErrEmptySession, info.ID)
+	}
+
+	title := info.Title
+	if title == "" {
+		title = fmt.Sprintf("Session %s", info.ID)
+	}
+	markdown, err := transcript.Render(info.ID, entries, transcript.FormatMarkdown, transcript.Options{Title: title})
+	if err != nil {
+		return Record{}, err
+	}
+
+	state, err := e.readState()
+	if err != nil {
+		return Record{}, err
+	}
+	var previous *Record
+	if record, ok := state[t.Name()][info.ID]; ok {
+		previous = &record
+	}
+
+	doc := Document{
+		SessionID: info.ID,
+		Title:     title,
+		Markdown:  markdown,
+		UpdatedAt: info.LastActivity,
+	}
+	record, err := t.Export(ctx, doc, previous)
+	if err != nil {
+		return Record{}, err
+	}
+	record.ExportedAt = time.Now()
+
+	if state[t.Name()] == nil {
+		state[t.Name()] = make(map[string]Record)
+	}
+	state[t.Name()][info.ID] = record
+	if err := e.writeState(state); err != nil {
+		return Record{}, err
+	}
+	return record, nil
+}
+
+// exportState maps target name -> session ID -> last export.
+type exportState map[string]map[string]Record
+
+func (e *Exporter) readState() (exportState, error) {
+	state := make(exportState)
+	data, err := os.ReadFile(e.statePath)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse export state: %w", err)
+	}
+	return state, nil
+}
+
+func (e *Exporter) writeState(state exportState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal export state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(e.statePath), 0755); err != nil {
+		return fmt.Errorf("failed to create export state directory: %w", err)
+	}
+
+	// Write to temp file first, then rename for atomicity
+	tmpPath := e.statePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write export state: %w", err)
+	}
+	if err := os.Rename(tmpPath, e.statePath); err != nil {
+		return fmt.Errorf("failed to save export state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+type fakeSource struct {
+	sessions []session.Info
+	entries  map[string][]session.Entry
+}
+
+func (s *fakeSource) ListSessions(ctx context.Context) ([]session.Info, error) {
+	return s.sessions, nil
+}
+
+func (s *fakeSource) GetSessionEntries(ctx context.Context, sessionID string) ([]session.Entry, error) {
+	return s.entries[sessionID], nil
+}
+
+type fakeTarget struct {
+	docs      []Document
+	previous  []*Record
+	exportErr error
+}
+
+func (t *fakeTarget) Name() string { return "fake" }
+
+func (t *fakeTarget) Export(ctx context.Context, doc Document, previous *Record) (Record, error) {
+	if t.exportErr != nil {
+		return Record{}, t.exportErr
+	}
+	t.docs = append(t.docs, doc)
+	t.previous = append(t.previous, previous)
+	return Record{Ref: doc.SessionID, Location: "fake://" + doc.SessionID}, nil
+}
+
+func newFakeSource() *fakeSource {
+	entry := session.Entry{Message: llm.Message{Role: llm.RoleUser, Content: "hello"}}
+	return &fakeSource{
+		sessions: []session.Info{
+			{ID: "work", Name: "work", BoundTo: []string{"telegram:1"}, Title: "Work notes", MessageCount: 1, LastActivity: time.Now().Add(-time.Hour)},
+			{ID: "telegram:2", MessageCount: 1, LastActivity: time.Now().Add(-time.Hour)},
+			{ID: "telegram:3"},
+		},
+		entries: map[string][]session.Entry{
+			"work":       {entry},
+			"telegram:2": {entry},
+		},
+	}
+}
+
+func TestExporter_Export(t *testing.T) {
+	target := &fakeTarget{}
+	exporter := NewExporter(newFakeSource(), t.TempDir(), target)
+
+	// A bound chat session exports the named session it is bound to
+	location, err := exporter.Export(context.Background(), "telegram:1", "fake")
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if location != "fake://work" {
+		t.Errorf("Export() location = %q", location)
+	}
+	doc := target.docs[0]
+	if doc.SessionID != "work" || doc.Title != "Work notes" || target.previous[0] != nil {
+		t.Errorf("exported document = %+v, previous = %v", doc, target.previous[0])
+	}
+
+	// The second export gets the record of the first one
+	if _, err := exporter.Export(context.Background(), "work", "fake"); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if target.previous[1] == nil || target.previous[1].Ref != "work" {
+		t.Errorf("previous record = %+v", target.previous[1])
+	}
+
+	if _, err := exporter.Export(context.Background(), "work", "notion"); !errors.Is(err, ErrUnknownTarget) {
+		t.Errorf("Export(unknown target) error = %v, want ErrUnknownTarget", err)
+	}
+	if _, err := exporter.Export(context.Background(), "telegram:3", "fake"); !errors.Is(err, ErrEmptySession) {
+		t.Errorf("Export(empty session) error = %v, want ErrEmptySession", err)
+	}
+}
+
+func TestExporter_ExportChanged(t *testing.T) {
+	source := newFakeSource()
+	target := &fakeTarget{}
+	exporter := NewExporter(source, t.TempDir(), target)
+
+	exported, err := exporter.ExportChanged(context.Background())
+	if err != nil || exported != 2 {
+		t.Fatalf("ExportChanged() = %d, %v; want 2", exported, err)
+	}
+
+	// Nothing changed since the export
+	exported, err = exporter.ExportChanged(context.Background())
+	if err != nil || exported != 0 {
+		t.Errorf("repeated ExportChanged() = %d, %v; want 0", exported, err)
+	}
+
+	source.sessions[1].LastActivity = time.Now().Add(time.Minute)
+	exported, err = exporter.ExportChanged(context.Background())
+	if err != nil || exported != 1 {
+		t.Errorf("ExportChanged() after activity = %d, %v; want 1", exported, err)
+	}
+	if last := target.docs[len(target.docs)-1]; last.SessionID != "telegram:2" {
+		t.Errorf("exported session = %s, want telegram:2", last.SessionID)
+	}
+
+	// Failures are reported and retried on the next run
+	source.sessions[1].LastActivity = time.Now().Add(time.Hour)
+	target.exportErr = errors.New("offline")
+	if _, err := exporter.ExportChanged(context.Background()); err == nil {
+		t.Error("ExportChanged() error = nil, want export failure")
+	}
+	target.exportErr = nil
+	if exported, _ := exporter.ExportChanged(context.Background()); exported != 1 {
+		t.Errorf("ExportChanged() after failure = %d, want 1", exported)
+	}
+}
@@ -0,0 +1,236 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// notionVersion is the Notion API version the requests are written for
+	notionVersion = "2022-06-28"
+
+	// notionMaxText is the maximum length of a rich text object
+	notionMaxText = 2000
+
+	// notionMaxChildren is the maximum number of blocks per request
+	notionMaxChildren = 100
+
+	// maxErrorBody limits how much of an error response is read
+	maxErrorBody = 4096
+)
+
+// NotionTarget creates a Notion page per session under a parent page.
+type NotionTarget struct {
+	token        string
+	parentPageID string
+	apiURL       string
+	client       *http.Client
+}
+
+// NewNotionTarget creates a target adding pages under parentPageID.
+func NewNotionTarget(token, parentPageID, apiURL string, timeout time.Duration) *NotionTarget {
+	return &NotionTarget{
+		token:        token,
+		parentPageID: parentPageID,
+		apiURL:       strings.TrimRight(apiURL, "/"),
+		client:       &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the target name.
+func (t *NotionTarget) Name() string {
+	return "notion"
+}
+
+// Export creates a page with the transcript and archives the page of the
+// previous export. Notion pages cannot be rewritten in a single request, so
+// a changed session gets a new page.
+func (t *NotionTarget) Export(ctx context.Context, doc Document, previous *Record) (Record, error) {
+	if previous != nil {
+		err := t.request(ctx, http.MethodPatch, "/v1/pages/"+previous.Ref, map[string]any{"archived": true}, nil)
+		if err != nil && !isNotFound(err) {
+			return Record{}, fmt.Errorf("failed to archive previous notion page: %w", err)
+		}
+	}
+
+	blocks := notionBlocks(doc.Markdown, doc.Title)
+	first := blocks[:min(len(blocks), notionMaxChildren)]
+
+	var page struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}
+	body := map[string]any{
+		"parent": map[string]any{"page_id": t.parentPageID},
+		"properties": map[string]any{
+			"title": map[string]any{"title": richText(doc.Title)},
+		},
+		"children": first,
+	}
+	if err := t.request(ctx, http.MethodPost, "/v1/pages", body, &page); err != nil {
+		return Record{}, fmt.Errorf("failed to create notion page: %w", err)
+	}
+
+	for rest := blocks[len(first):]; len(rest) > 0; {
+		chunk := rest[:min(len(rest), notionMaxChildren)]
+		rest = rest[len(chunk):]
+		err := t.request(ctx, http.MethodPatch, "/v1/blocks/"+page.ID+"/children", map[string]any{"children": chunk}, nil)
+		if err != nil {
+			return Record{}, fmt.Errorf("failed to append to notion page: %w", err)
+		}
+	}
+
+	return Record{Ref: page.ID, Location: page.URL}, nil
+}
+
+// notionStatusError is returned for unsuccessful Notion responses.
+type notionStatusError struct {
+	status int
+	body   string
+}
+
+func (e *notionStatusError) Error() string {
+	return fmt.Sprintf("notion request failed with status %d: %s", e.status, e.body)
+}
+
+func isNotFound(err error) bool {
+	var statusErr *notionStatusError
+	return errors.As(err, &statusErr) && statusErr.status == http.StatusNotFound
+}
+
+// request sends a JSON request to the Notion API and decodes the response into out.
+func (t *NotionTarget) request(ctx context.Context, method, path string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notion request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, t.apiURL+path, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create notion request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	req.Header.Set("Notion-Version", notionVersion)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("notion request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		errBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &notionStatusError{status: resp.StatusCode, body: strings.TrimSpace(string(errBody))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode notion response: %w", err)
+	}
+	return nil
+}
+
+// notionBlocks converts a Markdown transcript into Notion blocks: headings,
+// code blocks for fenced code and paragraphs for everything else. A leading
+// heading equal to the title is dropped, the page title already shows it.
+func notionBlocks(markdown, title string) []map[string]any {
+	blocks := []map[string]any{}
+	var paragraph []string
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, textBlock("paragraph", strings.Join(paragraph, "\n")))
+			paragraph = nil
+		}
+	}
+
+	lines := strings.Split(markdown, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if fence := fenceMarker(line); fence != "" {
+			flush()
+			var code []string
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != fence; i++ {
+				code = append(code, lines[i])
+			}
+			blocks = append(blocks, map[string]any{
+				"object": "block",
+				"type":   "code",
+				"code": map[string]any{
+					"rich_text": richText(strings.Join(code, "\n")),
+					"language":  "plain text",
+				},
+			})
+			continue
+		}
+
+		if heading, text := headingLevel(line); heading != "" {
+			flush()
+			if len(blocks) == 0 && heading == "heading_1" && text == title {
+				continue
+			}
+			blocks = append(blocks, textBlock(heading, text))
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	flush()
+	return blocks
+}
+
+// fenceMarker returns the backticks opening a fenced code block, or "".
+func fenceMarker(line string) string {
+	trimmed := strings.TrimLeft(line, "`")
+	if n := len(line) - len(trimmed); n >= 3 {
+		return line[:n]
+	}
+	return ""
+}
+
+// headingLevel returns the Notion block type and text of a Markdown heading.
+func headingLevel(line string) (string, string) {
+	for level, prefix := range []string{"# ", "## ", "### "} {
+		if strings.HasPrefix(line, prefix) {
+			return fmt.Sprintf("heading_%d", level+1), strings.TrimSpace(line[len(prefix):])
+		}
+	}
+	return "", ""
+}
+
+func textBlock(blockType, text string) map[string]any {
+	return map[string]any{
+		"object":  "block",
+		"type":    blockType,
+		blockType: map[string]any{"rich_text": richText(text)},
+	}
+}
+
+// richText splits text into rich text objects within the Notion length limit.
+func richText(text string) []map[string]any {
+	runes := []rune(text)
+	parts := []map[string]any{}
+	for len(runes) > 0 {
+		n := min(len(runes), notionMaxText)
+		parts = append(parts, map[string]any{
+			"type": "text",
+			"text": map[string]any{"content": string(runes[:n])},
+		})
+		runes = runes[n:]
+	}
+	return parts
+}
@@ -0,0 +1,111 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// notionServer records the requests sent to a fake Notion API.
+type notionServer struct {
+	mu       sync.Mutex
+	requests []string
+	bodies   []map[string]any
+}
+
+func (s *notionServer) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("Notion-Version") != notionVersion {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		s.bodies = append(s.bodies, body)
+		s.mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/v1/pages/gone":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPost:
+			_, _ = fmt.Fprint(w, `{"id":"page-1","url":"https://notion.so/page-1"}`)
+		default:
+			_, _ = fmt.Fprint(w, `{}`)
+		}
+	}
+}
+
+func TestNotionTarget_Export(t *testing.T) {
+	server := &notionServer{}
+	ts := httptest.NewServer(server.handler(t))
+	defer ts.Close()
+
+	// More blocks than fit into a single request
+	var md strings.Builder
+	md.WriteString("# Trip plan\n\n")
+	for i := range 150 {
+		fmt.Fprintf(&md, "message %d\n\n", i)
+	}
+
+	target := NewNotionTarget("secret", "parent", ts.URL+"/", 5*time.Second)
+	doc := Document{SessionID: "telegram:1", Title: "Trip plan", Markdown: md.String()}
+	record, err := target.Export(context.Background(), doc, &Record{Ref: "old"})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if record.Ref != "page-1" || record.Location != "https://notion.so/page-1" {
+		t.Errorf("Export() = %+v", record)
+	}
+
+	want := []string{"PATCH /v1/pages/old", "POST /v1/pages", "PATCH /v1/blocks/page-1/children"}
+	if strings.Join(server.requests, ", ") != strings.Join(want, ", ") {
+		t.Errorf("requests = %v, want %v", server.requests, want)
+	}
+	if server.bodies[0]["archived"] != true {
+		t.Errorf("archive body = %v", server.bodies[0])
+	}
+	if n := len(server.bodies[1]["children"].([]any)); n != notionMaxChildren {
+		t.Errorf("create children = %d, want %d", n, notionMaxChildren)
+	}
+	if n := len(server.bodies[2]["children"].([]any)); n != 50 {
+		t.Errorf("appended children = %d, want 50", n)
+	}
+
+	// A previous page deleted in Notion does not fail the export
+	if _, err := target.Export(context.Background(), doc, &Record{Ref: "gone"}); err != nil {
+		t.Errorf("Export(deleted previous page) error = %v", err)
+	}
+}
+
+func TestNotionBlocks(t *testing.T) {
+	md := "# Title\n\n### 👤 User\n\nline one\nline two\n\n```json\n{\"a\": 1}\n```\n\n" + strings.Repeat("x", 2500) + "\n"
+	blocks := notionBlocks(md, "Title")
+
+	var types []string
+	for _, b := range blocks {
+		types = append(types, b["type"].(string))
+	}
+	if got := strings.Join(types, ","); got != "heading_3,paragraph,code,paragraph" {
+		t.Fatalf("block types = %s", got)
+	}
+
+	paragraph := blocks[1]["paragraph"].(map[string]any)["rich_text"].([]map[string]any)
+	if content := paragraph[0]["text"].(map[string]any)["content"]; content != "line one\nline two" {
+		t.Errorf("paragraph = %q", content)
+	}
+	code := blocks[2]["code"].(map[string]any)["rich_text"].([]map[string]any)
+	if content := code[0]["text"].(map[string]any)["content"]; content != "{\"a\": 1}" {
+		t.Errorf("code = %q", content)
+	}
+	if long := blocks[3]["paragraph"].(map[string]any)["rich_text"].([]map[string]any); len(long) != 2 {
+		t.Errorf("long paragraph split into %d parts, want 2", len(long))
+	}
+}
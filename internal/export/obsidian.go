@@ -0,0 +1,76 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/transcript"
+)
+
+// ObsidianTarget writes transcripts as Markdown notes into a folder of an
+// Obsidian vault. Notes are named after the session title and carry the
+// session ID in their frontmatter.
+type ObsidianTarget struct {
+	dir string
+}
+
+// NewObsidianTarget creates a target writing to <vaultPath>/<folder>.
+func NewObsidianTarget(vaultPath, folder string) *ObsidianTarget {
+	return &ObsidianTarget{dir: filepath.Join(vaultPath, folder)}
+}
+
+// Name returns the target name.
+func (t *ObsidianTarget) Name() string {
+	return "obsidian"
+}
+
+// Export writes the note, replacing the note of the previous export.
+func (t *ObsidianTarget) Export(ctx context.Context, doc Document, previous *Record) (Record, error) {
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return Record{}, fmt.Errorf("failed to create obsidian folder: %w", err)
+	}
+
+	path := filepath.Join(t.dir, transcript.SafeFileName(doc.Title)+".md")
+	if _, err := os.Stat(path); err == nil && (previous == nil || previous.Ref != path) {
+		// Another session already has a note with this title
+		path = filepath.Join(t.dir, fmt.Sprintf("%s (%s).md",
+			transcript.SafeFileName(doc.Title), transcript.SafeFileName(doc.SessionID)))
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(obsidianNote(doc)), 0644); err != nil {
+		return Record{}, fmt.Errorf("failed to write obsidian note: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return Record{}, fmt.Errorf("failed to save obsidian note: %w", err)
+	}
+
+	// The title may have changed since the previous export
+	if previous != nil && previous.Ref != path {
+		if err := os.Remove(previous.Ref); err != nil && !os.IsNotExist(err) {
+			return Record{}, fmt.Errorf("failed to remove previous obsidian note: %w", err)
+		}
+	}
+
+	return Record{Ref: path, Location: path}, nil
+}
+
+// obsidianNote prepends YAML frontmatter to the transcript.
+func obsidianNote(doc Document) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "nexbot_session: %s\n", strconv.Quote(doc.SessionID))
+	if !doc.UpdatedAt.IsZero() {
+		fmt.Fprintf(&b, "updated: %s\n", doc.UpdatedAt.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&b, "exported: %s\n", time.Now().Format(time.RFC3339))
+	b.WriteString("tags: [nexbot]\n")
+	b.WriteString("---\n\n")
+	b.WriteString(doc.Markdown)
+	return b.String()
+}
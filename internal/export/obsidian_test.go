@@ -0,0 +1,54 @@
+package export
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestObsidianTarget_Export(t *testing.T) {
+	vault := t.TempDir()
+	target := NewObsidianTarget(vault, "Nexbot")
+	doc := Document{SessionID: "telegram:1", Title: "Trip plan", Markdown: "# Trip plan\n\nhello\n"}
+
+	record, err := target.Export(context.Background(), doc, nil)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	want := filepath.Join(vault, "Nexbot", "Trip_plan.md")
+	if record.Ref != want || record.Location != want {
+		t.Errorf("Export() = %+v, want %s", record, want)
+	}
+	data, err := os.ReadFile(want)
+	if err != nil {
+		t.Fatalf("Failed to read note: %v", err)
+	}
+	note := string(data)
+	if !strings.HasPrefix(note, "---\nnexbot_session: \"telegram:1\"\n") || !strings.HasSuffix(note, "---\n\n# Trip plan\n\nhello\n") {
+		t.Errorf("note = %q", note)
+	}
+
+	// Another session with the same title gets its own note
+	other, err := target.Export(context.Background(), Document{SessionID: "telegram:2", Title: "Trip plan"}, nil)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if other.Ref != filepath.Join(vault, "Nexbot", "Trip_plan (telegram_2).md") {
+		t.Errorf("Export(same title) = %s", other.Ref)
+	}
+
+	// A renamed session replaces its previous note
+	doc.Title = "Trip to Rome"
+	renamed, err := target.Export(context.Background(), doc, &record)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if renamed.Ref != filepath.Join(vault, "Nexbot", "Trip_to_Rome.md") {
+		t.Errorf("Export(renamed) = %s", renamed.Ref)
+	}
+	if _, err := os.Stat(record.Ref); !os.IsNotExist(err) {
+		t.Errorf("previous note still exists: %v", err)
+	}
+}
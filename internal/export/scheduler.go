@@ -0,0 +1,69 @@
+package export
+
+import (
+	"context"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// Scheduler periodically exports sessions changed since their last export.
+type Scheduler struct {
+	exporter *Exporter
+	interval time.Duration
+	logger   *logger.Logger
+	cancel   context.CancelFunc
+}
+
+// NewScheduler creates a scheduler running every interval.
+func NewScheduler(exporter *Exporter, interval time.Duration, log *logger.Logger) *Scheduler {
+	return &Scheduler{
+		exporter: exporter,
+		interval: interval,
+		logger:   log,
+	}
+}
+
+// Start begins periodic exports.
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	ticker := time.NewTicker(s.interval)
+
+	s.logger.Info("export scheduler started",
+		logger.Field{Key: "interval_minutes", Value: int(s.interval.Minutes())},
+		logger.Field{Key: "targets", Value: s.exporter.Targets()})
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.run(ctx)
+			case <-ctx.Done():
+				s.logger.Info("export scheduler stopped")
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler.
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// run exports the changed sessions once.
+func (s *Scheduler) run(ctx context.Context) {
+	exported, err := s.exporter.ExportChanged(ctx)
+	if err != nil && ctx.Err() == nil {
+		s.logger.Error("session export failed", err,
+			logger.Field{Key: "exported", Value: exported})
+		return
+	}
+	if exported > 0 {
+		s.logger.Info("sessions exported",
+			logger.Field{Key: "exported", Value: exported})
+	}
+}
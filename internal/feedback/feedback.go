@@ -0,0 +1,99 @@
+// Package feedback collects user satisfaction signals (the /feedback command
+// and message reactions) and aggregates them into quality reports.
+package feedback
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Feedback sources
+const (
+	SourceCommand  = "command"
+	SourceReaction = "reaction"
+)
+
+// DefaultProfile is used when no prompt profile is configured.
+const DefaultProfile = "default"
+
+// Entry is a single feedback record.
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	SessionID string    `json:"session_id"`
+	UserID    string    `json:"user_id,omitempty"`
+	Channel   string    `json:"channel,omitempty"`
+	Source    string    `json:"source"`
+	Score     int       `json:"score"` // -1 negative, 0 neutral, +1 positive
+	Rating    int       `json:"rating,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	Reaction  string    `json:"reaction,omitempty"`
+	MessageID int       `json:"message_id,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	Profile   string    `json:"profile,omitempty"`
+	Tools     []string  `json:"tools,omitempty"`
+}
+
+var positiveWords = map[string]bool{
+	"+": true, "+1": true, "👍": true, "good": true, "up": true, "yes": true, "хорошо": true, "да": true,
+}
+
+var negativeWords = map[string]bool{
+	"-": true, "-1": true, "👎": true, "bad": true, "down": true, "no": true, "плохо": true, "нет": true,
+}
+
+// ParseCommand parses the arguments of the /feedback command.
+// Supported forms: "/feedback + great answer", "/feedback bad", "/feedback 4 ok".
+// A numeric rating from 1 to 5 is mapped to a score: 1-2 negative, 3 neutral, 4-5 positive.
+// Text without a recognized score is stored as a neutral comment.
+func ParseCommand(text string) (score, rating int, comment string, err error) {
+	fields := strings.Fields(text)
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "/feedback") {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return 0, 0, "", fmt.Errorf("feedback is empty")
+	}
+
+	first := strings.ToLower(fields[0])
+	switch {
+	case positiveWords[first]:
+		return 1, 0, strings.Join(fields[1:], " "), nil
+	case negativeWords[first]:
+		return -1, 0, strings.Join(fields[1:], " "), nil
+	}
+
+	if n, convErr := strconv.Atoi(first); convErr == nil {
+		if n < 1 || n > 5 {
+			return 0, 0, "", fmt.Errorf("rating must be between 1 and 5 (got: %d)", n)
+		}
+		return RatingScore(n), n, strings.Join(fields[1:], " "), nil
+	}
+
+	return 0, 0, strings.Join(fields, " "), nil
+}
+
+// RatingScore maps a 1-5 rating to a score.
+func RatingScore(rating int) int {
+	switch {
+	case rating >= 4:
+		return 1
+	case rating <= 2:
+		return -1
+	default:
+		return 0
+	}
+}
+
+var reactionScores = map[string]int{
+	"👍": 1, "❤": 1, "❤️": 1, "🔥": 1, "🥰": 1, "👏": 1, "🎉": 1, "🤩": 1, "🙏": 1, "👌": 1, "😍": 1, "💯": 1, "🏆": 1,
+	"👎": -1, "💩": -1, "🤮": -1, "😢": -1, "🤬": -1, "💔": -1, "🤡": -1, "🥱": -1, "😭": -1,
+}
+
+// ReactionScore returns the score of a reaction emoji.
+// Reactions that carry no clear sentiment are not recognized.
+func ReactionScore(emoji string) (int, bool) {
+	score, ok := reactionScores[emoji]
+	return score, ok
+}
@@ -0,0 +1,114 @@
+package feedback
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text    string
+		score   int
+		rating  int
+		comment string
+	}{
+		{"/feedback + great answer", 1, 0, "great answer"},
+		{"/feedback 👎", -1, 0, ""},
+		{"/feedback Bad too long", -1, 0, "too long"},
+		{"/feedback 5", 1, 5, ""},
+		{"/feedback 3 ok", 0, 3, "ok"},
+		{"/feedback 1 wrong file", -1, 1, "wrong file"},
+		{"/feedback please be shorter", 0, 0, "please be shorter"},
+	}
+	for _, tt := range tests {
+		score, rating, comment, err := ParseCommand(tt.text)
+		require.NoError(t, err, tt.text)
+		assert.Equal(t, tt.score, score, tt.text)
+		assert.Equal(t, tt.rating, rating, tt.text)
+		assert.Equal(t, tt.comment, comment, tt.text)
+	}
+
+	for _, text := range []string{"/feedback", "/feedback  ", "/feedback 7"} {
+		_, _, _, err := ParseCommand(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestReactionScore(t *testing.T) {
+	score, ok := ReactionScore("👍")
+	assert.True(t, ok)
+	assert.Equal(t, 1, score)
+
+	score, ok = ReactionScore("👎")
+	assert.True(t, ok)
+	assert.Equal(t, -1, score)
+
+	_, ok = ReactionScore("🤔")
+	assert.False(t, ok)
+}
+
+func TestStore_AppendLoad(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	entries, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, store.Append(Entry{SessionID: "telegram:1", Source: SourceCommand, Score: 1}))
+	require.NoError(t, store.Append(Entry{SessionID: "telegram:1", Source: SourceReaction, Score: -1, Tools: []string{"shell"}}))
+
+	// Malformed lines are skipped
+	f, err := os.OpenFile(store.Path(), os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, _ = f.WriteString("not json\n")
+	require.NoError(t, f.Close())
+
+	entries, err = store.Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, []string{"shell"}, entries[1].Tools)
+
+	require.NoError(t, store.Append(Entry{SessionID: "telegram:2", Source: SourceCommand, Score: 1}))
+	removed, err := store.Remove(func(e Entry) bool { return e.SessionID == "telegram:1" })
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	entries, err = store.Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "telegram:2", entries[0].SessionID)
+}
+
+func TestBuildReport(t *testing.T) {
+	now := time.Now()
+	entries := []Entry{
+		{Timestamp: now.Add(-48 * time.Hour), Score: -1, Model: "old"},
+		{Timestamp: now, Score: 1, Model: "glm-4", Profile: "concise", Tools: []string{"shell", "shell"}},
+		{Timestamp: now, Score: -1, Model: "glm-4", Tools: []string{"shell", "fetch"}, Comment: "wrong url"},
+		{Timestamp: now, Score: 0, Model: "gpt-4o"},
+	}
+
+	report := BuildReport(entries, now.Add(-24*time.Hour))
+
+	assert.Equal(t, 3, report.Total.Count)
+	assert.Equal(t, 0.5, report.Total.Satisfaction())
+
+	require.Len(t, report.ByModel, 2)
+	assert.Equal(t, "glm-4", report.ByModel[0].Name)
+	assert.Equal(t, 2, report.ByModel[0].Count)
+	assert.Equal(t, -1.0, report.ByModel[1].Satisfaction(), "only neutral feedback")
+
+	require.Len(t, report.ByProfile, 2)
+	assert.Equal(t, DefaultProfile, report.ByProfile[0].Name)
+
+	shell := report.ByTool[0]
+	assert.Equal(t, "shell", shell.Name)
+	assert.Equal(t, 2, shell.Count, "tool is counted once per entry")
+
+	require.Len(t, report.Comments, 1)
+	assert.Contains(t, report.Format(), "wrong url")
+}
@@ -0,0 +1,179 @@
+package feedback
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Bucket aggregates feedback for one group (a model, a profile or a tool).
+type Bucket struct {
+	Name     string
+	Count    int
+	Positive int
+	Negative int
+	Neutral  int
+}
+
+// add counts an entry score.
+func (b *Bucket) add(score int) {
+	b.Count++
+	switch {
+	case score > 0:
+		b.Positive++
+	case score < 0:
+		b.Negative++
+	default:
+		b.Neutral++
+	}
+}
+
+// Satisfaction returns the share of positive feedback among rated entries
+// (neutral entries are ignored). Returns -1 if there are no rated entries.
+func (b Bucket) Satisfaction() float64 {
+	rated := b.Positive + b.Negative
+	if rated == 0 {
+		return -1
+	}
+	return float64(b.Positive) / float64(rated)
+}
+
+// Report is the aggregated feedback.
+type Report struct {
+	Since     time.Time
+	Total     Bucket
+	ByModel   []Bucket
+	ByProfile []Bucket
+	ByTool    []Bucket
+	Comments  []Entry // Most recent negative entries with comments
+}
+
+// maxReportComments limits the number of comments included in a report
+const maxReportComments = 10
+
+// BuildReport aggregates entries recorded at or after since (zero means all).
+// Groups are sorted by the number of entries, then by name.
+func BuildReport(entries []Entry, since time.Time) Report {
+	report := Report{Since: since, Total: Bucket{Name: "total"}}
+	models := map[string]*Bucket{}
+	profiles := map[string]*Bucket{}
+	tools := map[string]*Bucket{}
+
+	for _, e := range entries {
+		if !since.IsZero() && e.Timestamp.Before(since) {
+			continue
+		}
+		report.Total.add(e.Score)
+
+		model := e.Model
+		if model == "" {
+			model = "unknown"
+		}
+		bucketFor(models, model).add(e.Score)
+
+		profile := e.Profile
+		if profile == "" {
+			profile = DefaultProfile
+		}
+		bucketFor(profiles, profile).add(e.Score)
+
+		if len(e.Tools) == 0 {
+			bucketFor(tools, "(no tools)").add(e.Score)
+		}
+		seen := map[string]bool{}
+		for _, tool := range e.Tools {
+			if seen[tool] {
+				continue
+			}
+			seen[tool] = true
+			bucketFor(tools, tool).add(e.Score)
+		}
+
+		if e.Score < 0 && e.Comment != "" {
+			report.Comments = append(report.Comments, e)
+		}
+	}
+
+	report.ByModel = sortedBuckets(models)
+	report.ByProfile = sortedBuckets(profiles)
+	report.ByTool = sortedBuckets(tools)
+
+	sort.SliceStable(report.Comments, func(i, j int) bool {
+		return report.Comments[i].Timestamp.After(report.Comments[j].Timestamp)
+	})
+	if len(report.Comments) > maxReportComments {
+		report.Comments = report.Comments[:maxReportComments]
+	}
+	return report
+}
+
+func bucketFor(m map[string]*Bucket, name string) *Bucket {
+	b, ok := m[name]
+	if !ok {
+		b = &Bucket{Name: name}
+		m[name] = b
+	}
+	return b
+}
+
+func sortedBuckets(m map[string]*Bucket) []Bucket {
+	buckets := make([]Bucket, 0, len(m))
+	for _, b := range m {
+		buckets = append(buckets, *b)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+		return buckets[i].Name < buckets[j].Name
+	})
+	return buckets
+}
+
+// Format renders the report as plain text tables.
+func (r Report) Format() string {
+	var b strings.Builder
+	if r.Since.IsZero() {
+		b.WriteString("Feedback report (all time)\n")
+	} else {
+		fmt.Fprintf(&b, "Feedback report since %s\n", r.Since.Format("2006-01-02"))
+	}
+
+	if r.Total.Count == 0 {
+		b.WriteString("\nNo feedback recorded.\n")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "\nTotal: %d (👍 %d, 👎 %d, neutral %d), satisfaction %s\n",
+		r.Total.Count, r.Total.Positive, r.Total.Negative, r.Total.Neutral, formatSatisfaction(r.Total))
+
+	writeTable(&b, "By model", r.ByModel)
+	writeTable(&b, "By prompt profile", r.ByProfile)
+	writeTable(&b, "By tool", r.ByTool)
+
+	if len(r.Comments) > 0 {
+		b.WriteString("\nRecent negative comments:\n")
+		for _, e := range r.Comments {
+			fmt.Fprintf(&b, "  %s  %s  %s\n", e.Timestamp.Format("2006-01-02 15:04"), e.SessionID, e.Comment)
+		}
+	}
+	return b.String()
+}
+
+func writeTable(b *strings.Builder, title string, buckets []Bucket) {
+	fmt.Fprintf(b, "\n%s:\n", title)
+	fmt.Fprintf(b, "  %-30s %6s %6s %6s %8s\n", "NAME", "COUNT", "👍", "👎", "SATISF.")
+	for _, bucket := range buckets {
+		fmt.Fprintf(b, "  %-30s %6d %6d %6d %8s\n",
+			bucket.Name, bucket.Count, bucket.Positive, bucket.Negative, formatSatisfaction(bucket))
+	}
+}
+
+func formatSatisfaction(b Bucket) string {
+	s := b.Satisfaction()
+	if s < 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.0f%%", s*100)
+}
@@ -0,0 +1,131 @@
+package feedback
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// FeedbackSubdirectory is the subdirectory name for feedback data within workspace
+	FeedbackSubdirectory = "feedback"
+
+	// FeedbackFilename is the filename of the feedback log
+	FeedbackFilename = "feedback.jsonl"
+)
+
+// Store appends feedback entries to <workspace>/feedback/feedback.jsonl.
+type Store struct {
+	mu       sync.Mutex
+	filePath string
+}
+
+// NewStore creates a feedback store in the workspace.
+func NewStore(workspacePath string) *Store {
+	return &Store{filePath: filepath.Join(workspacePath, FeedbackSubdirectory, FeedbackFilename)}
+}
+
+// Path returns the feedback log path.
+func (s *Store) Path() string {
+	return s.filePath
+}
+
+// Append writes an entry to the log.
+func (s *Store) Append(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal feedback: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create feedback directory: %w", err)
+	}
+
+	f, err := os.OpenFile(s.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open feedback log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write feedback: %w", err)
+	}
+	return nil
+}
+
+// Load reads all entries. Malformed lines are skipped.
+// Returns an empty slice if the log doesn't exist.
+func (s *Store) Load() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.filePath)
+	if os.IsNotExist(err) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open feedback log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read feedback log: %w", err)
+	}
+	return entries, nil
+}
+
+// Remove deletes the entries that match and returns how many were removed.
+// Malformed lines are kept.
+func (s *Store) Remove(match func(Entry) bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.filePath)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read feedback log: %w", err)
+	}
+
+	var kept bytes.Buffer
+	removed := 0
+	for line := range bytes.Lines(data) {
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err == nil && match(entry) {
+			removed++
+			continue
+		}
+		kept.Write(line)
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+
+	// Write to temp file first, then rename for atomicity
+	tmpPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, kept.Bytes(), 0644); err != nil {
+		return 0, fmt.Errorf("failed to write feedback log: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.filePath); err != nil {
+		return 0, fmt.Errorf("failed to save feedback log: %w", err)
+	}
+	return removed, nil
+}
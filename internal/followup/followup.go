@@ -0,0 +1,195 @@
+// Package followup lets the agent check in with the user on its own: while
+// working on a task that finishes later (a build, a delivery, an answer from
+// someone) the agent schedules a follow-up, and when it is due the agent is
+// asked to check the state of the task and report to the user unprompted.
+// Follow-ups are one-time jobs of the cron scheduler and need the user's
+// opt-in, stored as a consent in the user registry.
+package followup
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent"
+	"github.com/aatumaykin/nexbot/internal/users"
+)
+
+const (
+	// ConsentName is the consent of the user registry that allows follow-ups.
+	// An onboarding consent question with this name asks for it on start.
+	ConsentName = "followups"
+
+	// createdBy marks the cron jobs of follow-ups
+	createdBy = "followup"
+
+	// DefaultMaxPending is the number of pending follow-ups per chat when none is configured
+	DefaultMaxPending = 5
+
+	// DefaultMaxDelay is the latest a follow-up can be scheduled when none is configured
+	DefaultMaxDelay = 7 * 24 * time.Hour
+)
+
+var (
+	// ErrNotOptedIn is returned when scheduling a follow-up for a user who didn't allow them
+	ErrNotOptedIn = errors.New("the user hasn't allowed follow-ups; they can enable them with /followups on")
+
+	// ErrNotFound is returned when cancelling an unknown follow-up
+	ErrNotFound = errors.New("follow-up not found")
+)
+
+// Consents reads and records the consent of users (implemented by users.Registry).
+type Consents interface {
+	FindBySession(sessionID string) (users.User, bool)
+	Register(identity users.Identity, profile users.User) (users.User, bool, error)
+	SetConsent(userID, name string, granted bool) (users.User, error)
+}
+
+// Config limits the follow-ups of a chat.
+type Config struct {
+	MaxPending int           // Pending follow-ups per chat (DefaultMaxPending if 0)
+	MaxDelay   time.Duration // Latest time a follow-up can be scheduled for (DefaultMaxDelay if 0)
+}
+
+// Manager schedules follow-ups as cron jobs.
+type Manager struct {
+	cron     agent.CronManager
+	consents Consents
+	cfg      Config
+}
+
+// NewManager creates a follow-up manager.
+func NewManager(cron agent.CronManager, consents Consents, cfg Config) *Manager {
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = DefaultMaxPending
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = DefaultMaxDelay
+	}
+	return &Manager{cron: cron, consents: consents, cfg: cfg}
+}
+
+// OptedIn reports whether the user of a chat allowed follow-ups.
+func (m *Manager) OptedIn(sessionID string) bool {
+	u, ok := m.consents.FindBySession(sessionID)
+	return ok && u.Consent[ConsentName]
+}
+
+// SetOptIn records whether the user of a chat allows follow-ups, registering
+// the user if the chat is unknown. Opting out cancels the pending follow-ups
+// of the chat; their number is returned.
+func (m *Manager) SetOptIn(sessionID string, allowed bool) (int, error) {
+	u, ok := m.consents.FindBySession(sessionID)
+	if !ok {
+		identity, err := users.ParseIdentity(sessionID)
+		if err != nil {
+			return 0, err
+		}
+		if u, _, err = m.consents.Register(identity, users.User{}); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := m.consents.SetConsent(u.ID, ConsentName, allowed); err != nil {
+		return 0, err
+	}
+	if allowed {
+		return 0, nil
+	}
+
+	cancelled := 0
+	for _, job := range m.Pending(sessionID) {
+		if err := m.remove(job.ID); err != nil {
+			return cancelled, err
+		}
+		cancelled++
+	}
+	return cancelled, nil
+}
+
+// Schedule creates a follow-up of a chat: at the given time the agent is
+// asked to check on the task and report to the user. note keeps the details
+// needed for the check (a build URL, a tracking number).
+func (m *Manager) Schedule(sessionID string, at time.Time, task, note string) (agent.Job, error) {
+	task = strings.TrimSpace(task)
+	if task == "" {
+		return agent.Job{}, fmt.Errorf("task is required")
+	}
+	if !m.OptedIn(sessionID) {
+		return agent.Job{}, ErrNotOptedIn
+	}
+
+	now := time.Now()
+	if !at.After(now) {
+		return agent.Job{}, fmt.Errorf("follow-up time must be in the future (got: %s)", at.Format(time.RFC3339))
+	}
+	if at.Sub(now) > m.cfg.MaxDelay {
+		return agent.Job{}, fmt.Errorf("follow-up can be scheduled at most %s ahead", m.cfg.MaxDelay)
+	}
+	if pending := len(m.Pending(sessionID)); pending >= m.cfg.MaxPending {
+		return agent.Job{}, fmt.Errorf("this chat already has %d pending follow-ups (limit: %d); cancel one first", pending, m.cfg.MaxPending)
+	}
+
+	job := agent.Job{
+		Type:      "oneshot",
+		ExecuteAt: &at,
+		Tool:      "agent",
+		Payload:   map[string]any{"message": Prompt(task, note, now)},
+		SessionID: sessionID,
+		Metadata: map[string]string{
+			"created_by": createdBy,
+			"created_at": now.Format(time.RFC3339),
+			"task":       task,
+		},
+	}
+	id, err := m.cron.AddJob(job)
+	if err != nil {
+		return agent.Job{}, fmt.Errorf("failed to schedule follow-up: %w", err)
+	}
+	job.ID = id
+	return job, nil
+}
+
+// Pending returns the follow-ups of a chat that are not due yet, earliest first.
+func (m *Manager) Pending(sessionID string) []agent.Job {
+	var pending []agent.Job
+	for _, job := range m.cron.ListJobs() {
+		if job.Metadata["created_by"] == createdBy && job.SessionID == sessionID && !job.Executed && job.ExecuteAt != nil {
+			pending = append(pending, job)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ExecuteAt.Before(*pending[j].ExecuteAt) })
+	return pending
+}
+
+// Cancel removes a pending follow-up of a chat.
+func (m *Manager) Cancel(sessionID, jobID string) error {
+	for _, job := range m.Pending(sessionID) {
+		if job.ID == jobID {
+			return m.remove(jobID)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrNotFound, jobID)
+}
+
+// remove deletes a follow-up from the scheduler and its storage.
+func (m *Manager) remove(jobID string) error {
+	if err := m.cron.RemoveJob(jobID); err != nil {
+		return fmt.Errorf("failed to cancel follow-up: %w", err)
+	}
+	return m.cron.RemoveFromStorage(jobID)
+}
+
+// Prompt builds the message the agent receives when a follow-up is due.
+func Prompt(task, note string, scheduledAt time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Follow-up scheduled on %s] You promised the user to follow up on: %s\n",
+		scheduledAt.Format("2006-01-02 15:04"), task)
+	if note = strings.TrimSpace(note); note != "" {
+		fmt.Fprintf(&b, "Details: %s\n", note)
+	}
+	b.WriteString("Check the current state now (use tools if needed) and report the outcome to the user, " +
+		"briefly reminding them what it is about. If it isn't finished yet, say so and schedule another follow-up if it is still worth it.")
+	return b.String()
+}
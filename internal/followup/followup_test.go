@@ -0,0 +1,130 @@
+package followup
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent"
+	"github.com/aatumaykin/nexbot/internal/users"
+)
+
+// fakeCron keeps jobs in memory.
+type fakeCron struct {
+	jobs   map[string]agent.Job
+	nextID int
+}
+
+func newFakeCron() *fakeCron {
+	return &fakeCron{jobs: make(map[string]agent.Job)}
+}
+
+func (c *fakeCron) AddJob(job agent.Job) (string, error) {
+	c.nextID++
+	job.ID = fmt.Sprintf("job-%d", c.nextID)
+	c.jobs[job.ID] = job
+	return job.ID, nil
+}
+
+func (c *fakeCron) RemoveJob(jobID string) error {
+	if _, ok := c.jobs[jobID]; !ok {
+		return fmt.Errorf("job not found: %s", jobID)
+	}
+	delete(c.jobs, jobID)
+	return nil
+}
+
+func (c *fakeCron) ListJobs() []agent.Job {
+	jobs := make([]agent.Job, 0, len(c.jobs))
+	for _, job := range c.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+func (c *fakeCron) RemoveFromStorage(string) error { return nil }
+
+func (c *fakeCron) AppendJob(agent.Job) error { return nil }
+
+func TestManager_Schedule(t *testing.T) {
+	cron := newFakeCron()
+	registry := users.NewRegistry(t.TempDir())
+	if err := registry.Load(nil); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	m := NewManager(cron, registry, Config{MaxPending: 2, MaxDelay: 24 * time.Hour})
+	const chat = "telegram:1"
+	in := func(d time.Duration) time.Time { return time.Now().Add(d) }
+
+	if _, err := m.Schedule(chat, in(time.Hour), "build", ""); !errors.Is(err, ErrNotOptedIn) {
+		t.Fatalf("Schedule() without opt-in error = %v", err)
+	}
+
+	// Opting in registers an unknown chat
+	if _, err := m.SetOptIn(chat, true); err != nil {
+		t.Fatalf("SetOptIn() error = %v", err)
+	}
+	if !m.OptedIn(chat) {
+		t.Fatal("OptedIn() = false after opt-in")
+	}
+
+	later, err := m.Schedule(chat, in(2*time.Hour), "deploy", "")
+	if err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	job, err := m.Schedule(chat, in(time.Hour), "nightly build", "pipeline #42")
+	if err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	if job.Tool != "agent" || job.Type != "oneshot" || job.SessionID != chat || job.Metadata["task"] != "nightly build" {
+		t.Errorf("Schedule() job = %+v", job)
+	}
+	message, _ := job.Payload["message"].(string)
+	if !strings.Contains(message, "nightly build") || !strings.Contains(message, "pipeline #42") {
+		t.Errorf("follow-up message = %q", message)
+	}
+
+	for _, tt := range []struct {
+		name string
+		at   time.Time
+		task string
+	}{
+		{"past", in(-time.Minute), "x"},
+		{"too far", in(48 * time.Hour), "x"},
+		{"no task", in(time.Hour), " "},
+		{"limit reached", in(time.Hour), "x"},
+	} {
+		if _, err := m.Schedule(chat, tt.at, tt.task, ""); err == nil {
+			t.Errorf("Schedule() %s: expected error", tt.name)
+		}
+	}
+
+	// Other cron jobs and other chats are not follow-ups of the chat
+	reminderAt := in(time.Hour)
+	cron.AddJob(agent.Job{Type: "oneshot", SessionID: chat, ExecuteAt: &reminderAt})
+	pending := m.Pending(chat)
+	if len(pending) != 2 || pending[0].ID != job.ID || pending[1].ID != later.ID {
+		t.Errorf("Pending() = %+v", pending)
+	}
+	if len(m.Pending("telegram:2")) != 0 {
+		t.Error("Pending() returned follow-ups of another chat")
+	}
+
+	if err := m.Cancel("telegram:2", job.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel() from another chat error = %v", err)
+	}
+	if err := m.Cancel(chat, job.ID); err != nil {
+		t.Errorf("Cancel() error = %v", err)
+	}
+
+	// Opting out cancels what is left
+	cancelled, err := m.SetOptIn(chat, false)
+	if err != nil || cancelled != 1 {
+		t.Errorf("SetOptIn(false) = %d, %v", cancelled, err)
+	}
+	if len(m.Pending(chat)) != 0 || m.OptedIn(chat) {
+		t.Error("follow-ups left after opt-out")
+	}
+}
@@ -0,0 +1,106 @@
+// Package forms collects missing tool arguments from the user with guided,
+// multi-turn questions instead of letting the LLM guess them. A tool declares
+// its required fields; the channel connector routes the user's answers (text
+// or inline keyboard buttons for choices) to the Manager, which validates
+// each answer and hands the completed arguments back to the tool.
+package forms
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType is the type of a form field.
+type FieldType string
+
+const (
+	FieldText     FieldType = "text"     // Free text (optionally matching Pattern)
+	FieldInteger  FieldType = "integer"  // Whole number
+	FieldNumber   FieldType = "number"   // Number
+	FieldBoolean  FieldType = "boolean"  // Yes/No buttons
+	FieldChoice   FieldType = "choice"   // One of Options, shown as buttons
+	FieldDateTime FieldType = "datetime" // Date and time, stored as RFC3339
+)
+
+// dateTimeLayouts are the accepted datetime answers (local time unless an offset is given).
+var dateTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"}
+
+// Field is a value asked from the user.
+type Field struct {
+	Name     string    // Tool argument name
+	Question string    // Question shown to the user
+	Type     FieldType // Value type (FieldText if empty)
+	Options  []string  // Choices for FieldChoice
+	Pattern  string    // Regular expression a FieldText answer must match (optional)
+}
+
+// Parse validates an answer and converts it to the argument value.
+func (f Field) Parse(answer string) (any, error) {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return nil, fmt.Errorf("answer cannot be empty")
+	}
+
+	switch f.Type {
+	case FieldText, "":
+		if f.Pattern != "" {
+			re, err := regexp.Compile(f.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern for field %s: %w", f.Name, err)
+			}
+			if !re.MatchString(answer) {
+				return nil, fmt.Errorf("answer does not match the expected format")
+			}
+		}
+		return answer, nil
+	case FieldInteger:
+		n, err := strconv.Atoi(answer)
+		if err != nil {
+			return nil, fmt.Errorf("expected a whole number")
+		}
+		return n, nil
+	case FieldNumber:
+		n, err := strconv.ParseFloat(strings.ReplaceAll(answer, ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number")
+		}
+		return n, nil
+	case FieldBoolean:
+		switch strings.ToLower(answer) {
+		case "yes", "y", "true", "да", "1":
+			return true, nil
+		case "no", "n", "false", "нет", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("expected yes or no")
+	case FieldChoice:
+		if i := slices.IndexFunc(f.Options, func(o string) bool { return strings.EqualFold(o, answer) }); i >= 0 {
+			return f.Options[i], nil
+		}
+		return nil, fmt.Errorf("expected one of: %s", strings.Join(f.Options, ", "))
+	case FieldDateTime:
+		for _, layout := range dateTimeLayouts {
+			if t, err := time.ParseInLocation(layout, answer, time.Local); err == nil {
+				return t.Format(time.RFC3339), nil
+			}
+		}
+		return nil, fmt.Errorf("expected a date and time like 2026-03-01 18:00")
+	default:
+		return nil, fmt.Errorf("unknown field type: %s", f.Type)
+	}
+}
+
+// options returns the button choices of the field, if any.
+func (f Field) options() []string {
+	switch f.Type {
+	case FieldChoice:
+		return f.Options
+	case FieldBoolean:
+		return []string{"Yes", "No"}
+	}
+	return nil
+}
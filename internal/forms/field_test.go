@@ -0,0 +1,60 @@
+package forms
+
+import (
+	"testing"
+	"time"
+)
+
+func TestField_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   Field
+		answer  string
+		want    any
+		wantErr bool
+	}{
+		{"text", Field{Type: FieldText}, "  hello ", "hello", false},
+		{"empty answer", Field{Type: FieldText}, " ", nil, true},
+		{"pattern match", Field{Pattern: `^job_\w+$`}, "job_1", "job_1", false},
+		{"pattern mismatch", Field{Pattern: `^job_\w+$`}, "1", nil, true},
+		{"integer", Field{Type: FieldInteger}, "42", 42, false},
+		{"invalid integer", Field{Type: FieldInteger}, "4.2", nil, true},
+		{"number with comma", Field{Type: FieldNumber}, "4,5", 4.5, false},
+		{"boolean yes", Field{Type: FieldBoolean}, "Yes", true, false},
+		{"boolean russian no", Field{Type: FieldBoolean}, "нет", false, false},
+		{"invalid boolean", Field{Type: FieldBoolean}, "maybe", nil, true},
+		{"choice ignores case", Field{Type: FieldChoice, Options: []string{"agent", "send_message"}}, "AGENT", "agent", false},
+		{"unknown choice", Field{Type: FieldChoice, Options: []string{"agent"}}, "shell", nil, true},
+		{"invalid datetime", Field{Type: FieldDateTime}, "tomorrow", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.field.Parse(tt.answer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %v (%T), want %v (%T)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestField_ParseDateTime(t *testing.T) {
+	field := Field{Type: FieldDateTime}
+
+	got, err := field.Parse("2026-03-01 18:00")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := time.Date(2026, 3, 1, 18, 0, 0, 0, time.Local).Format(time.RFC3339)
+	if got != want {
+		t.Errorf("Parse() = %v, want %v", got, want)
+	}
+
+	got, err = field.Parse("2026-03-01T18:00:00Z")
+	if err != nil || got != "2026-03-01T18:00:00Z" {
+		t.Errorf("Parse() = %v, %v, want RFC3339 answer unchanged", got, err)
+	}
+}
@@ -0,0 +1,305 @@
+package forms
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+)
+
+const (
+	// CallbackPrefix marks callback data of form buttons.
+	// Callback data format: "form:<token>:<option index>" or "form:<token>:cancel".
+	CallbackPrefix = "form:"
+
+	// CancelCommand cancels the active form
+	CancelCommand = "/cancel"
+
+	// DefaultTTL is how long an unanswered form stays active
+	DefaultTTL = 30 * time.Minute
+
+	// cancelValue is the callback value of the cancel button
+	cancelValue = "cancel"
+)
+
+var (
+	// ErrUnsupported is returned for sessions of channels that cannot route answers to forms.
+	ErrUnsupported = errors.New("forms are not supported in this channel")
+
+	// ErrActive is returned when the session already has an active form.
+	ErrActive = errors.New("another form is in progress")
+)
+
+// Publisher sends questions to the user.
+type Publisher interface {
+	PublishOutbound(msg bus.OutboundMessage) error
+}
+
+// CompleteFunc receives a completed form. It runs in its own goroutine.
+type CompleteFunc func(ctx context.Context, form *Form)
+
+// Config configures a Manager.
+type Config struct {
+	Channels []string      // Channels whose connector routes answers to the manager
+	TTL      time.Duration // How long an unanswered form stays active (DefaultTTL if 0)
+}
+
+// Form is a set of questions for a tool call.
+type Form struct {
+	Token     string
+	SessionID string
+	Tool      string
+	Args      map[string]any // Tool arguments; answers are stored under the field names
+	Fields    []Field
+
+	step      int
+	expiresAt time.Time
+}
+
+// Manager keeps the active form of each session. It is safe for concurrent use.
+type Manager struct {
+	mu        sync.Mutex
+	cfg       Config
+	forms     map[string]*Form // By session ID
+	publisher Publisher
+	complete  CompleteFunc
+	now       func() time.Time
+}
+
+// NewManager creates a Manager.
+func NewManager(cfg Config, publisher Publisher, complete CompleteFunc) *Manager {
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultTTL
+	}
+	return &Manager{
+		cfg:       cfg,
+		forms:     make(map[string]*Form),
+		publisher: publisher,
+		complete:  complete,
+		now:       time.Now,
+	}
+}
+
+// Supports reports whether answers from the session's channel reach the manager.
+func (m *Manager) Supports(sessionID string) bool {
+	channel, _, ok := strings.Cut(sessionID, ":")
+	return ok && slices.Contains(m.cfg.Channels, channel)
+}
+
+// Start asks the user the first question of a new form.
+func (m *Manager) Start(sessionID, tool string, args map[string]any, fields []Field) (*Form, error) {
+	if !m.Supports(sessionID) {
+		return nil, ErrUnsupported
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("form has no fields")
+	}
+
+	m.mu.Lock()
+	if m.activeLocked(sessionID) != nil {
+		m.mu.Unlock()
+		return nil, ErrActive
+	}
+	if args == nil {
+		args = make(map[string]any)
+	}
+	form := &Form{
+		Token:     newToken(),
+		SessionID: sessionID,
+		Tool:      tool,
+		Args:      args,
+		Fields:    fields,
+		expiresAt: m.now().Add(m.cfg.TTL),
+	}
+	m.forms[sessionID] = form
+	m.mu.Unlock()
+
+	if err := m.ask(form, ""); err != nil {
+		m.mu.Lock()
+		delete(m.forms, sessionID)
+		m.mu.Unlock()
+		return nil, err
+	}
+	return form, nil
+}
+
+// Active reports whether the session has an unexpired form.
+func (m *Manager) Active(sessionID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.activeLocked(sessionID) != nil
+}
+
+// Answer handles a text message. Returns false if the session has no active
+// form, so the message should be processed as usual.
+func (m *Manager) Answer(ctx context.Context, sessionID, text string) bool {
+	if strings.TrimSpace(text) == CancelCommand {
+		return m.cancel(sessionID, "")
+	}
+	return m.answer(ctx, sessionID, "", text)
+}
+
+// AnswerCallback handles a form button. Returns false if data is not form
+// callback data; buttons of finished or expired forms are ignored.
+func (m *Manager) AnswerCallback(ctx context.Context, sessionID, data string) bool {
+	token, value, ok := ParseCallback(data)
+	if !ok {
+		return false
+	}
+	if value == cancelValue {
+		m.cancel(sessionID, token)
+		return true
+	}
+
+	m.mu.Lock()
+	form := m.activeLocked(sessionID)
+	var answer string
+	if form != nil && form.Token == token {
+		options := form.Fields[form.step].options()
+		if i, err := strconv.Atoi(value); err == nil && i >= 0 && i < len(options) {
+			answer = options[i]
+		}
+	}
+	m.mu.Unlock()
+
+	if answer != "" {
+		m.answer(ctx, sessionID, token, answer)
+	}
+	return true
+}
+
+// answer validates an answer to the current question and asks the next one,
+// or completes the form. An empty token matches any form of the session.
+func (m *Manager) answer(ctx context.Context, sessionID, token, text string) bool {
+	m.mu.Lock()
+	form := m.activeLocked(sessionID)
+	if form == nil || (token != "" && form.Token != token) {
+		m.mu.Unlock()
+		return form != nil
+	}
+
+	field := form.Fields[form.step]
+	value, err := field.Parse(text)
+	if err != nil {
+		m.mu.Unlock()
+		_ = m.ask(form, "⚠️ "+capitalize(err.Error())+".\n\n")
+		return true
+	}
+
+	form.Args[field.Name] = value
+	form.step++
+	form.expiresAt = m.now().Add(m.cfg.TTL)
+	done := form.step == len(form.Fields)
+	if done {
+		delete(m.forms, sessionID)
+	}
+	m.mu.Unlock()
+
+	if !done {
+		_ = m.ask(form, "")
+		return true
+	}
+	if m.complete != nil {
+		go m.complete(context.WithoutCancel(ctx), form)
+	}
+	return true
+}
+
+// cancel cancels the active form of the session. A non-empty token must
+// match the form. Returns false if there is no active form.
+func (m *Manager) cancel(sessionID, token string) bool {
+	m.mu.Lock()
+	form := m.activeLocked(sessionID)
+	if form == nil || (token != "" && form.Token != token) {
+		m.mu.Unlock()
+		return false
+	}
+	delete(m.forms, sessionID)
+	m.mu.Unlock()
+
+	_ = m.send(form, "❌ Form cancelled.", nil)
+	return true
+}
+
+// activeLocked returns the unexpired form of a session. Must be called with mu held.
+func (m *Manager) activeLocked(sessionID string) *Form {
+	form, ok := m.forms[sessionID]
+	if !ok {
+		return nil
+	}
+	if !m.now().Before(form.expiresAt) {
+		delete(m.forms, sessionID)
+		return nil
+	}
+	return form
+}
+
+// ask sends the current question of a form with an optional prefix (validation error).
+func (m *Manager) ask(form *Form, prefix string) error {
+	field := form.Fields[form.step]
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	b.WriteString(fmt.Sprintf("📝 %s (%d/%d)", field.Question, form.step+1, len(form.Fields)))
+	options := field.options()
+	if len(options) == 0 {
+		b.WriteString("\n\nReply with your answer or " + CancelCommand + " to cancel.")
+	}
+
+	keyboard := &bus.InlineKeyboard{}
+	for i, option := range options {
+		keyboard.Rows = append(keyboard.Rows, []bus.InlineButton{{Text: option, Data: CallbackData(form.Token, strconv.Itoa(i))}})
+	}
+	keyboard.Rows = append(keyboard.Rows, []bus.InlineButton{{Text: "✖ Cancel", Data: CallbackData(form.Token, cancelValue)}})
+
+	return m.send(form, b.String(), keyboard)
+}
+
+// send publishes a message to the chat of the form.
+func (m *Manager) send(form *Form, content string, keyboard *bus.InlineKeyboard) error {
+	channel, chatID, _ := strings.Cut(form.SessionID, ":")
+	msg := bus.NewOutboundMessageWithKeyboard(bus.ChannelType(channel), chatID, form.SessionID, content,
+		form.Token, keyboard, bus.FormatTypePlain, map[string]any{"form_token": form.Token})
+	if err := m.publisher.PublishOutbound(*msg); err != nil {
+		return fmt.Errorf("failed to send form question: %w", err)
+	}
+	return nil
+}
+
+// CallbackData builds callback data for a form button.
+func CallbackData(token, value string) string {
+	return CallbackPrefix + token + ":" + value
+}
+
+// ParseCallback extracts the form token and button value from callback data.
+func ParseCallback(data string) (token, value string, ok bool) {
+	rest, found := strings.CutPrefix(data, CallbackPrefix)
+	if !found {
+		return "", "", false
+	}
+	token, value, ok = strings.Cut(rest, ":")
+	return token, value, ok && token != "" && value != ""
+}
+
+// newToken returns a random form token.
+func newToken() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// capitalize upper-cases the first letter of an error message.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,149 @@
+package forms
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+)
+
+// testPublisher records published messages.
+type testPublisher struct {
+	mu       sync.Mutex
+	messages []bus.OutboundMessage
+}
+
+func (p *testPublisher) PublishOutbound(msg bus.OutboundMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *testPublisher) last(t *testing.T) bus.OutboundMessage {
+	t.Helper()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.messages) == 0 {
+		t.Fatal("Expected a published message")
+	}
+	return p.messages[len(p.messages)-1]
+}
+
+var testFields = []Field{
+	{Name: "schedule", Question: "How often?"},
+	{Name: "tool", Question: "What to do?", Type: FieldChoice, Options: []string{"send_message", "agent"}},
+}
+
+func newTestManager() (*Manager, *testPublisher, chan *Form) {
+	publisher := &testPublisher{}
+	completed := make(chan *Form, 1)
+	m := NewManager(Config{Channels: []string{"telegram"}}, publisher, func(ctx context.Context, form *Form) {
+		completed <- form
+	})
+	return m, publisher, completed
+}
+
+func TestManager_Flow(t *testing.T) {
+	m, publisher, completed := newTestManager()
+	ctx := context.Background()
+
+	form, err := m.Start("telegram:1", "cron", map[string]any{"action": "add_recurring"}, testFields)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	msg := publisher.last(t)
+	if msg.UserID != "1" || !strings.Contains(msg.Content, "How often? (1/2)") {
+		t.Errorf("Unexpected first question: %+v", msg)
+	}
+
+	if _, err := m.Start("telegram:1", "cron", nil, testFields); err != ErrActive {
+		t.Errorf("Start() error = %v, want ErrActive", err)
+	}
+
+	if !m.Answer(ctx, "telegram:1", "0 0 9 * * *") {
+		t.Fatal("Expected the answer to be consumed")
+	}
+	msg = publisher.last(t)
+	if msg.InlineKeyboard == nil || len(msg.InlineKeyboard.Rows) != 3 {
+		t.Fatalf("Expected choice buttons and a cancel row, got %+v", msg.InlineKeyboard)
+	}
+
+	// Invalid answers repeat the question
+	m.Answer(ctx, "telegram:1", "shell")
+	if msg := publisher.last(t); !strings.HasPrefix(msg.Content, "⚠️") {
+		t.Errorf("Expected validation warning, got %q", msg.Content)
+	}
+
+	if !m.AnswerCallback(ctx, "telegram:1", CallbackData(form.Token, "1")) {
+		t.Fatal("Expected the callback to be consumed")
+	}
+
+	select {
+	case done := <-completed:
+		if done.Args["schedule"] != "0 0 9 * * *" || done.Args["tool"] != "agent" || done.Args["action"] != "add_recurring" {
+			t.Errorf("Unexpected completed args: %v", done.Args)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the form to complete")
+	}
+	if m.Active("telegram:1") {
+		t.Error("Expected no active form after completion")
+	}
+	if m.Answer(ctx, "telegram:1", "hello") {
+		t.Error("Expected messages after completion to reach the agent")
+	}
+}
+
+func TestManager_Cancel(t *testing.T) {
+	m, publisher, completed := newTestManager()
+
+	form, err := m.Start("telegram:1", "cron", nil, testFields)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if m.AnswerCallback(context.Background(), "telegram:1", CallbackData("other", cancelValue)); !m.Active("telegram:1") {
+		t.Fatal("Expected cancel button of another form to be ignored")
+	}
+	if !m.Answer(context.Background(), "telegram:1", CancelCommand) {
+		t.Fatal("Expected /cancel to be consumed")
+	}
+	if m.Active("telegram:1") || !strings.Contains(publisher.last(t).Content, "cancelled") {
+		t.Error("Expected the form to be cancelled")
+	}
+	if !m.AnswerCallback(context.Background(), "telegram:1", CallbackData(form.Token, "0")) {
+		t.Error("Expected buttons of a finished form to be consumed")
+	}
+	select {
+	case <-completed:
+		t.Error("Expected a cancelled form not to complete")
+	default:
+	}
+}
+
+func TestManager_Expiry(t *testing.T) {
+	m, _, _ := newTestManager()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	if _, err := m.Start("telegram:1", "cron", nil, testFields); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	now = now.Add(DefaultTTL)
+	if m.Active("telegram:1") || m.Answer(context.Background(), "telegram:1", "hello") {
+		t.Error("Expected an expired form to be inactive")
+	}
+}
+
+func TestManager_Unsupported(t *testing.T) {
+	m, _, _ := newTestManager()
+	if _, err := m.Start("api:1", "cron", nil, testFields); err != ErrUnsupported {
+		t.Errorf("Start() error = %v, want ErrUnsupported", err)
+	}
+	if m.AnswerCallback(context.Background(), "telegram:1", "page:abc:1") {
+		t.Error("Expected non-form callback data not to be consumed")
+	}
+}
@@ -0,0 +1,192 @@
+// Package gitws keeps the workspace under a local git repository. Every agent
+// turn that changes files ends with a commit naming the request's correlation
+// ID, so the history, diffs and reverts of the agent's changes are plain git.
+package gitws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultAuthorName is the author of the commits
+	DefaultAuthorName = "nexbot"
+
+	// DefaultAuthorEmail is the email of the commits' author
+	DefaultAuthorEmail = "nexbot@localhost"
+
+	// ignoreBegin and ignoreEnd mark the part of .gitignore managed by nexbot;
+	// lines outside the markers are left to the user
+	ignoreBegin = "# nexbot: begin (managed, edit workspace.git.exclude instead)"
+	ignoreEnd   = "# nexbot: end"
+
+	// maxSubjectChars limits the request quoted in a commit subject
+	maxSubjectChars = 60
+)
+
+// ErrGitNotFound is returned when the git binary is not installed.
+var ErrGitNotFound = errors.New("git is not installed")
+
+// Config configures a Repo. Zero values use the defaults.
+type Config struct {
+	AuthorName  string
+	AuthorEmail string
+	Exclude     []string // .gitignore patterns of paths never committed (sessions, secrets, ...)
+}
+
+// Turn describes the agent turn a commit records.
+type Turn struct {
+	CorrelationID string
+	SessionID     string
+	Request       string // The user's message
+}
+
+// Repo is the git repository of a workspace. Commits are serialized, so it is
+// safe for concurrent use; changes of turns running at the same time in
+// different sessions may end up in one commit.
+type Repo struct {
+	mu  sync.Mutex
+	dir string
+	cfg Config
+	git string
+}
+
+// Open prepares the workspace repository: it is initialized if the workspace
+// is not a repository yet, and the managed part of .gitignore is updated.
+func Open(ctx context.Context, dir string, cfg Config) (*Repo, error) {
+	if cfg.AuthorName == "" {
+		cfg.AuthorName = DefaultAuthorName
+	}
+	if cfg.AuthorEmail == "" {
+		cfg.AuthorEmail = DefaultAuthorEmail
+	}
+	git, err := exec.LookPath("git")
+	if err != nil {
+		return nil, ErrGitNotFound
+	}
+
+	r := &Repo{dir: dir, cfg: cfg, git: git}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if _, err := r.run(ctx, "init", "--quiet"); err != nil {
+			return nil, fmt.Errorf("failed to initialize workspace repository: %w", err)
+		}
+	}
+	if err := r.writeIgnore(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Commit commits all changes of the workspace for a turn. committed is false
+// if nothing changed.
+func (r *Repo) Commit(ctx context.Context, turn Turn) (hash string, committed bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.run(ctx, "add", "--all"); err != nil {
+		return "", false, fmt.Errorf("failed to stage changes: %w", err)
+	}
+	status, err := r.run(ctx, "status", "--porcelain")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to check changes: %w", err)
+	}
+	if strings.TrimSpace(status) == "" {
+		return "", false, nil
+	}
+
+	if _, err := r.run(ctx, "commit", "--quiet", "--no-verify", "-m", Message(turn)); err != nil {
+		return "", false, fmt.Errorf("failed to commit changes: %w", err)
+	}
+	hash, err = r.run(ctx, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return "", true, fmt.Errorf("failed to read commit: %w", err)
+	}
+	return strings.TrimSpace(hash), true, nil
+}
+
+// Message formats the commit message of a turn: the correlation ID and the
+// request in the subject, the session and correlation ID as trailers.
+func Message(turn Turn) string {
+	request := strings.Join(strings.Fields(turn.Request), " ")
+	if utf8.RuneCountInString(request) > maxSubjectChars {
+		request = string([]rune(request)[:maxSubjectChars-1]) + "…"
+	}
+
+	subject := "Agent turn " + turn.CorrelationID
+	if request != "" {
+		subject += ": " + request
+	}
+	var b strings.Builder
+	b.WriteString(subject + "\n\n")
+	if turn.SessionID != "" {
+		b.WriteString("Session: " + turn.SessionID + "\n")
+	}
+	b.WriteString("Correlation-ID: " + turn.CorrelationID + "\n")
+	return b.String()
+}
+
+// writeIgnore updates the managed part of .gitignore, keeping the user's lines.
+func (r *Repo) writeIgnore() error {
+	path := filepath.Join(r.dir, ".gitignore")
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read .gitignore: %w", err)
+	}
+
+	var user []string
+	managed := false
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		switch {
+		case line == ignoreBegin:
+			managed = true
+		case line == ignoreEnd:
+			managed = false
+		case !managed && (line != "" || len(user) > 0):
+			user = append(user, line)
+		}
+	}
+
+	lines := append([]string{ignoreBegin}, r.cfg.Exclude...)
+	lines = append(lines, ignoreEnd)
+	if len(user) > 0 {
+		lines = append(append(lines, ""), user...)
+	}
+	content := strings.Join(lines, "\n") + "\n"
+	if content == string(data) {
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write .gitignore: %w", err)
+	}
+	return nil
+}
+
+// run runs a git command in the repository and returns its output.
+func (r *Repo) run(ctx context.Context, args ...string) (string, error) {
+	command := args[0]
+	args = append([]string{
+		"-C", r.dir,
+		"-c", "user.name=" + r.cfg.AuthorName,
+		"-c", "user.email=" + r.cfg.AuthorEmail,
+		"-c", "commit.gpgsign=false",
+	}, args...)
+	cmd := exec.CommandContext(ctx, r.git, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %w: %s", command, err, msg)
+		}
+		return "", fmt.Errorf("git %s: %w", command, err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,109 @@
+package gitws
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// openRepo opens a repository in a temporary workspace, skipping the test
+// without git.
+func openRepo(t *testing.T, dir string) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo, err := Open(context.Background(), dir, Config{Exclude: []string{"/sessions/", "/secrets/"}})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return repo
+}
+
+// gitOutput runs a git command in dir.
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return string(out)
+}
+
+func TestRepo_Commit(t *testing.T) {
+	dir := t.TempDir()
+	repo := openRepo(t, dir)
+	ctx := context.Background()
+
+	for path, content := range map[string]string{
+		"notes/todo.md":         "- buy milk\n",
+		"sessions/telegram.log": "history",
+		"secrets/token":         "s3cr3t",
+	} {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hash, committed, err := repo.Commit(ctx, Turn{CorrelationID: "abc123", SessionID: "telegram:1", Request: "add milk to my todo"})
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if !committed || hash == "" {
+		t.Fatalf("Commit() = %q, %v, want a commit", hash, committed)
+	}
+
+	files := gitOutput(t, dir, "show", "--name-only", "--format=", "HEAD")
+	if !strings.Contains(files, "notes/todo.md") || !strings.Contains(files, ".gitignore") {
+		t.Errorf("committed files = %q", files)
+	}
+	if strings.Contains(files, "sessions/") || strings.Contains(files, "secrets/") {
+		t.Errorf("excluded files were committed: %q", files)
+	}
+	message := gitOutput(t, dir, "log", "-1", "--format=%B")
+	if !strings.HasPrefix(message, "Agent turn abc123: add milk to my todo") || !strings.Contains(message, "Correlation-ID: abc123") {
+		t.Errorf("commit message = %q", message)
+	}
+
+	// A turn without changes makes no commit
+	if _, committed, err := repo.Commit(ctx, Turn{CorrelationID: "def456"}); err != nil || committed {
+		t.Errorf("Commit() without changes = %v, %v", committed, err)
+	}
+}
+
+func TestOpen_KeepsUserIgnoreLines(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.bak\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	openRepo(t, dir)
+	// Opening again replaces the managed block only
+	openRepo(t, dir)
+
+	data, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ignoreBegin + "\n/sessions/\n/secrets/\n" + ignoreEnd + "\n\n*.bak\n"
+	if string(data) != want {
+		t.Errorf(".gitignore = %q, want %q", data, want)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	message := Message(Turn{CorrelationID: "abc123", Request: strings.Repeat("word ", 20)})
+	subject, _, _ := strings.Cut(message, "\n")
+	if !strings.HasPrefix(subject, "Agent turn abc123: word word") || !strings.HasSuffix(subject, "…") {
+		t.Errorf("subject = %q", subject)
+	}
+	if strings.Contains(message, "Session:") {
+		t.Errorf("message without session = %q", message)
+	}
+}
@@ -0,0 +1,94 @@
+package guardrail
+
+import (
+	"context"
+	"slices"
+)
+
+// Actions taken on content flagged as a prompt injection.
+const (
+	ActionWarn  = "warn"  // Keep the content and add a warning
+	ActionBlock = "block" // Replace the content with a notice
+)
+
+// DefaultTools are the tools whose output is treated as untrusted by default.
+var DefaultTools = []string{"web_fetch", "read_file", "search"}
+
+// Config configures a Guard.
+type Config struct {
+	Tools      []string   // Tools whose output is untrusted
+	Action     string     // ActionWarn or ActionBlock
+	Classifier Classifier // Optional second opinion on suspicious content
+}
+
+// Result describes how tool output was processed.
+type Result struct {
+	Findings []Finding
+	Verdict  *Verdict // Classifier verdict, nil if not classified
+	Flagged  bool     // Content is considered an injection attempt
+	Blocked  bool     // Content was withheld
+}
+
+// Guard applies guardrails to tool outputs.
+type Guard struct {
+	tools      []string
+	action     string
+	classifier Classifier
+}
+
+// New creates a Guard. Empty Tools default to DefaultTools, empty Action to ActionWarn.
+func New(cfg Config) *Guard {
+	g := &Guard{
+		tools:      cfg.Tools,
+		action:     cfg.Action,
+		classifier: cfg.Classifier,
+	}
+	if len(g.tools) == 0 {
+		g.tools = DefaultTools
+	}
+	if g.action == "" {
+		g.action = ActionWarn
+	}
+	return g
+}
+
+// Applies reports whether the output of the tool is treated as untrusted.
+func (g *Guard) Applies(toolName string) bool {
+	return slices.Contains(g.tools, toolName)
+}
+
+// Process scans the output of an untrusted tool and returns the content to
+// pass to the LLM. Suspicious content is classified when a classifier is
+// configured; if classification fails, the pattern findings decide.
+// Output of other tools is returned unchanged.
+func (g *Guard) Process(ctx context.Context, toolName, content string) (string, Result) {
+	if !g.Applies(toolName) {
+		return content, Result{}
+	}
+
+	result := Result{Findings: Scan(content)}
+	if len(result.Findings) > 0 {
+		result.Flagged = true
+		if g.classifier != nil {
+			if verdict, err := g.classifier.Classify(ctx, content, result.Findings); err == nil {
+				result.Verdict = &verdict
+				result.Flagged = verdict.Injection
+			}
+		}
+	}
+
+	if result.Flagged && g.action == ActionBlock {
+		result.Blocked = true
+		reason := ""
+		if result.Verdict != nil {
+			reason = result.Verdict.Reason
+		}
+		return Withhold(toolName, result.Findings, reason), result
+	}
+
+	var warnings []Finding
+	if result.Flagged {
+		warnings = result.Findings
+	}
+	return Wrap(toolName, content, warnings), result
+}
@@ -0,0 +1,71 @@
+// Package guardrail protects the agent loop from prompt injection in tool
+// outputs: it scans untrusted content (fetched pages, files) for injection
+// patterns, wraps it in clearly delimited blocks and optionally asks a cheap
+// model to classify suspicious content.
+package guardrail
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Finding is a suspicious fragment found in untrusted content.
+type Finding struct {
+	Pattern string // Pattern name
+	Excerpt string // Matched text
+}
+
+// pattern is a named injection pattern.
+type pattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// maxExcerptRunes limits the length of a finding excerpt
+const maxExcerptRunes = 80
+
+var patterns = []pattern{
+	{"ignore_instructions", regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\b.{0,30}\b(previous|prior|above|earlier|all|your|system)\b.{0,30}\b(instructions?|prompts?|rules|directions|guidelines)\b`)},
+	{"new_instructions", regexp.MustCompile(`(?i)\b(new|updated|real|actual)\s+(system\s+)?instructions?\s*:`)},
+	{"role_override", regexp.MustCompile(`(?i)\byou\s+are\s+now\s+(a|an|the|in)\b|\bact\s+as\s+(a|an)\s+\w+\s+(without|with\s+no)\s+(restrictions|limits|rules)`)},
+	{"system_prompt_probe", regexp.MustCompile(`(?i)\b(reveal|print|show|repeat|output)\b.{0,30}\b(system\s+prompt|hidden\s+instructions|initial\s+instructions)\b`)},
+	{"chat_template_tokens", regexp.MustCompile(`<\|(im_start|im_end|system|endoftext)\|>|\[/?INST\]|<</?SYS>>`)},
+	{"fake_role_header", regexp.MustCompile(`(?im)^\s*(###\s*)?(system|assistant)\s*:\s*\S`)},
+	{"tool_abuse", regexp.MustCompile(`(?i)\b(call|use|run|execute)\s+(the\s+)?(shell_exec|write_file|delete_file|send_message|notify|spawn)\b`)},
+	{"exfiltration", regexp.MustCompile(`(?i)\b(send|post|upload|forward)\b.{0,40}\b(api[\s_-]?keys?|tokens?|secrets?|passwords?|credentials)\b.{0,40}\b(to|at)\b`)},
+	{"ignore_instructions_ru", regexp.MustCompile(`(?i)(игнорируй|забудь|не\s+учитывай).{0,30}(предыдущие|все|прошлые|системные).{0,30}(инструкции|указания|правила)`)},
+}
+
+// Scan looks for prompt injection patterns in content.
+// Returns one finding per matched pattern.
+func Scan(content string) []Finding {
+	var findings []Finding
+	for _, p := range patterns {
+		if match := p.re.FindString(content); match != "" {
+			findings = append(findings, Finding{
+				Pattern: p.name,
+				Excerpt: excerpt(match),
+			})
+		}
+	}
+	return findings
+}
+
+// PatternNames returns the names of the findings.
+func PatternNames(findings []Finding) []string {
+	names := make([]string, len(findings))
+	for i, f := range findings {
+		names[i] = f.Pattern
+	}
+	return names
+}
+
+// excerpt normalizes whitespace and shortens a matched fragment.
+func excerpt(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= maxExcerptRunes {
+		return s
+	}
+	return string(runes[:maxExcerptRunes-1]) + "…"
+}
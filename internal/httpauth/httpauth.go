@@ -0,0 +1,238 @@
+// Package httpauth authenticates inbound HTTP requests (webhooks, HTTP API)
+// per route: HMAC signatures (GitHub-style), bearer tokens and IP allowlists.
+//
+// Nexbot has no inbound HTTP channel yet; the package provides the
+// middleware for such a channel to mount in front of its handlers.
+package httpauth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultSignatureHeader is the header carrying the HMAC signature
+	DefaultSignatureHeader = "X-Hub-Signature-256"
+
+	// DefaultMaxBodyBytes limits the body read for signature verification
+	DefaultMaxBodyBytes = 1 << 20
+
+	// signaturePrefix precedes the hex-encoded HMAC-SHA256 digest
+	signaturePrefix = "sha256="
+)
+
+var (
+	// ErrUnauthorized is returned for missing or invalid credentials
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrForbidden is returned for requests from addresses outside the allowlist
+	// and for paths without a route
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Route configures authentication for requests whose path starts with Path.
+// All configured checks must pass; a route without checks allows every request.
+type Route struct {
+	Path            string   // Path prefix ("/" matches all paths)
+	Secret          string   // HMAC-SHA256 secret; enables signature verification
+	SignatureHeader string   // Header with "sha256=<hex>" (DefaultSignatureHeader if empty)
+	Tokens          []string // Accepted bearer tokens
+	AllowedIPs      []string // Allowed client IPs and CIDRs
+	MaxBodyBytes    int64    // Body size limit for signed requests (DefaultMaxBodyBytes if 0)
+}
+
+type route struct {
+	Route
+	prefixes []netip.Prefix
+}
+
+// Guard authenticates requests against the most specific matching route.
+type Guard struct {
+	routes []route
+}
+
+// New validates routes and creates a Guard.
+func New(routes []Route) (*Guard, error) {
+	g := &Guard{routes: make([]route, 0, len(routes))}
+	for i, r := range routes {
+		if !strings.HasPrefix(r.Path, "/") {
+			return nil, fmt.Errorf("route %d: path must start with / (got: %q)", i, r.Path)
+		}
+		if r.SignatureHeader == "" {
+			r.SignatureHeader = DefaultSignatureHeader
+		}
+		if r.MaxBodyBytes <= 0 {
+			r.MaxBodyBytes = DefaultMaxBodyBytes
+		}
+
+		compiled := route{Route: r}
+		for _, entry := range r.AllowedIPs {
+			prefix, err := parsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("route %s: %w", r.Path, err)
+			}
+			compiled.prefixes = append(compiled.prefixes, prefix)
+		}
+		g.routes = append(g.routes, compiled)
+	}
+
+	// Longest prefix first, so the most specific route wins
+	sort.SliceStable(g.routes, func(i, j int) bool {
+		return len(g.routes[i].Path) > len(g.routes[j].Path)
+	})
+	return g, nil
+}
+
+// parsePrefix parses an IP address or CIDR.
+func parsePrefix(entry string) (netip.Prefix, error) {
+	entry = strings.TrimSpace(entry)
+	if addr, err := netip.ParseAddr(entry); err == nil {
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(entry)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid allowed IP: %s (expected: IP address or CIDR)", entry)
+	}
+	return prefix.Masked(), nil
+}
+
+// match returns the most specific route for path.
+func (g *Guard) match(path string) (*route, bool) {
+	for i := range g.routes {
+		prefix := g.routes[i].Path
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return &g.routes[i], true
+		}
+	}
+	return nil, false
+}
+
+// Verify authenticates r. Requests to paths without a route are rejected.
+// For signed routes the body is read and replaced, so handlers can read it again.
+func (g *Guard) Verify(r *http.Request) error {
+	rt, ok := g.match(r.URL.Path)
+	if !ok {
+		return fmt.Errorf("%w: no route for %s", ErrForbidden, r.URL.Path)
+	}
+
+	if len(rt.prefixes) > 0 {
+		if err := rt.checkIP(r.RemoteAddr); err != nil {
+			return err
+		}
+	}
+	if len(rt.Tokens) > 0 {
+		if err := rt.checkToken(r.Header.Get("Authorization")); err != nil {
+			return err
+		}
+	}
+	if rt.Secret != "" {
+		if err := rt.checkSignature(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkIP verifies the client address (the connection peer; proxy headers are not trusted).
+func (rt *route) checkIP(remoteAddr string) error {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("%w: invalid client address %q", ErrForbidden, remoteAddr)
+	}
+	addr = addr.Unmap()
+	for _, prefix := range rt.prefixes {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: client address %s is not allowed", ErrForbidden, addr)
+}
+
+// checkToken verifies an "Authorization: Bearer <token>" header.
+func (rt *route) checkToken(header string) error {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return fmt.Errorf("%w: missing bearer token", ErrUnauthorized)
+	}
+	for _, expected := range rt.Tokens {
+		if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(expected)) == 1 {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: invalid bearer token", ErrUnauthorized)
+}
+
+// checkSignature verifies the HMAC-SHA256 signature of the request body.
+func (rt *route) checkSignature(r *http.Request) error {
+	signature := r.Header.Get(rt.SignatureHeader)
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return fmt.Errorf("%w: missing %s signature", ErrUnauthorized, rt.SignatureHeader)
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrUnauthorized)
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = io.ReadAll(io.LimitReader(r.Body, rt.MaxBodyBytes+1))
+		_ = r.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+	if int64(len(body)) > rt.MaxBodyBytes {
+		return fmt.Errorf("%w: body exceeds %d bytes", ErrUnauthorized, rt.MaxBodyBytes)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if !hmac.Equal(Sign(rt.Secret, body), expected) {
+		return fmt.Errorf("%w: signature mismatch", ErrUnauthorized)
+	}
+	return nil
+}
+
+// Sign returns the HMAC-SHA256 digest of body.
+func Sign(secret string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// SignatureHeaderValue returns the "sha256=<hex>" header value for body.
+func SignatureHeaderValue(secret string, body []byte) string {
+	return signaturePrefix + hex.EncodeToString(Sign(secret, body))
+}
+
+// Middleware rejects unauthenticated requests with 401 or 403 before they reach next.
+func (g *Guard) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := g.Verify(r); err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, ErrForbidden) {
+				status = http.StatusForbidden
+			} else if !errors.Is(err, ErrUnauthorized) {
+				status = http.StatusBadRequest
+			}
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,115 @@
+package httpauth
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestGuard(t *testing.T) *Guard {
+	t.Helper()
+	g, err := New([]Route{
+		{Path: "/", Tokens: []string{"api-token"}},
+		{Path: "/webhooks/github", Secret: "gh-secret"},
+		{Path: "/webhooks/internal", AllowedIPs: []string{"10.0.0.0/8", "::1"}, Tokens: []string{"internal-token"}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return g
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New([]Route{{Path: "webhooks"}}); err == nil {
+		t.Error("Expected error for path without leading slash")
+	}
+	if _, err := New([]Route{{Path: "/", AllowedIPs: []string{"not-an-ip"}}}); err == nil {
+		t.Error("Expected error for invalid allowed IP")
+	}
+}
+
+func TestGuard_Verify(t *testing.T) {
+	g := newTestGuard(t)
+	body := `{"action":"opened"}`
+
+	tests := []struct {
+		name    string
+		path    string
+		remote  string
+		headers map[string]string
+		body    string
+		wantErr error
+	}{
+		{"bearer token", "/api/messages", "192.0.2.1:5000", map[string]string{"Authorization": "Bearer api-token"}, "", nil},
+		{"missing token", "/api/messages", "192.0.2.1:5000", nil, "", ErrUnauthorized},
+		{"wrong token", "/api/messages", "192.0.2.1:5000", map[string]string{"Authorization": "Bearer nope"}, "", ErrUnauthorized},
+		{"valid signature", "/webhooks/github", "192.0.2.1:5000",
+			map[string]string{DefaultSignatureHeader: SignatureHeaderValue("gh-secret", []byte(body))}, body, nil},
+		{"signature of another body", "/webhooks/github", "192.0.2.1:5000",
+			map[string]string{DefaultSignatureHeader: SignatureHeaderValue("gh-secret", []byte("{}"))}, body, ErrUnauthorized},
+		{"missing signature", "/webhooks/github/push", "192.0.2.1:5000", nil, body, ErrUnauthorized},
+		{"prefix is not a path segment", "/webhooks/githubx", "192.0.2.1:5000", nil, "", ErrUnauthorized},
+		{"allowed IP", "/webhooks/internal", "10.1.2.3:5000", map[string]string{"Authorization": "Bearer internal-token"}, "", nil},
+		{"allowed IPv6", "/webhooks/internal", "[::1]:5000", map[string]string{"Authorization": "Bearer internal-token"}, "", nil},
+		{"denied IP", "/webhooks/internal", "192.0.2.1:5000", map[string]string{"Authorization": "Bearer internal-token"}, "", ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.RemoteAddr = tt.remote
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			err := g.Verify(req)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && tt.body != "" {
+				data, _ := io.ReadAll(req.Body)
+				if string(data) != tt.body {
+					t.Errorf("Body after Verify() = %q, want %q", data, tt.body)
+				}
+			}
+		})
+	}
+}
+
+func TestGuard_Middleware(t *testing.T) {
+	g, err := New([]Route{{Path: "/hooks", Tokens: []string{"token"}}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	handler := g.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		path   string
+		token  string
+		status int
+	}{
+		{"/hooks", "token", http.StatusNoContent},
+		{"/hooks", "", http.StatusUnauthorized},
+		{"/other", "token", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("%s (token %q): status = %d, want %d", tt.path, tt.token, rec.Code, tt.status)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// Package invites provides single-use invite codes for self-service
+// whitelisting: an admin creates a code, a new user redeems it with /start
+// and stays allowed to use the bot. Codes and redemptions are persisted in
+// the workspace.
+package invites
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// InvitesSubdirectory is the subdirectory name for invites within workspace
+	InvitesSubdirectory = "invites"
+
+	// InvitesFilename is the filename of the invite store
+	InvitesFilename = "invites.json"
+
+	// DefaultTTL is how long an unused invite code stays valid
+	DefaultTTL = 72 * time.Hour
+)
+
+var (
+	// ErrNotFound is returned for unknown invite codes.
+	ErrNotFound = errors.New("invite code not found")
+
+	// ErrUsed is returned for invite codes that were already redeemed.
+	ErrUsed = errors.New("invite code already used")
+
+	// ErrExpired is returned for invite codes past their expiry.
+	ErrExpired = errors.New("invite code expired")
+)
+
+// Invite is a single-use invite code.
+type Invite struct {
+	Code      string    `json:"code"`
+	CreatedBy string    `json:"created_by"` // User ID of the admin
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UsedBy    string    `json:"used_by,omitempty"` // User ID that redeemed the code
+	UsedAt    time.Time `json:"used_at,omitzero"`
+}
+
+// Used reports whether the invite was redeemed.
+func (i Invite) Used() bool {
+	return i.UsedBy != ""
+}
+
+// Store keeps invite codes. It is safe for concurrent use.
+type Store struct {
+	filePath string
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	invites map[string]*Invite // By code
+}
+
+// NewStore creates a store persisted at <workspace>/invites/invites.json.
+// Codes expire after ttl (DefaultTTL if 0).
+func NewStore(workspacePath string, ttl time.Duration) *Store {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Store{
+		filePath: filepath.Join(workspacePath, InvitesSubdirectory, InvitesFilename),
+		ttl:      ttl,
+		now:      time.Now,
+		invites:  make(map[string]*Invite),
+	}
+}
+
+// TTL returns how long new codes stay valid.
+func (s *Store) TTL() time.Duration {
+	return s.ttl
+}
+
+// Load reads stored invites.
+func (s *Store) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read invites: %w", err)
+	}
+	var stored []Invite
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to parse invites: %w", err)
+	}
+	for i := range stored {
+		inv := stored[i]
+		s.invites[inv.Code] = &inv
+	}
+	return nil
+}
+
+// Create generates a new invite code on behalf of an admin.
+func (s *Store) Create(createdBy string) (Invite, error) {
+	code, err := newCode()
+	if err != nil {
+		return Invite{}, err
+	}
+	now := s.now()
+	inv := &Invite{Code: code, CreatedBy: createdBy, CreatedAt: now, ExpiresAt: now.Add(s.ttl)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invites[code] = inv
+	if err := s.saveLocked(); err != nil {
+		delete(s.invites, code)
+		return Invite{}, err
+	}
+	return *inv, nil
+}
+
+// Redeem marks a code as used by a user, who is allowed from then on.
+func (s *Store) Redeem(code, userID string) (Invite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv, ok := s.invites[code]
+	switch {
+	case !ok:
+		return Invite{}, ErrNotFound
+	case inv.Used():
+		return Invite{}, ErrUsed
+	case !s.now().Before(inv.ExpiresAt):
+		return Invite{}, ErrExpired
+	}
+
+	inv.UsedBy = userID
+	inv.UsedAt = s.now()
+	if err := s.saveLocked(); err != nil {
+		inv.UsedBy, inv.UsedAt = "", time.Time{}
+		return Invite{}, err
+	}
+	return *inv, nil
+}
+
+// Allowed reports whether the user redeemed an invite code.
+func (s *Store) Allowed(userID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, inv := range s.invites {
+		if inv.UsedBy == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Pending returns the unused codes that haven't expired, oldest first.
+func (s *Store) Pending() []Invite {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.now()
+	var list []Invite
+	for _, inv := range s.invites {
+		if !inv.Used() && now.Before(inv.ExpiresAt) {
+			list = append(list, *inv)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list
+}
+
+// saveLocked persists the store. Caller must hold s.mu.
+func (s *Store) saveLocked() error {
+	list := make([]Invite, 0, len(s.invites))
+	for _, inv := range s.invites {
+		list = append(list, *inv)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+
+	if err := os.MkdirAll(filepath.Dir(s.filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create invites directory: %w", err)
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal invites: %w", err)
+	}
+
+	// Write to temp file first, then rename for atomicity
+	tmpPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write invites: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.filePath); err != nil {
+		return fmt.Errorf("failed to save invites: %w", err)
+	}
+	return nil
+}
+
+// newCode returns a random code usable in a /start deep link.
+func newCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate invite code: %w", err)
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b), nil
+}
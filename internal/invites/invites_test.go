@@ -0,0 +1,68 @@
+package invites
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStore_CreateAndRedeem(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	s := NewStore(dir, time.Hour)
+	s.now = func() time.Time { return now }
+
+	inv, err := s.Create("1")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if len(inv.Code) != 8 || !inv.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("invite = %+v", inv)
+	}
+	if pending := s.Pending(); len(pending) != 1 || pending[0].Code != inv.Code {
+		t.Errorf("Pending() = %+v", pending)
+	}
+
+	if _, err := s.Redeem("NOPE", "42"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Redeem(unknown) error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Redeem(inv.Code, "42"); err != nil {
+		t.Fatalf("Redeem() error = %v", err)
+	}
+	if _, err := s.Redeem(inv.Code, "43"); !errors.Is(err, ErrUsed) {
+		t.Errorf("second Redeem() error = %v, want ErrUsed", err)
+	}
+	if !s.Allowed("42") || s.Allowed("43") {
+		t.Error("only the user who redeemed the code must be allowed")
+	}
+	if len(s.Pending()) != 0 {
+		t.Error("used codes must not be pending")
+	}
+
+	// Redemptions survive a restart
+	reloaded := NewStore(dir, time.Hour)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reloaded.Allowed("42") {
+		t.Error("redeemed user is not allowed after reload")
+	}
+}
+
+func TestStore_RedeemExpired(t *testing.T) {
+	now := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	s := NewStore(t.TempDir(), time.Hour)
+	s.now = func() time.Time { return now }
+
+	inv, err := s.Create("1")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	now = now.Add(time.Hour)
+	if _, err := s.Redeem(inv.Code, "42"); !errors.Is(err, ErrExpired) {
+		t.Errorf("Redeem() error = %v, want ErrExpired", err)
+	}
+	if s.Allowed("42") || len(s.Pending()) != 0 {
+		t.Error("expired code must not allow or stay pending")
+	}
+}
@@ -9,21 +9,25 @@ import (
 	"strings"
 
 	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/agent/transcript"
 	"github.com/aatumaykin/nexbot/internal/bus"
 	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/structured"
 )
 
 // Request структура запроса от CLI
 type Request struct {
-	Type      string `json:"type"`
-	SessionID string `json:"session_id"`
-	Content   string `json:"content"`
+	Type      string         `json:"type"`
+	SessionID string         `json:"session_id"`
+	Content   string         `json:"content"`
+	Schema    map[string]any `json:"schema,omitempty"` // JSON Schema ответа (structured)
 }
 
 // Response структура ответа CLI
 type Response struct {
 	Success bool   `json:"success"`
 	Error   string `json:"error,omitempty"`
+	Content string `json:"content,omitempty"`
 }
 
 // Handler обрабатывает IPC запросы
@@ -33,6 +37,7 @@ type Handler struct {
 	ctx        context.Context
 	sessionMgr *session.Manager
 	messageBus *bus.MessageBus
+	structured *structured.Generator
 }
 
 // NewHandler создаёт новый IPC Handler
@@ -50,6 +55,11 @@ func NewHandler(l *logger.Logger, sessionDir string, messageBus *bus.MessageBus)
 	}, nil
 }
 
+// SetStructured включает запросы structured (ответ LLM в виде JSON по схеме)
+func (h *Handler) SetStructured(generator *structured.Generator) {
+	h.structured = generator
+}
+
 // Start запускает IPC сервер
 func (h *Handler) Start(ctx context.Context, socketPath string) error {
 	h.ctx = ctx
@@ -113,6 +123,10 @@ func (h *Handler) handleConnection(conn net.Conn) {
 		h.handleSendMessage(&req, conn)
 	case "agent":
 		h.handleAgent(&req, conn)
+	case "session_export":
+		h.handleSessionExport(&req, conn)
+	case "structured":
+		h.handleStructured(&req, conn)
 	default:
 		h.sendErrorResponse(conn, fmt.Sprintf("unknown request type: %s", req.Type))
 	}
@@ -233,6 +247,87 @@ func (h *Handler) handleAgent(req *Request, conn net.Conn) {
 	}
 }
 
+// handleSessionExport обрабатывает запрос экспорта транскрипта сессии.
+// Формат (markdown/html) передаётся в поле Content.
+func (h *Handler) handleSessionExport(req *Request, conn net.Conn) {
+	format, err := transcript.ParseFormat(req.Content)
+	if err != nil {
+		h.sendErrorResponse(conn, err.Error())
+		return
+	}
+
+	sess, err := h.sessionMgr.Get(req.SessionID)
+	if err != nil {
+		h.sendErrorResponse(conn, fmt.Sprintf("session not found: %s", req.SessionID))
+		return
+	}
+
+	entries, err := sess.ReadEntries()
+	if err != nil {
+		h.sendErrorResponse(conn, fmt.Sprintf("failed to read session: %v", err))
+		return
+	}
+
+	content, err := transcript.Render(req.SessionID, entries, format, transcript.Options{})
+	if err != nil {
+		h.sendErrorResponse(conn, err.Error())
+		return
+	}
+
+	h.logger.Info("session_export request processed",
+		logger.Field{Key: "session_id", Value: req.SessionID},
+		logger.Field{Key: "format", Value: string(format)})
+
+	resp := Response{
+		Success: true,
+		Content: content,
+	}
+	encoder := json.NewEncoder(conn)
+	if err := encoder.Encode(resp); err != nil {
+		h.logger.Error("failed to send response", err)
+	}
+}
+
+// handleStructured обрабатывает запрос структурированного ответа: Content —
+// задание для LLM, Schema — JSON Schema ответа. Ответ проверяется по схеме,
+// в Content возвращается JSON.
+func (h *Handler) handleStructured(req *Request, conn net.Conn) {
+	if h.structured == nil {
+		h.sendErrorResponse(conn, "structured output is disabled")
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		h.sendErrorResponse(conn, "content is required")
+		return
+	}
+
+	ctx := h.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	result, err := h.structured.Generate(ctx, structured.Request{
+		Prompt: req.Content,
+		Schema: req.Schema,
+	})
+	if err != nil {
+		h.sendErrorResponse(conn, err.Error())
+		return
+	}
+
+	h.logger.Info("structured request processed",
+		logger.Field{Key: "attempts", Value: result.Attempts},
+		logger.Field{Key: "tokens", Value: result.Tokens})
+
+	resp := Response{
+		Success: true,
+		Content: result.JSON,
+	}
+	encoder := json.NewEncoder(conn)
+	if err := encoder.Encode(resp); err != nil {
+		h.logger.Error("failed to send response", err)
+	}
+}
+
 // validateChannel проверяет валидность канала
 func (h *Handler) validateChannel(channelType string) error {
 	validTypes := map[string]bool{
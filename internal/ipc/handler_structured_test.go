@@ -0,0 +1,66 @@
+package ipc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/structured"
+)
+
+// structuredRequest отправляет запрос structured и возвращает ответ
+func structuredRequest(t *testing.T, handler *Handler, req Request) Response {
+	t.Helper()
+	conn := &mockConn{}
+	if err := json.NewEncoder(&conn.readBuf).Encode(req); err != nil {
+		t.Fatalf("Failed to encode request: %v", err)
+	}
+	handler.handleConnection(conn)
+
+	var resp Response
+	if err := json.NewDecoder(&conn.writeBuf).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp
+}
+
+// Запрос structured возвращает JSON, проверенный по схеме
+func TestHandleStructured(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "info", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	handler, err := NewHandler(log, t.TempDir(), bus.New(100, 10, log))
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	req := Request{
+		Type:    "structured",
+		Content: "Name a primary color",
+		Schema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"color": map[string]any{"type": "string", "enum": []any{"red", "green", "blue"}}},
+			"required":   []any{"color"},
+		},
+	}
+
+	if resp := structuredRequest(t, handler, req); resp.Success || resp.Error != "structured output is disabled" {
+		t.Errorf("Expected disabled error, got %+v", resp)
+	}
+
+	provider := llm.NewFixturesProvider([]string{`{"color": "purple"}`, `{"color": "red"}`})
+	handler.SetStructured(structured.New(provider, structured.Config{}))
+
+	resp := structuredRequest(t, handler, req)
+	if !resp.Success || resp.Content != `{"color":"red"}` {
+		t.Errorf("Expected validated JSON, got %+v", resp)
+	}
+
+	req.Content = ""
+	if resp := structuredRequest(t, handler, req); resp.Success {
+		t.Errorf("Expected error for empty content, got %+v", resp)
+	}
+}
@@ -0,0 +1,82 @@
+// Package jobs implements a queue of non-interactive agent tasks for long
+// analyses the user does not need to watch. Jobs are stored as JSON files in
+// the workspace, so the CLI submits jobs and polls their status without a
+// connection to the running bot. The bot runs queued jobs one at a time while
+// no interactive messages are being processed and notifies the job session
+// when a job completes.
+package jobs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is the state of a job.
+type Status string
+
+const (
+	StatusQueued  Status = "queued"  // Waiting for the system to become idle
+	StatusRunning Status = "running" // Being processed by the agent
+	StatusDone    Status = "done"    // Completed, Result holds the response
+	StatusFailed  Status = "failed"  // Failed, Error holds the reason
+)
+
+// Finished reports whether the job will not change any more.
+func (s Status) Finished() bool {
+	return s == StatusDone || s == StatusFailed
+}
+
+// SessionChannel is the channel of sessions created for jobs submitted
+// without a session. Such jobs have no chat to notify.
+const SessionChannel = "job"
+
+// Job is a queued agent task.
+type Job struct {
+	ID         string     `json:"id"`
+	Prompt     string     `json:"prompt"`
+	SessionID  string     `json:"session_id"`
+	Status     Status     `json:"status"`
+	Result     string     `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// NewJob creates a queued job. Without a session the job runs in its own
+// session "job:<id>".
+func NewJob(prompt, sessionID string) (*Job, error) {
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return nil, fmt.Errorf("prompt cannot be empty")
+	}
+
+	id := fmt.Sprintf("job_%s", uuid.New().String()[:8])
+	sessionID = strings.TrimSpace(sessionID)
+	if sessionID == "" {
+		sessionID = SessionChannel + ":" + id
+	} else if channel, chatID, ok := strings.Cut(sessionID, ":"); !ok || channel == "" || chatID == "" {
+		return nil, fmt.Errorf("invalid session ID format, expected 'channel:chat_id', got: %s", sessionID)
+	}
+
+	return &Job{
+		ID:        id,
+		Prompt:    prompt,
+		SessionID: sessionID,
+		Status:    StatusQueued,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// Chat returns the channel and chat ID to notify about the job, or false if
+// the job runs in its own session.
+func (j *Job) Chat() (channel, chatID string, ok bool) {
+	channel, chatID, ok = strings.Cut(j.SessionID, ":")
+	if !ok || channel == SessionChannel {
+		return "", "", false
+	}
+	return channel, chatID, true
+}
@@ -0,0 +1,210 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+const (
+	// DefaultIdleAfter is the quiet period after interactive messages before a job starts
+	DefaultIdleAfter = 2 * time.Minute
+
+	// DefaultPollInterval is how often the queue is checked for new jobs
+	DefaultPollInterval = 10 * time.Second
+)
+
+// RunFunc processes a job and returns the agent response.
+type RunFunc func(ctx context.Context, job *Job) (string, error)
+
+// NotifyFunc is called when a job has finished.
+type NotifyFunc func(ctx context.Context, job *Job)
+
+// Config configures a Queue.
+type Config struct {
+	IdleAfter    time.Duration // Quiet period before a job starts (DefaultIdleAfter if 0, negative starts immediately)
+	PollInterval time.Duration // How often the store is checked (DefaultPollInterval if 0)
+}
+
+// Queue runs stored jobs one at a time while the system is idle.
+type Queue struct {
+	store  *Store
+	cfg    Config
+	run    RunFunc
+	notify NotifyFunc
+	logger *logger.Logger
+
+	mu           sync.Mutex
+	active       int       // Interactive messages being processed
+	lastActivity time.Time // End of the last interactive message
+	now          func() time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewQueue creates a queue. notify may be nil.
+func NewQueue(store *Store, cfg Config, run RunFunc, notify NotifyFunc, log *logger.Logger) *Queue {
+	if cfg.IdleAfter == 0 {
+		cfg.IdleAfter = DefaultIdleAfter
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	return &Queue{
+		store:  store,
+		cfg:    cfg,
+		run:    run,
+		notify: notify,
+		logger: log,
+		now:    time.Now,
+	}
+}
+
+// Begin records the start of an interactive message. Jobs do not start
+// until every Begin has a matching End and the idle period has passed.
+func (q *Queue) Begin() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.active++
+}
+
+// End records the end of an interactive message.
+func (q *Queue) End() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.active > 0 {
+		q.active--
+	}
+	q.lastActivity = q.now()
+}
+
+// Idle reports whether a job may start.
+func (q *Queue) Idle() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.active == 0 && q.now().Sub(q.lastActivity) >= q.cfg.IdleAfter
+}
+
+// Start requeues jobs interrupted by a previous shutdown and starts
+// processing the queue in the background.
+func (q *Queue) Start(ctx context.Context) error {
+	jobs, err := q.store.List()
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if job.Status == StatusRunning {
+			q.requeue(job)
+		}
+	}
+
+	ctx, q.cancel = context.WithCancel(ctx)
+	q.done = make(chan struct{})
+
+	go func() {
+		defer close(q.done)
+		ticker := time.NewTicker(q.cfg.PollInterval)
+		defer ticker.Stop()
+		for {
+			for ctx.Err() == nil && q.RunNext(ctx) {
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	q.logger.Info("Job queue started",
+		logger.Field{Key: "idle_after", Value: q.cfg.IdleAfter.String()})
+	return nil
+}
+
+// Stop stops processing and waits for the running job to be interrupted.
+// An interrupted job is queued again.
+func (q *Queue) Stop() {
+	if q.cancel == nil {
+		return
+	}
+	q.cancel()
+	<-q.done
+	q.logger.Info("Job queue stopped")
+}
+
+// RunNext runs the oldest queued job if the system is idle. Returns true if
+// a job was run.
+func (q *Queue) RunNext(ctx context.Context) bool {
+	if !q.Idle() {
+		return false
+	}
+
+	jobs, err := q.store.List()
+	if err != nil {
+		q.logger.Error("Failed to list jobs", err)
+		return false
+	}
+	var job *Job
+	for _, j := range jobs {
+		if j.Status == StatusQueued {
+			job = j
+			break
+		}
+	}
+	if job == nil {
+		return false
+	}
+
+	started := q.now()
+	job.Status = StatusRunning
+	job.StartedAt = &started
+	if err := q.store.Save(job); err != nil {
+		q.logger.Error("Failed to save job", err, logger.Field{Key: "job_id", Value: job.ID})
+		return false
+	}
+	q.logger.Info("Job started",
+		logger.Field{Key: "job_id", Value: job.ID},
+		logger.Field{Key: "session_id", Value: job.SessionID})
+
+	result, err := q.run(ctx, job)
+	if ctx.Err() != nil {
+		q.requeue(job)
+		return false
+	}
+
+	finished := q.now()
+	job.FinishedAt = &finished
+	if err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = StatusDone
+		job.Result = result
+	}
+	if err := q.store.Save(job); err != nil {
+		q.logger.Error("Failed to save job", err, logger.Field{Key: "job_id", Value: job.ID})
+	}
+	q.logger.Info("Job finished",
+		logger.Field{Key: "job_id", Value: job.ID},
+		logger.Field{Key: "status", Value: string(job.Status)},
+		logger.Field{Key: "duration", Value: finished.Sub(started).String()})
+
+	if q.notify != nil {
+		q.notify(ctx, job)
+	}
+	return true
+}
+
+// requeue puts an interrupted job back into the queue.
+func (q *Queue) requeue(job *Job) {
+	job.Status = StatusQueued
+	job.StartedAt = nil
+	if err := q.store.Save(job); err != nil {
+		q.logger.Error("Failed to requeue job", err, logger.Field{Key: "job_id", Value: job.ID})
+		return
+	}
+	q.logger.Info("Interrupted job queued again", logger.Field{Key: "job_id", Value: job.ID})
+}
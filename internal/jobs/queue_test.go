@@ -0,0 +1,104 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+func newTestQueue(t *testing.T, run RunFunc, notify NotifyFunc) (*Queue, *Store) {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	store := NewStore(t.TempDir())
+	return NewQueue(store, Config{IdleAfter: time.Minute}, run, notify, log), store
+}
+
+func TestQueue_RunNext(t *testing.T) {
+	var notified []*Job
+	q, store := newTestQueue(t,
+		func(ctx context.Context, job *Job) (string, error) {
+			if job.Prompt == "broken" {
+				return "", errors.New("llm unavailable")
+			}
+			return "report for " + job.Prompt, nil
+		},
+		func(ctx context.Context, job *Job) { notified = append(notified, job) })
+
+	ok, _ := store.Submit("logs", "telegram:1")
+	broken, _ := store.Submit("broken", "")
+
+	if !q.RunNext(context.Background()) || !q.RunNext(context.Background()) {
+		t.Fatal("Expected both jobs to run")
+	}
+	if q.RunNext(context.Background()) {
+		t.Error("Expected an empty queue")
+	}
+
+	done, _ := store.Load(ok.ID)
+	if done.Status != StatusDone || done.Result != "report for logs" || done.FinishedAt == nil {
+		t.Errorf("Expected done job with result, got %+v", done)
+	}
+	failed, _ := store.Load(broken.ID)
+	if failed.Status != StatusFailed || failed.Error != "llm unavailable" {
+		t.Errorf("Expected failed job with error, got %+v", failed)
+	}
+	if len(notified) != 2 || notified[0].ID != ok.ID {
+		t.Errorf("Expected 2 notifications in order, got %d", len(notified))
+	}
+}
+
+func TestQueue_WaitsForIdle(t *testing.T) {
+	q, store := newTestQueue(t, func(ctx context.Context, job *Job) (string, error) { return "ok", nil }, nil)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	q.now = func() time.Time { return now }
+	_, _ = store.Submit("analyze", "")
+
+	q.Begin()
+	if q.RunNext(context.Background()) {
+		t.Fatal("Expected no job while a message is being processed")
+	}
+	q.End()
+	if q.RunNext(context.Background()) {
+		t.Fatal("Expected no job right after a message")
+	}
+
+	now = now.Add(time.Minute)
+	if !q.RunNext(context.Background()) {
+		t.Error("Expected the job to run after the idle period")
+	}
+}
+
+func TestQueue_RequeuesInterruptedJobs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	q, store := newTestQueue(t, func(ctx context.Context, job *Job) (string, error) {
+		cancel()
+		return "", ctx.Err()
+	}, nil)
+	job, _ := store.Submit("analyze", "")
+
+	if q.RunNext(ctx) {
+		t.Error("Expected an interrupted job not to count as run")
+	}
+	loaded, _ := store.Load(job.ID)
+	if loaded.Status != StatusQueued || loaded.StartedAt != nil {
+		t.Errorf("Expected interrupted job to be queued again, got %+v", loaded)
+	}
+
+	// A job left running by a crash is queued again on start
+	loaded.Status = StatusRunning
+	_ = store.Save(loaded)
+	q.Begin() // keep the queue busy so the job is not run again
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	q.Stop()
+	if loaded, _ = store.Load(job.ID); loaded.Status != StatusQueued {
+		t.Errorf("Expected running job to be requeued on start, got %s", loaded.Status)
+	}
+}
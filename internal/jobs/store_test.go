@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewJob(t *testing.T) {
+	job, err := NewJob("  analyze the logs  ", "")
+	if err != nil {
+		t.Fatalf("NewJob() error = %v", err)
+	}
+	if job.Prompt != "analyze the logs" || job.Status != StatusQueued {
+		t.Errorf("NewJob() = %+v", job)
+	}
+	if job.SessionID != "job:"+job.ID {
+		t.Errorf("SessionID = %s, want own session", job.SessionID)
+	}
+	if _, _, ok := job.Chat(); ok {
+		t.Error("Expected a job in its own session to have no chat")
+	}
+
+	job, err = NewJob("analyze", "telegram:123")
+	if err != nil {
+		t.Fatalf("NewJob() error = %v", err)
+	}
+	if channel, chatID, ok := job.Chat(); !ok || channel != "telegram" || chatID != "123" {
+		t.Errorf("Chat() = %s, %s, %v", channel, chatID, ok)
+	}
+
+	if _, err := NewJob("", ""); err == nil {
+		t.Error("Expected error for empty prompt")
+	}
+	if _, err := NewJob("analyze", "telegram"); err == nil {
+		t.Error("Expected error for invalid session ID")
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	first, err := store.Submit("first", "")
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	second, err := store.Submit("second", "telegram:1")
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	loaded, err := store.Load(second.ID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Prompt != "second" || loaded.SessionID != "telegram:1" {
+		t.Errorf("Load() = %+v", loaded)
+	}
+
+	jobs, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != first.ID {
+		t.Errorf("List() should return 2 jobs oldest first, got %d", len(jobs))
+	}
+
+	for _, id := range []string{"job_missing", "../config", ""} {
+		if _, err := store.Load(id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Load(%q) error = %v, want ErrNotFound", id, err)
+		}
+	}
+}
+
+func TestStore_ListMissingDir(t *testing.T) {
+	jobs, err := NewStore(t.TempDir() + "/jobs").List()
+	if err != nil || len(jobs) != 0 {
+		t.Errorf("List() = %v, %v; want empty", jobs, err)
+	}
+}
@@ -0,0 +1,74 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileLock holds an exclusive lock on a file. The operating system drops
+// the lock when the process dies, so a standby takes over on its next
+// attempt. For instances on different hosts the file must be on a shared
+// disk with working locks (NFSv4, SMB).
+type FileLock struct {
+	path     string
+	identity string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileLock creates a lock on the file at path; identity is written into
+// the file while the lock is held.
+func NewFileLock(path, identity string) *FileLock {
+	return &FileLock{path: path, identity: identity}
+}
+
+// Acquire implements Lock.
+func (l *FileLock) Acquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file != nil {
+		return true, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return false, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	held, err := tryLockFile(f)
+	if err != nil || !held {
+		_ = f.Close()
+		return false, err
+	}
+
+	// Record the holder for operators; the lock itself is what counts
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(l.identity+"\n"), 0)
+	}
+	l.file = f
+	return true, nil
+}
+
+// Release implements Lock.
+func (l *FileLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := unlockFile(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
+}
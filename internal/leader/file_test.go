@@ -0,0 +1,56 @@
+package leader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileLock_SingleHolder(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "locks", "leader.lock")
+	first := NewFileLock(path, "first")
+	second := NewFileLock(path, "second")
+
+	held, err := first.Acquire(ctx)
+	if err != nil || !held {
+		t.Fatalf("first Acquire() = %v, %v; want true", held, err)
+	}
+	held, err = first.Acquire(ctx)
+	if err != nil || !held {
+		t.Fatalf("renewal Acquire() = %v, %v; want true", held, err)
+	}
+
+	held, err = second.Acquire(ctx)
+	if err != nil || held {
+		t.Fatalf("second Acquire() while held = %v, %v; want false", held, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(data)) != "first" {
+		t.Errorf("lock file = %q, want holder identity", data)
+	}
+
+	if err := first.Release(ctx); err != nil {
+		t.Fatalf("Release() error: %v", err)
+	}
+	held, err = second.Acquire(ctx)
+	if err != nil || !held {
+		t.Fatalf("second Acquire() after release = %v, %v; want true", held, err)
+	}
+	if err := second.Release(ctx); err != nil {
+		t.Fatalf("Release() error: %v", err)
+	}
+}
+
+func TestFileLock_ReleaseWithoutLock(t *testing.T) {
+	lock := NewFileLock(filepath.Join(t.TempDir(), "leader.lock"), "test")
+	if err := lock.Release(context.Background()); err != nil {
+		t.Errorf("Release() without lock error: %v", err)
+	}
+}
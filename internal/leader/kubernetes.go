@@ -0,0 +1,307 @@
+package leader
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// serviceAccountDir holds the credentials Kubernetes mounts into pods
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	// microTimeLayout is the layout of Lease timestamps
+	microTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+	// kubernetesRequestTimeout bounds a single API request
+	kubernetesRequestTimeout = 10 * time.Second
+)
+
+// errConflict reports that another instance changed the Lease first.
+var errConflict = errors.New("lease was modified concurrently")
+
+// KubernetesConfig configures a KubernetesLock.
+type KubernetesConfig struct {
+	Namespace     string        // Lease namespace; the pod namespace when empty
+	Name          string        // Lease name
+	Identity      string        // Holder identity written into the Lease
+	LeaseDuration time.Duration // How long the Lease is valid without renewal
+}
+
+// KubernetesLock holds a coordination.k8s.io/v1 Lease through the API
+// server, like client-go leader election. A Lease whose holder stopped
+// renewing it for LeaseDuration is taken over. Expiry is measured on the
+// local clock from the moment the Lease was last seen changing, so clock
+// skew between nodes doesn't matter.
+type KubernetesLock struct {
+	cfg       KubernetesConfig
+	server    string
+	tokenFile string
+	client    *http.Client
+	now       func() time.Time
+
+	mu           sync.Mutex
+	observed     leaseSpec
+	observedTime time.Time
+}
+
+// lease is the part of a Lease object the lock uses.
+type lease struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   leaseMetadata `json:"metadata"`
+	Spec       leaseSpec     `json:"spec"`
+}
+
+type leaseMetadata struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int    `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          string `json:"acquireTime,omitempty"`
+	RenewTime            string `json:"renewTime,omitempty"`
+	LeaseTransitions     int    `json:"leaseTransitions,omitempty"`
+}
+
+// NewKubernetesLock creates a Lease lock with the in-cluster service account
+// credentials.
+func NewKubernetesLock(cfg KubernetesConfig) (*KubernetesLock, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("not running in a Kubernetes pod: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+	}
+
+	if cfg.Namespace == "" {
+		data, err := os.ReadFile(serviceAccountDir + "/namespace")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pod namespace: %w", err)
+		}
+		cfg.Namespace = strings.TrimSpace(string(data))
+	}
+
+	ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("cluster CA contains no certificates")
+	}
+
+	client := &http.Client{
+		Timeout:   kubernetesRequestTimeout,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+	}
+	server := "https://" + net.JoinHostPort(host, port)
+	return newKubernetesLock(cfg, server, serviceAccountDir+"/token", client), nil
+}
+
+func newKubernetesLock(cfg KubernetesConfig, server, tokenFile string, client *http.Client) *KubernetesLock {
+	return &KubernetesLock{
+		cfg:       cfg,
+		server:    strings.TrimSuffix(server, "/"),
+		tokenFile: tokenFile,
+		client:    client,
+		now:       time.Now,
+	}
+}
+
+// Acquire implements Lock.
+func (l *KubernetesLock) Acquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	current, found, err := l.get(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if !found {
+		created := l.record(leaseSpec{}, now)
+		err := l.send(ctx, http.MethodPost, l.collectionPath(), created, nil)
+		if errors.Is(err, errConflict) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		l.observe(created.Spec, now)
+		return true, nil
+	}
+
+	if current.Spec != l.observed {
+		l.observe(current.Spec, now)
+	}
+	holder := current.Spec.HolderIdentity
+	if holder != "" && holder != l.cfg.Identity && !l.expired(current.Spec, now) {
+		return false, nil
+	}
+
+	updated := l.record(current.Spec, now)
+	updated.Metadata.ResourceVersion = current.Metadata.ResourceVersion
+	err = l.send(ctx, http.MethodPut, l.leasePath(), updated, nil)
+	if errors.Is(err, errConflict) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	l.observe(updated.Spec, now)
+	return true, nil
+}
+
+// Release implements Lock. The Lease is kept but its holder is cleared, so
+// a standby takes it on the next attempt instead of waiting for expiry.
+func (l *KubernetesLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current, found, err := l.get(ctx)
+	if err != nil || !found || current.Spec.HolderIdentity != l.cfg.Identity {
+		return err
+	}
+
+	current.Spec.HolderIdentity = ""
+	current.Spec.LeaseDurationSeconds = 1
+	current.Spec.RenewTime = l.now().UTC().Format(microTimeLayout)
+	err = l.send(ctx, http.MethodPut, l.leasePath(), current, nil)
+	if errors.Is(err, errConflict) {
+		return nil
+	}
+	return err
+}
+
+// record returns the Lease held by this instance, based on the previous spec.
+func (l *KubernetesLock) record(prev leaseSpec, now time.Time) lease {
+	spec := prev
+	stamp := now.UTC().Format(microTimeLayout)
+	if spec.HolderIdentity != l.cfg.Identity {
+		spec.HolderIdentity = l.cfg.Identity
+		spec.AcquireTime = stamp
+		if prev.HolderIdentity != "" || prev.AcquireTime != "" {
+			spec.LeaseTransitions++
+		}
+	}
+	spec.RenewTime = stamp
+	spec.LeaseDurationSeconds = int(l.cfg.LeaseDuration / time.Second)
+
+	return lease{
+		APIVersion: "coordination.k8s.io/v1",
+		Kind:       "Lease",
+		Metadata:   leaseMetadata{Name: l.cfg.Name, Namespace: l.cfg.Namespace},
+		Spec:       spec,
+	}
+}
+
+// observe remembers the Lease spec and when it was seen changing.
+func (l *KubernetesLock) observe(spec leaseSpec, now time.Time) {
+	l.observed = spec
+	l.observedTime = now
+}
+
+// expired reports whether the holder hasn't renewed the Lease for its duration.
+func (l *KubernetesLock) expired(spec leaseSpec, now time.Time) bool {
+	duration := time.Duration(spec.LeaseDurationSeconds) * time.Second
+	if duration <= 0 {
+		duration = l.cfg.LeaseDuration
+	}
+	return now.Sub(l.observedTime) > duration
+}
+
+func (l *KubernetesLock) collectionPath() string {
+	return fmt.Sprintf("/apis/coordination.k8s.io/v1/namespaces/%s/leases", l.cfg.Namespace)
+}
+
+func (l *KubernetesLock) leasePath() string {
+	return l.collectionPath() + "/" + l.cfg.Name
+}
+
+// get fetches the Lease; found is false when it doesn't exist yet.
+func (l *KubernetesLock) get(ctx context.Context) (lease, bool, error) {
+	var current lease
+	err := l.send(ctx, http.MethodGet, l.leasePath(), nil, &current)
+	var status *statusError
+	if errors.As(err, &status) && status.code == http.StatusNotFound {
+		return lease{}, false, nil
+	}
+	return current, err == nil, err
+}
+
+// statusError is an unexpected API server response.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("kubernetes API returned %d: %s", e.code, e.body)
+}
+
+// send performs an API request; a 409 Conflict is returned as errConflict.
+func (l *KubernetesLock) send(ctx context.Context, method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode lease: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, l.server+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// The token is read on every request: projected tokens are rotated
+	if l.tokenFile != "" {
+		token, err := os.ReadFile(l.tokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read service account token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("kubernetes API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read kubernetes API response: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusConflict:
+		return errConflict
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return &statusError{code: resp.StatusCode, body: strings.TrimSpace(string(data))}
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode lease: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLeaseServer stores a single Lease with optimistic concurrency.
+type fakeLeaseServer struct {
+	mu      sync.Mutex
+	lease   *lease
+	version int
+	auth    string
+}
+
+func (s *fakeLeaseServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.auth = r.Header.Get("Authorization")
+	const path = "/apis/coordination.k8s.io/v1/namespaces/bots/leases"
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == path+"/nexbot":
+		if s.lease == nil {
+			http.Error(w, `{"reason":"NotFound"}`, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(s.lease)
+	case r.Method == http.MethodPost && r.URL.Path == path:
+		if s.lease != nil {
+			http.Error(w, `{"reason":"AlreadyExists"}`, http.StatusConflict)
+			return
+		}
+		s.store(w, r)
+	case r.Method == http.MethodPut && r.URL.Path == path+"/nexbot":
+		var update lease
+		_ = json.NewDecoder(r.Body).Decode(&update)
+		if s.lease == nil || update.Metadata.ResourceVersion != s.lease.Metadata.ResourceVersion {
+			http.Error(w, `{"reason":"Conflict"}`, http.StatusConflict)
+			return
+		}
+		s.lease = &update
+		s.version++
+		s.lease.Metadata.ResourceVersion = strconv.Itoa(s.version)
+		_ = json.NewEncoder(w).Encode(s.lease)
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+func (s *fakeLeaseServer) store(w http.ResponseWriter, r *http.Request) {
+	var created lease
+	_ = json.NewDecoder(r.Body).Decode(&created)
+	s.version++
+	created.Metadata.ResourceVersion = strconv.Itoa(s.version)
+	s.lease = &created
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(s.lease)
+}
+
+func (s *fakeLeaseServer) holder() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lease == nil {
+		return ""
+	}
+	return s.lease.Spec.HolderIdentity
+}
+
+func newTestKubernetesLock(t *testing.T, server *httptest.Server, identity string, clock *time.Time) *KubernetesLock {
+	t.Helper()
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("secret-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	l := newKubernetesLock(KubernetesConfig{
+		Namespace:     "bots",
+		Name:          "nexbot",
+		Identity:      identity,
+		LeaseDuration: 15 * time.Second,
+	}, server.URL, tokenFile, server.Client())
+	l.now = func() time.Time { return *clock }
+	return l
+}
+
+func TestKubernetesLock_Failover(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeLeaseServer{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	active := newTestKubernetesLock(t, server, "active", &clock)
+	standby := newTestKubernetesLock(t, server, "standby", &clock)
+
+	if held, err := active.Acquire(ctx); err != nil || !held {
+		t.Fatalf("active Acquire() = %v, %v; want true", held, err)
+	}
+	if fake.auth != "Bearer secret-token" {
+		t.Errorf("Authorization = %q", fake.auth)
+	}
+	if held, err := standby.Acquire(ctx); err != nil || held {
+		t.Fatalf("standby Acquire() = %v, %v; want false", held, err)
+	}
+
+	// The active instance keeps renewing: the standby waits
+	clock = clock.Add(10 * time.Second)
+	if held, err := active.Acquire(ctx); err != nil || !held {
+		t.Fatalf("active renewal = %v, %v; want true", held, err)
+	}
+	clock = clock.Add(10 * time.Second)
+	if held, err := standby.Acquire(ctx); err != nil || held {
+		t.Fatalf("standby Acquire() while renewed = %v, %v; want false", held, err)
+	}
+
+	// The active instance dies: the standby takes over after the lease duration
+	clock = clock.Add(16 * time.Second)
+	if held, err := standby.Acquire(ctx); err != nil || !held {
+		t.Fatalf("standby Acquire() after expiry = %v, %v; want true", held, err)
+	}
+	if got := fake.holder(); got != "standby" {
+		t.Errorf("holder = %q, want standby", got)
+	}
+	if fake.lease.Spec.LeaseTransitions != 1 {
+		t.Errorf("leaseTransitions = %d, want 1", fake.lease.Spec.LeaseTransitions)
+	}
+	if held, err := active.Acquire(ctx); err != nil || held {
+		t.Fatalf("old leader Acquire() = %v, %v; want false", held, err)
+	}
+}
+
+func TestKubernetesLock_ReleaseHandsOver(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeLeaseServer{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	active := newTestKubernetesLock(t, server, "active", &clock)
+	standby := newTestKubernetesLock(t, server, "standby", &clock)
+
+	if held, err := active.Acquire(ctx); err != nil || !held {
+		t.Fatalf("active Acquire() = %v, %v; want true", held, err)
+	}
+	if err := active.Release(ctx); err != nil {
+		t.Fatalf("Release() error: %v", err)
+	}
+	if got := fake.holder(); got != "" {
+		t.Errorf("holder after release = %q, want empty", got)
+	}
+
+	// No need to wait for expiry after a release
+	if held, err := standby.Acquire(ctx); err != nil || !held {
+		t.Fatalf("standby Acquire() after release = %v, %v; want true", held, err)
+	}
+}
@@ -0,0 +1,159 @@
+// Package leader elects one active instance out of several nexbot processes
+// sharing a lock: a file lock on a shared disk or a Kubernetes Lease. Only the
+// leader runs the work passed to Run; the others wait and take over when the
+// leader exits or stops renewing its lock.
+package leader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+const (
+	// BackendFile locks a file on a disk shared by the instances
+	BackendFile = "file"
+
+	// BackendKubernetes holds a coordination.k8s.io Lease
+	BackendKubernetes = "kubernetes"
+
+	// releaseTimeout bounds releasing the lock on shutdown
+	releaseTimeout = 5 * time.Second
+)
+
+// Lock is a leadership lock shared by the instances.
+type Lock interface {
+	// Acquire takes the lock or renews it when this instance already holds
+	// it. It never blocks waiting for another holder and reports whether
+	// this instance holds the lock.
+	Acquire(ctx context.Context) (bool, error)
+
+	// Release gives the lock up so another instance can take it at once.
+	Release(ctx context.Context) error
+}
+
+// Config configures an Elector.
+type Config struct {
+	Identity      string        // Name of this instance in logs and lock records
+	RenewDeadline time.Duration // Leader steps down when the lock isn't renewed in time
+	RetryPeriod   time.Duration // Interval between Acquire calls
+}
+
+// Elector runs work only while this instance holds the lock.
+type Elector struct {
+	lock   Lock
+	cfg    Config
+	logger *logger.Logger
+	now    func() time.Time
+}
+
+// New creates an Elector for the lock.
+func New(lock Lock, cfg Config, log *logger.Logger) *Elector {
+	if cfg.Identity == "" {
+		cfg.Identity = DefaultIdentity()
+	}
+	return &Elector{lock: lock, cfg: cfg, logger: log, now: time.Now}
+}
+
+// DefaultIdentity names the instance by host name and process ID.
+func DefaultIdentity() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "nexbot"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Run campaigns for leadership until ctx is cancelled. When the lock is
+// acquired, lead is called with a context that is cancelled when leadership
+// is lost; stop is called right after. A lead error gives leadership up so
+// another instance can try. On return the lock is released.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context) error, stop func()) {
+	t := &term{elector: e, stop: stop}
+	ticker := time.NewTicker(e.cfg.RetryPeriod)
+	defer ticker.Stop()
+
+	for {
+		held, err := e.lock.Acquire(ctx)
+		switch {
+		case ctx.Err() != nil:
+			// Shutting down: the result of the last attempt doesn't matter
+		case err != nil:
+			e.logger.Warn("Leader lock attempt failed",
+				logger.Field{Key: "identity", Value: e.cfg.Identity},
+				logger.Field{Key: "error", Value: err.Error()})
+			if t.leading() && e.now().Sub(t.renewed) > e.cfg.RenewDeadline {
+				t.end("lock not renewed before the deadline")
+			}
+		case held && !t.leading():
+			if err := t.begin(ctx, lead); err != nil {
+				e.logger.Error("Failed to start as leader, stepping down", err,
+					logger.Field{Key: "identity", Value: e.cfg.Identity})
+				t.end("leader failed to start")
+				e.release()
+			}
+		case held:
+			t.renewed = e.now()
+		case t.leading():
+			t.end("lock taken by another instance")
+		}
+
+		select {
+		case <-ctx.Done():
+			if t.leading() {
+				t.end("shutdown")
+			}
+			e.release()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// term is the state of the current leadership term.
+type term struct {
+	elector *Elector
+	stop    func()
+	cancel  context.CancelFunc // nil when not leading
+	renewed time.Time          // Last successful Acquire while leading
+}
+
+func (t *term) leading() bool {
+	return t.cancel != nil
+}
+
+// begin starts a leadership term and runs lead.
+func (t *term) begin(ctx context.Context, lead func(ctx context.Context) error) error {
+	e := t.elector
+	e.logger.Info("Elected leader", logger.Field{Key: "identity", Value: e.cfg.Identity})
+
+	var leadCtx context.Context
+	leadCtx, t.cancel = context.WithCancel(ctx)
+	t.renewed = e.now()
+	return lead(leadCtx)
+}
+
+// end finishes the leadership term.
+func (t *term) end(reason string) {
+	t.cancel()
+	t.cancel = nil
+	t.stop()
+	t.elector.logger.Warn("Leadership lost",
+		logger.Field{Key: "identity", Value: t.elector.cfg.Identity},
+		logger.Field{Key: "reason", Value: reason})
+}
+
+// release gives the lock up with a fresh context: the run context is
+// usually cancelled already.
+func (e *Elector) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	if err := e.lock.Release(ctx); err != nil {
+		e.logger.Warn("Failed to release leader lock",
+			logger.Field{Key: "identity", Value: e.cfg.Identity},
+			logger.Field{Key: "error", Value: err.Error()})
+	}
+}
@@ -0,0 +1,137 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// fakeLock answers Acquire from a script controlled by the test.
+type fakeLock struct {
+	mu       sync.Mutex
+	held     bool
+	err      error
+	released int
+}
+
+func (l *fakeLock) set(held bool, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held, l.err = held, err
+}
+
+func (l *fakeLock) Acquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.held, l.err
+}
+
+func (l *fakeLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.released++
+	return nil
+}
+
+func (l *fakeLock) releases() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.released
+}
+
+func newTestElector(t *testing.T, lock Lock) *Elector {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(lock, Config{Identity: "test", RenewDeadline: 30 * time.Millisecond, RetryPeriod: 5 * time.Millisecond}, log)
+}
+
+// runElector runs the elector in the background and reports lead/stop calls.
+func runElector(ctx context.Context, e *Elector, leadErr error) (led, stopped chan struct{}, done chan struct{}) {
+	led, stopped, done = make(chan struct{}, 10), make(chan struct{}, 10), make(chan struct{})
+	go func() {
+		defer close(done)
+		e.Run(ctx, func(ctx context.Context) error {
+			led <- struct{}{}
+			return leadErr
+		}, func() { stopped <- struct{}{} })
+	}()
+	return led, stopped, done
+}
+
+func wait(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+func TestElector_LeadsAndStepsDownWhenLockIsLost(t *testing.T) {
+	lock := &fakeLock{held: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	led, stopped, done := runElector(ctx, newTestElector(t, lock), nil)
+	wait(t, led, "leadership")
+
+	lock.set(false, nil)
+	wait(t, stopped, "step down")
+
+	lock.set(true, nil)
+	wait(t, led, "second term")
+
+	cancel()
+	wait(t, stopped, "stop on shutdown")
+	wait(t, done, "Run to return")
+	if lock.releases() == 0 {
+		t.Error("lock was not released on shutdown")
+	}
+}
+
+func TestElector_StepsDownAfterRenewDeadline(t *testing.T) {
+	lock := &fakeLock{held: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	led, stopped, _ := runElector(ctx, newTestElector(t, lock), nil)
+	wait(t, led, "leadership")
+
+	// Errors shorter than the deadline keep the leader; longer ones don't
+	lock.set(false, errors.New("api unavailable"))
+	wait(t, stopped, "step down after deadline")
+}
+
+func TestElector_LeadErrorReleasesLock(t *testing.T) {
+	lock := &fakeLock{held: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	led, stopped, _ := runElector(ctx, newTestElector(t, lock), errors.New("telegram unavailable"))
+	wait(t, led, "leadership")
+	wait(t, stopped, "step down")
+	if lock.releases() == 0 {
+		t.Error("lock was not released after lead failed")
+	}
+}
+
+func TestElector_FollowerNeverLeads(t *testing.T) {
+	lock := &fakeLock{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	led, stopped, done := runElector(ctx, newTestElector(t, lock), nil)
+	time.Sleep(30 * time.Millisecond)
+	cancel()
+	wait(t, done, "Run to return")
+
+	if len(led) != 0 || len(stopped) != 0 {
+		t.Errorf("follower called lead %d and stop %d times", len(led), len(stopped))
+	}
+}
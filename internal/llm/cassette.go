@@ -0,0 +1,245 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ErrRecordingNotFound is returned in replay mode when no response was
+// recorded for a request.
+var ErrRecordingNotFound = errors.New("recording not found")
+
+// Cassette stores provider responses on disk keyed by request hash.
+// Each recording is a JSON file named <key>.json in the cassette directory.
+type Cassette struct {
+	dir string
+}
+
+// Recording is a single recorded request/response pair.
+type Recording struct {
+	Key      string       `json:"key"`
+	Request  ChatRequest  `json:"request"`
+	Response ChatResponse `json:"response"`
+}
+
+// NewCassette creates a cassette backed by the specified directory.
+func NewCassette(dir string) *Cassette {
+	return &Cassette{dir: dir}
+}
+
+// Dir returns the cassette directory.
+func (c *Cassette) Dir() string {
+	return c.dir
+}
+
+// Save writes a recording to the cassette directory.
+func (c *Cassette) Save(rec Recording) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recording: %w", err)
+	}
+
+	// Write to temp file first, then rename for atomicity
+	path := c.path(rec.Key)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to save recording: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads the recording for the given key.
+// Returns ErrRecordingNotFound if the key has not been recorded.
+func (c *Cassette) Load(key string) (*Recording, error) {
+	data, err := os.ReadFile(c.path(key))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrRecordingNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+
+	var rec Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("failed to parse recording %s: %w", key, err)
+	}
+
+	return &rec, nil
+}
+
+func (c *Cassette) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// RequestKey returns a stable hash of the request used to look up recordings.
+// Tools are sorted by name because registry order is not guaranteed.
+// If ignoreSystem is true, system messages are excluded from the key, since
+// the system prompt contains the current date and time. The response format
+// and the sampling parameters (including the seed and deterministic mode) are
+// keyed, so structured and plain requests and requests with different
+// sampling get their own recordings.
+func RequestKey(req ChatRequest, ignoreSystem bool) string {
+	keyed := ChatRequest{
+		Model:            req.Model,
+		Temperature:      req.Temperature,
+		MaxTokens:        req.MaxTokens,
+		TopP:             req.TopP,
+		FrequencyPenalty: req.FrequencyPenalty,
+		PresencePenalty:  req.PresencePenalty,
+		Stop:             req.Stop,
+		Seed:             req.Seed,
+		Deterministic:    req.Deterministic,
+		ResponseFormat:   req.ResponseFormat,
+	}
+
+	for _, msg := range req.Messages {
+		if ignoreSystem && msg.Role == RoleSystem {
+			continue
+		}
+		// Cache hints do not change the conversation
+		msg.Cache = false
+		keyed.Messages = append(keyed.Messages, msg)
+	}
+
+	if len(req.Tools) > 0 {
+		keyed.Tools = append([]ToolDefinition(nil), req.Tools...)
+		sort.Slice(keyed.Tools, func(i, j int) bool {
+			return keyed.Tools[i].Name < keyed.Tools[j].Name
+		})
+	}
+
+	// json.Marshal sorts map keys, so tool parameters are encoded deterministically
+	data, _ := json.Marshal(keyed)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// record forwards the request to the upstream provider and saves the response.
+func (m *MockProvider) record(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	if m.upstream == nil {
+		return nil, fmt.Errorf("mock provider: upstream provider is required in record mode")
+	}
+	if m.cassette == nil {
+		return nil, fmt.Errorf("mock provider: cassette directory is required in record mode")
+	}
+
+	m.recordMu.Lock()
+	defer m.recordMu.Unlock()
+
+	resp, err := m.upstream.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return m.save(req, resp)
+}
+
+// save records the response to the request.
+func (m *MockProvider) save(req ChatRequest, resp *ChatResponse) (*ChatResponse, error) {
+	rec := Recording{
+		Key:      RequestKey(req, m.ignoreSystem),
+		Request:  req,
+		Response: *resp,
+	}
+	if err := m.cassette.Save(rec); err != nil {
+		return nil, fmt.Errorf("mock provider: %w", err)
+	}
+	return resp, nil
+}
+
+// replay returns the recorded response for the request.
+func (m *MockProvider) replay(req ChatRequest) (*ChatResponse, error) {
+	if m.cassette == nil {
+		return nil, fmt.Errorf("mock provider: cassette directory is required in replay mode")
+	}
+
+	rec, err := m.cassette.Load(RequestKey(req, m.ignoreSystem))
+	if err != nil {
+		return nil, fmt.Errorf("mock provider: %w", err)
+	}
+
+	resp := rec.Response
+	return &resp, nil
+}
+
+// cassetteProvider is a record or replay MockProvider in front of a real
+// provider. Model listing and capabilities come from the real provider.
+type cassetteProvider struct {
+	*MockProvider
+	provider Provider
+}
+
+// cassetteStreamingProvider is a cassetteProvider of a streaming provider.
+type cassetteStreamingProvider struct {
+	cassetteProvider
+	streamer StreamingProvider
+}
+
+// NewCassetteProvider records the responses of provider to cassetteDir
+// (MockModeRecord), or replays the responses recorded there instead of
+// calling it (MockModeReplay). Unlike a bare MockProvider, the result
+// streams if provider does and lists the models of provider. System
+// messages are left out of request keys when ignoreSystem is true.
+func NewCassetteProvider(mode MockMode, provider Provider, cassetteDir string, ignoreSystem bool) Provider {
+	cfg := MockConfig{
+		Mode:               mode,
+		Upstream:           provider,
+		CassetteDir:        cassetteDir,
+		ToolCalling:        provider.SupportsToolCalling(),
+		IgnoreSystemPrompt: ignoreSystem,
+	}
+	cassette := cassetteProvider{MockProvider: NewMockProvider(cfg), provider: provider}
+	if streamer, ok := provider.(StreamingProvider); ok {
+		return &cassetteStreamingProvider{cassetteProvider: cassette, streamer: streamer}
+	}
+	return &cassette
+}
+
+// SupportsLogprobs reports whether the real provider returns logprobs;
+// recordings keep them.
+func (p *cassetteProvider) SupportsLogprobs() bool {
+	return SupportsLogprobs(p.provider)
+}
+
+// ListModels lists the models of the real provider.
+func (p *cassetteProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	return ListModels(ctx, p.provider)
+}
+
+// ChatStream streams the response of the real provider and records it, or
+// replays the recorded response as a single chunk.
+func (p *cassetteStreamingProvider) ChatStream(ctx context.Context, req ChatRequest, onDelta StreamFunc) (*ChatResponse, error) {
+	if p.mode == MockModeReplay {
+		resp, err := p.replay(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Content != "" && onDelta != nil {
+			onDelta(resp.Content)
+		}
+		return resp, nil
+	}
+
+	p.recordMu.Lock()
+	defer p.recordMu.Unlock()
+
+	resp, err := p.streamer.ChatStream(ctx, req, onDelta)
+	if err != nil {
+		return nil, err
+	}
+	return p.save(req, resp)
+}
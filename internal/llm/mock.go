@@ -3,17 +3,26 @@ package llm
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 )
 
 // MockProvider is a mock implementation of Provider interface for testing
-// and graceful degradation scenarios.
+// and graceful degradation scenarios. It is safe for concurrent use.
 type MockProvider struct {
-	responses     []string // Pre-defined responses (rotates through them)
-	responseIndex int      // Current index in responses
-	mode          MockMode // Mode of operation (echo, fixed, fixtures)
-	delay         int      // Simulated delay in milliseconds (for testing latency)
-	errorAfter    int      // Number of successful calls before returning errors
-	callCount     int      // Number of Chat() calls made
+	mu            sync.Mutex // Guards responses, responseIndex, errorAfter and callCount
+	responses     []string   // Pre-defined responses (rotates through them)
+	responseIndex int        // Current index in responses
+	mode          MockMode   // Mode of operation (echo, fixed, fixtures)
+	delay         int        // Simulated delay in milliseconds (for testing latency)
+	errorAfter    int        // Number of successful calls before returning errors
+	callCount     int        // Number of Chat() calls made
+
+	upstream     Provider   // Real provider used in record mode
+	cassette     *Cassette  // Recorded responses for record/replay modes
+	toolCalling  bool       // Reported tool calling support in replay mode
+	ignoreSystem bool       // Exclude system messages from request keys
+	recordMu     sync.Mutex // Serializes upstream calls and cassette writes
 }
 
 // MockMode defines the operation mode of the mock provider.
@@ -31,6 +40,13 @@ const (
 
 	// MockModeError always returns an error
 	MockModeError
+
+	// MockModeRecord forwards requests to the upstream provider and
+	// saves the responses to the cassette directory
+	MockModeRecord
+
+	// MockModeReplay serves responses previously saved in record mode
+	MockModeReplay
 )
 
 // MockConfig holds the configuration for the mock provider.
@@ -39,18 +55,31 @@ type MockConfig struct {
 	Responses  []string // Pre-defined responses (for Fixed/Fixtures modes)
 	Delay      int      // Simulated delay in milliseconds
 	ErrorAfter int      // Number of successful calls before returning errors
+
+	// Record/replay settings
+	Upstream           Provider // Real provider (required for Record mode)
+	CassetteDir        string   // Directory with recorded responses (Record/Replay modes)
+	ToolCalling        bool     // Tool calling support reported in Replay mode
+	IgnoreSystemPrompt bool     // Exclude system messages from request keys (they contain current time)
 }
 
 // NewMockProvider creates a new mock LLM provider.
 func NewMockProvider(cfg MockConfig) *MockProvider {
-	return &MockProvider{
+	p := &MockProvider{
 		mode:          cfg.Mode,
 		responses:     cfg.Responses,
 		responseIndex: 0,
 		delay:         cfg.Delay,
 		errorAfter:    cfg.ErrorAfter,
 		callCount:     0,
+		upstream:      cfg.Upstream,
+		toolCalling:   cfg.ToolCalling,
+		ignoreSystem:  cfg.IgnoreSystemPrompt,
+	}
+	if cfg.CassetteDir != "" {
+		p.cassette = NewCassette(cfg.CassetteDir)
 	}
+	return p
 }
 
 // NewEchoProvider creates a mock provider that echoes user messages.
@@ -76,6 +105,26 @@ func NewFixturesProvider(responses []string) *MockProvider {
 	})
 }
 
+// NewRecordProvider creates a mock provider that forwards requests to upstream
+// and records every response into cassetteDir.
+func NewRecordProvider(upstream Provider, cassetteDir string) *MockProvider {
+	return NewMockProvider(MockConfig{
+		Mode:        MockModeRecord,
+		Upstream:    upstream,
+		CassetteDir: cassetteDir,
+	})
+}
+
+// NewReplayProvider creates a mock provider that serves responses recorded
+// in cassetteDir. Requests without a recording return an error.
+func NewReplayProvider(cassetteDir string, toolCalling bool) *MockProvider {
+	return NewMockProvider(MockConfig{
+		Mode:        MockModeReplay,
+		CassetteDir: cassetteDir,
+		ToolCalling: toolCalling,
+	})
+}
+
 // NewErrorProvider creates a mock provider that always returns errors.
 func NewErrorProvider() *MockProvider {
 	return NewMockProvider(MockConfig{
@@ -85,11 +134,14 @@ func NewErrorProvider() *MockProvider {
 
 // Chat implements the Provider interface.
 func (m *MockProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	m.mu.Lock()
 	m.callCount++
+	callCount, errorAfter := m.callCount, m.errorAfter
+	m.mu.Unlock()
 
 	// Check if we should return an error
-	if m.errorAfter > 0 && m.callCount > m.errorAfter {
-		return nil, fmt.Errorf("mock provider error after %d calls", m.errorAfter)
+	if errorAfter > 0 && callCount > errorAfter {
+		return nil, fmt.Errorf("mock provider error after %d calls", errorAfter)
 	}
 
 	// Handle error mode
@@ -97,6 +149,23 @@ func (m *MockProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse
 		return nil, fmt.Errorf("mock provider error")
 	}
 
+	// Handle record/replay modes
+	switch m.mode {
+	case MockModeRecord:
+		return m.record(ctx, req)
+	case MockModeReplay:
+		return m.replay(req)
+	}
+
+	// Simulate the latency of a real provider
+	if m.delay > 0 {
+		select {
+		case <-time.After(time.Duration(m.delay) * time.Millisecond):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
 	// Get user message (last message if available)
 	var userMessage string
 	if len(req.Messages) > 0 {
@@ -108,6 +177,7 @@ func (m *MockProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse
 
 	// Determine response based on mode
 	var response string
+	m.mu.Lock()
 	switch m.mode {
 	case MockModeEcho:
 		if userMessage != "" {
@@ -131,6 +201,7 @@ func (m *MockProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse
 	default:
 		response = "Unknown mock mode"
 	}
+	m.mu.Unlock()
 
 	// Build response
 	return &ChatResponse{
@@ -146,35 +217,53 @@ func (m *MockProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse
 }
 
 // SupportsToolCalling implements the Provider interface.
-// Mock provider does not support tool calling.
+// Mock provider does not support tool calling, except in record mode
+// (mirrors upstream) and replay mode (as configured).
 func (m *MockProvider) SupportsToolCalling() bool {
-	return false
+	switch m.mode {
+	case MockModeRecord:
+		return m.upstream != nil && m.upstream.SupportsToolCalling()
+	case MockModeReplay:
+		return m.toolCalling
+	default:
+		return false
+	}
 }
 
 // GetCallCount returns the number of Chat() calls made to this provider.
 // Useful for testing.
 func (m *MockProvider) GetCallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return m.callCount
 }
 
 // ResetCallCount resets the call counter.
 // Useful for testing.
 func (m *MockProvider) ResetCallCount() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.callCount = 0
 }
 
 // SetErrorAfter configures the provider to return errors after N calls.
 func (m *MockProvider) SetErrorAfter(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.errorAfter = n
 }
 
 // GetResponses returns the current list of responses.
 func (m *MockProvider) GetResponses() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return m.responses
 }
 
 // SetResponses sets the list of responses.
 func (m *MockProvider) SetResponses(responses []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.responses = responses
 	m.responseIndex = 0
 }
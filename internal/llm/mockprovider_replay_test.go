@@ -0,0 +1,225 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMockProvider_RecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	upstream := NewFixturesProvider([]string{"first", "second"})
+	recorder := NewRecordProvider(upstream, dir)
+
+	req1 := ChatRequest{Model: "m", Messages: []Message{{Role: RoleUser, Content: "one"}}}
+	req2 := ChatRequest{Model: "m", Messages: []Message{{Role: RoleUser, Content: "two"}}}
+
+	for _, req := range []ChatRequest{req1, req2} {
+		if _, err := recorder.Chat(ctx, req); err != nil {
+			t.Fatalf("record Chat() error = %v", err)
+		}
+	}
+
+	replayer := NewReplayProvider(dir, false)
+
+	// Replay in reverse order: responses are keyed by request, not by position
+	resp, err := replayer.Chat(ctx, req2)
+	if err != nil {
+		t.Fatalf("replay Chat() error = %v", err)
+	}
+	if resp.Content != "second" {
+		t.Errorf("replay Chat() content = %q, want %q", resp.Content, "second")
+	}
+
+	resp, err = replayer.Chat(ctx, req1)
+	if err != nil {
+		t.Fatalf("replay Chat() error = %v", err)
+	}
+	if resp.Content != "first" {
+		t.Errorf("replay Chat() content = %q, want %q", resp.Content, "first")
+	}
+
+	if upstream.GetCallCount() != 2 {
+		t.Errorf("upstream call count = %d, want 2", upstream.GetCallCount())
+	}
+}
+
+func TestMockProvider_Replay_NotFound(t *testing.T) {
+	p := NewReplayProvider(t.TempDir(), false)
+
+	_, err := p.Chat(context.Background(), ChatRequest{
+		Messages: []Message{{Role: RoleUser, Content: "unknown"}},
+	})
+	if !errors.Is(err, ErrRecordingNotFound) {
+		t.Errorf("Chat() error = %v, want ErrRecordingNotFound", err)
+	}
+}
+
+func TestMockProvider_Record_RequiresUpstream(t *testing.T) {
+	p := NewRecordProvider(nil, t.TempDir())
+
+	if _, err := p.Chat(context.Background(), ChatRequest{}); err == nil {
+		t.Error("Chat() expected error without upstream provider")
+	}
+}
+
+func TestMockProvider_SupportsToolCalling_RecordReplay(t *testing.T) {
+	if NewRecordProvider(NewEchoProvider(), t.TempDir()).SupportsToolCalling() {
+		t.Error("record mode should mirror upstream tool calling support")
+	}
+	if !NewReplayProvider(t.TempDir(), true).SupportsToolCalling() {
+		t.Error("replay mode should report configured tool calling support")
+	}
+}
+
+func TestRequestKey(t *testing.T) {
+	toolA := ToolDefinition{Name: "a", Parameters: map[string]any{"type": "object"}}
+	toolB := ToolDefinition{Name: "b", Parameters: map[string]any{"type": "object"}}
+
+	base := ChatRequest{
+		Model:    "m",
+		Messages: []Message{{Role: RoleSystem, Content: "time 10:00"}, {Role: RoleUser, Content: "hi"}},
+		Tools:    []ToolDefinition{toolA, toolB},
+	}
+
+	reordered := base
+	reordered.Tools = []ToolDefinition{toolB, toolA}
+	if RequestKey(base, false) != RequestKey(reordered, false) {
+		t.Error("RequestKey() should not depend on tool order")
+	}
+
+	otherSystem := base
+	otherSystem.Messages = []Message{{Role: RoleSystem, Content: "time 11:00"}, {Role: RoleUser, Content: "hi"}}
+	if RequestKey(base, false) == RequestKey(otherSystem, false) {
+		t.Error("RequestKey() should include system messages by default")
+	}
+	if RequestKey(base, true) != RequestKey(otherSystem, true) {
+		t.Error("RequestKey() should ignore system messages when requested")
+	}
+
+	cached := base
+	cached.Messages = []Message{{Role: RoleSystem, Content: "time 10:00", Cache: true}, {Role: RoleUser, Content: "hi"}}
+	cached.CacheTools = true
+	if RequestKey(base, false) != RequestKey(cached, false) {
+		t.Error("RequestKey() should ignore cache hints")
+	}
+
+	otherUser := base
+	otherUser.Messages = []Message{{Role: RoleUser, Content: "bye"}}
+	if RequestKey(base, true) == RequestKey(otherUser, true) {
+		t.Error("RequestKey() should differ for different user messages")
+	}
+}
+
+func TestRequestKey_Sampling(t *testing.T) {
+	base := ChatRequest{Model: "m", Temperature: 0.7, Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+	keys := map[string]bool{RequestKey(base, false): true}
+	for _, s := range []Sampling{
+		{TopP: 0.9},
+		{FrequencyPenalty: 0.5},
+		{PresencePenalty: 0.5},
+		{Stop: []string{"END"}},
+		{Seed: 42},
+	} {
+		req := base
+		s.Temperature = base.Temperature
+		s.Apply(&req)
+		keys[RequestKey(req, false)] = true
+	}
+	deterministic := base
+	deterministic.Deterministic = true
+	keys[RequestKey(deterministic, false)] = true
+	if len(keys) != 7 {
+		t.Errorf("RequestKey() should differ for each sampling parameter, got %d distinct keys", len(keys))
+	}
+}
+
+func TestRequestKey_ResponseFormat(t *testing.T) {
+	plain := ChatRequest{Model: "m", Messages: []Message{{Role: RoleUser, Content: "list the steps"}}}
+	object := plain
+	object.ResponseFormat = &ResponseFormat{Type: ResponseFormatJSONObject}
+	schema := plain
+	schema.ResponseFormat = &ResponseFormat{Type: ResponseFormatJSONSchema, Name: "steps", Schema: map[string]any{"type": "array"}}
+
+	keys := map[string]bool{
+		RequestKey(plain, false):  true,
+		RequestKey(object, false): true,
+		RequestKey(schema, false): true,
+	}
+	if len(keys) != 3 {
+		t.Error("RequestKey() should differ for plain and structured requests")
+	}
+
+	// Replaying a structured request does not return the plain recording
+	dir := t.TempDir()
+	recorder := NewRecordProvider(NewFixedProvider("plain answer"), dir)
+	if _, err := recorder.Chat(context.Background(), plain); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	replayer := NewReplayProvider(dir, false)
+	if _, err := replayer.Chat(context.Background(), schema); !errors.Is(err, ErrRecordingNotFound) {
+		t.Errorf("Expected ErrRecordingNotFound for the structured request, got %v", err)
+	}
+	if resp, err := replayer.Chat(context.Background(), plain); err != nil || resp.Content != "plain answer" {
+		t.Errorf("Expected the plain recording, got %+v, %v", resp, err)
+	}
+}
+
+// listingStreamingStub is a streaming provider that also lists models.
+type listingStreamingStub struct {
+	streamingStub
+}
+
+func (p *listingStreamingStub) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	return []ModelInfo{{ID: "glm-4.7"}}, nil
+}
+
+func TestNewCassetteProvider(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	req := ChatRequest{Model: "m", Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+
+	plain := NewCassetteProvider(MockModeRecord, NewFixedProvider("fixed"), dir, true)
+	if _, ok := plain.(StreamingProvider); ok {
+		t.Error("cassette provider streams although the wrapped one doesn't")
+	}
+
+	upstream := &listingStreamingStub{}
+	recorder := NewCassetteProvider(MockModeRecord, upstream, dir, true)
+	streamer, ok := recorder.(StreamingProvider)
+	if !ok {
+		t.Fatal("cassette provider doesn't stream although the wrapped one does")
+	}
+	var deltas string
+	if _, err := streamer.ChatStream(ctx, req, func(d string) { deltas += d }); err != nil {
+		t.Fatalf("record ChatStream() error = %v", err)
+	}
+	if !upstream.streamed || deltas != "stream" {
+		t.Errorf("record ChatStream() streamed = %v, deltas = %q", upstream.streamed, deltas)
+	}
+
+	models, err := ListModels(ctx, recorder)
+	if err != nil || len(models) != 1 || models[0].ID != "glm-4.7" {
+		t.Errorf("ListModels() = %v, %v, want the models of the wrapped provider", models, err)
+	}
+
+	// Replay streams the recorded response without calling the provider
+	replayUpstream := &listingStreamingStub{}
+	replayer := NewCassetteProvider(MockModeReplay, replayUpstream, dir, true).(StreamingProvider)
+	deltas = ""
+	resp, err := replayer.ChatStream(ctx, req, func(d string) { deltas += d })
+	if err != nil {
+		t.Fatalf("replay ChatStream() error = %v", err)
+	}
+	if resp.Content != "stream" || deltas != "stream" {
+		t.Errorf("replay ChatStream() content = %q, deltas = %q, want %q", resp.Content, deltas, "stream")
+	}
+	if replayUpstream.streamed {
+		t.Error("replay ChatStream() called the wrapped provider")
+	}
+	if resp, err := replayer.Chat(ctx, req); err != nil || resp.Content != "stream" {
+		t.Errorf("replay Chat() = %v, %v, want the streamed recording", resp, err)
+	}
+}
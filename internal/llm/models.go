@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+// ErrModelListingUnsupported is returned by ListModels for providers that
+// cannot list their models.
+var ErrModelListingUnsupported = errors.New("provider does not support model listing")
+
+// Capabilities are the features of a model.
+type Capabilities struct {
+	Vision     bool `json:"vision"`      // Accepts images in the input
+	Tools      bool `json:"tools"`       // Supports native tool calling
+	MaxContext int  `json:"max_context"` // Context window in tokens, 0 if unknown
+}
+
+// ModelInfo describes a model offered by a provider.
+type ModelInfo struct {
+	ID           string       `json:"id"`
+	Capabilities Capabilities `json:"capabilities"`
+
+	// Known is true when the capabilities come from the built-in list;
+	// capabilities of unknown models are zero.
+	Known bool `json:"known"`
+}
+
+// ModelLister is an optional interface of providers that can list the
+// models available to the configured API key.
+type ModelLister interface {
+	Provider
+
+	// ListModels returns the available models with their capabilities.
+	ListModels(ctx context.Context) ([]ModelInfo, error)
+}
+
+// ListModels returns the models of provider, or ErrModelListingUnsupported
+// if the provider cannot list them.
+func ListModels(ctx context.Context, provider Provider) ([]ModelInfo, error) {
+	lister, ok := provider.(ModelLister)
+	if !ok {
+		return nil, ErrModelListingUnsupported
+	}
+	return lister.ListModels(ctx)
+}
+
+// knownModels are the capabilities of the models of each provider (by
+// agent.provider name), used for validation before any request is made.
+var knownModels = map[string]map[string]Capabilities{
+	"zai": {
+		"glm-4.7":       {Tools: true, MaxContext: 200000},
+		"glm-4.7-flash": {Tools: true, MaxContext: 200000},
+		"glm-4.6":       {Tools: true, MaxContext: 200000},
+		"glm-4.6v":      {Vision: true, Tools: true, MaxContext: 128000},
+		"glm-4.5":       {Tools: true, MaxContext: 128000},
+		"glm-4.5-air":   {Tools: true, MaxContext: 128000},
+		"glm-4.5-flash": {Tools: true, MaxContext: 128000},
+		"glm-4.5v":      {Vision: true, Tools: true, MaxContext: 64000},
+	},
+	"openai": {
+		"gpt-4o":      {Vision: true, Tools: true, MaxContext: 128000},
+		"gpt-4o-mini": {Vision: true, Tools: true, MaxContext: 128000},
+		"gpt-4.1":     {Vision: true, Tools: true, MaxContext: 1047576},
+		"o3-mini":     {Tools: true, MaxContext: 200000},
+	},
+}
+
+// LookupModel returns the built-in capabilities of a model of provider.
+// Returns false for models not in the built-in list.
+func LookupModel(provider, model string) (Capabilities, bool) {
+	caps, ok := knownModels[provider][model]
+	return caps, ok
+}
+
+// KnownModels returns the built-in list of models of provider, sorted by ID.
+func KnownModels(provider string) []ModelInfo {
+	models := make([]ModelInfo, 0, len(knownModels[provider]))
+	for id, caps := range knownModels[provider] {
+		models = append(models, ModelInfo{ID: id, Capabilities: caps, Known: true})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models
+}
+
+// describeModels returns the models with the given IDs, with built-in
+// capabilities where known, sorted by ID.
+func describeModels(provider string, ids []string) []ModelInfo {
+	models := make([]ModelInfo, 0, len(ids))
+	for _, id := range ids {
+		caps, known := LookupModel(provider, id)
+		models = append(models, ModelInfo{ID: id, Capabilities: caps, Known: known})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models
+}
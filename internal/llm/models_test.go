@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+func TestZAIProvider_ListModels(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v4/models" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.Write([]byte(`{"object":"list","data":[{"id":"glm-4.6"},{"id":"glm-5-preview"},{"id":"glm-4.5v"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewZAIProvider(ZAIConfig{APIKey: "test-key"}, log)
+	p.apiURL = server.URL + "/v4/chat/completions"
+
+	// Wrapped providers list the models of the provider
+	provider := NewScheduledProvider(p, NewScheduler(SchedulerConfig{}))
+	models, err := ListModels(context.Background(), provider)
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if len(models) != 3 {
+		t.Fatalf("Expected 3 models, got %+v", models)
+	}
+	if models[0].ID != "glm-4.5v" || !models[0].Known || !models[0].Capabilities.Vision {
+		t.Errorf("Expected glm-4.5v with vision, got %+v", models[0])
+	}
+	if models[2].ID != "glm-5-preview" || models[2].Known || models[2].Capabilities.MaxContext != 0 {
+		t.Errorf("Expected unknown glm-5-preview, got %+v", models[2])
+	}
+}
+
+func TestListModels_Unsupported(t *testing.T) {
+	if _, err := ListModels(context.Background(), NewMockProvider(MockConfig{})); !errors.Is(err, ErrModelListingUnsupported) {
+		t.Errorf("Expected ErrModelListingUnsupported, got %v", err)
+	}
+}
+
+func TestLookupModel(t *testing.T) {
+	caps, ok := LookupModel("zai", "glm-4.7")
+	if !ok || !caps.Tools || caps.MaxContext == 0 {
+		t.Errorf("Expected glm-4.7 with tools and a context window, got %+v (%v)", caps, ok)
+	}
+	if _, ok := LookupModel("zai", "gpt-4o"); ok {
+		t.Error("Expected models of other providers to be unknown")
+	}
+	if models := KnownModels("zai"); len(models) == 0 || models[0].ID > models[len(models)-1].ID {
+		t.Errorf("Expected the sorted built-in list, got %+v", models)
+	}
+}
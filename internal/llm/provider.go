@@ -17,6 +17,35 @@ type Provider interface {
 	SupportsToolCalling() bool
 }
 
+// StreamFunc receives chunks of the response text as they are generated.
+type StreamFunc func(delta string)
+
+// StreamingProvider is an optional interface of providers that can stream responses.
+type StreamingProvider interface {
+	Provider
+
+	// ChatStream sends a chat completion request like Chat, calling onDelta with
+	// chunks of the response content as they arrive. Returns the complete response,
+	// including tool calls, once generation finishes.
+	ChatStream(ctx context.Context, req ChatRequest, onDelta StreamFunc) (*ChatResponse, error)
+}
+
+// LogprobsProvider is an optional interface of providers that return the log
+// probabilities of generated tokens (ChatRequest.Logprobs).
+type LogprobsProvider interface {
+	Provider
+
+	// SupportsLogprobs reports whether responses carry ChatResponse.Logprobs
+	// when they are requested.
+	SupportsLogprobs() bool
+}
+
+// SupportsLogprobs reports whether provider returns token log probabilities.
+func SupportsLogprobs(provider Provider) bool {
+	p, ok := provider.(LogprobsProvider)
+	return ok && p.SupportsLogprobs()
+}
+
 // Role represents the role of a message sender in the conversation.
 type Role string
 
@@ -34,6 +63,12 @@ type Message struct {
 
 	// ToolCallID is set for RoleTool messages to identify which tool call this result is for
 	ToolCallID string `json:"tool_call_id,omitempty"`
+
+	// ToolCalls is set for RoleAssistant messages that requested tool calls
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+
+	// Cache marks the end of a stable prompt prefix that the provider may cache
+	Cache bool `json:"cache,omitempty"`
 }
 
 // FinishReason indicates why the model stopped generating tokens.
@@ -60,6 +95,9 @@ type Usage struct {
 	PromptTokens     int `json:"prompt_tokens"`     // Number of tokens in the prompt
 	CompletionTokens int `json:"completion_tokens"` // Number of tokens in the completion
 	TotalTokens      int `json:"total_tokens"`      // Total number of tokens used
+
+	// CachedTokens is the part of PromptTokens served from the provider's prompt cache
+	CachedTokens int `json:"cached_tokens,omitempty"`
 }
 
 // ChatRequest represents a request to send to the LLM provider for chat completion.
@@ -69,8 +107,45 @@ type ChatRequest struct {
 	Temperature float64   `json:"temperature"` // Sampling temperature (0.0-2.0)
 	MaxTokens   int       `json:"max_tokens"`  // Maximum tokens to generate
 
+	// Optional sampling parameters, zero when unset. Providers send only
+	// the ones their API supports.
+	TopP             float64  `json:"top_p,omitempty"`             // Nucleus sampling (0.0-1.0)
+	FrequencyPenalty float64  `json:"frequency_penalty,omitempty"` // Penalty for frequent tokens (-2.0-2.0)
+	PresencePenalty  float64  `json:"presence_penalty,omitempty"`  // Penalty for present tokens (-2.0-2.0)
+	Stop             []string `json:"stop,omitempty"`              // Sequences that end the generation
+	Seed             int      `json:"seed,omitempty"`              // Seed for reproducible sampling
+
+	// Deterministic asks for greedy decoding (temperature 0) so that the same
+	// request gets the same answer
+	Deterministic bool `json:"deterministic,omitempty"`
+
+	// Logprobs asks for the log probabilities of the generated tokens, for
+	// diagnostics. Providers without support (see SupportsLogprobs) ignore it.
+	Logprobs bool `json:"logprobs,omitempty"`
+
 	// Tools is a list of tools/functions the model can call. Only used if supported.
 	Tools []ToolDefinition `json:"tools,omitempty"`
+
+	// CacheTools marks the tool definitions as a stable prefix that the provider may cache
+	CacheTools bool `json:"cache_tools,omitempty"`
+
+	// ResponseFormat asks for machine-readable output. Providers without
+	// support ignore it or fall back to plain JSON mode.
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// Response format types.
+const (
+	ResponseFormatJSONObject = "json_object" // Any valid JSON object
+	ResponseFormatJSONSchema = "json_schema" // JSON matching Schema
+)
+
+// ResponseFormat describes the structured output expected from the model.
+type ResponseFormat struct {
+	Type   string         `json:"type"`             // ResponseFormatJSONObject or ResponseFormatJSONSchema
+	Name   string         `json:"name,omitempty"`   // Name of the schema (json_schema)
+	Schema map[string]any `json:"schema,omitempty"` // JSON Schema of the output (json_schema)
+	Strict bool           `json:"strict,omitempty"` // Ask the provider to enforce the schema exactly
 }
 
 // ToolDefinition defines a tool that the model can call.
@@ -91,4 +166,14 @@ type ChatResponse struct {
 
 	// Model is the actual model used for the completion (may differ from request)
 	Model string `json:"model"`
+
+	// Logprobs are the generated tokens with their log probabilities, when
+	// requested and supported by the provider
+	Logprobs []TokenLogprob `json:"logprobs,omitempty"`
+}
+
+// TokenLogprob is a generated token with its log probability.
+type TokenLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
 }
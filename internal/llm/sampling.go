@@ -0,0 +1,134 @@
+package llm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Sampling holds the sampling parameters of a request. Zero values are
+// unset: they keep the value of the layer below or the provider default.
+type Sampling struct {
+	Temperature      float64  `json:"temperature,omitempty"`
+	TopP             float64  `json:"top_p,omitempty"`
+	FrequencyPenalty float64  `json:"frequency_penalty,omitempty"`
+	PresencePenalty  float64  `json:"presence_penalty,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+	Seed             int      `json:"seed,omitempty"`
+}
+
+// SamplingKeys are the parameter names accepted by ParseSampling.
+var SamplingKeys = []string{"temperature", "top_p", "frequency_penalty", "presence_penalty", "stop", "seed"}
+
+// IsZero reports whether no parameter is set.
+func (s Sampling) IsZero() bool {
+	return s.Temperature == 0 && s.TopP == 0 && s.FrequencyPenalty == 0 &&
+		s.PresencePenalty == 0 && len(s.Stop) == 0 && s.Seed == 0
+}
+
+// Merge returns s with the parameters set in override replacing its own.
+func (s Sampling) Merge(override Sampling) Sampling {
+	if override.Temperature != 0 {
+		s.Temperature = override.Temperature
+	}
+	if override.TopP != 0 {
+		s.TopP = override.TopP
+	}
+	if override.FrequencyPenalty != 0 {
+		s.FrequencyPenalty = override.FrequencyPenalty
+	}
+	if override.PresencePenalty != 0 {
+		s.PresencePenalty = override.PresencePenalty
+	}
+	if len(override.Stop) > 0 {
+		s.Stop = override.Stop
+	}
+	if override.Seed != 0 {
+		s.Seed = override.Seed
+	}
+	return s
+}
+
+// Apply sets the parameters of s on req.
+func (s Sampling) Apply(req *ChatRequest) {
+	req.Temperature = s.Temperature
+	req.TopP = s.TopP
+	req.FrequencyPenalty = s.FrequencyPenalty
+	req.PresencePenalty = s.PresencePenalty
+	req.Stop = s.Stop
+	req.Seed = s.Seed
+}
+
+// Validate checks the parameters are in the ranges providers accept.
+func (s Sampling) Validate() error {
+	switch {
+	case s.Temperature < 0 || s.Temperature > 2:
+		return fmt.Errorf("temperature must be between 0 and 2")
+	case s.TopP < 0 || s.TopP > 1:
+		return fmt.Errorf("top_p must be between 0 and 1")
+	case s.FrequencyPenalty < -2 || s.FrequencyPenalty > 2:
+		return fmt.Errorf("frequency_penalty must be between -2 and 2")
+	case s.PresencePenalty < -2 || s.PresencePenalty > 2:
+		return fmt.Errorf("presence_penalty must be between -2 and 2")
+	case len(s.Stop) > 4:
+		return fmt.Errorf("at most 4 stop sequences are allowed")
+	case s.Seed < 0:
+		return fmt.Errorf("seed must be non-negative")
+	}
+	return nil
+}
+
+// ParseSampling parses "key=value" pairs such as "temperature=0.2 top_p=0.9".
+// Stop sequences are separated by "|": "stop=END|###".
+func ParseSampling(args string) (Sampling, error) {
+	var s Sampling
+	for _, pair := range strings.Fields(args) {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || value == "" {
+			return Sampling{}, fmt.Errorf("expected key=value, got %q", pair)
+		}
+
+		var err error
+		switch strings.ToLower(key) {
+		case "temperature":
+			s.Temperature, err = strconv.ParseFloat(value, 64)
+		case "top_p":
+			s.TopP, err = strconv.ParseFloat(value, 64)
+		case "frequency_penalty":
+			s.FrequencyPenalty, err = strconv.ParseFloat(value, 64)
+		case "presence_penalty":
+			s.PresencePenalty, err = strconv.ParseFloat(value, 64)
+		case "stop":
+			s.Stop = strings.Split(value, "|")
+		case "seed":
+			s.Seed, err = strconv.Atoi(value)
+		default:
+			return Sampling{}, fmt.Errorf("unknown parameter %q (expected one of %s)", key, strings.Join(SamplingKeys, ", "))
+		}
+		if err != nil {
+			return Sampling{}, fmt.Errorf("invalid %s value %q", key, value)
+		}
+	}
+	return s, s.Validate()
+}
+
+// String formats the parameters that are set as "key=value" pairs.
+func (s Sampling) String() string {
+	var parts []string
+	add := func(key string, v float64) {
+		if v != 0 {
+			parts = append(parts, key+"="+strconv.FormatFloat(v, 'g', -1, 64))
+		}
+	}
+	add("temperature", s.Temperature)
+	add("top_p", s.TopP)
+	add("frequency_penalty", s.FrequencyPenalty)
+	add("presence_penalty", s.PresencePenalty)
+	if len(s.Stop) > 0 {
+		parts = append(parts, "stop="+strings.Join(s.Stop, "|"))
+	}
+	if s.Seed != 0 {
+		parts = append(parts, "seed="+strconv.Itoa(s.Seed))
+	}
+	return strings.Join(parts, " ")
+}
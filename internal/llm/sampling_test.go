@@ -0,0 +1,36 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSampling_Merge(t *testing.T) {
+	base := Sampling{Temperature: 0.7, TopP: 0.95, Seed: 1}
+	got := base.Merge(Sampling{Temperature: 0.2, Stop: []string{"END"}})
+	if got.Temperature != 0.2 || got.TopP != 0.95 || got.Seed != 1 || len(got.Stop) != 1 {
+		t.Errorf("Merge() = %+v", got)
+	}
+	if base.Merge(Sampling{}).Temperature != 0.7 {
+		t.Error("Expected unset overrides to keep the base")
+	}
+}
+
+func TestParseSampling(t *testing.T) {
+	s, err := ParseSampling("temperature=0.2 TOP_P=0.9 stop=END|### seed=42")
+	if err != nil {
+		t.Fatalf("ParseSampling() error = %v", err)
+	}
+	if s.Temperature != 0.2 || s.TopP != 0.9 || s.Seed != 42 || strings.Join(s.Stop, ",") != "END,###" {
+		t.Errorf("ParseSampling() = %+v", s)
+	}
+	if got := s.String(); got != "temperature=0.2 top_p=0.9 stop=END|### seed=42" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for _, args := range []string{"temperature", "top_k=5", "top_p=abc", "top_p=1.5", "presence_penalty=-3", "stop=a|b|c|d|e"} {
+		if _, err := ParseSampling(args); err == nil {
+			t.Errorf("ParseSampling(%q) expected an error", args)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SchedulerConfig limits the requests sent with one API key.
+type SchedulerConfig struct {
+	MaxConcurrent     int // Requests in flight at once (0 = unlimited)
+	RequestsPerMinute int // Requests started in any 60 seconds (0 = unlimited)
+}
+
+// Scheduler queues requests to a provider so they stay within the limits of
+// its API key. Requests over the limits wait in per-session queues that are
+// served round-robin, so a session sending a burst of requests doesn't hold
+// up the others. It is safe for concurrent use.
+type Scheduler struct {
+	cfg SchedulerConfig
+
+	mu     sync.Mutex
+	active int                  // Requests in flight
+	starts []time.Time          // Start times of the requests of the last minute
+	queues map[string][]*waiter // Waiting requests by session
+	order  []string             // Sessions with waiting requests, in serving order
+	timer  *time.Timer          // Wakes the queue when the rate window frees up
+	wakeAt time.Time            // When the timer fires
+}
+
+// waiter is a request waiting for its turn.
+type waiter struct {
+	session string
+	ready   chan struct{}
+	granted bool
+}
+
+// NewScheduler creates a scheduler with the given limits.
+func NewScheduler(cfg SchedulerConfig) *Scheduler {
+	return &Scheduler{
+		cfg:    cfg,
+		queues: make(map[string][]*waiter),
+	}
+}
+
+var (
+	schedulersMu sync.Mutex
+	schedulers   = make(map[string]*Scheduler)
+)
+
+// SchedulerForKey returns the scheduler of an API key, creating it with cfg
+// on first use, so all providers using the key share its limits.
+func SchedulerForKey(apiKey string, cfg SchedulerConfig) *Scheduler {
+	schedulersMu.Lock()
+	defer schedulersMu.Unlock()
+
+	if s, ok := schedulers[apiKey]; ok {
+		return s
+	}
+	s := NewScheduler(cfg)
+	schedulers[apiKey] = s
+	return s
+}
+
+// Acquire waits until a request of the session may be sent and returns the
+// function that must be called when it completes. Requests without a session
+// share one queue.
+func (s *Scheduler) Acquire(ctx context.Context, session string) (release func(), err error) {
+	w := &waiter{session: session, ready: make(chan struct{})}
+
+	s.mu.Lock()
+	if _, queued := s.queues[session]; !queued {
+		s.order = append(s.order, session)
+	}
+	s.queues[session] = append(s.queues[session], w)
+	s.dispatchLocked()
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return s.releaseFunc(), nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	if w.granted {
+		// Granted while the context was cancelled
+		s.mu.Unlock()
+		s.releaseFunc()()
+		return nil, ctx.Err()
+	}
+	s.removeLocked(w)
+	s.mu.Unlock()
+	return nil, ctx.Err()
+}
+
+// Waiting returns the number of queued requests.
+func (s *Scheduler) Waiting() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for _, q := range s.queues {
+		n += len(q)
+	}
+	return n
+}
+
+// releaseFunc returns a function that frees the slot of a request once.
+func (s *Scheduler) releaseFunc() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.active--
+			s.dispatchLocked()
+		})
+	}
+}
+
+// dispatchLocked lets waiting requests go while the limits allow, taking one
+// request from each session in turn. Caller must hold s.mu.
+func (s *Scheduler) dispatchLocked() {
+	for len(s.order) > 0 {
+		if s.cfg.MaxConcurrent > 0 && s.active >= s.cfg.MaxConcurrent {
+			return
+		}
+
+		now := time.Now()
+		if s.cfg.RequestsPerMinute > 0 {
+			expired := 0
+			for expired < len(s.starts) && now.Sub(s.starts[expired]) >= time.Minute {
+				expired++
+			}
+			s.starts = s.starts[expired:]
+			if len(s.starts) >= s.cfg.RequestsPerMinute {
+				s.wakeLocked(s.starts[0].Add(time.Minute).Sub(now))
+				return
+			}
+			s.starts = append(s.starts, now)
+		}
+
+		session := s.order[0]
+		s.order = s.order[1:]
+		queue := s.queues[session]
+		w := queue[0]
+		if len(queue) > 1 {
+			s.queues[session] = queue[1:]
+			s.order = append(s.order, session)
+		} else {
+			delete(s.queues, session)
+		}
+
+		s.active++
+		w.granted = true
+		close(w.ready)
+	}
+}
+
+// wakeLocked dispatches again after d, when the oldest request leaves the
+// rate window. Caller must hold s.mu.
+func (s *Scheduler) wakeLocked(d time.Duration) {
+	at := time.Now().Add(d)
+	if s.timer != nil {
+		if !at.Before(s.wakeAt) {
+			return
+		}
+		s.timer.Stop()
+	}
+	s.wakeAt = at
+	s.timer = time.AfterFunc(d, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.timer = nil
+		s.dispatchLocked()
+	})
+}
+
+// removeLocked drops a waiting request from its queue. Caller must hold s.mu.
+func (s *Scheduler) removeLocked(w *waiter) {
+	queue := s.queues[w.session]
+	for i, q := range queue {
+		if q == w {
+			queue = append(queue[:i:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(queue) > 0 {
+		s.queues[w.session] = queue
+		return
+	}
+	delete(s.queues, w.session)
+	for i, session := range s.order {
+		if session == w.session {
+			s.order = append(s.order[:i:i], s.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// sessionKey is the context key of the session a request is made for.
+type sessionKey struct{}
+
+// WithSession returns a context whose requests are scheduled as requests of
+// the session.
+func WithSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, sessionID)
+}
+
+// SessionFromContext returns the session set by WithSession, or "".
+func SessionFromContext(ctx context.Context) string {
+	session, _ := ctx.Value(sessionKey{}).(string)
+	return session
+}
+
+// scheduledProvider sends the requests of a provider through a scheduler.
+type scheduledProvider struct {
+	provider  Provider
+	scheduler *Scheduler
+}
+
+// scheduledStreamingProvider is a scheduledProvider of a streaming provider.
+type scheduledStreamingProvider struct {
+	scheduledProvider
+	streamer StreamingProvider
+}
+
+// NewScheduledProvider wraps a provider so its requests wait for their turn
+// in the scheduler. The result streams if the provider does.
+func NewScheduledProvider(provider Provider, scheduler *Scheduler) Provider {
+	scheduled := scheduledProvider{provider: provider, scheduler: scheduler}
+	if streamer, ok := provider.(StreamingProvider); ok {
+		return &scheduledStreamingProvider{scheduledProvider: scheduled, streamer: streamer}
+	}
+	return &scheduled
+}
+
+// Chat waits for a slot and sends the request.
+func (p *scheduledProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	release, err := p.scheduler.Acquire(ctx, SessionFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return p.provider.Chat(ctx, req)
+}
+
+// SupportsToolCalling reports whether the wrapped provider supports tool calling.
+func (p *scheduledProvider) SupportsToolCalling() bool {
+	return p.provider.SupportsToolCalling()
+}
+
+// SupportsLogprobs reports whether the wrapped provider returns logprobs.
+func (p *scheduledProvider) SupportsLogprobs() bool {
+	return SupportsLogprobs(p.provider)
+}
+
+// ListModels lists the models of the wrapped provider without waiting for
+// a slot.
+func (p *scheduledProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	return ListModels(ctx, p.provider)
+}
+
+// ChatStream waits for a slot and streams the request.
+func (p *scheduledStreamingProvider) ChatStream(ctx context.Context, req ChatRequest, onDelta StreamFunc) (*ChatResponse, error) {
+	release, err := p.scheduler.Acquire(ctx, SessionFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return p.streamer.ChatStream(ctx, req, onDelta)
+}
@@ -0,0 +1,255 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// waitFor polls cond until it holds or the test times out.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestScheduler_MaxConcurrent(t *testing.T) {
+	s := NewScheduler(SchedulerConfig{MaxConcurrent: 2})
+
+	release1, err := s.Acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if _, err := s.Acquire(context.Background(), "b"); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := s.Acquire(ctx, "c"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire() over the limit error = %v, want deadline exceeded", err)
+	}
+	if n := s.Waiting(); n != 0 {
+		t.Errorf("Waiting() after cancel = %d, want 0", n)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		release, err := s.Acquire(context.Background(), "c")
+		if err != nil {
+			t.Errorf("Acquire() error = %v", err)
+			return
+		}
+		release()
+	}()
+	waitFor(t, func() bool { return s.Waiting() == 1 })
+
+	release1()
+	release1() // Releasing twice frees one slot
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("queued request was not served after release")
+	}
+}
+
+func TestScheduler_FairAcrossSessions(t *testing.T) {
+	s := NewScheduler(SchedulerConfig{MaxConcurrent: 1})
+	hold, err := s.Acquire(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	var served []string
+	var wg sync.WaitGroup
+	enqueue := func(session, name string) {
+		wg.Add(1)
+		want := s.Waiting() + 1
+		go func() {
+			defer wg.Done()
+			release, err := s.Acquire(context.Background(), session)
+			if err != nil {
+				t.Errorf("Acquire() error = %v", err)
+				return
+			}
+			mu.Lock()
+			served = append(served, name)
+			mu.Unlock()
+			release()
+		}()
+		waitFor(t, func() bool { return s.Waiting() == want })
+	}
+
+	// A burst of one session doesn't delay the request of another
+	enqueue("a", "a1")
+	enqueue("a", "a2")
+	enqueue("a", "a3")
+	enqueue("b", "b1")
+
+	hold()
+	wg.Wait()
+
+	want := []string{"a1", "b1", "a2", "a3"}
+	if len(served) != len(want) {
+		t.Fatalf("served = %v, want %v", served, want)
+	}
+	for i := range want {
+		if served[i] != want[i] {
+			t.Fatalf("served = %v, want %v", served, want)
+		}
+	}
+}
+
+func TestScheduler_RequestsPerMinute(t *testing.T) {
+	s := NewScheduler(SchedulerConfig{RequestsPerMinute: 2})
+
+	for range 2 {
+		release, err := s.Acquire(context.Background(), "a")
+		if err != nil {
+			t.Fatalf("Acquire() error = %v", err)
+		}
+		release()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := s.Acquire(ctx, "a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire() over the rate error = %v, want deadline exceeded", err)
+	}
+
+	// The request goes once the oldest start leaves the one minute window
+	s.mu.Lock()
+	s.starts[0] = time.Now().Add(-time.Minute + 50*time.Millisecond)
+	s.mu.Unlock()
+
+	start := time.Now()
+	release, err := s.Acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	release()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Acquire() waited %v, want about 50ms", elapsed)
+	}
+}
+
+func TestSchedulerForKey(t *testing.T) {
+	a := SchedulerForKey("test-key-shared", SchedulerConfig{MaxConcurrent: 1})
+	b := SchedulerForKey("test-key-shared", SchedulerConfig{MaxConcurrent: 5})
+	if a != b {
+		t.Error("SchedulerForKey() returned different schedulers for one key")
+	}
+	if c := SchedulerForKey("test-key-other", SchedulerConfig{}); c == a {
+		t.Error("SchedulerForKey() shared a scheduler between keys")
+	}
+}
+
+// streamingStub is a streaming provider that records its calls.
+type streamingStub struct {
+	streamed bool
+}
+
+func (p *streamingStub) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	return &ChatResponse{Content: "chat"}, nil
+}
+
+func (p *streamingStub) SupportsToolCalling() bool { return true }
+
+func (p *streamingStub) ChatStream(ctx context.Context, req ChatRequest, onDelta StreamFunc) (*ChatResponse, error) {
+	p.streamed = true
+	onDelta("stream")
+	return &ChatResponse{Content: "stream"}, nil
+}
+
+func TestNewScheduledProvider(t *testing.T) {
+	s := NewScheduler(SchedulerConfig{MaxConcurrent: 1})
+
+	plain := NewScheduledProvider(NewMockProvider(MockConfig{Mode: MockModeEcho}), s)
+	if _, ok := plain.(StreamingProvider); ok {
+		t.Error("scheduled provider streams although the wrapped one doesn't")
+	}
+	resp, err := plain.Chat(WithSession(context.Background(), "telegram:1"), ChatRequest{
+		Messages: []Message{{Role: RoleUser, Content: "hi"}},
+	})
+	if err != nil || resp == nil {
+		t.Fatalf("Chat() = %v, %v", resp, err)
+	}
+
+	stub := &streamingStub{}
+	scheduled := NewScheduledProvider(stub, s)
+	streamer, ok := scheduled.(StreamingProvider)
+	if !ok {
+		t.Fatal("scheduled provider doesn't stream although the wrapped one does")
+	}
+	var deltas string
+	if _, err := streamer.ChatStream(context.Background(), ChatRequest{}, func(d string) { deltas += d }); err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if !stub.streamed || deltas != "stream" {
+		t.Errorf("ChatStream() streamed = %v, deltas = %q", stub.streamed, deltas)
+	}
+	if !scheduled.SupportsToolCalling() {
+		t.Error("SupportsToolCalling() not passed through")
+	}
+
+	// Slots are released after each request
+	if n := s.Waiting(); n != 0 {
+		t.Errorf("Waiting() = %d, want 0", n)
+	}
+	release, err := s.Acquire(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Acquire() after requests error = %v", err)
+	}
+	release()
+}
+
+func TestSessionFromContext(t *testing.T) {
+	if got := SessionFromContext(context.Background()); got != "" {
+		t.Errorf("SessionFromContext() = %q, want empty", got)
+	}
+	if got := SessionFromContext(WithSession(context.Background(), "telegram:1")); got != "telegram:1" {
+		t.Errorf("SessionFromContext() = %q, want telegram:1", got)
+	}
+}
+
+// logprobsStub is a provider that returns logprobs.
+type logprobsStub struct {
+	streamingStub
+}
+
+func (p *logprobsStub) SupportsLogprobs() bool { return true }
+
+func TestSupportsLogprobs(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	if SupportsLogprobs(NewZAIProvider(ZAIConfig{APIKey: "test-key"}, log)) {
+		t.Error("SupportsLogprobs() = true for Z.ai, which does not return logprobs")
+	}
+	if SupportsLogprobs(NewMockProvider(MockConfig{Mode: MockModeEcho})) {
+		t.Error("SupportsLogprobs() = true for a provider without the capability")
+	}
+
+	// Wrappers report the capability of the wrapped provider
+	s := NewScheduler(SchedulerConfig{MaxConcurrent: 1})
+	for name, provider := range map[string]Provider{
+		"scheduled": NewScheduledProvider(&logprobsStub{}, s),
+		"cassette":  NewCassetteProvider(MockModeReplay, &logprobsStub{}, t.TempDir(), true),
+	} {
+		if !SupportsLogprobs(provider) {
+			t.Errorf("SupportsLogprobs() of the %s provider not passed through", name)
+		}
+	}
+}
@@ -37,6 +37,9 @@ type ZAIConfig struct {
 	APIKey         string `json:"api_key"`         // API key for authentication
 	Model          string `json:"model"`           // Default model to use (optional, defaults to glm-4.7)
 	TimeoutSeconds int    `json:"timeout_seconds"` // Timeout for HTTP requests in seconds
+
+	// Transport overrides the HTTP transport (proxy, CA bundle, DNS), optional
+	Transport http.RoundTripper `json:"-"`
 }
 
 // ZAIProvider implements the Provider interface for Z.ai Coding API.
@@ -49,12 +52,17 @@ type ZAIProvider struct {
 
 // zaiRequest represents the request format for Z.ai API.
 type zaiRequest struct {
-	Messages    []zaiMessage `json:"messages"`              // Conversation messages
-	Model       string       `json:"model"`                 // Model identifier
-	Temperature float64      `json:"temperature,omitempty"` // Sampling temperature
-	MaxTokens   int          `json:"max_tokens,omitempty"`  // Maximum tokens to generate
-	Tools       []zaiTool    `json:"tools,omitempty"`       // Available tools/functions
-	ToolChoice  string       `json:"tool_choice,omitempty"` // Tool selection mode (auto)
+	Messages       []zaiMessage       `json:"messages"`                  // Conversation messages
+	Model          string             `json:"model"`                     // Model identifier
+	Temperature    float64            `json:"temperature,omitempty"`     // Sampling temperature
+	MaxTokens      int                `json:"max_tokens,omitempty"`      // Maximum tokens to generate
+	TopP           float64            `json:"top_p,omitempty"`           // Nucleus sampling
+	Stop           []string           `json:"stop,omitempty"`            // Stop sequences
+	DoSample       *bool              `json:"do_sample,omitempty"`       // false disables sampling (greedy decoding)
+	Tools          []zaiTool          `json:"tools,omitempty"`           // Available tools/functions
+	ToolChoice     string             `json:"tool_choice,omitempty"`     // Tool selection mode (auto)
+	Stream         bool               `json:"stream,omitempty"`          // Stream the response as server-sent events
+	ResponseFormat *zaiResponseFormat `json:"response_format,omitempty"` // JSON output mode
 }
 
 // zaiMessage represents a message in Z.ai API format.
@@ -66,6 +74,12 @@ type zaiMessage struct {
 	ToolCalls        []zaiToolCall `json:"tool_calls,omitempty"`        // Tool calls requested
 }
 
+// zaiResponseFormat represents the response format of a request. Z.ai supports
+// JSON mode only: schemas are enforced by the prompt and validated by the caller.
+type zaiResponseFormat struct {
+	Type string `json:"type"` // "json_object"
+}
+
 // zaiTool represents a tool definition in Z.ai API format.
 type zaiTool struct {
 	Type     string         `json:"type"`     // Always "function"
@@ -106,6 +120,21 @@ type zaiUsage struct {
 	PromptTokens     int `json:"prompt_tokens"`     // Tokens in prompt
 	CompletionTokens int `json:"completion_tokens"` // Tokens in completion
 	TotalTokens      int `json:"total_tokens"`      // Total tokens used
+
+	// PromptTokensDetails reports prompt tokens served from the context cache
+	PromptTokensDetails struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+}
+
+// usage maps the token usage to the internal format.
+func (u zaiUsage) usage() Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+		CachedTokens:     u.PromptTokensDetails.CachedTokens,
+	}
 }
 
 // zaiAPIError represents an error response from the API.
@@ -130,7 +159,8 @@ func NewZAIProvider(cfg ZAIConfig, log *logger.Logger) *ZAIProvider {
 
 	return &ZAIProvider{
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: cfg.Transport,
 		},
 		config: cfg,
 		apiURL: ZAIEndpoint,
@@ -224,6 +254,8 @@ func (p *ZAIProvider) doRequest(ctx stdcontext.Context, reqBody []byte) (*zaiRes
 }
 
 // mapChatRequest maps internal ChatRequest to Z.ai API format.
+// Z.ai caches repeated prompt prefixes automatically, so cache hints
+// (Message.Cache, ChatRequest.CacheTools) need no explicit markers.
 func (p *ZAIProvider) mapChatRequest(req ChatRequest) zaiRequest {
 	messages := make([]zaiMessage, len(req.Messages))
 	for i, msg := range req.Messages {
@@ -232,6 +264,12 @@ func (p *ZAIProvider) mapChatRequest(req ChatRequest) zaiRequest {
 			Content:    msg.Content,
 			ToolCallID: msg.ToolCallID,
 		}
+		for _, tc := range msg.ToolCalls {
+			zaiTC := zaiToolCall{ID: tc.ID, Type: "function"}
+			zaiTC.Function.Name = tc.Name
+			zaiTC.Function.Arguments = tc.Arguments
+			messages[i].ToolCalls = append(messages[i].ToolCalls, zaiTC)
+		}
 	}
 
 	zaiReq := zaiRequest{
@@ -239,6 +277,20 @@ func (p *ZAIProvider) mapChatRequest(req ChatRequest) zaiRequest {
 		Model:       req.Model,
 		Temperature: req.Temperature,
 		MaxTokens:   req.MaxTokens,
+		TopP:        req.TopP,
+		Stop:        req.Stop,
+	}
+
+	// Z.ai ignores temperature and top_p without sampling
+	if req.Deterministic {
+		doSample := false
+		zaiReq.DoSample = &doSample
+	}
+
+	// Z.ai has no frequency/presence penalties or seed
+	if req.FrequencyPenalty != 0 || req.PresencePenalty != 0 || req.Seed != 0 {
+		p.logger.Debug("Z.ai does not support penalties or seed, ignoring them",
+			logger.Field{Key: "model", Value: req.Model})
 	}
 
 	// Map tools if provided
@@ -257,6 +309,11 @@ func (p *ZAIProvider) mapChatRequest(req ChatRequest) zaiRequest {
 		zaiReq.ToolChoice = "auto"
 	}
 
+	// Both JSON object and JSON schema requests use JSON mode
+	if req.ResponseFormat != nil {
+		zaiReq.ResponseFormat = &zaiResponseFormat{Type: ResponseFormatJSONObject}
+	}
+
 	return zaiReq
 }
 
@@ -269,12 +326,8 @@ func (p *ZAIProvider) mapChatResponse(zaiResp *zaiResponse) *ChatResponse {
 			Content:      "",
 			FinishReason: FinishReasonError,
 			ToolCalls:    []ToolCall{},
-			Usage: Usage{
-				PromptTokens:     zaiResp.Usage.PromptTokens,
-				CompletionTokens: zaiResp.Usage.CompletionTokens,
-				TotalTokens:      zaiResp.Usage.TotalTokens,
-			},
-			Model: zaiResp.Model,
+			Usage:        zaiResp.Usage.usage(),
+			Model:        zaiResp.Model,
 		}
 	}
 
@@ -308,12 +361,8 @@ func (p *ZAIProvider) mapChatResponse(zaiResp *zaiResponse) *ChatResponse {
 		Content:      content,
 		FinishReason: FinishReason(choice.FinishReason),
 		ToolCalls:    toolCalls,
-		Usage: Usage{
-			PromptTokens:     zaiResp.Usage.PromptTokens,
-			CompletionTokens: zaiResp.Usage.CompletionTokens,
-			TotalTokens:      zaiResp.Usage.TotalTokens,
-		},
-		Model: zaiResp.Model,
+		Usage:        zaiResp.Usage.usage(),
+		Model:        zaiResp.Model,
 	}
 }
 
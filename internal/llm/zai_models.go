@@ -0,0 +1,57 @@
+package llm
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// zaiModelsResponse is the OpenAI-compatible response of the models endpoint.
+type zaiModelsResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// ListModels returns the models available to the API key. Capabilities
+// come from the built-in list; models missing from it have none set.
+func (p *ZAIProvider) ListModels(ctx stdcontext.Context) ([]ModelInfo, error) {
+	url := strings.TrimSuffix(p.apiURL, "/chat/completions") + "/models"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.config.APIKey))
+
+	httpResp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return nil, &zaiHTTPError{
+			StatusCode: httpResp.StatusCode,
+			Body:       truncateResponse(respBody, 200),
+		}
+	}
+
+	var resp zaiModelsResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	ids := make([]string, 0, len(resp.Data))
+	for _, model := range resp.Data {
+		if model.ID != "" {
+			ids = append(ids, model.ID)
+		}
+	}
+	return describeModels("zai", ids), nil
+}
@@ -0,0 +1,154 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// zaiStreamChunk represents a server-sent event of a streamed Z.ai response.
+type zaiStreamChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta        zaiMessage `json:"delta"`                   // Content and tool call fragments
+		FinishReason string     `json:"finish_reason,omitempty"` // Set on the last chunk
+	} `json:"choices"`
+	Usage *zaiUsage    `json:"usage,omitempty"` // Sent with the last chunk
+	Error *zaiAPIError `json:"error,omitempty"`
+}
+
+// ChatStream sends a streaming chat completion request to Z.ai API.
+// The HTTP client timeout does not apply to streams, which can run for
+// minutes; the request is bounded by ctx instead.
+func (p *ZAIProvider) ChatStream(ctx stdcontext.Context, req ChatRequest, onDelta StreamFunc) (*ChatResponse, error) {
+	p.logger.DebugCtx(ctx, "Sending streaming chat request to Z.ai API",
+		logger.Field{Key: "model", Value: req.Model},
+		logger.Field{Key: "messages_count", Value: len(req.Messages)})
+
+	reqBody := p.mapChatRequest(req)
+	reqBody.Stream = true
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		p.logger.ErrorCtx(ctx, "Failed to marshal request", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.apiURL, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.config.APIKey))
+
+	client := *p.client
+	client.Timeout = 0
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		p.logger.ErrorCtx(ctx, "Failed to execute streaming request to Z.ai API", err)
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(httpResp.Body)
+		p.logger.ErrorCtx(ctx, "Z.ai API returned error status", nil,
+			logger.Field{Key: "status_code", Value: httpResp.StatusCode},
+			logger.Field{Key: "response_preview", Value: truncateResponse(respBody, 200)})
+		return nil, &zaiHTTPError{StatusCode: httpResp.StatusCode, Body: string(respBody)}
+	}
+
+	zaiResp, err := p.readStream(httpResp.Body, onDelta)
+	if err != nil {
+		p.logger.ErrorCtx(ctx, "Failed to read Z.ai response stream", err)
+		return nil, err
+	}
+	return p.mapChatResponse(zaiResp), nil
+}
+
+// readStream assembles a response from server-sent events, passing content
+// chunks to onDelta. Tool call fragments are merged by their index.
+func (p *ZAIProvider) readStream(body io.Reader, onDelta StreamFunc) (*zaiResponse, error) {
+	var (
+		resp      zaiResponse
+		content   strings.Builder
+		reasoning strings.Builder
+		toolCalls []zaiToolCall
+		finish    string
+	)
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk zaiStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return nil, fmt.Errorf("API error: %s (code: %s): %s",
+				chunk.Error.Type, chunk.Error.Code, chunk.Error.Message)
+		}
+		if chunk.Model != "" {
+			resp.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			resp.Usage = *chunk.Usage
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.FinishReason != "" {
+				finish = choice.FinishReason
+			}
+			delta := choice.Delta
+			if delta.Content != "" {
+				content.WriteString(delta.Content)
+				if onDelta != nil {
+					onDelta(delta.Content)
+				}
+			}
+			reasoning.WriteString(delta.ReasoningContent)
+
+			for _, tc := range delta.ToolCalls {
+				for len(toolCalls) <= tc.Index {
+					toolCalls = append(toolCalls, zaiToolCall{Type: "function", Index: len(toolCalls)})
+				}
+				call := &toolCalls[tc.Index]
+				if tc.ID != "" {
+					call.ID = tc.ID
+				}
+				call.Function.Name += tc.Function.Name
+				call.Function.Arguments += tc.Function.Arguments
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read response stream: %w", err)
+	}
+
+	resp.Choices = []zaiChoice{{
+		Message: zaiMessage{
+			Role:             string(RoleAssistant),
+			Content:          content.String(),
+			ReasoningContent: reasoning.String(),
+			ToolCalls:        toolCalls,
+		},
+		FinishReason: finish,
+	}}
+	return &resp, nil
+}
@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+func newStreamTestProvider(t *testing.T, events []string) *ZAIProvider {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req zaiRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Decode failed: %v", err)
+		}
+		if !req.Stream {
+			t.Error("Stream = false, want true")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, e := range events {
+			fmt.Fprintf(w, "data: %s\n\n", e)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	p := NewZAIProvider(ZAIConfig{APIKey: "test-key"}, log)
+	p.apiURL = server.URL
+	return p
+}
+
+func TestZAIProvider_ChatStream_Content(t *testing.T) {
+	p := newStreamTestProvider(t, []string{
+		`{"model":"glm-4.7","choices":[{"delta":{"role":"assistant","content":"Hel"}}]}`,
+		`{"choices":[{"delta":{"content":"lo"}}]}`,
+		`{"choices":[{"delta":{"content":"!"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`,
+		`[DONE]`,
+	})
+
+	var deltas []string
+	resp, err := p.ChatStream(context.Background(), ChatRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Hi"}},
+	}, func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	if err != nil {
+		t.Fatalf("ChatStream failed: %v", err)
+	}
+
+	if got := strings.Join(deltas, "|"); got != "Hel|lo|!" {
+		t.Errorf("deltas = %q, want %q", got, "Hel|lo|!")
+	}
+	if resp.Content != "Hello!" {
+		t.Errorf("Content = %q, want %q", resp.Content, "Hello!")
+	}
+	if resp.FinishReason != FinishReasonStop {
+		t.Errorf("FinishReason = %q, want %q", resp.FinishReason, FinishReasonStop)
+	}
+	if resp.Usage.TotalTokens != 5 {
+		t.Errorf("TotalTokens = %d, want 5", resp.Usage.TotalTokens)
+	}
+	if resp.Model != "glm-4.7" {
+		t.Errorf("Model = %q, want glm-4.7", resp.Model)
+	}
+}
+
+func TestZAIProvider_ChatStream_ToolCalls(t *testing.T) {
+	p := newStreamTestProvider(t, []string{
+		`{"choices":[{"delta":{"role":"assistant","tool_calls":[{"id":"call_1","index":0,"type":"function","function":{"name":"read_file","arguments":"{\"pa"}}]}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"th\":\"a.txt\"}"}}]}}]}`,
+		`{"choices":[{"delta":{},"finish_reason":"tool_calls"}]}`,
+		`[DONE]`,
+	})
+
+	called := false
+	resp, err := p.ChatStream(context.Background(), ChatRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Read a.txt"}},
+	}, func(string) { called = true })
+	if err != nil {
+		t.Fatalf("ChatStream failed: %v", err)
+	}
+
+	if called {
+		t.Error("onDelta called for a tool call response")
+	}
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("ToolCalls = %d, want 1", len(resp.ToolCalls))
+	}
+	tc := resp.ToolCalls[0]
+	if tc.ID != "call_1" || tc.Name != "read_file" || tc.Arguments != `{"path":"a.txt"}` {
+		t.Errorf("ToolCall = %+v", tc)
+	}
+	if resp.FinishReason != FinishReasonToolCalls {
+		t.Errorf("FinishReason = %q, want %q", resp.FinishReason, FinishReasonToolCalls)
+	}
+}
+
+func TestZAIProvider_ChatStream_Error(t *testing.T) {
+	p := newStreamTestProvider(t, []string{
+		`{"error":{"message":"overloaded","type":"server_error","code":"1305"}}`,
+	})
+
+	if _, err := p.ChatStream(context.Background(), ChatRequest{
+		Messages: []Message{{Role: RoleUser, Content: "Hi"}},
+	}, nil); err == nil {
+		t.Fatal("expected error for error chunk")
+	}
+}
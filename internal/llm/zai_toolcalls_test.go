@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+func TestMapChatRequest_AssistantToolCalls(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	p := NewZAIProvider(ZAIConfig{APIKey: "test"}, log)
+
+	zaiReq := p.mapChatRequest(ChatRequest{
+		Model: "glm-4.7",
+		Messages: []Message{
+			{Role: RoleUser, Content: "Read a.txt"},
+			{Role: RoleAssistant, ToolCalls: []ToolCall{{ID: "call_1", Name: "read_file", Arguments: `{"path":"a.txt"}`}}},
+			{Role: RoleTool, Content: "hello", ToolCallID: "call_1"},
+			{Role: RoleAssistant, Content: "It says hello"},
+		},
+	})
+
+	calls := zaiReq.Messages[1].ToolCalls
+	if len(calls) != 1 || calls[0].ID != "call_1" || calls[0].Type != "function" ||
+		calls[0].Function.Name != "read_file" || calls[0].Function.Arguments != `{"path":"a.txt"}` {
+		t.Fatalf("Assistant tool calls = %+v", calls)
+	}
+	if zaiReq.Messages[3].ToolCalls != nil {
+		t.Errorf("Expected no tool calls on a plain answer, got %+v", zaiReq.Messages[3].ToolCalls)
+	}
+
+	// Messages without tool calls keep the old wire format
+	data, err := json.Marshal(zaiReq.Messages[3])
+	if err != nil {
+		t.Fatalf("Failed to marshal message: %v", err)
+	}
+	if strings.Contains(string(data), "tool_calls") {
+		t.Errorf("Expected no tool_calls field, got %s", data)
+	}
+}
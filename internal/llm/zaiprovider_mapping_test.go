@@ -1,6 +1,8 @@
 package llm
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/aatumaykin/nexbot/internal/logger"
@@ -275,3 +277,108 @@ func TestMapChatResponse_UseReasoningContent(t *testing.T) {
 		t.Errorf("Content should use reasoning_content, got %q", resp.Content)
 	}
 }
+
+func TestMapChatResponse_CachedTokens(t *testing.T) {
+	log, err := logger.New(logger.Config{
+		Level:  "error",
+		Format: "text",
+		Output: "stdout",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	p := NewZAIProvider(ZAIConfig{APIKey: "test"}, log)
+
+	var zaiResp zaiResponse
+	body := `{"model":"glm-4.7","choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}],
+		"usage":{"prompt_tokens":1200,"completion_tokens":5,"total_tokens":1205,"prompt_tokens_details":{"cached_tokens":1024}}}`
+	if err := json.Unmarshal([]byte(body), &zaiResp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	resp := p.mapChatResponse(&zaiResp)
+
+	if resp.Usage.CachedTokens != 1024 || resp.Usage.PromptTokens != 1200 {
+		t.Errorf("Usage = %+v, want 1024 cached of 1200 prompt tokens", resp.Usage)
+	}
+}
+
+func TestMapChatRequest_ResponseFormat(t *testing.T) {
+	log, err := logger.New(logger.Config{
+		Level:  "error",
+		Format: "text",
+		Output: "stdout",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	p := NewZAIProvider(ZAIConfig{APIKey: "test"}, log)
+
+	if zaiReq := p.mapChatRequest(ChatRequest{}); zaiReq.ResponseFormat != nil {
+		t.Errorf("ResponseFormat = %+v, want nil", zaiReq.ResponseFormat)
+	}
+
+	zaiReq := p.mapChatRequest(ChatRequest{
+		Messages: []Message{{Role: RoleUser, Content: "List colors"}},
+		ResponseFormat: &ResponseFormat{
+			Type:   ResponseFormatJSONSchema,
+			Name:   "colors",
+			Schema: map[string]any{"type": "object"},
+		},
+	})
+	if zaiReq.ResponseFormat == nil || zaiReq.ResponseFormat.Type != "json_object" {
+		t.Errorf("ResponseFormat = %+v, want json_object", zaiReq.ResponseFormat)
+	}
+
+	data, err := json.Marshal(zaiReq)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !json.Valid(data) || !strings.Contains(string(data), `"response_format":{"type":"json_object"}`) {
+		t.Errorf("request JSON = %s", data)
+	}
+}
+
+func TestMapChatRequest_Sampling(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	p := NewZAIProvider(ZAIConfig{APIKey: "test"}, log)
+
+	var req ChatRequest
+	Sampling{Temperature: 0.3, TopP: 0.9, PresencePenalty: 0.5, Stop: []string{"END"}, Seed: 7}.Apply(&req)
+	data, err := json.Marshal(p.mapChatRequest(req))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	for _, want := range []string{`"temperature":0.3`, `"top_p":0.9`, `"stop":["END"]`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %s in request JSON %s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "penalty") || strings.Contains(string(data), "seed") {
+		t.Errorf("Expected unsupported parameters to be dropped, got %s", data)
+	}
+}
+
+func TestMapChatRequest_Deterministic(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	p := NewZAIProvider(ZAIConfig{APIKey: "test"}, log)
+
+	if zaiReq := p.mapChatRequest(ChatRequest{}); zaiReq.DoSample != nil {
+		t.Errorf("DoSample = %v, want unset", *zaiReq.DoSample)
+	}
+	data, err := json.Marshal(p.mapChatRequest(ChatRequest{Deterministic: true}))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"do_sample":false`) {
+		t.Errorf("Expected sampling to be disabled, got %s", data)
+	}
+}
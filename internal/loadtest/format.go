@@ -0,0 +1,48 @@
+package loadtest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Format renders a report as text.
+func Format(cfg Config, r *Report) string {
+	cfg.applyDefaults()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Load test: %d msg/s for %s, %d users, %d workers, LLM delay %s\n",
+		cfg.RPS, cfg.Duration, cfg.Users, cfg.Workers, cfg.LLMDelay)
+
+	fmt.Fprintf(&b, "\nMessages:\n")
+	fmt.Fprintf(&b, "  %-12s %d\n", "sent", r.Sent)
+	fmt.Fprintf(&b, "  %-12s %d\n", "answered", r.Answered)
+	fmt.Fprintf(&b, "  %-12s %d\n", "failed", r.Failed)
+	fmt.Fprintf(&b, "  %-12s %d (bus queue full)\n", "rejected", r.Rejected)
+	fmt.Fprintf(&b, "  %-12s %d (processor channel full)\n", "dropped", r.Dropped)
+	fmt.Fprintf(&b, "  %-12s %d (unanswered after %s)\n", "pending", r.Pending, cfg.DrainTimeout)
+
+	fmt.Fprintf(&b, "\nThroughput:  %.1f answers/s over %s\n", r.Throughput, time.Duration(r.ElapsedMs)*time.Millisecond)
+	fmt.Fprintf(&b, "Latency:     p50 %s, p90 %s, p99 %s, max %s\n",
+		formatMs(r.P50Ms, r.Answered), formatMs(r.P90Ms, r.Answered), formatMs(r.P99Ms, r.Answered), formatMs(r.MaxMs, r.Answered))
+	fmt.Fprintf(&b, "Queue:       max backlog %d/%d, saturated %.0f%% of the run\n", r.MaxBacklog, r.Capacity, r.SaturatedPct)
+
+	if r.Dropped > 0 || r.Rejected > 0 || r.Pending > 0 {
+		b.WriteString("\n⚠️  Messages were lost: the rate exceeds what the processor can answer\n")
+	}
+	return b.String()
+}
+
+// formatMs formats a duration in milliseconds; "-" when there is no data.
+func formatMs(ms int64, count int) string {
+	switch {
+	case count == 0:
+		return "-"
+	case ms >= 10000:
+		return fmt.Sprintf("%.0fs", float64(ms)/1000)
+	case ms >= 1000:
+		return fmt.Sprintf("%.1fs", float64(ms)/1000)
+	default:
+		return fmt.Sprintf("%dms", ms)
+	}
+}
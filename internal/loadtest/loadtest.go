@@ -0,0 +1,357 @@
+// Package loadtest drives the message bus and the agent loop with synthetic
+// inbound messages answered by the mock LLM provider. It measures throughput,
+// saturation of the inbound queue and answer latency without a real channel
+// or LLM provider, to find the limits of a deployment before adding users.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/loop"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	// Channel is the channel type of synthetic messages
+	Channel bus.ChannelType = "loadtest"
+
+	DefaultRPS            = 10
+	DefaultDuration       = 30 * time.Second
+	DefaultUsers          = 10
+	DefaultWorkers        = 1 // The bot processes messages one by one
+	DefaultLLMDelay       = 200 * time.Millisecond
+	DefaultQueueCapacity  = 1000 // Matches the [message_bus] capacity default
+	DefaultSubscriberSize = 10   // Matches the [message_bus] subscriber_channel_size default
+	DefaultDrainTimeout   = 30 * time.Second
+
+	// sampleInterval is how often the inbound backlog is measured
+	sampleInterval = 10 * time.Millisecond
+)
+
+// Config configures a load test run. Zero values use the defaults.
+type Config struct {
+	RPS            int           // Synthetic messages per second
+	Duration       time.Duration // How long messages are sent
+	Users          int           // Distinct users (sessions) the messages are spread over
+	Workers        int           // Messages processed concurrently
+	LLMDelay       time.Duration // Simulated latency of every LLM call
+	QueueCapacity  int           // Capacity of the bus queues
+	SubscriberSize int           // Buffer of the inbound subscriber channel
+	DrainTimeout   time.Duration // How long to wait for answers after the last message
+	Logger         *logger.Logger
+}
+
+// Report is the result of a load test run.
+type Report struct {
+	ElapsedMs    int64   `json:"elapsed_ms"` // From the first message to the last answer
+	Sent         int     `json:"sent"`       // Messages accepted by the bus
+	Rejected     int     `json:"rejected"`   // Messages refused because the bus queue was full
+	Dropped      int     `json:"dropped"`    // Messages dropped because the processor's channel was full
+	Answered     int     `json:"answered"`
+	Failed       int     `json:"failed"`     // Messages the agent failed to answer
+	Pending      int     `json:"pending"`    // Messages still unanswered after the drain timeout
+	Throughput   float64 `json:"throughput"` // Answers per second
+	P50Ms        int64   `json:"p50_ms"`
+	P90Ms        int64   `json:"p90_ms"`
+	P99Ms        int64   `json:"p99_ms"`
+	MaxMs        int64   `json:"max_ms"`
+	MaxBacklog   int     `json:"max_backlog"`   // Most messages waiting for a worker at once
+	Capacity     int     `json:"capacity"`      // Size of the processor's channel
+	SaturatedPct float64 `json:"saturated_pct"` // Share of the run the processor's channel was full
+}
+
+func (c *Config) applyDefaults() {
+	if c.RPS <= 0 {
+		c.RPS = DefaultRPS
+	}
+	if c.Duration <= 0 {
+		c.Duration = DefaultDuration
+	}
+	if c.Users <= 0 {
+		c.Users = DefaultUsers
+	}
+	if c.Workers <= 0 {
+		c.Workers = DefaultWorkers
+	}
+	if c.LLMDelay < 0 {
+		c.LLMDelay = 0
+	}
+	if c.QueueCapacity <= 0 {
+		c.QueueCapacity = DefaultQueueCapacity
+	}
+	if c.SubscriberSize <= 0 {
+		c.SubscriberSize = DefaultSubscriberSize
+	}
+	if c.DrainTimeout <= 0 {
+		c.DrainTimeout = DefaultDrainTimeout
+	}
+}
+
+// Run sends synthetic messages through a fresh bus and agent loop in a
+// temporary workspace and reports the results. Cancelling ctx stops sending
+// and waiting for answers.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	cfg.applyDefaults()
+	if cfg.Logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
+
+	workspace, err := os.MkdirTemp("", "nexbot-loadtest-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	defer os.RemoveAll(workspace)
+
+	agent, err := loop.NewLoop(loop.Config{
+		Workspace:  workspace,
+		SessionDir: filepath.Join(workspace, "sessions"),
+		SecretsDir: filepath.Join(workspace, "secrets"),
+		LLMProvider: llm.NewMockProvider(llm.MockConfig{
+			Mode:  llm.MockModeEcho,
+			Delay: int(cfg.LLMDelay.Milliseconds()),
+		}),
+		Logger: cfg.Logger,
+		Model:  "mock",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent loop: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	msgBus := bus.New(cfg.QueueCapacity, cfg.SubscriberSize, cfg.Logger)
+	if err := msgBus.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start message bus: %w", err)
+	}
+	defer func() { _ = msgBus.Stop() }()
+
+	rec := newRecorder()
+	inboundCh := msgBus.SubscribeInbound(ctx)
+	outboundCh := msgBus.SubscribeOutbound(ctx)
+
+	var workers sync.WaitGroup
+	for range cfg.Workers {
+		workers.Go(func() { process(ctx, msgBus, agent, inboundCh, rec) })
+	}
+	go func() {
+		for msg := range outboundCh {
+			rec.answer(msg.CorrelationID)
+		}
+	}()
+
+	// Measure the backlog of the processor's channel until the run ends
+	sampling := make(chan struct{})
+	var sampler sync.WaitGroup
+	var samples, saturated, maxBacklog int
+	sampler.Go(func() {
+		ticker := time.NewTicker(sampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				backlog := len(inboundCh)
+				samples++
+				if backlog >= cap(inboundCh) {
+					saturated++
+				}
+				maxBacklog = max(maxBacklog, backlog)
+			case <-sampling:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	started := time.Now()
+	send(ctx, msgBus, cfg, rec)
+
+	// Wait for the answers of every message the bus didn't drop
+	drain := time.NewTimer(cfg.DrainTimeout)
+	defer drain.Stop()
+	poll := time.NewTicker(sampleInterval)
+	defer poll.Stop()
+wait:
+	for !rec.settled(dropped(msgBus)) {
+		select {
+		case <-poll.C:
+		case <-drain.C:
+			break wait
+		case <-ctx.Done():
+			break wait
+		}
+	}
+	elapsed := time.Since(started)
+
+	close(sampling)
+	sampler.Wait()
+	cancel()
+	workers.Wait()
+
+	report := rec.report(elapsed, dropped(msgBus))
+	report.MaxBacklog = maxBacklog
+	report.Capacity = cap(inboundCh)
+	if samples > 0 {
+		report.SaturatedPct = float64(saturated) * 100 / float64(samples)
+	}
+	return report, nil
+}
+
+// send publishes messages at the configured rate for the configured duration,
+// spreading them over the users in turn.
+func send(ctx context.Context, msgBus *bus.MessageBus, cfg Config, rec *recorder) {
+	ticker := time.NewTicker(time.Second / time.Duration(cfg.RPS))
+	defer ticker.Stop()
+	deadline := time.After(cfg.Duration)
+
+	for i := 0; ; i++ {
+		user := fmt.Sprintf("user%d", i%cfg.Users)
+		msg := bus.NewInboundMessage(Channel, user, string(Channel)+":"+user, fmt.Sprintf("message %d", i), nil)
+		msg.CorrelationID = uuid.NewString()
+
+		// Record before publishing: the answer may arrive before Publish returns
+		rec.sent(msg.CorrelationID)
+		if err := msgBus.PublishInbound(*msg); err != nil {
+			rec.reject(msg.CorrelationID)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// process answers inbound messages through the agent loop like the bot's
+// message processor and publishes the answers.
+func process(ctx context.Context, msgBus *bus.MessageBus, agent *loop.Loop, inboundCh <-chan bus.InboundMessage, rec *recorder) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-inboundCh:
+			if !ok {
+				return
+			}
+			response, err := agent.Process(ctx, msg.SessionID, msg.Content)
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					rec.fail(msg.CorrelationID)
+				}
+				continue
+			}
+			out := bus.NewOutboundMessage(msg.ChannelType, msg.UserID, msg.SessionID, response, msg.CorrelationID, bus.FormatTypePlain, nil)
+			if err := msgBus.PublishOutbound(*out); err != nil {
+				rec.fail(msg.CorrelationID)
+			}
+		}
+	}
+}
+
+// dropped returns the number of inbound messages the bus dropped.
+func dropped(msgBus *bus.MessageBus) int {
+	metrics := msgBus.GetMetrics()
+	return int(metrics.InboundMessagesDropped)
+}
+
+// recorder tracks the messages in flight and the answer latencies.
+// It is safe for concurrent use.
+type recorder struct {
+	mu        sync.Mutex
+	inflight  map[string]time.Time // Send time by correlation ID
+	total     int
+	rejected  int
+	failed    int
+	latencies []time.Duration
+}
+
+func newRecorder() *recorder {
+	return &recorder{inflight: make(map[string]time.Time)}
+}
+
+func (r *recorder) sent(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inflight[id] = time.Now()
+	r.total++
+}
+
+func (r *recorder) reject(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.inflight, id)
+	r.rejected++
+}
+
+func (r *recorder) fail(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.inflight[id]; ok {
+		delete(r.inflight, id)
+		r.failed++
+	}
+}
+
+func (r *recorder) answer(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if sent, ok := r.inflight[id]; ok {
+		delete(r.inflight, id)
+		r.latencies = append(r.latencies, time.Since(sent))
+	}
+}
+
+// settled reports whether every message is answered, failed or dropped.
+func (r *recorder) settled(dropped int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.inflight) <= dropped
+}
+
+// report summarizes the recorded messages.
+func (r *recorder) report(elapsed time.Duration, dropped int) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &Report{
+		ElapsedMs: elapsed.Milliseconds(),
+		Sent:      r.total - r.rejected,
+		Rejected:  r.rejected,
+		Dropped:   dropped,
+		Answered:  len(r.latencies),
+		Failed:    r.failed,
+		Pending:   max(len(r.inflight)-dropped, 0),
+	}
+	if elapsed > 0 {
+		report.Throughput = float64(report.Answered) / elapsed.Seconds()
+	}
+	if len(r.latencies) > 0 {
+		sorted := slices.Clone(r.latencies)
+		slices.Sort(sorted)
+		report.P50Ms = percentile(sorted, 50).Milliseconds()
+		report.P90Ms = percentile(sorted, 90).Milliseconds()
+		report.P99Ms = percentile(sorted, 99).Milliseconds()
+		report.MaxMs = sorted[len(sorted)-1].Milliseconds()
+	}
+	return report
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
@@ -0,0 +1,88 @@
+package loadtest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+func testLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return log
+}
+
+func TestRun_AnswersEveryMessage(t *testing.T) {
+	report, err := Run(t.Context(), Config{
+		RPS:          50,
+		Duration:     300 * time.Millisecond,
+		Users:        3,
+		Workers:      2,
+		LLMDelay:     time.Millisecond,
+		DrainTimeout: 5 * time.Second,
+		Logger:       testLogger(t),
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Sent == 0 || report.Answered != report.Sent {
+		t.Errorf("Run() = %+v, want every sent message answered", report)
+	}
+	if report.Failed != 0 || report.Dropped != 0 || report.Rejected != 0 || report.Pending != 0 {
+		t.Errorf("Run() = %+v, want no lost messages", report)
+	}
+	if report.P99Ms < report.P50Ms || report.MaxMs < report.P99Ms {
+		t.Errorf("latencies p50 %d, p99 %d, max %d are not ordered", report.P50Ms, report.P99Ms, report.MaxMs)
+	}
+}
+
+func TestRun_ReportsSaturation(t *testing.T) {
+	// One worker answers 10 messages per second, 100 arrive per second
+	report, err := Run(t.Context(), Config{
+		RPS:            100,
+		Duration:       300 * time.Millisecond,
+		LLMDelay:       100 * time.Millisecond,
+		SubscriberSize: 2,
+		DrainTimeout:   5 * time.Second,
+		Logger:         testLogger(t),
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Dropped == 0 || report.Capacity != 2 || report.MaxBacklog != 2 || report.SaturatedPct == 0 {
+		t.Errorf("Run() = %+v, want a saturated channel and dropped messages", report)
+	}
+	if report.Answered+report.Dropped != report.Sent {
+		t.Errorf("Run() = %+v, want every message answered or dropped", report)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+	if got := percentile(sorted, 99); got != 99*time.Millisecond {
+		t.Errorf("percentile(99) = %s, want 99ms", got)
+	}
+	if got := percentile(sorted[:1], 50); got != time.Millisecond {
+		t.Errorf("percentile of one value = %s, want 1ms", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	out := Format(Config{RPS: 20}, &Report{
+		ElapsedMs: 31000, Sent: 600, Answered: 590, Dropped: 10, Throughput: 19.03,
+		P50Ms: 210, P90Ms: 950, P99Ms: 1500, MaxMs: 12000, MaxBacklog: 10, Capacity: 10, SaturatedPct: 42,
+	})
+	for _, want := range []string{"20 msg/s", "19.0 answers/s", "p99 1.5s", "max 12s", "10/10", "42%", "Messages were lost"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Format() missing %q:\n%s", want, out)
+		}
+	}
+}
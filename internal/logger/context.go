@@ -0,0 +1,70 @@
+package logger
+
+import "context"
+
+// contextFieldsKey хранит поля логирования в контексте
+type contextFieldsKey struct{}
+
+// WithContext возвращает контекст с полями, которые методы *Ctx добавляют к
+// каждой записи: correlation_id, session_id и т.п. задаются один раз в начале
+// обработки запроса, а не в каждом вызове. Поле с уже добавленным ключом
+// заменяется.
+func WithContext(ctx context.Context, fields ...Field) context.Context {
+	current := FieldsFromContext(ctx)
+	merged := make([]Field, 0, len(current)+len(fields))
+	for _, f := range current {
+		if !hasField(fields, f.Key) {
+			merged = append(merged, f)
+		}
+	}
+	for _, f := range fields {
+		if f.Value != nil && f.Value != "" {
+			merged = append(merged, f)
+		}
+	}
+	return context.WithValue(ctx, contextFieldsKey{}, merged)
+}
+
+// FieldsFromContext возвращает поля, добавленные в контекст через WithContext
+func FieldsFromContext(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(contextFieldsKey{}).([]Field)
+	return fields
+}
+
+// FromContext возвращает logger с полями контекста: для кода, который
+// логирует без контекста или передаёт logger дальше
+func (l *Logger) FromContext(ctx context.Context) *Logger {
+	fields := FieldsFromContext(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
+
+// withContextFields дополняет поля вызова полями контекста; поля вызова
+// имеют приоритет
+func withContextFields(ctx context.Context, fields []Field) []Field {
+	ctxFields := FieldsFromContext(ctx)
+	if len(ctxFields) == 0 {
+		return fields
+	}
+	all := make([]Field, 0, len(ctxFields)+len(fields))
+	for _, f := range ctxFields {
+		if !hasField(fields, f.Key) {
+			all = append(all, f)
+		}
+	}
+	return append(all, fields...)
+}
+
+func hasField(fields []Field, key string) bool {
+	for _, f := range fields {
+		if f.Key == key {
+			return true
+		}
+	}
+	return false
+}
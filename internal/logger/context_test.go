@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWithContext_AddsFieldsToCtxMethods(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := createTestLogger(t, buf, "json")
+
+	ctx := WithContext(context.Background(),
+		Field{Key: "correlation_id", Value: "req-1"},
+		Field{Key: "session_id", Value: "telegram:42"})
+	ctx = WithContext(ctx, Field{Key: "session_id", Value: "telegram:43"}, Field{Key: "empty", Value: ""})
+
+	log.InfoCtx(ctx, "handled", Field{Key: "correlation_id", Value: "explicit"})
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if record["correlation_id"] != "explicit" {
+		t.Errorf("correlation_id = %v, want the call site value", record["correlation_id"])
+	}
+	if record["session_id"] != "telegram:43" {
+		t.Errorf("session_id = %v, want the replaced value", record["session_id"])
+	}
+	if _, ok := record["empty"]; ok {
+		t.Error("empty field should be skipped")
+	}
+	if n := strings.Count(buf.String(), `"correlation_id"`); n != 1 {
+		t.Errorf("correlation_id written %d times, want 1", n)
+	}
+}
+
+func TestWithContext_IgnoredWithoutContext(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := createTestLogger(t, buf, "json")
+	ctx := WithContext(context.Background(), Field{Key: "correlation_id", Value: "req-1"})
+
+	log.Info("no context")
+	if strings.Contains(buf.String(), "req-1") {
+		t.Errorf("Info without context got context fields: %s", buf.String())
+	}
+
+	buf.Reset()
+	log.FromContext(ctx).Info("bound")
+	if !strings.Contains(buf.String(), `"correlation_id":"req-1"`) {
+		t.Errorf("FromContext logger lacks context fields: %s", buf.String())
+	}
+	if log.FromContext(context.Background()) != log {
+		t.Error("FromContext without fields should return the same logger")
+	}
+}
@@ -23,7 +23,9 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 )
 
 // Config представляет конфигурацию logger
@@ -35,7 +37,8 @@ type Config struct {
 
 // Logger представляет обёртку вокруг slog.Logger
 type Logger struct {
-	slog *slog.Logger
+	slog   *slog.Logger
+	stream *Stream // nil для logger, созданного не через New
 }
 
 // Field представляет поле для structured logging
@@ -97,8 +100,10 @@ func New(cfg Config) (*Logger, error) {
 		return nil, fmt.Errorf("invalid log format: %s (expected: json, text)", cfg.Format)
 	}
 
+	stream := newStream()
 	return &Logger{
-		slog: slog.New(handler),
+		slog:   slog.New(&teeHandler{next: handler, stream: stream}),
+		stream: stream,
 	}, nil
 }
 
@@ -118,46 +123,65 @@ func parseLevel(level string) (slog.Level, bool) {
 	}
 }
 
+// Enabled сообщает, записываются ли сообщения уровня level (в вывод или поток
+// логов). Позволяет не собирать поля отладочных сообщений на горячих путях
+func (l *Logger) Enabled(ctx context.Context, level slog.Level) bool {
+	return l.slog.Enabled(ctx, level)
+}
+
 // Debug логирует сообщение на уровне debug
 func (l *Logger) Debug(msg string, fields ...Field) {
-	l.slog.Debug(msg, l.fieldsToAny(fields...)...)
+	l.log(context.Background(), slog.LevelDebug, msg, fields)
 }
 
 // Info логирует сообщение на уровне info
 func (l *Logger) Info(msg string, fields ...Field) {
-	l.slog.Info(msg, l.fieldsToAny(fields...)...)
+	l.log(context.Background(), slog.LevelInfo, msg, fields)
 }
 
 // Warn логирует сообщение на уровне warn
 func (l *Logger) Warn(msg string, fields ...Field) {
-	l.slog.Warn(msg, l.fieldsToAny(fields...)...)
+	l.log(context.Background(), slog.LevelWarn, msg, fields)
 }
 
 // Error логирует сообщение на уровне error с ошибкой
 func (l *Logger) Error(msg string, err error, fields ...Field) {
 	allFields := append([]Field{{Key: "error", Value: err}}, fields...)
-	l.slog.Error(msg, l.fieldsToAny(allFields...)...)
+	l.log(context.Background(), slog.LevelError, msg, allFields)
 }
 
-// DebugCtx логирует сообщение с контекстом на уровне debug
+// DebugCtx логирует сообщение на уровне debug с полями контекста (см. WithContext)
 func (l *Logger) DebugCtx(ctx context.Context, msg string, fields ...Field) {
-	l.slog.DebugContext(ctx, msg, l.fieldsToAny(fields...)...)
+	l.log(ctx, slog.LevelDebug, msg, fields)
 }
 
-// InfoCtx логирует сообщение с контекстом на уровне info
+// InfoCtx логирует сообщение на уровне info с полями контекста (см. WithContext)
 func (l *Logger) InfoCtx(ctx context.Context, msg string, fields ...Field) {
-	l.slog.InfoContext(ctx, msg, l.fieldsToAny(fields...)...)
+	l.log(ctx, slog.LevelInfo, msg, fields)
 }
 
-// WarnCtx логирует сообщение с контекстом на уровне warn
+// WarnCtx логирует сообщение на уровне warn с полями контекста (см. WithContext)
 func (l *Logger) WarnCtx(ctx context.Context, msg string, fields ...Field) {
-	l.slog.WarnContext(ctx, msg, l.fieldsToAny(fields...)...)
+	l.log(ctx, slog.LevelWarn, msg, fields)
 }
 
-// ErrorCtx логирует сообщение с контекстом на уровне error с ошибкой
+// ErrorCtx логирует сообщение на уровне error с ошибкой и полями контекста
 func (l *Logger) ErrorCtx(ctx context.Context, msg string, err error, fields ...Field) {
 	allFields := append([]Field{{Key: "error", Value: err}}, fields...)
-	l.slog.ErrorContext(ctx, msg, l.fieldsToAny(allFields...)...)
+	l.log(ctx, slog.LevelError, msg, allFields)
+}
+
+// log записывает сообщение с адресом вызывающей функции, а не обёртки:
+// по нему определяется компонент записи в потоке логов
+func (l *Logger) log(ctx context.Context, level slog.Level, msg string, fields []Field) {
+	if !l.slog.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // runtime.Callers, log, метод Logger
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(l.fieldsToAny(withContextFields(ctx, fields)...)...)
+	_ = l.slog.Handler().Handle(ctx, r)
 }
 
 // fieldsToAny конвертирует срез Field в срез slog.Attr
@@ -172,10 +196,16 @@ func (l *Logger) fieldsToAny(fields ...Field) []any {
 // With возвращает новый logger с добавленными полями
 func (l *Logger) With(fields ...Field) *Logger {
 	return &Logger{
-		slog: l.slog.With(l.fieldsToAny(fields...)...),
+		slog:   l.slog.With(l.fieldsToAny(fields...)...),
+		stream: l.stream,
 	}
 }
 
+// Stream возвращает поток записей логов; nil для logger, созданного не через New
+func (l *Logger) Stream() *Stream {
+	return l.stream
+}
+
 // StdLogger возвращает стандартный logger для совместимости
 func (l *Logger) StdLogger() *slog.Logger {
 	return l.slog
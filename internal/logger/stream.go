@@ -0,0 +1,302 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// subscriptionBuffer is the minimum channel size of a stream subscription
+const subscriptionBuffer = 256
+
+// Entry is a log entry delivered to stream subscribers.
+type Entry struct {
+	Time      time.Time      `json:"time"`
+	Level     string         `json:"level"`
+	Component string         `json:"component,omitempty"` // Package that logged the entry
+	Message   string         `json:"msg"`
+	Attrs     map[string]any `json:"attrs,omitempty"`
+}
+
+// Filter selects stream entries.
+type Filter struct {
+	Level      slog.Level // Minimum level
+	Components []string   // Components to include (all when empty)
+}
+
+// Match reports whether the entry passes the filter.
+func (f Filter) Match(e Entry, level slog.Level) bool {
+	if level < f.Level {
+		return false
+	}
+	if len(f.Components) == 0 {
+		return true
+	}
+	for _, c := range f.Components {
+		if strings.EqualFold(c, e.Component) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLevel converts a level name (debug, info, warn, error) to a slog level.
+func ParseLevel(level string) (slog.Level, bool) {
+	return parseLevel(level)
+}
+
+// Subscription receives stream entries until its context is done; then C
+// is closed.
+type Subscription struct {
+	C <-chan Entry
+
+	ch      chan Entry
+	filter  Filter
+	dropped atomic.Int64
+}
+
+// Dropped returns the number of entries skipped because the subscriber
+// didn't keep up.
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Stream fans log entries out to subscribers and keeps a backlog of recent
+// entries for new subscribers. It is inactive, and costs nothing, until
+// Enable is called. Subscribers may ask for levels below the configured log
+// level; such entries are streamed but not written to the log output.
+type Stream struct {
+	active   atomic.Bool
+	minLevel atomic.Int64 // Lowest level requested by a subscriber
+
+	mu      sync.Mutex
+	backlog []stampedEntry
+	next    int
+	subs    map[*Subscription]struct{}
+}
+
+// stampedEntry is a backlog entry with its level for filtering.
+type stampedEntry struct {
+	entry Entry
+	level slog.Level
+}
+
+func newStream() *Stream {
+	s := &Stream{subs: map[*Subscription]struct{}{}}
+	s.minLevel.Store(math.MaxInt64)
+	return s
+}
+
+// Enable activates the stream, keeping up to backlog recent entries.
+// Enabling an active stream with the same backlog keeps its entries.
+func (s *Stream) Enable(backlog int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active.Load() && cap(s.backlog) == max(backlog, 0) {
+		return
+	}
+	s.backlog = make([]stampedEntry, 0, max(backlog, 0))
+	s.next = 0
+	s.active.Store(true)
+}
+
+// Subscribe delivers up to backlog recent matching entries followed by new
+// ones. The subscription ends when ctx is done.
+func (s *Stream) Subscribe(ctx context.Context, filter Filter, backlog int) *Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var recent []Entry
+	for _, e := range s.recentLocked() {
+		if filter.Match(e.entry, e.level) {
+			recent = append(recent, e.entry)
+		}
+	}
+	if backlog >= 0 && len(recent) > backlog {
+		recent = recent[len(recent)-backlog:]
+	}
+
+	ch := make(chan Entry, max(subscriptionBuffer, len(recent)))
+	for _, e := range recent {
+		ch <- e
+	}
+	sub := &Subscription{C: ch, ch: ch, filter: filter}
+	s.subs[sub] = struct{}{}
+	s.updateMinLevelLocked()
+
+	context.AfterFunc(ctx, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subs, sub)
+		s.updateMinLevelLocked()
+		close(sub.ch)
+	})
+	return sub
+}
+
+// recentLocked returns the backlog in chronological order.
+func (s *Stream) recentLocked() []stampedEntry {
+	if len(s.backlog) < cap(s.backlog) {
+		return s.backlog
+	}
+	return append(append([]stampedEntry{}, s.backlog[s.next:]...), s.backlog[:s.next]...)
+}
+
+func (s *Stream) updateMinLevelLocked() {
+	level := int64(math.MaxInt64)
+	for sub := range s.subs {
+		level = min(level, int64(sub.filter.Level))
+	}
+	s.minLevel.Store(level)
+}
+
+// wants reports whether a subscriber wants entries of the level.
+func (s *Stream) wants(level slog.Level) bool {
+	return s.active.Load() && int64(level) >= s.minLevel.Load()
+}
+
+// publish delivers an entry to matching subscribers; logged entries (those
+// written to the log output) are also kept in the backlog.
+func (s *Stream) publish(e Entry, level slog.Level, logged bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if logged && cap(s.backlog) > 0 {
+		stamped := stampedEntry{entry: e, level: level}
+		if len(s.backlog) < cap(s.backlog) {
+			s.backlog = append(s.backlog, stamped)
+		} else {
+			s.backlog[s.next] = stamped
+			s.next = (s.next + 1) % cap(s.backlog)
+		}
+	}
+
+	for sub := range s.subs {
+		if !sub.filter.Match(e, level) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// teeHandler passes records to the log output and to the stream.
+type teeHandler struct {
+	next   slog.Handler
+	stream *Stream
+	attrs  []slog.Attr // Attributes added by With, already prefixed by groups
+	group  string      // Group prefix for attribute keys ("a.b.")
+}
+
+func (h *teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level) || h.stream.wants(level)
+}
+
+func (h *teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	logged := h.next.Enabled(ctx, r.Level)
+	var err error
+	if logged {
+		err = h.next.Handle(ctx, r)
+	}
+	if h.stream.active.Load() && (logged || h.stream.wants(r.Level)) {
+		h.stream.publish(h.entry(r), r.Level, logged)
+	}
+	return err
+}
+
+func (h *teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithAttrs(attrs)
+	clone.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, a := range attrs {
+		clone.attrs = append(clone.attrs, slog.Attr{Key: h.group + a.Key, Value: a.Value})
+	}
+	return &clone
+}
+
+func (h *teeHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithGroup(name)
+	clone.group = h.group + name + "."
+	return &clone
+}
+
+// entry converts a record to a stream entry.
+func (h *teeHandler) entry(r slog.Record) Entry {
+	e := Entry{
+		Time:      r.Time,
+		Level:     r.Level.String(),
+		Component: component(r.PC),
+		Message:   r.Message,
+	}
+	if len(h.attrs) > 0 || r.NumAttrs() > 0 {
+		e.Attrs = make(map[string]any, len(h.attrs)+r.NumAttrs())
+		for _, a := range h.attrs {
+			e.Attrs[a.Key] = attrValue(a.Value)
+		}
+		r.Attrs(func(a slog.Attr) bool {
+			e.Attrs[h.group+a.Key] = attrValue(a.Value)
+			return true
+		})
+	}
+	return e
+}
+
+// attrValue converts an attribute value to a JSON friendly value.
+func attrValue(v slog.Value) any {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindGroup:
+		group := make(map[string]any, len(v.Group()))
+		for _, a := range v.Group() {
+			group[a.Key] = attrValue(a.Value)
+		}
+		return group
+	case slog.KindAny:
+		switch value := v.Any().(type) {
+		case error:
+			return value.Error()
+		case nil:
+			return nil
+		default:
+			return value
+		}
+	default:
+		return v.Any()
+	}
+}
+
+// components caches the component of a program counter.
+var components sync.Map
+
+// component returns the last element of the package path of the function
+// at pc: "telegram" for internal/channels/telegram.
+func component(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	if c, ok := components.Load(pc); ok {
+		return c.(string)
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	name := frame.Function
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[:i]
+	}
+	components.Store(pc, name)
+	return name
+}
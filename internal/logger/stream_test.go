@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newStreamLogger(buf *bytes.Buffer, level slog.Level) *Logger {
+	stream := newStream()
+	handler := slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: level})
+	return &Logger{slog: slog.New(&teeHandler{next: handler, stream: stream}), stream: stream}
+}
+
+func receive(t *testing.T, sub *Subscription) Entry {
+	t.Helper()
+	select {
+	case e := <-sub.C:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a log entry")
+		return Entry{}
+	}
+}
+
+func TestStream_InactiveByDefault(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := newStreamLogger(buf, slog.LevelInfo)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub := log.Stream().Subscribe(ctx, Filter{Level: slog.LevelDebug}, 0)
+	log.Info("not streamed")
+	select {
+	case e := <-sub.C:
+		t.Errorf("inactive stream delivered %+v", e)
+	default:
+	}
+	if !strings.Contains(buf.String(), "not streamed") {
+		t.Error("entry not written to the log output")
+	}
+}
+
+func TestStream_DeliversEntriesWithComponentAndAttrs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := newStreamLogger(buf, slog.LevelInfo)
+	log.Stream().Enable(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub := log.Stream().Subscribe(ctx, Filter{Level: slog.LevelInfo}, 0)
+	log.With(Field{Key: "session_id", Value: "telegram:1"}).Error("send failed", errors.New("timeout"),
+		Field{Key: "retry_in", Value: 2 * time.Second})
+
+	e := receive(t, sub)
+	if e.Message != "send failed" || e.Level != "ERROR" || e.Component != "logger" {
+		t.Errorf("entry = %+v", e)
+	}
+	if e.Attrs["session_id"] != "telegram:1" || e.Attrs["error"] != "timeout" || e.Attrs["retry_in"] != "2s" {
+		t.Errorf("attrs = %v", e.Attrs)
+	}
+}
+
+func TestStream_FiltersAndStreamsBelowLogLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := newStreamLogger(buf, slog.LevelInfo)
+	log.Stream().Enable(10)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	debug := log.Stream().Subscribe(ctx, Filter{Level: slog.LevelDebug, Components: []string{"LOGGER"}}, 0)
+	other := log.Stream().Subscribe(ctx, Filter{Level: slog.LevelDebug, Components: []string{"telegram"}}, 0)
+	log.Debug("debug detail")
+
+	if e := receive(t, debug); e.Message != "debug detail" {
+		t.Errorf("entry = %+v", e)
+	}
+	if strings.Contains(buf.String(), "debug detail") {
+		t.Error("debug entry written to the info log output")
+	}
+	select {
+	case e := <-other.C:
+		t.Errorf("component filter passed %+v", e)
+	default:
+	}
+
+	cancel()
+	for range debug.C {
+		// Drained until closed
+	}
+	for range other.C {
+	}
+	if log.Stream().wants(slog.LevelDebug) {
+		t.Error("stream still wants debug entries after subscribers left")
+	}
+}
+
+func TestStream_Backlog(t *testing.T) {
+	log := newStreamLogger(&bytes.Buffer{}, slog.LevelInfo)
+	log.Stream().Enable(3)
+	for _, msg := range []string{"one", "two", "three", "four"} {
+		log.Info(msg)
+	}
+	log.Debug("not logged, not kept")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub := log.Stream().Subscribe(ctx, Filter{Level: slog.LevelInfo}, 2)
+	if e := receive(t, sub); e.Message != "three" {
+		t.Errorf("first backlog entry = %q, want three", e.Message)
+	}
+	if e := receive(t, sub); e.Message != "four" {
+		t.Errorf("second backlog entry = %q, want four", e.Message)
+	}
+}
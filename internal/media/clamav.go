@@ -0,0 +1,165 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultClamAVAddress is the clamd socket of the Debian and Ubuntu packages
+	DefaultClamAVAddress = "unix:///var/run/clamav/clamd.ctl"
+
+	// DefaultScanTimeout bounds a scan when no timeout is set
+	DefaultScanTimeout = 30 * time.Second
+
+	// clamdChunkSize is the size of INSTREAM chunks
+	clamdChunkSize = 64 << 10
+)
+
+// ClamAV scans files with a clamd daemon over its INSTREAM protocol.
+type ClamAV struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamAV creates a clamd client. The address is "unix:///path/to/socket"
+// or "tcp://host:port"; a timeout of 0 uses DefaultScanTimeout.
+func NewClamAV(address string, timeout time.Duration) (*ClamAV, error) {
+	network, addr, err := ParseClamAVAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	if timeout <= 0 {
+		timeout = DefaultScanTimeout
+	}
+	return &ClamAV{network: network, address: addr, timeout: timeout}, nil
+}
+
+// ParseClamAVAddress splits a clamd address into the network and the address for net.Dial.
+func ParseClamAVAddress(address string) (network, addr string, err error) {
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		network, addr = "unix", strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "tcp://"):
+		network, addr = "tcp", strings.TrimPrefix(address, "tcp://")
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return "", "", fmt.Errorf("invalid clamd address %q: %w", address, err)
+		}
+	default:
+		return "", "", fmt.Errorf("invalid clamd address %q (expected: unix:///path or tcp://host:port)", address)
+	}
+	if addr == "" {
+		return "", "", fmt.Errorf("invalid clamd address %q", address)
+	}
+	return network, addr, nil
+}
+
+// Name identifies the scanner in logs.
+func (c *ClamAV) Name() string {
+	return "clamav"
+}
+
+// Ping checks that clamd is reachable.
+func (c *ClamAV) Ping(ctx context.Context) error {
+	reply, err := c.command(ctx, "zPING\x00", nil)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("unexpected clamd reply: %s", reply)
+	}
+	return nil
+}
+
+// Scan streams r to clamd and returns its verdict.
+func (c *ClamAV) Scan(ctx context.Context, r io.Reader) (Verdict, error) {
+	reply, err := c.command(ctx, "zINSTREAM\x00", r)
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	// Replies: "stream: OK", "stream: <signature> FOUND", "<reason> ERROR"
+	result := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case result == "OK":
+		return Verdict{}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return Verdict{Infected: true, Signature: strings.TrimSuffix(result, " FOUND")}, nil
+	default:
+		return Verdict{}, fmt.Errorf("clamd error: %s", reply)
+	}
+}
+
+// command sends a null-terminated command, followed by the content in
+// INSTREAM chunks if body is set, and returns the reply.
+func (c *ClamAV) command(ctx context.Context, cmd string, body io.Reader) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	w := bufio.NewWriterSize(conn, clamdChunkSize+4)
+	if _, err := w.WriteString(cmd); err != nil {
+		return "", fmt.Errorf("failed to send clamd command: %w", err)
+	}
+	if body != nil {
+		if err := writeChunks(w, body); err != nil {
+			return "", err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("failed to send clamd command: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadBytes(0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return string(bytes.TrimSpace(bytes.TrimSuffix(reply, []byte{0}))), nil
+}
+
+// writeChunks writes r as length-prefixed chunks, terminated by an empty chunk.
+func writeChunks(w io.Writer, r io.Reader) error {
+	buf := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, werr := w.Write(size); werr != nil {
+				return fmt.Errorf("failed to send file to clamd: %w", werr)
+			}
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return fmt.Errorf("failed to send file to clamd: %w", werr)
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := w.Write(size); err != nil {
+		return fmt.Errorf("failed to send file to clamd: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+// eicar is the standard antivirus test string.
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd serves the zPING and zINSTREAM commands of clamd and reports
+// content containing the EICAR string as infected.
+func fakeClamd(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				cmd, err := r.ReadString(0)
+				if err != nil {
+					return
+				}
+				switch cmd {
+				case "zPING\x00":
+					_, _ = conn.Write([]byte("PONG\x00"))
+				case "zINSTREAM\x00":
+					var content bytes.Buffer
+					size := make([]byte, 4)
+					for {
+						if _, err := io.ReadFull(r, size); err != nil {
+							return
+						}
+						n := binary.BigEndian.Uint32(size)
+						if n == 0 {
+							break
+						}
+						if _, err := io.CopyN(&content, r, int64(n)); err != nil {
+							return
+						}
+					}
+					reply := "stream: OK\x00"
+					if strings.Contains(content.String(), eicar) {
+						reply = "stream: Eicar-Signature FOUND\x00"
+					}
+					_, _ = conn.Write([]byte(reply))
+				default:
+					_, _ = conn.Write([]byte("UNKNOWN COMMAND\x00"))
+				}
+			}()
+		}
+	}()
+	return "tcp://" + ln.Addr().String()
+}
+
+func TestClamAV_Scan(t *testing.T) {
+	clamav, err := NewClamAV(fakeClamd(t), 0)
+	if err != nil {
+		t.Fatalf("NewClamAV() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if err := clamav.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	verdict, err := clamav.Scan(ctx, strings.NewReader("hello"))
+	if err != nil || verdict.Infected {
+		t.Errorf("Scan(clean) = %+v, %v", verdict, err)
+	}
+
+	// Content larger than one chunk
+	infected := strings.Repeat("a", clamdChunkSize+10) + eicar
+	verdict, err = clamav.Scan(ctx, strings.NewReader(infected))
+	if err != nil || !verdict.Infected || verdict.Signature != "Eicar-Signature" {
+		t.Errorf("Scan(eicar) = %+v, %v", verdict, err)
+	}
+}
+
+func TestClamAV_Unreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	address := "tcp://" + ln.Addr().String()
+	_ = ln.Close()
+
+	clamav, err := NewClamAV(address, 0)
+	if err != nil {
+		t.Fatalf("NewClamAV() error = %v", err)
+	}
+	if _, err := clamav.Scan(context.Background(), strings.NewReader("hello")); err == nil {
+		t.Error("Expected error when clamd is unreachable")
+	}
+}
+
+func TestParseClamAVAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		network string
+		addr    string
+		wantErr bool
+	}{
+		{"unix:///var/run/clamav/clamd.ctl", "unix", "/var/run/clamav/clamd.ctl", false},
+		{"tcp://127.0.0.1:3310", "tcp", "127.0.0.1:3310", false},
+		{"tcp://127.0.0.1", "", "", true},
+		{"unix://", "", "", true},
+		{"127.0.0.1:3310", "", "", true},
+	}
+	for _, tt := range tests {
+		network, addr, err := ParseClamAVAddress(tt.address)
+		if (err != nil) != tt.wantErr || network != tt.network || addr != tt.addr {
+			t.Errorf("ParseClamAVAddress(%q) = %q, %q, %v", tt.address, network, addr, err)
+		}
+	}
+}
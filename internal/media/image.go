@@ -0,0 +1,165 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // Registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+
+	_ "golang.org/x/image/bmp" // Registers the BMP decoder
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Registers the WebP decoder
+)
+
+const (
+	// DefaultImageMaxDimension is the largest width and height of a photo;
+	// Telegram scales larger photos down to it anyway
+	DefaultImageMaxDimension = 2560
+
+	// DefaultImageMaxSize is the largest photo Telegram accepts
+	DefaultImageMaxSize = 10 << 20
+
+	// DefaultJPEGQuality is the quality of re-encoded photos
+	DefaultJPEGQuality = 85
+
+	// minJPEGQuality is the lowest quality tried before the image is scaled down further
+	minJPEGQuality = 40
+)
+
+// ErrUnsupportedImage is returned for files that are not images in a known format.
+var ErrUnsupportedImage = errors.New("unsupported image format")
+
+// ImageOptions limits images sent as photos. Zero values use the defaults.
+type ImageOptions struct {
+	MaxWidth  int   // Wider images are scaled down keeping the aspect ratio
+	MaxHeight int   // Taller images are scaled down keeping the aspect ratio
+	MaxSize   int64 // Larger files are re-encoded as JPEG with decreasing quality
+	Quality   int   // JPEG quality of re-encoded images (1-100)
+}
+
+// withDefaults fills zero options with the defaults.
+func (o ImageOptions) withDefaults() ImageOptions {
+	if o.MaxWidth <= 0 {
+		o.MaxWidth = DefaultImageMaxDimension
+	}
+	if o.MaxHeight <= 0 {
+		o.MaxHeight = DefaultImageMaxDimension
+	}
+	if o.MaxSize <= 0 {
+		o.MaxSize = DefaultImageMaxSize
+	}
+	if o.Quality <= 0 || o.Quality > 100 {
+		o.Quality = DefaultJPEGQuality
+	}
+	return o
+}
+
+// PreparedImage is an image re-encoded for sending.
+type PreparedImage struct {
+	Data   []byte
+	Name   string // File name with the extension of the new format
+	Width  int
+	Height int
+}
+
+// PrepareImage makes an image fit the options: larger images are scaled
+// down, formats other than JPEG and PNG are converted, and files over the
+// size limit are compressed as JPEG. It returns nil when the image already
+// fits and can be sent unchanged, and ErrUnsupportedImage for unknown formats.
+func PrepareImage(r io.Reader, name string, opts ImageOptions) (*PreparedImage, error) {
+	opts = opts.withDefaults()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	fits := cfg.Width <= opts.MaxWidth && cfg.Height <= opts.MaxHeight && int64(len(data)) <= opts.MaxSize
+	if fits && (format == "jpeg" || format == "png") {
+		return nil, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	width, height := fitDimensions(cfg.Width, cfg.Height, opts.MaxWidth, opts.MaxHeight)
+	img = scale(img, width, height)
+
+	// Graphics (charts, screenshots) stay lossless while they fit
+	if format == "png" || format == "gif" {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("failed to encode image: %w", err)
+		}
+		if int64(buf.Len()) <= opts.MaxSize {
+			return &PreparedImage{Data: buf.Bytes(), Name: withExt(name, ".png"), Width: width, Height: height}, nil
+		}
+	}
+
+	img = flatten(img)
+	for {
+		for quality := opts.Quality; quality >= minJPEGQuality; quality -= 10 {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+				return nil, fmt.Errorf("failed to encode image: %w", err)
+			}
+			if int64(buf.Len()) <= opts.MaxSize {
+				return &PreparedImage{Data: buf.Bytes(), Name: withExt(name, ".jpg"), Width: width, Height: height}, nil
+			}
+		}
+		if width <= 1 && height <= 1 {
+			return nil, fmt.Errorf("failed to compress image below %d bytes", opts.MaxSize)
+		}
+		width, height = max(width*3/4, 1), max(height*3/4, 1)
+		img = scale(img, width, height)
+	}
+}
+
+// fitDimensions scales width and height down to the limits, keeping the aspect ratio.
+func fitDimensions(width, height, maxWidth, maxHeight int) (int, int) {
+	if width <= maxWidth && height <= maxHeight {
+		return width, height
+	}
+	ratio := min(float64(maxWidth)/float64(width), float64(maxHeight)/float64(height))
+	return max(int(float64(width)*ratio), 1), max(int(float64(height)*ratio), 1)
+}
+
+// scale resizes img to the given dimensions.
+func scale(img image.Image, width, height int) image.Image {
+	bounds := img.Bounds()
+	if bounds.Dx() == width && bounds.Dy() == height {
+		return img
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+	return dst
+}
+
+// flatten draws img on a white background, as JPEG has no transparency.
+func flatten(img image.Image) image.Image {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Over)
+	return dst
+}
+
+// withExt replaces the extension of a file name.
+func withExt(name, ext string) string {
+	if name == "" {
+		return "image" + ext
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name)) + ext
+}
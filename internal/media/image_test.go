@@ -0,0 +1,90 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// testImage returns a noisy image, so it does not compress too well.
+func testImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	rng := rand.New(rand.NewSource(1))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(rng.Intn(256)), uint8(x), uint8(y), 255})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestPrepareImage(t *testing.T) {
+	opts := ImageOptions{MaxWidth: 200, MaxHeight: 100}
+
+	// Fits: sent unchanged
+	small := encodePNG(t, testImage(100, 50))
+	prepared, err := PrepareImage(bytes.NewReader(small), "chart.png", opts)
+	if err != nil || prepared != nil {
+		t.Fatalf("PrepareImage(small) = %v, %v, want nil", prepared, err)
+	}
+
+	// Too large: scaled down keeping the aspect ratio, PNG stays PNG
+	large := encodePNG(t, testImage(400, 100))
+	prepared, err = PrepareImage(bytes.NewReader(large), "chart.png", opts)
+	if err != nil || prepared == nil {
+		t.Fatalf("PrepareImage(large) = %v, %v", prepared, err)
+	}
+	if prepared.Width != 200 || prepared.Height != 50 || prepared.Name != "chart.png" {
+		t.Errorf("PrepareImage(large) = %dx%d %s, want 200x50 chart.png", prepared.Width, prepared.Height, prepared.Name)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(prepared.Data))
+	if err != nil || format != "png" || cfg.Width != 200 || cfg.Height != 50 {
+		t.Errorf("encoded image = %s %dx%d, %v", format, cfg.Width, cfg.Height, err)
+	}
+
+	// Over the size limit: compressed as JPEG
+	opts.MaxSize = int64(len(small)) / 4
+	prepared, err = PrepareImage(bytes.NewReader(small), "chart.png", opts)
+	if err != nil || prepared == nil {
+		t.Fatalf("PrepareImage(heavy) = %v, %v", prepared, err)
+	}
+	if int64(len(prepared.Data)) > opts.MaxSize || prepared.Name != "chart.jpg" {
+		t.Errorf("PrepareImage(heavy) = %d bytes %s, want at most %d bytes", len(prepared.Data), prepared.Name, opts.MaxSize)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(prepared.Data)); err != nil {
+		t.Errorf("jpeg.Decode() error = %v", err)
+	}
+}
+
+func TestPrepareImage_ConvertsFormat(t *testing.T) {
+	palette := image.NewPaletted(image.Rect(0, 0, 10, 10), []color.Color{color.Black, color.White})
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, palette, nil); err != nil {
+		t.Fatalf("gif.Encode() error = %v", err)
+	}
+
+	prepared, err := PrepareImage(&buf, "anim.gif", ImageOptions{})
+	if err != nil || prepared == nil || prepared.Name != "anim.png" {
+		t.Fatalf("PrepareImage(gif) = %+v, %v, want a PNG", prepared, err)
+	}
+
+	if _, err := PrepareImage(strings.NewReader("not an image"), "a.txt", ImageOptions{}); !errors.Is(err, ErrUnsupportedImage) {
+		t.Errorf("PrepareImage(text) error = %v, want ErrUnsupportedImage", err)
+	}
+}
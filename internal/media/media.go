@@ -0,0 +1,368 @@
+// Package media checks files downloaded from users (documents, voice
+// messages, photos) before they are stored or processed: it enforces a size
+// limit per media kind, sniffs the real MIME type from the content instead of
+// trusting the declared one, and keeps executables out of the workspace by
+// moving them to a quarantine directory. An optional Scanner (ClamAV) checks
+// files for malware before they reach the workspace.
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// Kind is the kind of a downloaded file; each kind has its own size limit.
+type Kind string
+
+const (
+	KindPhoto    Kind = "photo"
+	KindDocument Kind = "document"
+	KindVoice    Kind = "voice"
+	KindAudio    Kind = "audio"
+	KindVideo    Kind = "video"
+)
+
+const (
+	// DefaultMaxSize is the size limit of kinds without one (the Bot API download limit)
+	DefaultMaxSize = 20 << 20
+
+	// DefaultMaxPhotoSize is the size limit of photos (the Telegram photo limit)
+	DefaultMaxPhotoSize = 10 << 20
+
+	// sniffLen is the number of bytes used to detect the type, as in http.DetectContentType
+	sniffLen = 512
+
+	// executableType is reported for detected executables
+	executableType = "application/x-executable"
+)
+
+var (
+	// ErrTooLarge is returned for files larger than the limit of their kind.
+	ErrTooLarge = errors.New("file is too large")
+
+	// ErrTypeNotAllowed is returned for files whose sniffed type is not allowed.
+	ErrTypeNotAllowed = errors.New("file type is not allowed")
+
+	// ErrExecutable is returned for executable files.
+	ErrExecutable = errors.New("executable files are not accepted")
+
+	// ErrInfected is returned for files in which the scanner detected malware.
+	ErrInfected = errors.New("malware detected")
+
+	// ErrScanFailed is returned when a file could not be scanned.
+	ErrScanFailed = errors.New("virus scan failed")
+)
+
+// Actions applied to files in which the scanner detected malware.
+const (
+	ActionReject     = "reject"     // Reject the file
+	ActionQuarantine = "quarantine" // Reject the file and keep it in quarantine
+	ActionWarn       = "warn"       // Accept the file and log the detection
+)
+
+// Verdict is the result of a malware scan.
+type Verdict struct {
+	Infected  bool
+	Signature string // Name of the detected malware
+}
+
+// Scanner checks file content for malware.
+type Scanner interface {
+	// Name identifies the scanner in logs.
+	Name() string
+
+	// Scan returns the verdict for the content.
+	Scan(ctx context.Context, r io.Reader) (Verdict, error)
+}
+
+// executableSignatures are the magic numbers of executable formats.
+var executableSignatures = [][]byte{
+	[]byte("\x7fELF"),        // ELF (Linux, BSD)
+	[]byte("MZ"),             // PE/DOS (Windows)
+	{0xfe, 0xed, 0xfa, 0xce}, // Mach-O 32-bit
+	{0xfe, 0xed, 0xfa, 0xcf}, // Mach-O 64-bit
+	{0xce, 0xfa, 0xed, 0xfe}, // Mach-O 32-bit, reversed
+	{0xcf, 0xfa, 0xed, 0xfe}, // Mach-O 64-bit, reversed
+	{0xca, 0xfe, 0xba, 0xbe}, // Mach-O universal, Java class
+	[]byte("#!"),             // Scripts with an interpreter
+	[]byte("\x00asm"),        // WebAssembly
+	[]byte("dex\n"),          // Android Dalvik
+}
+
+// executableExtensions are file name extensions of executables and scripts
+// that may have no magic number (batch files, PowerShell and the like).
+var executableExtensions = map[string]bool{
+	".exe": true, ".com": true, ".scr": true, ".msi": true, ".dll": true,
+	".bat": true, ".cmd": true, ".ps1": true, ".vbs": true, ".vbe": true,
+	".jse": true, ".wsf": true, ".hta": true, ".lnk": true, ".jar": true,
+	".apk": true, ".app": true,
+}
+
+// Config configures a Policy.
+type Config struct {
+	MaxSizes      map[Kind]int64 // Size limits in bytes by kind (DefaultMaxPhotoSize / DefaultMaxSize if missing)
+	AllowedTypes  []string       // Allowed sniffed MIME types of documents, "image/*" matches a group; empty allows all
+	QuarantineDir string         // Directory for rejected executables; empty discards them
+	Scanner       Scanner        // Malware scanner; nil disables scanning
+	ScanAction    string         // ActionReject (default), ActionQuarantine or ActionWarn
+	ScanFailOpen  bool           // Accept files that could not be scanned instead of rejecting them
+	Logger        *logger.Logger // Audit log of scans; required with a Scanner
+}
+
+// Info describes the content of a file.
+type Info struct {
+	MIME       string // Sniffed MIME type without parameters
+	Executable bool   // The content or the name is an executable
+}
+
+// Policy checks downloaded files.
+type Policy struct {
+	cfg Config
+	now func() time.Time
+}
+
+// New creates a Policy.
+func New(cfg Config) *Policy {
+	return &Policy{cfg: cfg, now: time.Now}
+}
+
+// MaxSize returns the size limit of a kind in bytes.
+func (p *Policy) MaxSize(kind Kind) int64 {
+	if size := p.cfg.MaxSizes[kind]; size > 0 {
+		return size
+	}
+	if kind == KindPhoto {
+		return DefaultMaxPhotoSize
+	}
+	return DefaultMaxSize
+}
+
+// CheckSize checks the declared size of a file before it is downloaded.
+func (p *Policy) CheckSize(kind Kind, size int64) error {
+	if size > p.MaxSize(kind) {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+// Open inspects the beginning of a download. It returns a reader with the
+// whole content that fails with ErrTooLarge once the limit of the kind is
+// exceeded, and the sniffed type. Executables fail with ErrExecutable and
+// documents of types that are not allowed with ErrTypeNotAllowed; the
+// reader is returned with these errors too, so the file can be quarantined.
+func (p *Policy) Open(kind Kind, name string, r io.Reader) (io.Reader, Info, error) {
+	br := bufio.NewReaderSize(r, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, Info{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	info := Sniff(name, head)
+	content := &limitedReader{r: br, left: p.MaxSize(kind)}
+	if info.Executable {
+		return content, info, ErrExecutable
+	}
+	if kind == KindDocument && !p.allowed(info.MIME) {
+		return content, info, fmt.Errorf("%w: %s", ErrTypeNotAllowed, info.MIME)
+	}
+	return content, info, nil
+}
+
+// Scan checks the content with the scanner before it is stored. The content
+// is spooled to a temporary file outside the workspace, so nothing is
+// written there before the verdict; the returned reader replays it and
+// Close removes the file. Every scan is written to the audit log. Infected
+// files fail with ErrInfected unless the action is ActionWarn; with
+// ActionQuarantine they are kept in quarantine. Without a scanner the
+// content is returned unchanged.
+func (p *Policy) Scan(ctx context.Context, kind Kind, name string, content io.Reader) (io.ReadCloser, error) {
+	if p.cfg.Scanner == nil {
+		return io.NopCloser(content), nil
+	}
+
+	spool, err := os.CreateTemp("", "nexbot-scan-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	file := &tempFile{File: spool}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(spool, hash), content)
+	if err == nil {
+		_, err = spool.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	scanner := p.cfg.Scanner.Name()
+	audit := []logger.Field{
+		{Key: "scanner", Value: scanner},
+		{Key: "kind", Value: string(kind)},
+		{Key: "name", Value: name},
+		{Key: "size", Value: size},
+		{Key: "sha256", Value: hex.EncodeToString(hash.Sum(nil))},
+	}
+
+	verdict, err := p.cfg.Scanner.Scan(ctx, spool)
+	if err == nil {
+		_, err = spool.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		p.cfg.Logger.ErrorCtx(ctx, "file scan failed", err,
+			append(audit, logger.Field{Key: "fail_open", Value: p.cfg.ScanFailOpen})...)
+		if p.cfg.ScanFailOpen {
+			return file, nil
+		}
+		_ = file.Close()
+		return nil, fmt.Errorf("%w: %v", ErrScanFailed, err)
+	}
+
+	if !verdict.Infected {
+		p.cfg.Logger.InfoCtx(ctx, "file scanned", append(audit, logger.Field{Key: "result", Value: "clean"})...)
+		return file, nil
+	}
+
+	action := p.cfg.ScanAction
+	if action == "" {
+		action = ActionReject
+	}
+	audit = append(audit,
+		logger.Field{Key: "result", Value: "infected"},
+		logger.Field{Key: "signature", Value: verdict.Signature},
+		logger.Field{Key: "action", Value: action})
+
+	switch action {
+	case ActionWarn:
+		p.cfg.Logger.WarnCtx(ctx, "malware detected in file", audit...)
+		return file, nil
+	case ActionQuarantine:
+		path, qerr := p.Quarantine(name, spool)
+		if qerr != nil {
+			p.cfg.Logger.ErrorCtx(ctx, "failed to quarantine infected file", qerr, audit...)
+		}
+		audit = append(audit, logger.Field{Key: "quarantine", Value: path})
+	}
+	p.cfg.Logger.WarnCtx(ctx, "malware detected in file", audit...)
+	_ = file.Close()
+	return nil, fmt.Errorf("%w: %s", ErrInfected, verdict.Signature)
+}
+
+// Quarantine stores a rejected file in the quarantine directory without the
+// execute permission, so it can be reviewed but not run. It returns the path
+// of the stored file, or an empty path when quarantine is disabled.
+func (p *Policy) Quarantine(name string, r io.Reader) (string, error) {
+	if p.cfg.QuarantineDir == "" {
+		return "", nil
+	}
+	if err := os.MkdirAll(p.cfg.QuarantineDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+
+	name = filepath.Base(filepath.Clean("/" + name))
+	if name == "/" || name == "." {
+		name = "file"
+	}
+	// CreateTemp makes the name unique and the file readable only by the owner
+	f, err := os.CreateTemp(p.cfg.QuarantineDir, p.now().UTC().Format("20060102-150405")+"-*-"+name+".quarantine")
+	if err != nil {
+		return "", fmt.Errorf("failed to create quarantine file: %w", err)
+	}
+	path := f.Name()
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return "", fmt.Errorf("failed to write quarantine file: %w", err)
+	}
+	return path, nil
+}
+
+// Sniff detects the type of a file from the first bytes of its content; the
+// name is only used to recognize executables without a magic number.
+func Sniff(name string, head []byte) Info {
+	if isExecutable(name, head) {
+		return Info{MIME: executableType, Executable: true}
+	}
+	mimeType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		mimeType = "application/octet-stream"
+	}
+	return Info{MIME: mimeType}
+}
+
+// allowed reports whether a sniffed type matches the allowed types.
+func (p *Policy) allowed(mimeType string) bool {
+	if len(p.cfg.AllowedTypes) == 0 {
+		return true
+	}
+	for _, pattern := range p.cfg.AllowedTypes {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if group, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mimeType, group+"/") {
+				return true
+			}
+		} else if mimeType == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// isExecutable reports whether the content or the name is an executable.
+func isExecutable(name string, head []byte) bool {
+	for _, sig := range executableSignatures {
+		if bytes.HasPrefix(head, sig) {
+			return true
+		}
+	}
+	return executableExtensions[strings.ToLower(filepath.Ext(name))]
+}
+
+// tempFile removes the file when it is closed.
+type tempFile struct {
+	*os.File
+}
+
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	_ = os.Remove(f.Name())
+	return err
+}
+
+// limitedReader fails with ErrTooLarge when more than left bytes are read.
+type limitedReader struct {
+	r    io.Reader
+	left int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, ErrTooLarge
+	}
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n - 1, ErrTooLarge
+	}
+	return n, err
+}
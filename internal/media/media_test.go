@@ -0,0 +1,211 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+func TestPolicy_MaxSize(t *testing.T) {
+	p := New(Config{MaxSizes: map[Kind]int64{KindVoice: 100}})
+
+	if got := p.MaxSize(KindVoice); got != 100 {
+		t.Errorf("MaxSize(voice) = %d, want 100", got)
+	}
+	if got := p.MaxSize(KindPhoto); got != DefaultMaxPhotoSize {
+		t.Errorf("MaxSize(photo) = %d, want %d", got, DefaultMaxPhotoSize)
+	}
+	if got := p.MaxSize(KindDocument); got != DefaultMaxSize {
+		t.Errorf("MaxSize(document) = %d, want %d", got, DefaultMaxSize)
+	}
+	if err := p.CheckSize(KindVoice, 101); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("CheckSize() error = %v, want ErrTooLarge", err)
+	}
+	if err := p.CheckSize(KindVoice, 100); err != nil {
+		t.Errorf("CheckSize() error = %v, want nil", err)
+	}
+}
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name       string
+		fileName   string
+		content    string
+		mime       string
+		executable bool
+	}{
+		{"png", "photo.jpg", "\x89PNG\r\n\x1a\n\x00\x00", "image/png", false},
+		{"text", "report.csv", "a,b\n1,2\n", "text/plain", false},
+		{"pdf", "doc.pdf", "%PDF-1.7\n", "application/pdf", false},
+		{"elf disguised as image", "photo.png", "\x7fELF\x02\x01\x01", executableType, true},
+		{"windows executable", "report.pdf", "MZ\x90\x00\x03", executableType, true},
+		{"shell script", "notes.txt", "#!/bin/sh\nrm -rf /\n", executableType, true},
+		{"batch file by name", "run.BAT", "echo hello\r\n", executableType, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := Sniff(tt.fileName, []byte(tt.content))
+			if info.MIME != tt.mime || info.Executable != tt.executable {
+				t.Errorf("Sniff() = %+v, want MIME %q, executable %v", info, tt.mime, tt.executable)
+			}
+		})
+	}
+}
+
+func TestPolicy_Open(t *testing.T) {
+	p := New(Config{
+		MaxSizes:     map[Kind]int64{KindDocument: 10},
+		AllowedTypes: []string{"text/plain", "image/*"},
+	})
+
+	r, info, err := p.Open(KindDocument, "a.txt", strings.NewReader("hello"))
+	if err != nil || info.MIME != "text/plain" {
+		t.Fatalf("Open() = %+v, %v", info, err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil || string(data) != "hello" {
+		t.Errorf("content = %q, %v, want the whole file", data, err)
+	}
+
+	r, _, err = p.Open(KindDocument, "a.txt", strings.NewReader("hello world!"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, err := io.ReadAll(r); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("reading past the limit: error = %v, want ErrTooLarge", err)
+	}
+
+	if _, _, err := p.Open(KindDocument, "a.pdf", strings.NewReader("%PDF-1.7\n")); !errors.Is(err, ErrTypeNotAllowed) {
+		t.Errorf("Open(pdf) error = %v, want ErrTypeNotAllowed", err)
+	}
+	if _, _, err := p.Open(KindDocument, "a.png", strings.NewReader("\x89PNG\r\n\x1a\n")); err != nil {
+		t.Errorf("Open(png) error = %v, want nil for image/*", err)
+	}
+	// Allowed types only apply to documents
+	if _, _, err := p.Open(KindVoice, "voice.ogg", strings.NewReader("OggS\x00\x02")); err != nil {
+		t.Errorf("Open(voice) error = %v, want nil", err)
+	}
+
+	r, _, err = p.Open(KindDocument, "a.txt", strings.NewReader("\x7fELF"))
+	if !errors.Is(err, ErrExecutable) || r == nil {
+		t.Fatalf("Open(elf) = %v, %v, want a reader and ErrExecutable", r, err)
+	}
+	if data, _ := io.ReadAll(r); string(data) != "\x7fELF" {
+		t.Errorf("content = %q, want the file for quarantine", data)
+	}
+}
+
+func TestPolicy_Quarantine(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "quarantine")
+	p := New(Config{QuarantineDir: dir})
+	p.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	path, err := p.Quarantine("../../evil.exe", strings.NewReader("MZ payload"))
+	if err != nil {
+		t.Fatalf("Quarantine() error = %v", err)
+	}
+	if filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), "20260301-120000-") ||
+		!strings.HasSuffix(path, "-evil.exe.quarantine") {
+		t.Errorf("path = %q", path)
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if stat.Mode().Perm()&0111 != 0 {
+		t.Errorf("mode = %v, quarantined files must not be executable", stat.Mode())
+	}
+	if data, _ := os.ReadFile(path); string(data) != "MZ payload" {
+		t.Errorf("content = %q", data)
+	}
+
+	// Quarantine disabled: nothing is stored
+	path, err = New(Config{}).Quarantine("evil.exe", strings.NewReader("MZ"))
+	if path != "" || err != nil {
+		t.Errorf("Quarantine() without a directory = %q, %v, want empty path", path, err)
+	}
+}
+
+// fakeScanner reports content containing "virus" as infected.
+type fakeScanner struct {
+	err error
+}
+
+func (s *fakeScanner) Name() string { return "fake" }
+
+func (s *fakeScanner) Scan(_ context.Context, r io.Reader) (Verdict, error) {
+	if s.err != nil {
+		return Verdict{}, s.err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Verdict{}, err
+	}
+	if strings.Contains(string(data), "virus") {
+		return Verdict{Infected: true, Signature: "Test.Virus"}, nil
+	}
+	return Verdict{}, nil
+}
+
+func TestPolicy_Scan(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("logger.New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		cfg        Config
+		content    string
+		wantErr    error
+		quarantine bool
+	}{
+		{"clean", Config{Scanner: &fakeScanner{}}, "hello", nil, false},
+		{"infected", Config{Scanner: &fakeScanner{}}, "a virus", ErrInfected, false},
+		{"infected with warn action", Config{Scanner: &fakeScanner{}, ScanAction: ActionWarn}, "a virus", nil, false},
+		{"infected with quarantine action", Config{Scanner: &fakeScanner{}, ScanAction: ActionQuarantine}, "a virus", ErrInfected, true},
+		{"scan failed", Config{Scanner: &fakeScanner{err: errors.New("clamd down")}}, "hello", ErrScanFailed, false},
+		{"scan failed, fail open", Config{Scanner: &fakeScanner{err: errors.New("clamd down")}, ScanFailOpen: true}, "hello", nil, false},
+		{"no scanner", Config{}, "a virus", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Logger = log
+			cfg.QuarantineDir = filepath.Join(t.TempDir(), "quarantine")
+			r, err := New(cfg).Scan(ctx, KindDocument, "file.txt", strings.NewReader(tt.content))
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Scan() error = %v, want %v", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Scan() error = %v", err)
+				}
+				data, err := io.ReadAll(r)
+				if err != nil || string(data) != tt.content {
+					t.Errorf("content = %q, %v, want %q", data, err, tt.content)
+				}
+				if err := r.Close(); err != nil {
+					t.Errorf("Close() error = %v", err)
+				}
+			}
+
+			entries, _ := os.ReadDir(cfg.QuarantineDir)
+			if (len(entries) == 1) != tt.quarantine {
+				t.Errorf("quarantined files = %d, want quarantine %v", len(entries), tt.quarantine)
+			}
+		})
+	}
+}
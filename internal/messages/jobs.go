@@ -0,0 +1,30 @@
+package messages
+
+import (
+	"fmt"
+
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/jobs"
+)
+
+// FormatJobNotification formats the notification about a finished background job.
+//
+// Parameters:
+//   - job: Finished job
+//   - maxChars: Maximum result length in the notification (0 = no limit); the
+//     full result stays available via `nexbot job status`
+//
+// Returns:
+//   - Notification ready to send to the job's chat
+func FormatJobNotification(job *jobs.Job, maxChars int) string {
+	if job.Status == jobs.StatusFailed {
+		return fmt.Sprintf(constants.MsgBackgroundJobFailed, job.ID, job.Error)
+	}
+
+	result := []rune(CleanContent(job.Result))
+	if maxChars > 0 && len(result) > maxChars {
+		return fmt.Sprintf(constants.MsgBackgroundJobDone, job.ID, string(result[:maxChars])) +
+			fmt.Sprintf(constants.MsgBackgroundJobTruncated, job.ID)
+	}
+	return fmt.Sprintf(constants.MsgBackgroundJobDone, job.ID, string(result))
+}
@@ -0,0 +1,26 @@
+package messages
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/jobs"
+)
+
+func TestFormatJobNotification(t *testing.T) {
+	done := &jobs.Job{ID: "job_1", Status: jobs.StatusDone, Result: "All services are healthy."}
+	if got := FormatJobNotification(done, 0); !strings.Contains(got, "job_1 finished") || !strings.HasSuffix(got, "All services are healthy.") {
+		t.Errorf("FormatJobNotification(done) = %q", got)
+	}
+
+	long := &jobs.Job{ID: "job_2", Status: jobs.StatusDone, Result: strings.Repeat("x", 100)}
+	got := FormatJobNotification(long, 10)
+	if strings.Contains(got, strings.Repeat("x", 11)) || !strings.Contains(got, "nexbot job status job_2") {
+		t.Errorf("FormatJobNotification(long) should truncate with a hint, got %q", got)
+	}
+
+	failed := &jobs.Job{ID: "job_3", Status: jobs.StatusFailed, Error: "context deadline exceeded"}
+	if got := FormatJobNotification(failed, 0); !strings.Contains(got, "job_3 failed: context deadline exceeded") {
+		t.Errorf("FormatJobNotification(failed) = %q", got)
+	}
+}
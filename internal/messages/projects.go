@@ -0,0 +1,44 @@
+package messages
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/constants"
+	"github.com/aatumaykin/nexbot/internal/projects"
+)
+
+// FormatProject formats the project of a chat with its brief and lists the
+// other projects the chat can switch to.
+//
+// Parameters:
+//   - current: Project the chat is attached to, or nil
+//   - all: All projects sorted by name
+//
+// Returns:
+//   - Formatted project overview ready for display
+func FormatProject(current *projects.Project, all []projects.Project) string {
+	builder := &strings.Builder{}
+	if current == nil {
+		builder.WriteString(constants.MsgProjectNone)
+	} else {
+		builder.WriteString(fmt.Sprintf("📁 Project %s · %d chats\n\n", current.Name, len(current.Sessions)))
+		if brief := strings.TrimSpace(current.Brief); brief != "" {
+			builder.WriteString(brief)
+		} else {
+			builder.WriteString("No brief yet: it is written after a few messages.")
+		}
+		builder.WriteString("\n\nUse /project leave to detach this chat.")
+	}
+
+	var others []string
+	for _, p := range all {
+		if current == nil || p.Name != current.Name {
+			others = append(others, p.Name)
+		}
+	}
+	if len(others) > 0 {
+		builder.WriteString(fmt.Sprintf("\n\nOther projects: %s", strings.Join(others, ", ")))
+	}
+	return builder.String()
+}
@@ -0,0 +1,107 @@
+package messages
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/constants"
+)
+
+// untitledSession is shown for sessions without a generated title
+const untitledSession = "(untitled)"
+
+// FormatSessionList formats a list of sessions with titles, last activity and message counts.
+//
+// Parameters:
+//   - sessions: Sessions sorted by last activity (most recent first)
+//   - currentID: ID of the chat session the list is shown in; it and the named
+//     session bound to it are marked with ▶
+//   - limit: Maximum number of sessions to show (0 = all)
+//
+// Returns:
+//   - Formatted session list ready for display
+func FormatSessionList(sessions []session.Info, currentID string, limit int) string {
+	if len(sessions) == 0 {
+		return constants.MsgSessionsEmpty
+	}
+
+	builder := &strings.Builder{}
+	builder.WriteString(fmt.Sprintf(constants.MsgSessionsHeader, len(sessions)))
+
+	shown := sessions
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	for _, s := range shown {
+		marker := "•"
+		if s.ID == currentID || slices.Contains(s.BoundTo, currentID) {
+			marker = "▶"
+		}
+		title := s.Title
+		if title == "" {
+			title = untitledSession
+		}
+		if s.Name != "" {
+			title = s.Name + " — " + title
+		}
+		builder.WriteString(fmt.Sprintf("\n%s %s\n   %s · %d messages · %s\n",
+			marker, title, s.ID, s.MessageCount, FormatLastActivity(s.LastActivity, time.Now())))
+	}
+
+	if hidden := len(sessions) - len(shown); hidden > 0 {
+		builder.WriteString(fmt.Sprintf(constants.MsgSessionsMore, hidden))
+	}
+
+	return builder.String()
+}
+
+// FormatLastActivity formats a timestamp relative to now ("5m ago", "3h ago")
+// and falls back to a date for activity older than a week.
+func FormatLastActivity(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 7*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	default:
+		return t.Format("2006-01-02")
+	}
+}
+
+// FormatContexts formats the contexts of a chat with the current one marked
+// with ▶. named reports whether the chat continues a named session instead.
+func FormatContexts(contexts []session.ContextInfo, named bool) string {
+	builder := &strings.Builder{}
+	builder.WriteString(constants.MsgContextsHeader)
+
+	for _, c := range contexts {
+		marker := "•"
+		if c.Current {
+			marker = "▶"
+		}
+		line := c.Name
+		if c.Title != "" {
+			line += " — " + c.Title
+		}
+		builder.WriteString(fmt.Sprintf("\n%s %s\n   %d messages", marker, line, c.MessageCount))
+		if !c.LastActivity.IsZero() {
+			builder.WriteString(" · " + FormatLastActivity(c.LastActivity, time.Now()))
+		}
+		builder.WriteString("\n")
+	}
+
+	if named {
+		builder.WriteString(constants.MsgContextNamedSession)
+	}
+	builder.WriteString("\n" + constants.MsgContextUsage)
+	return builder.String()
+}
@@ -0,0 +1,67 @@
+package messages
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/constants"
+)
+
+func TestFormatSessionList(t *testing.T) {
+	now := time.Now()
+	sessions := []session.Info{
+		{ID: "telegram:1", Title: "Nginx log rotation", MessageCount: 12, LastActivity: now.Add(-5 * time.Minute)},
+		{ID: "telegram:2", MessageCount: 3, LastActivity: now.Add(-3 * time.Hour)},
+		{ID: "telegram:3", Title: "Old", MessageCount: 1, LastActivity: now.Add(-30 * 24 * time.Hour)},
+	}
+
+	result := FormatSessionList(sessions, "telegram:2", 2)
+
+	wantContains := []string{
+		"Sessions (3)",
+		"• Nginx log rotation",
+		"telegram:1 · 12 messages · 5m ago",
+		"▶ (untitled)",
+		"3h ago",
+		"and 1 more",
+	}
+	for _, want := range wantContains {
+		if !strings.Contains(result, want) {
+			t.Errorf("FormatSessionList() missing %q in:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "telegram:3") {
+		t.Error("FormatSessionList() should respect the limit")
+	}
+
+	if got := FormatSessionList(nil, "", 0); got != constants.MsgSessionsEmpty {
+		t.Errorf("FormatSessionList(nil) = %q", got)
+	}
+}
+
+func TestFormatSessionList_NamedSession(t *testing.T) {
+	sessions := []session.Info{
+		{ID: "named:project-x", Name: "project-x", Title: "Migration plan", BoundTo: []string{"telegram:5"}, LastActivity: time.Now()},
+	}
+
+	result := FormatSessionList(sessions, "telegram:5", 0)
+	if !strings.Contains(result, "▶ project-x — Migration plan") {
+		t.Errorf("Expected bound named session to be marked as current, got:\n%s", result)
+	}
+}
+
+func TestFormatLastActivity(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := map[time.Duration]string{
+		10 * time.Second:    "just now",
+		2 * 24 * time.Hour:  "2d ago",
+		10 * 24 * time.Hour: "2026-02-28",
+	}
+	for ago, want := range tests {
+		if got := FormatLastActivity(now.Add(-ago), now); got != want {
+			t.Errorf("FormatLastActivity(-%v) = %q, want %q", ago, got, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package messages
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/aatumaykin/nexbot/internal/constants"
 )
@@ -48,3 +49,10 @@ func FormatStatusMessage(
 
 	return builder.String()
 }
+
+// FormatReconnectMessage formats the notice sent to allowed users when the
+// bot is reachable again after an outage.
+func FormatReconnectMessage(downtime time.Duration) string {
+	return fmt.Sprintf("🔌 Connection to Telegram restored after %s of downtime. Messages sent meanwhile will be answered now.",
+		downtime.Round(time.Second))
+}
@@ -3,6 +3,7 @@ package messages
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aatumaykin/nexbot/internal/constants"
 )
@@ -361,3 +362,10 @@ func TestFormatStatusMessage_CompleteOutput(t *testing.T) {
 		t.Error("Session info should come before LLM config")
 	}
 }
+
+func TestFormatReconnectMessage(t *testing.T) {
+	got := FormatReconnectMessage(12*time.Minute + 30*time.Second + 400*time.Millisecond)
+	if !strings.Contains(got, "restored after 12m30s") {
+		t.Errorf("FormatReconnectMessage() = %q, want the rounded downtime", got)
+	}
+}
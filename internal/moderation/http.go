@@ -0,0 +1,160 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultOpenAIBaseURL is the OpenAI API base URL
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+	// DefaultOpenAIModel is the OpenAI moderation model
+	DefaultOpenAIModel = "omni-moderation-latest"
+
+	// maxErrorBody limits the error response body included in errors
+	maxErrorBody = 512
+)
+
+// OpenAIBackend checks text with the OpenAI moderation API.
+type OpenAIBackend struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// NewOpenAIBackend creates an OpenAI moderation backend.
+// Empty baseURL and model use the OpenAI defaults.
+func NewOpenAIBackend(apiKey, baseURL, model string, timeout time.Duration) *OpenAIBackend {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
+	return &OpenAIBackend{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		model:   model,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the backend name.
+func (b *OpenAIBackend) Name() string {
+	return "openai"
+}
+
+type openAIModerationResponse struct {
+	Results []struct {
+		Flagged    bool            `json:"flagged"`
+		Categories map[string]bool `json:"categories"`
+	} `json:"results"`
+}
+
+// Check sends text to the /moderations endpoint.
+func (b *OpenAIBackend) Check(ctx context.Context, text string) (Verdict, error) {
+	var resp openAIModerationResponse
+	err := postJSON(ctx, b.client, b.baseURL+"/moderations", b.apiKey, map[string]any{
+		"model": b.model,
+		"input": text,
+	}, &resp)
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	var verdict Verdict
+	for _, result := range resp.Results {
+		if !result.Flagged {
+			continue
+		}
+		verdict.Flagged = true
+		for category, flagged := range result.Categories {
+			if flagged {
+				verdict.Categories = append(verdict.Categories, category)
+			}
+		}
+	}
+	sort.Strings(verdict.Categories)
+	return verdict, nil
+}
+
+// ClassifierBackend checks text with a local classifier service.
+//
+// The service receives POST {"text": "..."} and replies with
+// {"flagged": true, "categories": ["..."], "matches": ["..."]};
+// categories and matches are optional.
+type ClassifierBackend struct {
+	url    string
+	client *http.Client
+}
+
+// NewClassifierBackend creates a backend for the classifier service at url.
+func NewClassifierBackend(url string, timeout time.Duration) *ClassifierBackend {
+	return &ClassifierBackend{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the backend name.
+func (b *ClassifierBackend) Name() string {
+	return "classifier"
+}
+
+// Check sends text to the classifier service.
+func (b *ClassifierBackend) Check(ctx context.Context, text string) (Verdict, error) {
+	var resp struct {
+		Flagged    bool     `json:"flagged"`
+		Categories []string `json:"categories"`
+		Matches    []string `json:"matches"`
+	}
+	if err := postJSON(ctx, b.client, b.url, "", map[string]any{"text": text}, &resp); err != nil {
+		return Verdict{}, err
+	}
+	return Verdict{
+		Flagged:    resp.Flagged,
+		Categories: resp.Categories,
+		Matches:    resp.Matches,
+	}, nil
+}
+
+// postJSON posts a JSON body and decodes a JSON response.
+func postJSON(ctx context.Context, client *http.Client, url, apiKey string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal moderation request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create moderation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("moderation request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		errBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("moderation request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(errBody)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode moderation response: %w", err)
+	}
+	return nil
+}
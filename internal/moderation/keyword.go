@@ -0,0 +1,60 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// KeywordRule flags text matching a regular expression (case-insensitive).
+type KeywordRule struct {
+	Category string
+	Pattern  string
+}
+
+type compiledRule struct {
+	category string
+	re       *regexp.Regexp
+}
+
+// KeywordBackend flags text using regular expression rules.
+type KeywordBackend struct {
+	rules []compiledRule
+}
+
+// NewKeywordBackend compiles the rules.
+func NewKeywordBackend(rules []KeywordRule) (*KeywordBackend, error) {
+	b := &KeywordBackend{rules: make([]compiledRule, 0, len(rules))}
+	for i, rule := range rules {
+		re, err := regexp.Compile("(?i)" + rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keyword rule %d: %w", i, err)
+		}
+		category := rule.Category
+		if category == "" {
+			category = "keyword"
+		}
+		b.rules = append(b.rules, compiledRule{category: category, re: re})
+	}
+	return b, nil
+}
+
+// Name returns the backend name.
+func (b *KeywordBackend) Name() string {
+	return "keywords"
+}
+
+// Check flags text matching any rule. All matches are returned for redaction.
+func (b *KeywordBackend) Check(ctx context.Context, text string) (Verdict, error) {
+	var verdicts []Verdict
+	for _, rule := range b.rules {
+		if matches := rule.re.FindAllString(text, -1); len(matches) > 0 {
+			verdicts = append(verdicts, Verdict{
+				Flagged:    true,
+				Categories: []string{rule.category},
+				Matches:    matches,
+			})
+		}
+	}
+	return merge(verdicts), nil
+}
@@ -0,0 +1,51 @@
+// Package moderation checks outbound messages before connectors send them.
+// Backends (keyword rules, the OpenAI moderation API, a local classifier
+// service) decide whether text is flagged; the Moderator applies the
+// configured action: block, redact or flag to an admin.
+package moderation
+
+import (
+	"context"
+	"slices"
+)
+
+// Actions applied to flagged messages.
+const (
+	ActionBlock  = "block"  // Replace the message with a notice
+	ActionRedact = "redact" // Replace the flagged fragments
+	ActionFlag   = "flag"   // Send unchanged and notify the admin
+)
+
+// Verdict is the result of a moderation check.
+type Verdict struct {
+	Flagged    bool
+	Categories []string
+	Matches    []string // Flagged fragments, if the backend can locate them
+}
+
+// Backend checks text for content that must not be sent.
+type Backend interface {
+	// Name identifies the backend in logs and admin notices.
+	Name() string
+
+	// Check returns the verdict for text.
+	Check(ctx context.Context, text string) (Verdict, error)
+}
+
+// merge combines verdicts of several backends.
+func merge(verdicts []Verdict) Verdict {
+	var result Verdict
+	for _, v := range verdicts {
+		if !v.Flagged {
+			continue
+		}
+		result.Flagged = true
+		for _, c := range v.Categories {
+			if !slices.Contains(result.Categories, c) {
+				result.Categories = append(result.Categories, c)
+			}
+		}
+		result.Matches = append(result.Matches, v.Matches...)
+	}
+	return result
+}
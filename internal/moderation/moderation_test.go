@@ -0,0 +1,177 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+type recordingPublisher struct {
+	messages []bus.OutboundMessage
+}
+
+func (p *recordingPublisher) PublishOutbound(msg bus.OutboundMessage) error {
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+type failingBackend struct{}
+
+func (failingBackend) Name() string { return "failing" }
+
+func (failingBackend) Check(ctx context.Context, text string) (Verdict, error) {
+	return Verdict{}, errors.New("service unavailable")
+}
+
+func newTestModerator(t *testing.T, action, admin string, publisher Publisher) *Moderator {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	keywords, err := NewKeywordBackend([]KeywordRule{
+		{Category: "credentials", Pattern: `sk-[a-z0-9]{8,}`},
+		{Pattern: `\bdarn\b`},
+	})
+	if err != nil {
+		t.Fatalf("NewKeywordBackend() error = %v", err)
+	}
+	return New(Config{
+		Backends:       []Backend{failingBackend{}, keywords},
+		Action:         action,
+		AdminSessionID: admin,
+		Logger:         log,
+	}, publisher)
+}
+
+func TestKeywordBackend(t *testing.T) {
+	if _, err := NewKeywordBackend([]KeywordRule{{Pattern: "("}}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+
+	backend, _ := NewKeywordBackend([]KeywordRule{{Category: "profanity", Pattern: `\bdarn\b`}})
+	verdict, err := backend.Check(context.Background(), "Darn it, darn!")
+	if err != nil || !verdict.Flagged {
+		t.Fatalf("Expected text to be flagged, got %+v (err: %v)", verdict, err)
+	}
+	if len(verdict.Matches) != 2 || verdict.Categories[0] != "profanity" {
+		t.Errorf("Unexpected verdict: %+v", verdict)
+	}
+}
+
+func TestModerator_FilterOutbound(t *testing.T) {
+	ctx := context.Background()
+	flagged := *bus.NewOutboundMessage(bus.ChannelTypeTelegram, "42", "telegram:42",
+		"Your key is sk-abcdef123456, darn", "corr-1", bus.FormatTypeMarkdown, nil)
+
+	t.Run("clean messages are unchanged", func(t *testing.T) {
+		m := newTestModerator(t, ActionBlock, "", nil)
+		msg := *bus.NewOutboundMessage(bus.ChannelTypeTelegram, "42", "telegram:42", "hello", "", "", nil)
+		if got := m.FilterOutbound(ctx, msg); got.Content != "hello" {
+			t.Errorf("Content = %q, want unchanged", got.Content)
+		}
+	})
+
+	t.Run("block replaces content", func(t *testing.T) {
+		m := newTestModerator(t, ActionBlock, "", nil)
+		got := m.FilterOutbound(ctx, flagged)
+		if got.Content != DefaultBlockMessage || got.Format != bus.FormatTypePlain || got.CorrelationID != "corr-1" {
+			t.Errorf("Unexpected blocked message: %+v", got)
+		}
+	})
+
+	t.Run("redact replaces matches", func(t *testing.T) {
+		m := newTestModerator(t, ActionRedact, "", nil)
+		got := m.FilterOutbound(ctx, flagged)
+		if got.Content != "Your key is [redacted], [redacted]" {
+			t.Errorf("Content = %q", got.Content)
+		}
+	})
+
+	t.Run("flag notifies admin", func(t *testing.T) {
+		publisher := &recordingPublisher{}
+		m := newTestModerator(t, ActionFlag, "telegram:1", publisher)
+		got := m.FilterOutbound(ctx, flagged)
+		if got.Content != flagged.Content {
+			t.Errorf("Expected flagged message to be sent unchanged, got %q", got.Content)
+		}
+		if len(publisher.messages) != 1 {
+			t.Fatalf("Expected 1 admin notice, got %d", len(publisher.messages))
+		}
+		notice := publisher.messages[0]
+		if notice.SessionID != "telegram:1" || notice.UserID != "1" || !strings.Contains(notice.Content, "credentials") {
+			t.Errorf("Unexpected admin notice: %+v", notice)
+		}
+
+		// Admin notices bypass moderation
+		if got := m.FilterOutbound(ctx, notice); got.Content != notice.Content || len(publisher.messages) != 1 {
+			t.Error("Expected admin notice to bypass moderation")
+		}
+	})
+
+	t.Run("media captions are moderated", func(t *testing.T) {
+		m := newTestModerator(t, ActionBlock, "", nil)
+		media := &bus.MediaData{Type: "photo", Caption: "darn"}
+		msg := *bus.NewPhotoMessage(bus.ChannelTypeTelegram, "42", "telegram:42", media, "", "", nil)
+		got := m.FilterOutbound(ctx, msg)
+		if got.Media.Caption != DefaultBlockMessage || media.Caption != "darn" {
+			t.Errorf("Expected caption of a copy to be blocked, got %q (original %q)", got.Media.Caption, media.Caption)
+		}
+	})
+}
+
+func TestOpenAIBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/moderations" || r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("Unexpected request: %s %s", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		flagged := strings.Contains(body["input"].(string), "bad")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"results": []map[string]any{{
+				"flagged":    flagged,
+				"categories": map[string]bool{"harassment": flagged, "violence": false},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	backend := NewOpenAIBackend("test-key", server.URL, "", time.Second)
+	verdict, err := backend.Check(context.Background(), "bad words")
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !verdict.Flagged || len(verdict.Categories) != 1 || verdict.Categories[0] != "harassment" {
+		t.Errorf("Unexpected verdict: %+v", verdict)
+	}
+
+	if verdict, _ := backend.Check(context.Background(), "fine"); verdict.Flagged {
+		t.Error("Expected clean text not to be flagged")
+	}
+}
+
+func TestClassifierBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("model not loaded"))
+	}))
+	defer server.Close()
+
+	_, err := NewClassifierBackend(server.URL, time.Second).Check(context.Background(), "text")
+	if err == nil || !strings.Contains(err.Error(), "model not loaded") {
+		t.Errorf("Expected error with response body, got %v", err)
+	}
+}
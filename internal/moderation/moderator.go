@@ -0,0 +1,210 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+const (
+	// DefaultBlockMessage replaces blocked messages
+	DefaultBlockMessage = "⚠️ This message was withheld by content moderation."
+
+	// redactedMark replaces redacted fragments
+	redactedMark = "[redacted]"
+
+	// skipMetadataKey marks messages that bypass moderation (admin notices)
+	skipMetadataKey = "moderation_skip"
+
+	// adminExcerptRunes limits the original text quoted in admin notices
+	adminExcerptRunes = 500
+)
+
+// Publisher publishes outbound messages (admin notices).
+type Publisher interface {
+	PublishOutbound(msg bus.OutboundMessage) error
+}
+
+// Config configures a Moderator.
+type Config struct {
+	Backends       []Backend
+	Action         string // ActionBlock, ActionRedact or ActionFlag
+	AdminSessionID string // Session notified about flagged messages ("channel:chat_id"), optional
+	BlockMessage   string // Text that replaces blocked messages
+	Logger         *logger.Logger
+}
+
+// Moderator applies moderation to outbound messages. It implements bus.OutboundFilter.
+type Moderator struct {
+	backends       []Backend
+	action         string
+	adminSessionID string
+	blockMessage   string
+	logger         *logger.Logger
+	publisher      Publisher
+}
+
+// New creates a Moderator. Admin notices are published with publisher.
+func New(cfg Config, publisher Publisher) *Moderator {
+	m := &Moderator{
+		backends:       cfg.Backends,
+		action:         cfg.Action,
+		adminSessionID: cfg.AdminSessionID,
+		blockMessage:   cfg.BlockMessage,
+		logger:         cfg.Logger,
+		publisher:      publisher,
+	}
+	if m.action == "" {
+		m.action = ActionBlock
+	}
+	if m.blockMessage == "" {
+		m.blockMessage = DefaultBlockMessage
+	}
+	return m
+}
+
+// Check runs all backends on text. A failing backend is logged and skipped,
+// so moderation never prevents delivery because of an unavailable service.
+// Returns the merged verdict and the names of the backends that flagged the text.
+func (m *Moderator) Check(ctx context.Context, text string) (Verdict, []string) {
+	var verdicts []Verdict
+	var flaggedBy []string
+	for _, backend := range m.backends {
+		verdict, err := backend.Check(ctx, text)
+		if err != nil {
+			m.logger.WarnCtx(ctx, "moderation backend failed",
+				logger.Field{Key: "backend", Value: backend.Name()},
+				logger.Field{Key: "error", Value: err.Error()})
+			continue
+		}
+		if verdict.Flagged {
+			verdicts = append(verdicts, verdict)
+			flaggedBy = append(flaggedBy, backend.Name())
+		}
+	}
+	return merge(verdicts), flaggedBy
+}
+
+// FilterOutbound moderates the text and media caption of an outbound message
+// and returns the message to send.
+func (m *Moderator) FilterOutbound(ctx context.Context, msg bus.OutboundMessage) bus.OutboundMessage {
+	if msg.Type == bus.MessageTypeDelete || msg.Metadata[skipMetadataKey] == true {
+		return msg
+	}
+
+	original := messageText(msg)
+	if strings.TrimSpace(original) == "" {
+		return msg
+	}
+
+	verdict, flaggedBy := m.Check(ctx, original)
+	if !verdict.Flagged {
+		return msg
+	}
+
+	m.logger.WarnCtx(ctx, "outbound message flagged by moderation",
+		logger.Field{Key: "session_id", Value: msg.SessionID},
+		logger.Field{Key: "action", Value: m.action},
+		logger.Field{Key: "backends", Value: flaggedBy},
+		logger.Field{Key: "categories", Value: verdict.Categories})
+
+	switch m.action {
+	case ActionBlock:
+		msg = m.block(msg)
+	case ActionRedact:
+		msg = m.redact(msg, verdict)
+	}
+
+	m.notifyAdmin(ctx, msg, original, verdict, flaggedBy)
+	return msg
+}
+
+// block replaces the text of the message with the block notice.
+func (m *Moderator) block(msg bus.OutboundMessage) bus.OutboundMessage {
+	if msg.Content != "" {
+		msg.Content = m.blockMessage
+	}
+	if msg.Media != nil && msg.Media.Caption != "" {
+		media := *msg.Media
+		media.Caption = m.blockMessage
+		msg.Media = &media
+	}
+	msg.Format = bus.FormatTypePlain
+	msg.InlineKeyboard = nil
+	return msg
+}
+
+// redact replaces flagged fragments. Messages flagged without located
+// fragments are blocked.
+func (m *Moderator) redact(msg bus.OutboundMessage, verdict Verdict) bus.OutboundMessage {
+	if len(verdict.Matches) == 0 {
+		return m.block(msg)
+	}
+	msg.Content = redactText(msg.Content, verdict.Matches)
+	if msg.Media != nil && msg.Media.Caption != "" {
+		media := *msg.Media
+		media.Caption = redactText(media.Caption, verdict.Matches)
+		msg.Media = &media
+	}
+	return msg
+}
+
+// notifyAdmin sends a notice about a flagged message to the admin session.
+func (m *Moderator) notifyAdmin(ctx context.Context, msg bus.OutboundMessage, original string, verdict Verdict, flaggedBy []string) {
+	if m.adminSessionID == "" || m.publisher == nil || msg.SessionID == m.adminSessionID {
+		return
+	}
+	channel, chatID, ok := strings.Cut(m.adminSessionID, ":")
+	if !ok {
+		return
+	}
+
+	categories := strings.Join(verdict.Categories, ", ")
+	if categories == "" {
+		categories = "unspecified"
+	}
+	text := fmt.Sprintf("🚩 Moderation (%s): message to %s flagged by %s\nCategories: %s\n\n%s",
+		m.action, msg.SessionID, strings.Join(flaggedBy, ", "), categories, excerpt(original, adminExcerptRunes))
+
+	notice := bus.NewOutboundMessage(bus.ChannelType(channel), chatID, m.adminSessionID, text, "",
+		bus.FormatTypePlain, map[string]any{skipMetadataKey: true})
+	if err := m.publisher.PublishOutbound(*notice); err != nil {
+		m.logger.WarnCtx(ctx, "failed to notify admin about flagged message",
+			logger.Field{Key: "admin_session_id", Value: m.adminSessionID},
+			logger.Field{Key: "error", Value: err.Error()})
+	}
+}
+
+// messageText returns the text of a message that is shown to the recipient.
+func messageText(msg bus.OutboundMessage) string {
+	text := msg.Content
+	if msg.Media != nil && msg.Media.Caption != "" {
+		if text != "" {
+			text += "\n"
+		}
+		text += msg.Media.Caption
+	}
+	return text
+}
+
+// redactText replaces all matches in text.
+func redactText(text string, matches []string) string {
+	for _, match := range matches {
+		if match != "" {
+			text = strings.ReplaceAll(text, match, redactedMark)
+		}
+	}
+	return text
+}
+
+// excerpt shortens text to n runes.
+func excerpt(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,181 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/aatumaykin/nexbot/internal/netguard"
+)
+
+// Fetcher downloads a page.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (io.ReadCloser, error)
+}
+
+// HTTPFetcher downloads pages over HTTP under the SSRF policy of web_fetch.
+type HTTPFetcher struct {
+	policy    *netguard.Policy
+	client    *http.Client
+	userAgent string
+	maxSize   int64
+}
+
+// NewHTTPFetcher creates a fetcher; responses larger than maxSize are rejected.
+func NewHTTPFetcher(policy *netguard.Policy, timeout time.Duration, userAgent string, maxSize int64) *HTTPFetcher {
+	return &HTTPFetcher{
+		policy:    policy,
+		client:    policy.Client(timeout, true),
+		userAgent: userAgent,
+		maxSize:   maxSize,
+	}
+}
+
+// Fetch requests the page and returns its body.
+func (f *HTTPFetcher) Fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := f.policy.CheckURL(req.URL); err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if f.maxSize > 0 && resp.ContentLength > f.maxSize {
+		resp.Body.Close()
+		return nil, fmt.Errorf("response too large: %d bytes exceeds %d bytes limit", resp.ContentLength, f.maxSize)
+	}
+	if f.maxSize > 0 {
+		return struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(resp.Body, f.maxSize), resp.Body}, nil
+	}
+	return resp.Body, nil
+}
+
+// ignoredElements hold no visible text.
+var ignoredElements = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true,
+	atom.Template: true, atom.Svg: true, atom.Iframe: true,
+}
+
+// lineElements start a new line of the extracted text.
+var lineElements = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true,
+	atom.Br: true, atom.Dd: true, atom.Details: true, atom.Div: true, atom.Dl: true,
+	atom.Dt: true, atom.Figcaption: true, atom.Figure: true, atom.Footer: true,
+	atom.Form: true, atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true,
+	atom.H5: true, atom.H6: true, atom.Header: true, atom.Hr: true, atom.Li: true,
+	atom.Main: true, atom.Nav: true, atom.Ol: true, atom.P: true, atom.Pre: true,
+	atom.Section: true, atom.Summary: true, atom.Table: true, atom.Tr: true, atom.Ul: true,
+}
+
+// Extract returns the visible text of the elements matching the CSS
+// selector, one line per block element; without a selector the whole page
+// is used. An error is returned when the selector matches nothing.
+func Extract(r io.Reader, selector string) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse page: %w", err)
+	}
+
+	roots := []*html.Node{doc}
+	if selector != "" {
+		sel, err := cascadia.Compile(selector)
+		if err != nil {
+			return "", fmt.Errorf("invalid selector %q: %w", selector, err)
+		}
+		if roots = sel.MatchAll(doc); len(roots) == 0 {
+			return "", fmt.Errorf("selector %q matched nothing", selector)
+		}
+	}
+
+	var b strings.Builder
+	for _, root := range roots {
+		writeText(&b, root)
+		b.WriteByte('\n')
+	}
+
+	var lines []string
+	for line := range strings.SplitSeq(b.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// writeText writes the text of n, breaking lines around block elements.
+func writeText(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(n.Data)
+		return
+	case html.ElementNode:
+		if ignoredElements[n.DataAtom] {
+			return
+		}
+	case html.DocumentNode:
+	default:
+		return
+	}
+
+	line := n.Type == html.ElementNode && lineElements[n.DataAtom]
+	if line {
+		b.WriteByte('\n')
+	}
+	cells := 0
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		// Table cells of a row stay on one line
+		if c.Type == html.ElementNode && (c.DataAtom == atom.Td || c.DataAtom == atom.Th) {
+			if cells > 0 {
+				b.WriteString(" | ")
+			}
+			cells++
+		}
+		writeText(b, c)
+	}
+	if line {
+		b.WriteByte('\n')
+	}
+}
+
+// diffLines returns the lines that appeared in and disappeared from the
+// content, in order. Lines that only moved are not reported.
+func diffLines(old, new []string) (added, removed []string) {
+	return missing(new, old), missing(old, new)
+}
+
+// missing returns the lines of a that are not in b, counting repeated lines.
+func missing(a, b []string) []string {
+	count := make(map[string]int, len(b))
+	for _, line := range b {
+		count[line]++
+	}
+	var lines []string
+	for _, line := range a {
+		if count[line] > 0 {
+			count[line]--
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,498 @@
+// Package monitor checks web pages on an interval and notifies a session when
+// their content changes. Every check downloads the page, extracts the text of
+// the elements matching a CSS selector and compares it with the stored
+// snapshot. Changes are published to the message bus as inbound messages
+// with a summarized diff, so the agent processes them like any other message
+// in the target session.
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+const (
+	// DefaultInterval is used for rules without an interval when Config.DefaultInterval is not set
+	DefaultInterval = time.Hour
+
+	// DefaultMinInterval is used when Config.MinInterval is not set
+	DefaultMinInterval = 5 * time.Minute
+
+	// DefaultMaxDiffLines is used when Config.MaxDiffLines is not set
+	DefaultMaxDiffLines = 20
+
+	// DefaultMaxMonitors is used when Config.MaxMonitors is not set
+	DefaultMaxMonitors = 50
+
+	// tickInterval is how often due monitors are looked for
+	tickInterval = 30 * time.Second
+
+	// checkTimeout limits a single check
+	checkTimeout = time.Minute
+)
+
+// Publisher publishes inbound messages (implemented by bus.MessageBus).
+type Publisher interface {
+	PublishInbound(msg bus.InboundMessage) error
+}
+
+// Config configures the monitor.
+type Config struct {
+	DefaultInterval time.Duration // Interval of rules without one
+	MinInterval     time.Duration // Shortest interval a runtime rule may use
+	MaxDiffLines    int           // Maximum changed lines included in one notification
+	MaxMonitors     int           // Maximum number of runtime rules
+	Rules           []Rule        // Static rules from config
+	Storage         *Storage      // Storage for runtime rules and snapshots
+	Fetcher         Fetcher       // Downloads the pages
+}
+
+// Status is a rule with the result of its last check.
+type Status struct {
+	Rule
+	LastCheck  time.Time // Zero if the page wasn't checked yet
+	LastChange time.Time // Zero if no change was seen yet
+	LastError  string    // Error of the last check, if it failed
+}
+
+// ruleState is a rule with its schedule and last result.
+type ruleState struct {
+	Status
+	next     time.Time // When the rule is due
+	checking bool      // Whether a check is running
+}
+
+// Monitor checks pages and publishes notifications on change.
+type Monitor struct {
+	cfg       Config
+	publisher Publisher
+	logger    *logger.Logger
+
+	mu      sync.Mutex
+	rules   map[string]*ruleState
+	started bool
+	done    chan struct{}
+}
+
+// New creates a new Monitor.
+func New(cfg Config, publisher Publisher, log *logger.Logger) *Monitor {
+	if cfg.DefaultInterval <= 0 {
+		cfg.DefaultInterval = DefaultInterval
+	}
+	if cfg.MinInterval <= 0 {
+		cfg.MinInterval = DefaultMinInterval
+	}
+	if cfg.MaxDiffLines <= 0 {
+		cfg.MaxDiffLines = DefaultMaxDiffLines
+	}
+	if cfg.MaxMonitors <= 0 {
+		cfg.MaxMonitors = DefaultMaxMonitors
+	}
+	return &Monitor{
+		cfg:       cfg,
+		publisher: publisher,
+		logger:    log,
+		rules:     make(map[string]*ruleState),
+	}
+}
+
+// Start registers static and stored rules and starts checking them until
+// the context is cancelled or Stop is called. A rule checked before the
+// restart is next checked one interval after its last check.
+func (m *Monitor) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.started {
+		return fmt.Errorf("monitor already started")
+	}
+
+	rules := make([]Rule, 0, len(m.cfg.Rules))
+	for _, r := range m.cfg.Rules {
+		r.Static = true
+		if r.ID == "" {
+			r.ID = StaticRuleID(r)
+		}
+		rules = append(rules, r)
+	}
+	stored, err := m.cfg.Storage.Load()
+	if err != nil {
+		m.logger.Error("failed to load stored monitors", err)
+	}
+	rules = append(rules, stored...)
+
+	for _, r := range rules {
+		if err := m.addLocked(r); err != nil {
+			m.logger.Error("failed to register monitor", err,
+				logger.Field{Key: "monitor_id", Value: r.ID},
+				logger.Field{Key: "url", Value: r.URL})
+		}
+	}
+
+	m.done = make(chan struct{})
+	m.started = true
+	go m.run(ctx, m.done)
+
+	m.logger.Info("monitor started",
+		logger.Field{Key: "rules", Value: len(m.rules)})
+	return nil
+}
+
+// Stop stops checking pages.
+func (m *Monitor) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.started {
+		return fmt.Errorf("monitor not started")
+	}
+	m.started = false
+	close(m.done)
+	return nil
+}
+
+// Add registers a runtime rule and persists it. The page is first checked
+// on the next tick; that check stores the snapshot later checks compare with.
+func (m *Monitor) Add(rule Rule) (Rule, error) {
+	if rule.ID == "" {
+		rule.ID = GenerateRuleID()
+	}
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = time.Now()
+	}
+	rule.Static = false
+
+	if rule.IntervalMinutes > 0 && time.Duration(rule.IntervalMinutes)*time.Minute < m.cfg.MinInterval {
+		return Rule{}, fmt.Errorf("interval_minutes must be at least %d", int(m.cfg.MinInterval.Minutes()))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.started {
+		return Rule{}, fmt.Errorf("monitor not started")
+	}
+	runtime := 0
+	for _, st := range m.rules {
+		if !st.Static {
+			runtime++
+		}
+	}
+	if runtime >= m.cfg.MaxMonitors {
+		return Rule{}, fmt.Errorf("too many monitors (max %d), remove one first", m.cfg.MaxMonitors)
+	}
+	if err := m.addLocked(rule); err != nil {
+		return Rule{}, err
+	}
+	if err := m.saveLocked(); err != nil {
+		delete(m.rules, rule.ID)
+		return Rule{}, err
+	}
+
+	m.logger.Info("monitor added",
+		logger.Field{Key: "monitor_id", Value: rule.ID},
+		logger.Field{Key: "url", Value: rule.URL},
+		logger.Field{Key: "session_id", Value: rule.SessionID})
+	return rule, nil
+}
+
+// Remove removes a runtime rule and its snapshot by ID.
+func (m *Monitor) Remove(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st, ok := m.rules[id]
+	if !ok {
+		return fmt.Errorf("monitor not found: %s", id)
+	}
+	if st.Static {
+		return fmt.Errorf("monitor %s is defined in config and cannot be removed", id)
+	}
+
+	delete(m.rules, id)
+	if err := m.saveLocked(); err != nil {
+		return err
+	}
+	if err := m.cfg.Storage.DeleteSnapshot(id); err != nil {
+		m.logger.Error("failed to delete monitor snapshot", err, logger.Field{Key: "monitor_id", Value: id})
+	}
+
+	m.logger.Info("monitor removed", logger.Field{Key: "monitor_id", Value: id})
+	return nil
+}
+
+// List returns all rules with their last results, sorted by ID.
+func (m *Monitor) List() []Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]Status, 0, len(m.rules))
+	for _, st := range m.rules {
+		statuses = append(statuses, st.Status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ID < statuses[j].ID })
+	return statuses
+}
+
+// CheckNow checks a rule right away and reports whether the content changed.
+func (m *Monitor) CheckNow(ctx context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	st, ok := m.rules[id]
+	if !ok {
+		m.mu.Unlock()
+		return false, fmt.Errorf("monitor not found: %s", id)
+	}
+	if st.checking {
+		m.mu.Unlock()
+		return false, fmt.Errorf("monitor %s is being checked", id)
+	}
+	st.checking = true
+	rule := st.Rule
+	m.mu.Unlock()
+
+	return m.check(ctx, rule)
+}
+
+// interval returns how often a rule is checked.
+func (m *Monitor) interval(r Rule) time.Duration {
+	if r.IntervalMinutes > 0 {
+		return time.Duration(r.IntervalMinutes) * time.Minute
+	}
+	return m.cfg.DefaultInterval
+}
+
+// addLocked validates and registers a rule. Caller must hold m.mu.
+func (m *Monitor) addLocked(rule Rule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	if _, exists := m.rules[rule.ID]; exists {
+		return fmt.Errorf("monitor already exists: %s", rule.ID)
+	}
+
+	st := &ruleState{Status: Status{Rule: rule}, next: time.Now()}
+	if snapshot, err := m.cfg.Storage.LoadSnapshot(rule.ID); err == nil && snapshot != nil {
+		st.LastCheck = snapshot.CheckedAt
+		st.LastChange = snapshot.ChangedAt
+		st.next = snapshot.CheckedAt.Add(m.interval(rule))
+	}
+	m.rules[rule.ID] = st
+	return nil
+}
+
+// saveLocked persists runtime rules. Caller must hold m.mu.
+func (m *Monitor) saveLocked() error {
+	var rules []Rule
+	for _, st := range m.rules {
+		if !st.Static {
+			rules = append(rules, st.Rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return m.cfg.Storage.Save(rules)
+}
+
+// run checks due rules until the monitor is stopped.
+func (m *Monitor) run(ctx context.Context, done chan struct{}) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		m.checkDue(ctx)
+		select {
+		case <-ctx.Done():
+			_ = m.Stop()
+			return
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkDue checks the rules whose time has come, one after another.
+func (m *Monitor) checkDue(ctx context.Context) {
+	m.mu.Lock()
+	now := time.Now()
+	var due []Rule
+	for _, st := range m.rules {
+		if !st.checking && !now.Before(st.next) {
+			st.checking = true
+			due = append(due, st.Rule)
+		}
+	}
+	m.mu.Unlock()
+
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	for _, rule := range due {
+		if ctx.Err() != nil {
+			m.release(rule.ID)
+			continue
+		}
+		_, _ = m.check(ctx, rule)
+	}
+}
+
+// release marks a rule as not being checked without a result.
+func (m *Monitor) release(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if st, ok := m.rules[id]; ok {
+		st.checking = false
+	}
+}
+
+// check downloads the page of a rule, compares it with the snapshot and
+// notifies the session of a change. The first check only stores the snapshot.
+func (m *Monitor) check(ctx context.Context, rule Rule) (changed bool, err error) {
+	now := time.Now()
+	defer func() { m.finish(rule, now, changed, err) }()
+
+	content, err := m.fetch(ctx, rule)
+	if err != nil {
+		m.logger.Warn("monitor check failed",
+			logger.Field{Key: "monitor_id", Value: rule.ID},
+			logger.Field{Key: "url", Value: rule.URL},
+			logger.Field{Key: "error", Value: err.Error()})
+		return false, err
+	}
+
+	previous, err := m.cfg.Storage.LoadSnapshot(rule.ID)
+	if err != nil {
+		return false, err
+	}
+	snapshot := &Snapshot{Content: content, CheckedAt: now, ChangedAt: now}
+	if previous != nil && previous.Content == content {
+		snapshot.ChangedAt = previous.ChangedAt
+	}
+	if !m.registered(rule.ID) {
+		// Removed while the page was downloaded
+		return false, nil
+	}
+	if err := m.cfg.Storage.SaveSnapshot(rule.ID, snapshot); err != nil {
+		return false, err
+	}
+
+	if previous == nil || previous.Content == content {
+		return false, nil
+	}
+	m.notify(rule, previous.Content, content)
+	return true, nil
+}
+
+// fetch downloads a page and extracts the monitored text.
+func (m *Monitor) fetch(ctx context.Context, rule Rule) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	body, err := m.cfg.Fetcher.Fetch(ctx, rule.URL)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	return Extract(body, rule.Selector)
+}
+
+func (m *Monitor) registered(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.rules[id]
+	return ok
+}
+
+// finish records the result of a check and schedules the next one.
+func (m *Monitor) finish(rule Rule, at time.Time, changed bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st, ok := m.rules[rule.ID]
+	if !ok {
+		return
+	}
+	st.checking = false
+	st.next = at.Add(m.interval(rule))
+	st.LastCheck = at
+	st.LastError = ""
+	if err != nil {
+		st.LastError = err.Error()
+	}
+	if changed {
+		st.LastChange = at
+	}
+}
+
+// notify publishes a change notification for the rule session.
+func (m *Monitor) notify(rule Rule, previous, current string) {
+	channel, _, _ := strings.Cut(rule.SessionID, ":")
+	added, removed := diffLines(strings.Split(previous, "\n"), strings.Split(current, "\n"))
+
+	msg := bus.NewInboundMessage(
+		bus.ChannelType(channel),
+		"", // Empty user_id for system notifications
+		rule.SessionID,
+		m.formatNotification(rule, added, removed),
+		map[string]any{
+			"source":     "monitor",
+			"monitor_id": rule.ID,
+			"url":        rule.URL,
+		},
+	)
+
+	if err := m.publisher.PublishInbound(*msg); err != nil {
+		m.logger.Error("failed to publish monitor notification", err,
+			logger.Field{Key: "monitor_id", Value: rule.ID},
+			logger.Field{Key: "session_id", Value: rule.SessionID})
+		return
+	}
+
+	m.logger.Info("monitor notification sent",
+		logger.Field{Key: "monitor_id", Value: rule.ID},
+		logger.Field{Key: "url", Value: rule.URL},
+		logger.Field{Key: "added", Value: len(added)},
+		logger.Field{Key: "removed", Value: len(removed)})
+}
+
+// formatNotification builds the message text the agent receives: the added
+// and removed lines, up to MaxDiffLines in total.
+func (m *Monitor) formatNotification(rule Rule, added, removed []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[monitor %s] Content changed: %s", rule.ID, rule.URL)
+	if rule.Selector != "" {
+		fmt.Fprintf(&b, " (selector %q)", rule.Selector)
+	}
+
+	if len(added) == 0 && len(removed) == 0 {
+		b.WriteString("\n\nThe same lines appear in a different order.")
+		return b.String()
+	}
+
+	budget := m.cfg.MaxDiffLines
+	omitted := 0
+	section := func(title, prefix string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n\n%s (%d):", title, len(lines))
+		for _, line := range lines {
+			if budget == 0 {
+				omitted++
+				continue
+			}
+			b.WriteString("\n" + prefix + line)
+			budget--
+		}
+	}
+	section("Added", "+ ", added)
+	section("Removed", "- ", removed)
+	if omitted > 0 {
+		fmt.Fprintf(&b, "\n\n… %d more changed lines omitted", omitted)
+	}
+	return b.String()
+}
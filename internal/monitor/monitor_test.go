@@ -0,0 +1,284 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/netguard"
+)
+
+type fakePublisher struct {
+	mu   sync.Mutex
+	msgs []bus.InboundMessage
+}
+
+func (p *fakePublisher) PublishInbound(msg bus.InboundMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.msgs = append(p.msgs, msg)
+	return nil
+}
+
+func (p *fakePublisher) messages() []bus.InboundMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]bus.InboundMessage(nil), p.msgs...)
+}
+
+// fakeFetcher serves pages from a map.
+type fakeFetcher struct {
+	mu    sync.Mutex
+	pages map[string]string
+}
+
+func (f *fakeFetcher) set(url, page string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pages[url] = page
+}
+
+func (f *fakeFetcher) Fetch(_ context.Context, url string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	page, ok := f.pages[url]
+	if !ok {
+		return nil, fmt.Errorf("unexpected status 404 Not Found")
+	}
+	return io.NopCloser(strings.NewReader(page)), nil
+}
+
+func newTestMonitor(t *testing.T, cfg Config) (*Monitor, *fakePublisher, *fakeFetcher) {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	require.NoError(t, err)
+
+	fetcher, _ := cfg.Fetcher.(*fakeFetcher)
+	if fetcher == nil {
+		fetcher = &fakeFetcher{pages: make(map[string]string)}
+		cfg.Fetcher = fetcher
+	}
+	if cfg.Storage == nil {
+		cfg.Storage = NewStorage(t.TempDir())
+	}
+	pub := &fakePublisher{}
+	m := New(cfg, pub, log)
+	require.NoError(t, m.Start(context.Background()))
+	t.Cleanup(func() { _ = m.Stop() })
+	return m, pub, fetcher
+}
+
+func pricePage(price string) string {
+	return `<html><body><nav>Home</nav><div class="price">Price: ` + price + `</div><ul id="news"><li>Launch</li></ul></body></html>`
+}
+
+func TestMonitor_NotifiesOnChange(t *testing.T) {
+	m, pub, fetcher := newTestMonitor(t, Config{})
+	fetcher.set("https://shop.example/item", pricePage("$10"))
+
+	rule, err := m.Add(Rule{URL: "https://shop.example/item", Selector: ".price", SessionID: "telegram:42"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rule.ID, "mon_"))
+
+	// The first check only stores the snapshot
+	changed, err := m.CheckNow(context.Background(), rule.ID)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, pub.messages())
+
+	// Changes outside the selector are ignored
+	fetcher.set("https://shop.example/item", strings.Replace(pricePage("$10"), "Launch", "Sale", 1))
+	changed, err = m.CheckNow(context.Background(), rule.ID)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	fetcher.set("https://shop.example/item", pricePage("$8"))
+	changed, err = m.CheckNow(context.Background(), rule.ID)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	msgs := pub.messages()
+	require.Len(t, msgs, 1)
+	msg := msgs[0]
+	assert.Equal(t, bus.ChannelType("telegram"), msg.ChannelType)
+	assert.Equal(t, "telegram:42", msg.SessionID)
+	assert.Equal(t, "monitor", msg.Metadata["source"])
+	assert.Equal(t, rule.ID, msg.Metadata["monitor_id"])
+	assert.Contains(t, msg.Content, "Content changed: https://shop.example/item")
+	assert.Contains(t, msg.Content, "+ Price: $8")
+	assert.Contains(t, msg.Content, "- Price: $10")
+
+	statuses := m.List()
+	require.Len(t, statuses, 1)
+	assert.False(t, statuses[0].LastChange.IsZero())
+	assert.Empty(t, statuses[0].LastError)
+}
+
+func TestMonitor_CheckError(t *testing.T) {
+	m, pub, fetcher := newTestMonitor(t, Config{})
+	fetcher.set("https://example.com/", pricePage("$10"))
+
+	rule, err := m.Add(Rule{URL: "https://example.com/", Selector: ".missing", SessionID: "telegram:42"})
+	require.NoError(t, err)
+
+	_, err = m.CheckNow(context.Background(), rule.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "matched nothing")
+	assert.Contains(t, m.List()[0].LastError, "matched nothing")
+	assert.Empty(t, pub.messages())
+}
+
+func TestMonitor_PersistsRuntimeRules(t *testing.T) {
+	storage := NewStorage(t.TempDir())
+	fetcher := &fakeFetcher{pages: map[string]string{"https://example.com/": pricePage("$10")}}
+
+	m, _, _ := newTestMonitor(t, Config{
+		Storage: storage,
+		Fetcher: fetcher,
+		Rules:   []Rule{{URL: "https://example.com/static", SessionID: "telegram:1"}},
+	})
+	rule, err := m.Add(Rule{URL: "https://example.com/", SessionID: "telegram:42", IntervalMinutes: 10})
+	require.NoError(t, err)
+	_, err = m.CheckNow(context.Background(), rule.ID)
+	require.NoError(t, err)
+	require.NoError(t, m.Stop())
+
+	// Only the runtime rule is stored
+	stored, err := storage.Load()
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, rule.ID, stored[0].ID)
+
+	// After a restart the rule is back with its last check
+	restarted, _, _ := newTestMonitor(t, Config{Storage: storage, Fetcher: fetcher})
+	statuses := restarted.List()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, rule.ID, statuses[0].ID)
+	assert.False(t, statuses[0].LastCheck.IsZero())
+
+	require.NoError(t, restarted.Remove(rule.ID))
+	snapshot, err := storage.LoadSnapshot(rule.ID)
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+}
+
+func TestMonitor_StaticRules(t *testing.T) {
+	static := Rule{URL: "https://example.com/", Selector: "h1", SessionID: "telegram:42"}
+	m, _, _ := newTestMonitor(t, Config{Rules: []Rule{static}})
+
+	statuses := m.List()
+	require.Len(t, statuses, 1)
+	assert.True(t, statuses[0].Static)
+	assert.Equal(t, StaticRuleID(static), statuses[0].ID)
+
+	err := m.Remove(statuses[0].ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be removed")
+}
+
+func TestMonitor_AddLimits(t *testing.T) {
+	m, _, _ := newTestMonitor(t, Config{MinInterval: 10 * time.Minute, MaxMonitors: 1})
+
+	_, err := m.Add(Rule{URL: "https://example.com/", SessionID: "telegram:42", IntervalMinutes: 5})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at least 10")
+
+	_, err = m.Add(Rule{URL: "ftp://example.com/", SessionID: "telegram:42"})
+	require.Error(t, err)
+
+	_, err = m.Add(Rule{URL: "https://example.com/", Selector: "div[", SessionID: "telegram:42"})
+	require.Error(t, err)
+
+	_, err = m.Add(Rule{URL: "https://example.com/", SessionID: "telegram:42"})
+	require.NoError(t, err)
+	_, err = m.Add(Rule{URL: "https://example.com/other", SessionID: "telegram:42"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too many monitors")
+}
+
+func TestMonitor_FormatNotification(t *testing.T) {
+	m := New(Config{MaxDiffLines: 2}, &fakePublisher{}, nil)
+	rule := Rule{ID: "mon_1", URL: "https://example.com/", Selector: "li"}
+
+	text := m.formatNotification(rule, []string{"a", "b"}, []string{"c"})
+	assert.Contains(t, text, `(selector "li")`)
+	assert.Contains(t, text, "Added (2):\n+ a\n+ b")
+	assert.Contains(t, text, "Removed (1):")
+	assert.NotContains(t, text, "- c")
+	assert.Contains(t, text, "1 more changed lines omitted")
+
+	text = m.formatNotification(rule, nil, nil)
+	assert.Contains(t, text, "different order")
+}
+
+func TestExtract(t *testing.T) {
+	page := `<html><head><title>T</title><script>var x = 1;</script></head><body>
+		<h1>Releases</h1>
+		<ul id="releases"><li>v1.2 <b>stable</b></li><li>v1.1</li></ul>
+		<table><tr><th>Plan</th><th>Price</th></tr><tr><td>Pro</td><td>$8</td></tr></table>
+	</body></html>`
+
+	text, err := Extract(strings.NewReader(page), "")
+	require.NoError(t, err)
+	assert.Equal(t, "Releases\nv1.2 stable\nv1.1\nPlan | Price\nPro | $8", text)
+
+	text, err = Extract(strings.NewReader(page), "#releases li")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2 stable\nv1.1", text)
+
+	_, err = Extract(strings.NewReader(page), ".missing")
+	require.Error(t, err)
+}
+
+func TestDiffLines(t *testing.T) {
+	added, removed := diffLines([]string{"a", "b", "b", "c"}, []string{"c", "b", "d", "a"})
+	assert.Equal(t, []string{"d"}, added)
+	assert.Equal(t, []string{"b"}, removed)
+}
+
+func TestHTTPFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			assert.Equal(t, "test-agent", r.Header.Get("User-Agent"))
+			_, _ = w.Write([]byte(pricePage("$10")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPFetcher(&netguard.Policy{AllowPrivate: true}, 5*time.Second, "test-agent", 50)
+
+	body, err := fetcher.Fetch(context.Background(), server.URL+"/page")
+	require.Error(t, err, "Content-Length above the limit")
+	assert.Nil(t, body)
+
+	fetcher = NewHTTPFetcher(&netguard.Policy{AllowPrivate: true}, 5*time.Second, "test-agent", 1024)
+	body, err = fetcher.Fetch(context.Background(), server.URL+"/page")
+	require.NoError(t, err)
+	text, err := Extract(body, ".price")
+	body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "Price: $10", text)
+
+	_, err = fetcher.Fetch(context.Background(), server.URL+"/missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+
+	// Private addresses are blocked by default
+	fetcher = NewHTTPFetcher(&netguard.Policy{}, 5*time.Second, "test-agent", 1024)
+	_, err = fetcher.Fetch(context.Background(), server.URL+"/page")
+	require.Error(t, err)
+}
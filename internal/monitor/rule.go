@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/google/uuid"
+)
+
+// Rule describes a monitored page: which URL and part of it to check, how
+// often, and which session should be notified about changes.
+type Rule struct {
+	ID              string    `json:"id"`
+	URL             string    `json:"url"`
+	Selector        string    `json:"selector,omitempty"`         // CSS selector of the monitored part; empty means the whole page
+	IntervalMinutes int       `json:"interval_minutes,omitempty"` // 0 means the default interval
+	SessionID       string    `json:"session_id"`                 // Session to notify ("channel:chat_id")
+	CreatedAt       time.Time `json:"created_at"`
+
+	// Static rules come from the config file; they are not persisted and cannot be removed
+	Static bool `json:"-"`
+}
+
+// GenerateRuleID generates a short unique monitor ID.
+func GenerateRuleID() string {
+	return fmt.Sprintf("mon_%s", uuid.New().String()[:8])
+}
+
+// StaticRuleID derives a stable ID for a config rule without one, so its
+// snapshot survives restarts.
+func StaticRuleID(r Rule) string {
+	sum := sha256.Sum256([]byte(r.URL + "\x00" + r.Selector + "\x00" + r.SessionID))
+	return "mon_" + hex.EncodeToString(sum[:])[:8]
+}
+
+// Validate checks the rule fields.
+func (r Rule) Validate() error {
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q: must be an http:// or https:// URL", r.URL)
+	}
+	if r.Selector != "" {
+		if _, err := cascadia.Compile(r.Selector); err != nil {
+			return fmt.Errorf("invalid selector %q: %w", r.Selector, err)
+		}
+	}
+	if r.IntervalMinutes < 0 {
+		return fmt.Errorf("interval_minutes must be positive (got: %d)", r.IntervalMinutes)
+	}
+	if _, _, ok := strings.Cut(r.SessionID, ":"); !ok {
+		return fmt.Errorf("invalid session_id format: expected 'channel:chat_id', got '%s'", r.SessionID)
+	}
+	return nil
+}
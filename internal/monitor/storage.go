@@ -0,0 +1,115 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// MonitorSubdirectory is the subdirectory name for monitor data within workspace
+	MonitorSubdirectory = "monitor"
+
+	// RulesFilename is the filename for storing monitors added at runtime
+	RulesFilename = "monitors.json"
+
+	// SnapshotsSubdirectory holds the last seen content of every monitor
+	SnapshotsSubdirectory = "snapshots"
+)
+
+// Snapshot is the last seen content of a monitored page.
+type Snapshot struct {
+	Content   string    `json:"content"`
+	CheckedAt time.Time `json:"checked_at"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// Storage persists monitors added at runtime (e.g. by the agent) and the
+// snapshots of all monitors, so they survive restarts.
+type Storage struct {
+	dir string
+}
+
+// NewStorage creates a storage located at <workspace>/monitor.
+func NewStorage(workspacePath string) *Storage {
+	return &Storage{dir: filepath.Join(workspacePath, MonitorSubdirectory)}
+}
+
+// Load reads stored rules. Returns an empty slice if the file doesn't exist.
+func (s *Storage) Load() ([]Rule, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, RulesFilename))
+	if os.IsNotExist(err) {
+		return []Rule{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read monitors: %w", err)
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse monitors: %w", err)
+	}
+	return rules, nil
+}
+
+// Save overwrites the stored rules.
+func (s *Storage) Save(rules []Rule) error {
+	return s.write(filepath.Join(s.dir, RulesFilename), rules, "monitors")
+}
+
+// LoadSnapshot reads the snapshot of a monitor. Returns nil if there is none yet.
+func (s *Storage) LoadSnapshot(id string) (*Snapshot, error) {
+	data, err := os.ReadFile(s.snapshotPath(id))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// SaveSnapshot overwrites the snapshot of a monitor.
+func (s *Storage) SaveSnapshot(id string, snapshot *Snapshot) error {
+	return s.write(s.snapshotPath(id), snapshot, "snapshot")
+}
+
+// DeleteSnapshot removes the snapshot of a removed monitor.
+func (s *Storage) DeleteSnapshot(id string) error {
+	if err := os.Remove(s.snapshotPath(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *Storage) snapshotPath(id string) string {
+	return filepath.Join(s.dir, SnapshotsSubdirectory, id+".json")
+}
+
+// write stores v as JSON, writing to a temp file first and renaming it for atomicity.
+func (s *Storage) write(path string, v any, what string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create monitor directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", what, err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to save %s: %w", what, err)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+// Package msgtemplate keeps named templates of outbound messages. Tools and
+// the cron scheduler send notifications (alerts, reports) by template name
+// and data instead of free-form LLM text, so critical notifications always
+// look the same: a template with missing data fails instead of guessing.
+package msgtemplate
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+)
+
+var (
+	// ErrNotFound is returned for unknown templates
+	ErrNotFound = errors.New("message template not found")
+
+	namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+)
+
+// Definition describes a template as configured.
+type Definition struct {
+	Text        string   // Go text/template, e.g. "Build {{.build}} failed: {{.error}}"
+	Format      string   // Message format: "", "markdown", "html" or "markdownv2"
+	Description string   // When to use the template, shown to the LLM
+	Optional    []string // Fields that may be missing from the data (rendered as empty)
+}
+
+// Info describes a registered template.
+type Info struct {
+	Name        string
+	Description string
+	Format      bus.FormatType
+	Fields      []string // Data fields the template uses, sorted
+	Optional    []string // Fields that may be missing
+}
+
+// Message is a rendered template.
+type Message struct {
+	Text   string
+	Format bus.FormatType
+}
+
+// Registry keeps parsed templates. It is read-only after creation and safe
+// for concurrent use.
+type Registry struct {
+	templates map[string]*entry
+}
+
+type entry struct {
+	info     Info
+	optional map[string]bool
+	tmpl     *template.Template
+}
+
+// funcs are the functions available in templates
+var funcs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join": func(items []any, sep string) string {
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, sep)
+	},
+	"default": func(fallback, value any) any {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// NewRegistry parses the templates. All errors are reported at once, so a
+// broken template fails at startup rather than when an alert is due.
+func NewRegistry(defs map[string]Definition) (*Registry, error) {
+	r := &Registry{templates: make(map[string]*entry, len(defs))}
+
+	var errs []error
+	for name, def := range defs {
+		if !namePattern.MatchString(name) {
+			errs = append(errs, fmt.Errorf("template %q: name must be 1-64 characters: lowercase letters, digits, '-' or '_'", name))
+			continue
+		}
+		format := bus.FormatType(def.Format)
+		if !slices.Contains([]bus.FormatType{bus.FormatTypePlain, bus.FormatTypeMarkdown, bus.FormatTypeHTML, bus.FormatTypeMarkdownV2}, format) {
+			errs = append(errs, fmt.Errorf("template %q: invalid format %q (expected: markdown, html, markdownv2)", name, def.Format))
+			continue
+		}
+		if strings.TrimSpace(def.Text) == "" {
+			errs = append(errs, fmt.Errorf("template %q: text is required", name))
+			continue
+		}
+		tmpl, err := template.New(name).Funcs(funcs).Parse(def.Text)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("template %q: %w", name, err))
+			continue
+		}
+		optional := make(map[string]bool, len(def.Optional))
+		for _, field := range def.Optional {
+			optional[field] = true
+		}
+		r.templates[name] = &entry{
+			info: Info{
+				Name:        name,
+				Description: def.Description,
+				Format:      format,
+				Fields:      fields(tmpl.Tree),
+				Optional:    slices.Sorted(maps.Keys(optional)),
+			},
+			optional: optional,
+			tmpl:     tmpl,
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return r, nil
+}
+
+// Render fills a template with data. Every field the template uses must be
+// present unless it is optional; missing optional fields are empty.
+func (r *Registry) Render(name string, data map[string]any) (Message, error) {
+	e, ok := r.templates[name]
+	if !ok {
+		return Message{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	values := make(map[string]any, len(e.info.Fields))
+	maps.Copy(values, data)
+	var missing []string
+	for _, field := range e.info.Fields {
+		if _, ok := values[field]; ok {
+			continue
+		}
+		if e.optional[field] {
+			values[field] = ""
+			continue
+		}
+		missing = append(missing, field)
+	}
+	if len(missing) > 0 {
+		return Message{}, fmt.Errorf("template %s is missing data fields: %s", name, strings.Join(missing, ", "))
+	}
+
+	var b strings.Builder
+	if err := e.tmpl.Execute(&b, values); err != nil {
+		return Message{}, fmt.Errorf("failed to render template %s (fields: %s): %w", name, strings.Join(e.info.Fields, ", "), err)
+	}
+	return Message{Text: b.String(), Format: e.info.Format}, nil
+}
+
+// List returns the registered templates sorted by name.
+func (r *Registry) List() []Info {
+	list := make([]Info, 0, len(r.templates))
+	for _, e := range r.templates {
+		info := e.info
+		info.Fields = slices.Clone(e.info.Fields)
+		info.Optional = slices.Clone(e.info.Optional)
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// fields returns the top-level data fields a template refers to
+// ({{.build}}, {{if .error}}, {{range .items}}, {{$.build}}). Fields inside
+// "range" and "with" refer to their value and are not data fields.
+func fields(tree *parse.Tree) []string {
+	seen := make(map[string]bool)
+	nested := false
+	var walk func(node parse.Node)
+	var walkNested func(list *parse.ListNode)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walkNested(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walkNested(n.List)
+			walk(n.ElseList)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.FieldNode:
+			if !nested {
+				seen[n.Ident[0]] = true
+			}
+		case *parse.VariableNode:
+			if n.Ident[0] == "$" && len(n.Ident) > 1 {
+				seen[n.Ident[1]] = true
+			}
+		}
+	}
+	// walkNested walks a block whose dot is not the data: only $.field counts
+	walkNested = func(list *parse.ListNode) {
+		wasNested := nested
+		nested = true
+		walk(list)
+		nested = wasNested
+	}
+	walk(tree.Root)
+
+	return slices.Sorted(maps.Keys(seen))
+}
@@ -0,0 +1,68 @@
+package msgtemplate
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+)
+
+func TestRegistry_Render(t *testing.T) {
+	r, err := NewRegistry(map[string]Definition{
+		"build_failed": {
+			Text:        "*Build {{.build}} failed*{{if .note}}\n{{.note}}{{end}}\nSteps: {{range .steps}}{{.}} {{end}}{{with .owner}}{{upper .}} for {{$.build}}{{end}}",
+			Format:      "markdown",
+			Description: "CI build failure",
+			Optional:    []string{"note"},
+		},
+		"report": {Text: "Disk usage: {{default \"n/a\" .usage}}, hosts: {{join .hosts \", \"}}"},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	list := r.List()
+	if len(list) != 2 || list[0].Name != "build_failed" || !slices.Equal(list[0].Fields, []string{"build", "note", "owner", "steps"}) {
+		t.Errorf("List() = %+v", list)
+	}
+
+	msg, err := r.Render("build_failed", map[string]any{"build": "#42", "steps": []any{"test", "lint"}, "owner": "ci"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if msg.Text != "*Build #42 failed*\nSteps: test lint CI for #42" || msg.Format != bus.FormatTypeMarkdown {
+		t.Errorf("Render() = %+v", msg)
+	}
+
+	msg, err = r.Render("report", map[string]any{"usage": "", "hosts": []any{"a", "b"}})
+	if err != nil || msg.Text != "Disk usage: n/a, hosts: a, b" {
+		t.Errorf("Render(report) = %+v, %v", msg, err)
+	}
+
+	if _, err := r.Render("build_failed", map[string]any{"build": "#42"}); err == nil || !strings.Contains(err.Error(), "owner, steps") {
+		t.Errorf("Render() with missing fields error = %v", err)
+	}
+	if _, err := r.Render("unknown", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Render(unknown) error = %v", err)
+	}
+}
+
+func TestNewRegistry_Invalid(t *testing.T) {
+	_, err := NewRegistry(map[string]Definition{
+		"Bad Name":   {Text: "x"},
+		"bad_format": {Text: "x", Format: "rtf"},
+		"empty":      {Text: " "},
+		"broken":     {Text: "{{.build"},
+		"ok":         {Text: "fine"},
+	})
+	if err == nil {
+		t.Fatal("NewRegistry() expected error")
+	}
+	for _, name := range []string{"Bad Name", "bad_format", "empty", "broken"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error doesn't mention %q: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+// Package netguard protects HTTP tools against SSRF: it blocks requests to
+// private networks and cloud metadata endpoints, enforces domain allow/deny
+// lists and verifies resolved addresses on every connection, including
+// connections made for redirects.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/egress"
+)
+
+// ErrBlocked is returned (wrapped) for requests rejected by the policy.
+var ErrBlocked = errors.New("blocked by network policy")
+
+// maxRedirects limits the number of redirects followed by Client.
+const maxRedirects = 10
+
+// metadataHosts are cloud metadata endpoints, blocked even when private networks are allowed.
+var metadataHosts = map[string]bool{
+	"metadata":                   true,
+	"metadata.google.internal":   true,
+	"metadata.azure.internal":    true,
+	"instance-data":              true,
+	"instance-data.ec2.internal": true,
+}
+
+// metadataAddrs are cloud metadata IP addresses, blocked even when private networks are allowed.
+var metadataAddrs = []netip.Addr{
+	netip.MustParseAddr("169.254.169.254"), // AWS, GCP, Azure, OpenStack
+	netip.MustParseAddr("169.254.170.2"),   // AWS ECS task metadata
+	netip.MustParseAddr("100.100.100.200"), // Alibaba Cloud
+	netip.MustParseAddr("fd00:ec2::254"),   // AWS IPv6
+}
+
+// privatePrefixes are non-public ranges not covered by netip.Addr helpers.
+var privatePrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // Reserved
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64 (may embed private IPv4)
+}
+
+// Resolver resolves host names to IP addresses.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// Policy decides which URLs and addresses HTTP tools may connect to.
+type Policy struct {
+	// AllowPrivate allows loopback, private, link-local and other non-public
+	// addresses. Metadata endpoints stay blocked.
+	AllowPrivate bool
+
+	// AllowedDomains restricts requests to these domains and their subdomains (empty allows all).
+	AllowedDomains []string
+
+	// DeniedDomains blocks these domains and their subdomains. Takes precedence over AllowedDomains.
+	DeniedDomains []string
+
+	// Resolver is used to resolve host names (the Network resolver or
+	// net.DefaultResolver if nil).
+	Resolver Resolver
+
+	// Network supplies the outbound proxy, CA bundle and DNS overrides (optional).
+	Network *egress.Network
+}
+
+// CheckURL verifies the scheme and host of a URL against the policy.
+// Host names are checked against the domain lists; IP literals are checked directly.
+// Resolved addresses are verified when connecting (see DialContext).
+func (p *Policy) CheckURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: unsupported scheme %q", ErrBlocked, u.Scheme)
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return fmt.Errorf("%w: empty host", ErrBlocked)
+	}
+	return p.checkHost(host)
+}
+
+// checkHost verifies a lowercase host name or IP literal.
+func (p *Policy) checkHost(host string) error {
+	if metadataHosts[host] {
+		return fmt.Errorf("%w: %s is a cloud metadata endpoint", ErrBlocked, host)
+	}
+	if matchDomain(host, p.DeniedDomains) {
+		return fmt.Errorf("%w: domain %s is denied", ErrBlocked, host)
+	}
+	if len(p.AllowedDomains) > 0 && !matchDomain(host, p.AllowedDomains) {
+		return fmt.Errorf("%w: domain %s is not in the allowed list", ErrBlocked, host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return p.CheckAddr(host, addr)
+	}
+	if !p.AllowPrivate && (host == "localhost" || strings.HasSuffix(host, ".localhost")) {
+		return fmt.Errorf("%w: %s is a local address", ErrBlocked, host)
+	}
+	return nil
+}
+
+// CheckAddr verifies an IP address that host resolved to.
+func (p *Policy) CheckAddr(host string, addr netip.Addr) error {
+	addr = addr.Unmap()
+	for _, metadata := range metadataAddrs {
+		if addr == metadata {
+			return fmt.Errorf("%w: %s resolves to cloud metadata address %s", ErrBlocked, host, addr)
+		}
+	}
+	if !p.AllowPrivate && !IsPublic(addr) {
+		return fmt.Errorf("%w: %s resolves to non-public address %s", ErrBlocked, host, addr)
+	}
+	return nil
+}
+
+// IsPublic reports whether addr is a globally routable unicast address.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range privatePrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return addr != netip.MustParseAddr("255.255.255.255")
+}
+
+// matchDomain reports whether host equals one of the domains or is a subdomain of one.
+// Domains may be written as "example.com" or "*.example.com".
+func matchDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(domain, "*."), "."))
+		if domain != "" && (host == domain || strings.HasSuffix(host, "."+domain)) {
+			return true
+		}
+	}
+	return false
+}
+
+// DialContext returns a dial function that resolves the host, verifies every
+// resolved address and connects to a verified address. Verifying at connection
+// time covers redirects and prevents DNS rebinding between check and use.
+func (p *Policy) DialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if p.Network != nil && address == p.Network.ProxyAddr() {
+			// The configured proxy is trusted; targets are verified in proxy.
+			return p.Network.DialContext(dialer)(ctx, network, address)
+		}
+
+		host = strings.ToLower(strings.TrimSuffix(host, "."))
+		addrs, err := p.resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var lastErr error
+		for _, addr := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		return nil, lastErr
+	}
+}
+
+// resolve checks host, resolves it and verifies every resolved address.
+func (p *Policy) resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	if err := p.checkHost(host); err != nil {
+		return nil, err
+	}
+
+	resolver := p.Resolver
+	if resolver == nil {
+		if p.Network != nil {
+			resolver = p.Network.Resolver()
+		} else {
+			resolver = net.DefaultResolver
+		}
+	}
+	addrs, err := resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+	for _, addr := range addrs {
+		if err := p.CheckAddr(host, addr); err != nil {
+			return nil, err
+		}
+	}
+	return addrs, nil
+}
+
+// proxy returns the Network proxy for req after verifying the target, since
+// the proxy connects to it on our behalf. Unless private networks are allowed,
+// the target host must resolve locally so that its addresses can be checked.
+func (p *Policy) proxy(req *http.Request) (*url.URL, error) {
+	proxyURL, err := p.Network.Proxy(req)
+	if err != nil || proxyURL == nil {
+		// Direct connections are verified in DialContext
+		return proxyURL, err
+	}
+	if err := p.CheckURL(req.URL); err != nil {
+		return nil, err
+	}
+	if !p.AllowPrivate {
+		host := strings.ToLower(strings.TrimSuffix(req.URL.Hostname(), "."))
+		if _, err := p.resolve(req.Context(), host); err != nil {
+			return nil, err
+		}
+	}
+	return proxyURL, nil
+}
+
+// CheckRedirect verifies each redirect target against the policy.
+func (p *Policy) CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	return p.CheckURL(req.URL)
+}
+
+// Client returns an HTTP client that enforces the policy on every connection.
+// Environment proxies are not used, since they would bypass address checks;
+// the Network proxy is used with targets verified before each request.
+// If followRedirects is false, the first redirect response is returned.
+func (p *Policy) Client(timeout time.Duration, followRedirects bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           p.DialContext(dialer),
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if p.Network != nil {
+		transport.TLSClientConfig = p.Network.TLSConfig()
+		if p.Network.ProxyAddr() != "" {
+			transport.Proxy = p.proxy
+		}
+	}
+
+	client := &http.Client{
+		Timeout:       timeout,
+		Transport:     transport,
+		CheckRedirect: p.CheckRedirect,
+	}
+	if !followRedirects {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	return client
+}
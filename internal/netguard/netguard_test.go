@@ -0,0 +1,151 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/egress"
+)
+
+type staticResolver map[string][]netip.Addr
+
+func (r staticResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	return r[host], nil
+}
+
+func TestIsPublic(t *testing.T) {
+	tests := map[string]bool{
+		"8.8.8.8":         true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"fc00::1":         false,
+		"fe80::1":         false,
+		"::ffff:10.0.0.1": false,
+		"224.0.0.1":       false,
+	}
+	for addr, want := range tests {
+		if got := IsPublic(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("IsPublic(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestPolicy_CheckURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		url     string
+		blocked bool
+	}{
+		{"public host", Policy{}, "https://example.com/page", false},
+		{"unsupported scheme", Policy{}, "file:///etc/passwd", true},
+		{"loopback literal", Policy{}, "http://127.0.0.1:8080/", true},
+		{"localhost", Policy{}, "http://localhost/", true},
+		{"private allowed", Policy{AllowPrivate: true}, "http://192.168.1.10/", false},
+		{"metadata address always blocked", Policy{AllowPrivate: true}, "http://169.254.169.254/latest/", true},
+		{"metadata host always blocked", Policy{AllowPrivate: true}, "http://metadata.google.internal/", true},
+		{"mapped IPv6 loopback", Policy{}, "http://[::ffff:127.0.0.1]/", true},
+		{"denied subdomain", Policy{DeniedDomains: []string{"evil.com"}}, "https://api.evil.com/", true},
+		{"allowed domain", Policy{AllowedDomains: []string{"*.github.com"}}, "https://api.github.com/", false},
+		{"outside allow list", Policy{AllowedDomains: []string{"github.com"}}, "https://notgithub.com/", true},
+		{"deny wins over allow", Policy{AllowedDomains: []string{"github.com"}, DeniedDomains: []string{"gist.github.com"}}, "https://gist.github.com/", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := url.Parse(tt.url)
+			err := tt.policy.CheckURL(u)
+			if (err != nil) != tt.blocked {
+				t.Fatalf("CheckURL(%s) error = %v, blocked %v", tt.url, err, tt.blocked)
+			}
+			if err != nil && !errors.Is(err, ErrBlocked) {
+				t.Errorf("Expected ErrBlocked, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPolicy_DialContext_VerifiesResolvedAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+
+	// A public-looking host that resolves to a loopback address (DNS rebinding)
+	resolver := staticResolver{"rebind.example": {netip.MustParseAddr("127.0.0.1")}}
+	target := "http://rebind.example:" + serverURL.Port() + "/"
+
+	blocking := &Policy{Resolver: resolver}
+	if _, err := blocking.Client(time.Second, true).Get(target); !errors.Is(err, ErrBlocked) {
+		t.Errorf("Expected connection to be blocked, got %v", err)
+	}
+
+	allowing := &Policy{AllowPrivate: true, Resolver: resolver}
+	resp, err := allowing.Client(time.Second, true).Get(target)
+	if err != nil {
+		t.Fatalf("Expected connection with private networks allowed, got %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestPolicy_CheckRedirect(t *testing.T) {
+	policy := &Policy{}
+	req, _ := http.NewRequest(http.MethodGet, "http://10.0.0.5/admin", nil)
+	if err := policy.CheckRedirect(req, []*http.Request{{}}); !errors.Is(err, ErrBlocked) {
+		t.Errorf("Expected redirect to a private address to be blocked, got %v", err)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "https://example.com/", nil)
+	if err := policy.CheckRedirect(req, make([]*http.Request, maxRedirects)); err == nil {
+		t.Error("Expected error after too many redirects")
+	}
+}
+
+func TestPolicy_Client_VerifiesTargetsBeforeProxy(t *testing.T) {
+	// A plain HTTP proxy on localhost; the proxy itself is trusted
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("proxied " + r.URL.Host))
+	}))
+	defer proxy.Close()
+
+	network, err := egress.New(egress.Config{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatalf("egress.New() error = %v", err)
+	}
+	policy := &Policy{
+		Network: network,
+		Resolver: staticResolver{
+			"public.example":   {netip.MustParseAddr("93.184.216.34")},
+			"internal.example": {netip.MustParseAddr("10.0.0.1")},
+		},
+	}
+	client := policy.Client(time.Second, true)
+
+	resp, err := client.Get("http://public.example/")
+	if err != nil {
+		t.Fatalf("Expected request through proxy, got %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "proxied public.example" {
+		t.Errorf("Body = %q, want response from proxy", body)
+	}
+
+	if _, err := client.Get("http://internal.example/"); !errors.Is(err, ErrBlocked) {
+		t.Errorf("Expected private target to be blocked before the proxy, got %v", err)
+	}
+}
@@ -0,0 +1,288 @@
+// Package onboarding welcomes new users: it handles /start with deep-link
+// payloads (invite codes, referral source), registers the user in the user
+// registry, sends the onboarding message sequence and asks consent questions.
+package onboarding
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/users"
+)
+
+const (
+	// StartCommand starts the onboarding; deep links pass the payload as its argument
+	StartCommand = "/start"
+
+	// CallbackPrefix marks callback data of consent buttons.
+	// Callback data format: "consent:<name>:yes" or "consent:<name>:no".
+	CallbackPrefix = "consent:"
+
+	// Payload format: parts separated by "__", e.g. "inv_team2026__ref_github"
+	payloadSeparator = "__"
+	invitePrefix     = "inv_"
+	sourcePrefix     = "ref_"
+
+	// NamePlaceholder is replaced with the first name of the user in onboarding messages
+	NamePlaceholder = "{name}"
+)
+
+// DefaultMessages is the onboarding sequence when none is configured.
+var DefaultMessages = []string{"👋 Welcome, {name}! Send me a message to get started."}
+
+// Payload is the parsed payload of a /start deep link.
+type Payload struct {
+	InviteCode string
+	Source     string
+}
+
+// ParsePayload parses a deep-link payload: "inv_<code>" is an invite code,
+// "ref_<source>" a referral source; several parts are joined with "__".
+// Any other payload is taken as the referral source.
+func ParsePayload(payload string) Payload {
+	var p Payload
+	for part := range strings.SplitSeq(strings.TrimSpace(payload), payloadSeparator) {
+		switch {
+		case part == "":
+		case strings.HasPrefix(part, invitePrefix):
+			p.InviteCode = strings.TrimPrefix(part, invitePrefix)
+		case strings.HasPrefix(part, sourcePrefix):
+			p.Source = strings.TrimPrefix(part, sourcePrefix)
+		case p.Source == "":
+			p.Source = part
+		}
+	}
+	return p
+}
+
+// Consent is a yes/no question whose answer is stored with the user.
+type Consent struct {
+	Name     string // Key of the answer in users.User.Consent
+	Question string
+	Required bool // Messages are held until the user agrees
+}
+
+// Config configures a Flow.
+type Config struct {
+	Messages    []string  // Onboarding sequence sent to new users (DefaultMessages if empty)
+	Consents    []Consent // Asked in order after the sequence
+	InviteCodes []string  // Valid invite codes; users who joined with one are allowed outside the whitelist
+}
+
+// Registry stores users (implemented by users.Registry).
+type Registry interface {
+	FindByIdentity(identity users.Identity) (users.User, bool)
+	Register(identity users.Identity, profile users.User) (users.User, bool, error)
+	SetConsent(userID, name string, granted bool) (users.User, error)
+}
+
+// Publisher sends onboarding messages.
+type Publisher interface {
+	PublishOutbound(msg bus.OutboundMessage) error
+}
+
+// Flow runs the onboarding of users. It is safe for concurrent use.
+type Flow struct {
+	cfg       Config
+	registry  Registry
+	publisher Publisher
+	logger    *logger.Logger
+}
+
+// New creates a Flow.
+func New(cfg Config, registry Registry, publisher Publisher, log *logger.Logger) *Flow {
+	if len(cfg.Messages) == 0 {
+		cfg.Messages = DefaultMessages
+	}
+	return &Flow{cfg: cfg, registry: registry, publisher: publisher, logger: log}
+}
+
+// ValidInvite reports whether an invite code is configured.
+func (f *Flow) ValidInvite(code string) bool {
+	return code != "" && slices.Contains(f.cfg.InviteCodes, code)
+}
+
+// Invited reports whether the user joined with an invite code that is still valid.
+func (f *Flow) Invited(identity users.Identity) bool {
+	u, ok := f.registry.FindByIdentity(identity)
+	return ok && f.ValidInvite(u.InviteCode)
+}
+
+// Start handles /start with the parsed deep-link payload in a private chat
+// (sessionID). New users get the onboarding sequence, returning users a short
+// greeting; then the first unanswered consent question is asked.
+func (f *Flow) Start(ctx context.Context, sessionID string, identity users.Identity, name string, p Payload) error {
+	if p.InviteCode != "" && !f.ValidInvite(p.InviteCode) {
+		f.logger.WarnCtx(ctx, "invalid invite code",
+			logger.Field{Key: "identity", Value: identity.String()},
+			logger.Field{Key: "invite_code", Value: p.InviteCode})
+		if err := f.send(sessionID, "⚠️ This invite link is not valid.", nil); err != nil {
+			return err
+		}
+		p.InviteCode = ""
+	}
+
+	u, created, err := f.registry.Register(identity, users.User{Name: name, Source: p.Source, InviteCode: p.InviteCode})
+	if err != nil {
+		return fmt.Errorf("failed to register user: %w", err)
+	}
+
+	if created {
+		f.logger.InfoCtx(ctx, "user registered",
+			logger.Field{Key: "user_id", Value: u.ID},
+			logger.Field{Key: "source", Value: u.Source},
+			logger.Field{Key: "invited", Value: u.InviteCode != ""})
+		for _, text := range f.cfg.Messages {
+			if err := f.send(sessionID, strings.ReplaceAll(text, NamePlaceholder, name), nil); err != nil {
+				return err
+			}
+		}
+	} else if _, pending := f.nextConsent(u); !pending {
+		return f.send(sessionID, "👋 Welcome back! Send me a message to continue.", nil)
+	}
+
+	return f.askNext(sessionID, u)
+}
+
+// Hold registers users who write without /start and reports whether their
+// message must be held because a required consent is not granted; the
+// question is then asked again.
+func (f *Flow) Hold(ctx context.Context, sessionID string, identity users.Identity, name string) bool {
+	u, ok := f.registry.FindByIdentity(identity)
+	if !ok {
+		registered, _, err := f.registry.Register(identity, users.User{Name: name})
+		if err != nil {
+			f.logger.ErrorCtx(ctx, "failed to register user", err,
+				logger.Field{Key: "identity", Value: identity.String()})
+			return false
+		}
+		u = registered
+	}
+
+	for _, c := range f.cfg.Consents {
+		if c.Required && !u.Consent[c.Name] {
+			if err := f.ask(sessionID, c); err != nil {
+				f.logger.ErrorCtx(ctx, "failed to ask consent question", err,
+					logger.Field{Key: "user_id", Value: u.ID})
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// AnswerCallback handles a consent button. It returns false if the callback
+// data is not a consent answer.
+func (f *Flow) AnswerCallback(ctx context.Context, sessionID string, identity users.Identity, data string) bool {
+	name, granted, ok := ParseCallback(data)
+	if !ok {
+		return false
+	}
+	idx := slices.IndexFunc(f.cfg.Consents, func(c Consent) bool { return c.Name == name })
+	if idx < 0 {
+		// Button of a consent question that is no longer configured
+		return true
+	}
+	consent := f.cfg.Consents[idx]
+
+	u, ok := f.registry.FindByIdentity(identity)
+	if !ok {
+		return true
+	}
+	u, err := f.registry.SetConsent(u.ID, name, granted)
+	if err != nil {
+		f.logger.ErrorCtx(ctx, "failed to store consent", err,
+			logger.Field{Key: "identity", Value: identity.String()},
+			logger.Field{Key: "consent", Value: name})
+		return true
+	}
+	f.logger.InfoCtx(ctx, "consent answered",
+		logger.Field{Key: "user_id", Value: u.ID},
+		logger.Field{Key: "consent", Value: name},
+		logger.Field{Key: "granted", Value: granted})
+
+	if !granted && consent.Required {
+		if err := f.send(sessionID, "Without this consent I can't answer your messages. Send "+StartCommand+" if you change your mind.", nil); err != nil {
+			f.logger.ErrorCtx(ctx, "failed to send consent reply", err)
+		}
+		return true
+	}
+
+	if _, pending := f.nextConsent(u); !pending {
+		if err := f.send(sessionID, "✅ You're all set. Send me a message to get started.", nil); err != nil {
+			f.logger.ErrorCtx(ctx, "failed to send consent reply", err)
+		}
+		return true
+	}
+	if err := f.askNext(sessionID, u); err != nil {
+		f.logger.ErrorCtx(ctx, "failed to ask consent question", err)
+	}
+	return true
+}
+
+// nextConsent returns the first consent question the user hasn't agreed to.
+// Declined optional questions count as answered.
+func (f *Flow) nextConsent(u users.User) (Consent, bool) {
+	for _, c := range f.cfg.Consents {
+		granted, answered := u.Consent[c.Name]
+		if !answered || (c.Required && !granted) {
+			return c, true
+		}
+	}
+	return Consent{}, false
+}
+
+// askNext asks the next consent question, if any.
+func (f *Flow) askNext(sessionID string, u users.User) error {
+	c, ok := f.nextConsent(u)
+	if !ok {
+		return nil
+	}
+	return f.ask(sessionID, c)
+}
+
+// ask sends a consent question with agree and decline buttons.
+func (f *Flow) ask(sessionID string, c Consent) error {
+	keyboard := &bus.InlineKeyboard{Rows: [][]bus.InlineButton{{
+		{Text: "✅ Agree", Data: CallbackData(c.Name, true)},
+		{Text: "✖ Decline", Data: CallbackData(c.Name, false)},
+	}}}
+	return f.send(sessionID, "🔒 "+c.Question, keyboard)
+}
+
+// send publishes a message to the chat of the session.
+func (f *Flow) send(sessionID, content string, keyboard *bus.InlineKeyboard) error {
+	channel, chatID, _ := strings.Cut(sessionID, ":")
+	msg := bus.NewOutboundMessageWithKeyboard(bus.ChannelType(channel), chatID, sessionID, content,
+		"", keyboard, bus.FormatTypePlain, nil)
+	if err := f.publisher.PublishOutbound(*msg); err != nil {
+		return fmt.Errorf("failed to send onboarding message: %w", err)
+	}
+	return nil
+}
+
+// CallbackData builds callback data for a consent button.
+func CallbackData(name string, granted bool) string {
+	answer := "no"
+	if granted {
+		answer = "yes"
+	}
+	return CallbackPrefix + name + ":" + answer
+}
+
+// ParseCallback extracts the consent name and answer from callback data.
+func ParseCallback(data string) (name string, granted bool, ok bool) {
+	rest, found := strings.CutPrefix(data, CallbackPrefix)
+	if !found {
+		return "", false, false
+	}
+	name, answer, ok := strings.Cut(rest, ":")
+	if !ok || name == "" || (answer != "yes" && answer != "no") {
+		return "", false, false
+	}
+	return name, answer == "yes", true
+}
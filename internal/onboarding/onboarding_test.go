@@ -0,0 +1,160 @@
+package onboarding
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/users"
+)
+
+// recordingPublisher collects published messages.
+type recordingPublisher struct {
+	mu   sync.Mutex
+	msgs []bus.OutboundMessage
+}
+
+func (p *recordingPublisher) PublishOutbound(msg bus.OutboundMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.msgs = append(p.msgs, msg)
+	return nil
+}
+
+func (p *recordingPublisher) take() []bus.OutboundMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	msgs := p.msgs
+	p.msgs = nil
+	return msgs
+}
+
+func newTestFlow(t *testing.T, cfg Config) (*Flow, *users.Registry, *recordingPublisher) {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("logger.New() error = %v", err)
+	}
+	registry := users.NewRegistry(t.TempDir())
+	if err := registry.Load(nil); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	pub := &recordingPublisher{}
+	return New(cfg, registry, pub, log), registry, pub
+}
+
+func TestParsePayload(t *testing.T) {
+	tests := []struct {
+		payload string
+		want    Payload
+	}{
+		{"", Payload{}},
+		{"inv_team2026", Payload{InviteCode: "team2026"}},
+		{"ref_github", Payload{Source: "github"}},
+		{"inv_team2026__ref_github", Payload{InviteCode: "team2026", Source: "github"}},
+		{"ref_x__inv_abc-1", Payload{InviteCode: "abc-1", Source: "x"}},
+		{"newsletter", Payload{Source: "newsletter"}},
+	}
+	for _, tt := range tests {
+		if got := ParsePayload(tt.payload); got != tt.want {
+			t.Errorf("ParsePayload(%q) = %+v, want %+v", tt.payload, got, tt.want)
+		}
+	}
+}
+
+func TestFlow_StartRegistersAndAsksConsent(t *testing.T) {
+	flow, registry, pub := newTestFlow(t, Config{
+		Messages:    []string{"Hi {name}!", "I can run commands and reminders."},
+		Consents:    []Consent{{Name: "history", Question: "Keep the history?", Required: true}},
+		InviteCodes: []string{"team2026"},
+	})
+	ctx := context.Background()
+	identity := users.Identity{Channel: "telegram", Address: "42"}
+
+	if err := flow.Start(ctx, "telegram:42", identity, "Alice", ParsePayload("inv_team2026__ref_github")); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	msgs := pub.take()
+	if len(msgs) != 3 || msgs[0].Content != "Hi Alice!" || msgs[0].UserID != "42" {
+		t.Fatalf("messages = %+v, want the sequence and a consent question", msgs)
+	}
+	question := msgs[2]
+	if question.InlineKeyboard == nil || question.InlineKeyboard.Rows[0][0].Data != "consent:history:yes" {
+		t.Errorf("consent question = %+v", question)
+	}
+
+	u, ok := registry.FindByIdentity(identity)
+	if !ok || u.Source != "github" || u.InviteCode != "team2026" || u.Name != "Alice" {
+		t.Fatalf("registered user = %+v", u)
+	}
+	if !flow.Invited(identity) {
+		t.Error("user who joined with a valid invite must be invited")
+	}
+
+	// Messages are held until the required consent is granted
+	if !flow.Hold(ctx, "telegram:42", identity, "Alice") {
+		t.Error("Hold() = false before consent")
+	}
+	pub.take()
+	if !flow.AnswerCallback(ctx, "telegram:42", identity, "consent:history:yes") {
+		t.Fatal("AnswerCallback() ignored a consent button")
+	}
+	if msgs := pub.take(); len(msgs) != 1 || !strings.Contains(msgs[0].Content, "all set") {
+		t.Errorf("reply = %+v", msgs)
+	}
+	if flow.Hold(ctx, "telegram:42", identity, "Alice") {
+		t.Error("Hold() = true after consent")
+	}
+
+	// A second /start greets the returning user
+	if err := flow.Start(ctx, "telegram:42", identity, "Alice", Payload{}); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if msgs := pub.take(); len(msgs) != 1 || !strings.Contains(msgs[0].Content, "Welcome back") {
+		t.Errorf("messages = %+v", msgs)
+	}
+}
+
+func TestFlow_InvalidInvite(t *testing.T) {
+	flow, registry, pub := newTestFlow(t, Config{InviteCodes: []string{"team2026"}})
+	identity := users.Identity{Channel: "telegram", Address: "42"}
+
+	if err := flow.Start(context.Background(), "telegram:42", identity, "Bob", ParsePayload("inv_guess")); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	msgs := pub.take()
+	if len(msgs) != 2 || !strings.Contains(msgs[0].Content, "not valid") || msgs[1].Content != "👋 Welcome, Bob! Send me a message to get started." {
+		t.Errorf("messages = %+v", msgs)
+	}
+	if u, _ := registry.FindByIdentity(identity); u.InviteCode != "" {
+		t.Errorf("invalid invite code stored: %q", u.InviteCode)
+	}
+	if flow.Invited(identity) {
+		t.Error("invalid invite must not grant access")
+	}
+}
+
+func TestFlow_HoldRegistersUnknownUsers(t *testing.T) {
+	flow, registry, pub := newTestFlow(t, Config{
+		Consents: []Consent{{Name: "analytics", Question: "Share usage stats?"}},
+	})
+	identity := users.Identity{Channel: "telegram", Address: "7"}
+
+	// Optional consents don't hold messages
+	if flow.Hold(context.Background(), "telegram:7", identity, "Carol") {
+		t.Error("Hold() = true without required consents")
+	}
+	if _, ok := registry.FindByIdentity(identity); !ok {
+		t.Error("user writing without /start was not registered")
+	}
+	if msgs := pub.take(); len(msgs) != 0 {
+		t.Errorf("messages = %+v", msgs)
+	}
+	if flow.AnswerCallback(context.Background(), "telegram:7", identity, "form:abc:0") {
+		t.Error("AnswerCallback() consumed a foreign callback")
+	}
+}
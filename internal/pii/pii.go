@@ -0,0 +1,137 @@
+// Package pii masks personal data (emails, phone numbers, credit card
+// numbers) in text before it is stored: session histories and memory files.
+package pii
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Categories of personal data.
+const (
+	CategoryEmail      = "email"
+	CategoryPhone      = "phone"
+	CategoryCreditCard = "credit_card"
+)
+
+// Categories lists all supported categories in the order they are applied.
+// Card numbers go before phone numbers, which would match them too.
+var Categories = []string{CategoryEmail, CategoryCreditCard, CategoryPhone}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+	cardPattern  = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+	phonePattern = regexp.MustCompile(`(?:\+\d{1,3}[ \-]?)?(?:\(\d{2,4}\)|\b\d{2,4})(?:[ \-]?\d{2,4}){2,4}\b`)
+
+	// datePattern matches dates that look like grouped phone numbers
+	datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+)
+
+// Scrubber replaces personal data of the enabled categories with a
+// "[<category>]" mark. A nil Scrubber leaves text unchanged.
+type Scrubber struct {
+	categories []string
+}
+
+// New creates a scrubber for the given categories.
+// An empty list enables all categories.
+func New(categories []string) (*Scrubber, error) {
+	if len(categories) == 0 {
+		return &Scrubber{categories: Categories}, nil
+	}
+
+	enabled := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		if !slices.Contains(Categories, category) {
+			return nil, fmt.Errorf("unknown PII category: %s (expected: %s)", category, strings.Join(Categories, ", "))
+		}
+		enabled[category] = true
+	}
+
+	s := &Scrubber{}
+	for _, category := range Categories {
+		if enabled[category] {
+			s.categories = append(s.categories, category)
+		}
+	}
+	return s, nil
+}
+
+// Scrub returns text with personal data masked.
+func (s *Scrubber) Scrub(text string) string {
+	if s == nil || text == "" {
+		return text
+	}
+	for _, category := range s.categories {
+		mark := "[" + category + "]"
+		switch category {
+		case CategoryEmail:
+			if strings.Contains(text, "@") {
+				text = emailPattern.ReplaceAllLiteralString(text, mark)
+			}
+		case CategoryCreditCard:
+			text = cardPattern.ReplaceAllStringFunc(text, func(match string) string {
+				if luhnValid(digits(match)) {
+					return mark
+				}
+				return match
+			})
+		case CategoryPhone:
+			text = phonePattern.ReplaceAllStringFunc(text, func(match string) string {
+				if isPhone(match) {
+					return mark
+				}
+				return match
+			})
+		}
+	}
+	return text
+}
+
+// isPhone filters phone pattern matches: 10 to 15 digits, written with a
+// country code or grouped by separators, and not a date.
+func isPhone(match string) bool {
+	n := len(digits(match))
+	if n < 10 || n > 15 {
+		return false
+	}
+	if strings.HasPrefix(match, "+") {
+		return true
+	}
+	return strings.ContainsAny(match, " -()") && !datePattern.MatchString(match)
+}
+
+// digits returns the digits of s.
+func digits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// luhnValid reports whether a number passes the Luhn checksum used by
+// payment cards.
+func luhnValid(number string) bool {
+	if len(number) < 13 || len(number) > 19 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
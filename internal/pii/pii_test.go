@@ -0,0 +1,54 @@
+package pii
+
+import "testing"
+
+func TestScrubber_Scrub(t *testing.T) {
+	s, err := New(nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"email", "write to alice.smith+news@mail.example.com please", "write to [email] please"},
+		{"international phone", "call +7 912 345-67-89 tomorrow", "call [phone] tomorrow"},
+		{"grouped phone", "office: (555) 123-4567", "office: [phone]"},
+		{"card", "card 4111 1111 1111 1111 exp 12/30", "card [credit_card] exp 12/30"},
+		{"card without separators", "4111111111111111", "[credit_card]"},
+		{"invalid checksum", "order 4111 1111 1111 1112", "order 4111 1111 1111 1112"},
+		{"date", "due 2026-10-18 10:00", "due 2026-10-18 10:00"},
+		{"ip address", "host 192.168.100.200", "host 192.168.100.200"},
+		{"plain id", "user 1234567890", "user 1234567890"},
+		{"year", "in 2026 we shipped 3 releases", "in 2026 we shipped 3 releases"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Scrub(tt.text); got != tt.want {
+				t.Errorf("Scrub(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrubber_Categories(t *testing.T) {
+	s, err := New([]string{CategoryEmail})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	text := "bob@example.com, +1 202 555 0143"
+	if got, want := s.Scrub(text), "[email], +1 202 555 0143"; got != want {
+		t.Errorf("Scrub() = %q, want %q", got, want)
+	}
+
+	if _, err := New([]string{"passport"}); err == nil {
+		t.Error("New() with an unknown category must fail")
+	}
+
+	var disabled *Scrubber
+	if got := disabled.Scrub(text); got != text {
+		t.Errorf("nil Scrub() = %q, want unchanged", got)
+	}
+}
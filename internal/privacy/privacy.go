@@ -0,0 +1,401 @@
+// Package privacy handles data subject requests: it exports everything the
+// bot stores about a user (sessions, session metadata, artifacts, exported
+// transcripts, feedback and the user registry entry) and erases it, leaving
+// a tombstone record of the deletion.
+package privacy
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/transcript"
+	"github.com/aatumaykin/nexbot/internal/artifacts"
+	"github.com/aatumaykin/nexbot/internal/cleanup"
+	"github.com/aatumaykin/nexbot/internal/feedback"
+	"github.com/aatumaykin/nexbot/internal/users"
+)
+
+const (
+	// Subdirectory is the subdirectory name for privacy records within workspace
+	Subdirectory = "privacy"
+
+	// TombstonesFilename is the filename of the deletion log
+	TombstonesFilename = "tombstones.jsonl"
+
+	// exportsSubdirectory holds transcripts exported with /export
+	exportsSubdirectory = "exports"
+)
+
+// Who requested an erasure
+const (
+	RequestedByUser  = "user"  // /forget_me in chat
+	RequestedByAdmin = "admin" // nexbot user purge
+)
+
+// ErrNotFound is returned for references that are neither a known user nor
+// a "channel:address" identity.
+var ErrNotFound = errors.New("user not found")
+
+// Sessions reads and erases session histories (implemented by session.Manager).
+type Sessions interface {
+	Files(sessionID string) []string
+	Erase(sessionID string) ([]string, error)
+}
+
+// Registry stores users (implemented by users.Registry).
+type Registry interface {
+	Get(userID string) (users.User, bool)
+	FindByIdentity(identity users.Identity) (users.User, bool)
+	Delete(userID string) error
+}
+
+// Subject is the person whose data is exported or erased.
+type Subject struct {
+	UserID     string
+	User       *users.User // Registry entry; nil for chats without a registered user
+	SessionIDs []string    // Chat sessions of the user ("channel:address")
+}
+
+// Inventory lists the data stored about a subject.
+type Inventory struct {
+	Subject  Subject
+	Files    []string // Workspace files holding the user's data
+	Feedback int      // Feedback entries of the user
+}
+
+// Empty reports whether nothing is stored about the subject.
+func (inv Inventory) Empty() bool {
+	return inv.Subject.User == nil && len(inv.Files) == 0 && inv.Feedback == 0
+}
+
+// Tombstone records an erasure without keeping personal data.
+type Tombstone struct {
+	Subject     string    `json:"subject"` // SHA-256 of the user ID
+	DeletedAt   time.Time `json:"deleted_at"`
+	RequestedBy string    `json:"requested_by"` // RequestedByUser or RequestedByAdmin
+	Sessions    int       `json:"sessions"`     // Erased session histories
+	Files       int       `json:"files"`        // Erased files, including session histories
+	Feedback    int       `json:"feedback"`     // Erased feedback entries
+	Exported    bool      `json:"exported"`     // The data was exported before the erasure
+}
+
+// Service exports and erases user data. It is safe for concurrent use.
+type Service struct {
+	workspace string
+	sessions  Sessions
+	registry  Registry
+	artifacts *artifacts.Store
+	feedback  *feedback.Store
+	now       func() time.Time
+
+	mu sync.Mutex // Serializes erasures and tombstone writes
+}
+
+// New creates a service for the workspace.
+func New(workspacePath string, sessions Sessions, registry Registry) *Service {
+	return &Service{
+		workspace: workspacePath,
+		sessions:  sessions,
+		registry:  registry,
+		artifacts: artifacts.NewStore(workspacePath),
+		feedback:  feedback.NewStore(workspacePath),
+		now:       time.Now,
+	}
+}
+
+// TombstonesPath returns the path of the deletion log.
+func (s *Service) TombstonesPath() string {
+	return filepath.Join(s.workspace, Subdirectory, TombstonesFilename)
+}
+
+// Resolve finds the subject for a user ID or a "channel:address" identity.
+// An identity without a registered user is a subject with a single chat session.
+func (s *Service) Resolve(ref string) (Subject, error) {
+	if u, ok := s.registry.Get(ref); ok {
+		return subjectOf(u), nil
+	}
+	identity, err := users.ParseIdentity(ref)
+	if err != nil {
+		return Subject{}, fmt.Errorf("%w: %s", ErrNotFound, ref)
+	}
+	if u, ok := s.registry.FindByIdentity(identity); ok {
+		return subjectOf(u), nil
+	}
+	if !validSessionID(identity.String()) {
+		return Subject{}, fmt.Errorf("%w: %s", ErrNotFound, ref)
+	}
+	return Subject{UserID: identity.String(), SessionIDs: []string{identity.String()}}, nil
+}
+
+// subjectOf returns the subject of a registered user.
+func subjectOf(u users.User) Subject {
+	subj := Subject{UserID: u.ID, User: &u}
+	for _, id := range u.Identities {
+		if validSessionID(id.String()) {
+			subj.SessionIDs = append(subj.SessionIDs, id.String())
+		}
+	}
+	return subj
+}
+
+// validSessionID reports whether an identity can name session files: it
+// must not escape the workspace directories.
+func validSessionID(sessionID string) bool {
+	return !strings.ContainsAny(sessionID, `/\`) && !strings.Contains(sessionID, "..")
+}
+
+// Inventory lists the data stored about a subject.
+func (s *Service) Inventory(subj Subject) (Inventory, error) {
+	inv := Inventory{Subject: subj}
+	for _, sessionID := range subj.SessionIDs {
+		files, err := s.sessionFiles(sessionID)
+		if err != nil {
+			return Inventory{}, err
+		}
+		inv.Files = append(inv.Files, files...)
+	}
+	slices.Sort(inv.Files)
+	inv.Files = slices.Compact(inv.Files)
+
+	entries, err := s.feedback.Load()
+	if err != nil {
+		return Inventory{}, err
+	}
+	for _, e := range entries {
+		if ownsFeedback(subj, e) {
+			inv.Feedback++
+		}
+	}
+	return inv, nil
+}
+
+// sessionFiles returns the files of a chat session: history and metadata,
+// archived history, artifacts and exported transcripts.
+func (s *Service) sessionFiles(sessionID string) ([]string, error) {
+	files := s.sessions.Files(sessionID)
+
+	archived := filepath.Join(s.workspace, cleanup.ArchiveSubdirectory, string(cleanup.ItemSession), sessionID+".jsonl")
+	if _, err := os.Stat(archived); err == nil {
+		files = append(files, archived)
+	}
+
+	for _, dir := range s.artifactDirs(sessionID) {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to list artifacts of %s: %w", sessionID, err)
+		}
+	}
+
+	exports, err := filepath.Glob(filepath.Join(s.workspace, exportsSubdirectory, transcript.SafeFileName(sessionID)+"-*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exports of %s: %w", sessionID, err)
+	}
+	return append(files, exports...), nil
+}
+
+// artifactDirs returns the current and archived artifact directories of a session.
+func (s *Service) artifactDirs(sessionID string) []string {
+	dir, err := s.artifacts.SessionDir(sessionID)
+	if err != nil {
+		return nil
+	}
+	return []string{dir, filepath.Join(s.workspace, cleanup.ArchiveSubdirectory, artifacts.Subdirectory, sessionID)}
+}
+
+// ownsFeedback reports whether a feedback entry belongs to the subject.
+func ownsFeedback(subj Subject, e feedback.Entry) bool {
+	if slices.Contains(subj.SessionIDs, e.SessionID) {
+		return true
+	}
+	return e.UserID != "" && e.Channel != "" && slices.Contains(subj.SessionIDs, e.Channel+":"+e.UserID)
+}
+
+// Export writes a zip archive with all data stored about the subject: the
+// files under their workspace paths, profile.json and feedback.jsonl.
+func (s *Service) Export(subj Subject, w io.Writer) error {
+	inv, err := s.Inventory(subj)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	for _, path := range inv.Files {
+		rel, err := filepath.Rel(s.workspace, path)
+		if err != nil {
+			rel = filepath.Base(path)
+		}
+		if err := addFile(zw, filepath.ToSlash(rel), path); err != nil {
+			return err
+		}
+	}
+
+	if subj.User != nil {
+		data, err := json.MarshalIndent(subj.User, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal profile: %w", err)
+		}
+		if err := addBytes(zw, "profile.json", data); err != nil {
+			return err
+		}
+	}
+
+	if inv.Feedback > 0 {
+		entries, err := s.feedback.Load()
+		if err != nil {
+			return err
+		}
+		var data []byte
+		for _, e := range entries {
+			if !ownsFeedback(subj, e) {
+				continue
+			}
+			line, err := json.Marshal(e)
+			if err != nil {
+				return fmt.Errorf("failed to marshal feedback: %w", err)
+			}
+			data = append(append(data, line...), '\n')
+		}
+		if err := addBytes(zw, "feedback.jsonl", data); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write export archive: %w", err)
+	}
+	return nil
+}
+
+// addFile copies a file into the archive.
+func addFile(zw *zip.Writer, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	dst, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to export archive: %w", name, err)
+	}
+	if _, err := io.Copy(dst, f); err != nil {
+		return fmt.Errorf("failed to add %s to export archive: %w", name, err)
+	}
+	return nil
+}
+
+// addBytes writes data into the archive.
+func addBytes(zw *zip.Writer, name string, data []byte) error {
+	dst, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to export archive: %w", name, err)
+	}
+	if _, err := dst.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to export archive: %w", name, err)
+	}
+	return nil
+}
+
+// Erase deletes all data stored about the subject and appends a tombstone
+// to the deletion log. exported records whether the data was exported first.
+func (s *Service) Erase(subj Subject, requestedBy string, exported bool) (Tombstone, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv, err := s.Inventory(subj)
+	if err != nil {
+		return Tombstone{}, err
+	}
+	ts := Tombstone{
+		Subject:     HashSubject(subj.UserID),
+		DeletedAt:   s.now().UTC(),
+		RequestedBy: requestedBy,
+		Files:       len(inv.Files),
+		Exported:    exported,
+	}
+
+	for _, sessionID := range subj.SessionIDs {
+		erased, err := s.sessions.Erase(sessionID)
+		if err != nil {
+			return Tombstone{}, err
+		}
+		ts.Sessions += len(erased)
+		for _, dir := range s.artifactDirs(sessionID) {
+			if err := os.RemoveAll(dir); err != nil {
+				return Tombstone{}, fmt.Errorf("failed to erase artifacts of %s: %w", sessionID, err)
+			}
+		}
+	}
+	// Whatever is left: archived histories and exported transcripts
+	for _, path := range inv.Files {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return Tombstone{}, fmt.Errorf("failed to erase %s: %w", path, err)
+		}
+	}
+
+	ts.Feedback, err = s.feedback.Remove(func(e feedback.Entry) bool { return ownsFeedback(subj, e) })
+	if err != nil {
+		return Tombstone{}, err
+	}
+
+	if subj.User != nil {
+		if err := s.registry.Delete(subj.User.ID); err != nil {
+			return Tombstone{}, err
+		}
+	}
+
+	if err := s.appendTombstone(ts); err != nil {
+		return Tombstone{}, err
+	}
+	return ts, nil
+}
+
+// appendTombstone writes a record to the deletion log. Caller must hold s.mu.
+func (s *Service) appendTombstone(ts Tombstone) error {
+	data, err := json.Marshal(ts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tombstone: %w", err)
+	}
+
+	path := s.TombstonesPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create privacy directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open tombstone log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write tombstone: %w", err)
+	}
+	return nil
+}
+
+// HashSubject returns the tombstone subject of a user ID, so a deletion
+// can be confirmed later without storing the ID itself.
+func HashSubject(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,221 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/feedback"
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/users"
+)
+
+// newTestService creates a service with a user who talked in a chat, has an
+// artifact, an exported transcript and feedback, next to another user's chat.
+func newTestService(t *testing.T) (*Service, string, *users.Registry) {
+	t.Helper()
+	ws := t.TempDir()
+
+	sessions, err := session.NewManager(filepath.Join(ws, "sessions"))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	for _, id := range []string{"telegram:1", "telegram:2"} {
+		sess, _, err := sessions.GetOrCreate(id)
+		if err != nil {
+			t.Fatalf("GetOrCreate() error = %v", err)
+		}
+		_ = sess.Append(llm.Message{Role: llm.RoleUser, Content: "hello from " + id})
+	}
+
+	registry := users.NewRegistry(ws)
+	if err := registry.Load(nil); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, err := registry.Link("alice", users.Identity{Channel: "telegram", Address: "1"}); err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+
+	writeFile(t, filepath.Join(ws, "artifacts", "telegram:1", "1", "report.csv"), "a,b")
+	writeFile(t, filepath.Join(ws, "exports", "telegram_1-20260501-100000.md"), "# transcript")
+	writeFile(t, filepath.Join(ws, "exports", "telegram_2-20260501-100000.md"), "# other transcript")
+
+	fb := feedback.NewStore(ws)
+	_ = fb.Append(feedback.Entry{SessionID: "telegram:1", Score: 1})
+	_ = fb.Append(feedback.Entry{SessionID: "telegram:2", Score: -1})
+
+	svc := New(ws, sessions, registry)
+	svc.now = func() time.Time { return time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC) }
+	return svc, ws, registry
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func TestService_Resolve(t *testing.T) {
+	svc, _, _ := newTestService(t)
+
+	for _, ref := range []string{"alice", "telegram:1"} {
+		subj, err := svc.Resolve(ref)
+		if err != nil || subj.UserID != "alice" || subj.User == nil {
+			t.Errorf("Resolve(%q) = %+v, %v", ref, subj, err)
+		}
+	}
+	if subj, err := svc.Resolve("telegram:2"); err != nil || subj.User != nil || subj.SessionIDs[0] != "telegram:2" {
+		t.Errorf("Resolve(unregistered chat) = %+v, %v", subj, err)
+	}
+	for _, ref := range []string{"bob", "telegram:../x"} {
+		if _, err := svc.Resolve(ref); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Resolve(%q) error = %v, want ErrNotFound", ref, err)
+		}
+	}
+}
+
+func TestService_ExportAndErase(t *testing.T) {
+	svc, ws, registry := newTestService(t)
+	subj, err := svc.Resolve("alice")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	inv, err := svc.Inventory(subj)
+	if err != nil {
+		t.Fatalf("Inventory() error = %v", err)
+	}
+	if len(inv.Files) != 3 || inv.Feedback != 1 {
+		t.Fatalf("Inventory() = %+v, want history, artifact, export and one feedback entry", inv)
+	}
+
+	var buf bytes.Buffer
+	if err := svc.Export(subj, &buf); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	got := strings.Join(names, ",")
+	for _, want := range []string{"sessions/telegram:1.jsonl", "artifacts/telegram:1/1/report.csv", "exports/telegram_1-20260501-100000.md", "profile.json", "feedback.jsonl"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("export archive = %s, missing %s", got, want)
+		}
+	}
+
+	ts, err := svc.Erase(subj, RequestedByAdmin, true)
+	if err != nil {
+		t.Fatalf("Erase() error = %v", err)
+	}
+	if ts.Subject != HashSubject("alice") || ts.Sessions != 1 || ts.Files != 3 || ts.Feedback != 1 || !ts.Exported {
+		t.Errorf("tombstone = %+v", ts)
+	}
+
+	if inv, _ := svc.Inventory(subj); len(inv.Files) != 0 || inv.Feedback != 0 {
+		t.Errorf("data left after Erase(): %+v", inv)
+	}
+	if _, ok := registry.Get("alice"); ok {
+		t.Error("user must be removed from the registry")
+	}
+	for _, path := range []string{"sessions/telegram:2.jsonl", "exports/telegram_2-20260501-100000.md"} {
+		if _, err := os.Stat(filepath.Join(ws, path)); err != nil {
+			t.Errorf("data of another user removed: %s", path)
+		}
+	}
+
+	// The tombstone doesn't contain the user ID
+	data, err := os.ReadFile(svc.TombstonesPath())
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var stored Tombstone
+	if err := json.Unmarshal(bytes.TrimSpace(data), &stored); err != nil || stored.RequestedBy != RequestedByAdmin {
+		t.Errorf("tombstone log = %s (%v)", data, err)
+	}
+	if bytes.Contains(data, []byte("alice")) || bytes.Contains(data, []byte("telegram:1")) {
+		t.Errorf("tombstone log contains personal data: %s", data)
+	}
+}
+
+func TestService_EraseContexts(t *testing.T) {
+	svc, ws, _ := newTestService(t)
+	sessions := svc.sessions.(*session.Manager)
+
+	// Two contexts of the user's chat, the chat back in its own history,
+	// and a context of another chat with a similar ID
+	for _, c := range []struct{ chat, name string }{
+		{"telegram:1", "work"},
+		{"telegram:1", "home"},
+		{"telegram:12", "work"},
+	} {
+		if _, err := sessions.SwitchContext(c.chat, c.name); err != nil {
+			t.Fatalf("SwitchContext() error = %v", err)
+		}
+		sess, _, err := sessions.GetOrCreate(c.chat)
+		if err != nil {
+			t.Fatalf("GetOrCreate() error = %v", err)
+		}
+		_ = sess.Append(llm.Message{Role: llm.RoleUser, Content: "hello from " + c.name})
+		if _, err := sessions.SwitchContext(c.chat, session.DefaultContext); err != nil {
+			t.Fatalf("SwitchContext() error = %v", err)
+		}
+	}
+
+	subj, err := svc.Resolve("alice")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := svc.Export(subj, &buf); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	got := strings.Join(names, ",")
+	for _, want := range []string{"sessions/telegram:1@work.jsonl", "sessions/telegram:1@home.jsonl"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("export archive = %s, missing %s", got, want)
+		}
+	}
+	if strings.Contains(got, "telegram:12") {
+		t.Errorf("export archive = %s, contains another chat", got)
+	}
+
+	ts, err := svc.Erase(subj, RequestedByUser, false)
+	if err != nil {
+		t.Fatalf("Erase() error = %v", err)
+	}
+	if ts.Sessions != 3 {
+		t.Errorf("tombstone sessions = %d, want the chat and its two contexts", ts.Sessions)
+	}
+	for _, name := range []string{"telegram:1@work.jsonl", "telegram:1@home.jsonl", "telegram:1.jsonl"} {
+		if _, err := os.Stat(filepath.Join(ws, "sessions", name)); !os.IsNotExist(err) {
+			t.Errorf("history left after Erase(): %s", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(ws, "sessions", "telegram:12@work.jsonl")); err != nil {
+		t.Errorf("context of another chat removed: %v", err)
+	}
+}
@@ -0,0 +1,326 @@
+// Package process manages long-running background processes started by the agent.
+// Jobs outlive a single agent turn: their output is kept in a ring buffer and
+// mirrored to <workspace>/processes/<id>.log, so the agent can start a dev
+// server in one turn and tail its logs in the next.
+package process
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+const (
+	// LogsSubdirectory is the subdirectory for job output logs within workspace
+	LogsSubdirectory = "processes"
+
+	// DefaultMaxJobs is used when Config.MaxJobs is not set
+	DefaultMaxJobs = 5
+
+	// DefaultOutputLines is used when Config.OutputLines is not set
+	DefaultOutputLines = 200
+
+	// DefaultStopTimeout is used when Config.StopTimeout is not set
+	DefaultStopTimeout = 5 * time.Second
+)
+
+// Status represents the state of a job.
+type Status string
+
+const (
+	StatusRunning Status = "running" // Process is running
+	StatusExited  Status = "exited"  // Process exited on its own
+	StatusStopped Status = "stopped" // Process was stopped via Stop
+	StatusFailed  Status = "failed"  // Process could not be waited for
+)
+
+// Config configures the process manager.
+type Config struct {
+	Workspace   string        // Working directory and base for log files
+	MaxJobs     int           // Maximum number of concurrently running jobs
+	OutputLines int           // Number of recent output lines kept in memory per job
+	StopTimeout time.Duration // Grace period between SIGTERM and SIGKILL
+}
+
+// Job is a snapshot of a background process.
+type Job struct {
+	ID        string     `json:"id"`
+	Command   string     `json:"command"`
+	SessionID string     `json:"session_id,omitempty"`
+	PID       int        `json:"pid"`
+	Status    Status     `json:"status"`
+	ExitCode  int        `json:"exit_code"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	LogPath   string     `json:"log_path"`
+}
+
+// job is the internal mutable job state.
+type job struct {
+	Job
+	cmd     *exec.Cmd
+	output  *ringBuffer
+	logFile *os.File
+	done    chan struct{}
+	stopped bool
+}
+
+// Manager starts, tracks and stops background processes.
+type Manager struct {
+	cfg    Config
+	logger *logger.Logger
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+// NewManager creates a new process manager.
+func NewManager(cfg Config, log *logger.Logger) *Manager {
+	if cfg.MaxJobs <= 0 {
+		cfg.MaxJobs = DefaultMaxJobs
+	}
+	if cfg.OutputLines <= 0 {
+		cfg.OutputLines = DefaultOutputLines
+	}
+	if cfg.StopTimeout <= 0 {
+		cfg.StopTimeout = DefaultStopTimeout
+	}
+	return &Manager{
+		cfg:    cfg,
+		logger: log,
+		jobs:   make(map[string]*job),
+	}
+}
+
+// Start launches a process in the workspace directory.
+// name and args are the already parsed and validated command; display is
+// the command as shown to the user.
+func (m *Manager) Start(name string, args []string, display, sessionID string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if n := m.runningLocked(); n >= m.cfg.MaxJobs {
+		return Job{}, fmt.Errorf("too many running jobs (%d/%d), stop one first", n, m.cfg.MaxJobs)
+	}
+
+	id := fmt.Sprintf("proc_%s", uuid.New().String()[:8])
+
+	logDir := filepath.Join(m.cfg.Workspace, LogsSubdirectory)
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return Job{}, fmt.Errorf("failed to create processes directory: %w", err)
+	}
+	logPath := filepath.Join(logDir, id+".log")
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to create job log: %w", err)
+	}
+
+	cmd := exec.Command(name, args...)
+	cmd.Dir = m.cfg.Workspace
+	// Own process group so Stop also terminates children (e.g. npm -> node)
+	setProcessGroup(cmd)
+
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	if err := cmd.Start(); err != nil {
+		_ = pw.Close()
+		_ = logFile.Close()
+		return Job{}, fmt.Errorf("failed to start process: %w", err)
+	}
+
+	j := &job{
+		Job: Job{
+			ID:        id,
+			Command:   display,
+			SessionID: sessionID,
+			PID:       cmd.Process.Pid,
+			Status:    StatusRunning,
+			StartedAt: time.Now(),
+			LogPath:   logPath,
+		},
+		cmd:     cmd,
+		output:  newRingBuffer(m.cfg.OutputLines),
+		logFile: logFile,
+		done:    make(chan struct{}),
+	}
+	m.jobs[id] = j
+
+	copied := make(chan struct{})
+	go m.copyOutput(j, pr, copied)
+	go m.wait(j, pw, copied)
+
+	if m.logger != nil {
+		m.logger.Info("background process started",
+			logger.Field{Key: "job_id", Value: id},
+			logger.Field{Key: "command", Value: display},
+			logger.Field{Key: "pid", Value: j.PID})
+	}
+
+	return j.Job, nil
+}
+
+// copyOutput reads process output line by line into the ring buffer and log file.
+func (m *Manager) copyOutput(j *job, r io.Reader, copied chan struct{}) {
+	defer close(copied)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		j.output.Add(line)
+		_, _ = fmt.Fprintln(j.logFile, line)
+	}
+	// Drain the rest (e.g. an over-long line) so the process never blocks on a full pipe
+	_, _ = io.Copy(io.Discard, r)
+}
+
+// wait waits for process exit and records the result.
+func (m *Manager) wait(j *job, pw *io.PipeWriter, copied chan struct{}) {
+	err := j.cmd.Wait()
+	_ = pw.Close()
+	<-copied
+
+	m.mu.Lock()
+	now := time.Now()
+	j.EndedAt = &now
+	exitErr, isExitErr := err.(*exec.ExitError)
+	switch {
+	case err != nil && !isExitErr:
+		j.Status = StatusFailed
+		j.ExitCode = -1
+	case j.stopped:
+		j.Status = StatusStopped
+	default:
+		j.Status = StatusExited
+	}
+	if isExitErr {
+		j.ExitCode = exitErr.ExitCode()
+	}
+	_ = j.logFile.Close()
+	m.mu.Unlock()
+
+	close(j.done)
+
+	if m.logger != nil {
+		m.logger.Info("background process finished",
+			logger.Field{Key: "job_id", Value: j.ID},
+			logger.Field{Key: "status", Value: j.Status},
+			logger.Field{Key: "exit_code", Value: j.ExitCode})
+	}
+}
+
+// List returns all jobs (running and finished) ordered by start time.
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		jobs = append(jobs, j.Job)
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].StartedAt.Before(jobs[k].StartedAt) })
+	return jobs
+}
+
+// Get returns a job snapshot by ID.
+func (m *Manager) Get(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("job not found: %s", id)
+	}
+	return j.Job, nil
+}
+
+// Output returns up to the last n lines of job output (n <= 0 returns all buffered lines).
+func (m *Manager) Output(id string, n int) ([]string, error) {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("job not found: %s", id)
+	}
+	return j.output.Last(n), nil
+}
+
+// Stop terminates a running job: asks its process group to exit (SIGTERM),
+// then kills it after the stop timeout.
+func (m *Manager) Stop(id string) error {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("job not found: %s", id)
+	}
+	if j.Status != StatusRunning {
+		m.mu.Unlock()
+		return fmt.Errorf("job %s is not running (status: %s)", id, j.Status)
+	}
+	j.stopped = true
+	pid := j.PID
+	m.mu.Unlock()
+
+	terminateGroup(pid)
+
+	select {
+	case <-j.done:
+	case <-time.After(m.cfg.StopTimeout):
+		killGroup(pid)
+		<-j.done
+	}
+
+	if m.logger != nil {
+		m.logger.Info("background process stopped",
+			logger.Field{Key: "job_id", Value: id})
+	}
+	return nil
+}
+
+// StopAll stops all running jobs. Used on application shutdown.
+func (m *Manager) StopAll() {
+	for _, j := range m.List() {
+		if j.Status == StatusRunning {
+			_ = m.Stop(j.ID)
+		}
+	}
+}
+
+// Forget removes a finished job from the list. Its log file is kept.
+func (m *Manager) Forget(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return fmt.Errorf("job not found: %s", id)
+	}
+	if j.Status == StatusRunning {
+		return fmt.Errorf("job %s is still running", id)
+	}
+	delete(m.jobs, id)
+	return nil
+}
+
+func (m *Manager) runningLocked() int {
+	n := 0
+	for _, j := range m.jobs {
+		if j.Status == StatusRunning {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,95 @@
+package process
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager(t *testing.T, cfg Config) *Manager {
+	t.Helper()
+	cfg.Workspace = t.TempDir()
+	m := NewManager(cfg, nil)
+	t.Cleanup(m.StopAll)
+	return m
+}
+
+func waitStatus(t *testing.T, m *Manager, id string, status Status) Job {
+	t.Helper()
+	var job Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = m.Get(id)
+		return err == nil && job.Status == status
+	}, 5*time.Second, 20*time.Millisecond)
+	return job
+}
+
+func TestManager_StartAndOutput(t *testing.T) {
+	m := newTestManager(t, Config{})
+
+	job, err := m.Start("sh", []string{"-c", "echo one; echo two >&2; exit 3"}, "demo", "telegram:1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusRunning, job.Status)
+	assert.Equal(t, "telegram:1", job.SessionID)
+
+	job = waitStatus(t, m, job.ID, StatusExited)
+	assert.Equal(t, 3, job.ExitCode)
+	assert.NotNil(t, job.EndedAt)
+
+	out, err := m.Output(job.ID, 0)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"one", "two"}, out)
+
+	data, err := os.ReadFile(job.LogPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "one")
+}
+
+func TestManager_Stop(t *testing.T) {
+	m := newTestManager(t, Config{StopTimeout: time.Second})
+
+	job, err := m.Start("sleep", []string{"30"}, "sleep 30", "")
+	require.NoError(t, err)
+
+	require.NoError(t, m.Stop(job.ID))
+	job = waitStatus(t, m, job.ID, StatusStopped)
+
+	assert.Error(t, m.Stop(job.ID), "stopping a finished job should fail")
+	require.NoError(t, m.Forget(job.ID))
+	assert.Empty(t, m.List())
+}
+
+func TestManager_MaxJobs(t *testing.T) {
+	m := newTestManager(t, Config{MaxJobs: 1})
+
+	_, err := m.Start("sleep", []string{"30"}, "sleep 30", "")
+	require.NoError(t, err)
+
+	_, err = m.Start("sleep", []string{"30"}, "sleep 30", "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "too many running jobs")
+}
+
+func TestManager_UnknownJob(t *testing.T) {
+	m := newTestManager(t, Config{})
+
+	_, err := m.Output("missing", 10)
+	assert.Error(t, err)
+	assert.Error(t, m.Stop("missing"))
+}
+
+func TestRingBuffer(t *testing.T) {
+	b := newRingBuffer(3)
+	assert.Empty(t, b.Last(0))
+
+	for i := 1; i <= 5; i++ {
+		b.Add(strings.Repeat("x", i))
+	}
+	assert.Equal(t, []string{"xxx", "xxxx", "xxxxx"}, b.Last(0))
+	assert.Equal(t, []string{"xxxxx"}, b.Last(1))
+}
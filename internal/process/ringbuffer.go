@@ -0,0 +1,44 @@
+package process
+
+import "sync"
+
+// ringBuffer keeps the last N lines of output.
+type ringBuffer struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{lines: make([]string, size)}
+}
+
+// Add appends a line, overwriting the oldest one when full.
+func (b *ringBuffer) Add(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lines[b.next] = line
+	b.next = (b.next + 1) % len(b.lines)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// Last returns up to n most recent lines in order (n <= 0 returns all).
+func (b *ringBuffer) Last(n int) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var all []string
+	if b.full {
+		all = append(all, b.lines[b.next:]...)
+	}
+	all = append(all, b.lines[:b.next]...)
+
+	if n > 0 && n < len(all) {
+		all = all[len(all)-n:]
+	}
+	return all
+}
@@ -0,0 +1,90 @@
+package projects
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// DefaultMaxBriefChars limits the length of a brief when no limit is configured
+const DefaultMaxBriefChars = 2000
+
+// briefMessageRunes truncates each message sent for a brief update
+const briefMessageRunes = 1000
+
+const briefPrompt = "You maintain the brief of a project the user works on with an assistant across many conversations. " +
+	"Update the current brief with the new conversation below. Keep what is still true: the goal of the project, " +
+	"decisions made and why, files, paths, hosts and commands involved, open tasks. Drop what the conversation " +
+	"made outdated and everything that doesn't matter for future work on the project. " +
+	"Reply with the updated brief only, as short Markdown bullet points in the language of the conversation, " +
+	"at most %d characters."
+
+// BriefRequest builds an LLM request that updates the brief of a project
+// with new messages. Only user and assistant messages with text are sent.
+func BriefRequest(p Project, messages []llm.Message, model string, maxChars int) llm.ChatRequest {
+	if maxChars <= 0 {
+		maxChars = DefaultMaxBriefChars
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Project: %s\n\nCurrent brief:\n", p.Name)
+	if strings.TrimSpace(p.Brief) == "" {
+		b.WriteString("(empty)\n")
+	} else {
+		b.WriteString(p.Brief + "\n")
+	}
+	b.WriteString("\nNew conversation:\n\n")
+	for _, msg := range messages {
+		if (msg.Role != llm.RoleUser && msg.Role != llm.RoleAssistant) || strings.TrimSpace(msg.Content) == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n\n", msg.Role, truncateRunes(strings.TrimSpace(msg.Content), briefMessageRunes))
+	}
+
+	return llm.ChatRequest{
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: fmt.Sprintf(briefPrompt, maxChars)},
+			{Role: llm.RoleUser, Content: b.String()},
+		},
+		Model:       model,
+		Temperature: 0.2,
+		// About three characters per token, with room for the bullet markup
+		MaxTokens: maxChars/3 + 100,
+	}
+}
+
+// CleanBrief normalizes a generated brief: strips a "Brief:" heading and a
+// code fence around it, and limits its length.
+func CleanBrief(s string, maxChars int) string {
+	if maxChars <= 0 {
+		maxChars = DefaultMaxBriefChars
+	}
+
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "```markdown")
+	s = strings.TrimPrefix(s, "```")
+	s = strings.TrimSuffix(s, "```")
+	s = strings.TrimSpace(s)
+	if first, rest, found := strings.Cut(s, "\n"); found {
+		heading := strings.ToLower(strings.Trim(first, "#*: "))
+		if heading == "brief" || heading == "updated brief" || heading == "project brief" {
+			s = strings.TrimSpace(rest)
+		}
+	}
+	return truncateRunes(s, maxChars)
+}
+
+// truncateRunes shortens s to at most n runes, cutting at a line end when
+// possible and adding an ellipsis when cut.
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	cut := string(runes[:n-1])
+	if i := strings.LastIndex(cut, "\n"); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "…"
+}
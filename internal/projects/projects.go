@@ -0,0 +1,259 @@
+// Package projects groups conversations into named projects. Chats are
+// attached to a project; the project keeps a brief (a short summary of its
+// goals, decisions, files and open tasks) that is maintained automatically
+// from the conversations and added to the system prompt of every attached
+// chat, so a new session on the same project starts with its context.
+package projects
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ProjectsSubdirectory is the subdirectory name for projects within workspace
+	ProjectsSubdirectory = "projects"
+
+	// ProjectsFilename is the filename of the project store
+	ProjectsFilename = "projects.json"
+)
+
+var (
+	// ErrInvalidName is returned for project names that are not allowed
+	ErrInvalidName = errors.New("project name must be 1-64 characters: lowercase letters, digits, '-' or '_'")
+
+	// ErrNotFound is returned for unknown projects
+	ErrNotFound = errors.New("project not found")
+
+	projectNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+)
+
+// Project is a named group of conversations with a shared brief.
+type Project struct {
+	Name           string    `json:"name"`
+	Brief          string    `json:"brief,omitempty"`
+	Sessions       []string  `json:"sessions,omitempty"` // Attached chat sessions ("channel:chat_id")
+	CreatedAt      time.Time `json:"created_at"`
+	BriefUpdatedAt time.Time `json:"brief_updated_at,omitzero"`
+}
+
+// NormalizeName lowercases and validates a project name.
+func NormalizeName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !projectNamePattern.MatchString(name) {
+		return "", ErrInvalidName
+	}
+	return name, nil
+}
+
+// Store keeps projects and the chats attached to them. It is safe for concurrent use.
+type Store struct {
+	filePath string
+
+	mu       sync.RWMutex
+	projects map[string]*Project
+}
+
+// NewStore creates a store persisted at <workspace>/projects/projects.json.
+func NewStore(workspacePath string) *Store {
+	return &Store{
+		filePath: filepath.Join(workspacePath, ProjectsSubdirectory, ProjectsFilename),
+		projects: make(map[string]*Project),
+	}
+}
+
+// Load reads stored projects.
+func (s *Store) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read projects: %w", err)
+	}
+
+	var stored []Project
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to parse projects: %w", err)
+	}
+	for i := range stored {
+		p := stored[i]
+		s.projects[p.Name] = &p
+	}
+	return nil
+}
+
+// Attach attaches a chat to a project, creating the project if needed.
+// A chat belongs to at most one project, so it is detached from its previous
+// one. Reports whether the project was created.
+func (s *Store) Attach(sessionID, name string) (Project, bool, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return Project{}, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.snapshotLocked()
+	s.detachLocked(sessionID)
+
+	p, ok := s.projects[name]
+	created := !ok
+	if created {
+		p = &Project{Name: name, CreatedAt: time.Now()}
+		s.projects[name] = p
+	}
+	p.Sessions = append(p.Sessions, sessionID)
+
+	if err := s.saveLocked(); err != nil {
+		s.projects = previous
+		return Project{}, false, err
+	}
+	return copyProject(p), created, nil
+}
+
+// Detach detaches a chat from its project. Returns the name of the project,
+// or "" if the chat wasn't attached. The project and its brief are kept.
+func (s *Store) Detach(sessionID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.snapshotLocked()
+	name := s.detachLocked(sessionID)
+	if name == "" {
+		return "", nil
+	}
+	if err := s.saveLocked(); err != nil {
+		s.projects = previous
+		return "", err
+	}
+	return name, nil
+}
+
+// ForSession returns the project a chat is attached to.
+func (s *Store) ForSession(sessionID string) (Project, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, p := range s.projects {
+		if slices.Contains(p.Sessions, sessionID) {
+			return copyProject(p), true
+		}
+	}
+	return Project{}, false
+}
+
+// Get returns a project by name.
+func (s *Store) Get(name string) (Project, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.projects[name]
+	if !ok {
+		return Project{}, false
+	}
+	return copyProject(p), true
+}
+
+// List returns all projects sorted by name.
+func (s *Store) List() []Project {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]Project, 0, len(s.projects))
+	for _, p := range s.projects {
+		list = append(list, copyProject(p))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// SetBrief replaces the brief of a project.
+func (s *Store) SetBrief(name, brief string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.projects[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	previous := *p
+	p.Brief = brief
+	p.BriefUpdatedAt = time.Now()
+
+	if err := s.saveLocked(); err != nil {
+		*p = previous
+		return err
+	}
+	return nil
+}
+
+// detachLocked removes a chat from its project and returns the project
+// name, or "". Caller must hold s.mu.
+func (s *Store) detachLocked(sessionID string) string {
+	for _, p := range s.projects {
+		if i := slices.Index(p.Sessions, sessionID); i >= 0 {
+			p.Sessions = slices.Delete(p.Sessions, i, i+1)
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// snapshotLocked returns a copy of the projects to restore when saving
+// fails. Caller must hold s.mu.
+func (s *Store) snapshotLocked() map[string]*Project {
+	snapshot := make(map[string]*Project, len(s.projects))
+	for name, p := range s.projects {
+		c := copyProject(p)
+		snapshot[name] = &c
+	}
+	return snapshot
+}
+
+// saveLocked persists the store. Caller must hold s.mu.
+func (s *Store) saveLocked() error {
+	list := make([]Project, 0, len(s.projects))
+	for _, p := range s.projects {
+		list = append(list, *p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	if err := os.MkdirAll(filepath.Dir(s.filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create projects directory: %w", err)
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal projects: %w", err)
+	}
+
+	// Write to temp file first, then rename for atomicity
+	tmpPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write projects: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.filePath); err != nil {
+		return fmt.Errorf("failed to save projects: %w", err)
+	}
+	return nil
+}
+
+// copyProject returns a copy of a project that doesn't share its sessions.
+func copyProject(p *Project) Project {
+	c := *p
+	c.Sessions = slices.Clone(p.Sessions)
+	return c
+}
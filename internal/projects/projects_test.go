@@ -0,0 +1,113 @@
+package projects
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+func TestStore_AttachDetach(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+
+	p, created, err := store.Attach("telegram:1", " Homelab ")
+	if err != nil {
+		t.Fatalf("Attach() error = %v", err)
+	}
+	if !created || p.Name != "homelab" || len(p.Sessions) != 1 {
+		t.Errorf("Attach() = %+v, created %v", p, created)
+	}
+
+	// A second chat joins the project; a chat belongs to one project only
+	if _, created, _ := store.Attach("email:me@example.com", "homelab"); created {
+		t.Error("Attach() created an existing project")
+	}
+	if _, _, err := store.Attach("telegram:1", "blog"); err != nil {
+		t.Fatalf("Attach() error = %v", err)
+	}
+	homelab, _ := store.Get("homelab")
+	if len(homelab.Sessions) != 1 || homelab.Sessions[0] != "email:me@example.com" {
+		t.Errorf("homelab sessions = %v", homelab.Sessions)
+	}
+	if p, ok := store.ForSession("telegram:1"); !ok || p.Name != "blog" {
+		t.Errorf("ForSession() = %+v, %v", p, ok)
+	}
+
+	if _, _, err := store.Attach("telegram:1", "no spaces"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("Attach() invalid name error = %v", err)
+	}
+
+	if err := store.SetBrief("homelab", "- nginx on 10.0.0.2"); err != nil {
+		t.Fatalf("SetBrief() error = %v", err)
+	}
+	if err := store.SetBrief("missing", "x"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SetBrief() unknown project error = %v", err)
+	}
+
+	name, err := store.Detach("telegram:1")
+	if err != nil || name != "blog" {
+		t.Errorf("Detach() = %q, %v", name, err)
+	}
+	if name, _ := store.Detach("telegram:1"); name != "" {
+		t.Errorf("Detach() of a detached chat = %q", name)
+	}
+
+	// Projects and briefs are persisted
+	reloaded := NewStore(dir)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	list := reloaded.List()
+	if len(list) != 2 || list[0].Name != "blog" || list[1].Brief != "- nginx on 10.0.0.2" || list[1].BriefUpdatedAt.IsZero() {
+		t.Errorf("List() after reload = %+v", list)
+	}
+	if _, ok := reloaded.ForSession("email:me@example.com"); !ok {
+		t.Error("attachment not persisted")
+	}
+}
+
+func TestBriefRequest(t *testing.T) {
+	p := Project{Name: "homelab", Brief: "- nginx on 10.0.0.2"}
+	req := BriefRequest(p, []llm.Message{
+		{Role: llm.RoleUser, Content: "Move nginx to port 8080"},
+		{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{{ID: "1", Name: "shell_exec"}}},
+		{Role: llm.RoleTool, Content: "secret tool output", ToolCallID: "1"},
+		{Role: llm.RoleAssistant, Content: "Done, nginx listens on 8080."},
+	}, "model", 500)
+
+	if req.Model != "model" || len(req.Messages) != 2 {
+		t.Fatalf("BriefRequest() = %+v", req)
+	}
+	if !strings.Contains(req.Messages[0].Content, "at most 500 characters") {
+		t.Errorf("system prompt = %q", req.Messages[0].Content)
+	}
+	content := req.Messages[1].Content
+	for _, want := range []string{"Project: homelab", "- nginx on 10.0.0.2", "user: Move nginx to port 8080", "assistant: Done"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("request misses %q: %q", want, content)
+		}
+	}
+	if strings.Contains(content, "secret tool output") {
+		t.Error("tool results sent for a brief update")
+	}
+}
+
+func TestCleanBrief(t *testing.T) {
+	tests := []struct {
+		in    string
+		limit int
+		want  string
+	}{
+		{"- a\n- b", 100, "- a\n- b"},
+		{"## Updated brief\n- a", 100, "- a"},
+		{"```markdown\n- a\n```", 100, "- a"},
+		{"- first line\n- second line", 20, "- first line…"},
+	}
+	for _, tt := range tests {
+		if got := CleanBrief(tt.in, tt.limit); got != tt.want {
+			t.Errorf("CleanBrief(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
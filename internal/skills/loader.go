@@ -2,6 +2,7 @@ package skills
 
 import (
 	"fmt"
+	"io/fs"
 	"maps"
 	"os"
 	"path/filepath"
@@ -15,6 +16,7 @@ import (
 type Loader struct {
 	workspace          *workspace.Workspace
 	builtinDir         string
+	builtinFS          fs.FS
 	parser             *Parser
 	searcher           SkillSearcher
 	cache              map[string]*Skill
@@ -28,6 +30,7 @@ type Loader struct {
 type LoaderConfig struct {
 	Workspace    *workspace.Workspace
 	BuiltinDir   string
+	BuiltinFS    fs.FS // Embedded builtin skills (bundle builds); BuiltinDir overrides them
 	CacheEnabled bool
 }
 
@@ -36,6 +39,7 @@ func NewLoader(cfg LoaderConfig) *Loader {
 	loader := &Loader{
 		workspace:    cfg.Workspace,
 		builtinDir:   cfg.BuiltinDir,
+		builtinFS:    cfg.BuiltinFS,
 		parser:       NewParser(),
 		cache:        make(map[string]*Skill),
 		cacheEnabled: cfg.CacheEnabled,
@@ -62,11 +66,16 @@ func (l *Loader) Load() (map[string]*Skill, error) {
 		l.workspaceSkillsDir = l.workspace.Subpath(workspace.SubdirSkills)
 	}
 
-	// Load builtin skills
+	// Load builtin skills: embedded first, then the builtin directory
+	embeddedSkills, err := l.loadFS(l.builtinFS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load embedded skills: %w", err)
+	}
 	builtinSkills, err := l.loadDirectory(l.builtinDir, "builtin")
 	if err != nil {
 		return nil, fmt.Errorf("failed to load builtin skills: %w", err)
 	}
+	builtinSkills = l.mergeSkills(embeddedSkills, builtinSkills)
 
 	// Load workspace skills
 	var workspaceSkills map[string]*Skill
@@ -218,6 +227,46 @@ func (l *Loader) loadDirectory(dirPath, source string) (map[string]*Skill, error
 	return skills, nil
 }
 
+// loadFS loads all SKILL.md files from an embedded file system. FilePath of
+// the skills is the path inside the file system.
+func (l *Loader) loadFS(fsys fs.FS) (map[string]*Skill, error) {
+	skills := make(map[string]*Skill)
+	if fsys == nil {
+		return skills, nil
+	}
+
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != "SKILL.md" {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return fmt.Errorf("failed to read skill file %s: %w", path, err)
+		}
+		skill, err := l.parser.Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("failed to load skill from %s: %w", path, err)
+		}
+		skill.FilePath = path
+
+		if existing, exists := skills[skill.Metadata.Name]; exists {
+			return fmt.Errorf("duplicate skill name '%s' in %s (already defined in %s)",
+				skill.Metadata.Name, path, existing.FilePath)
+		}
+		skills[skill.Metadata.Name] = skill
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return skills, nil
+}
+
 // mergeSkills merges skills from builtin and workspace sources.
 // Workspace skills take priority over builtin skills.
 func (l *Loader) mergeSkills(builtin, workspace map[string]*Skill) map[string]*Skill {
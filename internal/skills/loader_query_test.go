@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 )
 
 func TestLoader_Get(t *testing.T) {
@@ -403,3 +404,32 @@ This skill commits changes.
 		t.Errorf("Expected 1 result for 'GIT', got %d", len(results))
 	}
 }
+
+func TestLoader_BuiltinFS(t *testing.T) {
+	embedded := fstest.MapFS{
+		"examples/embedded/SKILL.md": {Data: []byte("---\nname: embedded\ndescription: Embedded skill\n---\n\nEmbedded.\n")},
+		"examples/shared/SKILL.md":   {Data: []byte("---\nname: shared\ndescription: Embedded version\n---\n\nEmbedded.\n")},
+	}
+
+	builtinDir := filepath.Join(t.TempDir(), "skills")
+	if err := os.MkdirAll(filepath.Join(builtinDir, "shared"), 0755); err != nil {
+		t.Fatalf("Failed to create builtin directory: %v", err)
+	}
+	override := "---\nname: shared\ndescription: Directory version\n---\n\nOverride.\n"
+	if err := os.WriteFile(filepath.Join(builtinDir, "shared", "SKILL.md"), []byte(override), 0644); err != nil {
+		t.Fatalf("Failed to write skill: %v", err)
+	}
+
+	loader := NewLoader(LoaderConfig{BuiltinFS: embedded, BuiltinDir: builtinDir})
+	skills, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if skill := skills["embedded"]; skill == nil || skill.FilePath != "examples/embedded/SKILL.md" {
+		t.Errorf("embedded skill = %+v", skill)
+	}
+	if skill := skills["shared"]; skill == nil || skill.Metadata.Description != "Directory version" {
+		t.Errorf("builtin directory should override embedded skills, got %+v", skill)
+	}
+}
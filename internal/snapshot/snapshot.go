@@ -0,0 +1,451 @@
+// Package snapshot keeps the workspace state from before each agent turn, so
+// /rollback can undo everything the agent changed in a turn. Before a file
+// tool writes or deletes a path, the turn copies the original content to
+// <workspace>/snapshots/<session>/<turn>/; rolling the turn back restores
+// the copies and removes the files the turn created.
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Subdirectory is the workspace subdirectory holding snapshots
+	Subdirectory = "snapshots"
+
+	// DefaultMaxTurns is the number of turns per session that can be rolled back
+	DefaultMaxTurns = 5
+
+	// DefaultMaxFileSize is the size of the largest file kept in a snapshot
+	DefaultMaxFileSize = 10 << 20
+
+	// manifestFilename lists the saved paths of a turn
+	manifestFilename = "manifest.json"
+
+	// filesDir holds the copies of the saved files
+	filesDir = "files"
+
+	// turnIDFormat names turn directories so they sort by time
+	turnIDFormat = "20060102-150405.000000000"
+)
+
+var (
+	// ErrNothingToRollback is returned when a session has no turns with changes.
+	ErrNothingToRollback = errors.New("no changes to roll back")
+
+	// ErrInvalidSession is returned for session IDs that are not usable as a directory name.
+	ErrInvalidSession = errors.New("invalid session ID")
+)
+
+// Config configures a Store. Zero values use the defaults.
+type Config struct {
+	MaxTurns    int   // Turns per session that can be rolled back; older ones are dropped
+	MaxFileSize int64 // Larger files are not copied and can't be restored
+}
+
+// Entry is the state of a path before the turn changed it.
+type Entry struct {
+	Path    string      `json:"path"`
+	Existed bool        `json:"existed"`
+	Dir     bool        `json:"dir,omitempty"`
+	Link    string      `json:"link,omitempty"`    // Target of a symbolic link
+	Mode    fs.FileMode `json:"mode,omitempty"`    // Permissions of the file or directory
+	Backup  string      `json:"backup,omitempty"`  // Name of the copy in files/
+	Skipped bool        `json:"skipped,omitempty"` // Larger than MaxFileSize, not copied
+}
+
+// Result describes a rolled back turn.
+type Result struct {
+	Time      time.Time // When the turn changed the first file
+	Restored  int       // Files and directories restored
+	Removed   int       // Paths created by the turn that were removed
+	Skipped   []string  // Files too large to restore
+	Remaining int       // Turns that can still be rolled back
+}
+
+// manifest is the index of a turn.
+type manifest struct {
+	CreatedAt time.Time `json:"created_at"`
+	Entries   []Entry   `json:"entries"`
+}
+
+// Store keeps the snapshots of turns per session. It is safe for concurrent use.
+type Store struct {
+	mu   sync.Mutex
+	root string
+	cfg  Config
+	now  func() time.Time
+}
+
+// NewStore creates a snapshot store in the workspace.
+func NewStore(workspacePath string, cfg Config) *Store {
+	if cfg.MaxTurns <= 0 {
+		cfg.MaxTurns = DefaultMaxTurns
+	}
+	if cfg.MaxFileSize <= 0 {
+		cfg.MaxFileSize = DefaultMaxFileSize
+	}
+	return &Store{
+		root: filepath.Join(workspacePath, Subdirectory),
+		cfg:  cfg,
+		now:  time.Now,
+	}
+}
+
+// sessionDir returns the snapshot directory of a session.
+func (s *Store) sessionDir(sessionID string) (string, error) {
+	if sessionID == "" || sessionID == "." || sessionID == ".." || strings.ContainsAny(sessionID, `/\`) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidSession, sessionID)
+	}
+	return filepath.Join(s.root, sessionID), nil
+}
+
+// Begin starts a turn of a session. Nothing is stored until the turn saves a path.
+func (s *Store) Begin(sessionID string) *Turn {
+	return &Turn{store: s, sessionID: sessionID, seen: make(map[string]bool)}
+}
+
+// Turn records the original state of the paths changed during one agent turn.
+type Turn struct {
+	store     *Store
+	sessionID string
+
+	dir      string // Created on the first save
+	manifest manifest
+	seen     map[string]bool
+}
+
+// Save records the state of paths before they are changed. Paths saved
+// earlier in the turn are skipped, so the turn keeps the state from before
+// its first change. Directories are saved with everything in them.
+func (t *Turn) Save(paths ...string) error {
+	s := t.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []Entry
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		if !filepath.IsAbs(path) || t.seen[path] || within(path, s.root) {
+			continue
+		}
+		saved, err := t.capture(path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, saved...)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if err := t.ensureDir(); err != nil {
+		return err
+	}
+	for i := range entries {
+		entry := &entries[i]
+		if !entry.Existed || entry.Dir || entry.Link != "" {
+			continue
+		}
+		info, err := os.Stat(entry.Path)
+		if err != nil {
+			return fmt.Errorf("failed to access %s: %w", entry.Path, err)
+		}
+		if info.Size() > s.cfg.MaxFileSize {
+			entry.Skipped = true
+			continue
+		}
+		entry.Backup = strconv.Itoa(len(t.manifest.Entries) + i)
+		if err := copyFile(entry.Path, filepath.Join(t.dir, filesDir, entry.Backup)); err != nil {
+			return fmt.Errorf("failed to save %s: %w", entry.Path, err)
+		}
+	}
+
+	t.manifest.Entries = append(t.manifest.Entries, entries...)
+	if err := writeManifest(t.dir, t.manifest); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		t.seen[entry.Path] = true
+	}
+	return nil
+}
+
+// capture returns the entries of a path: the path itself and, for
+// directories, everything inside that was not saved yet.
+func (t *Turn) capture(path string) ([]Entry, error) {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return []Entry{{Path: path}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to access %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		entry, err := existing(path, info)
+		return []Entry{entry}, err
+	}
+
+	var entries []Entry
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if within(p, t.store.root) {
+			return filepath.SkipDir
+		}
+		if t.seen[p] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entry, err := existing(p, info)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// existing returns the entry of an existing path.
+func existing(path string, info fs.FileInfo) (Entry, error) {
+	entry := Entry{Path: path, Existed: true, Mode: info.Mode().Perm()}
+	switch {
+	case info.IsDir():
+		entry.Dir = true
+	case info.Mode()&fs.ModeSymlink != 0:
+		link, err := os.Readlink(path)
+		if err != nil {
+			return Entry{}, fmt.Errorf("failed to read link %s: %w", path, err)
+		}
+		entry.Link = link
+	}
+	return entry, nil
+}
+
+// ensureDir creates the directory of the turn and drops the oldest turns
+// beyond MaxTurns. Must be called with the store's mu held.
+func (t *Turn) ensureDir() error {
+	if t.dir != "" {
+		return nil
+	}
+	sessionDir, err := t.store.sessionDir(t.sessionID)
+	if err != nil {
+		return err
+	}
+
+	now := t.store.now()
+	dir := filepath.Join(sessionDir, now.UTC().Format(turnIDFormat))
+	if err := os.MkdirAll(filepath.Join(dir, filesDir), 0700); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	t.dir = dir
+	t.manifest.CreatedAt = now
+
+	turns, err := listTurns(sessionDir)
+	if err != nil {
+		return err
+	}
+	for len(turns) > t.store.cfg.MaxTurns {
+		if err := os.RemoveAll(filepath.Join(sessionDir, turns[0])); err != nil {
+			return fmt.Errorf("failed to remove old snapshot: %w", err)
+		}
+		turns = turns[1:]
+	}
+	return nil
+}
+
+// Rollback undoes the latest turn of a session that changed files: saved
+// files and directories are restored, paths the turn created are removed.
+func (s *Store) Rollback(sessionID string) (Result, error) {
+	sessionDir, err := s.sessionDir(sessionID)
+	if err != nil {
+		return Result{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	turns, err := listTurns(sessionDir)
+	if err != nil {
+		return Result{}, err
+	}
+	if len(turns) == 0 {
+		return Result{}, ErrNothingToRollback
+	}
+	dir := filepath.Join(sessionDir, turns[len(turns)-1])
+	m, err := readManifest(dir)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Time: m.CreatedAt, Remaining: len(turns) - 1}
+	// Created paths are removed first, deepest first
+	for _, entry := range slices.Backward(m.Entries) {
+		if entry.Existed {
+			continue
+		}
+		if _, err := os.Lstat(entry.Path); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.RemoveAll(entry.Path); err != nil {
+			return Result{}, fmt.Errorf("failed to remove %s: %w", entry.Path, err)
+		}
+		result.Removed++
+	}
+	// Saved paths are restored parents first, in the order they were saved
+	for _, entry := range m.Entries {
+		if !entry.Existed {
+			continue
+		}
+		if entry.Skipped {
+			result.Skipped = append(result.Skipped, entry.Path)
+			continue
+		}
+		if err := restore(dir, entry); err != nil {
+			return Result{}, err
+		}
+		result.Restored++
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return Result{}, fmt.Errorf("failed to remove snapshot: %w", err)
+	}
+	return result, nil
+}
+
+// restore puts a saved path back.
+func restore(dir string, entry Entry) error {
+	if entry.Dir {
+		if err := os.MkdirAll(entry.Path, entry.Mode); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", entry.Path, err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(entry.Path), 0755); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", entry.Path, err)
+	}
+	// A directory may have replaced the file
+	if info, err := os.Lstat(entry.Path); err == nil && (info.IsDir() || entry.Link != "") {
+		if err := os.RemoveAll(entry.Path); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", entry.Path, err)
+		}
+	}
+	if entry.Link != "" {
+		if err := os.Symlink(entry.Link, entry.Path); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", entry.Path, err)
+		}
+		return nil
+	}
+	if err := copyFile(filepath.Join(dir, filesDir, entry.Backup), entry.Path); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", entry.Path, err)
+	}
+	if err := os.Chmod(entry.Path, entry.Mode); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", entry.Path, err)
+	}
+	return nil
+}
+
+// Turns returns the number of turns of a session that can be rolled back.
+func (s *Store) Turns(sessionID string) int {
+	sessionDir, err := s.sessionDir(sessionID)
+	if err != nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	turns, _ := listTurns(sessionDir)
+	return len(turns)
+}
+
+// listTurns returns the turn directories of a session, oldest first.
+func listTurns(sessionDir string) ([]string, error) {
+	dirEntries, err := os.ReadDir(sessionDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	var turns []string
+	for _, d := range dirEntries {
+		if d.IsDir() {
+			turns = append(turns, d.Name())
+		}
+	}
+	slices.Sort(turns)
+	return turns, nil
+}
+
+// readManifest reads the manifest of a turn.
+func readManifest(dir string) (manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestFilename))
+	if err != nil {
+		return manifest{}, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return manifest{}, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	return m, nil
+}
+
+// writeManifest atomically writes the manifest of a turn.
+func writeManifest(dir string, m manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	tmp := filepath.Join(dir, manifestFilename+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, manifestFilename)); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// copyFile copies the content of src to dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// within reports whether path is dir or inside it.
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,193 @@
+package snapshot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeFile creates a file with content, creating its directory.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
+
+// readFile returns the content of a file, "<missing>" if it doesn't exist.
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "<missing>"
+	}
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	return string(data)
+}
+
+func TestStore_Rollback(t *testing.T) {
+	ws := t.TempDir()
+	store := NewStore(ws, Config{})
+	notes := filepath.Join(ws, "notes.txt")
+	created := filepath.Join(ws, "new", "draft.md")
+	writeFile(t, notes, "original")
+
+	turn := store.Begin("telegram:1")
+	if err := turn.Save(notes); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	writeFile(t, notes, "changed once")
+	// The second change of the turn keeps the state from before the first
+	if err := turn.Save(notes, created); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	writeFile(t, notes, "changed twice")
+	writeFile(t, created, "draft")
+
+	result, err := store.Rollback("telegram:1")
+	if err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if result.Restored != 1 || result.Removed != 1 || result.Remaining != 0 || len(result.Skipped) != 0 {
+		t.Errorf("Rollback() = %+v", result)
+	}
+	if got := readFile(t, notes); got != "original" {
+		t.Errorf("notes.txt = %q, want original", got)
+	}
+	if got := readFile(t, created); got != "<missing>" {
+		t.Errorf("draft.md = %q, want it removed", got)
+	}
+
+	if _, err := store.Rollback("telegram:1"); !errors.Is(err, ErrNothingToRollback) {
+		t.Errorf("second Rollback() error = %v, want ErrNothingToRollback", err)
+	}
+}
+
+func TestStore_Rollback_DeletedDirectory(t *testing.T) {
+	ws := t.TempDir()
+	store := NewStore(ws, Config{})
+	logs := filepath.Join(ws, "logs")
+	writeFile(t, filepath.Join(logs, "a.log"), "a")
+	writeFile(t, filepath.Join(logs, "old", "b.log"), "b")
+
+	if err := store.Begin("telegram:1").Save(logs); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := os.RemoveAll(logs); err != nil {
+		t.Fatalf("Failed to delete directory: %v", err)
+	}
+
+	result, err := store.Rollback("telegram:1")
+	if err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if result.Restored != 4 {
+		t.Errorf("Restored = %d, want 4 (2 directories, 2 files)", result.Restored)
+	}
+	if readFile(t, filepath.Join(logs, "a.log")) != "a" || readFile(t, filepath.Join(logs, "old", "b.log")) != "b" {
+		t.Error("Expected the deleted files to be restored")
+	}
+}
+
+func TestStore_Rollback_TurnsInOrder(t *testing.T) {
+	ws := t.TempDir()
+	store := NewStore(ws, Config{MaxTurns: 2})
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	path := filepath.Join(ws, "todo.txt")
+	writeFile(t, path, "v0")
+
+	// Three turns change the file; only the last two are kept
+	for _, content := range []string{"v1", "v2", "v3"} {
+		if err := store.Begin("telegram:1").Save(path); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		writeFile(t, path, content)
+		now = now.Add(time.Minute)
+	}
+	if got := store.Turns("telegram:1"); got != 2 {
+		t.Fatalf("Turns() = %d, want 2", got)
+	}
+
+	result, err := store.Rollback("telegram:1")
+	if err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if got := readFile(t, path); got != "v2" || result.Remaining != 1 {
+		t.Errorf("after first rollback: content %q, remaining %d", got, result.Remaining)
+	}
+	if _, err := store.Rollback("telegram:1"); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if got := readFile(t, path); got != "v1" {
+		t.Errorf("after second rollback: content %q, want v1", got)
+	}
+}
+
+func TestStore_Rollback_SkipsLargeFiles(t *testing.T) {
+	ws := t.TempDir()
+	store := NewStore(ws, Config{MaxFileSize: 4})
+	path := filepath.Join(ws, "big.bin")
+	writeFile(t, path, "too large")
+
+	if err := store.Begin("telegram:1").Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	writeFile(t, path, "changed")
+
+	result, err := store.Rollback("telegram:1")
+	if err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0] != path || result.Restored != 0 {
+		t.Errorf("Rollback() = %+v", result)
+	}
+	if got := readFile(t, path); got != "changed" {
+		t.Errorf("big.bin = %q, want it unchanged", got)
+	}
+}
+
+func TestTurn_Save_IgnoresSnapshots(t *testing.T) {
+	ws := t.TempDir()
+	store := NewStore(ws, Config{})
+	writeFile(t, filepath.Join(ws, "a.txt"), "a")
+
+	// Saving the whole workspace does not copy the snapshots into themselves
+	turn := store.Begin("telegram:1")
+	if err := turn.Save(filepath.Join(ws, "a.txt")); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := turn.Save(ws, filepath.Join(ws, Subdirectory)); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	for _, entry := range turn.manifest.Entries {
+		if within(entry.Path, store.root) {
+			t.Errorf("Saved a path inside the snapshots: %s", entry.Path)
+		}
+	}
+
+	// Nothing saved, nothing stored
+	if err := store.Begin("telegram:2").Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if got := store.Turns("telegram:2"); got != 0 {
+		t.Errorf("Turns() = %d, want 0", got)
+	}
+}
+
+func TestStore_InvalidSession(t *testing.T) {
+	store := NewStore(t.TempDir(), Config{})
+	if _, err := store.Rollback("../x"); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("Rollback() error = %v, want ErrInvalidSession", err)
+	}
+	if err := store.Begin("..").Save(filepath.Join(t.TempDir(), "a")); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("Save() error = %v, want ErrInvalidSession", err)
+	}
+}
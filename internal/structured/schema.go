@@ -0,0 +1,338 @@
+package structured
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidSchema is returned for schemas the validator cannot use.
+var ErrInvalidSchema = errors.New("invalid schema")
+
+// schemaTypes are the JSON Schema types known to the validator.
+var schemaTypes = []string{"object", "array", "string", "number", "integer", "boolean", "null"}
+
+// CheckSchema reports whether a schema uses only the supported keywords with
+// valid values: type, properties, required, additionalProperties, items,
+// enum, const, anyOf, minimum, maximum, minLength, maxLength, pattern,
+// minItems and maxItems. Other keywords (description, format, ...) are ignored.
+func CheckSchema(schema map[string]any) error {
+	return checkSchema(schema, "$")
+}
+
+func checkSchema(schema map[string]any, path string) error {
+	if t, ok := schema["type"]; ok {
+		types, ok := typeList(t)
+		if !ok {
+			return fmt.Errorf("%w: %s: type must be a string or a list of strings", ErrInvalidSchema, path)
+		}
+		for _, name := range types {
+			if !slices.Contains(schemaTypes, name) {
+				return fmt.Errorf("%w: %s: unknown type %q", ErrInvalidSchema, path, name)
+			}
+		}
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("%w: %s: invalid pattern: %v", ErrInvalidSchema, path, err)
+		}
+	}
+	if properties, ok := schema["properties"]; ok {
+		props, ok := properties.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%w: %s: properties must be an object", ErrInvalidSchema, path)
+		}
+		for name, prop := range props {
+			sub, ok := prop.(map[string]any)
+			if !ok {
+				return fmt.Errorf("%w: %s.%s: schema must be an object", ErrInvalidSchema, path, name)
+			}
+			if err := checkSchema(sub, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	if items, ok := schema["items"]; ok {
+		sub, ok := items.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%w: %s: items must be an object", ErrInvalidSchema, path)
+		}
+		if err := checkSchema(sub, path+"[]"); err != nil {
+			return err
+		}
+	}
+	if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+		if err := checkSchema(additional, path+".*"); err != nil {
+			return err
+		}
+	}
+	if anyOf, ok := schema["anyOf"]; ok {
+		list, ok := anyList(anyOf)
+		if !ok {
+			return fmt.Errorf("%w: %s: anyOf must be a list", ErrInvalidSchema, path)
+		}
+		for i, option := range list {
+			sub, ok := option.(map[string]any)
+			if !ok {
+				return fmt.Errorf("%w: %s: anyOf[%d] must be an object", ErrInvalidSchema, path, i)
+			}
+			if err := checkSchema(sub, path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks a decoded JSON value (as produced by encoding/json) against
+// a schema. All violations are returned, each prefixed with the JSON path of
+// the value ("$.items[0].name: ...").
+func Validate(schema map[string]any, value any) []string {
+	var problems []string
+	validate(schema, value, "$", &problems)
+	return problems
+}
+
+func validate(schema map[string]any, value any, path string, problems *[]string) {
+	report := func(format string, args ...any) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if t, ok := schema["type"]; ok {
+		types, _ := typeList(t)
+		if !slices.ContainsFunc(types, func(name string) bool { return hasType(value, name) }) {
+			report("expected %s, got %s", strings.Join(types, " or "), typeName(value))
+			return
+		}
+	}
+
+	if enum, ok := anyList(schema["enum"]); ok && !slices.ContainsFunc(enum, func(v any) bool { return equal(v, value) }) {
+		report("must be one of %s", formatValues(enum))
+	}
+	if c, ok := schema["const"]; ok && !equal(c, value) {
+		report("must be %v", c)
+	}
+
+	if anyOf, ok := anyList(schema["anyOf"]); ok {
+		matched := false
+		for _, option := range anyOf {
+			sub, _ := option.(map[string]any)
+			var subProblems []string
+			validate(sub, value, path, &subProblems)
+			if len(subProblems) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			report("does not match any of the allowed schemas")
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		validateObject(schema, v, path, problems)
+	case []any:
+		if minItems, ok := number(schema["minItems"]); ok && float64(len(v)) < minItems {
+			report("must have at least %v items", minItems)
+		}
+		if maxItems, ok := number(schema["maxItems"]); ok && float64(len(v)) > maxItems {
+			report("must have at most %v items", maxItems)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				validate(items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if minLength, ok := number(schema["minLength"]); ok && length < minLength {
+			report("must be at least %v characters", minLength)
+		}
+		if maxLength, ok := number(schema["maxLength"]); ok && length > maxLength {
+			report("must be at most %v characters", maxLength)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				report("must match pattern %s", pattern)
+			}
+		}
+	case float64:
+		if minimum, ok := number(schema["minimum"]); ok && v < minimum {
+			report("must be >= %v", minimum)
+		}
+		if maximum, ok := number(schema["maximum"]); ok && v > maximum {
+			report("must be <= %v", maximum)
+		}
+	}
+}
+
+// validateObject checks required, properties and additionalProperties.
+func validateObject(schema map[string]any, object map[string]any, path string, problems *[]string) {
+	for _, name := range stringList(schema["required"]) {
+		if _, ok := object[name]; !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: missing required property %q", path, name))
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if prop, ok := properties[name].(map[string]any); ok {
+			validate(prop, object[name], path+"."+name, problems)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*problems = append(*problems, fmt.Sprintf("%s: unexpected property %q", path, name))
+			}
+		case map[string]any:
+			validate(additional, object[name], path+"."+name, problems)
+		}
+	}
+}
+
+// typeList returns the types of a "type" keyword.
+func typeList(t any) ([]string, bool) {
+	switch v := t.(type) {
+	case string:
+		return []string{v}, true
+	case []string:
+		return v, true
+	case []any:
+		types := make([]string, 0, len(v))
+		for _, item := range v {
+			name, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			types = append(types, name)
+		}
+		return types, true
+	}
+	return nil, false
+}
+
+// anyList returns the items of a list keyword (enum, anyOf), decoded from
+// JSON or built in Go.
+func anyList(v any) ([]any, bool) {
+	switch list := v.(type) {
+	case []any:
+		return list, true
+	case []string:
+		items := make([]any, len(list))
+		for i, item := range list {
+			items[i] = item
+		}
+		return items, true
+	case []map[string]any:
+		items := make([]any, len(list))
+		for i, item := range list {
+			items[i] = item
+		}
+		return items, true
+	}
+	return nil, false
+}
+
+// stringList returns the strings of a "required" keyword.
+func stringList(v any) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []any:
+		names := make([]string, 0, len(list))
+		for _, item := range list {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// hasType reports whether a decoded JSON value has a JSON Schema type.
+func hasType(value any, name string) bool {
+	switch name {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		v, ok := value.(float64)
+		return ok && v == math.Trunc(v)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return false
+}
+
+// typeName returns the JSON type of a decoded value for error messages.
+func typeName(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// number returns a numeric keyword value.
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// equal compares a schema value with a decoded JSON value.
+func equal(schemaValue, value any) bool {
+	if n, ok := number(schemaValue); ok {
+		v, isNumber := value.(float64)
+		return isNumber && v == n
+	}
+	return fmt.Sprint(schemaValue) == fmt.Sprint(value)
+}
+
+// formatValues formats enum values for error messages.
+func formatValues(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprintf("%v", v)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,178 @@
+// Package structured asks the LLM for machine-readable answers: the request
+// carries a JSON response format (enforced by providers that support it),
+// the schema is also given in the prompt, and every reply is parsed and
+// validated locally. Invalid replies are sent back to the model with the
+// validation errors until the output matches or the retries run out.
+package structured
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+const (
+	// DefaultMaxRetries is the number of corrections asked after an invalid reply
+	DefaultMaxRetries = 2
+
+	// DefaultName names schemas without a name
+	DefaultName = "response"
+
+	jsonPrompt   = "Reply with a single JSON value only: no prose, no code fences."
+	schemaPrompt = jsonPrompt + " The value must match this JSON Schema:\n%s"
+	retryPrompt  = "Your reply is not valid:\n- %s\nReply with the corrected JSON only."
+)
+
+// ErrNoValidOutput is returned when no reply matched the schema.
+var ErrNoValidOutput = errors.New("no valid structured output")
+
+// Config configures a Generator.
+type Config struct {
+	Model       string // Default model (Request.Model overrides it)
+	MaxRetries  int    // Corrections after an invalid reply (DefaultMaxRetries if 0, negative disables)
+	MaxTokens   int    // Maximum tokens of a reply
+	Temperature float64
+}
+
+// Generator produces JSON answers validated against a schema.
+type Generator struct {
+	cfg      Config
+	provider llm.Provider
+}
+
+// New creates a Generator.
+func New(provider llm.Provider, cfg Config) *Generator {
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	return &Generator{cfg: cfg, provider: provider}
+}
+
+// Request is a request for a structured answer.
+type Request struct {
+	Prompt   string         // The task, sent as the last user message
+	Messages []llm.Message  // Earlier messages (system instructions, input data)
+	Schema   map[string]any // JSON Schema of the answer (any JSON value if nil)
+	Name     string         // Name of the schema (DefaultName if empty)
+	Model    string         // Model of the request (Config.Model if empty)
+}
+
+// Result is a validated structured answer.
+type Result struct {
+	JSON     string // Compact JSON of the answer
+	Value    any    // The decoded answer
+	Attempts int    // LLM calls made
+	Tokens   int
+}
+
+// Generate asks for a JSON answer and validates it against the schema,
+// asking the model to correct invalid replies.
+func (g *Generator) Generate(ctx context.Context, req Request) (*Result, error) {
+	format := &llm.ResponseFormat{Type: llm.ResponseFormatJSONObject}
+	system := jsonPrompt
+	if req.Schema != nil {
+		if err := CheckSchema(req.Schema); err != nil {
+			return nil, err
+		}
+		schema, err := json.MarshalIndent(req.Schema, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+		}
+		name := req.Name
+		if name == "" {
+			name = DefaultName
+		}
+		format = &llm.ResponseFormat{Type: llm.ResponseFormatJSONSchema, Name: name, Schema: req.Schema, Strict: true}
+		system = fmt.Sprintf(schemaPrompt, schema)
+	}
+
+	model := req.Model
+	if model == "" {
+		model = g.cfg.Model
+	}
+
+	messages := []llm.Message{{Role: llm.RoleSystem, Content: system}}
+	messages = append(messages, req.Messages...)
+	messages = append(messages, llm.Message{Role: llm.RoleUser, Content: req.Prompt})
+
+	result := &Result{}
+	var problems []string
+	for attempt := 0; attempt <= g.cfg.MaxRetries; attempt++ {
+		resp, err := g.provider.Chat(ctx, llm.ChatRequest{
+			Messages:       messages,
+			Model:          model,
+			Temperature:    g.cfg.Temperature,
+			MaxTokens:      g.cfg.MaxTokens,
+			ResponseFormat: format,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("structured output request failed: %w", err)
+		}
+		result.Attempts++
+		result.Tokens += resp.Usage.TotalTokens
+
+		var value any
+		value, problems = parse(resp.Content, req.Schema)
+		if len(problems) == 0 {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode structured output: %w", err)
+			}
+			result.JSON = string(data)
+			result.Value = value
+			return result, nil
+		}
+
+		messages = append(messages,
+			llm.Message{Role: llm.RoleAssistant, Content: resp.Content},
+			llm.Message{Role: llm.RoleUser, Content: fmt.Sprintf(retryPrompt, strings.Join(problems, "\n- "))},
+		)
+	}
+	return nil, fmt.Errorf("%w after %d attempts: %s", ErrNoValidOutput, result.Attempts, strings.Join(problems, "; "))
+}
+
+// parse decodes a reply and validates it against the schema.
+func parse(content string, schema map[string]any) (any, []string) {
+	value, err := Extract(content)
+	if err != nil {
+		return nil, []string{err.Error()}
+	}
+	if schema == nil {
+		return value, nil
+	}
+	return value, Validate(schema, value)
+}
+
+// Extract decodes the JSON value of a reply, tolerating code fences and text
+// around a JSON object or array.
+func Extract(content string) (any, error) {
+	content = strings.TrimSpace(content)
+	var value any
+	if err := json.Unmarshal([]byte(content), &value); err == nil {
+		return value, nil
+	}
+
+	start := strings.IndexAny(content, "{[")
+	if start < 0 {
+		return nil, errors.New("the reply contains no JSON")
+	}
+	closing := "}"
+	if content[start] == '[' {
+		closing = "]"
+	}
+	end := strings.LastIndex(content, closing)
+	if end < start {
+		return nil, errors.New("the reply contains no complete JSON value")
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &value); err != nil {
+		return nil, fmt.Errorf("the reply is not valid JSON: %v", err)
+	}
+	return value, nil
+}
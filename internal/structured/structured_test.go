@@ -0,0 +1,176 @@
+package structured
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+// scriptedProvider returns the replies in order and records the requests.
+type scriptedProvider struct {
+	replies  []string
+	requests []llm.ChatRequest
+}
+
+func (p *scriptedProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.requests = append(p.requests, req)
+	reply := p.replies[min(len(p.requests), len(p.replies))-1]
+	return &llm.ChatResponse{Content: reply, FinishReason: llm.FinishReasonStop, Usage: llm.Usage{TotalTokens: 10}}, nil
+}
+
+func (p *scriptedProvider) SupportsToolCalling() bool { return false }
+
+var taskSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"title":    map[string]any{"type": "string", "minLength": 1},
+		"priority": map[string]any{"type": "string", "enum": []string{"low", "high"}},
+		"tags":     map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "maxItems": 2},
+		"estimate": map[string]any{"type": "integer", "minimum": 0},
+	},
+	"required":             []string{"title", "priority"},
+	"additionalProperties": false,
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{
+			name:  "valid",
+			value: `{"title": "Fix login", "priority": "high", "tags": ["auth"], "estimate": 3}`,
+		},
+		{
+			name:  "wrong type",
+			value: `["Fix login"]`,
+			want:  []string{"$: expected object, got array"},
+		},
+		{
+			name:  "missing and unexpected properties",
+			value: `{"title": "", "owner": "bob"}`,
+			want: []string{
+				`$: missing required property "priority"`,
+				`$: unexpected property "owner"`,
+				"$.title: must be at least 1 characters",
+			},
+		},
+		{
+			name:  "nested violations",
+			value: `{"title": "x", "priority": "urgent", "tags": ["a", 1, "c"], "estimate": 1.5}`,
+			want: []string{
+				"$.estimate: expected integer, got number",
+				"$.priority: must be one of [low, high]",
+				"$.tags: must have at most 2 items",
+				"$.tags[1]: expected string, got number",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := Extract(tt.value)
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			got := Validate(taskSchema, value)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Validate() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	if err := CheckSchema(taskSchema); err != nil {
+		t.Errorf("CheckSchema() error = %v", err)
+	}
+	invalid := []map[string]any{
+		{"type": "text"},
+		{"type": "object", "properties": []string{"a"}},
+		{"type": "array", "items": map[string]any{"type": 1}},
+		{"type": "string", "pattern": "("},
+	}
+	for _, schema := range invalid {
+		if err := CheckSchema(schema); !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("CheckSchema(%v) error = %v, want ErrInvalidSchema", schema, err)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	for _, content := range []string{
+		`{"a": 1}`,
+		"```json\n{\"a\": 1}\n```",
+		`Here you go: {"a": 1} Hope it helps.`,
+	} {
+		value, err := Extract(content)
+		if err != nil {
+			t.Errorf("Extract(%q) error = %v", content, err)
+			continue
+		}
+		if m, ok := value.(map[string]any); !ok || m["a"] != float64(1) {
+			t.Errorf("Extract(%q) = %v", content, value)
+		}
+	}
+	if _, err := Extract("no json here"); err == nil {
+		t.Error("Extract() of plain text should fail")
+	}
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	provider := &scriptedProvider{replies: []string{
+		`{"title": "Fix login"}`,
+		"```json\n{\"title\": \"Fix login\", \"priority\": \"high\"}\n```",
+	}}
+	g := New(provider, Config{Model: "glm-4.7-flash"})
+
+	result, err := g.Generate(context.Background(), Request{Prompt: "Turn this into a task: login is broken!", Schema: taskSchema, Name: "task"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result.JSON != `{"priority":"high","title":"Fix login"}` || result.Attempts != 2 || result.Tokens != 20 {
+		t.Errorf("Generate() = %+v", result)
+	}
+
+	first := provider.requests[0]
+	if first.Model != "glm-4.7-flash" || first.ResponseFormat == nil || first.ResponseFormat.Type != llm.ResponseFormatJSONSchema || first.ResponseFormat.Name != "task" {
+		t.Errorf("first request model = %q, response format = %+v", first.Model, first.ResponseFormat)
+	}
+	if !strings.Contains(first.Messages[0].Content, `"additionalProperties": false`) {
+		t.Errorf("system prompt does not include the schema: %q", first.Messages[0].Content)
+	}
+	retry := provider.requests[1].Messages
+	if last := retry[len(retry)-1].Content; !strings.Contains(last, `missing required property "priority"`) {
+		t.Errorf("retry prompt does not include the validation errors: %q", last)
+	}
+}
+
+func TestGenerator_GenerateGivesUp(t *testing.T) {
+	provider := &scriptedProvider{replies: []string{"not json"}}
+	g := New(provider, Config{MaxRetries: 1})
+
+	_, err := g.Generate(context.Background(), Request{Prompt: "x", Schema: taskSchema})
+	if !errors.Is(err, ErrNoValidOutput) || len(provider.requests) != 2 {
+		t.Errorf("Generate() error = %v after %d requests, want ErrNoValidOutput after 2", err, len(provider.requests))
+	}
+
+	if _, err := g.Generate(context.Background(), Request{Prompt: "x", Schema: map[string]any{"type": "text"}}); !errors.Is(err, ErrInvalidSchema) {
+		t.Errorf("Generate() with an invalid schema error = %v, want ErrInvalidSchema", err)
+	}
+}
+
+func TestGenerator_GenerateWithoutSchema(t *testing.T) {
+	provider := &scriptedProvider{replies: []string{`[1, 2, 3]`}}
+	result, err := New(provider, Config{}).Generate(context.Background(), Request{Prompt: "first three numbers"})
+	if err != nil || result.JSON != "[1,2,3]" {
+		t.Fatalf("Generate() = %+v, %v", result, err)
+	}
+	if provider.requests[0].ResponseFormat.Type != llm.ResponseFormatJSONObject {
+		t.Errorf("response format = %+v, want json_object", provider.requests[0].ResponseFormat)
+	}
+}
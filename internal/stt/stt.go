@@ -0,0 +1,27 @@
+// Package stt converts speech to text. Providers transcribe audio files:
+// the Whisper API (OpenAI or a compatible server) or a local whisper.cpp
+// binary. Transcription is used for Telegram voice messages and by the
+// transcribe_audio tool.
+package stt
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoSpeech is returned when no speech is recognized in the audio.
+var ErrNoSpeech = errors.New("no speech recognized")
+
+// Options control a transcription.
+type Options struct {
+	Language string // ISO-639-1 language of the audio; empty detects the language
+}
+
+// Provider transcribes audio files.
+type Provider interface {
+	// Name identifies the provider in logs.
+	Name() string
+
+	// Transcribe returns the text spoken in the audio file at path.
+	Transcribe(ctx context.Context, path string, opts Options) (string, error)
+}
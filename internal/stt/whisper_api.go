@@ -0,0 +1,127 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultWhisperBaseURL is the OpenAI API base URL
+	DefaultWhisperBaseURL = "https://api.openai.com/v1"
+
+	// DefaultWhisperModel is the OpenAI transcription model
+	DefaultWhisperModel = "whisper-1"
+
+	// maxErrorBody limits the error response body included in errors
+	maxErrorBody = 512
+)
+
+// WhisperAPIProvider transcribes audio with the OpenAI transcription API or
+// a server implementing it (e.g. faster-whisper-server).
+type WhisperAPIProvider struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// NewWhisperAPIProvider creates a Whisper API provider.
+// Empty baseURL and model use the OpenAI defaults.
+func NewWhisperAPIProvider(apiKey, baseURL, model string, timeout time.Duration) *WhisperAPIProvider {
+	if baseURL == "" {
+		baseURL = DefaultWhisperBaseURL
+	}
+	if model == "" {
+		model = DefaultWhisperModel
+	}
+	return &WhisperAPIProvider{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		model:   model,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the provider name.
+func (p *WhisperAPIProvider) Name() string {
+	return "whisper_api"
+}
+
+// Transcribe uploads the audio file and returns the recognized text.
+func (p *WhisperAPIProvider) Transcribe(ctx context.Context, path string, opts Options) (string, error) {
+	body, contentType, err := p.requestBody(path, opts)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/audio/transcriptions", body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create transcription request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("transcription request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		errBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return "", fmt.Errorf("transcription request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(errBody)))
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode transcription response: %w", err)
+	}
+
+	text := strings.TrimSpace(result.Text)
+	if text == "" {
+		return "", ErrNoSpeech
+	}
+	return text, nil
+}
+
+// requestBody builds the multipart form with the audio file.
+func (p *WhisperAPIProvider) requestBody(path string, opts Options) (io.Reader, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open audio file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	_ = w.WriteField("model", p.model)
+	_ = w.WriteField("response_format", "json")
+	if opts.Language != "" {
+		_ = w.WriteField("language", opts.Language)
+	}
+
+	part, err := w.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create transcription request: %w", err)
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return nil, "", fmt.Errorf("failed to read audio file: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to create transcription request: %w", err)
+	}
+	return &buf, w.FormDataContentType(), nil
+}
@@ -0,0 +1,83 @@
+package stt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeAudio creates a fake audio file.
+func writeAudio(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte("audio"), 0644); err != nil {
+		t.Fatalf("Failed to write audio file: %v", err)
+	}
+	return path
+}
+
+func TestWhisperAPIProvider_Transcribe(t *testing.T) {
+	var form map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/transcriptions" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("unexpected request: %s %v", r.URL.Path, r.Header)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("ParseMultipartForm() error = %v", err)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("FormFile() error = %v", err)
+		}
+		data, _ := io.ReadAll(file)
+		form = map[string]string{
+			"model":    r.FormValue("model"),
+			"language": r.FormValue("language"),
+			"file":     header.Filename + ":" + string(data),
+		}
+		_, _ = fmt.Fprint(w, `{"text": " Buy milk tomorrow. "}`)
+	}))
+	defer server.Close()
+
+	provider := NewWhisperAPIProvider("key", server.URL+"/v1/", "", 5*time.Second)
+	text, err := provider.Transcribe(context.Background(), writeAudio(t, "voice.ogg"), Options{Language: "en"})
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if text != "Buy milk tomorrow." {
+		t.Errorf("Transcribe() = %q", text)
+	}
+	if form["model"] != DefaultWhisperModel || form["language"] != "en" || form["file"] != "voice.ogg:audio" {
+		t.Errorf("form = %v", form)
+	}
+}
+
+func TestWhisperAPIProvider_Errors(t *testing.T) {
+	status := http.StatusUnauthorized
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = fmt.Fprint(w, `{"text": ""}`)
+	}))
+	defer server.Close()
+
+	provider := NewWhisperAPIProvider("key", server.URL, "", 5*time.Second)
+	if _, err := provider.Transcribe(context.Background(), writeAudio(t, "a.ogg"), Options{}); err == nil {
+		t.Error("Transcribe() error = nil, want status error")
+	}
+
+	status = http.StatusOK
+	if _, err := provider.Transcribe(context.Background(), writeAudio(t, "a.ogg"), Options{}); !errors.Is(err, ErrNoSpeech) {
+		t.Errorf("Transcribe(silence) error = %v, want ErrNoSpeech", err)
+	}
+
+	if _, err := provider.Transcribe(context.Background(), "/missing.ogg", Options{}); err == nil {
+		t.Error("Transcribe(missing file) error = nil")
+	}
+}
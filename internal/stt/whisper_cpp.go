@@ -0,0 +1,117 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultWhisperCppBinary is the whisper.cpp command line binary
+	DefaultWhisperCppBinary = "whisper-cli"
+
+	// DefaultFFmpegBinary converts audio into the WAV format whisper.cpp reads
+	DefaultFFmpegBinary = "ffmpeg"
+
+	// maxStderr limits the command output included in errors
+	maxStderr = 512
+)
+
+// WhisperCppProvider transcribes audio locally with the whisper.cpp command
+// line tool. whisper.cpp reads 16 kHz WAV files, so other formats (e.g. the
+// OGG/Opus of Telegram voice messages) are converted with ffmpeg first.
+type WhisperCppProvider struct {
+	binary  string
+	model   string
+	ffmpeg  string
+	threads int
+}
+
+// NewWhisperCppProvider creates a whisper.cpp provider using the given model
+// file. Empty binary and ffmpeg use the defaults found in PATH; threads <= 0
+// uses the whisper.cpp default.
+func NewWhisperCppProvider(binary, model, ffmpeg string, threads int) *WhisperCppProvider {
+	if binary == "" {
+		binary = DefaultWhisperCppBinary
+	}
+	if ffmpeg == "" {
+		ffmpeg = DefaultFFmpegBinary
+	}
+	return &WhisperCppProvider{
+		binary:  binary,
+		model:   model,
+		ffmpeg:  ffmpeg,
+		threads: threads,
+	}
+}
+
+// Name returns the provider name.
+func (p *WhisperCppProvider) Name() string {
+	return "whisper_cpp"
+}
+
+// Transcribe runs whisper.cpp on the audio file and returns the recognized text.
+func (p *WhisperCppProvider) Transcribe(ctx context.Context, path string, opts Options) (string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("failed to access audio file: %w", err)
+	}
+
+	input := path
+	if !strings.EqualFold(filepath.Ext(path), ".wav") {
+		tmpDir, err := os.MkdirTemp("", "nexbot-stt-")
+		if err != nil {
+			return "", fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(tmpDir) }()
+
+		input = filepath.Join(tmpDir, "audio.wav")
+		if _, err := run(ctx, p.ffmpeg, "-nostdin", "-y", "-i", path, "-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", input); err != nil {
+			return "", fmt.Errorf("failed to convert audio: %w", err)
+		}
+	}
+
+	language := opts.Language
+	if language == "" {
+		language = "auto"
+	}
+	args := []string{"-m", p.model, "-f", input, "-l", language, "-nt", "-np"}
+	if p.threads > 0 {
+		args = append(args, "-t", strconv.Itoa(p.threads))
+	}
+
+	out, err := run(ctx, p.binary, args...)
+	if err != nil {
+		return "", fmt.Errorf("whisper.cpp failed: %w", err)
+	}
+
+	text := strings.Join(strings.Fields(out), " ")
+	if text == "" || text == "[BLANK_AUDIO]" {
+		return "", ErrNoSpeech
+	}
+	return text, nil
+}
+
+// run executes a command and returns its standard output.
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > maxStderr {
+			msg = msg[len(msg)-maxStderr:]
+		}
+		if msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,74 @@
+package stt
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeScript creates an executable shell script.
+func writeScript(t *testing.T, name, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	return path
+}
+
+func TestWhisperCppProvider_Transcribe(t *testing.T) {
+	argsFile := filepath.Join(t.TempDir(), "args")
+	// Fake whisper.cpp: records its arguments and prints a transcript
+	whisper := writeScript(t, "whisper-cli", `echo "$@" > `+argsFile+`
+printf ' Buy milk\n tomorrow.\n'
+`)
+	// Fake ffmpeg: copies the input (after -i) to the output (last argument)
+	ffmpeg := writeScript(t, "ffmpeg", `while [ "$1" != "-i" ]; do shift; done
+in="$2"
+for out; do :; done
+cp "$in" "$out"
+`)
+
+	provider := NewWhisperCppProvider(whisper, "/models/ggml-base.bin", ffmpeg, 4)
+	text, err := provider.Transcribe(context.Background(), writeAudio(t, "voice.ogg"), Options{})
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if text != "Buy milk tomorrow." {
+		t.Errorf("Transcribe() = %q", text)
+	}
+
+	args, _ := os.ReadFile(argsFile)
+	got := strings.TrimSpace(string(args))
+	if !strings.HasPrefix(got, "-m /models/ggml-base.bin -f ") || !strings.Contains(got, "audio.wav -l auto -nt -np -t 4") {
+		t.Errorf("whisper.cpp args = %q", got)
+	}
+
+	// WAV files are passed as they are
+	wav := writeAudio(t, "voice.wav")
+	if _, err := provider.Transcribe(context.Background(), wav, Options{Language: "ru"}); err != nil {
+		t.Fatalf("Transcribe(wav) error = %v", err)
+	}
+	args, _ = os.ReadFile(argsFile)
+	if !strings.Contains(string(args), "-f "+wav+" -l ru") {
+		t.Errorf("whisper.cpp args = %q", args)
+	}
+}
+
+func TestWhisperCppProvider_Errors(t *testing.T) {
+	failing := writeScript(t, "whisper-cli", "echo 'model not found' >&2\nexit 1\n")
+	provider := NewWhisperCppProvider(failing, "model.bin", "", 0)
+	_, err := provider.Transcribe(context.Background(), writeAudio(t, "a.wav"), Options{})
+	if err == nil || !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("Transcribe() error = %v, want whisper.cpp stderr", err)
+	}
+
+	blank := writeScript(t, "whisper-cli", "echo ' [BLANK_AUDIO]'\n")
+	provider = NewWhisperCppProvider(blank, "model.bin", "", 0)
+	if _, err := provider.Transcribe(context.Background(), writeAudio(t, "a.wav"), Options{}); !errors.Is(err, ErrNoSpeech) {
+		t.Errorf("Transcribe(blank) error = %v, want ErrNoSpeech", err)
+	}
+}
@@ -0,0 +1,82 @@
+// Package systemd implements the sd_notify protocol: readiness and stopping
+// notifications and watchdog keep-alive pings for services running with
+// Type=notify and WatchdogSec. Without systemd (NOTIFY_SOCKET unset) all
+// calls are no-ops.
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Notification states
+const (
+	// StateReady tells systemd that startup is finished
+	StateReady = "READY=1"
+	// StateStopping tells systemd that the service is shutting down
+	StateStopping = "STOPPING=1"
+	// StateReloading tells systemd that the service is reloading
+	StateReloading = "RELOADING=1"
+	// StateWatchdog is the watchdog keep-alive ping
+	StateWatchdog = "WATCHDOG=1"
+)
+
+// Environment variables set by systemd
+const (
+	envNotifySocket = "NOTIFY_SOCKET"
+	envWatchdogUsec = "WATCHDOG_USEC"
+	envWatchdogPID  = "WATCHDOG_PID"
+)
+
+// Notify sends a state to systemd, e.g. StateReady or "STATUS=...".
+// It returns false without an error when the service doesn't run under
+// systemd notify.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv(envNotifySocket)
+	if socket == "" {
+		return false, nil
+	}
+	// Abstract namespace sockets are written with a leading @
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("failed to send notification: %w", err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the watchdog timeout configured by WatchdogSec,
+// or 0 when the watchdog is disabled or meant for another process.
+func WatchdogInterval() (time.Duration, error) {
+	usecText := os.Getenv(envWatchdogUsec)
+	if usecText == "" {
+		return 0, nil
+	}
+	usec, err := strconv.ParseInt(usecText, 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, fmt.Errorf("invalid %s: %q", envWatchdogUsec, usecText)
+	}
+
+	if pidText := os.Getenv(envWatchdogPID); pidText != "" {
+		pid, err := strconv.Atoi(pidText)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %q", envWatchdogPID, pidText)
+		}
+		if pid != os.Getpid() {
+			return 0, nil
+		}
+	}
+
+	return time.Duration(usec) * time.Microsecond, nil
+}
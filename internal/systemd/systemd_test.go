@@ -0,0 +1,106 @@
+package systemd
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// listen creates a notify socket and points NOTIFY_SOCKET to it
+func listen(t *testing.T) *net.UnixConn {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv(envNotifySocket, path)
+	return conn
+}
+
+func TestNotify(t *testing.T) {
+	conn := listen(t)
+
+	sent, err := Notify(StateReady)
+	if err != nil || !sent {
+		t.Fatalf("Notify() = %v, %v, want sent", sent, err)
+	}
+
+	buf := make([]byte, 64)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil || string(buf[:n]) != StateReady {
+		t.Errorf("received %q, %v, want %q", buf[:n], err, StateReady)
+	}
+}
+
+func TestNotify_WithoutSystemd(t *testing.T) {
+	t.Setenv(envNotifySocket, "")
+	if sent, err := Notify(StateReady); sent || err != nil {
+		t.Errorf("Notify() without NOTIFY_SOCKET = %v, %v, want a no-op", sent, err)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	tests := []struct {
+		usec    string
+		pid     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", "", 0, false},
+		{"30000000", "", 30 * time.Second, false},
+		{"30000000", strconv.Itoa(os.Getpid()), 30 * time.Second, false},
+		{"30000000", "1", 0, false},
+		{"abc", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Setenv(envWatchdogUsec, tt.usec)
+		t.Setenv(envWatchdogPID, tt.pid)
+		got, err := WatchdogInterval()
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("WatchdogInterval(usec=%q, pid=%q) = %v, %v", tt.usec, tt.pid, got, err)
+		}
+	}
+}
+
+func TestWatchdog_Ping(t *testing.T) {
+	var pings int
+	var failed []string
+	w := NewWatchdog(10*time.Second, func(name string, err error) { failed = append(failed, name) })
+	w.notify = func(state string) (bool, error) {
+		if state == StateWatchdog {
+			pings++
+		}
+		return true, nil
+	}
+
+	healthy := true
+	w.AddCheck("bus", func() error { return nil })
+	w.AddCheck("telegram", func() error {
+		if !healthy {
+			return errors.New("long polling is not running")
+		}
+		return nil
+	})
+
+	if err := w.Ping(); err != nil || pings != 1 {
+		t.Fatalf("Ping() = %v, pings = %d, want a ping", err, pings)
+	}
+
+	healthy = false
+	if err := w.Ping(); err == nil || pings != 1 {
+		t.Errorf("Ping() with a failing check = %v, pings = %d, want no ping", err, pings)
+	}
+	if len(failed) != 1 || failed[0] != "telegram" {
+		t.Errorf("failed checks = %v, want [telegram]", failed)
+	}
+	if w.interval != 5*time.Second {
+		t.Errorf("interval = %v, want half of the timeout", w.interval)
+	}
+}
@@ -0,0 +1,74 @@
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Check reports whether a component is alive; an error means it is hung.
+type Check func() error
+
+// Watchdog sends keep-alive pings to systemd while all checks pass. When a
+// check fails, pings stop and systemd restarts the service after the
+// watchdog timeout.
+type Watchdog struct {
+	interval time.Duration
+	checks   map[string]Check
+	notify   func(string) (bool, error)
+	onFail   func(name string, err error)
+}
+
+// NewWatchdog creates a watchdog for the timeout returned by
+// WatchdogInterval. Pings are sent every half of the timeout, as systemd
+// recommends. onFail is called for every failed check (may be nil).
+func NewWatchdog(timeout time.Duration, onFail func(name string, err error)) *Watchdog {
+	return &Watchdog{
+		interval: timeout / 2,
+		checks:   make(map[string]Check),
+		notify:   Notify,
+		onFail:   onFail,
+	}
+}
+
+// AddCheck adds a liveness check.
+func (w *Watchdog) AddCheck(name string, check Check) {
+	w.checks[name] = check
+}
+
+// Run pings the watchdog until the context is cancelled.
+func (w *Watchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = w.Ping()
+		}
+	}
+}
+
+// Ping runs the checks and sends a keep-alive ping when all of them pass.
+func (w *Watchdog) Ping() error {
+	names := make([]string, 0, len(w.checks))
+	for name := range w.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := w.checks[name](); err != nil {
+			if w.onFail != nil {
+				w.onFail(name, err)
+			}
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	_, err := w.notify(StateWatchdog)
+	return err
+}
@@ -0,0 +1,35 @@
+// Package textutil holds small text helpers shared by packages that quote
+// user, LLM or tool text in a bounded space.
+package textutil
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ellipsis marks text shortened by Truncate.
+const Ellipsis = "…"
+
+// Truncate shortens s to at most n runes. When s is cut, trailing
+// whitespace is dropped and the ellipsis takes the last rune.
+func Truncate(s string, n int) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimRightFunc(string(runes[:n-1]), unicode.IsSpace) + Ellipsis
+}
+
+// TruncateBytes shortens s to at most max bytes without splitting a UTF-8
+// sequence. Returns s unchanged if max is not positive.
+func TruncateBytes(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
@@ -0,0 +1,197 @@
+// Package throttle limits inbound messages per user: messages per minute,
+// messages in flight (queued or being processed) and temporary mutes for
+// abusive floods. Limits are configured per channel.
+package throttle
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+)
+
+const (
+	// MetadataKey marks admitted messages whose slot is released by Done
+	MetadataKey = "throttle_admitted"
+
+	// DefaultSlowDownMessage is sent when a rate limit is exceeded
+	DefaultSlowDownMessage = "⏳ You're sending messages too quickly. Please slow down."
+
+	// DefaultBusyMessage is sent when too many messages are in flight
+	DefaultBusyMessage = "⏳ Still working on your previous messages. Please wait a moment."
+
+	// window is the rate limit window
+	window = time.Minute
+)
+
+// Rule configures limits for a channel. Non-positive values disable a limit.
+type Rule struct {
+	MessagesPerMinute int           // Messages accepted per minute
+	MaxConcurrent     int           // Messages queued or being processed at once
+	MuteThreshold     int           // Messages per minute (including rejected ones) that trigger a mute
+	MuteDuration      time.Duration // Mute duration
+}
+
+// active reports whether the rule limits anything.
+func (r Rule) active() bool {
+	return r.MessagesPerMinute > 0 || r.MaxConcurrent > 0 || (r.MuteThreshold > 0 && r.MuteDuration > 0)
+}
+
+// Config configures a Limiter.
+type Config struct {
+	Default         Rule            // Rule for channels without an entry in Channels
+	Channels        map[string]Rule // Rules per channel type
+	SlowDownMessage string          // Reply when the rate limit is exceeded
+	BusyMessage     string          // Reply when too many messages are in flight
+	MuteMessage     string          // Reply when a user is muted (mentions the duration if empty)
+}
+
+// Decision is the result of Admit.
+type Decision struct {
+	Allowed bool
+	Reply   string // Message for the user; empty means reject silently
+	Muted   bool   // The user has just been muted
+}
+
+type userState struct {
+	times      []time.Time // Message times within the window
+	pending    int         // Admitted messages not yet released
+	mutedUntil time.Time
+	notifiedAt time.Time // Last slow-down reply, to avoid replying to every message
+}
+
+// Limiter tracks per-user message rates. It is safe for concurrent use.
+type Limiter struct {
+	mu        sync.Mutex
+	cfg       Config
+	users     map[string]*userState
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// New creates a Limiter.
+func New(cfg Config) *Limiter {
+	if cfg.SlowDownMessage == "" {
+		cfg.SlowDownMessage = DefaultSlowDownMessage
+	}
+	if cfg.BusyMessage == "" {
+		cfg.BusyMessage = DefaultBusyMessage
+	}
+	return &Limiter{
+		cfg:   cfg,
+		users: make(map[string]*userState),
+		now:   time.Now,
+	}
+}
+
+// rule returns the rule for a channel.
+func (l *Limiter) rule(channel bus.ChannelType) Rule {
+	if rule, ok := l.cfg.Channels[string(channel)]; ok {
+		return rule
+	}
+	return l.cfg.Default
+}
+
+// Admit decides whether msg is accepted. Admitted messages are marked in
+// metadata and occupy an in-flight slot until Done is called.
+func (l *Limiter) Admit(msg *bus.InboundMessage) Decision {
+	rule := l.rule(msg.ChannelType)
+	if !rule.active() || msg.UserID == "" {
+		return Decision{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	key := string(msg.ChannelType) + ":" + msg.UserID
+	state, ok := l.users[key]
+	if !ok {
+		state = &userState{}
+		l.users[key] = state
+	}
+
+	if now.Before(state.mutedUntil) {
+		return Decision{}
+	}
+
+	state.times = append(prune(state.times, now), now)
+
+	if rule.MuteThreshold > 0 && rule.MuteDuration > 0 && len(state.times) >= rule.MuteThreshold {
+		state.mutedUntil = now.Add(rule.MuteDuration)
+		state.times = nil
+		return Decision{Reply: l.muteMessage(rule.MuteDuration), Muted: true}
+	}
+
+	if rule.MessagesPerMinute > 0 && len(state.times) > rule.MessagesPerMinute {
+		return Decision{Reply: l.notify(state, now, l.cfg.SlowDownMessage)}
+	}
+
+	if rule.MaxConcurrent > 0 && state.pending >= rule.MaxConcurrent {
+		return Decision{Reply: l.notify(state, now, l.cfg.BusyMessage)}
+	}
+
+	state.pending++
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]any)
+	}
+	msg.Metadata[MetadataKey] = true
+	return Decision{Allowed: true}
+}
+
+// Done releases the in-flight slot of an admitted message.
+func (l *Limiter) Done(msg bus.InboundMessage) {
+	if msg.Metadata[MetadataKey] != true {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if state, ok := l.users[string(msg.ChannelType)+":"+msg.UserID]; ok && state.pending > 0 {
+		state.pending--
+	}
+}
+
+// notify returns reply at most once per window, so a flood does not trigger a reply flood.
+func (l *Limiter) notify(state *userState, now time.Time, reply string) string {
+	if now.Sub(state.notifiedAt) < window {
+		return ""
+	}
+	state.notifiedAt = now
+	return reply
+}
+
+// muteMessage returns the reply for a newly muted user.
+func (l *Limiter) muteMessage(duration time.Duration) string {
+	if l.cfg.MuteMessage != "" {
+		return l.cfg.MuteMessage
+	}
+	return fmt.Sprintf("🔇 Too many messages. Your messages will be ignored for %s.", duration.Round(time.Second))
+}
+
+// sweep removes idle users once per window.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < window {
+		return
+	}
+	l.lastSweep = now
+	for key, state := range l.users {
+		state.times = prune(state.times, now)
+		if len(state.times) == 0 && state.pending == 0 && !now.Before(state.mutedUntil) && now.Sub(state.notifiedAt) >= window {
+			delete(l.users, key)
+		}
+	}
+}
+
+// prune drops times outside the window.
+func prune(times []time.Time, now time.Time) []time.Time {
+	i := 0
+	for i < len(times) && now.Sub(times[i]) >= window {
+		i++
+	}
+	return times[i:]
+}
@@ -0,0 +1,114 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+)
+
+// testClock is a manually advanced clock.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func newTestLimiter(cfg Config) (*Limiter, *testClock) {
+	clock := &testClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	l := New(cfg)
+	l.now = clock.Now
+	return l, clock
+}
+
+func message(channel bus.ChannelType, userID string) *bus.InboundMessage {
+	return bus.NewInboundMessage(channel, userID, string(channel)+":"+userID, "hi", nil)
+}
+
+func TestLimiter_MessagesPerMinute(t *testing.T) {
+	l, clock := newTestLimiter(Config{Default: Rule{MessagesPerMinute: 2}})
+
+	for i := 0; i < 2; i++ {
+		msg := message(bus.ChannelTypeTelegram, "1")
+		if d := l.Admit(msg); !d.Allowed {
+			t.Fatalf("message %d: expected to be allowed", i)
+		}
+		l.Done(*msg)
+	}
+
+	d := l.Admit(message(bus.ChannelTypeTelegram, "1"))
+	if d.Allowed || d.Reply != DefaultSlowDownMessage {
+		t.Errorf("Expected slow down reply, got %+v", d)
+	}
+	if d := l.Admit(message(bus.ChannelTypeTelegram, "1")); d.Allowed || d.Reply != "" {
+		t.Errorf("Expected silent rejection after the reply, got %+v", d)
+	}
+
+	// Other users are not affected
+	if d := l.Admit(message(bus.ChannelTypeTelegram, "2")); !d.Allowed {
+		t.Error("Expected another user to be allowed")
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	if d := l.Admit(message(bus.ChannelTypeTelegram, "1")); !d.Allowed {
+		t.Error("Expected message to be allowed after the window")
+	}
+}
+
+func TestLimiter_MaxConcurrent(t *testing.T) {
+	l, _ := newTestLimiter(Config{Default: Rule{MaxConcurrent: 1}})
+
+	first := message(bus.ChannelTypeTelegram, "1")
+	if d := l.Admit(first); !d.Allowed || first.Metadata[MetadataKey] != true {
+		t.Fatalf("Expected first message to be admitted and marked, got %+v", d)
+	}
+	if d := l.Admit(message(bus.ChannelTypeTelegram, "1")); d.Allowed || d.Reply != DefaultBusyMessage {
+		t.Errorf("Expected busy reply, got %+v", d)
+	}
+
+	l.Done(*first)
+	if d := l.Admit(message(bus.ChannelTypeTelegram, "1")); !d.Allowed {
+		t.Error("Expected message to be allowed after Done")
+	}
+}
+
+func TestLimiter_Mute(t *testing.T) {
+	l, clock := newTestLimiter(Config{
+		Default: Rule{MessagesPerMinute: 2, MuteThreshold: 4, MuteDuration: 10 * time.Minute},
+	})
+
+	var muted Decision
+	for i := 0; i < 4; i++ {
+		muted = l.Admit(message(bus.ChannelTypeTelegram, "1"))
+	}
+	if !muted.Muted || muted.Reply == "" {
+		t.Fatalf("Expected user to be muted with a reply, got %+v", muted)
+	}
+
+	clock.now = clock.now.Add(5 * time.Minute)
+	if d := l.Admit(message(bus.ChannelTypeTelegram, "1")); d.Allowed || d.Reply != "" {
+		t.Errorf("Expected muted user to be ignored silently, got %+v", d)
+	}
+
+	clock.now = clock.now.Add(5 * time.Minute)
+	if d := l.Admit(message(bus.ChannelTypeTelegram, "1")); !d.Allowed {
+		t.Error("Expected message to be allowed after the mute expires")
+	}
+}
+
+func TestLimiter_ChannelRules(t *testing.T) {
+	l, _ := newTestLimiter(Config{
+		Default:  Rule{MessagesPerMinute: 1},
+		Channels: map[string]Rule{"api": {}},
+	})
+
+	for i := 0; i < 3; i++ {
+		if d := l.Admit(message(bus.ChannelTypeAPI, "1")); !d.Allowed {
+			t.Fatal("Expected unlimited channel to allow all messages")
+		}
+	}
+	l.Admit(message(bus.ChannelTypeTelegram, "1"))
+	if d := l.Admit(message(bus.ChannelTypeTelegram, "1")); d.Allowed {
+		t.Error("Expected default rule to apply to telegram")
+	}
+}
@@ -0,0 +1,18 @@
+package tools
+
+import "context"
+
+// approvedKey is the context key marking tool calls the user approved
+type approvedKey struct{}
+
+// WithApproved marks ctx as running tool calls the user approved, so tools
+// don't ask for confirmation again (shell ask_commands).
+func WithApproved(ctx context.Context) context.Context {
+	return context.WithValue(ctx, approvedKey{}, true)
+}
+
+// Approved reports whether ctx runs tool calls the user approved.
+func Approved(ctx context.Context) bool {
+	approved, _ := ctx.Value(approvedKey{}).(bool)
+	return approved
+}
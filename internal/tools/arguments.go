@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/structured"
+)
+
+// ValidateArguments checks that args is a JSON object (empty args count as
+// one) matching the parameters schema of tool. It returns the problems
+// found, none for valid arguments or a tool without a schema.
+func ValidateArguments(tool Tool, args string) []string {
+	schema := tool.Parameters()
+	if len(schema) == 0 {
+		return nil
+	}
+	if strings.TrimSpace(args) == "" {
+		args = "{}"
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(args), &value); err != nil {
+		return []string{"arguments are not valid JSON: " + err.Error()}
+	}
+	if _, ok := value.(map[string]any); !ok {
+		return []string{"arguments must be a JSON object"}
+	}
+	return structured.Validate(schema, value)
+}
+
+// checkArguments returns a validation error listing the problems of the
+// arguments of tc, or nil if they are valid.
+func checkArguments(tool Tool, tc ToolCall) *ToolError {
+	problems := ValidateArguments(tool, tc.Arguments)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	toolErr := NewValidationError(
+		ErrCodeInvalidArgs,
+		fmt.Sprintf("invalid arguments for %s: %s", tc.Name, strings.Join(problems, "; ")),
+		map[string]any{"problems": problems})
+	toolErr.Args = tc.Arguments
+	toolErr.Suggestion = "Fix the arguments to match the parameters schema of the tool and call it again"
+	return toolErr
+}
@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type schemaTool struct{}
+
+func (schemaTool) Name() string        { return "lookup" }
+func (schemaTool) Description() string { return "Look up a key" }
+func (schemaTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"key":   map[string]any{"type": "string"},
+			"limit": map[string]any{"type": "integer"},
+		},
+		"required": []any{"key"},
+	}
+}
+func (schemaTool) Execute(ctx context.Context, args string) (string, error) { return "", nil }
+
+func TestValidateArguments(t *testing.T) {
+	tests := []struct {
+		args string
+		want string // Substring of the first problem, empty if valid
+	}{
+		{`{"key":"a","limit":5}`, ""},
+		{`{"key":"a"`, "not valid JSON"},
+		{`["a"]`, "must be a JSON object"},
+		{``, "key"},
+		{`{"key":"a","limit":"five"}`, "limit"},
+	}
+	for _, tt := range tests {
+		problems := ValidateArguments(schemaTool{}, tt.args)
+		if tt.want == "" {
+			if len(problems) != 0 {
+				t.Errorf("ValidateArguments(%q) = %v, want none", tt.args, problems)
+			}
+			continue
+		}
+		if len(problems) == 0 || !strings.Contains(problems[0], tt.want) {
+			t.Errorf("ValidateArguments(%q) = %v, want a problem with %q", tt.args, problems, tt.want)
+		}
+	}
+}
+
+func TestValidateArguments_NoSchema(t *testing.T) {
+	tool := &mockTool{name: "raw", parameters: map[string]any{}}
+	if problems := ValidateArguments(tool, "a.txt"); len(problems) != 0 {
+		t.Errorf("Expected tools without a schema not to be checked, got %v", problems)
+	}
+}
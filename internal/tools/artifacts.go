@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/artifacts"
+)
+
+// ArtifactStore keeps the files produced by tools per session (implemented by artifacts.Store).
+type ArtifactStore interface {
+	List(sessionID string) ([]artifacts.Entry, error)
+	Find(sessionID, ref string) (artifacts.Entry, error)
+}
+
+// ArtifactsTool implements the Tool interface for referring to files
+// produced earlier in the conversation.
+type ArtifactsTool struct {
+	store ArtifactStore
+}
+
+// ArtifactsArgs represents the arguments for the artifacts tool.
+type ArtifactsArgs struct {
+	Action  string `json:"action"`  // Action: "list" or "send"
+	Ref     string `json:"ref"`     // Artifact ID or file name (for send)
+	Caption string `json:"caption"` // Optional caption (for send)
+}
+
+// NewArtifactsTool creates a new ArtifactsTool instance.
+func NewArtifactsTool(store ArtifactStore) *ArtifactsTool {
+	return &ArtifactsTool{store: store}
+}
+
+// Name returns the tool name.
+func (t *ArtifactsTool) Name() string {
+	return "artifacts"
+}
+
+// Description returns a description of what the tool does.
+func (t *ArtifactsTool) Description() string {
+	return "Lists the files produced by tools in this conversation and sends them to the user again. Use 'list' to find a file the user refers to (\"send me that CSV\"), then 'send' with its ID."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *ArtifactsTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"description": "Action to perform: 'list' to show the files of this conversation, 'send' to deliver one to the user.",
+				"enum":        []string{"list", "send"},
+			},
+			"ref": map[string]any{
+				"type":        "string",
+				"description": "Artifact ID from 'list' or file name. Required for 'send' action. Examples: {\"action\": \"send\", \"ref\": \"2\"}",
+			},
+			"caption": map[string]any{
+				"type":        "string",
+				"description": "Optional caption shown with the file.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+// Execute executes the artifacts tool.
+func (t *ArtifactsTool) Execute(ctx context.Context, args string) (string, error) {
+	result, err := t.ExecuteResult(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	return result.Content, nil
+}
+
+// ExecuteResult executes the artifacts tool. Sent files are returned as
+// artifacts of the result, so they are delivered with the answer.
+func (t *ArtifactsTool) ExecuteResult(ctx context.Context, args string) (*Result, error) {
+	var params ArtifactsArgs
+	if err := parseJSON(args, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse artifacts arguments: %w", err)
+	}
+
+	sessionID := getSessionID(ctx)
+	if sessionID == "" {
+		return nil, fmt.Errorf("artifacts are only available within a conversation")
+	}
+
+	switch params.Action {
+	case "list":
+		return t.list(sessionID)
+	case "send":
+		return t.send(sessionID, params)
+	default:
+		return nil, fmt.Errorf("invalid action: %s. Valid actions: list, send", params.Action)
+	}
+}
+
+// list describes the artifacts of the session.
+func (t *ArtifactsTool) list(sessionID string) (*Result, error) {
+	entries, err := t.store.List(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return &Result{Content: "No files have been produced in this conversation."}, nil
+	}
+
+	var b strings.Builder
+	b.WriteString("Files produced in this conversation:\n")
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "- [%s] %s (%s, %s", entry.ID, entry.Name, entry.MimeType, formatBytes(entry.Size))
+		if entry.Tool != "" {
+			fmt.Fprintf(&b, ", by %s", entry.Tool)
+		}
+		fmt.Fprintf(&b, ", %s)", entry.CreatedAt.Format("2006-01-02 15:04"))
+		if entry.Caption != "" {
+			fmt.Fprintf(&b, " — %s", entry.Caption)
+		}
+		b.WriteString("\n")
+	}
+	return &Result{Content: b.String()}, nil
+}
+
+// send returns an artifact of the session for delivery to the user.
+func (t *ArtifactsTool) send(sessionID string, params ArtifactsArgs) (*Result, error) {
+	if params.Ref == "" {
+		return nil, fmt.Errorf("ref parameter is required for send action")
+	}
+
+	entry, err := t.store.Find(sessionID, params.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	caption := params.Caption
+	if caption == "" {
+		caption = entry.Caption
+	}
+	return &Result{
+		Content: fmt.Sprintf("File %s will be sent to the user with the answer.", entry.Name),
+		Artifacts: []Artifact{{
+			Path:     entry.Path,
+			Name:     entry.Name,
+			MimeType: entry.MimeType,
+			Caption:  caption,
+		}},
+	}, nil
+}
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/artifacts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupArtifactsTool(t *testing.T) (*ArtifactsTool, artifacts.Entry) {
+	t.Helper()
+	store := artifacts.NewStore(t.TempDir())
+
+	src := filepath.Join(t.TempDir(), "report.csv")
+	require.NoError(t, os.WriteFile(src, []byte("a,b"), 0644))
+	entry, err := store.Register("telegram:1", src, artifacts.Entry{MimeType: "text/csv", Tool: "write_file"})
+	require.NoError(t, err)
+
+	return NewArtifactsTool(store), entry
+}
+
+func TestArtifactsTool_List(t *testing.T) {
+	tool, _ := setupArtifactsTool(t)
+
+	ctx := context.WithValue(context.Background(), sessionIDKey, "telegram:1")
+	result, err := tool.Execute(ctx, `{"action": "list"}`)
+	require.NoError(t, err)
+	assert.Contains(t, result, "[1] report.csv (text/csv, 3 B, by write_file")
+
+	ctx = context.WithValue(context.Background(), sessionIDKey, "telegram:2")
+	result, err = tool.Execute(ctx, `{"action": "list"}`)
+	require.NoError(t, err)
+	assert.Contains(t, result, "No files")
+}
+
+func TestArtifactsTool_Send(t *testing.T) {
+	tool, entry := setupArtifactsTool(t)
+	ctx := context.WithValue(context.Background(), sessionIDKey, "telegram:1")
+
+	result, err := tool.ExecuteResult(ctx, `{"action": "send", "ref": "report.csv", "caption": "Отчёт"}`)
+	require.NoError(t, err)
+	require.Len(t, result.Artifacts, 1)
+	assert.Equal(t, Artifact{Path: entry.Path, Name: "report.csv", MimeType: "text/csv", Caption: "Отчёт"}, result.Artifacts[0])
+
+	_, err = tool.ExecuteResult(ctx, `{"action": "send", "ref": "missing"}`)
+	assert.ErrorIs(t, err, artifacts.ErrNotFound)
+
+	_, err = tool.ExecuteResult(context.Background(), `{"action": "send", "ref": "1"}`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package tools
+
+import "fmt"
+
+// Tool classes group related tools for per-class call budgets.
+const (
+	ClassShell      = "shell"
+	ClassFile       = "file"
+	ClassWeb        = "web"
+	ClassMessaging  = "messaging"
+	ClassScheduling = "scheduling"
+	ClassAgent      = "agent"
+)
+
+// ErrCodeBudgetExhausted is returned when a tool class has no calls left.
+const ErrCodeBudgetExhausted = "budget_exhausted"
+
+var toolClasses = map[string]string{
+	"shell_exec":        ClassShell,
+	"process":           ClassShell,
+	"read_file":         ClassFile,
+	"write_file":        ClassFile,
+	"list_dir":          ClassFile,
+	"delete_file":       ClassFile,
+	"web_fetch":         ClassWeb,
+	"search":            ClassWeb,
+	"send_message":      ClassMessaging,
+	"notify":            ClassMessaging,
+	"pin_message":       ClassMessaging,
+	"unpin_message":     ClassMessaging,
+	"set_chat_title":    ClassMessaging,
+	"cron":              ClassScheduling,
+	"watch":             ClassScheduling,
+	"monitor":           ClassScheduling,
+	"schedule_followup": ClassScheduling,
+	"spawn":             ClassAgent,
+}
+
+// ToolClass returns the budget class of a tool.
+// Tools without a known class form a class of their own, named after the tool.
+func ToolClass(name string) string {
+	if class, ok := toolClasses[name]; ok {
+		return class
+	}
+	return name
+}
+
+// CallBudget limits the number of tool calls per class within one request.
+// Budgets are keyed by class name or by tool name (plain or qualified, e.g.
+// fs.write); a tool name budget takes precedence over its class budget. Not safe for concurrent use.
+type CallBudget struct {
+	limits map[string]int
+	used   map[string]int
+}
+
+// NewCallBudget creates a budget from limits. Non-positive limits are ignored.
+func NewCallBudget(limits map[string]int) *CallBudget {
+	b := &CallBudget{
+		limits: make(map[string]int, len(limits)),
+		used:   make(map[string]int),
+	}
+	for key, limit := range limits {
+		if limit > 0 {
+			b.limits[key] = limit
+		}
+	}
+	return b
+}
+
+// key returns the budget key of a tool, or "" if the tool is unlimited.
+func (b *CallBudget) key(name string) string {
+	if _, ok := b.limits[name]; ok {
+		return name
+	}
+	if qualified := qualifiedByName(name); qualified != "" {
+		if _, ok := b.limits[qualified]; ok {
+			return qualified
+		}
+	}
+	if class := ToolClass(name); class != name {
+		if _, ok := b.limits[class]; ok {
+			return class
+		}
+	}
+	return ""
+}
+
+// Take records a call of the named tool.
+// Reports false without recording if the tool's budget is exhausted.
+func (b *CallBudget) Take(name string) bool {
+	key := b.key(name)
+	if key == "" {
+		return true
+	}
+	if b.used[key] >= b.limits[key] {
+		return false
+	}
+	b.used[key]++
+	return true
+}
+
+// Remaining returns the calls left for the named tool, or -1 if it is unlimited.
+func (b *CallBudget) Remaining(name string) int {
+	key := b.key(name)
+	if key == "" {
+		return -1
+	}
+	return b.limits[key] - b.used[key]
+}
+
+// ExhaustedError returns the error reported for a call denied by the budget.
+func (b *CallBudget) ExhaustedError(name string) *ToolError {
+	key := b.key(name)
+	return &ToolError{
+		Type:       ErrorTypeRateLimit,
+		Code:       ErrCodeBudgetExhausted,
+		Message:    fmt.Sprintf("tool budget exhausted: %s is limited to %d calls per request", key, b.limits[key]),
+		Suggestion: "Do not call these tools again; finish the task with the information you already have",
+		Retryable:  false,
+	}
+}
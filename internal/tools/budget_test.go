@@ -0,0 +1,62 @@
+package tools
+
+import "testing"
+
+func TestToolClass(t *testing.T) {
+	tests := map[string]string{
+		"shell_exec":  ClassShell,
+		"process":     ClassShell,
+		"read_file":   ClassFile,
+		"web_fetch":   ClassWeb,
+		"notify":      ClassMessaging,
+		"spawn":       ClassAgent,
+		"system_time": "system_time",
+	}
+	for name, want := range tests {
+		if got := ToolClass(name); got != want {
+			t.Errorf("ToolClass(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCallBudget(t *testing.T) {
+	budget := NewCallBudget(map[string]int{
+		ClassShell:  2,
+		"read_file": 1,
+		ClassFile:   5,
+		ClassWeb:    0,
+	})
+
+	if !budget.Take("shell_exec") || !budget.Take("process") {
+		t.Fatal("Expected first two shell calls to be allowed")
+	}
+	if budget.Take("shell_exec") {
+		t.Error("Expected third shell call to be denied")
+	}
+	if budget.Remaining("process") != 0 {
+		t.Errorf("Remaining(process) = %d, want 0", budget.Remaining("process"))
+	}
+
+	// Tool name budget takes precedence over its class budget
+	if !budget.Take("read_file") || budget.Take("read_file") {
+		t.Error("Expected read_file to be limited to one call")
+	}
+	if budget.Remaining("write_file") != 5 {
+		t.Errorf("Remaining(write_file) = %d, want 5", budget.Remaining("write_file"))
+	}
+
+	// Non-positive and missing budgets are unlimited
+	if budget.Remaining("web_fetch") != -1 || budget.Remaining("system_time") != -1 {
+		t.Error("Expected web and unknown tools to be unlimited")
+	}
+	for range 10 {
+		if !budget.Take("system_time") {
+			t.Fatal("Expected unlimited tool to be allowed")
+		}
+	}
+
+	err := budget.ExhaustedError("shell_exec")
+	if err.Code != ErrCodeBudgetExhausted || err.Type != ErrorTypeRateLimit || err.Retryable {
+		t.Errorf("Unexpected error: %+v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// chatAdminTimeout bounds waiting for the channel to apply a chat change
+const chatAdminTimeout = 10 * time.Second
+
+// ChatAdministrator changes chats through their channel (implemented by
+// loop.AgentMessageSender). The channel checks that the bot has the
+// administrator rights the change needs.
+type ChatAdministrator interface {
+	PinMessage(userID, channelType, sessionID, messageID string, timeout time.Duration) (*agent.MessageResult, error)
+	UnpinMessage(userID, channelType, sessionID, messageID string, timeout time.Duration) (*agent.MessageResult, error)
+	SetChatTitle(userID, channelType, sessionID, title string, timeout time.Duration) (*agent.MessageResult, error)
+}
+
+// ChatAdminArgs represents the arguments of the chat administration tools.
+type ChatAdminArgs struct {
+	SessionID string `json:"session_id"` // Chat to change (defaults to the current session)
+	MessageID string `json:"message_id"` // Message to pin or unpin
+	Title     string `json:"title"`      // New chat title (set_chat_title)
+}
+
+// PinMessageTool pins a message in a chat.
+type PinMessageTool struct {
+	admin  ChatAdministrator
+	logger *logger.Logger
+}
+
+// NewPinMessageTool creates a new PinMessageTool instance.
+func NewPinMessageTool(admin ChatAdministrator, logger *logger.Logger) *PinMessageTool {
+	return &PinMessageTool{admin: admin, logger: logger}
+}
+
+// Name returns the tool name.
+func (t *PinMessageTool) Name() string {
+	return "pin_message"
+}
+
+// Description returns a description of what the tool does.
+func (t *PinMessageTool) Description() string {
+	return "Pins a message in a chat, e.g. a daily summary you just sent. Without message_id the last message you sent to the chat is pinned. The bot must be a chat administrator allowed to pin messages."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *PinMessageTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"session_id": sessionIDParameter(),
+			"message_id": map[string]any{
+				"type":        "string",
+				"description": "ID of the message to pin. Defaults to the last message you sent to the chat.",
+			},
+		},
+	}
+}
+
+// Execute executes the pin_message tool.
+func (t *PinMessageTool) Execute(ctx context.Context, args string) (string, error) {
+	var params ChatAdminArgs
+	if err := parseJSON(args, &params); err != nil {
+		return "", fmt.Errorf("failed to parse pin_message arguments: %w", err)
+	}
+	return runChatAdmin(ctx, t.logger, t.Name(), params.SessionID, func(userID, channelType, sessionID string) (*agent.MessageResult, error) {
+		return t.admin.PinMessage(userID, channelType, sessionID, params.MessageID, chatAdminTimeout)
+	})
+}
+
+// UnpinMessageTool unpins a message in a chat.
+type UnpinMessageTool struct {
+	admin  ChatAdministrator
+	logger *logger.Logger
+}
+
+// NewUnpinMessageTool creates a new UnpinMessageTool instance.
+func NewUnpinMessageTool(admin ChatAdministrator, logger *logger.Logger) *UnpinMessageTool {
+	return &UnpinMessageTool{admin: admin, logger: logger}
+}
+
+// Name returns the tool name.
+func (t *UnpinMessageTool) Name() string {
+	return "unpin_message"
+}
+
+// Description returns a description of what the tool does.
+func (t *UnpinMessageTool) Description() string {
+	return "Unpins a message in a chat. Without message_id the most recently pinned message is unpinned. The bot must be a chat administrator allowed to pin messages."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *UnpinMessageTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"session_id": sessionIDParameter(),
+			"message_id": map[string]any{
+				"type":        "string",
+				"description": "ID of the message to unpin. Defaults to the most recently pinned message.",
+			},
+		},
+	}
+}
+
+// Execute executes the unpin_message tool.
+func (t *UnpinMessageTool) Execute(ctx context.Context, args string) (string, error) {
+	var params ChatAdminArgs
+	if err := parseJSON(args, &params); err != nil {
+		return "", fmt.Errorf("failed to parse unpin_message arguments: %w", err)
+	}
+	return runChatAdmin(ctx, t.logger, t.Name(), params.SessionID, func(userID, channelType, sessionID string) (*agent.MessageResult, error) {
+		return t.admin.UnpinMessage(userID, channelType, sessionID, params.MessageID, chatAdminTimeout)
+	})
+}
+
+// SetChatTitleTool renames a group chat.
+type SetChatTitleTool struct {
+	admin  ChatAdministrator
+	logger *logger.Logger
+}
+
+// NewSetChatTitleTool creates a new SetChatTitleTool instance.
+func NewSetChatTitleTool(admin ChatAdministrator, logger *logger.Logger) *SetChatTitleTool {
+	return &SetChatTitleTool{admin: admin, logger: logger}
+}
+
+// Name returns the tool name.
+func (t *SetChatTitleTool) Name() string {
+	return "set_chat_title"
+}
+
+// Description returns a description of what the tool does.
+func (t *SetChatTitleTool) Description() string {
+	return "Renames a group chat. Only works in groups and channels where the bot is an administrator allowed to change chat info."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *SetChatTitleTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"session_id": sessionIDParameter(),
+			"title": map[string]any{
+				"type":        "string",
+				"description": "New chat title (1-128 characters).",
+			},
+		},
+		"required": []string{"title"},
+	}
+}
+
+// Execute executes the set_chat_title tool.
+func (t *SetChatTitleTool) Execute(ctx context.Context, args string) (string, error) {
+	var params ChatAdminArgs
+	if err := parseJSON(args, &params); err != nil {
+		return "", fmt.Errorf("failed to parse set_chat_title arguments: %w", err)
+	}
+	title := strings.TrimSpace(params.Title)
+	if title == "" {
+		return "", fmt.Errorf("title parameter is required")
+	}
+	if len([]rune(title)) > 128 {
+		return "", fmt.Errorf("title is too long: %d characters (max 128)", len([]rune(title)))
+	}
+	return runChatAdmin(ctx, t.logger, t.Name(), params.SessionID, func(userID, channelType, sessionID string) (*agent.MessageResult, error) {
+		return t.admin.SetChatTitle(userID, channelType, sessionID, title, chatAdminTimeout)
+	})
+}
+
+// sessionIDParameter returns the schema of the session_id parameter.
+func sessionIDParameter() map[string]any {
+	return map[string]any{
+		"type":        "string",
+		"description": "Chat in 'channel:chat_id' format (e.g. 'telegram:-1001234567890'). Defaults to the current conversation.",
+	}
+}
+
+// runChatAdmin resolves the chat of a chat administration tool and applies the change.
+func runChatAdmin(ctx context.Context, log *logger.Logger, tool, sessionID string, apply func(userID, channelType, sessionID string) (*agent.MessageResult, error)) (string, error) {
+	if sessionID == "" {
+		sessionID = getSessionID(ctx)
+	}
+	channelType, chatID, ok := strings.Cut(sessionID, ":")
+	if !ok || channelType == "" || chatID == "" {
+		return "", errors.New("session_id must be in format 'channel:chat_id' (e.g., 'telegram:123456789')")
+	}
+
+	result, err := apply(chatID, channelType, sessionID)
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w", tool, err)
+	}
+	if !result.Success {
+		if result.Error != nil {
+			return "", fmt.Errorf("❌ %s failed\n\n%s", tool, result.Error.ToLLMContext())
+		}
+		return "", fmt.Errorf("❌ %s failed (no error details available)", tool)
+	}
+
+	log.Info("chat administration tool executed",
+		logger.Field{Key: "tool", Value: tool},
+		logger.Field{Key: "session_id", Value: sessionID})
+	return fmt.Sprintf("✅ %s done\n   Session: %s", tool, sessionID), nil
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChatAdmin records chat administration calls.
+type fakeChatAdmin struct {
+	calls  []string
+	result *agent.MessageResult
+}
+
+func (f *fakeChatAdmin) record(call string) (*agent.MessageResult, error) {
+	f.calls = append(f.calls, call)
+	if f.result != nil {
+		return f.result, nil
+	}
+	return &agent.MessageResult{Success: true}, nil
+}
+
+func (f *fakeChatAdmin) PinMessage(userID, channelType, sessionID, messageID string, timeout time.Duration) (*agent.MessageResult, error) {
+	return f.record("pin " + sessionID + " " + messageID)
+}
+
+func (f *fakeChatAdmin) UnpinMessage(userID, channelType, sessionID, messageID string, timeout time.Duration) (*agent.MessageResult, error) {
+	return f.record("unpin " + sessionID + " " + messageID)
+}
+
+func (f *fakeChatAdmin) SetChatTitle(userID, channelType, sessionID, title string, timeout time.Duration) (*agent.MessageResult, error) {
+	return f.record("title " + sessionID + " " + title)
+}
+
+func TestChatAdminTools(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	require.NoError(t, err)
+	admin := &fakeChatAdmin{}
+	ctx := context.WithValue(context.Background(), sessionIDKey, "telegram:-100123")
+
+	// The current session is used by default
+	result, err := NewPinMessageTool(admin, log).Execute(ctx, `{}`)
+	require.NoError(t, err)
+	assert.Contains(t, result, "pin_message done")
+
+	_, err = NewUnpinMessageTool(admin, log).Execute(ctx, `{"session_id": "telegram:-100456", "message_id": "7"}`)
+	require.NoError(t, err)
+
+	_, err = NewSetChatTitleTool(admin, log).Execute(ctx, `{"title": " Weekly sync "}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"pin telegram:-100123 ",
+		"unpin telegram:-100456 7",
+		"title telegram:-100123 Weekly sync",
+	}, admin.calls)
+
+	_, err = NewSetChatTitleTool(admin, log).Execute(ctx, `{"title": ""}`)
+	assert.ErrorContains(t, err, "title parameter is required")
+
+	_, err = NewPinMessageTool(admin, log).Execute(context.Background(), `{}`)
+	assert.ErrorContains(t, err, "session_id must be in format")
+
+	admin.result = &agent.MessageResult{Success: false}
+	_, err = NewPinMessageTool(admin, log).Execute(ctx, `{}`)
+	assert.ErrorContains(t, err, "pin_message failed")
+}
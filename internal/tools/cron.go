@@ -8,16 +8,27 @@ import (
 	"time"
 
 	"github.com/aatumaykin/nexbot/internal/agent"
+	"github.com/aatumaykin/nexbot/internal/forms"
 	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/users"
 )
 
 // CronTool implements the Tool interface for cron job management.
 // It allows scheduling, listing, and managing recurring tasks.
 type CronTool struct {
 	cronManager agent.CronManager
+	profiles    SessionUsers
 	logger      *logger.Logger
 }
 
+// SessionUsers finds the user of a session (implemented by users.Registry).
+type SessionUsers interface {
+	FindBySession(sessionID string) (users.User, bool)
+}
+
+// cronTZPrefix sets the timezone of a cron expression
+const cronTZPrefix = "CRON_TZ="
+
 // CronArgs represents the arguments for the cron tool.
 type CronArgs struct {
 	Action    string `json:"action"`     // Action: "add_recurring", "add_oneshot", "remove", "list"
@@ -37,6 +48,25 @@ func NewCronTool(cronManager agent.CronManager, logger *logger.Logger) *CronTool
 	}
 }
 
+// SetProfiles enables user timezones: recurring schedules of a session whose
+// user has a timezone run in that timezone.
+func (t *CronTool) SetProfiles(profiles SessionUsers) {
+	t.profiles = profiles
+}
+
+// localizeSchedule prefixes a schedule with the timezone of the session's
+// user unless it already sets one.
+func (t *CronTool) localizeSchedule(schedule, sessionID string) string {
+	if t.profiles == nil || sessionID == "" || strings.HasPrefix(schedule, cronTZPrefix) || strings.HasPrefix(schedule, "TZ=") {
+		return schedule
+	}
+	u, ok := t.profiles.FindBySession(sessionID)
+	if !ok || u.Location() == nil {
+		return schedule
+	}
+	return cronTZPrefix + u.Timezone + " " + schedule
+}
+
 // Name returns the tool name.
 func (t *CronTool) Name() string {
 	return "cron"
@@ -72,7 +102,7 @@ func (t *CronTool) Parameters() map[string]any {
 			},
 			"payload": map[string]any{
 				"type":        "string",
-				"description": "JSON string with parameters for the tool. For 'send_message' or 'agent', this should be {\"message\": \"your text\"}. Required when tool is not empty.",
+				"description": "JSON string with parameters for the tool. For 'send_message' or 'agent', this should be {\"message\": \"your text\"}; 'send_message' also accepts a message template: {\"template\": \"name\", \"data\": {...}}. Required when tool is not empty.",
 			},
 			"session_id": map[string]any{
 				"type":        "string",
@@ -89,7 +119,7 @@ func (t *CronTool) Parameters() map[string]any {
 
 // Execute executes the cron tool.
 // args is a JSON-encoded string containing the tool's input parameters.
-func (t *CronTool) Execute(args string) (string, error) {
+func (t *CronTool) Execute(ctx context.Context, args string) (string, error) {
 	// Parse arguments
 	var params CronArgs
 	if err := json.Unmarshal([]byte(args), &params); err != nil {
@@ -157,6 +187,8 @@ func (t *CronTool) addRecurring(ctx context.Context, params map[string]any) (str
 		return "", fmt.Errorf("session_id is required for send_message and agent tools")
 	}
 
+	schedule = t.localizeSchedule(schedule, sessionID)
+
 	// Create job using domain model
 	job := agent.Job{
 		Type:      "recurring",
@@ -311,6 +343,34 @@ func (t *CronTool) listJobs(ctx context.Context, params map[string]any) (string,
 	return result.String(), nil
 }
 
+// FormFields returns the fields required by the action, so missing ones are asked from the user.
+func (t *CronTool) FormFields(args map[string]any) []forms.Field {
+	toolField := forms.Field{
+		Name:     "tool",
+		Question: "What should the job do?",
+		Type:     forms.FieldChoice,
+		Options:  []string{"send_message", "agent"},
+	}
+
+	switch args["action"] {
+	case "add_recurring":
+		return []forms.Field{
+			{Name: "schedule", Question: "How often should the job run? Cron expression, e.g. 0 0 9 * * *"},
+			toolField,
+		}
+	case "add_oneshot":
+		return []forms.Field{
+			{Name: "execute_at", Question: "When should the job run? E.g. 2026-03-01 18:00", Type: forms.FieldDateTime},
+			toolField,
+		}
+	case "remove":
+		return []forms.Field{
+			{Name: "job_id", Question: "Which job should be removed? Job ID"},
+		}
+	}
+	return nil
+}
+
 // ToSchema returns the OpenAI-compatible schema for this tool.
 func (t *CronTool) ToSchema() map[string]any {
 	return t.Parameters()
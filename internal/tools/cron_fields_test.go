@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -45,7 +46,7 @@ func TestCronToolFieldsPreservation(t *testing.T) {
 	}
 
 	argsJSON, _ := json.Marshal(args)
-	result, err := tool.Execute(string(argsJSON))
+	result, err := tool.Execute(context.Background(), string(argsJSON))
 	require.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "One-time job added successfully", "Result should contain success message")
 
@@ -114,7 +115,7 @@ func TestCronToolFieldsPreservation(t *testing.T) {
 	}
 
 	argsJSON2, _ := json.Marshal(args2)
-	result2, err := tool.Execute(string(argsJSON2))
+	result2, err := tool.Execute(context.Background(), string(argsJSON2))
 	require.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result2, "Recurring job added successfully", "Result should contain success message")
 
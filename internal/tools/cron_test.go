@@ -10,6 +10,7 @@ import (
 	"github.com/aatumaykin/nexbot/internal/bus"
 	"github.com/aatumaykin/nexbot/internal/cron"
 	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/users"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -118,13 +119,38 @@ func TestCronToolAddRecurring(t *testing.T) {
 		"session_id": "telegram:123456789"
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "Recurring job added successfully", "Result should contain success message")
 	assert.Contains(t, result, "0 0 0 * * *", "Result should contain schedule")
 	assert.Contains(t, result, "test command", "Result should contain message")
 }
 
+// sessionUsers maps session IDs to users
+type sessionUsers map[string]users.User
+
+func (s sessionUsers) FindBySession(sessionID string) (users.User, bool) {
+	u, ok := s[sessionID]
+	return u, ok
+}
+
+// TestCronToolAddRecurring_UserTimezone tests that schedules run in the user's timezone.
+func TestCronToolAddRecurring_UserTimezone(t *testing.T) {
+	tool := setupCronTool(t)
+	tool.SetProfiles(sessionUsers{"telegram:1": {ID: "alice", Timezone: "Europe/Berlin"}})
+
+	add := func(schedule, sessionID string) string {
+		args := `{"action": "add_recurring", "schedule": "` + schedule + `", "tool": "send_message", "payload": "{\"message\": \"hi\"}", "session_id": "` + sessionID + `"}`
+		result, err := tool.Execute(context.Background(), args)
+		require.NoError(t, err)
+		return result
+	}
+
+	assert.Contains(t, add("0 0 9 * * *", "telegram:1"), "Schedule: CRON_TZ=Europe/Berlin 0 0 9 * * *")
+	assert.Contains(t, add("CRON_TZ=UTC 0 0 9 * * *", "telegram:1"), "Schedule: CRON_TZ=UTC 0 0 9 * * *")
+	assert.Contains(t, add("0 0 9 * * *", "telegram:2"), "Schedule: 0 0 9 * * *")
+}
+
 // TestCronToolAddOneshot tests adding a one-time job.
 func TestCronToolAddOneshot(t *testing.T) {
 	tool := setupCronTool(t)
@@ -138,7 +164,7 @@ func TestCronToolAddOneshot(t *testing.T) {
 		"session_id": "telegram:123456789"
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "One-time job added successfully", "Result should contain success message")
 	assert.Contains(t, result, "test command", "Result should contain message")
@@ -161,7 +187,7 @@ func TestCronToolRemoveJob(t *testing.T) {
 		"session_id": "telegram:123456789"
 	}`
 
-	addResult, err := tool.Execute(addArgs)
+	addResult, err := tool.Execute(context.Background(), addArgs)
 	require.NoError(t, err, "Failed to add job")
 
 	// Extract job ID from result (format: "   Job ID: <id>")
@@ -185,7 +211,7 @@ func TestCronToolRemoveJob(t *testing.T) {
 		"job_id": "` + jobID + `"
 	}`
 
-	result, err := tool.Execute(removeArgs)
+	result, err := tool.Execute(context.Background(), removeArgs)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "Job removed successfully", "Result should contain success message")
 	assert.Contains(t, result, jobID, "Result should contain job ID")
@@ -208,7 +234,7 @@ func TestCronToolListJobs(t *testing.T) {
 		"session_id": "telegram:123456789"
 	}`
 
-	_, err := tool.Execute(addArgs)
+	_, err := tool.Execute(context.Background(), addArgs)
 	require.NoError(t, err, "Failed to add job")
 
 	// List jobs
@@ -216,7 +242,7 @@ func TestCronToolListJobs(t *testing.T) {
 		"action": "list"
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "command1", "Result should contain command1")
 }
@@ -229,7 +255,7 @@ func TestCronToolListJobs_Empty(t *testing.T) {
 		"action": "list"
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "No scheduled jobs found", "Result should indicate no jobs")
 }
@@ -242,7 +268,7 @@ func TestCronToolInvalidAction(t *testing.T) {
 		"action": "invalid_action"
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.Error(t, err, "Execute should return error for invalid action")
 	assert.Empty(t, result, "Result should be empty on error")
 	assert.Contains(t, err.Error(), "invalid action", "Error should mention 'invalid action'")
@@ -296,7 +322,7 @@ func TestCronToolMissingRequiredParams(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			tool := setupCronTool(t)
-			_, err := tool.Execute(tc.args)
+			_, err := tool.Execute(context.Background(), tc.args)
 			assert.Error(t, err, "Execute should return error")
 			assert.Contains(t, err.Error(), tc.expectedErr, "Error should contain expected message")
 		})
@@ -309,7 +335,7 @@ func TestCronToolInvalidJSON(t *testing.T) {
 
 	args := `{invalid json`
 
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 	assert.Error(t, err, "Execute should return error for invalid JSON")
 	assert.Contains(t, err.Error(), "failed to parse cron arguments", "Error should mention parse error")
 }
@@ -326,7 +352,7 @@ func TestCronToolInvalidExecuteAt(t *testing.T) {
 		"session_id": "telegram:123456789"
 	}`
 
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 	assert.Error(t, err, "Execute should return error for invalid date")
 	assert.Contains(t, err.Error(), "invalid execute_at format", "Error should mention date format")
 }
@@ -338,3 +364,23 @@ func TestCronToolToSchema(t *testing.T) {
 	assert.NotNil(t, schema, "Schema should not be nil")
 	assert.Equal(t, tool.Parameters(), schema, "Schema should match parameters")
 }
+
+// TestCronToolFormFields tests the fields asked from the user for each action.
+func TestCronToolFormFields(t *testing.T) {
+	tool := setupCronTool(t)
+
+	names := func(action string) []string {
+		var result []string
+		for _, field := range tool.FormFields(map[string]any{"action": action}) {
+			result = append(result, field.Name)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"schedule", "tool"}, names("add_recurring"))
+	assert.Equal(t, []string{"execute_at", "tool"}, names("add_oneshot"))
+	assert.Equal(t, []string{"job_id"}, names("remove"))
+	assert.Empty(t, names("list"), "List needs no fields")
+
+	var _ FormTool = tool
+}
@@ -0,0 +1,43 @@
+package tools
+
+import "context"
+
+// DryRunPrefix starts the results of calls that were not executed in dry-run mode
+const DryRunPrefix = "[dry run, not executed] "
+
+// DryRunTool is an optional interface of tools that change something outside
+// the agent (files, processes, remote services). In dry-run mode such tools
+// report what a call would do instead of doing it.
+type DryRunTool interface {
+	Tool
+
+	// DryRun describes what a call with the given arguments would do (a diff,
+	// a command, a request). mutates is false for calls that change nothing
+	// (a GET request); they run as usual. Invalid calls return the error the
+	// call would fail with.
+	DryRun(ctx context.Context, args string) (report string, mutates bool, err error)
+}
+
+// ReadOnlyTool is an optional interface of tools that change nothing outside
+// the agent. In dry-run mode they run as usual; calls of tools that are
+// neither read-only nor DryRunTool are not executed.
+type ReadOnlyTool interface {
+	Tool
+
+	// ReadOnly reports whether the tool only reads.
+	ReadOnly() bool
+}
+
+// dryRunKey is the context key enabling dry-run mode
+type dryRunKey struct{}
+
+// WithDryRun enables dry-run mode for the tool calls run with ctx.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// DryRun reports whether ctx runs tool calls in dry-run mode.
+func DryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
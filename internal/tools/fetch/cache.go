@@ -0,0 +1,149 @@
+package fetch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// diskCache stores responses that carry validators (ETag or Last-Modified)
+// in a directory, one body file and one metadata file per URL. A cached
+// response is used after the server confirms it with 304 Not Modified.
+type diskCache struct {
+	dir string
+}
+
+// cacheEntry is the metadata of a cached response.
+type cacheEntry struct {
+	URL          string      `json:"url"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	StatusCode   int         `json:"status_code"`
+	Status       string      `json:"status"`
+	Header       http.Header `json:"header"`
+	StoredAt     time.Time   `json:"stored_at"`
+
+	key string
+}
+
+// key returns the file name of a URL in the cache.
+func (c *diskCache) key(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *diskCache) metaPath(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *diskCache) bodyPath(key string) string {
+	return filepath.Join(c.dir, key+".body")
+}
+
+// load returns the cached response of a URL, or nil if there is none.
+func (c *diskCache) load(url string) *cacheEntry {
+	key := c.key(url)
+	data, err := os.ReadFile(c.metaPath(key))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != url {
+		return nil
+	}
+	if _, err := os.Stat(c.bodyPath(key)); err != nil {
+		return nil
+	}
+	entry.key = key
+	return &entry
+}
+
+// open opens the body of a cached response.
+func (c *diskCache) open(entry *cacheEntry) (*os.File, error) {
+	return os.Open(c.bodyPath(entry.key))
+}
+
+// create returns a writer for the body of a response to store. The body is
+// written to a temporary file and replaces the cached one on commit.
+func (c *diskCache) create(url string) (*cacheWriter, error) {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	f, err := os.CreateTemp(c.dir, "body-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache file: %w", err)
+	}
+	return &cacheWriter{cache: c, key: c.key(url), file: f}, nil
+}
+
+// cacheWriter writes the body of a response to the cache.
+type cacheWriter struct {
+	cache     *diskCache
+	key       string
+	file      *os.File
+	committed bool
+}
+
+func (w *cacheWriter) Write(p []byte) (int, error) {
+	return w.file.Write(p)
+}
+
+// commit stores the written body with its metadata.
+func (w *cacheWriter) commit(entry *cacheEntry) error {
+	w.committed = true
+	if err := w.file.Close(); err != nil {
+		_ = os.Remove(w.file.Name())
+		return err
+	}
+	if err := os.Rename(w.file.Name(), w.cache.bodyPath(w.key)); err != nil {
+		_ = os.Remove(w.file.Name())
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	meta, err := os.CreateTemp(w.cache.dir, "meta-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := meta.Write(data); err != nil {
+		meta.Close()
+		_ = os.Remove(meta.Name())
+		return err
+	}
+	if err := meta.Close(); err != nil {
+		_ = os.Remove(meta.Name())
+		return err
+	}
+	return os.Rename(meta.Name(), w.cache.metaPath(w.key))
+}
+
+// discard drops the written body unless it was committed.
+func (w *cacheWriter) discard() {
+	if w.committed {
+		return
+	}
+	w.file.Close()
+	_ = os.Remove(w.file.Name())
+}
+
+// isCacheable reports whether a response may be stored: a complete 200
+// response with a validator that doesn't forbid storing.
+func isCacheable(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	if resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" {
+		return false
+	}
+	cacheControl := strings.ToLower(resp.Header.Get("Cache-Control"))
+	return !strings.Contains(cacheControl, "no-store") && !strings.Contains(cacheControl, "private")
+}
@@ -0,0 +1,91 @@
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+func TestFetchTool_Execute_CacheRevalidation(t *testing.T) {
+	var full, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<p>Cached page</p>"))
+	}))
+	defer server.Close()
+
+	log, _ := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	tool := NewFetchTool(testConfig(), log)
+	tool.SetCacheDir(t.TempDir())
+
+	fetchPage := func(args map[string]any) map[string]any {
+		t.Helper()
+		data, _ := json.Marshal(args)
+		result, err := tool.Execute(context.Background(), string(data))
+		require.NoError(t, err)
+		var resultJSON map[string]any
+		require.NoError(t, json.Unmarshal([]byte(result), &resultJSON))
+		return resultJSON
+	}
+
+	first := fetchPage(map[string]any{"url": server.URL})
+	assert.Equal(t, "Cached page", first["content"])
+	assert.Nil(t, first["cached"])
+
+	second := fetchPage(map[string]any{"url": server.URL, "format": "markdown"})
+	assert.Equal(t, "Cached page", second["content"])
+	assert.Equal(t, true, second["cached"])
+	assert.Equal(t, float64(http.StatusOK), second["status"])
+	assert.Equal(t, int32(1), full.Load())
+	assert.Equal(t, int32(1), notModified.Load())
+
+	// Requests with credentials bypass the cache
+	fetchPage(map[string]any{"url": server.URL, "headers": map[string]string{"Authorization": "Bearer x"}})
+	assert.Equal(t, int32(2), full.Load())
+}
+
+func TestFetchTool_Execute_CacheSkipsUncacheable(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") != "" {
+			t.Error("unexpected conditional request")
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write([]byte("fresh"))
+	}))
+	defer server.Close()
+
+	log, _ := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	tool := NewFetchTool(testConfig(), log)
+	dir := t.TempDir()
+	tool.SetCacheDir(dir)
+
+	args, _ := json.Marshal(map[string]string{"url": server.URL})
+	for range 2 {
+		_, err := tool.Execute(context.Background(), string(args))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), requests.Load())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
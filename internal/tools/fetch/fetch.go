@@ -1,26 +1,33 @@
 package fetch
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"regexp"
+	"slices"
 	"strings"
 	"time"
 
-	"github.com/JohannesKaufmann/html-to-markdown"
-	"github.com/PuerkitoBio/goquery"
 	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/egress"
 	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/netguard"
+	"github.com/aatumaykin/nexbot/internal/tools"
+	"golang.org/x/net/html"
 )
 
 type FetchTool struct {
-	cfg       *config.Config
-	logger    *logger.Logger
-	resolver  func(string, string) string
-	sessionID string
+	cfg        *config.Config
+	logger     *logger.Logger
+	resolver   func(string, string) string
+	sessionID  string
+	network    *egress.Network
+	politeness *politeness
+	cache      *diskCache
 }
 
 type FetchArgs struct {
@@ -42,8 +49,9 @@ type BasicAuth struct {
 
 func NewFetchTool(cfg *config.Config, log *logger.Logger) *FetchTool {
 	return &FetchTool{
-		cfg:    cfg,
-		logger: log,
+		cfg:        cfg,
+		logger:     log,
+		politeness: newPoliteness(),
 	}
 }
 
@@ -51,6 +59,27 @@ func (t *FetchTool) Name() string {
 	return "web_fetch"
 }
 
+// ConfigSection returns the configuration table of the tool ([tools.fetch]).
+func (t *FetchTool) ConfigSection() string {
+	return "fetch"
+}
+
+// ConfigSchema returns the keys of [tools.fetch].
+func (t *FetchTool) ConfigSchema() []tools.ConfigField {
+	return []tools.ConfigField{
+		{Key: "enabled", Type: tools.ConfigBool},
+		{Key: "timeout_seconds", Type: tools.ConfigInt},
+		{Key: "max_response_size", Type: tools.ConfigInt},
+		{Key: "user_agent", Type: tools.ConfigString},
+		{Key: "allow_private_networks", Type: tools.ConfigBool},
+		{Key: "allowed_domains", Type: tools.ConfigStringList},
+		{Key: "denied_domains", Type: tools.ConfigStringList},
+		{Key: "respect_robots", Type: tools.ConfigBool},
+		{Key: "min_interval_ms", Type: tools.ConfigInt},
+		{Key: "cache_dir", Type: tools.ConfigString},
+	}
+}
+
 func (t *FetchTool) Description() string {
 	return "Fetch content from a URL. Returns formatted text with metadata."
 }
@@ -65,9 +94,9 @@ func (t *FetchTool) Parameters() map[string]any {
 			},
 			"format": map[string]any{
 				"type":        "string",
-				"enum":        []string{"text", "html", "markdown", "json"},
+				"enum":        []string{"text", "html", "markdown", "readability", "json"},
 				"default":     "text",
-				"description": "Output format: 'text' (strips HTML tags), 'html' (raw HTML), 'markdown' (converts HTML to Markdown), or 'json' (parse JSON response)",
+				"description": "Output format: 'text' (strips HTML tags), 'html' (raw HTML), 'markdown' (converts HTML to Markdown), 'readability' (only the main article as Markdown, without navigation, ads and comments; adds title and byline), or 'json' (parse JSON response)",
 			},
 			"headers": map[string]any{
 				"type":        "object",
@@ -131,7 +160,71 @@ func (t *FetchTool) SetSessionID(sessionID string) {
 	t.sessionID = sessionID
 }
 
-func (t *FetchTool) Execute(args string) (string, error) {
+// SetNetwork sets the outbound proxy, CA bundle and DNS overrides for requests.
+func (t *FetchTool) SetNetwork(network *egress.Network) {
+	t.network = network
+}
+
+// SetCacheDir enables the on-disk response cache in dir.
+func (t *FetchTool) SetCacheDir(dir string) {
+	t.cache = &diskCache{dir: dir}
+}
+
+// Execute fetches the URL, reporting download progress through ctx.
+// Cancelling ctx aborts the request.
+// maxDryRunBody limits the request body shown by DryRun
+const maxDryRunBody = 2000
+
+// DryRun reports the request a call would send. Only requests that can
+// change something remotely (POST, PUT, PATCH, DELETE, ...) are reported;
+// GET, HEAD and OPTIONS requests run as usual. Secrets stay unresolved.
+func (t *FetchTool) DryRun(ctx context.Context, args string) (string, bool, error) {
+	var fetchArgs FetchArgs
+	if err := json.Unmarshal([]byte(args), &fetchArgs); err != nil {
+		return "", true, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	method := strings.ToUpper(fetchArgs.Method)
+	if method == "" || method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+		return "", false, nil
+	}
+
+	if fetchArgs.URL == "" {
+		return "", true, fmt.Errorf("url is required")
+	}
+	if !t.cfg.Tools.Fetch.Enabled {
+		return "", true, fmt.Errorf("web_fetch tool is disabled in configuration")
+	}
+	if !strings.HasPrefix(fetchArgs.URL, "http://") && !strings.HasPrefix(fetchArgs.URL, "https://") {
+		return "", true, fmt.Errorf("url must start with http:// or https://")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Would send %s %s", method, fetchArgs.URL)
+	names := make([]string, 0, len(fetchArgs.Headers))
+	for name := range fetchArgs.Headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n%s: %s", name, fetchArgs.Headers[name])
+	}
+	if fetchArgs.BasicAuth != nil && fetchArgs.BasicAuth.Username != "" {
+		fmt.Fprintf(&b, "\nAuthorization: Basic %s:***", fetchArgs.BasicAuth.Username)
+	}
+	if len(fetchArgs.Cookies) > 0 {
+		fmt.Fprintf(&b, "\nCookie: %d cookies", len(fetchArgs.Cookies))
+	}
+	if fetchArgs.Body != "" && method != http.MethodDelete {
+		body := fetchArgs.Body
+		if len([]rune(body)) > maxDryRunBody {
+			body = string([]rune(body)[:maxDryRunBody]) + "…"
+		}
+		b.WriteString("\n\n" + body)
+	}
+	return b.String(), true, nil
+}
+
+func (t *FetchTool) Execute(ctx context.Context, args string) (string, error) {
 	var fetchArgs FetchArgs
 	if err := json.Unmarshal([]byte(args), &fetchArgs); err != nil {
 		return "", fmt.Errorf("failed to parse arguments: %w", err)
@@ -165,15 +258,9 @@ func (t *FetchTool) Execute(args string) (string, error) {
 		timeout = time.Duration(*fetchArgs.Timeout) * time.Second
 	}
 
-	client := &http.Client{
-		Timeout: timeout,
-	}
-
-	if fetchArgs.FollowRedirects != nil && !*fetchArgs.FollowRedirects {
-		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		}
-	}
+	policy := t.networkPolicy()
+	followRedirects := fetchArgs.FollowRedirects == nil || *fetchArgs.FollowRedirects
+	client := policy.Client(timeout, followRedirects)
 
 	var bodyReader io.Reader
 	if fetchArgs.Body != "" {
@@ -189,10 +276,13 @@ func (t *FetchTool) Execute(args string) (string, error) {
 		return "", fmt.Errorf("url must start with http:// or https://")
 	}
 
-	req, err := http.NewRequest(fetchArgs.Method, url, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, fetchArgs.Method, url, bodyReader)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
+	if err := policy.CheckURL(req.URL); err != nil {
+		return "", err
+	}
 	req.Header.Set("User-Agent", t.cfg.Tools.Fetch.UserAgent)
 	req.Header.Set("Accept", "*/*")
 	if fetchArgs.Body != "" {
@@ -233,6 +323,26 @@ func (t *FetchTool) Execute(args string) (string, error) {
 		req.Header.Set("Cookie", strings.Join(cookiePairs, "; "))
 	}
 
+	// Only plain GET requests are cached: responses to requests with
+	// credentials or custom headers are never written to disk
+	var cached *cacheEntry
+	cacheable := t.cache != nil && fetchArgs.Method == http.MethodGet &&
+		len(fetchArgs.Headers) == 0 && fetchArgs.BasicAuth == nil && len(fetchArgs.Cookies) == 0
+	if cacheable {
+		if cached = t.cache.load(url); cached != nil {
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
+	}
+
+	if err := t.beforeRequest(ctx, client, req.URL); err != nil {
+		return "", err
+	}
+
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("request failed: %w", err)
@@ -244,46 +354,105 @@ func (t *FetchTool) Execute(args string) (string, error) {
 			resp.ContentLength, t.cfg.Tools.Fetch.MaxResponseSize)
 	}
 
-	limitReader := io.LimitReader(resp.Body, t.cfg.Tools.Fetch.MaxResponseSize)
-	body, err := io.ReadAll(limitReader)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+	// The body is processed as it arrives: HTML is converted without holding
+	// the raw page, and reading stops as soon as the size limit is reached.
+	var source io.Reader = tools.NewProgressReader(ctx, resp.Body, resp.ContentLength)
+	statusCode, status, header := resp.StatusCode, resp.Status, resp.Header
+	fromCache := false
+	var store *cacheWriter
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		f, err := t.cache.open(cached)
+		if err != nil {
+			return "", fmt.Errorf("failed to read cached response: %w", err)
+		}
+		defer f.Close()
+		source, fromCache = f, true
+		statusCode, status, header = cached.StatusCode, cached.Status, cached.Header
+	case cacheable && isCacheable(resp):
+		if store, err = t.cache.create(url); err != nil {
+			t.logger.Warn("Failed to cache response", logger.Field{Key: "error", Value: err.Error()})
+		} else {
+			defer store.discard()
+			source = io.TeeReader(source, store)
+		}
 	}
-
-	if int64(len(body)) >= t.cfg.Tools.Fetch.MaxResponseSize {
+	body := newCappedReader(source, t.cfg.Tools.Fetch.MaxResponseSize)
+	contentType := header.Get("Content-Type")
+	isHTML := strings.Contains(contentType, "text/html")
+
+	var content string
+	var raw []byte
+	var page *article
+	switch {
+	case fetchArgs.Format == "text" && isHTML:
+		content, err = htmlToText(body)
+	case fetchArgs.Format == "markdown" && isHTML:
+		content, err = renderMarkdown(body)
+	case fetchArgs.Format == "readability" && isHTML:
+		page, err = extractArticle(body)
+		if page != nil {
+			content = page.Content
+		}
+	default:
+		raw, err = io.ReadAll(body)
+		content = string(raw)
+	}
+	if errors.Is(err, errResponseTooLarge) {
 		return "", fmt.Errorf("response truncated: exceeds %d bytes limit", t.cfg.Tools.Fetch.MaxResponseSize)
 	}
-
-	contentType := resp.Header.Get("Content-Type")
-	content := string(body)
-
-	if fetchArgs.Format == "text" && strings.Contains(contentType, "text/html") {
-		content = t.stripHTML(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if fetchArgs.Format == "markdown" && strings.Contains(contentType, "text/html") {
-		content = t.htmlToMarkdown(content)
+	// The whole body goes to the cache even if the conversion stopped early
+	if _, drainErr := io.Copy(io.Discard, body); store != nil && drainErr == nil {
+		err := store.commit(&cacheEntry{
+			URL:          url,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			StatusCode:   resp.StatusCode,
+			Status:       resp.Status,
+			Header:       resp.Header,
+			StoredAt:     time.Now(),
+		})
+		if err != nil {
+			t.logger.Warn("Failed to cache response", logger.Field{Key: "error", Value: err.Error()})
+		}
 	}
 
 	result := map[string]any{
 		"url":         fetchArgs.URL,
-		"status":      resp.StatusCode,
-		"statusText":  resp.Status,
+		"status":      statusCode,
+		"statusText":  status,
 		"contentType": contentType,
 		"length":      len(content),
 		"content":     content,
 	}
 
+	if page != nil {
+		if page.Title != "" {
+			result["title"] = page.Title
+		}
+		if page.Byline != "" {
+			result["byline"] = page.Byline
+		}
+	}
+
 	if fetchArgs.Format == "json" {
 		var jsonData any
-		if err := json.Unmarshal(body, &jsonData); err != nil {
+		if err := json.Unmarshal(raw, &jsonData); err != nil {
 			return "", fmt.Errorf("failed to parse JSON response: %w", err)
 		}
 		result["json"] = jsonData
 	}
 
 	headers := make(map[string]string)
-	for key, values := range resp.Header {
+	if fromCache {
+		result["cached"] = true
+	}
+
+	for key, values := range header {
 		if len(values) > 0 {
 			headers[key] = values[0]
 		}
@@ -298,52 +467,104 @@ func (t *FetchTool) Execute(args string) (string, error) {
 	return string(resultJSON), nil
 }
 
-func (t *FetchTool) stripHTML(html string) string {
-	reScript := regexp.MustCompile(`(?i)<script[^>]*>.*?</script>`)
-	html = reScript.ReplaceAllString(html, "")
-
-	reStyle := regexp.MustCompile(`(?i)<style[^>]*>.*?</style>`)
-	html = reStyle.ReplaceAllString(html, "")
+// networkPolicy returns the SSRF policy from the fetch tool configuration.
+func (t *FetchTool) networkPolicy() *netguard.Policy {
+	return &netguard.Policy{
+		AllowPrivate:   t.cfg.Tools.Fetch.AllowPrivateNetworks,
+		AllowedDomains: t.cfg.Tools.Fetch.AllowedDomains,
+		DeniedDomains:  t.cfg.Tools.Fetch.DeniedDomains,
+		Network:        t.network,
+	}
+}
 
-	reTags := regexp.MustCompile(`<[^>]+>`)
-	html = reTags.ReplaceAllString(html, "\n")
+// errResponseTooLarge is returned by cappedReader once the size limit is reached.
+var errResponseTooLarge = errors.New("response too large")
 
-	reSpace := regexp.MustCompile(`\s+`)
-	html = reSpace.ReplaceAllString(html, " ")
+// cappedReader fails with errResponseTooLarge as soon as limit bytes are read,
+// so a large body is rejected without being read to the end.
+type cappedReader struct {
+	r     io.Reader
+	read  int64
+	limit int64
+}
 
-	return strings.TrimSpace(html)
+func newCappedReader(r io.Reader, limit int64) *cappedReader {
+	return &cappedReader{r: r, limit: limit}
 }
 
-func (t *FetchTool) htmlToMarkdown(html string) string {
-	opts := &md.Options{
-		HeadingStyle:    "atx",
-		CodeBlockStyle:  "fenced",
-		EmDelimiter:     "*",
-		StrongDelimiter: "**",
+func (c *cappedReader) Read(p []byte) (int, error) {
+	if c.read >= c.limit {
+		return 0, errResponseTooLarge
+	}
+	if left := c.limit - c.read; int64(len(p)) > left {
+		p = p[:left]
 	}
+	n, err := c.r.Read(p)
+	c.read += int64(n)
+	if c.read >= c.limit {
+		return n, errResponseTooLarge
+	}
+	return n, err
+}
 
-	converter := md.NewConverter("", true, opts)
+func (t *FetchTool) stripHTML(s string) string {
+	text, _ := htmlToText(strings.NewReader(s))
+	return text
+}
 
-	converter.Keep("a", "img")
+// htmlToText converts an HTML stream to plain text token by token, without
+// holding the page in memory: script and style contents are dropped, tags
+// separate words, whitespace collapses to single spaces and entities are
+// decoded.
+func htmlToText(r io.Reader) (string, error) {
+	z := html.NewTokenizer(r)
+	var b strings.Builder
+	skip := 0 // Depth inside script and style elements
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if errors.Is(z.Err(), io.EOF) {
+				return b.String(), nil
+			}
+			return "", z.Err()
+		case html.StartTagToken:
+			if isSkippedElement(z) {
+				skip++
+			}
+		case html.EndTagToken:
+			if isSkippedElement(z) && skip > 0 {
+				skip--
+			}
+		case html.TextToken:
+			if skip > 0 {
+				continue
+			}
+			for _, word := range strings.Fields(string(z.Text())) {
+				if b.Len() > 0 {
+					b.WriteByte(' ')
+				}
+				b.WriteString(word)
+			}
+		}
+	}
+}
 
-	converter.AddRules(md.Rule{
-		Filter: []string{"nav", "footer", "aside", "script", "style"},
-		Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
-			return new("")
-		},
-	})
+// isSkippedElement reports whether the current tag starts or ends an element
+// whose text is not content.
+func isSkippedElement(z *html.Tokenizer) bool {
+	name, _ := z.TagName()
+	return string(name) == "script" || string(name) == "style"
+}
 
-	markdown, err := converter.ConvertString(html)
+func (t *FetchTool) htmlToMarkdown(s string) string {
+	markdown, err := renderMarkdown(strings.NewReader(s))
 	if err != nil {
 		t.logger.Error("Failed to convert HTML to Markdown", err)
 		return ""
 	}
-
-	reSpace := regexp.MustCompile(`\s+`)
-	markdown = reSpace.ReplaceAllString(markdown, " ")
-
-	reCleanNewlines := regexp.MustCompile(`\n{3,}`)
-	markdown = reCleanNewlines.ReplaceAllString(markdown, "\n\n")
-
-	return strings.TrimSpace(markdown)
+	return markdown
 }
+
+// Ensure FetchTool implements Tool interface
+var _ tools.Tool = (*FetchTool)(nil)
@@ -1,6 +1,7 @@
 package fetch
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"io"
@@ -21,10 +22,11 @@ func testConfig() *config.Config {
 	return &config.Config{
 		Tools: config.ToolsConfig{
 			Fetch: config.FetchToolConfig{
-				Enabled:         true,
-				TimeoutSeconds:  10,
-				MaxResponseSize: 1024 * 1024,
-				UserAgent:       "nexbot/1.0",
+				Enabled:              true,
+				TimeoutSeconds:       10,
+				MaxResponseSize:      1024 * 1024,
+				UserAgent:            "nexbot/1.0",
+				AllowPrivateNetworks: true, // Test servers listen on localhost
 			},
 		},
 	}
@@ -96,7 +98,7 @@ func TestFetchTool_Execute_Success(t *testing.T) {
 		"url": server.URL,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -142,7 +144,7 @@ func TestFetchTool_Execute_InvalidURL(t *testing.T) {
 				"url": tt.url,
 			})
 
-			_, err := tool.Execute(string(args))
+			_, err := tool.Execute(context.Background(), string(args))
 
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), tt.errorContains)
@@ -179,7 +181,7 @@ func TestFetchTool_Execute_HTMLStrip(t *testing.T) {
 		"format": "text",
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -226,7 +228,7 @@ func TestFetchTool_Execute_RawHTML(t *testing.T) {
 		"format": "html",
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -252,10 +254,11 @@ func TestFetchTool_Execute_Timeout(t *testing.T) {
 	cfg := &config.Config{
 		Tools: config.ToolsConfig{
 			Fetch: config.FetchToolConfig{
-				Enabled:         true,
-				TimeoutSeconds:  1,
-				MaxResponseSize: 1024 * 1024,
-				UserAgent:       "nexbot/1.0",
+				Enabled:              true,
+				TimeoutSeconds:       1,
+				MaxResponseSize:      1024 * 1024,
+				UserAgent:            "nexbot/1.0",
+				AllowPrivateNetworks: true, // Test servers listen on localhost
 			},
 		},
 	}
@@ -265,12 +268,38 @@ func TestFetchTool_Execute_Timeout(t *testing.T) {
 		"url": server.URL,
 	})
 
-	_, err := tool.Execute(string(args))
+	_, err := tool.Execute(context.Background(), string(args))
 
 	require.Error(t, err)
 	assert.Contains(t, strings.ToLower(err.Error()), "timeout")
 }
 
+func TestFetchTool_Execute_Cancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	log, _ := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	tool := NewFetchTool(testConfig(), log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	args, _ := json.Marshal(map[string]string{"url": server.URL})
+	start := time.Now()
+	_, err := tool.Execute(ctx, string(args))
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
 func TestFetchTool_Execute_SizeLimit(t *testing.T) {
 	// Create mock server that returns large content
 	largeContent := strings.Repeat("x", 1024*1024) // 1MB
@@ -285,10 +314,11 @@ func TestFetchTool_Execute_SizeLimit(t *testing.T) {
 	cfg := &config.Config{
 		Tools: config.ToolsConfig{
 			Fetch: config.FetchToolConfig{
-				Enabled:         true,
-				TimeoutSeconds:  10,
-				MaxResponseSize: 100, // Only allow 100 bytes
-				UserAgent:       "nexbot/1.0",
+				Enabled:              true,
+				TimeoutSeconds:       10,
+				MaxResponseSize:      100, // Only allow 100 bytes
+				UserAgent:            "nexbot/1.0",
+				AllowPrivateNetworks: true, // Test servers listen on localhost
 			},
 		},
 	}
@@ -298,12 +328,79 @@ func TestFetchTool_Execute_SizeLimit(t *testing.T) {
 		"url": server.URL,
 	})
 
-	_, err := tool.Execute(string(args))
+	_, err := tool.Execute(context.Background(), string(args))
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "exceeds")
 }
 
+func TestFetchTool_Execute_SizeLimit_ContentLength(t *testing.T) {
+	// The announced size is rejected before the body is read
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1048576")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(strings.Repeat("x", 1024*1024)))
+	}))
+	defer server.Close()
+
+	log, _ := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	cfg := testConfig()
+	cfg.Tools.Fetch.MaxResponseSize = 100
+	tool := NewFetchTool(cfg, log)
+
+	args, _ := json.Marshal(map[string]string{"url": server.URL})
+	_, err := tool.Execute(context.Background(), string(args))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "response too large: 1048576 bytes")
+}
+
+func TestFetchTool_Execute_SizeLimit_StreamedHTML(t *testing.T) {
+	// Without Content-Length the limit applies while the page is converted
+	page := "<html><body>" + strings.Repeat("<p>paragraph</p>", 10000) + "</body></html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	log, _ := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	cfg := testConfig()
+	cfg.Tools.Fetch.MaxResponseSize = int64(len(page))
+	tool := NewFetchTool(cfg, log)
+
+	for _, format := range []string{"text", "markdown", "html"} {
+		args, _ := json.Marshal(map[string]string{"url": server.URL, "format": format})
+		_, err := tool.Execute(context.Background(), string(args))
+		require.Error(t, err, format)
+		assert.Contains(t, err.Error(), "response truncated", format)
+	}
+
+	cfg.Tools.Fetch.MaxResponseSize = int64(len(page)) + 1
+	args, _ := json.Marshal(map[string]string{"url": server.URL, "format": "text"})
+	result, err := tool.Execute(context.Background(), string(args))
+	require.NoError(t, err)
+
+	var resultJSON map[string]any
+	require.NoError(t, json.Unmarshal([]byte(result), &resultJSON))
+	content := resultJSON["content"].(string)
+	assert.Equal(t, strings.TrimSpace(strings.Repeat("paragraph ", 10000)), content)
+}
+
+func TestCappedReader(t *testing.T) {
+	data, err := io.ReadAll(newCappedReader(strings.NewReader("abc"), 4))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", string(data))
+
+	r := newCappedReader(strings.NewReader("abcdef"), 4)
+	data, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, errResponseTooLarge)
+	assert.Equal(t, "abcd", string(data))
+	assert.Equal(t, int64(4), r.read)
+}
+
 func TestFetchTool_Execute_JSONResponse(t *testing.T) {
 	jsonResponse := `{"name":"Test","value":123,"nested":{"key":"value"}}`
 
@@ -322,7 +419,7 @@ func TestFetchTool_Execute_JSONResponse(t *testing.T) {
 		"format": "text",
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -350,7 +447,7 @@ func TestFetchTool_Execute_HTTPError(t *testing.T) {
 		"url": server.URL,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -379,7 +476,7 @@ func TestFetchTool_Execute_Disabled(t *testing.T) {
 		"url": "http://example.com",
 	})
 
-	_, err := tool.Execute(string(args))
+	_, err := tool.Execute(context.Background(), string(args))
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "disabled")
@@ -405,7 +502,7 @@ func TestFetchTool_Execute_MissingURL(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := tool.Execute(tt.args)
+			_, err := tool.Execute(context.Background(), tt.args)
 
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), "url")
@@ -429,10 +526,11 @@ func TestFetchTool_Execute_UserAgent(t *testing.T) {
 	cfg := &config.Config{
 		Tools: config.ToolsConfig{
 			Fetch: config.FetchToolConfig{
-				Enabled:         true,
-				TimeoutSeconds:  10,
-				MaxResponseSize: 1024 * 1024,
-				UserAgent:       "CustomAgent/1.0",
+				Enabled:              true,
+				TimeoutSeconds:       10,
+				MaxResponseSize:      1024 * 1024,
+				UserAgent:            "CustomAgent/1.0",
+				AllowPrivateNetworks: true, // Test servers listen on localhost
 			},
 		},
 	}
@@ -442,7 +540,7 @@ func TestFetchTool_Execute_UserAgent(t *testing.T) {
 		"url": server.URL,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -498,6 +596,16 @@ func TestStripHTML(t *testing.T) {
 			html:     `<p>  Multiple   spaces  </p>`,
 			expected: "Multiple spaces",
 		},
+		{
+			name:     "multiline script removal",
+			html:     "<p>Content</p><script>\nvar a = 1;\nalert(a);\n</script><p>More</p>",
+			expected: "Content More",
+		},
+		{
+			name:     "entities decoded",
+			html:     `<p>Fish &amp; chips &lt;3</p>`,
+			expected: "Fish & chips <3",
+		},
 	}
 
 	for _, tt := range tests {
@@ -536,7 +644,7 @@ func TestFetchTool_Execute_FollowRedirects_True(t *testing.T) {
 		"followRedirects": true,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -570,7 +678,7 @@ func TestFetchTool_Execute_FollowRedirects_False(t *testing.T) {
 		"followRedirects": false,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -601,7 +709,7 @@ func TestFetchTool_Execute_FollowRedirects_Default(t *testing.T) {
 		"url": redirectServer.URL,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -625,10 +733,11 @@ func TestFetchTool_Execute_TimeoutOverride(t *testing.T) {
 	cfg := &config.Config{
 		Tools: config.ToolsConfig{
 			Fetch: config.FetchToolConfig{
-				Enabled:         true,
-				TimeoutSeconds:  10,
-				MaxResponseSize: 1024 * 1024,
-				UserAgent:       "nexbot/1.0",
+				Enabled:              true,
+				TimeoutSeconds:       10,
+				MaxResponseSize:      1024 * 1024,
+				UserAgent:            "nexbot/1.0",
+				AllowPrivateNetworks: true, // Test servers listen on localhost
 			},
 		},
 	}
@@ -639,7 +748,7 @@ func TestFetchTool_Execute_TimeoutOverride(t *testing.T) {
 		"timeout": 2,
 	})
 
-	_, err := tool.Execute(string(args))
+	_, err := tool.Execute(context.Background(), string(args))
 
 	require.Error(t, err)
 	assert.Contains(t, strings.ToLower(err.Error()), "timeout")
@@ -657,10 +766,11 @@ func TestFetchTool_Execute_TimeoutOverride_Valid(t *testing.T) {
 	cfg := &config.Config{
 		Tools: config.ToolsConfig{
 			Fetch: config.FetchToolConfig{
-				Enabled:         true,
-				TimeoutSeconds:  10,
-				MaxResponseSize: 1024 * 1024,
-				UserAgent:       "nexbot/1.0",
+				Enabled:              true,
+				TimeoutSeconds:       10,
+				MaxResponseSize:      1024 * 1024,
+				UserAgent:            "nexbot/1.0",
+				AllowPrivateNetworks: true, // Test servers listen on localhost
 			},
 		},
 	}
@@ -671,7 +781,7 @@ func TestFetchTool_Execute_TimeoutOverride_Valid(t *testing.T) {
 		"timeout": 5,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -711,7 +821,7 @@ func TestFetchTool_Execute_Timeout_TooLow(t *testing.T) {
 				"timeout": tt.timeout,
 			})
 
-			_, err := tool.Execute(string(args))
+			_, err := tool.Execute(context.Background(), string(args))
 
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), tt.errorContains)
@@ -747,7 +857,7 @@ func TestFetchTool_Execute_Timeout_TooHigh(t *testing.T) {
 				"timeout": tt.timeout,
 			})
 
-			_, err := tool.Execute(string(args))
+			_, err := tool.Execute(context.Background(), string(args))
 
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), tt.errorContains)
@@ -766,10 +876,11 @@ func TestFetchTool_Execute_Timeout_Default(t *testing.T) {
 	cfg := &config.Config{
 		Tools: config.ToolsConfig{
 			Fetch: config.FetchToolConfig{
-				Enabled:         true,
-				TimeoutSeconds:  10,
-				MaxResponseSize: 1024 * 1024,
-				UserAgent:       "nexbot/1.0",
+				Enabled:              true,
+				TimeoutSeconds:       10,
+				MaxResponseSize:      1024 * 1024,
+				UserAgent:            "nexbot/1.0",
+				AllowPrivateNetworks: true, // Test servers listen on localhost
 			},
 		},
 	}
@@ -779,7 +890,7 @@ func TestFetchTool_Execute_Timeout_Default(t *testing.T) {
 		"url": server.URL,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -808,7 +919,7 @@ func TestFetchTool_Execute_JSONFormat(t *testing.T) {
 		"format": "json",
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -843,7 +954,7 @@ func TestFetchTool_Execute_JSONFormat_InvalidJSON(t *testing.T) {
 		"format": "json",
 	})
 
-	_, err := tool.Execute(string(args))
+	_, err := tool.Execute(context.Background(), string(args))
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to parse JSON response")
@@ -863,7 +974,7 @@ func TestFetchTool_Execute_StatusText(t *testing.T) {
 		"url": server.URL,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -892,7 +1003,7 @@ func TestFetchTool_Execute_Headers(t *testing.T) {
 		"url": server.URL,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -935,7 +1046,7 @@ func TestFetchTool_Execute_BasicAuth(t *testing.T) {
 		},
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -983,7 +1094,7 @@ func TestFetchTool_Execute_BasicAuth_SecretPassword(t *testing.T) {
 		},
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -1017,7 +1128,7 @@ func TestFetchTool_Execute_BasicAuth_Invalid(t *testing.T) {
 		},
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -1055,7 +1166,7 @@ func TestFetchTool_Execute_Cookies(t *testing.T) {
 		},
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -1087,7 +1198,7 @@ func TestFetchTool_Execute_Cookies_Empty(t *testing.T) {
 		"cookies": map[string]string{},
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -1121,7 +1232,7 @@ func TestFetchTool_Execute_Cookies_Single(t *testing.T) {
 		},
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -1167,7 +1278,7 @@ func TestFetchTool_Execute_BasicAuthAndCookies(t *testing.T) {
 		},
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -1217,7 +1328,7 @@ func TestFetchTool_Execute_MarkdownFormat(t *testing.T) {
 		"format": "markdown",
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 
 	require.NoError(t, err)
 
@@ -1427,7 +1538,7 @@ func TestFetchTool_Execute_POST(t *testing.T) {
 		"body":   `{"test":"data"}`,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 	require.NoError(t, err)
 
 	var resultJSON map[string]any
@@ -1459,7 +1570,7 @@ func TestFetchTool_Execute_PUT(t *testing.T) {
 		"body":   `{"updated":true}`,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 	require.NoError(t, err)
 
 	var resultJSON map[string]any
@@ -1490,7 +1601,7 @@ func TestFetchTool_Execute_PATCH(t *testing.T) {
 		"body":   `{"field":"value"}`,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 	require.NoError(t, err)
 
 	var resultJSON map[string]any
@@ -1516,7 +1627,7 @@ func TestFetchTool_Execute_DELETE(t *testing.T) {
 		"method": "DELETE",
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 	require.NoError(t, err)
 
 	var resultJSON map[string]any
@@ -1540,7 +1651,7 @@ func TestFetchTool_Execute_GET_Default(t *testing.T) {
 		"url": server.URL,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 	require.NoError(t, err)
 
 	var resultJSON map[string]any
@@ -1572,7 +1683,7 @@ func TestFetchTool_Execute_GET_WithBody_Ignored(t *testing.T) {
 		"body":   `{"ignored":"data"}`,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 	require.NoError(t, err)
 
 	var resultJSON map[string]any
@@ -1602,7 +1713,7 @@ func TestFetchTool_Execute_DELETE_WithBody_Ignored(t *testing.T) {
 		"body":   `{"ignored":"data"}`,
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 	require.NoError(t, err)
 
 	var resultJSON map[string]any
@@ -1637,7 +1748,7 @@ func TestFetchTool_Execute_POST_WithCustomContentType(t *testing.T) {
 		},
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 	require.NoError(t, err)
 
 	var resultJSON map[string]any
@@ -1667,7 +1778,7 @@ func TestFetchTool_Execute_POST_WithoutBody(t *testing.T) {
 		"method": "POST",
 	})
 
-	result, err := tool.Execute(string(args))
+	result, err := tool.Execute(context.Background(), string(args))
 	require.NoError(t, err)
 
 	var resultJSON map[string]any
@@ -1675,3 +1786,66 @@ func TestFetchTool_Execute_POST_WithoutBody(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, float64(200), resultJSON["status"])
 }
+
+func TestFetchTool_DryRun(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	tool := NewFetchTool(testConfig(), log)
+
+	args, _ := json.Marshal(map[string]any{
+		"url":     "https://api.example.com/items",
+		"method":  "POST",
+		"headers": map[string]string{"X-Token": "$API_TOKEN"},
+		"body":    `{"name":"test"}`,
+	})
+	report, mutates, err := tool.DryRun(context.Background(), string(args))
+	require.NoError(t, err)
+	assert.True(t, mutates)
+	assert.Contains(t, report, "Would send POST https://api.example.com/items")
+	assert.Contains(t, report, "X-Token: $API_TOKEN")
+	assert.Contains(t, report, `{"name":"test"}`)
+
+	// GET requests change nothing and run as usual
+	_, mutates, err = tool.DryRun(context.Background(), `{"url": "https://example.com"}`)
+	require.NoError(t, err)
+	assert.False(t, mutates)
+
+	_, _, err = tool.DryRun(context.Background(), `{"url": "ftp://example.com", "method": "PUT"}`)
+	assert.Error(t, err)
+}
+
+func TestFetchTool_Execute_BlocksPrivateNetworks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Request to a private address must not reach the server")
+	}))
+	defer server.Close()
+
+	log, _ := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	cfg := testConfig()
+	cfg.Tools.Fetch.AllowPrivateNetworks = false
+	tool := NewFetchTool(cfg, log)
+
+	for _, url := range []string{server.URL, "http://169.254.169.254/latest/meta-data/", "http://metadata.google.internal/"} {
+		args, _ := json.Marshal(map[string]string{"url": url})
+		_, err := tool.Execute(context.Background(), string(args))
+		require.Error(t, err, url)
+		assert.Contains(t, err.Error(), "blocked by network policy", url)
+	}
+}
+
+func TestFetchTool_Execute_RedirectToDeniedDomain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://internal.corp.example/admin", http.StatusFound)
+	}))
+	defer server.Close()
+
+	log, _ := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	cfg := testConfig()
+	cfg.Tools.Fetch.DeniedDomains = []string{"corp.example"}
+	tool := NewFetchTool(cfg, log)
+
+	args, _ := json.Marshal(map[string]string{"url": server.URL})
+	_, err := tool.Execute(context.Background(), string(args))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "domain internal.corp.example is denied")
+}
@@ -0,0 +1,440 @@
+package fetch
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// renderMarkdown parses an HTML document and renders it as Markdown by
+// walking the node tree, so that tables, nested lists and blockquotes keep
+// their structure. Page chrome (navigation, footers, sidebars) and non-content
+// elements are dropped.
+func renderMarkdown(r io.Reader) (string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", err
+	}
+	return renderBlocks(doc, "\n\n"), nil
+}
+
+// skippedElements are elements whose content is not part of the page text.
+var skippedElements = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Nav:      true,
+	atom.Footer:   true,
+	atom.Aside:    true,
+	atom.Iframe:   true,
+	atom.Svg:      true,
+	atom.Button:   true,
+}
+
+// blockElements are elements rendered as separate blocks. Other elements
+// are rendered inline, as part of the surrounding paragraph.
+var blockElements = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Blockquote: true, atom.Body: true,
+	atom.Dd: true, atom.Details: true, atom.Div: true, atom.Dl: true, atom.Dt: true,
+	atom.Fieldset: true, atom.Figcaption: true, atom.Figure: true, atom.Form: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Header: true, atom.Hr: true, atom.Html: true, atom.Li: true, atom.Main: true,
+	atom.Ol: true, atom.P: true, atom.Pre: true, atom.Section: true, atom.Summary: true,
+	atom.Table: true, atom.Ul: true,
+}
+
+func isBlock(n *html.Node) bool {
+	return n.Type == html.DocumentNode || (n.Type == html.ElementNode && blockElements[n.DataAtom])
+}
+
+func isSkipped(n *html.Node) bool {
+	return n.Type == html.CommentNode || n.Type == html.DoctypeNode ||
+		(n.Type == html.ElementNode && skippedElements[n.DataAtom])
+}
+
+// renderBlocks renders the children of n as blocks joined by sep. Runs of
+// inline children form a paragraph.
+func renderBlocks(n *html.Node, sep string) string {
+	var blocks []string
+	var para strings.Builder
+
+	flush := func() {
+		if p := cleanParagraph(para.String()); p != "" {
+			blocks = append(blocks, p)
+		}
+		para.Reset()
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case isSkipped(c):
+		case isBlock(c):
+			flush()
+			if b := renderBlock(c); b != "" {
+				blocks = append(blocks, b)
+			}
+		default:
+			renderInline(&para, c)
+		}
+	}
+	flush()
+
+	return strings.Join(blocks, sep)
+}
+
+// renderBlock renders a block element.
+func renderBlock(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := singleLine(inlineText(n))
+		if text == "" {
+			return ""
+		}
+		level := int(n.Data[1] - '0')
+		return strings.Repeat("#", level) + " " + text
+	case atom.Hr:
+		return "---"
+	case atom.Pre:
+		return renderPre(n)
+	case atom.Blockquote:
+		return prefixLines(renderBlocks(n, "\n\n"), "> ", ">")
+	case atom.Ul, atom.Ol:
+		return renderList(n)
+	case atom.Table:
+		return renderTable(n)
+	case atom.Li:
+		return renderBlocks(n, "\n")
+	default:
+		return renderBlocks(n, "\n\n")
+	}
+}
+
+// renderList renders a list; items that span several lines are indented
+// under their marker, so nested lists keep their level.
+func renderList(n *html.Node) string {
+	number := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		number = start
+	}
+
+	var items []string
+	indent := ""
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || isSkipped(c) {
+			continue
+		}
+		// A list nested without an <li> belongs to the previous item
+		if (c.DataAtom == atom.Ul || c.DataAtom == atom.Ol) && len(items) > 0 {
+			if nested := renderList(c); nested != "" {
+				items[len(items)-1] += "\n" + indentLines(nested, indent)
+			}
+			continue
+		}
+
+		content := renderBlocks(c, "\n")
+		if content == "" {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		indent = strings.Repeat(" ", len(marker))
+		items = append(items, marker+strings.TrimPrefix(indentLines(content, indent), indent))
+	}
+	return strings.Join(items, "\n")
+}
+
+// renderTable renders a table as a Markdown table. The first row is the
+// header; rows are padded to the same number of columns.
+func renderTable(n *html.Node) string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch c.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				walk(c)
+			case atom.Tr:
+				rows = append(rows, tableRow(c))
+			}
+		}
+	}
+	walk(n)
+
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	if caption := findChild(n, atom.Caption); caption != nil {
+		if text := singleLine(inlineText(caption)); text != "" {
+			b.WriteString(text + "\n\n")
+		}
+	}
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// tableRow renders the cells of a row; a cell spanning several columns is
+// followed by empty cells.
+func tableRow(tr *html.Node) []string {
+	var cells []string
+	for c := tr.FirstChild; c != nil; c = c.NextSibling {
+		if c.DataAtom != atom.Td && c.DataAtom != atom.Th {
+			continue
+		}
+		text := strings.ReplaceAll(singleLine(inlineText(c)), "|", `\|`)
+		cells = append(cells, text)
+		if span, err := strconv.Atoi(attr(c, "colspan")); err == nil {
+			for i := 1; i < min(span, 100); i++ {
+				cells = append(cells, "")
+			}
+		}
+	}
+	return cells
+}
+
+// renderPre renders preformatted text as a fenced code block, keeping its
+// whitespace. The language is taken from a "language-*" class of <code>.
+func renderPre(n *html.Node) string {
+	var b strings.Builder
+	rawText(&b, n)
+	code := strings.Trim(b.String(), "\n")
+	if strings.TrimSpace(code) == "" {
+		return ""
+	}
+
+	lang := ""
+	if c := findChild(n, atom.Code); c != nil {
+		for class := range strings.FieldsSeq(attr(c, "class")) {
+			if l, ok := strings.CutPrefix(class, "language-"); ok {
+				lang = l
+				break
+			}
+			if l, ok := strings.CutPrefix(class, "lang-"); ok {
+				lang = l
+				break
+			}
+		}
+	}
+
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + code + "\n" + fence
+}
+
+// renderInline appends the inline Markdown of n to b. <br> becomes a newline;
+// whitespace of the source collapses to single spaces.
+func renderInline(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(collapseSpace(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if isSkipped(n) {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		b.WriteString("\n")
+	case atom.Strong, atom.B:
+		b.WriteString(wrap(inlineText(n), "**"))
+	case atom.Em, atom.I:
+		b.WriteString(wrap(inlineText(n), "*"))
+	case atom.Del, atom.S, atom.Strike:
+		b.WriteString(wrap(inlineText(n), "~~"))
+	case atom.Code, atom.Kbd, atom.Samp:
+		b.WriteString(inlineCode(n))
+	case atom.A:
+		text := singleLine(inlineText(n))
+		href := strings.TrimSpace(attr(n, "href"))
+		switch {
+		case text == "":
+		case href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:"):
+			b.WriteString(text)
+		default:
+			b.WriteString("[" + text + "](" + href + ")")
+		}
+	case atom.Img:
+		if src := strings.TrimSpace(attr(n, "src")); src != "" {
+			b.WriteString("![" + singleLine(attr(n, "alt")) + "](" + src + ")")
+		}
+	default:
+		// Blocks inside inline content (a <div> in a table cell) are
+		// separated by spaces
+		if isBlock(n) {
+			b.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			renderInline(b, c)
+		}
+		if isBlock(n) {
+			b.WriteString(" ")
+		}
+	}
+}
+
+// inlineText renders the children of n inline.
+func inlineText(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		renderInline(&b, c)
+	}
+	return b.String()
+}
+
+// inlineCode renders inline code, choosing a delimiter longer than any run
+// of backticks inside.
+func inlineCode(n *html.Node) string {
+	var b strings.Builder
+	rawText(&b, n)
+	code := singleLine(b.String())
+	if code == "" {
+		return ""
+	}
+	delim := "`"
+	for strings.Contains(code, delim) {
+		delim += "`"
+	}
+	if len(delim) > 1 {
+		return delim + " " + code + " " + delim
+	}
+	return delim + code + delim
+}
+
+// wrap surrounds text with a delimiter, keeping surrounding whitespace
+// outside of it: "<b> x </b>" becomes " **x** ".
+func wrap(text, delim string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	start := strings.Index(text, trimmed)
+	return text[:start] + delim + trimmed + delim + text[start+len(trimmed):]
+}
+
+// rawText appends the text of n and its descendants as is; <br> becomes a
+// newline.
+func rawText(b *strings.Builder, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode:
+			b.WriteString(c.Data)
+		case c.Type == html.ElementNode && c.DataAtom == atom.Br:
+			b.WriteString("\n")
+		case c.Type == html.ElementNode && !isSkipped(c):
+			rawText(b, c)
+		}
+	}
+}
+
+// cleanParagraph collapses whitespace within each line of a paragraph and
+// drops empty lines. Lines come from <br> only: text nodes are collapsed.
+func cleanParagraph(s string) string {
+	var lines []string
+	for line := range strings.SplitSeq(s, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// collapseSpace collapses runs of whitespace, including line breaks of the
+// source, to single spaces.
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// singleLine collapses all whitespace, including line breaks, to single spaces.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// prefixLines prefixes every line of s; empty lines get emptyPrefix.
+func prefixLines(s, prefix, emptyPrefix string) string {
+	if s == "" {
+		return ""
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = emptyPrefix
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// indentLines indents every non-empty line of s.
+func indentLines(s, indent string) string {
+	return prefixLines(s, indent, "")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// findChild returns the first descendant element of n with the given tag.
+func findChild(n *html.Node, tag atom.Atom) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if c.DataAtom == tag {
+			return c
+		}
+		if found := findChild(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
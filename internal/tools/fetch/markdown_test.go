@@ -0,0 +1,89 @@
+package fetch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name: "table with header",
+			html: `<table>
+				<thead><tr><th>Name</th><th>Price</th></tr></thead>
+				<tbody>
+					<tr><td>Apple</td><td>1 | 2 €</td></tr>
+					<tr><td colspan="2">Out of <b>stock</b></td></tr>
+					<tr><td>Pear</td></tr>
+				</tbody>
+			</table>`,
+			expected: "| Name | Price |\n| --- | --- |\n| Apple | 1 \\| 2 € |\n| Out of **stock** |  |\n| Pear |  |",
+		},
+		{
+			name: "nested lists",
+			html: `<ul>
+				<li>Fruit
+					<ol start="3"><li>Apple</li><li>Pear<ul><li>Green</li></ul></li></ol>
+				</li>
+				<li>Vegetables</li>
+			</ul>`,
+			expected: "- Fruit\n  3. Apple\n  4. Pear\n     - Green\n- Vegetables",
+		},
+		{
+			name:     "list nested without li",
+			html:     `<ul><li>One</li><ul><li>Sub</li></ul><li>Two</li></ul>`,
+			expected: "- One\n  - Sub\n- Two",
+		},
+		{
+			name: "blockquote",
+			html: `<blockquote><p>First paragraph</p><p>Second
+				line</p><blockquote>Nested</blockquote></blockquote>`,
+			expected: "> First paragraph\n>\n> Second line\n>\n> > Nested",
+		},
+		{
+			name:     "list item with paragraphs and a quote",
+			html:     `<ol><li><p>Step one</p><blockquote>Note</blockquote></li></ol>`,
+			expected: "1. Step one\n   > Note",
+		},
+		{
+			name:     "code block keeps whitespace",
+			html:     "<pre><code class=\"language-go\">func main() {\n\tfmt.Println(\"```\")\n}\n</code></pre>",
+			expected: "````go\nfunc main() {\n\tfmt.Println(\"```\")\n}\n````",
+		},
+		{
+			name:     "inline code with backticks",
+			html:     "<p>Run <code>a `b`</code> now</p>",
+			expected: "Run `` a `b` `` now",
+		},
+		{
+			name:     "source line breaks are not kept",
+			html:     "<p>One\n  sentence <em> split </em>\nover lines<br>New line</p>",
+			expected: "One sentence *split* over lines\nNew line",
+		},
+		{
+			name:     "page chrome dropped",
+			html:     `<html><head><title>T</title></head><body><nav>Menu</nav><main><h2>Head <a href="#top">line</a></h2><p>Text</p></main><footer>Footer</footer></body></html>`,
+			expected: "## Head line\n\nText",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := renderMarkdown(strings.NewReader(tt.html))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestRenderMarkdown_ReadError(t *testing.T) {
+	_, err := renderMarkdown(newCappedReader(strings.NewReader("<p>"+strings.Repeat("x", 100)+"</p>"), 10))
+	assert.ErrorIs(t, err, errResponseTooLarge)
+}
@@ -0,0 +1,285 @@
+package fetch
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+const (
+	// robotsTTL is how long the robots.txt of a site is reused
+	robotsTTL = 24 * time.Hour
+	// maxRobotsSize limits the part of robots.txt that is read
+	maxRobotsSize = 512 * 1024
+	// maxCrawlDelay caps the Crawl-delay of robots.txt
+	maxCrawlDelay = time.Minute
+	// maxThrottledHosts is how many hosts are tracked before stale ones are forgotten
+	maxThrottledHosts = 1000
+)
+
+// politeness keeps the robots.txt rules of the sites and the earliest time
+// of the next request to each host. It is safe for concurrent use.
+type politeness struct {
+	mu     sync.Mutex
+	robots map[string]*robotsRules // By scheme and host
+	next   map[string]time.Time    // By host
+}
+
+func newPoliteness() *politeness {
+	return &politeness{
+		robots: make(map[string]*robotsRules),
+		next:   make(map[string]time.Time),
+	}
+}
+
+// wait blocks until a request to host is allowed by the interval and
+// reserves the slot, so concurrent requests to one host are spaced out.
+func (p *politeness) wait(ctx context.Context, host string, interval time.Duration) error {
+	if interval <= 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	at := now
+	if next := p.next[host]; next.After(now) {
+		at = next
+	}
+	if len(p.next) >= maxThrottledHosts {
+		for h, next := range p.next {
+			if next.Before(now) {
+				delete(p.next, h)
+			}
+		}
+	}
+	p.next[host] = at.Add(interval)
+	p.mu.Unlock()
+
+	delay := at.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cachedRobots returns the unexpired robots.txt rules of a site.
+func (p *politeness) cachedRobots(site string) *robotsRules {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	rules := p.robots[site]
+	if rules == nil || time.Since(rules.fetched) > robotsTTL {
+		return nil
+	}
+	return rules
+}
+
+func (p *politeness) storeRobots(site string, rules *robotsRules) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.robots[site] = rules
+}
+
+// robotsRules are the rules of a robots.txt that apply to the user agent.
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+	fetched    time.Time
+}
+
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+// allowed reports whether path (with the query) may be fetched: the longest
+// matching rule wins, and Allow wins a tie.
+func (r *robotsRules) allowed(path string) bool {
+	allowed, longest := true, -1
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if len(rule.pattern) > longest || (len(rule.pattern) == longest && rule.allow) {
+			allowed, longest = rule.allow, len(rule.pattern)
+		}
+	}
+	return allowed
+}
+
+// robotsMatch matches a path against a robots.txt pattern: "*" matches any
+// characters and a trailing "$" anchors the end of the path.
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(path, part) && len(path)-len(part) >= pos
+		}
+		idx := strings.Index(path[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+	return !anchored || pos == len(path)
+}
+
+// robotsGroup is a group of rules for a set of user agents.
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// parseRobots parses a robots.txt and returns the rules of the groups that
+// name the user agent, or of the "*" groups when no group names it.
+func parseRobots(r io.Reader, userAgent string) *robotsRules {
+	var groups []*robotsGroup
+	var group *robotsGroup
+	inAgents := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive User-agent lines share one group
+			if !inAgents {
+				group = &robotsGroup{}
+				groups = append(groups, group)
+			}
+			group.agents = append(group.agents, strings.ToLower(value))
+			inAgents = true
+		case "allow", "disallow":
+			inAgents = false
+			if group != nil && value != "" {
+				group.rules = append(group.rules, robotsRule{pattern: value, allow: key == "allow"})
+			}
+		case "crawl-delay":
+			inAgents = false
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && group != nil && seconds > 0 {
+				group.crawlDelay = min(time.Duration(seconds*float64(time.Second)), maxCrawlDelay)
+			}
+		default:
+			inAgents = false
+		}
+	}
+
+	agent := strings.ToLower(userAgent)
+	rules := &robotsRules{fetched: time.Now()}
+	for _, wildcard := range []bool{false, true} {
+		for _, g := range groups {
+			if g.matches(agent, wildcard) {
+				rules.rules = append(rules.rules, g.rules...)
+				rules.crawlDelay = max(rules.crawlDelay, g.crawlDelay)
+			}
+		}
+		if len(rules.rules) > 0 || rules.crawlDelay > 0 {
+			break
+		}
+	}
+	return rules
+}
+
+// matches reports whether the group names the user agent, or is a "*"
+// group when wildcard is set.
+func (g *robotsGroup) matches(agent string, wildcard bool) bool {
+	for _, a := range g.agents {
+		if wildcard && a == "*" || !wildcard && a != "*" && a != "" && strings.Contains(agent, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchRobots requests the robots.txt of the site of u. A missing
+// robots.txt allows everything.
+func (t *FetchTool) fetchRobots(ctx context.Context, client *http.Client, u *url.URL) (*robotsRules, error) {
+	robotsURL := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", t.cfg.Tools.Fetch.UserAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return parseRobots(io.LimitReader(resp.Body, maxRobotsSize), t.cfg.Tools.Fetch.UserAgent), nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return &robotsRules{fetched: time.Now()}, nil
+	default:
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+}
+
+// beforeRequest applies robots.txt and the per-host request interval to a
+// request to u. The robots.txt of a site is requested once a day; a site
+// whose robots.txt can't be read is allowed and asked again next time.
+func (t *FetchTool) beforeRequest(ctx context.Context, client *http.Client, u *url.URL) error {
+	host := strings.ToLower(u.Host)
+	interval := time.Duration(t.cfg.Tools.Fetch.MinIntervalMs) * time.Millisecond
+
+	if t.cfg.Tools.Fetch.RespectRobots {
+		site := u.Scheme + "://" + host
+		rules := t.politeness.cachedRobots(site)
+		if rules == nil {
+			if err := t.politeness.wait(ctx, host, interval); err != nil {
+				return err
+			}
+			var err error
+			rules, err = t.fetchRobots(ctx, client, u)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				t.logger.Warn("Failed to fetch robots.txt, allowing the request",
+					logger.Field{Key: "host", Value: host},
+					logger.Field{Key: "error", Value: err.Error()})
+			} else {
+				t.politeness.storeRobots(site, rules)
+			}
+		}
+		if rules != nil {
+			if !rules.allowed(u.RequestURI()) {
+				return fmt.Errorf("fetching %s is disallowed by robots.txt of %s", u.RequestURI(), host)
+			}
+			interval = max(interval, rules.crawlDelay)
+		}
+	}
+
+	return t.politeness.wait(ctx, host, interval)
+}
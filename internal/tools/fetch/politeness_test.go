@@ -0,0 +1,158 @@
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+func TestParseRobots(t *testing.T) {
+	robots := `# Comment
+User-agent: *
+Disallow: /private/
+Allow: /private/public-page
+Disallow: /*.pdf$
+Crawl-delay: 2
+
+User-agent: OtherBot
+User-agent: nexbot
+Disallow: /
+Allow: /docs
+Crawl-delay: 500
+`
+	generic := parseRobots(strings.NewReader(robots), "Mozilla/5.0 Chrome")
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"/", true},
+		{"/private/", false},
+		{"/private/page?x=1", false},
+		{"/private/public-page", true},
+		{"/files/report.pdf", false},
+		{"/files/report.pdf?download=1", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, generic.allowed(tt.path), tt.path)
+	}
+	assert.Equal(t, 2*time.Second, generic.crawlDelay)
+
+	// A group naming the user agent replaces the "*" group
+	named := parseRobots(strings.NewReader(robots), "nexbot/1.0")
+	assert.False(t, named.allowed("/private/public-page"))
+	assert.True(t, named.allowed("/docs/setup"))
+	assert.Equal(t, maxCrawlDelay, named.crawlDelay)
+
+	empty := parseRobots(strings.NewReader(""), "nexbot/1.0")
+	assert.True(t, empty.allowed("/anything"))
+}
+
+func TestRobotsMatch(t *testing.T) {
+	assert.True(t, robotsMatch("/a*c", "/abbc/d"))
+	assert.True(t, robotsMatch("/a*c$", "/abbc"))
+	assert.False(t, robotsMatch("/a*c$", "/abbcd"))
+	assert.True(t, robotsMatch("/a$", "/a"))
+	assert.False(t, robotsMatch("/a$", "/ab"))
+	assert.False(t, robotsMatch("/ab*", "/a"))
+}
+
+func TestPoliteness_Wait(t *testing.T) {
+	p := newPoliteness()
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 3 {
+		require.NoError(t, p.wait(ctx, "example.com", 50*time.Millisecond))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	// Other hosts are not delayed
+	start = time.Now()
+	require.NoError(t, p.wait(ctx, "example.org", 50*time.Millisecond))
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, p.wait(cancelled, "example.com", time.Hour), context.Canceled)
+}
+
+func TestFetchTool_Execute_RespectRobots(t *testing.T) {
+	var robotsRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsRequests.Add(1)
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /admin\n"))
+			return
+		}
+		_, _ = w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	log, _ := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	cfg := testConfig()
+	cfg.Tools.Fetch.RespectRobots = true
+	tool := NewFetchTool(cfg, log)
+
+	args, _ := json.Marshal(map[string]string{"url": server.URL + "/admin/users"})
+	_, err := tool.Execute(context.Background(), string(args))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "disallowed by robots.txt")
+
+	args, _ = json.Marshal(map[string]string{"url": server.URL + "/page"})
+	result, err := tool.Execute(context.Background(), string(args))
+	require.NoError(t, err)
+	assert.Contains(t, result, `"content": "OK"`)
+
+	// robots.txt is requested once per site
+	assert.Equal(t, int32(1), robotsRequests.Load())
+}
+
+func TestFetchTool_Execute_RobotsUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	log, _ := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	cfg := testConfig()
+	cfg.Tools.Fetch.RespectRobots = true
+	tool := NewFetchTool(cfg, log)
+
+	args, _ := json.Marshal(map[string]string{"url": server.URL + "/page"})
+	_, err := tool.Execute(context.Background(), string(args))
+	require.NoError(t, err)
+}
+
+func TestFetchTool_Execute_MinInterval(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	log, _ := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	cfg := testConfig()
+	cfg.Tools.Fetch.MinIntervalMs = 100
+	tool := NewFetchTool(cfg, log)
+
+	args, _ := json.Marshal(map[string]string{"url": server.URL})
+	start := time.Now()
+	for range 2 {
+		_, err := tool.Execute(context.Background(), string(args))
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
@@ -0,0 +1,363 @@
+package fetch
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// article is the main content of a page with its metadata.
+type article struct {
+	Title   string
+	Byline  string
+	Content string // Markdown
+}
+
+// Class and id patterns of the scoring heuristics, after Mozilla's Readability.
+var (
+	reUnlikely = regexp.MustCompile(`(?i)-ad-|ai2html|banner|breadcrumbs|combx|comment|community|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|related|remark|replies|rss|shoutbox|sidebar|skyscraper|social|sponsor|supplemental|ad-break|agegate|pagination|pager|popup|yom-remote|cookie|newsletter|subscribe`)
+	reMaybe    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	rePositive = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story`)
+	reNegative = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+	reByline   = regexp.MustCompile(`(?i)byline|author|dateline|writtenby|p-author`)
+)
+
+// unlikelyRoles are ARIA roles of page chrome.
+var unlikelyRoles = map[string]bool{
+	"menu": true, "menubar": true, "complementary": true, "navigation": true,
+	"alert": true, "alertdialog": true, "dialog": true, "banner": true, "contentinfo": true,
+}
+
+const (
+	// minParagraphLength is the shortest text that counts as a paragraph
+	minParagraphLength = 25
+	// scoreLevels is how many ancestors of a paragraph share its score
+	scoreLevels = 5
+)
+
+// extractArticle parses an HTML document and returns its main content,
+// without navigation, ads, comments and other boilerplate. Paragraphs are
+// scored by length and punctuation, the scores propagate to their ancestors,
+// and the best scoring element with its related siblings is the article.
+func extractArticle(r io.Reader) (*article, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &article{Title: pageTitle(doc)}
+	a.Byline = pageByline(doc)
+	prune(doc)
+
+	var blocks []string
+	for _, n := range mainContent(doc) {
+		var b string
+		if isBlock(n) {
+			b = renderBlock(n)
+		} else {
+			var para strings.Builder
+			renderInline(&para, n)
+			b = cleanParagraph(para.String())
+		}
+		if b != "" {
+			blocks = append(blocks, b)
+		}
+	}
+	a.Content = strings.Join(blocks, "\n\n")
+	return a, nil
+}
+
+// pageTitle returns the Open Graph title, the <title> or the first <h1>.
+func pageTitle(doc *html.Node) string {
+	if title := metaContent(doc, "og:title"); title != "" {
+		return title
+	}
+	if n := findChild(doc, atom.Title); n != nil {
+		if title := singleLine(textOf(n)); title != "" {
+			return title
+		}
+	}
+	if n := findChild(doc, atom.H1); n != nil {
+		return singleLine(textOf(n))
+	}
+	return ""
+}
+
+// pageByline returns the author from the metadata or from a byline element.
+// A byline element is removed so it is not repeated in the content.
+func pageByline(doc *html.Node) string {
+	if author := metaContent(doc, "author"); author != "" {
+		return author
+	}
+	if author := metaContent(doc, "article:author"); author != "" && !strings.Contains(author, "://") {
+		return author
+	}
+
+	var byline string
+	var walk func(*html.Node) bool
+	walk = func(n *html.Node) bool {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || isSkipped(c) {
+				continue
+			}
+			if attr(c, "rel") == "author" || attr(c, "itemprop") == "author" || reByline.MatchString(classAndID(c)) {
+				if text := singleLine(textOf(c)); text != "" && len(text) < 100 {
+					byline = text
+					n.RemoveChild(c)
+					return true
+				}
+			}
+			if walk(c) {
+				return true
+			}
+		}
+		return false
+	}
+	walk(doc)
+	return byline
+}
+
+// metaContent returns the content of a <meta> with the given name or property.
+func metaContent(doc *html.Node, name string) string {
+	var content string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil && content == ""; c = c.NextSibling {
+			if c.Type == html.ElementNode && c.DataAtom == atom.Meta &&
+				(attr(c, "name") == name || attr(c, "property") == name) {
+				content = singleLine(attr(c, "content"))
+			}
+			walk(c)
+		}
+	}
+	walk(doc)
+	return content
+}
+
+// prune removes hidden elements and elements that look like page chrome by
+// their class, id or role.
+func prune(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode && isUnlikely(c) {
+			n.RemoveChild(c)
+		} else {
+			prune(c)
+		}
+		c = next
+	}
+}
+
+func isUnlikely(n *html.Node) bool {
+	if isSkipped(n) || unlikelyRoles[attr(n, "role")] {
+		return true
+	}
+	if _, hidden := attrValue(n, "hidden"); hidden || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	if style := strings.ReplaceAll(attr(n, "style"), " ", ""); strings.Contains(style, "display:none") {
+		return true
+	}
+	switch n.DataAtom {
+	case atom.Html, atom.Body, atom.Article, atom.Main, atom.A:
+		return false
+	}
+	match := classAndID(n)
+	return reUnlikely.MatchString(match) && !reMaybe.MatchString(match)
+}
+
+// mainContent returns the elements that make up the article: the best
+// scoring element and its siblings that look like part of the same text.
+// Without a scoring element the whole body is returned.
+func mainContent(doc *html.Node) []*html.Node {
+	scores := make(map[*html.Node]float64)
+	scoreParagraphs(doc, scores)
+
+	// Candidates are compared in document order, so ties go to the first
+	var top *html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if score, ok := scores[c]; ok {
+				scores[c] = score * (1 - linkDensity(c))
+				if top == nil || scores[c] > scores[top] {
+					top = c
+				}
+			}
+			walk(c)
+		}
+	}
+	walk(doc)
+	if top == nil {
+		if body := findChild(doc, atom.Body); body != nil {
+			return []*html.Node{body}
+		}
+		return []*html.Node{doc}
+	}
+	if top.Parent == nil {
+		return []*html.Node{top}
+	}
+
+	threshold := max(10, scores[top]*0.2)
+	var nodes []*html.Node
+	for s := top.Parent.FirstChild; s != nil; s = s.NextSibling {
+		if s == top || isRelatedSibling(s, top, scores, threshold) {
+			nodes = append(nodes, s)
+		}
+	}
+	return nodes
+}
+
+// isRelatedSibling reports whether a sibling of the top element belongs to
+// the article: it scores well itself or is a text paragraph.
+func isRelatedSibling(s, top *html.Node, scores map[*html.Node]float64, threshold float64) bool {
+	if s.Type != html.ElementNode {
+		return false
+	}
+	score := scores[s]
+	if class := attr(top, "class"); class != "" && attr(s, "class") == class {
+		score += scores[top] * 0.2
+	}
+	if score >= threshold {
+		return true
+	}
+	if s.DataAtom != atom.P {
+		return false
+	}
+	text := singleLine(textOf(s))
+	density := linkDensity(s)
+	switch {
+	case len(text) > 80:
+		return density < 0.25
+	case len(text) > 0:
+		return density == 0 && (strings.Contains(text, ". ") || strings.HasSuffix(text, "."))
+	}
+	return false
+}
+
+// scoreParagraphs scores text blocks and adds the scores to their ancestors:
+// the parent gets the full score, farther ancestors a decreasing share.
+func scoreParagraphs(n *html.Node, scores map[*html.Node]float64) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		scoreParagraphs(c, scores)
+		if !isParagraph(c) {
+			continue
+		}
+
+		text := singleLine(textOf(c))
+		if len(text) < minParagraphLength {
+			continue
+		}
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text)/100), 3)
+
+		level := 0
+		for p := c.Parent; p != nil && p.Type == html.ElementNode && level < scoreLevels; p = p.Parent {
+			if _, ok := scores[p]; !ok {
+				scores[p] = initialScore(p)
+			}
+			switch level {
+			case 0:
+				scores[p] += score
+			case 1:
+				scores[p] += score / 2
+			default:
+				scores[p] += score / float64(level*3)
+			}
+			level++
+		}
+	}
+}
+
+// isParagraph reports whether an element holds a block of text: a
+// paragraph-like element or a <div> without block children.
+func isParagraph(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		return true
+	case atom.Div:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if isBlock(c) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// initialScore weighs an element by its tag, class and id.
+func initialScore(n *html.Node) float64 {
+	var score float64
+	switch n.DataAtom {
+	case atom.Div, atom.Article, atom.Main, atom.Section:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+	for _, value := range []string{attr(n, "class"), attr(n, "id")} {
+		if value == "" {
+			continue
+		}
+		if reNegative.MatchString(value) {
+			score -= 25
+		}
+		if rePositive.MatchString(value) {
+			score += 25
+		}
+	}
+	return score
+}
+
+// linkDensity returns the share of the text of n that is inside links.
+func linkDensity(n *html.Node) float64 {
+	total := len(singleLine(textOf(n)))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if c.DataAtom == atom.A {
+				links += len(singleLine(textOf(c)))
+			} else {
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return float64(links) / float64(total)
+}
+
+// textOf returns the text of n and its descendants.
+func textOf(n *html.Node) string {
+	var b strings.Builder
+	rawText(&b, n)
+	return b.String()
+}
+
+func classAndID(n *html.Node) string {
+	return attr(n, "class") + " " + attr(n, "id")
+}
+
+func attrValue(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
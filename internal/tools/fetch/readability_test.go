@@ -0,0 +1,97 @@
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html>
+<head>
+	<title>Growing tomatoes | Garden Weekly</title>
+	<meta property="og:title" content="Growing tomatoes on a balcony">
+	<script>trackPageView();</script>
+</head>
+<body>
+	<nav><a href="/">Home</a> <a href="/news">News</a> <a href="/about">About</a></nav>
+	<div class="site-header"><a href="/">Garden Weekly</a> <a href="/login">Log in</a></div>
+	<div id="page">
+		<div class="post">
+			<h1>Growing tomatoes on a balcony</h1>
+			<p class="byline">By Jane Doe</p>
+			<div class="post-content">
+				<p>Tomatoes need at least six hours of direct sun a day, so pick the sunniest corner of the balcony, and turn the pots every week.</p>
+				<p>Use pots of at least twenty liters, fill them with loose, fertile soil, and add a layer of mulch to keep the moisture in.</p>
+				<h2>Watering</h2>
+				<p>Water deeply, in the morning, at the base of the plant; wet leaves invite blight, mildew and other fungal diseases.</p>
+				<ul><li>Check the soil daily</li><li>Feed every two weeks</li></ul>
+			</div>
+			<div class="share-buttons"><a href="/share/fb">Share</a> <a href="/share/tw">Tweet</a></div>
+		</div>
+		<div class="sidebar"><p>Subscribe to our newsletter, and get a free seed catalog, gardening tips and more every week!</p></div>
+		<div id="comments"><p>Great article, thanks, I will try this on my balcony this summer, and report back.</p></div>
+	</div>
+	<div class="ad-banner" hidden><p>Buy the best fertilizer now, cheap, fast delivery, and a money back guarantee!</p></div>
+	<footer>© Garden Weekly</footer>
+</body>
+</html>`
+
+func TestExtractArticle(t *testing.T) {
+	a, err := extractArticle(strings.NewReader(articlePage))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Growing tomatoes on a balcony", a.Title)
+	assert.Equal(t, "By Jane Doe", a.Byline)
+
+	for _, want := range []string{"six hours of direct sun", "twenty liters", "## Watering", "- Check the soil daily"} {
+		assert.Contains(t, a.Content, want)
+	}
+	for _, unwanted := range []string{"Home", "Log in", "Share", "newsletter", "Great article", "fertilizer", "©", "trackPageView", "By Jane Doe"} {
+		assert.NotContains(t, a.Content, unwanted)
+	}
+}
+
+func TestExtractArticle_Metadata(t *testing.T) {
+	page := `<html><head><title>Plain title</title><meta name="author" content="John Smith"></head>
+		<body><p>Short.</p></body></html>`
+	a, err := extractArticle(strings.NewReader(page))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Plain title", a.Title)
+	assert.Equal(t, "John Smith", a.Byline)
+	// Without a scoring paragraph the whole body is the content
+	assert.Equal(t, "Short.", a.Content)
+}
+
+func TestFetchTool_Execute_ReadabilityFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(articlePage))
+	}))
+	defer server.Close()
+
+	log, _ := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	tool := NewFetchTool(testConfig(), log)
+
+	args, _ := json.Marshal(map[string]string{"url": server.URL, "format": "readability"})
+	result, err := tool.Execute(context.Background(), string(args))
+	require.NoError(t, err)
+
+	var resultJSON map[string]any
+	require.NoError(t, json.Unmarshal([]byte(result), &resultJSON))
+	assert.Equal(t, "Growing tomatoes on a balcony", resultJSON["title"])
+	assert.Equal(t, "By Jane Doe", resultJSON["byline"])
+	content := resultJSON["content"].(string)
+	assert.Contains(t, content, "six hours of direct sun")
+	assert.NotContains(t, content, "newsletter")
+	assert.Less(t, len(content), len(articlePage)/2)
+}
@@ -7,7 +7,9 @@ import (
 	"strings"
 
 	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/tools"
 	"github.com/aatumaykin/nexbot/internal/workspace"
+	"github.com/pmezard/go-difflib/difflib"
 )
 
 // fileToolBase contains common fields for file tools.
@@ -16,6 +18,88 @@ type fileToolBase struct {
 	cfg       *config.Config
 }
 
+// ConfigSection returns the configuration table shared by the file tools ([tools.file]).
+func (b *fileToolBase) ConfigSection() string {
+	return "file"
+}
+
+// ConfigSchema returns the keys of [tools.file].
+func (b *fileToolBase) ConfigSchema() []tools.ConfigField {
+	return []tools.ConfigField{
+		{Key: "enabled", Type: tools.ConfigBool},
+		{Key: "whitelist_dirs", Type: tools.ConfigStringList},
+		{Key: "read_only_dirs", Type: tools.ConfigStringList},
+		{Key: "validate_skill_content", Type: tools.ConfigBool},
+		{Key: "diff_summary", Type: tools.ConfigBool},
+		{Key: "diff_summary_max_lines", Type: tools.ConfigInt},
+	}
+}
+
+// resolvePath resolves a relative path against the workspace. Absolute paths
+// must be inside tools.file.whitelist_dirs.
+func (b *fileToolBase) resolvePath(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		cleanPath := filepath.Clean(path)
+		if strings.Contains(cleanPath, "..") {
+			return "", fmt.Errorf("path contains directory traversal attempt")
+		}
+		if b.inWhitelist(cleanPath) {
+			return cleanPath, nil
+		}
+		return "", fmt.Errorf("absolute path is not in whitelist_dirs")
+	}
+
+	if b.workspace == nil {
+		return "", fmt.Errorf("workspace is not configured")
+	}
+	fullPath, err := b.workspace.ResolvePath(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	return fullPath, nil
+}
+
+// inWhitelist reports whether an absolute path is inside one of
+// tools.file.whitelist_dirs. Paths are compared normalized, ignoring case
+// on Windows.
+func (b *fileToolBase) inWhitelist(path string) bool {
+	for _, allowedDir := range b.cfg.Tools.File.WhitelistDirs {
+		if workspace.IsWithin(path, allowedDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxDiffLines limits the diffs of dry-run reports and change summaries
+const maxDiffLines = 200
+
+// unifiedDiff returns a unified diff of a file's content, empty if nothing
+// changed. A missing file is diffed against /dev/null.
+func unifiedDiff(path, old, content string, exists bool) string {
+	from := path
+	if !exists {
+		from = "/dev/null"
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(old),
+		B:        difflib.SplitLines(content),
+		FromFile: from,
+		ToFile:   path,
+		Context:  3,
+	})
+	if err != nil || diff == "" {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	if len(lines) > maxDiffLines {
+		hidden := len(lines) - maxDiffLines
+		lines = append(lines[:maxDiffLines], fmt.Sprintf("… %d more lines", hidden))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // parseJSON is a helper function to parse JSON arguments.
 func parseJSON(jsonStr string, v any) error {
 	decoder := json.NewDecoder(strings.NewReader(jsonStr))
@@ -1,6 +1,7 @@
 package file
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -39,6 +40,76 @@ func (t *DeleteFileTool) Name() string {
 	return "delete_file"
 }
 
+// Destructive reports that deleting always destroys data.
+func (t *DeleteFileTool) Destructive(string) bool {
+	return true
+}
+
+// DryRun reports what a call would delete.
+func (t *DeleteFileTool) DryRun(ctx context.Context, args string) (string, bool, error) {
+	var fileArgs DeleteFileArgs
+	if err := parseJSON(args, &fileArgs); err != nil {
+		return "", true, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	if fileArgs.Path == "" {
+		return "", true, fmt.Errorf("path is required")
+	}
+	path, err := t.resolvePath(fileArgs.Path)
+	if err != nil {
+		return "", true, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", true, fmt.Errorf("file or directory not found: %s", path)
+		}
+		return "", true, fmt.Errorf("failed to access path: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Sprintf("Would delete file %s (%d bytes)", path, info.Size()), true, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", true, fmt.Errorf("failed to check directory: %w", err)
+	}
+	if len(entries) == 0 {
+		return fmt.Sprintf("Would delete empty directory %s", path), true, nil
+	}
+	if !fileArgs.Recursive {
+		return "", true, fmt.Errorf("directory is not empty, use recursive=true to delete: %s", path)
+	}
+	files := 0
+	_ = filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files++
+		}
+		return nil
+	})
+	return fmt.Sprintf("Would delete directory %s recursively (%d files)", path, files), true, nil
+}
+
+// AffectedPaths returns the file or directory a call deletes.
+func (t *DeleteFileTool) AffectedPaths(args string) []string {
+	var fileArgs DeleteFileArgs
+	if err := parseJSON(args, &fileArgs); err != nil || fileArgs.Path == "" {
+		return nil
+	}
+	// Resolved like Execute does: absolute paths are used as-is
+	if filepath.IsAbs(fileArgs.Path) {
+		return []string{filepath.Clean(fileArgs.Path)}
+	}
+	if t.workspace == nil {
+		return nil
+	}
+	path, err := t.workspace.ResolvePath(fileArgs.Path)
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Clean(path)}
+}
+
 // Description returns a description of what the tool does.
 func (t *DeleteFileTool) Description() string {
 	return "Delete file or directory from workspace. Supports recursive deletion."
@@ -65,7 +136,7 @@ func (t *DeleteFileTool) Parameters() map[string]any {
 
 // Execute deletes a file or directory.
 // args is a JSON-encoded string containing the tool's input parameters.
-func (t *DeleteFileTool) Execute(args string) (string, error) {
+func (t *DeleteFileTool) Execute(ctx context.Context, args string) (string, error) {
 	// Parse arguments
 	var fileArgs DeleteFileArgs
 	if err := parseJSON(args, &fileArgs); err != nil {
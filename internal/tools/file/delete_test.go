@@ -1,6 +1,7 @@
 package file
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -97,7 +98,7 @@ func TestDeleteFileTool_Execute_File(t *testing.T) {
 
 	// Delete the file
 	args := `{"path": "test.txt"}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -126,7 +127,7 @@ func TestDeleteFileTool_Execute_EmptyDirectory(t *testing.T) {
 
 	// Delete the directory
 	args := `{"path": "emptydir"}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -159,7 +160,7 @@ func TestDeleteFileTool_Execute_NonEmptyDirectory_Recursive(t *testing.T) {
 
 	// Delete the directory recursively
 	args := `{"path": "nonemptydir", "recursive": true}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -192,7 +193,7 @@ func TestDeleteFileTool_Execute_NonEmptyDirectory_NonRecursive(t *testing.T) {
 
 	// Try to delete without recursive flag (should fail)
 	args := `{"path": "nonemptydir", "recursive": false}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	if err == nil {
 		t.Error("Expected error when trying to delete non-empty directory without recursive flag")
@@ -210,7 +211,7 @@ func TestDeleteFileTool_Execute_NotFound(t *testing.T) {
 
 	// Try to delete non-existent file
 	args := `{"path": "nonexistent.txt"}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	if err == nil {
 		t.Error("Expected error for non-existent file")
@@ -228,7 +229,7 @@ func TestDeleteFileTool_Execute_MissingPath(t *testing.T) {
 
 	// Try to delete with missing path
 	args := `{}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	if err == nil {
 		t.Error("Expected error for missing path")
@@ -246,7 +247,7 @@ func TestDeleteFileTool_Execute_DirectoryTraversal(t *testing.T) {
 
 	// Try to escape workspace using directory traversal
 	args := `{"path": "../etc/passwd"}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	if err == nil {
 		t.Error("Expected error for directory traversal attempt")
@@ -1,6 +1,7 @@
 package file
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -40,6 +41,11 @@ func (t *ListDirTool) Name() string {
 	return "list_dir"
 }
 
+// ReadOnly reports that the tool only reads.
+func (t *ListDirTool) ReadOnly() bool {
+	return true
+}
+
 // Description returns a description of what the tool does.
 func (t *ListDirTool) Description() string {
 	return "List directory contents in workspace. Supports recursive listing."
@@ -71,7 +77,7 @@ func (t *ListDirTool) Parameters() map[string]any {
 
 // Execute lists directory contents.
 // args is a JSON-encoded string containing the tool's input parameters.
-func (t *ListDirTool) Execute(args string) (string, error) {
+func (t *ListDirTool) Execute(ctx context.Context, args string) (string, error) {
 	// Parse arguments
 	var dirArgs ListDirArgs
 	if err := parseJSON(args, &dirArgs); err != nil {
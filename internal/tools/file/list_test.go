@@ -1,6 +1,7 @@
 package file
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -111,7 +112,7 @@ func TestListDirTool_Execute_Flat(t *testing.T) {
 
 	tool := NewListDirTool(ws, testConfig())
 	args := `{"path": "."}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -149,7 +150,7 @@ func TestListDirTool_Execute_Recursive(t *testing.T) {
 
 	tool := NewListDirTool(ws, testConfig())
 	args := `{"path": ".", "recursive": true}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	// Just verify that recursive mode doesn't error
 	if err != nil {
@@ -170,7 +171,7 @@ func TestListDirTool_Execute_IncludeHidden(t *testing.T) {
 
 	// Without include_hidden
 	args := `{"path": ".", "include_hidden": false}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -181,7 +182,7 @@ func TestListDirTool_Execute_IncludeHidden(t *testing.T) {
 
 	// With include_hidden
 	args = `{"path": ".", "include_hidden": true}`
-	result, err = tool.Execute(args)
+	result, err = tool.Execute(context.Background(), args)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -203,7 +204,7 @@ func TestListDirTool_Execute_NotDirectory(t *testing.T) {
 
 	tool := NewListDirTool(ws, testConfig())
 	args := `{"path": "notadir.txt"}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	if err == nil {
 		t.Error("Expected error for non-directory path")
@@ -0,0 +1,50 @@
+//go:build windows
+
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/workspace"
+)
+
+// TestFileTools_WindowsPaths tests whitelist_dirs written with forward
+// slashes and another letter case, and paths with both separators.
+func TestFileTools_WindowsPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws := workspace.New(config.WorkspaceConfig{Path: t.TempDir()})
+	cfg := testConfig()
+	cfg.Tools.File.WhitelistDirs = []string{strings.ToUpper(filepath.ToSlash(tmpDir)) + "/"}
+
+	target := filepath.Join(tmpDir, "notes.txt")
+	write := NewWriteFileTool(ws, cfg)
+	if _, err := write.Execute(context.Background(), fmt.Sprintf(`{"path": %q, "content": "windows"}`, target)); err != nil {
+		t.Fatalf("write_file error: %v", err)
+	}
+
+	read := NewReadFileTool(ws, cfg)
+	result, err := read.Execute(context.Background(), fmt.Sprintf(`{"path": %q}`, filepath.ToSlash(target)))
+	if err != nil {
+		t.Fatalf("read_file error: %v", err)
+	}
+	if !contains(result, "windows") {
+		t.Errorf("read_file result = %s", result)
+	}
+
+	// Relative paths with backslashes stay inside the workspace
+	if _, err := write.Execute(context.Background(), `{"path": "docs\\readme.md", "content": "ok"}`); err != nil {
+		t.Fatalf("write_file error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(ws.Path(), "docs", "readme.md")); err != nil {
+		t.Errorf("relative path was not resolved in the workspace: %v", err)
+	}
+	if _, err := read.Execute(context.Background(), `{"path": "..\\outside.txt"}`); err == nil {
+		t.Error("read_file should reject paths escaping the workspace")
+	}
+}
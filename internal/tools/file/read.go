@@ -1,12 +1,14 @@
 package file
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/tools"
 	"github.com/aatumaykin/nexbot/internal/workspace"
 )
 
@@ -22,6 +24,7 @@ type ReadFileArgs struct {
 	Offset   int    `json:"offset,omitempty"`   // Line offset (0-based, defaults to 0)
 	Limit    int    `json:"limit,omitempty"`    // Maximum number of lines to read (defaults to 2000)
 	Encoding string `json:"encoding,omitempty"` // File encoding (defaults to "utf-8", only utf-8 is supported currently)
+	Attach   bool   `json:"attach,omitempty"`   // Deliver the file to the user with the answer
 }
 
 // NewReadFileTool creates a new ReadFileTool instance.
@@ -41,6 +44,11 @@ func (t *ReadFileTool) Name() string {
 	return "read_file"
 }
 
+// ReadOnly reports that the tool only reads.
+func (t *ReadFileTool) ReadOnly() bool {
+	return true
+}
+
 // Description returns a description of what the tool does.
 func (t *ReadFileTool) Description() string {
 	return "Read file contents from workspace. Returns content with line numbers."
@@ -70,6 +78,11 @@ func (t *ReadFileTool) Parameters() map[string]any {
 				"description": "The file encoding. Currently only 'utf-8' is supported. Examples: {\"path\": \"data.txt\", \"encoding\": \"utf-8\"}",
 				"default":     "utf-8",
 			},
+			"attach": map[string]any{
+				"type":        "boolean",
+				"description": "Send the file itself to the user together with the answer. Defaults to false. Examples: {\"path\": \"photo.png\", \"attach\": true}",
+				"default":     false,
+			},
 		},
 		"required": []string{"path"},
 	}
@@ -77,16 +90,26 @@ func (t *ReadFileTool) Parameters() map[string]any {
 
 // Execute reads the file content and returns it.
 // args is a JSON-encoded string containing the tool's input parameters.
-func (t *ReadFileTool) Execute(args string) (string, error) {
+func (t *ReadFileTool) Execute(ctx context.Context, args string) (string, error) {
+	result, err := t.ExecuteResult(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	return result.Content, nil
+}
+
+// ExecuteResult reads the file content and, if requested, attaches the
+// file itself to the answer.
+func (t *ReadFileTool) ExecuteResult(ctx context.Context, args string) (*tools.Result, error) {
 	// Parse arguments
 	var fileArgs ReadFileArgs
 	if err := parseJSON(args, &fileArgs); err != nil {
-		return "", fmt.Errorf("failed to parse arguments: %w", err)
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
 	}
 
 	// Validate arguments
 	if fileArgs.Path == "" {
-		return "", fmt.Errorf("path is required")
+		return nil, fmt.Errorf("path is required")
 	}
 
 	// Set defaults
@@ -104,7 +127,7 @@ func (t *ReadFileTool) Execute(args string) (string, error) {
 
 	// Validate encoding
 	if fileArgs.Encoding != "utf-8" {
-		return "", fmt.Errorf("unsupported encoding: %s (only utf-8 is supported)", fileArgs.Encoding)
+		return nil, fmt.Errorf("unsupported encoding: %s (only utf-8 is supported)", fileArgs.Encoding)
 	}
 
 	// Resolve path
@@ -116,29 +139,21 @@ func (t *ReadFileTool) Execute(args string) (string, error) {
 		cleanPath := filepath.Clean(fileArgs.Path)
 		// Check for directory traversal attempts
 		if strings.Contains(cleanPath, "..") {
-			return "", fmt.Errorf("path contains directory traversal attempt")
+			return nil, fmt.Errorf("path contains directory traversal attempt")
 		}
 		// Check whitelist_dirs on the clean path
-		allowed := false
-		for _, allowedDir := range t.cfg.Tools.File.WhitelistDirs {
-			// Exact check: path must either be equal to allowedDir or start with allowedDir + separator
-			if cleanPath == allowedDir || strings.HasPrefix(cleanPath, allowedDir+string(filepath.Separator)) {
-				allowed = true
-				break
-			}
-		}
-		if !allowed {
-			return "", fmt.Errorf("absolute path is not in whitelist_dirs")
+		if !t.inWhitelist(cleanPath) {
+			return nil, fmt.Errorf("absolute path is not in whitelist_dirs")
 		}
 		fullPath = cleanPath
 	} else {
 		// Relative path - resolve against workspace
 		if t.workspace == nil {
-			return "", fmt.Errorf("workspace is not configured")
+			return nil, fmt.Errorf("workspace is not configured")
 		}
 		fullPath, err = t.workspace.ResolvePath(fileArgs.Path)
 		if err != nil {
-			return "", fmt.Errorf("failed to resolve path: %w", err)
+			return nil, fmt.Errorf("failed to resolve path: %w", err)
 		}
 	}
 
@@ -146,29 +161,35 @@ func (t *ReadFileTool) Execute(args string) (string, error) {
 	info, err := os.Stat(fullPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return "", fmt.Errorf("file not found: %s", fullPath)
+			return nil, fmt.Errorf("file not found: %s", fullPath)
 		}
-		return "", fmt.Errorf("failed to access file: %w", err)
+		return nil, fmt.Errorf("failed to access file: %w", err)
 	}
 
 	// Check if it's a regular file
 	if info.IsDir() {
-		return "", fmt.Errorf("path is a directory, not a file: %s", fullPath)
+		return nil, fmt.Errorf("path is a directory, not a file: %s", fullPath)
 	}
 
 	// Read file content
 	content, err := os.ReadFile(fullPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// Split into lines and apply offset/limit
 	lines := splitLines(string(content))
 
+	result := &tools.Result{MimeType: "text/plain"}
+	if fileArgs.Attach {
+		result.Artifacts = []tools.Artifact{tools.NewArtifact(filepath.Clean(fullPath), "")}
+	}
+
 	// Apply offset
 	if fileArgs.Offset >= len(lines) {
-		return fmt.Sprintf("# File: %s\n# Offset %d is beyond file length (%d lines)\n",
-			filepath.Clean(fullPath), fileArgs.Offset, len(lines)), nil
+		result.Content = fmt.Sprintf("# File: %s\n# Offset %d is beyond file length (%d lines)\n",
+			filepath.Clean(fullPath), fileArgs.Offset, len(lines))
+		return result, nil
 	}
 
 	startLine := fileArgs.Offset
@@ -177,14 +198,15 @@ func (t *ReadFileTool) Execute(args string) (string, error) {
 	selectedLines := lines[startLine:endLine]
 
 	// Format output with line numbers
-	var result strings.Builder
-	result.WriteString(fmt.Sprintf("# File: %s (lines %d-%d of %d)\n",
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("# File: %s (lines %d-%d of %d)\n",
 		filepath.Clean(fullPath), startLine+1, endLine, len(lines)))
 
 	for i, line := range selectedLines {
 		lineNum := startLine + i + 1
-		result.WriteString(fmt.Sprintf("%06d| %s\n", lineNum, line))
+		output.WriteString(fmt.Sprintf("%06d| %s\n", lineNum, line))
 	}
 
-	return result.String(), nil
+	result.Content = output.String()
+	return result, nil
 }
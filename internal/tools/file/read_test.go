@@ -1,6 +1,7 @@
 package file
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -121,7 +122,7 @@ func TestReadFileTool_Execute_Success(t *testing.T) {
 
 	// Execute
 	args := `{"path": "test.txt"}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -137,6 +138,33 @@ func TestReadFileTool_Execute_Success(t *testing.T) {
 	}
 }
 
+func TestReadFileTool_ExecuteResult(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws := workspace.New(config.WorkspaceConfig{Path: tmpDir})
+
+	testFile := filepath.Join(tmpDir, "notes.txt")
+	if err := os.WriteFile(testFile, []byte("line1"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tool := NewReadFileTool(ws, testConfig())
+
+	result, err := tool.ExecuteResult(context.Background(), `{"path": "notes.txt", "attach": true}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.MimeType != "text/plain" {
+		t.Errorf("Expected mime type 'text/plain', got '%s'", result.MimeType)
+	}
+	if !contains(result.Content, "000001| line1") {
+		t.Errorf("Expected content with line numbers, got: %s", result.Content)
+	}
+	if len(result.Artifacts) != 1 || result.Artifacts[0].Path != testFile {
+		t.Errorf("Expected artifact for %s, got %v", testFile, result.Artifacts)
+	}
+}
+
 func TestReadFileTool_Execute_WithOffset(t *testing.T) {
 	tmpDir := t.TempDir()
 	ws := workspace.New(config.WorkspaceConfig{Path: tmpDir})
@@ -152,7 +180,7 @@ func TestReadFileTool_Execute_WithOffset(t *testing.T) {
 
 	// Read from line 2
 	args := `{"path": "test.txt", "offset": 1}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -183,7 +211,7 @@ func TestReadFileTool_Execute_WithLimit(t *testing.T) {
 
 	// Read only 2 lines
 	args := `{"path": "test.txt", "limit": 2}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -206,7 +234,7 @@ func TestReadFileTool_Execute_NotFound(t *testing.T) {
 	tool := NewReadFileTool(ws, testConfig())
 
 	args := `{"path": "nonexistent.txt"}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected error for nonexistent file")
 	}
@@ -230,7 +258,7 @@ func TestReadFileTool_Execute_Directory(t *testing.T) {
 	tool := NewReadFileTool(ws, testConfig())
 
 	args := `{"path": "subdir"}`
-	_, err = tool.Execute(args)
+	_, err = tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected error for directory path")
 	}
@@ -248,7 +276,7 @@ func TestReadFileTool_Execute_EscapeAttempt(t *testing.T) {
 
 	// Try to escape workspace
 	args := `{"path": "../etc/passwd"}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected error for path escape attempt")
 	}
@@ -262,7 +290,7 @@ func TestReadFileTool_Execute_MissingPath(t *testing.T) {
 	tool := NewReadFileTool(nil, testConfig())
 
 	args := `{}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected error for missing path")
 	}
@@ -285,7 +313,7 @@ func TestReadFileTool_Execute_UnsupportedEncoding(t *testing.T) {
 	tool := NewReadFileTool(ws, testConfig())
 
 	args := `{"path": "test.txt", "encoding": "iso-8859-1"}`
-	_, err = tool.Execute(args)
+	_, err = tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected error for unsupported encoding")
 	}
@@ -299,7 +327,7 @@ func TestReadFileTool_Execute_InvalidJSON(t *testing.T) {
 	tool := NewReadFileTool(nil, testConfig())
 
 	args := `{invalid json}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected error for invalid JSON")
 	}
@@ -325,8 +353,8 @@ func TestReadFileTool_Execute_AbsolutePath(t *testing.T) {
 	cfg.Tools.File.WhitelistDirs = []string{tmpDir}
 	tool := NewReadFileTool(ws, cfg)
 
-	args := fmt.Sprintf(`{"path": "%s"}`, testFile)
-	result, err := tool.Execute(args)
+	args := fmt.Sprintf(`{"path": %q}`, testFile)
+	result, err := tool.Execute(context.Background(), args)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -351,7 +379,7 @@ func TestReadFileTool_Execute_BeyondFileLength(t *testing.T) {
 
 	// Try to read from beyond file length
 	args := `{"path": "test.txt", "offset": 10}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -376,7 +404,7 @@ func TestReadFileTool_Execute_CRLF(t *testing.T) {
 	tool := NewReadFileTool(ws, testConfig())
 
 	args := `{"path": "test.txt"}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -400,7 +428,7 @@ func TestReadFileTool_Execute_EmptyFile(t *testing.T) {
 	tool := NewReadFileTool(ws, testConfig())
 
 	args := `{"path": "test.txt"}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -426,7 +454,7 @@ func TestReadFileTool_Execute_LargeOffset(t *testing.T) {
 
 	// Negative offset should be treated as 0
 	args := `{"path": "test.txt", "offset": -5}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -561,7 +589,7 @@ func TestReadFileTool_Execute_PathTraversal(t *testing.T) {
 
 	// Test relative path traversal
 	args := `{"path": "../test.txt"}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected error for path traversal")
 	}
@@ -569,8 +597,8 @@ func TestReadFileTool_Execute_PathTraversal(t *testing.T) {
 	// Test absolute path traversal outside whitelist_dirs
 	parentDir := filepath.Dir(tmpDir)
 	parentFile := filepath.Join(parentDir, "test.txt")
-	args = fmt.Sprintf(`{"path": "%s"}`, parentFile)
-	_, err = tool.Execute(args)
+	args = fmt.Sprintf(`{"path": %q}`, parentFile)
+	_, err = tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected error for absolute path outside whitelist_dirs")
 	}
@@ -593,8 +621,8 @@ func TestReadFileTool_Execute_AbsolutePathWithTraversal(t *testing.T) {
 	// After cleaning, /tmp/xxx/../../test.txt becomes /tmp/test.txt
 	// which is outside tmpDir whitelist
 	traversalPath := filepath.Join(tmpDir, "..", "..", "test.txt")
-	args := fmt.Sprintf(`{"path": "%s"}`, traversalPath)
-	_, err := tool.Execute(args)
+	args := fmt.Sprintf(`{"path": %q}`, traversalPath)
+	_, err := tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected error for absolute path outside whitelist_dirs")
 	}
@@ -0,0 +1,116 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/stt"
+	"github.com/aatumaykin/nexbot/internal/workspace"
+)
+
+// TranscribeAudioTool implements the Tool interface for converting speech in
+// audio files to text.
+type TranscribeAudioTool struct {
+	fileToolBase
+	provider stt.Provider
+	language string
+}
+
+// TranscribeAudioArgs represents the arguments for the transcribe_audio tool.
+type TranscribeAudioArgs struct {
+	Path     string `json:"path"`               // Path to the audio file (relative to workspace or absolute)
+	Language string `json:"language,omitempty"` // ISO-639-1 language of the audio
+}
+
+// NewTranscribeAudioTool creates a new TranscribeAudioTool instance.
+// language is the default language of the audio (empty detects it).
+func NewTranscribeAudioTool(ws *workspace.Workspace, cfg *config.Config, provider stt.Provider, language string) *TranscribeAudioTool {
+	return &TranscribeAudioTool{
+		fileToolBase: fileToolBase{
+			workspace: ws,
+			cfg:       cfg,
+		},
+		provider: provider,
+		language: language,
+	}
+}
+
+// Name returns the tool name.
+func (t *TranscribeAudioTool) Name() string {
+	return "transcribe_audio"
+}
+
+// ReadOnly reports that the tool only reads.
+func (t *TranscribeAudioTool) ReadOnly() bool {
+	return true
+}
+
+// Description returns a description of what the tool does.
+func (t *TranscribeAudioTool) Description() string {
+	return "Transcribe speech in an audio file (ogg, mp3, wav, m4a, ...) in workspace to text."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *TranscribeAudioTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "The path to the audio file. Can be absolute or relative to the workspace directory. Examples: {\"path\": \"uploads/meeting.ogg\"}",
+			},
+			"language": map[string]any{
+				"type":        "string",
+				"description": "Optional ISO-639-1 language of the audio, e.g. \"en\" or \"ru\". Omit to detect it.",
+			},
+		},
+		"required": []string{"path"},
+	}
+}
+
+// Execute transcribes the audio file and returns the text.
+func (t *TranscribeAudioTool) Execute(ctx context.Context, args string) (string, error) {
+	var params TranscribeAudioArgs
+	if err := parseJSON(args, &params); err != nil {
+		return "", fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	if params.Path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+
+	fullPath, err := t.resolvePath(params.Path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("file not found: %s", fullPath)
+		}
+		return "", fmt.Errorf("failed to access file: %w", err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("path is a directory, not a file: %s", fullPath)
+	}
+
+	language := params.Language
+	if language == "" {
+		language = t.language
+	}
+
+	start := time.Now()
+	text, err := t.provider.Transcribe(ctx, fullPath, stt.Options{Language: language})
+	if errors.Is(err, stt.ErrNoSpeech) {
+		return fmt.Sprintf("# Audio: %s\n# No speech recognized\n", fullPath), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to transcribe audio: %w", err)
+	}
+
+	return fmt.Sprintf("# Audio: %s\n# Transcribed by %s in %s\n\n%s\n",
+		fullPath, t.provider.Name(), time.Since(start).Round(100*time.Millisecond), text), nil
+}
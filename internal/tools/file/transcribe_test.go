@@ -0,0 +1,67 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/stt"
+	"github.com/aatumaykin/nexbot/internal/workspace"
+)
+
+// fakeSTT is an stt.Provider returning a fixed transcript.
+type fakeSTT struct {
+	text     string
+	err      error
+	path     string
+	language string
+}
+
+func (f *fakeSTT) Name() string { return "fake" }
+
+func (f *fakeSTT) Transcribe(ctx context.Context, path string, opts stt.Options) (string, error) {
+	f.path = path
+	f.language = opts.Language
+	return f.text, f.err
+}
+
+func TestTranscribeAudioTool_Execute(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws := workspace.New(config.WorkspaceConfig{Path: tmpDir})
+	if err := os.WriteFile(filepath.Join(tmpDir, "memo.ogg"), []byte("audio"), 0644); err != nil {
+		t.Fatalf("Failed to write audio file: %v", err)
+	}
+
+	provider := &fakeSTT{text: "Buy milk tomorrow."}
+	tool := NewTranscribeAudioTool(ws, testConfig(), provider, "en")
+
+	result, err := tool.Execute(context.Background(), `{"path": "memo.ogg"}`)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.HasSuffix(result, "\n\nBuy milk tomorrow.\n") {
+		t.Errorf("Execute() = %q", result)
+	}
+	if provider.path != filepath.Join(tmpDir, "memo.ogg") || provider.language != "en" {
+		t.Errorf("Transcribe() called with %q, %q", provider.path, provider.language)
+	}
+
+	// The language argument overrides the default
+	if _, err := tool.Execute(context.Background(), `{"path": "memo.ogg", "language": "ru"}`); err != nil || provider.language != "ru" {
+		t.Errorf("Execute(language) = %v, language %q", err, provider.language)
+	}
+
+	provider.err = stt.ErrNoSpeech
+	if result, err := tool.Execute(context.Background(), `{"path": "memo.ogg"}`); err != nil || !strings.Contains(result, "No speech recognized") {
+		t.Errorf("Execute(silence) = %q, %v", result, err)
+	}
+
+	for _, args := range []string{`{"path": ""}`, `{"path": "missing.ogg"}`, `{"path": "../memo.ogg"}`, `{"path": "/etc/passwd"}`} {
+		if _, err := tool.Execute(context.Background(), args); err == nil {
+			t.Errorf("Execute(%s) error = nil", args)
+		}
+	}
+}
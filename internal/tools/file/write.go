@@ -1,12 +1,14 @@
 package file
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/tools"
 	"github.com/aatumaykin/nexbot/internal/workspace"
 )
 
@@ -14,13 +16,20 @@ import (
 // It writes content to a file in the workspace.
 type WriteFileTool struct {
 	fileToolBase
+	scrubber Scrubber // Masks personal data in memory files (nil — off)
+}
+
+// Scrubber masks personal data in text (implemented by pii.Scrubber).
+type Scrubber interface {
+	Scrub(text string) string
 }
 
 // WriteFileArgs represents the arguments for the write_file tool.
 type WriteFileArgs struct {
-	Path    string `json:"path"`           // Path to the file (relative to workspace or absolute)
-	Content string `json:"content"`        // Content to write to the file
-	Mode    string `json:"mode,omitempty"` // Write mode: "create" (default), "append", "overwrite"
+	Path    string `json:"path"`             // Path to the file (relative to workspace or absolute)
+	Content string `json:"content"`          // Content to write to the file
+	Mode    string `json:"mode,omitempty"`   // Write mode: "create" (default), "append", "overwrite"
+	Attach  bool   `json:"attach,omitempty"` // Deliver the written file to the user with the answer
 }
 
 // NewWriteFileTool creates a new WriteFileTool instance.
@@ -35,11 +44,108 @@ func NewWriteFileTool(ws *workspace.Workspace, cfg *config.Config) *WriteFileToo
 	}
 }
 
+// SetScrubber enables masking of personal data in content written to the
+// workspace memory directory.
+func (t *WriteFileTool) SetScrubber(scrubber Scrubber) {
+	t.scrubber = scrubber
+}
+
 // Name returns the tool name.
 func (t *WriteFileTool) Name() string {
 	return "write_file"
 }
 
+// Destructive reports whether a call overwrites an existing file.
+func (t *WriteFileTool) Destructive(args string) bool {
+	var fileArgs WriteFileArgs
+	if err := parseJSON(args, &fileArgs); err != nil || fileArgs.Mode != "overwrite" {
+		return false
+	}
+	path, err := t.resolvePath(fileArgs.Path)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// DryRun reports the diff a call would apply to the file.
+func (t *WriteFileTool) DryRun(ctx context.Context, args string) (string, bool, error) {
+	var fileArgs WriteFileArgs
+	if err := parseJSON(args, &fileArgs); err != nil {
+		return "", true, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	if fileArgs.Path == "" {
+		return "", true, fmt.Errorf("path is required")
+	}
+	if fileArgs.Content == "" {
+		return "", true, fmt.Errorf("content is required")
+	}
+	if fileArgs.Mode == "" {
+		fileArgs.Mode = "create"
+	}
+
+	path, err := t.resolvePath(fileArgs.Path)
+	if err != nil {
+		return "", true, err
+	}
+	if isSkillPath(path) && t.workspace != nil {
+		if err := validateSkillPath(path, t.workspace.Path()); err != nil {
+			return "", true, err
+		}
+	}
+	if t.scrubber != nil && t.workspace != nil &&
+		workspace.IsWithin(path, t.workspace.Subpath(workspace.SubdirMemory)) {
+		fileArgs.Content = t.scrubber.Scrub(fileArgs.Content)
+	}
+
+	old, err := os.ReadFile(path)
+	fileExists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return "", true, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var action, content string
+	switch fileArgs.Mode {
+	case "create":
+		if fileExists {
+			return "", true, fmt.Errorf("file already exists and mode is 'create': %s", path)
+		}
+		action, content = "create", fileArgs.Content
+	case "append":
+		if !fileExists {
+			return "", true, fmt.Errorf("file does not exist and mode is 'append': %s", path)
+		}
+		action, content = "append to", string(old)+fileArgs.Content
+	case "overwrite":
+		action, content = "overwrite", fileArgs.Content
+		if !fileExists {
+			action = "create"
+		}
+	default:
+		return "", true, fmt.Errorf("invalid mode '%s', must be one of: create, append, overwrite", fileArgs.Mode)
+	}
+
+	diff := "(no changes)"
+	if d := unifiedDiff(path, string(old), content, fileExists); d != "" {
+		diff = "```diff\n" + d + "\n```"
+	}
+	return fmt.Sprintf("Would %s %s (%d bytes):\n%s", action, path, len(fileArgs.Content), diff), true, nil
+}
+
+// AffectedPaths returns the file a call writes.
+func (t *WriteFileTool) AffectedPaths(args string) []string {
+	var fileArgs WriteFileArgs
+	if err := parseJSON(args, &fileArgs); err != nil || fileArgs.Path == "" {
+		return nil
+	}
+	path, err := t.resolvePath(fileArgs.Path)
+	if err != nil {
+		return nil
+	}
+	return []string{path}
+}
+
 // Description returns a description of what the tool does.
 func (t *WriteFileTool) Description() string {
 	return "Write content to a file in workspace. Supports create, append, overwrite modes."
@@ -64,6 +170,11 @@ func (t *WriteFileTool) Parameters() map[string]any {
 				"enum":        []string{"create", "append", "overwrite"},
 				"default":     "create",
 			},
+			"attach": map[string]any{
+				"type":        "boolean",
+				"description": "Send the written file to the user together with the answer. Defaults to false. Examples: {\"path\": \"report.csv\", \"content\": \"a,b\\n1,2\", \"mode\": \"overwrite\", \"attach\": true}",
+				"default":     false,
+			},
 		},
 		"required": []string{"path", "content"},
 	}
@@ -71,19 +182,29 @@ func (t *WriteFileTool) Parameters() map[string]any {
 
 // Execute writes content to a file.
 // args is a JSON-encoded string containing the tool's input parameters.
-func (t *WriteFileTool) Execute(args string) (string, error) {
+func (t *WriteFileTool) Execute(ctx context.Context, args string) (string, error) {
+	result, err := t.ExecuteResult(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	return result.Content, nil
+}
+
+// ExecuteResult writes content to a file and, if requested, attaches the
+// written file to the answer.
+func (t *WriteFileTool) ExecuteResult(ctx context.Context, args string) (*tools.Result, error) {
 	// Parse arguments
 	var fileArgs WriteFileArgs
 	if err := parseJSON(args, &fileArgs); err != nil {
-		return "", fmt.Errorf("failed to parse arguments: %w", err)
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
 	}
 
 	// Validate arguments
 	if fileArgs.Path == "" {
-		return "", fmt.Errorf("path is required")
+		return nil, fmt.Errorf("path is required")
 	}
 	if fileArgs.Content == "" {
-		return "", fmt.Errorf("content is required")
+		return nil, fmt.Errorf("content is required")
 	}
 
 	// Set defaults
@@ -94,7 +215,7 @@ func (t *WriteFileTool) Execute(args string) (string, error) {
 	// Validate mode
 	validModes := map[string]bool{"create": true, "append": true, "overwrite": true}
 	if !validModes[fileArgs.Mode] {
-		return "", fmt.Errorf("invalid mode '%s', must be one of: create, append, overwrite", fileArgs.Mode)
+		return nil, fmt.Errorf("invalid mode '%s', must be one of: create, append, overwrite", fileArgs.Mode)
 	}
 
 	// Resolve path
@@ -107,11 +228,11 @@ func (t *WriteFileTool) Execute(args string) (string, error) {
 	} else {
 		// Relative path - resolve against workspace
 		if t.workspace == nil {
-			return "", fmt.Errorf("workspace is not configured")
+			return nil, fmt.Errorf("workspace is not configured")
 		}
 		fullPath, err = t.workspace.ResolvePath(fileArgs.Path)
 		if err != nil {
-			return "", fmt.Errorf("failed to resolve path: %w", err)
+			return nil, fmt.Errorf("failed to resolve path: %w", err)
 		}
 	}
 
@@ -126,13 +247,13 @@ func (t *WriteFileTool) Execute(args string) (string, error) {
 		}
 
 		if err := validateSkillPath(cleanPath, workspaceRoot); err != nil {
-			return "", err
+			return nil, err
 		}
 
 		// Validate skill content if enabled in config
 		if t.cfg.Tools.File.ValidateSkillContent {
 			if err := validateSkillContent(fileArgs.Content); err != nil {
-				return "", fmt.Errorf("skill content validation failed: %w", err)
+				return nil, fmt.Errorf("skill content validation failed: %w", err)
 			}
 		}
 	}
@@ -140,33 +261,37 @@ func (t *WriteFileTool) Execute(args string) (string, error) {
 	// Check for directory traversal attempts
 	if filepath.IsAbs(fileArgs.Path) {
 		// Check whitelist_dirs
-		allowed := false
-		for _, allowedDir := range t.cfg.Tools.File.WhitelistDirs {
-			// Exact check: path must either be equal to allowedDir or start with allowedDir + separator
-			if fullPath == allowedDir || strings.HasPrefix(fullPath, allowedDir+string(filepath.Separator)) {
-				allowed = true
-				break
-			}
-		}
-		if !allowed {
-			return "", fmt.Errorf("absolute paths are not allowed")
+		if !t.inWhitelist(fullPath) {
+			return nil, fmt.Errorf("absolute paths are not allowed")
 		}
 		// Additional check for directory traversal
 		if strings.Contains(cleanPath, "..") {
-			return "", fmt.Errorf("path contains directory traversal attempt")
+			return nil, fmt.Errorf("path contains directory traversal attempt")
 		}
 	}
 
+	// Mask personal data before it is stored in memory
+	if t.scrubber != nil && t.workspace != nil &&
+		workspace.IsWithin(cleanPath, t.workspace.Subpath(workspace.SubdirMemory)) {
+		fileArgs.Content = t.scrubber.Scrub(fileArgs.Content)
+	}
+
 	// Create parent directories if they don't exist
 	parentDir := filepath.Dir(cleanPath)
 	if err := os.MkdirAll(parentDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create parent directories: %w", err)
+		return nil, fmt.Errorf("failed to create parent directories: %w", err)
 	}
 
 	// Check if file exists
 	_, err = os.Stat(cleanPath)
 	fileExists := err == nil
 
+	// Keep the old content for the diff of the change
+	var old []byte
+	if t.cfg.Tools.File.DiffSummary && fileExists && fileArgs.Mode == "overwrite" {
+		old, _ = os.ReadFile(cleanPath)
+	}
+
 	// Handle different modes
 	var file *os.File
 	defer func() {
@@ -178,41 +303,65 @@ func (t *WriteFileTool) Execute(args string) (string, error) {
 	switch fileArgs.Mode {
 	case "create":
 		if fileExists {
-			return "", fmt.Errorf("file already exists and mode is 'create': %s", cleanPath)
+			return nil, fmt.Errorf("file already exists and mode is 'create': %s", cleanPath)
 		}
 		file, err = os.Create(cleanPath)
 		if err != nil {
-			return "", fmt.Errorf("failed to create file: %w", err)
+			return nil, fmt.Errorf("failed to create file: %w", err)
 		}
 
 	case "append":
 		if !fileExists {
-			return "", fmt.Errorf("file does not exist and mode is 'append': %s", cleanPath)
+			return nil, fmt.Errorf("file does not exist and mode is 'append': %s", cleanPath)
 		}
 		file, err = os.OpenFile(cleanPath, os.O_WRONLY|os.O_APPEND, 0644)
 		if err != nil {
-			return "", fmt.Errorf("failed to open file for appending: %w", err)
+			return nil, fmt.Errorf("failed to open file for appending: %w", err)
 		}
 
 	case "overwrite":
 		file, err = os.Create(cleanPath)
 		if err != nil {
-			return "", fmt.Errorf("failed to create/overwrite file: %w", err)
+			return nil, fmt.Errorf("failed to create/overwrite file: %w", err)
 		}
 
 	default:
-		return "", fmt.Errorf("unknown mode: %s", fileArgs.Mode)
+		return nil, fmt.Errorf("unknown mode: %s", fileArgs.Mode)
 	}
 
 	// Write content
 	if _, err := file.WriteString(fileArgs.Content); err != nil {
-		return "", fmt.Errorf("failed to write content: %w", err)
+		return nil, fmt.Errorf("failed to write content: %w", err)
 	}
 
 	// Ensure content is flushed to disk
 	if err := file.Sync(); err != nil {
-		return "", fmt.Errorf("failed to sync file: %w", err)
+		return nil, fmt.Errorf("failed to sync file: %w", err)
 	}
 
-	return fmt.Sprintf("Successfully wrote %d bytes to %s", len(fileArgs.Content), cleanPath), nil
+	result := &tools.Result{
+		Content: fmt.Sprintf("Successfully wrote %d bytes to %s", len(fileArgs.Content), cleanPath),
+	}
+	if fileArgs.Attach {
+		result.Artifacts = []tools.Artifact{tools.NewArtifact(cleanPath, "")}
+	}
+	if t.cfg.Tools.File.DiffSummary {
+		result.Changes = t.changes(cleanPath, fileArgs, string(old), fileExists)
+	}
+	return result, nil
+}
+
+// changes returns the diff of a write. An append is diffed against the end of
+// the file only, so large files are not read back.
+func (t *WriteFileTool) changes(path string, fileArgs WriteFileArgs, old string, fileExists bool) []tools.FileChange {
+	var diff string
+	if fileArgs.Mode == "append" {
+		diff = unifiedDiff(path, "", fileArgs.Content, true)
+	} else {
+		diff = unifiedDiff(path, old, fileArgs.Content, fileExists)
+	}
+	if diff == "" {
+		return nil
+	}
+	return []tools.FileChange{{Path: path, Diff: diff}}
 }
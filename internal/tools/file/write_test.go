@@ -1,13 +1,16 @@
 package file
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/pii"
 	"github.com/aatumaykin/nexbot/internal/workspace"
 )
 
@@ -102,7 +105,7 @@ func TestWriteFileTool_Execute_CreateMode(t *testing.T) {
 	tool := NewWriteFileTool(ws, testConfig())
 
 	args := `{"path": "test.txt", "content": "Hello, World!"}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -124,6 +127,64 @@ func TestWriteFileTool_Execute_CreateMode(t *testing.T) {
 	}
 }
 
+func TestWriteFileTool_Execute_ScrubsMemory(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws := workspace.New(config.WorkspaceConfig{Path: tmpDir})
+	tool := NewWriteFileTool(ws, testConfig())
+	scrubber, err := pii.New(nil)
+	if err != nil {
+		t.Fatalf("pii.New() error = %v", err)
+	}
+	tool.SetScrubber(scrubber)
+
+	for _, path := range []string{"memory/contacts.md", "notes.md"} {
+		args := fmt.Sprintf(`{"path": %q, "content": "Bob: bob@example.com"}`, path)
+		if _, err := tool.Execute(context.Background(), args); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	memory, _ := os.ReadFile(filepath.Join(tmpDir, "memory", "contacts.md"))
+	if string(memory) != "Bob: [email]" {
+		t.Errorf("Expected masked memory file, got '%s'", memory)
+	}
+	notes, _ := os.ReadFile(filepath.Join(tmpDir, "notes.md"))
+	if string(notes) != "Bob: bob@example.com" {
+		t.Errorf("Files outside memory must not be masked, got '%s'", notes)
+	}
+}
+
+func TestWriteFileTool_ExecuteResult_Attach(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws := workspace.New(config.WorkspaceConfig{Path: tmpDir})
+	tool := NewWriteFileTool(ws, testConfig())
+
+	result, err := tool.ExecuteResult(context.Background(), `{"path": "report.csv", "content": "a,b", "attach": true}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.Artifacts) != 1 {
+		t.Fatalf("Expected 1 artifact, got %d", len(result.Artifacts))
+	}
+	artifact := result.Artifacts[0]
+	if artifact.Path != filepath.Join(tmpDir, "report.csv") {
+		t.Errorf("Expected artifact path %s, got %s", filepath.Join(tmpDir, "report.csv"), artifact.Path)
+	}
+	if artifact.Name != "report.csv" {
+		t.Errorf("Expected artifact name 'report.csv', got '%s'", artifact.Name)
+	}
+
+	// Without attach no artifacts are produced
+	result, err = tool.ExecuteResult(context.Background(), `{"path": "other.csv", "content": "a,b"}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Artifacts) != 0 {
+		t.Errorf("Expected no artifacts, got %v", result.Artifacts)
+	}
+}
+
 func TestWriteFileTool_Execute_AppendMode(t *testing.T) {
 	tmpDir := t.TempDir()
 	ws := workspace.New(config.WorkspaceConfig{Path: tmpDir})
@@ -137,7 +198,7 @@ func TestWriteFileTool_Execute_AppendMode(t *testing.T) {
 
 	// Append content
 	args := `{"path": "test.txt", "mode": "append", "content": "Appended"}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -171,7 +232,7 @@ func TestWriteFileTool_Execute_OverwriteMode(t *testing.T) {
 
 	// Overwrite content
 	args := `{"path": "test.txt", "mode": "overwrite", "content": "Overwritten"}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -192,6 +253,74 @@ func TestWriteFileTool_Execute_OverwriteMode(t *testing.T) {
 	}
 }
 
+func TestWriteFileTool_Destructive(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws := workspace.New(config.WorkspaceConfig{Path: tmpDir})
+	tool := NewWriteFileTool(ws, testConfig())
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "test.txt"), []byte("Initial"), 0644); err != nil {
+		t.Fatalf("Failed to create initial file: %v", err)
+	}
+
+	tests := map[string]bool{
+		`{"path": "test.txt", "mode": "overwrite", "content": "x"}`: true,
+		`{"path": "new.txt", "mode": "overwrite", "content": "x"}`:  false,
+		`{"path": "test.txt", "mode": "append", "content": "x"}`:    false,
+		`{"path": "test.txt", "content": "x"}`:                      false,
+	}
+	for args, want := range tests {
+		if got := tool.Destructive(args); got != want {
+			t.Errorf("Destructive(%s) = %v, want %v", args, got, want)
+		}
+	}
+}
+
+func TestWriteFileTool_DryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws := workspace.New(config.WorkspaceConfig{Path: tmpDir})
+	tool := NewWriteFileTool(ws, testConfig())
+
+	filePath := filepath.Join(tmpDir, "test.txt")
+	if err := os.WriteFile(filePath, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatalf("Failed to create initial file: %v", err)
+	}
+
+	report, mutates, err := tool.DryRun(context.Background(), `{"path": "test.txt", "mode": "overwrite", "content": "one\nthree\n"}`)
+	if err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+	if !mutates {
+		t.Error("Expected write_file to mutate")
+	}
+	for _, want := range []string{"Would overwrite", "-two", "+three", " one"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, report)
+		}
+	}
+
+	report, _, err = tool.DryRun(context.Background(), `{"path": "new.txt", "content": "hello\n"}`)
+	if err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+	if !strings.Contains(report, "Would create") || !strings.Contains(report, "--- /dev/null") || !strings.Contains(report, "+hello") {
+		t.Errorf("Expected a creation diff, got:\n%s", report)
+	}
+
+	// Calls that would fail report the same error
+	if _, _, err := tool.DryRun(context.Background(), `{"path": "test.txt", "content": "x"}`); err == nil {
+		t.Error("Expected error when file already exists in create mode")
+	}
+
+	// Nothing is written
+	content, _ := os.ReadFile(filePath)
+	if string(content) != "one\ntwo\n" {
+		t.Errorf("Expected the file to be unchanged, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "new.txt")); !os.IsNotExist(err) {
+		t.Error("Expected new.txt not to be created")
+	}
+}
+
 func TestWriteFileTool_Execute_CreateExistingFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	ws := workspace.New(config.WorkspaceConfig{Path: tmpDir})
@@ -205,7 +334,7 @@ func TestWriteFileTool_Execute_CreateExistingFile(t *testing.T) {
 
 	// Try to create again (should fail)
 	args := `{"path": "test.txt", "mode": "create", "content": "New content"}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	if err == nil {
 		t.Error("Expected error when file already exists in create mode")
@@ -223,7 +352,7 @@ func TestWriteFileTool_Execute_AppendNonExisting(t *testing.T) {
 
 	// Try to append to non-existent file
 	args := `{"path": "nonexistent.txt", "mode": "append", "content": "Content"}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	if err == nil {
 		t.Error("Expected error when appending to non-existent file")
@@ -240,7 +369,7 @@ func TestWriteFileTool_Execute_CreateDirectories(t *testing.T) {
 	tool := NewWriteFileTool(ws, testConfig())
 
 	args := `{"path": "subdir/test.txt", "content": "Content"}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -269,7 +398,7 @@ func TestWriteFileTool_Execute_InvalidMode(t *testing.T) {
 	tool := NewWriteFileTool(ws, testConfig())
 
 	args := `{"path": "test.txt", "content": "Content", "mode": "invalid"}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	if err == nil {
 		t.Error("Expected error for invalid mode")
@@ -301,16 +430,16 @@ func TestWriteFileTool_WhitelistAbsolutePaths(t *testing.T) {
 
 	// Тест 1: Абсолютный путь внутри whitelist должен работать
 	allowedFile := filepath.Join(whitelistDir, "allowed.txt")
-	args := fmt.Sprintf(`{"path": "%s", "content": "test content", "mode": "create"}`, allowedFile)
-	_, err := tool.Execute(args)
+	args := fmt.Sprintf(`{"path": %q, "content": "test content", "mode": "create"}`, allowedFile)
+	_, err := tool.Execute(context.Background(), args)
 	if err != nil {
 		t.Errorf("Expected absolute path in whitelist to be allowed, got error: %v", err)
 	}
 
 	// Тест 2: Абсолютный путь вне whitelist должен быть запрещён
-	forbiddenFile := "/tmp/forbidden.txt"
-	args = fmt.Sprintf(`{"path": "%s", "content": "test content", "mode": "create"}`, forbiddenFile)
-	_, err = tool.Execute(args)
+	forbiddenFile := filepath.Join(t.TempDir(), "forbidden.txt")
+	args = fmt.Sprintf(`{"path": %q, "content": "test content", "mode": "create"}`, forbiddenFile)
+	_, err = tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected absolute path outside whitelist to be rejected")
 	}
@@ -320,7 +449,7 @@ func TestWriteFileTool_WhitelistAbsolutePaths(t *testing.T) {
 
 	// Тест 3: Относительные пути должны работать (они относятся к workspace)
 	args = `{"path": "relative.txt", "content": "test content", "mode": "create"}`
-	_, err = tool.Execute(args)
+	_, err = tool.Execute(context.Background(), args)
 	if err != nil {
 		t.Errorf("Expected relative path to work, got error: %v", err)
 	}
@@ -332,7 +461,7 @@ func TestWriteFileTool_SkillPathValidation_InvalidLocation(t *testing.T) {
 	tool := NewWriteFileTool(ws, testConfig())
 
 	args := `{"path": "tmp/SKILL.md", "content": "---\nname: test\n---\ncontent"}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	if err == nil {
 		t.Error("Expected error for skill file outside skills/ directory")
@@ -349,7 +478,7 @@ func TestWriteFileTool_SkillPathValidation_InvalidFilename(t *testing.T) {
 	tool := NewWriteFileTool(ws, testConfig())
 
 	args := `{"path": "skills/my_skill/my_file.txt", "content": "content"}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	if err != nil {
 		t.Errorf("Expected non-SKILL.md file to succeed, got error: %v", err)
@@ -362,7 +491,7 @@ func TestWriteFileTool_SkillPathValidation_ValidPath(t *testing.T) {
 	tool := NewWriteFileTool(ws, testConfig())
 
 	args := `{"path": "skills/my_skill/SKILL.md", "content": "---\nname: test\n---\ncontent"}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	if err != nil {
 		t.Errorf("Expected valid skill path to succeed, got error: %v", err)
@@ -390,7 +519,7 @@ func TestWriteFileTool_SkillContentValidation_Enabled(t *testing.T) {
 	tool := NewWriteFileTool(ws, cfg)
 
 	args := `{"path": "skills/my_skill/SKILL.md", "content": "invalid content without frontmatter"}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	if err == nil {
 		t.Error("Expected error for skill content without YAML frontmatter")
@@ -417,7 +546,7 @@ func TestWriteFileTool_SkillContentValidation_Disabled(t *testing.T) {
 	tool := NewWriteFileTool(ws, cfg)
 
 	args := `{"path": "skills/my_skill/SKILL.md", "content": "invalid content without frontmatter"}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	if err != nil {
 		t.Errorf("Expected validation disabled to succeed, got error: %v", err)
@@ -448,7 +577,7 @@ version: 1.0.0
 Test content here`
 
 	args := fmt.Sprintf(`{"path": "skills/my_skill/SKILL.md", "content": %s}`, jsonEscape(content))
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	if err != nil {
 		t.Errorf("Expected valid skill content to succeed, got error: %v", err)
@@ -476,7 +605,7 @@ description: A test skill
 Test content without closing delimiter`
 
 	args := fmt.Sprintf(`{"path": "skills/my_skill/SKILL.md", "content": %s}`, jsonEscape(content))
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 
 	if err == nil {
 		t.Error("Expected error for skill content without closing YAML delimiter")
@@ -491,3 +620,48 @@ func jsonEscape(s string) string {
 	b, _ := json.Marshal(s)
 	return string(b)
 }
+
+func TestWriteFileTool_ExecuteResult_Changes(t *testing.T) {
+	tmpDir := t.TempDir()
+	ws := workspace.New(config.WorkspaceConfig{Path: tmpDir})
+	cfg := testConfig()
+	tool := NewWriteFileTool(ws, cfg)
+
+	filePath := filepath.Join(tmpDir, "test.txt")
+	if err := os.WriteFile(filePath, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatalf("Failed to create initial file: %v", err)
+	}
+
+	// Disabled: no changes are reported
+	args := `{"path": "test.txt", "mode": "overwrite", "content": "one\nthree\n"}`
+	result, err := tool.ExecuteResult(context.Background(), args)
+	if err != nil {
+		t.Fatalf("ExecuteResult failed: %v", err)
+	}
+	if len(result.Changes) != 0 {
+		t.Errorf("Expected no changes when diff_summary is off, got %+v", result.Changes)
+	}
+
+	cfg.Tools.File.DiffSummary = true
+	result, err = tool.ExecuteResult(context.Background(), `{"path": "test.txt", "mode": "overwrite", "content": "one\nfour\n"}`)
+	if err != nil {
+		t.Fatalf("ExecuteResult failed: %v", err)
+	}
+	if len(result.Changes) != 1 || result.Changes[0].Path != filePath {
+		t.Fatalf("Expected one change of %s, got %+v", filePath, result.Changes)
+	}
+	for _, want := range []string{"-three", "+four", " one"} {
+		if !strings.Contains(result.Changes[0].Diff, want) {
+			t.Errorf("Expected diff to contain %q, got:\n%s", want, result.Changes[0].Diff)
+		}
+	}
+
+	// An append shows the added lines only
+	result, err = tool.ExecuteResult(context.Background(), `{"path": "test.txt", "mode": "append", "content": "five\n"}`)
+	if err != nil {
+		t.Fatalf("ExecuteResult failed: %v", err)
+	}
+	if len(result.Changes) != 1 || !strings.Contains(result.Changes[0].Diff, "+five") || strings.Contains(result.Changes[0].Diff, "four") {
+		t.Errorf("Expected an append diff, got %+v", result.Changes)
+	}
+}
@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// FollowupScheduler schedules follow-ups of a chat (implemented by followup.Manager).
+type FollowupScheduler interface {
+	Schedule(sessionID string, at time.Time, task, note string) (agent.Job, error)
+	Pending(sessionID string) []agent.Job
+	Cancel(sessionID, jobID string) error
+}
+
+// ScheduleFollowupTool implements the Tool interface for agent-initiated
+// check-ins: the agent schedules a follow-up on an unfinished task and is
+// woken up later to check on it and report to the user.
+type ScheduleFollowupTool struct {
+	scheduler FollowupScheduler
+	logger    *logger.Logger
+}
+
+// ScheduleFollowupArgs represents the arguments for the schedule_followup tool.
+type ScheduleFollowupArgs struct {
+	Action    string `json:"action"`     // "schedule" (default), "list" or "cancel"
+	Task      string `json:"task"`       // What to follow up on
+	Note      string `json:"note"`       // Details needed for the check
+	InMinutes int    `json:"in_minutes"` // Delay of the follow-up
+	At        string `json:"at"`         // ISO8601 time of the follow-up (instead of in_minutes)
+	JobID     string `json:"job_id"`     // Follow-up to cancel
+}
+
+// NewScheduleFollowupTool creates a new ScheduleFollowupTool instance.
+func NewScheduleFollowupTool(scheduler FollowupScheduler, logger *logger.Logger) *ScheduleFollowupTool {
+	return &ScheduleFollowupTool{scheduler: scheduler, logger: logger}
+}
+
+// Name returns the tool name.
+func (t *ScheduleFollowupTool) Name() string {
+	return "schedule_followup"
+}
+
+// Description returns a description of what the tool does.
+func (t *ScheduleFollowupTool) Description() string {
+	return "Schedules a proactive follow-up on an unfinished task: at the given time you will be asked to check on it and report to the user without waiting for them to ask (\"the build you asked me to watch has finished\"). Use it when a task completes later (a build, a deployment, a delivery) and the user would want to hear the outcome. Works only if the user allowed follow-ups with /followups on; tell them about it if scheduling is refused."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *ScheduleFollowupTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"description": "Action: 'schedule' (default) to create a follow-up, 'list' to show pending follow-ups of this chat, 'cancel' to remove one.",
+				"enum":        []string{"schedule", "list", "cancel"},
+			},
+			"task": map[string]any{
+				"type":        "string",
+				"description": "What to follow up on, e.g. \"nightly build of the backend\". Required for 'schedule'.",
+			},
+			"note": map[string]any{
+				"type":        "string",
+				"description": "Details you will need for the check: URLs, IDs, commands, what the user expects.",
+			},
+			"in_minutes": map[string]any{
+				"type":        "integer",
+				"description": "Follow up in N minutes. Examples: {\"task\": \"CI pipeline #42\", \"in_minutes\": 30}",
+			},
+			"at": map[string]any{
+				"type":        "string",
+				"description": "ISO8601 time of the follow-up (e.g. '2026-02-05T09:00:00+03:00'), instead of in_minutes.",
+			},
+			"job_id": map[string]any{
+				"type":        "string",
+				"description": "Follow-up to cancel. Required for 'cancel'.",
+			},
+		},
+	}
+}
+
+// Execute executes the schedule_followup tool.
+func (t *ScheduleFollowupTool) Execute(ctx context.Context, args string) (string, error) {
+	var params ScheduleFollowupArgs
+	if err := parseJSON(args, &params); err != nil {
+		return "", fmt.Errorf("failed to parse schedule_followup arguments: %w", err)
+	}
+
+	sessionID := getSessionID(ctx)
+	if sessionID == "" {
+		return "", fmt.Errorf("follow-ups are only available within a conversation")
+	}
+
+	switch params.Action {
+	case "", "schedule":
+		return t.schedule(ctx, sessionID, params)
+	case "list":
+		return t.list(sessionID), nil
+	case "cancel":
+		if params.JobID == "" {
+			return "", fmt.Errorf("job_id parameter is required for cancel action")
+		}
+		if err := t.scheduler.Cancel(sessionID, params.JobID); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Follow-up %s cancelled.", params.JobID), nil
+	default:
+		return "", fmt.Errorf("invalid action: %s. Valid actions: schedule, list, cancel", params.Action)
+	}
+}
+
+// schedule creates a follow-up of the chat.
+func (t *ScheduleFollowupTool) schedule(ctx context.Context, sessionID string, params ScheduleFollowupArgs) (string, error) {
+	if strings.TrimSpace(params.Task) == "" {
+		return "", fmt.Errorf("task parameter is required for schedule action")
+	}
+
+	var at time.Time
+	switch {
+	case params.At != "":
+		parsed, err := time.Parse(time.RFC3339, params.At)
+		if err != nil {
+			return "", fmt.Errorf("invalid at format (expected ISO8601): %w", err)
+		}
+		at = parsed
+	case params.InMinutes > 0:
+		at = time.Now().Add(time.Duration(params.InMinutes) * time.Minute)
+	default:
+		return "", fmt.Errorf("in_minutes or at parameter is required for schedule action")
+	}
+
+	job, err := t.scheduler.Schedule(sessionID, at, params.Task, params.Note)
+	if err != nil {
+		return "", err
+	}
+
+	t.logger.InfoCtx(ctx, "follow-up scheduled",
+		logger.Field{Key: "job_id", Value: job.ID},
+		logger.Field{Key: "session_id", Value: sessionID},
+		logger.Field{Key: "execute_at", Value: at})
+
+	return fmt.Sprintf("Follow-up %s scheduled for %s: %s", job.ID, at.Local().Format("2006-01-02 15:04"), strings.TrimSpace(params.Task)), nil
+}
+
+// list formats the pending follow-ups of the chat.
+func (t *ScheduleFollowupTool) list(sessionID string) string {
+	pending := t.scheduler.Pending(sessionID)
+	if len(pending) == 0 {
+		return "No pending follow-ups in this chat."
+	}
+
+	var b strings.Builder
+	b.WriteString("Pending follow-ups:\n")
+	for _, job := range pending {
+		fmt.Fprintf(&b, "- %s at %s: %s\n", job.ID, job.ExecuteAt.Local().Format("2006-01-02 15:04"), job.Metadata["task"])
+	}
+	return b.String()
+}
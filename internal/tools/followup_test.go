@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/cron"
+	"github.com/aatumaykin/nexbot/internal/followup"
+	"github.com/aatumaykin/nexbot/internal/users"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleFollowupTool(t *testing.T) {
+	scheduler, storage, log, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	registry := users.NewRegistry(t.TempDir())
+	require.NoError(t, registry.Load(nil))
+	manager := followup.NewManager(cron.NewCronSchedulerAdapter(scheduler, storage), registry, followup.Config{})
+	tool := NewScheduleFollowupTool(manager, log)
+	ctx := context.WithValue(context.Background(), sessionIDKey, "telegram:1")
+
+	// Follow-ups need the user's opt-in
+	_, err := tool.Execute(ctx, `{"task": "CI pipeline #42", "in_minutes": 30}`)
+	assert.True(t, errors.Is(err, followup.ErrNotOptedIn), "error = %v", err)
+
+	_, err = manager.SetOptIn("telegram:1", true)
+	require.NoError(t, err)
+
+	out, err := tool.Execute(ctx, `{"task": "CI pipeline #42", "note": "https://ci.example.com/42", "in_minutes": 30}`)
+	require.NoError(t, err)
+	assert.Contains(t, out, "CI pipeline #42")
+
+	pending := manager.Pending("telegram:1")
+	require.Len(t, pending, 1)
+	assert.Equal(t, "agent", pending[0].Tool)
+
+	out, err = tool.Execute(ctx, `{"action": "list"}`)
+	require.NoError(t, err)
+	assert.Contains(t, out, pending[0].ID)
+
+	_, err = tool.Execute(ctx, `{"action": "cancel", "job_id": "`+pending[0].ID+`"}`)
+	require.NoError(t, err)
+	out, _ = tool.Execute(ctx, `{"action": "list"}`)
+	assert.True(t, strings.HasPrefix(out, "No pending follow-ups"), "list = %q", out)
+
+	for _, args := range []string{
+		`{"task": "build"}`,
+		`{"task": "build", "at": "tomorrow"}`,
+		`{"in_minutes": 10}`,
+		`{"action": "cancel"}`,
+		`{"action": "snooze"}`,
+	} {
+		_, err := tool.Execute(ctx, args)
+		assert.Error(t, err, "Execute(%s)", args)
+	}
+	_, err = tool.Execute(context.Background(), `{"task": "build", "in_minutes": 10}`)
+	assert.Error(t, err, "Execute() without a session should fail")
+}
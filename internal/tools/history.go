@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+)
+
+// maxHistoryResults caps the limit argument of search_history
+const maxHistoryResults = 20
+
+// HistorySearcher finds earlier exchanges of a conversation (implemented by session.Manager).
+type HistorySearcher interface {
+	Search(sessionID string, opts session.SearchOptions) ([]session.Match, error)
+}
+
+// SearchHistoryTool implements the Tool interface for searching earlier
+// exchanges of the conversation, so old messages can be recalled without
+// keeping the whole history in context.
+type SearchHistoryTool struct {
+	searcher HistorySearcher
+}
+
+// SearchHistoryArgs represents the arguments for the search_history tool.
+type SearchHistoryArgs struct {
+	Query string `json:"query"` // Words to look for
+	Scope string `json:"scope"` // "current" (default) or "all"
+	Days  int    `json:"days"`  // Only exchanges of the last N days (0 = any time)
+	Limit int    `json:"limit"` // Maximum results (default 5)
+}
+
+// NewSearchHistoryTool creates a new SearchHistoryTool instance.
+func NewSearchHistoryTool(searcher HistorySearcher) *SearchHistoryTool {
+	return &SearchHistoryTool{searcher: searcher}
+}
+
+// Name returns the tool name.
+func (t *SearchHistoryTool) Name() string {
+	return "search_history"
+}
+
+// ReadOnly reports that the tool only reads.
+func (t *SearchHistoryTool) ReadOnly() bool {
+	return true
+}
+
+// Description returns a description of what the tool does.
+func (t *SearchHistoryTool) Description() string {
+	return "Searches earlier messages of this conversation and returns the matching exchanges (user message and reply) with their time. Use it when the user refers to something discussed before that is no longer in context (\"what did I ask you about nginx last week\"). Scope 'all' also searches saved named sessions."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *SearchHistoryTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"query": map[string]any{
+				"type":        "string",
+				"description": "Keywords to look for, e.g. \"nginx proxy\". Examples: {\"query\": \"nginx\", \"days\": 7}",
+			},
+			"scope": map[string]any{
+				"type":        "string",
+				"description": "Where to search: 'current' for this conversation (default), 'all' to include saved named sessions.",
+				"enum":        []string{"current", "all"},
+			},
+			"days": map[string]any{
+				"type":        "integer",
+				"description": "Only search exchanges of the last N days (default: any time).",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum number of results (default %d, at most %d).", session.DefaultSearchLimit, maxHistoryResults),
+			},
+		},
+		"required": []string{"query"},
+	}
+}
+
+// Execute searches the conversation history.
+func (t *SearchHistoryTool) Execute(ctx context.Context, args string) (string, error) {
+	var params SearchHistoryArgs
+	if err := parseJSON(args, &params); err != nil {
+		return "", fmt.Errorf("failed to parse search_history arguments: %w", err)
+	}
+	if strings.TrimSpace(params.Query) == "" {
+		return "", fmt.Errorf("query parameter is required")
+	}
+
+	sessionID := getSessionID(ctx)
+	if sessionID == "" {
+		return "", fmt.Errorf("history search is only available within a conversation")
+	}
+
+	opts := session.SearchOptions{
+		Query: params.Query,
+		Limit: min(params.Limit, maxHistoryResults),
+	}
+	switch params.Scope {
+	case "", "current":
+	case "all":
+		opts.AllSessions = true
+	default:
+		return "", fmt.Errorf("invalid scope: %s. Valid scopes: current, all", params.Scope)
+	}
+	if params.Days < 0 {
+		return "", fmt.Errorf("days must not be negative (got: %d)", params.Days)
+	}
+	if params.Days > 0 {
+		opts.Since = time.Now().AddDate(0, 0, -params.Days)
+	}
+
+	matches, err := t.searcher.Search(sessionID, opts)
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return fmt.Sprintf("No earlier messages match %q.", params.Query), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Earlier exchanges matching %q:\n", params.Query)
+	for i, match := range matches {
+		fmt.Fprintf(&b, "\n%d. ", i+1)
+		if !match.Time.IsZero() {
+			b.WriteString(match.Time.Local().Format("2006-01-02 15:04"))
+		}
+		if match.SessionID != sessionID {
+			fmt.Fprintf(&b, " [session %s", match.SessionID)
+			if match.Title != "" {
+				fmt.Fprintf(&b, ": %s", match.Title)
+			}
+			b.WriteString("]")
+		}
+		fmt.Fprintf(&b, "\nUser: %s\nAssistant: %s\n", match.Question, match.Answer)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+func TestSearchHistoryTool(t *testing.T) {
+	mgr, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	sess, _, _ := mgr.GetOrCreate("telegram:1")
+	_ = sess.Append(llm.Message{Role: llm.RoleUser, Content: "How do I reload nginx?"})
+	_ = sess.Append(llm.Message{Role: llm.RoleAssistant, Content: "Run nginx -s reload."})
+
+	tool := NewSearchHistoryTool(mgr)
+	ctx := context.WithValue(context.Background(), sessionIDKey, "telegram:1")
+
+	out, err := tool.Execute(ctx, `{"query": "nginx", "days": 7}`)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(out, "User: How do I reload nginx?") || !strings.Contains(out, "Assistant: Run nginx -s reload.") {
+		t.Errorf("Execute() = %q", out)
+	}
+
+	out, err = tool.Execute(ctx, `{"query": "postgres"}`)
+	if err != nil || !strings.Contains(out, "No earlier messages") {
+		t.Errorf("Execute(no match) = %q, %v", out, err)
+	}
+
+	if _, err := tool.Execute(ctx, `{"query": "nginx", "scope": "everywhere"}`); err == nil {
+		t.Error("Execute() with invalid scope should fail")
+	}
+	if _, err := tool.Execute(context.Background(), `{"query": "nginx"}`); err == nil {
+		t.Error("Execute() without a session should fail")
+	}
+}
@@ -1,9 +1,11 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"strings"
 	"time"
 
@@ -15,23 +17,39 @@ import (
 // SendMessageTool implements the Tool interface for sending messages through the message bus.
 // It allows the LLM to send messages to external channels (e.g., Telegram).
 type SendMessageTool struct {
-	sender agent.MessageSender
-	logger *logger.Logger
+	sender    agent.MessageSender
+	logger    *logger.Logger
+	pages     *bus.PageStore   // Store for paged lists (message_type "list")
+	templates MessageTemplates // Templates for "template" messages
 }
 
 // SendMessageArgs represents the arguments for the send message tool.
 type SendMessageArgs struct {
 	SessionID           string              `json:"session_id"`                      // required
 	Message             string              `json:"message,omitempty"`               // optional for edit/delete/media types
-	MessageType         string              `json:"message_type,omitempty"`          // text, edit, delete, photo, document
+	MessageType         string              `json:"message_type,omitempty"`          // text, edit, delete, photo, document, sticker, animation, list
 	Format              string              `json:"format,omitempty"`                // plain, markdown, html, markdownv2 (default: plain)
 	MessageID           string              `json:"message_id,omitempty"`            // required for edit/delete
-	MediaURL            string              `json:"media_url,omitempty"`             // required for photo/document
+	MediaURL            string              `json:"media_url,omitempty"`             // required for media types unless media_file_id is set
+	MediaFileID         string              `json:"media_file_id,omitempty"`         // channel file ID, alternative to media_url
 	MediaCaption        string              `json:"media_caption,omitempty"`         // optional caption for media
 	ReplyTo             string              `json:"reply_to,omitempty"`              // message ID to reply to
 	InlineKeyboard      *InlineKeyboardArgs `json:"inline_keyboard,omitempty"`       // optional
 	WaitForConfirmation *bool               `json:"wait_for_confirmation,omitempty"` // true for sync mode (default), false for async mode
 	Timeout             int                 `json:"timeout,omitempty"`               // timeout in seconds for sync mode (default: 5)
+	Items               []string            `json:"items,omitempty"`                 // required for list: list entries, one per line
+	PageSize            int                 `json:"page_size,omitempty"`             // optional for list: entries per page (default: 10)
+	Template            string              `json:"template,omitempty"`              // optional: message template to render instead of message
+	Data                map[string]any      `json:"data,omitempty"`                  // optional: template fields
+	Table               *TableArgs          `json:"table,omitempty"`                 // optional for text: table rendered by the channel after the message
+}
+
+// TableArgs represents a table for the send message tool.
+// Cells may be strings or numbers.
+type TableArgs struct {
+	Title   string  `json:"title,omitempty"`
+	Headers []any   `json:"headers,omitempty"`
+	Rows    [][]any `json:"rows"`
 }
 
 // InlineKeyboardArgs represents an inline keyboard for the send message tool.
@@ -55,6 +73,18 @@ func NewSendMessageTool(sender agent.MessageSender, logger *logger.Logger) *Send
 	}
 }
 
+// SetPageStore enables the "list" message type, which sends long lists
+// page by page with Prev/Next buttons.
+func (t *SendMessageTool) SetPageStore(pages *bus.PageStore) {
+	t.pages = pages
+}
+
+// SetTemplates enables sending messages by template name and data
+// instead of free-form text.
+func (t *SendMessageTool) SetTemplates(templates MessageTemplates) {
+	t.templates = templates
+}
+
 // Name returns the tool name.
 func (t *SendMessageTool) Name() string {
 	return "send_message"
@@ -67,7 +97,7 @@ func (t *SendMessageTool) Description() string {
 
 // Parameters returns the JSON Schema for the tool's parameters.
 func (t *SendMessageTool) Parameters() map[string]any {
-	return map[string]any{
+	params := map[string]any{
 		"type": "object",
 		"properties": map[string]any{
 			"session_id": map[string]any{
@@ -76,8 +106,8 @@ func (t *SendMessageTool) Parameters() map[string]any {
 			},
 			"message_type": map[string]any{
 				"type":        "string",
-				"description": "Message type: 'text' (default), 'edit', 'delete', 'photo', 'document'.",
-				"enum":        []string{"text", "edit", "delete", "photo", "document"},
+				"description": "Message type: 'text' (default), 'edit', 'delete', 'photo', 'document', 'sticker', 'animation', 'list'. Use 'list' for long lists (search results, notes, sessions): they are sent page by page with Prev/Next buttons.",
+				"enum":        []string{"text", "edit", "delete", "photo", "document", "sticker", "animation", "list"},
 			},
 			"message": map[string]any{
 				"type":        "string",
@@ -94,11 +124,15 @@ func (t *SendMessageTool) Parameters() map[string]any {
 			},
 			"media_url": map[string]any{
 				"type":        "string",
-				"description": "URL of the media file. Required for 'photo' and 'document' types.",
+				"description": "URL of the media file. Required for 'photo', 'document', 'sticker' and 'animation' types unless media_file_id is set.",
+			},
+			"media_file_id": map[string]any{
+				"type":        "string",
+				"description": "Channel file ID of the media, e.g. the file_id of a received sticker. Alternative to media_url.",
 			},
 			"media_caption": map[string]any{
 				"type":        "string",
-				"description": "Caption for the media (photo/document).",
+				"description": "Caption for the media (photo/document/animation).",
 			},
 			"reply_to": map[string]any{
 				"type":        "string",
@@ -146,14 +180,41 @@ func (t *SendMessageTool) Parameters() map[string]any {
 				"type":        "integer",
 				"description": "Timeout in seconds for sync mode (default: 5). Ignored in async mode.",
 			},
+			"items": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "List entries for 'list' type. 'message' is used as the list title.",
+			},
+			"page_size": map[string]any{
+				"type":        "integer",
+				"description": "Entries per page for 'list' type (default: 10).",
+			},
+			"table": map[string]any{
+				"type":        "object",
+				"description": "Table for 'text' type, sent after 'message' and rendered by the channel (a monospace block in Telegram). Use it for tabular data instead of Markdown pipe tables. Example: {\"headers\": [\"Host\", \"Used\"], \"rows\": [[\"nas\", \"93%\"]]}",
+				"properties": map[string]any{
+					"title":   map[string]any{"type": "string", "description": "Table title."},
+					"headers": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Column headers."},
+					"rows": map[string]any{
+						"type":        "array",
+						"description": "Rows of cells.",
+						"items":       map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					},
+				},
+				"required": []string{"rows"},
+			},
 		},
 		"required": []string{"session_id"},
 	}
+	if t.templates != nil {
+		maps.Copy(params["properties"].(map[string]any), templateProperties(t.templates))
+	}
+	return params
 }
 
 // Execute executes the send message tool.
 // args is a JSON-encoded string containing the tool's input parameters.
-func (t *SendMessageTool) Execute(args string) (string, error) {
+func (t *SendMessageTool) Execute(ctx context.Context, args string) (string, error) {
 	// Parse arguments
 	var params SendMessageArgs
 	if err := json.Unmarshal([]byte(args), &params); err != nil {
@@ -169,6 +230,18 @@ func (t *SendMessageTool) Execute(args string) (string, error) {
 		return "", errors.New("session_id must be in format 'channel:chat_id' (e.g., 'telegram:123456789')")
 	}
 
+	// A template replaces the message text and sets its format
+	if params.Template != "" {
+		rendered, err := renderTemplate(t.templates, params.Template, params.Data)
+		if err != nil {
+			return "", err
+		}
+		params.Message = rendered.Text
+		if params.Format == "" {
+			params.Format = string(rendered.Format)
+		}
+	}
+
 	// Default message_type is "text"
 	messageType := params.MessageType
 	if messageType == "" {
@@ -218,8 +291,26 @@ func (t *SendMessageTool) Execute(args string) (string, error) {
 		timeout = 5 * time.Second
 	}
 
+	// A paged list is sent as a text message showing the first page
+	if messageType == "list" {
+		if t.pages == nil {
+			return "", fmt.Errorf("list messages are not supported")
+		}
+		if len(params.Items) == 0 {
+			return "", fmt.Errorf("items parameter is required for list messages")
+		}
+		list := t.pages.Put(params.Message, params.Items, params.PageSize, format)
+		params.Message, keyboard = list.Render(0)
+		messageType = "text"
+	}
+
 	switch messageType {
 	case "text":
+		if params.Table != nil {
+			result, err = t.sendTable(userID, channelType, params, keyboard, format, timeout)
+			actionDesc = "text message with table"
+			break
+		}
 		if params.Message == "" {
 			return "", fmt.Errorf("message parameter is required for text messages")
 		}
@@ -351,8 +442,63 @@ func (t *SendMessageTool) Execute(args string) (string, error) {
 				actionDesc, params.SessionID, params.MediaURL), nil
 		}
 
+	case "sticker":
+		if params.MediaURL == "" && params.MediaFileID == "" {
+			return "", fmt.Errorf("media_url or media_file_id parameter is required for sticker messages")
+		}
+		media := &bus.MediaData{
+			Type:   "sticker",
+			URL:    params.MediaURL,
+			FileID: params.MediaFileID,
+		}
+		if waitForConfirmation {
+			result, err = t.sender.SendStickerMessage(userID, channelType, params.SessionID, media, keyboard, timeout)
+			actionDesc = "sticker message"
+		} else {
+			err = t.sender.SendStickerMessageAsync(userID, channelType, params.SessionID, media, keyboard)
+			actionDesc = "sticker message (async)"
+			if err != nil {
+				return "", fmt.Errorf("failed to send %s: %w", actionDesc, err)
+			}
+			t.logger.Info("send_message tool executed (async mode)",
+				logger.Field{Key: "session_id", Value: params.SessionID},
+				logger.Field{Key: "message_type", Value: messageType},
+				logger.Field{Key: "action", Value: actionDesc},
+				logger.Field{Key: "media", Value: mediaSource(params)})
+			return fmt.Sprintf("✅ %s queued successfully\n   Session: %s\n   Media: %s",
+				actionDesc, params.SessionID, mediaSource(params)), nil
+		}
+
+	case "animation":
+		if params.MediaURL == "" && params.MediaFileID == "" {
+			return "", fmt.Errorf("media_url or media_file_id parameter is required for animation messages")
+		}
+		media := &bus.MediaData{
+			Type:    "animation",
+			URL:     params.MediaURL,
+			FileID:  params.MediaFileID,
+			Caption: params.MediaCaption,
+		}
+		if waitForConfirmation {
+			result, err = t.sender.SendAnimationMessage(userID, channelType, params.SessionID, media, keyboard, format, timeout)
+			actionDesc = "animation message"
+		} else {
+			err = t.sender.SendAnimationMessageAsync(userID, channelType, params.SessionID, media, keyboard, format)
+			actionDesc = "animation message (async)"
+			if err != nil {
+				return "", fmt.Errorf("failed to send %s: %w", actionDesc, err)
+			}
+			t.logger.Info("send_message tool executed (async mode)",
+				logger.Field{Key: "session_id", Value: params.SessionID},
+				logger.Field{Key: "message_type", Value: messageType},
+				logger.Field{Key: "action", Value: actionDesc},
+				logger.Field{Key: "media", Value: mediaSource(params)})
+			return fmt.Sprintf("✅ %s queued successfully\n   Session: %s\n   Media: %s",
+				actionDesc, params.SessionID, mediaSource(params)), nil
+		}
+
 	default:
-		return "", fmt.Errorf("unknown message_type: %s (valid types: text, edit, delete, photo, document)", messageType)
+		return "", fmt.Errorf("unknown message_type: %s (valid types: text, edit, delete, photo, document, sticker, animation, list)", messageType)
 	}
 
 	if err != nil {
@@ -390,6 +536,10 @@ The message was not delivered. You may need to:
 		details = fmt.Sprintf("   Message: %s", params.Message)
 	case "photo", "document":
 		details = fmt.Sprintf("   Media URL: %s\n   Caption: %s", params.MediaURL, params.MediaCaption)
+	case "sticker":
+		details = fmt.Sprintf("   Media: %s", mediaSource(params))
+	case "animation":
+		details = fmt.Sprintf("   Media: %s\n   Caption: %s", mediaSource(params), params.MediaCaption)
 	case "delete":
 		details = fmt.Sprintf("   Deleted message ID: %s", params.MessageID)
 	}
@@ -398,10 +548,58 @@ The message was not delivered. You may need to:
 	if keyboard != nil {
 		keyboardInfo = fmt.Sprintf("\n   Keyboard: %d row(s)", len(keyboard.Rows))
 	}
+	// The ID lets the agent edit the message later or check its status
+	if result.MessageID != "" && messageType != "delete" {
+		keyboardInfo += fmt.Sprintf("\n   Message ID: %s", result.MessageID)
+	}
 	return fmt.Sprintf("✅ %s sent successfully\n   Session: %s\n%s%s",
 		actionDesc, params.SessionID, details, keyboardInfo), nil
 }
 
+// sendTable sends a text message with a table, waiting for confirmation.
+// Senders without table support get the table as a code block.
+func (t *SendMessageTool) sendTable(userID, channelType string, params SendMessageArgs, keyboard *bus.InlineKeyboard, format bus.FormatType, timeout time.Duration) (*agent.MessageResult, error) {
+	if len(params.Table.Rows) == 0 {
+		return nil, fmt.Errorf("table.rows parameter is required")
+	}
+	table := &bus.Table{
+		Title:   params.Table.Title,
+		Headers: tableCells(params.Table.Headers),
+		Rows:    make([][]string, len(params.Table.Rows)),
+	}
+	for i, row := range params.Table.Rows {
+		table.Rows[i] = tableCells(row)
+	}
+
+	if sender, ok := t.sender.(agent.TableSender); ok {
+		return sender.SendTableMessage(userID, channelType, params.SessionID, params.Message, table, keyboard, format, timeout)
+	}
+	message := strings.TrimSpace(params.Message + "\n\n```\n" + table.Text() + "\n```")
+	if format == "" {
+		format = bus.FormatTypeMarkdown
+	}
+	return t.sender.SendMessageWithKeyboard(userID, channelType, params.SessionID, message, keyboard, format, timeout)
+}
+
+// tableCells converts table cells of any JSON type to strings.
+func tableCells(cells []any) []string {
+	converted := make([]string, len(cells))
+	for i, cell := range cells {
+		if cell != nil {
+			converted[i] = fmt.Sprint(cell)
+		}
+	}
+	return converted
+}
+
+// mediaSource describes where the media of a message comes from: the URL or the file ID.
+func mediaSource(params SendMessageArgs) string {
+	if params.MediaFileID != "" {
+		return "file_id " + params.MediaFileID
+	}
+	return params.MediaURL
+}
+
 // ToSchema returns the OpenAI-compatible schema for this tool.
 func (t *SendMessageTool) ToSchema() map[string]any {
 	return t.Parameters()
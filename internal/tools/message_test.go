@@ -8,6 +8,7 @@ import (
 	"github.com/aatumaykin/nexbot/internal/agent"
 	"github.com/aatumaykin/nexbot/internal/bus"
 	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/msgtemplate"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -16,6 +17,7 @@ import (
 type mockMessageSender struct {
 	sendFunc         func(userID, channelType, sessionID, message string, timeout time.Duration) (*agent.MessageResult, error)
 	sendKeyboardFunc func(userID, channelType, sessionID, message string, keyboard *bus.InlineKeyboard, timeout time.Duration) (*agent.MessageResult, error)
+	sendAsyncFunc    func(userID, channelType, sessionID, message string) error
 }
 
 func (m *mockMessageSender) SendMessage(userID, channelType, sessionID, message string, format bus.FormatType, timeout time.Duration) (*agent.MessageResult, error) {
@@ -51,7 +53,18 @@ func (m *mockMessageSender) SendDocumentMessage(userID, channelType, sessionID s
 	return &agent.MessageResult{Success: true}, nil
 }
 
+func (m *mockMessageSender) SendStickerMessage(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard, timeout time.Duration) (*agent.MessageResult, error) {
+	return &agent.MessageResult{Success: true}, nil
+}
+
+func (m *mockMessageSender) SendAnimationMessage(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard, format bus.FormatType, timeout time.Duration) (*agent.MessageResult, error) {
+	return &agent.MessageResult{Success: true}, nil
+}
+
 func (m *mockMessageSender) SendMessageAsync(userID, channelType, sessionID, message string) error {
+	if m.sendAsyncFunc != nil {
+		return m.sendAsyncFunc(userID, channelType, sessionID, message)
+	}
 	return nil
 }
 
@@ -75,6 +88,14 @@ func (m *mockMessageSender) SendDocumentMessageAsync(userID, channelType, sessio
 	return nil
 }
 
+func (m *mockMessageSender) SendStickerMessageAsync(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard) error {
+	return nil
+}
+
+func (m *mockMessageSender) SendAnimationMessageAsync(userID, channelType, sessionID string, media *bus.MediaData, keyboard *bus.InlineKeyboard, format bus.FormatType) error {
+	return nil
+}
+
 // setupTestEnvironmentForMessage creates a test environment with message bus and logger.
 func setupTestEnvironmentForMessage(t *testing.T) (*bus.MessageBus, *logger.Logger, func()) {
 	// Create logger
@@ -140,7 +161,7 @@ func TestSendMessageToolDefaults(t *testing.T) {
 		"session_id": "telegram:123456789"
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "sent successfully", "Result should contain success message")
 	assert.Contains(t, result, "Session: telegram:123456789", "Result should contain session ID")
@@ -156,7 +177,7 @@ func TestSendMessageToolCustomSession(t *testing.T) {
 		"session_id": "telegram:456"
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "sent successfully", "Result should contain success message")
 	assert.Contains(t, result, "Session: telegram:456", "Result should contain custom session ID")
@@ -186,7 +207,7 @@ func TestSendMessageToolPublishError(t *testing.T) {
 		"session_id": "telegram:test-session"
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	// Should return error since sender returns error
 	assert.Error(t, err, "Execute should return error when sender fails")
 	assert.Empty(t, result, "Result should be empty on error")
@@ -201,7 +222,7 @@ func TestSendMessageToolMissingMessage(t *testing.T) {
 		"session_id": "telegram:123456789"
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.Error(t, err, "Execute should return error for missing message")
 	assert.Empty(t, result, "Result should be empty on error")
 	assert.Contains(t, err.Error(), "message parameter is required", "Error should mention required field")
@@ -213,7 +234,7 @@ func TestSendMessageToolInvalidJSON(t *testing.T) {
 
 	args := `{invalid json`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.Error(t, err, "Execute should return error for invalid JSON")
 	assert.Empty(t, result, "Result should be empty on error")
 	assert.Contains(t, err.Error(), "failed to parse send_message arguments", "Error should mention parse error")
@@ -228,7 +249,7 @@ func TestSendMessageToolAllCustom(t *testing.T) {
 		"session_id": "telegram:123456789"
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "sent successfully", "Result should contain success message")
 	assert.Contains(t, result, "Session: telegram:123456789", "Result should contain custom session ID")
@@ -342,7 +363,7 @@ func TestSendMessageToolWithInlineKeyboard(t *testing.T) {
 		}
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "sent successfully", "Result should contain success message")
 	assert.Contains(t, result, "Keyboard: 2 row(s)", "Result should mention keyboard")
@@ -395,7 +416,7 @@ func TestSendMessageToolWithEmptyKeyboard(t *testing.T) {
 		}
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.False(t, sentWithKeyboard, "Should not use SendMessageWithKeyboard for empty keyboard")
 	assert.Contains(t, result, "sent successfully", "Result should contain success message")
@@ -425,7 +446,7 @@ func TestSendMessageToolAsyncMode(t *testing.T) {
 		"wait_for_confirmation": false
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "queued successfully", "Result should mention async mode")
 	assert.Contains(t, result, "async", "Result should indicate async mode")
@@ -461,7 +482,7 @@ func TestSendMessageToolAsyncModeWithKeyboard(t *testing.T) {
 		}
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "queued successfully", "Result should mention async mode")
 	assert.False(t, usedAsync, "Should use async method, not sync")
@@ -487,7 +508,7 @@ func TestSendMessageToolAsyncModeEdit(t *testing.T) {
 		"wait_for_confirmation": false
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "queued successfully", "Result should mention async mode")
 	assert.Contains(t, result, "Message ID: 123", "Result should contain message ID")
@@ -512,7 +533,7 @@ func TestSendMessageToolAsyncModeDelete(t *testing.T) {
 		"wait_for_confirmation": false
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "queued successfully", "Result should mention async mode")
 	assert.Contains(t, result, "Message ID: 456", "Result should contain message ID")
@@ -538,7 +559,7 @@ func TestSendMessageToolAsyncModePhoto(t *testing.T) {
 		"wait_for_confirmation": false
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "queued successfully", "Result should mention async mode")
 	assert.Contains(t, result, "https://example.com/photo.jpg", "Result should contain media URL")
@@ -564,12 +585,50 @@ func TestSendMessageToolAsyncModeDocument(t *testing.T) {
 		"wait_for_confirmation": false
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "queued successfully", "Result should mention async mode")
 	assert.Contains(t, result, "https://example.com/file.pdf", "Result should contain media URL")
 }
 
+// TestSendMessageToolStickerAndAnimation tests sticker and animation messages by file ID and URL.
+func TestSendMessageToolStickerAndAnimation(t *testing.T) {
+	log, err := logger.New(logger.Config{
+		Level:  "error",
+		Format: "text",
+		Output: "stdout",
+	})
+	require.NoError(t, err, "Failed to create logger")
+
+	sender := &mockMessageSender{}
+	tool := NewSendMessageTool(sender, log)
+
+	result, err := tool.Execute(context.Background(), `{
+		"session_id": "telegram:123456789",
+		"message_type": "sticker",
+		"media_file_id": "CAACAgIAAxkBAAE"
+	}`)
+	assert.NoError(t, err, "Execute should not return error")
+	assert.Contains(t, result, "sticker message sent successfully")
+	assert.Contains(t, result, "file_id CAACAgIAAxkBAAE", "Result should contain file ID")
+
+	result, err = tool.Execute(context.Background(), `{
+		"session_id": "telegram:123456789",
+		"message_type": "animation",
+		"media_url": "https://example.com/cat.gif",
+		"wait_for_confirmation": false
+	}`)
+	assert.NoError(t, err, "Execute should not return error")
+	assert.Contains(t, result, "queued successfully", "Result should mention async mode")
+	assert.Contains(t, result, "https://example.com/cat.gif", "Result should contain media URL")
+
+	_, err = tool.Execute(context.Background(), `{
+		"session_id": "telegram:123456789",
+		"message_type": "sticker"
+	}`)
+	assert.ErrorContains(t, err, "media_url or media_file_id parameter is required")
+}
+
 // TestSendMessageToolCustomTimeout tests custom timeout in sync mode.
 func TestSendMessageToolCustomTimeout(t *testing.T) {
 	log, err := logger.New(logger.Config{
@@ -594,7 +653,7 @@ func TestSendMessageToolCustomTimeout(t *testing.T) {
 		"timeout": 10
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "sent successfully", "Result should contain success message")
 	assert.Equal(t, 10*time.Second, capturedTimeout, "Timeout should be 10 seconds")
@@ -623,7 +682,7 @@ func TestSendMessageToolDefaultTimeout(t *testing.T) {
 		"session_id": "telegram:123456789"
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "sent successfully", "Result should contain success message")
 	assert.Equal(t, 5*time.Second, capturedTimeout, "Default timeout should be 5 seconds")
@@ -653,9 +712,113 @@ func TestSendMessageToolWaitForConfirmationTrue(t *testing.T) {
 		"wait_for_confirmation": true
 	}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	assert.NoError(t, err, "Execute should not return error")
 	assert.Contains(t, result, "sent successfully", "Result should contain success message")
 	assert.NotContains(t, result, "queued successfully", "Result should not mention async mode")
 	assert.False(t, usedAsync, "Should use sync method")
 }
+
+func TestSendMessageTool_ListMessage(t *testing.T) {
+	var sentContent string
+	var sentKeyboard *bus.InlineKeyboard
+	sender := &mockMessageSender{
+		sendKeyboardFunc: func(userID, channelType, sessionID, message string, keyboard *bus.InlineKeyboard, timeout time.Duration) (*agent.MessageResult, error) {
+			sentContent = message
+			sentKeyboard = keyboard
+			return &agent.MessageResult{Success: true}, nil
+		},
+	}
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	require.NoError(t, err)
+
+	tool := NewSendMessageTool(sender, log)
+
+	args := `{"session_id": "telegram:1", "message_type": "list", "message": "Notes", "items": ["a", "b", "c"], "page_size": 2}`
+	_, err = tool.Execute(context.Background(), args)
+	assert.Error(t, err, "list messages require a page store")
+
+	tool.SetPageStore(bus.NewPageStore(0, 0))
+	_, err = tool.Execute(context.Background(), args)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Notes\n\na\nb", sentContent)
+	require.NotNil(t, sentKeyboard)
+	assert.Equal(t, "Next ▶", sentKeyboard.Rows[0][1].Text)
+
+	_, err = tool.Execute(context.Background(), `{"session_id": "telegram:1", "message_type": "list", "message": "Empty"}`)
+	assert.Error(t, err)
+}
+
+// TestSendMessageToolTemplate tests sending a message template by name and data.
+func TestSendMessageToolTemplate(t *testing.T) {
+	var sent string
+	sender := &mockMessageSender{
+		sendFunc: func(userID, channelType, sessionID, message string, timeout time.Duration) (*agent.MessageResult, error) {
+			sent = message
+			return &agent.MessageResult{Success: true}, nil
+		},
+	}
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	require.NoError(t, err)
+	tool := NewSendMessageTool(sender, log)
+
+	_, err = tool.Execute(context.Background(), `{"session_id": "telegram:1", "template": "build_failed"}`)
+	assert.Error(t, err, "templates are not configured")
+
+	templates, err := msgtemplate.NewRegistry(map[string]msgtemplate.Definition{
+		"build_failed": {Text: "Build {{.build}} failed: {{.error}}", Description: "CI build failure"},
+	})
+	require.NoError(t, err)
+	tool.SetTemplates(templates)
+
+	props := tool.Parameters()["properties"].(map[string]any)
+	require.Contains(t, props, "template")
+	assert.Contains(t, props["template"].(map[string]any)["description"], "build_failed (fields: build, error) — CI build failure")
+
+	_, err = tool.Execute(context.Background(), `{"session_id": "telegram:1", "template": "build_failed", "data": {"build": "#42", "error": "tests failed"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, "Build #42 failed: tests failed", sent)
+
+	_, err = tool.Execute(context.Background(), `{"session_id": "telegram:1", "template": "build_failed", "data": {"build": "#42"}}`)
+	assert.ErrorContains(t, err, "missing data fields: error")
+}
+
+// tableMessageSender is a mock sender that supports tables.
+type tableMessageSender struct {
+	mockMessageSender
+	table *bus.Table
+}
+
+func (m *tableMessageSender) SendTableMessage(userID, channelType, sessionID, message string, table *bus.Table, keyboard *bus.InlineKeyboard, format bus.FormatType, timeout time.Duration) (*agent.MessageResult, error) {
+	m.table = table
+	return &agent.MessageResult{Success: true}, nil
+}
+
+// TestSendMessageToolTable tests sending a table, natively and as a code block fallback.
+func TestSendMessageToolTable(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	require.NoError(t, err)
+	args := `{"session_id": "telegram:1", "message": "Disks:", "table": {"headers": ["Host", "Used"], "rows": [["nas", 93], ["web", null]]}}`
+
+	sender := &tableMessageSender{}
+	_, err = NewSendMessageTool(sender, log).Execute(context.Background(), args)
+	require.NoError(t, err)
+	require.NotNil(t, sender.table)
+	assert.Equal(t, []string{"Host", "Used"}, sender.table.Headers)
+	assert.Equal(t, [][]string{{"nas", "93"}, {"web", ""}}, sender.table.Rows)
+
+	var sent string
+	fallback := &mockMessageSender{
+		sendFunc: func(userID, channelType, sessionID, message string, timeout time.Duration) (*agent.MessageResult, error) {
+			sent = message
+			return &agent.MessageResult{Success: true}, nil
+		},
+	}
+	_, err = NewSendMessageTool(fallback, log).Execute(context.Background(), args)
+	require.NoError(t, err)
+	assert.Equal(t, "Disks:\n\n```\nHost  Used\n----  ----\nnas     93\nweb\n```", sent)
+
+	_, err = NewSendMessageTool(sender, log).Execute(context.Background(), `{"session_id": "telegram:1", "table": {"rows": []}}`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,199 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/monitor"
+)
+
+// MonitorManager manages web page monitors (implemented by monitor.Monitor).
+type MonitorManager interface {
+	Add(rule monitor.Rule) (monitor.Rule, error)
+	Remove(id string) error
+	List() []monitor.Status
+	CheckNow(ctx context.Context, id string) (bool, error)
+}
+
+// MonitorTool implements the Tool interface for web page monitor management.
+// It lets the agent subscribe a session to changes of a web page.
+type MonitorTool struct {
+	manager MonitorManager
+	logger  *logger.Logger
+}
+
+// MonitorArgs represents the arguments for the monitor tool.
+type MonitorArgs struct {
+	Action          string `json:"action"`           // Action: "add", "remove", "list", "check"
+	URL             string `json:"url"`              // Page to monitor
+	Selector        string `json:"selector"`         // CSS selector of the monitored part
+	IntervalMinutes int    `json:"interval_minutes"` // Check interval
+	SessionID       string `json:"session_id"`       // Session to notify
+	MonitorID       string `json:"monitor_id"`       // Monitor ID for removal and checks
+}
+
+// NewMonitorTool creates a new MonitorTool instance.
+func NewMonitorTool(manager MonitorManager, logger *logger.Logger) *MonitorTool {
+	return &MonitorTool{
+		manager: manager,
+		logger:  logger,
+	}
+}
+
+// Name returns the tool name.
+func (t *MonitorTool) Name() string {
+	return "monitor"
+}
+
+// Description returns a description of what the tool does.
+func (t *MonitorTool) Description() string {
+	return "Monitors web pages and notifies the session with a summary of what changed. Use it for requests like 'tell me when the price on this page changes'. Supports adding, listing, removing and checking monitors right away."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *MonitorTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"description": "Action to perform: 'add' to create a monitor, 'list' to show all monitors, 'remove' to delete a monitor, 'check' to check a monitor now.",
+				"enum":        []string{"add", "remove", "list", "check"},
+			},
+			"url": map[string]any{
+				"type":        "string",
+				"description": "URL of the page to monitor (http or https). Required for 'add' action.",
+			},
+			"selector": map[string]any{
+				"type":        "string",
+				"description": "CSS selector of the part of the page to monitor (e.g. '.price', '#releases li'). If empty, the text of the whole page is monitored.",
+			},
+			"interval_minutes": map[string]any{
+				"type":        "integer",
+				"description": "How often to check the page, in minutes. If omitted, the default interval is used.",
+			},
+			"session_id": map[string]any{
+				"type":        "string",
+				"description": "Session ID to notify. Format: 'channel:chat_id' (e.g., 'telegram:35052705'). Required for 'add' action.",
+			},
+			"monitor_id": map[string]any{
+				"type":        "string",
+				"description": "Monitor ID. Required for 'remove' and 'check' actions.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+// Execute executes the monitor tool.
+// args is a JSON-encoded string containing the tool's input parameters.
+func (t *MonitorTool) Execute(ctx context.Context, args string) (string, error) {
+	var params MonitorArgs
+	if err := json.Unmarshal([]byte(args), &params); err != nil {
+		return "", fmt.Errorf("failed to parse monitor arguments: %w", err)
+	}
+
+	switch params.Action {
+	case "add":
+		return t.add(ctx, params)
+	case "remove":
+		if params.MonitorID == "" {
+			return "", fmt.Errorf("monitor_id parameter is required for remove action")
+		}
+		if err := t.manager.Remove(params.MonitorID); err != nil {
+			return "", fmt.Errorf("failed to remove monitor: %w", err)
+		}
+		return fmt.Sprintf("Monitor %s removed", params.MonitorID), nil
+	case "list":
+		return t.list(), nil
+	case "check":
+		if params.MonitorID == "" {
+			return "", fmt.Errorf("monitor_id parameter is required for check action")
+		}
+		changed, err := t.manager.CheckNow(ctx, params.MonitorID)
+		if err != nil {
+			return "", fmt.Errorf("failed to check monitor: %w", err)
+		}
+		if changed {
+			return fmt.Sprintf("Monitor %s: content changed, the session will be notified", params.MonitorID), nil
+		}
+		return fmt.Sprintf("Monitor %s: no changes", params.MonitorID), nil
+	default:
+		return "", fmt.Errorf("invalid action: %s. Valid actions: add, remove, list, check", params.Action)
+	}
+}
+
+// add creates a new monitor and checks it once, so the first snapshot is
+// stored and a selector that matches nothing is reported right away.
+func (t *MonitorTool) add(ctx context.Context, params MonitorArgs) (string, error) {
+	if params.URL == "" {
+		return "", fmt.Errorf("url parameter is required for add action")
+	}
+	if params.SessionID == "" {
+		return "", fmt.Errorf("session_id parameter is required for add action")
+	}
+
+	rule, err := t.manager.Add(monitor.Rule{
+		URL:             params.URL,
+		Selector:        params.Selector,
+		IntervalMinutes: params.IntervalMinutes,
+		SessionID:       params.SessionID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to add monitor: %w", err)
+	}
+
+	t.logger.Info("monitor added via tool",
+		logger.Field{Key: "monitor_id", Value: rule.ID},
+		logger.Field{Key: "url", Value: rule.URL})
+
+	result := fmt.Sprintf("Monitor created successfully.\nMonitor ID: %s\nURL: %s\n%s", rule.ID, rule.URL, describeMonitor(rule))
+	if _, err := t.manager.CheckNow(ctx, rule.ID); err != nil {
+		result += fmt.Sprintf("\nWarning: the first check failed: %v. The monitor keeps trying on its interval.", err)
+	}
+	return result, nil
+}
+
+// list formats all monitors.
+func (t *MonitorTool) list() string {
+	statuses := t.manager.List()
+	if len(statuses) == 0 {
+		return "No monitors configured."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Monitors (%d):\n", len(statuses))
+	for _, s := range statuses {
+		fmt.Fprintf(&b, "\n- ID: %s\n  URL: %s\n  Session: %s\n  %s\n", s.ID, s.URL, s.SessionID, describeMonitor(s.Rule))
+		if !s.LastCheck.IsZero() {
+			fmt.Fprintf(&b, "  Last check: %s\n", s.LastCheck.Format(time.RFC3339))
+		}
+		if !s.LastChange.IsZero() {
+			fmt.Fprintf(&b, "  Last change: %s\n", s.LastChange.Format(time.RFC3339))
+		}
+		if s.LastError != "" {
+			fmt.Fprintf(&b, "  Last error: %s\n", s.LastError)
+		}
+		if s.Static {
+			b.WriteString("  Source: config (cannot be removed)\n")
+		}
+	}
+	return b.String()
+}
+
+// describeMonitor describes which part of a page a monitor checks and how often.
+func describeMonitor(r monitor.Rule) string {
+	part := "Part: whole page"
+	if r.Selector != "" {
+		part = fmt.Sprintf("Part: %s", r.Selector)
+	}
+	interval := "default interval"
+	if r.IntervalMinutes > 0 {
+		interval = fmt.Sprintf("every %d min", r.IntervalMinutes)
+	}
+	return fmt.Sprintf("%s; Checked: %s", part, interval)
+}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Tool namespaces group tools by what they act on. A tool is also addressed
+// by its qualified name namespace.name (fs.write, net.fetch). Tools keep
+// their plain names (write_file, web_fetch) towards the LLM: provider
+// function names can't contain dots, and sessions, prompts and budgets
+// written before namespaces refer to the plain names.
+const (
+	NamespaceFS        = "fs"
+	NamespaceNet       = "net"
+	NamespaceSys       = "sys"
+	NamespaceMessaging = "msg"
+	NamespaceAgent     = "agent"
+)
+
+// ErrNamespaceDisabled is returned when a tool of a disabled namespace is registered.
+var ErrNamespaceDisabled = errors.New("tool namespace is disabled")
+
+// namespacePattern matches valid namespace names
+var namespacePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// builtinQualified maps built-in tool names to their qualified names
+var builtinQualified = map[string]string{
+	"read_file":         "fs.read",
+	"write_file":        "fs.write",
+	"list_dir":          "fs.list",
+	"delete_file":       "fs.delete",
+	"transcribe_audio":  "fs.transcribe",
+	"web_fetch":         "net.fetch",
+	"shell_exec":        "sys.shell",
+	"process":           "sys.process",
+	"system_time":       "sys.time",
+	"send_message":      "msg.send",
+	"notify":            "msg.notify",
+	"pin_message":       "msg.pin",
+	"unpin_message":     "msg.unpin",
+	"set_chat_title":    "msg.set_chat_title",
+	"get_send_status":   "msg.send_status",
+	"edit_message":      "msg.edit",
+	"delete_message":    "msg.delete",
+	"cron":              "agent.cron",
+	"watch":             "agent.watch",
+	"monitor":           "agent.monitor",
+	"spawn":             "agent.spawn",
+	"artifacts":         "agent.artifacts",
+	"search_history":    "agent.search_history",
+	"schedule_followup": "agent.schedule_followup",
+	"plot":              "agent.plot",
+	"structured_output": "agent.structured_output",
+}
+
+// NamespacedTool is an optional interface for tools that don't ship with
+// nexbot (plugins): the tool is addressed as Namespace().Name() besides its
+// name, and is disabled with its namespace.
+type NamespacedTool interface {
+	Tool
+
+	// Namespace returns the namespace of the tool, e.g. "net".
+	Namespace() string
+}
+
+// ValidNamespace reports whether name is a valid namespace name:
+// lower case letters, digits and underscores, starting with a letter.
+func ValidNamespace(name string) bool {
+	return namespacePattern.MatchString(name)
+}
+
+// QualifiedName returns the qualified name of a tool, or "" if the tool has
+// no namespace.
+func QualifiedName(tool Tool) string {
+	if namespaced, ok := tool.(NamespacedTool); ok && namespaced.Namespace() != "" {
+		return namespaced.Namespace() + "." + tool.Name()
+	}
+	return builtinQualified[tool.Name()]
+}
+
+// qualifiedByName returns the qualified name of a built-in tool name, or ""
+func qualifiedByName(name string) string {
+	return builtinQualified[name]
+}
+
+// namespaceOf returns the namespace part of a qualified name.
+func namespaceOf(qualified string) string {
+	namespace, _, _ := strings.Cut(qualified, ".")
+	return namespace
+}
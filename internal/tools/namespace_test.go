@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// pluginTool is a mock tool declaring its own namespace
+type pluginTool struct {
+	mockTool
+	namespace string
+}
+
+func (p *pluginTool) Namespace() string {
+	return p.namespace
+}
+
+func TestRegistry_QualifiedNames(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register(&mockTool{name: "write_file"}); err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+
+	for _, name := range []string{"write_file", "fs.write"} {
+		tool, ok := registry.Get(name)
+		if !ok || tool.Name() != "write_file" {
+			t.Errorf("Get(%q) = %v, %v, want write_file", name, tool, ok)
+		}
+	}
+	if got := registry.Resolve("fs.write"); got != "write_file" {
+		t.Errorf("Resolve(fs.write) = %q, want write_file", got)
+	}
+
+	// The LLM sees the plain names
+	if schemas := registry.ToSchema(); len(schemas) != 1 || schemas[0].Name != "write_file" {
+		t.Errorf("ToSchema() = %+v, want write_file only", schemas)
+	}
+}
+
+func TestRegistry_DisableNamespace(t *testing.T) {
+	registry := NewRegistry()
+	registry.DisableNamespace(NamespaceSys)
+
+	err := registry.Register(&mockTool{name: "shell_exec"})
+	if !errors.Is(err, ErrNamespaceDisabled) {
+		t.Errorf("Register(shell_exec) error = %v, want ErrNamespaceDisabled", err)
+	}
+	if err := registry.Register(&pluginTool{mockTool: mockTool{name: "ps"}, namespace: NamespaceSys}); !errors.Is(err, ErrNamespaceDisabled) {
+		t.Errorf("Register(sys plugin) error = %v, want ErrNamespaceDisabled", err)
+	}
+	if err := registry.Register(&mockTool{name: "read_file"}); err != nil {
+		t.Errorf("Register(read_file) error: %v", err)
+	}
+	if _, ok := registry.Get("shell_exec"); ok {
+		t.Error("tool of a disabled namespace should not be registered")
+	}
+}
+
+func TestRegistry_RegisterPlugin(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register(&mockTool{name: "web_fetch"}); err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+
+	plugin := &pluginTool{mockTool: mockTool{name: "search"}, namespace: NamespaceNet}
+	if err := registry.RegisterPlugin(plugin); err != nil {
+		t.Fatalf("RegisterPlugin() error: %v", err)
+	}
+	if tool, ok := registry.Get("net.search"); !ok || tool != plugin {
+		t.Errorf("Get(net.search) = %v, %v, want the plugin", tool, ok)
+	}
+
+	tests := []struct {
+		name string
+		tool Tool
+		want string
+	}{
+		{"same name", &mockTool{name: "web_fetch"}, "web_fetch is already registered"},
+		{"name taken by alias", &mockTool{name: "net.fetch"}, "net.fetch is already an alias of web_fetch"},
+		{"qualified name taken", &pluginTool{mockTool: mockTool{name: "fetch"}, namespace: NamespaceNet}, "net.fetch is already an alias"},
+		{"invalid namespace", &pluginTool{mockTool: mockTool{name: "x"}, namespace: "Net"}, "invalid namespace"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.RegisterPlugin(tt.tool)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("RegisterPlugin() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	// Built-in registration keeps replacing tools
+	if err := registry.Register(&mockTool{name: "web_fetch", description: "new"}); err != nil {
+		t.Errorf("Register() of a built-in tool should replace it, got %v", err)
+	}
+}
+
+func TestRegistry_Alias(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register(&mockTool{name: "web_fetch"}); err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+
+	if err := registry.Alias("fetch_url", "web_fetch"); err != nil {
+		t.Fatalf("Alias() error: %v", err)
+	}
+	if _, ok := registry.Get("fetch_url"); !ok {
+		t.Error("Get() should resolve the alias")
+	}
+	if err := registry.Alias("fetch_url", "web_fetch"); err == nil {
+		t.Error("Alias() should reject a taken alias")
+	}
+	if err := registry.Alias("x", "missing"); err == nil {
+		t.Error("Alias() should reject an unknown tool")
+	}
+}
+
+func TestCallBudget_QualifiedName(t *testing.T) {
+	budget := NewCallBudget(map[string]int{"fs.write": 1})
+	if !budget.Take("write_file") || budget.Take("write_file") {
+		t.Error("budget keyed by qualified name should limit the plain tool name")
+	}
+}
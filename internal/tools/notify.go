@@ -0,0 +1,272 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/agent"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/users"
+)
+
+// UserRegistry resolves users and their channel identities (implemented by users.Registry).
+type UserRegistry interface {
+	Get(userID string) (users.User, bool)
+	FindBySession(sessionID string) (users.User, bool)
+	List() []users.User
+	Link(userID string, identity users.Identity) (users.User, error)
+	Unlink(userID string, identity users.Identity) error
+	Targets(userID string, channels []string) ([]users.Identity, error)
+}
+
+// NotifyTool implements the Tool interface for delivering
+// notifications to a user across channels using the user registry.
+type NotifyTool struct {
+	registry  UserRegistry
+	sender    agent.MessageSender
+	channels  []string // Channels with a running connector
+	templates MessageTemplates
+	logger    *logger.Logger
+}
+
+// NotifyArgs represents the arguments for the notify tool.
+type NotifyArgs struct {
+	Action   string         `json:"action"`   // Action: "send", "link", "unlink", "list"
+	User     string         `json:"user"`     // User ID (defaults to the owner of the current session)
+	Channels []string       `json:"channels"` // Channels to deliver to
+	Message  string         `json:"message"`  // Notification text
+	Identity string         `json:"identity"` // Identity for link/unlink ("channel:address")
+	Template string         `json:"template"` // Message template to render instead of message
+	Data     map[string]any `json:"data"`     // Template fields
+}
+
+// NewNotifyTool creates a new NotifyTool instance.
+// channels lists the channels that can actually deliver messages.
+func NewNotifyTool(registry UserRegistry, sender agent.MessageSender, channels []string, logger *logger.Logger) *NotifyTool {
+	return &NotifyTool{
+		registry: registry,
+		sender:   sender,
+		channels: channels,
+		logger:   logger,
+	}
+}
+
+// SetTemplates enables sending notifications by template name and data
+// instead of free-form text.
+func (t *NotifyTool) SetTemplates(templates MessageTemplates) {
+	t.templates = templates
+}
+
+// Name returns the tool name.
+func (t *NotifyTool) Name() string {
+	return "notify"
+}
+
+// Description returns a description of what the tool does.
+func (t *NotifyTool) Description() string {
+	return "Delivers notifications to a user on one or more of their linked channels (telegram, email, discord, ...) and manages linked identities. Use 'link' when the user tells you an address on another channel, then 'send' with the requested channels."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *NotifyTool) Parameters() map[string]any {
+	params := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"description": "Action to perform: 'send' to deliver a notification, 'link' to add an identity to the user, 'unlink' to remove one, 'list' to show known users and their identities.",
+				"enum":        []string{"send", "link", "unlink", "list"},
+			},
+			"user": map[string]any{
+				"type":        "string",
+				"description": "User ID. Defaults to the user of the current conversation.",
+			},
+			"channels": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Channels to deliver to (e.g. ['telegram', 'email']). If empty, the user's default channels are used.",
+			},
+			"message": map[string]any{
+				"type":        "string",
+				"description": "Notification text. Required for 'send' action.",
+			},
+			"identity": map[string]any{
+				"type":        "string",
+				"description": "Identity in 'channel:address' format (e.g. 'email:alice@example.com'). Required for 'link' and 'unlink' actions.",
+			},
+		},
+		"required": []string{"action"},
+	}
+	if t.templates != nil {
+		maps.Copy(params["properties"].(map[string]any), templateProperties(t.templates))
+	}
+	return params
+}
+
+// Execute executes the notify tool.
+// The current session from the context identifies the default user.
+func (t *NotifyTool) Execute(ctx context.Context, args string) (string, error) {
+	var params NotifyArgs
+	if err := parseJSON(args, &params); err != nil {
+		return "", fmt.Errorf("failed to parse notify arguments: %w", err)
+	}
+
+	sessionID := getSessionID(ctx)
+
+	switch params.Action {
+	case "send":
+		return t.send(sessionID, params)
+	case "link":
+		return t.link(sessionID, params)
+	case "unlink":
+		return t.unlink(sessionID, params)
+	case "list":
+		return t.list(), nil
+	default:
+		return "", fmt.Errorf("invalid action: %s. Valid actions: send, link, unlink, list", params.Action)
+	}
+}
+
+// resolveUser returns the requested user ID or the owner of the current session.
+func (t *NotifyTool) resolveUser(sessionID, userID string) (string, error) {
+	if userID != "" {
+		return userID, nil
+	}
+	if u, ok := t.registry.FindBySession(sessionID); ok {
+		return u.ID, nil
+	}
+	return "", fmt.Errorf("user parameter is required: the current session is not linked to a known user")
+}
+
+// send delivers a notification to the user's identities.
+func (t *NotifyTool) send(sessionID string, params NotifyArgs) (string, error) {
+	if params.Template != "" {
+		rendered, err := renderTemplate(t.templates, params.Template, params.Data)
+		if err != nil {
+			return "", err
+		}
+		params.Message = rendered.Text
+	}
+	if params.Message == "" {
+		return "", fmt.Errorf("message parameter is required for send action")
+	}
+	userID, err := t.resolveUser(sessionID, params.User)
+	if err != nil {
+		return "", err
+	}
+
+	targets, err := t.registry.Targets(userID, params.Channels)
+	if err != nil {
+		return "", err
+	}
+	if len(targets) == 0 {
+		return "", fmt.Errorf("user %s has no linked identities", userID)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Notification for %s:\n", userID)
+	for _, target := range targets {
+		if !slices.Contains(t.channels, target.Channel) {
+			fmt.Fprintf(&b, "- %s: skipped (channel %s is not connected)\n", target, target.Channel)
+			continue
+		}
+		if err := t.sender.SendMessageAsync("", target.Channel, target.String(), params.Message); err != nil {
+			fmt.Fprintf(&b, "- %s: failed (%v)\n", target, err)
+			continue
+		}
+		fmt.Fprintf(&b, "- %s: sent\n", target)
+	}
+
+	if t.logger != nil {
+		t.logger.Info("notification fan-out",
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "targets", Value: len(targets)})
+	}
+	return b.String(), nil
+}
+
+// link adds an identity to the user. If the current session has no user yet,
+// a new user is created and the session identity is linked as well.
+func (t *NotifyTool) link(sessionID string, params NotifyArgs) (string, error) {
+	identity, err := users.ParseIdentity(params.Identity)
+	if err != nil {
+		return "", err
+	}
+
+	userID := params.User
+	if userID == "" {
+		if u, ok := t.registry.FindBySession(sessionID); ok {
+			userID = u.ID
+		} else if sessionIdentity, err := users.ParseIdentity(sessionID); err == nil {
+			userID = sessionID
+			if _, err := t.registry.Link(userID, sessionIdentity); err != nil {
+				return "", fmt.Errorf("failed to link session: %w", err)
+			}
+		} else {
+			return "", fmt.Errorf("user parameter is required for link action")
+		}
+	}
+
+	u, err := t.registry.Link(userID, identity)
+	if err != nil {
+		return "", fmt.Errorf("failed to link identity: %w", err)
+	}
+	return fmt.Sprintf("Linked %s to user %s.\n%s", identity, u.ID, formatUser(u)), nil
+}
+
+// unlink removes an identity from the user.
+func (t *NotifyTool) unlink(sessionID string, params NotifyArgs) (string, error) {
+	identity, err := users.ParseIdentity(params.Identity)
+	if err != nil {
+		return "", err
+	}
+	userID, err := t.resolveUser(sessionID, params.User)
+	if err != nil {
+		return "", err
+	}
+	if err := t.registry.Unlink(userID, identity); err != nil {
+		return "", fmt.Errorf("failed to unlink identity: %w", err)
+	}
+	return fmt.Sprintf("Unlinked %s from user %s", identity, userID), nil
+}
+
+// list formats all known users.
+func (t *NotifyTool) list() string {
+	list := t.registry.List()
+	if len(list) == 0 {
+		return "No users registered."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Users (%d), connected channels: %s\n", len(list), strings.Join(t.channels, ", "))
+	for _, u := range list {
+		b.WriteString("\n")
+		b.WriteString(formatUser(u))
+	}
+	return b.String()
+}
+
+// formatUser formats a user with identities.
+func formatUser(u users.User) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "- ID: %s\n", u.ID)
+	if u.Name != "" {
+		fmt.Fprintf(&b, "  Name: %s\n", u.Name)
+	}
+	for _, id := range u.Identities {
+		fmt.Fprintf(&b, "  %s\n", id)
+	}
+	if len(u.DefaultChannels) > 0 {
+		fmt.Fprintf(&b, "  Default channels: %s\n", strings.Join(u.DefaultChannels, ", "))
+	}
+	if u.Timezone != "" {
+		fmt.Fprintf(&b, "  Timezone: %s\n", u.Timezone)
+	}
+	return b.String()
+}
+
+// Ensure NotifyTool implements Tool interface
+var _ Tool = (*NotifyTool)(nil)
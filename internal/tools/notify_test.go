@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/users"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupNotifyTool(t *testing.T, static []users.User) (*NotifyTool, *[]string) {
+	t.Helper()
+	registry := users.NewRegistry(t.TempDir())
+	require.NoError(t, registry.Load(static))
+
+	var sent []string
+	sender := &mockMessageSender{
+		sendAsyncFunc: func(userID, channelType, sessionID, message string) error {
+			sent = append(sent, sessionID)
+			return nil
+		},
+	}
+	return NewNotifyTool(registry, sender, []string{"telegram"}, nil), &sent
+}
+
+func TestNotifyTool_SendFanOut(t *testing.T) {
+	tool, sent := setupNotifyTool(t, []users.User{{
+		ID: "alice",
+		Identities: []users.Identity{
+			{Channel: "telegram", Address: "123"},
+			{Channel: "email", Address: "alice@example.com"},
+		},
+	}})
+	ctx := context.WithValue(context.Background(), sessionIDKey, "telegram:123")
+
+	result, err := tool.Execute(ctx, `{"action": "send", "message": "done"}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"telegram:123"}, *sent)
+	assert.Contains(t, result, "telegram:123: sent")
+	assert.Contains(t, result, "email:alice@example.com: skipped")
+}
+
+func TestNotifyTool_SendSelectedChannel(t *testing.T) {
+	tool, sent := setupNotifyTool(t, []users.User{{
+		ID: "alice",
+		Identities: []users.Identity{
+			{Channel: "telegram", Address: "123"},
+			{Channel: "telegram-alt", Address: "456"},
+		},
+	}})
+
+	_, err := tool.Execute(context.Background(), `{"action": "send", "user": "alice", "channels": ["telegram"], "message": "hi"}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"telegram:123"}, *sent)
+
+	_, err = tool.Execute(context.Background(), `{"action": "send", "user": "alice", "channels": ["discord"], "message": "hi"}`)
+	assert.Error(t, err)
+}
+
+func TestNotifyTool_LinkCreatesUserFromSession(t *testing.T) {
+	tool, _ := setupNotifyTool(t, nil)
+	ctx := context.WithValue(context.Background(), sessionIDKey, "telegram:42")
+
+	_, err := tool.Execute(ctx, `{"action": "link", "identity": "email:bob@example.com"}`)
+	require.NoError(t, err)
+
+	u, ok := tool.registry.FindBySession("telegram:42")
+	require.True(t, ok)
+	_, hasEmail := u.Identity("email")
+	assert.True(t, hasEmail)
+
+	result, err := tool.Execute(context.Background(), `{"action": "list"}`)
+	require.NoError(t, err)
+	assert.Contains(t, result, "email:bob@example.com")
+}
+
+func TestNotifyTool_UnknownUser(t *testing.T) {
+	tool, _ := setupNotifyTool(t, nil)
+
+	_, err := tool.Execute(context.Background(), `{"action": "send", "message": "hi"}`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,216 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent"
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+// ownMessageTimeout bounds waiting for the channel to edit or delete a message
+const ownMessageTimeout = 10 * time.Second
+
+// OwnMessageArgs represents the arguments of the edit_message and
+// delete_message tools.
+type OwnMessageArgs struct {
+	MessageID string `json:"message_id"` // Message to change (edit: defaults to the latest answer)
+	Message   string `json:"message"`    // New text (edit_message)
+	Format    string `json:"format"`     // plain, markdown, html, markdownv2 (edit_message)
+}
+
+// EditMessageTool edits a message the agent sent earlier to the chat of the
+// conversation. Only messages with a delivery receipt in the session can be
+// edited, so the agent can't touch messages of the user or of other chats.
+type EditMessageTool struct {
+	sender   agent.MessageSender
+	receipts ReceiptReader
+	logger   *logger.Logger
+}
+
+// NewEditMessageTool creates a new EditMessageTool instance.
+func NewEditMessageTool(sender agent.MessageSender, receipts ReceiptReader, logger *logger.Logger) *EditMessageTool {
+	return &EditMessageTool{sender: sender, receipts: receipts, logger: logger}
+}
+
+// Name returns the tool name.
+func (t *EditMessageTool) Name() string {
+	return "edit_message"
+}
+
+// Description returns a description of what the tool does.
+func (t *EditMessageTool) Description() string {
+	return "Replaces the text of a message you sent earlier in this chat. Use it to correct a previous answer instead of sending a separate \"correction:\" message. Without message_id your latest text message is edited; IDs of earlier messages are returned by send_message and get_send_status."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *EditMessageTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"message_id": map[string]any{
+				"type":        "string",
+				"description": "ID of your message to edit. Defaults to your latest text message in this chat.",
+			},
+			"message": map[string]any{
+				"type":        "string",
+				"description": "The full corrected text of the message. Examples: {\"message\": \"The meeting is at 15:00, not 14:00.\"}",
+			},
+			"format": map[string]any{
+				"type":        "string",
+				"description": "Message format: 'plain' (default), 'markdown', 'html', 'markdownv2'.",
+				"enum":        []string{"plain", "markdown", "html", "markdownv2"},
+			},
+		},
+		"required": []string{"message"},
+	}
+}
+
+// Execute executes the edit_message tool.
+func (t *EditMessageTool) Execute(ctx context.Context, args string) (string, error) {
+	var params OwnMessageArgs
+	if err := parseJSON(args, &params); err != nil {
+		return "", fmt.Errorf("failed to parse edit_message arguments: %w", err)
+	}
+	if strings.TrimSpace(params.Message) == "" {
+		return "", fmt.Errorf("message parameter is required")
+	}
+
+	return runOwnMessage(ctx, t.logger, t.receipts, t.Name(), params.MessageID, true, func(userID, channelType, sessionID, messageID string) (*agent.MessageResult, error) {
+		return t.sender.SendEditMessage(userID, channelType, sessionID, messageID, params.Message, nil, bus.FormatType(params.Format), ownMessageTimeout)
+	})
+}
+
+// DeleteMessageTool deletes a message the agent sent earlier to the chat of
+// the conversation. Like EditMessageTool, it only accepts the agent's own
+// messages.
+type DeleteMessageTool struct {
+	sender   agent.MessageSender
+	receipts ReceiptReader
+	logger   *logger.Logger
+}
+
+// NewDeleteMessageTool creates a new DeleteMessageTool instance.
+func NewDeleteMessageTool(sender agent.MessageSender, receipts ReceiptReader, logger *logger.Logger) *DeleteMessageTool {
+	return &DeleteMessageTool{sender: sender, receipts: receipts, logger: logger}
+}
+
+// Name returns the tool name.
+func (t *DeleteMessageTool) Name() string {
+	return "delete_message"
+}
+
+// Description returns a description of what the tool does.
+func (t *DeleteMessageTool) Description() string {
+	return "Deletes a message you sent earlier in this chat, e.g. an answer that turned out to be wrong or a stale notification. IDs of your messages are returned by send_message and get_send_status."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *DeleteMessageTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"message_id": map[string]any{
+				"type":        "string",
+				"description": "ID of your message to delete. Examples: {\"message_id\": \"1234\"}",
+			},
+		},
+		"required": []string{"message_id"},
+	}
+}
+
+// Execute executes the delete_message tool.
+func (t *DeleteMessageTool) Execute(ctx context.Context, args string) (string, error) {
+	var params OwnMessageArgs
+	if err := parseJSON(args, &params); err != nil {
+		return "", fmt.Errorf("failed to parse delete_message arguments: %w", err)
+	}
+	if params.MessageID == "" {
+		return "", fmt.Errorf("message_id parameter is required")
+	}
+
+	return runOwnMessage(ctx, t.logger, t.receipts, t.Name(), params.MessageID, false, func(userID, channelType, sessionID, messageID string) (*agent.MessageResult, error) {
+		return t.sender.SendDeleteMessage(userID, channelType, sessionID, messageID, ownMessageTimeout)
+	})
+}
+
+// runOwnMessage checks that a message belongs to the agent in the current
+// conversation and applies the change.
+func runOwnMessage(ctx context.Context, log *logger.Logger, receipts ReceiptReader, tool, messageID string, text bool, apply func(userID, channelType, sessionID, messageID string) (*agent.MessageResult, error)) (string, error) {
+	sessionID := getSessionID(ctx)
+	channelType, chatID, ok := strings.Cut(sessionID, ":")
+	if !ok || channelType == "" || chatID == "" {
+		return "", fmt.Errorf("%s is only available within a chat conversation", tool)
+	}
+
+	list, err := receipts.Receipts(sessionID)
+	if err != nil {
+		return "", err
+	}
+	messageID, err = ownMessage(list, messageID, text)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := apply(chatID, channelType, sessionID, messageID)
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w", tool, err)
+	}
+	if !result.Success {
+		if result.Error != nil {
+			return "", fmt.Errorf("❌ %s failed\n\n%s", tool, result.Error.ToLLMContext())
+		}
+		return "", fmt.Errorf("❌ %s failed (no error details available)", tool)
+	}
+
+	log.Info("own message tool executed",
+		logger.Field{Key: "tool", Value: tool},
+		logger.Field{Key: "session_id", Value: sessionID},
+		logger.Field{Key: "message_id", Value: messageID})
+	return fmt.Sprintf("✅ %s done\n   Message ID: %s", tool, messageID), nil
+}
+
+// ownMessage finds a message the agent sent in a conversation by its delivery
+// receipts. An empty ID selects the latest message; text limits the choice to
+// text messages, which are the only ones that can be edited. Receipts of pins
+// don't count: a pinned message may be the user's.
+func ownMessage(receipts []session.Receipt, messageID string, text bool) (string, error) {
+	deleted := make(map[string]bool)
+	for i := len(receipts) - 1; i >= 0; i-- {
+		r := receipts[i]
+		if !r.Success || r.MessageID == "" || (messageID != "" && r.MessageID != messageID) {
+			continue
+		}
+		switch bus.MessageType(r.Type) {
+		case bus.MessageTypeDelete:
+			deleted[r.MessageID] = true
+			continue
+		case bus.MessageTypeText, bus.MessageTypeEdit:
+		case bus.MessageTypePhoto, bus.MessageTypeDocument, bus.MessageTypeSticker, bus.MessageTypeAnimation:
+			if text {
+				if messageID == "" {
+					continue
+				}
+				return "", fmt.Errorf("message %s is a %s message, only text messages can be edited", r.MessageID, r.Type)
+			}
+		default:
+			continue
+		}
+		if deleted[r.MessageID] {
+			if messageID == "" {
+				continue
+			}
+			return "", fmt.Errorf("message %s was deleted", r.MessageID)
+		}
+		return r.MessageID, nil
+	}
+	if messageID == "" {
+		return "", errors.New("no message of yours to edit was found in this chat")
+	}
+	return "", fmt.Errorf("message %s is not one of your recent messages in this chat (only the latest %d are known)", messageID, session.MaxReceipts)
+}
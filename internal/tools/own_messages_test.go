@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent"
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReceipts returns fixed delivery receipts.
+type fakeReceipts []session.Receipt
+
+func (f fakeReceipts) Receipts(sessionID string) ([]session.Receipt, error) {
+	return f, nil
+}
+
+// recordingSender records the messages it edits and deletes.
+type recordingSender struct {
+	mockMessageSender
+	calls []string
+}
+
+func (s *recordingSender) SendEditMessage(userID, channelType, sessionID, messageID, content string, keyboard *bus.InlineKeyboard, format bus.FormatType, timeout time.Duration) (*agent.MessageResult, error) {
+	s.calls = append(s.calls, "edit "+sessionID+" "+messageID+" "+content)
+	return &agent.MessageResult{Success: true}, nil
+}
+
+func (s *recordingSender) SendDeleteMessage(userID, channelType, sessionID, messageID string, timeout time.Duration) (*agent.MessageResult, error) {
+	s.calls = append(s.calls, "delete "+sessionID+" "+messageID)
+	return &agent.MessageResult{Success: true}, nil
+}
+
+func TestOwnMessageTools(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	require.NoError(t, err)
+	receipts := fakeReceipts{
+		{MessageID: "10", Type: "text", Success: true},
+		{MessageID: "11", Type: "text", Success: true},
+		{MessageID: "12", Type: "photo", Success: true},
+		{MessageID: "13", Type: "text"},              // Not delivered
+		{MessageID: "5", Type: "pin", Success: true}, // The user's message pinned by the bot
+		{MessageID: "11", Type: "delete", Success: true},
+	}
+	sender := &recordingSender{}
+	edit := NewEditMessageTool(sender, receipts, log)
+	del := NewDeleteMessageTool(sender, receipts, log)
+	ctx := context.WithValue(context.Background(), sessionIDKey, "telegram:42")
+
+	// The latest text message that still exists is edited by default
+	result, err := edit.Execute(ctx, `{"message": "Fixed answer"}`)
+	require.NoError(t, err)
+	assert.Contains(t, result, "Message ID: 10")
+
+	_, err = del.Execute(ctx, `{"message_id": "12"}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"edit telegram:42 10 Fixed answer",
+		"delete telegram:42 12",
+	}, sender.calls)
+
+	for _, tc := range []struct {
+		name string
+		tool Tool
+		args string
+	}{
+		{"deleted message", edit, `{"message_id": "11", "message": "x"}`},
+		{"photo edit", edit, `{"message_id": "12", "message": "x"}`},
+		{"failed send", del, `{"message_id": "13"}`},
+		{"pinned user message", del, `{"message_id": "5"}`},
+		{"unknown message", del, `{"message_id": "99"}`},
+		{"missing text", edit, `{"message_id": "10"}`},
+		{"missing id", del, `{}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.tool.Execute(ctx, tc.args)
+			assert.Error(t, err)
+		})
+	}
+	assert.Len(t, sender.calls, 2)
+
+	_, err = edit.Execute(context.Background(), `{"message": "x"}`)
+	assert.Error(t, err, "tools need the current conversation")
+}
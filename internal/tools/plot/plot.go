@@ -0,0 +1,469 @@
+// Package plot provides the plot tool, which renders tabular JSON data into
+// PNG line, bar and pie charts. Charts are saved in the workspace and
+// delivered to the user as photos with the answer.
+package plot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/agent/transcript"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/tools"
+	"github.com/wcharczuk/go-chart/v2"
+)
+
+const (
+	// Chart types
+	TypeLine = "line"
+	TypeBar  = "bar"
+	TypePie  = "pie"
+
+	// maxRows limits the number of data rows of a chart
+	maxRows = 1000
+
+	// maxTimeTicks is the number of points up to which each time gets a tick
+	maxTimeTicks = 12
+
+	// Chart size in pixels
+	chartWidth  = 1024
+	chartHeight = 600
+)
+
+// dateLayouts are the layouts x values are parsed with for time axes.
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// renderer is a chart that renders itself (chart.Chart, chart.BarChart, chart.PieChart).
+type renderer interface {
+	Render(rp chart.RendererProvider, w io.Writer) error
+}
+
+// PlotTool implements the Tool interface for rendering charts.
+type PlotTool struct {
+	dir    string
+	logger *logger.Logger
+}
+
+// PlotArgs represents the arguments for the plot tool.
+type PlotArgs struct {
+	Type     string           `json:"type"`     // Chart type: "line", "bar" or "pie"
+	Title    string           `json:"title"`    // Chart title
+	Data     []map[string]any `json:"data"`     // Data rows
+	X        string           `json:"x"`        // Column with x values or labels
+	Y        []string         `json:"y"`        // Columns with values (default: all numeric columns)
+	XLabel   string           `json:"x_label"`  // X axis name (line charts)
+	YLabel   string           `json:"y_label"`  // Y axis name (line and bar charts)
+	Filename string           `json:"filename"` // PNG file name (default: from the title)
+	Caption  string           `json:"caption"`  // Caption shown with the chart
+}
+
+// NewPlotTool creates a new PlotTool saving charts in dir.
+func NewPlotTool(dir string, log *logger.Logger) *PlotTool {
+	return &PlotTool{
+		dir:    dir,
+		logger: log,
+	}
+}
+
+// Name returns the tool name.
+func (t *PlotTool) Name() string {
+	return "plot"
+}
+
+// ConfigSection returns the configuration table of the tool ([tools.plot]).
+func (t *PlotTool) ConfigSection() string {
+	return "plot"
+}
+
+// ConfigSchema returns the keys of [tools.plot].
+func (t *PlotTool) ConfigSchema() []tools.ConfigField {
+	return []tools.ConfigField{
+		{Key: "enabled", Type: tools.ConfigBool},
+		{Key: "dir", Type: tools.ConfigString},
+	}
+}
+
+// Description returns a description of what the tool does.
+func (t *PlotTool) Description() string {
+	return "Renders tabular data into a PNG chart (line, bar or pie) and sends it to the user as a photo with the answer. Pass rows as JSON objects and name the x column and value columns."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *PlotTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"type": map[string]any{
+				"type":        "string",
+				"description": "Chart type: 'line' for trends (one line per y column; dates and numbers on x are scaled), 'bar' to compare values, 'pie' for shares of a whole. Bar and pie charts take one y column.",
+				"enum":        []string{TypeLine, TypeBar, TypePie},
+			},
+			"title": map[string]any{
+				"type":        "string",
+				"description": "Chart title.",
+			},
+			"data": map[string]any{
+				"type":        "array",
+				"description": "Data rows as objects. Example: [{\"day\": \"2026-10-12\", \"prompt\": 1200, \"completion\": 300}, {\"day\": \"2026-10-13\", \"prompt\": 900, \"completion\": 250}]",
+				"items":       map[string]any{"type": "object"},
+			},
+			"x": map[string]any{
+				"type":        "string",
+				"description": "Column with x values (line) or labels (bar, pie). Example: \"day\"",
+			},
+			"y": map[string]any{
+				"type":        "array",
+				"description": "Columns with numeric values. Defaults to all numeric columns except x. Example: [\"prompt\", \"completion\"]",
+				"items":       map[string]any{"type": "string"},
+			},
+			"x_label": map[string]any{
+				"type":        "string",
+				"description": "Optional x axis name (line charts).",
+			},
+			"y_label": map[string]any{
+				"type":        "string",
+				"description": "Optional y axis name (line and bar charts).",
+			},
+			"filename": map[string]any{
+				"type":        "string",
+				"description": "Optional PNG file name. Defaults to a name derived from the title.",
+			},
+			"caption": map[string]any{
+				"type":        "string",
+				"description": "Optional caption shown with the chart.",
+			},
+		},
+		"required": []string{"type", "data", "x"},
+	}
+}
+
+// Execute executes the plot tool.
+func (t *PlotTool) Execute(ctx context.Context, args string) (string, error) {
+	result, err := t.ExecuteResult(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	return result.Content, nil
+}
+
+// ExecuteResult renders the chart and returns it as an artifact.
+func (t *PlotTool) ExecuteResult(ctx context.Context, args string) (*tools.Result, error) {
+	var params PlotArgs
+	if err := json.Unmarshal([]byte(args), &params); err != nil {
+		return nil, fmt.Errorf("failed to parse plot arguments: %w", err)
+	}
+
+	if len(params.Data) == 0 {
+		return nil, fmt.Errorf("data parameter is required")
+	}
+	if len(params.Data) > maxRows {
+		return nil, fmt.Errorf("too many data rows: %d (max %d)", len(params.Data), maxRows)
+	}
+	if params.X == "" {
+		return nil, fmt.Errorf("x parameter is required")
+	}
+
+	columns := params.Y
+	if len(columns) == 0 {
+		columns = numericColumns(params.Data, params.X)
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("no numeric columns to plot besides %q", params.X)
+		}
+	}
+
+	var renderable renderer
+	var err error
+	switch params.Type {
+	case TypeLine:
+		renderable, err = lineChart(params, columns)
+	case TypeBar, TypePie:
+		if len(columns) > 1 {
+			return nil, fmt.Errorf("%s charts take one y column (got: %s)", params.Type, strings.Join(columns, ", "))
+		}
+		renderable, err = valueChart(params, columns[0])
+	default:
+		return nil, fmt.Errorf("invalid chart type: %s. Valid types: line, bar, pie", params.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := renderable.Render(chart.PNG, &buf); err != nil {
+		return nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+
+	path := filepath.Join(t.dir, chartFilename(params))
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create charts directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write chart: %w", err)
+	}
+
+	t.logger.DebugCtx(ctx, "Chart rendered",
+		logger.Field{Key: "type", Value: params.Type},
+		logger.Field{Key: "rows", Value: len(params.Data)},
+		logger.Field{Key: "path", Value: path})
+
+	caption := params.Caption
+	if caption == "" {
+		caption = params.Title
+	}
+	return &tools.Result{
+		Content:   fmt.Sprintf("Chart saved to %s (%d rows, %s) and will be sent to the user with the answer.", path, len(params.Data), strings.Join(columns, ", ")),
+		Artifacts: []tools.Artifact{tools.NewArtifact(path, caption)},
+	}, nil
+}
+
+// lineChart builds a line chart with one series per column. Numeric and date
+// x values are placed on a scaled axis, other values are spaced evenly and
+// shown as labels.
+func lineChart(params PlotArgs, columns []string) (renderer, error) {
+	if len(params.Data) < 2 {
+		return nil, fmt.Errorf("line charts need at least 2 data rows")
+	}
+
+	xs, times, labels := xValues(params.Data, params.X)
+
+	graph := &chart.Chart{
+		Title:  params.Title,
+		Width:  chartWidth,
+		Height: chartHeight,
+		Background: chart.Style{
+			Padding: chart.Box{Top: 50, Left: 20, Right: 20, Bottom: 20},
+		},
+		XAxis: chart.XAxis{Name: params.XLabel},
+		YAxis: chart.YAxis{Name: params.YLabel},
+	}
+	if times != nil {
+		formatter := timeFormatter(times)
+		graph.XAxis.ValueFormatter = formatter
+		if len(times) <= maxTimeTicks {
+			// Few points: tick each of them instead of evenly spaced times
+			for _, t := range times {
+				graph.XAxis.Ticks = append(graph.XAxis.Ticks, chart.Tick{Value: chart.TimeToFloat64(t), Label: formatter(t)})
+			}
+		}
+	}
+	if labels != nil {
+		for i, label := range labels {
+			graph.XAxis.Ticks = append(graph.XAxis.Ticks, chart.Tick{Value: float64(i), Label: label})
+		}
+	}
+
+	for _, column := range columns {
+		ys, err := columnValues(params.Data, column)
+		if err != nil {
+			return nil, err
+		}
+		if times != nil {
+			graph.Series = append(graph.Series, chart.TimeSeries{Name: column, XValues: times, YValues: ys})
+		} else {
+			graph.Series = append(graph.Series, chart.ContinuousSeries{Name: column, XValues: xs, YValues: ys})
+		}
+	}
+	if len(columns) > 1 {
+		graph.Elements = []chart.Renderable{chart.Legend(graph)}
+	}
+	return graph, nil
+}
+
+// valueChart builds a bar or pie chart of one column labelled by x.
+func valueChart(params PlotArgs, column string) (renderer, error) {
+	ys, err := columnValues(params.Data, column)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]chart.Value, len(ys))
+	for i, row := range params.Data {
+		values[i] = chart.Value{Label: label(row[params.X]), Value: ys[i]}
+	}
+
+	if params.Type == TypePie {
+		for _, v := range values {
+			if v.Value < 0 {
+				return nil, fmt.Errorf("pie charts need non-negative values (%s: %g)", v.Label, v.Value)
+			}
+		}
+		if !slices.ContainsFunc(values, func(v chart.Value) bool { return v.Value > 0 }) {
+			return nil, fmt.Errorf("pie charts need at least one positive value")
+		}
+		return &chart.PieChart{
+			Title:  params.Title,
+			Width:  chartWidth,
+			Height: chartHeight,
+			Values: values,
+		}, nil
+	}
+
+	if !slices.ContainsFunc(values, func(v chart.Value) bool { return v.Value != 0 }) {
+		return nil, fmt.Errorf("bar charts need at least one non-zero value")
+	}
+	// Bars start at zero, so their heights compare the values
+	yRange := &chart.ContinuousRange{Min: min(0, slices.Min(ys)), Max: max(0, slices.Max(ys))}
+	return &chart.BarChart{
+		Title:  params.Title,
+		Width:  chartWidth,
+		Height: chartHeight,
+		Background: chart.Style{
+			Padding: chart.Box{Top: 50},
+		},
+		BarWidth: max(10, min(120, (chartWidth-150)*2/3/len(values))),
+		YAxis:    chart.YAxis{Name: params.YLabel, Range: yRange},
+		Bars:     values,
+	}, nil
+}
+
+// xValues returns the x values of rows: numbers if all values are numbers,
+// otherwise times if all values are dates, otherwise evenly spaced positions
+// with their labels.
+func xValues(rows []map[string]any, column string) (xs []float64, times []time.Time, labels []string) {
+	xs = make([]float64, len(rows))
+	numeric := true
+	for i, row := range rows {
+		v, ok := number(row[column])
+		if !ok {
+			numeric = false
+			break
+		}
+		xs[i] = v
+	}
+	if numeric {
+		return xs, nil, nil
+	}
+
+	times = make([]time.Time, len(rows))
+	for i, row := range rows {
+		t, ok := parseTime(row[column])
+		if !ok {
+			times = nil
+			break
+		}
+		times[i] = t
+	}
+	if times != nil {
+		return nil, times, nil
+	}
+
+	labels = make([]string, len(rows))
+	for i, row := range rows {
+		xs[i] = float64(i)
+		labels[i] = label(row[column])
+	}
+	return xs, nil, labels
+}
+
+// columnValues returns the numeric values of a column.
+func columnValues(rows []map[string]any, column string) ([]float64, error) {
+	values := make([]float64, len(rows))
+	for i, row := range rows {
+		raw, ok := row[column]
+		if !ok {
+			return nil, fmt.Errorf("row %d has no column %q", i+1, column)
+		}
+		v, ok := number(raw)
+		if !ok {
+			return nil, fmt.Errorf("row %d: column %q is not a number: %v", i+1, column, raw)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// numericColumns returns the columns other than x whose values are numbers
+// in every row, sorted by name.
+func numericColumns(rows []map[string]any, x string) []string {
+	var columns []string
+	for column := range rows[0] {
+		if column == x {
+			continue
+		}
+		if _, err := columnValues(rows, column); err == nil {
+			columns = append(columns, column)
+		}
+	}
+	slices.Sort(columns)
+	return columns
+}
+
+// number converts a JSON value to a finite number. Numeric strings are accepted.
+func number(v any) (float64, bool) {
+	var f float64
+	switch n := v.(type) {
+	case float64:
+		f = n
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		if err != nil {
+			return 0, false
+		}
+		f = parsed
+	default:
+		return 0, false
+	}
+	return f, !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+func parseTime(v any) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// timeFormatter shows dates, adding the time of day when the data spans less
+// than three days.
+func timeFormatter(times []time.Time) chart.ValueFormatter {
+	first, last := slices.MinFunc(times, time.Time.Compare), slices.MaxFunc(times, time.Time.Compare)
+	if last.Sub(first) < 72*time.Hour {
+		return chart.TimeValueFormatterWithFormat("01-02 15:04")
+	}
+	return chart.TimeDateValueFormatter
+}
+
+func label(v any) string {
+	switch l := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return l
+	case float64:
+		return strconv.FormatFloat(l, 'f', -1, 64)
+	default:
+		return fmt.Sprint(l)
+	}
+}
+
+// chartFilename returns the PNG file name for a chart.
+func chartFilename(params PlotArgs) string {
+	name := filepath.Base(filepath.Clean("/" + params.Filename))
+	if name == "/" || name == "." {
+		name = params.Title
+		if name == "" {
+			name = "chart-" + time.Now().Format("20060102-150405")
+		}
+		name = transcript.SafeFileName(name)
+	}
+	if !strings.EqualFold(filepath.Ext(name), ".png") {
+		name += ".png"
+	}
+	return name
+}
@@ -0,0 +1,121 @@
+package plot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+func newTestTool(t *testing.T) (*PlotTool, string) {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	dir := filepath.Join(t.TempDir(), "charts")
+	return NewPlotTool(dir, log), dir
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Failed to marshal arguments: %v", err)
+	}
+	return string(data)
+}
+
+func TestPlotTool_ExecuteResult(t *testing.T) {
+	usage := []map[string]any{
+		{"day": "2026-10-12", "prompt": 1200, "completion": 300},
+		{"day": "2026-10-13", "prompt": 900, "completion": "250"},
+		{"day": "2026-10-14", "prompt": 1500, "completion": 410},
+	}
+
+	tests := []struct {
+		name     string
+		args     map[string]any
+		wantFile string
+	}{
+		{
+			name:     "line over dates with default columns",
+			args:     map[string]any{"type": "line", "title": "Token usage", "data": usage, "x": "day"},
+			wantFile: "Token_usage.png",
+		},
+		{
+			name: "line over labels",
+			args: map[string]any{"type": "line", "data": []map[string]any{
+				{"model": "a", "latency": 1.5}, {"model": "b", "latency": 0.7},
+			}, "x": "model", "filename": "latency"},
+			wantFile: "latency.png",
+		},
+		{
+			name:     "bar",
+			args:     map[string]any{"type": "bar", "data": usage, "x": "day", "y": []string{"prompt"}, "filename": "../prompt.png"},
+			wantFile: "prompt.png",
+		},
+		{
+			name:     "pie",
+			args:     map[string]any{"type": "pie", "title": "Share", "data": usage, "x": "day", "y": []string{"completion"}},
+			wantFile: "Share.png",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool, dir := newTestTool(t)
+
+			result, err := tool.ExecuteResult(context.Background(), mustJSON(t, tt.args))
+			if err != nil {
+				t.Fatalf("ExecuteResult() error = %v", err)
+			}
+			if len(result.Artifacts) != 1 {
+				t.Fatalf("Artifacts = %+v, want 1", result.Artifacts)
+			}
+
+			artifact := result.Artifacts[0]
+			if artifact.Path != filepath.Join(dir, tt.wantFile) || !artifact.IsImage() {
+				t.Errorf("Artifact = %+v, want image %s", artifact, tt.wantFile)
+			}
+			data, err := os.ReadFile(artifact.Path)
+			if err != nil || !bytes.HasPrefix(data, []byte("\x89PNG")) {
+				t.Errorf("chart is not a PNG: %v", err)
+			}
+		})
+	}
+}
+
+func TestPlotTool_ExecuteResult_Errors(t *testing.T) {
+	rows := []map[string]any{{"day": "mon", "n": 1}, {"day": "tue", "n": 2}}
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantErr string
+	}{
+		{"no data", map[string]any{"type": "bar", "x": "day"}, "data parameter is required"},
+		{"no x", map[string]any{"type": "bar", "data": rows}, "x parameter is required"},
+		{"invalid type", map[string]any{"type": "scatter", "data": rows, "x": "day"}, "invalid chart type"},
+		{"missing column", map[string]any{"type": "bar", "data": rows, "x": "day", "y": []string{"m"}}, `has no column "m"`},
+		{"no numeric columns", map[string]any{"type": "bar", "data": rows, "x": "n"}, "no numeric columns"},
+		{"several pie columns", map[string]any{"type": "pie", "data": rows, "x": "day", "y": []string{"n", "n"}}, "take one y column"},
+		{"single line point", map[string]any{"type": "line", "data": rows[:1], "x": "day"}, "at least 2 data rows"},
+		{"negative pie value", map[string]any{"type": "pie", "data": []map[string]any{{"day": "mon", "n": -1}}, "x": "day"}, "non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool, _ := newTestTool(t)
+			_, err := tool.ExecuteResult(context.Background(), mustJSON(t, tt.args))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ExecuteResult() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
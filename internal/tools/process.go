@@ -0,0 +1,245 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/process"
+)
+
+// defaultProcessOutputLines is the number of output lines returned by the output action by default.
+const defaultProcessOutputLines = 50
+
+// ProcessManager manages background processes (implemented by process.Manager).
+type ProcessManager interface {
+	Start(name string, args []string, display, sessionID string) (process.Job, error)
+	List() []process.Job
+	Get(id string) (process.Job, error)
+	Output(id string, n int) ([]string, error)
+	Stop(id string) error
+}
+
+// ProcessTool implements the Tool interface for managing
+// long-running background processes (dev servers, watchers, builds).
+// Commands are validated against the shell tool allow/deny lists.
+type ProcessTool struct {
+	manager   ProcessManager
+	validator *ShellValidator
+	shell     string // Shell interpreter from [tools.shell]
+	logger    *logger.Logger
+}
+
+// ProcessArgs represents the arguments for the process tool.
+type ProcessArgs struct {
+	Action  string `json:"action"`  // Action: "start", "list", "output", "stop"
+	Command string `json:"command"` // Command to start
+	JobID   string `json:"job_id"`  // Job ID for output/stop
+	Lines   int    `json:"lines"`   // Number of output lines to return
+}
+
+// NewProcessTool creates a new ProcessTool instance.
+// The shell tool configuration provides the command allow/deny lists and
+// the shell interpreter.
+func NewProcessTool(manager ProcessManager, cfg *config.Config, logger *logger.Logger) *ProcessTool {
+	return &ProcessTool{
+		manager:   manager,
+		validator: NewShellValidatorFromConfig(cfg.Tools.Shell),
+		shell:     cfg.Tools.Shell.Shell,
+		logger:    logger,
+	}
+}
+
+// Name returns the tool name.
+func (t *ProcessTool) Name() string {
+	return "process"
+}
+
+// ConfigSection returns the configuration table of the tool ([tools.process]).
+func (t *ProcessTool) ConfigSection() string {
+	return "process"
+}
+
+// ConfigSchema returns the keys of [tools.process].
+func (t *ProcessTool) ConfigSchema() []ConfigField {
+	return []ConfigField{
+		{Key: "enabled", Type: ConfigBool},
+		{Key: "max_jobs", Type: ConfigInt},
+		{Key: "output_lines", Type: ConfigInt},
+		{Key: "stop_timeout_seconds", Type: ConfigInt},
+	}
+}
+
+// Description returns a description of what the tool does.
+func (t *ProcessTool) Description() string {
+	return "Manages long-running background processes (e.g. dev servers). Start a process, list jobs, read recent output and stop jobs. Jobs keep running between messages."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *ProcessTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"description": "Action to perform: 'start' to launch a background process, 'list' to show jobs, 'output' to read recent output, 'stop' to terminate a job.",
+				"enum":        []string{"start", "list", "output", "stop"},
+			},
+			"command": map[string]any{
+				"type":        "string",
+				"description": "Command to run in the workspace (must be allowed by the shell tool configuration). Required for 'start' action. Example: 'npm run dev'.",
+			},
+			"job_id": map[string]any{
+				"type":        "string",
+				"description": "Job ID. Required for 'output' and 'stop' actions.",
+			},
+			"lines": map[string]any{
+				"type":        "integer",
+				"description": "Number of most recent output lines to return for 'output' action. Defaults to 50.",
+				"default":     defaultProcessOutputLines,
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+// Execute executes the process tool with the provided execution context.
+// The session ID from the context is recorded on started jobs.
+func (t *ProcessTool) Execute(ctx context.Context, args string) (string, error) {
+	var params ProcessArgs
+	if err := parseJSON(args, &params); err != nil {
+		return "", fmt.Errorf("failed to parse process arguments: %w", err)
+	}
+
+	switch params.Action {
+	case "start":
+		return t.start(ctx, params.Command)
+	case "list":
+		return t.list(), nil
+	case "output":
+		if params.JobID == "" {
+			return "", fmt.Errorf("job_id parameter is required for output action")
+		}
+		return t.output(params.JobID, params.Lines)
+	case "stop":
+		if params.JobID == "" {
+			return "", fmt.Errorf("job_id parameter is required for stop action")
+		}
+		if err := t.manager.Stop(params.JobID); err != nil {
+			return "", fmt.Errorf("failed to stop job: %w", err)
+		}
+		return t.output(params.JobID, 10)
+	default:
+		return "", fmt.Errorf("invalid action: %s. Valid actions: start, list, output, stop", params.Action)
+	}
+}
+
+// DryRun reports the process a start or stop call would start or stop.
+// Reading jobs (list, output) runs as usual.
+func (t *ProcessTool) DryRun(ctx context.Context, args string) (string, bool, error) {
+	var params ProcessArgs
+	if err := parseJSON(args, &params); err != nil {
+		return "", true, fmt.Errorf("failed to parse process arguments: %w", err)
+	}
+
+	switch params.Action {
+	case "start":
+		command := strings.TrimSpace(params.Command)
+		if command == "" {
+			return "", true, fmt.Errorf("command parameter is required for start action")
+		}
+		note := ""
+		if err := t.validator.Validate(command); err != nil {
+			if !strings.Contains(err.Error(), "# CONFIRM_REQUIRED:") {
+				return "", true, fmt.Errorf("command validation failed: %w", err)
+			}
+			note = " (needs the user's confirmation)"
+		}
+		return fmt.Sprintf("Would start a background process%s:\n%s", note, command), true, nil
+	case "stop":
+		if params.JobID == "" {
+			return "", true, fmt.Errorf("job_id parameter is required for stop action")
+		}
+		return fmt.Sprintf("Would stop job %s", params.JobID), true, nil
+	default:
+		return "", false, nil
+	}
+}
+
+// start validates and launches a command.
+func (t *ProcessTool) start(ctx context.Context, command string) (string, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return "", fmt.Errorf("command parameter is required for start action")
+	}
+
+	if err := t.validator.Validate(command); err != nil {
+		// Let the agent ask the user for confirmation
+		if strings.Contains(err.Error(), "# CONFIRM_REQUIRED:") {
+			return err.Error(), nil
+		}
+		return "", fmt.Errorf("command validation failed: %w", err)
+	}
+
+	name, cmdArgs, err := buildCommand(t.shell, command)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse command: %w", err)
+	}
+
+	job, err := t.manager.Start(name, cmdArgs, command, getSessionID(ctx))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Process started.\nJob ID: %s\nPID: %d\nCommand: %s\nUse action 'output' with this job_id to read its logs.",
+		job.ID, job.PID, job.Command), nil
+}
+
+// list formats all jobs.
+func (t *ProcessTool) list() string {
+	jobs := t.manager.List()
+	if len(jobs) == 0 {
+		return "No background processes."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Background processes (%d):\n", len(jobs))
+	for _, j := range jobs {
+		fmt.Fprintf(&b, "\n- ID: %s\n  Command: %s\n  Status: %s\n", j.ID, j.Command, describeJobStatus(j))
+	}
+	return b.String()
+}
+
+// output formats job status and recent output.
+func (t *ProcessTool) output(id string, lines int) (string, error) {
+	job, err := t.manager.Get(id)
+	if err != nil {
+		return "", err
+	}
+	if lines <= 0 {
+		lines = defaultProcessOutputLines
+	}
+	out, err := t.manager.Output(id, lines)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Job: %s\n# Command: %s\n# Status: %s\n# Output (last %d lines):\n", job.ID, job.Command, describeJobStatus(job), len(out))
+	b.WriteString(strings.Join(out, "\n"))
+	return b.String(), nil
+}
+
+// describeJobStatus formats job status with uptime or exit code.
+func describeJobStatus(j process.Job) string {
+	if j.Status == process.StatusRunning {
+		return fmt.Sprintf("running for %s (pid %d)", time.Since(j.StartedAt).Round(time.Second), j.PID)
+	}
+	return fmt.Sprintf("%s (exit code %d)", j.Status, j.ExitCode)
+}
+
+// Ensure ProcessTool implements Tool interface
+var _ Tool = (*ProcessTool)(nil)
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+	"github.com/aatumaykin/nexbot/internal/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupProcessTool(t *testing.T, shell config.ShellToolConfig) *ProcessTool {
+	t.Helper()
+	manager := process.NewManager(process.Config{Workspace: t.TempDir(), StopTimeout: time.Second}, nil)
+	t.Cleanup(manager.StopAll)
+
+	cfg := &config.Config{}
+	cfg.Tools.Shell = shell
+	return NewProcessTool(manager, cfg, nil)
+}
+
+func TestProcessTool_StartOutputStop(t *testing.T) {
+	tool := setupProcessTool(t, config.ShellToolConfig{AllowedCommands: []string{"sleep *"}})
+	ctx := context.WithValue(context.Background(), sessionIDKey, "telegram:1")
+
+	result, err := tool.Execute(ctx, `{"action": "start", "command": "sleep 30"}`)
+	require.NoError(t, err)
+	assert.Contains(t, result, "Job ID: proc_")
+
+	jobs := tool.manager.List()
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "telegram:1", jobs[0].SessionID)
+
+	result, err = tool.Execute(context.Background(), `{"action": "list"}`)
+	require.NoError(t, err)
+	assert.Contains(t, result, jobs[0].ID)
+	assert.Contains(t, result, "running")
+
+	result, err = tool.Execute(context.Background(), `{"action": "stop", "job_id": "`+jobs[0].ID+`"}`)
+	require.NoError(t, err)
+	assert.Contains(t, result, "stopped")
+}
+
+func TestProcessTool_ValidatesCommand(t *testing.T) {
+	tool := setupProcessTool(t, config.ShellToolConfig{DenyCommands: []string{"rm *"}})
+
+	_, err := tool.Execute(context.Background(), `{"action": "start", "command": "rm -rf /tmp/x"}`)
+	assert.Error(t, err)
+	assert.Empty(t, tool.manager.List())
+}
+
+func TestProcessTool_InvalidArgs(t *testing.T) {
+	tool := setupProcessTool(t, config.ShellToolConfig{})
+
+	_, err := tool.Execute(context.Background(), `{"action": "start"}`)
+	assert.Error(t, err)
+
+	_, err = tool.Execute(context.Background(), `{"action": "output"}`)
+	assert.Error(t, err)
+
+	_, err = tool.Execute(context.Background(), `{"action": "unknown"}`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/users"
+)
+
+// ProfileRegistry reads and updates user profiles (implemented by users.Registry).
+type ProfileRegistry interface {
+	FindBySession(sessionID string) (users.User, bool)
+	Update(userID string, change func(u *users.User)) (users.User, error)
+}
+
+// ProfileTool implements the Tool interface for reading and updating the
+// profile of the current user: name, language, timezone and preferences.
+// The role can only be set in the config.
+type ProfileTool struct {
+	registry ProfileRegistry
+	logger   *logger.Logger
+}
+
+// ProfileArgs represents the arguments for the profile tool.
+type ProfileArgs struct {
+	Action      string            `json:"action"`      // Action: "get", "set"
+	Name        string            `json:"name"`        // New name
+	Language    string            `json:"language"`    // New language code
+	Timezone    string            `json:"timezone"`    // New IANA timezone
+	Preferences map[string]string `json:"preferences"` // Preferences to set; an empty value removes the key
+}
+
+// NewProfileTool creates a new ProfileTool instance.
+func NewProfileTool(registry ProfileRegistry, logger *logger.Logger) *ProfileTool {
+	return &ProfileTool{
+		registry: registry,
+		logger:   logger,
+	}
+}
+
+// Name returns the tool name.
+func (t *ProfileTool) Name() string {
+	return "profile"
+}
+
+// Description returns a description of what the tool does.
+func (t *ProfileTool) Description() string {
+	return "Reads and updates the profile of the current user (name, language, timezone, preferences). Use 'set' when the user tells you how to address them, which language they prefer, where they live or how they like answers."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *ProfileTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"description": "Action to perform: 'get' to show the profile, 'set' to update the given fields.",
+				"enum":        []string{"get", "set"},
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"description": "Name to address the user by.",
+			},
+			"language": map[string]any{
+				"type":        "string",
+				"description": "Preferred language code (e.g. 'en', 'ru').",
+			},
+			"timezone": map[string]any{
+				"type":        "string",
+				"description": "IANA timezone (e.g. 'Europe/Berlin'). Recurring reminders use it.",
+			},
+			"preferences": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+				"description":          "Preferences to store (e.g. {'units': 'metric'}). An empty value removes the preference.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+// Execute executes the profile tool for the user of the current session.
+func (t *ProfileTool) Execute(ctx context.Context, args string) (string, error) {
+	var params ProfileArgs
+	if err := parseJSON(args, &params); err != nil {
+		return "", fmt.Errorf("failed to parse profile arguments: %w", err)
+	}
+
+	sessionID := getSessionID(ctx)
+	u, ok := t.registry.FindBySession(sessionID)
+	if !ok {
+		return "", fmt.Errorf("the current session is not linked to a known user; use notify 'link' first")
+	}
+
+	switch params.Action {
+	case "get":
+		return formatProfile(u), nil
+	case "set":
+		return t.set(ctx, u.ID, params)
+	default:
+		return "", fmt.Errorf("invalid action: %s. Valid actions: get, set", params.Action)
+	}
+}
+
+// set updates the given profile fields.
+func (t *ProfileTool) set(ctx context.Context, userID string, params ProfileArgs) (string, error) {
+	if params.Name == "" && params.Language == "" && params.Timezone == "" && len(params.Preferences) == 0 {
+		return "", fmt.Errorf("nothing to update: set name, language, timezone or preferences")
+	}
+	if params.Timezone != "" {
+		if _, err := time.LoadLocation(params.Timezone); err != nil {
+			return "", fmt.Errorf("invalid timezone %q: %w", params.Timezone, err)
+		}
+	}
+
+	u, err := t.registry.Update(userID, func(u *users.User) {
+		if params.Name != "" {
+			u.Name = params.Name
+		}
+		if params.Language != "" {
+			u.Language = strings.ToLower(params.Language)
+		}
+		if params.Timezone != "" {
+			u.Timezone = params.Timezone
+		}
+		for key, value := range params.Preferences {
+			if value == "" {
+				delete(u.Preferences, key)
+				continue
+			}
+			if u.Preferences == nil {
+				u.Preferences = make(map[string]string)
+			}
+			u.Preferences[key] = value
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if t.logger != nil {
+		t.logger.InfoCtx(ctx, "user profile updated", logger.Field{Key: "user_id", Value: userID})
+	}
+	return "✅ Profile updated\n" + formatProfile(u), nil
+}
+
+// formatProfile formats the profile of a user.
+func formatProfile(u users.User) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "User: %s\n", u.ID)
+	if u.Name != "" {
+		fmt.Fprintf(&b, "Name: %s\n", u.Name)
+	}
+	if u.Language != "" {
+		fmt.Fprintf(&b, "Language: %s\n", u.Language)
+	}
+	if u.Timezone != "" {
+		fmt.Fprintf(&b, "Timezone: %s\n", u.Timezone)
+	}
+	if u.Role != "" {
+		fmt.Fprintf(&b, "Role: %s\n", u.Role)
+	}
+	for _, key := range slices.Sorted(maps.Keys(u.Preferences)) {
+		fmt.Fprintf(&b, "Preference %s: %s\n", key, u.Preferences[key])
+	}
+	return b.String()
+}
+
+// Ensure ProfileTool implements Tool interface
+var _ Tool = (*ProfileTool)(nil)
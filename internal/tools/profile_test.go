@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/users"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileTool_SetAndGet(t *testing.T) {
+	registry := users.NewRegistry(t.TempDir())
+	require.NoError(t, registry.Load([]users.User{{
+		ID:          "alice",
+		Role:        users.RoleAdmin,
+		Identities:  []users.Identity{{Channel: "telegram", Address: "123"}},
+		Preferences: map[string]string{"tone": "formal"},
+	}}))
+	tool := NewProfileTool(registry, nil)
+	ctx := context.WithValue(context.Background(), sessionIDKey, "telegram:123")
+
+	result, err := tool.Execute(ctx, `{"action": "set", "language": "DE", "timezone": "Europe/Berlin", "preferences": {"units": "metric", "tone": ""}}`)
+	require.NoError(t, err)
+	assert.Contains(t, result, "Profile updated")
+
+	u, _ := registry.Get("alice")
+	assert.Equal(t, "de", u.Language)
+	assert.Equal(t, "Europe/Berlin", u.Timezone)
+	assert.Equal(t, map[string]string{"units": "metric"}, u.Preferences)
+
+	result, err = tool.Execute(ctx, `{"action": "get"}`)
+	require.NoError(t, err)
+	assert.Contains(t, result, "Role: admin")
+
+	_, err = tool.Execute(ctx, `{"action": "set", "timezone": "Mars/Olympus"}`)
+	assert.Error(t, err)
+
+	_, err = tool.Execute(context.WithValue(context.Background(), sessionIDKey, "telegram:999"), `{"action": "get"}`)
+	assert.Error(t, err, "unknown session")
+}
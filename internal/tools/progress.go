@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/textutil"
+)
+
+// maxStatusRunes limits the status text of a progress update
+const maxStatusRunes = 200
+
+// Progress is a progress update of a running tool.
+type Progress struct {
+	Percent int    // Completion percentage (0-100), or -1 if unknown
+	Status  string // Short status text, optional
+}
+
+// ProgressFunc receives progress updates of a running tool.
+type ProgressFunc func(p Progress)
+
+// progressKey is the context key of the progress function of a tool call
+type progressKey struct{}
+
+// WithProgress returns a context that delivers progress reports of a tool call to fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ReportProgress reports the progress of the tool running with ctx.
+// Percent is clamped to 0-100, negative values mean unknown progress.
+// Does nothing if the caller does not listen for progress.
+func ReportProgress(ctx context.Context, percent int, status string) {
+	fn, ok := ctx.Value(progressKey{}).(ProgressFunc)
+	if !ok || fn == nil {
+		return
+	}
+	if percent < 0 {
+		percent = -1
+	} else if percent > 100 {
+		percent = 100
+	}
+	fn(Progress{Percent: percent, Status: status})
+}
+
+// progressReader reports how much of a body has been read.
+type progressReader struct {
+	ctx   context.Context
+	r     io.Reader
+	total int64 // Expected size, or -1 if unknown
+	read  int64
+}
+
+// NewProgressReader returns a reader that reports progress of reading r.
+// total is the expected size in bytes; if it is unknown (negative), only
+// the number of bytes read is reported.
+func NewProgressReader(ctx context.Context, r io.Reader, total int64) io.Reader {
+	return &progressReader{ctx: ctx, r: r, total: total}
+}
+
+// Read reads from the underlying reader and reports the progress.
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.read += int64(n)
+		if p.total > 0 {
+			ReportProgress(p.ctx, int(p.read*100/p.total),
+				fmt.Sprintf("%s of %s", formatBytes(p.read), formatBytes(p.total)))
+		} else {
+			ReportProgress(p.ctx, -1, formatBytes(p.read))
+		}
+	}
+	return n, err
+}
+
+// progressWriter reports the last line written as the status of a tool.
+type progressWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+// NewProgressWriter returns a writer that writes to w and reports the last
+// complete line written as progress status, e.g. for command output.
+func NewProgressWriter(ctx context.Context, w io.Writer) io.Writer {
+	return &progressWriter{ctx: ctx, w: w}
+}
+
+// Write writes to the underlying writer and reports the last line of b.
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	lines := strings.Split(string(bytes.TrimRight(b[:n], "\r\n")), "\n")
+	if line := strings.TrimSpace(lines[len(lines)-1]); line != "" {
+		ReportProgress(p.ctx, -1, textutil.Truncate(line, maxStatusRunes))
+	}
+	return n, err
+}
+
+// formatBytes formats a byte count for progress status text.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+// collectProgress returns a context that records progress reports.
+func collectProgress() (context.Context, *[]Progress) {
+	var reports []Progress
+	ctx := WithProgress(context.Background(), func(p Progress) {
+		reports = append(reports, p)
+	})
+	return ctx, &reports
+}
+
+func TestReportProgress(t *testing.T) {
+	ctx, reports := collectProgress()
+
+	ReportProgress(ctx, 50, "half")
+	ReportProgress(ctx, 150, "")
+	ReportProgress(ctx, -5, "unknown")
+
+	want := []Progress{{50, "half"}, {100, ""}, {-1, "unknown"}}
+	if len(*reports) != len(want) {
+		t.Fatalf("reports = %v, want %v", *reports, want)
+	}
+	for i, p := range *reports {
+		if p != want[i] {
+			t.Errorf("report %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+
+	// No listener: reporting is a no-op
+	ReportProgress(context.Background(), 10, "ignored")
+}
+
+func TestProgressReader(t *testing.T) {
+	ctx, reports := collectProgress()
+	data := strings.Repeat("x", 4096)
+
+	r := NewProgressReader(ctx, strings.NewReader(data), int64(len(data)))
+	if _, err := io.Copy(io.Discard, io.LimitReader(r, 2048)); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	last := (*reports)[len(*reports)-1]
+	if last.Percent != 50 || last.Status != "2.0 KB of 4.0 KB" {
+		t.Errorf("last report = %+v, want 50%% of 4.0 KB", last)
+	}
+
+	// Unknown size reports only the bytes read
+	ctx, reports = collectProgress()
+	if _, err := io.ReadAll(NewProgressReader(ctx, strings.NewReader("abc"), -1)); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if got := (*reports)[0]; got.Percent != -1 || got.Status != "3 B" {
+		t.Errorf("report = %+v, want unknown percent and 3 B", got)
+	}
+}
+
+func TestProgressWriter(t *testing.T) {
+	ctx, reports := collectProgress()
+	var buf bytes.Buffer
+
+	w := NewProgressWriter(ctx, &buf)
+	_, _ = w.Write([]byte("step 1\nstep 2\n"))
+	_, _ = w.Write([]byte("\n"))
+
+	if buf.String() != "step 1\nstep 2\n\n" {
+		t.Errorf("output = %q", buf.String())
+	}
+	if len(*reports) != 1 || (*reports)[0].Status != "step 2" {
+		t.Errorf("reports = %+v, want one report with last line", *reports)
+	}
+}
@@ -4,16 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/aatumaykin/nexbot/internal/forms"
 )
 
 // contextKey is the type for context keys to avoid collisions
-type contextKey struct{}
+type contextKey string
 
-var (
-	sessionIDKey      contextKey = struct{}{}
-	secretResolverKey contextKey = struct{}{}
+const (
+	sessionIDKey      contextKey = "session_id"
+	secretResolverKey contextKey = "secret_resolver"
 )
 
 // Error codes for tool execution
@@ -23,6 +26,7 @@ const (
 	ErrCodeInvalidFormat = "invalid_format"
 	ErrCodeInvalidValue  = "invalid_value"
 	ErrCodePathTraversal = "path_traversal"
+	ErrCodeInvalidArgs   = "invalid_arguments"
 
 	// Permission errors
 	ErrCodePermissionDenied = "permission_denied"
@@ -37,6 +41,7 @@ const (
 	ErrCodeExecutionFailed = "execution_failed"
 	ErrCodeTimeout         = "timeout"
 	ErrCodeCancelled       = "cancelled"
+	ErrCodeToolPanic       = "tool_panic"
 
 	// Rate limit errors
 	ErrCodeRateLimitExceeded = "rate_limit_exceeded"
@@ -66,17 +71,9 @@ type Tool interface {
 
 	// Execute runs the tool with the provided arguments.
 	// args is a JSON-encoded string containing the tool's input parameters.
-	Execute(args string) (string, error)
-}
-
-// ContextualTool is an optional interface that tools can implement to receive execution context.
-// If a tool implements this interface, ExecuteWithContext will be called instead of Execute.
-type ContextualTool interface {
-	Tool
-
-	// ExecuteWithContext runs the tool with the provided arguments and execution context.
-	// The context can be used for cancellation, deadlines, and timeout handling.
-	ExecuteWithContext(ctx context.Context, args string) (string, error)
+	// ctx carries the session, deadline and cancellation of the call: long
+	// running tools must stop when it is done.
+	Execute(ctx context.Context, args string) (string, error)
 }
 
 // SecretAwareTool is an optional interface that tools can implement to receive secret resolver.
@@ -89,23 +86,70 @@ type SecretAwareTool interface {
 	SetSecretResolver(resolver func(string, string) string)
 }
 
+// FormTool is an optional interface that tools can implement to declare required fields.
+// If the LLM calls the tool without some of them, the user is asked for the missing
+// values with a form and the tool runs once the form is completed.
+type FormTool interface {
+	Tool
+
+	// FormFields returns the fields required for the given arguments.
+	// Fields already present in args are not asked.
+	FormFields(args map[string]any) []forms.Field
+}
+
+// DestructiveTool is an optional interface of tools whose calls can destroy
+// data (delete or overwrite files, remove them in the shell). If approvals
+// are enabled for the tool's class, the user approves such calls first.
+type DestructiveTool interface {
+	Tool
+
+	// Destructive reports whether a call with the given arguments destroys data.
+	Destructive(args string) bool
+}
+
 // Registry manages the collection of available tools.
 // It provides thread-safe operations for registering and retrieving tools.
 type Registry struct {
-	mu    sync.RWMutex
-	tools map[string]Tool
+	mu       sync.RWMutex
+	tools    map[string]Tool
+	aliases  map[string]string // Alias or qualified name -> tool name
+	disabled map[string]bool   // Disabled namespaces
 }
 
 // NewRegistry creates a new empty tool registry.
 func NewRegistry() *Registry {
 	return &Registry{
-		tools: make(map[string]Tool),
+		tools:    make(map[string]Tool),
+		aliases:  make(map[string]string),
+		disabled: make(map[string]bool),
 	}
 }
 
+// DisableNamespace disables the tools of a namespace: registering them
+// returns ErrNamespaceDisabled. Tools registered before are kept.
+func (r *Registry) DisableNamespace(namespace string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.disabled[namespace] = true
+}
+
 // Register adds a tool to the registry.
 // If a tool with the same name already exists, it will be replaced.
+// The qualified name of the tool (fs.write for write_file) becomes its alias.
 func (r *Registry) Register(tool Tool) error {
+	return r.register(tool, false)
+}
+
+// RegisterPlugin adds a tool that doesn't ship with nexbot. Unlike Register,
+// it fails instead of replacing when the name or qualified name of the tool
+// collides with a registered tool or alias.
+func (r *Registry) RegisterPlugin(tool Tool) error {
+	return r.register(tool, true)
+}
+
+// register adds a tool, rejecting name collisions when strict is set.
+func (r *Registry) register(tool Tool, strict bool) error {
 	if tool == nil {
 		return fmt.Errorf("cannot register nil tool")
 	}
@@ -118,17 +162,89 @@ func (r *Registry) Register(tool Tool) error {
 		return fmt.Errorf("tool name cannot be empty")
 	}
 
+	qualified := QualifiedName(tool)
+	if namespace := namespaceOf(qualified); namespace != "" {
+		if !ValidNamespace(namespace) {
+			return fmt.Errorf("invalid namespace %q of tool %s", namespace, name)
+		}
+		if r.disabled[namespace] {
+			return fmt.Errorf("%w: %s", ErrNamespaceDisabled, namespace)
+		}
+	}
+
+	if strict {
+		if err := r.checkCollision(name); err != nil {
+			return err
+		}
+		if qualified != "" {
+			if err := r.checkCollision(qualified); err != nil {
+				return err
+			}
+		}
+	}
+
 	r.tools[name] = tool
+	if qualified != "" {
+		r.aliases[qualified] = name
+	}
 	return nil
 }
 
-// Get retrieves a tool by its name.
+// Alias makes a tool reachable under another name, e.g. an old name of a
+// renamed tool. The alias can't collide with a tool or another alias.
+func (r *Registry) Alias(alias, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tools[name]; !ok {
+		return fmt.Errorf("cannot alias unknown tool %s", name)
+	}
+	if err := r.checkCollision(alias); err != nil {
+		return err
+	}
+	r.aliases[alias] = name
+	return nil
+}
+
+// checkCollision returns an error if name is taken by a tool or an alias.
+// The caller must hold the lock.
+func (r *Registry) checkCollision(name string) error {
+	if _, ok := r.tools[name]; ok {
+		return fmt.Errorf("tool name collision: %s is already registered", name)
+	}
+	if target, ok := r.aliases[name]; ok {
+		return fmt.Errorf("tool name collision: %s is already an alias of %s", name, target)
+	}
+	return nil
+}
+
+// Resolve returns the name of the tool a name or alias refers to.
+// Unknown names are returned unchanged.
+func (r *Registry) Resolve(name string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.resolve(name)
+}
+
+// resolve is Resolve for callers holding the lock.
+func (r *Registry) resolve(name string) string {
+	if _, ok := r.tools[name]; ok {
+		return name
+	}
+	if target, ok := r.aliases[name]; ok {
+		return target
+	}
+	return name
+}
+
+// Get retrieves a tool by its name, qualified name or alias.
 // Returns the tool and true if found, nil and false otherwise.
 func (r *Registry) Get(name string) (Tool, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	tool, ok := r.tools[name]
+	tool, ok := r.tools[r.resolve(name)]
 	return tool, ok
 }
 
@@ -148,6 +264,8 @@ func (r *Registry) List() []Tool {
 
 // ToSchema converts the registered tools to OpenAI-compatible function definitions.
 // This returns a slice of ToolDefinition that can be sent to LLM providers.
+// Definitions are sorted by name, so repeated requests share a stable,
+// cacheable prompt prefix.
 func (r *Registry) ToSchema() []ToolDefinition {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -160,6 +278,9 @@ func (r *Registry) ToSchema() []ToolDefinition {
 			Parameters:  tool.Parameters(),
 		})
 	}
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Name < schemas[j].Name
+	})
 
 	return schemas
 }
@@ -184,12 +305,23 @@ type ToolCall struct {
 type ToolResult struct {
 	ToolCallID string         `json:"tool_call_id"`
 	Content    string         `json:"content"`
+	MimeType   string         `json:"mime_type,omitempty"` // Content type of Content (StructuredTool only)
+	Artifacts  []Artifact     `json:"artifacts,omitempty"` // Files produced by the call (StructuredTool only)
+	Changes    []FileChange   `json:"changes,omitempty"`   // File changes made by the call (StructuredTool only)
 	Error      *ToolError     `json:"error,omitempty"`
 	TimedOut   bool           `json:"timed_out,omitempty"`
 	ExitCode   int            `json:"exit_code,omitempty"`
 	Details    map[string]any `json:"details,omitempty"`
 }
 
+// ErrorClass returns the type of the error of a failed call, or "" if the call succeeded.
+func (r ToolResult) ErrorClass() ErrorType {
+	if r.Error == nil {
+		return ""
+	}
+	return r.Error.Type
+}
+
 // ExecutionConfig represents the configuration for tool execution.
 type ExecutionConfig struct {
 	Timeout        time.Duration                       // Timeout for tool execution
@@ -226,6 +358,14 @@ func ExecuteToolCallWithContext(registry *Registry, tc ToolCall, ctx context.Con
 		}, nil
 	}
 
+	// Reject arguments that do not match the parameters schema before
+	// dispatch, so the model gets the problems instead of the tool junk.
+	// Calls from the agent loop were checked already; repeating the cheap
+	// schema walk keeps every other caller covered.
+	if toolErr := checkArguments(tool, tc); toolErr != nil {
+		return ToolResult{ToolCallID: tc.ID, Error: toolErr}, nil
+	}
+
 	// Set secret resolver on tool if it supports it
 	if cfg != nil && cfg.SecretResolver != nil {
 		if secretAwareTool, ok := tool.(SecretAwareTool); ok {
@@ -267,44 +407,81 @@ func ExecuteToolCallWithContext(registry *Registry, tc ToolCall, ctx context.Con
 
 	// Create a channel for the result
 	type executionResult struct {
-		result string
-		err    error
+		result   *Result
+		err      error
+		panicked bool
 	}
 	resultChan := make(chan executionResult, 1)
 
-	// Execute the tool
+	// Execute the tool, with a structured result if the tool supports it.
+	// In dry-run mode mutating calls and calls of tools that are not known to
+	// be read-only only report what they would do.
 	go func() {
-		var res string
-		var err error
-
-		// Check if tool implements ContextualTool for context support
-		if contextualTool, ok := tool.(ContextualTool); ok {
-			res, err = contextualTool.ExecuteWithContext(execCtx, tc.Arguments)
-		} else {
-			// Fall back to regular Execute (no context support)
-			res, err = tool.Execute(tc.Arguments)
+		// A panicking tool fails its call instead of the whole process
+		defer func() {
+			if r := recover(); r != nil {
+				resultChan <- executionResult{err: fmt.Errorf("tool panicked: %v", r), panicked: true}
+			}
+		}()
+		if DryRun(execCtx) {
+			if dryRunTool, ok := tool.(DryRunTool); ok {
+				report, mutates, err := dryRunTool.DryRun(execCtx, tc.Arguments)
+				if err != nil || mutates {
+					resultChan <- executionResult{result: &Result{Content: DryRunPrefix + report}, err: err}
+					return
+				}
+			} else if readOnlyTool, ok := tool.(ReadOnlyTool); !ok || !readOnlyTool.ReadOnly() {
+				// Tools that may change something but cannot describe it are refused
+				report := fmt.Sprintf("would call %s with %s", tc.Name, tc.Arguments)
+				resultChan <- executionResult{result: &Result{Content: DryRunPrefix + report}}
+				return
+			}
 		}
-
-		resultChan <- executionResult{result: res, err: err}
+		// Changed files are saved first, so the turn can be rolled back
+		if snapshotTool, ok := tool.(SnapshotTool); ok {
+			if snapshotter := snapshotterFrom(execCtx); snapshotter != nil {
+				if err := snapshotter.Save(snapshotTool.AffectedPaths(tc.Arguments)...); err != nil {
+					resultChan <- executionResult{err: fmt.Errorf("failed to save files for rollback: %w", err)}
+					return
+				}
+			}
+		}
+		if structured, ok := tool.(StructuredTool); ok {
+			res, err := structured.ExecuteResult(execCtx, tc.Arguments)
+			resultChan <- executionResult{result: res, err: err}
+			return
+		}
+		res, err := tool.Execute(execCtx, tc.Arguments)
+		resultChan <- executionResult{result: &Result{Content: res}, err: err}
 	}()
 
 	// Wait for result or timeout
 	select {
 	case res := <-resultChan:
 		if res.err != nil {
+			code := ErrCodeExecutionFailed
+			if res.panicked {
+				code = ErrCodeToolPanic
+			}
 			return ToolResult{
 				ToolCallID: tc.ID,
 				Error: NewExecutionError(
-					ErrCodeExecutionFailed,
+					code,
 					res.err.Error(),
 					"",
 					0),
 			}, nil
 		}
 
+		if res.result == nil {
+			res.result = &Result{}
+		}
 		return ToolResult{
 			ToolCallID: tc.ID,
-			Content:    res.result,
+			Content:    res.result.Content,
+			MimeType:   res.result.MimeType,
+			Artifacts:  res.result.Artifacts,
+			Changes:    res.result.Changes,
 		}, nil
 
 	case <-execCtx.Done():
@@ -1,7 +1,9 @@
 package tools
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -25,7 +27,7 @@ func (m *mockTool) Parameters() map[string]any {
 	return m.parameters
 }
 
-func (m *mockTool) Execute(args string) (string, error) {
+func (m *mockTool) Execute(ctx context.Context, args string) (string, error) {
 	if m.executeFunc != nil {
 		return m.executeFunc(args)
 	}
@@ -139,6 +141,52 @@ func TestExecuteToolCall_NotFound(t *testing.T) {
 	}
 }
 
+func TestExecuteToolCall_InvalidArguments(t *testing.T) {
+	registry := NewRegistry()
+	executed := 0
+	tool := &mockTool{
+		name: "search",
+		parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"query": map[string]any{"type": "string"},
+				"limit": map[string]any{"type": "integer"},
+				"order": map[string]any{"type": "string", "enum": []any{"asc", "desc"}},
+			},
+			"required": []any{"query"},
+		},
+		executeFunc: func(args string) (string, error) {
+			executed++
+			return "found", nil
+		},
+	}
+	if err := registry.Register(tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	for _, args := range []string{`{"limit":5}`, `{"query":"a","limit":"5"}`, `{"query":"a","order":"up"}`, `{"query":`} {
+		result, err := ExecuteToolCall(registry, ToolCall{ID: "call_1", Name: "search", Arguments: args})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Error == nil || result.Error.Code != ErrCodeInvalidArgs || result.Error.Type != ErrorTypeValidation {
+			t.Errorf("Expected an invalid arguments error for %s, got %+v", args, result.Error)
+			continue
+		}
+		if !strings.Contains(result.Error.ToLLMContext(), "problems") {
+			t.Errorf("Expected the problems in the LLM context, got %q", result.Error.ToLLMContext())
+		}
+	}
+	if executed != 0 {
+		t.Errorf("Expected invalid calls not to run, ran %d times", executed)
+	}
+
+	result, err := ExecuteToolCall(registry, ToolCall{ID: "call_2", Name: "search", Arguments: `{"query":"a","order":"asc"}`})
+	if err != nil || result.Content != "found" {
+		t.Errorf("Expected valid arguments to run, got %+v, %v", result, err)
+	}
+}
+
 func TestExecuteToolCall_ExecutionError(t *testing.T) {
 	registry := NewRegistry()
 
@@ -170,6 +218,231 @@ func TestExecuteToolCall_ExecutionError(t *testing.T) {
 	}
 }
 
+// structuredMockTool is a mock tool that returns a structured result.
+type structuredMockTool struct {
+	mockTool
+	result *Result
+}
+
+func (m *structuredMockTool) ExecuteResult(ctx context.Context, args string) (*Result, error) {
+	return m.result, nil
+}
+
+func TestExecuteToolCall_StructuredResult(t *testing.T) {
+	registry := NewRegistry()
+
+	artifact := NewArtifact("/tmp/chart.png", "Chart")
+	tool := &structuredMockTool{
+		mockTool: mockTool{name: "structured_tool", parameters: map[string]any{}},
+		result: &Result{
+			Content:   "| a | b |",
+			MimeType:  "text/markdown",
+			Artifacts: []Artifact{artifact},
+		},
+	}
+	if err := registry.Register(tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	result, err := ExecuteToolCall(registry, ToolCall{ID: "call_1", Name: "structured_tool", Arguments: "{}"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Content != "| a | b |" {
+		t.Errorf("Expected structured content, got '%s'", result.Content)
+	}
+	if result.MimeType != "text/markdown" {
+		t.Errorf("Expected mime type 'text/markdown', got '%s'", result.MimeType)
+	}
+	if len(result.Artifacts) != 1 || result.Artifacts[0] != artifact {
+		t.Errorf("Expected artifacts %v, got %v", []Artifact{artifact}, result.Artifacts)
+	}
+	if result.ErrorClass() != "" {
+		t.Errorf("Expected no error class, got '%s'", result.ErrorClass())
+	}
+}
+
+func TestExecuteToolCall_Panic(t *testing.T) {
+	registry := NewRegistry()
+	tool := &mockTool{name: "panic_tool", parameters: map[string]any{}, executeFunc: func(args string) (string, error) {
+		panic("nil map")
+	}}
+	if err := registry.Register(tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	result, err := ExecuteToolCall(registry, ToolCall{ID: "call_1", Name: "panic_tool", Arguments: "{}"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Error == nil || result.Error.Code != ErrCodeToolPanic {
+		t.Fatalf("Expected a tool panic error, got %v", result.Error)
+	}
+	if !strings.Contains(result.Error.Message, "nil map") {
+		t.Errorf("Expected the panic value in the error, got '%s'", result.Error.Message)
+	}
+}
+
+type dryRunMockTool struct {
+	mockTool
+	executed bool
+}
+
+func (m *dryRunMockTool) Execute(ctx context.Context, args string) (string, error) {
+	m.executed = true
+	return "executed", nil
+}
+
+func (m *dryRunMockTool) DryRun(ctx context.Context, args string) (string, bool, error) {
+	if args == `{"read": true}` {
+		return "", false, nil
+	}
+	return "Would write " + args, true, nil
+}
+
+func TestExecuteToolCallWithContext_DryRun(t *testing.T) {
+	registry := NewRegistry()
+	tool := &dryRunMockTool{mockTool: mockTool{name: "dry_tool", parameters: map[string]any{}}}
+	if err := registry.Register(tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+	ctx := WithDryRun(context.Background())
+
+	result, err := ExecuteToolCallWithContext(registry, ToolCall{ID: "call_1", Name: "dry_tool", Arguments: "{}"}, ctx, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tool.executed {
+		t.Error("Expected the mutating call not to be executed")
+	}
+	if result.Content != DryRunPrefix+"Would write {}" {
+		t.Errorf("Expected dry-run report, got '%s'", result.Content)
+	}
+
+	// Calls that change nothing run as usual
+	result, err = ExecuteToolCallWithContext(registry, ToolCall{ID: "call_2", Name: "dry_tool", Arguments: `{"read": true}`}, ctx, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !tool.executed || result.Content != "executed" {
+		t.Errorf("Expected the read-only call to be executed, got '%s'", result.Content)
+	}
+
+	// Without dry-run mode mutating calls run
+	tool.executed = false
+	if _, err := ExecuteToolCallWithContext(registry, ToolCall{ID: "call_3", Name: "dry_tool", Arguments: "{}"}, context.Background(), nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !tool.executed {
+		t.Error("Expected the call to be executed without dry-run mode")
+	}
+}
+
+type readOnlyMockTool struct {
+	mockTool
+}
+
+func (m *readOnlyMockTool) ReadOnly() bool {
+	return true
+}
+
+func TestExecuteToolCallWithContext_DryRunWithoutSupport(t *testing.T) {
+	registry := NewRegistry()
+	executed := false
+	sideEffecting := &mockTool{name: "notify", parameters: map[string]any{}, executeFunc: func(args string) (string, error) {
+		executed = true
+		return "sent", nil
+	}}
+	readOnly := &readOnlyMockTool{mockTool: mockTool{name: "read_tool", parameters: map[string]any{}, executeFunc: func(args string) (string, error) {
+		return "read", nil
+	}}}
+	for _, tool := range []Tool{sideEffecting, readOnly} {
+		if err := registry.Register(tool); err != nil {
+			t.Fatalf("Failed to register tool: %v", err)
+		}
+	}
+	ctx := WithDryRun(context.Background())
+
+	// A tool that is neither read-only nor able to describe its calls is refused
+	result, err := ExecuteToolCallWithContext(registry, ToolCall{ID: "call_1", Name: "notify", Arguments: `{"text": "hi"}`}, ctx, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if executed {
+		t.Error("Expected the side-effecting call not to be executed")
+	}
+	if want := DryRunPrefix + `would call notify with {"text": "hi"}`; result.Content != want {
+		t.Errorf("Expected '%s', got '%s'", want, result.Content)
+	}
+
+	// Read-only tools run as usual
+	result, err = ExecuteToolCallWithContext(registry, ToolCall{ID: "call_2", Name: "read_tool", Arguments: "{}"}, ctx, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Content != "read" {
+		t.Errorf("Expected the read-only call to be executed, got '%s'", result.Content)
+	}
+}
+
+type snapshotMockTool struct {
+	mockTool
+}
+
+func (m *snapshotMockTool) AffectedPaths(args string) []string {
+	return []string{"/workspace/" + args}
+}
+
+type mockSnapshotter struct {
+	saved []string
+	err   error
+}
+
+func (m *mockSnapshotter) Save(paths ...string) error {
+	m.saved = append(m.saved, paths...)
+	return m.err
+}
+
+func TestExecuteToolCallWithContext_Snapshot(t *testing.T) {
+	registry := NewRegistry()
+	executed := 0
+	tool := &snapshotMockTool{mockTool: mockTool{name: "write_tool", parameters: map[string]any{}, executeFunc: func(args string) (string, error) {
+		executed++
+		return "written", nil
+	}}}
+	if err := registry.Register(tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	snapshotter := &mockSnapshotter{}
+	ctx := WithSnapshotter(context.Background(), snapshotter)
+	result, err := ExecuteToolCallWithContext(registry, ToolCall{ID: "call_1", Name: "write_tool", Arguments: "a.txt"}, ctx, nil)
+	if err != nil || result.Content != "written" {
+		t.Fatalf("Unexpected result: %+v, %v", result, err)
+	}
+	if len(snapshotter.saved) != 1 || snapshotter.saved[0] != "/workspace/a.txt" {
+		t.Errorf("Expected the affected path to be saved, got %v", snapshotter.saved)
+	}
+
+	// A call whose files can't be saved does not run
+	snapshotter.err = fmt.Errorf("disk full")
+	result, err = ExecuteToolCallWithContext(registry, ToolCall{ID: "call_2", Name: "write_tool", Arguments: "b.txt"}, ctx, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Error == nil || executed != 1 {
+		t.Errorf("Expected the call to fail without running, got %+v (executed %d times)", result, executed)
+	}
+}
+
+func TestToolResult_ErrorClass(t *testing.T) {
+	result := ToolResult{Error: NewTimeoutError("TIMEOUT", "too slow", nil)}
+	if result.ErrorClass() != ErrorTypeTimeout {
+		t.Errorf("Expected error class '%s', got '%s'", ErrorTypeTimeout, result.ErrorClass())
+	}
+}
+
 func TestRegistry_ToJSON(t *testing.T) {
 	registry := NewRegistry()
 
@@ -200,6 +473,24 @@ func TestRegistry_ToJSON(t *testing.T) {
 	}
 }
 
+func TestRegistry_ToSchema_SortedByName(t *testing.T) {
+	registry := NewRegistry()
+	for _, name := range []string{"write_file", "list_dir", "read_file"} {
+		if err := registry.Register(&mockTool{name: name}); err != nil {
+			t.Fatalf("Failed to register tool: %v", err)
+		}
+	}
+
+	// Repeated requests must share the same tool prefix to be cacheable
+	schemas := registry.ToSchema()
+	want := []string{"list_dir", "read_file", "write_file"}
+	for i, schema := range schemas {
+		if schema.Name != want[i] {
+			t.Fatalf("ToSchema()[%d] = %s, want %s", i, schema.Name, want[i])
+		}
+	}
+}
+
 func TestRegistry_ConcurrentAccess(t *testing.T) {
 	registry := NewRegistry()
 
@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// ErrToolRecordingNotFound is returned in replay mode when no result was
+// recorded for a tool call.
+var ErrToolRecordingNotFound = errors.New("tool recording not found")
+
+// ToolRecorder makes runs reproducible: in record mode it saves the result of
+// every tool call, in replay mode it returns the saved results instead of
+// running the tools, so a replayed run sees the outputs of the recorded one.
+//
+// Results are keyed by tool name, arguments and the number of earlier calls
+// with the same name and arguments, so repeated calls (e.g. reading a file
+// before and after a change) replay in order.
+type ToolRecorder struct {
+	dir    string
+	replay bool
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+// toolRecording is a recorded tool call with its result.
+type toolRecording struct {
+	Name      string     `json:"name"`
+	Arguments string     `json:"arguments"`
+	Result    ToolResult `json:"result"`
+}
+
+// NewToolRecorder creates a recorder storing results in dir. With replay set
+// it serves the results recorded there.
+func NewToolRecorder(dir string, replay bool) *ToolRecorder {
+	return &ToolRecorder{dir: dir, replay: replay, calls: make(map[string]int)}
+}
+
+// Replaying reports whether the recorder serves recorded results.
+func (r *ToolRecorder) Replaying() bool {
+	return r.replay
+}
+
+// Load returns the recorded result of a call, with the ID of tc.
+// Returns ErrToolRecordingNotFound if the call was not recorded.
+func (r *ToolRecorder) Load(tc ToolCall) (ToolResult, error) {
+	path := r.path(tc)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ToolResult{}, fmt.Errorf("%w: %s", ErrToolRecordingNotFound, tc.Name)
+	}
+	if err != nil {
+		return ToolResult{}, fmt.Errorf("failed to read tool recording: %w", err)
+	}
+
+	var rec toolRecording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return ToolResult{}, fmt.Errorf("failed to parse tool recording %s: %w", filepath.Base(path), err)
+	}
+	rec.Result.ToolCallID = tc.ID
+	return rec.Result, nil
+}
+
+// Save records the result of a call.
+func (r *ToolRecorder) Save(tc ToolCall, result ToolResult) error {
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create tool recordings directory: %w", err)
+	}
+	data, err := json.MarshalIndent(toolRecording{Name: tc.Name, Arguments: tc.Arguments, Result: result}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal tool recording: %w", err)
+	}
+
+	path := r.path(tc)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write tool recording: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to save tool recording: %w", err)
+	}
+	return nil
+}
+
+// path returns the recording file of the next call with the name and
+// arguments of tc.
+func (r *ToolRecorder) path(tc ToolCall) string {
+	call := tc.Name + "\x00" + tc.Arguments
+
+	r.mu.Lock()
+	n := r.calls[call]
+	r.calls[call] = n + 1
+	r.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(call + "\x00" + strconv.Itoa(n)))
+	return filepath.Join(r.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestToolRecorder_RecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	call := ToolCall{ID: "call-1", Name: "read_file", Arguments: `{"path":"notes.md"}`}
+
+	recorder := NewToolRecorder(dir, false)
+	for _, content := range []string{"before", "after"} {
+		if err := recorder.Save(call, ToolResult{ToolCallID: call.ID, Content: content}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	// Repeated calls replay in the recorded order
+	replayer := NewToolRecorder(dir, true)
+	call.ID = "call-2"
+	for _, want := range []string{"before", "after"} {
+		result, err := replayer.Load(call)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if result.Content != want || result.ToolCallID != "call-2" {
+			t.Errorf("Load() = %+v, want %q for call-2", result, want)
+		}
+	}
+
+	if _, err := replayer.Load(call); !errors.Is(err, ErrToolRecordingNotFound) {
+		t.Errorf("Load() past the recording error = %v, want ErrToolRecordingNotFound", err)
+	}
+}
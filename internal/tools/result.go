@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// Result is the structured result of a tool call.
+type Result struct {
+	Content   string       // Result text for the LLM
+	MimeType  string       // Content type of Content, "text/plain" if empty
+	Artifacts []Artifact   // Files produced by the call, delivered to the user with the answer
+	Changes   []FileChange // Changes of file content made by the call, shown to the user with the answer
+}
+
+// FileChange is a change of a file's content made by a tool call.
+type FileChange struct {
+	Path string `json:"path"` // Absolute path of the file
+	Diff string `json:"diff"` // Unified diff of the change
+}
+
+// Artifact is a file produced by a tool call.
+type Artifact struct {
+	Path     string `json:"path"`              // Absolute path of the file
+	Name     string `json:"name"`              // File name shown to the user
+	MimeType string `json:"mime_type"`         // Content type of the file
+	Caption  string `json:"caption,omitempty"` // Optional caption
+}
+
+// NewArtifact creates an artifact for a file, detecting the content type
+// from the file extension.
+func NewArtifact(path, caption string) Artifact {
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return Artifact{
+		Path:     path,
+		Name:     filepath.Base(path),
+		MimeType: mimeType,
+		Caption:  caption,
+	}
+}
+
+// IsImage reports whether the artifact is an image, which channels show inline.
+func (a Artifact) IsImage() bool {
+	mediaType, _, err := mime.ParseMediaType(a.MimeType)
+	return err == nil && strings.HasPrefix(mediaType, "image/")
+}
+
+// StructuredTool is an optional interface that tools can implement to return
+// structured results. If a tool implements this interface, ExecuteResult
+// will be called instead of Execute.
+type StructuredTool interface {
+	Tool
+
+	// ExecuteResult runs the tool like Execute and returns a structured result.
+	ExecuteResult(ctx context.Context, args string) (*Result, error)
+}
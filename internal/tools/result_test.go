@@ -0,0 +1,31 @@
+package tools
+
+import "testing"
+
+func TestNewArtifact(t *testing.T) {
+	tests := []struct {
+		path     string
+		name     string
+		mimeType string
+		image    bool
+	}{
+		{"/workspace/chart.png", "chart.png", "image/png", true},
+		{"/workspace/report.pdf", "report.pdf", "application/pdf", false},
+		{"/workspace/data.unknownext", "data.unknownext", "application/octet-stream", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewArtifact(tt.path, "caption")
+			if a.Path != tt.path || a.Name != tt.name || a.Caption != "caption" {
+				t.Errorf("NewArtifact(%q) = %+v", tt.path, a)
+			}
+			if a.MimeType != tt.mimeType {
+				t.Errorf("MimeType = %q, want %q", a.MimeType, tt.mimeType)
+			}
+			if a.IsImage() != tt.image {
+				t.Errorf("IsImage() = %v, want %v", a.IsImage(), tt.image)
+			}
+		})
+	}
+}
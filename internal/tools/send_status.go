@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+)
+
+// defaultSendStatusLimit is the number of receipts listed by get_send_status
+// when no message is asked for.
+const defaultSendStatusLimit = 5
+
+// ReceiptReader returns the delivery receipts of a conversation (implemented by session.Manager).
+type ReceiptReader interface {
+	Receipts(sessionID string) ([]session.Receipt, error)
+}
+
+// SendStatusTool implements the Tool interface for checking whether the
+// messages sent to the chat of the conversation were delivered.
+type SendStatusTool struct {
+	receipts ReceiptReader
+}
+
+// SendStatusArgs represents the arguments for the get_send_status tool.
+type SendStatusArgs struct {
+	MessageID     string `json:"message_id"`     // Channel ID of the message
+	CorrelationID string `json:"correlation_id"` // ID of the send request
+	Limit         int    `json:"limit"`          // Maximum receipts listed (default 5)
+}
+
+// NewSendStatusTool creates a new SendStatusTool instance.
+func NewSendStatusTool(receipts ReceiptReader) *SendStatusTool {
+	return &SendStatusTool{receipts: receipts}
+}
+
+// Name returns the tool name.
+func (t *SendStatusTool) Name() string {
+	return "get_send_status"
+}
+
+// ReadOnly reports that the tool only reads.
+func (t *SendStatusTool) ReadOnly() bool {
+	return true
+}
+
+// Description returns a description of what the tool does.
+func (t *SendStatusTool) Description() string {
+	return fmt.Sprintf("Checks whether messages sent to this chat were delivered: the status, message ID and error of the latest %d sends. Use it to confirm that a notification actually went out, or to find the ID of an earlier message to edit or delete it. Only delivery is known; whether the user read a message is not.", session.MaxReceipts)
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *SendStatusTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"message_id": map[string]any{
+				"type":        "string",
+				"description": "ID of the message to check, as returned by send_message. Examples: {\"message_id\": \"1234\"}",
+			},
+			"correlation_id": map[string]any{
+				"type":        "string",
+				"description": "ID of the send request to check.",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Number of latest sends to list when no message is given (default %d, at most %d).", defaultSendStatusLimit, session.MaxReceipts),
+			},
+		},
+	}
+}
+
+// Execute reports the delivery status of sent messages.
+func (t *SendStatusTool) Execute(ctx context.Context, args string) (string, error) {
+	var params SendStatusArgs
+	if err := parseJSON(args, &params); err != nil {
+		return "", fmt.Errorf("failed to parse get_send_status arguments: %w", err)
+	}
+
+	sessionID := getSessionID(ctx)
+	if sessionID == "" {
+		return "", fmt.Errorf("send status is only available within a conversation")
+	}
+
+	receipts, err := t.receipts.Receipts(sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	if params.MessageID != "" || params.CorrelationID != "" {
+		// The latest receipt of a message wins, e.g. after it was edited
+		for i := len(receipts) - 1; i >= 0; i-- {
+			r := receipts[i]
+			if (params.MessageID == "" || r.MessageID == params.MessageID) &&
+				(params.CorrelationID == "" || r.CorrelationID == params.CorrelationID) {
+				return formatReceipt(r), nil
+			}
+		}
+		return "No send status recorded for this message. Only the latest sends of the chat are kept.", nil
+	}
+
+	if len(receipts) == 0 {
+		return "No messages were sent to this chat yet.", nil
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultSendStatusLimit
+	}
+	receipts = receipts[max(0, len(receipts)-limit):]
+
+	var b strings.Builder
+	b.WriteString("Latest sends, newest first:\n")
+	for i := len(receipts) - 1; i >= 0; i-- {
+		fmt.Fprintf(&b, "\n%s", formatReceipt(receipts[i]))
+	}
+	return b.String(), nil
+}
+
+// formatReceipt describes a delivery receipt in one line.
+func formatReceipt(r session.Receipt) string {
+	var b strings.Builder
+	if r.Success {
+		b.WriteString("✅ delivered")
+	} else {
+		b.WriteString("❌ failed")
+	}
+	if !r.Time.IsZero() {
+		fmt.Fprintf(&b, " at %s", r.Time.Local().Format("2006-01-02 15:04:05"))
+	}
+	if r.Type != "" {
+		fmt.Fprintf(&b, " | type: %s", r.Type)
+	}
+	if r.MessageID != "" {
+		fmt.Fprintf(&b, " | message_id: %s", r.MessageID)
+	}
+	fmt.Fprintf(&b, " | correlation_id: %s", r.CorrelationID)
+	if r.Duplicate {
+		b.WriteString(" | repeated send skipped")
+	}
+	if r.Error != "" {
+		fmt.Fprintf(&b, " | error: %s", r.Error)
+	}
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/agent/session"
+	"github.com/aatumaykin/nexbot/internal/llm"
+)
+
+func TestSendStatusTool(t *testing.T) {
+	mgr, err := session.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	tool := NewSendStatusTool(mgr)
+	ctx := context.WithValue(context.Background(), sessionIDKey, "telegram:1")
+
+	out, err := tool.Execute(ctx, `{}`)
+	if err != nil || !strings.Contains(out, "No messages were sent") {
+		t.Errorf("Execute(no receipts) = %q, %v", out, err)
+	}
+
+	sess, _, _ := mgr.GetOrCreate("telegram:1")
+	_ = sess.Append(llm.Message{Role: llm.RoleUser, Content: "Remind me at 9"})
+	_ = sess.AddReceipt(session.Receipt{CorrelationID: "c1", MessageID: "101", Type: "text", Success: true})
+	_ = sess.AddReceipt(session.Receipt{CorrelationID: "c2", Type: "photo", Error: "Bad Request: wrong file"})
+
+	out, err = tool.Execute(ctx, `{"message_id": "101"}`)
+	if err != nil || !strings.Contains(out, "✅ delivered") || !strings.Contains(out, "correlation_id: c1") {
+		t.Errorf("Execute(message_id) = %q, %v", out, err)
+	}
+
+	out, err = tool.Execute(ctx, `{"correlation_id": "c2"}`)
+	if err != nil || !strings.Contains(out, "❌ failed") || !strings.Contains(out, "wrong file") {
+		t.Errorf("Execute(correlation_id) = %q, %v", out, err)
+	}
+
+	out, err = tool.Execute(ctx, `{"message_id": "999"}`)
+	if err != nil || !strings.Contains(out, "No send status") {
+		t.Errorf("Execute(unknown message) = %q, %v", out, err)
+	}
+
+	out, err = tool.Execute(ctx, `{"limit": 1}`)
+	if err != nil || !strings.Contains(out, "c2") || strings.Contains(out, "c1") {
+		t.Errorf("Execute(limit) = %q, %v", out, err)
+	}
+
+	if _, err := tool.Execute(context.Background(), `{}`); err == nil {
+		t.Error("Execute() without a session should fail")
+	}
+}
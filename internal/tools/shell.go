@@ -50,6 +50,59 @@ func (t *ShellExecTool) Name() string {
 	return "shell_exec"
 }
 
+// Destructive reports whether a call runs a command that deletes or overwrites data.
+func (t *ShellExecTool) Destructive(args string) bool {
+	var shellArgs ShellExecArgs
+	if err := parseJSON(args, &shellArgs); err != nil {
+		return false
+	}
+	return t.validator.IsDestructive(strings.TrimSpace(shellArgs.Command))
+}
+
+// DryRun reports the command a call would run, with secrets masked.
+func (t *ShellExecTool) DryRun(ctx context.Context, args string) (string, bool, error) {
+	var shellArgs ShellExecArgs
+	if err := parseJSON(args, &shellArgs); err != nil {
+		return "", true, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	command := strings.TrimSpace(shellArgs.Command)
+	if command == "" {
+		return "", true, fmt.Errorf("command is required")
+	}
+	if !t.cfg.Tools.Shell.Enabled {
+		return "", true, fmt.Errorf("shell_exec tool is disabled in configuration")
+	}
+
+	resolvedCommand := t.resolveSecrets(ctx, command)
+	note := ""
+	if err := t.validator.Validate(resolvedCommand); err != nil {
+		if !strings.Contains(err.Error(), "# CONFIRM_REQUIRED:") {
+			return "", true, fmt.Errorf("command validation failed: %w", err)
+		}
+		if !Approved(ctx) {
+			note = " (needs the user's confirmation)"
+		}
+	}
+	return fmt.Sprintf("Would run%s in %s:\n%s", note, t.cfg.Workspace.Path, t.maskSecrets(resolvedCommand)), true, nil
+}
+
+// ConfigSection returns the configuration table of the tool ([tools.shell]).
+func (t *ShellExecTool) ConfigSection() string {
+	return "shell"
+}
+
+// ConfigSchema returns the keys of [tools.shell].
+func (t *ShellExecTool) ConfigSchema() []ConfigField {
+	return []ConfigField{
+		{Key: "enabled", Type: ConfigBool},
+		{Key: "allowed_commands", Type: ConfigStringList},
+		{Key: "deny_commands", Type: ConfigStringList},
+		{Key: "ask_commands", Type: ConfigStringList},
+		{Key: "timeout_seconds", Type: ConfigInt},
+		{Key: "shell", Type: ConfigString},
+	}
+}
+
 // Description returns a description of what the tool does.
 func (t *ShellExecTool) Description() string {
 	return "Execute shell commands with security restrictions (whitelist, timeout, logging)."
@@ -69,16 +122,10 @@ func (t *ShellExecTool) Parameters() map[string]any {
 	}
 }
 
-// Execute executes a shell command.
-// args is a JSON-encoded string containing the tool's input parameters.
-func (t *ShellExecTool) Execute(args string) (string, error) {
-	return t.ExecuteWithContext(context.Background(), args)
-}
-
-// ExecuteWithContext executes a shell command with context support.
+// Execute executes a shell command with context support.
 // The context is used for cancellation and timeouts.
 // It also resolves secret references in the command.
-func (t *ShellExecTool) ExecuteWithContext(ctx context.Context, args string) (string, error) {
+func (t *ShellExecTool) Execute(ctx context.Context, args string) (string, error) {
 	// Parse arguments
 	var shellArgs ShellExecArgs
 	if err := parseJSON(args, &shellArgs); err != nil {
@@ -103,11 +150,14 @@ func (t *ShellExecTool) ExecuteWithContext(ctx context.Context, args string) (st
 
 	// Validate command against deny/ask/allowed lists
 	if err := t.validator.Validate(resolvedCommand); err != nil {
-		// Check if confirmation is required
+		// Check if confirmation is required; approved commands run
 		if strings.Contains(err.Error(), "# CONFIRM_REQUIRED:") {
-			return err.Error(), nil
+			if !Approved(ctx) {
+				return err.Error(), nil
+			}
+		} else {
+			return "", fmt.Errorf("command validation failed: %w", err)
 		}
-		return "", fmt.Errorf("command validation failed: %w", err)
 	}
 
 	// Determine timeout
@@ -184,8 +234,9 @@ func getSessionID(ctx context.Context) string {
 
 // executeCommand executes a shell command and returns its combined stdout/stderr.
 func (t *ShellExecTool) executeCommand(ctx context.Context, command, workingDir string) (string, error) {
-	// Parse command and arguments safely (without shell interpretation)
-	cmdName, args, err := parseCommandArgs(command)
+	// Parse command and arguments safely (shell interpretation only with
+	// an explicit shell, e.g. cmd on Windows)
+	cmdName, args, err := buildCommand(t.cfg.Tools.Shell.Shell, command)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse command: %w", err)
 	}
@@ -196,9 +247,9 @@ func (t *ShellExecTool) executeCommand(ctx context.Context, command, workingDir
 	// Set working directory to workspace
 	cmd.Dir = workingDir
 
-	// Capture stdout and stderr combined
+	// Capture stdout and stderr combined, reporting stdout lines as progress
 	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
+	cmd.Stdout = NewProgressWriter(ctx, &stdout)
 	cmd.Stderr = &stderr
 
 	// Run command
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Shells that run shell_exec and process commands (tools.shell.shell).
+const (
+	// ShellDirect runs the command directly, without a shell interpreter
+	ShellDirect = "direct"
+	// ShellCmd runs the command with cmd.exe (Windows)
+	ShellCmd = "cmd"
+	// ShellPowerShell runs the command with Windows PowerShell
+	ShellPowerShell = "powershell"
+	// ShellPwsh runs the command with PowerShell 7+
+	ShellPwsh = "pwsh"
+)
+
+// DefaultShell returns the shell used when tools.shell.shell is empty:
+// cmd on Windows, where commands like dir and type are shell built-ins,
+// and direct execution elsewhere.
+func DefaultShell() string {
+	return defaultShellFor(runtime.GOOS)
+}
+
+// defaultShellFor returns the default shell of an operating system.
+func defaultShellFor(goos string) string {
+	if goos == "windows" {
+		return ShellCmd
+	}
+	return ShellDirect
+}
+
+// buildCommand returns the program and arguments that run a validated
+// command with the given shell. Direct execution parses the command without
+// shell interpretation; cmd and PowerShell get the command as a single
+// argument, so Windows paths with backslashes are passed unchanged.
+//
+// The shell validator blocks chaining and redirection; buildCommand also
+// rejects the expansion syntax of the Windows shells (%VAR% and ^ escapes in
+// cmd, $variables in PowerShell), which would expose environment variables.
+func buildCommand(shell, command string) (string, []string, error) {
+	if shell == "" {
+		shell = DefaultShell()
+	}
+
+	switch shell {
+	case ShellDirect:
+		return parseCommandArgs(command)
+	case ShellCmd:
+		if strings.ContainsAny(command, "%^\r\n") {
+			return "", nil, fmt.Errorf("cmd expansion characters (%%, ^, line breaks) are not allowed")
+		}
+		return "cmd", []string{"/D", "/S", "/C", command}, nil
+	case ShellPowerShell, ShellPwsh:
+		if strings.ContainsAny(command, "$@\r\n") {
+			return "", nil, fmt.Errorf("PowerShell expansion characters ($, @, line breaks) are not allowed")
+		}
+		return shell, []string{"-NoProfile", "-NonInteractive", "-Command", command}, nil
+	default:
+		return "", nil, fmt.Errorf("unknown shell: %s", shell)
+	}
+}
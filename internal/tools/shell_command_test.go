@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildCommand(t *testing.T) {
+	tests := []struct {
+		shell    string
+		command  string
+		wantName string
+		wantArgs []string
+		wantErr  string
+	}{
+		{ShellDirect, `ls -la "my dir"`, "ls", []string{"-la", "my dir"}, ""},
+		{ShellCmd, `type C:\work\notes.txt`, "cmd", []string{"/D", "/S", "/C", `type C:\work\notes.txt`}, ""},
+		{ShellPowerShell, "Get-ChildItem", "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", "Get-ChildItem"}, ""},
+		{ShellPwsh, "Get-Date", "pwsh", []string{"-NoProfile", "-NonInteractive", "-Command", "Get-Date"}, ""},
+		{ShellCmd, "echo %API_KEY%", "", nil, "cmd expansion"},
+		{ShellPwsh, "echo $env:API_KEY", "", nil, "PowerShell expansion"},
+		{"bash", "ls", "", nil, "unknown shell"},
+	}
+
+	for _, tt := range tests {
+		name, args, err := buildCommand(tt.shell, tt.command)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("buildCommand(%q, %q) error = %v, want %q", tt.shell, tt.command, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || name != tt.wantName || !slices.Equal(args, tt.wantArgs) {
+			t.Errorf("buildCommand(%q, %q) = %q %q, %v", tt.shell, tt.command, name, args, err)
+		}
+	}
+}
+
+func TestDefaultShellFor(t *testing.T) {
+	if got := defaultShellFor("windows"); got != ShellCmd {
+		t.Errorf("defaultShellFor(windows) = %q, want %q", got, ShellCmd)
+	}
+	if got := defaultShellFor("linux"); got != ShellDirect {
+		t.Errorf("defaultShellFor(linux) = %q, want %q", got, ShellDirect)
+	}
+}
@@ -2,6 +2,8 @@ package tools
 
 import (
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/aatumaykin/nexbot/internal/config"
@@ -88,6 +90,44 @@ func (v *ShellValidator) Validate(command string) error {
 	return nil
 }
 
+// destructiveCommands are commands that delete or overwrite data
+var destructiveCommands = map[string]bool{
+	"rm": true, "rmdir": true, "unlink": true, "shred": true, "dd": true,
+	"truncate": true, "mv": true, "wipefs": true, "mkfs": true,
+	// Windows
+	"del": true, "erase": true, "rd": true, "remove-item": true,
+}
+
+// IsDestructive reports whether a command deletes or overwrites data: a
+// known destructive command (rm, mv, dd, mkfs.*), find -delete, git clean,
+// git reset --hard, or a command matching ask_commands.
+func (v *ShellValidator) IsDestructive(command string) bool {
+	for _, askPattern := range v.askCommands {
+		if v.MatchPattern(command, askPattern) {
+			return true
+		}
+	}
+
+	cmdName, args, err := parseCommandArgsForValidation(command)
+	if err != nil {
+		return false
+	}
+	name := strings.ToLower(filepath.Base(cmdName))
+	name = strings.TrimSuffix(name, ".exe")
+	if base, _, ok := strings.Cut(name, "."); ok && base == "mkfs" {
+		name = base
+	}
+	switch {
+	case destructiveCommands[name]:
+		return true
+	case name == "find":
+		return slices.Contains(args, "-delete") || (slices.Contains(args, "-exec") && slices.Contains(args, "rm"))
+	case name == "git" && len(args) > 0:
+		return args[0] == "clean" || (args[0] == "reset" && slices.Contains(args, "--hard"))
+	}
+	return false
+}
+
 // MatchPattern checks if a command matches a given pattern.
 // Pattern types:
 //   - Exact match: "echo hello" matches "echo hello"
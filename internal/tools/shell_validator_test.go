@@ -579,3 +579,27 @@ func TestShellValidator_Validate_ShellInjection(t *testing.T) {
 		})
 	}
 }
+
+func TestShellValidator_IsDestructive(t *testing.T) {
+	validator := NewShellValidator(nil, []string{"docker *"}, nil)
+
+	tests := map[string]bool{
+		"rm -rf build":               true,
+		"/bin/rm old.log":            true,
+		"mv a.txt b.txt":             true,
+		"mkfs.ext4 /dev/sdb1":        true,
+		"find . -name *.tmp -delete": true,
+		"git reset --hard HEAD~1":    true,
+		"git clean -fd":              true,
+		"docker rm web":              true,
+		"ls -la":                     false,
+		"git reset HEAD~1":           false,
+		"find . -name *.go":          false,
+		"cat rm.txt":                 false,
+	}
+	for command, want := range tests {
+		if got := validator.IsDestructive(command); got != want {
+			t.Errorf("IsDestructive(%q) = %v, want %v", command, got, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+//go:build windows
+
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+)
+
+// TestShellExecTool_Windows runs cmd built-ins with the default shell.
+func TestShellExecTool_Windows(t *testing.T) {
+	cfg := &config.Config{
+		Workspace: config.WorkspaceConfig{Path: t.TempDir()},
+		Tools: config.ToolsConfig{
+			Shell: config.ShellToolConfig{
+				Enabled:         true,
+				AllowedCommands: []string{"echo", "dir"},
+				TimeoutSeconds:  10,
+			},
+		},
+	}
+	tool := NewShellExecTool(cfg, nil)
+
+	result, err := tool.Execute(context.Background(), `{"command": "echo Hello from cmd"}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !contains(result, "Hello from cmd") || !contains(result, "Exit code: 0") {
+		t.Errorf("echo result = %s", result)
+	}
+
+	result, err = tool.Execute(context.Background(), `{"command": "dir /B"}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !contains(result, "Exit code: 0") {
+		t.Errorf("dir result = %s", result)
+	}
+}
@@ -0,0 +1,34 @@
+package tools
+
+import "context"
+
+// SnapshotTool is an optional interface of tools that change workspace
+// files. Before such a call runs, the paths it changes are saved, so the
+// user can undo the agent's turn with /rollback.
+type SnapshotTool interface {
+	Tool
+
+	// AffectedPaths returns the absolute paths a call with the given
+	// arguments writes or deletes. Invalid arguments return nil.
+	AffectedPaths(args string) []string
+}
+
+// Snapshotter saves the state of paths before a tool changes them
+// (implemented by snapshot.Turn).
+type Snapshotter interface {
+	Save(paths ...string) error
+}
+
+// snapshotterKey is the context key of the turn's snapshotter
+type snapshotterKey struct{}
+
+// WithSnapshotter makes the tool calls run with ctx save the paths they change.
+func WithSnapshotter(ctx context.Context, snapshotter Snapshotter) context.Context {
+	return context.WithValue(ctx, snapshotterKey{}, snapshotter)
+}
+
+// snapshotterFrom returns the snapshotter of ctx, nil if there is none.
+func snapshotterFrom(ctx context.Context) Snapshotter {
+	snapshotter, _ := ctx.Value(snapshotterKey{}).(Snapshotter)
+	return snapshotter
+}
@@ -10,7 +10,7 @@ import (
 // This avoids circular import with the subagent package.
 type SpawnFunc func(ctx context.Context, parentSession string, task string) (string, error)
 
-// SpawnTool implements the Tool and ContextualTool interfaces for spawning subagents.
+// SpawnTool implements the Tool interface for spawning subagents.
 // It creates isolated agent instances with their own sessions for parallel task execution.
 type SpawnTool struct {
 	spawnFunc SpawnFunc
@@ -62,17 +62,9 @@ func (t *SpawnTool) Parameters() map[string]any {
 	}
 }
 
-// Execute runs the tool with the provided arguments.
-// args is a JSON-encoded string containing the tool's input parameters.
-// This method is part of the Tool interface and delegates to ExecuteWithContext.
-func (t *SpawnTool) Execute(args string) (string, error) {
-	return t.ExecuteWithContext(context.Background(), args)
-}
-
-// ExecuteWithContext runs the tool with the provided arguments and execution context.
+// Execute runs the tool with the provided arguments and execution context.
 // The context can be used for cancellation, deadlines, and timeout handling.
-// This is the preferred method for spawning subagents as it provides better control.
-func (t *SpawnTool) ExecuteWithContext(ctx context.Context, args string) (string, error) {
+func (t *SpawnTool) Execute(ctx context.Context, args string) (string, error) {
 	// Parse arguments
 	var spawnArgs SpawnArgs
 	if err := parseJSON(args, &spawnArgs); err != nil {
@@ -109,6 +101,3 @@ func (t *SpawnTool) ExecuteWithContext(ctx context.Context, args string) (string
 
 // Ensure SpawnTool implements Tool interface
 var _ Tool = (*SpawnTool)(nil)
-
-// Ensure SpawnTool implements ContextualTool interface
-var _ ContextualTool = (*SpawnTool)(nil)
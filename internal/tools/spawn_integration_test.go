@@ -157,8 +157,9 @@ func TestSpawnToolIntegrationErrorHandling(t *testing.T) {
 
 	result, err := ExecuteToolCall(registry, toolCall)
 	require.NoError(t, err)
-	assert.NotNil(t, result.Error)
-	assert.Contains(t, result.Error.Message, "parse")
+	require.NotNil(t, result.Error)
+	assert.Equal(t, ErrCodeInvalidArgs, result.Error.Code)
+	assert.Contains(t, result.Error.Message, "not valid JSON")
 
 	// Test missing task
 	toolCall.Arguments = `{}`
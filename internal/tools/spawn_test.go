@@ -114,7 +114,7 @@ func TestSpawnTool_Execute_Success(t *testing.T) {
 	tool := NewSpawnTool(mock.Spawn)
 
 	args := `{"task": "Test task description"}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -125,7 +125,7 @@ func TestSpawnTool_Execute_Success(t *testing.T) {
 	}
 }
 
-func TestSpawnTool_ExecuteWithContext_Success(t *testing.T) {
+func TestSpawnTool_Execute_WithContext(t *testing.T) {
 	mock := &mockSpawnFunc{
 		result: "Another task completed",
 	}
@@ -134,7 +134,7 @@ func TestSpawnTool_ExecuteWithContext_Success(t *testing.T) {
 	ctx := context.Background()
 
 	args := `{"task": "Another test task"}`
-	result, err := tool.ExecuteWithContext(ctx, args)
+	result, err := tool.Execute(ctx, args)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -153,7 +153,7 @@ func TestSpawnTool_Execute_WithTimeout(t *testing.T) {
 	tool := NewSpawnTool(mock.Spawn)
 
 	args := `{"task": "Test task with timeout", "timeout_seconds": 60}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -169,7 +169,7 @@ func TestSpawnTool_Execute_MissingTask(t *testing.T) {
 	tool := NewSpawnTool(mock.Spawn)
 
 	args := `{}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected error for missing task")
 	}
@@ -185,7 +185,7 @@ func TestSpawnTool_Execute_InvalidTimeout(t *testing.T) {
 
 	// Test negative timeout
 	args := `{"task": "Test", "timeout_seconds": -5}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected error for negative timeout")
 	}
@@ -196,7 +196,7 @@ func TestSpawnTool_Execute_InvalidTimeout(t *testing.T) {
 
 	// Test zero timeout
 	args = `{"task": "Test", "timeout_seconds": 0}`
-	_, err = tool.Execute(args)
+	_, err = tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected error for zero timeout")
 	}
@@ -211,7 +211,7 @@ func TestSpawnTool_Execute_InvalidJSON(t *testing.T) {
 	tool := NewSpawnTool(mock.Spawn)
 
 	args := `{invalid json}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected error for invalid JSON")
 	}
@@ -228,7 +228,7 @@ func TestSpawnTool_Execute_SpawnError(t *testing.T) {
 	tool := NewSpawnTool(mock.Spawn)
 
 	args := `{"task": "Test task"}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected error for spawn failure")
 	}
@@ -238,14 +238,6 @@ func TestSpawnTool_Execute_SpawnError(t *testing.T) {
 	}
 }
 
-func TestSpawnTool_ContextualToolInterface(t *testing.T) {
-	mock := &mockSpawnFunc{}
-	tool := NewSpawnTool(mock.Spawn)
-
-	// Verify tool implements ContextualTool
-	var _ ContextualTool = tool
-}
-
 func TestSpawnTool_ToolInterface(t *testing.T) {
 	mock := &mockSpawnFunc{}
 	tool := NewSpawnTool(mock.Spawn)
@@ -379,7 +371,7 @@ func TestSpawnTool_RegistryIntegration(t *testing.T) {
 	}
 }
 
-func TestSpawnTool_ExecuteWithContext_Cancellation(t *testing.T) {
+func TestSpawnTool_Execute_Cancellation(t *testing.T) {
 	mock := &mockSpawnFunc{
 		checkCtxCanceled: true,
 	}
@@ -391,7 +383,7 @@ func TestSpawnTool_ExecuteWithContext_Cancellation(t *testing.T) {
 	cancel() // Cancel immediately
 
 	args := `{"task": "Test task"}`
-	_, err := tool.ExecuteWithContext(ctx, args)
+	_, err := tool.Execute(ctx, args)
 	if err == nil {
 		t.Error("Expected error for cancelled context")
 	}
@@ -406,7 +398,7 @@ func TestSpawnTool_Execute_EmptyTask(t *testing.T) {
 	tool := NewSpawnTool(mock.Spawn)
 
 	args := `{"task": ""}`
-	_, err := tool.Execute(args)
+	_, err := tool.Execute(context.Background(), args)
 	if err == nil {
 		t.Error("Expected error for empty task")
 	}
@@ -426,7 +418,7 @@ func TestSpawnTool_Execute_ContextTimeout(t *testing.T) {
 	tool := NewSpawnTool(mock.Spawn)
 
 	args := `{"task": "Test task", "timeout_seconds": 300}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/structured"
+)
+
+// StructuredOutputTool implements the Tool interface for extracting
+// machine-readable data: the input is turned into JSON that is validated
+// against a JSON Schema, with automatic retries on invalid output.
+type StructuredOutputTool struct {
+	generator *structured.Generator
+}
+
+// StructuredOutputArgs represents the arguments for the structured_output tool.
+type StructuredOutputArgs struct {
+	Prompt string         `json:"prompt"` // What to produce
+	Input  string         `json:"input"`  // Data to process (text, tool output)
+	Schema map[string]any `json:"schema"` // JSON Schema of the result
+	Name   string         `json:"name"`   // Name of the schema
+}
+
+// NewStructuredOutputTool creates a new StructuredOutputTool instance.
+func NewStructuredOutputTool(generator *structured.Generator) *StructuredOutputTool {
+	return &StructuredOutputTool{generator: generator}
+}
+
+// Name returns the tool name.
+func (t *StructuredOutputTool) Name() string {
+	return "structured_output"
+}
+
+// ReadOnly reports that the tool only reads.
+func (t *StructuredOutputTool) ReadOnly() bool {
+	return true
+}
+
+// Description returns a description of what the tool does.
+func (t *StructuredOutputTool) Description() string {
+	return "Produces JSON that is guaranteed to match a JSON Schema: extracts fields from text, converts tool output into records, " +
+		"or answers in a machine-readable form. The result is validated and regenerated when invalid."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *StructuredOutputTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"prompt": map[string]any{
+				"type":        "string",
+				"description": "What to produce, e.g. 'Extract the invoice number, date and total'.",
+			},
+			"input": map[string]any{
+				"type":        "string",
+				"description": "Text or data to process (optional).",
+			},
+			"schema": map[string]any{
+				"type":        "object",
+				"description": "JSON Schema of the result (type, properties, required, items, enum, ...). Without a schema any JSON is accepted.",
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"description": "Short name of the schema, e.g. 'invoice'.",
+			},
+		},
+		"required": []string{"prompt"},
+	}
+}
+
+// Execute executes the structured_output tool and returns the validated JSON.
+func (t *StructuredOutputTool) Execute(ctx context.Context, args string) (string, error) {
+	var params StructuredOutputArgs
+	if err := parseJSON(args, &params); err != nil {
+		return "", fmt.Errorf("failed to parse structured_output arguments: %w", err)
+	}
+	if strings.TrimSpace(params.Prompt) == "" {
+		return "", fmt.Errorf("prompt is required")
+	}
+
+	var messages []llm.Message
+	if params.Input != "" {
+		messages = append(messages, llm.Message{Role: llm.RoleUser, Content: "Input:\n" + params.Input})
+	}
+
+	result, err := t.generator.Generate(ctx, structured.Request{
+		Prompt:   params.Prompt,
+		Messages: messages,
+		Schema:   params.Schema,
+		Name:     params.Name,
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.JSON, nil
+}
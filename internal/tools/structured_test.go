@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/llm"
+	"github.com/aatumaykin/nexbot/internal/structured"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredOutputTool_Execute(t *testing.T) {
+	provider := llm.NewFixturesProvider([]string{
+		`{"number": "INV-7"}`,
+		`{"number": "INV-7", "total": 120.5}`,
+	})
+	tool := NewStructuredOutputTool(structured.New(provider, structured.Config{}))
+
+	result, err := tool.Execute(context.Background(), `{
+		"prompt": "Extract the invoice number and total",
+		"input": "Invoice INV-7, total due: 120.50 EUR",
+		"schema": {"type": "object", "properties": {"number": {"type": "string"}, "total": {"type": "number"}}, "required": ["number", "total"]}
+	}`)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"number": "INV-7", "total": 120.5}`, result)
+	assert.Equal(t, 2, provider.GetCallCount())
+}
+
+func TestStructuredOutputTool_RequiresPrompt(t *testing.T) {
+	tool := NewStructuredOutputTool(structured.New(llm.NewFixedProvider("{}"), structured.Config{}))
+
+	_, err := tool.Execute(context.Background(), `{"prompt": " "}`)
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -27,6 +28,11 @@ func (t *SystemTimeTool) Name() string {
 	return "system_time"
 }
 
+// ReadOnly reports that the tool only reads.
+func (t *SystemTimeTool) ReadOnly() bool {
+	return true
+}
+
 // Description returns a description of what the tool does.
 func (t *SystemTimeTool) Description() string {
 	return "Возвращает текущее системное время и дату"
@@ -47,7 +53,7 @@ func (t *SystemTimeTool) ToSchema() map[string]any {
 }
 
 // Execute executes the system time tool.
-func (t *SystemTimeTool) Execute(args string) (string, error) {
+func (t *SystemTimeTool) Execute(ctx context.Context, args string) (string, error) {
 	now := time.Now().Local()
 
 	result := fmt.Sprintf("RFC3339: %s\n", now.Format(time.RFC3339))
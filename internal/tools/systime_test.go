@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"regexp"
 	"strings"
 	"testing"
@@ -66,7 +67,7 @@ func TestSystemTimeToolParameters(t *testing.T) {
 func TestSystemTimeToolExecute(t *testing.T) {
 	tool := setupSystemTimeTool(t)
 
-	result, err := tool.Execute("")
+	result, err := tool.Execute(context.Background(), "")
 	assert.NoError(t, err, "Execute should not return error")
 	assert.NotEmpty(t, result, "Result should not be empty")
 
@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/msgtemplate"
+)
+
+// MessageTemplates renders named outbound message templates (implemented by msgtemplate.Registry).
+type MessageTemplates interface {
+	Render(name string, data map[string]any) (msgtemplate.Message, error)
+	List() []msgtemplate.Info
+}
+
+// templateProperties returns the "template" and "data" parameters of tools
+// that can send templated messages, listing the templates and their fields.
+func templateProperties(templates MessageTemplates) map[string]any {
+	list := templates.List()
+	names := make([]string, len(list))
+	var b strings.Builder
+	b.WriteString("Name of a message template to send instead of 'message', for notifications that must look the same every time. Templates:")
+	for i, info := range list {
+		names[i] = info.Name
+		fmt.Fprintf(&b, " %s (fields: %s", info.Name, strings.Join(info.Fields, ", "))
+		if len(info.Optional) > 0 {
+			fmt.Fprintf(&b, "; optional: %s", strings.Join(info.Optional, ", "))
+		}
+		b.WriteString(")")
+		if info.Description != "" {
+			fmt.Fprintf(&b, " — %s", info.Description)
+		}
+		b.WriteString(";")
+	}
+
+	return map[string]any{
+		"template": map[string]any{
+			"type":        "string",
+			"description": strings.TrimSuffix(b.String(), ";") + ".",
+			"enum":        names,
+		},
+		"data": map[string]any{
+			"type":        "object",
+			"description": "Values of the template fields. Example: {\"template\": \"build_failed\", \"data\": {\"build\": \"#42\", \"error\": \"tests failed\"}}",
+		},
+	}
+}
+
+// renderTemplate renders a template for a tool that has templates enabled.
+func renderTemplate(templates MessageTemplates, name string, data map[string]any) (msgtemplate.Message, error) {
+	if templates == nil {
+		return msgtemplate.Message{}, fmt.Errorf("message templates are not configured")
+	}
+	return templates.Render(name, data)
+}
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ConfigType is the TOML type of a tool configuration key.
+type ConfigType string
+
+// Configuration key types
+const (
+	ConfigString     ConfigType = "string"
+	ConfigInt        ConfigType = "int"
+	ConfigBool       ConfigType = "bool"
+	ConfigStringList ConfigType = "[]string"
+)
+
+// ConfigField describes a key of a tool configuration table.
+type ConfigField struct {
+	Key      string
+	Type     ConfigType
+	Required bool // The key must be set when the tool is enabled
+}
+
+// ToolConfig is an optional interface for tools configured by a
+// [tools.<section>] table. The table is checked against the schema at
+// startup, so a typo or a missing key fails the start instead of the
+// first call of the tool.
+type ToolConfig interface {
+	Tool
+
+	// ConfigSection returns the name of the configuration table, e.g. "shell"
+	// for [tools.shell]. Tools sharing a table return the same section.
+	ConfigSection() string
+
+	// ConfigSchema returns the keys of the configuration table.
+	ConfigSchema() []ConfigField
+}
+
+// ValidateConfig checks a tool configuration table against a schema:
+// unknown keys, missing required keys and values of the wrong type are
+// reported. section names the table in errors.
+func ValidateConfig(section string, schema []ConfigField, table map[string]any) []error {
+	var errors []error
+
+	fields := make(map[string]ConfigField, len(schema))
+	for _, field := range schema {
+		fields[field.Key] = field
+		value, ok := table[field.Key]
+		if !ok {
+			if field.Required {
+				errors = append(errors, fmt.Errorf("tools.%s.%s is required", section, field.Key))
+			}
+			continue
+		}
+		if !hasConfigType(value, field.Type) {
+			errors = append(errors, fmt.Errorf("tools.%s.%s must be %s (got: %T)", section, field.Key, field.Type, value))
+		}
+	}
+
+	// Unknown keys are reported in a stable order
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := fields[key]; !ok {
+			errors = append(errors, fmt.Errorf("tools.%s: unknown key %q", section, key))
+		}
+	}
+	return errors
+}
+
+// hasConfigType reports whether a decoded TOML value has the given type.
+func hasConfigType(value any, typ ConfigType) bool {
+	switch typ {
+	case ConfigString:
+		_, ok := value.(string)
+		return ok
+	case ConfigInt:
+		_, ok := value.(int64)
+		return ok
+	case ConfigBool:
+		_, ok := value.(bool)
+		return ok
+	case ConfigStringList:
+		list, ok := value.([]any)
+		if !ok {
+			return false
+		}
+		for _, item := range list {
+			if _, ok := item.(string); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/config"
+)
+
+func TestValidateConfig(t *testing.T) {
+	schema := []ConfigField{
+		{Key: "enabled", Type: ConfigBool},
+		{Key: "endpoint", Type: ConfigString, Required: true},
+		{Key: "timeout_seconds", Type: ConfigInt},
+		{Key: "hosts", Type: ConfigStringList},
+	}
+
+	tests := []struct {
+		name  string
+		table map[string]any
+		want  []string
+	}{
+		{
+			name:  "valid",
+			table: map[string]any{"enabled": true, "endpoint": "http://localhost", "timeout_seconds": int64(5), "hosts": []any{"a", "b"}},
+		},
+		{
+			name:  "missing required key",
+			table: map[string]any{"enabled": true},
+			want:  []string{"tools.search.endpoint is required"},
+		},
+		{
+			name:  "unknown keys",
+			table: map[string]any{"endpoint": "x", "timeout_secs": int64(5), "enable": true},
+			want:  []string{`tools.search: unknown key "enable"`, `tools.search: unknown key "timeout_secs"`},
+		},
+		{
+			name:  "wrong types",
+			table: map[string]any{"endpoint": "x", "timeout_seconds": "5", "hosts": []any{"a", int64(1)}},
+			want:  []string{"tools.search.timeout_seconds must be int (got: string)", "tools.search.hosts must be []string"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateConfig("search", schema, tt.table)
+			if len(errs) != len(tt.want) {
+				t.Fatalf("ValidateConfig() = %v, want %d errors", errs, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("error %d = %q, want %q", i, errs[i], want)
+				}
+			}
+		})
+	}
+}
+
+// TestToolConfigSchemas_MatchConfig tests that the schemas of the shell and
+// process tools list exactly the keys of their config sections
+func TestToolConfigSchemas_MatchConfig(t *testing.T) {
+	cfg := &config.Config{}
+	tests := []struct {
+		tool    ToolConfig
+		section any
+	}{
+		{NewShellExecTool(cfg, nil), config.ShellToolConfig{}},
+		{NewProcessTool(nil, cfg, nil), config.ProcessToolConfig{}},
+	}
+
+	for _, tt := range tests {
+		var keys []string
+		for _, field := range tt.tool.ConfigSchema() {
+			keys = append(keys, field.Key)
+		}
+		if want := tomlKeys(tt.section); !reflect.DeepEqual(keys, want) {
+			t.Errorf("%s schema keys = %v, want %v", tt.tool.ConfigSection(), keys, want)
+		}
+	}
+}
+
+// tomlKeys returns the toml tags of a config struct
+func tomlKeys(section any) []string {
+	var keys []string
+	typ := reflect.TypeOf(section)
+	for i := 0; i < typ.NumField(); i++ {
+		keys = append(keys, typ.Field(i).Tag.Get("toml"))
+	}
+	return keys
+}
@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"testing"
 
 	"github.com/aatumaykin/nexbot/internal/config"
@@ -127,7 +128,7 @@ func TestShellExecTool_Execute_Disabled(t *testing.T) {
 
 	tool := NewShellExecTool(cfg, log)
 	args := `{"command": "echo test"}`
-	_, err = tool.Execute(args)
+	_, err = tool.Execute(context.Background(), args)
 
 	if err == nil {
 		t.Error("Expected error when shell tool is disabled")
@@ -156,7 +157,7 @@ func TestShellExecTool_Execute_NotWhitelisted(t *testing.T) {
 
 	tool := NewShellExecTool(cfg, log)
 	args := `{"command": "rm -rf /"}`
-	_, err = tool.Execute(args)
+	_, err = tool.Execute(context.Background(), args)
 
 	if err == nil {
 		t.Error("Expected error for non-whitelisted command")
@@ -185,7 +186,7 @@ func TestShellExecTool_Execute_Echo(t *testing.T) {
 
 	tool := NewShellExecTool(cfg, log)
 	args := `{"command": "echo 'Hello, World!'"}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -221,7 +222,7 @@ func TestShellExecTool_Execute_Timeout(t *testing.T) {
 
 	// Just verify that the tool can be called
 	// Actual timeout behavior may vary by system
-	_, err = tool.Execute(args)
+	_, err = tool.Execute(context.Background(), args)
 	// We expect some kind of error (timeout or killed), but don't enforce it
 	_ = err
 }
@@ -245,7 +246,7 @@ func TestShellExecTool_Execute_FailedCommand(t *testing.T) {
 	tool := NewShellExecTool(cfg, log)
 	args := `{"command": "sh -c 'exit 1'"}`
 
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 
 	// Command execution should "succeed" (no error from tool.Execute)
 	// but result should contain error information
@@ -277,7 +278,7 @@ func TestShellExecTool_Execute_EmptyWhitelist(t *testing.T) {
 
 	tool := NewShellExecTool(cfg, log)
 	args := `{"command": "echo test"}`
-	_, err = tool.Execute(args)
+	_, err = tool.Execute(context.Background(), args)
 
 	// With new logic: all lists empty = fail-open (all commands allowed)
 	if err != nil {
@@ -304,7 +305,7 @@ func TestShellExecTool_Execute_DenyCommand(t *testing.T) {
 
 	tool := NewShellExecTool(cfg, log)
 	args := `{"command": "rm -rf /tmp/test"}`
-	_, err = tool.Execute(args)
+	_, err = tool.Execute(context.Background(), args)
 
 	if err == nil {
 		t.Error("Expected error for denied command")
@@ -334,7 +335,7 @@ func TestShellExecTool_Execute_AskCommand(t *testing.T) {
 
 	tool := NewShellExecTool(cfg, log)
 	args := `{"command": "git commit -m test"}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -365,7 +366,7 @@ func TestShellExecTool_Execute_Priority(t *testing.T) {
 
 	tool := NewShellExecTool(cfg, log)
 	args := `{"command": "rm -rf /tmp/test"}`
-	_, err = tool.Execute(args)
+	_, err = tool.Execute(context.Background(), args)
 
 	if err == nil {
 		t.Error("Expected error (deny has priority)")
@@ -396,7 +397,7 @@ func TestShellExecTool_Execute_AllListsEmpty(t *testing.T) {
 
 	tool := NewShellExecTool(cfg, log)
 	args := `{"command": "echo test"}`
-	result, err := tool.Execute(args)
+	result, err := tool.Execute(context.Background(), args)
 
 	if err != nil {
 		t.Fatalf("Unexpected error (all lists empty = all allowed): %v", err)
@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/watcher"
+)
+
+// WatchManager manages file watches (implemented by watcher.Watcher).
+type WatchManager interface {
+	Add(rule watcher.Rule) (watcher.Rule, error)
+	Remove(id string) error
+	List() []watcher.Rule
+}
+
+// WatchTool implements the Tool interface for file watch management.
+// It lets the agent subscribe a session to changes in workspace files.
+type WatchTool struct {
+	manager WatchManager
+	logger  *logger.Logger
+}
+
+// WatchArgs represents the arguments for the watch tool.
+type WatchArgs struct {
+	Action    string   `json:"action"`     // Action: "add", "remove", "list"
+	Path      string   `json:"path"`       // File or directory to watch
+	Patterns  []string `json:"patterns"`   // File name globs for directory watches
+	Match     string   `json:"match"`      // Regex for new lines
+	SessionID string   `json:"session_id"` // Session to notify
+	WatchID   string   `json:"watch_id"`   // Watch ID for removal
+}
+
+// NewWatchTool creates a new WatchTool instance.
+func NewWatchTool(manager WatchManager, logger *logger.Logger) *WatchTool {
+	return &WatchTool{
+		manager: manager,
+		logger:  logger,
+	}
+}
+
+// Name returns the tool name.
+func (t *WatchTool) Name() string {
+	return "watch"
+}
+
+// Description returns a description of what the tool does.
+func (t *WatchTool) Description() string {
+	return "Watches files in the workspace and notifies the session when they change. Use it for requests like 'tell me when deploy.log gets a new ERROR line'. Supports adding, listing and removing watches."
+}
+
+// Parameters returns the JSON Schema for the tool's parameters.
+func (t *WatchTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"description": "Action to perform: 'add' to create a watch, 'list' to show all watches, 'remove' to delete a watch.",
+				"enum":        []string{"add", "remove", "list"},
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "File or directory to watch (relative to workspace or absolute). Required for 'add' action. Example: 'logs/deploy.log'.",
+			},
+			"patterns": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "File name glob patterns when watching a directory (e.g. ['*.log']). Empty means all files.",
+			},
+			"match": map[string]any{
+				"type":        "string",
+				"description": "Regular expression applied to newly appended lines (e.g. 'ERROR'). If empty, any change is reported.",
+			},
+			"session_id": map[string]any{
+				"type":        "string",
+				"description": "Session ID to notify. Format: 'channel:chat_id' (e.g., 'telegram:35052705'). Required for 'add' action.",
+			},
+			"watch_id": map[string]any{
+				"type":        "string",
+				"description": "Watch ID to remove. Required for 'remove' action.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+// Execute executes the watch tool.
+// args is a JSON-encoded string containing the tool's input parameters.
+func (t *WatchTool) Execute(ctx context.Context, args string) (string, error) {
+	var params WatchArgs
+	if err := json.Unmarshal([]byte(args), &params); err != nil {
+		return "", fmt.Errorf("failed to parse watch arguments: %w", err)
+	}
+
+	switch params.Action {
+	case "add":
+		return t.add(params)
+	case "remove":
+		if params.WatchID == "" {
+			return "", fmt.Errorf("watch_id parameter is required for remove action")
+		}
+		if err := t.manager.Remove(params.WatchID); err != nil {
+			return "", fmt.Errorf("failed to remove watch: %w", err)
+		}
+		return fmt.Sprintf("Watch %s removed", params.WatchID), nil
+	case "list":
+		return t.list(), nil
+	default:
+		return "", fmt.Errorf("invalid action: %s. Valid actions: add, remove, list", params.Action)
+	}
+}
+
+// add creates a new watch.
+func (t *WatchTool) add(params WatchArgs) (string, error) {
+	if params.Path == "" {
+		return "", fmt.Errorf("path parameter is required for add action")
+	}
+	if params.SessionID == "" {
+		return "", fmt.Errorf("session_id parameter is required for add action")
+	}
+
+	rule, err := t.manager.Add(watcher.Rule{
+		Path:      params.Path,
+		Patterns:  params.Patterns,
+		Match:     params.Match,
+		SessionID: params.SessionID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to add watch: %w", err)
+	}
+
+	t.logger.Info("watch added via tool",
+		logger.Field{Key: "watch_id", Value: rule.ID},
+		logger.Field{Key: "path", Value: rule.Path})
+
+	return fmt.Sprintf("Watch created successfully.\nWatch ID: %s\nPath: %s\n%s", rule.ID, rule.Path, describeWatchFilter(rule)), nil
+}
+
+// list formats all watches.
+func (t *WatchTool) list() string {
+	rules := t.manager.List()
+	if len(rules) == 0 {
+		return "No watches configured."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Watches (%d):\n", len(rules))
+	for _, r := range rules {
+		fmt.Fprintf(&b, "\n- ID: %s\n  Path: %s\n  Session: %s\n  %s\n", r.ID, r.Path, r.SessionID, describeWatchFilter(r))
+		if r.Static {
+			b.WriteString("  Source: config (cannot be removed)\n")
+		}
+	}
+	return b.String()
+}
+
+// describeWatchFilter describes which changes a watch reports.
+func describeWatchFilter(r watcher.Rule) string {
+	var parts []string
+	if len(r.Patterns) > 0 {
+		parts = append(parts, fmt.Sprintf("Files: %s", strings.Join(r.Patterns, ", ")))
+	}
+	if r.Match != "" {
+		parts = append(parts, fmt.Sprintf("Reports new lines matching: %s", r.Match))
+	} else {
+		parts = append(parts, "Reports: any change")
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,165 @@
+package update
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// maxBinarySize limits the downloaded binary
+const maxBinarySize = 200 << 20
+
+// AssetName returns the name of the release binary for the current
+// platform, as built by "make release".
+func AssetName() string {
+	return fmt.Sprintf("nexbot-%s-%s", runtime.GOOS, runtime.GOARCH)
+}
+
+// Install downloads the binary of release for the current platform, checks
+// it against the published SHA-256 checksum and replaces the running binary.
+// Returns the path of the replaced binary. The running process is not
+// restarted.
+func (c *Checker) Install(ctx context.Context, release *Release) (string, error) {
+	name := AssetName()
+	var binary, checksum *Asset
+	for i := range release.Assets {
+		switch release.Assets[i].Name {
+		case name:
+			binary = &release.Assets[i]
+		case name + ".sha256":
+			checksum = &release.Assets[i]
+		}
+	}
+	if binary == nil {
+		return "", fmt.Errorf("release %s has no binary %s", release.Tag, name)
+	}
+	if checksum == nil {
+		return "", fmt.Errorf("release %s has no checksum for %s", release.Tag, name)
+	}
+
+	want, err := c.fetchChecksum(ctx, checksum.URL)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := c.executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the running binary: %w", err)
+	}
+
+	tmp := path + ".new"
+	if err := c.download(ctx, binary.URL, tmp, want); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := replace(path, tmp); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return path, nil
+}
+
+// fetchChecksum downloads a checksum file ("<hex>  <file>") and returns the hash.
+func (c *Checker) fetchChecksum(ctx context.Context, url string) (string, error) {
+	body, err := c.get(ctx, url)
+	if err != nil {
+		return "", fmt.Errorf("failed to download checksum: %w", err)
+	}
+	defer body.Close()
+
+	line, err := bufio.NewReader(io.LimitReader(body, 1024)).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read checksum: %w", err)
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("checksum file is empty")
+	}
+	sum := strings.ToLower(fields[0])
+	if _, err := hex.DecodeString(sum); err != nil || len(sum) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid checksum %q", fields[0])
+	}
+	return sum, nil
+}
+
+// download saves url to path and checks its SHA-256 hash.
+func (c *Checker) download(ctx context.Context, url, path, want string) error {
+	body, err := c.get(ctx, url)
+	if err != nil {
+		return fmt.Errorf("failed to download binary: %w", err)
+	}
+	defer body.Close()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(file, hash), io.LimitReader(body, maxBinarySize+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save binary: %w", err)
+	}
+	if n > maxBinarySize {
+		return fmt.Errorf("binary is larger than %d MB", maxBinarySize>>20)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf("checksum mismatch: got %s, want %s", got, want)
+	}
+	return nil
+}
+
+// get requests url and returns the body of a successful response.
+func (c *Checker) get(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "nexbot/"+c.cfg.CurrentVersion)
+	resp, err := c.cfg.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// replace moves the new binary over the running one. The running binary
+// is moved aside first, since Windows does not allow overwriting it.
+func replace(path, tmp string) error {
+	old := path + ".old"
+	os.Remove(old)
+	if err := os.Rename(path, old); err != nil {
+		return fmt.Errorf("failed to move the running binary: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Rename(old, path)
+		return fmt.Errorf("failed to install the new binary: %w", err)
+	}
+	if runtime.GOOS != "windows" {
+		os.Remove(old)
+	}
+	return nil
+}
+
+// executablePath returns the path of the running binary with symlinks resolved.
+func executablePath() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
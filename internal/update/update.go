@@ -0,0 +1,280 @@
+// Package update checks the latest GitHub release of the bot and notifies
+// the admins when it is newer than the running version. The new binary is
+// installed only when self-update is enabled; the bot then has to be
+// restarted to run it.
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+const (
+	// DefaultRepo is the GitHub repository whose releases are checked
+	DefaultRepo = "aatumaykin/nexbot"
+
+	// DefaultAPIURL is the GitHub REST API base URL
+	DefaultAPIURL = "https://api.github.com"
+
+	// DefaultInterval is used when Config.Interval is not set
+	DefaultInterval = 24 * time.Hour
+
+	// startDelay delays the first check, so it does not slow down startup
+	startDelay = time.Minute
+
+	// maxResponseSize limits the release metadata read from the API
+	maxResponseSize = 1 << 20
+)
+
+// Publisher publishes outbound messages (implemented by bus.MessageBus).
+type Publisher interface {
+	PublishOutbound(msg bus.OutboundMessage) error
+}
+
+// Release is a published GitHub release.
+type Release struct {
+	Tag    string  `json:"tag_name"`
+	URL    string  `json:"html_url"`
+	Assets []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Config configures a Checker.
+type Config struct {
+	Repo           string        // GitHub repository ("owner/name")
+	CurrentVersion string        // Version of the running binary
+	Interval       time.Duration // Time between checks
+	SessionID      string        // Admin session notified about new versions ("channel:chat_id"), optional
+	SelfUpdate     bool          // Install new versions over the running binary
+	Client         *http.Client  // HTTP client (proxy settings); http.DefaultClient if nil
+	APIURL         string        // GitHub API base URL; DefaultAPIURL if empty
+	Logger         *logger.Logger
+}
+
+// Checker periodically compares the running version with the latest release.
+type Checker struct {
+	cfg       Config
+	publisher Publisher
+	logger    *logger.Logger
+	cancel    context.CancelFunc
+
+	mu       sync.Mutex
+	notified string // Tag of the release the admins were notified about
+
+	// executable returns the path of the running binary (replaced in tests)
+	executable func() (string, error)
+}
+
+// New creates a Checker. Notices are published with publisher.
+func New(cfg Config, publisher Publisher) *Checker {
+	if cfg.Repo == "" {
+		cfg.Repo = DefaultRepo
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = DefaultAPIURL
+	}
+	return &Checker{
+		cfg:        cfg,
+		publisher:  publisher,
+		logger:     cfg.Logger,
+		executable: executablePath,
+	}
+}
+
+// Start begins periodic checks. The first check runs shortly after start.
+func (c *Checker) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+
+	c.logger.Info("update checker started",
+		logger.Field{Key: "repo", Value: c.cfg.Repo},
+		logger.Field{Key: "interval_hours", Value: c.cfg.Interval.Hours()},
+		logger.Field{Key: "self_update", Value: c.cfg.SelfUpdate})
+
+	go func() {
+		timer := time.NewTimer(startDelay)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+				if _, err := c.Check(ctx); err != nil && ctx.Err() == nil {
+					c.logger.Warn("update check failed", logger.Field{Key: "error", Value: err.Error()})
+				}
+				timer.Reset(c.cfg.Interval)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the checker.
+func (c *Checker) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// Check fetches the latest release and returns it if it is newer than the
+// running version, or nil. The admins are notified once per release; with
+// self-update enabled the release is installed first.
+func (c *Checker) Check(ctx context.Context) (*Release, error) {
+	release, err := c.Latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !Newer(release.Tag, c.cfg.CurrentVersion) {
+		c.logger.Debug("running the latest version",
+			logger.Field{Key: "version", Value: c.cfg.CurrentVersion},
+			logger.Field{Key: "latest", Value: release.Tag})
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	seen := c.notified == release.Tag
+	c.notified = release.Tag
+	c.mu.Unlock()
+	if seen {
+		return release, nil
+	}
+
+	text := fmt.Sprintf("🆕 Nexbot %s is available (running %s)\n%s", release.Tag, c.cfg.CurrentVersion, release.URL)
+	c.logger.Info("new version available",
+		logger.Field{Key: "version", Value: c.cfg.CurrentVersion},
+		logger.Field{Key: "latest", Value: release.Tag})
+
+	if c.cfg.SelfUpdate {
+		path, err := c.Install(ctx, release)
+		if err != nil {
+			c.logger.Error("self-update failed", err, logger.Field{Key: "latest", Value: release.Tag})
+			text += fmt.Sprintf("\n\nSelf-update failed: %v", err)
+		} else {
+			c.logger.Info("new version installed",
+				logger.Field{Key: "latest", Value: release.Tag},
+				logger.Field{Key: "path", Value: path})
+			text += fmt.Sprintf("\n\nInstalled to %s. Restart the bot to run it.", path)
+		}
+	}
+	c.notify(text)
+	return release, nil
+}
+
+// Latest fetches the latest published release (drafts and pre-releases are
+// skipped by GitHub).
+func (c *Checker) Latest(ctx context.Context) (*Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", strings.TrimRight(c.cfg.APIURL, "/"), c.cfg.Repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "nexbot/"+c.cfg.CurrentVersion)
+
+	resp, err := c.cfg.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("no releases found in %s", c.cfg.Repo)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var release Release
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+	if release.Tag == "" {
+		return nil, fmt.Errorf("release has no tag")
+	}
+	return &release, nil
+}
+
+// notify sends a notice to the admin session, if configured.
+func (c *Checker) notify(text string) {
+	if c.cfg.SessionID == "" || c.publisher == nil {
+		return
+	}
+	channel, chatID, ok := strings.Cut(c.cfg.SessionID, ":")
+	if !ok {
+		return
+	}
+	msg := bus.NewOutboundMessage(bus.ChannelType(channel), chatID, c.cfg.SessionID, text, "",
+		bus.FormatTypePlain, nil)
+	if err := c.publisher.PublishOutbound(*msg); err != nil {
+		c.logger.Warn("failed to send update notice", logger.Field{Key: "error", Value: err.Error()})
+	}
+}
+
+// Newer reports whether version latest is newer than current. Versions are
+// compared as semantic versions ("v1.2.3", "1.2.3-rc1"); a pre-release is
+// older than its release. Versions that cannot be parsed are never newer.
+func Newer(latest, current string) bool {
+	l, lpre, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, cpre, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	switch {
+	case lpre == cpre:
+		return false
+	case lpre == "":
+		return true
+	case cpre == "":
+		return false
+	default:
+		return lpre > cpre
+	}
+}
+
+// parseVersion splits a version into its numeric parts and pre-release.
+func parseVersion(version string) (parts [3]int, pre string, ok bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, pre, _ = strings.Cut(version, "-")
+
+	fields := strings.Split(version, ".")
+	if len(fields) > 3 {
+		return parts, "", false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parts, "", false
+		}
+		parts[i] = n
+	}
+	return parts, pre, true
+}
@@ -0,0 +1,192 @@
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+type recordingPublisher struct {
+	messages []bus.OutboundMessage
+}
+
+func (p *recordingPublisher) PublishOutbound(msg bus.OutboundMessage) error {
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+// newReleaseServer serves the latest release with the current platform's
+// binary and its checksum.
+func newReleaseServer(t *testing.T, tag string, binary []byte, checksum string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/repos/owner/bot/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Release{
+			Tag: tag,
+			URL: "https://github.com/owner/bot/releases/" + tag,
+			Assets: []Asset{
+				{Name: AssetName(), URL: server.URL + "/binary"},
+				{Name: AssetName() + ".sha256", URL: server.URL + "/checksum"},
+			},
+		})
+	})
+	mux.HandleFunc("/binary", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary)
+	})
+	mux.HandleFunc("/checksum", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(checksum + "  " + AssetName() + "\n"))
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestChecker(t *testing.T, server *httptest.Server, current string, selfUpdate bool) (*Checker, *recordingPublisher) {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	publisher := &recordingPublisher{}
+	c := New(Config{
+		Repo:           "owner/bot",
+		CurrentVersion: current,
+		SessionID:      "telegram:42",
+		SelfUpdate:     selfUpdate,
+		APIURL:         server.URL,
+		Logger:         log,
+	}, publisher)
+	return c, publisher
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"v1.2.0", "1.1.9", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"1.10.0", "1.9.0", true},
+		{"v1.2.0", "1.2.0-rc1", true},
+		{"v1.2.0-rc2", "1.2.0-rc1", true},
+		{"v1.2.0-rc1", "1.2.0", false},
+		{"v1.1.0", "1.2.0", false},
+		{"v2", "1.9.9", true},
+		{"v1.2.0", "0.1.0-dev", true},
+		{"nightly", "1.0.0", false},
+		{"v1.2.0", "unknown", false},
+	}
+	for _, tt := range tests {
+		if got := Newer(tt.latest, tt.current); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.latest, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestChecker_Check(t *testing.T) {
+	server := newReleaseServer(t, "v1.3.0", nil, "")
+	c, publisher := newTestChecker(t, server, "1.2.0", false)
+
+	release, err := c.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if release == nil || release.Tag != "v1.3.0" {
+		t.Fatalf("Expected release v1.3.0, got %+v", release)
+	}
+	if len(publisher.messages) != 1 {
+		t.Fatalf("Expected 1 notice, got %d", len(publisher.messages))
+	}
+	notice := publisher.messages[0]
+	if notice.SessionID != "telegram:42" || !strings.Contains(notice.Content, "v1.3.0 is available (running 1.2.0)") {
+		t.Errorf("Unexpected notice to %s: %q", notice.SessionID, notice.Content)
+	}
+
+	// The admins are notified once per release
+	if _, err := c.Check(context.Background()); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(publisher.messages) != 1 {
+		t.Errorf("Expected no repeated notice, got %d notices", len(publisher.messages))
+	}
+}
+
+func TestChecker_Check_UpToDate(t *testing.T) {
+	server := newReleaseServer(t, "v1.2.0", nil, "")
+	c, publisher := newTestChecker(t, server, "v1.2.0", false)
+
+	release, err := c.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if release != nil || len(publisher.messages) != 0 {
+		t.Errorf("Expected no update, got %+v and %d notices", release, len(publisher.messages))
+	}
+}
+
+func TestChecker_SelfUpdate(t *testing.T) {
+	binary := []byte("new binary")
+	server := newReleaseServer(t, "v1.3.0", binary, sha256Hex(binary))
+	c, publisher := newTestChecker(t, server, "1.2.0", true)
+
+	path := filepath.Join(t.TempDir(), "nexbot")
+	if err := os.WriteFile(path, []byte("old binary"), 0755); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+	c.executable = func() (string, error) { return path, nil }
+
+	if _, err := c.Check(context.Background()); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read binary: %v", err)
+	}
+	if string(data) != "new binary" {
+		t.Errorf("Expected the binary to be replaced, got %q", data)
+	}
+	if len(publisher.messages) != 1 || !strings.Contains(publisher.messages[0].Content, "Restart the bot") {
+		t.Errorf("Expected an installed notice, got %+v", publisher.messages)
+	}
+}
+
+func TestChecker_SelfUpdate_ChecksumMismatch(t *testing.T) {
+	server := newReleaseServer(t, "v1.3.0", []byte("tampered"), sha256Hex([]byte("new binary")))
+	c, publisher := newTestChecker(t, server, "1.2.0", true)
+
+	path := filepath.Join(t.TempDir(), "nexbot")
+	if err := os.WriteFile(path, []byte("old binary"), 0755); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+	c.executable = func() (string, error) { return path, nil }
+
+	if _, err := c.Check(context.Background()); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "old binary" {
+		t.Errorf("Expected the binary to be kept, got %q", data)
+	}
+	if _, err := os.Stat(path + ".new"); !os.IsNotExist(err) {
+		t.Error("Expected the downloaded file to be removed")
+	}
+	if len(publisher.messages) != 1 || !strings.Contains(publisher.messages[0].Content, "checksum mismatch") {
+		t.Errorf("Expected a failure notice, got %+v", publisher.messages)
+	}
+}
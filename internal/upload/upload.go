@@ -0,0 +1,188 @@
+// Package upload stores files sent by users (the /upload flow) in the
+// workspace, so the agent's file tools can work on them. Paths are resolved
+// like the file tools resolve them: relative to the workspace, or absolute
+// within the allowed directories.
+package upload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultDir is the workspace directory for uploads without a path
+	DefaultDir = "uploads"
+
+	// DefaultMaxSize is the largest accepted file (the Bot API download limit)
+	DefaultMaxSize = 20 << 20
+)
+
+var (
+	// ErrNotAllowed is returned for paths outside the workspace and the allowed directories.
+	ErrNotAllowed = errors.New("path is outside the workspace and allowed directories")
+
+	// ErrExists is returned when the target file already exists.
+	ErrExists = errors.New("file already exists")
+
+	// ErrTooLarge is returned for files larger than the size limit.
+	ErrTooLarge = errors.New("file is too large")
+)
+
+// Config configures a Store.
+type Config struct {
+	Workspace    string   // Workspace root; relative paths are resolved against it
+	AllowedDirs  []string // Directories outside the workspace that accept absolute paths
+	ReadOnlyDirs []string // Directories that never accept uploads
+	Dir          string   // Directory for uploads without a path, relative to the workspace (DefaultDir if empty)
+	MaxSize      int64    // Size limit in bytes (DefaultMaxSize if 0)
+}
+
+// Result describes a stored file.
+type Result struct {
+	Path   string // Absolute path of the stored file
+	Name   string // Path for the file tools: relative to the workspace, absolute outside it
+	Size   int64  // Size in bytes
+	SHA256 string // Hex-encoded SHA-256 of the content
+}
+
+// Store saves uploaded files.
+type Store struct {
+	cfg Config
+}
+
+// NewStore creates a Store.
+func NewStore(cfg Config) *Store {
+	if cfg.Dir == "" {
+		cfg.Dir = DefaultDir
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = DefaultMaxSize
+	}
+	return &Store{cfg: cfg}
+}
+
+// MaxSize returns the size limit in bytes.
+func (s *Store) MaxSize() int64 {
+	return s.cfg.MaxSize
+}
+
+// Resolve returns the absolute target path for an upload. An empty path
+// stores the file under the uploads directory; a path ending with a slash
+// is a directory that keeps the original file name.
+func (s *Store) Resolve(path, fileName string) (string, error) {
+	fileName = filepath.Base(filepath.Clean("/" + fileName))
+	if fileName == "/" || fileName == "." {
+		fileName = ""
+	}
+
+	path = strings.TrimSpace(path)
+	switch {
+	case path == "":
+		path = s.cfg.Dir + "/"
+		fallthrough
+	case strings.HasSuffix(path, "/"):
+		if fileName == "" {
+			return "", fmt.Errorf("file name is required when the path is a directory")
+		}
+		path += fileName
+	}
+
+	target := path
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(s.cfg.Workspace, target)
+	}
+	target = filepath.Clean(target)
+
+	for _, dir := range s.cfg.ReadOnlyDirs {
+		if within(target, dir) {
+			return "", fmt.Errorf("%w: %s is read-only", ErrNotAllowed, dir)
+		}
+	}
+	if !filepath.IsAbs(path) {
+		if within(target, s.cfg.Workspace) && target != filepath.Clean(s.cfg.Workspace) {
+			return target, nil
+		}
+		return "", ErrNotAllowed
+	}
+	for _, dir := range s.cfg.AllowedDirs {
+		if within(target, dir) && target != filepath.Clean(dir) {
+			return target, nil
+		}
+	}
+	return "", ErrNotAllowed
+}
+
+// Save writes r to the target path. Existing files are not overwritten and
+// the file only appears once it is completely written.
+func (s *Store) Save(target string, r io.Reader) (*Result, error) {
+	if _, err := os.Stat(target); err == nil {
+		return nil, ErrExists
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(r, s.cfg.MaxSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	if size > s.cfg.MaxSize {
+		return nil, ErrTooLarge
+	}
+
+	// Link instead of rename, so a file created meanwhile is not replaced
+	if err := os.Link(tmp.Name(), target); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return nil, ErrExists
+		}
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+	if err := os.Chmod(target, 0644); err != nil {
+		return nil, fmt.Errorf("failed to set file mode: %w", err)
+	}
+
+	name := target
+	if rel, err := filepath.Rel(s.cfg.Workspace, target); err == nil && within(target, s.cfg.Workspace) {
+		name = rel
+	}
+	return &Result{Path: target, Name: name, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// FormatSize formats a byte count into a human-readable string.
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// within reports whether path is dir or inside it.
+func within(path, dir string) bool {
+	if dir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Clean(dir), path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,100 @@
+package upload
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStore_Resolve(t *testing.T) {
+	ws := t.TempDir()
+	allowed := t.TempDir()
+	s := NewStore(Config{
+		Workspace:    ws,
+		AllowedDirs:  []string{allowed},
+		ReadOnlyDirs: []string{filepath.Join(ws, "skills")},
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		fileName string
+		want     string
+		wantErr  error
+	}{
+		{"default directory", "", "report.csv", filepath.Join(ws, "uploads", "report.csv"), nil},
+		{"relative file", "data/input.csv", "report.csv", filepath.Join(ws, "data", "input.csv"), nil},
+		{"relative directory", "data/", "report.csv", filepath.Join(ws, "data", "report.csv"), nil},
+		{"file name is sanitized", "", "../../etc/passwd", filepath.Join(ws, "uploads", "passwd"), nil},
+		{"absolute in allowed dir", filepath.Join(allowed, "a.txt"), "", filepath.Join(allowed, "a.txt"), nil},
+		{"traversal", "../outside.txt", "", "", ErrNotAllowed},
+		{"absolute outside", "/etc/passwd", "", "", ErrNotAllowed},
+		{"absolute workspace path", filepath.Join(ws, "a.txt"), "", "", ErrNotAllowed},
+		{"read-only dir", "skills/x/SKILL.md", "", "", ErrNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Resolve(tt.path, tt.fileName)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Resolve() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Resolve() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := s.Resolve("data/", ""); err == nil {
+		t.Error("Expected error for a directory path without a file name")
+	}
+}
+
+func TestStore_Save(t *testing.T) {
+	ws := t.TempDir()
+	s := NewStore(Config{Workspace: ws, MaxSize: 10})
+	target := filepath.Join(ws, "uploads", "hello.txt")
+
+	result, err := s.Save(target, strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	// sha256("hello")
+	if result.Name != filepath.Join("uploads", "hello.txt") || result.Size != 5 || result.SHA256 != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "hello" {
+		t.Errorf("Stored content = %q", data)
+	}
+
+	if _, err := s.Save(target, strings.NewReader("again")); !errors.Is(err, ErrExists) {
+		t.Errorf("Save() error = %v, want ErrExists", err)
+	}
+
+	large := filepath.Join(ws, "uploads", "large.txt")
+	if _, err := s.Save(large, strings.NewReader(strings.Repeat("x", 11))); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Save() error = %v, want ErrTooLarge", err)
+	}
+	if _, err := os.Stat(large); !os.IsNotExist(err) {
+		t.Error("Expected a rejected upload to leave no file")
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(target))
+	if len(entries) != 1 {
+		t.Errorf("Expected only the stored file in the directory, got %d entries", len(entries))
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	if got := FormatSize(512); got != "512 B" {
+		t.Errorf("FormatSize(512) = %q", got)
+	}
+	if got := FormatSize(3 << 20); got != "3.0 MB" {
+		t.Errorf("FormatSize(3 MiB) = %q", got)
+	}
+}
@@ -0,0 +1,434 @@
+// Package users provides a registry that links a person's identities across
+// channels (telegram chat ID, email address, discord user, ...).
+// The agent uses it to decide where to deliver notifications: "email me the
+// report, ping me on Telegram when done" resolves to concrete addresses.
+package users
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// UsersSubdirectory is the subdirectory name for the user registry within workspace
+	UsersSubdirectory = "users"
+
+	// UsersFilename is the filename of the user registry
+	UsersFilename = "users.json"
+
+	// RoleAdmin is the role of users who administer the bot
+	RoleAdmin = "admin"
+
+	// RoleUser is the default role
+	RoleUser = "user"
+)
+
+// Identity is an address of a user in a specific channel.
+// Its string form is "channel:address" and matches the session ID format
+// for chat channels (e.g. "telegram:123456789").
+type Identity struct {
+	Channel string
+	Address string
+}
+
+// ParseIdentity parses a "channel:address" string.
+func ParseIdentity(s string) (Identity, error) {
+	channel, address, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok || channel == "" || address == "" {
+		return Identity{}, fmt.Errorf("invalid identity %q: expected 'channel:address'", s)
+	}
+	return Identity{Channel: strings.ToLower(channel), Address: address}, nil
+}
+
+// String returns the "channel:address" form.
+func (i Identity) String() string {
+	return i.Channel + ":" + i.Address
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (i Identity) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (i *Identity) UnmarshalText(data []byte) error {
+	parsed, err := ParseIdentity(string(data))
+	if err != nil {
+		return err
+	}
+	*i = parsed
+	return nil
+}
+
+// User is a person known to the bot with all linked identities.
+type User struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name,omitempty"`
+	Identities      []Identity `json:"identities"`
+	DefaultChannels []string   `json:"default_channels,omitempty"` // Channels used when none are requested
+
+	// Profile used by the prompt builder, the scheduler and notifications
+	Language    string            `json:"language,omitempty"`    // Preferred reply language (e.g. "en", "ru")
+	Timezone    string            `json:"timezone,omitempty"`    // IANA timezone (e.g. "Europe/Berlin")
+	Role        string            `json:"role,omitempty"`        // RoleAdmin or RoleUser
+	Preferences map[string]string `json:"preferences,omitempty"` // Free-form preferences by key
+
+	// Onboarding details, filled in when the user starts the bot
+	Source     string          `json:"source,omitempty"`      // Referral source of the start link
+	InviteCode string          `json:"invite_code,omitempty"` // Invite code the user joined with
+	JoinedAt   time.Time       `json:"joined_at,omitzero"`
+	Consent    map[string]bool `json:"consent,omitempty"` // Answers to consent questions by name
+
+	// Static users come from the config file; config values win over stored ones on load
+	Static bool `json:"-"`
+}
+
+// Location returns the timezone of the user, or nil if it is not set or invalid.
+func (u User) Location() *time.Location {
+	if u.Timezone == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// Identity returns the user identity for a channel.
+func (u User) Identity(channel string) (Identity, bool) {
+	for _, id := range u.Identities {
+		if id.Channel == channel {
+			return id, true
+		}
+	}
+	return Identity{}, false
+}
+
+// Registry stores users and resolves identities.
+type Registry struct {
+	filePath string
+
+	mu    sync.RWMutex
+	users map[string]*User
+}
+
+// NewRegistry creates a registry persisted at <workspace>/users/users.json.
+func NewRegistry(workspacePath string) *Registry {
+	return &Registry{
+		filePath: filepath.Join(workspacePath, UsersSubdirectory, UsersFilename),
+		users:    make(map[string]*User),
+	}
+}
+
+// Load reads stored users and merges static users from config.
+// Static users take precedence; identities linked at runtime and onboarding
+// details are kept, and profile fields not set in config keep their stored values.
+func (r *Registry) Load(static []User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := os.ReadFile(r.filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read user registry: %w", err)
+	}
+	if err == nil {
+		var stored []User
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return fmt.Errorf("failed to parse user registry: %w", err)
+		}
+		for i := range stored {
+			u := stored[i]
+			r.users[u.ID] = &u
+		}
+	}
+
+	for _, su := range static {
+		su.Static = true
+		if existing, ok := r.users[su.ID]; ok {
+			for _, id := range existing.Identities {
+				if !slices.Contains(su.Identities, id) {
+					su.Identities = append(su.Identities, id)
+				}
+			}
+			// Onboarding details are only stored at runtime
+			su.Source, su.InviteCode = existing.Source, existing.InviteCode
+			su.JoinedAt, su.Consent = existing.JoinedAt, existing.Consent
+			su.Language = cmp.Or(su.Language, existing.Language)
+			su.Timezone = cmp.Or(su.Timezone, existing.Timezone)
+			su.Role = cmp.Or(su.Role, existing.Role)
+			if len(existing.Preferences) > 0 {
+				prefs := maps.Clone(existing.Preferences)
+				maps.Copy(prefs, su.Preferences)
+				su.Preferences = prefs
+			}
+		}
+		r.users[su.ID] = &su
+	}
+
+	return nil
+}
+
+// Get returns a user by ID.
+func (r *Registry) Get(userID string) (User, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[userID]
+	if !ok {
+		return User{}, false
+	}
+	return copyUser(u), true
+}
+
+// FindByIdentity returns the user that owns an identity.
+func (r *Registry) FindByIdentity(identity Identity) (User, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.users {
+		if slices.Contains(u.Identities, identity) {
+			return copyUser(u), true
+		}
+	}
+	return User{}, false
+}
+
+// FindBySession returns the user that owns a chat session ("channel:chat_id").
+func (r *Registry) FindBySession(sessionID string) (User, bool) {
+	identity, err := ParseIdentity(sessionID)
+	if err != nil {
+		return User{}, false
+	}
+	return r.FindByIdentity(identity)
+}
+
+// List returns all users sorted by ID.
+func (r *Registry) List() []User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]User, 0, len(r.users))
+	for _, u := range r.users {
+		list = append(list, copyUser(u))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Link adds an identity to a user, creating the user if needed.
+// An identity can belong to only one user.
+func (r *Registry) Link(userID string, identity Identity) (User, error) {
+	if userID == "" {
+		return User{}, fmt.Errorf("user id is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.users {
+		if u.ID != userID && slices.Contains(u.Identities, identity) {
+			return User{}, fmt.Errorf("identity %s is already linked to user %s", identity, u.ID)
+		}
+	}
+
+	u, ok := r.users[userID]
+	if !ok {
+		u = &User{ID: userID}
+		r.users[userID] = u
+	}
+	if !slices.Contains(u.Identities, identity) {
+		u.Identities = append(u.Identities, identity)
+	}
+
+	if err := r.saveLocked(); err != nil {
+		return User{}, err
+	}
+	return copyUser(u), nil
+}
+
+// Register returns the user that owns an identity, creating a user with the
+// identity as ID if it is unknown. Source and invite code of the profile are
+// recorded unless the user already has them. Reports whether the user was created.
+func (r *Registry) Register(identity Identity, profile User) (User, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var u *User
+	for _, existing := range r.users {
+		if slices.Contains(existing.Identities, identity) {
+			u = existing
+			break
+		}
+	}
+	created := u == nil
+	if created {
+		u = &User{ID: identity.String(), Name: profile.Name, Identities: []Identity{identity}, JoinedAt: profile.JoinedAt}
+		if u.JoinedAt.IsZero() {
+			u.JoinedAt = time.Now()
+		}
+		r.users[u.ID] = u
+	}
+	if u.Source == "" {
+		u.Source = profile.Source
+	}
+	if u.InviteCode == "" {
+		u.InviteCode = profile.InviteCode
+	}
+
+	if err := r.saveLocked(); err != nil {
+		return User{}, false, err
+	}
+	return copyUser(u), created, nil
+}
+
+// SetConsent records the answer of a user to a consent question.
+func (r *Registry) SetConsent(userID, name string, granted bool) (User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[userID]
+	if !ok {
+		return User{}, fmt.Errorf("user not found: %s", userID)
+	}
+	if u.Consent == nil {
+		u.Consent = make(map[string]bool)
+	}
+	u.Consent[name] = granted
+
+	if err := r.saveLocked(); err != nil {
+		return User{}, err
+	}
+	return copyUser(u), nil
+}
+
+// Update applies a change to the profile of a user and persists it.
+// ID and identities can't be changed this way.
+func (r *Registry) Update(userID string, change func(u *User)) (User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[userID]
+	if !ok {
+		return User{}, fmt.Errorf("user not found: %s", userID)
+	}
+	previous := *u
+	updated := copyUser(u)
+	change(&updated)
+	updated.ID, updated.Identities, updated.Static = u.ID, u.Identities, u.Static
+	*u = updated
+
+	if err := r.saveLocked(); err != nil {
+		*u = previous
+		return User{}, err
+	}
+	return copyUser(u), nil
+}
+
+// Delete removes a user with all linked identities. Static users are
+// loaded again from config on the next start.
+func (r *Registry) Delete(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[userID]
+	if !ok {
+		return fmt.Errorf("user not found: %s", userID)
+	}
+	delete(r.users, userID)
+	if err := r.saveLocked(); err != nil {
+		r.users[userID] = u
+		return err
+	}
+	return nil
+}
+
+// Unlink removes an identity from a user.
+func (r *Registry) Unlink(userID string, identity Identity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[userID]
+	if !ok {
+		return fmt.Errorf("user not found: %s", userID)
+	}
+	idx := slices.Index(u.Identities, identity)
+	if idx < 0 {
+		return fmt.Errorf("identity %s is not linked to user %s", identity, userID)
+	}
+	u.Identities = slices.Delete(u.Identities, idx, idx+1)
+
+	return r.saveLocked()
+}
+
+// Targets resolves the identities a notification should be delivered to.
+// If channels is empty, the user's default channels are used; if those are
+// not set either, all identities are returned.
+func (r *Registry) Targets(userID string, channels []string) ([]Identity, error) {
+	u, ok := r.Get(userID)
+	if !ok {
+		return nil, fmt.Errorf("user not found: %s", userID)
+	}
+
+	if len(channels) == 0 {
+		channels = u.DefaultChannels
+	}
+	if len(channels) == 0 {
+		return u.Identities, nil
+	}
+
+	var targets []Identity
+	for _, ch := range channels {
+		id, ok := u.Identity(strings.ToLower(ch))
+		if !ok {
+			return nil, fmt.Errorf("user %s has no %s identity", userID, ch)
+		}
+		targets = append(targets, id)
+	}
+	return targets, nil
+}
+
+// saveLocked persists the registry. Caller must hold r.mu.
+func (r *Registry) saveLocked() error {
+	list := make([]User, 0, len(r.users))
+	for _, u := range r.users {
+		list = append(list, *u)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+
+	if err := os.MkdirAll(filepath.Dir(r.filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create users directory: %w", err)
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal user registry: %w", err)
+	}
+
+	// Write to temp file first, then rename for atomicity
+	tmpPath := r.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write user registry: %w", err)
+	}
+	if err := os.Rename(tmpPath, r.filePath); err != nil {
+		return fmt.Errorf("failed to save user registry: %w", err)
+	}
+	return nil
+}
+
+func copyUser(u *User) User {
+	c := *u
+	c.Identities = slices.Clone(u.Identities)
+	c.DefaultChannels = slices.Clone(u.DefaultChannels)
+	c.Consent = maps.Clone(u.Consent)
+	c.Preferences = maps.Clone(u.Preferences)
+	return c
+}
@@ -0,0 +1,178 @@
+package users
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIdentity(t *testing.T) {
+	id, err := ParseIdentity("Telegram:123")
+	require.NoError(t, err)
+	assert.Equal(t, Identity{Channel: "telegram", Address: "123"}, id)
+	assert.Equal(t, "telegram:123", id.String())
+
+	id, err = ParseIdentity("email:alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", id.Address)
+
+	for _, s := range []string{"", "telegram", ":123", "telegram:"} {
+		_, err := ParseIdentity(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestRegistry_LinkAndPersist(t *testing.T) {
+	dir := t.TempDir()
+	tg := Identity{Channel: "telegram", Address: "123"}
+	email := Identity{Channel: "email", Address: "alice@example.com"}
+
+	r := NewRegistry(dir)
+	require.NoError(t, r.Load(nil))
+
+	_, err := r.Link("alice", tg)
+	require.NoError(t, err)
+	_, err = r.Link("alice", email)
+	require.NoError(t, err)
+
+	_, err = r.Link("bob", tg)
+	assert.Error(t, err, "identity can belong to only one user")
+
+	// Reload from disk
+	r2 := NewRegistry(dir)
+	require.NoError(t, r2.Load(nil))
+
+	u, ok := r2.FindBySession("telegram:123")
+	require.True(t, ok)
+	assert.Equal(t, "alice", u.ID)
+	assert.ElementsMatch(t, []Identity{tg, email}, u.Identities)
+
+	require.NoError(t, r2.Unlink("alice", email))
+	u, _ = r2.Get("alice")
+	assert.Equal(t, []Identity{tg}, u.Identities)
+}
+
+func TestRegistry_StaticUsersMerge(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRegistry(dir)
+	require.NoError(t, r.Load(nil))
+	_, err := r.Link("alice", Identity{Channel: "email", Address: "a@example.com"})
+	require.NoError(t, err)
+
+	r2 := NewRegistry(dir)
+	require.NoError(t, r2.Load([]User{{
+		ID:         "alice",
+		Name:       "Alice",
+		Identities: []Identity{{Channel: "telegram", Address: "1"}},
+	}}))
+
+	u, ok := r2.Get("alice")
+	require.True(t, ok)
+	assert.True(t, u.Static)
+	assert.Equal(t, "Alice", u.Name)
+	assert.Len(t, u.Identities, 2)
+}
+
+func TestRegistry_Targets(t *testing.T) {
+	r := NewRegistry(t.TempDir())
+	require.NoError(t, r.Load([]User{
+		{
+			ID: "alice",
+			Identities: []Identity{
+				{Channel: "telegram", Address: "1"},
+				{Channel: "email", Address: "a@example.com"},
+			},
+			DefaultChannels: []string{"telegram"},
+		},
+		{
+			ID:         "bob",
+			Identities: []Identity{{Channel: "telegram", Address: "2"}, {Channel: "discord", Address: "b"}},
+		},
+	}))
+
+	targets, err := r.Targets("alice", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Identity{{Channel: "telegram", Address: "1"}}, targets)
+
+	targets, err = r.Targets("alice", []string{"email", "telegram"})
+	require.NoError(t, err)
+	assert.Len(t, targets, 2)
+
+	targets, err = r.Targets("bob", nil)
+	require.NoError(t, err)
+	assert.Len(t, targets, 2, "all identities without default channels")
+
+	_, err = r.Targets("bob", []string{"email"})
+	assert.Error(t, err)
+
+	_, err = r.Targets("carol", nil)
+	assert.Error(t, err)
+}
+
+func TestRegistry_RegisterAndConsent(t *testing.T) {
+	dir := t.TempDir()
+	tg := Identity{Channel: "telegram", Address: "123"}
+
+	r := NewRegistry(dir)
+	require.NoError(t, r.Load(nil))
+
+	u, created, err := r.Register(tg, User{Name: "Alice", Source: "twitter"})
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "telegram:123", u.ID)
+	assert.Equal(t, "twitter", u.Source)
+	assert.False(t, u.JoinedAt.IsZero())
+
+	// A second start keeps the first source and records a missing invite code
+	u, created, err = r.Register(tg, User{Source: "github", InviteCode: "team"})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "twitter", u.Source)
+	assert.Equal(t, "team", u.InviteCode)
+
+	_, err = r.SetConsent(u.ID, "history", true)
+	require.NoError(t, err)
+	_, err = r.SetConsent("unknown", "history", true)
+	assert.Error(t, err)
+
+	// Onboarding details survive a reload, also for users defined in config
+	reloaded := NewRegistry(dir)
+	require.NoError(t, reloaded.Load([]User{{ID: "telegram:123", Name: "Alice (config)", Identities: []Identity{tg}}}))
+	u, ok := reloaded.FindByIdentity(tg)
+	require.True(t, ok)
+	assert.Equal(t, "Alice (config)", u.Name)
+	assert.Equal(t, "twitter", u.Source)
+	assert.Equal(t, map[string]bool{"history": true}, u.Consent)
+}
+
+func TestRegistry_UpdateProfile(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRegistry(dir)
+	require.NoError(t, r.Load(nil))
+	_, err := r.Link("alice", Identity{Channel: "telegram", Address: "1"})
+	require.NoError(t, err)
+
+	u, err := r.Update("alice", func(u *User) {
+		u.Timezone = "Europe/Berlin"
+		u.Language = "de"
+		u.Preferences = map[string]string{"units": "metric"}
+		u.Identities = nil // ignored
+	})
+	require.NoError(t, err)
+	assert.Len(t, u.Identities, 1)
+	require.NotNil(t, u.Location())
+	assert.Equal(t, "Europe/Berlin", u.Location().String())
+
+	_, err = r.Update("bob", func(u *User) {})
+	assert.Error(t, err)
+
+	// Config values win, stored profile fields fill the gaps
+	r2 := NewRegistry(dir)
+	require.NoError(t, r2.Load([]User{{ID: "alice", Language: "en", Role: RoleAdmin, Preferences: map[string]string{"tone": "brief"}}}))
+	u, _ = r2.Get("alice")
+	assert.Equal(t, "en", u.Language)
+	assert.Equal(t, "Europe/Berlin", u.Timezone)
+	assert.Equal(t, RoleAdmin, u.Role)
+	assert.Equal(t, map[string]string{"units": "metric", "tone": "brief"}, u.Preferences)
+}
@@ -0,0 +1,65 @@
+package watcher
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Rule describes a single watch: which path to observe, which changes are
+// interesting and which session should be notified.
+type Rule struct {
+	ID        string    `json:"id"`
+	Path      string    `json:"path"`               // File or directory (absolute or relative to workspace)
+	Patterns  []string  `json:"patterns,omitempty"` // Glob patterns for file names (directory watches only)
+	Match     string    `json:"match,omitempty"`    // Regex for new lines; empty means any change
+	SessionID string    `json:"session_id"`         // Session to notify ("channel:chat_id")
+	CreatedAt time.Time `json:"created_at"`
+
+	// Static rules come from the config file; they are not persisted and cannot be removed
+	Static bool `json:"-"`
+}
+
+// GenerateRuleID generates a short unique watch ID.
+func GenerateRuleID() string {
+	return fmt.Sprintf("watch_%s", uuid.New().String()[:8])
+}
+
+// Validate checks rule fields that do not depend on the file system.
+func (r Rule) Validate() error {
+	if strings.TrimSpace(r.Path) == "" {
+		return fmt.Errorf("path is required")
+	}
+	if _, _, ok := strings.Cut(r.SessionID, ":"); !ok {
+		return fmt.Errorf("invalid session_id format: expected 'channel:chat_id', got '%s'", r.SessionID)
+	}
+	if r.Match != "" {
+		if _, err := regexp.Compile(r.Match); err != nil {
+			return fmt.Errorf("invalid match regex: %w", err)
+		}
+	}
+	for _, p := range r.Patterns {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// matchesName reports whether a file name matches the rule patterns.
+// A rule without patterns matches every file.
+func (r Rule) matchesName(name string) bool {
+	if len(r.Patterns) == 0 {
+		return true
+	}
+	for _, p := range r.Patterns {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package watcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// WatcherSubdirectory is the subdirectory name for watcher data within workspace
+	WatcherSubdirectory = "watcher"
+
+	// RulesFilename is the filename for storing watches added at runtime
+	RulesFilename = "watches.json"
+)
+
+// Storage persists watches added at runtime (e.g. by the agent),
+// so they survive restarts. Static rules from config are not stored.
+type Storage struct {
+	filePath string
+}
+
+// NewStorage creates a storage located at <workspace>/watcher/watches.json.
+func NewStorage(workspacePath string) *Storage {
+	return &Storage{filePath: filepath.Join(workspacePath, WatcherSubdirectory, RulesFilename)}
+}
+
+// Load reads stored rules. Returns an empty slice if the file doesn't exist.
+func (s *Storage) Load() ([]Rule, error) {
+	data, err := os.ReadFile(s.filePath)
+	if os.IsNotExist(err) {
+		return []Rule{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watches: %w", err)
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse watches: %w", err)
+	}
+	return rules, nil
+}
+
+// Save overwrites the stored rules.
+func (s *Storage) Save(rules []Rule) error {
+	if err := os.MkdirAll(filepath.Dir(s.filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create watcher directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal watches: %w", err)
+	}
+
+	// Write to temp file first, then rename for atomicity
+	tmpPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write watches: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.filePath); err != nil {
+		return fmt.Errorf("failed to save watches: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,582 @@
+// Package watcher observes workspace files and notifies a session when they change.
+// It is built on fsnotify: events are debounced per file, filtered by file name
+// patterns and, for log-like files, by a regex applied to newly appended lines.
+// Notifications are published to the message bus as inbound messages, so the
+// agent processes them like any other message in the target session.
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+const (
+	// DefaultDebounce is used when Config.Debounce is not set
+	DefaultDebounce = 500 * time.Millisecond
+
+	// DefaultMaxLines is used when Config.MaxLines is not set
+	DefaultMaxLines = 20
+
+	// maxReadBytes limits how much new content is read per notification
+	maxReadBytes = 1 << 20
+)
+
+// Publisher publishes inbound messages (implemented by bus.MessageBus).
+type Publisher interface {
+	PublishInbound(msg bus.InboundMessage) error
+}
+
+// Config configures the watcher.
+type Config struct {
+	Workspace   string        // Workspace root; relative rule paths are resolved against it
+	Debounce    time.Duration // Quiet period before a change is reported
+	MaxLines    int           // Maximum matching lines included in one notification
+	AllowedDirs []string      // Extra directories runtime rules may point to (besides workspace)
+	Rules       []Rule        // Static rules from config
+	Storage     *Storage      // Storage for runtime rules (optional)
+}
+
+// ruleState is a rule with resolved paths and compiled regex.
+type ruleState struct {
+	Rule
+	abs   string         // Absolute path
+	dir   string         // Directory registered in fsnotify
+	isDir bool           // Whether the rule watches a whole directory
+	re    *regexp.Regexp // Compiled Match (nil = any change)
+}
+
+// Watcher watches files and publishes notifications on change.
+type Watcher struct {
+	cfg       Config
+	publisher Publisher
+	logger    *logger.Logger
+
+	fs      *fsnotify.Watcher
+	mu      sync.Mutex
+	rules   map[string]*ruleState
+	dirRefs map[string]int         // Watched directory -> number of rules using it
+	timers  map[string]*time.Timer // Pending debounced notifications by key
+	offsets map[string]int64       // Read offsets by key (rule ID + path)
+	started bool
+	done    chan struct{}
+}
+
+// New creates a new Watcher.
+func New(cfg Config, publisher Publisher, log *logger.Logger) *Watcher {
+	if cfg.Debounce <= 0 {
+		cfg.Debounce = DefaultDebounce
+	}
+	if cfg.MaxLines <= 0 {
+		cfg.MaxLines = DefaultMaxLines
+	}
+	return &Watcher{
+		cfg:       cfg,
+		publisher: publisher,
+		logger:    log,
+		rules:     make(map[string]*ruleState),
+		dirRefs:   make(map[string]int),
+		timers:    make(map[string]*time.Timer),
+		offsets:   make(map[string]int64),
+	}
+}
+
+// Start creates the fsnotify watcher, registers static and stored rules and
+// starts processing events until the context is cancelled or Stop is called.
+func (w *Watcher) Start(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.started {
+		return fmt.Errorf("watcher already started")
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create fsnotify watcher: %w", err)
+	}
+	w.fs = fsw
+	w.done = make(chan struct{})
+	w.started = true
+
+	rules := make([]Rule, 0, len(w.cfg.Rules))
+	for _, r := range w.cfg.Rules {
+		r.Static = true
+		rules = append(rules, r)
+	}
+	if w.cfg.Storage != nil {
+		stored, err := w.cfg.Storage.Load()
+		if err != nil {
+			w.logger.Error("failed to load stored watches", err)
+		}
+		rules = append(rules, stored...)
+	}
+
+	for _, r := range rules {
+		if r.ID == "" {
+			r.ID = GenerateRuleID()
+		}
+		if err := w.addLocked(r); err != nil {
+			// A missing path must not prevent the bot from starting
+			w.logger.Error("failed to register watch", err,
+				logger.Field{Key: "watch_id", Value: r.ID},
+				logger.Field{Key: "path", Value: r.Path})
+		}
+	}
+
+	go w.run(ctx, fsw, w.done)
+
+	w.logger.Info("watcher started",
+		logger.Field{Key: "rules", Value: len(w.rules)})
+	return nil
+}
+
+// Stop stops event processing and releases fsnotify resources.
+func (w *Watcher) Stop() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.started {
+		return fmt.Errorf("watcher not started")
+	}
+
+	for key, t := range w.timers {
+		t.Stop()
+		delete(w.timers, key)
+	}
+	w.started = false
+	close(w.done)
+	return w.fs.Close()
+}
+
+// Add registers a runtime rule and persists it. Runtime rules must point
+// inside the workspace or one of the allowed directories.
+func (w *Watcher) Add(rule Rule) (Rule, error) {
+	if rule.ID == "" {
+		rule.ID = GenerateRuleID()
+	}
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = time.Now()
+	}
+	rule.Static = false
+
+	if err := w.checkAllowed(w.resolve(rule.Path)); err != nil {
+		return Rule{}, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.started {
+		return Rule{}, fmt.Errorf("watcher not started")
+	}
+	if err := w.addLocked(rule); err != nil {
+		return Rule{}, err
+	}
+	if err := w.saveLocked(); err != nil {
+		w.removeLocked(rule.ID)
+		return Rule{}, err
+	}
+
+	w.logger.Info("watch added",
+		logger.Field{Key: "watch_id", Value: rule.ID},
+		logger.Field{Key: "path", Value: rule.Path},
+		logger.Field{Key: "session_id", Value: rule.SessionID})
+	return rule, nil
+}
+
+// Remove removes a runtime rule by ID.
+func (w *Watcher) Remove(id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	st, ok := w.rules[id]
+	if !ok {
+		return fmt.Errorf("watch not found: %s", id)
+	}
+	if st.Static {
+		return fmt.Errorf("watch %s is defined in config and cannot be removed", id)
+	}
+
+	w.removeLocked(id)
+	if err := w.saveLocked(); err != nil {
+		return err
+	}
+
+	w.logger.Info("watch removed", logger.Field{Key: "watch_id", Value: id})
+	return nil
+}
+
+// List returns all rules sorted by ID.
+func (w *Watcher) List() []Rule {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	rules := make([]Rule, 0, len(w.rules))
+	for _, st := range w.rules {
+		rules = append(rules, st.Rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
+
+// resolve converts a rule path into an absolute path.
+func (w *Watcher) resolve(path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(w.cfg.Workspace, path)
+	}
+	return filepath.Clean(path)
+}
+
+// checkAllowed verifies that a runtime rule path is inside the workspace or an allowed directory.
+func (w *Watcher) checkAllowed(abs string) error {
+	roots := append([]string{w.cfg.Workspace}, w.cfg.AllowedDirs...)
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		rel, err := filepath.Rel(filepath.Clean(root), abs)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("path %s is outside the workspace and allowed directories", abs)
+}
+
+// addLocked validates and registers a rule. Caller must hold w.mu.
+func (w *Watcher) addLocked(rule Rule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	if _, exists := w.rules[rule.ID]; exists {
+		return fmt.Errorf("watch already exists: %s", rule.ID)
+	}
+
+	st := &ruleState{Rule: rule, abs: w.resolve(rule.Path)}
+	if rule.Match != "" {
+		st.re = regexp.MustCompile(rule.Match) // validated above
+	}
+
+	info, err := os.Stat(st.abs)
+	switch {
+	case err == nil && info.IsDir():
+		st.isDir = true
+		st.dir = st.abs
+	case err == nil || os.IsNotExist(err):
+		// Watch the parent directory so that file creation and log rotation are noticed
+		st.dir = filepath.Dir(st.abs)
+	default:
+		return fmt.Errorf("failed to stat %s: %w", st.abs, err)
+	}
+
+	if w.dirRefs[st.dir] == 0 {
+		if err := w.fs.Add(st.dir); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", st.dir, err)
+		}
+	}
+	w.dirRefs[st.dir]++
+	w.rules[rule.ID] = st
+
+	// Only lines appended after the watch was registered are reported
+	if st.re != nil {
+		for _, path := range w.existingFiles(st) {
+			if info, err := os.Stat(path); err == nil {
+				w.offsets[offsetKey(rule.ID, path)] = info.Size()
+			}
+		}
+	}
+	return nil
+}
+
+// removeLocked unregisters a rule. Caller must hold w.mu.
+func (w *Watcher) removeLocked(id string) {
+	st, ok := w.rules[id]
+	if !ok {
+		return
+	}
+	delete(w.rules, id)
+
+	prefix := id + "\x00"
+	for key, t := range w.timers {
+		if strings.HasPrefix(key, prefix) {
+			t.Stop()
+			delete(w.timers, key)
+		}
+	}
+	for key := range w.offsets {
+		if strings.HasPrefix(key, prefix) {
+			delete(w.offsets, key)
+		}
+	}
+
+	w.dirRefs[st.dir]--
+	if w.dirRefs[st.dir] <= 0 {
+		delete(w.dirRefs, st.dir)
+		_ = w.fs.Remove(st.dir)
+	}
+}
+
+// saveLocked persists runtime rules. Caller must hold w.mu.
+func (w *Watcher) saveLocked() error {
+	if w.cfg.Storage == nil {
+		return nil
+	}
+	var rules []Rule
+	for _, st := range w.rules {
+		if !st.Static {
+			rules = append(rules, st.Rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return w.cfg.Storage.Save(rules)
+}
+
+// existingFiles returns files currently covered by a rule.
+func (w *Watcher) existingFiles(st *ruleState) []string {
+	if !st.isDir {
+		return []string{st.abs}
+	}
+	entries, err := os.ReadDir(st.abs)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && st.matchesName(e.Name()) {
+			files = append(files, filepath.Join(st.abs, e.Name()))
+		}
+	}
+	return files
+}
+
+// run processes fsnotify events until the watcher is stopped.
+func (w *Watcher) run(ctx context.Context, fsw *fsnotify.Watcher, done chan struct{}) {
+	for {
+		select {
+		case <-ctx.Done():
+			_ = w.Stop()
+			return
+		case <-done:
+			return
+		case ev, ok := <-fsw.Events:
+			if !ok {
+				return
+			}
+			w.handleEvent(ev)
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Error("watcher error", err)
+		}
+	}
+}
+
+// handleEvent routes an fsnotify event to matching rules.
+func (w *Watcher) handleEvent(ev fsnotify.Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.started {
+		return
+	}
+
+	path := filepath.Clean(ev.Name)
+	for id, st := range w.rules {
+		if !st.covers(path) {
+			continue
+		}
+
+		key := offsetKey(id, path)
+		switch {
+		case ev.Has(fsnotify.Create), ev.Has(fsnotify.Remove), ev.Has(fsnotify.Rename):
+			// New or rotated file: read it from the beginning
+			w.offsets[key] = 0
+			if !ev.Has(fsnotify.Create) {
+				continue
+			}
+		case !ev.Has(fsnotify.Write):
+			continue
+		}
+
+		w.scheduleLocked(id, path, key)
+	}
+}
+
+// covers reports whether a path belongs to the rule.
+func (st *ruleState) covers(path string) bool {
+	if st.isDir {
+		return filepath.Dir(path) == st.abs && st.matchesName(filepath.Base(path))
+	}
+	return path == st.abs
+}
+
+// scheduleLocked (re)starts the debounce timer for a rule and path. Caller must hold w.mu.
+func (w *Watcher) scheduleLocked(id, path, key string) {
+	if t, ok := w.timers[key]; ok {
+		t.Reset(w.cfg.Debounce)
+		return
+	}
+	w.timers[key] = time.AfterFunc(w.cfg.Debounce, func() {
+		w.fire(id, path, key)
+	})
+}
+
+// fire collects changes after the debounce period and publishes a notification.
+func (w *Watcher) fire(id, path, key string) {
+	w.mu.Lock()
+	delete(w.timers, key)
+	st, ok := w.rules[id]
+	if !ok || !w.started {
+		w.mu.Unlock()
+		return
+	}
+	rule := st.Rule
+	re := st.re
+
+	var lines []string
+	if re != nil {
+		newLines, offset, err := readNewLines(path, w.offsets[key])
+		if err != nil {
+			w.mu.Unlock()
+			w.logger.Error("failed to read watched file", err,
+				logger.Field{Key: "watch_id", Value: id},
+				logger.Field{Key: "path", Value: path})
+			return
+		}
+		w.offsets[key] = offset
+		for _, line := range newLines {
+			if re.MatchString(line) {
+				lines = append(lines, line)
+			}
+		}
+	}
+	w.mu.Unlock()
+
+	if re != nil && len(lines) == 0 {
+		return
+	}
+
+	w.notify(rule, path, lines)
+}
+
+// notify publishes a notification for the rule session.
+func (w *Watcher) notify(rule Rule, path string, lines []string) {
+	channel, _, _ := strings.Cut(rule.SessionID, ":")
+	content := w.formatNotification(rule, path, lines)
+
+	msg := bus.NewInboundMessage(
+		bus.ChannelType(channel),
+		"", // Empty user_id for system notifications
+		rule.SessionID,
+		content,
+		map[string]any{
+			"source":   "watcher",
+			"watch_id": rule.ID,
+			"path":     path,
+		},
+	)
+
+	if err := w.publisher.PublishInbound(*msg); err != nil {
+		w.logger.Error("failed to publish watcher notification", err,
+			logger.Field{Key: "watch_id", Value: rule.ID},
+			logger.Field{Key: "session_id", Value: rule.SessionID})
+		return
+	}
+
+	w.logger.Info("watcher notification sent",
+		logger.Field{Key: "watch_id", Value: rule.ID},
+		logger.Field{Key: "path", Value: path},
+		logger.Field{Key: "lines", Value: len(lines)})
+}
+
+// formatNotification builds the message text the agent receives.
+func (w *Watcher) formatNotification(rule Rule, path string, lines []string) string {
+	display := path
+	if rel, err := filepath.Rel(w.cfg.Workspace, path); err == nil && !strings.HasPrefix(rel, "..") {
+		display = rel
+	}
+
+	var b strings.Builder
+	if len(lines) == 0 {
+		fmt.Fprintf(&b, "[watcher %s] File changed: %s", rule.ID, display)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "[watcher %s] New lines matching %q in %s:\n\n", rule.ID, rule.Match, display)
+	shown := lines
+	if len(shown) > w.cfg.MaxLines {
+		shown = shown[len(shown)-w.cfg.MaxLines:]
+		fmt.Fprintf(&b, "… %d earlier lines omitted\n", len(lines)-len(shown))
+	}
+	b.WriteString(strings.Join(shown, "\n"))
+	return b.String()
+}
+
+// readNewLines reads complete lines appended to a file since offset.
+// If the file shrank (truncated or rotated), it is read from the beginning.
+// Returns the lines and the new offset.
+func readNewLines(path string, offset int64) ([]string, int64, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, offset, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, offset, err
+	}
+	size := info.Size()
+	if size < offset {
+		offset = 0
+	}
+	if size == offset {
+		return nil, offset, nil
+	}
+
+	start := offset
+	if size-start > maxReadBytes {
+		start = size - maxReadBytes
+	}
+	data := make([]byte, size-start)
+	if _, err := f.ReadAt(data, start); err != nil && err != io.EOF {
+		return nil, offset, err
+	}
+
+	// Keep an incomplete trailing line for the next read
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil, offset, nil
+	}
+	data = data[:end]
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if start > offset {
+		// First line may be cut in the middle after skipping content
+		lines = lines[1:]
+	}
+	lines = slices.DeleteFunc(lines, func(s string) bool { return strings.TrimSpace(s) == "" })
+	return lines, start + int64(end) + 1, nil
+}
+
+func offsetKey(id, path string) string {
+	return id + "\x00" + path
+}
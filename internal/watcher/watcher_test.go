@@ -0,0 +1,203 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/logger"
+)
+
+type fakePublisher struct {
+	mu   sync.Mutex
+	msgs []bus.InboundMessage
+}
+
+func (p *fakePublisher) PublishInbound(msg bus.InboundMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.msgs = append(p.msgs, msg)
+	return nil
+}
+
+func (p *fakePublisher) messages() []bus.InboundMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]bus.InboundMessage(nil), p.msgs...)
+}
+
+func newTestWatcher(t *testing.T, cfg Config) (*Watcher, *fakePublisher) {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error", Format: "text", Output: "stdout"})
+	require.NoError(t, err)
+
+	if cfg.Debounce == 0 {
+		cfg.Debounce = 50 * time.Millisecond
+	}
+	pub := &fakePublisher{}
+	w := New(cfg, pub, log)
+	require.NoError(t, w.Start(context.Background()))
+	t.Cleanup(func() { _ = w.Stop() })
+	return w, pub
+}
+
+func appendFile(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestWatcher_MatchNewLines(t *testing.T) {
+	ws := t.TempDir()
+	logPath := filepath.Join(ws, "deploy.log")
+	appendFile(t, logPath, "ERROR old failure\n")
+
+	w, pub := newTestWatcher(t, Config{Workspace: ws})
+	rule, err := w.Add(Rule{Path: "deploy.log", Match: "ERROR", SessionID: "telegram:42"})
+	require.NoError(t, err)
+
+	appendFile(t, logPath, "INFO started\n")
+	appendFile(t, logPath, "ERROR disk full\n")
+
+	require.Eventually(t, func() bool { return len(pub.messages()) == 1 }, 2*time.Second, 20*time.Millisecond)
+
+	// Debounced into a single notification; old lines are not reported
+	time.Sleep(150 * time.Millisecond)
+	msgs := pub.messages()
+	require.Len(t, msgs, 1)
+
+	msg := msgs[0]
+	assert.Equal(t, "telegram:42", msg.SessionID)
+	assert.Equal(t, bus.ChannelTypeTelegram, msg.ChannelType)
+	assert.Equal(t, "watcher", msg.Metadata["source"])
+	assert.Equal(t, rule.ID, msg.Metadata["watch_id"])
+	assert.Contains(t, msg.Content, "ERROR disk full")
+	assert.NotContains(t, msg.Content, "old failure")
+	assert.NotContains(t, msg.Content, "INFO started")
+}
+
+func TestWatcher_NoMatchNoNotification(t *testing.T) {
+	ws := t.TempDir()
+	logPath := filepath.Join(ws, "app.log")
+	appendFile(t, logPath, "")
+
+	w, pub := newTestWatcher(t, Config{Workspace: ws})
+	_, err := w.Add(Rule{Path: logPath, Match: "ERROR", SessionID: "telegram:1"})
+	require.NoError(t, err)
+
+	appendFile(t, logPath, "INFO all good\n")
+	time.Sleep(300 * time.Millisecond)
+	assert.Empty(t, pub.messages())
+}
+
+func TestWatcher_DirectoryPatterns(t *testing.T) {
+	ws := t.TempDir()
+	dir := filepath.Join(ws, "reports")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	w, pub := newTestWatcher(t, Config{Workspace: ws})
+	_, err := w.Add(Rule{Path: "reports", Patterns: []string{"*.csv"}, SessionID: "telegram:7"})
+	require.NoError(t, err)
+
+	appendFile(t, filepath.Join(dir, "notes.txt"), "ignored\n")
+	appendFile(t, filepath.Join(dir, "daily.csv"), "a,b\n")
+
+	require.Eventually(t, func() bool { return len(pub.messages()) >= 1 }, 2*time.Second, 20*time.Millisecond)
+	time.Sleep(150 * time.Millisecond)
+
+	msgs := pub.messages()
+	require.Len(t, msgs, 1)
+	assert.Contains(t, msgs[0].Content, "daily.csv")
+}
+
+func TestWatcher_AddOutsideWorkspace(t *testing.T) {
+	ws := t.TempDir()
+	w, _ := newTestWatcher(t, Config{Workspace: ws})
+
+	_, err := w.Add(Rule{Path: "/etc/passwd", SessionID: "telegram:1"})
+	assert.Error(t, err)
+
+	allowed := t.TempDir()
+	w2, _ := newTestWatcher(t, Config{Workspace: ws, AllowedDirs: []string{allowed}})
+	_, err = w2.Add(Rule{Path: filepath.Join(allowed, "x.log"), SessionID: "telegram:1"})
+	assert.NoError(t, err)
+}
+
+func TestWatcher_PersistAndRemove(t *testing.T) {
+	ws := t.TempDir()
+	storage := NewStorage(ws)
+
+	w, _ := newTestWatcher(t, Config{
+		Workspace: ws,
+		Storage:   storage,
+		Rules:     []Rule{{ID: "static", Path: ".", SessionID: "telegram:1"}},
+	})
+
+	rule, err := w.Add(Rule{Path: "deploy.log", Match: "ERROR", SessionID: "telegram:1"})
+	require.NoError(t, err)
+	assert.Len(t, w.List(), 2)
+
+	stored, err := storage.Load()
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, rule.ID, stored[0].ID)
+
+	assert.Error(t, w.Remove("static"))
+	require.NoError(t, w.Remove(rule.ID))
+	assert.Error(t, w.Remove(rule.ID))
+
+	stored, err = storage.Load()
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+}
+
+func TestRule_Validate(t *testing.T) {
+	assert.NoError(t, Rule{Path: "a.log", SessionID: "telegram:1"}.Validate())
+	assert.Error(t, Rule{SessionID: "telegram:1"}.Validate())
+	assert.Error(t, Rule{Path: "a.log", SessionID: "nochannel"}.Validate())
+	assert.Error(t, Rule{Path: "a.log", SessionID: "telegram:1", Match: "("}.Validate())
+	assert.Error(t, Rule{Path: "a.log", SessionID: "telegram:1", Patterns: []string{"["}}.Validate())
+}
+
+func TestReadNewLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "f.log")
+	appendFile(t, path, "one\ntwo\npart")
+
+	lines, offset, err := readNewLines(path, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one", "two"}, lines)
+	assert.Equal(t, int64(len("one\ntwo\n")), offset)
+
+	// Incomplete line is returned once finished
+	appendFile(t, path, "ial\n")
+	lines, offset, err = readNewLines(path, offset)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"partial"}, lines)
+
+	// Truncated file is read from the beginning
+	require.NoError(t, os.WriteFile(path, []byte("new\n"), 0644))
+	lines, _, err = readNewLines(path, offset)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new"}, lines)
+}
+
+func TestFormatNotification_MaxLines(t *testing.T) {
+	w := New(Config{Workspace: "/ws", MaxLines: 2}, &fakePublisher{}, nil)
+	content := w.formatNotification(Rule{ID: "watch_1", Match: "E"}, "/ws/a.log", []string{"E1", "E2", "E3"})
+
+	assert.Contains(t, content, "a.log")
+	assert.Contains(t, content, "1 earlier lines omitted")
+	assert.False(t, strings.Contains(content, "E1"))
+	assert.Contains(t, content, "E3")
+}
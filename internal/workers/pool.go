@@ -8,7 +8,9 @@ import (
 	"sync"
 
 	"github.com/aatumaykin/nexbot/internal/bus"
+	"github.com/aatumaykin/nexbot/internal/digest"
 	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/msgtemplate"
 )
 
 // WorkerPool manages a pool of goroutine workers for concurrent task execution.
@@ -22,6 +24,8 @@ type WorkerPool struct {
 	logger     *logger.Logger
 	metrics    *PoolMetrics
 	messageBus *bus.MessageBus
+	digest     *digest.Aggregator
+	templates  *msgtemplate.Registry
 }
 
 // NewPool creates a new worker pool with the specified configuration.
@@ -40,6 +44,18 @@ func NewPool(workers int, bufferSize int, logger *logger.Logger, messageBus *bus
 	}
 }
 
+// SetDigest batches messages of cron send_message tasks into digests
+// instead of sending them immediately.
+func (p *WorkerPool) SetDigest(d *digest.Aggregator) {
+	p.digest = d
+}
+
+// SetTemplates lets cron send_message tasks send message templates:
+// {"template": "name", "data": {...}} instead of {"message": "text"}.
+func (p *WorkerPool) SetTemplates(templates *msgtemplate.Registry) {
+	p.templates = templates
+}
+
 // Start initializes and starts all worker goroutines.
 func (p *WorkerPool) Start() {
 	p.logger.Info("starting worker pool",
@@ -146,6 +162,11 @@ func (p *WorkerPool) QueueSize() int {
 	return len(p.taskQueue)
 }
 
+// QueueCapacity returns the maximum number of tasks waiting in the queue.
+func (p *WorkerPool) QueueCapacity() int {
+	return cap(p.taskQueue)
+}
+
 // taskWaitGroup wraps sync.WaitGroup with thread-safe metrics access.
 type taskWaitGroup struct {
 	sync.RWMutex
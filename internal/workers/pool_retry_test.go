@@ -10,6 +10,7 @@ import (
 	"github.com/aatumaykin/nexbot/internal/bus"
 	"github.com/aatumaykin/nexbot/internal/cron"
 	"github.com/aatumaykin/nexbot/internal/logger"
+	"github.com/aatumaykin/nexbot/internal/msgtemplate"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -235,6 +236,47 @@ func TestPool_ExecuteSendMessage_Success(t *testing.T) {
 	}
 }
 
+func TestPool_ExecuteSendMessage_Template(t *testing.T) {
+	log, err := logger.New(logger.Config{Level: "debug", Format: "text", Output: "stdout"})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	messageBus := bus.New(100, 10, log)
+	require.NoError(t, messageBus.Start(ctx))
+	defer func() { _ = messageBus.Stop() }()
+
+	outboundCh := messageBus.SubscribeOutbound(ctx)
+
+	templates, err := msgtemplate.NewRegistry(map[string]msgtemplate.Definition{
+		"disk_alert": {Text: "*Disk {{.host}}*: {{.usage}}% used", Format: "markdown"},
+	})
+	require.NoError(t, err)
+	pool := NewPool(1, 10, log, messageBus)
+	pool.SetTemplates(templates)
+
+	task := Task{
+		ID:   "template-task",
+		Type: "cron",
+		Payload: cron.CronTaskPayload{
+			Tool:      "send_message",
+			SessionID: "telegram:987654321",
+			Payload:   map[string]any{"template": "disk_alert", "data": map[string]any{"host": "nas", "usage": 93}},
+		},
+	}
+
+	result := pool.executeCronTask(ctx, task)
+	require.NoError(t, result.Error)
+
+	select {
+	case msg := <-outboundCh:
+		assert.Equal(t, "*Disk nas*: 93% used", msg.Content)
+		assert.Equal(t, bus.FormatTypeMarkdown, msg.Format)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Timeout waiting for outbound message")
+	}
+}
+
 func TestPool_ExecuteAgent_Success(t *testing.T) {
 	log, err := logger.New(logger.Config{Level: "debug", Format: "text", Output: "stdout"})
 	require.NoError(t, err)
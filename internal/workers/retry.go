@@ -8,6 +8,7 @@ import (
 
 	"github.com/aatumaykin/nexbot/internal/bus"
 	"github.com/aatumaykin/nexbot/internal/cron"
+	"github.com/aatumaykin/nexbot/internal/digest"
 	"github.com/aatumaykin/nexbot/internal/logger"
 )
 
@@ -115,8 +116,9 @@ func (p *WorkerPool) executeSendMessage(ctx context.Context, task Task, payload
 	// Parse channel and chat ID from session_id
 	var channel, chatID string
 	if strings.Contains(sessionID, ":") {
-		parts := strings.Split(sessionID, ":")
-		if len(parts) != 2 {
+		// A third part is the thread of a group chat ("telegram:chat_id:root_message_id")
+		parts := strings.SplitN(sessionID, ":", 3)
+		if len(parts) < 2 {
 			return "", fmt.Errorf("invalid session_id format: expected 'channel:chat_id', got '%s'", sessionID)
 		}
 		channel = parts[0]
@@ -130,21 +132,42 @@ func (p *WorkerPool) executeSendMessage(ctx context.Context, task Task, payload
 		return "", fmt.Errorf("no message content provided")
 	}
 
-	content, ok := payload.Payload["message"].(string)
-	if !ok {
-		return "", fmt.Errorf("no message content provided")
+	content, _ := payload.Payload["message"].(string)
+	format := bus.FormatType(payload.Format)
+
+	// A template replaces the message text and sets its format
+	if name, ok := payload.Payload["template"].(string); ok && name != "" {
+		if p.templates == nil {
+			return "", fmt.Errorf("message templates are not configured")
+		}
+		data, _ := payload.Payload["data"].(map[string]any)
+		rendered, err := p.templates.Render(name, data)
+		if err != nil {
+			return "", err
+		}
+		content = rendered.Text
+		if format == "" {
+			format = rendered.Format
+		}
 	}
 
 	if content == "" {
 		return "", fmt.Errorf("no message content provided")
 	}
 
+	// Batch the message into the digest of the session
+	if p.digest != nil && p.digest.Add(ctx, sessionID, digest.SourceCron, content) {
+		p.logger.InfoCtx(ctx, "send_message queued for digest",
+			logger.Field{Key: "task_id", Value: task.ID},
+			logger.Field{Key: "session_id", Value: sessionID})
+		return fmt.Sprintf("message queued for digest to %s:%s", channel, chatID), nil
+	}
+
 	// Create outbound message
-	format := bus.FormatType(payload.Format)
 	outboundMsg := bus.OutboundMessage{
 		ChannelType: bus.ChannelType(channel),
 		UserID:      "",
-		SessionID:   fmt.Sprintf("%s:%s", channel, chatID),
+		SessionID:   sessionID,
 		Content:     content,
 		Format:      format,
 		Timestamp:   time.Now(),
@@ -176,8 +199,9 @@ func (p *WorkerPool) executeAgent(ctx context.Context, task Task, payload cron.C
 	// Parse channel and chat ID from session_id
 	var channel, chatID string
 	if strings.Contains(sessionID, ":") {
-		parts := strings.Split(sessionID, ":")
-		if len(parts) != 2 {
+		// A third part is the thread of a group chat ("telegram:chat_id:root_message_id")
+		parts := strings.SplitN(sessionID, ":", 3)
+		if len(parts) < 2 {
 			return "", fmt.Errorf("invalid session_id format: expected 'channel:chat_id', got '%s'", sessionID)
 		}
 		channel = parts[0]
@@ -204,7 +228,7 @@ func (p *WorkerPool) executeAgent(ctx context.Context, task Task, payload cron.C
 	msg := bus.NewInboundMessage(
 		bus.ChannelType(channel),
 		"", // Empty user_id for cron tasks
-		fmt.Sprintf("%s:%s", channel, chatID),
+		sessionID,
 		content,
 		map[string]any{
 			"cron_job_id": task.ID,
@@ -0,0 +1,40 @@
+package workspace
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// caseInsensitivePaths reports whether paths are compared ignoring case,
+// as on Windows file systems.
+var caseInsensitivePaths = runtime.GOOS == "windows"
+
+// NormalizePath cleans a path and converts slashes to the OS separator, so
+// "C:/Users/bot/" and "C:\Users\bot" name the same directory on Windows.
+func NormalizePath(path string) string {
+	if path == "" {
+		return ""
+	}
+	return filepath.Clean(filepath.FromSlash(path))
+}
+
+// IsWithin reports whether path is dir or inside it. Both paths are
+// normalized first; on Windows the comparison ignores case.
+func IsWithin(path, dir string) bool {
+	path, dir = NormalizePath(path), NormalizePath(dir)
+	if path == "" || dir == "" {
+		return false
+	}
+	if caseInsensitivePaths {
+		path, dir = strings.ToLower(path), strings.ToLower(dir)
+	}
+	if path == dir {
+		return true
+	}
+	prefix := dir
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	return strings.HasPrefix(path, prefix)
+}
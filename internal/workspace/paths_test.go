@@ -0,0 +1,46 @@
+package workspace
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIsWithin(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "Data")
+
+	tests := []struct {
+		path string
+		dir  string
+		want bool
+	}{
+		{root, root, true},
+		{filepath.Join(root, "notes", "a.md"), root, true},
+		{filepath.Join(root, "notes"), root + string(filepath.Separator), true},
+		{filepath.ToSlash(filepath.Join(root, "a.md")), filepath.ToSlash(root) + "/", true},
+		{root + "-backup", root, false},
+		{filepath.Dir(root), root, false},
+		{filepath.Join(root, "a.md"), "", false},
+	}
+	for _, tt := range tests {
+		if got := IsWithin(tt.path, tt.dir); got != tt.want {
+			t.Errorf("IsWithin(%q, %q) = %v, want %v", tt.path, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestIsWithin_CaseInsensitive(t *testing.T) {
+	saved := caseInsensitivePaths
+	defer func() { caseInsensitivePaths = saved }()
+
+	root := filepath.Join(t.TempDir(), "Data")
+	path := filepath.Join(filepath.Dir(root), "DATA", "a.md")
+
+	caseInsensitivePaths = false
+	if IsWithin(path, root) {
+		t.Errorf("IsWithin(%q, %q) should be case-sensitive", path, root)
+	}
+	caseInsensitivePaths = true
+	if !IsWithin(path, root) {
+		t.Errorf("IsWithin(%q, %q) should ignore case on Windows", path, root)
+	}
+}
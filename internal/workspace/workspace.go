@@ -356,7 +356,7 @@ func (w *Workspace) ValidatePath(path string) error {
 // expandHome expands ~ to the user's home directory.
 // If the path doesn't start with ~/, it's returned unchanged.
 func expandHome(path string) string {
-	if len(path) > 0 && path[0] == '~' && (len(path) == 1 || path[1] == '/') {
+	if len(path) > 0 && path[0] == '~' && (len(path) == 1 || path[1] == '/' || path[1] == filepath.Separator) {
 		home, err := os.UserHomeDir()
 		if err != nil {
 			// If we can't get home directory, return original path
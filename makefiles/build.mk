@@ -2,7 +2,7 @@
 # Build & Deploy
 # ==========================================
 
-.PHONY: build build-all build-linux build-darwin build-darwin-arm build-windows install install-user release
+.PHONY: build build-bundle build-all build-linux build-darwin build-darwin-arm build-windows docker-bundle install install-user release
 
 build: ## Build binary for current platform
 	@echo "🔨 Building $(BINARY_NAME) for $(GOOS)/$(GOARCH)..."
@@ -15,6 +15,24 @@ build: ## Build binary for current platform
 	@echo "✅ Build complete: $(OUTPUT_DIR)/$(BINARY_NAME)"
 	@ls -lh $(OUTPUT_DIR)/$(BINARY_NAME)
 
+build-bundle: ## Build a static binary with embedded prompts and skills
+	@echo "🔨 Building bundled $(BINARY_NAME) for $(GOOS)/$(GOARCH)..."
+	@mkdir -p $(OUTPUT_DIR)
+	CGO_ENABLED=0 go build -v -tags bundle \
+		-ldflags "$(LDFLAGS)" \
+		-o $(OUTPUT_DIR)/$(BINARY_NAME)-bundle \
+		$(MAIN_PATH)
+	@echo "✅ Build complete: $(OUTPUT_DIR)/$(BINARY_NAME)-bundle"
+	@ls -lh $(OUTPUT_DIR)/$(BINARY_NAME)-bundle
+
+docker-bundle: ## Build a scratch container image with the bundled binary
+	@echo "🐳 Building image $(BINARY_NAME):$(VERSION)..."
+	docker build \
+		--build-arg VERSION=$(VERSION) \
+		--build-arg GIT_COMMIT=$(GIT_COMMIT) \
+		-t $(BINARY_NAME):$(VERSION) .
+	@echo "✅ Image built: $(BINARY_NAME):$(VERSION)"
+
 build-all: ## Build binary for all target platforms
 	@echo "🔨 Building $(BINARY_NAME) for all platforms..."
 	@mkdir -p $(OUTPUT_DIR)